@@ -0,0 +1,236 @@
+package blockchain
+
+import (
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/rlp"
+	"github.com/thetatoken/theta/store"
+	"github.com/thetatoken/theta/store/freezer"
+)
+
+// The tables of the ancient store, each holding an entry per height
+const (
+	AncientBlocksTable   = "blocks"   // the finalized block, empty if there is none at the height
+	AncientReceiptsTable = "receipts" // the receipts of the txs of the finalized block
+)
+
+// AncientTables lists the tables of the ancient store
+var AncientTables = []string{AncientBlocksTable, AncientReceiptsTable}
+
+// ancientBlockKey constructs the DB key for the height of the given block moved to the ancient store.
+func ancientBlockKey(hash common.Hash) common.Bytes {
+	return append(common.Bytes("anc/"), hash[:]...)
+}
+
+// SetAncientStore sets the ancient store the finalized blocks and their receipts are moved to once they are far
+// behind the tip. It should be called before the chain is used, and the ancient store should be opened with
+// AncientTables and an offset above the root height, since the root block is always kept in the DB.
+func (ch *Chain) SetAncientStore(ancient *freezer.Freezer) {
+	ch.ancient = ancient
+	ch.repairAncientStore()
+}
+
+// repairAncientStore completes moving the last frozen block, which might have been interrupted by a crash
+// after the block was appended to the ancient store.
+func (ch *Chain) repairAncientStore() {
+	frontier := ch.ancient.Frontier()
+	if frontier == ch.ancient.Offset() {
+		return
+	}
+	block, err := ch.findAncientBlockByHeight(frontier - 1)
+	if err != nil {
+		logger.Panic(err)
+	}
+	if block != nil {
+		if err := ch.removeFrozenBlock(block); err != nil {
+			logger.Panic(err)
+		}
+	}
+}
+
+// AncientFrontier returns the first height not moved to the ancient store, 0 if there is no ancient store.
+func (ch *Chain) AncientFrontier() uint64 {
+	if ch.ancient == nil {
+		return 0
+	}
+	return ch.ancient.Frontier()
+}
+
+// FreezeBlocks moves the finalized blocks up to endHeight (inclusive) and their receipts from the DB to the
+// ancient store. To avoid stalling the caller, at most maxBlocks blocks are moved per call. Returns the first
+// height not moved yet.
+func (ch *Chain) FreezeBlocks(endHeight uint64, maxBlocks uint64) (uint64, error) {
+	if ch.ancient == nil {
+		return 0, nil
+	}
+
+	rootHeight := ch.Root().Height
+	frontier := ch.ancient.Frontier()
+	frozen := []*core.ExtendedBlock{}
+	for height := frontier; height <= endHeight && height < frontier+maxBlocks; height++ {
+		// The root block is kept in the DB, and the blocks below it are not in the DB when the node starts
+		// from a snapshot, so their heights have empty entries
+		var block *core.ExtendedBlock
+		if height > rootHeight {
+			if block = ch.findFinalizedBlockByHeight(height); block == nil {
+				break // not finalized yet
+			}
+		}
+
+		entries, err := ch.ancientEntries(block)
+		if err != nil {
+			return height, err
+		}
+		if err := ch.ancient.Append(height, entries); err != nil {
+			return height, err
+		}
+		if block != nil {
+			frozen = append(frozen, block)
+		}
+	}
+	if err := ch.ancient.Sync(); err != nil {
+		return frontier, err
+	}
+
+	// The blocks are removed from the DB only once they are durable in the ancient store
+	for _, block := range frozen {
+		if err := ch.removeFrozenBlock(block); err != nil {
+			return ch.ancient.Frontier(), err
+		}
+	}
+	if len(frozen) > 0 {
+		logger.Infof("Moved the blocks from height %v to %v to the ancient store", frontier, ch.ancient.Frontier()-1)
+	}
+	return ch.ancient.Frontier(), nil
+}
+
+func (ch *Chain) findFinalizedBlockByHeight(height uint64) *core.ExtendedBlock {
+	for _, block := range ch.FindBlocksByHeight(height) {
+		if block.Status.IsFinalized() {
+			return block
+		}
+	}
+	return nil
+}
+
+// ancientEntries encodes the given block and the receipts of its txs for the ancient store
+func (ch *Chain) ancientEntries(block *core.ExtendedBlock) (map[string][]byte, error) {
+	if block == nil {
+		return map[string][]byte{AncientBlocksTable: {}, AncientReceiptsTable: {}}, nil
+	}
+	rawBlock, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		return nil, err
+	}
+	receipts := []*TxReceiptEntry{}
+	for _, rawTx := range block.Txs {
+		if receipt, found := ch.findTxReceiptInDB(crypto.Keccak256Hash(rawTx)); found {
+			receipts = append(receipts, receipt)
+		}
+	}
+	rawReceipts, err := rlp.EncodeToBytes(receipts)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{AncientBlocksTable: rawBlock, AncientReceiptsTable: rawReceipts}, nil
+}
+
+// removeFrozenBlock replaces the given frozen block with a pointer to its height, and removes its receipts
+// from the DB. A receipt is kept if the tx has been re-included in a later block.
+func (ch *Chain) removeFrozenBlock(block *core.ExtendedBlock) error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	hash := block.Hash()
+	if err := ch.store.Put(ancientBlockKey(hash), block.Height); err != nil {
+		return err
+	}
+	for _, rawTx := range block.Txs {
+		txHash := crypto.Keccak256Hash(rawTx)
+		txIndexEntry := &TxIndexEntry{}
+		if err := ch.store.Get(txIndexKey(txHash), txIndexEntry); err == nil && txIndexEntry.BlockHeight > block.Height {
+			continue
+		}
+		if err := ch.store.Delete(txReceiptKey(txHash)); err != nil && err != store.ErrKeyNotFound {
+			return err
+		}
+	}
+	if err := ch.store.Delete(hash[:]); err != nil && err != store.ErrKeyNotFound {
+		return err
+	}
+	return nil
+}
+
+// findAncientBlock looks up the given block in the ancient store.
+func (ch *Chain) findAncientBlock(hash common.Hash) (*core.ExtendedBlock, error) {
+	if ch.ancient == nil {
+		return nil, store.ErrKeyNotFound
+	}
+	var height uint64
+	if err := ch.store.Get(ancientBlockKey(hash), &height); err != nil {
+		return nil, err
+	}
+	block, err := ch.findAncientBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil || block.Hash() != hash {
+		return nil, store.ErrKeyNotFound
+	}
+	return block, nil
+}
+
+// findAncientBlockByHeight returns the finalized block at the given height in the ancient store, nil if
+// there is none.
+func (ch *Chain) findAncientBlockByHeight(height uint64) (*core.ExtendedBlock, error) {
+	raw, err := ch.ancient.Retrieve(AncientBlocksTable, height)
+	if err == freezer.ErrNotFound {
+		return nil, store.ErrKeyNotFound
+	}
+	if err != nil || len(raw) == 0 {
+		return nil, err
+	}
+	block := &core.ExtendedBlock{}
+	if err := rlp.DecodeBytes(raw, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// findAncientTxReceipt looks up the receipt of the given tx in the ancient store, through the tx index.
+func (ch *Chain) findAncientTxReceipt(hash common.Hash) (*TxReceiptEntry, bool) {
+	if ch.ancient == nil {
+		return nil, false
+	}
+	txIndexEntry := &TxIndexEntry{}
+	if err := ch.store.Get(txIndexKey(hash), txIndexEntry); err != nil {
+		return nil, false
+	}
+	height := txIndexEntry.BlockHeight
+	if prunedHeight, ok := ch.IndexPruningProgress(IndexTypeTxReceipt); ok && height <= prunedHeight {
+		return nil, false
+	}
+	raw, err := ch.ancient.Retrieve(AncientReceiptsTable, height)
+	if err != nil || len(raw) == 0 {
+		if err != nil && err != freezer.ErrNotFound {
+			logger.Error(err)
+		}
+		return nil, false
+	}
+	receipts := []*TxReceiptEntry{}
+	if err := rlp.DecodeBytes(raw, &receipts); err != nil {
+		logger.Error(err)
+		return nil, false
+	}
+	for _, receipt := range receipts {
+		if receipt.TxHash != hash {
+			continue
+		}
+		if prunedHeight, ok := ch.IndexPruningProgress(IndexTypeTxLog); ok && height <= prunedHeight {
+			receipt.Logs = nil
+		}
+		return receipt, true
+	}
+	return nil, false
+}
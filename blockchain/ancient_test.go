@@ -0,0 +1,97 @@
+package blockchain
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/store"
+	"github.com/thetatoken/theta/store/freezer"
+)
+
+func TestFreezeBlocks(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+
+	dir, err := ioutil.TempDir("", "ancient")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+	ancient, err := freezer.Open(dir, AncientTables, chain.Root().Height+1)
+	require.Nil(err)
+	defer ancient.Close()
+	chain.SetAncientStore(ancient)
+
+	parent := "a0"
+	for _, name := range []string{"a1", "a2", "a3", "a4"} {
+		block := core.CreateTestBlock(name, parent)
+		block.Txs = []common.Bytes{common.Bytes("tx_" + name)}
+		_, err := chain.AddBlock(block)
+		require.Nil(err)
+		txHash := crypto.Keccak256Hash(block.Txs[0])
+		require.Nil(chain.store.Put(txReceiptKey(txHash), TxReceiptEntry{TxHash: txHash, GasUsed: 21000}))
+		parent = name
+	}
+	_, err = chain.AddBlock(core.CreateTestBlock("b2", "a1")) // not finalized
+	require.Nil(err)
+	require.Nil(chain.FinalizePreviousBlocks(core.GetTestBlock("a3").Hash()))
+
+	// Only the finalized blocks are moved
+	frontier, err := chain.FreezeBlocks(10, 100)
+	require.Nil(err)
+	assert.Equal(uint64(4), frontier)
+	frontier, err = chain.FreezeBlocks(10, 100)
+	require.Nil(err)
+	assert.Equal(uint64(4), frontier)
+
+	a2 := core.GetTestBlock("a2")
+	hash := a2.Hash()
+	assert.Equal(store.ErrKeyNotFound, chain.store.Get(hash[:], &core.ExtendedBlock{}))
+	block, err := chain.FindBlock(hash)
+	require.Nil(err)
+	assert.Equal(hash, block.Hash())
+	assert.True(block.Status.IsFinalized())
+	assert.Equal(2, len(chain.FindBlocksByHeight(2)))
+
+	txHash := crypto.Keccak256Hash(common.Bytes("tx_a2"))
+	_, found := chain.findTxReceiptInDB(txHash)
+	assert.False(found)
+	receipt, found := chain.FindTxReceiptByHash(txHash)
+	require.True(found)
+	assert.Equal(uint64(21000), receipt.GasUsed)
+	tx, block, found := chain.FindTxByHash(txHash)
+	require.True(found)
+	assert.Equal(common.Bytes("tx_a2"), tx)
+	assert.Equal(hash, block.Hash())
+
+	// The root block and the blocks not moved are still in the DB
+	root := chain.Root()
+	require.NotNil(root)
+	assert.Nil(chain.store.Get(root.Hash().Bytes(), &core.ExtendedBlock{}))
+	a4 := core.GetTestBlock("a4").Hash()
+	assert.Nil(chain.store.Get(a4[:], &core.ExtendedBlock{}))
+	_, found = chain.findTxReceiptInDB(crypto.Keccak256Hash(common.Bytes("tx_a4")))
+	assert.True(found)
+
+	// The receipts pruned by the index retention are not served from the ancient store either
+	require.Nil(chain.store.Put(indexPruningProgressKey(IndexTypeTxReceipt), uint64(2)))
+	_, found = chain.FindTxReceiptByHash(txHash)
+	assert.False(found)
+	_, found = chain.FindTxReceiptByHash(crypto.Keccak256Hash(common.Bytes("tx_a3")))
+	assert.True(found)
+
+	// Moving the last frozen block is completed when it was interrupted
+	a3 := core.GetTestBlock("a3").Hash()
+	frozenA3, err := chain.FindBlock(a3)
+	require.Nil(err)
+	require.Nil(chain.store.Put(a3[:], frozenA3))
+	chain.SetAncientStore(ancient)
+	assert.Equal(store.ErrKeyNotFound, chain.store.Get(a3[:], &core.ExtendedBlock{}))
+}
@@ -0,0 +1,262 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store"
+)
+
+// BalanceHistoryBucketSize is the number of heights covered by a bucket of balance checkpoints.
+const BalanceHistoryBucketSize = 1000
+
+// MaxBalanceHistoryWindow is the maximum number of heights that can be covered by a single balance history query.
+const MaxBalanceHistoryWindow = 1000 * BalanceHistoryBucketSize
+
+// latestBalanceKey constructs the DB key for the latest balance checkpoint of the given address.
+func latestBalanceKey(address common.Address) common.Bytes {
+	return append(common.Bytes("bal/"), address[:]...)
+}
+
+// balanceHistoryKey constructs the composite DB key for the balance checkpoints of the given address
+// in the given bucket.
+func balanceHistoryKey(address common.Address, bucket uint64) common.Bytes {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, bucket)
+	key := append(common.Bytes("bah/"), address[:]...)
+	return append(key, buf[:n]...)
+}
+
+// BalanceCheckpoint records the balance of an address after the finalized block at the given height.
+type BalanceCheckpoint struct {
+	Height  uint64
+	Balance types.Coins
+}
+
+// balanceHistoryBucket contains the balance checkpoints of an address within a bucket of heights, and
+// links to the last checkpoint before the bucket so the history can be traversed backwards.
+type balanceHistoryBucket struct {
+	HasPrevious    bool
+	PreviousHeight uint64
+	Checkpoints    []BalanceCheckpoint
+}
+
+// BalanceReader reads the balances of the accounts in the state of a block.
+type BalanceReader interface {
+	// GetBalances returns the balances of the given addresses in the state of the given block. The
+	// addresses without an account are left out.
+	GetBalances(block *core.Block, addresses []common.Address) (map[common.Address]types.Coins, error)
+}
+
+// AddBlockBalanceCheckpoints records the balances of the accounts involved in the txs of the given finalized
+// block, read from the state of the block. Balance changes not caused by a tx of the block, e.g. the stake
+// returns, are captured the next time the account is involved in a tx.
+func (ch *Chain) AddBlockBalanceCheckpoints(block *core.ExtendedBlock, reader BalanceReader) error {
+	addresses := blockTxAddresses(block)
+	if len(addresses) == 0 {
+		return nil
+	}
+	balances, err := reader.GetBalances(block.Block, addresses)
+	if err != nil {
+		return err
+	}
+	return ch.AddBalanceCheckpoints(block.Height, balances)
+}
+
+// AddBalanceCheckpoints records the balances of the given addresses after the finalized block at the
+// given height. A checkpoint is only added if the balance has changed since the last checkpoint.
+func (ch *Chain) AddBalanceCheckpoints(height uint64, balances map[common.Address]types.Coins) error {
+	for address, balance := range balances {
+		balance = balance.NoNil()
+		latest, found := ch.findLatestBalanceCheckpoint(address)
+		if found && (latest.Height >= height || latest.Balance.IsEqual(balance)) {
+			continue
+		}
+
+		key := balanceHistoryKey(address, height/BalanceHistoryBucketSize)
+		bucket := &balanceHistoryBucket{}
+		err := ch.store.Get(key, bucket)
+		if err == store.ErrKeyNotFound {
+			bucket = &balanceHistoryBucket{
+				HasPrevious:    found,
+				PreviousHeight: latest.Height,
+			}
+		} else if err != nil {
+			return err
+		}
+
+		checkpoint := BalanceCheckpoint{
+			Height:  height,
+			Balance: balance,
+		}
+		bucket.Checkpoints = append(bucket.Checkpoints, checkpoint)
+		if err := ch.store.Put(key, bucket); err != nil {
+			return err
+		}
+		if err := ch.store.Put(latestBalanceKey(address), checkpoint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeBalanceCheckpoints removes the balance checkpoints at or above the given height of the given
+// addresses, e.g. when the finalized blocks from the height are rolled back. The checkpoint before the
+// height becomes the latest checkpoint again.
+func (ch *Chain) removeBalanceCheckpoints(height uint64, addresses []common.Address) error {
+	for _, address := range addresses {
+		for {
+			latest, found := ch.findLatestBalanceCheckpoint(address)
+			if !found || latest.Height < height {
+				break
+			}
+
+			bucketIndex := latest.Height / BalanceHistoryBucketSize
+			bucket, ok := ch.findBalanceHistoryBucket(address, bucketIndex)
+			if !ok {
+				bucket = &balanceHistoryBucket{}
+			}
+			for len(bucket.Checkpoints) > 0 && bucket.Checkpoints[len(bucket.Checkpoints)-1].Height >= height {
+				bucket.Checkpoints = bucket.Checkpoints[:len(bucket.Checkpoints)-1]
+			}
+
+			if len(bucket.Checkpoints) > 0 {
+				if err := ch.store.Put(balanceHistoryKey(address, bucketIndex), bucket); err != nil {
+					return err
+				}
+				if err := ch.store.Put(latestBalanceKey(address), bucket.Checkpoints[len(bucket.Checkpoints)-1]); err != nil {
+					return err
+				}
+				break
+			}
+
+			err := ch.store.Delete(balanceHistoryKey(address, bucketIndex))
+			if err != nil && err != store.ErrKeyNotFound {
+				return err
+			}
+			previous, found := BalanceCheckpoint{}, false
+			if bucket.HasPrevious {
+				previous, found = ch.findBalanceCheckpointInBucket(address, bucket.PreviousHeight)
+			}
+			if !found {
+				err := ch.store.Delete(latestBalanceKey(address))
+				if err != nil && err != store.ErrKeyNotFound {
+					return err
+				}
+				break
+			}
+			if err := ch.store.Put(latestBalanceKey(address), previous); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// findBalanceCheckpointInBucket returns the last checkpoint at or before the given height in the bucket
+// of the height.
+func (ch *Chain) findBalanceCheckpointInBucket(address common.Address, height uint64) (BalanceCheckpoint, bool) {
+	bucket, ok := ch.findBalanceHistoryBucket(address, height/BalanceHistoryBucketSize)
+	if !ok {
+		return BalanceCheckpoint{}, false
+	}
+	for i := len(bucket.Checkpoints) - 1; i >= 0; i-- {
+		if bucket.Checkpoints[i].Height <= height {
+			return bucket.Checkpoints[i], true
+		}
+	}
+	return BalanceCheckpoint{}, false
+}
+
+func (ch *Chain) findLatestBalanceCheckpoint(address common.Address) (BalanceCheckpoint, bool) {
+	checkpoint := BalanceCheckpoint{}
+	err := ch.store.Get(latestBalanceKey(address), &checkpoint)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Error(err)
+		}
+		return BalanceCheckpoint{}, false
+	}
+	return checkpoint, true
+}
+
+func (ch *Chain) findBalanceHistoryBucket(address common.Address, bucket uint64) (*balanceHistoryBucket, bool) {
+	entry := &balanceHistoryBucket{}
+	err := ch.store.Get(balanceHistoryKey(address, bucket), entry)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Error(err)
+		}
+		return nil, false
+	}
+	return entry, true
+}
+
+// findBalanceCheckpointAt returns the last checkpoint at or before the given height.
+func (ch *Chain) findBalanceCheckpointAt(address common.Address, height uint64) (BalanceCheckpoint, bool) {
+	latest, found := ch.findLatestBalanceCheckpoint(address)
+	if !found || latest.Height <= height {
+		return latest, found
+	}
+
+	// Start from the bucket of the height if it has checkpoints, otherwise walk back from the latest checkpoint
+	bucketHeight := latest.Height
+	if _, ok := ch.findBalanceHistoryBucket(address, height/BalanceHistoryBucketSize); ok {
+		bucketHeight = height
+	}
+	for {
+		bucket, ok := ch.findBalanceHistoryBucket(address, bucketHeight/BalanceHistoryBucketSize)
+		if !ok {
+			return BalanceCheckpoint{}, false
+		}
+		for i := len(bucket.Checkpoints) - 1; i >= 0; i-- {
+			if bucket.Checkpoints[i].Height <= height {
+				return bucket.Checkpoints[i], true
+			}
+		}
+		if !bucket.HasPrevious {
+			return BalanceCheckpoint{}, false
+		}
+		bucketHeight = bucket.PreviousHeight
+	}
+}
+
+// FindBalanceHistory returns the balance checkpoints of the given address over heights [start, end].
+// The first checkpoint is the balance as of the start height, if the address has any checkpoint at or
+// before the start height.
+func (ch *Chain) FindBalanceHistory(address common.Address, start, end uint64) ([]BalanceCheckpoint, error) {
+	if start > end {
+		return nil, errors.New("start height must not be greater than end height")
+	}
+	if end-start >= MaxBalanceHistoryWindow {
+		return nil, errors.New("height window too large")
+	}
+
+	checkpoints := []BalanceCheckpoint{}
+	if checkpoint, ok := ch.findBalanceCheckpointAt(address, start); ok {
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	latest, found := ch.findLatestBalanceCheckpoint(address)
+	if !found || latest.Height <= start {
+		return checkpoints, nil
+	}
+	if latest.Height < end {
+		end = latest.Height
+	}
+	for b := start / BalanceHistoryBucketSize; b <= end/BalanceHistoryBucketSize; b++ {
+		bucket, ok := ch.findBalanceHistoryBucket(address, b)
+		if !ok {
+			continue
+		}
+		for _, checkpoint := range bucket.Checkpoints {
+			if checkpoint.Height > start && checkpoint.Height <= end {
+				checkpoints = append(checkpoints, checkpoint)
+			}
+		}
+	}
+	return checkpoints, nil
+}
@@ -0,0 +1,112 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestBalanceHistory(t *testing.T) {
+	assert := assert.New(t)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	chain.AddBalanceCheckpoints(5, map[common.Address]types.Coins{alice: types.NewCoins(100, 0)})
+	chain.AddBalanceCheckpoints(10, map[common.Address]types.Coins{alice: types.NewCoins(100, 0), bob: types.NewCoins(0, 7)})
+	chain.AddBalanceCheckpoints(1500, map[common.Address]types.Coins{alice: types.NewCoins(200, 0)})
+	chain.AddBalanceCheckpoints(3200, map[common.Address]types.Coins{alice: types.NewCoins(50, 1)})
+
+	// Before the first checkpoint
+	checkpoints, err := chain.FindBalanceHistory(alice, 1, 4)
+	assert.Nil(err)
+	assert.Equal(0, len(checkpoints))
+
+	// Unchanged balances are not recorded
+	checkpoints, err = chain.FindBalanceHistory(alice, 6, 2000)
+	assert.Nil(err)
+	assert.Equal(2, len(checkpoints))
+	assert.Equal(uint64(5), checkpoints[0].Height)
+	assert.Equal(int64(100), checkpoints[0].Balance.ThetaWei.Int64())
+	assert.Equal(uint64(1500), checkpoints[1].Height)
+	assert.Equal(int64(200), checkpoints[1].Balance.ThetaWei.Int64())
+
+	// The balance as of the start height is found across buckets without checkpoints
+	checkpoints, err = chain.FindBalanceHistory(alice, 2500, 2600)
+	assert.Nil(err)
+	assert.Equal(1, len(checkpoints))
+	assert.Equal(uint64(1500), checkpoints[0].Height)
+
+	checkpoints, err = chain.FindBalanceHistory(alice, 4000, 5000)
+	assert.Nil(err)
+	assert.Equal(1, len(checkpoints))
+	assert.Equal(uint64(3200), checkpoints[0].Height)
+	assert.Equal(int64(1), checkpoints[0].Balance.TFuelWei.Int64())
+
+	checkpoints, err = chain.FindBalanceHistory(bob, 1, 5000)
+	assert.Nil(err)
+	assert.Equal(1, len(checkpoints))
+	assert.Equal(uint64(10), checkpoints[0].Height)
+
+	_, err = chain.FindBalanceHistory(alice, 2, 1)
+	assert.NotNil(err)
+	_, err = chain.FindBalanceHistory(alice, 1, MaxBalanceHistoryWindow+1)
+	assert.NotNil(err)
+}
+
+func TestRemoveBalanceCheckpoints(t *testing.T) {
+	assert := assert.New(t)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	chain.AddBalanceCheckpoints(5, map[common.Address]types.Coins{alice: types.NewCoins(100, 0)})
+	chain.AddBalanceCheckpoints(10, map[common.Address]types.Coins{alice: types.NewCoins(150, 0), bob: types.NewCoins(0, 7)})
+	chain.AddBalanceCheckpoints(1500, map[common.Address]types.Coins{alice: types.NewCoins(200, 0)})
+	chain.AddBalanceCheckpoints(1501, map[common.Address]types.Coins{alice: types.NewCoins(250, 0)})
+
+	// Within a bucket
+	assert.Nil(chain.removeBalanceCheckpoints(1501, []common.Address{alice}))
+	latest, found := chain.findLatestBalanceCheckpoint(alice)
+	assert.True(found)
+	assert.Equal(uint64(1500), latest.Height)
+
+	// Across buckets, the checkpoint before the emptied bucket becomes the latest again
+	assert.Nil(chain.removeBalanceCheckpoints(1000, []common.Address{alice}))
+	latest, found = chain.findLatestBalanceCheckpoint(alice)
+	assert.True(found)
+	assert.Equal(uint64(10), latest.Height)
+	checkpoints, err := chain.FindBalanceHistory(alice, 1, 2000)
+	assert.Nil(err)
+	assert.Equal(2, len(checkpoints))
+
+	// A new checkpoint after the rollback links to the remaining checkpoints
+	chain.AddBalanceCheckpoints(1600, map[common.Address]types.Coins{alice: types.NewCoins(300, 0)})
+	checkpoint, found := chain.findBalanceCheckpointAt(alice, 1599)
+	assert.True(found)
+	assert.Equal(uint64(10), checkpoint.Height)
+
+	// All checkpoints removed
+	assert.Nil(chain.removeBalanceCheckpoints(10, []common.Address{bob}))
+	_, found = chain.findLatestBalanceCheckpoint(bob)
+	assert.False(found)
+	checkpoints, err = chain.FindBalanceHistory(bob, 1, 2000)
+	assert.Nil(err)
+	assert.Equal(0, len(checkpoints))
+
+	// The checkpoints below the height are kept
+	assert.Nil(chain.removeBalanceCheckpoints(8, []common.Address{alice}))
+	checkpoints, err = chain.FindBalanceHistory(alice, 1, 2000)
+	assert.Nil(err)
+	assert.Equal(1, len(checkpoints))
+	assert.Equal(uint64(5), checkpoints[0].Height)
+}
@@ -0,0 +1,248 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store"
+)
+
+// MaxLogsQueryWindow is the maximum number of blocks that can be searched by a single logs query.
+const MaxLogsQueryWindow = 10000
+
+// MaxLogsQueryResults is the maximum number of logs returned by a single logs query.
+const MaxLogsQueryResults = 10000
+
+// logsBloomKey constructs the DB key for the logs bloom of the finalized block at the given height.
+func logsBloomKey(height uint64) common.Bytes {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, height)
+	return append(common.Bytes("blm/"), buf[:n]...)
+}
+
+// LogsBloomEntry records the bloom filter of the logs emitted by the txs of a finalized block.
+type LogsBloomEntry struct {
+	BlockHash common.Hash
+	Bloom     core.Bloom
+}
+
+// LogsBloom returns the bloom filter of the addresses and the topics of the given logs, the same as the
+// logs bloom of the Ethereum blocks.
+func LogsBloom(logs []*types.Log) core.Bloom {
+	bin := new(big.Int)
+	for _, log := range logs {
+		bin.Or(bin, core.Bloom9(log.Address.Bytes()))
+		for _, topic := range log.Topics {
+			bin.Or(bin, core.Bloom9(topic.Bytes()))
+		}
+	}
+	return core.BytesToBloom(bin.Bytes())
+}
+
+// AddLogsBloom adds the bloom of the logs of the given finalized block to the bloom index, given the
+// receipts of its txs.
+func (ch *Chain) AddLogsBloom(block *core.ExtendedBlock, receipts BlockTxReceipts) {
+	logs := []*types.Log{}
+	for _, receipt := range receipts {
+		if receipt != nil {
+			logs = append(logs, receipt.Logs...)
+		}
+	}
+
+	entry := LogsBloomEntry{
+		BlockHash: block.Hash(),
+		Bloom:     LogsBloom(logs),
+	}
+	err := ch.store.Put(logsBloomKey(block.Height), entry)
+	if err != nil {
+		logger.Panic(err)
+	}
+}
+
+// removeLogsBloom removes the logs bloom of the finalized block at the given height from the bloom index.
+func (ch *Chain) removeLogsBloom(height uint64) error {
+	err := ch.store.Delete(logsBloomKey(height))
+	if err != nil && err != store.ErrKeyNotFound {
+		return err
+	}
+	return nil
+}
+
+// FindLogsBloom looks up the logs bloom of the finalized block at the given height.
+func (ch *Chain) FindLogsBloom(height uint64) (*LogsBloomEntry, bool) {
+	entry := &LogsBloomEntry{}
+	err := ch.store.Get(logsBloomKey(height), entry)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Error(err)
+		}
+		return nil, false
+	}
+	return entry, true
+}
+
+// LogFilter selects the logs by the address of the emitting contract and by their topics, with the
+// semantics of the Ethereum log filters: a log matches if its address is any of the addresses, and each
+// of its topics is any of the topics at the same position. An empty list matches anything.
+type LogFilter struct {
+	Addresses []common.Address
+	Topics    [][]common.Hash
+}
+
+// MayMatch returns false if the block with the given logs bloom has no log matching the filter. It might
+// return true for a block without any matching log.
+func (f *LogFilter) MayMatch(bloom core.Bloom) bool {
+	if len(f.Addresses) > 0 {
+		found := false
+		for _, address := range f.Addresses {
+			if core.BloomLookup(bloom, address) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, topics := range f.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range topics {
+			if core.BloomLookup(bloom, topic) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches returns true if the given log matches the filter.
+func (f *LogFilter) Matches(log *types.Log) bool {
+	if len(f.Addresses) > 0 {
+		found := false
+		for _, address := range f.Addresses {
+			if log.Address == address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Topics) > len(log.Topics) {
+		return false
+	}
+	for i, topics := range f.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range topics {
+			if log.Topics[i] == topic {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// FilteredLog is a log matching a filter, along with its position in the chain.
+type FilteredLog struct {
+	*types.Log
+	BlockHash   common.Hash
+	BlockHeight uint64
+	TxHash      common.Hash
+	TxIndex     uint64
+	LogIndex    uint64 // index of the log in the block
+}
+
+// GetLogs returns the logs of the finalized blocks within heights [start, end] matching the given filter.
+// The blocks whose logs blooms rule out the filter are skipped without reading their receipts. The blocks
+// finalized before the bloom index was introduced are searched through their receipts, and the blocks whose
+// logs have been pruned are skipped.
+func (ch *Chain) GetLogs(start, end uint64, filter *LogFilter) ([]*FilteredLog, error) {
+	if start > end {
+		return nil, errors.New("start height must not be greater than end height")
+	}
+	if end-start >= MaxLogsQueryWindow {
+		return nil, errors.New("height window too large")
+	}
+
+	for _, indexType := range []IndexType{IndexTypeTxReceipt, IndexTypeTxLog} {
+		if prunedHeight, ok := ch.IndexPruningProgress(indexType); ok && start <= prunedHeight {
+			start = prunedHeight + 1
+		}
+	}
+
+	logs := []*FilteredLog{}
+	for height := start; height <= end; height++ {
+		var block *core.ExtendedBlock
+		if entry, found := ch.FindLogsBloom(height); found {
+			if !filter.MayMatch(entry.Bloom) {
+				continue
+			}
+			b, err := ch.FindBlock(entry.BlockHash)
+			if err != nil {
+				continue
+			}
+			block = b
+		} else {
+			block = ch.FindFinalizedBlockByHeight(height)
+			if block == nil {
+				continue
+			}
+		}
+
+		logIndex := uint64(0)
+		for txIndex, rawTx := range block.Txs {
+			txHash := crypto.Keccak256Hash(rawTx)
+			receipt, found := ch.FindTxReceiptByHash(txHash)
+			if !found {
+				continue
+			}
+			for _, log := range receipt.Logs {
+				if filter.Matches(log) {
+					if len(logs) >= MaxLogsQueryResults {
+						return nil, errors.New("too many logs matching the filter, narrow down the height window")
+					}
+					logs = append(logs, &FilteredLog{
+						Log:         log,
+						BlockHash:   block.Hash(),
+						BlockHeight: block.Height,
+						TxHash:      txHash,
+						TxIndex:     uint64(txIndex),
+						LogIndex:    logIndex,
+					})
+				}
+				logIndex++
+			}
+		}
+	}
+	return logs, nil
+}
+
+// FindFinalizedBlockByHeight returns the finalized block at the given height, nil if not found.
+func (ch *Chain) FindFinalizedBlockByHeight(height uint64) *core.ExtendedBlock {
+	for _, block := range ch.FindBlocksByHeight(height) {
+		if block.Status.IsFinalized() {
+			return block
+		}
+	}
+	return nil
+}
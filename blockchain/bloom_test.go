@@ -0,0 +1,123 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestLogsBloom(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	carol := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	transfer := common.HexToHash("0xaaaa")
+	approval := common.HexToHash("0xbbbb")
+
+	tx1 := common.Bytes("tx1")
+	tx2 := common.Bytes("tx2")
+	tx3 := common.Bytes("tx3")
+	receipts := map[string][]*types.Log{
+		"tx1": {&types.Log{Address: alice, Topics: []common.Hash{transfer}}},
+		"tx2": {&types.Log{Address: bob, Topics: []common.Hash{approval}}},
+		"tx3": {
+			&types.Log{Address: alice, Topics: []common.Hash{approval}},
+			&types.Log{Address: bob, Topics: []common.Hash{transfer, approval}},
+		},
+	}
+	for _, tx := range []common.Bytes{tx1, tx2, tx3} {
+		txHash := crypto.Keccak256Hash(tx)
+		err := chain.store.Put(txReceiptKey(txHash), TxReceiptEntry{
+			TxHash: txHash,
+			Logs:   receipts[string(tx)],
+		})
+		require.Nil(err)
+	}
+
+	block1 := core.CreateTestBlock("b1", "")
+	block1.Height = 1
+	block1.Txs = []common.Bytes{tx1}
+
+	block2 := core.CreateTestBlock("b2", "")
+	block2.Height = 2
+	block2.Txs = []common.Bytes{tx2, tx3}
+
+	eb1, err := chain.AddBlock(block1)
+	require.Nil(err)
+	eb2, err := chain.AddBlock(block2)
+	require.Nil(err)
+	for _, eb := range []*core.ExtendedBlock{eb1, eb2} {
+		eb.Status = core.BlockStatusDirectlyFinalized
+		require.Nil(chain.saveBlock(eb))
+	}
+
+	// Block 2 is searched through its receipts before its bloom is added
+	logs, err := chain.GetLogs(1, 2, &LogFilter{Addresses: []common.Address{bob}})
+	require.Nil(err)
+	require.Equal(2, len(logs))
+	assert.Equal(uint64(2), logs[0].BlockHeight)
+	assert.Equal(crypto.Keccak256Hash(tx3), logs[1].TxHash)
+	assert.Equal(uint64(1), logs[1].TxIndex)
+	assert.Equal(uint64(2), logs[1].LogIndex)
+
+	chain.AddLogsBloom(eb1, chain.FindBlockTxReceipts(eb1))
+	chain.AddLogsBloom(eb2, chain.FindBlockTxReceipts(eb2))
+
+	entry, found := chain.FindLogsBloom(1)
+	require.True(found)
+	assert.Equal(eb1.Hash(), entry.BlockHash)
+	assert.True((&LogFilter{Addresses: []common.Address{alice}}).MayMatch(entry.Bloom))
+	assert.True((&LogFilter{Topics: [][]common.Hash{{transfer}}}).MayMatch(entry.Bloom))
+	assert.False((&LogFilter{Addresses: []common.Address{carol}}).MayMatch(entry.Bloom))
+	assert.False((&LogFilter{Topics: [][]common.Hash{{approval}}}).MayMatch(entry.Bloom))
+
+	logs, err = chain.GetLogs(1, 2, &LogFilter{Addresses: []common.Address{alice}})
+	require.Nil(err)
+	require.Equal(2, len(logs))
+	assert.Equal(uint64(1), logs[0].BlockHeight)
+	assert.Equal(uint64(2), logs[1].BlockHeight)
+	assert.Equal(uint64(1), logs[1].LogIndex)
+
+	// The topics are matched by position, and any of the alternatives at a position matches
+	logs, err = chain.GetLogs(1, 2, &LogFilter{Topics: [][]common.Hash{{}, {approval}}})
+	require.Nil(err)
+	require.Equal(1, len(logs))
+	assert.Equal(bob, logs[0].Address)
+	logs, err = chain.GetLogs(1, 2, &LogFilter{Topics: [][]common.Hash{{transfer, approval}}})
+	require.Nil(err)
+	assert.Equal(4, len(logs))
+
+	logs, err = chain.GetLogs(1, 2, &LogFilter{Addresses: []common.Address{carol}})
+	require.Nil(err)
+	assert.Equal(0, len(logs))
+
+	_, err = chain.GetLogs(2, 1, &LogFilter{})
+	assert.NotNil(err)
+	_, err = chain.GetLogs(0, MaxLogsQueryWindow, &LogFilter{})
+	assert.NotNil(err)
+
+	// Rolling back the finalization removes the bloom
+	require.Nil(chain.RollbackIndices(eb2))
+	_, found = chain.FindLogsBloom(2)
+	assert.False(found)
+
+	// Pruning the logs removes the bloom, and the pruned heights are skipped
+	_, err = chain.PruneIndex(IndexTypeTxLog, 1, 10)
+	require.Nil(err)
+	_, found = chain.FindLogsBloom(1)
+	assert.False(found)
+	logs, err = chain.GetLogs(1, 2, &LogFilter{Addresses: []common.Address{alice}})
+	require.Nil(err)
+	require.Equal(1, len(logs))
+	assert.Equal(uint64(2), logs[0].BlockHeight)
+}
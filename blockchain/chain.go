@@ -4,13 +4,16 @@ import (
 	"encoding/binary"
 	"fmt"
 	"sync"
+	"time"
 
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/store"
+	"github.com/thetatoken/theta/store/freezer"
 )
 
 const maxDistance = 2000
@@ -24,6 +27,9 @@ type Chain struct {
 	ChainID string
 	root    common.Hash
 
+	firstSeen *lru.Cache       // first-seen times of the txs and blocks not finalized yet, nil if not recorded
+	ancient   *freezer.Freezer // the finalized blocks far behind the tip, nil if they are kept in the DB
+
 	mu *sync.RWMutex
 }
 
@@ -71,9 +77,8 @@ func (ch *Chain) addBlock(block *core.Block, isSnapshotRoot bool) (*core.Extende
 		return nil, errors.Errorf("ChainID mismatch: block.ChainID(%s) != %s", block.ChainID, ch.ChainID)
 	}
 
-	val := &core.ExtendedBlock{}
 	hash := block.Hash()
-	err := ch.store.Get(hash[:], val)
+	val, err := ch.findBlock(hash)
 	if err == nil {
 		// Block has already been added.
 		return val, fmt.Errorf("Block has already been added: %X", hash[:])
@@ -91,6 +96,10 @@ func (ch *Chain) addBlock(block *core.Block, isSnapshotRoot bool) (*core.Extende
 		}
 	}
 
+	if !isSnapshotRoot {
+		ch.RecordFirstSeen(hash, time.Now())
+	}
+
 	extendedBlock := &core.ExtendedBlock{Block: block}
 
 	// Update children if present.
@@ -223,6 +232,23 @@ func (ch *Chain) MarkBlockValid(hash common.Hash) *core.ExtendedBlock {
 	return block
 }
 
+// MarkBlockValidInBatch marks the block valid along with the other writes in the given batch. The batch
+// is written while holding the chain lock, so that it can not overwrite a concurrent update of the block.
+func (ch *Chain) MarkBlockValidInBatch(hash common.Hash, batch store.Batch) (*core.ExtendedBlock, error) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	block, err := ch.findBlock(hash)
+	if err != nil {
+		return nil, err
+	}
+	block.Status = core.BlockStatusValid
+	if err := saveBlockTo(batch, block); err != nil {
+		return nil, err
+	}
+	return block, batch.Write()
+}
+
 func (ch *Chain) MarkBlockInvalid(hash common.Hash) *core.ExtendedBlock {
 	ch.mu.Lock()
 	defer ch.mu.Unlock()
@@ -274,6 +300,79 @@ func (ch *Chain) FinalizePreviousBlocks(hash common.Hash) error {
 	ch.mu.Lock()
 	defer ch.mu.Unlock()
 
+	return ch.finalizePreviousBlocks(hash, ch.store)
+}
+
+// FinalizePreviousBlocksInBatch marks the block and its ancestors finalized along with the other writes
+// in the given batch. The batch is written while holding the chain lock, so that it can not overwrite a
+// concurrent update of the blocks. Nothing is written if the branch cannot be finalized.
+func (ch *Chain) FinalizePreviousBlocksInBatch(hash common.Hash, batch store.Batch) error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if err := ch.finalizePreviousBlocks(hash, batch); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// FindBlocksToFinalize returns the block and its ancestors that are not finalized yet in the ascending
+// order of height, i.e. the blocks that FinalizePreviousBlocks marks directly or indirectly finalized.
+func (ch *Chain) FindBlocksToFinalize(hash common.Hash) ([]*core.ExtendedBlock, error) {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	blocks := []*core.ExtendedBlock{}
+	for !hash.IsEmpty() {
+		block, err := ch.findBlock(hash)
+		if err != nil || block.Status.IsFinalized() {
+			break
+		}
+		if block.Status == core.BlockStatusDisposed {
+			return nil, errors.New("Cannot finalize disposed branch")
+		}
+		blocks = append(blocks, block)
+		hash = block.Parent
+	}
+	for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+		blocks[i], blocks[j] = blocks[j], blocks[i]
+	}
+	return blocks, nil
+}
+
+// RollbackIndices removes the given finalized block from the stats, supply, logs bloom, timings, NFT, contract stats,
+// slash, search and balance indices when its finalization is rolled back. The blocks need to be rolled back in the
+// descending order of height.
+func (ch *Chain) RollbackIndices(block *core.ExtendedBlock) error {
+	if err := ch.removeBlockStats(block.Height); err != nil {
+		return err
+	}
+	if err := ch.removeSupplyDelta(block.Height); err != nil {
+		return err
+	}
+	if err := ch.removeLogsBloom(block.Height); err != nil {
+		return err
+	}
+	if err := ch.removeTxTimings(block); err != nil {
+		return err
+	}
+	receipts := ch.FindBlockTxReceipts(block)
+	if err := ch.removeNFTTransfers(block, receipts); err != nil {
+		return err
+	}
+	if err := ch.removeContractStats(block, receipts); err != nil {
+		return err
+	}
+	if err := ch.removeSlashRecords(block, receipts); err != nil {
+		return err
+	}
+	if err := ch.removeTxsFromSearchIndex(block); err != nil {
+		return err
+	}
+	return ch.removeBalanceCheckpoints(block.Height, blockTxAddresses(block))
+}
+
+func (ch *Chain) finalizePreviousBlocks(hash common.Hash, w blockWriter) error {
 	status := core.BlockStatusDirectlyFinalized
 	for !hash.IsEmpty() {
 		block, err := ch.findBlock(hash)
@@ -285,7 +384,7 @@ func (ch *Chain) FinalizePreviousBlocks(hash common.Hash) error {
 		}
 		block.Status = status
 		status = core.BlockStatusIndirectlyFinalized // Only the first block is marked as directly finalized
-		err = ch.saveBlock(block)
+		err = saveBlockTo(w, block)
 		if err != nil {
 			logger.Panic(err)
 		}
@@ -299,10 +398,19 @@ func (ch *Chain) IsOrphan(block *core.Block) bool {
 	return err != nil
 }
 
+// blockWriter is either the store of the chain or a batch of writes to the store
+type blockWriter interface {
+	Put(key common.Bytes, value interface{}) error
+}
+
 // saveBlock updates a previously stored block.
 func (ch *Chain) saveBlock(block *core.ExtendedBlock) error {
+	return saveBlockTo(ch.store, block)
+}
+
+func saveBlockTo(w blockWriter, block *core.ExtendedBlock) error {
 	hash := block.Hash()
-	return ch.store.Put(hash[:], block)
+	return w.Put(hash[:], block)
 }
 
 func (ch *Chain) SaveBlock(block *core.ExtendedBlock) error {
@@ -316,10 +424,13 @@ func (ch *Chain) FindBlock(hash common.Hash) (*core.ExtendedBlock, error) {
 	return ch.findBlock(hash)
 }
 
-// findBlock is the non-locking version of FindBlock.
+// findBlock is the non-locking version of FindBlock. The blocks moved to the ancient store are looked up there.
 func (ch *Chain) findBlock(hash common.Hash) (*core.ExtendedBlock, error) {
 	var block core.ExtendedBlock
 	err := ch.store.Get(hash[:], &block)
+	if err == store.ErrKeyNotFound {
+		return ch.findAncientBlock(hash)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,45 @@
+package blockchain
+
+import (
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/store"
+)
+
+// verifiedContractKey constructs the DB key for the verified source of the given contract.
+func verifiedContractKey(address common.Address) common.Bytes {
+	return append(common.Bytes("vcr/"), address[:]...)
+}
+
+// VerifiedContract is a contract whose source was compiled by the node into its deployed bytecode. It is local
+// to the node, and not part of the consensus.
+type VerifiedContract struct {
+	Address         common.Address
+	Name            string
+	CompilerVersion string
+	Optimize        bool
+	Runs            uint64
+	EVMVersion      string
+	Source          string
+	ABI             string
+	CodeHash        common.Hash
+	FullMatch       bool   // whether the metadata hash embedded in the bytecode matched as well
+	VerifiedAt      uint64 // Unix timestamp in seconds
+}
+
+// AddVerifiedContract adds the given contract to the registry, replacing its earlier verification, if any.
+func (ch *Chain) AddVerifiedContract(contract *VerifiedContract) error {
+	return ch.store.Put(verifiedContractKey(contract.Address), contract)
+}
+
+// FindVerifiedContract looks up the verified contract at the given address.
+func (ch *Chain) FindVerifiedContract(address common.Address) (*VerifiedContract, bool) {
+	contract := &VerifiedContract{}
+	err := ch.store.Get(verifiedContractKey(address), contract)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Error(err)
+		}
+		return nil, false
+	}
+	return contract, true
+}
@@ -0,0 +1,227 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sort"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store"
+)
+
+// ContractStatsBucketSize is the number of heights covered by a bucket of the contract stats.
+const ContractStatsBucketSize = 1000
+
+// MaxContractStatsWindow is the maximum number of heights that can be aggregated by a single contract stats query.
+const MaxContractStatsWindow = 100 * ContractStatsBucketSize
+
+// maxTopCallers is the number of the most frequent callers returned with the contract stats.
+const maxTopCallers = 10
+
+// contractStatsKey constructs the composite DB key for the stats of the given contract in the given bucket.
+func contractStatsKey(contract common.Address, bucket uint64) common.Bytes {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, bucket)
+	key := append(common.Bytes("cst/"), contract[:]...)
+	return append(key, buf[:n]...)
+}
+
+// ContractCaller records the calls of a contract made by an address.
+type ContractCaller struct {
+	Address common.Address
+	Calls   uint64
+}
+
+// contractBlockStats records the calls of a contract made by the txs of a finalized block.
+type contractBlockStats struct {
+	Height   uint64
+	Calls    uint64
+	Failures uint64
+	GasUsed  uint64
+	Callers  []ContractCaller
+}
+
+// contractStatsBucket contains the stats of a contract for the blocks calling it within a bucket of heights.
+type contractStatsBucket struct {
+	Blocks []contractBlockStats
+}
+
+// ContractStats summarizes the calls of a contract over a height window. Only the txs calling the contract
+// directly are counted, not the calls from other contracts.
+type ContractStats struct {
+	Contract      common.Address
+	StartHeight   uint64
+	EndHeight     uint64
+	Calls         uint64
+	Failures      uint64
+	GasUsed       uint64
+	UniqueCallers uint64
+	TopCallers    []ContractCaller // the most frequent callers, in the descending order of calls
+}
+
+// blockContractStats collects the stats of the contracts called by the txs of the given finalized block.
+func blockContractStats(block *core.ExtendedBlock, receipts BlockTxReceipts) map[common.Address]*contractBlockStats {
+	stats := make(map[common.Address]*contractBlockStats)
+	for i, receipt := range receipts {
+		if receipt == nil {
+			continue
+		}
+		tx, err := types.TxFromBytes(block.Txs[i])
+		if err != nil {
+			continue
+		}
+		var from, to common.Address
+		switch tx := tx.(type) {
+		case *types.SmartContractTx:
+			from, to = tx.From.Address, tx.To.Address
+		case *types.SmartContractTxV2:
+			from, to = tx.From.Address, tx.To.Address
+		default:
+			continue
+		}
+		if to.IsEmpty() { // contract deployment
+			continue
+		}
+		entry, ok := stats[to]
+		if !ok {
+			entry = &contractBlockStats{Height: block.Height}
+			stats[to] = entry
+		}
+		entry.Calls++
+		entry.GasUsed += receipt.GasUsed
+		if receipt.EvmErr != "" {
+			entry.Failures++
+		}
+		entry.Callers = addContractCaller(entry.Callers, from)
+	}
+	return stats
+}
+
+func addContractCaller(callers []ContractCaller, address common.Address) []ContractCaller {
+	for i := range callers {
+		if callers[i].Address == address {
+			callers[i].Calls++
+			return callers
+		}
+	}
+	return append(callers, ContractCaller{Address: address, Calls: 1})
+}
+
+// AddContractStats adds the calls of the contracts made by the txs of the given finalized block to the contract
+// stats index, given the receipts of its txs. A block indexed already, e.g. when the finalization is replayed,
+// is skipped.
+func (ch *Chain) AddContractStats(block *core.ExtendedBlock, receipts BlockTxReceipts) error {
+	for contract, stats := range blockContractStats(block, receipts) {
+		key := contractStatsKey(contract, block.Height/ContractStatsBucketSize)
+		bucket := &contractStatsBucket{}
+		if err := ch.store.Get(key, bucket); err != nil && err != store.ErrKeyNotFound {
+			return err
+		}
+		indexed := false
+		for _, entry := range bucket.Blocks {
+			if entry.Height == block.Height {
+				indexed = true
+				break
+			}
+		}
+		if indexed {
+			continue
+		}
+		bucket.Blocks = append(bucket.Blocks, *stats)
+		if err := ch.store.Put(key, bucket); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeContractStats removes the calls made by the txs of the given finalized block from the contract stats index.
+func (ch *Chain) removeContractStats(block *core.ExtendedBlock, receipts BlockTxReceipts) error {
+	for contract := range blockContractStats(block, receipts) {
+		key := contractStatsKey(contract, block.Height/ContractStatsBucketSize)
+		bucket := &contractStatsBucket{}
+		err := ch.store.Get(key, bucket)
+		if err == store.ErrKeyNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		blocks := []contractBlockStats{}
+		for _, entry := range bucket.Blocks {
+			if entry.Height != block.Height {
+				blocks = append(blocks, entry)
+			}
+		}
+		if len(blocks) == 0 {
+			err = ch.store.Delete(key)
+		} else {
+			bucket.Blocks = blocks
+			err = ch.store.Put(key, bucket)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetContractStats aggregates the calls of the given contract over heights [start, end].
+func (ch *Chain) GetContractStats(contract common.Address, start, end uint64) (*ContractStats, error) {
+	if start > end {
+		return nil, errors.New("start height must not be greater than end height")
+	}
+	if end-start >= MaxContractStatsWindow {
+		return nil, errors.New("height window too large")
+	}
+
+	stats := &ContractStats{
+		Contract:    contract,
+		StartHeight: start,
+		EndHeight:   end,
+		TopCallers:  []ContractCaller{},
+	}
+	calls := make(map[common.Address]uint64)
+	for b := start / ContractStatsBucketSize; b <= end/ContractStatsBucketSize; b++ {
+		bucket := &contractStatsBucket{}
+		err := ch.store.Get(contractStatsKey(contract, b), bucket)
+		if err == store.ErrKeyNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range bucket.Blocks {
+			if entry.Height < start || entry.Height > end {
+				continue
+			}
+			stats.Calls += entry.Calls
+			stats.Failures += entry.Failures
+			stats.GasUsed += entry.GasUsed
+			for _, caller := range entry.Callers {
+				calls[caller.Address] += caller.Calls
+			}
+		}
+	}
+
+	callers := []ContractCaller{}
+	for address, n := range calls {
+		callers = append(callers, ContractCaller{Address: address, Calls: n})
+	}
+	stats.UniqueCallers = uint64(len(callers))
+	sort.Slice(callers, func(i, j int) bool {
+		if callers[i].Calls != callers[j].Calls {
+			return callers[i].Calls > callers[j].Calls
+		}
+		return bytes.Compare(callers[i].Address[:], callers[j].Address[:]) < 0
+	})
+	if len(callers) > maxTopCallers {
+		callers = callers[:maxTopCallers]
+	}
+	stats.TopCallers = append(stats.TopCallers, callers...)
+
+	return stats, nil
+}
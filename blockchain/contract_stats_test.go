@@ -0,0 +1,80 @@
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestContractStats(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+
+	contract := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	alice := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	bob := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	call := func(from common.Address, seq uint64, gasUsed uint64, evmErr string) common.Bytes {
+		tx := &types.SmartContractTx{
+			From:     types.TxInput{Address: from, Sequence: seq},
+			To:       types.TxOutput{Address: contract},
+			GasLimit: 100000,
+			GasPrice: big.NewInt(1),
+		}
+		raw, err := types.TxToBytes(tx)
+		require.Nil(err)
+		txHash := crypto.Keccak256Hash(raw)
+		require.Nil(chain.store.Put(txReceiptKey(txHash), TxReceiptEntry{TxHash: txHash, GasUsed: gasUsed, EvmErr: evmErr}))
+		return raw
+	}
+	deploy, err := types.TxToBytes(&types.SmartContractTx{From: types.TxInput{Address: alice}, GasPrice: big.NewInt(1)})
+	require.Nil(err)
+
+	block1 := core.CreateTestBlock("b1", "")
+	block1.Height = 999
+	block1.Txs = []common.Bytes{call(alice, 1, 100, ""), call(alice, 2, 200, "execution reverted"), deploy}
+	block2 := core.CreateTestBlock("b2", "")
+	block2.Height = 1000
+	block2.Txs = []common.Bytes{call(bob, 1, 300, "")}
+	eb1, err := chain.AddBlock(block1)
+	require.Nil(err)
+	eb2, err := chain.AddBlock(block2)
+	require.Nil(err)
+
+	require.Nil(chain.AddContractStats(eb1, chain.FindBlockTxReceipts(eb1)))
+	require.Nil(chain.AddContractStats(eb2, chain.FindBlockTxReceipts(eb2)))
+	require.Nil(chain.AddContractStats(eb2, chain.FindBlockTxReceipts(eb2))) // replayed
+
+	stats, err := chain.GetContractStats(contract, 1, 1000)
+	require.Nil(err)
+	assert.Equal(uint64(3), stats.Calls)
+	assert.Equal(uint64(1), stats.Failures)
+	assert.Equal(uint64(600), stats.GasUsed)
+	assert.Equal(uint64(2), stats.UniqueCallers)
+	assert.Equal([]ContractCaller{{alice, 2}, {bob, 1}}, stats.TopCallers)
+
+	stats, err = chain.GetContractStats(contract, 1000, 2000)
+	require.Nil(err)
+	assert.Equal(uint64(1), stats.Calls)
+	assert.Equal(uint64(300), stats.GasUsed)
+
+	require.Nil(chain.RollbackIndices(eb2))
+	stats, err = chain.GetContractStats(contract, 1, 2000)
+	require.Nil(err)
+	assert.Equal(uint64(2), stats.Calls)
+	assert.Equal(uint64(1), stats.UniqueCallers)
+
+	_, err = chain.GetContractStats(contract, 0, MaxContractStatsWindow)
+	assert.NotNil(err)
+	_, err = chain.GetContractStats(contract, 2, 1)
+	assert.NotNil(err)
+}
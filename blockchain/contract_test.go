@@ -0,0 +1,44 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestVerifiedContracts(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chain := CreateTestChain()
+	address := common.HexToAddress("0x2e833968e5bb786ae419c4d13189fb081cc43bab")
+
+	_, found := chain.FindVerifiedContract(address)
+	assert.False(found)
+
+	contract := &VerifiedContract{
+		Address:         address,
+		Name:            "Token",
+		CompilerVersion: "0.8.17+commit.8df45f5f",
+		Optimize:        true,
+		Runs:            200,
+		Source:          "contract Token {}",
+		ABI:             "[]",
+		CodeHash:        common.BytesToHash([]byte{1}),
+		FullMatch:       true,
+		VerifiedAt:      1000,
+	}
+	require.Nil(chain.AddVerifiedContract(contract))
+	stored, found := chain.FindVerifiedContract(address)
+	require.True(found)
+	assert.Equal(contract, stored)
+
+	// A new verification replaces the earlier one
+	contract.Name = "TokenV2"
+	require.Nil(chain.AddVerifiedContract(contract))
+	stored, found = chain.FindVerifiedContract(address)
+	require.True(found)
+	assert.Equal("TokenV2", stored.Name)
+}
@@ -0,0 +1,284 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store"
+)
+
+// NFTTransferTopic is the first topic of the Transfer events of the TNT-721 tokens. The TNT-20 tokens emit the
+// same event, but the TNT-721 tokens index the token ID as well, so their events have four topics and no data.
+var NFTTransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// nftOwnerKey constructs the DB key for the TNT-721 tokens owned by the given address.
+func nftOwnerKey(owner common.Address) common.Bytes {
+	return append(common.Bytes("nfo/"), owner[:]...)
+}
+
+// nftTransferPrefix constructs the DB key prefix for the transfers of the given TNT-721 token.
+func nftTransferPrefix(contract common.Address, tokenID *big.Int) common.Bytes {
+	key := append(common.Bytes("nft/"), contract[:]...)
+	return append(key, common.BigToHash(tokenID).Bytes()...)
+}
+
+// nftTransferKey constructs the DB key for a transfer of the given TNT-721 token. Each transfer has its own key,
+// so indexing a transfer does not rewrite the history of the token. The position of the transfer is big endian,
+// so the keys of the token are iterated in the chain order.
+func nftTransferKey(contract common.Address, tokenID *big.Int, height uint64, txIndex int, logIndex uint64) common.Bytes {
+	position := make([]byte, 24)
+	binary.BigEndian.PutUint64(position[0:], height)
+	binary.BigEndian.PutUint64(position[8:], uint64(txIndex))
+	binary.BigEndian.PutUint64(position[16:], logIndex)
+	return append(nftTransferPrefix(contract, tokenID), position...)
+}
+
+// nftMetadataKey constructs the DB key for the metadata pointer of the given TNT-721 token.
+func nftMetadataKey(contract common.Address, tokenID *big.Int) common.Bytes {
+	key := append(common.Bytes("nfm/"), contract[:]...)
+	return append(key, common.BigToHash(tokenID).Bytes()...)
+}
+
+// NFT identifies a TNT-721 token
+type NFT struct {
+	Contract common.Address
+	TokenID  *big.Int
+}
+
+func (n NFT) equals(contract common.Address, tokenID *big.Int) bool {
+	return n.Contract == contract && n.TokenID.Cmp(tokenID) == 0
+}
+
+// nftOwnership lists the TNT-721 tokens owned by an address, in the order they were received
+type nftOwnership struct {
+	Tokens []NFT
+}
+
+// NFTTransfer records a Transfer event of a TNT-721 token. The mints are from, and the burns are to, the
+// zero address.
+type NFTTransfer struct {
+	From     common.Address
+	To       common.Address
+	TxHash   common.Hash
+	Height   uint64
+	LogIndex uint64 // index of the event among the logs of the tx
+}
+
+// NFTHistory records the transfers of a TNT-721 token, the last one determines its current owner
+type NFTHistory struct {
+	Transfers []NFTTransfer
+}
+
+// Owner returns the current owner of the token, or the zero address if it is burnt
+func (h *NFTHistory) Owner() common.Address {
+	if len(h.Transfers) == 0 {
+		return common.Address{}
+	}
+	return h.Transfers[len(h.Transfers)-1].To
+}
+
+// NFTMetadata is the metadata pointer of a TNT-721 token, i.e. its tokenURI, as read at the given height.
+type NFTMetadata struct {
+	URI    string
+	Height uint64
+}
+
+// isNFTTransfer returns whether the given log is a Transfer event of a TNT-721 token, and the token ID if so.
+func isNFTTransfer(log *types.Log) (*big.Int, bool) {
+	if len(log.Topics) != 4 || log.Topics[0] != NFTTransferTopic || len(log.Data) != 0 {
+		return nil, false
+	}
+	return log.Topics[3].Big(), true
+}
+
+// blockNFTTransfers calls the given function for the TNT-721 transfers of the given finalized block, in order, along
+// with the DB keys of the transfers.
+func blockNFTTransfers(block *core.ExtendedBlock, receipts BlockTxReceipts, f func(contract common.Address, tokenID *big.Int, key common.Bytes, transfer NFTTransfer) error) error {
+	for txIndex, receipt := range receipts {
+		if receipt == nil {
+			continue
+		}
+		txHash := crypto.Keccak256Hash(block.Txs[txIndex])
+		for i, log := range receipt.Logs {
+			tokenID, ok := isNFTTransfer(log)
+			if !ok {
+				continue
+			}
+			transfer := NFTTransfer{
+				From:     common.BytesToAddress(log.Topics[1].Bytes()),
+				To:       common.BytesToAddress(log.Topics[2].Bytes()),
+				TxHash:   txHash,
+				Height:   block.Height,
+				LogIndex: uint64(i),
+			}
+			key := nftTransferKey(log.Address, tokenID, block.Height, txIndex, transfer.LogIndex)
+			if err := f(log.Address, tokenID, key, transfer); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AddNFTTransfers adds the TNT-721 transfers of the given finalized block to the NFT index, given the receipts of
+// its txs. A transfer indexed already, e.g. when the finalization is replayed, is skipped.
+func (ch *Chain) AddNFTTransfers(block *core.ExtendedBlock, receipts BlockTxReceipts) error {
+	return blockNFTTransfers(block, receipts, func(contract common.Address, tokenID *big.Int, key common.Bytes, transfer NFTTransfer) error {
+		err := ch.store.Get(key, &NFTTransfer{})
+		if err == nil {
+			return nil
+		} else if err != store.ErrKeyNotFound {
+			return err
+		}
+		if err := ch.store.Put(key, transfer); err != nil {
+			return err
+		}
+		return ch.moveNFT(contract, tokenID, transfer.From, transfer.To)
+	})
+}
+
+// removeNFTTransfers removes the TNT-721 transfers of the given finalized block from the NFT index.
+func (ch *Chain) removeNFTTransfers(block *core.ExtendedBlock, receipts BlockTxReceipts) error {
+	type indexedTransfer struct {
+		contract common.Address
+		tokenID  *big.Int
+		key      common.Bytes
+		transfer NFTTransfer
+	}
+	transfers := []indexedTransfer{}
+	err := blockNFTTransfers(block, receipts, func(contract common.Address, tokenID *big.Int, key common.Bytes, transfer NFTTransfer) error {
+		transfers = append(transfers, indexedTransfer{contract, tokenID, key, transfer})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The transfers are undone in the reverse order, so a token moved several times in the block ends up with
+	// its owner before the block
+	for i := len(transfers) - 1; i >= 0; i-- {
+		contract, tokenID, key, transfer := transfers[i].contract, transfers[i].tokenID, transfers[i].key, transfers[i].transfer
+		err := ch.store.Get(key, &NFTTransfer{})
+		if err == store.ErrKeyNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := ch.store.Delete(key); err != nil {
+			return err
+		}
+		if err := ch.moveNFT(contract, tokenID, transfer.To, transfer.From); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveNFT moves the given token between the lists of the tokens owned by the given addresses. The metadata
+// pointer of the token is dropped, so that it is read again, e.g. when a burnt token ID is minted again.
+func (ch *Chain) moveNFT(contract common.Address, tokenID *big.Int, from, to common.Address) error {
+	if err := ch.store.Delete(nftMetadataKey(contract, tokenID)); err != nil && err != store.ErrKeyNotFound {
+		return err
+	}
+	if from == to {
+		return nil
+	}
+	if !from.IsEmpty() {
+		ownership := ch.findNFTOwnership(from)
+		for i, token := range ownership.Tokens {
+			if token.equals(contract, tokenID) {
+				ownership.Tokens = append(ownership.Tokens[:i], ownership.Tokens[i+1:]...)
+				break
+			}
+		}
+		var err error
+		if len(ownership.Tokens) == 0 {
+			err = ch.store.Delete(nftOwnerKey(from))
+		} else {
+			err = ch.store.Put(nftOwnerKey(from), ownership)
+		}
+		if err != nil && err != store.ErrKeyNotFound {
+			return err
+		}
+	}
+	if !to.IsEmpty() {
+		ownership := ch.findNFTOwnership(to)
+		for _, token := range ownership.Tokens {
+			if token.equals(contract, tokenID) {
+				return nil
+			}
+		}
+		ownership.Tokens = append(ownership.Tokens, NFT{Contract: contract, TokenID: tokenID})
+		if err := ch.store.Put(nftOwnerKey(to), ownership); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ch *Chain) findNFTOwnership(owner common.Address) *nftOwnership {
+	ownership := &nftOwnership{}
+	err := ch.store.Get(nftOwnerKey(owner), ownership)
+	if err != nil && err != store.ErrKeyNotFound {
+		logger.Error(err)
+	}
+	return ownership
+}
+
+// FindNFTsByOwner returns the TNT-721 tokens owned by the given address, in the order they were received.
+func (ch *Chain) FindNFTsByOwner(owner common.Address) []NFT {
+	return ch.findNFTOwnership(owner).Tokens
+}
+
+// FindNFTHistory looks up the transfers of the given TNT-721 token.
+func (ch *Chain) FindNFTHistory(contract common.Address, tokenID *big.Int) (*NFTHistory, bool) {
+	iterator, ok := ch.store.(store.PrefixIterator)
+	if !ok {
+		logger.Error(store.ErrIterationNotSupported)
+		return nil, false
+	}
+	history := &NFTHistory{}
+	var decodeErr error
+	err := iterator.IterateWithPrefix(nftTransferPrefix(contract, tokenID), func(key common.Bytes, decode func(value interface{}) error) bool {
+		transfer := NFTTransfer{}
+		if decodeErr = decode(&transfer); decodeErr != nil {
+			return false
+		}
+		history.Transfers = append(history.Transfers, transfer)
+		return true
+	})
+	if err == nil {
+		err = decodeErr
+	}
+	if err != nil {
+		logger.Error(err)
+		return nil, false
+	}
+	if len(history.Transfers) == 0 {
+		return nil, false
+	}
+	return history, true
+}
+
+// AddNFTMetadata records the metadata pointer of the given TNT-721 token. It is kept until the token is
+// transferred.
+func (ch *Chain) AddNFTMetadata(contract common.Address, tokenID *big.Int, metadata *NFTMetadata) error {
+	return ch.store.Put(nftMetadataKey(contract, tokenID), metadata)
+}
+
+// FindNFTMetadata looks up the metadata pointer of the given TNT-721 token.
+func (ch *Chain) FindNFTMetadata(contract common.Address, tokenID *big.Int) (*NFTMetadata, bool) {
+	metadata := &NFTMetadata{}
+	err := ch.store.Get(nftMetadataKey(contract, tokenID), metadata)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Error(err)
+		}
+		return nil, false
+	}
+	return metadata, true
+}
@@ -0,0 +1,98 @@
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func nftTransferLog(contract, from, to common.Address, tokenID int64) *types.Log {
+	return &types.Log{
+		Address: contract,
+		Topics: []common.Hash{
+			NFTTransferTopic,
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+			common.BigToHash(big.NewInt(tokenID)),
+		},
+	}
+}
+
+func TestNFTIndex(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+
+	nft := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	alice := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	bob := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	zero := common.Address{}
+
+	receipts := map[string][]*types.Log{
+		"tx1": {nftTransferLog(nft, zero, alice, 1), nftTransferLog(nft, zero, alice, 2)},
+		// A TNT-20 transfer has the amount in the data
+		"tx2": {{Address: nft, Topics: []common.Hash{NFTTransferTopic, {}, {}}, Data: common.BigToHash(big.NewInt(5)).Bytes()}},
+		"tx3": {nftTransferLog(nft, alice, bob, 1), nftTransferLog(nft, bob, zero, 1)},
+	}
+	for tx, logs := range receipts {
+		txHash := crypto.Keccak256Hash([]byte(tx))
+		require.Nil(chain.store.Put(txReceiptKey(txHash), TxReceiptEntry{TxHash: txHash, Logs: logs}))
+	}
+
+	block1 := core.CreateTestBlock("b1", "")
+	block1.Height = 1
+	block1.Txs = []common.Bytes{common.Bytes("tx1"), common.Bytes("tx2")}
+	block2 := core.CreateTestBlock("b2", "")
+	block2.Height = 2
+	block2.Txs = []common.Bytes{common.Bytes("tx3")}
+	eb1, err := chain.AddBlock(block1)
+	require.Nil(err)
+	eb2, err := chain.AddBlock(block2)
+	require.Nil(err)
+
+	require.Nil(chain.AddNFTTransfers(eb1, chain.FindBlockTxReceipts(eb1)))
+	assert.Equal([]NFT{{nft, big.NewInt(1)}, {nft, big.NewInt(2)}}, chain.FindNFTsByOwner(alice))
+	require.Nil(chain.AddNFTMetadata(nft, big.NewInt(1), &NFTMetadata{URI: "ipfs://1", Height: 1}))
+	metadata, found := chain.FindNFTMetadata(nft, big.NewInt(1))
+	require.True(found)
+	assert.Equal("ipfs://1", metadata.URI)
+
+	// The replayed finalization does not index the transfers again
+	require.Nil(chain.AddNFTTransfers(eb2, chain.FindBlockTxReceipts(eb2)))
+	require.Nil(chain.AddNFTTransfers(eb2, chain.FindBlockTxReceipts(eb2)))
+	assert.Equal([]NFT{{nft, big.NewInt(2)}}, chain.FindNFTsByOwner(alice))
+	assert.Equal(0, len(chain.FindNFTsByOwner(bob)))
+	history, found := chain.FindNFTHistory(nft, big.NewInt(1))
+	require.True(found)
+	require.Equal(3, len(history.Transfers))
+	assert.Equal(bob, history.Transfers[1].To)
+	assert.Equal(uint64(1), history.Transfers[2].LogIndex)
+	assert.Equal(zero, history.Owner())
+	// Each transfer is stored under its own key
+	transfer := NFTTransfer{}
+	require.Nil(chain.store.Get(nftTransferKey(nft, big.NewInt(1), 2, 0, 1), &transfer))
+	assert.Equal(zero, transfer.To)
+	_, found = chain.FindNFTMetadata(nft, big.NewInt(1))
+	assert.False(found)
+
+	// Rolling back the block returns the token to its owner before the block
+	require.Nil(chain.RollbackIndices(eb2))
+	assert.Equal([]NFT{{nft, big.NewInt(2)}, {nft, big.NewInt(1)}}, chain.FindNFTsByOwner(alice))
+	history, found = chain.FindNFTHistory(nft, big.NewInt(1))
+	require.True(found)
+	assert.Equal(1, len(history.Transfers))
+	assert.Equal(alice, history.Owner())
+
+	require.Nil(chain.RollbackIndices(eb1))
+	assert.Equal(0, len(chain.FindNFTsByOwner(alice)))
+	_, found = chain.FindNFTHistory(nft, big.NewInt(2))
+	assert.False(found)
+}
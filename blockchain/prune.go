@@ -0,0 +1,128 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/store"
+)
+
+// IndexType identifies an index that can be pruned independently from the state.
+type IndexType string
+
+const (
+	// IndexTypeTxIndex is the transaction hash -> block position index
+	IndexTypeTxIndex IndexType = "txi"
+	// IndexTypeTxReceipt is the transaction receipt index, including the logs
+	IndexTypeTxReceipt IndexType = "txr"
+	// IndexTypeTxLog is the logs of the transaction receipts. Pruning the logs retains the
+	// rest of the receipts, e.g. the gas used and the EVM error
+	IndexTypeTxLog IndexType = "log"
+)
+
+// indexPruningProgressKey constructs the DB key for the pruning progress of the given index.
+func indexPruningProgressKey(indexType IndexType) common.Bytes {
+	return append(common.Bytes("ipp/"), []byte(indexType)...)
+}
+
+// IndexPruningProgress returns the height up to which (inclusive) the given index has been
+// pruned. Returns false if the index has never been pruned.
+func (ch *Chain) IndexPruningProgress(indexType IndexType) (uint64, bool) {
+	var processedHeight uint64
+	err := ch.store.Get(indexPruningProgressKey(indexType), &processedHeight)
+	if err != nil {
+		return 0, false
+	}
+	return processedHeight, true
+}
+
+// PruneIndex prunes the given index up to targetEndHeight (inclusive). To avoid stalling
+// the caller, at most maxHeightsToPrune heights are pruned per call, so the index catches
+// up gradually. Returns the height up to which the index has been pruned.
+func (ch *Chain) PruneIndex(indexType IndexType, targetEndHeight uint64, maxHeightsToPrune uint64) (uint64, error) {
+	processedHeight, ok := ch.IndexPruningProgress(indexType)
+	if !ok {
+		processedHeight = ch.Root().Height
+	}
+
+	startHeight := processedHeight + 1
+	endHeight := processedHeight + maxHeightsToPrune
+	if endHeight > targetEndHeight {
+		endHeight = targetEndHeight
+	}
+	if endHeight < startHeight {
+		return processedHeight, nil
+	}
+
+	// Save the progress before pruning so that an interrupted pruning is not repeated
+	err := ch.store.Put(indexPruningProgressKey(indexType), endHeight)
+	if err != nil {
+		return processedHeight, err
+	}
+
+	for height := startHeight; height <= endHeight; height++ {
+		err := ch.pruneIndexAtHeight(indexType, height)
+		if err != nil {
+			return endHeight, fmt.Errorf("Failed to prune %v index at height %v: %v", indexType, height, err)
+		}
+	}
+
+	logger.Infof("Pruned %v index from height %v to %v", indexType, startHeight, endHeight)
+
+	return endHeight, nil
+}
+
+func (ch *Chain) pruneIndexAtHeight(indexType IndexType, height uint64) error {
+	for _, block := range ch.FindBlocksByHeight(height) {
+		for _, rawTx := range block.Txs {
+			txHash := crypto.Keccak256Hash(rawTx)
+
+			switch indexType {
+			case IndexTypeTxIndex:
+				key := txIndexKey(txHash)
+				txIndexEntry := &TxIndexEntry{}
+				err := ch.store.Get(key, txIndexEntry)
+				if err == store.ErrKeyNotFound {
+					continue
+				}
+				// The same tx might have been re-included in a later block
+				if err == nil && txIndexEntry.BlockHeight > height {
+					continue
+				}
+				if err := ch.store.Delete(key); err != nil {
+					return err
+				}
+				// The timings are looked up by the tx hash as well
+				err = ch.store.Delete(txTimingKey(txHash))
+				if err != nil && err != store.ErrKeyNotFound {
+					return err
+				}
+			case IndexTypeTxReceipt:
+				err := ch.store.Delete(txReceiptKey(txHash))
+				if err != nil && err != store.ErrKeyNotFound {
+					return err
+				}
+			case IndexTypeTxLog:
+				receipt, found := ch.FindTxReceiptByHash(txHash)
+				if !found || len(receipt.Logs) == 0 {
+					continue
+				}
+				receipt.Logs = nil
+				if err := ch.store.Put(txReceiptKey(txHash), receipt); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("Unknown index type: %v", indexType)
+			}
+		}
+	}
+
+	// The logs bloom is of no use once the logs are gone
+	if indexType == IndexTypeTxReceipt || indexType == IndexTypeTxLog {
+		if err := ch.removeLogsBloom(height); err != nil {
+			return err
+		}
+	}
+	return nil
+}
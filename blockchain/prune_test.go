@@ -0,0 +1,95 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestPruneIndex(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tx1 := common.Bytes("tx1")
+	tx2 := common.Bytes("tx2")
+	tx3 := common.Bytes("tx3")
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+
+	block1 := core.CreateTestBlock("b1", "")
+	block1.Height = 1
+	block1.Txs = []common.Bytes{tx1, tx2}
+
+	block2 := core.CreateTestBlock("b2", "")
+	block2.Height = 2
+	block2.Txs = []common.Bytes{tx3}
+
+	_, err := chain.AddBlock(block1)
+	require.Nil(err)
+	_, err = chain.AddBlock(block2)
+	require.Nil(err)
+
+	for _, tx := range []common.Bytes{tx1, tx2, tx3} {
+		txHash := crypto.Keccak256Hash(tx)
+		err = chain.store.Put(txReceiptKey(txHash), TxReceiptEntry{
+			TxHash:  txHash,
+			Logs:    []*types.Log{&types.Log{Data: tx}},
+			GasUsed: 100,
+		})
+		require.Nil(err)
+	}
+
+	_, pruned := chain.IndexPruningProgress(IndexTypeTxLog)
+	assert.False(pruned)
+
+	// Prune the logs of block1 only
+	prunedHeight, err := chain.PruneIndex(IndexTypeTxLog, 1, 10)
+	require.Nil(err)
+	assert.Equal(uint64(1), prunedHeight)
+
+	receipt, found := chain.FindTxReceiptByHash(crypto.Keccak256Hash(tx1))
+	assert.True(found)
+	assert.Equal(0, len(receipt.Logs))
+	assert.Equal(uint64(100), receipt.GasUsed)
+
+	receipt, found = chain.FindTxReceiptByHash(crypto.Keccak256Hash(tx3))
+	assert.True(found)
+	assert.Equal(1, len(receipt.Logs))
+
+	// Prune the receipts, at most one height at a time
+	prunedHeight, err = chain.PruneIndex(IndexTypeTxReceipt, 2, 1)
+	require.Nil(err)
+	assert.Equal(uint64(1), prunedHeight)
+	_, found = chain.FindTxReceiptByHash(crypto.Keccak256Hash(tx2))
+	assert.False(found)
+	_, found = chain.FindTxReceiptByHash(crypto.Keccak256Hash(tx3))
+	assert.True(found)
+
+	prunedHeight, err = chain.PruneIndex(IndexTypeTxReceipt, 2, 1)
+	require.Nil(err)
+	assert.Equal(uint64(2), prunedHeight)
+	_, found = chain.FindTxReceiptByHash(crypto.Keccak256Hash(tx3))
+	assert.False(found)
+
+	// Tx indices are not affected by receipt pruning
+	_, _, found = chain.FindTxByHash(crypto.Keccak256Hash(tx1))
+	assert.True(found)
+
+	prunedHeight, err = chain.PruneIndex(IndexTypeTxIndex, 1, 10)
+	require.Nil(err)
+	assert.Equal(uint64(1), prunedHeight)
+	_, _, found = chain.FindTxByHash(crypto.Keccak256Hash(tx1))
+	assert.False(found)
+	_, _, found = chain.FindTxByHash(crypto.Keccak256Hash(tx3))
+	assert.True(found)
+
+	progress, pruned := chain.IndexPruningProgress(IndexTypeTxIndex)
+	assert.True(pruned)
+	assert.Equal(uint64(1), progress)
+}
@@ -0,0 +1,160 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store"
+)
+
+// SlashTopic is the topic of the log recorded in the receipt of a slash tx.
+var SlashTopic = crypto.Keccak256Hash([]byte("Slash(address,address,uint256,uint256,uint256)"))
+
+// slashHistoryKey constructs the DB key for the slash history of the given address.
+func slashHistoryKey(address common.Address) common.Bytes {
+	return append(common.Bytes("slh/"), address[:]...)
+}
+
+// SlashRecord records a penalty applied to an address.
+type SlashRecord struct {
+	TxHash        common.Hash
+	Height        uint64
+	Reason        string
+	SlashedAmount types.Coins
+	Beneficiary   common.Address // the account that received the slashed amount
+}
+
+// SlashHistory contains the penalties applied to an address, in the order of execution.
+type SlashHistory struct {
+	Records []SlashRecord
+}
+
+// NewSlashLog creates the log recording in the receipt of a slash tx that the reserved fund of the given
+// sequence of the slashed address was slashed, and the slashed amount was transferred to the beneficiary.
+func NewSlashLog(slashed, beneficiary common.Address, reserveSequence uint64, amount types.Coins) *types.Log {
+	amount = amount.NoNil()
+	data := common.LeftPadBytes(new(big.Int).SetUint64(reserveSequence).Bytes(), 32)
+	data = append(data, common.LeftPadBytes(amount.ThetaWei.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(amount.TFuelWei.Bytes(), 32)...)
+	return &types.Log{
+		Address: slashed,
+		Topics:  []common.Hash{SlashTopic, common.BytesToHash(slashed.Bytes()), common.BytesToHash(beneficiary.Bytes())},
+		Data:    data,
+	}
+}
+
+// parseSlashLog returns the slashed address and the penalty recorded by the given log, if it is a slash log.
+func parseSlashLog(log *types.Log) (common.Address, SlashRecord, bool) {
+	if len(log.Topics) != 3 || log.Topics[0] != SlashTopic || len(log.Data) != 96 {
+		return common.Address{}, SlashRecord{}, false
+	}
+	reserveSequence := new(big.Int).SetBytes(log.Data[:32])
+	record := SlashRecord{
+		Reason: fmt.Sprintf("Overspent reserved fund %v", reserveSequence),
+		SlashedAmount: types.Coins{
+			ThetaWei: new(big.Int).SetBytes(log.Data[32:64]),
+			TFuelWei: new(big.Int).SetBytes(log.Data[64:96]),
+		},
+		Beneficiary: common.BytesToAddress(log.Topics[2].Bytes()),
+	}
+	return common.BytesToAddress(log.Topics[1].Bytes()), record, true
+}
+
+// blockSlashRecords calls the given function for the penalties recorded in the receipts of the txs of the given
+// finalized block, in order.
+func blockSlashRecords(block *core.ExtendedBlock, receipts BlockTxReceipts, f func(address common.Address, record SlashRecord) error) error {
+	for _, receipt := range receipts {
+		if receipt == nil {
+			continue
+		}
+		for _, log := range receipt.Logs {
+			address, record, ok := parseSlashLog(log)
+			if !ok {
+				continue
+			}
+			record.TxHash = receipt.TxHash
+			record.Height = block.Height
+			if err := f(address, record); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AddSlashRecords adds the penalties applied by the slash txs of the given finalized block to the slash
+// histories, given the receipts of its txs. A record of the same tx, e.g. when the finalization is replayed,
+// is replaced.
+func (ch *Chain) AddSlashRecords(block *core.ExtendedBlock, receipts BlockTxReceipts) error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	return blockSlashRecords(block, receipts, func(address common.Address, record SlashRecord) error {
+		history, _ := ch.findSlashHistory(address)
+		replaced := false
+		for i := range history.Records {
+			if history.Records[i].TxHash == record.TxHash {
+				history.Records[i] = record
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			history.Records = append(history.Records, record)
+		}
+		return ch.store.Put(slashHistoryKey(address), history)
+	})
+}
+
+// removeSlashRecords removes the penalties applied by the slash txs of the given finalized block from the
+// slash histories.
+func (ch *Chain) removeSlashRecords(block *core.ExtendedBlock, receipts BlockTxReceipts) error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	return blockSlashRecords(block, receipts, func(address common.Address, record SlashRecord) error {
+		history, found := ch.findSlashHistory(address)
+		if !found {
+			return nil
+		}
+		records := []SlashRecord{}
+		for _, r := range history.Records {
+			if r.TxHash != record.TxHash {
+				records = append(records, r)
+			}
+		}
+		if len(records) == 0 {
+			err := ch.store.Delete(slashHistoryKey(address))
+			if err != nil && err != store.ErrKeyNotFound {
+				return err
+			}
+			return nil
+		}
+		history.Records = records
+		return ch.store.Put(slashHistoryKey(address), history)
+	})
+}
+
+// FindSlashHistory looks up the slash history of the given address.
+func (ch *Chain) FindSlashHistory(address common.Address) (*SlashHistory, bool) {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.findSlashHistory(address)
+}
+
+func (ch *Chain) findSlashHistory(address common.Address) (*SlashHistory, bool) {
+	history := &SlashHistory{}
+	err := ch.store.Get(slashHistoryKey(address), history)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Error(err)
+		}
+		return &SlashHistory{}, false
+	}
+	return history, true
+}
@@ -0,0 +1,74 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestSlashHistory(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+	address := common.HexToAddress("0x2E833968E5bB786Ae419c4d13189fB081Cc43bab")
+	proposer := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	_, found := chain.FindSlashHistory(address)
+	assert.False(found)
+
+	receipts := map[string][]*types.Log{
+		"tx1": {NewSlashLog(address, proposer, 1, types.NewCoins(0, 100))},
+		"tx2": {},
+		"tx3": {NewSlashLog(address, proposer, 2, types.NewCoins(10, 200))},
+	}
+	for tx, logs := range receipts {
+		txHash := crypto.Keccak256Hash([]byte(tx))
+		require.Nil(chain.store.Put(txReceiptKey(txHash), TxReceiptEntry{TxHash: txHash, Logs: logs}))
+	}
+
+	block1 := core.CreateTestBlock("b1", "")
+	block1.Height = 10
+	block1.Txs = []common.Bytes{common.Bytes("tx1"), common.Bytes("tx2")}
+	block2 := core.CreateTestBlock("b2", "")
+	block2.Height = 20
+	block2.Txs = []common.Bytes{common.Bytes("tx3")}
+	eb1, err := chain.AddBlock(block1)
+	require.Nil(err)
+	eb2, err := chain.AddBlock(block2)
+	require.Nil(err)
+
+	require.Nil(chain.AddSlashRecords(eb1, chain.FindBlockTxReceipts(eb1)))
+	require.Nil(chain.AddSlashRecords(eb2, chain.FindBlockTxReceipts(eb2)))
+
+	// A replayed finalization does not duplicate the record
+	require.Nil(chain.AddSlashRecords(eb2, chain.FindBlockTxReceipts(eb2)))
+
+	history, found := chain.FindSlashHistory(address)
+	require.True(found)
+	require.Equal(2, len(history.Records))
+	assert.Equal(crypto.Keccak256Hash([]byte("tx1")), history.Records[0].TxHash)
+	assert.Equal(uint64(10), history.Records[0].Height)
+	assert.Equal("Overspent reserved fund 1", history.Records[0].Reason)
+	assert.Equal(proposer, history.Records[0].Beneficiary)
+	assert.Equal(uint64(20), history.Records[1].Height)
+	assert.Equal(int64(10), history.Records[1].SlashedAmount.ThetaWei.Int64())
+	assert.Equal(int64(200), history.Records[1].SlashedAmount.TFuelWei.Int64())
+
+	// Rolling back the finalization removes the records of the block
+	require.Nil(chain.RollbackIndices(eb2))
+	history, found = chain.FindSlashHistory(address)
+	require.True(found)
+	require.Equal(1, len(history.Records))
+	assert.Equal(uint64(10), history.Records[0].Height)
+
+	require.Nil(chain.RollbackIndices(eb1))
+	_, found = chain.FindSlashHistory(address)
+	assert.False(found)
+}
@@ -0,0 +1,151 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/store"
+)
+
+// MaxChainStatsWindow is the maximum number of blocks that can be aggregated by a single stats query.
+const MaxChainStatsWindow = 10000
+
+// blockStatsKey constructs the DB key for the given block height.
+func blockStatsKey(height uint64) common.Bytes {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, height)
+	return append(common.Bytes("bst/"), buf[:n]...)
+}
+
+// BlockStatsEntry records the production statistics of a finalized block.
+type BlockStatsEntry struct {
+	BlockHash   common.Hash
+	Height      uint64
+	Timestamp   uint64 // block timestamp set by the proposer
+	NumTxs      uint64
+	GasUsed     uint64
+	FinalizedAt uint64 // local time when the block was finalized
+}
+
+// AddBlockStats adds the statistics of the given finalized block to the stats index, given the
+// receipts of its txs.
+func (ch *Chain) AddBlockStats(block *core.ExtendedBlock, receipts BlockTxReceipts, finalizedAt time.Time) {
+	gasUsed := uint64(0)
+	for _, receipt := range receipts {
+		if receipt != nil {
+			gasUsed += receipt.GasUsed
+		}
+	}
+
+	timestamp := uint64(0)
+	if block.Timestamp != nil {
+		timestamp = block.Timestamp.Uint64()
+	}
+
+	entry := BlockStatsEntry{
+		BlockHash:   block.Hash(),
+		Height:      block.Height,
+		Timestamp:   timestamp,
+		NumTxs:      uint64(len(block.Txs)),
+		GasUsed:     gasUsed,
+		FinalizedAt: uint64(finalizedAt.Unix()),
+	}
+
+	err := ch.store.Put(blockStatsKey(block.Height), entry)
+	if err != nil {
+		logger.Panic(err)
+	}
+}
+
+// removeBlockStats removes the statistics of the finalized block at the given height from the stats index.
+func (ch *Chain) removeBlockStats(height uint64) error {
+	err := ch.store.Delete(blockStatsKey(height))
+	if err != nil && err != store.ErrKeyNotFound {
+		return err
+	}
+	return nil
+}
+
+// FindBlockStats looks up the statistics of the finalized block at the given height.
+func (ch *Chain) FindBlockStats(height uint64) (*BlockStatsEntry, bool) {
+	entry := &BlockStatsEntry{}
+	err := ch.store.Get(blockStatsKey(height), entry)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Error(err)
+		}
+		return nil, false
+	}
+	return entry, true
+}
+
+// ChainStats summarizes the block production statistics over a height window.
+type ChainStats struct {
+	StartHeight                uint64
+	EndHeight                  uint64
+	NumBlocks                  uint64 // number of blocks in the window with stats available
+	AverageBlockInterval       float64
+	AverageTxsPerBlock         float64
+	AverageGasUsedPerBlock     float64
+	EmptyBlockRatio            float64
+	AverageFinalizationLatency float64
+}
+
+// GetChainStats aggregates the block stats index over heights [start, end]. Heights without
+// stats, e.g. blocks finalized before the index was introduced, are skipped.
+func (ch *Chain) GetChainStats(start, end uint64) (*ChainStats, error) {
+	if start > end {
+		return nil, errors.New("start height must not be greater than end height")
+	}
+	if end-start >= MaxChainStatsWindow {
+		return nil, errors.New("height window too large")
+	}
+
+	stats := &ChainStats{
+		StartHeight: start,
+		EndHeight:   end,
+	}
+
+	var totalTxs, totalGas, numEmpty, totalLatency, totalInterval, numIntervals uint64
+	var prev *BlockStatsEntry
+	for height := start; height <= end; height++ {
+		entry, found := ch.FindBlockStats(height)
+		if !found {
+			prev = nil
+			continue
+		}
+
+		stats.NumBlocks++
+		totalTxs += entry.NumTxs
+		totalGas += entry.GasUsed
+		if entry.NumTxs == 0 {
+			numEmpty++
+		}
+		if entry.FinalizedAt > entry.Timestamp {
+			totalLatency += entry.FinalizedAt - entry.Timestamp
+		}
+		if prev != nil && entry.Timestamp >= prev.Timestamp {
+			totalInterval += entry.Timestamp - prev.Timestamp
+			numIntervals++
+		}
+		prev = entry
+	}
+
+	if stats.NumBlocks == 0 {
+		return stats, nil
+	}
+
+	numBlocks := float64(stats.NumBlocks)
+	stats.AverageTxsPerBlock = float64(totalTxs) / numBlocks
+	stats.AverageGasUsedPerBlock = float64(totalGas) / numBlocks
+	stats.EmptyBlockRatio = float64(numEmpty) / numBlocks
+	stats.AverageFinalizationLatency = float64(totalLatency) / numBlocks
+	if numIntervals > 0 {
+		stats.AverageBlockInterval = float64(totalInterval) / float64(numIntervals)
+	}
+
+	return stats, nil
+}
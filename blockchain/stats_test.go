@@ -0,0 +1,58 @@
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+)
+
+func TestChainStats(t *testing.T) {
+	assert := assert.New(t)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+
+	block1 := core.CreateTestBlock("b1", "")
+	block1.Height = 1
+	block1.Timestamp = big.NewInt(100)
+	block1.Txs = []common.Bytes{common.Bytes("tx1"), common.Bytes("tx2")}
+
+	block2 := core.CreateTestBlock("b2", "")
+	block2.Height = 2
+	block2.Timestamp = big.NewInt(106)
+
+	block3 := core.CreateTestBlock("b3", "")
+	block3.Height = 3
+	block3.Timestamp = big.NewInt(110)
+	block3.Txs = []common.Bytes{common.Bytes("tx3")}
+
+	chain.AddBlockStats(&core.ExtendedBlock{Block: block1}, nil, time.Unix(102, 0))
+	chain.AddBlockStats(&core.ExtendedBlock{Block: block2}, nil, time.Unix(110, 0))
+	chain.AddBlockStats(&core.ExtendedBlock{Block: block3}, nil, time.Unix(113, 0))
+
+	entry, found := chain.FindBlockStats(2)
+	assert.True(found)
+	assert.Equal(uint64(2), entry.Height)
+	assert.Equal(uint64(0), entry.NumTxs)
+
+	_, found = chain.FindBlockStats(4)
+	assert.False(found)
+
+	stats, err := chain.GetChainStats(1, 4)
+	assert.Nil(err)
+	assert.Equal(uint64(3), stats.NumBlocks)
+	assert.Equal(float64(5), stats.AverageBlockInterval)
+	assert.Equal(float64(1), stats.AverageTxsPerBlock)
+	assert.Equal(float64(1)/float64(3), stats.EmptyBlockRatio)
+	assert.Equal(float64(3), stats.AverageFinalizationLatency)
+
+	_, err = chain.GetChainStats(4, 1)
+	assert.NotNil(err)
+
+	_, err = chain.GetChainStats(1, MaxChainStatsWindow+1)
+	assert.NotNil(err)
+}
@@ -0,0 +1,234 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store"
+)
+
+// MaxSupplyDeltaWindow is the maximum number of blocks that can be aggregated by a single supply query.
+const MaxSupplyDeltaWindow = 10000
+
+// supplyDeltaKey constructs the DB key for the supply delta of the finalized block at the given height.
+func supplyDeltaKey(height uint64) common.Bytes {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, height)
+	return append(common.Bytes("sdl/"), buf[:n]...)
+}
+
+// latestSupplyDeltaKey is the DB key for the supply delta of the last indexed block, which carries
+// the cumulative totals.
+var latestSupplyDeltaKey = common.Bytes("sdt/")
+
+// SupplyDeltaEntry records the coins minted and the fees burned by a finalized block. The cumulative
+// totals are accumulated over the blocks indexed since the index was introduced.
+type SupplyDeltaEntry struct {
+	BlockHash            common.Hash
+	Height               uint64
+	Minted               types.Coins // minted by the coinbase tx
+	FeesBurned           types.Coins // tx fees, which are deducted from the senders and not credited to any account
+	CumulativeMinted     types.Coins
+	CumulativeFeesBurned types.Coins
+}
+
+// AddSupplyDelta adds the supply delta of the given finalized block to the supply index, given the
+// receipts of its txs. A block indexed already, e.g. when the finalization is replayed, is skipped.
+func (ch *Chain) AddSupplyDelta(block *core.ExtendedBlock, receipts BlockTxReceipts) error {
+	entry := SupplyDeltaEntry{
+		BlockHash:            block.Hash(),
+		Height:               block.Height,
+		Minted:               types.NewCoins(0, 0),
+		FeesBurned:           types.NewCoins(0, 0),
+		CumulativeMinted:     types.NewCoins(0, 0),
+		CumulativeFeesBurned: types.NewCoins(0, 0),
+	}
+
+	latest := &SupplyDeltaEntry{}
+	err := ch.store.Get(latestSupplyDeltaKey, latest)
+	if err == nil {
+		if latest.Height >= block.Height {
+			return nil
+		}
+		entry.CumulativeMinted = latest.CumulativeMinted
+		entry.CumulativeFeesBurned = latest.CumulativeFeesBurned
+	} else if err != store.ErrKeyNotFound {
+		return err
+	}
+
+	for i, rawTx := range block.Txs {
+		tx, err := types.TxFromBytes(rawTx)
+		if err != nil {
+			logger.Errorf("Failed to parse tx for the supply index: %v", err)
+			continue
+		}
+		var receipt *TxReceiptEntry
+		if i < len(receipts) {
+			receipt = receipts[i]
+		}
+		minted, fee := ch.getTxSupplyDelta(tx, receipt)
+		entry.Minted = entry.Minted.Plus(minted)
+		entry.FeesBurned = entry.FeesBurned.Plus(fee)
+	}
+	entry.CumulativeMinted = entry.CumulativeMinted.Plus(entry.Minted)
+	entry.CumulativeFeesBurned = entry.CumulativeFeesBurned.Plus(entry.FeesBurned)
+
+	if err := ch.store.Put(supplyDeltaKey(block.Height), entry); err != nil {
+		return err
+	}
+	return ch.store.Put(latestSupplyDeltaKey, entry)
+}
+
+// removeSupplyDelta removes the supply delta of the finalized block at the given height from the supply
+// index. If the block is the last indexed block, the supply delta of its parent carries the cumulative
+// totals again.
+func (ch *Chain) removeSupplyDelta(height uint64) error {
+	err := ch.store.Delete(supplyDeltaKey(height))
+	if err != nil && err != store.ErrKeyNotFound {
+		return err
+	}
+
+	latest := &SupplyDeltaEntry{}
+	err = ch.store.Get(latestSupplyDeltaKey, latest)
+	if err == store.ErrKeyNotFound || (err == nil && latest.Height < height) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if previous, found := ch.FindSupplyDelta(height - 1); found {
+		return ch.store.Put(latestSupplyDeltaKey, previous)
+	}
+	err = ch.store.Delete(latestSupplyDeltaKey)
+	if err != nil && err != store.ErrKeyNotFound {
+		return err
+	}
+	return nil
+}
+
+// getTxSupplyDelta returns the coins minted and the fee burned by the given tx, given its receipt if any.
+func (ch *Chain) getTxSupplyDelta(tx types.Tx, receipt *TxReceiptEntry) (minted types.Coins, fee types.Coins) {
+	switch tx := tx.(type) {
+	case *types.CoinbaseTx:
+		for _, output := range tx.Outputs {
+			minted = minted.Plus(output.Coins)
+		}
+	case *types.SendTx:
+		fee = tx.Fee
+	case *types.ReserveFundTx:
+		fee = tx.Fee
+	case *types.ReleaseFundTx:
+		fee = tx.Fee
+	case *types.ServicePaymentTx:
+		fee = tx.Fee
+	case *types.SplitRuleTx:
+		fee = tx.Fee
+	case *types.DepositStakeTx:
+		fee = tx.Fee
+	case *types.DepositStakeTxV2:
+		fee = tx.Fee
+	case *types.WithdrawStakeTx:
+		fee = tx.Fee
+	case *types.StakeRewardDistributionTx:
+		fee = tx.Fee
+	case *types.ParameterChangeTx:
+		fee = tx.Fee
+	case *types.SubchainCheckpointTx:
+		fee = tx.Fee
+	case *types.SubchainRegistrationTx:
+		fee = tx.Fee
+	case *types.CrossChainSendTx:
+		fee = tx.Fee
+	case *types.CrossChainDeliverTx:
+		fee = tx.Fee
+	case *types.ValidatorKeyChangeTx:
+		fee = tx.Fee
+	case *types.StakeAutoCompoundingTx:
+		fee = tx.Fee
+	case *types.ScheduledTx:
+		// The fee is paid by the wrapped tx, whose receipt is recorded under its own hash
+		if inner, err := types.TxFromBytes(tx.Tx); err == nil {
+			innerReceipt, _ := ch.FindTxReceiptByHash(crypto.Keccak256Hash(tx.Tx))
+			return ch.getTxSupplyDelta(inner, innerReceipt)
+		}
+	case *types.SmartContractTx:
+		// The fee of a smart contract tx is determined by the gas actually used
+		if receipt != nil && tx.GasPrice != nil {
+			fee = types.Coins{
+				ThetaWei: big.NewInt(0),
+				TFuelWei: new(big.Int).Mul(tx.GasPrice, new(big.Int).SetUint64(receipt.GasUsed)),
+			}
+		}
+	case *types.SmartContractTxV2:
+		// The gas price of a dynamic fee tx depends on the base fee, and is recorded in the receipt
+		if receipt != nil && receipt.GasPrice() != nil {
+			fee = types.Coins{
+				ThetaWei: big.NewInt(0),
+				TFuelWei: new(big.Int).Mul(receipt.GasPrice(), new(big.Int).SetUint64(receipt.GasUsed)),
+			}
+		}
+	}
+	return minted.NoNil(), fee.NoNil()
+}
+
+// FindSupplyDelta looks up the supply delta of the finalized block at the given height.
+func (ch *Chain) FindSupplyDelta(height uint64) (*SupplyDeltaEntry, bool) {
+	entry := &SupplyDeltaEntry{}
+	err := ch.store.Get(supplyDeltaKey(height), entry)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Error(err)
+		}
+		return nil, false
+	}
+	return entry, true
+}
+
+// SupplyDelta summarizes the supply changes over a height window.
+type SupplyDelta struct {
+	StartHeight          uint64
+	EndHeight            uint64
+	NumBlocks            uint64 // number of blocks in the window with supply deltas available
+	Minted               types.Coins
+	FeesBurned           types.Coins
+	CumulativeMinted     types.Coins // as of the last indexed block in the window
+	CumulativeFeesBurned types.Coins // as of the last indexed block in the window
+}
+
+// GetSupplyDelta aggregates the supply index over heights [start, end]. Heights without supply
+// deltas, e.g. blocks finalized before the index was introduced, are skipped.
+func (ch *Chain) GetSupplyDelta(start, end uint64) (*SupplyDelta, error) {
+	if start > end {
+		return nil, errors.New("start height must not be greater than end height")
+	}
+	if end-start >= MaxSupplyDeltaWindow {
+		return nil, errors.New("height window too large")
+	}
+
+	delta := &SupplyDelta{
+		StartHeight:          start,
+		EndHeight:            end,
+		Minted:               types.NewCoins(0, 0),
+		FeesBurned:           types.NewCoins(0, 0),
+		CumulativeMinted:     types.NewCoins(0, 0),
+		CumulativeFeesBurned: types.NewCoins(0, 0),
+	}
+	for height := start; height <= end; height++ {
+		entry, found := ch.FindSupplyDelta(height)
+		if !found {
+			continue
+		}
+
+		delta.NumBlocks++
+		delta.Minted = delta.Minted.Plus(entry.Minted)
+		delta.FeesBurned = delta.FeesBurned.Plus(entry.FeesBurned)
+		delta.CumulativeMinted = entry.CumulativeMinted
+		delta.CumulativeFeesBurned = entry.CumulativeFeesBurned
+	}
+
+	return delta, nil
+}
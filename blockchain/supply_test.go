@@ -0,0 +1,92 @@
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestSupplyDelta(t *testing.T) {
+	assert := assert.New(t)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	toBytes := func(tx types.Tx) common.Bytes {
+		raw, err := types.TxToBytes(tx)
+		assert.Nil(err)
+		return raw
+	}
+
+	coinbaseTx := &types.CoinbaseTx{
+		Proposer: types.TxInput{Address: alice},
+		Outputs: []types.TxOutput{
+			{Address: alice, Coins: types.NewCoins(0, 300)},
+			{Address: bob, Coins: types.NewCoins(0, 200)},
+		},
+		BlockHeight: 1,
+	}
+	sendTx := &types.SendTx{
+		Fee:     types.NewCoins(0, 10),
+		Inputs:  []types.TxInput{{Address: alice, Coins: types.NewCoins(0, 110)}},
+		Outputs: []types.TxOutput{{Address: bob, Coins: types.NewCoins(0, 100)}},
+	}
+	smartContractTx := &types.SmartContractTx{
+		From:     types.TxInput{Address: alice, Coins: types.NewCoins(0, 0)},
+		To:       types.TxOutput{Address: bob},
+		GasLimit: 100000,
+		GasPrice: big.NewInt(4),
+	}
+	chain.AddTxReceipt(smartContractTx, nil, nil, common.Address{}, 25, smartContractTx.GasPrice, nil)
+
+	block1 := core.CreateTestBlock("b1", "")
+	block1.Height = 1
+	block1.Txs = []common.Bytes{toBytes(coinbaseTx), toBytes(sendTx)}
+
+	block2 := core.CreateTestBlock("b2", "")
+	block2.Height = 2
+
+	block3 := core.CreateTestBlock("b3", "")
+	block3.Height = 3
+	block3.Txs = []common.Bytes{toBytes(smartContractTx)}
+
+	for _, block := range []*core.Block{block1, block2, block3} {
+		eb := &core.ExtendedBlock{Block: block}
+		assert.Nil(chain.AddSupplyDelta(eb, chain.FindBlockTxReceipts(eb)))
+	}
+
+	// A block is only accounted once
+	eb3 := &core.ExtendedBlock{Block: block3}
+	assert.Nil(chain.AddSupplyDelta(eb3, chain.FindBlockTxReceipts(eb3)))
+
+	entry, found := chain.FindSupplyDelta(1)
+	assert.True(found)
+	assert.Equal(int64(500), entry.Minted.TFuelWei.Int64())
+	assert.Equal(int64(10), entry.FeesBurned.TFuelWei.Int64())
+
+	entry, found = chain.FindSupplyDelta(3)
+	assert.True(found)
+	assert.Equal(int64(0), entry.Minted.TFuelWei.Int64())
+	assert.Equal(int64(100), entry.FeesBurned.TFuelWei.Int64())
+	assert.Equal(int64(500), entry.CumulativeMinted.TFuelWei.Int64())
+	assert.Equal(int64(110), entry.CumulativeFeesBurned.TFuelWei.Int64())
+
+	delta, err := chain.GetSupplyDelta(2, 4)
+	assert.Nil(err)
+	assert.Equal(uint64(2), delta.NumBlocks)
+	assert.Equal(int64(0), delta.Minted.TFuelWei.Int64())
+	assert.Equal(int64(100), delta.FeesBurned.TFuelWei.Int64())
+	assert.Equal(int64(500), delta.CumulativeMinted.TFuelWei.Int64())
+
+	_, err = chain.GetSupplyDelta(4, 1)
+	assert.NotNil(err)
+	_, err = chain.GetSupplyDelta(1, MaxSupplyDeltaWindow+1)
+	assert.NotNil(err)
+}
@@ -0,0 +1,74 @@
+package blockchain
+
+// findFinalizedBlockTimestamp returns the timestamp of the finalized block at the given height. The
+// block stats index is used as the timestamp index, with the blocks as the fallback for the heights
+// finalized before the index was introduced.
+func (ch *Chain) findFinalizedBlockTimestamp(height uint64) (uint64, bool) {
+	if entry, found := ch.FindBlockStats(height); found {
+		return entry.Timestamp, true
+	}
+	for _, block := range ch.FindBlocksByHeight(height) {
+		if block.Status.IsFinalized() && block.Timestamp != nil {
+			return block.Timestamp.Uint64(), true
+		}
+	}
+	return 0, false
+}
+
+// findFinalizedBlockTimestampFrom returns the first height in [height, limit] with a finalized block,
+// and the timestamp of the block.
+func (ch *Chain) findFinalizedBlockTimestampFrom(height, limit uint64) (uint64, uint64, bool) {
+	for h := height; h <= limit; h++ {
+		if timestamp, found := ch.findFinalizedBlockTimestamp(h); found {
+			return h, timestamp, true
+		}
+	}
+	return 0, 0, false
+}
+
+// FindFinalizedBlockHeightByTimestamp binary searches heights [start, end] for the finalized block
+// closest to the given Unix timestamp. If before is true, it returns the height of the last block with
+// a timestamp not after the given timestamp, otherwise the height of the first block with a timestamp
+// not before the given timestamp. Returns false if there is no such block in the range.
+func (ch *Chain) FindFinalizedBlockHeightByTimestamp(timestamp uint64, before bool, start, end uint64) (uint64, bool) {
+	var result uint64
+	found := false
+
+	lo, hi := start, end
+	for lo <= hi {
+		mid := lo + (hi-lo)/2
+		height, blockTimestamp, ok := ch.findFinalizedBlockTimestampFrom(mid, hi)
+		if !ok {
+			// No finalized blocks in [mid, hi]
+			if mid == 0 {
+				break
+			}
+			hi = mid - 1
+			continue
+		}
+
+		if before {
+			if blockTimestamp <= timestamp {
+				result, found = height, true
+				lo = height + 1
+			} else {
+				if mid == 0 {
+					break
+				}
+				hi = mid - 1
+			}
+		} else {
+			if blockTimestamp >= timestamp {
+				result, found = height, true
+				if mid == 0 {
+					break
+				}
+				hi = mid - 1
+			} else {
+				lo = height + 1
+			}
+		}
+	}
+
+	return result, found
+}
@@ -0,0 +1,59 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/core"
+)
+
+func TestFindFinalizedBlockHeightByTimestamp(t *testing.T) {
+	assert := assert.New(t)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+
+	// Blocks at heights 1 to 10 with timestamps 100, 110, ..., 190, except the missing height 5
+	for height := uint64(1); height <= 10; height++ {
+		if height == 5 {
+			continue
+		}
+		block := core.CreateTestBlock(fmt.Sprintf("b%v", height), "")
+		block.Height = height
+		block.Timestamp = big.NewInt(int64(90 + 10*height))
+		chain.AddBlockStats(&core.ExtendedBlock{Block: block}, nil, time.Unix(0, 0))
+	}
+
+	height, found := chain.FindFinalizedBlockHeightByTimestamp(130, true, 1, 12)
+	assert.True(found)
+	assert.Equal(uint64(4), height)
+
+	height, found = chain.FindFinalizedBlockHeightByTimestamp(135, true, 1, 12)
+	assert.True(found)
+	assert.Equal(uint64(4), height)
+
+	height, found = chain.FindFinalizedBlockHeightByTimestamp(135, false, 1, 12)
+	assert.True(found)
+	assert.Equal(uint64(6), height)
+
+	height, found = chain.FindFinalizedBlockHeightByTimestamp(150, false, 1, 12)
+	assert.True(found)
+	assert.Equal(uint64(6), height)
+
+	height, found = chain.FindFinalizedBlockHeightByTimestamp(1000, true, 1, 12)
+	assert.True(found)
+	assert.Equal(uint64(10), height)
+
+	_, found = chain.FindFinalizedBlockHeightByTimestamp(1000, false, 1, 12)
+	assert.False(found)
+
+	_, found = chain.FindFinalizedBlockHeightByTimestamp(50, true, 1, 12)
+	assert.False(found)
+
+	height, found = chain.FindFinalizedBlockHeightByTimestamp(50, false, 1, 12)
+	assert.True(found)
+	assert.Equal(uint64(1), height)
+}
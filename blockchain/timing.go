@@ -0,0 +1,126 @@
+package blockchain
+
+import (
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/store"
+)
+
+// maxFirstSeenEntries is the maximum number of the txs and blocks whose first-seen times are kept in memory
+// until they are finalized.
+const maxFirstSeenEntries = 200000
+
+// txTimingKey constructs the DB key for the timings of the given finalized tx.
+func txTimingKey(hash common.Hash) common.Bytes {
+	return append(common.Bytes("ttm/"), hash[:]...)
+}
+
+// TxTimingEntry records when a finalized tx was first seen by the node, when the block including it was
+// first seen, and when the block was finalized. The times are Unix timestamps in milliseconds, 0 if unknown,
+// e.g. for a tx received directly in a block, or seen before the node restarted.
+type TxTimingEntry struct {
+	TxHash         common.Hash
+	BlockHash      common.Hash
+	BlockHeight    uint64
+	FirstSeen      uint64
+	BlockFirstSeen uint64
+	FinalizedAt    uint64
+}
+
+// EnableFirstSeen starts recording the first-seen times of the txs and the blocks for the timings index. They
+// are not recorded otherwise.
+func (ch *Chain) EnableFirstSeen() error {
+	cache, err := lru.New(maxFirstSeenEntries)
+	if err != nil {
+		return err
+	}
+	ch.firstSeen = cache
+	return nil
+}
+
+func unixMilli(t time.Time) uint64 {
+	return uint64(t.UnixNano() / int64(time.Millisecond))
+}
+
+// RecordFirstSeen records the given time as the first-seen time of the given tx or block, unless it has
+// been seen before.
+func (ch *Chain) RecordFirstSeen(hash common.Hash, seenAt time.Time) {
+	if ch.firstSeen == nil {
+		return
+	}
+	ch.firstSeen.ContainsOrAdd(hash, unixMilli(seenAt))
+}
+
+// FindFirstSeen returns the first-seen time of the given tx or block not finalized yet, in Unix milliseconds.
+func (ch *Chain) FindFirstSeen(hash common.Hash) (uint64, bool) {
+	if ch.firstSeen == nil {
+		return 0, false
+	}
+	seenAt, ok := ch.firstSeen.Peek(hash)
+	if !ok {
+		return 0, false
+	}
+	return seenAt.(uint64), true
+}
+
+// AddTxTimings adds the timings of the txs of the given finalized block to the timings index. The first-seen
+// times of the txs and the block are no longer kept in memory afterwards.
+func (ch *Chain) AddTxTimings(block *core.ExtendedBlock, finalizedAt time.Time) error {
+	blockHash := block.Hash()
+	blockFirstSeen, _ := ch.FindFirstSeen(blockHash)
+	for _, rawTx := range block.Txs {
+		txHash := crypto.Keccak256Hash(rawTx)
+		firstSeen, _ := ch.FindFirstSeen(txHash)
+		entry := TxTimingEntry{
+			TxHash:         txHash,
+			BlockHash:      blockHash,
+			BlockHeight:    block.Height,
+			FirstSeen:      firstSeen,
+			BlockFirstSeen: blockFirstSeen,
+			FinalizedAt:    unixMilli(finalizedAt),
+		}
+		if err := ch.store.Put(txTimingKey(txHash), entry); err != nil {
+			return err
+		}
+		if ch.firstSeen != nil {
+			ch.firstSeen.Remove(txHash)
+		}
+	}
+	if ch.firstSeen != nil {
+		ch.firstSeen.Remove(blockHash)
+	}
+	return nil
+}
+
+// removeTxTimings removes the timings of the txs of the given finalized block from the timings index.
+func (ch *Chain) removeTxTimings(block *core.ExtendedBlock) error {
+	for _, rawTx := range block.Txs {
+		txHash := crypto.Keccak256Hash(rawTx)
+		entry, found := ch.FindTxTimings(txHash)
+		if !found || entry.BlockHash != block.Hash() {
+			continue
+		}
+		if err := ch.store.Delete(txTimingKey(txHash)); err != nil && err != store.ErrKeyNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindTxTimings looks up the timings of the given finalized tx.
+func (ch *Chain) FindTxTimings(hash common.Hash) (*TxTimingEntry, bool) {
+	entry := &TxTimingEntry{}
+	err := ch.store.Get(txTimingKey(hash), entry)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Error(err)
+		}
+		return nil, false
+	}
+	return entry, true
+}
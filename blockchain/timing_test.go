@@ -0,0 +1,59 @@
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+)
+
+func TestTxTimings(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+	require.Nil(chain.EnableFirstSeen())
+
+	rawTx := common.Bytes("tx1")
+	txHash := crypto.Keccak256Hash(rawTx)
+	seenAt := time.Unix(1000, 0)
+	chain.RecordFirstSeen(txHash, seenAt)
+
+	// Only the first sighting is recorded
+	chain.RecordFirstSeen(txHash, seenAt.Add(time.Second))
+	firstSeen, ok := chain.FindFirstSeen(txHash)
+	require.True(ok)
+	assert.Equal(uint64(1000000), firstSeen)
+
+	block := core.CreateTestBlock("b1", "")
+	block.Height = 1
+	block.Txs = []common.Bytes{rawTx}
+	eb, err := chain.AddBlock(block)
+	require.Nil(err)
+	blockFirstSeen, ok := chain.FindFirstSeen(eb.Hash())
+	require.True(ok)
+
+	require.Nil(chain.AddTxTimings(eb, seenAt.Add(3*time.Second)))
+	entry, found := chain.FindTxTimings(txHash)
+	require.True(found)
+	assert.Equal(eb.Hash(), entry.BlockHash)
+	assert.Equal(uint64(1), entry.BlockHeight)
+	assert.Equal(uint64(1000000), entry.FirstSeen)
+	assert.Equal(blockFirstSeen, entry.BlockFirstSeen)
+	assert.Equal(uint64(1003000), entry.FinalizedAt)
+
+	// The first-seen times are released once finalized
+	_, ok = chain.FindFirstSeen(txHash)
+	assert.False(ok)
+	_, ok = chain.FindFirstSeen(eb.Hash())
+	assert.False(ok)
+
+	require.Nil(chain.RollbackIndices(eb))
+	_, found = chain.FindTxTimings(txHash)
+	assert.False(found)
+}
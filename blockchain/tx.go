@@ -1,6 +1,8 @@
 package blockchain
 
 import (
+	"math/big"
+
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
@@ -48,22 +50,29 @@ func (ch *Chain) AddTxsToIndex(block *core.ExtendedBlock, force bool) {
 
 // FindTxByHash looks up transaction by hash and additionally returns the containing block.
 func (ch *Chain) FindTxByHash(hash common.Hash) (tx common.Bytes, block *core.ExtendedBlock, founded bool) {
+	tx, block, _, founded = ch.FindTxAndIndexByHash(hash)
+	return tx, block, founded
+}
+
+// FindTxAndIndexByHash looks up transaction by hash and additionally returns the containing block and the
+// position of the transaction in the block.
+func (ch *Chain) FindTxAndIndexByHash(hash common.Hash) (tx common.Bytes, block *core.ExtendedBlock, index uint64, founded bool) {
 	txIndexEntry := &TxIndexEntry{}
 	err := ch.store.Get(txIndexKey(hash), txIndexEntry)
 	if err != nil {
 		if err != store.ErrKeyNotFound {
 			logger.Error(err)
 		}
-		return nil, nil, false
+		return nil, nil, 0, false
 	}
 	block, err = ch.FindBlock(txIndexEntry.BlockHash)
 	if err != nil {
 		if err == store.ErrKeyNotFound {
-			return nil, nil, false
+			return nil, nil, 0, false
 		}
 		logger.Panic(err)
 	}
-	return block.Txs[txIndexEntry.Index], block, true
+	return block.Txs[txIndexEntry.Index], block, txIndexEntry.Index, true
 }
 
 // ---------------- Tx Receipts ---------------
@@ -81,11 +90,23 @@ type TxReceiptEntry struct {
 	ContractAddress common.Address
 	GasUsed         uint64
 	EvmErr          string
+
+	// GasPrices holds the gas price the transaction was charged at, which depends on the base fee of the block
+	// for a SmartContractTxV2. It is a tail field so that the receipts stored before it was added still decode.
+	GasPrices []*big.Int `rlp:"tail"`
+}
+
+// GasPrice returns the gas price the transaction was charged at, or nil if it was not recorded
+func (r *TxReceiptEntry) GasPrice() *big.Int {
+	if len(r.GasPrices) == 0 {
+		return nil
+	}
+	return r.GasPrices[0]
 }
 
 // AddTxReceipt adds transaction receipt.
 func (ch *Chain) AddTxReceipt(tx types.Tx, logs []*types.Log, evmRet common.Bytes,
-	contractAddr common.Address, gasUsed uint64, evmErr error) {
+	contractAddr common.Address, gasUsed uint64, gasPrice *big.Int, evmErr error) {
 	raw, err := types.TxToBytes(tx)
 	if err != nil {
 		// Should never happen
@@ -104,6 +125,9 @@ func (ch *Chain) AddTxReceipt(tx types.Tx, logs []*types.Log, evmRet common.Byte
 		GasUsed:         gasUsed,
 		EvmErr:          errStr,
 	}
+	if gasPrice != nil {
+		txReceiptEntry.GasPrices = []*big.Int{gasPrice}
+	}
 	key := txReceiptKey(txHash)
 
 	err = ch.store.Put(key, txReceiptEntry)
@@ -114,6 +138,29 @@ func (ch *Chain) AddTxReceipt(tx types.Tx, logs []*types.Log, evmRet common.Byte
 
 // FindTxReceiptByHash looks up transaction receipt by hash.
 func (ch *Chain) FindTxReceiptByHash(hash common.Hash) (*TxReceiptEntry, bool) {
+	if receipt, found := ch.findTxReceiptInDB(hash); found {
+		return receipt, true
+	}
+	return ch.findAncientTxReceipt(hash)
+}
+
+// BlockTxReceipts are the receipts of the txs of a finalized block, in the order of the txs. They are looked up
+// once and shared by the indices of the block. The entry of a tx without a receipt is nil.
+type BlockTxReceipts []*TxReceiptEntry
+
+// FindBlockTxReceipts looks up the receipts of the txs of the given block.
+func (ch *Chain) FindBlockTxReceipts(block *core.ExtendedBlock) BlockTxReceipts {
+	receipts := make(BlockTxReceipts, len(block.Txs))
+	for i, rawTx := range block.Txs {
+		if receipt, found := ch.FindTxReceiptByHash(crypto.Keccak256Hash(rawTx)); found {
+			receipts[i] = receipt
+		}
+	}
+	return receipts
+}
+
+// findTxReceiptInDB looks up the transaction receipt in the DB, not in the ancient store.
+func (ch *Chain) findTxReceiptInDB(hash common.Hash) (*TxReceiptEntry, bool) {
 	txReceiptEntry := &TxReceiptEntry{}
 
 	key := txReceiptKey(hash)
@@ -34,6 +34,14 @@ func TestTxIndex(t *testing.T) {
 		assert.Equal(block.Hash(), block1.Hash())
 	}
 
+	for idx, t := range block1.Txs {
+		tx, block, index, found := chain.FindTxAndIndexByHash(crypto.Keccak256Hash(t))
+		assert.True(found)
+		assert.Equal(t, tx)
+		assert.Equal(block.Hash(), block1.Hash())
+		assert.Equal(uint64(idx), index)
+	}
+
 	tx, block, found := chain.FindTxByHash(crypto.Keccak256Hash(tx4))
 	assert.False(found)
 	assert.Nil(tx)
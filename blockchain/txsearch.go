@@ -0,0 +1,388 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store"
+)
+
+// MaxTxSearchWindow is the maximum number of heights that can be scanned by a single search.
+const MaxTxSearchWindow = 10000
+
+// Transaction types recorded in the search index.
+const (
+	TxSearchTypeCoinbase                = "coinbase"
+	TxSearchTypeSlash                   = "slash"
+	TxSearchTypeSend                    = "send"
+	TxSearchTypeReserveFund             = "reserve_fund"
+	TxSearchTypeReleaseFund             = "release_fund"
+	TxSearchTypeServicePayment          = "service_payment"
+	TxSearchTypeSplitRule               = "split_rule"
+	TxSearchTypeSmartContract           = "smart_contract"
+	TxSearchTypeDepositStake            = "deposit_stake"
+	TxSearchTypeWithdrawStake           = "withdraw_stake"
+	TxSearchTypeStakeRewardDistribution = "stake_reward_distribution"
+	TxSearchTypeParameterChange         = "parameter_change"
+	TxSearchTypeSubchainCheckpoint      = "subchain_checkpoint"
+	TxSearchTypeCrossChainSend          = "cross_chain_send"
+	TxSearchTypeCrossChainDeliver       = "cross_chain_deliver"
+	TxSearchTypeValidatorKeyChange      = "validator_key_change"
+	TxSearchTypeStakeAutoCompounding    = "stake_auto_compounding"
+	TxSearchTypeSubchainRegistration    = "subchain_registration"
+)
+
+// txSearchKey constructs the DB key for the search entries of the finalized block at the given height.
+func txSearchKey(height uint64) common.Bytes {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, height)
+	return append(common.Bytes("txs/"), buf[:n]...)
+}
+
+// txAddressKey constructs the composite DB key for the transactions of the given address at the given height.
+func txAddressKey(address common.Address, height uint64) common.Bytes {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, height)
+	key := append(common.Bytes("txa/"), address[:]...)
+	return append(key, buf[:n]...)
+}
+
+// TxSearchEntry summarizes a finalized transaction for the search index.
+type TxSearchEntry struct {
+	TxHash common.Hash
+	Height uint64
+	Type   string
+	From   []common.Address
+	To     []common.Address
+	Amount types.Coins // total amount transferred by the transaction
+}
+
+// txAddressEntry lists the positions of the transactions involving an address in the search
+// entries of a height.
+type txAddressEntry struct {
+	FromIndices []uint64
+	ToIndices   []uint64
+}
+
+// AddTxsToSearchIndex adds the transactions of the given finalized block to the search index.
+func (ch *Chain) AddTxsToSearchIndex(block *core.ExtendedBlock) {
+	entries := []TxSearchEntry{}
+	addressEntries := make(map[common.Address]*txAddressEntry)
+	getAddressEntry := func(address common.Address) *txAddressEntry {
+		addressEntry, ok := addressEntries[address]
+		if !ok {
+			addressEntry = &txAddressEntry{}
+			addressEntries[address] = addressEntry
+		}
+		return addressEntry
+	}
+
+	for _, rawTx := range block.Txs {
+		tx, err := types.TxFromBytes(rawTx)
+		if err != nil {
+			logger.Errorf("Failed to parse tx for the search index: %v", err)
+			continue
+		}
+		entry := newTxSearchEntry(tx)
+		entry.TxHash = crypto.Keccak256Hash(rawTx)
+		entry.Height = block.Height
+
+		idx := uint64(len(entries))
+		for _, address := range entry.From {
+			addressEntry := getAddressEntry(address)
+			addressEntry.FromIndices = appendIndex(addressEntry.FromIndices, idx)
+		}
+		for _, address := range entry.To {
+			addressEntry := getAddressEntry(address)
+			addressEntry.ToIndices = appendIndex(addressEntry.ToIndices, idx)
+		}
+		entries = append(entries, entry)
+	}
+
+	err := ch.store.Put(txSearchKey(block.Height), entries)
+	if err != nil {
+		logger.Panic(err)
+	}
+	for address, addressEntry := range addressEntries {
+		err := ch.store.Put(txAddressKey(address, block.Height), addressEntry)
+		if err != nil {
+			logger.Panic(err)
+		}
+	}
+}
+
+// removeTxsFromSearchIndex removes the transactions of the given finalized block from the search index.
+func (ch *Chain) removeTxsFromSearchIndex(block *core.ExtendedBlock) error {
+	keys := []common.Bytes{txSearchKey(block.Height)}
+	for _, address := range blockTxAddresses(block) {
+		keys = append(keys, txAddressKey(address, block.Height))
+	}
+	for _, key := range keys {
+		err := ch.store.Delete(key)
+		if err != nil && err != store.ErrKeyNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendIndex(indices []uint64, idx uint64) []uint64 {
+	// An address can appear more than once in a tx, e.g. in multiple outputs
+	if len(indices) > 0 && indices[len(indices)-1] == idx {
+		return indices
+	}
+	return append(indices, idx)
+}
+
+// TxAddresses returns the addresses involved in the given transaction, i.e. the senders and the recipients.
+func TxAddresses(tx types.Tx) []common.Address {
+	entry := newTxSearchEntry(tx)
+	return append(entry.From, entry.To...)
+}
+
+// blockTxAddresses returns the distinct addresses involved in the transactions of the given block.
+func blockTxAddresses(block *core.ExtendedBlock) []common.Address {
+	addresses := []common.Address{}
+	seen := make(map[common.Address]bool)
+	for _, rawTx := range block.Txs {
+		tx, err := types.TxFromBytes(rawTx)
+		if err != nil {
+			continue
+		}
+		for _, address := range TxAddresses(tx) {
+			if !seen[address] {
+				seen[address] = true
+				addresses = append(addresses, address)
+			}
+		}
+	}
+	return addresses
+}
+
+// TxSearchTypeOf returns the search index type of the transaction, e.g. "service_payment"
+func TxSearchTypeOf(tx types.Tx) string {
+	return newTxSearchEntry(tx).Type
+}
+
+func newTxSearchEntry(tx types.Tx) TxSearchEntry {
+	entry := TxSearchEntry{}
+	switch tx := tx.(type) {
+	case *types.CoinbaseTx:
+		entry.Type = TxSearchTypeCoinbase
+		entry.From = []common.Address{tx.Proposer.Address}
+		for _, output := range tx.Outputs {
+			entry.To = append(entry.To, output.Address)
+			entry.Amount = entry.Amount.Plus(output.Coins)
+		}
+	case *types.SlashTx:
+		entry.Type = TxSearchTypeSlash
+		entry.From = []common.Address{tx.Proposer.Address}
+		entry.To = []common.Address{tx.SlashedAddress}
+	case *types.SendTx:
+		entry.Type = TxSearchTypeSend
+		for _, input := range tx.Inputs {
+			entry.From = append(entry.From, input.Address)
+		}
+		for _, output := range tx.Outputs {
+			entry.To = append(entry.To, output.Address)
+			entry.Amount = entry.Amount.Plus(output.Coins)
+		}
+	case *types.ReserveFundTx:
+		entry.Type = TxSearchTypeReserveFund
+		entry.From = []common.Address{tx.Source.Address}
+		entry.Amount = tx.Collateral
+	case *types.ReleaseFundTx:
+		entry.Type = TxSearchTypeReleaseFund
+		entry.From = []common.Address{tx.Source.Address}
+	case *types.ServicePaymentTx:
+		entry.Type = TxSearchTypeServicePayment
+		entry.From = []common.Address{tx.Source.Address}
+		entry.To = []common.Address{tx.Target.Address}
+		entry.Amount = tx.Source.Coins
+	case *types.SplitRuleTx:
+		entry.Type = TxSearchTypeSplitRule
+		entry.From = []common.Address{tx.Initiator.Address}
+		for _, split := range tx.Splits {
+			entry.To = append(entry.To, split.Address)
+		}
+	case *types.SmartContractTx:
+		entry.Type = TxSearchTypeSmartContract
+		entry.From = []common.Address{tx.From.Address}
+		entry.To = []common.Address{tx.To.Address}
+		entry.Amount = tx.From.Coins
+	case *types.SmartContractTxV2:
+		entry.Type = TxSearchTypeSmartContract
+		entry.From = []common.Address{tx.From.Address}
+		entry.To = []common.Address{tx.To.Address}
+		entry.Amount = tx.From.Coins
+	case *types.DepositStakeTx:
+		entry.Type = TxSearchTypeDepositStake
+		entry.From = []common.Address{tx.Source.Address}
+		entry.To = []common.Address{tx.Holder.Address}
+		entry.Amount = tx.Source.Coins
+	case *types.DepositStakeTxV2:
+		entry.Type = TxSearchTypeDepositStake
+		entry.From = []common.Address{tx.Source.Address}
+		entry.To = []common.Address{tx.Holder.Address}
+		entry.Amount = tx.Source.Coins
+	case *types.WithdrawStakeTx:
+		entry.Type = TxSearchTypeWithdrawStake
+		entry.From = []common.Address{tx.Source.Address}
+		entry.To = []common.Address{tx.Holder.Address}
+	case *types.StakeRewardDistributionTx:
+		entry.Type = TxSearchTypeStakeRewardDistribution
+		entry.From = []common.Address{tx.Holder.Address}
+		entry.To = []common.Address{tx.Beneficiary.Address}
+	case *types.ParameterChangeTx:
+		entry.Type = TxSearchTypeParameterChange
+		entry.From = []common.Address{tx.Proposer.Address}
+	case *types.SubchainCheckpointTx:
+		entry.Type = TxSearchTypeSubchainCheckpoint
+		entry.From = []common.Address{tx.Submitter.Address}
+	case *types.SubchainRegistrationTx:
+		entry.Type = TxSearchTypeSubchainRegistration
+		entry.From = []common.Address{tx.Proposer.Address}
+		entry.To = []common.Address{tx.Operator}
+	case *types.CrossChainSendTx:
+		entry.Type = TxSearchTypeCrossChainSend
+		entry.From = []common.Address{tx.Sender.Address}
+	case *types.CrossChainDeliverTx:
+		entry.Type = TxSearchTypeCrossChainDeliver
+		entry.From = []common.Address{tx.Relayer.Address}
+		entry.To = []common.Address{tx.Message.Receiver}
+	case *types.ValidatorKeyChangeTx:
+		entry.Type = TxSearchTypeValidatorKeyChange
+		entry.From = []common.Address{tx.Source.Address}
+		entry.To = []common.Address{tx.Holder, tx.NewHolder}
+	case *types.StakeAutoCompoundingTx:
+		entry.Type = TxSearchTypeStakeAutoCompounding
+		entry.From = []common.Address{tx.Source.Address}
+		entry.To = []common.Address{tx.Holder}
+	case *types.ScheduledTx:
+		// Indexed as the wrapped tx
+		if inner, err := types.TxFromBytes(tx.Tx); err == nil {
+			return newTxSearchEntry(inner)
+		}
+	}
+	entry.Amount = entry.Amount.NoNil()
+	return entry
+}
+
+// TxSearchFilter specifies the conditions a transaction must satisfy to be returned by SearchTxs.
+// Empty fields match all the transactions.
+type TxSearchFilter struct {
+	StartHeight uint64
+	EndHeight   uint64
+	Types       []string
+	From        *common.Address
+	To          *common.Address
+	MinAmount   *types.Coins
+	Limit       int // maximum number of entries to return, 0 means no limit
+}
+
+// SearchTxs returns the indexed transactions within heights [StartHeight, EndHeight] matching the
+// filter, ordered by height. Heights without search entries, e.g. blocks finalized before the index
+// was introduced, are skipped.
+func (ch *Chain) SearchTxs(filter *TxSearchFilter) ([]TxSearchEntry, error) {
+	if filter.StartHeight > filter.EndHeight {
+		return nil, errors.New("start height must not be greater than end height")
+	}
+	if filter.EndHeight-filter.StartHeight >= MaxTxSearchWindow {
+		return nil, errors.New("height window too large")
+	}
+
+	txTypes := make(map[string]bool)
+	for _, t := range filter.Types {
+		txTypes[t] = true
+	}
+
+	results := []TxSearchEntry{}
+	for height := filter.StartHeight; height <= filter.EndHeight; height++ {
+		indices, ok := ch.findTxIndicesByAddress(filter, height)
+		if !ok {
+			continue
+		}
+
+		entries := []TxSearchEntry{}
+		err := ch.store.Get(txSearchKey(height), &entries)
+		if err != nil {
+			if err != store.ErrKeyNotFound {
+				logger.Error(err)
+			}
+			continue
+		}
+
+		for i, entry := range entries {
+			if indices != nil && !indices[uint64(i)] {
+				continue
+			}
+			if len(txTypes) > 0 && !txTypes[entry.Type] {
+				continue
+			}
+			if filter.From != nil && !containsAddress(entry.From, *filter.From) {
+				continue
+			}
+			if filter.To != nil && !containsAddress(entry.To, *filter.To) {
+				continue
+			}
+			if filter.MinAmount != nil && !entry.Amount.IsGTE(*filter.MinAmount) {
+				continue
+			}
+			results = append(results, entry)
+			if filter.Limit > 0 && len(results) >= filter.Limit {
+				return results, nil
+			}
+		}
+	}
+	return results, nil
+}
+
+// findTxIndicesByAddress looks up the positions of the transactions at the given height matching the
+// address filter with the composite address key, so that only the heights involving the address are
+// loaded. Returns nil if there is no address filter, and false if no transaction matches.
+func (ch *Chain) findTxIndicesByAddress(filter *TxSearchFilter, height uint64) (map[uint64]bool, bool) {
+	var address common.Address
+	var fromAddress bool
+	if filter.From != nil {
+		address, fromAddress = *filter.From, true
+	} else if filter.To != nil {
+		address = *filter.To
+	} else {
+		return nil, true
+	}
+
+	addressEntry := &txAddressEntry{}
+	err := ch.store.Get(txAddressKey(address, height), addressEntry)
+	if err != nil {
+		if err != store.ErrKeyNotFound {
+			logger.Error(err)
+		}
+		return nil, false
+	}
+
+	positions := addressEntry.ToIndices
+	if fromAddress {
+		positions = addressEntry.FromIndices
+	}
+	if len(positions) == 0 {
+		return nil, false
+	}
+	indices := make(map[uint64]bool)
+	for _, idx := range positions {
+		indices[idx] = true
+	}
+	return indices, true
+}
+
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
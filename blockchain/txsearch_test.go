@@ -0,0 +1,96 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestSearchTxs(t *testing.T) {
+	assert := assert.New(t)
+
+	core.ResetTestBlocks()
+	chain := CreateTestChain()
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	carol := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	toBytes := func(tx types.Tx) common.Bytes {
+		raw, err := types.TxToBytes(tx)
+		assert.Nil(err)
+		return raw
+	}
+	sendTx := func(from, to common.Address, tfuel int64) common.Bytes {
+		return toBytes(&types.SendTx{
+			Fee:     types.NewCoins(0, 1),
+			Inputs:  []types.TxInput{{Address: from, Coins: types.NewCoins(0, tfuel+1)}},
+			Outputs: []types.TxOutput{{Address: to, Coins: types.NewCoins(0, tfuel)}},
+		})
+	}
+
+	block1 := core.CreateTestBlock("b1", "")
+	block1.Height = 1
+	block1.Txs = []common.Bytes{sendTx(alice, bob, 100), sendTx(bob, carol, 5)}
+
+	block2 := core.CreateTestBlock("b2", "")
+	block2.Height = 2
+	block2.Txs = []common.Bytes{
+		toBytes(&types.DepositStakeTx{
+			Fee:     types.NewCoins(0, 1),
+			Source:  types.TxInput{Address: alice, Coins: types.NewCoins(1000, 0)},
+			Holder:  types.TxOutput{Address: carol},
+			Purpose: core.StakeForGuardian,
+		}),
+		sendTx(carol, alice, 200),
+	}
+
+	chain.AddTxsToSearchIndex(&core.ExtendedBlock{Block: block1})
+	chain.AddTxsToSearchIndex(&core.ExtendedBlock{Block: block2})
+
+	// No filter
+	entries, err := chain.SearchTxs(&TxSearchFilter{StartHeight: 1, EndHeight: 3})
+	assert.Nil(err)
+	assert.Equal(4, len(entries))
+	assert.Equal(uint64(1), entries[0].Height)
+	assert.Equal(TxSearchTypeSend, entries[0].Type)
+	assert.Equal(int64(100), entries[0].Amount.TFuelWei.Int64())
+	assert.Equal(TxSearchTypeDepositStake, entries[2].Type)
+
+	// Type filter
+	entries, err = chain.SearchTxs(&TxSearchFilter{StartHeight: 1, EndHeight: 2, Types: []string{TxSearchTypeDepositStake}})
+	assert.Nil(err)
+	assert.Equal(1, len(entries))
+	assert.Equal([]common.Address{carol}, entries[0].To)
+
+	// Address filters
+	entries, err = chain.SearchTxs(&TxSearchFilter{StartHeight: 1, EndHeight: 2, From: &alice})
+	assert.Nil(err)
+	assert.Equal(2, len(entries))
+	entries, err = chain.SearchTxs(&TxSearchFilter{StartHeight: 1, EndHeight: 2, From: &bob, To: &carol})
+	assert.Nil(err)
+	assert.Equal(1, len(entries))
+	entries, err = chain.SearchTxs(&TxSearchFilter{StartHeight: 1, EndHeight: 2, From: &bob, To: &alice})
+	assert.Nil(err)
+	assert.Equal(0, len(entries))
+	entries, err = chain.SearchTxs(&TxSearchFilter{StartHeight: 2, EndHeight: 2, To: &alice})
+	assert.Nil(err)
+	assert.Equal(1, len(entries))
+
+	// Minimum amount and limit
+	minAmount := types.NewCoins(0, 100)
+	entries, err = chain.SearchTxs(&TxSearchFilter{StartHeight: 1, EndHeight: 2, MinAmount: &minAmount})
+	assert.Nil(err)
+	assert.Equal(2, len(entries))
+	entries, err = chain.SearchTxs(&TxSearchFilter{StartHeight: 1, EndHeight: 2, Limit: 3})
+	assert.Nil(err)
+	assert.Equal(3, len(entries))
+
+	_, err = chain.SearchTxs(&TxSearchFilter{StartHeight: 2, EndHeight: 1})
+	assert.NotNil(err)
+	_, err = chain.SearchTxs(&TxSearchFilter{StartHeight: 1, EndHeight: MaxTxSearchWindow + 1})
+	assert.NotNil(err)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/node"
+	"github.com/thetatoken/theta/rpc"
+	"github.com/thetatoken/theta/store/database/backend"
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+var backupDir string
+var backupBaseDir string
+var backupRPCEndpoint string
+
+// backupCmd backs up the database of a running node
+// Example:
+//		theta backup --config=../privatenet/node
+//		theta backup --config=../privatenet/node --base=../privatenet/node/backup/db/2020-06-01-000000
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up the database of a running node",
+	Long: `Back up the chain and state database of a running node without stopping it. The backup is taken by the node
+through its RPC service. With --base, only the changes since the given full backup are backed up.`,
+	Run: runBackup,
+}
+
+// restoreCmd rebuilds the database from a backup
+// Example:
+//		theta restore --config=../privatenet/node --backup_dir=../privatenet/node/backup/db/2020-06-01-000000
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Rebuild the database of a stopped node from a backup",
+	Run:   runRestore,
+}
+
+func init() {
+	backupCmd.Flags().StringVar(&backupDir, "backup_dir", "", "backup directory (default is <config path>/backup/db/<time>)")
+	backupCmd.Flags().StringVar(&backupBaseDir, "base", "", "full backup to take an incremental backup against")
+	backupCmd.Flags().StringVar(&backupRPCEndpoint, "rpc_endpoint", "", "RPC endpoint of the node (default is the local RPC port in the config)")
+	RootCmd.AddCommand(backupCmd)
+
+	restoreCmd.Flags().StringVar(&backupDir, "backup_dir", "", "backup directory")
+	RootCmd.AddCommand(restoreCmd)
+}
+
+func runBackup(cmd *cobra.Command, args []string) {
+	endpoint := backupRPCEndpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("http://localhost:%v/rpc", viper.GetString(common.CfgRPCPort))
+	}
+	if backupDir == "" {
+		backupDir = path.Join(cfgPath, "backup", "db", time.Now().UTC().Format("2006-01-02-150405"))
+	}
+
+	// The node might run in a different working directory
+	dir, err := filepath.Abs(backupDir)
+	if err != nil {
+		exitWithError("Invalid backup directory: %v", err)
+	}
+	baseDir := ""
+	if backupBaseDir != "" {
+		if baseDir, err = filepath.Abs(backupBaseDir); err != nil {
+			exitWithError("Invalid base backup directory: %v", err)
+		}
+	}
+
+	client := rpcc.NewRPCClient(endpoint)
+	res, err := client.Call("theta.BackupDB", rpc.BackupDBArgs{BackupDir: dir, BaseDir: baseDir})
+	if err != nil {
+		exitWithError("Failed to start the backup: %v", err)
+	}
+	if res.Error != nil {
+		exitWithError("Failed to start the backup: %v", res.Error)
+	}
+	fmt.Printf("Backing up the database to %v...\n", dir)
+
+	status := &rpc.GetDBBackupStatusResult{}
+	for {
+		time.Sleep(5 * time.Second)
+
+		res, err := client.Call("theta.GetDBBackupStatus", rpc.GetDBBackupStatusArgs{})
+		if err != nil {
+			exitWithError("Failed to get the backup status: %v", err)
+		}
+		if res.Error != nil {
+			exitWithError("Failed to get the backup status: %v", res.Error)
+		}
+		if err := res.GetObject(status); err != nil {
+			exitWithError("Failed to parse the backup status: %v", err)
+		}
+		if !status.Running {
+			break
+		}
+	}
+	if status.Error != "" {
+		exitWithError("Backup failed: %v", status.Error)
+	}
+
+	s, err := json.MarshalIndent(status, "", "    ")
+	if err != nil {
+		exitWithError("Failed to parse the backup status: %v", err)
+	}
+	fmt.Println(string(s))
+}
+
+func runRestore(cmd *cobra.Command, args []string) {
+	if backupDir == "" {
+		exitWithError("--backup_dir is required")
+	}
+
+	layout := node.NewDataLayout(cfgPath)
+	if err := backend.RestoreBackup(backupDir, layout.MainDBPath(), layout.RefDBPath()); err != nil {
+		exitWithError("Restore failed: %v", err)
+	}
+	fmt.Printf("Database restored to %v\n", layout.DB)
+}
+
+func exitWithError(format string, args ...interface{}) {
+	fmt.Printf(format+"\n", args...)
+	os.Exit(1)
+}
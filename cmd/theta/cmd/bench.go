@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"runtime/pprof"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/snapshot"
+)
+
+var benchChainPath string
+var benchIterations int
+var benchCPUProfilePath string
+var benchMemProfilePath string
+
+// benchCmd represents the bench command
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Performance benchmarks",
+}
+
+// benchImportCmd replays a captured block range and measures the block execution
+// Example:
+//		theta bench import --config=../privatenet/node --snapshot=./theta_snapshot-1000 --chain=./theta_chain-1000-2000
+var benchImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Benchmark the import of a captured block range",
+	Long: `Replay a captured block range against a fresh state a number of times, and report the txs/sec, the state
+commit time, the signature verification time and the allocations of the block execution. The range is captured
+as the snapshot of the state at its start and the chain backup of the following blocks, e.g. exported with
+"thetacli backup snapshot" and "thetacli backup chain". Comparing the median run between two builds tells
+whether a change made the ledger slower.`,
+	Run: runBenchImport,
+}
+
+func init() {
+	benchImportCmd.Flags().StringVar(&benchChainPath, "chain", "", "chain backup with the blocks following the snapshot")
+	benchImportCmd.Flags().IntVar(&benchIterations, "iterations", 5, "number of replays")
+	benchImportCmd.Flags().StringVar(&benchCPUProfilePath, "cpuprofile", "", "write the CPU profile of the last replay to the given file")
+	benchImportCmd.Flags().StringVar(&benchMemProfilePath, "memprofile", "", "write the allocation profile of the replays to the given file")
+	benchCmd.AddCommand(benchImportCmd)
+	RootCmd.AddCommand(benchCmd)
+}
+
+func runBenchImport(cmd *cobra.Command, args []string) {
+	if len(snapshotPath) == 0 {
+		snapshotPath = path.Join(cfgPath, "snapshot")
+	}
+	if len(benchChainPath) == 0 {
+		exitWithError("The chain backup to replay is required")
+	}
+
+	snapshotBlockHeader := snapshot.LoadSnapshotCheckpointHeader(snapshotPath)
+	if snapshotBlockHeader == nil {
+		exitWithError("Failed to read the snapshot %v", snapshotPath)
+	}
+	viper.Set(common.CfgGenesisChainID, snapshotBlockHeader.ChainID)
+	forkHeights := map[string]uint64{}
+	if err := viper.UnmarshalKey(common.CfgForkHeights, &forkHeights); err != nil {
+		exitWithError("Failed to parse the fork heights: %v", err)
+	}
+	if err := core.LoadForkConfig(snapshotBlockHeader.ChainID, forkHeights); err != nil {
+		exitWithError("Failed to load the fork config: %v", err)
+	}
+
+	config := snapshot.ImportBenchmarkConfig{
+		SnapshotPath: snapshotPath,
+		ChainPath:    benchChainPath,
+		Iterations:   benchIterations,
+	}
+	if len(benchCPUProfilePath) != 0 {
+		file, err := os.Create(benchCPUProfilePath)
+		if err != nil {
+			exitWithError("Failed to create the CPU profile: %v", err)
+		}
+		defer file.Close()
+		config.CPUProfile = file
+	}
+
+	result, err := snapshot.BenchmarkImport(config)
+	if err != nil {
+		exitWithError("Import benchmark failed: %v", err)
+	}
+
+	if len(benchMemProfilePath) != 0 {
+		file, err := os.Create(benchMemProfilePath)
+		if err != nil {
+			exitWithError("Failed to create the allocation profile: %v", err)
+		}
+		defer file.Close()
+		if err := pprof.Lookup("allocs").WriteTo(file, 0); err != nil {
+			exitWithError("Failed to write the allocation profile: %v", err)
+		}
+	}
+
+	s, err := json.MarshalIndent(result, "", "    ")
+	if err != nil {
+		exitWithError("Failed to parse the benchmark result: %v", err)
+	}
+	fmt.Println(string(s))
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/node"
+	msg "github.com/thetatoken/theta/p2p/messenger"
+	msgl "github.com/thetatoken/theta/p2pl/messenger"
+)
+
+// hostedChainConfig is the config of a chain hosted in the same process as the main chain
+type hostedChainConfig struct {
+	Path    string `mapstructure:"path"`    // Directory holding the snapshot and the db of the chain
+	P2PPort int    `mapstructure:"p2pPort"` // libp2p port of the chain
+	Seeds   string `mapstructure:"seeds"`   // Comma separated libp2p seeds of the chain
+
+	ForkHeights map[string]uint64 `mapstructure:"forkHeights"` // Fork height overrides of the chain
+	Params      map[string]string `mapstructure:"params"`      // Expected genesis parameters of the chain
+}
+
+// newHostedChainNodes creates the nodes of the chains hosted in the same process as the main chain. Each
+// hosted chain has its own db, mempool, consensus engine and libp2p messenger, and its RPC requests are
+// served by the RPC server of the main chain with the chain_id parameter. Each hosted chain has its own
+// fork schedule and chain parameters, while the node key and the rest of the config are shared with the
+// main chain. Since the shadow mode is process-wide, the Mainnet cannot be hosted when it is on, otherwise
+// the hosted Mainnet would diverge from the consensus.
+func newHostedChainNodes(privKey *crypto.PrivateKey, mainNode *node.Node, ctx context.Context) ([]*node.Node, []*msgl.Messenger) {
+	configs := []hostedChainConfig{}
+	if err := viper.UnmarshalKey(common.CfgHostedChains, &configs); err != nil {
+		log.Fatalf("Failed to parse the hosted chains: %v", err)
+	}
+
+	chainIDs := map[string]bool{mainNode.Chain.ChainID: true}
+	nodes := []*node.Node{}
+	networks := []*msgl.Messenger{}
+	for _, config := range configs {
+		if config.Path == "" || config.P2PPort == 0 {
+			log.Fatalf("The path and the p2p port of the hosted chain are required: %+v", config)
+		}
+
+		layout := node.DefaultDataLayout(config.Path, config.Path)
+		db := openDB(layout)
+		snapshotPath := layout.Snapshot
+		root := loadRoot(db, snapshotPath, "", "")
+		if chainIDs[root.ChainID] {
+			log.Fatalf("Chain %v is hosted more than once", root.ChainID)
+		}
+		chainIDs[root.ChainID] = true
+		if root.ChainID == core.MainnetChainID && viper.GetBool(common.CfgShadowEnabled) {
+			log.Fatalf("Cannot host the %v in the shadow mode", core.MainnetChainID)
+		}
+		if err := core.LoadForkConfig(root.ChainID, config.ForkHeights); err != nil {
+			log.Fatalf("Failed to load the fork config of chain %v: %v", root.ChainID, err)
+		}
+		if err := loadChainParams(db, root, config.Params); err != nil {
+			log.Fatalf("Failed to load the chain parameters of chain %v: %v", root.ChainID, err)
+		}
+
+		seeds := strings.FieldsFunc(config.Seeds, func(c rune) bool {
+			return c == ','
+		})
+		network := newMessenger(privKey, seeds, config.P2PPort, false, root.ChainID, ctx)
+
+		var networkOld *msg.Messenger // The hosted chains only run on libp2p
+		n := node.NewNode(&node.Params{
+			ChainID:              root.ChainID,
+			PrivateKey:           privKey,
+			Root:                 root,
+			NetworkOld:           networkOld,
+			Network:              network,
+			DB:                   db,
+			SnapshotPath:         snapshotPath,
+			SnapshotSchedulerDir: path.Join(config.Path, "backup", "scheduled_snapshot"),
+			AncientPath:          layout.Ancient,
+		})
+		if mainNode.RPC != nil {
+			mainNode.RPC.HostChain(root.ChainID, n.RPC)
+		}
+		log.Infof("Hosting chain %v, path: %v, p2p port: %v", root.ChainID, config.Path, config.P2PPort)
+
+		nodes = append(nodes, n)
+		networks = append(networks, network)
+	}
+	return nodes, networks
+}
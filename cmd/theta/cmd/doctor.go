@@ -0,0 +1,478 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/consensus"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/node"
+	"github.com/thetatoken/theta/rlp"
+	"github.com/thetatoken/theta/rpc"
+	"github.com/thetatoken/theta/store/database/backend"
+	"github.com/thetatoken/theta/store/kvstore"
+	"github.com/thetatoken/theta/version"
+	ks "github.com/thetatoken/theta/wallet/softwallet/keystore"
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+const (
+	DoctorStatusOK   = "ok"
+	DoctorStatusWarn = "warn"
+	DoctorStatusFail = "fail"
+	DoctorStatusSkip = "skip"
+
+	doctorMaxClockDrift    = time.Second
+	doctorMinFreeDiskBytes = 50 * 1024 * 1024 * 1024 // 50 GB
+	doctorMinIOPS          = 200
+	doctorIOPSProbeWrites  = 100
+	doctorMaxSyncLagSecs   = 60
+)
+
+var doctorReportPath string
+var doctorNTPServer string
+var doctorRPCEndpoint string
+
+// doctorCmd diagnoses the common problems of a node
+// Example:
+//
+//	theta doctor --config=../privatenet/node
+//	theta doctor --config=../privatenet/node --report=doctor.json
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the node setup",
+	Long: `Check the config, the port reachability, the clock drift, the disk space and IOPS, the DB integrity, the peer
+connectivity and the sync lag of the node, and write the results into a report which can be attached to a support
+ticket. The DB integrity is only checked when the node is stopped, and the peers and the sync lag only when it is running.`,
+	Run: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().StringVar(&doctorReportPath, "report", "", "path of the JSON report (default is <config path>/doctor-<time>.json)")
+	doctorCmd.Flags().StringVar(&doctorNTPServer, "ntp_server", "", "NTP server to measure the clock drift against (default is the NTP server in the config)")
+	doctorCmd.Flags().StringVar(&doctorRPCEndpoint, "rpc_endpoint", "", "RPC endpoint of the node (default is the local RPC port in the config)")
+	RootCmd.AddCommand(doctorCmd)
+}
+
+// DoctorCheck is the result of a diagnostic check
+type DoctorCheck struct {
+	Name    string      `json:"name"`
+	Status  string      `json:"status"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// DoctorReport is the diagnostic report of a node
+type DoctorReport struct {
+	Time       time.Time              `json:"time"`
+	Version    string                 `json:"version"`
+	GitHash    string                 `json:"git_hash"`
+	OS         string                 `json:"os"`
+	Arch       string                 `json:"arch"`
+	NumCPU     int                    `json:"num_cpu"`
+	ConfigFile string                 `json:"config_file"`
+	Config     map[string]interface{} `json:"config"`
+	Checks     []*DoctorCheck         `json:"checks"`
+	Summary    map[string]int         `json:"summary"` // number of checks by status
+}
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	report := &DoctorReport{
+		Time:       time.Now().UTC(),
+		Version:    version.Version,
+		GitHash:    version.GitHash,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		NumCPU:     runtime.NumCPU(),
+		ConfigFile: viper.ConfigFileUsed(),
+		Config:     viper.AllSettings(),
+		Checks:     []*DoctorCheck{},
+		Summary:    map[string]int{},
+	}
+
+	endpoint := doctorRPCEndpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("http://localhost:%v/rpc", viper.GetString(common.CfgRPCPort))
+	}
+	client := rpcc.NewRPCClient(endpoint)
+	status, statusErr := getNodeStatus(client)
+	nodeRunning := statusErr == nil
+
+	layout := node.NewDataLayout(cfgPath)
+	// The disk checks probe the disk of the DB, or of its parent directory before the DB is created
+	diskPath := layout.DB
+	if _, err := os.Stat(diskPath); err != nil {
+		diskPath = path.Dir(layout.DB)
+	}
+
+	checks := []func() *DoctorCheck{
+		checkConfig,
+		checkKey,
+		func() *DoctorCheck { return checkPorts(nodeRunning) },
+		checkClockDrift,
+		func() *DoctorCheck { return checkDiskSpace(diskPath) },
+		func() *DoctorCheck { return checkDiskIOPS(diskPath) },
+		func() *DoctorCheck { return checkDB(layout, nodeRunning) },
+		func() *DoctorCheck { return checkPeers(client, statusErr) },
+		func() *DoctorCheck { return checkSyncLag(status, statusErr) },
+	}
+	for _, check := range checks {
+		result := check()
+		report.Checks = append(report.Checks, result)
+		report.Summary[result.Status]++
+		fmt.Printf("[%-4v] %-12v %v\n", result.Status, result.Name, result.Message)
+	}
+
+	if doctorReportPath == "" {
+		doctorReportPath = path.Join(cfgPath, fmt.Sprintf("doctor-%v.json", report.Time.Format("2006-01-02-150405")))
+	}
+	raw, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		exitWithError("Failed to encode the report: %v", err)
+	}
+	if err := ioutil.WriteFile(doctorReportPath, raw, 0600); err != nil {
+		exitWithError("Failed to write the report: %v", err)
+	}
+	fmt.Printf("\n%v ok, %v warn, %v fail, %v skip. Report written to %v\n", report.Summary[DoctorStatusOK],
+		report.Summary[DoctorStatusWarn], report.Summary[DoctorStatusFail], report.Summary[DoctorStatusSkip], doctorReportPath)
+
+	if report.Summary[DoctorStatusFail] > 0 {
+		os.Exit(1)
+	}
+}
+
+func checkConfig() *DoctorCheck {
+	check := &DoctorCheck{Name: "config", Status: DoctorStatusOK}
+	if viper.ConfigFileUsed() == "" {
+		check.Status = DoctorStatusFail
+		check.Message = fmt.Sprintf("No config file found under %v", cfgPath)
+		return check
+	}
+
+	problems := []string{}
+	ports := map[int]string{}
+	for _, key := range []string{common.CfgP2PPort, common.CfgP2PLPort, common.CfgRPCPort} {
+		port := viper.GetInt(key)
+		if !viper.IsSet(key) && key == common.CfgP2PLPort {
+			continue
+		}
+		if port <= 0 || port > 65535 {
+			problems = append(problems, fmt.Sprintf("%v is not a valid port: %v", key, viper.GetString(key)))
+			continue
+		}
+		if other, ok := ports[port]; ok {
+			problems = append(problems, fmt.Sprintf("%v and %v use the same port %v", other, key, port))
+		}
+		ports[port] = key
+	}
+	if viper.GetString(common.CfgGenesisChainID) == "" {
+		problems = append(problems, fmt.Sprintf("%v is not set", common.CfgGenesisChainID))
+	}
+	if viper.GetInt(common.CfgP2PMinNumPeers) > viper.GetInt(common.CfgP2PMaxNumPeers) {
+		problems = append(problems, fmt.Sprintf("%v is larger than %v", common.CfgP2PMinNumPeers, common.CfgP2PMaxNumPeers))
+	}
+	if viper.GetString(common.CfgP2PSeeds) == "" && viper.GetString(common.CfgLibP2PSeeds) == "" &&
+		!viper.GetBool(common.CfgP2PIsBootstrapNode) {
+		problems = append(problems, "no seeds are configured")
+	}
+	if viper.GetBool(common.CfgStorageStatePruningEnabled) &&
+		viper.GetInt(common.CfgStorageStatePruningRetainedBlocks) < int(common.CheckpointInterval) {
+		problems = append(problems, fmt.Sprintf("%v is less than a checkpoint interval", common.CfgStorageStatePruningRetainedBlocks))
+	}
+
+	if len(problems) > 0 {
+		check.Status = DoctorStatusWarn
+		check.Message = fmt.Sprintf("%v problem(s) in %v", len(problems), viper.ConfigFileUsed())
+		check.Details = problems
+		return check
+	}
+	check.Message = fmt.Sprintf("%v looks sane", viper.ConfigFileUsed())
+	return check
+}
+
+func checkKey() *DoctorCheck {
+	check := &DoctorCheck{Name: "key", Status: DoctorStatusOK}
+	keyPath := viper.GetString(common.CfgKeyPath)
+	if keyPath == "" {
+		keyPath = cfgPath
+	}
+	keysDir := path.Join(keyPath, "key")
+	keystore, err := ks.NewKeystoreEncrypted(keysDir, ks.StandardScryptN, ks.StandardScryptP)
+	if err != nil {
+		check.Status = DoctorStatusFail
+		check.Message = fmt.Sprintf("Failed to open the keystore %v: %v", keysDir, err)
+		return check
+	}
+	addresses, err := keystore.ListKeyAddresses()
+	switch {
+	case err != nil:
+		check.Status = DoctorStatusFail
+		check.Message = fmt.Sprintf("Failed to list the keys: %v", err)
+	case len(addresses) == 0:
+		check.Status = DoctorStatusWarn
+		check.Message = "No node key yet, it will be created on the first start"
+	case len(addresses) > 1:
+		check.Status = DoctorStatusFail
+		check.Message = fmt.Sprintf("Multiple keys under %v, the node needs exactly one", path.Join(keysDir, "encrypted"))
+	default:
+		check.Message = fmt.Sprintf("Node key %v", addresses[0].Hex())
+	}
+	return check
+}
+
+// checkPorts checks the ports of the node. While the node is running they should accept connections,
+// otherwise they should be free for the node to bind.
+func checkPorts(nodeRunning bool) *DoctorCheck {
+	check := &DoctorCheck{Name: "ports", Status: DoctorStatusOK}
+	keys := []string{common.CfgP2PPort, common.CfgRPCPort}
+	if viper.IsSet(common.CfgP2PLPort) {
+		keys = append(keys, common.CfgP2PLPort)
+	}
+
+	details := map[string]string{}
+	problems := 0
+	for _, key := range keys {
+		address := fmt.Sprintf("localhost:%v", viper.GetInt(key))
+		conn, err := net.DialTimeout("tcp", address, 2*time.Second)
+		listening := err == nil
+		if listening {
+			conn.Close()
+		}
+		switch {
+		case nodeRunning && listening:
+			details[key] = fmt.Sprintf("%v accepts connections", address)
+		case !nodeRunning && !listening:
+			details[key] = fmt.Sprintf("%v is free", address)
+		case nodeRunning:
+			details[key] = fmt.Sprintf("%v does not accept connections", address)
+			problems++
+		default:
+			details[key] = fmt.Sprintf("%v is in use by another process", address)
+			problems++
+		}
+	}
+	check.Details = details
+	if problems > 0 {
+		check.Status = DoctorStatusFail
+		check.Message = fmt.Sprintf("%v port(s) unavailable", problems)
+		return check
+	}
+	check.Message = fmt.Sprintf("%v port(s) available", len(keys))
+	return check
+}
+
+func checkClockDrift() *DoctorCheck {
+	check := &DoctorCheck{Name: "clock", Status: DoctorStatusOK}
+	if doctorNTPServer == "" {
+		doctorNTPServer = viper.GetString(common.CfgClockNTPServer)
+	}
+	if doctorNTPServer == "" {
+		check.Status = DoctorStatusSkip
+		check.Message = "No NTP server configured"
+		return check
+	}
+	offset, err := consensus.QueryNTPOffset(doctorNTPServer)
+	if err != nil {
+		check.Status = DoctorStatusWarn
+		check.Message = fmt.Sprintf("Failed to query %v: %v", doctorNTPServer, err)
+		return check
+	}
+	check.Details = map[string]string{"server": doctorNTPServer, "offset": offset.String()}
+	if offset < 0 {
+		offset = -offset
+	}
+	if offset > time.Duration(viper.GetInt(common.CfgSyncMaxBlockTimestampDrift))*time.Second {
+		check.Status = DoctorStatusFail
+		check.Message = fmt.Sprintf("Clock is off by %v, the blocks of the node will be rejected", offset)
+	} else if offset > doctorMaxClockDrift {
+		check.Status = DoctorStatusWarn
+		check.Message = fmt.Sprintf("Clock is off by %v, please enable the time synchronization", offset)
+	} else {
+		check.Message = fmt.Sprintf("Clock is off by %v", offset)
+	}
+	return check
+}
+
+func checkDiskSpace(dataPath string) *DoctorCheck {
+	check := &DoctorCheck{Name: "disk_space", Status: DoctorStatusOK}
+	free, total, err := diskUsage(dataPath)
+	if err != nil {
+		check.Status = DoctorStatusSkip
+		check.Message = fmt.Sprintf("Failed to get the disk usage of %v: %v", dataPath, err)
+		return check
+	}
+	check.Details = map[string]uint64{"free_bytes": free, "total_bytes": total}
+	check.Message = fmt.Sprintf("%.1f GB free of %.1f GB", float64(free)/1e9, float64(total)/1e9)
+	if free < doctorMinFreeDiskBytes {
+		check.Status = DoctorStatusWarn
+	}
+	return check
+}
+
+// checkDiskIOPS measures the synced write IOPS of the data directory, which bounds the block commit rate
+func checkDiskIOPS(dataPath string) *DoctorCheck {
+	check := &DoctorCheck{Name: "disk_iops", Status: DoctorStatusOK}
+	file, err := ioutil.TempFile(dataPath, "doctor-iops-")
+	if err != nil {
+		check.Status = DoctorStatusSkip
+		check.Message = fmt.Sprintf("Failed to create a probe file in %v: %v", dataPath, err)
+		return check
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	block := make([]byte, 4096)
+	start := time.Now()
+	for i := 0; i < doctorIOPSProbeWrites; i++ {
+		if _, err := file.WriteAt(block, int64(i*len(block))); err != nil {
+			check.Status = DoctorStatusSkip
+			check.Message = fmt.Sprintf("Failed to write the probe file: %v", err)
+			return check
+		}
+		if err := file.Sync(); err != nil {
+			check.Status = DoctorStatusSkip
+			check.Message = fmt.Sprintf("Failed to sync the probe file: %v", err)
+			return check
+		}
+	}
+	iops := int(float64(doctorIOPSProbeWrites) / time.Since(start).Seconds())
+	check.Details = map[string]int{"synced_write_iops": iops}
+	check.Message = fmt.Sprintf("%v synced writes per second", iops)
+	if iops < doctorMinIOPS {
+		check.Status = DoctorStatusWarn
+		check.Message += ", the node might fall behind the chain"
+	}
+	return check
+}
+
+// checkDB checks that the DB opens and that the snapshot root block and its state are in place
+func checkDB(layout *node.DataLayout, nodeRunning bool) *DoctorCheck {
+	check := &DoctorCheck{Name: "db", Status: DoctorStatusOK}
+	if nodeRunning {
+		check.Status = DoctorStatusSkip
+		check.Message = "The node is running and holds the DB lock, stop it to check the DB"
+		return check
+	}
+
+	mainDBPath := layout.MainDBPath()
+	if _, err := os.Stat(mainDBPath); os.IsNotExist(err) {
+		check.Status = DoctorStatusSkip
+		check.Message = fmt.Sprintf("No DB under %v yet", mainDBPath)
+		return check
+	}
+	db, err := backend.NewLDBDatabase(mainDBPath, layout.RefDBPath(),
+		viper.GetInt(common.CfgStorageLevelDBCacheSize), viper.GetInt(common.CfgStorageLevelDBHandles))
+	if err != nil {
+		check.Status = DoctorStatusFail
+		check.Message = fmt.Sprintf("Failed to open the DB: %v", err)
+		return check
+	}
+	defer db.Close()
+
+	raw, err := db.Get([]byte("/snapshot_blockheader"))
+	if err != nil {
+		check.Status = DoctorStatusWarn
+		check.Message = "No snapshot loaded into the DB yet"
+		return check
+	}
+	header := &core.BlockHeader{}
+	if err := rlp.DecodeBytes(raw, header); err != nil {
+		check.Status = DoctorStatusFail
+		check.Message = fmt.Sprintf("Failed to decode the snapshot header: %v", err)
+		return check
+	}
+	rootHash := header.Hash()
+	root := core.ExtendedBlock{}
+	if err := kvstore.NewKVStore(db).Get(rootHash[:], &root); err != nil {
+		check.Status = DoctorStatusFail
+		check.Message = fmt.Sprintf("Snapshot root block %v is missing: %v", rootHash.Hex(), err)
+		return check
+	}
+	if ok, err := db.Has(root.StateHash[:]); err != nil || !ok {
+		check.Status = DoctorStatusFail
+		check.Message = fmt.Sprintf("State root %v of the snapshot is missing", root.StateHash.Hex())
+		return check
+	}
+	check.Message = fmt.Sprintf("Snapshot root block %v at height %v and its state are intact", rootHash.Hex(), root.Height)
+	return check
+}
+
+func checkPeers(client *rpcc.RPCClient, statusErr error) *DoctorCheck {
+	check := &DoctorCheck{Name: "peers", Status: DoctorStatusOK}
+	if statusErr != nil {
+		check.Status = DoctorStatusSkip
+		check.Message = fmt.Sprintf("The node is not reachable over RPC: %v", statusErr)
+		return check
+	}
+	res, err := client.Call("theta.GetPeers", rpc.GetPeersArgs{})
+	if err == nil && res.Error != nil {
+		err = res.Error
+	}
+	peers := &rpc.GetPeersResult{}
+	if err == nil {
+		err = res.GetObject(peers)
+	}
+	if err != nil {
+		check.Status = DoctorStatusFail
+		check.Message = fmt.Sprintf("Failed to get the peers: %v", err)
+		return check
+	}
+
+	numPeers := len(peers.Peers)
+	check.Details = peers.Peers
+	check.Message = fmt.Sprintf("%v peer(s) connected", numPeers)
+	if numPeers == 0 {
+		check.Status = DoctorStatusFail
+		check.Message += ", please check the seeds and the firewall"
+	} else if numPeers < viper.GetInt(common.CfgP2PMinNumPeers) {
+		check.Status = DoctorStatusWarn
+		check.Message += fmt.Sprintf(", fewer than %v", viper.GetInt(common.CfgP2PMinNumPeers))
+	}
+	return check
+}
+
+func checkSyncLag(status *rpc.GetStatusResult, statusErr error) *DoctorCheck {
+	check := &DoctorCheck{Name: "sync", Status: DoctorStatusOK}
+	if statusErr != nil {
+		check.Status = DoctorStatusSkip
+		check.Message = fmt.Sprintf("The node is not reachable over RPC: %v", statusErr)
+		return check
+	}
+	check.Details = status
+
+	lag := int64(0)
+	if status.CurrentTime != nil && status.LatestFinalizedBlockTime != nil {
+		lag = new(big.Int).Sub((*big.Int)(status.CurrentTime), (*big.Int)(status.LatestFinalizedBlockTime)).Int64()
+	}
+	check.Message = fmt.Sprintf("Finalized height %v, %v seconds behind", uint64(status.LatestFinalizedBlockHeight), lag)
+	if status.Syncing || lag > doctorMaxSyncLagSecs {
+		check.Status = DoctorStatusWarn
+		check.Message += ", still syncing"
+	}
+	return check
+}
+
+func getNodeStatus(client *rpcc.RPCClient) (*rpc.GetStatusResult, error) {
+	res, err := client.Call("theta.GetStatus", rpc.GetStatusArgs{})
+	if err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	status := &rpc.GetStatusResult{}
+	if err := res.GetObject(status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
@@ -0,0 +1,14 @@
+// +build !windows
+
+package cmd
+
+import "syscall"
+
+// diskUsage returns the free and the total bytes of the file system holding the path
+func diskUsage(path string) (free uint64, total uint64, err error) {
+	var stat syscall.Statfs_t
+	if err = syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), stat.Blocks * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,10 @@
+// +build windows
+
+package cmd
+
+import "errors"
+
+// diskUsage returns the free and the total bytes of the file system holding the path
+func diskUsage(path string) (free uint64, total uint64, err error) {
+	return 0, 0, errors.New("Not implemented")
+}
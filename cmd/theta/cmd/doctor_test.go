@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/node"
+	"github.com/thetatoken/theta/rpc"
+	"github.com/thetatoken/theta/store/database/backend"
+	ks "github.com/thetatoken/theta/wallet/softwallet/keystore"
+)
+
+func TestDoctorCheckConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	defer viper.SetConfigFile(viper.ConfigFileUsed())
+	for _, key := range []string{common.CfgP2PPort, common.CfgRPCPort, common.CfgGenesisChainID, common.CfgP2PSeeds} {
+		defer viper.Set(key, viper.Get(key))
+	}
+
+	viper.SetConfigFile("")
+	assert.Equal(DoctorStatusFail, checkConfig().Status)
+
+	viper.SetConfigFile("/tmp/config.yaml")
+	viper.Set(common.CfgP2PPort, 12000)
+	viper.Set(common.CfgRPCPort, 16888)
+	viper.Set(common.CfgGenesisChainID, "privatenet")
+	viper.Set(common.CfgP2PSeeds, "127.0.0.1:12001")
+	check := checkConfig()
+	assert.Equal(DoctorStatusOK, check.Status, check.Details)
+
+	// Port clashes and a missing chain ID are reported together
+	viper.Set(common.CfgRPCPort, 12000)
+	viper.Set(common.CfgGenesisChainID, "")
+	check = checkConfig()
+	assert.Equal(DoctorStatusWarn, check.Status)
+	assert.Equal(2, len(check.Details.([]string)))
+}
+
+func TestDoctorCheckKey(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	keyPath, err := ioutil.TempDir("", "doctor_key")
+	require.Nil(err)
+	defer os.RemoveAll(keyPath)
+	defer viper.Set(common.CfgKeyPath, viper.GetString(common.CfgKeyPath))
+	viper.Set(common.CfgKeyPath, keyPath)
+
+	// The node key is created on the first start
+	assert.Equal(DoctorStatusWarn, checkKey().Status)
+
+	keystore, err := ks.NewKeystoreEncrypted(path.Join(keyPath, "key"), ks.LightScryptN, ks.LightScryptP)
+	require.Nil(err)
+	addKey := func() *crypto.PrivateKey {
+		key, _, err := crypto.GenerateKeyPair()
+		require.Nil(err)
+		require.Nil(keystore.StoreKey(ks.NewKey(key), "qwertyuiop"))
+		return key
+	}
+
+	key := addKey()
+	check := checkKey()
+	assert.Equal(DoctorStatusOK, check.Status)
+	assert.Contains(check.Message, key.PublicKey().Address().Hex())
+
+	addKey()
+	assert.Equal(DoctorStatusFail, checkKey().Status)
+}
+
+func TestDoctorCheckDB(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dataPath, err := ioutil.TempDir("", "doctor_db")
+	require.Nil(err)
+	defer os.RemoveAll(dataPath)
+	layout := node.DefaultDataLayout(dataPath, dataPath)
+
+	assert.Equal(DoctorStatusSkip, checkDB(layout, false).Status)
+
+	db, err := backend.NewLDBDatabase(layout.MainDBPath(), layout.RefDBPath(), 16, 16)
+	require.Nil(err)
+	db.Close()
+
+	// The running node holds the DB lock
+	assert.Equal(DoctorStatusSkip, checkDB(layout, true).Status)
+
+	// The DB opens, but no snapshot is loaded yet
+	assert.Equal(DoctorStatusWarn, checkDB(layout, false).Status)
+
+	db, err = backend.NewLDBDatabase(layout.MainDBPath(), layout.RefDBPath(), 16, 16)
+	require.Nil(err)
+	require.Nil(db.Put([]byte("/snapshot_blockheader"), []byte("garbage")))
+	db.Close()
+	assert.Equal(DoctorStatusFail, checkDB(layout, false).Status)
+}
+
+func TestDoctorCheckSyncLag(t *testing.T) {
+	assert := assert.New(t)
+
+	status := &rpc.GetStatusResult{
+		LatestFinalizedBlockHeight: 100,
+		LatestFinalizedBlockTime:   (*common.JSONBig)(big.NewInt(1000)),
+		CurrentTime:                (*common.JSONBig)(big.NewInt(1010)),
+	}
+	assert.Equal(DoctorStatusOK, checkSyncLag(status, nil).Status)
+
+	status.CurrentTime = (*common.JSONBig)(big.NewInt(1000 + doctorMaxSyncLagSecs + 1))
+	assert.Equal(DoctorStatusWarn, checkSyncLag(status, nil).Status)
+
+	status.CurrentTime = (*common.JSONBig)(big.NewInt(1010))
+	status.Syncing = true
+	assert.Equal(DoctorStatusWarn, checkSyncLag(status, nil).Status)
+
+	assert.Equal(DoctorStatusSkip, checkSyncLag(nil, os.ErrNotExist).Status)
+}
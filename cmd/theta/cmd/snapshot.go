@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/node"
+	"github.com/thetatoken/theta/snapshot"
+)
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Snapshot utilities",
+}
+
+// snapshotVerifyCmd verifies a snapshot file without importing it
+// Example:
+//		theta snapshot verify --config=../privatenet/node --snapshot=../privatenet/node/snapshot
+var snapshotVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the integrity of a snapshot file before importing it",
+	Run:   runSnapshotVerify,
+}
+
+func init() {
+	snapshotCmd.AddCommand(snapshotVerifyCmd)
+	RootCmd.AddCommand(snapshotCmd)
+}
+
+func runSnapshotVerify(cmd *cobra.Command, args []string) {
+	if len(snapshotPath) == 0 {
+		snapshotPath = node.NewDataLayout(cfgPath).Snapshot
+	}
+
+	verification, err := snapshot.VerifySnapshot(snapshotPath)
+	if err != nil {
+		fmt.Printf("Snapshot verification failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	s, err := json.MarshalIndent(verification, "", "    ")
+	if err != nil {
+		fmt.Printf("Failed to parse the verification result: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(s))
+}
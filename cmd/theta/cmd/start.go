@@ -10,6 +10,7 @@ import (
 	"path"
 	"runtime"
 	"strings"
+	"syscall"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -17,14 +18,18 @@ import (
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/autotune"
+	"github.com/thetatoken/theta/common/membudget"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/node"
 	msg "github.com/thetatoken/theta/p2p/messenger"
 	msgl "github.com/thetatoken/theta/p2pl/messenger"
 	"github.com/thetatoken/theta/rlp"
 	"github.com/thetatoken/theta/snapshot"
+	"github.com/thetatoken/theta/store/database"
 	"github.com/thetatoken/theta/store/database/backend"
 	"github.com/thetatoken/theta/version"
 	ks "github.com/thetatoken/theta/wallet/softwallet/keystore"
@@ -51,65 +56,52 @@ func runStart(cmd *cobra.Command, args []string) {
 		log.Fatalf("Failed to load or create key: %v", err)
 	}
 
-	// Open database
-	dbPath := viper.GetString(common.CfgDataPath)
-	if dbPath == "" {
-		dbPath = cfgPath
+	layout := node.NewDataLayout(cfgPath)
+	if len(snapshotPath) != 0 {
+		layout.Snapshot = snapshotPath // the --snapshot flag takes precedence over the config
 	}
+	if err := layout.Prepare(); err != nil {
+		log.Fatalf("Failed to create the data directories: %v", err)
+	}
+	if err := layout.RedirectLogs(); err != nil {
+		log.Fatalf("Failed to open the log file: %v", err)
+	}
+	log.Infof("Data layout: db: %v, ancient: %v, snapshot: %v, logs: %v", layout.DB, layout.Ancient, layout.Snapshot, layout.LogFilePath())
 
-	mainDBPath := path.Join(dbPath, "db", "main")
-	refDBPath := path.Join(dbPath, "db", "ref")
-	db, err := backend.NewLDBDatabase(mainDBPath, refDBPath,
-		viper.GetInt(common.CfgStorageLevelDBCacheSize),
-		viper.GetInt(common.CfgStorageLevelDBHandles))
-
-	if err != nil {
-		log.Fatalf("Failed to connect to the db. main: %v, ref: %v, err: %v",
-			mainDBPath, refDBPath, err)
+	// Size the caches, limits and workers for the host before they are created
+	if viper.GetBool(common.CfgAutoTuneEnabled) {
+		autotune.Tune(autotune.DetectResources())
 	}
 
+	// Open database
+	db := openDB(layout)
+
 	// load snapshot
-	if len(snapshotPath) == 0 {
-		snapshotPath = path.Join(cfgPath, "snapshot")
-	}
+	snapshotPath = layout.Snapshot
+	root := loadRoot(db, snapshotPath, chainImportDirPath, chainCorrectionPath)
 
-	var root *core.Block
-	var snapshotBlockHeader *core.BlockHeader
-	dbSnapshotHeader := &core.BlockHeader{}
-	skipLoadSnapshot := false
+	viper.Set(common.CfgGenesisChainID, root.ChainID)
 
-	// Read last verified snapshot header from db and compare with current snapshot
-	raw, err := db.Get([]byte("/snapshot_blockheader"))
-	if err == nil {
-		err = rlp.DecodeBytes(raw, dbSnapshotHeader)
-		if err == nil {
-			snapshotBlockHeader = snapshot.LoadSnapshotCheckpointHeader(snapshotPath)
-			if snapshotBlockHeader.Hash() == dbSnapshotHeader.Hash() {
-				// snapshot has already been loaded into db
-				skipLoadSnapshot = true
-			}
-		}
+	forkHeights := map[string]uint64{}
+	if err := viper.UnmarshalKey(common.CfgForkHeights, &forkHeights); err != nil {
+		log.Fatalf("Failed to parse the fork heights: %v", err)
 	}
-	if skipLoadSnapshot && !viper.GetBool(common.CfgForceValidateSnapshot) {
-		log.Println("Skip validating snapshot")
+	if viper.GetBool(common.CfgShadowEnabled) {
+		err = core.LoadShadowForkConfig(root.ChainID, forkHeights)
 	} else {
-		snapshotBlockHeader, err = snapshot.ValidateSnapshot(snapshotPath, chainImportDirPath, chainCorrectionPath)
-		if err != nil {
-			log.Fatalf("Snapshot validation failed, err: %v", err)
-		}
-
-		raw, err := rlp.EncodeToBytes(snapshotBlockHeader)
-		if err == nil {
-			err = db.Put([]byte("/snapshot_blockheader"), raw)
-			if err != nil {
-				log.Errorf("Failed to save snapshot validation result: %v", err)
-			}
-		}
+		err = core.LoadForkConfig(root.ChainID, forkHeights)
+	}
+	if err != nil {
+		log.Fatalf("Failed to load the fork config: %v", err)
 	}
 
-	root = &core.Block{BlockHeader: snapshotBlockHeader}
-
-	viper.Set(common.CfgGenesisChainID, root.ChainID)
+	chainParams := map[string]string{}
+	if err := viper.UnmarshalKey(common.CfgGenesisParams, &chainParams); err != nil {
+		log.Fatalf("Failed to parse the chain parameters: %v", err)
+	}
+	if err := loadChainParams(db, root, chainParams); err != nil {
+		log.Fatalf("Failed to load the chain parameters: %v", err)
+	}
 
 	// Parse seeds and filter out empty item.
 	f := func(c rune) bool {
@@ -124,12 +116,12 @@ func runStart(cmd *cobra.Command, args []string) {
 		port := viper.GetInt(common.CfgP2PLPort)
 		peerSeeds := strings.FieldsFunc(viper.GetString(common.CfgLibP2PSeeds), f)
 		seedPeerOnly := viper.GetBool(common.CfgP2PSeedPeerOnly)
-		network = newMessenger(privKey, peerSeeds, port, seedPeerOnly, ctx)
+		network = newMessenger(privKey, peerSeeds, port, seedPeerOnly, "", ctx)
 	}
 	if p2pOpt != common.P2POptLibp2p {
 		portOld := viper.GetInt(common.CfgP2PPort)
 		peerSeedsOld := strings.FieldsFunc(viper.GetString(common.CfgP2PSeeds), f)
-		networkOld = newMessengerOld(privKey, peerSeedsOld, portOld, ctx)
+		networkOld = newMessengerOld(privKey, peerSeedsOld, portOld, root.ChainID, ctx)
 	}
 
 	params := &node.Params{
@@ -142,24 +134,22 @@ func runStart(cmd *cobra.Command, args []string) {
 		SnapshotPath:        snapshotPath,
 		ChainImportDirPath:  chainImportDirPath,
 		ChainCorrectionPath: chainCorrectionPath,
+		AncientPath:         layout.Ancient,
 	}
 
 	n := node.NewNode(params)
+	if networkOld != nil {
+		networkOld.SetPeerRoleResolver(n.PeerRoleResolver)
+	}
+	hostedNodes, hostedNetworks := newHostedChainNodes(privKey, n, ctx)
 
-	c := make(chan os.Signal)
-	signal.Notify(c, os.Interrupt)
-	done := make(chan struct{})
-	go func() {
-		<-c
-		signal.Stop(c)
-		cancel()
-		network.Stop()
-		// Wait at most 5 seconds before forcefully shutting down.
-		<-time.After(time.Duration(5) * time.Second)
-		close(done)
-	}()
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	n.Start(ctx)
+	for _, hn := range hostedNodes {
+		hn.Start(ctx)
+	}
 
 	if viper.GetBool(common.CfgProfEnabled) {
 		go func() {
@@ -171,17 +161,123 @@ func runStart(cmd *cobra.Command, args []string) {
 		go memoryCleanupRoutine()
 	}
 
+	if viper.GetBool(common.CfgAutoTuneEnabled) {
+		go autotune.Run(ctx)
+	}
+
+	stopped := make(chan struct{})
 	go func() {
 		n.Wait()
-		close(done)
+		for _, hn := range hostedNodes {
+			hn.Wait()
+		}
+		close(stopped)
 	}()
 
-	<-done
+	select {
+	case <-c:
+		signal.Stop(c)
+		timeout := time.Duration(viper.GetInt(common.CfgNodeShutdownTimeoutSecs)) * time.Second
+		log.Infof("Shutting down, timeout: %v", timeout)
+		if !n.Shutdown(timeout) {
+			log.Warnf("Failed to shut down gracefully within %v, exiting", timeout)
+		}
+		for _, hn := range hostedNodes {
+			if !hn.Shutdown(timeout) {
+				log.Warnf("Failed to shut down chain %v gracefully within %v", hn.Chain.ChainID, timeout)
+			}
+		}
+	case <-stopped:
+	}
+
+	// Cancelling ctx stops all the node sub components at once, so it is done after the node shuts down
+	cancel()
+	if network != nil {
+		network.Stop()
+	}
+	if networkOld != nil {
+		networkOld.Stop()
+	}
+	for _, hostedNetwork := range hostedNetworks {
+		hostedNetwork.Stop()
+	}
+
 	log.Infof("")
 	log.Infof("Graceful exit.")
 	printExitBanner()
 }
 
+// openDB opens the main db and the reference db of the given data layout
+func openDB(layout *node.DataLayout) *backend.LDBDatabase {
+	mainDBPath := layout.MainDBPath()
+	refDBPath := layout.RefDBPath()
+	db, err := backend.NewLDBDatabase(mainDBPath, refDBPath,
+		membudget.TrieCacheSizeMB(viper.GetInt(common.CfgStorageLevelDBCacheSize)),
+		viper.GetInt(common.CfgStorageLevelDBHandles))
+
+	if err != nil {
+		log.Fatalf("Failed to connect to the db. main: %v, ref: %v, err: %v",
+			mainDBPath, refDBPath, err)
+	}
+	return db
+}
+
+// loadRoot validates the snapshot unless it has already been loaded into the db, and returns the
+// snapshot block as the root block of the chain
+func loadRoot(db database.Database, snapshotPath, chainImportDirPath, chainCorrectionPath string) *core.Block {
+	var snapshotBlockHeader *core.BlockHeader
+	dbSnapshotHeader := &core.BlockHeader{}
+	skipLoadSnapshot := false
+
+	// Read last verified snapshot header from db and compare with current snapshot
+	raw, err := db.Get([]byte("/snapshot_blockheader"))
+	if err == nil {
+		err = rlp.DecodeBytes(raw, dbSnapshotHeader)
+		if err == nil {
+			snapshotBlockHeader = snapshot.LoadSnapshotCheckpointHeader(snapshotPath)
+			if snapshotBlockHeader.Hash() == dbSnapshotHeader.Hash() {
+				// snapshot has already been loaded into db
+				skipLoadSnapshot = true
+			}
+		}
+	}
+	if skipLoadSnapshot && !viper.GetBool(common.CfgForceValidateSnapshot) {
+		log.Println("Skip validating snapshot")
+	} else {
+		snapshotBlockHeader, err = snapshot.ValidateSnapshot(snapshotPath, chainImportDirPath, chainCorrectionPath)
+		if err != nil {
+			log.Fatalf("Snapshot validation failed, err: %v", err)
+		}
+
+		raw, err := rlp.EncodeToBytes(snapshotBlockHeader)
+		if err == nil {
+			err = db.Put([]byte("/snapshot_blockheader"), raw)
+			if err != nil {
+				log.Errorf("Failed to save snapshot validation result: %v", err)
+			}
+		}
+	}
+
+	return &core.Block{BlockHeader: snapshotBlockHeader}
+}
+
+// loadChainParams loads the chain parameters committed to the genesis state, which is carried over to the
+// state of the root block. The configured parameters are optional, if set, they need to match the ones of
+// the genesis, so that a node whose config diverges from the chain refuses to start instead of forking.
+func loadChainParams(db database.Database, root *core.Block, configured map[string]string) error {
+	params := state.NewStoreView(root.Height, root.StateHash, db).GetChainParams()
+	if len(configured) > 0 {
+		expected, err := core.ParseChainParams(configured)
+		if err != nil {
+			return err
+		}
+		if !expected.Equal(params) {
+			return fmt.Errorf("the configured chain parameters %v do not match the ones of the genesis %v", expected, params)
+		}
+	}
+	return core.LoadChainParams(root.ChainID, params)
+}
+
 func loadOrCreateKey() (*crypto.PrivateKey, error) {
 	keyPath := viper.GetString(common.CfgKeyPath)
 	if keyPath == "" {
@@ -277,12 +373,18 @@ func loadOrCreateKey() (*crypto.PrivateKey, error) {
 	return nodePrivKey, nil
 }
 
-func newMessenger(privKey *crypto.PrivateKey, seedPeerNetAddresses []string, port int, seedPeerOnly bool, ctx context.Context) *msgl.Messenger {
+func newMessenger(privKey *crypto.PrivateKey, seedPeerNetAddresses []string, port int, seedPeerOnly bool, chainID string, ctx context.Context) *msgl.Messenger {
 	log.WithFields(log.Fields{
 		"pubKey":  fmt.Sprintf("%v", privKey.PublicKey().ToBytes()),
 		"address": fmt.Sprintf("%v", privKey.PublicKey().Address()),
 	}).Info("Using key:")
 	msgrConfig := msgl.GetDefaultMessengerConfig()
+	if err := msgrConfig.SetTransports(viper.GetString(common.CfgLibP2PTransports)); err != nil {
+		log.WithFields(log.Fields{"err": err}).Fatal("Invalid libp2p transports.")
+	}
+	if chainID != "" {
+		msgrConfig.SetChainID(chainID)
+	}
 	messenger, err := msgl.CreateMessenger(privKey.PublicKey(), seedPeerNetAddresses, port, seedPeerOnly, msgrConfig, true, ctx)
 	if err != nil {
 		log.WithFields(log.Fields{"err": err}).Fatal("Failed to create Messenger instance.")
@@ -290,13 +392,14 @@ func newMessenger(privKey *crypto.PrivateKey, seedPeerNetAddresses []string, por
 	return messenger
 }
 
-func newMessengerOld(privKey *crypto.PrivateKey, seedPeerNetAddresses []string, port int, ctx context.Context) *msg.Messenger {
+func newMessengerOld(privKey *crypto.PrivateKey, seedPeerNetAddresses []string, port int, chainID string, ctx context.Context) *msg.Messenger {
 	log.WithFields(log.Fields{
 		"pubKey":  fmt.Sprintf("%v", privKey.PublicKey().ToBytes()),
 		"address": fmt.Sprintf("%v", privKey.PublicKey().Address()),
 	}).Info("Using key")
 	msgrConfig := msg.GetDefaultMessengerConfig()
 	msgrConfig.SetAddressBookFilePath(path.Join(cfgPath, "addrbook.json"))
+	msgrConfig.SetChainID(chainID)
 	messenger, err := msg.CreateMessenger(privKey, seedPeerNetAddresses, port, msgrConfig)
 	if err != nil {
 		log.WithFields(log.Fields{"err": err}).Fatal("Failed to create Messenger instance")
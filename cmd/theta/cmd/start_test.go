@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestLoadChainParams(t *testing.T) {
+	assert := assert.New(t)
+
+	chainID := "privatenet"
+	defer core.LoadChainParams(chainID, nil)
+
+	db := backend.NewMemDatabase()
+	sv := state.NewStoreView(0, common.Hash{}, db)
+	sv.SetChainParams(core.ChainParams{core.ChainParamMinValidatorStake: big.NewInt(1e18)})
+	root := &core.Block{BlockHeader: &core.BlockHeader{ChainID: chainID, StateHash: sv.Save()}}
+
+	// The parameters are loaded from the genesis state, the config is optional
+	assert.Nil(loadChainParams(db, root, nil))
+	assert.Equal(big.NewInt(1e18), core.MinValidatorStake(chainID))
+	assert.Nil(loadChainParams(db, root, map[string]string{core.ChainParamMinValidatorStake: "1000000000000000000"}))
+
+	// The node refuses to start if its config differs from the genesis
+	assert.NotNil(loadChainParams(db, root, map[string]string{core.ChainParamMinValidatorStake: "1"}))
+	assert.NotNil(loadChainParams(db, root, map[string]string{
+		core.ChainParamMinValidatorStake: "1000000000000000000",
+		core.ChainParamMinGasPrice:       "1",
+	}))
+
+	// A chain without parameters in its genesis takes the Mainnet values
+	mainnetRoot := &core.Block{BlockHeader: &core.BlockHeader{ChainID: core.MainnetChainID, StateHash: state.NewStoreView(0, common.Hash{}, db).Save()}}
+	assert.Nil(loadChainParams(db, mainnetRoot, nil))
+	assert.NotNil(loadChainParams(db, mainnetRoot, map[string]string{core.ChainParamMinValidatorStake: "1"}))
+}
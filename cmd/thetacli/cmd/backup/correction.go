@@ -1,16 +1,12 @@
 package backup
 
 import (
-	"encoding/json"
-	"fmt"
-
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/rpc"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 var (
@@ -29,7 +25,7 @@ var chainCorrectionCmd = &cobra.Command{
 }
 
 func doChainCorrectionCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
 	res, err := client.Call("theta.BackupChainCorrection", rpc.BackupChainCorrectionArgs{SnapshotHeight: heightFlag, EndBlockHash: common.HexToHash(hashFlag), Config: configFlag, ExclusionTxs: exclusionTxsFlag})
 	if err != nil {
@@ -38,11 +34,7 @@ func doChainCorrectionCmd(cmd *cobra.Command, args []string) {
 	if res.Error != nil {
 		utils.Error("Failed to get backup chain res details: %v\n", res.Error)
 	}
-	json, err := json.MarshalIndent(res.Result, "", "    ")
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n%v\n", err, string(json))
-	}
-	fmt.Println(string(json))
+	utils.PrintResult(res.Result)
 }
 
 func init() {
@@ -1,15 +1,11 @@
 package backup
 
 import (
-	"encoding/json"
-	"fmt"
-
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/rpc"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // snapshotCmd represents the snapshot backup command.
@@ -24,7 +20,7 @@ var snapshotCmd = &cobra.Command{
 }
 
 func doSnapshotCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
 	res, err := client.Call("theta.BackupSnapshot", rpc.BackupSnapshotArgs{Config: configFlag, Height: heightFlag, Version: versionFlag})
 	if err != nil {
@@ -33,11 +29,7 @@ func doSnapshotCmd(cmd *cobra.Command, args []string) {
 	if res.Error != nil {
 		utils.Error("Failed to get backup snapshot res details: %v\n", res.Error)
 	}
-	json, err := json.MarshalIndent(res.Result, "", "    ")
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n%v\n", err, string(json))
-	}
-	fmt.Println(string(json))
+	utils.PrintResult(res.Result)
 }
 
 func init() {
@@ -2,13 +2,11 @@ package call
 
 import (
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"math/big"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
@@ -77,14 +75,14 @@ func doSmartContractCmd(cmd *cobra.Command, args []string) {
 		utils.Error("Failed to encode smart contract transaction: %v\n", sctx)
 	}
 	if verboseFlag {
-		fmt.Printf("Encoded Tx: %x\n\n", sctxBytes)
+		utils.Progress("Encoded Tx: %x\n\n", sctxBytes)
 	}
 
 	rpcCallArgs := rpc.CallSmartContractArgs{
 		SctxBytes: hex.EncodeToString(sctxBytes),
 	}
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
 	res, err := client.Call("theta.CallSmartContract", rpcCallArgs)
 	if err != nil {
@@ -93,11 +91,7 @@ func doSmartContractCmd(cmd *cobra.Command, args []string) {
 	if res.Error != nil {
 		utils.Error("Failed to execute smart contract: %v\n", res.Error)
 	}
-	json, err := json.MarshalIndent(res.Result, "", "    ")
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n%s\n", err, string(json))
-	}
-	fmt.Println(string(json))
+	utils.PrintResult(res.Result)
 }
 
 func init() {
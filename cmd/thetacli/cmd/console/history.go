@@ -0,0 +1,89 @@
+package console
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// maxHistoryFileLines caps the size of the history file, the older lines are dropped at startup
+const maxHistoryFileLines = 1000
+
+// readHistory reads the lines of the history file. If the file grew beyond maxHistoryFileLines,
+// it is rewritten with the most recent lines.
+func readHistory(historyPath string) ([]string, error) {
+	file, err := os.Open(historyPath)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := []string{}
+	truncated := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > maxHistoryFileLines {
+			lines = lines[1:]
+			truncated = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if truncated {
+		content := strings.Join(lines, "\n") + "\n"
+		if err := ioutil.WriteFile(historyPath, []byte(content), 0600); err != nil {
+			return nil, err
+		}
+	}
+	return lines, nil
+}
+
+//
+// terminalIO is the input and the output of the terminal, which can be swapped after the terminal
+// is created. The terminal keeps its history private, so the history is loaded by replaying the
+// lines as input with the output discarded.
+//
+type terminalIO struct {
+	io.Reader
+	io.Writer
+}
+
+// loadHistory replays the most recent history lines into the terminal, so that they can be recalled
+// with the arrow keys. The terminal only keeps the last maxHistoryLines lines.
+func loadHistory(term *terminal.Terminal, tio *terminalIO, lines []string) {
+	if len(lines) > maxHistoryLines {
+		lines = lines[len(lines)-maxHistoryLines:]
+	}
+	var sb strings.Builder
+	count := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" || strings.IndexFunc(line, isControl) >= 0 {
+			continue // would be interpreted as key strokes
+		}
+		sb.WriteString(line + "\r")
+		count++
+	}
+
+	reader, writer := tio.Reader, tio.Writer
+	tio.Reader, tio.Writer = strings.NewReader(sb.String()), ioutil.Discard
+	for i := 0; i < count; i++ {
+		if _, err := term.ReadLine(); err != nil {
+			break
+		}
+	}
+	tio.Reader, tio.Writer = reader, writer
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
@@ -0,0 +1,70 @@
+package console
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+func TestReadHistoryCapsFile(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "console_history")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+	historyPath := path.Join(dir, historyFileName)
+
+	lines, err := readHistory(historyPath)
+	require.Nil(err)
+	assert.Equal(0, len(lines))
+
+	var sb strings.Builder
+	for i := 0; i < maxHistoryFileLines+10; i++ {
+		sb.WriteString(fmt.Sprintf("GetAccount height=%v\n", i))
+	}
+	require.Nil(ioutil.WriteFile(historyPath, []byte(sb.String()), 0600))
+
+	// Only the most recent lines are kept, in the file as well
+	lines, err = readHistory(historyPath)
+	require.Nil(err)
+	require.Equal(maxHistoryFileLines, len(lines))
+	assert.Equal("GetAccount height=10", lines[0])
+	assert.Equal(fmt.Sprintf("GetAccount height=%v", maxHistoryFileLines+9), lines[len(lines)-1])
+
+	reread, err := readHistory(historyPath)
+	require.Nil(err)
+	assert.Equal(lines, reread)
+}
+
+func TestLoadHistory(t *testing.T) {
+	assert := assert.New(t)
+
+	output := &strings.Builder{}
+	tio := &terminalIO{strings.NewReader(""), output}
+	term := terminal.NewTerminal(tio, "> ")
+
+	history := []string{"GetStatus", "", "unlock \x1b[A", "GetAccount address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab"}
+	for i := 0; i < maxHistoryLines; i++ {
+		history = append([]string{"methods"}, history...)
+	}
+	loadHistory(term, tio, history)
+	assert.Equal(0, output.Len()) // the replay is not echoed
+
+	// The arrow keys recall the loaded lines, skipping the blank and the control characters
+	keyUp := "\x1b[A"
+	tio.Reader = strings.NewReader(keyUp + "\r" + strings.Repeat(keyUp, 3) + "\r") // the recalled line is added again
+	line, err := term.ReadLine()
+	assert.Nil(err)
+	assert.Equal("GetAccount address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab", line)
+	line, err = term.ReadLine()
+	assert.Nil(err)
+	assert.Equal("GetStatus", line)
+}
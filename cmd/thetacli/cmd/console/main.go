@@ -0,0 +1,97 @@
+package console
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const historyFileName = "console_history"
+
+// ConsoleCmd represents the console command
+// Example:
+//		thetacli console
+//		thetacli console < script.txt
+var ConsoleCmd = &cobra.Command{
+	Use:   "console",
+	Short: "Start an interactive console",
+	Long: `Start an interactive console which calls the RPC methods of the remote node over a persistent connection.
+The console keeps the key unlocked for the session, records the command history, and completes the method
+names and arguments with the tab key. Type "help" in the console for the list of commands.`,
+	Example: `thetacli console`,
+	Run:     runConsole,
+}
+
+func runConsole(cmd *cobra.Command, args []string) {
+	cfgPath := cmd.Flag("config").Value.String()
+	s := newSession(viper.GetString(utils.CfgRemoteRPCEndpoint), cfgPath)
+	defer s.lock()
+
+	historyPath := path.Join(cfgPath, historyFileName)
+	history, err := readHistory(historyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read the console history: %v\n", err)
+	}
+	historyFile, err := os.OpenFile(historyPath, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0600)
+	if err == nil {
+		s.historyPath = historyFile.Name()
+		defer historyFile.Close()
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		// Non-interactive, e.g. commands piped in from a script
+		s.out = os.Stdout
+		s.readPassword = utils.GetPassword
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if !s.execute(scanner.Text()) {
+				return
+			}
+		}
+		return
+	}
+
+	oldState, err := terminal.MakeRaw(fd)
+	if err != nil {
+		utils.Error("Failed to start the console: %v\n", err)
+	}
+	defer terminal.Restore(fd, oldState)
+
+	tio := &terminalIO{os.Stdin, os.Stdout}
+	term := terminal.NewTerminal(tio, "> ")
+	if width, height, err := terminal.GetSize(fd); err == nil {
+		term.SetSize(width, height)
+	}
+	loadHistory(term, tio, history)
+	term.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return s.complete(line, pos)
+	}
+	s.out = term
+	s.readPassword = term.ReadPassword
+
+	fmt.Fprintf(term, "Welcome to the Theta console, connected to %v\n", s.endpoint)
+	fmt.Fprintf(term, "Type \"help\" for the list of commands, \"exit\" or Ctrl-D to quit\n")
+	for {
+		line, err := term.ReadLine()
+		if err != nil {
+			return // io.EOF on Ctrl-D
+		}
+		if historyFile != nil && strings.TrimSpace(line) != "" {
+			fmt.Fprintln(historyFile, line)
+		}
+		if !s.execute(line) {
+			return
+		}
+	}
+}
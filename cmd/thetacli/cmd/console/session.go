@@ -0,0 +1,408 @@
+package console
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+	"github.com/thetatoken/theta/wallet"
+	wtypes "github.com/thetatoken/theta/wallet/types"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// maxHistoryLines is the number of lines the history command prints
+const maxHistoryLines = 100
+
+var builtinCommands = map[string]string{
+	"help":    "help [method]: list the commands, or show the arguments of an RPC method",
+	"methods": "methods: list the RPC methods",
+	"unlock":  "unlock <address>: unlock a key of the soft wallet for the session",
+	"lock":    "lock: lock the unlocked key",
+	"whoami":  "whoami: show the unlocked address",
+	"sign":    "sign <hex>: sign the bytes with the unlocked key, e.g. the sign_bytes of a composed tx",
+	"history": "history: show the recent commands",
+	"exit":    "exit: quit the console",
+}
+
+//
+// session holds the state of a console session: the RPC client, which reuses its connection
+// across the calls, and the key unlocked by the user.
+//
+type session struct {
+	endpoint    string
+	cfgPath     string
+	historyPath string
+	client      *rpcc.RPCClient
+	methods     map[string]reflect.Type // RPC method name -> args type
+
+	wallet  wtypes.Wallet
+	address common.Address
+
+	out          io.Writer
+	readPassword func(prompt string) (string, error)
+}
+
+func newSession(endpoint, cfgPath string) *session {
+	return &session{
+		endpoint: endpoint,
+		cfgPath:  cfgPath,
+		client:   utils.NewRPCClient(endpoint),
+		methods:  rpcMethods(),
+		out:      os.Stdout,
+	}
+}
+
+// rpcMethods lists the methods the RPC service exports, i.e. the ones in the form of
+// func (t *ThetaRPCService) Method(args *MethodArgs, result *MethodResult) error
+func rpcMethods() map[string]reflect.Type {
+	methods := make(map[string]reflect.Type)
+	serviceType := reflect.TypeOf((*rpc.ThetaRPCService)(nil))
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	for i := 0; i < serviceType.NumMethod(); i++ {
+		method := serviceType.Method(i)
+		mtype := method.Type
+		if mtype.NumIn() != 3 || mtype.NumOut() != 1 || mtype.Out(0) != errorType {
+			continue
+		}
+		if mtype.In(1).Kind() != reflect.Ptr || mtype.In(2).Kind() != reflect.Ptr {
+			continue
+		}
+		methods[method.Name] = mtype.In(1).Elem()
+	}
+	return methods
+}
+
+// execute runs the command line, and returns false if the console should quit
+func (s *session) execute(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return true
+	}
+	fields := strings.Fields(line)
+	command, params := fields[0], strings.TrimSpace(strings.TrimPrefix(line, fields[0]))
+
+	switch command {
+	case "exit", "quit":
+		return false
+	case "help":
+		s.help(params)
+	case "methods":
+		for _, name := range s.methodNames() {
+			fmt.Fprintln(s.out, name)
+		}
+	case "unlock":
+		s.unlock(params)
+	case "lock":
+		s.lock()
+	case "whoami":
+		if s.wallet == nil {
+			fmt.Fprintln(s.out, "No key unlocked")
+		} else {
+			fmt.Fprintln(s.out, s.address.Hex())
+		}
+	case "sign":
+		s.sign(params)
+	case "history":
+		s.history()
+	default:
+		s.call(command, params)
+	}
+	return true
+}
+
+func (s *session) help(method string) {
+	if method == "" {
+		names := []string{}
+		for name := range builtinCommands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintln(s.out, builtinCommands[name])
+		}
+		fmt.Fprintln(s.out, "<method> [key=value ...] or <method> {json}: call an RPC method, e.g. GetAccount address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab")
+		return
+	}
+
+	name, argsType, ok := s.findMethod(method)
+	if !ok {
+		fmt.Fprintf(s.out, "Unknown method: %v\n", method)
+		return
+	}
+	fmt.Fprintf(s.out, "%v arguments:\n", name)
+	for _, key := range argKeys(argsType) {
+		field, _ := argField(argsType, key)
+		fmt.Fprintf(s.out, "  %v (%v)\n", key, field.Type)
+	}
+}
+
+func (s *session) unlock(address string) {
+	if !common.IsHexAddress(address) {
+		fmt.Fprintln(s.out, "Usage: unlock <address>")
+		return
+	}
+	s.lock()
+
+	w, err := wallet.OpenWallet(s.cfgPath, wtypes.WalletTypeSoft, true)
+	if err != nil {
+		fmt.Fprintf(s.out, "Failed to open wallet: %v\n", err)
+		return
+	}
+	password, err := s.readPassword("Please enter password: ")
+	if err != nil {
+		fmt.Fprintf(s.out, "Failed to get password: %v\n", err)
+		return
+	}
+	addr := common.HexToAddress(address)
+	if err := w.Unlock(addr, password, nil); err != nil {
+		fmt.Fprintf(s.out, "Failed to unlock address %v: %v\n", addr.Hex(), err)
+		return
+	}
+	s.wallet, s.address = w, addr
+	fmt.Fprintf(s.out, "Unlocked %v\n", addr.Hex())
+}
+
+func (s *session) lock() {
+	if s.wallet == nil {
+		return
+	}
+	s.wallet.Lock(s.address)
+	s.wallet, s.address = nil, common.Address{}
+}
+
+func (s *session) sign(hexStr string) {
+	if s.wallet == nil {
+		fmt.Fprintln(s.out, "No key unlocked, run unlock <address> first")
+		return
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(hexStr, "0x"))
+	if err != nil || len(data) == 0 {
+		fmt.Fprintln(s.out, "Usage: sign <hex>")
+		return
+	}
+	sig, err := s.wallet.Sign(s.address, data)
+	if err != nil {
+		fmt.Fprintf(s.out, "Failed to sign: %v\n", err)
+		return
+	}
+	fmt.Fprintf(s.out, "0x%v\n", hex.EncodeToString(sig.ToBytes()))
+}
+
+func (s *session) history() {
+	if s.historyPath == "" {
+		return
+	}
+	file, err := os.Open(s.historyPath)
+	if err != nil {
+		fmt.Fprintf(s.out, "Failed to read the history: %v\n", err)
+		return
+	}
+	defer file.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > maxHistoryLines {
+			lines = lines[1:]
+		}
+	}
+	for _, line := range lines {
+		fmt.Fprintln(s.out, line)
+	}
+}
+
+// call calls the RPC method with the params, either a JSON object or key=value pairs
+func (s *session) call(method, params string) {
+	name, argsType, ok := s.findMethod(method)
+	if !ok {
+		fmt.Fprintf(s.out, "Unknown command: %v, type \"help\" for the list of commands\n", method)
+		return
+	}
+
+	var args map[string]interface{}
+	var err error
+	if strings.HasPrefix(params, "{") {
+		err = json.Unmarshal([]byte(params), &args)
+	} else {
+		args, err = parseArgs(argsType, strings.Fields(params))
+	}
+	if err != nil {
+		fmt.Fprintf(s.out, "Invalid arguments: %v\n", err)
+		return
+	}
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+
+	res, err := s.client.Call("theta."+name, args)
+	if err != nil {
+		fmt.Fprintf(s.out, "Failed to call %v: %v\n", name, err)
+		return
+	}
+	if res.Error != nil {
+		fmt.Fprintf(s.out, "Server returned error: %v\n", res.Error)
+		return
+	}
+	formatted, err := json.MarshalIndent(res.Result, "", "    ")
+	if err != nil {
+		fmt.Fprintf(s.out, "Failed to parse server response: %v\n", err)
+		return
+	}
+	fmt.Fprintln(s.out, string(formatted))
+}
+
+// findMethod looks up the RPC method, with or without the "theta." prefix, case-insensitively
+func (s *session) findMethod(method string) (string, reflect.Type, bool) {
+	method = strings.TrimPrefix(method, "theta.")
+	for name, argsType := range s.methods {
+		if strings.EqualFold(name, method) {
+			return name, argsType, true
+		}
+	}
+	return "", nil, false
+}
+
+func (s *session) methodNames() []string {
+	names := []string{}
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// complete completes the word under the cursor: the command for the first word, and the
+// argument keys of the RPC method for the following ones
+func (s *session) complete(line string, pos int) (string, int, bool) {
+	head, tail := line[:pos], line[pos:]
+	start := strings.LastIndex(head, " ") + 1
+	prefix := head[start:]
+
+	candidates := []string{}
+	if start == 0 {
+		for name := range builtinCommands {
+			candidates = append(candidates, name)
+		}
+		candidates = append(candidates, s.methodNames()...)
+	} else if _, argsType, ok := s.findMethod(strings.Fields(head)[0]); ok && !strings.Contains(prefix, "=") {
+		for _, key := range argKeys(argsType) {
+			candidates = append(candidates, key+"=")
+		}
+	}
+
+	matches := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(prefix)) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+	sort.Strings(matches)
+
+	completion := matches[0]
+	if len(matches) > 1 {
+		// Complete the common prefix, and list the candidates
+		completion = commonPrefix(matches)
+		fmt.Fprintf(s.out, "%v\n", strings.Join(matches, "  "))
+		if len(completion) <= len(prefix) {
+			return "", 0, false
+		}
+	} else if !strings.HasSuffix(completion, "=") {
+		completion += " "
+	}
+	newLine := head[:start] + completion + tail
+	return newLine, start + len(completion), true
+}
+
+// parseArgs builds the args of the RPC method from the key=value pairs. Values of the string
+// and JSON string typed fields (e.g. the heights and the amounts) are passed as strings, and
+// the others as JSON literals.
+func parseArgs(argsType reflect.Type, pairs []string) (map[string]interface{}, error) {
+	args := make(map[string]interface{})
+	for _, pair := range pairs {
+		idx := strings.Index(pair, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("%v, expected key=value", pair)
+		}
+		key, value := pair[:idx], pair[idx+1:]
+		field, ok := argField(argsType, key)
+		if !ok {
+			return nil, fmt.Errorf("unknown key %v", key)
+		}
+		if field.Type.Kind() == reflect.String || reflect.PtrTo(field.Type).Implements(textUnmarshalerType) {
+			args[key] = value
+			continue
+		}
+		var literal interface{}
+		if err := json.Unmarshal([]byte(value), &literal); err != nil {
+			return nil, fmt.Errorf("invalid value for %v: %v", key, value)
+		}
+		args[key] = literal
+	}
+	return args, nil
+}
+
+var textUnmarshalerType = reflect.TypeOf((*interface{ UnmarshalText([]byte) error })(nil)).Elem()
+
+// argKeys returns the JSON keys of the args type
+func argKeys(argsType reflect.Type) []string {
+	keys := []string{}
+	if argsType.Kind() != reflect.Struct {
+		return keys
+	}
+	for i := 0; i < argsType.NumField(); i++ {
+		if key := jsonKey(argsType.Field(i)); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func argField(argsType reflect.Type, key string) (reflect.StructField, bool) {
+	if argsType.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+	for i := 0; i < argsType.NumField(); i++ {
+		if jsonKey(argsType.Field(i)) == key {
+			return argsType.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func jsonKey(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return "" // unexported
+	}
+	tag := strings.Split(field.Tag.Get("json"), ",")[0]
+	if tag == "-" {
+		return ""
+	}
+	if tag == "" {
+		return field.Name
+	}
+	return tag
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,93 @@
+package contract
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/vm/abi"
+)
+
+// callCmd represents the call command
+// Example:
+//		thetacli contract call --to=0x5f3a1d... --abi=Token.abi balanceOf 2E833968E5bB786Ae419c4d13189fB081Cc43bab
+var callCmd = &cobra.Command{
+	Use:     "call <function> [args...]",
+	Short:   "Call a smart contract function without sending a transaction",
+	Long:    `Call a smart contract function locally on the remote node, and decode the return values according to the ABI.`,
+	Example: `thetacli contract call --to=0x5f3a1d... --abi=Token.abi balanceOf 2E833968E5bB786Ae419c4d13189fB081Cc43bab`,
+	Args:    cobra.MinimumNArgs(1),
+	Run:     doCallCmd,
+}
+
+type callResult struct {
+	GasUsed common.JSONUint64 `json:"gas_used"`
+	VmError string            `json:"vm_error,omitempty"`
+	Return  []*abi.DecodedArg `json:"return"`
+}
+
+func doCallCmd(cmd *cobra.Command, args []string) {
+	contractABI := loadABI()
+	function, err := contractABI.Function(args[0], len(args)-1)
+	if err != nil {
+		utils.Exit(utils.ExitCodeUsage, "%v\n", err)
+	}
+	data, err := function.EncodeInputs(args[1:])
+	if err != nil {
+		utils.Exit(utils.ExitCodeUsage, "Failed to encode the arguments: %v\n", err)
+	}
+	value, ok := types.ParseCoinAmount(valueFlag)
+	if !ok {
+		utils.Error("Failed to parse value: %v\n", valueFlag)
+	}
+	gasPrice, ok := types.ParseCoinAmount(gasPriceFlag)
+	if !ok {
+		utils.Error("Failed to parse gas price: %v\n", gasPriceFlag)
+	}
+	gasLimit := gasLimitFlag
+	if gasLimit == 0 {
+		gasLimit = types.MaximumTxGasLimit
+	}
+
+	sctx := &types.SmartContractTx{
+		From: types.TxInput{
+			Address:  common.HexToAddress(fromFlag),
+			Coins:    types.Coins{ThetaWei: big.NewInt(0), TFuelWei: value},
+			Sequence: seqFlag,
+		},
+		To:       types.TxOutput{Address: common.HexToAddress(toFlag)},
+		GasLimit: gasLimit,
+		GasPrice: gasPrice,
+		Data:     data,
+	}
+	result := callSmartContract(newClient(), sctx)
+
+	output := callResult{GasUsed: result.GasUsed, VmError: result.VmError, Return: []*abi.DecodedArg{}}
+	if result.VmError == "" {
+		ret, err := hex.DecodeString(result.VmReturn)
+		if err != nil {
+			utils.Error("Failed to decode the return value: %v\n", err)
+		}
+		if output.Return, err = function.DecodeOutputs(ret); err != nil {
+			utils.Error("Failed to decode the return value: %v\n", err)
+		}
+	}
+	utils.PrintResult(output)
+}
+
+func init() {
+	callCmd.Flags().StringVar(&fromFlag, "from", "", "The caller address, the zero address if not set")
+	callCmd.Flags().StringVar(&toFlag, "to", "", "The smart contract address")
+	callCmd.Flags().StringVar(&abiFlag, "abi", "", "Path to the contract ABI JSON, or a compiler artifact with an abi field")
+	callCmd.Flags().StringVar(&valueFlag, "value", "0", "Value to be transferred")
+	callCmd.Flags().StringVar(&gasPriceFlag, "gas_price", fmt.Sprintf("%dwei", types.MinimumGasPriceJune2021), "The gas price")
+	callCmd.Flags().Uint64Var(&gasLimitFlag, "gas_limit", 0, "The gas limit")
+	callCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
+
+	callCmd.MarkFlagRequired("to")
+	callCmd.MarkFlagRequired("abi")
+}
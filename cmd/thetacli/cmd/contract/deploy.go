@@ -0,0 +1,63 @@
+package contract
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// deployCmd represents the deploy command
+// Example:
+//		thetacli contract deploy --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --abi=Token.abi --bin=Token.bin "My Token" 1000000
+var deployCmd = &cobra.Command{
+	Use:     "deploy [constructor args...]",
+	Short:   "Deploy a smart contract",
+	Long:    `Deploy a smart contract from its bytecode, with the constructor arguments encoded according to the ABI.`,
+	Example: `thetacli contract deploy --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --abi=Token.abi --bin=Token.bin "My Token" 1000000`,
+	Run:     doDeployCmd,
+}
+
+func doDeployCmd(cmd *cobra.Command, args []string) {
+	if binFlag == "" {
+		utils.Exit(utils.ExitCodeUsage, "The bytecode file cannot be empty\n")
+	}
+	contractABI := loadABI()
+	input, err := contractABI.Constructor().EncodeInputs(args)
+	if err != nil {
+		utils.Exit(utils.ExitCodeUsage, "Failed to encode the constructor arguments: %v\n", err)
+	}
+	data := append(decodeHexFile(binFlag), input...)
+
+	wallet, from := unlockCaller(cmd)
+	defer wallet.Lock(from)
+
+	client := newClient()
+	sctx := composeSmartContractTx(client, from, common.Address{}, data)
+	hash := signAndBroadcast(client, wallet, sctx)
+	if asyncFlag {
+		utils.PrintSuccess(fmt.Sprintf("Deployment transaction broadcasted: %v", hash), map[string]string{"hash": hash})
+		return
+	}
+	utils.PrintResult(waitForReceipt(client, hash, contractABI, nil))
+}
+
+func init() {
+	deployCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	deployCmd.Flags().StringVar(&fromFlag, "from", "", "The deployer address")
+	deployCmd.Flags().StringVar(&abiFlag, "abi", "", "Path to the contract ABI JSON, or a compiler artifact with an abi field")
+	deployCmd.Flags().StringVar(&binFlag, "bin", "", "Path to the hex encoded contract bytecode")
+	deployCmd.Flags().StringVar(&valueFlag, "value", "0", "Value to be transferred")
+	deployCmd.Flags().StringVar(&gasPriceFlag, "gas_price", fmt.Sprintf("%dwei", types.MinimumGasPriceJune2021), "The gas price")
+	deployCmd.Flags().Uint64Var(&gasLimitFlag, "gas_limit", 0, "The gas limit, estimated if not set")
+	deployCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, the next one of the deployer if not set")
+	deployCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+	deployCmd.Flags().BoolVar(&asyncFlag, "async", false, "return without waiting for the receipt")
+
+	deployCmd.MarkFlagRequired("chain")
+	deployCmd.MarkFlagRequired("from")
+	deployCmd.MarkFlagRequired("abi")
+	deployCmd.MarkFlagRequired("bin")
+}
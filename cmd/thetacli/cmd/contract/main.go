@@ -0,0 +1,34 @@
+package contract
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Common flags used in Contract sub commands.
+var (
+	chainIDFlag  string
+	fromFlag     string
+	toFlag       string
+	abiFlag      string
+	binFlag      string
+	valueFlag    string
+	gasPriceFlag string
+	gasLimitFlag uint64
+	seqFlag      uint64
+	passwordFlag string
+	asyncFlag    bool
+)
+
+// ContractCmd represents the contract command
+var ContractCmd = &cobra.Command{
+	Use:   "contract",
+	Short: "Deploy and interact with smart contracts using their ABI",
+	Long: `Deploy and interact with smart contracts using their Solidity ABI JSON. The arguments are human readable:
+numbers in decimal or 0x-prefixed hex, addresses and bytes in hex, and arrays in JSON, e.g. '["0x01","0x02"]'.`,
+}
+
+func init() {
+	ContractCmd.AddCommand(deployCmd)
+	ContractCmd.AddCommand(callCmd)
+	ContractCmd.AddCommand(sendCmd)
+}
@@ -0,0 +1,64 @@
+package contract
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// sendCmd represents the send command
+// Example:
+//		thetacli contract send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --to=0x5f3a1d... --abi=Token.abi transfer 9F1233798E905E173560071255140b4A8aBd3Ec6 1000
+var sendCmd = &cobra.Command{
+	Use:     "send <function> [args...]",
+	Short:   "Send a transaction calling a smart contract function",
+	Long:    `Send a transaction calling a smart contract function, and decode the return values and the events of the receipt according to the ABI.`,
+	Example: `thetacli contract send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --to=0x5f3a1d... --abi=Token.abi transfer 9F1233798E905E173560071255140b4A8aBd3Ec6 1000`,
+	Args:    cobra.MinimumNArgs(1),
+	Run:     doSendCmd,
+}
+
+func doSendCmd(cmd *cobra.Command, args []string) {
+	contractABI := loadABI()
+	function, err := contractABI.Function(args[0], len(args)-1)
+	if err != nil {
+		utils.Exit(utils.ExitCodeUsage, "%v\n", err)
+	}
+	data, err := function.EncodeInputs(args[1:])
+	if err != nil {
+		utils.Exit(utils.ExitCodeUsage, "Failed to encode the arguments: %v\n", err)
+	}
+
+	wallet, from := unlockCaller(cmd)
+	defer wallet.Lock(from)
+
+	client := newClient()
+	sctx := composeSmartContractTx(client, from, common.HexToAddress(toFlag), data)
+	hash := signAndBroadcast(client, wallet, sctx)
+	if asyncFlag {
+		utils.PrintSuccess(fmt.Sprintf("Transaction broadcasted: %v", hash), map[string]string{"hash": hash})
+		return
+	}
+	utils.PrintResult(waitForReceipt(client, hash, contractABI, function))
+}
+
+func init() {
+	sendCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	sendCmd.Flags().StringVar(&fromFlag, "from", "", "The caller address")
+	sendCmd.Flags().StringVar(&toFlag, "to", "", "The smart contract address")
+	sendCmd.Flags().StringVar(&abiFlag, "abi", "", "Path to the contract ABI JSON, or a compiler artifact with an abi field")
+	sendCmd.Flags().StringVar(&valueFlag, "value", "0", "Value to be transferred")
+	sendCmd.Flags().StringVar(&gasPriceFlag, "gas_price", fmt.Sprintf("%dwei", types.MinimumGasPriceJune2021), "The gas price")
+	sendCmd.Flags().Uint64Var(&gasLimitFlag, "gas_limit", 0, "The gas limit, estimated if not set")
+	sendCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction, the next one of the caller if not set")
+	sendCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+	sendCmd.Flags().BoolVar(&asyncFlag, "async", false, "return without waiting for the receipt")
+
+	sendCmd.MarkFlagRequired("chain")
+	sendCmd.MarkFlagRequired("from")
+	sendCmd.MarkFlagRequired("to")
+	sendCmd.MarkFlagRequired("abi")
+}
@@ -0,0 +1,260 @@
+package contract
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/tx"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/vm/abi"
+	"github.com/thetatoken/theta/rpc"
+	wtypes "github.com/thetatoken/theta/wallet/types"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+const (
+	// gasLimitMarginPercent is the margin added to the estimated gas
+	gasLimitMarginPercent = 25
+
+	receiptPollInterval = time.Second
+	receiptPollTimeout  = 30 * time.Second
+)
+
+type txReceipt struct {
+	Hash            string            `json:"hash"`
+	BlockHeight     common.JSONUint64 `json:"block_height"`
+	Status          string            `json:"status"`
+	ContractAddress string            `json:"contract_address,omitempty"`
+	GasUsed         common.JSONUint64 `json:"gas_used"`
+	VmError         string            `json:"vm_error,omitempty"`
+	Return          []*abi.DecodedArg `json:"return,omitempty"`
+	Events          []decodedEvent    `json:"events"`
+}
+
+// receiptEntry is the JSON form of blockchain.TxReceiptEntry
+type receiptEntry struct {
+	Logs            []*types.Log
+	EvmRet          common.Bytes
+	ContractAddress common.Address
+	GasUsed         uint64
+	EvmErr          string
+}
+
+type decodedEvent struct {
+	Address string            `json:"address"`
+	Name    string            `json:"name,omitempty"` // empty if the event is not in the ABI
+	Args    []*abi.DecodedArg `json:"args,omitempty"`
+	Topics  []common.Hash     `json:"topics,omitempty"`
+	Data    string            `json:"data,omitempty"`
+}
+
+func loadABI() *abi.ABI {
+	data, err := ioutil.ReadFile(abiFlag)
+	if err != nil {
+		utils.Error("Failed to read the ABI: %v\n", err)
+	}
+	contractABI, err := abi.Parse(data)
+	if err != nil {
+		utils.Error("Failed to parse the ABI: %v\n", err)
+	}
+	return contractABI
+}
+
+func newClient() *rpcc.RPCClient {
+	return utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+}
+
+// composeSmartContractTx composes the unsigned transaction. The sequence defaults to the next one
+// of the caller, and the gas limit to the estimated gas plus a margin.
+func composeSmartContractTx(client *rpcc.RPCClient, from common.Address, to common.Address, data []byte) *types.SmartContractTx {
+	value, ok := types.ParseCoinAmount(valueFlag)
+	if !ok {
+		utils.Error("Failed to parse value: %v\n", valueFlag)
+	}
+	gasPrice, ok := types.ParseCoinAmount(gasPriceFlag)
+	if !ok {
+		utils.Error("Failed to parse gas price: %v\n", gasPriceFlag)
+	}
+
+	sequence := seqFlag
+	if sequence == 0 {
+		sequence = nextSequence(client, from)
+	}
+
+	sctx := &types.SmartContractTx{
+		From: types.TxInput{
+			Address:  from,
+			Coins:    types.Coins{ThetaWei: big.NewInt(0), TFuelWei: value},
+			Sequence: sequence,
+		},
+		To:       types.TxOutput{Address: to},
+		GasLimit: gasLimitFlag,
+		GasPrice: gasPrice,
+		Data:     data,
+	}
+	if sctx.GasLimit == 0 {
+		sctx.GasLimit = types.MaximumTxGasLimit
+		result := callSmartContract(client, sctx)
+		if result.VmError != "" {
+			utils.Error("Gas estimation failed, the transaction would revert: %v\n", result.VmError)
+		}
+		gasLimit := uint64(result.GasUsed) * (100 + gasLimitMarginPercent) / 100
+		if gasLimit < types.MaximumTxGasLimit {
+			sctx.GasLimit = gasLimit
+		}
+	}
+	return sctx
+}
+
+func nextSequence(client *rpcc.RPCClient, address common.Address) uint64 {
+	res, err := client.Call("theta.GetAccount", rpc.GetAccountArgs{Address: address.Hex()})
+	if err != nil {
+		utils.Error("Failed to get account details: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to get account details: %v\n", res.Error)
+	}
+	account := struct {
+		Sequence common.JSONUint64 `json:"sequence"`
+	}{}
+	if err := res.GetObject(&account); err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
+	}
+	return uint64(account.Sequence) + 1
+}
+
+func callSmartContract(client *rpcc.RPCClient, sctx *types.SmartContractTx) *rpc.CallSmartContractResult {
+	raw, err := types.TxToBytes(sctx)
+	if err != nil {
+		utils.Error("Failed to encode smart contract transaction: %v\n", err)
+	}
+	res, err := client.Call("theta.CallSmartContract", rpc.CallSmartContractArgs{SctxBytes: hex.EncodeToString(raw)})
+	if err != nil {
+		utils.Error("Failed to call smart contract: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to execute smart contract: %v\n", res.Error)
+	}
+	result := &rpc.CallSmartContractResult{}
+	if err := res.GetObject(result); err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
+	}
+	return result
+}
+
+// signAndBroadcast signs the transaction with the caller key and broadcasts it, and returns
+// the transaction hash
+func signAndBroadcast(client *rpcc.RPCClient, wallet wtypes.Wallet, sctx *types.SmartContractTx) string {
+	sig, err := wallet.Sign(sctx.From.Address, sctx.SignBytes(chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	sctx.SetSignature(sctx.From.Address, sig)
+
+	raw, err := types.TxToBytes(sctx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	signedTx := hex.EncodeToString(raw)
+
+	method := "theta.BroadcastRawTransaction"
+	if asyncFlag {
+		method = "theta.BroadcastRawTransactionAsync"
+	}
+	res, err := client.Call(method, rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+	if err != nil {
+		utils.Error("Failed to broadcast transaction: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Server returned error: %v\n", res.Error)
+	}
+	result := &rpc.BroadcastRawTransactionAsyncResult{}
+	if err := res.GetObject(result); err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
+	}
+	return result.TxHash
+}
+
+// waitForReceipt waits for the transaction to be finalized, and decodes its receipt
+func waitForReceipt(client *rpcc.RPCClient, hash string, contractABI *abi.ABI, function *abi.Entry) *txReceipt {
+	receipt := &txReceipt{Hash: hash, Status: rpc.TxStatusPending, Events: []decodedEvent{}}
+	deadline := time.Now().Add(receiptPollTimeout)
+	for {
+		res, err := client.Call("theta.GetTransaction", rpc.GetTransactionArgs{Hash: hash})
+		if err == nil && res.Error == nil {
+			// The tx itself is not decoded, only the status and the receipt are needed
+			result := struct {
+				BlockHeight common.JSONUint64 `json:"block_height"`
+				Status      string            `json:"status"`
+				Receipt     *receiptEntry     `json:"receipt"`
+			}{}
+			if err := res.GetObject(&result); err == nil {
+				receipt.Status = result.Status
+				if result.Status == rpc.TxStatusFinalized && result.Receipt != nil {
+					receipt.BlockHeight = result.BlockHeight
+					fillReceipt(receipt, result.Receipt, contractABI, function)
+					return receipt
+				}
+				if result.Status == rpc.TxStatusAbandoned {
+					return receipt
+				}
+			}
+		}
+		if time.Now().After(deadline) {
+			return receipt
+		}
+		time.Sleep(receiptPollInterval)
+	}
+}
+
+func fillReceipt(receipt *txReceipt, entry *receiptEntry, contractABI *abi.ABI, function *abi.Entry) {
+	receipt.GasUsed = common.JSONUint64(entry.GasUsed)
+	receipt.VmError = entry.EvmErr
+	if entry.ContractAddress != (common.Address{}) {
+		receipt.ContractAddress = entry.ContractAddress.Hex()
+	}
+	if function != nil && entry.EvmErr == "" && len(entry.EvmRet) > 0 {
+		receipt.Return, _ = function.DecodeOutputs(entry.EvmRet)
+	}
+
+	for _, log := range entry.Logs {
+		event := decodedEvent{Address: log.Address.Hex()}
+		if decoded, err := contractABI.DecodeLog(log.Topics, log.Data); err == nil {
+			event.Name, event.Args = decoded.Event, decoded.Args
+		} else {
+			event.Topics, event.Data = log.Topics, "0x"+hex.EncodeToString(log.Data)
+		}
+		receipt.Events = append(receipt.Events, event)
+	}
+}
+
+func unlockCaller(cmd *cobra.Command) (wtypes.Wallet, common.Address) {
+	if fromFlag == "" {
+		utils.Error("The from address cannot be empty\n")
+	}
+	wallet, address, err := tx.SoftWalletUnlock(cmd.Flag("config").Value.String(), fromFlag, passwordFlag)
+	if err != nil || wallet == nil {
+		utils.Error("Failed to unlock %v: %v\n", fromFlag, err)
+	}
+	return wallet, address
+}
+
+func decodeHexFile(path string) []byte {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		utils.Error("Failed to read %v: %v\n", path, err)
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(content)), "0x"))
+	if err != nil {
+		utils.Error("Failed to decode %v: %v\n", path, err)
+	}
+	return data
+}
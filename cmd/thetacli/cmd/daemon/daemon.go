@@ -12,6 +12,7 @@ import (
 // startDaemonCmd runs the thetacli daemon
 // Example:
 //		thetacli daemon start --port=16889
+//		thetacli daemon start --port=16889 --signer_policy=./signer_policy.json
 var startDaemonCmd = &cobra.Command{
 	Use:     "start",
 	Short:   "Run the thatacli daemon",
@@ -23,6 +24,11 @@ var startDaemonCmd = &cobra.Command{
 		if err != nil {
 			log.Fatalf("Failed to run the ThetaCli Daemon: %v", err)
 		}
+		if signerPolicyFlag != "" {
+			if err := server.EnableSigner(signerPolicyFlag); err != nil {
+				log.Fatalf("Failed to enable the signer service: %v", err)
+			}
+		}
 		daemon := &ThetaCliDaemon{
 			RPC: server,
 		}
@@ -33,6 +39,7 @@ var startDaemonCmd = &cobra.Command{
 
 func init() {
 	startDaemonCmd.Flags().StringVar(&portFlag, "port", "16889", "Port to run the ThetaCli Daemon")
+	startDaemonCmd.Flags().StringVar(&signerPolicyFlag, "signer_policy", "", "Policy file of the signer service, which enables the SignTx API")
 }
 
 type ThetaCliDaemon struct {
@@ -5,7 +5,8 @@ import (
 )
 
 var (
-	portFlag string
+	portFlag         string
+	signerPolicyFlag string
 )
 
 // DaemonCmd represents the call command
@@ -35,7 +35,7 @@ var deleteCmd = &cobra.Command{
 			utils.Error("Failed to get password: %v\n", err)
 		}
 
-		fmt.Println("Are you sure to delete the key? Please enter 'no' to stop or 'yes' to proceed: ")
+		utils.Progress("Are you sure to delete the key? Please enter 'no' to stop or 'yes' to proceed: \n")
 		confirmation, err := utils.GetConfirmation()
 		if err != nil {
 			utils.Error("Failed to get confirmation: %v\n", err)
@@ -59,6 +59,6 @@ var deleteCmd = &cobra.Command{
 			utils.Error("Failed to delete key for address %v: %v\n", address.Hex(), err)
 		}
 
-		fmt.Printf("Key for address %v has been deleted\n", address.Hex())
+		utils.PrintSuccess(fmt.Sprintf("Key for address %v has been deleted", address.Hex()), address)
 	},
 }
@@ -27,6 +27,10 @@ var listCmd = &cobra.Command{
 			utils.Error("Failed to list keys: %v\n", err)
 		}
 
+		if utils.IsJSONOutput() {
+			utils.PrintResult(keyAddresses)
+			return
+		}
 		for _, keyAddress := range keyAddresses {
 			fmt.Printf("%s\n", keyAddress.Hex())
 		}
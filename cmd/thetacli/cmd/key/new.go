@@ -33,6 +33,6 @@ var newCmd = &cobra.Command{
 			utils.Error("Failed to generate new key: %v\n", err)
 		}
 
-		fmt.Printf("Successfully created key: %v\n", address.Hex())
+		utils.PrintSuccess(fmt.Sprintf("Successfully created key: %v", address.Hex()), address)
 	},
 }
@@ -45,6 +45,6 @@ var passwordCmd = &cobra.Command{
 			utils.Error("Failed to update password: %v\n", err)
 		}
 
-		fmt.Printf("Password updated successfully\n")
+		utils.PrintSuccess("Password updated successfully", address)
 	},
 }
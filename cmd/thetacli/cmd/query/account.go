@@ -1,7 +1,6 @@
 package query
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
@@ -10,12 +9,13 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // accountCmd represents the account command.
 // Example:
 //		thetacli query account --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab
+//		thetacli query account --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --watch=5s
+//		thetacli query account --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --after_tx=0x1a2b...
 var accountCmd = &cobra.Command{
 	Use:     "account",
 	Short:   "Get account status",
@@ -25,28 +25,29 @@ var accountCmd = &cobra.Command{
 }
 
 func doAccountCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
-	res, err := client.Call("theta.GetAccount", rpc.GetAccountArgs{
-		Address: addressFlag,
-		Height:  common.JSONUint64(heightFlag),
-		Preview: previewFlag})
-	if err != nil {
-		utils.Error("Failed to get account details: %v\n", err)
-	}
-	if res.Error != nil {
-		utils.Error("Failed to get account details: %v\n", res.Error)
-	}
-	json, err := json.MarshalIndent(res.Result, "", "    ")
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n%v\n", err, string(json))
-	}
-	fmt.Println(string(json))
+	runQuery("account "+addressFlag, func() (interface{}, error) {
+		res, err := client.Call("theta.GetAccount", rpc.GetAccountArgs{
+			Address: addressFlag,
+			Height:  common.JSONUint64(heightFlag),
+			Preview: previewFlag,
+			AfterTx: afterTxFlag})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get account details: %w", err)
+		}
+		if res.Error != nil {
+			return nil, fmt.Errorf("Failed to get account details: %w", res.Error)
+		}
+		return res.Result, nil
+	})
 }
 
 func init() {
 	accountCmd.Flags().StringVar(&addressFlag, "address", "", "Address of the account")
 	accountCmd.Flags().Uint64Var(&heightFlag, "height", uint64(0), "height of the block")
 	accountCmd.Flags().BoolVar(&previewFlag, "preview", false, "Preview account balance from the screened view")
+	accountCmd.Flags().StringVar(&afterTxFlag, "after_tx", "", "Hash of a broadcasted transaction whose effect the account status should reflect")
+	addWatchFlag(accountCmd)
 	accountCmd.MarkFlagRequired("address")
 }
@@ -0,0 +1,44 @@
+package query
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// balanceHistoryCmd represents the balance_history command.
+// Example:
+//		thetacli query balance_history --address=2E833968E5bB786Ae419c4d13189fB081Cc43bab --start=1000 --end=2000
+var balanceHistoryCmd = &cobra.Command{
+	Use:     "balance_history",
+	Short:   "Get the balance history of an address",
+	Long:    `Get the Theta and TFuel balances of an address over a height window. Defaults to the latest 100000 finalized blocks.`,
+	Example: `thetacli query balance_history --address=2E833968E5bB786Ae419c4d13189fB081Cc43bab --start=1000 --end=2000`,
+	Run:     doBalanceHistoryCmd,
+}
+
+func doBalanceHistoryCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	res, err := client.Call("theta.GetBalanceHistory", rpc.GetBalanceHistoryArgs{
+		Address: addressFlag,
+		Start:   common.JSONUint64(startFlag),
+		End:     common.JSONUint64(endFlag),
+	})
+	if err != nil {
+		utils.Error("Failed to get balance history: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to get balance history: %v\n", res.Error)
+	}
+	utils.PrintResult(res.Result)
+}
+
+func init() {
+	balanceHistoryCmd.Flags().StringVar(&addressFlag, "address", "", "Address of the account")
+	balanceHistoryCmd.Flags().Uint64Var(&startFlag, "start", uint64(0), "starting height of the window")
+	balanceHistoryCmd.Flags().Uint64Var(&endFlag, "end", uint64(0), "ending height of the window")
+	balanceHistoryCmd.MarkFlagRequired("address")
+}
@@ -0,0 +1,39 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// baseFeeCmd represents the basefee command.
+// Example:
+//		thetacli query basefee
+//		thetacli query basefee --height=10000
+var baseFeeCmd = &cobra.Command{
+	Use:     "basefee",
+	Short:   "Get the base fee per gas",
+	Long:    `Get the base fee per gas in TFuelWei of the block after the finalized block at the given height, which the smart contract transactions in that block pay at least.`,
+	Example: `thetacli query basefee --height=10000`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+		res, err := client.Call("theta.GetBaseFee", rpc.GetBaseFeeArgs{
+			Height: common.JSONUint64(heightFlag),
+		})
+		if err != nil {
+			utils.Error("Failed to get base fee: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.Error("Failed to retrieve base fee: %v\n", res.Error)
+		}
+		utils.PrintResult(res.Result)
+	},
+}
+
+func init() {
+	baseFeeCmd.Flags().Uint64Var(&heightFlag, "height", uint64(0), "Block height, 0 for the last finalized block")
+}
@@ -1,7 +1,6 @@
 package query
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/thetatoken/theta/common"
@@ -19,6 +18,8 @@ import (
 // Example:
 //		thetacli query block --height=300
 //		thetacli query block --hash=0xc88485a473527c55c5ddb067b018324b7e390b188e76702bc1db74dfc2dc6d13
+//		thetacli query block --timestamp=1617235200 --direction=before
+//		thetacli query block --watch
 //
 var blockCmd = &cobra.Command{
 	Use:     "block",
@@ -26,42 +27,73 @@ var blockCmd = &cobra.Command{
 	Long:    `Get block details.`,
 	Example: `thetacli query block --height=300`,
 	Run: func(cmd *cobra.Command, args []string) {
-		client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
-		var res *jsonrpc.RPCResponse
-		var err error
-		if len(hashFlag) != 0 {
-			res, err = client.Call("theta.GetBlock", rpc.GetBlockArgs{
-				Hash: common.HexToHash(hashFlag),
-			})
-		} else if endFlag != 0 {
-			res, err = client.Call("theta.GetBlocksByRange", rpc.GetBlocksByRangeArgs{
-				Start: common.JSONUint64(startFlag),
-				End: common.JSONUint64(endFlag),
-			})
-		} else {
-			res, err = client.Call("theta.GetBlockByHeight", rpc.GetBlockByHeightArgs{
-				Height: common.JSONUint64(heightFlag),
-			})
+		// Without a block specified, watch the latest finalized block
+		watchLatest := watchFlag > 0 && len(hashFlag) == 0 && timestampFlag == 0 && endFlag == 0 && heightFlag == 0
+		title := "block"
+		if watchLatest {
+			title = "latest finalized block"
 		}
+		runQuery(title, func() (interface{}, error) {
+			var res *jsonrpc.RPCResponse
+			var err error
+			if watchLatest {
+				res, err = getLatestFinalizedBlock(client)
+			} else if len(hashFlag) != 0 {
+				res, err = client.Call("theta.GetBlock", rpc.GetBlockArgs{
+					Hash: common.HexToHash(hashFlag),
+				})
+			} else if timestampFlag != 0 {
+				res, err = client.Call("theta.GetBlockByTimestamp", rpc.GetBlockByTimestampArgs{
+					Timestamp: common.JSONUint64(timestampFlag),
+					Direction: directionFlag,
+				})
+			} else if endFlag != 0 {
+				res, err = client.Call("theta.GetBlocksByRange", rpc.GetBlocksByRangeArgs{
+					Start: common.JSONUint64(startFlag),
+					End:   common.JSONUint64(endFlag),
+				})
+			} else {
+				res, err = client.Call("theta.GetBlockByHeight", rpc.GetBlockByHeightArgs{
+					Height: common.JSONUint64(heightFlag),
+				})
+			}
 
-		if err != nil {
-			utils.Error("Failed to get block(s) details: %v\n", err)
-		}
-		if res.Error != nil {
-			utils.Error("Failed to retrieve block(s) details: %v\n", res.Error)
-		}
-		json, err := json.MarshalIndent(res.Result, "", "    ")
-		if err != nil {
-			utils.Error("Failed to parse server response: %v\n%v\n", err, string(json))
-		}
-		fmt.Println(string(json))
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get block(s) details: %w", err)
+			}
+			if res.Error != nil {
+				return nil, fmt.Errorf("Failed to retrieve block(s) details: %w", res.Error)
+			}
+			return res.Result, nil
+		})
 	},
 }
 
+func getLatestFinalizedBlock(client *rpcc.RPCClient) (*jsonrpc.RPCResponse, error) {
+	res, err := client.Call("theta.GetStatus", rpc.GetStatusArgs{})
+	if err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return res, nil
+	}
+	status := &rpc.GetStatusResult{}
+	if err := res.GetObject(status); err != nil {
+		return nil, err
+	}
+	return client.Call("theta.GetBlockByHeight", rpc.GetBlockByHeightArgs{
+		Height: status.LatestFinalizedBlockHeight,
+	})
+}
+
 func init() {
 	blockCmd.Flags().StringVar(&hashFlag, "hash", "", "Block hash")
 	blockCmd.Flags().Uint64Var(&heightFlag, "height", uint64(0), "height of the block")
 	blockCmd.Flags().Uint64Var(&startFlag, "start", uint64(0), "starting height of the blocks")
 	blockCmd.Flags().Uint64Var(&endFlag, "end", uint64(0), "ending height of the blocks")
+	blockCmd.Flags().Uint64Var(&timestampFlag, "timestamp", uint64(0), "Unix timestamp to find the closest finalized block")
+	blockCmd.Flags().StringVar(&directionFlag, "direction", rpc.TimestampDirectionBefore, "find the last block before or the first block after the timestamp, before or after")
+	addWatchFlag(blockCmd)
 }
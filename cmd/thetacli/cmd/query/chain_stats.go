@@ -0,0 +1,42 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// chainStatsCmd represents the chain_stats command.
+// Example:
+//		thetacli query chain_stats --start=1000 --end=2000
+var chainStatsCmd = &cobra.Command{
+	Use:     "chain_stats",
+	Short:   "Get block production statistics",
+	Long:    `Get block production statistics over a height window. Defaults to the latest 100 finalized blocks.`,
+	Example: `thetacli query chain_stats --start=1000 --end=2000`,
+	Run:     doChainStatsCmd,
+}
+
+func doChainStatsCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	res, err := client.Call("theta.GetChainStats", rpc.GetChainStatsArgs{
+		Start: common.JSONUint64(startFlag),
+		End:   common.JSONUint64(endFlag),
+	})
+	if err != nil {
+		utils.Error("Failed to get chain stats: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to get chain stats: %v\n", res.Error)
+	}
+	utils.PrintResult(res.Result)
+}
+
+func init() {
+	chainStatsCmd.Flags().Uint64Var(&startFlag, "start", uint64(0), "starting height of the window")
+	chainStatsCmd.Flags().Uint64Var(&endFlag, "end", uint64(0), "ending height of the window")
+}
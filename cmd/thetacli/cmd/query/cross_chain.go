@@ -0,0 +1,88 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// crossChainChannelCmd represents the cross_chain_channel command.
+// Example:
+//		thetacli query cross_chain_channel --peer_chain_id=tsub_360777
+var crossChainChannelCmd = &cobra.Command{
+	Use:     "cross_chain_channel",
+	Short:   "Get the message channel with a peer chain",
+	Long:    `Get the nonces of the next message sent to and the next message to be received from a peer chain.`,
+	Example: `thetacli query cross_chain_channel --peer_chain_id=tsub_360777`,
+	Run:     doCrossChainChannelCmd,
+}
+
+// crossChainMessagesCmd represents the cross_chain_messages command.
+// Example:
+//		thetacli query cross_chain_messages --peer_chain_id=tsub_360777 --height=1000 --start=20
+//		thetacli query cross_chain_messages --peer_chain_id=tsub_360777 --received
+var crossChainMessagesCmd = &cobra.Command{
+	Use:   "cross_chain_messages",
+	Short: "Get the messages sent to or received from a peer chain",
+	Long: `Get the messages sent to a peer chain with their proofs against the state root of a finalized block,
+or the messages received from the peer chain with --received.`,
+	Example: `thetacli query cross_chain_messages --peer_chain_id=tsub_360777 --height=1000 --start=20`,
+	Run:     doCrossChainMessagesCmd,
+}
+
+func doCrossChainChannelCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	res, err := client.Call("theta.GetCrossChainChannel", rpc.GetCrossChainChannelArgs{PeerChainID: peerChainIDFlag})
+	if err != nil {
+		utils.Error("Failed to get cross-chain channel: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to retrieve cross-chain channel: %v\n", res.Error)
+	}
+	utils.PrintResult(res.Result)
+}
+
+func doCrossChainMessagesCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	var res *rpcc.RPCResponse
+	var err error
+	if receivedFlag {
+		res, err = client.Call("theta.GetReceivedCrossChainMessages", rpc.GetReceivedCrossChainMessagesArgs{
+			SourceChainID: peerChainIDFlag,
+			StartNonce:    common.JSONUint64(startFlag),
+			Limit:         common.JSONUint64(limitFlag),
+		})
+	} else {
+		res, err = client.Call("theta.GetCrossChainMessages", rpc.GetCrossChainMessagesArgs{
+			TargetChainID: peerChainIDFlag,
+			Height:        common.JSONUint64(heightFlag),
+			StartNonce:    common.JSONUint64(startFlag),
+			Limit:         common.JSONUint64(limitFlag),
+		})
+	}
+	if err != nil {
+		utils.Error("Failed to get cross-chain messages: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to retrieve cross-chain messages: %v\n", res.Error)
+	}
+	utils.PrintResult(res.Result)
+}
+
+func init() {
+	crossChainChannelCmd.Flags().StringVar(&peerChainIDFlag, "peer_chain_id", "", "Chain ID of the peer chain")
+	crossChainChannelCmd.MarkFlagRequired("peer_chain_id")
+
+	crossChainMessagesCmd.Flags().StringVar(&peerChainIDFlag, "peer_chain_id", "", "Chain ID of the peer chain")
+	crossChainMessagesCmd.Flags().BoolVar(&receivedFlag, "received", false, "get the messages received from the peer chain")
+	crossChainMessagesCmd.Flags().Uint64Var(&heightFlag, "height", uint64(0), "height of the finalized block to prove the sent messages against, the latest one if not specified")
+	crossChainMessagesCmd.Flags().Uint64Var(&startFlag, "start", uint64(0), "nonce of the first message")
+	crossChainMessagesCmd.Flags().Uint64Var(&limitFlag, "limit", uint64(0), "max number of the messages")
+	crossChainMessagesCmd.MarkFlagRequired("peer_chain_id")
+}
@@ -0,0 +1,45 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// eenVotesCmd represents the een_votes command.
+// Example:
+//		thetacli query een_votes --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --limit=20
+var eenVotesCmd = &cobra.Command{
+	Use:   "een_votes",
+	Short: "Get the diagnostics of the recent votes from an elite edge node",
+	Long: `Get how the recent votes from the elite edge node were handled by the queried node, i.e. when they arrived,
+why they were rejected, and whether they were aggregated into the finalized blocks. Query a guardian or validator
+node which the elite edge node votes through.`,
+	Example: `thetacli query een_votes --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --limit=20`,
+	Run:     doEENVotesCmd,
+}
+
+func doEENVotesCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	res, err := client.Call("theta.GetEliteEdgeNodeVoteDiagnostics", rpc.GetEliteEdgeNodeVoteDiagnosticsArgs{
+		Address: addressFlag,
+		Limit:   common.JSONUint64(limitFlag),
+	})
+	if err != nil {
+		utils.Error("Failed to get elite edge node vote diagnostics: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to retrieve elite edge node vote diagnostics: %v\n", res.Error)
+	}
+	utils.PrintResult(res.Result)
+}
+
+func init() {
+	eenVotesCmd.Flags().StringVar(&addressFlag, "address", "", "Address of the elite edge node")
+	eenVotesCmd.Flags().Uint64Var(&limitFlag, "limit", uint64(0), "Max number of the recent votes, 0 for all")
+	eenVotesCmd.MarkFlagRequired("address")
+}
@@ -1,16 +1,11 @@
 package query
 
 import (
-	"encoding/json"
-	"fmt"
-
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/rpc"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // eenpCmd represents the eenp command.
@@ -24,7 +19,7 @@ var eenpCmd = &cobra.Command{
 }
 
 func doEenpCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
 	height := heightFlag
 	res, err := client.Call("theta.GetEenpByHeight", rpc.GetEenpByHeightArgs{Height: common.JSONUint64(height)})
@@ -34,11 +29,7 @@ func doEenpCmd(cmd *cobra.Command, args []string) {
 	if res.Error != nil {
 		utils.Error("Failed to get elite edge node pool: %v\n", res.Error)
 	}
-	json, err := json.MarshalIndent(res.Result, "", "    ")
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n%s\n", err, string(json))
-	}
-	fmt.Println(string(json))
+	utils.PrintResult(res.Result)
 }
 
 func init() {
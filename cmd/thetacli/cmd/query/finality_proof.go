@@ -0,0 +1,52 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// finalityProofCmd represents the finality_proof command.
+// Example:
+//		thetacli query finality_proof --height=300 --verify
+var finalityProofCmd = &cobra.Command{
+	Use:     "finality_proof",
+	Short:   "Get the finality proof of a block",
+	Long:    `Get a self-contained proof that a block has been finalized, which can be verified offline.`,
+	Example: `thetacli query finality_proof --height=300 --verify`,
+	Run:     doFinalityProofCmd,
+}
+
+func doFinalityProofCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	res, err := client.Call("theta.GetFinalityProof", rpc.GetFinalityProofArgs{
+		Hash:   common.HexToHash(hashFlag),
+		Height: common.JSONUint64(heightFlag),
+	})
+	if err != nil {
+		utils.Error("Failed to get finality proof: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to get finality proof: %v\n", res.Error)
+	}
+	if verifyFlag {
+		proof := &rpc.GetFinalityProofResult{}
+		if err := res.GetObject(proof); err != nil {
+			utils.Error("Failed to parse server response: %v\n", err)
+		}
+		if err := proof.Verify(); err != nil {
+			utils.Error("Failed to verify finality proof: %v\n", err)
+		}
+	}
+	utils.PrintResult(res.Result)
+}
+
+func init() {
+	finalityProofCmd.Flags().StringVar(&hashFlag, "hash", "", "Block hash")
+	finalityProofCmd.Flags().Uint64Var(&heightFlag, "height", uint64(0), "height of the block")
+	finalityProofCmd.Flags().BoolVar(&verifyFlag, "verify", false, "Verify the proof against the validator sets and guardian pool it carries")
+}
@@ -0,0 +1,31 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// forksCmd represents the forks command.
+// Example:
+//		thetacli query forks
+var forksCmd = &cobra.Command{
+	Use:     "forks",
+	Short:   "Get the fork schedule",
+	Long:    `Get the activation heights of the forks of the chain, and whether each fork is active at the last finalized block.`,
+	Example: `thetacli query forks`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+		res, err := client.Call("theta.GetForkConfig", rpc.GetForkConfigArgs{})
+		if err != nil {
+			utils.Error("Failed to get fork config: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.Error("Failed to retrieve fork config: %v\n", res.Error)
+		}
+		utils.PrintResult(res.Result)
+	},
+}
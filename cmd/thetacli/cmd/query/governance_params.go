@@ -0,0 +1,31 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// governanceParamsCmd represents the governance_params command.
+// Example:
+//		thetacli query governance_params
+var governanceParamsCmd = &cobra.Command{
+	Use:     "governance_params",
+	Short:   "Get the governed parameters and the pending changes",
+	Long:    `Get the current values of the governed parameters, e.g. the block gas limit, and the pending parameter changes voted by the validators.`,
+	Example: `thetacli query governance_params`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+		res, err := client.Call("theta.GetGovernanceParams", rpc.GetGovernanceParamsArgs{})
+		if err != nil {
+			utils.Error("Failed to get governance params: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.Error("Failed to retrieve governance params: %v\n", res.Error)
+		}
+		utils.PrintResult(res.Result)
+	},
+}
@@ -9,16 +9,16 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // guardianCmd retreves guardian related information from Theta server.
 // Example:
 //		thetacli query guardian
+//		thetacli query guardian --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab
 var guardianCmd = &cobra.Command{
 	Use:     "guardian",
 	Short:   "Get guardian info",
-	Long:    `Get guardian status.`,
+	Long:    `Get guardian status. By default the info is derived from the node key, use --address to derive it from another key in the node keystore.`,
 	Example: `thetacli query guardian`,
 	Run:     doGuardianCmd,
 }
@@ -32,9 +32,25 @@ type GuardianResult struct {
 }
 
 func doGuardianCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	output := getGuardianInfo()
+	utils.PrintResult(output)
+}
+
+// getGuardianInfo queries the guardian info of the key specified by the address flag.
+func getGuardianInfo() *GuardianResult {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	guardianArgs := rpc.GetGuardianInfoArgs{}
+	if addressFlag != "" {
+		password, err := utils.GetPassword(fmt.Sprintf("Please enter the password for key %v: ", addressFlag))
+		if err != nil {
+			utils.Error("Failed to get password: %v\n", err)
+		}
+		guardianArgs.Address = addressFlag
+		guardianArgs.Password = password
+	}
 
-	res, err := client.Call("theta.GetGuardianInfo", rpc.GetGuardianInfoArgs{})
+	res, err := client.Call("theta.GetGuardianInfo", guardianArgs)
 	if err != nil {
 		utils.Error("Failed to get guardian info: %v\n", err)
 	}
@@ -69,11 +85,9 @@ func doGuardianCmd(cmd *cobra.Command, args []string) {
 		Signature: sig,
 	}
 	output.Summary = address + blsPubkey + blsPop + sig
-	json, err := json.MarshalIndent(output, "", "    ")
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n%v\n", err, string(json))
-	}
-	fmt.Println(string(json))
+	return output
 }
 
-func init() {}
+func init() {
+	guardianCmd.Flags().StringVar(&addressFlag, "address", "", "Address of the key in the node keystore, default to the node key")
+}
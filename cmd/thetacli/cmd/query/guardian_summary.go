@@ -0,0 +1,41 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// guardianSummaryCmd exports the guardian summary required by the staking portal to a file.
+// Example:
+//		thetacli query guardian_summary --file=guardian_summary.json
+var guardianSummaryCmd = &cobra.Command{
+	Use:     "guardian_summary",
+	Short:   "Export the guardian summary",
+	Long:    `Export the guardian summary required by the staking portal to a file.`,
+	Example: `thetacli query guardian_summary --file=guardian_summary.json`,
+	Run:     doGuardianSummaryCmd,
+}
+
+func doGuardianSummaryCmd(cmd *cobra.Command, args []string) {
+	output := getGuardianInfo()
+	json, err := json.MarshalIndent(output, "", "    ")
+	if err != nil {
+		utils.Error("Failed to encode guardian summary: %v\n", err)
+	}
+
+	err = ioutil.WriteFile(fileFlag, json, 0600)
+	if err != nil {
+		utils.Error("Failed to write guardian summary to %v: %v\n", fileFlag, err)
+	}
+	utils.PrintSuccess(fmt.Sprintf("Guardian summary of %v exported to %v", output.Address, fileFlag), output)
+}
+
+func init() {
+	guardianSummaryCmd.Flags().StringVar(&addressFlag, "address", "", "Address of the key in the node keystore, default to the node key")
+	guardianSummaryCmd.Flags().StringVar(&fileFlag, "file", "guardian_summary.json", "Path of the exported summary file")
+}
@@ -0,0 +1,44 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// guardianVotesCmd represents the guardian_votes command.
+// Example:
+//		thetacli query guardian_votes --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --limit=50
+var guardianVotesCmd = &cobra.Command{
+	Use:   "guardian_votes",
+	Short: "Get the inclusion of a guardian's votes in the recent checkpoints",
+	Long: `Get whether the vote of the guardian is included in the aggregated guardian votes of each recent
+checkpoint block, and the inclusion rate. A low rate usually indicates connectivity problems of the guardian node.`,
+	Example: `thetacli query guardian_votes --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --limit=50`,
+	Run:     doGuardianVotesCmd,
+}
+
+func doGuardianVotesCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	res, err := client.Call("theta.GetGuardianVoteInclusion", rpc.GetGuardianVoteInclusionArgs{
+		Address:        addressFlag,
+		NumCheckpoints: common.JSONUint64(limitFlag),
+	})
+	if err != nil {
+		utils.Error("Failed to get guardian vote inclusion: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to retrieve guardian vote inclusion: %v\n", res.Error)
+	}
+	utils.PrintResult(res.Result)
+}
+
+func init() {
+	guardianVotesCmd.Flags().StringVar(&addressFlag, "address", "", "Holder address of the guardian")
+	guardianVotesCmd.Flags().Uint64Var(&limitFlag, "limit", uint64(0), "Number of the recent checkpoints to check, 0 for the default")
+	guardianVotesCmd.MarkFlagRequired("address")
+}
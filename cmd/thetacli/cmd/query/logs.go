@@ -0,0 +1,59 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// logsCmd represents the logs command.
+// Example:
+//		thetacli query logs --start=1000 --end=2000 --addresses=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --topics=0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Get the logs emitted by the smart contracts",
+	Long: `Get the logs of the finalized blocks within a height window, emitted by any of the given contracts and with the
+given topics. Each --topics value lists the alternatives for the topic at its position, separated by "|", and an empty
+value matches any topic. Defaults to the latest finalized block.`,
+	Example: `thetacli query logs --start=1000 --end=2000 --addresses=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --topics=0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef`,
+	Run:     doLogsCmd,
+}
+
+func doLogsCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	topics := [][]string{}
+	for _, alternatives := range topicsFlag {
+		if len(alternatives) == 0 {
+			topics = append(topics, []string{})
+			continue
+		}
+		topics = append(topics, strings.Split(alternatives, "|"))
+	}
+
+	res, err := client.Call("theta.GetLogs", rpc.GetLogsArgs{
+		Start:     common.JSONUint64(startFlag),
+		End:       common.JSONUint64(endFlag),
+		Addresses: addressesFlag,
+		Topics:    topics,
+	})
+	if err != nil {
+		utils.Error("Failed to get logs: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to get logs: %v\n", res.Error)
+	}
+	utils.PrintResult(res.Result)
+}
+
+func init() {
+	logsCmd.Flags().Uint64Var(&startFlag, "start", uint64(0), "starting height of the window")
+	logsCmd.Flags().Uint64Var(&endFlag, "end", uint64(0), "ending height of the window")
+	logsCmd.Flags().StringSliceVar(&addressesFlag, "addresses", []string{}, "addresses of the contracts emitting the logs")
+	logsCmd.Flags().StringArrayVar(&topicsFlag, "topics", []string{}, "topics by position, with the alternatives separated by |")
+}
@@ -1,7 +1,10 @@
 package query
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 )
 
 var (
@@ -9,11 +12,30 @@ var (
 	heightFlag       uint64
 	addressFlag      string
 	previewFlag      bool
+	afterTxFlag      string
 	resourceIDFlag   string
 	hashFlag         string
 	startFlag        uint64
 	endFlag          uint64
 	skipEdgeNodeFlag bool
+	fileFlag         string
+	txTypesFlag      []string
+	fromFlag         string
+	toFlag           string
+	minThetaFlag     string
+	minTFuelFlag     string
+	limitFlag        uint64
+	timestampFlag    uint64
+	directionFlag    string
+	subchainIDFlag   string
+	peerChainIDFlag  string
+	sourceFlag       string
+	holderFlag       string
+	receivedFlag     bool
+	verifyFlag       bool
+	watchFlag        time.Duration
+	addressesFlag    []string
+	topicsFlag       []string
 )
 
 // QueryCmd represents the query command
@@ -25,15 +47,65 @@ var QueryCmd = &cobra.Command{
 func init() {
 	QueryCmd.AddCommand(statusCmd)
 	QueryCmd.AddCommand(accountCmd)
+	QueryCmd.AddCommand(balanceHistoryCmd)
 	QueryCmd.AddCommand(guardianCmd)
+	QueryCmd.AddCommand(guardianSummaryCmd)
+	QueryCmd.AddCommand(guardianVotesCmd)
 	QueryCmd.AddCommand(blockCmd)
+	QueryCmd.AddCommand(chainStatsCmd)
+	QueryCmd.AddCommand(supplyDeltaCmd)
+	QueryCmd.AddCommand(logsCmd)
+	QueryCmd.AddCommand(finalityProofCmd)
 	QueryCmd.AddCommand(txCmd)
+	QueryCmd.AddCommand(searchTxsCmd)
+	QueryCmd.AddCommand(pendingCmd)
 	QueryCmd.AddCommand(splitRuleCmd)
 	QueryCmd.AddCommand(vcpCmd)
+	QueryCmd.AddCommand(validatorKeyChangesCmd)
 	QueryCmd.AddCommand(gcpCmd)
+	QueryCmd.AddCommand(governanceParamsCmd)
+	QueryCmd.AddCommand(stakingParamsCmd)
+	QueryCmd.AddCommand(stakesCmd)
+	QueryCmd.AddCommand(randomnessCmd)
+	QueryCmd.AddCommand(baseFeeCmd)
+	QueryCmd.AddCommand(subchainCmd)
+	QueryCmd.AddCommand(subchainCheckpointCmd)
+	QueryCmd.AddCommand(crossChainChannelCmd)
+	QueryCmd.AddCommand(crossChainMessagesCmd)
+	QueryCmd.AddCommand(upgradeCmd)
+	QueryCmd.AddCommand(forksCmd)
+	QueryCmd.AddCommand(shadowReportCmd)
+	QueryCmd.AddCommand(snapshotScheduleCmd)
 	QueryCmd.AddCommand(eenpCmd)
+	QueryCmd.AddCommand(eenVotesCmd)
+	QueryCmd.AddCommand(validatorReportCmd)
+	QueryCmd.AddCommand(stakeAutoCompoundingCmd)
 	QueryCmd.AddCommand(srdrsCmd)
 	QueryCmd.AddCommand(stakeReturnsCmd)
+	QueryCmd.AddCommand(stakeWithdrawalCmd)
+	QueryCmd.AddCommand(slashHistoryCmd)
+	QueryCmd.AddCommand(rewardDistributionCmd)
 	QueryCmd.AddCommand(peersCmd)
+	QueryCmd.AddCommand(peerCapabilitiesCmd)
 	QueryCmd.AddCommand(versionCmd)
 }
+
+// addWatchFlag adds the watch flag to the command. The flag can be set without a value to use the
+// default interval, e.g. --watch or --watch=500ms
+func addWatchFlag(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&watchFlag, "watch", 0, "Keep polling with the given interval and render a live-updating view")
+	cmd.Flags().Lookup("watch").NoOptDefVal = utils.DefaultWatchInterval.String()
+}
+
+// runQuery prints the result of the query, or keeps polling it if the watch flag is set
+func runQuery(title string, fetch func() (interface{}, error)) {
+	if watchFlag > 0 {
+		utils.Watch(watchFlag, title, fetch)
+		return
+	}
+	result, err := fetch()
+	if err != nil {
+		utils.Error("%v\n", err)
+	}
+	utils.PrintResult(result)
+}
@@ -0,0 +1,37 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// peerCapabilitiesCmd represents the peer_capabilities command.
+// Example:
+//		thetacli query peer_capabilities
+var peerCapabilitiesCmd = &cobra.Command{
+	Use:     "peer_capabilities",
+	Short:   "Get the capabilities advertised by the connected peers",
+	Long:    `Get the role and the capabilities (archive, tx index, snapshot offer, RPC open) advertised by the connected peers.`,
+	Example: `thetacli query peer_capabilities`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+		res, err := client.Call("theta.GetPeerCapabilities", rpc.GetPeerCapabilitiesArgs{
+			SkipEdgeNode: skipEdgeNodeFlag,
+		})
+		if err != nil {
+			utils.Error("Failed to get peer capabilities: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.Error("Failed to retrieve peer capabilities: %v\n", res.Error)
+		}
+		utils.PrintResult(res.Result)
+	},
+}
+
+func init() {
+	peerCapabilitiesCmd.Flags().BoolVar(&skipEdgeNodeFlag, "skip_edge_node", true, "skip peer edge nodes")
+}
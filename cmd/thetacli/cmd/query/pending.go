@@ -0,0 +1,45 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// pendingCmd represents the pending command.
+// Example:
+//		thetacli query pending --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab
+//		thetacli query pending --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --watch
+var pendingCmd = &cobra.Command{
+	Use:     "pending",
+	Short:   "Get pending transactions of an address",
+	Long:    `Get the transactions sent by an address that are still pending in the mempool, and the next usable sequence.`,
+	Example: `thetacli query pending --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab`,
+	Run:     doPendingCmd,
+}
+
+func doPendingCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	runQuery("pending transactions of "+addressFlag, func() (interface{}, error) {
+		res, err := client.Call("theta.GetPendingTransactionsByAddress", rpc.GetPendingTransactionsByAddressArgs{
+			Address: addressFlag})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get pending transactions: %w", err)
+		}
+		if res.Error != nil {
+			return nil, fmt.Errorf("Failed to get pending transactions: %w", res.Error)
+		}
+		return res.Result, nil
+	})
+}
+
+func init() {
+	pendingCmd.Flags().StringVar(&addressFlag, "address", "", "Address of the sender")
+	addWatchFlag(pendingCmd)
+	pendingCmd.MarkFlagRequired("address")
+}
@@ -0,0 +1,39 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// randomnessCmd represents the randomness command.
+// Example:
+//		thetacli query randomness
+//		thetacli query randomness --height=10000
+var randomnessCmd = &cobra.Command{
+	Use:     "randomness",
+	Short:   "Get the randomness beacon value",
+	Long:    `Get the randomness derived by the finalized block at the given height, which the smart contracts in the next block read from the randomness precompile.`,
+	Example: `thetacli query randomness --height=10000`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+		res, err := client.Call("theta.GetRandomness", rpc.GetRandomnessArgs{
+			Height: common.JSONUint64(heightFlag),
+		})
+		if err != nil {
+			utils.Error("Failed to get randomness: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.Error("Failed to retrieve randomness: %v\n", res.Error)
+		}
+		utils.PrintResult(res.Result)
+	},
+}
+
+func init() {
+	randomnessCmd.Flags().Uint64Var(&heightFlag, "height", uint64(0), "Block height, 0 for the last finalized block")
+}
@@ -0,0 +1,37 @@
+package query
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// rewardDistributionCmd represents the reward_distribution command.
+// Example:
+//		thetacli query reward_distribution --height=1000
+var rewardDistributionCmd = &cobra.Command{
+	Use:     "reward_distribution",
+	Short:   "Get the reward distribution of a checkpoint block",
+	Example: `thetacli query reward_distribution --height=1000`,
+	Run:     doRewardDistributionCmd,
+}
+
+func doRewardDistributionCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	res, err := client.Call("theta.GetRewardDistribution", rpc.GetRewardDistributionArgs{Height: common.JSONUint64(heightFlag)})
+	if err != nil {
+		utils.Error("Failed to get reward distribution: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to get reward distribution: %v\n", res.Error)
+	}
+	utils.PrintResult(res.Result)
+}
+
+func init() {
+	rewardDistributionCmd.Flags().Uint64Var(&heightFlag, "height", uint64(0), "height of the checkpoint block")
+	rewardDistributionCmd.MarkFlagRequired("height")
+}
@@ -0,0 +1,55 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// searchTxsCmd represents the search_txs command.
+// Example:
+//		thetacli query search_txs --start=1000 --end=2000 --types=send,stake --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab
+var searchTxsCmd = &cobra.Command{
+	Use:   "search_txs",
+	Short: "Search finalized transactions",
+	Long: `Search finalized transactions by type, sender, recipient, and minimum amount over a height window.
+Defaults to the latest 1000 finalized blocks.`,
+	Example: `thetacli query search_txs --start=1000 --end=2000 --types=send,stake --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab`,
+	Run:     doSearchTxsCmd,
+}
+
+func doSearchTxsCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	res, err := client.Call("theta.SearchTransactions", rpc.SearchTransactionsArgs{
+		StartHeight: common.JSONUint64(startFlag),
+		EndHeight:   common.JSONUint64(endFlag),
+		Types:       txTypesFlag,
+		From:        fromFlag,
+		To:          toFlag,
+		MinTheta:    minThetaFlag,
+		MinTFuel:    minTFuelFlag,
+		Limit:       common.JSONUint64(limitFlag),
+	})
+	if err != nil {
+		utils.Error("Failed to search transactions: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to search transactions: %v\n", res.Error)
+	}
+	utils.PrintResult(res.Result)
+}
+
+func init() {
+	searchTxsCmd.Flags().Uint64Var(&startFlag, "start", uint64(0), "starting height of the window")
+	searchTxsCmd.Flags().Uint64Var(&endFlag, "end", uint64(0), "ending height of the window")
+	searchTxsCmd.Flags().StringSliceVar(&txTypesFlag, "types", []string{}, "transaction types, e.g. send,smart_contract,stake")
+	searchTxsCmd.Flags().StringVar(&fromFlag, "from", "", "address of the sender")
+	searchTxsCmd.Flags().StringVar(&toFlag, "to", "", "address of the recipient")
+	searchTxsCmd.Flags().StringVar(&minThetaFlag, "min_theta", "", "minimum amount of Theta transferred, in ThetaWei")
+	searchTxsCmd.Flags().StringVar(&minTFuelFlag, "min_tfuel", "", "minimum amount of TFuel transferred, in TFuelWei")
+	searchTxsCmd.Flags().Uint64Var(&limitFlag, "limit", uint64(0), "maximum number of transactions to return")
+}
@@ -0,0 +1,31 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// shadowReportCmd represents the shadow_report command.
+// Example:
+//		thetacli query shadow_report
+var shadowReportCmd = &cobra.Command{
+	Use:     "shadow_report",
+	Short:   "Get the divergences found by the shadow execution",
+	Long:    `Get the state root, tx result and receipt divergences found by a node running in the shadow mode.`,
+	Example: `thetacli query shadow_report`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+		res, err := client.Call("theta.GetShadowReport", rpc.GetShadowReportArgs{})
+		if err != nil {
+			utils.Error("Failed to get shadow report: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.Error("Failed to retrieve shadow report: %v\n", res.Error)
+		}
+		utils.PrintResult(res.Result)
+	},
+}
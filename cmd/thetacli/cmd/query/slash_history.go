@@ -0,0 +1,36 @@
+package query
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// slashHistoryCmd represents the slash_history command.
+// Example:
+//		thetacli query slash_history --address=2E833968E5bB786Ae419c4d13189fB081Cc43bab
+var slashHistoryCmd = &cobra.Command{
+	Use:     "slash_history",
+	Short:   "Get the slash history of an address",
+	Example: `thetacli query slash_history --address=2E833968E5bB786Ae419c4d13189fB081Cc43bab`,
+	Run:     doSlashHistoryCmd,
+}
+
+func doSlashHistoryCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	res, err := client.Call("theta.GetSlashHistory", rpc.GetSlashHistoryArgs{Address: addressFlag})
+	if err != nil {
+		utils.Error("Failed to get slash history: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to get slash history: %v\n", res.Error)
+	}
+	utils.PrintResult(res.Result)
+}
+
+func init() {
+	slashHistoryCmd.Flags().StringVar(&addressFlag, "address", "", "Address of the account")
+	slashHistoryCmd.MarkFlagRequired("address")
+}
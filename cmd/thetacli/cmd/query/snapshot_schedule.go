@@ -0,0 +1,31 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// snapshotScheduleCmd represents the snapshot_schedule command.
+// Example:
+//		thetacli query snapshot_schedule
+var snapshotScheduleCmd = &cobra.Command{
+	Use:     "snapshot_schedule",
+	Short:   "Get the status of the background snapshot generation",
+	Long:    `Get the snapshot schedule, the outcome of the last scheduled snapshot, and the retained snapshots.`,
+	Example: `thetacli query snapshot_schedule`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+		res, err := client.Call("theta.GetSnapshotSchedule", rpc.GetSnapshotScheduleArgs{})
+		if err != nil {
+			utils.Error("Failed to get snapshot schedule: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.Error("Failed to retrieve snapshot schedule: %v\n", res.Error)
+		}
+		utils.PrintResult(res.Result)
+	},
+}
@@ -1,16 +1,11 @@
 package query
 
 import (
-	"encoding/json"
-	"fmt"
-
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/rpc"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // srdrsCmd represents the eenp command.
@@ -24,7 +19,7 @@ var srdrsCmd = &cobra.Command{
 }
 
 func doSrdrsCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 	height := heightFlag
 	res, err := client.Call("theta.GetStakeRewardDistributionByHeight", rpc.GetStakeRewardDistributionRuleSetByHeightArgs{
 		Height:  common.JSONUint64(height),
@@ -36,11 +31,7 @@ func doSrdrsCmd(cmd *cobra.Command, args []string) {
 	if res.Error != nil {
 		utils.Error("Failed to get stake reward distribution rule set: %v\n", res.Error)
 	}
-	json, err := json.MarshalIndent(res.Result, "", "    ")
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n%s\n", err, string(json))
-	}
-	fmt.Println(string(json))
+	utils.PrintResult(res.Result)
 }
 
 func init() {
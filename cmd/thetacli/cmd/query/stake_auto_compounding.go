@@ -0,0 +1,40 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// stakeAutoCompoundingCmd represents the stake_auto_compounding command.
+// Example:
+//		thetacli query stake_auto_compounding --address=2E833968E5bB786Ae419c4d13189fB081Cc43bab
+var stakeAutoCompoundingCmd = &cobra.Command{
+	Use:     "stake_auto_compounding",
+	Short:   "Get the stake auto-compounding status of an address",
+	Long:    `Get whether the TFuel rewards of an address are compounded into its elite edge node stake.`,
+	Example: `thetacli query stake_auto_compounding --address=2E833968E5bB786Ae419c4d13189fB081Cc43bab`,
+	Run:     doStakeAutoCompoundingCmd,
+}
+
+func doStakeAutoCompoundingCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	res, err := client.Call("theta.GetStakeAutoCompounding", rpc.GetStakeAutoCompoundingArgs{
+		Address: addressFlag,
+	})
+	if err != nil {
+		utils.Error("Failed to get stake auto-compounding status: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to retrieve stake auto-compounding status: %v\n", res.Error)
+	}
+	utils.PrintResult(res.Result)
+}
+
+func init() {
+	stakeAutoCompoundingCmd.Flags().StringVar(&addressFlag, "address", "", "Address of the stake source")
+	stakeAutoCompoundingCmd.MarkFlagRequired("address")
+}
@@ -1,8 +1,6 @@
 package query
 
 import (
-	"encoding/json"
-	"fmt"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -24,12 +22,11 @@ var stakeReturnsCmd = &cobra.Command{
 }
 
 func doStakeReturnsCmd(cmd *cobra.Command, args []string) {
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
 	purpose := purposeFlag
 	if purpose != 2 {
-		fmt.Println("Only support querying stake return for elite edge nodes (purpose=2) for now")
-		return
+		utils.Exit(utils.ExitCodeUsage, "Only support querying stake return for elite edge nodes (purpose=2) for now\n")
 	}
 
 	height := heightFlag
@@ -46,11 +43,7 @@ func doStakeReturnsCmd(cmd *cobra.Command, args []string) {
 	if res.Error != nil {
 		utils.Error("Failed to get stake returns: %v\n", res.Error)
 	}
-	json, err := json.MarshalIndent(res.Result, "", "    ")
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n%s\n", err, string(json))
-	}
-	fmt.Println(string(json))
+	utils.PrintResult(res.Result)
 }
 
 func init() {
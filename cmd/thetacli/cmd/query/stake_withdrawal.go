@@ -0,0 +1,43 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// stakeWithdrawalCmd represents the stake_withdrawal command.
+// Example:
+//		thetacli query stake_withdrawal --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --holder=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --purpose=0
+var stakeWithdrawalCmd = &cobra.Command{
+	Use:     "stake_withdrawal",
+	Short:   "Simulate a stake withdrawal",
+	Long:    `Simulate the withdrawal of a stake submitted now, showing the height and the estimated time the stake is returned at, and the rewards it would forfeit during the lockup.`,
+	Example: `thetacli query stake_withdrawal --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --holder=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --purpose=0`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+		res, err := client.Call("theta.SimulateStakeWithdrawal", rpc.SimulateStakeWithdrawalArgs{
+			Source:  sourceFlag,
+			Holder:  holderFlag,
+			Purpose: purposeFlag,
+		})
+		if err != nil {
+			utils.Error("Failed to simulate stake withdrawal: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.Error("Failed to simulate stake withdrawal: %v\n", res.Error)
+		}
+		utils.PrintResult(res.Result)
+	},
+}
+
+func init() {
+	stakeWithdrawalCmd.Flags().StringVar(&sourceFlag, "source", "", "Address of the staker")
+	stakeWithdrawalCmd.Flags().StringVar(&holderFlag, "holder", "", "Address of the stake holder")
+	stakeWithdrawalCmd.Flags().Uint8Var(&purposeFlag, "purpose", uint8(0), "Purpose of the stake, validator_node=0, guardian_node=1, elite_edge_node=2")
+	stakeWithdrawalCmd.MarkFlagRequired("source")
+	stakeWithdrawalCmd.MarkFlagRequired("holder")
+}
@@ -0,0 +1,156 @@
+package query
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// stakesCmd represents the stakes command.
+// Example:
+//		thetacli query stakes --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab
+//		thetacli query stakes --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab --reward_checkpoints=20
+var stakesCmd = &cobra.Command{
+	Use:   "stakes",
+	Short: "Get the staking dashboard of an address",
+	Long: `Get the validator, guardian and elite edge node stakes deposited by or delegated to an address, along with
+the pending stake returns, the rewards of the recent checkpoints, and the stake reward split rules.`,
+	Example: `thetacli query stakes --address=0x2E833968E5bB786Ae419c4d13189fB081Cc43bab`,
+	Run:     doStakesCmd,
+}
+
+var stakeRoles = []string{rpc.StakeRoleValidator, rpc.StakeRoleGuardian, rpc.StakeRoleEliteEdgeNode}
+
+func doStakesCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	res, err := client.Call("theta.GetStakeSummary", rpc.GetStakeSummaryArgs{
+		Address:           addressFlag,
+		RewardCheckpoints: common.JSONUint64(limitFlag),
+	})
+	if err != nil {
+		utils.Error("Failed to get stakes: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to get stakes: %v\n", res.Error)
+	}
+	if utils.IsJSONOutput() {
+		utils.PrintResult(res.Result)
+		return
+	}
+
+	summary := &rpc.GetStakeSummaryResult{}
+	if err := res.GetObject(summary); err != nil {
+		utils.Error("Failed to parse server response: %v\n", err)
+	}
+	printStakeSummary(common.HexToAddress(addressFlag), summary)
+}
+
+func printStakeSummary(address common.Address, summary *rpc.GetStakeSummaryResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "Stakes of %v at block %v\n", address.Hex(), uint64(summary.BlockHeight))
+
+	fmt.Fprintf(w, "\nTotals\n")
+	fmt.Fprintf(w, "  ROLE\tSTAKED\tDELEGATED TO ADDRESS\n")
+	for _, role := range stakeRoles {
+		fmt.Fprintf(w, "  %v\t%v\t%v\n", role,
+			formatStakeAmount(role, summary.TotalStaked[role]), formatStakeAmount(role, summary.TotalDelegated[role]))
+	}
+
+	fmt.Fprintf(w, "\nActive stakes\n")
+	if len(summary.Stakes) == 0 {
+		fmt.Fprintf(w, "  none\n")
+	} else {
+		fmt.Fprintf(w, "  ROLE\tHOLDER\tSOURCE\tAMOUNT\n")
+		for _, stake := range summary.Stakes {
+			fmt.Fprintf(w, "  %v\t%v\t%v\t%v\n", stake.Role, stake.Holder.Hex(), stake.Source.Hex(),
+				formatStakeAmount(stake.Role, stake.Amount))
+		}
+	}
+
+	fmt.Fprintf(w, "\nPending returns\n")
+	if len(summary.PendingReturns) == 0 {
+		fmt.Fprintf(w, "  none\n")
+	} else {
+		fmt.Fprintf(w, "  ROLE\tHOLDER\tSOURCE\tAMOUNT\tRETURN HEIGHT\n")
+		for _, stake := range summary.PendingReturns {
+			fmt.Fprintf(w, "  %v\t%v\t%v\t%v\t%v\n", stake.Role, stake.Holder.Hex(), stake.Source.Hex(),
+				formatStakeAmount(stake.Role, stake.Amount), uint64(stake.ReturnHeight))
+		}
+	}
+
+	fmt.Fprintf(w, "\nRecent rewards\n")
+	if len(summary.Rewards) == 0 {
+		fmt.Fprintf(w, "  none\n")
+	} else {
+		fmt.Fprintf(w, "  HEIGHT\tTYPE\tHOLDER\tSOURCE\tREWARD\tSPLIT TO\tSPLIT\n")
+		for _, reward := range summary.Rewards {
+			splitTo := ""
+			if !reward.Beneficiary.IsEmpty() {
+				splitTo = reward.Beneficiary.Hex()
+			}
+			fmt.Fprintf(w, "  %v\t%v\t%v\t%v\t%v\t%v\t%v\n", uint64(reward.Height), reward.HolderType,
+				reward.Holder.Hex(), reward.Source.Hex(), formatWei(reward.Reward, "TFuel"), splitTo,
+				formatWei(reward.SplitReward, "TFuel"))
+		}
+	}
+	fmt.Fprintf(w, "  Total received by the address: %v\n", formatWei(summary.TotalRewards, "TFuel"))
+	if len(summary.RewardsUnavailable) > 0 {
+		heights := make([]string, len(summary.RewardsUnavailable))
+		for i, height := range summary.RewardsUnavailable {
+			heights[i] = fmt.Sprintf("%v", uint64(height))
+		}
+		fmt.Fprintf(w, "  Rewards unavailable at checkpoints %v\n", strings.Join(heights, ", "))
+	}
+
+	fmt.Fprintf(w, "\nSplit rules\n")
+	if len(summary.SplitRules) == 0 {
+		fmt.Fprintf(w, "  none\n")
+	} else {
+		fmt.Fprintf(w, "  HOLDER\tBENEFICIARY\tSPLIT\n")
+		for _, rule := range summary.SplitRules {
+			fmt.Fprintf(w, "  %v\t%v\t%v.%02v%%\n", rule.StakeHolder.Hex(), rule.Beneficiary.Hex(),
+				rule.SplitBasisPoint/100, rule.SplitBasisPoint%100)
+		}
+	}
+}
+
+// formatStakeAmount formats the stake amount in Theta for the validators and guardians, and in
+// TFuel for the elite edge nodes
+func formatStakeAmount(role string, amount *common.JSONBig) string {
+	if role == rpc.StakeRoleEliteEdgeNode {
+		return formatWei(amount, "TFuel")
+	}
+	return formatWei(amount, "Theta")
+}
+
+// formatWei formats the amount in Wei as a decimal amount of the token
+func formatWei(amount *common.JSONBig, token string) string {
+	if amount == nil {
+		return "0 " + token
+	}
+	oneToken := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	whole, frac := new(big.Int).QuoRem((*big.Int)(amount), oneToken, new(big.Int))
+	if frac.Sign() == 0 {
+		return fmt.Sprintf("%v %v", whole, token)
+	}
+	fracStr := frac.String()
+	fracStr = strings.TrimRight(strings.Repeat("0", 18-len(fracStr))+fracStr, "0")
+	return fmt.Sprintf("%v.%v %v", whole, fracStr, token)
+}
+
+func init() {
+	stakesCmd.Flags().StringVar(&addressFlag, "address", "", "Address of the staker, guardian, validator or elite edge node")
+	stakesCmd.Flags().Uint64Var(&limitFlag, "reward_checkpoints", 0, "Number of recent checkpoints to collect the rewards from, default to 5")
+	stakesCmd.MarkFlagRequired("address")
+}
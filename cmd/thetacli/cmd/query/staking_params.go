@@ -0,0 +1,39 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// stakingParamsCmd represents the staking_params command.
+// Example:
+//		thetacli query staking_params
+//		thetacli query staking_params --height=10000
+var stakingParamsCmd = &cobra.Command{
+	Use:     "staking_params",
+	Short:   "Get the staking parameters",
+	Long:    `Get the min stakes, pool sizes, return locking period and reward rates of the validators, guardians and elite edge nodes at the given height, along with the forks which change them.`,
+	Example: `thetacli query staking_params`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+		res, err := client.Call("theta.GetStakingParams", rpc.GetStakingParamsArgs{
+			Height: common.JSONUint64(heightFlag),
+		})
+		if err != nil {
+			utils.Error("Failed to get staking params: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.Error("Failed to retrieve staking params: %v\n", res.Error)
+		}
+		utils.PrintResult(res.Result)
+	},
+}
+
+func init() {
+	stakingParamsCmd.Flags().Uint64Var(&heightFlag, "height", uint64(0), "Block height, 0 for the next block")
+}
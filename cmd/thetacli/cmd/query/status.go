@@ -1,7 +1,6 @@
 package query
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
@@ -9,31 +8,33 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // statusCmd represents the account command.
 // Example:
 //		thetacli query status
+//		thetacli query status --watch
 var statusCmd = &cobra.Command{
 	Use:     "status",
 	Short:   "Get blockchain status",
 	Long:    `Get blockchain status.`,
 	Example: `thetacli query status`,
 	Run: func(cmd *cobra.Command, args []string) {
-		client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
-		res, err := client.Call("theta.GetStatus", rpc.GetStatusArgs{})
-		if err != nil {
-			utils.Error("Failed to get blockchain status: %v\n", err)
-		}
-		if res.Error != nil {
-			utils.Error("Failed to retrieve blockchain status: %v\n", res.Error)
-		}
-		json, err := json.MarshalIndent(res.Result, "", "    ")
-		if err != nil {
-			utils.Error("Failed to parse server response: %v\n%v\n", err, string(json))
-		}
-		fmt.Println(string(json))
+		runQuery("blockchain status", func() (interface{}, error) {
+			res, err := client.Call("theta.GetStatus", rpc.GetStatusArgs{})
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get blockchain status: %w", err)
+			}
+			if res.Error != nil {
+				return nil, fmt.Errorf("Failed to retrieve blockchain status: %w", res.Error)
+			}
+			return res.Result, nil
+		})
 	},
 }
+
+func init() {
+	addWatchFlag(statusCmd)
+}
@@ -0,0 +1,70 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// subchainCmd represents the subchain command.
+// Example:
+//		thetacli query subchain --subchain_id=tsub_360777
+var subchainCmd = &cobra.Command{
+	Use:     "subchain",
+	Short:   "Get the subchain anchored to the main chain",
+	Long:    `Get the operator and the latest anchored height of a subchain.`,
+	Example: `thetacli query subchain --subchain_id=tsub_360777`,
+	Run:     doSubchainCmd,
+}
+
+// subchainCheckpointCmd represents the subchain_checkpoint command.
+// Example:
+//		thetacli query subchain_checkpoint --subchain_id=tsub_360777 --height=1000
+var subchainCheckpointCmd = &cobra.Command{
+	Use:     "subchain_checkpoint",
+	Short:   "Get an anchored subchain checkpoint with its proof",
+	Long:    `Get an anchored subchain checkpoint, the latest one if the height is not specified, together with its proof against the state root of a finalized main chain block.`,
+	Example: `thetacli query subchain_checkpoint --subchain_id=tsub_360777 --height=1000`,
+	Run:     doSubchainCheckpointCmd,
+}
+
+func doSubchainCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	res, err := client.Call("theta.GetSubchain", rpc.GetSubchainArgs{SubchainID: subchainIDFlag})
+	if err != nil {
+		utils.Error("Failed to get subchain: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to retrieve subchain: %v\n", res.Error)
+	}
+	utils.PrintResult(res.Result)
+}
+
+func doSubchainCheckpointCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	res, err := client.Call("theta.GetSubchainCheckpoint", rpc.GetSubchainCheckpointArgs{
+		SubchainID: subchainIDFlag,
+		Height:     common.JSONUint64(heightFlag),
+	})
+	if err != nil {
+		utils.Error("Failed to get subchain checkpoint: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to retrieve subchain checkpoint: %v\n", res.Error)
+	}
+	utils.PrintResult(res.Result)
+}
+
+func init() {
+	subchainCmd.Flags().StringVar(&subchainIDFlag, "subchain_id", "", "Chain ID of the subchain")
+	subchainCmd.MarkFlagRequired("subchain_id")
+
+	subchainCheckpointCmd.Flags().StringVar(&subchainIDFlag, "subchain_id", "", "Chain ID of the subchain")
+	subchainCheckpointCmd.Flags().Uint64Var(&heightFlag, "height", uint64(0), "height of the subchain block, the latest anchored one if not specified")
+	subchainCheckpointCmd.MarkFlagRequired("subchain_id")
+}
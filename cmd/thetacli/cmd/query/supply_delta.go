@@ -0,0 +1,42 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// supplyDeltaCmd represents the supply_delta command.
+// Example:
+//		thetacli query supply_delta --start=1000 --end=2000
+var supplyDeltaCmd = &cobra.Command{
+	Use:     "supply_delta",
+	Short:   "Get the coins minted and the fees burned",
+	Long:    `Get the coins minted by the coinbase txs and the tx fees burned over a height window. Defaults to the latest 100 finalized blocks.`,
+	Example: `thetacli query supply_delta --start=1000 --end=2000`,
+	Run:     doSupplyDeltaCmd,
+}
+
+func doSupplyDeltaCmd(cmd *cobra.Command, args []string) {
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	res, err := client.Call("theta.GetSupplyDelta", rpc.GetSupplyDeltaArgs{
+		Start: common.JSONUint64(startFlag),
+		End:   common.JSONUint64(endFlag),
+	})
+	if err != nil {
+		utils.Error("Failed to get supply delta: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Failed to get supply delta: %v\n", res.Error)
+	}
+	utils.PrintResult(res.Result)
+}
+
+func init() {
+	supplyDeltaCmd.Flags().Uint64Var(&startFlag, "start", uint64(0), "starting height of the window")
+	supplyDeltaCmd.Flags().Uint64Var(&endFlag, "end", uint64(0), "ending height of the window")
+}
@@ -0,0 +1,31 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// upgradeCmd represents the upgrade command.
+// Example:
+//		thetacli query upgrade
+var upgradeCmd = &cobra.Command{
+	Use:     "upgrade",
+	Short:   "Get the pending upgrade",
+	Long:    `Get the pending upgrade plan, whether it is implemented by the running binary, and whether the node has halted at the upgrade height.`,
+	Example: `thetacli query upgrade`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+		res, err := client.Call("theta.GetPendingUpgrade", rpc.GetPendingUpgradeArgs{})
+		if err != nil {
+			utils.Error("Failed to get pending upgrade: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.Error("Failed to retrieve pending upgrade: %v\n", res.Error)
+		}
+		utils.PrintResult(res.Result)
+	},
+}
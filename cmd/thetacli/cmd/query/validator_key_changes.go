@@ -0,0 +1,31 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// validatorKeyChangesCmd represents the validator_key_changes command.
+// Example:
+//		thetacli query validator_key_changes
+var validatorKeyChangesCmd = &cobra.Command{
+	Use:     "validator_key_changes",
+	Short:   "Get the pending validator key changes",
+	Long:    `Get the pending changes of the validator signing keys and the heights they take effect.`,
+	Example: `thetacli query validator_key_changes`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+		res, err := client.Call("theta.GetValidatorKeyChanges", rpc.GetValidatorKeyChangesArgs{})
+		if err != nil {
+			utils.Error("Failed to get validator key changes: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.Error("Failed to retrieve validator key changes: %v\n", res.Error)
+		}
+		utils.PrintResult(res.Result)
+	},
+}
@@ -0,0 +1,40 @@
+package query
+
+import (
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// validatorReportCmd represents the validator_report command.
+// Example:
+//		thetacli query validator_report --limit=2000
+var validatorReportCmd = &cobra.Command{
+	Use:   "validator_report",
+	Short: "Get the performance report of the queried validator node",
+	Long: `Get the epochs the queried validator node participated in, its proposals and votes against the ones
+expected of it, the rewards it earned, and the proposals and votes it missed with the reasons, over the recent
+finalized blocks. The reasons are only known for the epochs since the node started.`,
+	Example: `thetacli query validator_report --limit=2000`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+		res, err := client.Call("theta.GetSelfValidatorReport", rpc.GetSelfValidatorReportArgs{
+			NumBlocks: common.JSONUint64(limitFlag),
+		})
+		if err != nil {
+			utils.Error("Failed to get validator report: %v\n", err)
+		}
+		if res.Error != nil {
+			utils.Error("Failed to retrieve validator report: %v\n", res.Error)
+		}
+		utils.PrintResult(res.Result)
+	},
+}
+
+func init() {
+	validatorReportCmd.Flags().Uint64Var(&limitFlag, "limit", uint64(0), "Number of the recent finalized blocks covered, 0 for 1000")
+}
@@ -1,15 +1,10 @@
 package query
 
 import (
-	"encoding/json"
-	"fmt"
-
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/rpc"
-
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // versionCmd represents the version command.
@@ -20,7 +15,7 @@ var versionCmd = &cobra.Command{
 	Short:   "Get the Theta version",
 	Example: `thetacli query version`,
 	Run: func(cmd *cobra.Command, args []string) {
-		client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+		client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
 		res, err := client.Call("theta.GetVersion", rpc.GetVersionArgs{})
 		if err != nil {
@@ -29,10 +24,6 @@ var versionCmd = &cobra.Command{
 		if res.Error != nil {
 			utils.Error("Failed to get version: %v\n", res.Error)
 		}
-		json, err := json.MarshalIndent(res.Result, "", "    ")
-		if err != nil {
-			utils.Error("Failed to parse server response: %v\n%s\n", err, string(json))
-		}
-		fmt.Println(string(json))
+		utils.PrintResult(res.Result)
 	},
 }
@@ -12,10 +12,13 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/call"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/console"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/contract"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/daemon"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/key"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/query"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/tx"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 )
 
 var cfgPath string
@@ -31,8 +34,7 @@ var RootCmd = &cobra.Command{
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	if err := RootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		utils.Exit(utils.ExitCodeUsage, "%v\n", err)
 	}
 }
 
@@ -40,6 +42,8 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	RootCmd.PersistentFlags().StringVar(&cfgPath, "config", getDefaultConfigPath(), fmt.Sprintf("config path (default is %s)", getDefaultConfigPath()))
+	RootCmd.PersistentFlags().String(utils.CfgOutput, utils.OutputText, "Output format (text|json)")
+	viper.BindPFlag(utils.CfgOutput, RootCmd.PersistentFlags().Lookup(utils.CfgOutput))
 
 	RootCmd.AddCommand(daemon.DaemonCmd)
 	RootCmd.AddCommand(key.KeyCmd)
@@ -47,6 +51,8 @@ func init() {
 	RootCmd.AddCommand(query.QueryCmd)
 	RootCmd.AddCommand(call.CallCmd)
 	RootCmd.AddCommand(backup.BackupCmd)
+	RootCmd.AddCommand(console.ConsoleCmd)
+	RootCmd.AddCommand(contract.ContractCmd)
 	RootCmd.AddCommand(versionCmd)
 }
 
@@ -62,7 +68,11 @@ func initConfig() {
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		utils.Progress("Using config file: %v\n", viper.ConfigFileUsed())
+	}
+
+	if output := viper.GetString(utils.CfgOutput); output != utils.OutputText && output != utils.OutputJSON {
+		utils.Exit(utils.ExitCodeUsage, "Invalid output format: %v, must be text or json\n", output)
 	}
 }
 
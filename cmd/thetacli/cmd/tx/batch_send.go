@@ -0,0 +1,100 @@
+package tx
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
+	wtypes "github.com/thetatoken/theta/wallet/types"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// batchSendCmd represents the batch send command. The payout file is a CSV file with one
+// "address,theta,tfuel" line per recipient, lines starting with # are ignored.
+// Example:
+//		thetacli tx batch_send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --payouts=payouts.csv --split
+//		thetacli tx batch_send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --payouts=payouts.csv --split --dry_run
+var batchSendCmd = &cobra.Command{
+	Use:     "batch_send",
+	Short:   "Send tokens to a list of recipients",
+	Example: `thetacli tx batch_send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --payouts=payouts.csv --split`,
+	Run:     doBatchSendCmd,
+}
+
+func doBatchSendCmd(cmd *cobra.Command, args []string) {
+	walletType := getWalletType(cmd)
+	if walletType == wtypes.WalletTypeSoft && len(fromFlag) == 0 {
+		utils.Error("The from address cannot be empty")
+		return
+	}
+
+	payouts, err := readBatchPayouts(payoutsFlag)
+	if err != nil {
+		utils.Error("Failed to read the payouts: %v\n", err)
+	}
+
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil || wallet == nil {
+		return
+	}
+	defer wallet.Lock(fromAddress)
+
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	composed, txs, err := composeBatchTxs(client, fromAddress, payouts, splitFlag)
+	if err != nil {
+		utils.Error("%v\n", err)
+	}
+
+	if dryRunFlag {
+		utils.PrintResultWithMessage(fmt.Sprintf("Composed %v transactions, not broadcasted:", len(composed.Txs)), composed)
+		return
+	}
+
+	for i, batchTx := range composed.Txs {
+		signedTx, err := signBatchTx(wallet, fromAddress, txs[i], chainIDFlag)
+		if err != nil {
+			utils.Error("Failed to sign transaction %v: %v\n", i, err)
+		}
+
+		var res *rpcc.RPCResponse
+		if asyncFlag {
+			res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionAsyncArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+		} else {
+			res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+		}
+		if err != nil {
+			utils.Error("Failed to broadcast transaction %v: %v\n", i, err)
+		}
+		if res.Error != nil {
+			utils.Error("Server returned error for transaction %v: %v\n", i, res.Error)
+		}
+		result := &rpc.BroadcastRawTransactionResult{}
+		err = res.GetObject(result)
+		if err != nil {
+			utils.Error("Failed to parse server response: %v\n", err)
+		}
+		utils.Progress("Successfully broadcasted transaction %v/%v with %v outputs: %v\n",
+			i+1, len(composed.Txs), batchTx.NumOutputs, result.TxHash)
+	}
+}
+
+func init() {
+	batchSendCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	batchSendCmd.Flags().StringVar(&fromFlag, "from", "", "Address to send from")
+	batchSendCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	batchSendCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the first transaction, 0 for the next sequence of the sender")
+	batchSendCmd.Flags().StringVar(&payoutsFlag, "payouts", "", "CSV file with one address,theta,tfuel line per recipient")
+	batchSendCmd.Flags().IntVar(&maxOutputsFlag, "max_outputs", 0, "Max number of recipients per transaction, 0 for the max allowed")
+	batchSendCmd.Flags().BoolVar(&splitFlag, "split", false, "Split the payouts into multiple transactions if they do not fit in one")
+	batchSendCmd.Flags().BoolVar(&dryRunFlag, "dry_run", false, "Print the composed transactions without signing and broadcasting them")
+	batchSendCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano|trezor)")
+	batchSendCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	batchSendCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	batchSendCmd.MarkFlagRequired("chain")
+	batchSendCmd.MarkFlagRequired("payouts")
+}
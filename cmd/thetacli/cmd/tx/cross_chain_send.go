@@ -0,0 +1,108 @@
+package tx
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// crossChainSendCmd represents the cross-chain send command
+// Example:
+//		thetacli tx cross_chain_send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --target_chain_id=tsub_360777 --receiver=9F1233798E905E173560071255140b4A8aBd3Ec6 --data=68656c6c6f --seq=8
+var crossChainSendCmd = &cobra.Command{
+	Use:     "cross_chain_send",
+	Short:   "Send a message to another chain",
+	Long:    `Send a message to an account of another chain. The message is delivered to the target chain by the relayers.`,
+	Example: `thetacli tx cross_chain_send --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --target_chain_id=tsub_360777 --receiver=9F1233798E905E173560071255140b4A8aBd3Ec6 --data=68656c6c6f --seq=8`,
+	Run:     doCrossChainSendCmd,
+}
+
+func doCrossChainSendCmd(cmd *cobra.Command, args []string) {
+	wallet, senderAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil {
+		return
+	}
+	defer wallet.Lock(senderAddress)
+
+	fee, ok := types.ParseCoinAmount(feeFlag)
+	if !ok {
+		utils.Error("Failed to parse fee")
+	}
+
+	data, err := hex.DecodeString(strings.TrimPrefix(dataFlag, "0x"))
+	if err != nil {
+		utils.Error("Failed to parse data: %v\n", err)
+	}
+
+	crossChainSendTx := &types.CrossChainSendTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		Sender: types.TxInput{
+			Address:  senderAddress,
+			Sequence: uint64(seqFlag),
+		},
+		TargetChainID: targetChainIDFlag,
+		Receiver:      common.HexToAddress(receiverFlag),
+		Data:          data,
+	}
+
+	sig, err := wallet.Sign(senderAddress, crossChainSendTx.SignBytes(chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	crossChainSendTx.SetSignature(senderAddress, sig)
+
+	raw, err := types.TxToBytes(crossChainSendTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	signedTx := hex.EncodeToString(raw)
+
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	var res *rpcc.RPCResponse
+	if asyncFlag {
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+	} else {
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+	}
+	if err != nil {
+		utils.Error("Failed to broadcast transaction: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Server returned error: %v\n", res.Error)
+	}
+	utils.PrintSuccess("Successfully broadcasted transaction.", res.Result)
+}
+
+func init() {
+	crossChainSendCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	crossChainSendCmd.Flags().StringVar(&fromFlag, "from", "", "Address of the sender")
+	crossChainSendCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	crossChainSendCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWei), "Fee")
+	crossChainSendCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
+	crossChainSendCmd.Flags().StringVar(&targetChainIDFlag, "target_chain_id", "", "Chain ID of the target chain")
+	crossChainSendCmd.Flags().StringVar(&receiverFlag, "receiver", "", "Address of the receiver on the target chain")
+	crossChainSendCmd.Flags().StringVar(&dataFlag, "data", "", "Hex encoded payload of the message")
+	crossChainSendCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	crossChainSendCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	crossChainSendCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	crossChainSendCmd.MarkFlagRequired("chain")
+	crossChainSendCmd.MarkFlagRequired("from")
+	crossChainSendCmd.MarkFlagRequired("target_chain_id")
+	crossChainSendCmd.MarkFlagRequired("receiver")
+	crossChainSendCmd.MarkFlagRequired("seq")
+}
@@ -167,13 +167,13 @@ func doDepositStakeCmd(cmd *cobra.Command, args []string) {
 	}
 	signedTx := hex.EncodeToString(raw)
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
 	var res *rpcc.RPCResponse
 	if asyncFlag {
-		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
 	} else {
-		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
 	}
 	if err != nil {
 		utils.Error("Failed to broadcast transaction: %v\n", err)
@@ -181,7 +181,7 @@ func doDepositStakeCmd(cmd *cobra.Command, args []string) {
 	if res.Error != nil {
 		utils.Error("Server returned error: %v\n", res.Error)
 	}
-	fmt.Printf("Successfully broadcasted transaction.\n")
+	utils.PrintSuccess("Successfully broadcasted transaction.", res.Result)
 }
 
 func init() {
@@ -36,6 +36,29 @@ var (
 	beneficiaryFlag              string
 	splitBasisPointFlag          uint64
 	passwordFlag                 string
+	parameterFlag                string
+	parameterValueFlag           uint64
+	subchainIDFlag               string
+	subchainHeightFlag           uint64
+	blockHashFlag                string
+	stateHashFlag                string
+	operatorFlag                 string
+	targetChainIDFlag            string
+	receiverFlag                 string
+	newHolderFlag                string
+	newHolderPasswordFlag        string
+	newSourceFlag                string
+	disableFlag                  bool
+	notBeforeFlag                uint64
+	feePayerFlag                 string
+	feePayerPasswordFlag         string
+	payoutsFlag                  string
+	csvFlag                      string
+	reportFlag                   string
+	maxOutputsFlag               int
+	splitFlag                    bool
+	timeoutFlag                  uint64
+	dryRunFlag                   bool
 )
 
 // TxCmd represents the Tx command
@@ -47,6 +70,8 @@ var TxCmd = &cobra.Command{
 
 func init() {
 	TxCmd.AddCommand(sendCmd)
+	TxCmd.AddCommand(batchSendCmd)
+	TxCmd.AddCommand(sendBatchCmd)
 	TxCmd.AddCommand(reserveFundCmd)
 	//TxCmd.AddCommand(releaseFundCmd) // No need for releaseFundCmd since auto-release is already implemented
 	TxCmd.AddCommand(splitRuleCmd)
@@ -54,4 +79,10 @@ func init() {
 	TxCmd.AddCommand(depositStakeCmd)
 	TxCmd.AddCommand(withdrawStakeCmd)
 	TxCmd.AddCommand(stakeRewardDistributionCmd)
+	TxCmd.AddCommand(parameterChangeCmd)
+	TxCmd.AddCommand(subchainRegistrationCmd)
+	TxCmd.AddCommand(subchainCheckpointCmd)
+	TxCmd.AddCommand(crossChainSendCmd)
+	TxCmd.AddCommand(validatorKeyChangeCmd)
+	TxCmd.AddCommand(stakeAutoCompoundingCmd)
 }
@@ -0,0 +1,99 @@
+package tx
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// parameterChangeCmd represents the parameter change command
+// Example:
+//		thetacli tx change_parameter --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --parameter=block_gas_limit --value=300000000 --seq=8
+var parameterChangeCmd = &cobra.Command{
+	Use:     "change_parameter",
+	Short:   "Vote for changing a governed parameter as a validator",
+	Long:    `Vote for changing a governed parameter (block_gas_limit|max_num_txs_per_block) as a validator. The change takes effect after validators with a 2/3 majority of the stake have voted for it.`,
+	Example: `thetacli tx change_parameter --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --parameter=block_gas_limit --value=300000000 --seq=8`,
+	Run:     doParameterChangeCmd,
+}
+
+func doParameterChangeCmd(cmd *cobra.Command, args []string) {
+	wallet, proposerAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil {
+		return
+	}
+	defer wallet.Lock(proposerAddress)
+
+	fee, ok := types.ParseCoinAmount(feeFlag)
+	if !ok {
+		utils.Error("Failed to parse fee")
+	}
+
+	parameterChangeTx := &types.ParameterChangeTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		Proposer: types.TxInput{
+			Address:  proposerAddress,
+			Sequence: uint64(seqFlag),
+		},
+		Parameter: parameterFlag,
+		Value:     parameterValueFlag,
+	}
+
+	sig, err := wallet.Sign(proposerAddress, parameterChangeTx.SignBytes(chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	parameterChangeTx.SetSignature(proposerAddress, sig)
+
+	raw, err := types.TxToBytes(parameterChangeTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	signedTx := hex.EncodeToString(raw)
+
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	var res *rpcc.RPCResponse
+	if asyncFlag {
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+	} else {
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+	}
+	if err != nil {
+		utils.Error("Failed to broadcast transaction: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Server returned error: %v\n", res.Error)
+	}
+	utils.PrintSuccess("Successfully broadcasted transaction.", res.Result)
+}
+
+func init() {
+	parameterChangeCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	parameterChangeCmd.Flags().StringVar(&fromFlag, "from", "", "Address of the validator")
+	parameterChangeCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	parameterChangeCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWei), "Fee")
+	parameterChangeCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
+	parameterChangeCmd.Flags().StringVar(&parameterFlag, "parameter", "", "Name of the parameter (block_gas_limit|max_num_txs_per_block)")
+	parameterChangeCmd.Flags().Uint64Var(&parameterValueFlag, "value", 0, "New value of the parameter")
+	parameterChangeCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	parameterChangeCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	parameterChangeCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	parameterChangeCmd.MarkFlagRequired("chain")
+	parameterChangeCmd.MarkFlagRequired("from")
+	parameterChangeCmd.MarkFlagRequired("parameter")
+	parameterChangeCmd.MarkFlagRequired("value")
+	parameterChangeCmd.MarkFlagRequired("seq")
+}
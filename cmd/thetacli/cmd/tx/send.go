@@ -2,7 +2,6 @@ package tx
 
 import (
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -15,7 +14,6 @@ import (
 	wtypes "github.com/thetatoken/theta/wallet/types"
 
 	"github.com/ybbus/jsonrpc"
-	rpcc "github.com/ybbus/jsonrpc"
 )
 
 // sendCmd represents the send command
@@ -64,10 +62,14 @@ func doSendCmd(cmd *cobra.Command, args []string) {
 	if !ok {
 		utils.Error("Failed to parse fee")
 	}
+	inputTFuel := new(big.Int).Add(tfuel, fee)
+	if len(feePayerFlag) != 0 {
+		inputTFuel = tfuel // the fee is paid by the fee payer
+	}
 	inputs := []types.TxInput{{
 		Address: fromAddress,
 		Coins: types.Coins{
-			TFuelWei: new(big.Int).Add(tfuel, fee),
+			TFuelWei: inputTFuel,
 			ThetaWei: theta,
 		},
 		Sequence: uint64(seqFlag),
@@ -87,26 +89,48 @@ func doSendCmd(cmd *cobra.Command, args []string) {
 		Inputs:  inputs,
 		Outputs: outputs,
 	}
+	if len(feePayerFlag) != 0 {
+		sendTx.FeePayers = []types.FeePayer{{Address: common.HexToAddress(feePayerFlag)}}
+	}
 
-	sig, err := wallet.Sign(fromAddress, sendTx.SignBytes(chainIDFlag))
+	signBytes := sendTx.SignBytes(signChainID())
+	sig, err := wallet.Sign(fromAddress, signBytes)
 	if err != nil {
 		utils.Error("Failed to sign transaction: %v\n", err)
 	}
 	sendTx.SetSignature(fromAddress, sig)
 
+	if len(feePayerFlag) != 0 {
+		feePayerWallet, feePayerAddress, err := SoftWalletUnlock(cmd.Flag("config").Value.String(), feePayerFlag, feePayerPasswordFlag)
+		if err != nil {
+			return
+		}
+		defer feePayerWallet.Lock(feePayerAddress)
+
+		feePayerSig, err := feePayerWallet.Sign(feePayerAddress, signBytes)
+		if err != nil {
+			utils.Error("Failed to sign transaction with the fee payer key: %v\n", err)
+		}
+		sendTx.SetSignature(feePayerAddress, feePayerSig)
+	}
+
 	raw, err := types.TxToBytes(sendTx)
 	if err != nil {
 		utils.Error("Failed to encode transaction: %v\n", err)
 	}
+	raw, err = wrapScheduledTx(raw)
+	if err != nil {
+		utils.Error("Failed to schedule transaction: %v\n", err)
+	}
 	signedTx := hex.EncodeToString(raw)
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
 	var res *jsonrpc.RPCResponse
 	if asyncFlag {
-		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
 	} else {
-		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
 	}
 
 	if err != nil {
@@ -120,11 +144,7 @@ func doSendCmd(cmd *cobra.Command, args []string) {
 	if err != nil {
 		utils.Error("Failed to parse server response: %v\n", err)
 	}
-	formatted, err := json.MarshalIndent(result, "", "    ")
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n", err)
-	}
-	fmt.Printf("Successfully broadcasted transaction:\n%s\n", formatted)
+	utils.PrintResultWithMessage("Successfully broadcasted transaction:", result)
 }
 
 func init() {
@@ -139,6 +159,9 @@ func init() {
 	sendCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano|trezor)")
 	sendCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
 	sendCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+	sendCmd.Flags().StringVar(&feePayerFlag, "fee_payer", "", "Address paying the fee instead of the sender, which needs to be in the soft wallet")
+	sendCmd.Flags().StringVar(&feePayerPasswordFlag, "fee_payer_password", "", "password to unlock the fee payer key")
+	sendCmd.Flags().Uint64Var(&notBeforeFlag, "not_before", 0, "Earliest block height to include the transaction, 0 to include it right away")
 
 	sendCmd.MarkFlagRequired("chain")
 	//sendCmd.MarkFlagRequired("from")
@@ -0,0 +1,426 @@
+package tx
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+	wtypes "github.com/thetatoken/theta/wallet/types"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+const (
+	batchTxStatusSkipped         = "skipped"
+	batchTxStatusBroadcastFailed = "broadcast_failed"
+
+	batchTxPollInterval = 2 * time.Second
+)
+
+// sendBatchCmd represents the batch send command. Each row of the CSV file is either "address,tfuel"
+// or "address,theta,tfuel", rows starting with # are ignored.
+// Example:
+//		thetacli tx sendbatch --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --csv=payouts.csv
+//		thetacli tx sendbatch --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --csv=payouts.csv --split --report=report.csv --timeout=600
+//		thetacli tx sendbatch --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --csv=payouts.csv --dry_run
+var sendBatchCmd = &cobra.Command{
+	Use:     "sendbatch",
+	Short:   "Send tokens to the recipients listed in a CSV file",
+	Example: `thetacli tx sendbatch --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --csv=payouts.csv`,
+	Run:     doSendBatchCmd,
+}
+
+// batchTxRecord tracks a composed transaction of the batch
+type batchTxRecord struct {
+	tx          rpc.BatchSendTx
+	hash        string
+	status      string
+	blockHeight uint64
+}
+
+type sendBatchTxResult struct {
+	Sequence    common.JSONUint64 `json:"sequence"`
+	NumOutputs  int               `json:"num_outputs"`
+	Hash        string            `json:"hash"`
+	Status      string            `json:"status"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+}
+
+type sendBatchResult struct {
+	Report       string              `json:"report"`
+	NumFinalized int                 `json:"num_finalized"`
+	Txs          []sendBatchTxResult `json:"txs"`
+}
+
+func doSendBatchCmd(cmd *cobra.Command, args []string) {
+	walletType := getWalletType(cmd)
+	if walletType == wtypes.WalletTypeSoft && len(fromFlag) == 0 {
+		utils.Error("The from address cannot be empty")
+		return
+	}
+
+	payouts, err := readBatchPayouts(csvFlag)
+	if err != nil {
+		utils.Error("Failed to read the payouts: %v\n", err)
+	}
+
+	wallet, fromAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil || wallet == nil {
+		return
+	}
+	defer wallet.Lock(fromAddress)
+
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	composed, txs, err := composeBatchTxs(client, fromAddress, payouts, splitFlag)
+	if err != nil {
+		utils.Error("%v\n", err)
+	}
+
+	if dryRunFlag {
+		utils.PrintResultWithMessage(fmt.Sprintf("Composed %v transactions, not broadcasted:", len(composed.Txs)), composed)
+		return
+	}
+
+	records := make([]*batchTxRecord, len(composed.Txs))
+	for i, batchTx := range composed.Txs {
+		records[i] = &batchTxRecord{tx: batchTx, status: batchTxStatusSkipped}
+	}
+
+	// The sequences are consecutive, so the remaining transactions are skipped once one fails
+	var failure error
+	for i, record := range records {
+		signedTx, err := signBatchTx(wallet, fromAddress, txs[i], chainIDFlag)
+		if err != nil {
+			failure = fmt.Errorf("Failed to sign transaction %v: %w", i, err)
+			utils.Progress("%v\n", failure)
+			break
+		}
+		res, err := client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionAsyncArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+		if err == nil && res.Error != nil {
+			err = res.Error
+		}
+		result := &rpc.BroadcastRawTransactionAsyncResult{}
+		if err == nil {
+			err = res.GetObject(result)
+		}
+		if err != nil {
+			failure = fmt.Errorf("Failed to broadcast transaction %v: %w", i, err)
+			utils.Progress("%v\n", failure)
+			record.status = batchTxStatusBroadcastFailed
+			break
+		}
+		record.hash = result.TxHash
+		record.status = rpc.TxStatusPending
+		utils.Progress("Broadcasted transaction %v/%v with %v outputs, sequence %v: %v\n",
+			i+1, len(records), record.tx.NumOutputs, record.tx.Sequence, record.hash)
+	}
+
+	trackBatchTxs(client, records, time.Duration(timeoutFlag)*time.Second)
+
+	reportPath := reportFlag
+	if reportPath == "" {
+		reportPath = strings.TrimSuffix(csvFlag, ".csv") + ".report.csv"
+	}
+	if err := writeBatchReport(reportPath, payouts, records); err != nil {
+		utils.Error("Failed to write the report: %v\n", err)
+	}
+
+	result := sendBatchResult{Report: reportPath, Txs: []sendBatchTxResult{}}
+	for _, record := range records {
+		if record.status == rpc.TxStatusFinalized {
+			result.NumFinalized++
+		}
+		result.Txs = append(result.Txs, sendBatchTxResult{
+			Sequence:    record.tx.Sequence,
+			NumOutputs:  record.tx.NumOutputs,
+			Hash:        record.hash,
+			Status:      record.status,
+			BlockHeight: common.JSONUint64(record.blockHeight),
+		})
+	}
+	utils.PrintSuccess(fmt.Sprintf("%v/%v transactions finalized, report written to %v",
+		result.NumFinalized, len(records), reportPath), result)
+
+	if failure != nil {
+		os.Exit(utils.ClassifyError(failure))
+	}
+	if result.NumFinalized != len(records) {
+		os.Exit(utils.ExitCodeError)
+	}
+}
+
+// composeBatchTxs has the node compose the transactions of the payouts, and rebuilds them locally
+func composeBatchTxs(client *rpcc.RPCClient, fromAddress common.Address, payouts []rpc.BatchPayout, split bool) (
+	*rpc.ComposeBatchSendTxResult, []*types.SendTx, error) {
+	res, err := client.Call("theta.ComposeBatchSendTx", rpc.ComposeBatchSendTxArgs{
+		Source:          fromAddress.Hex(),
+		Payouts:         payouts,
+		MaxOutputsPerTx: maxOutputsFlag,
+		Split:           split,
+		Sequence:        common.JSONUint64(seqFlag),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to compose transactions: %w", err)
+	}
+	if res.Error != nil {
+		return nil, nil, fmt.Errorf("Server returned error: %w", res.Error)
+	}
+	composed := &rpc.ComposeBatchSendTxResult{}
+	err = res.GetObject(composed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to parse server response: %v", err)
+	}
+	txs, err := rebuildBatchTxs(fromAddress, payouts, composed, seqFlag)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Rejected the transactions composed by the server: %v", err)
+	}
+	return composed, txs, nil
+}
+
+// rebuildBatchTxs rebuilds the transactions composed by the node from the payouts, so that only
+// transactions paying out exactly the payouts, in order, get signed. The node merely picks how the
+// payouts are split, the sequences and the fees, which are checked as well. A zero firstSeq means
+// the node picks the first sequence.
+func rebuildBatchTxs(fromAddress common.Address, payouts []rpc.BatchPayout, composed *rpc.ComposeBatchSendTxResult, firstSeq uint64) ([]*types.SendTx, error) {
+	outputs, err := rpc.ParseBatchPayouts(fromAddress, payouts)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := []*types.SendTx{}
+	idx := 0
+	sequence := firstSeq
+	for i, batchTx := range composed.Txs {
+		raw, err := hex.DecodeString(batchTx.TxBytes)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %v: %v", i, err)
+		}
+		tx, err := types.TxFromBytes(raw)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %v: %v", i, err)
+		}
+		composedTx, ok := tx.(*types.SendTx)
+		if !ok {
+			return nil, fmt.Errorf("transaction %v is not a send transaction", i)
+		}
+		if len(composedTx.Inputs) != 1 || composedTx.Inputs[0].Address != fromAddress || len(composedTx.FeePayers) != 0 {
+			return nil, fmt.Errorf("transaction %v does not have %v as the only input", i, fromAddress.Hex())
+		}
+
+		numOutputs := len(composedTx.Outputs)
+		if numOutputs == 0 || numOutputs != batchTx.NumOutputs || idx+numOutputs > len(outputs) {
+			return nil, fmt.Errorf("transaction %v has an unexpected number of outputs: %v", i, numOutputs)
+		}
+		expected := outputs[idx : idx+numOutputs]
+		amount := types.NewCoins(0, 0)
+		for j, output := range composedTx.Outputs {
+			if output.Address != expected[j].Address || !output.Coins.IsEqual(expected[j].Coins) {
+				return nil, fmt.Errorf("output %v of transaction %v does not match payout %v", j, i, idx+j)
+			}
+			amount = amount.Plus(expected[j].Coins)
+		}
+		idx += numOutputs
+
+		if i == 0 && sequence == 0 {
+			sequence = composedTx.Inputs[0].Sequence
+		}
+		if composedTx.Inputs[0].Sequence != sequence || uint64(batchTx.Sequence) != sequence {
+			return nil, fmt.Errorf("transaction %v has sequence %v, expected %v", i, composedTx.Inputs[0].Sequence, sequence)
+		}
+
+		fee := composedTx.Fee.NoNil()
+		maxFee := maxBatchTxFee(numOutputs)
+		if fee.ThetaWei.Sign() != 0 || fee.TFuelWei.Sign() <= 0 || fee.TFuelWei.Cmp(maxFee) > 0 {
+			return nil, fmt.Errorf("transaction %v has fee %v, expected at most %vwei TFuel", i, fee, maxFee)
+		}
+
+		txs = append(txs, &types.SendTx{
+			Fee: types.Coins{ThetaWei: big.NewInt(0), TFuelWei: fee.TFuelWei},
+			Inputs: []types.TxInput{{
+				Address:  fromAddress,
+				Coins:    amount.Plus(types.Coins{ThetaWei: big.NewInt(0), TFuelWei: fee.TFuelWei}),
+				Sequence: sequence,
+			}},
+			Outputs: expected,
+		})
+		sequence++
+	}
+	if idx != len(outputs) {
+		return nil, fmt.Errorf("the transactions pay out %v of the %v payouts", idx, len(outputs))
+	}
+	return txs, nil
+}
+
+// maxBatchTxFee returns the highest minimum fee of a send transaction with the given number of
+// outputs under any of the fee rules
+func maxBatchTxFee(numOutputs int) *big.Int {
+	maxFee := new(big.Int).SetUint64(types.MinimumTransactionFeeTFuelWei)
+	fee := types.GetSendTxMinimumTransactionFeeTFuelWei(uint64(numOutputs+1), chainIDFlag, math.MaxUint64)
+	if fee.Cmp(maxFee) > 0 {
+		maxFee = fee
+	}
+	return maxFee
+}
+
+// signBatchTx signs the rebuilt transaction and returns the hex encoded signed transaction
+func signBatchTx(wallet wtypes.Wallet, fromAddress common.Address, sendTx *types.SendTx, chainID string) (string, error) {
+	sig, err := wallet.Sign(fromAddress, sendTx.SignBytes(chainID))
+	if err != nil {
+		return "", err
+	}
+	sendTx.SetSignature(fromAddress, sig)
+
+	raw, err := types.TxToBytes(sendTx)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// trackBatchTxs polls the status of the pending transactions until they are finalized or
+// abandoned, or the timeout is reached
+func trackBatchTxs(client *rpcc.RPCClient, records []*batchTxRecord, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for {
+		numPending := 0
+		for _, record := range records {
+			if record.status != rpc.TxStatusPending {
+				continue
+			}
+			res, err := client.Call("theta.GetTransaction", rpc.GetTransactionArgs{Hash: record.hash})
+			if err != nil || res.Error != nil {
+				numPending++
+				continue
+			}
+			// Only the status and the height are needed, the tx itself is not decoded
+			status := struct {
+				BlockHeight common.JSONUint64 `json:"block_height"`
+				Status      string            `json:"status"`
+			}{}
+			if err := res.GetObject(&status); err != nil {
+				numPending++
+				continue
+			}
+			switch status.Status {
+			case rpc.TxStatusFinalized:
+				record.status = rpc.TxStatusFinalized
+				record.blockHeight = uint64(status.BlockHeight)
+				utils.Progress("Transaction %v finalized at height %v\n", record.hash, record.blockHeight)
+			case rpc.TxStatusAbandoned:
+				record.status = rpc.TxStatusAbandoned
+				utils.Progress("Transaction %v abandoned\n", record.hash)
+			default:
+				numPending++
+			}
+		}
+
+		if numPending == 0 || time.Now().After(deadline) {
+			return
+		}
+		time.Sleep(batchTxPollInterval)
+	}
+}
+
+// readBatchPayouts reads the "address,tfuel" or "address,theta,tfuel" rows of the payout file
+func readBatchPayouts(path string) ([]rpc.BatchPayout, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	payouts := []rpc.BatchPayout{}
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch len(record) {
+		case 2:
+			payouts = append(payouts, rpc.BatchPayout{
+				Address: strings.TrimSpace(record[0]),
+				TFuel:   strings.TrimSpace(record[1]),
+			})
+		case 3:
+			payouts = append(payouts, rpc.BatchPayout{
+				Address: strings.TrimSpace(record[0]),
+				Theta:   strings.TrimSpace(record[1]),
+				TFuel:   strings.TrimSpace(record[2]),
+			})
+		default:
+			return nil, fmt.Errorf("row %v: expected 2 or 3 fields, got %v", row, len(record))
+		}
+	}
+	return payouts, nil
+}
+
+// writeBatchReport writes one row per payout with the transaction it was included in. The
+// composed transactions carry the payouts in their original order.
+func writeBatchReport(path string, payouts []rpc.BatchPayout, records []*batchTxRecord) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"address", "theta", "tfuel", "sequence", "tx_hash", "status", "block_height"})
+	idx := 0
+	for _, record := range records {
+		for j := 0; j < record.tx.NumOutputs && idx < len(payouts); j++ {
+			payout := payouts[idx]
+			writer.Write([]string{
+				payout.Address,
+				payout.Theta,
+				payout.TFuel,
+				strconv.FormatUint(uint64(record.tx.Sequence), 10),
+				record.hash,
+				record.status,
+				strconv.FormatUint(record.blockHeight, 10),
+			})
+			idx++
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func init() {
+	sendBatchCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	sendBatchCmd.Flags().StringVar(&fromFlag, "from", "", "Address to send from")
+	sendBatchCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	sendBatchCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the first transaction, 0 for the next sequence of the sender")
+	sendBatchCmd.Flags().StringVar(&csvFlag, "csv", "", "CSV file with one address,tfuel or address,theta,tfuel row per recipient")
+	sendBatchCmd.Flags().StringVar(&reportFlag, "report", "", "Path of the results report, default to <csv>.report.csv")
+	sendBatchCmd.Flags().IntVar(&maxOutputsFlag, "max_outputs", 0, "Max number of recipients per transaction, 0 for the max allowed")
+	sendBatchCmd.Flags().BoolVar(&splitFlag, "split", false, "Split the payouts into multiple transactions if they do not fit in one")
+	sendBatchCmd.Flags().Uint64Var(&timeoutFlag, "timeout", 300, "Seconds to wait for the transactions to be finalized")
+	sendBatchCmd.Flags().BoolVar(&dryRunFlag, "dry_run", false, "Print the composed transactions without signing and broadcasting them")
+	sendBatchCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano|trezor)")
+	sendBatchCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	sendBatchCmd.MarkFlagRequired("chain")
+	sendBatchCmd.MarkFlagRequired("csv")
+}
@@ -0,0 +1,152 @@
+package tx
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+)
+
+var (
+	batchTestSource  = common.HexToAddress("0x2E833968E5bB786Ae419c4d13189fB081Cc43bab")
+	batchTestPayouts = []rpc.BatchPayout{
+		{Address: "0x9F1233798E905E173560071255140b4A8aBd3Ec6", TFuel: "10"},
+		{Address: "0x4A8aBd3Ec69F1233798E905E173560071255140b", Theta: "1wei", TFuel: "2wei"},
+		{Address: "0x71255140b4A8aBd3Ec69F1233798E905E1735600", Theta: "3"},
+	}
+)
+
+// composeTestBatch composes the payouts the way an honest node does, with at most maxOutputs
+// outputs per transaction, and lets tamper modify each composed transaction
+func composeTestBatch(t *testing.T, maxOutputs int, tamper func(i int, tx *types.SendTx)) *rpc.ComposeBatchSendTxResult {
+	outputs, err := rpc.ParseBatchPayouts(batchTestSource, batchTestPayouts)
+	require.Nil(t, err)
+
+	composed := &rpc.ComposeBatchSendTxResult{}
+	for i, start := 0, 0; start < len(outputs); i, start = i+1, start+maxOutputs {
+		end := start + maxOutputs
+		if end > len(outputs) {
+			end = len(outputs)
+		}
+		fee := types.Coins{ThetaWei: big.NewInt(0), TFuelWei: big.NewInt(int64(types.MinimumTransactionFeeTFuelWei))}
+		amount := types.NewCoins(0, 0)
+		batch := make([]types.TxOutput, end-start)
+		for j, output := range outputs[start:end] {
+			batch[j] = output
+			amount = amount.Plus(output.Coins)
+		}
+		tx := &types.SendTx{
+			Fee: fee,
+			Inputs: []types.TxInput{{
+				Address:  batchTestSource,
+				Coins:    amount.Plus(fee),
+				Sequence: uint64(5 + i),
+			}},
+			Outputs: batch,
+		}
+		if tamper != nil {
+			tamper(i, tx)
+		}
+		raw, err := types.TxToBytes(tx)
+		require.Nil(t, err)
+		composed.Txs = append(composed.Txs, rpc.BatchSendTx{
+			TxBytes:    hex.EncodeToString(raw),
+			SignBytes:  hex.EncodeToString(tx.SignBytes("privatenet")),
+			Sequence:   common.JSONUint64(tx.Inputs[0].Sequence),
+			NumOutputs: len(tx.Outputs),
+		})
+	}
+	return composed
+}
+
+func TestRebuildBatchTxs(t *testing.T) {
+	assert := assert.New(t)
+
+	composed := composeTestBatch(t, 2, nil)
+	txs, err := rebuildBatchTxs(batchTestSource, batchTestPayouts, composed, 0)
+	assert.Nil(err)
+	assert.Equal(2, len(txs))
+	for i, tx := range txs {
+		// The rebuilt transactions are the ones an honest node composes
+		assert.Equal(composed.Txs[i].SignBytes, hex.EncodeToString(tx.SignBytes("privatenet")))
+	}
+	assert.Equal(uint64(6), txs[1].Inputs[0].Sequence)
+
+	_, err = rebuildBatchTxs(batchTestSource, batchTestPayouts, composed, 5)
+	assert.Nil(err)
+	_, err = rebuildBatchTxs(batchTestSource, batchTestPayouts, composed, 7)
+	assert.NotNil(err)
+}
+
+func TestRebuildBatchTxsRejectsTamperedTxs(t *testing.T) {
+	attacker := common.HexToAddress("0x0000000000000000000000000000000000000bad")
+	tests := []struct {
+		name   string
+		tamper func(i int, tx *types.SendTx)
+	}{
+		{"redirected output", func(i int, tx *types.SendTx) { tx.Outputs[0].Address = attacker }},
+		{"changed amount", func(i int, tx *types.SendTx) { tx.Outputs[0].Coins.TFuelWei = big.NewInt(1e18) }},
+		{"extra output", func(i int, tx *types.SendTx) {
+			tx.Outputs = append(tx.Outputs, types.TxOutput{Address: attacker, Coins: types.NewCoins(0, 1)})
+		}},
+		{"other input", func(i int, tx *types.SendTx) { tx.Inputs[0].Address = attacker }},
+		{"extra input", func(i int, tx *types.SendTx) {
+			tx.Inputs = append(tx.Inputs, types.TxInput{Address: attacker, Coins: types.NewCoins(0, 1)})
+		}},
+		{"excessive fee", func(i int, tx *types.SendTx) { tx.Fee.TFuelWei = big.NewInt(0).Mul(big.NewInt(1e18), big.NewInt(1000)) }},
+		{"theta fee", func(i int, tx *types.SendTx) { tx.Fee.ThetaWei = big.NewInt(1) }},
+		{"sequence gap", func(i int, tx *types.SendTx) { tx.Inputs[0].Sequence += uint64(i) }},
+	}
+	for _, test := range tests {
+		composed := composeTestBatch(t, 2, test.tamper)
+		_, err := rebuildBatchTxs(batchTestSource, batchTestPayouts, composed, 0)
+		assert.NotNil(t, err, test.name)
+	}
+
+	// A response leaving out some of the payouts
+	composed := composeTestBatch(t, 2, nil)
+	composed.Txs = composed.Txs[:1]
+	_, err := rebuildBatchTxs(batchTestSource, batchTestPayouts, composed, 0)
+	assert.NotNil(t, err)
+
+	// A response whose transactions are not send transactions
+	composed = composeTestBatch(t, 2, nil)
+	raw, err := types.TxToBytes(&types.CoinbaseTx{Proposer: types.TxInput{Address: batchTestSource, Coins: types.NewCoins(0, 0)}})
+	require.Nil(t, err)
+	composed.Txs[0].TxBytes = hex.EncodeToString(raw)
+	_, err = rebuildBatchTxs(batchTestSource, batchTestPayouts, composed, 0)
+	assert.NotNil(t, err)
+}
+
+func TestReadBatchPayouts(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "sendbatch")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "payouts.csv")
+	content := "# address,theta,tfuel\n" +
+		"0x9F1233798E905E173560071255140b4A8aBd3Ec6, 10\n" +
+		"0x4A8aBd3Ec69F1233798E905E173560071255140b,1wei,2wei\n"
+	require.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	payouts, err := readBatchPayouts(path)
+	assert.Nil(err)
+	assert.Equal([]rpc.BatchPayout{
+		{Address: "0x9F1233798E905E173560071255140b4A8aBd3Ec6", TFuel: "10"},
+		{Address: "0x4A8aBd3Ec69F1233798E905E173560071255140b", Theta: "1wei", TFuel: "2wei"},
+	}, payouts)
+
+	require.Nil(t, ioutil.WriteFile(path, []byte("0x9F1233798E905E173560071255140b4A8aBd3Ec6\n"), 0600))
+	_, err = readBatchPayouts(path)
+	assert.NotNil(err)
+}
@@ -2,7 +2,6 @@ package tx
 
 import (
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -93,13 +92,13 @@ func doSmartContractCmd(cmd *cobra.Command, args []string) {
 	}
 	signedTx := hex.EncodeToString(raw)
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
 	var res *rpcc.RPCResponse
 	if asyncFlag {
-		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
 	} else {
-		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
 	}
 	if err != nil {
 		utils.Error("Failed to broadcast transaction: %v\n", err)
@@ -112,11 +111,7 @@ func doSmartContractCmd(cmd *cobra.Command, args []string) {
 	if err != nil {
 		utils.Error("Failed to parse server response: %v\n", err)
 	}
-	formatted, err := json.MarshalIndent(result, "", "    ")
-	if err != nil {
-		utils.Error("Failed to parse server response: %v\n", err)
-	}
-	fmt.Printf("Successfully broadcasted transaction:\n%s\n", formatted)
+	utils.PrintResultWithMessage("Successfully broadcasted transaction:", result)
 }
 
 func init() {
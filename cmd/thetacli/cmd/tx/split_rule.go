@@ -39,8 +39,7 @@ func doSplitRuleCmd(cmd *cobra.Command, args []string) {
 	}
 
 	if len(addressesFlag) != len(percentagesFlag) {
-		fmt.Println("Should have the same number of addresses and percentages")
-		return
+		utils.Exit(utils.ExitCodeUsage, "Should have the same number of addresses and percentages\n")
 	}
 	var splits []types.Split
 	for idx, addressStr := range addressesFlag {
@@ -48,14 +47,12 @@ func doSplitRuleCmd(cmd *cobra.Command, args []string) {
 
 		address, err := hex.DecodeString(addressStr)
 		if err != nil {
-			fmt.Println("The address must be a hex string")
-			return
+			utils.Exit(utils.ExitCodeUsage, "The address must be a hex string\n")
 		}
 
 		percentage, err := strconv.ParseUint(percentageStr, 10, 32)
 		if err != nil {
-			fmt.Println(err)
-			return
+			utils.Exit(utils.ExitCodeUsage, "Failed to parse percentage: %v\n", err)
 		}
 
 		split := types.Split{
@@ -93,13 +90,13 @@ func doSplitRuleCmd(cmd *cobra.Command, args []string) {
 	}
 	signedTx := hex.EncodeToString(raw)
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
 	var res *rpcc.RPCResponse
 	if asyncFlag {
-		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
 	} else {
-		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
 	}
 	if err != nil {
 		utils.Error("Failed to broadcast transaction: %v\n", err)
@@ -107,7 +104,7 @@ func doSplitRuleCmd(cmd *cobra.Command, args []string) {
 	if res.Error != nil {
 		utils.Error("Server returned error: %v\n", res.Error)
 	}
-	fmt.Printf("Successfully broadcasted transaction.\n")
+	utils.PrintSuccess("Successfully broadcasted transaction.", res.Result)
 }
 
 func init() {
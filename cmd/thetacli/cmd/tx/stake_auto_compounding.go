@@ -0,0 +1,101 @@
+package tx
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// stakeAutoCompoundingCmd represents the stake auto-compounding command
+// Example:
+//		thetacli tx stake_auto_compound --chain="privatenet" --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --holder=0x2aC9f7D8a6d3E8a4F1b0c2e5D7F9a1B3c5E7d9F1 --seq=8
+//		thetacli tx stake_auto_compound --chain="privatenet" --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --holder=0x2aC9f7D8a6d3E8a4F1b0c2e5D7F9a1B3c5E7d9F1 --disable --seq=9
+var stakeAutoCompoundingCmd = &cobra.Command{
+	Use:   "stake_auto_compound",
+	Short: "Compound the TFuel rewards into the elite edge node stake",
+	Long: `Opt in to (or out of, with --disable) compounding the TFuel rewards of the source address into its stake
+to the given elite edge node at distribution time, instead of paying them to the source balance.`,
+	Example: `thetacli tx stake_auto_compound --chain="privatenet" --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --holder=0x2aC9f7D8a6d3E8a4F1b0c2e5D7F9a1B3c5E7d9F1 --seq=8`,
+	Run:     doStakeAutoCompoundingCmd,
+}
+
+func doStakeAutoCompoundingCmd(cmd *cobra.Command, args []string) {
+	wallet, sourceAddress, err := walletUnlockWithPath(cmd, sourceFlag, pathFlag, passwordFlag)
+	if err != nil {
+		return
+	}
+	defer wallet.Lock(sourceAddress)
+
+	fee, ok := types.ParseCoinAmount(feeFlag)
+	if !ok {
+		utils.Error("Failed to parse fee")
+	}
+
+	stakeAutoCompoundingTx := &types.StakeAutoCompoundingTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		Source: types.TxInput{
+			Address:  sourceAddress,
+			Sequence: uint64(seqFlag),
+		},
+		Holder:  common.HexToAddress(holderFlag),
+		Enabled: !disableFlag,
+	}
+
+	sig, err := wallet.Sign(sourceAddress, stakeAutoCompoundingTx.SignBytes(chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	stakeAutoCompoundingTx.SetSignature(sourceAddress, sig)
+
+	raw, err := types.TxToBytes(stakeAutoCompoundingTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	signedTx := hex.EncodeToString(raw)
+
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	var res *rpcc.RPCResponse
+	if asyncFlag {
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+	} else {
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+	}
+	if err != nil {
+		utils.Error("Failed to broadcast transaction: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Server returned error: %v\n", res.Error)
+	}
+	utils.PrintSuccess("Successfully broadcasted transaction.", res.Result)
+}
+
+func init() {
+	stakeAutoCompoundingCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	stakeAutoCompoundingCmd.Flags().StringVar(&sourceFlag, "source", "", "Source of the stake")
+	stakeAutoCompoundingCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	stakeAutoCompoundingCmd.Flags().StringVar(&holderFlag, "holder", "", "Elite edge node the rewards are staked to")
+	stakeAutoCompoundingCmd.Flags().BoolVar(&disableFlag, "disable", false, "Opt out of auto-compounding")
+	stakeAutoCompoundingCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWei), "Fee")
+	stakeAutoCompoundingCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
+	stakeAutoCompoundingCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	stakeAutoCompoundingCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	stakeAutoCompoundingCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	stakeAutoCompoundingCmd.MarkFlagRequired("chain")
+	stakeAutoCompoundingCmd.MarkFlagRequired("source")
+	stakeAutoCompoundingCmd.MarkFlagRequired("holder")
+	stakeAutoCompoundingCmd.MarkFlagRequired("seq")
+}
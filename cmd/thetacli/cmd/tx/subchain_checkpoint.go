@@ -0,0 +1,105 @@
+package tx
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// subchainCheckpointCmd represents the subchain checkpoint command
+// Example:
+//		thetacli tx subchain_checkpoint --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --subchain_id=tsub_360777 --height=1000 --block_hash=0x8f6e... --state_hash=0x3a1c... --seq=8
+var subchainCheckpointCmd = &cobra.Command{
+	Use:     "subchain_checkpoint",
+	Short:   "Anchor a finalized subchain block to the main chain",
+	Long:    `Anchor a finalized subchain block to the main chain. The subchain needs to be registered by the validators, and only its operator can anchor its blocks with increasing heights.`,
+	Example: `thetacli tx subchain_checkpoint --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --subchain_id=tsub_360777 --height=1000 --block_hash=0x8f6e... --state_hash=0x3a1c... --seq=8`,
+	Run:     doSubchainCheckpointCmd,
+}
+
+func doSubchainCheckpointCmd(cmd *cobra.Command, args []string) {
+	wallet, submitterAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil {
+		return
+	}
+	defer wallet.Lock(submitterAddress)
+
+	fee, ok := types.ParseCoinAmount(feeFlag)
+	if !ok {
+		utils.Error("Failed to parse fee")
+	}
+
+	subchainCheckpointTx := &types.SubchainCheckpointTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		Submitter: types.TxInput{
+			Address:  submitterAddress,
+			Sequence: uint64(seqFlag),
+		},
+		SubchainID: subchainIDFlag,
+		Height:     subchainHeightFlag,
+		BlockHash:  common.HexToHash(blockHashFlag),
+		StateHash:  common.HexToHash(stateHashFlag),
+	}
+
+	sig, err := wallet.Sign(submitterAddress, subchainCheckpointTx.SignBytes(chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	subchainCheckpointTx.SetSignature(submitterAddress, sig)
+
+	raw, err := types.TxToBytes(subchainCheckpointTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	signedTx := hex.EncodeToString(raw)
+
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	var res *rpcc.RPCResponse
+	if asyncFlag {
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+	} else {
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+	}
+	if err != nil {
+		utils.Error("Failed to broadcast transaction: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Server returned error: %v\n", res.Error)
+	}
+	utils.PrintSuccess("Successfully broadcasted transaction.", res.Result)
+}
+
+func init() {
+	subchainCheckpointCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	subchainCheckpointCmd.Flags().StringVar(&fromFlag, "from", "", "Address of the subchain operator")
+	subchainCheckpointCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	subchainCheckpointCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWei), "Fee")
+	subchainCheckpointCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
+	subchainCheckpointCmd.Flags().StringVar(&subchainIDFlag, "subchain_id", "", "Chain ID of the subchain")
+	subchainCheckpointCmd.Flags().Uint64Var(&subchainHeightFlag, "height", 0, "Height of the finalized subchain block")
+	subchainCheckpointCmd.Flags().StringVar(&blockHashFlag, "block_hash", "", "Hash of the finalized subchain block")
+	subchainCheckpointCmd.Flags().StringVar(&stateHashFlag, "state_hash", "", "State root of the finalized subchain block")
+	subchainCheckpointCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	subchainCheckpointCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	subchainCheckpointCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	subchainCheckpointCmd.MarkFlagRequired("chain")
+	subchainCheckpointCmd.MarkFlagRequired("from")
+	subchainCheckpointCmd.MarkFlagRequired("subchain_id")
+	subchainCheckpointCmd.MarkFlagRequired("height")
+	subchainCheckpointCmd.MarkFlagRequired("block_hash")
+	subchainCheckpointCmd.MarkFlagRequired("seq")
+}
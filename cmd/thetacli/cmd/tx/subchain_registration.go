@@ -0,0 +1,100 @@
+package tx
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// subchainRegistrationCmd represents the subchain registration command
+// Example:
+//		thetacli tx register_subchain --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --subchain_id=tsub_360777 --operator=0x70f587259738cB626A1720Af7038B8DcDb6a42a0 --seq=8
+var subchainRegistrationCmd = &cobra.Command{
+	Use:     "register_subchain",
+	Short:   "Vote for registering a subchain with its operator as a validator",
+	Long:    `Vote for registering a subchain with its operator, or for replacing the operator of a registered subchain, as a validator. The registration takes effect after validators with a 2/3 majority of the stake have voted for it.`,
+	Example: `thetacli tx register_subchain --chain="privatenet" --from=2E833968E5bB786Ae419c4d13189fB081Cc43bab --subchain_id=tsub_360777 --operator=0x70f587259738cB626A1720Af7038B8DcDb6a42a0 --seq=8`,
+	Run:     doSubchainRegistrationCmd,
+}
+
+func doSubchainRegistrationCmd(cmd *cobra.Command, args []string) {
+	wallet, proposerAddress, err := walletUnlockWithPath(cmd, fromFlag, pathFlag, passwordFlag)
+	if err != nil {
+		return
+	}
+	defer wallet.Lock(proposerAddress)
+
+	fee, ok := types.ParseCoinAmount(feeFlag)
+	if !ok {
+		utils.Error("Failed to parse fee")
+	}
+
+	subchainRegistrationTx := &types.SubchainRegistrationTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		Proposer: types.TxInput{
+			Address:  proposerAddress,
+			Sequence: uint64(seqFlag),
+		},
+		SubchainID: subchainIDFlag,
+		Operator:   common.HexToAddress(operatorFlag),
+	}
+
+	sig, err := wallet.Sign(proposerAddress, subchainRegistrationTx.SignBytes(chainIDFlag))
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	subchainRegistrationTx.SetSignature(proposerAddress, sig)
+
+	raw, err := types.TxToBytes(subchainRegistrationTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	signedTx := hex.EncodeToString(raw)
+
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	var res *rpcc.RPCResponse
+	if asyncFlag {
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+	} else {
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+	}
+	if err != nil {
+		utils.Error("Failed to broadcast transaction: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Server returned error: %v\n", res.Error)
+	}
+	utils.PrintSuccess("Successfully broadcasted transaction.", res.Result)
+}
+
+func init() {
+	subchainRegistrationCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	subchainRegistrationCmd.Flags().StringVar(&fromFlag, "from", "", "Address of the validator")
+	subchainRegistrationCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	subchainRegistrationCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWei), "Fee")
+	subchainRegistrationCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
+	subchainRegistrationCmd.Flags().StringVar(&subchainIDFlag, "subchain_id", "", "Chain ID of the subchain")
+	subchainRegistrationCmd.Flags().StringVar(&operatorFlag, "operator", "", "Address of the subchain operator")
+	subchainRegistrationCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	subchainRegistrationCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	subchainRegistrationCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	subchainRegistrationCmd.MarkFlagRequired("chain")
+	subchainRegistrationCmd.MarkFlagRequired("from")
+	subchainRegistrationCmd.MarkFlagRequired("subchain_id")
+	subchainRegistrationCmd.MarkFlagRequired("operator")
+	subchainRegistrationCmd.MarkFlagRequired("seq")
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
+	ttypes "github.com/thetatoken/theta/ledger/types"
 	"github.com/thetatoken/theta/wallet"
 	"github.com/thetatoken/theta/wallet/types"
 	wtypes "github.com/thetatoken/theta/wallet/types"
@@ -43,25 +44,25 @@ func walletUnlockWithPath(cmd *cobra.Command, addressStr string, path string, pa
 func ColdWalletUnlock(walletType wtypes.WalletType, derivationPath types.DerivationPath) (wtypes.Wallet, common.Address, error) {
 	wallet, err := wallet.OpenWallet("", walletType, true)
 	if err != nil {
-		fmt.Printf("Failed to open wallet: %v\n", err)
+		utils.Error("Failed to open wallet: %v\n", err)
 		return nil, common.Address{}, err
 	}
 
 	err = wallet.Unlock(common.Address{}, "", derivationPath)
 	if err != nil {
-		fmt.Printf("Failed to unlock wallet: %v\n", err)
+		utils.Error("Failed to unlock wallet: %v\n", err)
 		return nil, common.Address{}, err
 	}
 
 	addresses, err := wallet.List()
 	if err != nil {
-		fmt.Printf("Failed to list wallet addresses: %v\n", err)
+		utils.Error("Failed to list wallet addresses: %v\n", err)
 		return nil, common.Address{}, err
 	}
 
 	if len(addresses) == 0 {
 		errMsg := fmt.Sprintf("No address detected in the wallet\n")
-		fmt.Printf(errMsg)
+		utils.Error(errMsg)
 		return nil, common.Address{}, fmt.Errorf(errMsg)
 	}
 	address := addresses[0]
@@ -74,7 +75,7 @@ func ColdWalletUnlock(walletType wtypes.WalletType, derivationPath types.Derivat
 func SoftWalletUnlock(cfgPath, addressStr string, password string) (wtypes.Wallet, common.Address, error) {
 	wallet, err := wallet.OpenWallet(cfgPath, wtypes.WalletTypeSoft, true)
 	if err != nil {
-		fmt.Printf("Failed to open wallet: %v\n", err)
+		utils.Error("Failed to open wallet: %v\n", err)
 		return nil, common.Address{}, err
 	}
 
@@ -82,7 +83,7 @@ func SoftWalletUnlock(cfgPath, addressStr string, password string) (wtypes.Walle
 		prompt := fmt.Sprintf("Please enter password: ")
 		password, err = utils.GetPassword(prompt)
 		if err != nil {
-			fmt.Printf("Failed to get password: %v\n", err)
+			utils.Error("Failed to get password: %v\n", err)
 			return nil, common.Address{}, err
 		}
 	}
@@ -90,7 +91,7 @@ func SoftWalletUnlock(cfgPath, addressStr string, password string) (wtypes.Walle
 	address := common.HexToAddress(addressStr)
 	err = wallet.Unlock(address, password, nil)
 	if err != nil {
-		fmt.Printf("Failed to unlock address %v: %v\n", address.Hex(), err)
+		utils.Error("Failed to unlock address %v: %v\n", address.Hex(), err)
 		return nil, common.Address{}, err
 	}
 
@@ -162,3 +163,23 @@ func strToHarden(x string) (int, error) {
 func H(x int) int {
 	return x | HARDENED_FLAG
 }
+
+// signChainID returns the chain ID to sign the transaction for, which differs from the chain ID of the
+// chain if the transaction is scheduled with the --not_before flag
+func signChainID() string {
+	if notBeforeFlag == 0 {
+		return chainIDFlag
+	}
+	return ttypes.ScheduledTxChainID(chainIDFlag, notBeforeFlag)
+}
+
+// wrapScheduledTx wraps the signed transaction into a scheduled transaction if the --not_before flag is set
+func wrapScheduledTx(raw []byte) ([]byte, error) {
+	if notBeforeFlag == 0 {
+		return raw, nil
+	}
+	return ttypes.TxToBytes(&ttypes.ScheduledTx{
+		NotBefore: notBeforeFlag,
+		Tx:        raw,
+	})
+}
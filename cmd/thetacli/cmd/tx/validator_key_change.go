@@ -0,0 +1,120 @@
+package tx
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rpc"
+
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// validatorKeyChangeCmd represents the validator key change command
+// Example:
+//		thetacli tx change_validator_key --chain="privatenet" --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --holder=2E833968E5bB786Ae419c4d13189fB081Cc43bab --new_holder=9F1233798E905E173560071255140b4A8aBd3Ec6 --seq=8
+var validatorKeyChangeCmd = &cobra.Command{
+	Use:   "change_validator_key",
+	Short: "Move the stakes of a validator to a new signing key",
+	Long: `Move the stakes of a validator to a new holder address, i.e. a new signing key, without unstaking. The
+transaction is signed by the source of the stakes and by the new holder key, which needs to be in the soft wallet.
+The change takes effect after a delay of about one day, when the node needs to be restarted with the new key.`,
+	Example: `thetacli tx change_validator_key --chain="privatenet" --source=2E833968E5bB786Ae419c4d13189fB081Cc43bab --holder=2E833968E5bB786Ae419c4d13189fB081Cc43bab --new_holder=9F1233798E905E173560071255140b4A8aBd3Ec6 --seq=8`,
+	Run:     doValidatorKeyChangeCmd,
+}
+
+func doValidatorKeyChangeCmd(cmd *cobra.Command, args []string) {
+	wallet, sourceAddress, err := walletUnlockWithPath(cmd, sourceFlag, pathFlag, passwordFlag)
+	if err != nil {
+		return
+	}
+	defer wallet.Lock(sourceAddress)
+
+	newHolderWallet, newHolderAddress, err := SoftWalletUnlock(cmd.Flag("config").Value.String(), newHolderFlag, newHolderPasswordFlag)
+	if err != nil {
+		return
+	}
+	defer newHolderWallet.Lock(newHolderAddress)
+
+	fee, ok := types.ParseCoinAmount(feeFlag)
+	if !ok {
+		utils.Error("Failed to parse fee")
+	}
+
+	validatorKeyChangeTx := &types.ValidatorKeyChangeTx{
+		Fee: types.Coins{
+			ThetaWei: new(big.Int).SetUint64(0),
+			TFuelWei: fee,
+		},
+		Source: types.TxInput{
+			Address:  sourceAddress,
+			Sequence: uint64(seqFlag),
+		},
+		Holder:    common.HexToAddress(holderFlag),
+		NewHolder: newHolderAddress,
+	}
+	if newSourceFlag != "" {
+		validatorKeyChangeTx.NewSource = common.HexToAddress(newSourceFlag)
+	}
+
+	signBytes := validatorKeyChangeTx.SignBytes(chainIDFlag)
+	sig, err := wallet.Sign(sourceAddress, signBytes)
+	if err != nil {
+		utils.Error("Failed to sign transaction: %v\n", err)
+	}
+	validatorKeyChangeTx.SetSignature(sourceAddress, sig)
+
+	newHolderSig, err := newHolderWallet.Sign(newHolderAddress, signBytes)
+	if err != nil {
+		utils.Error("Failed to sign transaction with the new holder key: %v\n", err)
+	}
+	validatorKeyChangeTx.SetSignature(newHolderAddress, newHolderSig)
+
+	raw, err := types.TxToBytes(validatorKeyChangeTx)
+	if err != nil {
+		utils.Error("Failed to encode transaction: %v\n", err)
+	}
+	signedTx := hex.EncodeToString(raw)
+
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+
+	var res *rpcc.RPCResponse
+	if asyncFlag {
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+	} else {
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
+	}
+	if err != nil {
+		utils.Error("Failed to broadcast transaction: %v\n", err)
+	}
+	if res.Error != nil {
+		utils.Error("Server returned error: %v\n", res.Error)
+	}
+	utils.PrintSuccess("Successfully broadcasted transaction.", res.Result)
+}
+
+func init() {
+	validatorKeyChangeCmd.Flags().StringVar(&chainIDFlag, "chain", "", "Chain ID")
+	validatorKeyChangeCmd.Flags().StringVar(&sourceFlag, "source", "", "Source of the stakes")
+	validatorKeyChangeCmd.Flags().StringVar(&pathFlag, "path", "", "Wallet derivation path")
+	validatorKeyChangeCmd.Flags().StringVar(&holderFlag, "holder", "", "Current holder address of the validator")
+	validatorKeyChangeCmd.Flags().StringVar(&newHolderFlag, "new_holder", "", "New holder address of the validator")
+	validatorKeyChangeCmd.Flags().StringVar(&newHolderPasswordFlag, "new_holder_password", "", "password to unlock the new holder key")
+	validatorKeyChangeCmd.Flags().StringVar(&newSourceFlag, "new_source", "", "New address the stakes are returned to (optional)")
+	validatorKeyChangeCmd.Flags().StringVar(&feeFlag, "fee", fmt.Sprintf("%dwei", types.MinimumTransactionFeeTFuelWei), "Fee")
+	validatorKeyChangeCmd.Flags().Uint64Var(&seqFlag, "seq", 0, "Sequence number of the transaction")
+	validatorKeyChangeCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
+	validatorKeyChangeCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
+	validatorKeyChangeCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+
+	validatorKeyChangeCmd.MarkFlagRequired("chain")
+	validatorKeyChangeCmd.MarkFlagRequired("source")
+	validatorKeyChangeCmd.MarkFlagRequired("holder")
+	validatorKeyChangeCmd.MarkFlagRequired("new_holder")
+	validatorKeyChangeCmd.MarkFlagRequired("seq")
+}
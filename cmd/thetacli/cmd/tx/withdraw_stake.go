@@ -55,7 +55,7 @@ func doWithdrawStakeCmd(cmd *cobra.Command, args []string) {
 		Purpose: purposeFlag,
 	}
 
-	sig, err := wallet.Sign(sourceAddress, withdrawStakeTx.SignBytes(chainIDFlag))
+	sig, err := wallet.Sign(sourceAddress, withdrawStakeTx.SignBytes(signChainID()))
 	if err != nil {
 		utils.Error("Failed to sign transaction: %v\n", err)
 	}
@@ -65,15 +65,19 @@ func doWithdrawStakeCmd(cmd *cobra.Command, args []string) {
 	if err != nil {
 		utils.Error("Failed to encode transaction: %v\n", err)
 	}
+	raw, err = wrapScheduledTx(raw)
+	if err != nil {
+		utils.Error("Failed to schedule transaction: %v\n", err)
+	}
 	signedTx := hex.EncodeToString(raw)
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
 	var res *rpcc.RPCResponse
 	if asyncFlag {
-		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+		res, err = client.Call("theta.BroadcastRawTransactionAsync", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
 	} else {
-		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+		res, err = client.Call("theta.BroadcastRawTransaction", rpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: chainIDFlag})
 	}
 	if err != nil {
 		utils.Error("Failed to broadcast transaction: %v\n", err)
@@ -81,7 +85,7 @@ func doWithdrawStakeCmd(cmd *cobra.Command, args []string) {
 	if res.Error != nil {
 		utils.Error("Server returned error: %v\n", res.Error)
 	}
-	fmt.Printf("Successfully broadcasted transaction.\n")
+	utils.PrintSuccess("Successfully broadcasted transaction.", res.Result)
 }
 
 func init() {
@@ -95,6 +99,7 @@ func init() {
 	withdrawStakeCmd.Flags().StringVar(&walletFlag, "wallet", "soft", "Wallet type (soft|nano)")
 	withdrawStakeCmd.Flags().BoolVar(&asyncFlag, "async", false, "block until tx has been included in the blockchain")
 	withdrawStakeCmd.Flags().StringVar(&passwordFlag, "password", "", "password to unlock the wallet")
+	withdrawStakeCmd.Flags().Uint64Var(&notBeforeFlag, "not_before", 0, "Earliest block height to include the transaction, 0 to include it right away")
 
 	withdrawStakeCmd.MarkFlagRequired("chain")
 	withdrawStakeCmd.MarkFlagRequired("source")
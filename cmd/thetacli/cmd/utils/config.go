@@ -5,9 +5,11 @@ import "github.com/spf13/viper"
 const (
 	CfgRemoteRPCEndpoint = "remoteRPCEndpoint"
 	CfgDebug             = "debug"
+	CfgOutput            = "output"
 )
 
 func init() {
 	viper.SetDefault(CfgRemoteRPCEndpoint, "http://localhost:16888/rpc")
 	viper.SetDefault(CfgDebug, false)
+	viper.SetDefault(CfgOutput, OutputText)
 }
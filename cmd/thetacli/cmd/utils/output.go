@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common/result"
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// Exit codes of thetacli. Scripts can rely on them to tell the common failure classes apart.
+const (
+	ExitCodeOK               = 0
+	ExitCodeError            = 1 // unclassified failure
+	ExitCodeUsage            = 2 // invalid command, flag or argument
+	ExitCodeNotFound         = 3 // account, transaction, block, etc. not found
+	ExitCodeInsufficientFund = 4
+	ExitCodeInvalidSequence  = 5
+	ExitCodeRPCUnavailable   = 6 // failed to reach the remote RPC endpoint
+)
+
+// ErrorClass names the failure class of an exit code in the JSON output
+var ErrorClass = map[int]string{
+	ExitCodeError:            "error",
+	ExitCodeUsage:            "usage",
+	ExitCodeNotFound:         "not_found",
+	ExitCodeInsufficientFund: "insufficient_fund",
+	ExitCodeInvalidSequence:  "invalid_sequence",
+	ExitCodeRPCUnavailable:   "rpc_unavailable",
+}
+
+//
+// Output is the schema of the JSON output. Exactly one of Result and Error is set.
+//
+type Output struct {
+	Result interface{}  `json:"result,omitempty"`
+	Error  *OutputError `json:"error,omitempty"`
+}
+
+type OutputError struct {
+	Class    string `json:"class"`
+	ExitCode int    `json:"exit_code"`
+	Message  string `json:"message"`
+}
+
+// IsJSONOutput returns true if the command output is in JSON
+func IsJSONOutput() bool {
+	return viper.GetString(CfgOutput) == OutputJSON
+}
+
+// PrintResult prints the result as indented JSON
+func PrintResult(result interface{}) {
+	if IsJSONOutput() {
+		printOutput(Output{Result: result})
+		return
+	}
+	formatted, err := json.MarshalIndent(result, "", "    ")
+	if err != nil {
+		Error("Failed to parse server response: %v\n", err)
+	}
+	fmt.Println(string(formatted))
+}
+
+// PrintResultWithMessage prints the message followed by the result in the text mode, and only
+// the result in the JSON mode
+func PrintResultWithMessage(msg string, result interface{}) {
+	if IsJSONOutput() {
+		printOutput(Output{Result: result})
+		return
+	}
+	formatted, err := json.MarshalIndent(result, "", "    ")
+	if err != nil {
+		Error("Failed to parse server response: %v\n", err)
+	}
+	fmt.Printf("%s\n%s\n", msg, formatted)
+}
+
+// PrintSuccess prints the message in the text mode, and the result in the JSON mode
+func PrintSuccess(msg string, result interface{}) {
+	if IsJSONOutput() {
+		printOutput(Output{Result: result})
+		return
+	}
+	fmt.Println(msg)
+}
+
+// Progress prints the progress of a long running command. In the JSON mode, the progress goes
+// to stderr to keep stdout parsable.
+func Progress(msg string, args ...interface{}) {
+	if IsJSONOutput() {
+		fmt.Fprintf(os.Stderr, msg, args...)
+		return
+	}
+	fmt.Printf(msg, args...)
+}
+
+// Exit prints the error and exits with the given exit code
+func Exit(exitCode int, msg string, args ...interface{}) {
+	if IsJSONOutput() {
+		printOutput(Output{Error: &OutputError{
+			Class:    ErrorClass[exitCode],
+			ExitCode: exitCode,
+			Message:  strings.TrimSpace(fmt.Sprintf(msg, args...)),
+		}})
+	} else {
+		fmt.Fprintf(os.Stderr, msg, args...)
+	}
+	os.Exit(exitCode)
+}
+
+// ClassifyError determines the exit code of the error. The errors returned by the remote RPC
+// endpoint are classified by the result code they carry, and the errors of the HTTP transport
+// mean the endpoint is unavailable.
+func ClassifyError(err error) int {
+	if err == nil {
+		return ExitCodeOK
+	}
+	var rpcErr *rpcc.RPCError
+	if errors.As(err, &rpcErr) {
+		switch resultCode(rpcErr) {
+		case result.CodeInvalidSequence:
+			return ExitCodeInvalidSequence
+		case result.CodeInsufficientFund, result.CodeNotEnoughBalanceToStake:
+			return ExitCodeInsufficientFund
+		case result.CodeNotFound:
+			return ExitCodeNotFound
+		}
+		return ExitCodeError
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ExitCodeRPCUnavailable
+	}
+	return ExitCodeError
+}
+
+// resultCode returns the result code carried by the data of the RPC error, or CodeOK if none
+func resultCode(rpcErr *rpcc.RPCError) result.ErrorCode {
+	if rpcErr.Data == nil {
+		return result.CodeOK
+	}
+	raw, err := json.Marshal(rpcErr.Data)
+	if err != nil {
+		return result.CodeOK
+	}
+	data := result.ErrorData{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return result.CodeOK
+	}
+	return data.ResultCode
+}
+
+// classifyArgs classifies the first error in the arguments
+func classifyArgs(args []interface{}) int {
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			return ClassifyError(err)
+		}
+	}
+	return ExitCodeError
+}
+
+func printOutput(output Output) {
+	formatted, err := json.MarshalIndent(output, "", "    ")
+	if err != nil {
+		formatted, _ = json.Marshal(Output{Error: &OutputError{
+			Class:    ErrorClass[ExitCodeError],
+			ExitCode: ExitCodeError,
+			Message:  err.Error(),
+		}})
+	}
+	fmt.Println(string(formatted))
+}
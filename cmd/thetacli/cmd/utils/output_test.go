@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// decodeRPCError decodes the error object the way the RPC client does
+func decodeRPCError(t *testing.T, raw string) *rpcc.RPCError {
+	decoder := json.NewDecoder(bytes.NewBufferString(raw))
+	decoder.UseNumber()
+	rpcErr := &rpcc.RPCError{}
+	require.Nil(t, decoder.Decode(rpcErr))
+	return rpcErr
+}
+
+func TestClassifyRPCError(t *testing.T) {
+	assert := assert.New(t)
+
+	sequenceErr := decodeRPCError(t, `{"code":-32000,"message":"ValidateInputAdvanced: Got 3, expected 2. (acc.seq=1)","data":{"result_code":100002}}`)
+	assert.Equal(ExitCodeInvalidSequence, ClassifyError(sequenceErr))
+
+	fundErr := decodeRPCError(t, `{"code":-32000,"message":"Source balance is 1, but the payouts and fees require 2","data":{"result_code":100003}}`)
+	assert.Equal(ExitCodeInsufficientFund, ClassifyError(fundErr))
+
+	stakeErr := decodeRPCError(t, `{"code":-32000,"message":"Not enough balance to stake","data":{"result_code":106004}}`)
+	assert.Equal(ExitCodeInsufficientFund, ClassifyError(stakeErr))
+
+	notFoundErr := decodeRPCError(t, `{"code":-32000,"message":"Account with address 0x01 is not found","data":{"result_code":108001}}`)
+	assert.Equal(ExitCodeNotFound, ClassifyError(notFoundErr))
+
+	// The wrapped errors are classified by the RPC error they wrap
+	assert.Equal(ExitCodeNotFound, ClassifyError(fmt.Errorf("Failed to get account details: %w", notFoundErr)))
+
+	// The message is not taken into account
+	noCodeErr := decodeRPCError(t, `{"code":-32000,"message":"Insufficient fund: invalid sequence, not found"}`)
+	assert.Equal(ExitCodeError, ClassifyError(noCodeErr))
+	otherCodeErr := decodeRPCError(t, `{"code":-32000,"message":"Invalid signature","data":{"result_code":100001}}`)
+	assert.Equal(ExitCodeError, ClassifyError(otherCodeErr))
+	malformedErr := decodeRPCError(t, `{"code":-32603,"message":"internal error","data":"GetAccount failed"}`)
+	assert.Equal(ExitCodeError, ClassifyError(malformedErr))
+}
+
+func TestClassifyTransportError(t *testing.T) {
+	assert := assert.New(t)
+
+	err := &url.Error{
+		Op:  "Post",
+		URL: "http://localhost:16888/rpc",
+		Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+	}
+	assert.Equal(ExitCodeRPCUnavailable, ClassifyError(err))
+	assert.Equal(ExitCodeRPCUnavailable, ClassifyError(fmt.Errorf("Failed to get blockchain status: %w", err)))
+
+	assert.Equal(ExitCodeOK, ClassifyError(nil))
+	assert.Equal(ExitCodeError, ClassifyError(errors.New("connection refused")))
+}
+
+func TestClassifyArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	notFoundErr := decodeRPCError(t, `{"code":-32000,"message":"not found","data":{"result_code":108001}}`)
+	assert.Equal(ExitCodeNotFound, classifyArgs([]interface{}{"0x01", notFoundErr}))
+	assert.Equal(ExitCodeError, classifyArgs([]interface{}{"0x01", 3}))
+	assert.Equal(ExitCodeError, classifyArgs(nil))
+}
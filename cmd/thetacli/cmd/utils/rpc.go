@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"github.com/thetatoken/theta/rpc/lib/ipc"
+	rpcc "github.com/ybbus/jsonrpc"
+)
+
+// NewRPCClient creates the client of the remote RPC endpoint, which is either the HTTP URL of the node, or
+// the IPC socket of a local node, e.g. unix:///home/theta/node/theta.ipc
+func NewRPCClient(endpoint string) *rpcc.RPCClient {
+	socketPath, ok := ipc.ParseEndpoint(endpoint)
+	if !ok {
+		return rpcc.NewRPCClient(endpoint)
+	}
+	c := rpcc.NewRPCClient(ipc.RPCURL)
+	c.SetHTTPClient(ipc.NewHTTPClient(socketPath, 0))
+	return c
+}
@@ -2,7 +2,6 @@ package utils
 
 import (
 	"bufio"
-	"fmt"
 	"os"
 	"strings"
 
@@ -41,7 +40,8 @@ func stdinLine() (string, error) {
 	return strings.TrimSpace(line), nil
 }
 
+// Error prints the error and exits with the exit code of the failure class of the first error in
+// the arguments
 func Error(msg string, args ...interface{}) {
-	fmt.Printf(msg, args...)
-	os.Exit(1)
+	Exit(classifyArgs(args), msg, args...)
 }
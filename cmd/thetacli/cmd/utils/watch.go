@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	// DefaultWatchInterval is the polling interval when the watch flag is set without a value
+	DefaultWatchInterval = 2 * time.Second
+
+	ansiClearScreen = "\033[H\033[2J"
+	ansiHighlight   = "\033[7m"
+	ansiReset       = "\033[0m"
+)
+
+// Watch polls the result with the given interval until interrupted. On a terminal the screen is
+// redrawn on every poll with the lines changed since the previous poll highlighted. Otherwise, e.g.
+// when the output is piped, the results are appended, one compact JSON object per line in the
+// JSON mode. Errors do not stop the polling, so that a restarting node can be monitored.
+func Watch(interval time.Duration, title string, fetch func() (interface{}, error)) {
+	w := &watcher{
+		interval:   interval,
+		title:      title,
+		jsonOutput: IsJSONOutput(),
+		isTerminal: terminal.IsTerminal(int(os.Stdout.Fd())) && !IsJSONOutput(),
+	}
+	for {
+		result, err := fetch()
+		fmt.Print(w.frame(result, err, time.Now()))
+		time.Sleep(interval)
+	}
+}
+
+// watcher renders the polled results, keeping the lines of the last successful poll to highlight the changes
+type watcher struct {
+	interval   time.Duration
+	title      string
+	jsonOutput bool
+	isTerminal bool
+	previous   []string
+}
+
+func (w *watcher) frame(result interface{}, err error, now time.Time) string {
+	if w.jsonOutput {
+		output := Output{Result: result}
+		if err != nil {
+			output = Output{Error: &OutputError{
+				Class:    ErrorClass[ClassifyError(err)],
+				ExitCode: ClassifyError(err),
+				Message:  err.Error(),
+			}}
+		}
+		formatted, err := json.Marshal(output)
+		if err != nil {
+			return ""
+		}
+		return string(formatted) + "\n"
+	}
+
+	var lines []string
+	if err != nil {
+		lines = []string{fmt.Sprintf("Error: %v", err)}
+	} else if formatted, err := json.MarshalIndent(result, "", "    "); err != nil {
+		lines = []string{fmt.Sprintf("Failed to parse server response: %v", err)}
+	} else {
+		lines = strings.Split(string(formatted), "\n")
+	}
+
+	var sb strings.Builder
+	if w.isTerminal {
+		sb.WriteString(ansiClearScreen)
+	}
+	sb.WriteString(fmt.Sprintf("Every %v: %v    %v\n\n", w.interval, w.title, now.Format("2006-01-02 15:04:05")))
+	for i, line := range lines {
+		changed := w.previous != nil && (i >= len(w.previous) || w.previous[i] != line)
+		if w.isTerminal && changed {
+			sb.WriteString(ansiHighlight + line + ansiReset + "\n")
+		} else {
+			sb.WriteString(line + "\n")
+		}
+	}
+	if err == nil {
+		w.previous = lines
+	}
+	return sb.String()
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchFrameHighlightsChanges(t *testing.T) {
+	assert := assert.New(t)
+
+	w := &watcher{interval: 2 * time.Second, title: "status", isTerminal: true}
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.Local)
+
+	// Nothing is highlighted on the first poll
+	frame := w.frame(map[string]int{"height": 1, "peers": 3}, nil, now)
+	assert.True(strings.HasPrefix(frame, ansiClearScreen+"Every 2s: status    2021-06-01 12:00:00\n\n"))
+	assert.NotContains(frame, ansiHighlight)
+
+	frame = w.frame(map[string]int{"height": 2, "peers": 3}, nil, now)
+	assert.Contains(frame, ansiHighlight+`    "height": 2,`+ansiReset)
+	assert.Contains(frame, "\n"+`    "peers": 3`+"\n")
+
+	// Errors are shown but do not reset the lines to compare with
+	frame = w.frame(nil, errors.New("connection refused"), now)
+	assert.Contains(frame, "Error: connection refused")
+	frame = w.frame(map[string]int{"height": 2, "peers": 3}, nil, now)
+	assert.NotContains(frame, ansiHighlight)
+}
+
+func TestWatchFramePlainOutput(t *testing.T) {
+	assert := assert.New(t)
+
+	// Without a terminal, the frames are appended without the escape sequences
+	w := &watcher{interval: time.Second, title: "status"}
+	w.frame(map[string]int{"height": 1}, nil, time.Now())
+	frame := w.frame(map[string]int{"height": 2}, nil, time.Now())
+	assert.NotContains(frame, "\033")
+	assert.Contains(frame, `"height": 2`)
+}
+
+func TestWatchFrameJSONOutput(t *testing.T) {
+	require := require.New(t)
+
+	w := &watcher{interval: time.Second, title: "status", jsonOutput: true}
+	frame := w.frame(map[string]int{"height": 1}, nil, time.Now())
+	require.Equal(1, strings.Count(frame, "\n"))
+	output := &Output{}
+	require.Nil(json.Unmarshal([]byte(frame), output))
+	require.Nil(output.Error)
+
+	frame = w.frame(nil, errors.New("connection refused"), time.Now())
+	output = &Output{}
+	require.Nil(json.Unmarshal([]byte(frame), output))
+	require.NotNil(output.Error)
+	require.Equal("connection refused", output.Error.Message)
+}
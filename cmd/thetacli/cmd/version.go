@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
+	"github.com/thetatoken/theta/rpc"
 	"github.com/thetatoken/theta/version"
 )
 
@@ -19,5 +21,13 @@ func init() {
 }
 
 func runVersion(cmd *cobra.Command, args []string) {
+	if utils.IsJSONOutput() {
+		utils.PrintResult(rpc.GetVersionResult{
+			Version:   version.Version,
+			GitHash:   version.GitHash,
+			Timestamp: version.Timestamp,
+		})
+		return
+	}
 	fmt.Printf("Version %v %s\nBuilt at %s\n", version.Version, version.GitHash, version.Timestamp)
 }
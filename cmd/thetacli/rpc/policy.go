@@ -0,0 +1,358 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+const defaultApprovalTimeout = 10 * time.Second
+
+// SignerPolicyConfig is the JSON policy file of the signer service. The policy denies by default: only the listed
+// signers may sign the listed tx types, every destination of a transaction must be in the allowlist, and the
+// amount a signer spends per day, fees included, must stay within the daily caps, where a missing cap means zero.
+//
+// Example:
+//
+//	{
+//	  "allowed_signers": ["0x2E833968E5bB786Ae419c4d13189fB081Cc43bab"],
+//	  "allowed_tx_types": ["send", "smart_contract"],
+//	  "allowed_destinations": ["0x70f587259738cB626A1720Af7038B8DcDb6a42a0"],
+//	  "daily_theta_cap": "1000000000000000000000",
+//	  "daily_tfuel_cap": "5000000000000000000000",
+//	  "approval_webhook": "https://approvals.example.com/theta",
+//	  "approval_timeout_secs": 10
+//	}
+type SignerPolicyConfig struct {
+	AllowedSigners      []string `json:"allowed_signers"`
+	AllowedTxTypes      []string `json:"allowed_tx_types"`     // a subset of SignableTxTypes
+	AllowedDestinations []string `json:"allowed_destinations"` // the recipients of the transactions
+	DailyThetaCap       string   `json:"daily_theta_cap"`      // in ThetaWei, per signer per UTC day
+	DailyTFuelCap       string   `json:"daily_tfuel_cap"`      // in TFuelWei, per signer per UTC day, fees included
+	ApprovalWebhook     string   `json:"approval_webhook"`     // every transaction passing the other rules is POSTed for approval
+	ApprovalTimeoutSecs int      `json:"approval_timeout_secs"`
+}
+
+// ApprovalRequest is the body POSTed to the approval webhook. The webhook approves the signing by responding
+// 200 with {"approved": true}, any other response rejects it.
+type ApprovalRequest struct {
+	ChainID    string      `json:"chain_id"`
+	Signer     string      `json:"signer"`
+	Type       string      `json:"type"`
+	To         []string    `json:"to"`
+	Amount     types.Coins `json:"amount"`      // spent by the signer, fees included
+	SpentToday types.Coins `json:"spent_today"` // including this transaction
+	TxBytes    string      `json:"tx_bytes"`
+}
+
+type ApprovalResponse struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason"`
+}
+
+// SignerPolicy decides whether the signer service signs a transaction, and keeps track of the daily amounts
+type SignerPolicy struct {
+	signers      map[common.Address]bool
+	txTypes      map[string]bool
+	destinations map[common.Address]bool
+	dailyCap     types.Coins
+	webhook      string
+	client       *http.Client
+
+	mu    *sync.Mutex
+	day   string
+	spent map[common.Address]types.Coins
+}
+
+// LoadSignerPolicy loads the policy from the given JSON file
+func LoadSignerPolicy(path string) (*SignerPolicy, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the signer policy: %v", err)
+	}
+	config := SignerPolicyConfig{}
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("Failed to parse the signer policy: %v", err)
+	}
+	return NewSignerPolicy(config)
+}
+
+// NewSignerPolicy creates a SignerPolicy from the given config
+func NewSignerPolicy(config SignerPolicyConfig) (*SignerPolicy, error) {
+	p := &SignerPolicy{
+		signers:      parseAddressSet(config.AllowedSigners),
+		txTypes:      make(map[string]bool),
+		destinations: parseAddressSet(config.AllowedDestinations),
+		dailyCap:     types.NewCoins(0, 0),
+		webhook:      config.ApprovalWebhook,
+		mu:           &sync.Mutex{},
+		spent:        make(map[common.Address]types.Coins),
+	}
+	for _, txType := range config.AllowedTxTypes {
+		txType = strings.ToLower(txType)
+		if !isSignableTxType(txType) {
+			return nil, fmt.Errorf("Transaction type %v cannot be evaluated by the signer policy, the supported types are %v",
+				txType, strings.Join(SignableTxTypes, ", "))
+		}
+		p.txTypes[txType] = true
+	}
+
+	var ok bool
+	if config.DailyThetaCap != "" {
+		if p.dailyCap.ThetaWei, ok = new(big.Int).SetString(config.DailyThetaCap, 10); !ok {
+			return nil, fmt.Errorf("Invalid daily_theta_cap: %v", config.DailyThetaCap)
+		}
+	}
+	if config.DailyTFuelCap != "" {
+		if p.dailyCap.TFuelWei, ok = new(big.Int).SetString(config.DailyTFuelCap, 10); !ok {
+			return nil, fmt.Errorf("Invalid daily_tfuel_cap: %v", config.DailyTFuelCap)
+		}
+	}
+
+	timeout := defaultApprovalTimeout
+	if config.ApprovalTimeoutSecs > 0 {
+		timeout = time.Duration(config.ApprovalTimeoutSecs) * time.Second
+	}
+	p.client = &http.Client{Timeout: timeout}
+
+	return p, nil
+}
+
+func parseAddressSet(addresses []string) map[common.Address]bool {
+	set := make(map[common.Address]bool)
+	for _, address := range addresses {
+		set[common.HexToAddress(address)] = true
+	}
+	return set
+}
+
+// SignableTxTypes are the tx types the policy can evaluate, the transactions of the other types are refused
+var SignableTxTypes = []string{
+	blockchain.TxSearchTypeSend,
+	blockchain.TxSearchTypeSmartContract,
+	blockchain.TxSearchTypeDepositStake,
+	blockchain.TxSearchTypeWithdrawStake,
+	blockchain.TxSearchTypeStakeRewardDistribution,
+	blockchain.TxSearchTypeStakeAutoCompounding,
+	blockchain.TxSearchTypeCrossChainSend,
+}
+
+func isSignableTxType(txType string) bool {
+	for _, t := range SignableTxTypes {
+		if t == txType {
+			return true
+		}
+	}
+	return false
+}
+
+// txSpending describes what signing a transaction lets the signer spend
+type txSpending struct {
+	txType string
+	to     []common.Address
+	amount types.Coins // the max amount leaving the account of the signer, fees included
+}
+
+// evaluateTx returns the spending of the signer in the transaction. Transactions the policy cannot evaluate, and
+// the ones the signer does not sign, are refused.
+func evaluateTx(signer common.Address, tx types.Tx) (*txSpending, error) {
+	spending := &txSpending{amount: types.NewCoins(0, 0)}
+	isSigner := false
+	if v2, ok := tx.(*types.SmartContractTxV2); ok {
+		// Evaluated at the max fee per gas, the most the transaction can be charged
+		tx = v2.ToSmartContractTx(v2.MaxFeePerGas)
+	}
+	switch tx := tx.(type) {
+	case *types.SendTx:
+		spending.txType = blockchain.TxSearchTypeSend
+		for _, input := range tx.Inputs {
+			if input.Address == signer {
+				isSigner = true
+				spending.amount = spending.amount.Plus(input.Coins.NoNil())
+			}
+		}
+		if feePayer := tx.GetFeePayer(); feePayer != nil && feePayer.Address == signer {
+			isSigner = true
+			spending.amount = spending.amount.Plus(tx.Fee.NoNil())
+		}
+		for _, output := range tx.Outputs {
+			spending.to = append(spending.to, output.Address)
+		}
+	case *types.SmartContractTx:
+		spending.txType = blockchain.TxSearchTypeSmartContract
+		gas := types.NewCoins(0, 0)
+		if tx.GasPrice != nil {
+			gas.TFuelWei = new(big.Int).Mul(tx.GasPrice, new(big.Int).SetUint64(tx.GasLimit))
+		}
+		feePayer := tx.GetFeePayer()
+		if tx.From.Address == signer {
+			isSigner = true
+			spending.amount = spending.amount.Plus(tx.From.Coins.NoNil())
+			if feePayer == nil {
+				spending.amount = spending.amount.Plus(gas)
+			}
+		}
+		if feePayer != nil && feePayer.Address == signer {
+			isSigner = true
+			spending.amount = spending.amount.Plus(gas)
+		}
+		spending.to = []common.Address{tx.To.Address}
+	case *types.DepositStakeTx:
+		spending.txType = blockchain.TxSearchTypeDepositStake
+		isSigner = tx.Source.Address == signer
+		spending.amount = tx.Source.Coins.NoNil().Plus(tx.Fee.NoNil())
+		spending.to = []common.Address{tx.Holder.Address}
+	case *types.DepositStakeTxV2:
+		spending.txType = blockchain.TxSearchTypeDepositStake
+		isSigner = tx.Source.Address == signer
+		spending.amount = tx.Source.Coins.NoNil().Plus(tx.Fee.NoNil())
+		spending.to = []common.Address{tx.Holder.Address}
+	case *types.WithdrawStakeTx:
+		spending.txType = blockchain.TxSearchTypeWithdrawStake
+		isSigner = tx.Source.Address == signer
+		spending.amount = tx.Fee.NoNil()
+		spending.to = []common.Address{tx.Holder.Address}
+	case *types.StakeRewardDistributionTx:
+		spending.txType = blockchain.TxSearchTypeStakeRewardDistribution
+		isSigner = tx.Holder.Address == signer
+		spending.amount = tx.Fee.NoNil()
+		spending.to = []common.Address{tx.Beneficiary.Address}
+	case *types.StakeAutoCompoundingTx:
+		spending.txType = blockchain.TxSearchTypeStakeAutoCompounding
+		isSigner = tx.Source.Address == signer
+		spending.amount = tx.Fee.NoNil()
+		spending.to = []common.Address{tx.Holder}
+	case *types.CrossChainSendTx:
+		spending.txType = blockchain.TxSearchTypeCrossChainSend
+		isSigner = tx.Sender.Address == signer
+		spending.amount = tx.Sender.Coins.NoNil().Plus(tx.Fee.NoNil())
+		spending.to = []common.Address{tx.Receiver}
+	default:
+		return nil, fmt.Errorf("Policy violation: transaction type %T cannot be evaluated by the signer policy", tx)
+	}
+	if !isSigner {
+		return nil, fmt.Errorf("Policy violation: %v is not a signer of the transaction", signer.Hex())
+	}
+	if !spending.amount.IsNonnegative() {
+		return nil, fmt.Errorf("Policy violation: negative amount %v", spending.amount)
+	}
+	return spending, nil
+}
+
+// Authorize checks the transaction against the policy, and reserves its amount in the daily cap of the signer.
+// The returned release function returns the reserved amount, in case the transaction ends up not signed.
+func (p *SignerPolicy) Authorize(chainID string, signer common.Address, tx types.Tx, txBytes string) (release func(), err error) {
+	if !p.signers[signer] {
+		return nil, fmt.Errorf("Policy violation: signer %v is not allowed", signer.Hex())
+	}
+	spending, err := evaluateTx(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	if !p.txTypes[spending.txType] {
+		return nil, fmt.Errorf("Policy violation: transaction type %v is not allowed", spending.txType)
+	}
+	for _, to := range spending.to {
+		if !p.destinations[to] {
+			return nil, fmt.Errorf("Policy violation: destination %v is not in the allowlist", to.Hex())
+		}
+	}
+
+	day, spent, err := p.reserve(signer, spending.amount)
+	if err != nil {
+		return nil, err
+	}
+	release = func() { p.unreserve(day, signer, spending.amount) }
+
+	if p.webhook != "" {
+		to := []string{}
+		for _, address := range spending.to {
+			to = append(to, address.Hex())
+		}
+		req := &ApprovalRequest{
+			ChainID:    chainID,
+			Signer:     signer.Hex(),
+			Type:       spending.txType,
+			To:         to,
+			Amount:     spending.amount,
+			SpentToday: spent,
+			TxBytes:    txBytes,
+		}
+		if err := p.requestApproval(req); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	return release, nil
+}
+
+// reserve adds the amount to the spending of the signer today, unless the daily cap would be exceeded
+func (p *SignerPolicy) reserve(signer common.Address, amount types.Coins) (string, types.Coins, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rollDay()
+	spent := p.spent[signer].NoNil().Plus(amount)
+	if spent.ThetaWei.Cmp(p.dailyCap.ThetaWei) > 0 {
+		return "", types.Coins{}, fmt.Errorf("Policy violation: the daily cap of %v ThetaWei would be exceeded", p.dailyCap.ThetaWei)
+	}
+	if spent.TFuelWei.Cmp(p.dailyCap.TFuelWei) > 0 {
+		return "", types.Coins{}, fmt.Errorf("Policy violation: the daily cap of %v TFuelWei would be exceeded", p.dailyCap.TFuelWei)
+	}
+	p.spent[signer] = spent
+	return p.day, spent, nil
+}
+
+// unreserve returns the amount reserved on the given day, unless the day has passed
+func (p *SignerPolicy) unreserve(day string, signer common.Address, amount types.Coins) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if spent, ok := p.spent[signer]; ok && day == p.day {
+		p.spent[signer] = spent.Minus(amount)
+	}
+}
+
+// rollDay resets the daily spending at the start of each UTC day. Needs to be called with the lock held.
+func (p *SignerPolicy) rollDay() {
+	day := time.Now().UTC().Format("2006-01-02")
+	if day != p.day {
+		p.day = day
+		p.spent = make(map[common.Address]types.Coins)
+	}
+}
+
+func (p *SignerPolicy) requestApproval(req *ApprovalRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Post(p.webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Approval webhook failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	approval := ApprovalResponse{}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Approval webhook rejected the transaction with status %v", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&approval); err != nil {
+		return fmt.Errorf("Failed to parse the approval webhook response: %v", err)
+	}
+	if !approval.Approved {
+		return fmt.Errorf("Approval webhook rejected the transaction: %v", approval.Reason)
+	}
+	return nil
+}
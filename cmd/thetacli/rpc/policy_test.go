@@ -0,0 +1,192 @@
+package rpc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var (
+	policyTestSigner = common.HexToAddress("0x2E833968E5bB786Ae419c4d13189fB081Cc43bab")
+	policyTestDest   = common.HexToAddress("0x70f587259738cB626A1720Af7038B8DcDb6a42a0")
+	policyTestOther  = common.HexToAddress("0x0000000000000000000000000000000000000bad")
+)
+
+func newTestSignerPolicy(t *testing.T, thetaCap, tfuelCap string) *SignerPolicy {
+	policy, err := NewSignerPolicy(SignerPolicyConfig{
+		AllowedSigners:      []string{policyTestSigner.Hex()},
+		AllowedTxTypes:      SignableTxTypes,
+		AllowedDestinations: []string{policyTestDest.Hex()},
+		DailyThetaCap:       thetaCap,
+		DailyTFuelCap:       tfuelCap,
+	})
+	require.Nil(t, err)
+	return policy
+}
+
+func policyTestInput(address common.Address, theta, tfuel int64) types.TxInput {
+	return types.TxInput{Address: address, Coins: types.NewCoins(theta, tfuel)}
+}
+
+func TestSignerPolicyTxTypes(t *testing.T) {
+	fee := types.NewCoins(0, 1)
+	tests := []struct {
+		name   string
+		tx     func(to common.Address) types.Tx
+		amount types.Coins // spent by the signer, fees included
+	}{
+		{"send", func(to common.Address) types.Tx {
+			return &types.SendTx{
+				Fee:     fee,
+				Inputs:  []types.TxInput{policyTestInput(policyTestSigner, 10, 21)},
+				Outputs: []types.TxOutput{{Address: to, Coins: types.NewCoins(10, 20)}},
+			}
+		}, types.NewCoins(10, 21)},
+		{"smart_contract", func(to common.Address) types.Tx {
+			return &types.SmartContractTx{
+				From:     policyTestInput(policyTestSigner, 0, 5),
+				To:       types.TxOutput{Address: to},
+				GasLimit: 100,
+				GasPrice: big.NewInt(2),
+			}
+		}, types.NewCoins(0, 205)},
+		{"deposit_stake", func(to common.Address) types.Tx {
+			return &types.DepositStakeTx{Fee: fee, Source: policyTestInput(policyTestSigner, 100, 0), Holder: types.TxOutput{Address: to}}
+		}, types.NewCoins(100, 1)},
+		{"deposit_stake_v2", func(to common.Address) types.Tx {
+			return &types.DepositStakeTxV2{Fee: fee, Source: policyTestInput(policyTestSigner, 0, 100), Holder: types.TxOutput{Address: to}}
+		}, types.NewCoins(0, 101)},
+		{"withdraw_stake", func(to common.Address) types.Tx {
+			return &types.WithdrawStakeTx{Fee: fee, Source: policyTestInput(policyTestSigner, 0, 0), Holder: types.TxOutput{Address: to}}
+		}, types.NewCoins(0, 1)},
+		{"stake_reward_distribution", func(to common.Address) types.Tx {
+			return &types.StakeRewardDistributionTx{Fee: fee, Holder: policyTestInput(policyTestSigner, 0, 0), Beneficiary: types.TxOutput{Address: to}}
+		}, types.NewCoins(0, 1)},
+		{"stake_auto_compounding", func(to common.Address) types.Tx {
+			return &types.StakeAutoCompoundingTx{Fee: fee, Source: policyTestInput(policyTestSigner, 0, 0), Holder: to}
+		}, types.NewCoins(0, 1)},
+		{"cross_chain_send", func(to common.Address) types.Tx {
+			return &types.CrossChainSendTx{Fee: fee, Sender: policyTestInput(policyTestSigner, 0, 3), TargetChainID: "subchain", Receiver: to}
+		}, types.NewCoins(0, 4)},
+	}
+
+	for _, test := range tests {
+		spending, err := evaluateTx(policyTestSigner, test.tx(policyTestDest))
+		require.Nil(t, err, test.name)
+		assert.True(t, test.amount.IsEqual(spending.amount), "%v: amount %v, expected %v", test.name, spending.amount, test.amount)
+		assert.Equal(t, []common.Address{policyTestDest}, spending.to, test.name)
+
+		policy := newTestSignerPolicy(t, "1000", "1000")
+		release, err := policy.Authorize("privatenet", policyTestSigner, test.tx(policyTestDest), "")
+		assert.Nil(t, err, test.name)
+		assert.NotNil(t, release, test.name)
+
+		// The real destination of each tx type is checked against the allowlist
+		_, err = policy.Authorize("privatenet", policyTestSigner, test.tx(policyTestOther), "")
+		assert.NotNil(t, err, test.name)
+
+		// Only the signer of the transaction may sign it
+		_, err = evaluateTx(policyTestOther, test.tx(policyTestDest))
+		assert.NotNil(t, err, test.name)
+	}
+}
+
+func TestSignerPolicyRefusesUnsupportedTxTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := newTestSignerPolicy(t, "1000", "1000")
+	txs := []types.Tx{
+		&types.ReserveFundTx{Fee: types.NewCoins(0, 1), Source: policyTestInput(policyTestSigner, 0, 10), Collateral: types.NewCoins(0, 10)},
+		&types.SplitRuleTx{Fee: types.NewCoins(0, 1), Initiator: policyTestInput(policyTestSigner, 0, 0)},
+		&types.ParameterChangeTx{Fee: types.NewCoins(0, 1), Proposer: policyTestInput(policyTestSigner, 0, 0)},
+		&types.CrossChainDeliverTx{Fee: types.NewCoins(0, 1), Relayer: policyTestInput(policyTestSigner, 0, 0),
+			Message: core.CrossChainMessage{Receiver: policyTestDest}},
+	}
+	for _, tx := range txs {
+		_, err := policy.Authorize("privatenet", policyTestSigner, tx, "")
+		assert.NotNil(err, "%T", tx)
+	}
+
+	_, err := NewSignerPolicy(SignerPolicyConfig{AllowedTxTypes: []string{"reserve_fund"}})
+	assert.NotNil(err)
+}
+
+func TestSignerPolicyDeniesByDefault(t *testing.T) {
+	assert := assert.New(t)
+
+	policy, err := NewSignerPolicy(SignerPolicyConfig{})
+	require.Nil(t, err)
+	tx := &types.SendTx{
+		Fee:     types.NewCoins(0, 1),
+		Inputs:  []types.TxInput{policyTestInput(policyTestSigner, 0, 2)},
+		Outputs: []types.TxOutput{{Address: policyTestDest, Coins: types.NewCoins(0, 1)}},
+	}
+	_, err = policy.Authorize("privatenet", policyTestSigner, tx, "")
+	assert.NotNil(err)
+
+	// Allowed signer and tx type, but no destinations nor caps
+	policy, err = NewSignerPolicy(SignerPolicyConfig{
+		AllowedSigners: []string{policyTestSigner.Hex()},
+		AllowedTxTypes: []string{"send"},
+	})
+	require.Nil(t, err)
+	_, err = policy.Authorize("privatenet", policyTestSigner, tx, "")
+	assert.NotNil(err)
+
+	// Allowed destination, but the missing daily cap means nothing can be spent
+	policy, err = NewSignerPolicy(SignerPolicyConfig{
+		AllowedSigners:      []string{policyTestSigner.Hex()},
+		AllowedTxTypes:      []string{"send"},
+		AllowedDestinations: []string{policyTestDest.Hex()},
+	})
+	require.Nil(t, err)
+	_, err = policy.Authorize("privatenet", policyTestSigner, tx, "")
+	assert.NotNil(err)
+}
+
+func TestSignerPolicyDailyCap(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := newTestSignerPolicy(t, "0", "10")
+	send := func(amount int64) types.Tx {
+		return &types.SendTx{
+			Fee:     types.NewCoins(0, 1),
+			Inputs:  []types.TxInput{policyTestInput(policyTestSigner, 0, amount+1)},
+			Outputs: []types.TxOutput{{Address: policyTestDest, Coins: types.NewCoins(0, amount)}},
+		}
+	}
+
+	// The fees count towards the cap: 4+1 and 4+1 exhaust the cap of 10
+	_, err := policy.Authorize("privatenet", policyTestSigner, send(4), "")
+	assert.Nil(err)
+	release, err := policy.Authorize("privatenet", policyTestSigner, send(4), "")
+	assert.Nil(err)
+	_, err = policy.Authorize("privatenet", policyTestSigner, send(0), "")
+	assert.NotNil(err)
+
+	// The released amount can be spent again
+	release()
+	_, err = policy.Authorize("privatenet", policyTestSigner, send(4), "")
+	assert.Nil(err)
+
+	// Withdrawing a stake only spends the fee, which is capped as well
+	withdraw := &types.WithdrawStakeTx{Fee: types.NewCoins(0, 1), Source: policyTestInput(policyTestSigner, 0, 0),
+		Holder: types.TxOutput{Address: policyTestDest}}
+	_, err = policy.Authorize("privatenet", policyTestSigner, withdraw, "")
+	assert.NotNil(err)
+
+	// No theta can be spent with a zero theta cap
+	policy = newTestSignerPolicy(t, "0", "10")
+	theta := &types.SendTx{
+		Fee:     types.NewCoins(0, 1),
+		Inputs:  []types.TxInput{policyTestInput(policyTestSigner, 1, 1)},
+		Outputs: []types.TxOutput{{Address: policyTestDest, Coins: types.NewCoins(1, 0)}},
+	}
+	_, err = policy.Authorize("privatenet", policyTestSigner, theta, "")
+	assert.NotNil(err)
+}
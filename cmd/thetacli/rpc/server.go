@@ -26,6 +26,7 @@ var logger *log.Entry
 
 type ThetaCliRPCService struct {
 	wallet wt.Wallet
+	policy *SignerPolicy // enables the SignTx API when set
 
 	// Life cycle
 	wg      *sync.WaitGroup
@@ -81,6 +82,17 @@ func NewThetaCliRPCServer(cfgPath, port string) (*ThetaCliRPCServer, error) {
 	return t, nil
 }
 
+// EnableSigner enables the SignTx API, guarded by the policy loaded from the given file.
+func (t *ThetaCliRPCServer) EnableSigner(policyPath string) error {
+	policy, err := LoadSignerPolicy(policyPath)
+	if err != nil {
+		return err
+	}
+	t.policy = policy
+	logger.WithFields(log.Fields{"policy": policyPath}).Info("Signer service enabled")
+	return nil
+}
+
 // Start creates the main goroutine.
 func (t *ThetaCliRPCServer) Start(ctx context.Context) {
 	c, cancel := context.WithCancel(ctx)
@@ -0,0 +1,92 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/hexutil"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// ------------------------------- SignTx -----------------------------------
+
+type SignTxArgs struct {
+	ChainID string `json:"chain_id"`
+	Signer  string `json:"signer"`   // address of the unlocked key to sign with
+	TxBytes string `json:"tx_bytes"` // hex encoded unsigned transaction
+}
+
+type SignTxResult struct {
+	TxBytes string `json:"tx_bytes"` // hex encoded signed transaction, ready for BroadcastRawTransaction
+	TxHash  string `json:"hash"`
+}
+
+type signableTx interface {
+	types.Tx
+	SetSignature(addr common.Address, sig *crypto.Signature) bool
+}
+
+// SignTx signs the transaction with an unlocked key, if the transaction complies with the signer policy. The
+// signer service is only available when the daemon runs with a signer policy.
+func (t *ThetaCliRPCService) SignTx(args *SignTxArgs, result *SignTxResult) (err error) {
+	if t.policy == nil {
+		return errors.New("The signer service is not enabled, please start the daemon with a signer policy")
+	}
+	if args.ChainID == "" {
+		return errors.New("The chain ID must be specified")
+	}
+	signer := common.HexToAddress(args.Signer)
+	if !t.wallet.IsUnlocked(signer) {
+		return fmt.Errorf("The key of signer %v is locked", signer.Hex())
+	}
+
+	raw, err := hex.DecodeString(trimHexPrefix(args.TxBytes))
+	if err != nil {
+		return fmt.Errorf("Failed to decode the transaction: %v", err)
+	}
+	tx, err := types.TxFromBytes(raw)
+	if err != nil {
+		return fmt.Errorf("Failed to parse the transaction: %v", err)
+	}
+	stx, ok := tx.(signableTx)
+	if !ok {
+		return fmt.Errorf("Transaction type %T cannot be signed", tx)
+	}
+
+	release, err := t.policy.Authorize(args.ChainID, signer, tx, args.TxBytes)
+	if err != nil {
+		logger.Warnf("Refused to sign the transaction of %v: %v", signer.Hex(), err)
+		return err
+	}
+
+	sig, err := t.wallet.Sign(signer, stx.SignBytes(args.ChainID))
+	if err != nil {
+		release()
+		return fmt.Errorf("Failed to sign the transaction: %v", err)
+	}
+	if !stx.SetSignature(signer, sig) {
+		release()
+		return fmt.Errorf("Signer %v is not a signer of the transaction", signer.Hex())
+	}
+
+	signed, err := types.TxToBytes(stx)
+	if err != nil {
+		release()
+		return fmt.Errorf("Failed to encode the transaction: %v", err)
+	}
+	result.TxBytes = hex.EncodeToString(signed)
+	result.TxHash = crypto.Keccak256Hash(signed).Hex()
+
+	logger.Infof("Signed transaction %v for %v", result.TxHash, signer.Hex())
+	return nil
+}
+
+func trimHexPrefix(s string) string {
+	if hexutil.Has0xPrefix(s) {
+		return s[2:]
+	}
+	return s
+}
@@ -7,7 +7,6 @@ import (
 	"strconv"
 
 	"github.com/spf13/viper"
-	rpcc "github.com/ybbus/jsonrpc"
 
 	"github.com/thetatoken/theta/cmd/thetacli/cmd/utils"
 	"github.com/thetatoken/theta/common"
@@ -102,13 +101,13 @@ func (t *ThetaCliRPCService) Send(args *SendArgs, result *SendResult) (err error
 	}
 	signedTx := hex.EncodeToString(raw)
 
-	client := rpcc.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
+	client := utils.NewRPCClient(viper.GetString(utils.CfgRemoteRPCEndpoint))
 
 	rpcMethod := "theta.BroadcastRawTransaction"
 	if args.Async {
 		rpcMethod = "theta.BroadcastRawTransactionAsync"
 	}
-	res, err := client.Call(rpcMethod, trpc.BroadcastRawTransactionArgs{TxBytes: signedTx})
+	res, err := client.Call(rpcMethod, trpc.BroadcastRawTransactionArgs{TxBytes: signedTx, ChainID: args.ChainID})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,250 @@
+// Package autotune sizes the caches, the peer limits, the worker counts and the memory budget of the node for the
+// CPUs and the memory of the host, so that the same config fits the diverse hardware the nodes run on. The tuned
+// values are set as the config defaults, hence the values set in the config file, the env or the flags take
+// precedence over them.
+package autotune
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/membudget"
+)
+
+var logger *log.Entry = log.WithFields(log.Fields{"prefix": "autotune"})
+
+const mib = 1024 * 1024
+
+// The files the resources are read from
+var (
+	meminfoPath = "/proc/meminfo"
+	cgroupRoot  = "/sys/fs/cgroup"
+)
+
+// budgetTuned indicates whether the memory budget in use is the tuned one, which is then adjusted periodically
+var budgetTuned bool
+
+// Resources describes the resources available to the node
+type Resources struct {
+	NumCPU          int
+	TotalMemory     uint64 // in bytes, 0 if unknown
+	AvailableMemory uint64 // in bytes, 0 if unknown
+}
+
+// DetectResources detects the CPUs and the memory available to the node, within the limits of its cgroup, e.g.
+// of its container
+func DetectResources() Resources {
+	res := Resources{NumCPU: runtime.NumCPU()}
+	if quota := cgroupCPUQuota(); quota > 0 && quota < res.NumCPU {
+		res.NumCPU = quota
+	}
+
+	total, available, err := readMeminfo(meminfoPath)
+	if err != nil {
+		logger.Debugf("Failed to detect the memory: %v", err)
+		return res
+	}
+	if limit, usage, ok := cgroupMemory(); ok && limit < total {
+		total = limit
+		if usage >= limit {
+			available = 0
+		} else if limit-usage < available {
+			available = limit - usage
+		}
+	}
+	res.TotalMemory, res.AvailableMemory = total, available
+	return res
+}
+
+// Tune sets the config defaults tuned for the given resources
+func Tune(res Resources) {
+	values := tunedValues(res)
+	for key, value := range values {
+		viper.SetDefault(key, value)
+	}
+	// The min number of peers follows the max one, whether it is tuned or not
+	viper.SetDefault(common.CfgP2PMinNumPeers, viper.GetInt(common.CfgP2PMaxNumPeers)/2)
+
+	budgetMB, ok := values[common.CfgMemoryBudgetMB]
+	budgetTuned = ok && viper.GetInt64(common.CfgMemoryBudgetMB) == budgetMB.(int64)
+
+	logger.Infof("Tuned for %v CPUs, %v MB of memory and %v MB available: %v",
+		res.NumCPU, res.TotalMemory/mib, res.AvailableMemory/mib, values)
+}
+
+// tunedValues returns the config values tuned for the given resources
+func tunedValues(res Resources) map[string]interface{} {
+	values := make(map[string]interface{})
+	totalMB := int(res.TotalMemory / mib)
+	if totalMB > 0 {
+		values[common.CfgStorageLevelDBCacheSize] = clamp(totalMB/16, 64, 2048)
+		values[common.CfgMemoryBudgetMB] = memoryBudgetMB(res, 0)
+	}
+	if res.NumCPU > 0 {
+		values[common.CfgSyncSignatureVerifyWorkers] = res.NumCPU
+		values[common.CfgRPCNumWorkers] = clamp(8*res.NumCPU, 8, 64)
+
+		maxPeers := 16 * res.NumCPU
+		if totalMB > 0 && totalMB/64 < maxPeers {
+			maxPeers = totalMB / 64
+		}
+		values[common.CfgP2PMaxNumPeers] = clamp(maxPeers, 16, 128)
+	}
+	return values
+}
+
+// memoryBudgetMB returns the memory budget for the given resources, i.e. half of the memory, but no more than
+// three quarters of the memory the components of the budget can use: the available memory plus the memory
+// they already use
+func memoryBudgetMB(res Resources, used int64) int64 {
+	budget := res.TotalMemory / 2
+	if res.AvailableMemory > 0 {
+		if byAvailable := (res.AvailableMemory + uint64(used)) * 3 / 4; byAvailable < budget {
+			budget = byAvailable
+		}
+	}
+	budgetMB := int64(budget / mib)
+	if budgetMB < 64 {
+		budgetMB = 64
+	}
+	return budgetMB
+}
+
+// Run re-detects the available memory periodically to adjust the tuned memory budget, until the context is done.
+// The config is not updated, since it is not safe for concurrent use.
+func Run(ctx context.Context) {
+	interval := time.Duration(viper.GetInt(common.CfgAutoTuneIntervalSecs)) * time.Second
+	if !budgetTuned || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if res := DetectResources(); res.TotalMemory > 0 {
+				membudget.Rebudget(memoryBudgetMB(res, membudget.TotalUsed()))
+			}
+		}
+	}
+}
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+// readMeminfo reads the total and the available memory in bytes from /proc/meminfo
+func readMeminfo(path string) (total uint64, available uint64, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	var free uint64
+	hasAvailable := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text()) // e.g. "MemTotal: 16314400 kB"
+		if len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total = kb * 1024
+		case "MemAvailable:":
+			available, hasAvailable = kb*1024, true
+		case "MemFree:":
+			free = kb * 1024
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	if total == 0 {
+		return 0, 0, fmt.Errorf("no MemTotal in %v", path)
+	}
+	if !hasAvailable { // kernels older than 3.14
+		available = free
+	}
+	return total, available, nil
+}
+
+// cgroupCPUQuota returns the CPU quota of the cgroup rounded up to whole CPUs, 0 if there is none
+func cgroupCPUQuota() int {
+	var quota, period string
+	if raw, err := readFile(filepath.Join(cgroupRoot, "cpu.max")); err == nil { // cgroup v2, e.g. "200000 100000"
+		fields := strings.Fields(raw)
+		if len(fields) != 2 {
+			return 0
+		}
+		quota, period = fields[0], fields[1]
+	} else { // cgroup v1
+		var err1, err2 error
+		quota, err1 = readFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_quota_us"))
+		period, err2 = readFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_period_us"))
+		if err1 != nil || err2 != nil {
+			return 0
+		}
+	}
+	q, err1 := strconv.ParseInt(quota, 10, 64) // "max" or -1 means no quota
+	p, err2 := strconv.ParseInt(period, 10, 64)
+	if err1 != nil || err2 != nil || q <= 0 || p <= 0 {
+		return 0
+	}
+	return int((q + p - 1) / p)
+}
+
+// cgroupMemory returns the memory limit and usage of the cgroup in bytes, ok is false if there is no limit
+func cgroupMemory() (limit uint64, usage uint64, ok bool) {
+	limitPath := filepath.Join(cgroupRoot, "memory.max") // cgroup v2
+	usagePath := filepath.Join(cgroupRoot, "memory.current")
+	if _, err := os.Stat(limitPath); err != nil { // cgroup v1
+		limitPath = filepath.Join(cgroupRoot, "memory", "memory.limit_in_bytes")
+		usagePath = filepath.Join(cgroupRoot, "memory", "memory.usage_in_bytes")
+	}
+	raw, err := readFile(limitPath)
+	if err != nil {
+		return 0, 0, false
+	}
+	limit, err = strconv.ParseUint(raw, 10, 64) // "max" means no limit
+	if err != nil {
+		return 0, 0, false
+	}
+	if raw, err := readFile(usagePath); err == nil {
+		usage, _ = strconv.ParseUint(raw, 10, 64)
+	}
+	return limit, usage, true
+}
+
+func readFile(path string) (string, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
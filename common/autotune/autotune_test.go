@@ -0,0 +1,109 @@
+package autotune
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.Nil(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+	}
+}
+
+func TestDetectResources(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "autotune")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+	defer func(path, root string) { meminfoPath, cgroupRoot = path, root }(meminfoPath, cgroupRoot)
+	meminfoPath = filepath.Join(dir, "meminfo")
+	cgroupRoot = filepath.Join(dir, "cgroup")
+
+	writeFiles(t, dir, map[string]string{
+		"meminfo": "MemTotal:        8388608 kB\nMemFree:         1048576 kB\nMemAvailable:    4194304 kB\n",
+	})
+	res := DetectResources()
+	assert.Equal(uint64(8192*mib), res.TotalMemory)
+	assert.Equal(uint64(4096*mib), res.AvailableMemory)
+
+	// cgroup v1 without limits
+	writeFiles(t, dir, map[string]string{
+		"cgroup/cpu/cpu.cfs_quota_us":         "-1\n",
+		"cgroup/cpu/cpu.cfs_period_us":        "100000\n",
+		"cgroup/memory/memory.limit_in_bytes": "9223372036854771712\n",
+		"cgroup/memory/memory.usage_in_bytes": "1073741824\n",
+	})
+	assert.Equal(0, cgroupCPUQuota())
+	res = DetectResources()
+	assert.Equal(uint64(8192*mib), res.TotalMemory)
+
+	// cgroup v2 with limits
+	writeFiles(t, dir, map[string]string{
+		"cgroup/cpu.max":        "150000 100000\n",
+		"cgroup/memory.max":     "2147483648\n",
+		"cgroup/memory.current": "1610612736\n",
+	})
+	assert.Equal(2, cgroupCPUQuota())
+	res = DetectResources()
+	assert.Equal(uint64(2048*mib), res.TotalMemory)
+	assert.Equal(uint64(512*mib), res.AvailableMemory)
+
+	writeFiles(t, dir, map[string]string{"cgroup/cpu.max": "max 100000\n", "cgroup/memory.max": "max\n"})
+	assert.Equal(0, cgroupCPUQuota())
+	res = DetectResources()
+	assert.Equal(uint64(8192*mib), res.TotalMemory)
+
+	// The available memory is the free memory on the old kernels
+	writeFiles(t, dir, map[string]string{"meminfo": "MemTotal:        8388608 kB\nMemFree:         1048576 kB\n"})
+	res = DetectResources()
+	assert.Equal(uint64(1024*mib), res.AvailableMemory)
+}
+
+func TestTune(t *testing.T) {
+	assert := assert.New(t)
+
+	values := tunedValues(Resources{NumCPU: 2, TotalMemory: 2048 * mib, AvailableMemory: 512 * mib})
+	assert.Equal(128, values[common.CfgStorageLevelDBCacheSize])
+	assert.Equal(int64(384), values[common.CfgMemoryBudgetMB])
+	assert.Equal(2, values[common.CfgSyncSignatureVerifyWorkers])
+	assert.Equal(16, values[common.CfgRPCNumWorkers])
+	assert.Equal(32, values[common.CfgP2PMaxNumPeers])
+
+	values = tunedValues(Resources{NumCPU: 32, TotalMemory: 128 * 1024 * mib, AvailableMemory: 120 * 1024 * mib})
+	assert.Equal(2048, values[common.CfgStorageLevelDBCacheSize])
+	assert.Equal(int64(64*1024), values[common.CfgMemoryBudgetMB])
+	assert.Equal(64, values[common.CfgRPCNumWorkers])
+	assert.Equal(128, values[common.CfgP2PMaxNumPeers])
+
+	// The memory related values are not tuned if the memory is unknown
+	values = tunedValues(Resources{NumCPU: 4})
+	assert.Nil(values[common.CfgStorageLevelDBCacheSize])
+	assert.Nil(values[common.CfgMemoryBudgetMB])
+	assert.Equal(64, values[common.CfgP2PMaxNumPeers])
+
+	// The values set explicitly take precedence
+	viper.Set(common.CfgP2PMaxNumPeers, 40)
+	defer viper.Set(common.CfgP2PMaxNumPeers, 64)
+	Tune(Resources{NumCPU: 8, TotalMemory: 16 * 1024 * mib, AvailableMemory: 8 * 1024 * mib})
+	assert.Equal(40, viper.GetInt(common.CfgP2PMaxNumPeers))
+	assert.Equal(20, viper.GetInt(common.CfgP2PMinNumPeers))
+	assert.Equal(1024, viper.GetInt(common.CfgStorageLevelDBCacheSize))
+	assert.Equal(int64(6*1024), viper.GetInt64(common.CfgMemoryBudgetMB))
+	assert.True(budgetTuned)
+
+	// The budget grows with the memory the components use
+	res := Resources{TotalMemory: 16 * 1024 * mib, AvailableMemory: 8 * 1024 * mib}
+	assert.Equal(int64(6912), memoryBudgetMB(res, 1024*mib))
+}
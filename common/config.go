@@ -10,14 +10,40 @@ const (
 
 	// CfgDataPath defines custom DB path
 	CfgDataPath = "data.path"
+	// CfgDataDBPath sets the directory of the DB holding the blocks and the state, defaults to <data path>/db.
+	// The blocks and the state share the DB, since both are keyed by their hashes.
+	CfgDataDBPath = "data.dbPath"
+	// CfgDataSnapshotPath sets the path of the snapshot the node starts from, defaults to <config path>/snapshot.
+	CfgDataSnapshotPath = "data.snapshotPath"
+	// CfgDataLogPath sets the directory of the log file, the logs are written to the stdout if empty.
+	CfgDataLogPath = "data.logPath"
+	// CfgDataAncientPath sets the directory of the ancient store holding the finalized blocks far behind the tip,
+	// defaults to <db path>/ancient.
+	CfgDataAncientPath = "data.ancientPath"
 
 	// CfgKeyPath defines custom key path
 	CfgKeyPath = "key.path"
 
 	// CfgNodeType indicates the type of the node, e.g. blockchain node/edge node
 	CfgNodeType = "node.type"
+	// CfgNodeShutdownTimeoutSecs defines the deadline (in seconds) for the node to shut down gracefully
+	CfgNodeShutdownTimeoutSecs = "node.shutdownTimeoutSecs"
 	// CfgForceValidateSnapshot defines wether validation of snapshot can be skipped
 	CfgForceValidateSnapshot = "snapshot.force_validate"
+	// CfgSnapshotSchedulerEnabled indicates whether to generate snapshots in the background periodically
+	CfgSnapshotSchedulerEnabled = "snapshot.schedulerEnabled"
+	// CfgSnapshotSchedulerInterval defines the number of finalized blocks between the scheduled snapshots
+	CfgSnapshotSchedulerInterval = "snapshot.schedulerInterval"
+	// CfgSnapshotSchedulerRetainedSnapshots defines the number of the latest scheduled snapshots to keep, 0 keeps all of them
+	CfgSnapshotSchedulerRetainedSnapshots = "snapshot.schedulerRetainedSnapshots"
+	// CfgSnapshotSchedulerMaxBytesPerSec limits the write rate of the scheduled snapshots, 0 means unlimited
+	CfgSnapshotSchedulerMaxBytesPerSec = "snapshot.schedulerMaxBytesPerSec"
+	// CfgSnapshotSchedulerDir defines the directory of the scheduled snapshots, defaults to <config path>/backup/scheduled_snapshot
+	CfgSnapshotSchedulerDir = "snapshot.schedulerDir"
+
+	// CfgHostedChains lists the other chains hosted in the same process, each with the directory holding its
+	// snapshot and db, its libp2p port and seeds, e.g. [{path: /data/subchain, p2pPort: 12100, seeds: ""}]
+	CfgHostedChains = "chains.hosted"
 
 	// CfgGenesisHash defines the hash of the genesis block
 	CfgGenesisHash = "genesis.hash"
@@ -34,6 +60,27 @@ const (
 	CfgConsensusEdgeNodeVoteQueueSize = "consensus.edgeNodeVoteQueueSize"
 	// CfgConsensusPassThroughGuardianVote defines the how guardian vote is handled.
 	CfgConsensusPassThroughGuardianVote = "consensus.passThroughGuardianVote"
+	// CfgConsensusProposalSnapshotRetention defines the number of heights for which the snapshots of the candidate
+	// txs of the blocks proposed by the node are kept. 0 disables the snapshots.
+	CfgConsensusProposalSnapshotRetention = "consensus.proposalSnapshotRetention"
+	// CfgConsensusTxSelection sets how the node picks the mempool transactions for its block proposals: fee_priority
+	// orders them by the effective gas price, and fifo by their arrival time.
+	CfgConsensusTxSelection = "consensus.txSelection"
+	// CfgConsensusTxTypeReserved reserves the block space for the transaction types, picked before the others
+	// regardless of the order, e.g. "service_payment: 200, deposit_stake: 50" in number of transactions.
+	CfgConsensusTxTypeReserved = "consensus.txTypeReserved"
+	// CfgConsensusTxTypeLimits caps the number of the transactions of the types in a block proposal,
+	// e.g. "smart_contract: 1000".
+	CfgConsensusTxTypeLimits = "consensus.txTypeLimits"
+	// CfgConsensusFaultsEquivocate makes the validator propose two conflicting blocks. Like the other
+	// consensus.faults.* configs, it only takes effect in the binaries built with the "faults" tag.
+	CfgConsensusFaultsEquivocate = "consensus.faults.equivocate"
+	// CfgConsensusFaultsWithholdBlocks makes the validator keep its proposed blocks to itself.
+	CfgConsensusFaultsWithholdBlocks = "consensus.faults.withholdBlocks"
+	// CfgConsensusFaultsVoteDelayMs delays the votes of the validator, in milliseconds.
+	CfgConsensusFaultsVoteDelayMs = "consensus.faults.voteDelayMs"
+	// CfgConsensusFaultsMalformedGossipRate sets the fraction of the proposals and votes the validator corrupts.
+	CfgConsensusFaultsMalformedGossipRate = "consensus.faults.malformedGossipRate"
 
 	// CfgStorageStatePruningEnabled indicates whether state pruning is enabled
 	CfgStorageStatePruningEnabled = "storage.statePruningEnabled"
@@ -43,17 +90,85 @@ const (
 	CfgStorageStatePruningRetainedBlocks = "storage.statePruningRetainedBlocks"
 	// CfgStorageStatePruningSkipCheckpoints indicates if the checkpoint state trie should be retained
 	CfgStorageStatePruningSkipCheckpoints = "storage.statePruningSkipCheckpoints"
+	// CfgStorageTxIndexRetainedBlocks indicates the number of blocks whose tx indices are retained, 0 means retaining all
+	CfgStorageTxIndexRetainedBlocks = "storage.txIndexRetainedBlocks"
+	// CfgStorageReceiptRetainedBlocks indicates the number of blocks whose tx receipts are retained, 0 means retaining all
+	CfgStorageReceiptRetainedBlocks = "storage.receiptRetainedBlocks"
+	// CfgStorageLogRetainedBlocks indicates the number of blocks whose tx receipt logs are retained, 0 means retaining all
+	CfgStorageLogRetainedBlocks = "storage.logRetainedBlocks"
+	// CfgStorageAncientRetainedBlocks indicates the number of the latest finalized blocks kept in the DB, the older
+	// blocks and their receipts are moved to the ancient store. 0 keeps all of them in the DB
+	CfgStorageAncientRetainedBlocks = "storage.ancientRetainedBlocks"
+	// CfgStorageIndexPruningInterval indicates the tx index and receipt pruning interval (in terms of blocks), which
+	// is the interval of moving the blocks to the ancient store as well
+	CfgStorageIndexPruningInterval = "storage.indexPruningInterval"
+	// CfgStorageSupplyIndexEnabled indicates whether to index the coins minted and the fees burned by the finalized
+	// blocks for the supply queries. Only the blocks finalized while it is enabled are indexed
+	CfgStorageSupplyIndexEnabled = "storage.supplyIndexEnabled"
+	// CfgStorageNFTIndexEnabled indicates whether to index the TNT-721 transfers of the finalized blocks for the NFT
+	// ownership queries. Only the blocks finalized while it is enabled are indexed
+	CfgStorageNFTIndexEnabled = "storage.nftIndexEnabled"
+	// CfgStorageContractStatsIndexEnabled indicates whether to index the contract calls of the finalized blocks for the
+	// per-contract gas and call stats. Only the blocks finalized while it is enabled are indexed
+	CfgStorageContractStatsIndexEnabled = "storage.contractStatsIndexEnabled"
+	// CfgStorageTxTimingIndexEnabled indicates whether to record when the txs and the blocks are first seen, and to
+	// index the timings of the finalized txs for the inclusion latency queries
+	CfgStorageTxTimingIndexEnabled = "storage.txTimingIndexEnabled"
+	// CfgStorageMaxRollbackBlocks indicates the maximum number of finalized blocks the startup consistency check can roll back
+	CfgStorageMaxRollbackBlocks = "storage.maxRollbackBlocks"
 	// CfgStorageLevelDBCacheSize indicates Level DB cache size
 	CfgStorageLevelDBCacheSize = "storage.levelDBCacheSize"
 	// CfgStorageLevelDBHandles indicates Level DB handle count
 	CfgStorageLevelDBHandles = "storage.levelDBHandles"
 
+	// CfgMemoryBudgetMB sets the memory budget (in MB) partitioned across the trie cache, the block cache, the
+	// mempool and the p2p buffers. The budget is enforced with soft limits, 0 means no budget
+	CfgMemoryBudgetMB = "memory.budgetMB"
+	// CfgMemoryTrieCacheShare sets the percentage of the memory budget for the trie cache, which caps the Level DB cache size
+	CfgMemoryTrieCacheShare = "memory.trieCacheShare"
+	// CfgMemoryBlockCacheShare sets the percentage of the memory budget for the blocks downloaded but not processed yet
+	CfgMemoryBlockCacheShare = "memory.blockCacheShare"
+	// CfgMemoryMempoolShare sets the percentage of the memory budget for the pending transactions of the mempool
+	CfgMemoryMempoolShare = "memory.mempoolShare"
+	// CfgMemoryP2PShare sets the percentage of the memory budget for the receive buffers of the peer connections
+	CfgMemoryP2PShare = "memory.p2pShare"
+
+	// CfgAutoTuneEnabled sets whether to size the caches, the peer limits, the worker counts and the memory budget for
+	// the CPUs and the memory detected on the host. The values set explicitly take precedence over the tuned ones
+	CfgAutoTuneEnabled = "autotune.enabled"
+	// CfgAutoTuneIntervalSecs sets the interval (in seconds) of re-detecting the available memory to adjust the memory budget
+	CfgAutoTuneIntervalSecs = "autotune.intervalSecs"
+
 	// CfgSyncMessageQueueSize defines the capacity of Sync Manager message queue.
 	CfgSyncMessageQueueSize = "sync.messageQueueSize"
 	// CfgSyncDownloadByHash indicates whether should download blocks using hash.
 	CfgSyncDownloadByHash = "sync.downloadByHash"
 	// CfgSyncDownloadByHeader indicates whether should download blocks using header.
 	CfgSyncDownloadByHeader = "sync.downloadByHeader"
+	// CfgSyncCompactBlockRelay indicates whether to gossip blocks as compact blocks.
+	CfgSyncCompactBlockRelay = "sync.compactBlockRelay"
+	// CfgSyncMaxVoteEpochLead defines how many epochs a vote can be ahead of the local tip.
+	CfgSyncMaxVoteEpochLead = "sync.maxVoteEpochLead"
+	// CfgSyncMaxVoteHeightLag defines how many blocks a vote can be behind the last finalized block.
+	CfgSyncMaxVoteHeightLag = "sync.maxVoteHeightLag"
+	// CfgSyncMaxBlockTimestampDrift defines how many seconds a block timestamp can be ahead of the local clock.
+	CfgSyncMaxBlockTimestampDrift = "sync.maxBlockTimestampDrift"
+
+	// CfgMempoolTxGossipFanout defines the max number of peers to gossip a transaction to, 0 means all peers.
+	CfgMempoolTxGossipFanout = "mempool.txGossipFanout"
+	// CfgMempoolTxGossipMaxDelayMillis defines the max random delay (in milliseconds) before relaying a transaction.
+	CfgMempoolTxGossipMaxDelayMillis = "mempool.txGossipMaxDelayMillis"
+	// CfgMempoolRelayPeerTxs indicates whether to relay transactions received from peers. Broadcast-only
+	// nodes (e.g. RPC nodes) disable it so that they only gossip the transactions submitted to them.
+	CfgMempoolRelayPeerTxs = "mempool.relayPeerTxs"
+	// CfgMempoolBlocklist lists the addresses whose transactions are rejected by the mempool. The blocklist only
+	// applies to the admission of the transactions, the blocks proposed by other nodes are never checked against it.
+	CfgMempoolBlocklist = "mempool.blocklist"
+	// CfgMempoolBlocklistFile sets the path of a file listing additional blocked addresses, one per line. The file
+	// is reloaded when it changes.
+	CfgMempoolBlocklistFile = "mempool.blocklistFile"
+	// CfgMempoolBlocklistAuditLog sets the path of the file recording the transactions rejected by the blocklist.
+	CfgMempoolBlocklistAuditLog = "mempool.blocklistAuditLog"
 
 	// CfgP2POpt sets which P2P network to use: p2p, libp2p, or both.
 	CfgP2POpt = "p2p.opt"
@@ -81,6 +196,9 @@ const (
 	CfgLibP2PSeeds = "p2p.libp2pSeeds"
 	// CfgLibP2PRendezvous is the libp2p rendezvous string
 	CfgLibP2PRendezvous = "p2p.libp2pRendezvous"
+	// CfgLibP2PTransports sets the comma separated libp2p transports to listen on, "tcp" and/or "quic".
+	// "quic" requires a binary built with the quic tag.
+	CfgLibP2PTransports = "p2p.libp2pTransports"
 	// CfgP2PMessageQueueSize sets the message queue size for network interface.
 	CfgP2PMessageQueueSize = "p2p.messageQueueSize"
 	// CfgP2PSeedPeerOnlyOutbound decides whether only the seed peers can be outbound peers.
@@ -101,9 +219,41 @@ const (
 	CfgP2PConnectionFIFO = "p2p.connectionFIFO"
 	// CfgP2PNatMapping sets whether to perform NAT mapping
 	CfgP2PNatMapping = "p2p.natMapping"
+	// CfgP2PMaxNumValidatorPeers specifies the connection slots reserved for validator peers
+	CfgP2PMaxNumValidatorPeers = "p2p.maxNumValidatorPeers"
+	// CfgP2PMaxNumGuardianPeers specifies the connection slots reserved for guardian peers
+	CfgP2PMaxNumGuardianPeers = "p2p.maxNumGuardianPeers"
+	// CfgP2PMaxNumEdgeNodePeers specifies the max number of edge node peers, 0 means no limit
+	CfgP2PMaxNumEdgeNodePeers = "p2p.maxNumEdgeNodePeers"
+	// CfgP2PPrivatePeerIDs specifies the IDs of the peers (e.g. validators behind a sentry node) that are never
+	// shared with other peers. Private peers are always relayed to and exempt from the connection slot limits
+	CfgP2PPrivatePeerIDs = "p2p.privatePeerIDs"
+	// CfgP2PInboundDisabled decides whether to reject all inbound connections, e.g. for a validator behind sentry nodes
+	CfgP2PInboundDisabled = "p2p.inboundDisabled"
 	// CfgP2PMaxConnections specifies the number of max connections a node can accept
 	CfgP2PMaxConnections = "p2p.maxConnections"
+	// CfgP2PSeenCacheEnabled sets whether to drop duplicate gossip messages before they are parsed
+	CfgP2PSeenCacheEnabled = "p2p.seenCacheEnabled"
+	// CfgP2PSeenCacheBucketSecs specifies the time span (in seconds) covered by each bucket of the seen cache
+	CfgP2PSeenCacheBucketSecs = "p2p.seenCacheBucketSecs"
+	// CfgP2PSeenCacheNumBuckets specifies the number of buckets of the seen cache
+	CfgP2PSeenCacheNumBuckets = "p2p.seenCacheNumBuckets"
+	// CfgP2PAdvertiseSnapshot sets whether to advertise to the peers that the node offers snapshots for state sync
+	CfgP2PAdvertiseSnapshot = "p2p.advertiseSnapshot"
+	// CfgP2PTopologyProbe sets whether the node reports its peers to the topology probes of its peers, and
+	// can probe the topology of its neighborhood
+	CfgP2PTopologyProbe = "p2p.topologyProbe"
 
+	// CfgSyncSignatureVerifyWorkers sets the number of goroutines verifying the signatures of the synced blocks, 0 for the number of CPUs.
+	CfgSyncSignatureVerifyWorkers = "sync.signatureVerifyWorkers"
+	// CfgSyncSignatureSampleRate sets the fraction of the block and vote signatures verified below the trusted checkpoint. The
+	// blocks below the checkpoint are certified by its hash, but a forged block is only detected once the sync reaches the
+	// checkpoint, so the sampling should only be used with a checkpoint from a trusted source. 1 verifies all the signatures.
+	CfgSyncSignatureSampleRate = "sync.signatureSampleRate"
+	// CfgSyncTrustedCheckpointHeight sets the height of a trusted checkpoint in addition to the hardcoded block hashes.
+	CfgSyncTrustedCheckpointHeight = "sync.trustedCheckpointHeight"
+	// CfgSyncTrustedCheckpointHash sets the block hash of the trusted checkpoint.
+	CfgSyncTrustedCheckpointHash = "sync.trustedCheckpointHash"
 	// CfgSyncInboundResponseWhitelist filters inbound messages based on peer ID.
 	CfgSyncInboundResponseWhitelist = "sync.inboundResponseWhitelist"
 
@@ -117,6 +267,78 @@ const (
 	CfgRPCMaxConnections = "rpc.maxConnections"
 	// CfgRPCTimeoutSecs set a timeout for RPC.
 	CfgRPCTimeoutSecs = "rpc.timeoutSecs"
+	// CfgRPCMaxRequestBytes limits the size of a single RPC request, 0 means no limit.
+	CfgRPCMaxRequestBytes = "rpc.maxRequestBytes"
+	// CfgRPCMaxResponseBytes limits the size of a single RPC response, 0 means no limit. Larger results
+	// should be retrieved with the streaming endpoints.
+	CfgRPCMaxResponseBytes = "rpc.maxResponseBytes"
+	// CfgRPCNumWorkers sets the number of workers running the RPC handlers.
+	CfgRPCNumWorkers = "rpc.numWorkers"
+	// CfgRPCMaxQueueDepth limits the number of pending requests of each priority class. Requests
+	// beyond the limit are rejected right away.
+	CfgRPCMaxQueueDepth = "rpc.maxQueueDepth"
+	// CfgRPCStreamFlushInterval sets the number of items written between flushes of a streaming response.
+	CfgRPCStreamFlushInterval = "rpc.streamFlushInterval"
+	// CfgRPCCacheEnabled sets whether to cache the finalized RPC query results. The cached results are
+	// not invalidated when the state or the indices are pruned.
+	CfgRPCCacheEnabled = "rpc.cacheEnabled"
+	// CfgRPCCacheSize sets the maximum number of cached RPC query results.
+	CfgRPCCacheSize = "rpc.cacheSize"
+	// CfgRPCBreakerFailureRatio sets the ratio of failed calls, i.e. the panics and the calls exceeding the
+	// RPC timeout, above which the circuit breaker of an RPC method opens. 0 disables the circuit breakers.
+	CfgRPCBreakerFailureRatio = "rpc.breakerFailureRatio"
+	// CfgRPCBreakerMinCalls sets the min number of calls of an RPC method in a window before its circuit breaker can open.
+	CfgRPCBreakerMinCalls = "rpc.breakerMinCalls"
+	// CfgRPCBreakerWindowSecs sets the length of the window over which the failure ratio is computed.
+	CfgRPCBreakerWindowSecs = "rpc.breakerWindowSecs"
+	// CfgRPCBreakerCooldownSecs sets how long an open circuit breaker rejects the calls before letting a trial call through.
+	CfgRPCBreakerCooldownSecs = "rpc.breakerCooldownSecs"
+	// CfgRPCRequireChainID sets whether the broadcast RPCs reject the transactions submitted without the chain_id parameter.
+	CfgRPCRequireChainID = "rpc.requireChainID"
+	// CfgRPCHealthMaxBlocksBehind sets the max number of blocks the node can lag behind the network tip and still be ready.
+	CfgRPCHealthMaxBlocksBehind = "rpc.healthMaxBlocksBehind"
+	// CfgRPCHealthMinPeers sets the min number of peers required for the node to be ready.
+	CfgRPCHealthMinPeers = "rpc.healthMinPeers"
+	// CfgRPCHealthMempoolStallSecs sets how long a non-empty mempool can go without draining before it is considered wedged.
+	// 0 disables the check.
+	CfgRPCHealthMempoolStallSecs = "rpc.healthMempoolStallSecs"
+	// CfgRPCHealthCheckDBWrite sets whether the readiness check verifies that the database is writable.
+	CfgRPCHealthCheckDBWrite = "rpc.healthCheckDBWrite"
+	// CfgRPCProfile sets the RPC profile of the main RPC listener, i.e. the methods it serves. The built-in profiles
+	// are operator (all the methods), public, wallet and archive.
+	CfgRPCProfile = "rpc.profile"
+	// CfgRPCProfiles defines custom RPC profiles, or overrides the built-in ones, by the method patterns allowed and
+	// denied, e.g. {readonly: {allow: ["theta.Get*"], deny: ["theta.GetPeers"]}}
+	CfgRPCProfiles = "rpc.profiles"
+	// CfgRPCListeners lists the additional RPC listeners, each serving the methods of its RPC profile,
+	// e.g. [{address: 127.0.0.1, port: 16889, profile: operator}]
+	CfgRPCListeners = "rpc.listeners"
+	// CfgRPCIPCPath sets the path of the Unix domain socket serving the RPC requests, the IPC listener is disabled if empty.
+	CfgRPCIPCPath = "rpc.ipcPath"
+	// CfgRPCIPCMode sets the octal file mode of the RPC IPC socket, which controls who can call the node through it.
+	CfgRPCIPCMode = "rpc.ipcMode"
+	// CfgRPCIPCProfile sets the RPC profile of the IPC listener.
+	CfgRPCIPCProfile = "rpc.ipcProfile"
+	// CfgRPCAuditLog sets where the audit records of the sensitive RPC calls are written, either the path of a
+	// file rotated by size, or "syslog". The audit log is disabled if empty.
+	CfgRPCAuditLog = "rpc.auditLog"
+	// CfgRPCAuditMethods lists the patterns of the audited RPC methods, defaults to the broadcast, admin and keystore methods.
+	CfgRPCAuditMethods = "rpc.auditMethods"
+	// CfgRPCAuditLogMaxSizeMB sets the size at which the audit log file is rotated.
+	CfgRPCAuditLogMaxSizeMB = "rpc.auditLogMaxSizeMB"
+	// CfgRPCAuditLogMaxBackups sets the number of rotated audit log files kept.
+	CfgRPCAuditLogMaxBackups = "rpc.auditLogMaxBackups"
+	// CfgRPCFilterTimeoutSecs sets how long a filter created by the NewFilter RPCs is kept without being polled.
+	CfgRPCFilterTimeoutSecs = "rpc.filterTimeoutSecs"
+	// CfgRPCMaxFilters limits the number of filters installed at the same time.
+	CfgRPCMaxFilters = "rpc.maxFilters"
+	// CfgRPCContractRegistryEnabled enables the registry of the contracts verified by the node, which compiles the
+	// uploaded sources, and decodes the calls and the logs of the verified contracts in the RPC responses.
+	CfgRPCContractRegistryEnabled = "rpc.contractRegistry.enabled"
+	// CfgRPCContractRegistrySolc sets the path of the solc binary compiling the uploaded sources.
+	CfgRPCContractRegistrySolc = "rpc.contractRegistry.solc"
+	// CfgRPCContractRegistryCompileTimeoutSecs limits how long a compilation of an uploaded source can take.
+	CfgRPCContractRegistryCompileTimeoutSecs = "rpc.contractRegistry.compileTimeoutSecs"
 
 	// CfgLogLevels sets the log level.
 	CfgLogLevels = "log.levels"
@@ -127,6 +349,36 @@ const (
 	// CfgGuardianRoundLength defines the length of a guardian voting round.
 	CfgGuardianRoundLength = "guardian.roundLength"
 
+	// CfgUpgradeName specifies the name of the pending coordinated upgrade
+	CfgUpgradeName = "upgrade.name"
+	// CfgUpgradeHeight specifies the block height of the pending upgrade. A node running a binary that
+	// does not implement the upgrade stops producing and applying blocks from that height
+	CfgUpgradeHeight = "upgrade.height"
+	// CfgUpgradeInfo specifies the description of the pending upgrade, e.g. the release URL
+	CfgUpgradeInfo = "upgrade.info"
+
+	// CfgForkHeights overrides the activation heights of the forks, e.g. "theta3: 1000", to schedule the forks
+	// on a testnet. It cannot be used on the Mainnet
+	CfgForkHeights = "fork.heights"
+
+	// CfgGenesisParams sets the expected economic parameters of the chain, e.g. "min_validator_stake: '1000000000000000000'".
+	// The parameters are committed to the genesis snapshot, the node refuses to start if they differ. Optional
+	CfgGenesisParams = "genesis.params"
+
+	// CfgClockNTPServer specifies the NTP server to check the local clock against, empty to disable the NTP checks
+	CfgClockNTPServer = "clock.ntpServer"
+	// CfgClockNTPCheckIntervalSecs defines the interval (in seconds) between the NTP checks
+	CfgClockNTPCheckIntervalSecs = "clock.ntpCheckIntervalSecs"
+	// CfgClockMaxDriftSecs defines how many seconds the local clock can drift before the node warns about it
+	CfgClockMaxDriftSecs = "clock.maxDriftSecs"
+
+	// CfgShadowEnabled indicates whether the node runs in the shadow mode, where it follows the blocks
+	// finalized by the validators without voting, executes them with the local ledger logic, and records
+	// the divergences instead of rejecting the blocks. It should only run on a copy of the node data
+	CfgShadowEnabled = "shadow.enabled"
+	// CfgShadowReferenceRPC specifies the RPC endpoint of a reference node to compare the tx receipts with
+	CfgShadowReferenceRPC = "shadow.referenceRPC"
+
 	// Graphite Server to collet metrics
 	CfgMetricsServer = "metrics.server"
 
@@ -154,25 +406,69 @@ p2p:
 
 func init() {
 	viper.SetDefault(CfgNodeType, 1) // 1: blockchain node, 2: edge node
+	viper.SetDefault(CfgNodeShutdownTimeoutSecs, 30)
 	viper.SetDefault(CfgForceValidateSnapshot, false)
+	viper.SetDefault(CfgSnapshotSchedulerEnabled, false)
+	viper.SetDefault(CfgSnapshotSchedulerInterval, 14400) // roughly once a day
+	viper.SetDefault(CfgSnapshotSchedulerRetainedSnapshots, 3)
+	viper.SetDefault(CfgSnapshotSchedulerMaxBytesPerSec, 32*1024*1024)
+	viper.SetDefault(CfgSnapshotSchedulerDir, "")
 
 	viper.SetDefault(CfgConsensusMaxEpochLength, 20)
 	viper.SetDefault(CfgConsensusMinProposalWait, 6)
 	viper.SetDefault(CfgConsensusMessageQueueSize, 512)
 	viper.SetDefault(CfgConsensusEdgeNodeVoteQueueSize, 100000)
 	viper.SetDefault(CfgConsensusPassThroughGuardianVote, false)
+	viper.SetDefault(CfgConsensusProposalSnapshotRetention, 100000)
+	viper.SetDefault(CfgConsensusTxSelection, "fee_priority")
+	viper.SetDefault(CfgConsensusFaultsEquivocate, false)
+	viper.SetDefault(CfgConsensusFaultsWithholdBlocks, false)
+	viper.SetDefault(CfgConsensusFaultsVoteDelayMs, 0)
+	viper.SetDefault(CfgConsensusFaultsMalformedGossipRate, 0.0)
 
 	viper.SetDefault(CfgSyncMessageQueueSize, 512)
 	viper.SetDefault(CfgSyncDownloadByHash, false)
 	viper.SetDefault(CfgSyncDownloadByHeader, true)
+	viper.SetDefault(CfgSyncCompactBlockRelay, true)
+	viper.SetDefault(CfgSyncMaxVoteEpochLead, 1000)
+	viper.SetDefault(CfgSyncMaxVoteHeightLag, 100)
+	viper.SetDefault(CfgSyncMaxBlockTimestampDrift, 120)
+	viper.SetDefault(CfgSyncSignatureVerifyWorkers, 0)
+	viper.SetDefault(CfgSyncSignatureSampleRate, 1.0)
+	viper.SetDefault(CfgSyncTrustedCheckpointHeight, 0)
+	viper.SetDefault(CfgSyncTrustedCheckpointHash, "")
+
+	viper.SetDefault(CfgMempoolTxGossipFanout, 0)
+	viper.SetDefault(CfgMempoolTxGossipMaxDelayMillis, 0)
+	viper.SetDefault(CfgMempoolRelayPeerTxs, true)
+	viper.SetDefault(CfgMempoolBlocklistFile, "")
+	viper.SetDefault(CfgMempoolBlocklistAuditLog, "")
 
 	viper.SetDefault(CfgStorageStatePruningEnabled, true)
 	viper.SetDefault(CfgStorageStatePruningInterval, 16)
 	viper.SetDefault(CfgStorageStatePruningRetainedBlocks, 2048)
 	viper.SetDefault(CfgStorageStatePruningSkipCheckpoints, true)
+	viper.SetDefault(CfgStorageTxIndexRetainedBlocks, 0)
+	viper.SetDefault(CfgStorageReceiptRetainedBlocks, 0)
+	viper.SetDefault(CfgStorageLogRetainedBlocks, 0)
+	viper.SetDefault(CfgStorageAncientRetainedBlocks, 0)
+	viper.SetDefault(CfgStorageIndexPruningInterval, 16)
+	viper.SetDefault(CfgStorageSupplyIndexEnabled, false)
+	viper.SetDefault(CfgStorageNFTIndexEnabled, false)
+	viper.SetDefault(CfgStorageContractStatsIndexEnabled, false)
+	viper.SetDefault(CfgStorageTxTimingIndexEnabled, false)
+	viper.SetDefault(CfgStorageMaxRollbackBlocks, 2048)
 	viper.SetDefault(CfgStorageLevelDBCacheSize, 256)
 	viper.SetDefault(CfgStorageLevelDBHandles, 16)
 
+	viper.SetDefault(CfgMemoryBudgetMB, 0)
+	viper.SetDefault(CfgMemoryTrieCacheShare, 40)
+	viper.SetDefault(CfgMemoryBlockCacheShare, 30)
+	viper.SetDefault(CfgMemoryMempoolShare, 15)
+	viper.SetDefault(CfgMemoryP2PShare, 15)
+	viper.SetDefault(CfgAutoTuneEnabled, true)
+	viper.SetDefault(CfgAutoTuneIntervalSecs, 60)
+
 	viper.SetDefault(CfgRPCEnabled, false)
 	viper.SetDefault(CfgP2PMessageQueueSize, 512)
 	viper.SetDefault(CfgP2PName, "Anonymous")
@@ -193,18 +489,71 @@ func init() {
 	viper.SetDefault(CfgBufferPoolSize, 8)
 	viper.SetDefault(CfgP2PConnectionFIFO, false)
 	viper.SetDefault(CfgP2PNatMapping, false)
+	viper.SetDefault(CfgP2PMaxNumValidatorPeers, 32)
+	viper.SetDefault(CfgP2PMaxNumGuardianPeers, 64)
+	viper.SetDefault(CfgP2PMaxNumEdgeNodePeers, 0)
+	viper.SetDefault(CfgP2PPrivatePeerIDs, "")
+	viper.SetDefault(CfgP2PAdvertiseSnapshot, false)
+	viper.SetDefault(CfgP2PTopologyProbe, false)
+	viper.SetDefault(CfgP2PInboundDisabled, false)
 	viper.SetDefault(CfgP2PMaxConnections, 2048)
+	viper.SetDefault(CfgLibP2PTransports, "tcp")
+	viper.SetDefault(CfgP2PSeenCacheEnabled, true)
+	viper.SetDefault(CfgP2PSeenCacheBucketSecs, 30)
+	viper.SetDefault(CfgP2PSeenCacheNumBuckets, 4)
 
 	viper.SetDefault(CfgRPCAddress, "0.0.0.0")
 	viper.SetDefault(CfgRPCPort, "16888")
 	viper.SetDefault(CfgRPCMaxConnections, 200)
 	viper.SetDefault(CfgRPCTimeoutSecs, 60)
+	viper.SetDefault(CfgRPCMaxRequestBytes, 10*1024*1024)
+	viper.SetDefault(CfgRPCMaxResponseBytes, 64*1024*1024)
+	viper.SetDefault(CfgRPCStreamFlushInterval, 100)
+	viper.SetDefault(CfgRPCNumWorkers, 32)
+	viper.SetDefault(CfgRPCMaxQueueDepth, 1000)
+	viper.SetDefault(CfgRPCCacheEnabled, false)
+	viper.SetDefault(CfgRPCCacheSize, 4096)
+	viper.SetDefault(CfgRPCBreakerFailureRatio, 0.5)
+	viper.SetDefault(CfgRPCBreakerMinCalls, 20)
+	viper.SetDefault(CfgRPCBreakerWindowSecs, 60)
+	viper.SetDefault(CfgRPCBreakerCooldownSecs, 30)
+	viper.SetDefault(CfgRPCRequireChainID, false)
+	viper.SetDefault(CfgRPCHealthMaxBlocksBehind, 20)
+	viper.SetDefault(CfgRPCHealthMinPeers, 1)
+	viper.SetDefault(CfgRPCHealthMempoolStallSecs, 300)
+	viper.SetDefault(CfgRPCHealthCheckDBWrite, true)
+	viper.SetDefault(CfgRPCProfile, "operator")
+	viper.SetDefault(CfgRPCIPCPath, "")
+	viper.SetDefault(CfgRPCIPCMode, "0600")
+	viper.SetDefault(CfgRPCIPCProfile, "operator")
+	viper.SetDefault(CfgRPCAuditLog, "")
+	viper.SetDefault(CfgRPCAuditLogMaxSizeMB, 100)
+	viper.SetDefault(CfgRPCAuditLogMaxBackups, 10)
+	viper.SetDefault(CfgRPCFilterTimeoutSecs, 300)
+	viper.SetDefault(CfgRPCMaxFilters, 1000)
+	viper.SetDefault(CfgRPCContractRegistryEnabled, false)
+	viper.SetDefault(CfgRPCContractRegistrySolc, "solc")
+	viper.SetDefault(CfgRPCContractRegistryCompileTimeoutSecs, 60)
 
 	viper.SetDefault(CfgLogLevels, "*:debug")
 	viper.SetDefault(CfgLogPrintSelfID, false)
 
 	viper.SetDefault(CfgGuardianRoundLength, 30)
 
+	viper.SetDefault(CfgUpgradeName, "")
+	viper.SetDefault(CfgUpgradeHeight, 0)
+	viper.SetDefault(CfgUpgradeInfo, "")
+
+	viper.SetDefault(CfgForkHeights, map[string]uint64{})
+	viper.SetDefault(CfgGenesisParams, map[string]string{})
+
+	viper.SetDefault(CfgClockNTPServer, "pool.ntp.org")
+	viper.SetDefault(CfgClockNTPCheckIntervalSecs, 1800)
+	viper.SetDefault(CfgClockMaxDriftSecs, 5)
+
+	viper.SetDefault(CfgShadowEnabled, false)
+	viper.SetDefault(CfgShadowReferenceRPC, "")
+
 	viper.SetDefault(CfgMetricsServer, "guardian-metrics.thetatoken.org")
 
 	viper.SetDefault(CfgProfEnabled, false)
@@ -21,6 +21,42 @@ const HeightJune2021FeeAdjustment uint64 = 10709540 // approximate time: 12pm Ju
 // HeightEnableTheta3 specifies the minimal block height to enable the Theta3.0 feature.
 const HeightEnableTheta3 uint64 = 10968061 // approximate time: 12pm June 30, 2021 PT
 
+// HeightEnableParameterChange specifies the minimal block height to enable the validator governed block
+// gas limit and max number of txs per block. It is to be scheduled by a future network upgrade.
+const HeightEnableParameterChange uint64 = 1 << 62
+
+// HeightEnableSubchainAnchoring specifies the minimal block height to accept the subchain checkpoint
+// transactions. It is to be scheduled by a future network upgrade.
+const HeightEnableSubchainAnchoring uint64 = 1 << 62
+
+// HeightEnableCrossChainMessaging specifies the minimal block height to accept the cross-chain message
+// transactions. It is to be scheduled by a future network upgrade.
+const HeightEnableCrossChainMessaging uint64 = 1 << 62
+
+// HeightEnableValidatorKeyChange specifies the minimal block height to accept the validator key change
+// transactions. It is to be scheduled by a future network upgrade.
+const HeightEnableValidatorKeyChange uint64 = 1 << 62
+
+// HeightEnableStakeAutoCompounding specifies the minimal block height to compound the staking rewards into
+// the elite edge node stakes. It is to be scheduled by a future network upgrade.
+const HeightEnableStakeAutoCompounding uint64 = 1 << 62
+
+// HeightEnableRandomnessBeacon specifies the minimal block height to derive the per-block randomness
+// exposed to the smart contracts. It is to be scheduled by a future network upgrade.
+const HeightEnableRandomnessBeacon uint64 = 1 << 62
+
+// HeightEnableScheduledTx specifies the minimal block height to accept the scheduled transactions. It is to be
+// scheduled by a future network upgrade.
+const HeightEnableScheduledTx uint64 = 1 << 62
+
+// HeightEnableFeeSponsorship specifies the minimal block height to accept the transactions with a fee payer
+// other than the sender. It is to be scheduled by a future network upgrade.
+const HeightEnableFeeSponsorship uint64 = 1 << 62
+
+// HeightEnableDynamicBaseFee specifies the minimal block height to charge the smart contract gas at the base fee
+// adjusted by the block utilization. It is to be scheduled by a future network upgrade.
+const HeightEnableDynamicBaseFee uint64 = 1 << 62
+
 // CheckpointInterval defines the interval between checkpoints.
 const CheckpointInterval = int64(100)
 
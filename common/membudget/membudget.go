@@ -0,0 +1,196 @@
+// Package membudget partitions the memory budget of the node across the components holding most of its
+// memory, i.e. the trie cache, the block cache, the mempool and the p2p buffers. Each component tracks its
+// usage against its share of the budget. The limits are soft: a component over its limit sheds new load, or
+// gives back the memory it could keep, rather than failing the allocations already under way.
+package membudget
+
+import (
+	"sync"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
+)
+
+var logger *log.Entry = log.WithFields(log.Fields{"prefix": "membudget"})
+
+// Component is a component of the node the memory budget is partitioned across
+type Component string
+
+const (
+	TrieCache  Component = "trie_cache"  // the DB cache holding the recently used trie nodes
+	BlockCache Component = "block_cache" // the blocks downloaded but not processed by the consensus yet
+	Mempool    Component = "mempool"     // the pending transactions
+	P2P        Component = "p2p"         // the buffers of the peer connections
+)
+
+// shareConfigs maps the components to the config of their share of the budget
+var shareConfigs = map[Component]string{
+	TrieCache:  common.CfgMemoryTrieCacheShare,
+	BlockCache: common.CfgMemoryBlockCacheShare,
+	Mempool:    common.CfgMemoryMempoolShare,
+	P2P:        common.CfgMemoryP2PShare,
+}
+
+// Limit returns the soft limit of the given component in bytes, 0 if no memory budget is configured. The
+// shares are percentages of the budget, and are scaled down if they add up to more than 100.
+func Limit(component Component) int64 {
+	return limit(component, viper.GetInt64(common.CfgMemoryBudgetMB))
+}
+
+func limit(component Component, budgetMB int64) int64 {
+	if budgetMB <= 0 {
+		return 0
+	}
+	totalShares := int64(0)
+	for _, cfgKey := range shareConfigs {
+		if share := viper.GetInt64(cfgKey); share > 0 {
+			totalShares += share
+		}
+	}
+	if totalShares < 100 {
+		totalShares = 100
+	}
+	share := viper.GetInt64(shareConfigs[component])
+	if share <= 0 {
+		return 0
+	}
+	return budgetMB * 1024 * 1024 * share / totalShares
+}
+
+var (
+	allocations   = make(map[Component]*Allocation)
+	allocationsMu sync.Mutex
+)
+
+// Get returns the allocation shared by the users of the given component. Its limit is read from the config
+// the first time it is requested.
+func Get(component Component) *Allocation {
+	allocationsMu.Lock()
+	defer allocationsMu.Unlock()
+
+	if a, ok := allocations[component]; ok {
+		return a
+	}
+	a := NewAllocation(component, Limit(component))
+	if limit := a.Limit(); limit > 0 {
+		logger.Infof("Memory budget of the %v: %v MB", component, limit/(1024*1024))
+	}
+	allocations[component] = a
+	return a
+}
+
+// Rebudget sets the limits of the shared allocations for the given budget (in MB), e.g. once the budget has
+// been re-tuned for the memory available. The trie cache keeps the size it was opened with though.
+func Rebudget(budgetMB int64) {
+	allocationsMu.Lock()
+	defer allocationsMu.Unlock()
+
+	for component, a := range allocations {
+		if newLimit := limit(component, budgetMB); newLimit != a.Limit() {
+			logger.Infof("Memory budget of the %v: %v MB", component, newLimit/(1024*1024))
+			a.setLimit(newLimit)
+		}
+	}
+}
+
+// TotalUsed returns the memory used by the components sharing the budget in bytes
+func TotalUsed() int64 {
+	allocationsMu.Lock()
+	defer allocationsMu.Unlock()
+
+	total := int64(0)
+	for _, a := range allocations {
+		total += a.Used()
+	}
+	return total
+}
+
+// Allocation tracks the memory used by a component against its limit. A nil Allocation tracks nothing and
+// has no limit.
+type Allocation struct {
+	component Component
+	limit     int64 // 0 means no limit
+	used      int64
+
+	limitGauge metrics.Gauge
+	usedGauge  metrics.Gauge
+	refused    metrics.Counter
+}
+
+// NewAllocation creates an allocation with the given limit (in bytes) for the component
+func NewAllocation(component Component, limit int64) *Allocation {
+	prefix := "membudget/" + string(component)
+	a := &Allocation{
+		component:  component,
+		limitGauge: metrics.GetOrRegisterGauge(prefix+"/limit", nil),
+		usedGauge:  metrics.GetOrRegisterGauge(prefix+"/used", nil),
+		refused:    metrics.GetOrRegisterCounter(prefix+"/refused", nil),
+	}
+	a.setLimit(limit)
+	return a
+}
+
+func (a *Allocation) setLimit(limit int64) {
+	atomic.StoreInt64(&a.limit, limit)
+	a.limitGauge.Update(limit)
+}
+
+// Limit returns the soft limit in bytes, 0 if there is none
+func (a *Allocation) Limit() int64 {
+	if a == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&a.limit)
+}
+
+// Used returns the memory in use in bytes
+func (a *Allocation) Used() int64 {
+	if a == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&a.used)
+}
+
+// Exceeded returns whether the memory in use is above the limit
+func (a *Allocation) Exceeded() bool {
+	return a.Limit() > 0 && a.Used() > a.Limit()
+}
+
+// Admit returns whether the component can take more load, i.e. its memory in use is below the limit. A
+// refusal is counted in the metrics.
+func (a *Allocation) Admit() bool {
+	if a.Limit() == 0 || a.Used() < a.Limit() {
+		return true
+	}
+	a.refused.Inc(1)
+	return false
+}
+
+// Reserve records that the component uses size more bytes
+func (a *Allocation) Reserve(size int64) {
+	if a == nil || size == 0 {
+		return
+	}
+	a.usedGauge.Update(atomic.AddInt64(&a.used, size))
+}
+
+// Release records that the component no longer uses size bytes
+func (a *Allocation) Release(size int64) {
+	a.Reserve(-size)
+}
+
+// TrieCacheSizeMB returns the size (in MB) of the DB cache holding the trie nodes, i.e. the configured size
+// capped by the trie cache share of the budget. The cache is accounted as used once it is sized.
+func TrieCacheSizeMB(configuredMB int) int {
+	a := Get(TrieCache)
+	sizeMB := int64(configuredMB)
+	if limitMB := a.Limit() / (1024 * 1024); limitMB > 0 && sizeMB > limitMB {
+		logger.Infof("Capping the DB cache size from %v MB to %v MB", sizeMB, limitMB)
+		sizeMB = limitMB
+	}
+	a.Reserve(sizeMB * 1024 * 1024)
+	return int(sizeMB)
+}
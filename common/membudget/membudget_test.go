@@ -0,0 +1,82 @@
+package membudget
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+)
+
+const mib = 1024 * 1024
+
+func TestLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(int64(0), Limit(TrieCache))
+
+	viper.Set(common.CfgMemoryBudgetMB, 1000)
+	defer viper.Set(common.CfgMemoryBudgetMB, 0)
+	assert.Equal(int64(400*mib), Limit(TrieCache))
+	assert.Equal(int64(150*mib), Limit(P2P))
+
+	// The shares adding up to more than 100 are scaled down
+	viper.Set(common.CfgMemoryP2PShare, 115)
+	defer viper.Set(common.CfgMemoryP2PShare, 15)
+	assert.Equal(int64(200*mib), Limit(TrieCache))
+	assert.Equal(int64(575*mib), Limit(P2P))
+
+	viper.Set(common.CfgMemoryMempoolShare, 0)
+	defer viper.Set(common.CfgMemoryMempoolShare, 15)
+	assert.Equal(int64(0), Limit(Mempool))
+}
+
+func TestAllocation(t *testing.T) {
+	assert := assert.New(t)
+
+	a := NewAllocation(P2P, 100)
+	assert.True(a.Admit())
+	a.Reserve(100)
+	assert.False(a.Exceeded())
+	assert.False(a.Admit())
+	a.Reserve(1)
+	assert.True(a.Exceeded())
+	a.Release(101)
+	assert.Equal(int64(0), a.Used())
+	assert.True(a.Admit())
+
+	unlimited := NewAllocation(Mempool, 0)
+	unlimited.Reserve(1 << 40)
+	assert.False(unlimited.Exceeded())
+	assert.True(unlimited.Admit())
+
+	var none *Allocation
+	none.Reserve(10)
+	assert.Equal(int64(0), none.Used())
+	assert.False(none.Exceeded())
+	assert.True(none.Admit())
+}
+
+func TestTrieCacheSizeMB(t *testing.T) {
+	assert := assert.New(t)
+
+	viper.Set(common.CfgMemoryBudgetMB, 100)
+	defer viper.Set(common.CfgMemoryBudgetMB, 0)
+	assert.Equal(40, TrieCacheSizeMB(256))
+	assert.Equal(int64(40*mib), Get(TrieCache).Used())
+	assert.Equal(16, TrieCacheSizeMB(16))
+}
+
+func TestRebudget(t *testing.T) {
+	assert := assert.New(t)
+
+	a := Get(Mempool)
+	assert.Equal(int64(0), a.Limit())
+	Rebudget(100)
+	assert.Equal(int64(15*mib), a.Limit())
+	a.Reserve(10)
+	assert.True(TotalUsed() >= 10)
+	Rebudget(0)
+	assert.Equal(int64(0), a.Limit())
+	a.Release(10)
+}
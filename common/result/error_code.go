@@ -41,4 +41,13 @@ const (
 	CodeInsufficientStake       ErrorCode = 106003
 	CodeNotEnoughBalanceToStake ErrorCode = 106004
 	CodeStakeExceedsCap         ErrorCode = 106005
+
+	// Scheduled Tx Errors
+	CodeTxNotYetEligible ErrorCode = 107001
+
+	// Query Errors
+	CodeNotFound ErrorCode = 108001
+
+	// Blocklist Errors
+	CodeBlockedAddress ErrorCode = 109001
 )
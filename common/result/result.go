@@ -67,3 +67,9 @@ func Error(msgFormat string, a ...interface{}) Result {
 		Info:    make(Info),
 	}
 }
+
+// ErrorData is the data of the RPC errors that carry a result code. Clients can tell the failure
+// classes apart by the result code, which unlike the error message is stable.
+type ErrorData struct {
+	ResultCode ErrorCode `json:"result_code"`
+}
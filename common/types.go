@@ -409,6 +409,9 @@ const (
 
 	// ChannelIDAggregatedEliteEdgeNodeVotes indicates the channel for Elite Edge Node aggregated vote messages
 	ChannelIDAggregatedEliteEdgeNodeVotes
+
+	// ChannelIDCompactBlock indicates the channel for compact blocks
+	ChannelIDCompactBlock
 )
 
 // P2POptEnum defines the p2p network
@@ -0,0 +1,229 @@
+package consensus
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
+)
+
+const (
+	maxClockSamples = 64 // number of the recent block timestamps to estimate the drift from
+	minClockSamples = 8  // number of the samples required before warning about the drift
+
+	ntpTimeout     = 5 * time.Second
+	ntpEpochOffset = 2208988800 // seconds from 1900 to 1970
+)
+
+// ClockStatus is the estimated drift of the local clock. The drift is positive if the local clock is ahead.
+type ClockStatus struct {
+	BlockDriftMillis int64 `json:"block_drift_ms"` // median drift against the timestamps of the recent proposals
+	NumBlockSamples  int   `json:"num_block_samples"`
+
+	NTPServer      string `json:"ntp_server,omitempty"`
+	NTPDriftMillis int64  `json:"ntp_drift_ms"`
+	NTPCheckedAt   int64  `json:"ntp_checked_at,omitempty"` // unix time of the last successful NTP query
+	NTPError       string `json:"ntp_error,omitempty"`
+
+	MaxDriftMillis int64 `json:"max_drift_ms"`
+	Warning        bool  `json:"warning"` // whether the drift exceeds the max drift
+}
+
+// ClockMonitor estimates the drift of the local clock from the timestamps of the fresh blocks proposed by the
+// other nodes, and optionally from an NTP server. A drifting clock silently gets the proposals of the node
+// rejected by its peers, and its votes land in the wrong epochs, so the monitor warns in the log and the metrics.
+type ClockMonitor struct {
+	logger *log.Entry
+
+	mu      *sync.Mutex
+	samples []time.Duration // ring buffer of the drift samples from the block timestamps
+	next    int
+
+	ntpServer    string
+	ntpInterval  time.Duration
+	ntpDrift     time.Duration
+	ntpCheckedAt time.Time
+	ntpErr       error
+
+	maxDrift time.Duration
+	warning  bool
+}
+
+// NewClockMonitor creates a new instance of ClockMonitor
+func NewClockMonitor(logger *log.Entry) *ClockMonitor {
+	return &ClockMonitor{
+		logger:      logger,
+		mu:          &sync.Mutex{},
+		samples:     []time.Duration{},
+		ntpServer:   viper.GetString(common.CfgClockNTPServer),
+		ntpInterval: time.Duration(viper.GetInt(common.CfgClockNTPCheckIntervalSecs)) * time.Second,
+		maxDrift:    time.Duration(viper.GetInt(common.CfgClockMaxDriftSecs)) * time.Second,
+	}
+}
+
+// Start starts the periodic NTP checks, if an NTP server is configured
+func (cm *ClockMonitor) Start(ctx context.Context) {
+	if cm.ntpServer == "" || cm.ntpInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(cm.ntpInterval)
+		defer ticker.Stop()
+		for {
+			cm.checkNTP()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// AddBlockSample records the drift against the timestamp of a block just proposed by another node
+func (cm *ClockMonitor) AddBlockSample(timestamp *big.Int, received time.Time) {
+	if timestamp == nil {
+		return
+	}
+	drift := received.Sub(time.Unix(timestamp.Int64(), 0))
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if len(cm.samples) < maxClockSamples {
+		cm.samples = append(cm.samples, drift)
+	} else {
+		cm.samples[cm.next] = drift
+	}
+	cm.next = (cm.next + 1) % maxClockSamples
+	cm.update()
+}
+
+func (cm *ClockMonitor) checkNTP() {
+	offset, err := QueryNTPOffset(cm.ntpServer)
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cm.ntpErr = err
+	if err != nil {
+		cm.logger.WithFields(log.Fields{"server": cm.ntpServer, "error": err}).Debug("Failed to query NTP server")
+		return
+	}
+	cm.ntpDrift = -offset
+	cm.ntpCheckedAt = time.Now()
+	metrics.GetOrRegisterGauge("clock/ntp_drift_ms", nil).Update(cm.ntpDrift.Milliseconds())
+	cm.update()
+}
+
+// update re-evaluates the warning, and logs when it changes. Needs to be called with the lock held.
+func (cm *ClockMonitor) update() {
+	blockDrift := cm.blockDrift()
+	metrics.GetOrRegisterGauge("clock/block_drift_ms", nil).Update(blockDrift.Milliseconds())
+
+	warning := false
+	if len(cm.samples) >= minClockSamples && absDuration(blockDrift) > cm.maxDrift {
+		warning = true
+	}
+	if !cm.ntpCheckedAt.IsZero() && absDuration(cm.ntpDrift) > cm.maxDrift {
+		warning = true
+	}
+
+	if warning && !cm.warning {
+		cm.logger.WithFields(log.Fields{
+			"blockDrift": blockDrift,
+			"ntpDrift":   cm.ntpDrift,
+			"maxDrift":   cm.maxDrift,
+		}).Warn("Local clock is drifting, the proposals and votes of the node might be rejected. Please enable the time synchronization")
+	} else if !warning && cm.warning {
+		cm.logger.WithFields(log.Fields{"blockDrift": blockDrift, "ntpDrift": cm.ntpDrift}).Info("Local clock drift is back within the limit")
+	}
+	cm.warning = warning
+
+	warningGauge := int64(0)
+	if warning {
+		warningGauge = 1
+	}
+	metrics.GetOrRegisterGauge("clock/warning", nil).Update(warningGauge)
+}
+
+// blockDrift returns the median of the block samples, which is robust against the few proposers with drifting
+// clocks themselves. Needs to be called with the lock held.
+func (cm *ClockMonitor) blockDrift() time.Duration {
+	if len(cm.samples) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(cm.samples))
+	copy(sorted, cm.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
+// Status returns the current drift estimation
+func (cm *ClockMonitor) Status() *ClockStatus {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	status := &ClockStatus{
+		BlockDriftMillis: cm.blockDrift().Milliseconds(),
+		NumBlockSamples:  len(cm.samples),
+		NTPServer:        cm.ntpServer,
+		NTPDriftMillis:   cm.ntpDrift.Milliseconds(),
+		MaxDriftMillis:   cm.maxDrift.Milliseconds(),
+		Warning:          cm.warning,
+	}
+	if !cm.ntpCheckedAt.IsZero() {
+		status.NTPCheckedAt = cm.ntpCheckedAt.Unix()
+	}
+	if cm.ntpErr != nil {
+		status.NTPError = cm.ntpErr.Error()
+	}
+	return status
+}
+
+// QueryNTPOffset returns the offset of the NTP server time to the local clock, using the SNTP protocol (RFC 4330)
+func QueryNTPOffset(server string) (time.Duration, error) {
+	conn, err := net.DialTimeout("udp", net.JoinHostPort(server, "123"), ntpTimeout)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(ntpTimeout))
+
+	req := make([]byte, 48)
+	req[0] = 0x1b // LI = 0, VN = 3, Mode = 3 (client)
+	sent := time.Now()
+	if _, err := conn.Write(req); err != nil {
+		return 0, err
+	}
+	resp := make([]byte, 48)
+	if _, err := conn.Read(resp); err != nil {
+		return 0, err
+	}
+	received := time.Now()
+
+	serverReceived := ntpTime(resp[32:40])
+	serverSent := ntpTime(resp[40:48])
+	return (serverReceived.Sub(sent) + serverSent.Sub(received)) / 2, nil
+}
+
+func ntpTime(b []byte) time.Time {
+	secs := int64(binary.BigEndian.Uint32(b[0:4])) - ntpEpochOffset
+	frac := int64(binary.BigEndian.Uint32(b[4:8]))
+	return time.Unix(secs, (frac*1e9)>>32)
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
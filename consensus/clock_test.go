@@ -0,0 +1,46 @@
+package consensus
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockMonitorBlockDrift(t *testing.T) {
+	require := require.New(t)
+	cm := NewClockMonitor(log.NewEntry(log.New()))
+	cm.maxDrift = 5 * time.Second
+
+	// The block timestamps have a resolution of seconds
+	now := time.Unix(time.Now().Unix(), 0)
+	timestamp := big.NewInt(now.Unix())
+
+	// Not enough samples to warn yet
+	for i := 0; i < minClockSamples-1; i++ {
+		cm.AddBlockSample(timestamp, now.Add(10*time.Second))
+	}
+	status := cm.Status()
+	require.Equal(minClockSamples-1, status.NumBlockSamples)
+	require.False(status.Warning)
+
+	cm.AddBlockSample(timestamp, now.Add(10*time.Second))
+	status = cm.Status()
+	require.Equal(int64(10000), status.BlockDriftMillis)
+	require.True(status.Warning)
+
+	// The median ignores the few proposers with drifting clocks
+	for i := 0; i < maxClockSamples; i++ {
+		received := now
+		if i%4 == 0 {
+			received = now.Add(-time.Minute)
+		}
+		cm.AddBlockSample(timestamp, received)
+	}
+	status = cm.Status()
+	require.Equal(maxClockSamples, status.NumBlockSamples)
+	require.Equal(int64(0), status.BlockDriftMillis)
+	require.False(status.Warning)
+}
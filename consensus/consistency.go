@@ -0,0 +1,152 @@
+package consensus
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+)
+
+//
+// ConsistencyReport summarizes the startup consistency check of the chain DB and the state DB
+//
+type ConsistencyReport struct {
+	LastFinalizedHeight uint64   // height of the last finalized block recorded by the consensus state
+	ConsistentHeight    uint64   // height of the last finalized block after the repair
+	RolledBackBlocks    uint64   // number of finalized blocks rolled back
+	ReindexedTxs        int      // number of txs added back to the tx index
+	Issues              []string // inconsistencies found
+}
+
+// IsConsistent returns whether no inconsistency was found
+func (r *ConsistencyReport) IsConsistent() bool {
+	return len(r.Issues) == 0
+}
+
+// checkConsistency verifies the last finalized block, the availability of its state root and the
+// completeness of its tx index. Missing tx index entries are added back. If the block or its state
+// is unavailable, e.g. after an unclean shutdown, the consensus state is rolled back to the last
+// consistent finalized block, and the blocks rolled back are removed from the indices and marked
+// pending to be re-applied. Nothing is rolled back if no consistent finalized block is found within
+// the configured number of blocks, in which case an error is returned.
+func (e *ConsensusEngine) checkConsistency() (*ConsistencyReport, error) {
+	report := &ConsistencyReport{}
+	root := e.chain.Root()
+	summary := e.state.GetSummary()
+
+	block, err := e.chain.FindBlock(summary.LastFinalizedBlock)
+	if err != nil {
+		report.Issues = append(report.Issues, fmt.Sprintf("last finalized block not found: %v", err))
+		block = root
+		if cc, err := e.chain.FindBlock(summary.HighestCCBlock); err == nil {
+			block = e.findLastFinalizedBlockByHeight(cc.Height)
+		}
+	}
+	report.LastFinalizedHeight = block.Height
+
+	maxRollback := uint64(viper.GetInt(common.CfgStorageMaxRollbackBlocks))
+	rolledBack := []*core.ExtendedBlock{}
+	for {
+		issue := e.checkFinalizedBlock(block)
+		if issue == "" {
+			break
+		}
+		report.Issues = append(report.Issues, issue)
+
+		if block.Height <= root.Height || uint64(len(rolledBack)) >= maxRollback {
+			return report, fmt.Errorf("no consistent finalized block found within %v blocks below height %v",
+				len(rolledBack), report.LastFinalizedHeight)
+		}
+		parent, err := e.chain.FindBlock(block.Parent)
+		if err != nil {
+			return report, fmt.Errorf("failed to find parent block %v of block %v: %v", block.Parent.Hex(), block.Hash().Hex(), err)
+		}
+		rolledBack = append(rolledBack, block)
+		block = parent
+	}
+	report.ConsistentHeight = block.Height
+
+	for _, rb := range rolledBack {
+		if err := e.chain.RollbackIndices(rb); err != nil {
+			return report, fmt.Errorf("failed to roll back the indices of block %v: %v", rb.Hash().Hex(), err)
+		}
+		if rb.Status.IsValid() {
+			rb.Status = core.BlockStatusPending
+			e.chain.SaveBlock(rb)
+		}
+		report.RolledBackBlocks++
+	}
+
+	report.ReindexedTxs = e.repairTxIndex(block)
+	if report.ReindexedTxs > 0 {
+		report.Issues = append(report.Issues, fmt.Sprintf("%v txs missing from the tx index", report.ReindexedTxs))
+	}
+
+	if block.Hash() != summary.LastFinalizedBlock {
+		e.state.SetLastFinalizedBlock(block)
+	}
+
+	ccIssue := "highest CC block not found"
+	if cc, err := e.chain.FindBlock(summary.HighestCCBlock); err == nil && cc.Height >= block.Height {
+		ccIssue = e.checkState(cc)
+	}
+	if ccIssue != "" {
+		report.Issues = append(report.Issues, ccIssue)
+		e.state.SetHighestCCBlock(block)
+	}
+
+	return report, nil
+}
+
+// findLastFinalizedBlockByHeight returns the highest finalized block at or below the given height,
+// or the root block if none is found.
+func (e *ConsensusEngine) findLastFinalizedBlockByHeight(height uint64) *core.ExtendedBlock {
+	root := e.chain.Root()
+	for h := height; h > root.Height; h-- {
+		for _, block := range e.chain.FindBlocksByHeight(h) {
+			if block.Status.IsFinalized() {
+				return block
+			}
+		}
+	}
+	return root
+}
+
+// checkFinalizedBlock returns the inconsistency of the given last finalized block, or an empty string
+// if it is consistent.
+func (e *ConsensusEngine) checkFinalizedBlock(block *core.ExtendedBlock) string {
+	// The last finalized block might be a committed block after an auto rewind
+	if !block.Status.IsValid() && block.Hash() != e.chain.Root().Hash() {
+		return fmt.Sprintf("block %v at height %v is not valid, status: %v", block.Hash().Hex(), block.Height, block.Status)
+	}
+	return e.checkState(block)
+}
+
+// checkState returns the inconsistency if the state root of the given block is unavailable, or an empty
+// string otherwise. The state is checked by resetting the ledger state to the block, so the ledger state
+// needs to be reset to the highest CC block after the consistency check.
+func (e *ConsensusEngine) checkState(block *core.ExtendedBlock) string {
+	if result := e.ledger.ResetState(block.Block); result.IsError() {
+		return fmt.Sprintf("state root %v of height %v is unavailable", block.StateHash.Hex(), block.Height)
+	}
+	return ""
+}
+
+// repairTxIndex adds the txs of the given finalized block missing from the tx index back to the index,
+// and returns the number of the missing txs.
+func (e *ConsensusEngine) repairTxIndex(block *core.ExtendedBlock) int {
+	missing := 0
+	for _, rawTx := range block.Txs {
+		_, indexedBlock, found := e.chain.FindTxByHash(crypto.Keccak256Hash(rawTx))
+		if !found || indexedBlock.Hash() != block.Hash() {
+			missing++
+		}
+	}
+	if missing > 0 {
+		e.chain.AddTxsToIndex(block, true)
+	}
+	return missing
+}
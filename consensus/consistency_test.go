@@ -0,0 +1,144 @@
+package consensus
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database/backend"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+// consistencyTestLedger is a ledger whose state is only available for the given blocks
+type consistencyTestLedger struct {
+	importTestLedger
+}
+
+func (l *consistencyTestLedger) FinalizeState(height uint64, rootHash common.Hash) result.Result {
+	return result.OK
+}
+
+var consistencyTestRecipient = common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+// newConsistencyTestEngine creates an engine whose chain has the blocks a1 to a3 finalized and indexed,
+// with a3 being the highest CC block. The state is available for the root block and a1 to a3.
+func newConsistencyTestEngine(t *testing.T) (*ConsensusEngine, *blockchain.Chain, *consistencyTestLedger) {
+	require := require.New(t)
+
+	privKey, _, _ := crypto.GenerateKeyPair()
+	validatorManager := MockValidatorManager{PrivKey: privKey}
+
+	core.ResetTestBlocks()
+	store := kvstore.NewKVStore(backend.NewMemDatabase())
+	root := core.CreateTestBlock("a0", "")
+	chain := blockchain.NewChain("testchain", store, root)
+
+	ledger := &consistencyTestLedger{importTestLedger{states: map[common.Hash]bool{root.Hash(): true}}}
+	ce := NewConsensusEngine(privKey, store, chain, nil, validatorManager)
+	ce.SetLedger(ledger)
+
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	for i, parent := range []string{"a0", "a1", "a2"} {
+		block := core.CreateTestBlock(fmt.Sprintf("a%v", i+1), parent)
+		rawTx, err := types.TxToBytes(&types.SendTx{
+			Fee:     types.NewCoins(0, 1),
+			Inputs:  []types.TxInput{{Address: sender, Coins: types.NewCoins(0, 2)}},
+			Outputs: []types.TxOutput{{Address: consistencyTestRecipient, Coins: types.NewCoins(0, 1)}},
+		})
+		require.Nil(err)
+		block.Txs = []common.Bytes{rawTx}
+		_, err = chain.AddBlock(block)
+		require.Nil(err)
+		eb := chain.MarkBlockValid(block.Hash())
+		require.Nil(ce.persistFinalizedBlock(eb))
+		ce.state.SetHighestCCBlock(eb)
+		ledger.states[block.Hash()] = true
+	}
+	return ce, chain, ledger
+}
+
+func TestCheckConsistency(t *testing.T) {
+	require := require.New(t)
+
+	ce, _, _ := newConsistencyTestEngine(t)
+	report, err := ce.checkConsistency()
+	require.Nil(err)
+	require.True(report.IsConsistent())
+	require.Equal(uint64(3), report.LastFinalizedHeight)
+	require.Equal(uint64(3), report.ConsistentHeight)
+	require.Equal(core.GetTestBlock("a3").Hash(), ce.state.GetLastFinalizedBlock().Hash())
+}
+
+func TestCheckConsistencyRollback(t *testing.T) {
+	require := require.New(t)
+
+	defer viper.Set(common.CfgStorageSupplyIndexEnabled, viper.GetBool(common.CfgStorageSupplyIndexEnabled))
+	viper.Set(common.CfgStorageSupplyIndexEnabled, true)
+
+	ce, chain, ledger := newConsistencyTestEngine(t)
+	a3 := core.GetTestBlock("a3")
+	delete(ledger.states, a3.Hash())
+
+	report, err := ce.checkConsistency()
+	require.Nil(err)
+	require.False(report.IsConsistent())
+	require.Equal(uint64(3), report.LastFinalizedHeight)
+	require.Equal(uint64(2), report.ConsistentHeight)
+	require.Equal(uint64(1), report.RolledBackBlocks)
+
+	// The consensus state points to the consistent block, and the block rolled back gets re-applied
+	a2Hash := core.GetTestBlock("a2").Hash()
+	require.Equal(a2Hash, ce.state.GetLastFinalizedBlock().Hash())
+	require.Equal(a2Hash, ce.state.GetHighestCCBlock().Hash())
+	eb3, err := chain.FindBlock(a3.Hash())
+	require.Nil(err)
+	require.True(eb3.Status.IsPending())
+
+	// The block rolled back is removed from the indices
+	_, found := chain.FindBlockStats(3)
+	require.False(found)
+	_, found = chain.FindBlockStats(2)
+	require.True(found)
+	_, found = chain.FindSupplyDelta(3)
+	require.False(found)
+	entries, err := chain.SearchTxs(&blockchain.TxSearchFilter{StartHeight: 1, EndHeight: 3, To: &consistencyTestRecipient})
+	require.Nil(err)
+	require.Equal(2, len(entries))
+
+	// Re-finalizing the block indexes it again
+	eb3 = chain.MarkBlockValid(a3.Hash())
+	require.Nil(ce.persistFinalizedBlock(eb3))
+	supply, found := chain.FindSupplyDelta(3)
+	require.True(found)
+	require.Equal(int64(3), supply.CumulativeFeesBurned.TFuelWei.Int64())
+}
+
+func TestCheckConsistencyRollbackLimit(t *testing.T) {
+	require := require.New(t)
+
+	defer viper.Set(common.CfgStorageMaxRollbackBlocks, viper.GetInt(common.CfgStorageMaxRollbackBlocks))
+	viper.Set(common.CfgStorageMaxRollbackBlocks, 1)
+
+	ce, chain, ledger := newConsistencyTestEngine(t)
+	a3 := core.GetTestBlock("a3")
+	delete(ledger.states, a3.Hash())
+	delete(ledger.states, core.GetTestBlock("a2").Hash())
+
+	// Nothing is rolled back if no consistent block is found within the limit
+	report, err := ce.checkConsistency()
+	require.NotNil(err)
+	require.Equal(uint64(0), report.RolledBackBlocks)
+	require.Equal(a3.Hash(), ce.state.GetLastFinalizedBlock().Hash())
+	eb3, err := chain.FindBlock(a3.Hash())
+	require.Nil(err)
+	require.True(eb3.Status.IsFinalized())
+	_, found := chain.FindBlockStats(3)
+	require.True(found)
+}
@@ -3,6 +3,7 @@ package consensus
 import (
 	"context"
 	"sync"
+	"time"
 
 	lru "github.com/hashicorp/golang-lru"
 	log "github.com/sirupsen/logrus"
@@ -26,9 +27,11 @@ type EliteEdgeNodeEngine struct {
 	privKey *bls.SecretKey
 
 	voteBookkeeper *EENVoteBookkeeper
+	diagnostics    *EENVoteDiagnostics
 
 	// State for current voting
 	block           common.Hash
+	blockStartTime  time.Time
 	round           uint32
 	currVote        *core.AggregatedEENVotes
 	nextVote        *core.AggregatedEENVotes
@@ -47,6 +50,7 @@ func NewEliteEdgeNodeEngine(c *ConsensusEngine, privateKey *bls.SecretKey) *Elit
 		privKey: privateKey,
 
 		voteBookkeeper: CreateEENVoteBookkeeper(DefaultMaxNumVotesCached),
+		diagnostics:    NewEENVoteDiagnostics(),
 
 		evIncoming:  make(chan *core.EENVote, viper.GetInt(common.CfgConsensusEdgeNodeVoteQueueSize)),
 		aevIncoming: make(chan *core.AggregatedEENVotes, viper.GetInt(common.CfgConsensusEdgeNodeVoteQueueSize)),
@@ -59,6 +63,7 @@ func (e *EliteEdgeNodeEngine) StartNewBlock(block common.Hash) {
 	defer e.mu.Unlock()
 
 	e.block = block
+	e.blockStartTime = time.Now()
 	e.nextVote = nil
 	e.currVote = nil
 	e.round = 1
@@ -73,6 +78,7 @@ func (e *EliteEdgeNodeEngine) StartNewBlock(block common.Hash) {
 	if err != nil {
 		e.logger.Panic(err)
 	}
+	e.diagnostics.Prune(e.blockStartTime)
 
 	e.logger.WithFields(log.Fields{
 		"block": block.Hex(),
@@ -123,6 +129,26 @@ func (e *EliteEdgeNodeEngine) GetBestVote() *core.AggregatedEENVotes {
 	return e.nextVote
 }
 
+// GetVoteDiagnostics returns how the recent votes from the given elite edge node were handled, latest first
+func (e *EliteEdgeNodeEngine) GetVoteDiagnostics(address common.Address, limit int) []*EENVoteDiagnostic {
+	return e.diagnostics.Get(address, limit)
+}
+
+// recordVote records the diagnostic of the vote, an empty reason means the vote is accepted
+func (e *EliteEdgeNodeEngine) recordVote(vote *core.EENVote, receivedAt time.Time, reason string) {
+	diagnostic := &EENVoteDiagnostic{
+		Address:    vote.Address,
+		Block:      vote.Block,
+		ReceivedAt: receivedAt,
+		Accepted:   reason == "",
+		Reason:     reason,
+	}
+	if vote.Block == e.block {
+		diagnostic.Delay = receivedAt.Sub(e.blockStartTime)
+	}
+	e.diagnostics.Record(diagnostic)
+}
+
 func (e *EliteEdgeNodeEngine) Start(ctx context.Context) {
 	go e.mainLoop(ctx)
 }
@@ -150,7 +176,9 @@ func (e *EliteEdgeNodeEngine) processVote(vote *core.EENVote) {
 
 	logger.Debugf("Process edge node vote {%v : %v}", vote.Address, vote.Block.Hex())
 
-	if !e.validateVote(vote) {
+	receivedAt := time.Now()
+	if ok, reason := e.validateVote(vote); !ok {
+		e.recordVote(vote, receivedAt, reason)
 		return
 	}
 
@@ -159,8 +187,10 @@ func (e *EliteEdgeNodeEngine) processVote(vote *core.EENVote) {
 	aggregatedVote, err := e.convertVote(vote)
 	if err != nil {
 		logger.Warnf("Discard vote from edge node %v, reason: %v", vote.Address, err)
+		e.recordVote(vote, receivedAt, EENVoteRejectedConversionError)
 		return
 	}
+	e.recordVote(vote, receivedAt, "")
 
 	logger.Debugf("Converted edge node vote to aggregated vote {%v : %v}", vote.Address, vote.Block.Hex())
 
@@ -250,6 +280,12 @@ func (e *EliteEdgeNodeEngine) HandleVote(vote *core.EENVote) {
 		return
 	default:
 		e.logger.Debug("EliteEdgeNodeEngine queue is full, discarding elite edge node vote: %v", vote)
+		e.diagnostics.Record(&EENVoteDiagnostic{
+			Address:    vote.Address,
+			Block:      vote.Block,
+			ReceivedAt: time.Now(),
+			Reason:     EENVoteRejectedQueueFull,
+		})
 	}
 }
 
@@ -262,7 +298,8 @@ func (e *EliteEdgeNodeEngine) HandleAggregatedVote(vote *core.AggregatedEENVotes
 	}
 }
 
-func (e *EliteEdgeNodeEngine) validateVote(vote *core.EENVote) (res bool) {
+// validateVote checks the vote, and returns the reason if the vote is invalid
+func (e *EliteEdgeNodeEngine) validateVote(vote *core.EENVote) (res bool, reason string) {
 	if e.eenp == nil {
 		// e.logger.WithFields(log.Fields{
 		// 	"local.block":  e.block.Hex(),
@@ -270,6 +307,7 @@ func (e *EliteEdgeNodeEngine) validateVote(vote *core.EENVote) (res bool) {
 		// 	"vote.block":   vote.Block.Hex(),
 		// 	"vote.address": vote.Address,
 		// }).Debug("The elite edge node pool is nil, cannot validate vote")
+		reason = EENVoteRejectedNotReady
 		return
 	}
 
@@ -280,6 +318,7 @@ func (e *EliteEdgeNodeEngine) validateVote(vote *core.EENVote) (res bool) {
 		// 	"vote.block":   vote.Block.Hex(),
 		// 	"vote.address": vote.Address,
 		// }).Debug("Ignoring elite edge node vote: local not ready")
+		reason = EENVoteRejectedNotReady
 		return
 	}
 	if vote.Block != e.block {
@@ -289,6 +328,7 @@ func (e *EliteEdgeNodeEngine) validateVote(vote *core.EENVote) (res bool) {
 		// 	"vote.block":   vote.Block.Hex(),
 		// 	"vote.address": vote.Address,
 		// }).Debug("Ignoring elite edge node vote: block hash does not match with local candidate")
+		reason = EENVoteRejectedBlockMismatch
 		return
 	}
 
@@ -310,6 +350,7 @@ func (e *EliteEdgeNodeEngine) validateVote(vote *core.EENVote) (res bool) {
 		// 	"vote.block":   vote.Block.Hex(),
 		// 	"vote.address": vote.Address,
 		// }).Debug("Ignoring elite edge node vote: not selected by random sampling")
+		reason = EENVoteRejectedNotSelected
 		return
 	}
 
@@ -321,6 +362,8 @@ func (e *EliteEdgeNodeEngine) validateVote(vote *core.EENVote) (res bool) {
 			"vote.block":   vote.Block.Hex(),
 			"vote.address": vote.Address,
 		}).Debug("Ignoring elite edge node vote: failed to get pubkey")
+		reason = EENVoteRejectedUnknownNode
+		return
 	}
 	if result := vote.Validate(pubkeys[0]); result.IsError() {
 		e.logger.WithFields(log.Fields{
@@ -330,6 +373,7 @@ func (e *EliteEdgeNodeEngine) validateVote(vote *core.EENVote) (res bool) {
 			"vote.address": vote.Address,
 			"result":       result.Message,
 		}).Debug("Ignoring elite edge node vote: invalid signature")
+		reason = EENVoteRejectedInvalidSig
 		return
 	}
 
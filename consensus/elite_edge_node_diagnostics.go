@@ -0,0 +1,90 @@
+package consensus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/thetatoken/theta/common"
+)
+
+const (
+	// maxEENVoteDiagnosticsPerNode is the max number of the recent votes kept for each elite edge node
+	maxEENVoteDiagnosticsPerNode = 100
+
+	// eenVoteDiagnosticsLife is how long the diagnostics of an elite edge node are kept after its last vote
+	eenVoteDiagnosticsLife = 24 * time.Hour
+)
+
+// Reasons for which an elite edge node vote is not aggregated
+const (
+	EENVoteRejectedNotReady        = "local node not ready"
+	EENVoteRejectedBlockMismatch   = "vote is not for the checkpoint the local node is voting on"
+	EENVoteRejectedNotSelected     = "elite edge node not selected by random sampling for the checkpoint"
+	EENVoteRejectedUnknownNode     = "elite edge node not found in the pool"
+	EENVoteRejectedInvalidSig      = "invalid signature"
+	EENVoteRejectedQueueFull       = "vote queue full"
+	EENVoteRejectedConversionError = "failed to convert the vote"
+)
+
+// EENVoteDiagnostic records how the local node handled a vote from an elite edge node
+type EENVoteDiagnostic struct {
+	Address    common.Address `json:"address"`
+	Block      common.Hash    `json:"block"`       // the checkpoint block voted on
+	ReceivedAt time.Time      `json:"received_at"` // when the vote reached the local node
+	Delay      time.Duration  `json:"delay"`       // from the local finalization of the checkpoint to the vote arrival
+	Accepted   bool           `json:"accepted"`    // whether the vote was accepted for aggregation
+	Reason     string         `json:"reason"`      // why the vote was rejected
+}
+
+// EENVoteDiagnostics keeps the recent vote diagnostics of each elite edge node
+type EENVoteDiagnostics struct {
+	mu      *sync.Mutex
+	records map[common.Address][]*EENVoteDiagnostic
+}
+
+func NewEENVoteDiagnostics() *EENVoteDiagnostics {
+	return &EENVoteDiagnostics{
+		mu:      &sync.Mutex{},
+		records: make(map[common.Address][]*EENVoteDiagnostic),
+	}
+}
+
+// Record adds the diagnostic of a vote
+func (d *EENVoteDiagnostics) Record(diagnostic *EENVoteDiagnostic) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	records := append(d.records[diagnostic.Address], diagnostic)
+	if len(records) > maxEENVoteDiagnosticsPerNode {
+		records = records[len(records)-maxEENVoteDiagnosticsPerNode:]
+	}
+	d.records[diagnostic.Address] = records
+}
+
+// Get returns the latest diagnostics of the given elite edge node, latest first
+func (d *EENVoteDiagnostics) Get(address common.Address, limit int) []*EENVoteDiagnostic {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	records := d.records[address]
+	if limit <= 0 || limit > len(records) {
+		limit = len(records)
+	}
+	ret := make([]*EENVoteDiagnostic, 0, limit)
+	for i := len(records) - 1; i >= len(records)-limit; i-- {
+		ret = append(ret, records[i])
+	}
+	return ret
+}
+
+// Prune removes the diagnostics of the elite edge nodes which have not voted for a while
+func (d *EENVoteDiagnostics) Prune(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for address, records := range d.records {
+		if now.Sub(records[len(records)-1].ReceivedAt) > eenVoteDiagnosticsLife {
+			delete(d.records, address)
+		}
+	}
+}
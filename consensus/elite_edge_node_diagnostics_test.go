@@ -0,0 +1,49 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestEENVoteDiagnostics(t *testing.T) {
+	assert := assert.New(t)
+
+	een1 := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	een2 := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	now := time.Now()
+	d := NewEENVoteDiagnostics()
+	for i := 0; i < maxEENVoteDiagnosticsPerNode+10; i++ {
+		d.Record(&EENVoteDiagnostic{
+			Address:    een1,
+			Block:      common.BytesToHash([]byte{byte(i)}),
+			ReceivedAt: now,
+			Accepted:   true,
+		})
+	}
+	d.Record(&EENVoteDiagnostic{
+		Address:    een2,
+		ReceivedAt: now.Add(-2 * eenVoteDiagnosticsLife),
+		Reason:     EENVoteRejectedNotSelected,
+	})
+
+	// Only the latest votes are kept, latest first
+	records := d.Get(een1, 0)
+	assert.Equal(maxEENVoteDiagnosticsPerNode, len(records))
+	assert.Equal(common.BytesToHash([]byte{byte(maxEENVoteDiagnosticsPerNode + 9)}), records[0].Block)
+	records = d.Get(een1, 3)
+	assert.Equal(3, len(records))
+	assert.Equal(common.BytesToHash([]byte{byte(maxEENVoteDiagnosticsPerNode + 7)}), records[2].Block)
+
+	records = d.Get(een2, 10)
+	assert.Equal(1, len(records))
+	assert.False(records[0].Accepted)
+
+	// The nodes which have not voted for a while are pruned
+	d.Prune(now)
+	assert.Equal(0, len(d.Get(een2, 0)))
+	assert.Equal(maxEENVoteDiagnosticsPerNode, len(d.Get(een1, 0)))
+}
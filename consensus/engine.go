@@ -16,12 +16,14 @@ import (
 	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/common/timer"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/dispatcher"
 	"github.com/thetatoken/theta/rlp"
 	"github.com/thetatoken/theta/store"
+	"github.com/thetatoken/theta/upgrade"
 )
 
 var logger = log.WithFields(log.Fields{"prefix": "consensus"})
@@ -40,10 +42,21 @@ type ConsensusEngine struct {
 	ledger           core.Ledger
 	guardian         *GuardianEngine
 	eliteEdgeNode    *EliteEdgeNodeEngine
+	clock            *ClockMonitor
+	faults           *faultInjector
+	activity         *ValidatorActivity
 
-	incoming        chan interface{}
-	finalizedBlocks chan *core.Block
-	hasSynced       bool
+	incoming                chan interface{}
+	finalizedBlocks         chan *core.Block
+	finalizedBlockListeners []func(block *core.ExtendedBlock)
+	hasSynced               bool
+
+	timeSource timer.Clock
+
+	// Stepping mode, where the caller drives the engine with Step() instead of the main loop
+	stepping     bool
+	selfMessages []interface{}
+	inEpoch      bool
 
 	// Life cycle
 	wg      *sync.WaitGroup
@@ -52,9 +65,9 @@ type ConsensusEngine struct {
 	stopped bool
 
 	mu            *sync.Mutex
-	epochTimer    *time.Timer
-	proposalTimer *time.Timer
-	guardianTimer *time.Ticker
+	epochTimer    timer.Timer
+	proposalTimer timer.Timer
+	guardianTimer timer.Ticker
 
 	state *State
 }
@@ -70,6 +83,8 @@ func NewConsensusEngine(privateKey *crypto.PrivateKey, db store.Store, chain *bl
 		incoming:        make(chan interface{}, viper.GetInt(common.CfgConsensusMessageQueueSize)),
 		finalizedBlocks: make(chan *core.Block, viper.GetInt(common.CfgConsensusMessageQueueSize)),
 
+		timeSource: timer.RealClock(),
+
 		wg: &sync.WaitGroup{},
 
 		mu:    &sync.Mutex{},
@@ -87,6 +102,12 @@ func NewConsensusEngine(privateKey *crypto.PrivateKey, db store.Store, chain *bl
 	}
 	e.guardian = NewGuardianEngine(e, blsKey)
 	e.eliteEdgeNode = NewEliteEdgeNodeEngine(e, blsKey)
+	e.clock = NewClockMonitor(logger)
+	e.activity = NewValidatorActivity()
+	e.faults = newFaultInjector()
+	if e.faults != nil {
+		e.logger.WithFields(log.Fields{"faults": e.faults.config}).Warn("Fault injection enabled, the node misbehaves on purpose")
+	}
 
 	e.logger.WithFields(log.Fields{"state": e.state}).Info("Starting state")
 
@@ -102,6 +123,18 @@ func (e *ConsensusEngine) GetLedger() core.Ledger {
 	return e.ledger
 }
 
+// AddFinalizedBlockListener registers a function called with each block finalized by the engine, in order. It is
+// called on the consensus goroutine, so it must not block. Must be called before the engine starts.
+func (e *ConsensusEngine) AddFinalizedBlockListener(listener func(block *core.ExtendedBlock)) {
+	e.finalizedBlockListeners = append(e.finalizedBlockListeners, listener)
+}
+
+// SetClock replaces the source of the time and the timers of the engine, so that the tests can drive
+// the engine with a simulated time. Must be called before the engine starts.
+func (e *ConsensusEngine) SetClock(clock timer.Clock) {
+	e.timeSource = clock
+}
+
 // ID returns the identifier of current node.
 func (e *ConsensusEngine) ID() string {
 	return e.privateKey.PublicKey().Address().Hex()
@@ -122,6 +155,11 @@ func (e *ConsensusEngine) GetEpoch() uint64 {
 	return e.state.GetEpoch()
 }
 
+// ClockMonitor returns the monitor of the local clock drift
+func (e *ConsensusEngine) ClockMonitor() *ClockMonitor {
+	return e.clock
+}
+
 // GetValidatorManager returns a pointer to the valiator manager.
 func (e *ConsensusEngine) GetValidatorManager() core.ValidatorManager {
 	return e.validatorManager
@@ -129,6 +167,20 @@ func (e *ConsensusEngine) GetValidatorManager() core.ValidatorManager {
 
 // Start starts sub components and kick off the main loop.
 func (e *ConsensusEngine) Start(ctx context.Context) {
+	e.initialize(ctx)
+
+	e.wg.Add(1)
+	go e.mainLoop()
+}
+
+// StartStepping starts the sub components like Start, but without the main loop. The caller drives the
+// engine with Step from its own goroutine instead, which makes the order of the events deterministic.
+func (e *ConsensusEngine) StartStepping(ctx context.Context) {
+	e.stepping = true
+	e.initialize(ctx)
+}
+
+func (e *ConsensusEngine) initialize(ctx context.Context) {
 	c, cancel := context.WithCancel(ctx)
 	e.ctx = c
 	e.cancel = cancel
@@ -141,6 +193,27 @@ func (e *ConsensusEngine) Start(ctx context.Context) {
 		}).Fatal("Invalid configuration: max epoch length must be larger than minimal proposal wait")
 	}
 
+	// Recover the block import interrupted by a crash, if any, before loading the ledger state.
+	e.recoverImport()
+
+	// Verify the chain DB and the state DB are consistent, and repair them if not. If they cannot be
+	// repaired, the node still starts from the recorded consensus state, so that it can be inspected.
+	if report, err := e.checkConsistency(); err != nil {
+		e.logger.WithFields(log.Fields{
+			"error":               err,
+			"lastFinalizedHeight": report.LastFinalizedHeight,
+			"issues":              report.Issues,
+		}).Error("Failed to repair inconsistent database on startup, please resync from a snapshot")
+	} else if !report.IsConsistent() {
+		e.logger.WithFields(log.Fields{
+			"lastFinalizedHeight": report.LastFinalizedHeight,
+			"consistentHeight":    report.ConsistentHeight,
+			"rolledBackBlocks":    report.RolledBackBlocks,
+			"reindexedTxs":        report.ReindexedTxs,
+			"issues":              report.Issues,
+		}).Warn("Repaired inconsistent database on startup")
+	}
+
 	// Set ledger state pointer to initial state.
 	lastCC := e.autoRewind(e.state.GetHighestCCBlock())
 	//e.ledger.ResetState(lastCC.Height, lastCC.StateHash)
@@ -149,11 +222,9 @@ func (e *ConsensusEngine) Start(ctx context.Context) {
 	e.resetGuardianTimer()
 	e.guardian.Start(e.ctx)
 	e.eliteEdgeNode.Start(e.ctx)
+	e.clock.Start(e.ctx)
 
 	e.checkSyncStatus()
-
-	e.wg.Add(1)
-	go e.mainLoop()
 }
 
 func (e *ConsensusEngine) autoRewind(lastCC *core.ExtendedBlock) *core.ExtendedBlock {
@@ -266,31 +337,98 @@ func (e *ConsensusEngine) mainLoop() {
 				if endEpoch {
 					break Epoch
 				}
-			case <-e.epochTimer.C:
-				e.logger.WithFields(log.Fields{"e.epoch": e.GetEpoch()}).Debug("Epoch timeout. Repeating epoch")
-				e.vote()
+			case <-e.epochTimer.C():
+				e.handleEpochTimeout()
 				break Epoch
-			case <-e.proposalTimer.C:
+			case <-e.proposalTimer.C():
 				e.propose()
-			case <-e.guardianTimer.C:
-				v := e.guardian.GetVoteToBroadcast()
+			case <-e.guardianTimer.C():
+				e.handleGuardianRound()
+			}
+		}
+	}
+}
 
-				if v != nil {
-					e.guardian.logger.WithFields(log.Fields{"vote": v}).Debug("Broadcasting guardian vote")
-					e.broadcastGuardianVote(v)
-				}
-				e.guardian.StartNewRound()
+// Step handles at most one pending event in the stepping mode, and returns false if there was none. The
+// events are picked in a fixed order: the messages the engine sent to itself, the incoming messages, then
+// the epoch, proposal and guardian timers.
+func (e *ConsensusEngine) Step() bool {
+	if !e.stepping {
+		e.logger.Panic("Step called on a consensus engine not started in the stepping mode")
+	}
+	if !e.inEpoch {
+		e.enterEpoch()
+		e.inEpoch = true
+	}
+
+	if len(e.selfMessages) > 0 {
+		msg := e.selfMessages[0]
+		e.selfMessages = e.selfMessages[1:]
+		e.inEpoch = !e.processMessage(msg)
+		return true
+	}
+	select {
+	case msg := <-e.incoming:
+		e.inEpoch = !e.processMessage(msg)
+		return true
+	default:
+	}
+	select {
+	case <-e.epochTimer.C():
+		e.handleEpochTimeout()
+		e.inEpoch = false
+		return true
+	default:
+	}
+	select {
+	case <-e.proposalTimer.C():
+		e.propose()
+		return true
+	default:
+	}
+	select {
+	case <-e.guardianTimer.C():
+		e.handleGuardianRound()
+		return true
+	default:
+	}
+	return false
+}
 
-				eenv := e.eliteEdgeNode.GetVoteToBroadcast()
+func (e *ConsensusEngine) handleEpochTimeout() {
+	e.logger.WithFields(log.Fields{"e.epoch": e.GetEpoch()}).Debug("Epoch timeout. Repeating epoch")
+	e.activity.RecordTimeout(e.GetEpoch(), e.timeSource.Now())
+	e.vote()
+}
 
-				if eenv != nil {
-					e.eliteEdgeNode.logger.WithFields(log.Fields{"vote": eenv}).Debug("Broadcasting aggregated elite edge node vote")
-					e.broadcastAggregatedEliteEdgeNodeVotes(eenv)
-				}
-				e.eliteEdgeNode.StartNewRound()
-			}
-		}
+func (e *ConsensusEngine) handleGuardianRound() {
+	v := e.guardian.GetVoteToBroadcast()
+
+	if v != nil {
+		e.guardian.logger.WithFields(log.Fields{"vote": v}).Debug("Broadcasting guardian vote")
+		e.broadcastGuardianVote(v)
+	}
+	e.guardian.StartNewRound()
+
+	eenv := e.eliteEdgeNode.GetVoteToBroadcast()
+
+	if eenv != nil {
+		e.eliteEdgeNode.logger.WithFields(log.Fields{"vote": eenv}).Debug("Broadcasting aggregated elite edge node vote")
+		e.broadcastAggregatedEliteEdgeNodeVotes(eenv)
+	}
+	e.eliteEdgeNode.StartNewRound()
+}
+
+// addSelfMessage queues a message the engine sent to itself. The main loop is the consumer of the incoming
+// queue, so the message is added from another goroutine to avoid blocking on a full queue.
+func (e *ConsensusEngine) addSelfMessage(msg interface{}) {
+	if e.stepping {
+		e.selfMessages = append(e.selfMessages, msg)
+		return
 	}
+	go func() {
+		e.AddMessage(msg)
+	}()
 }
 
 // enterEpoch is called when engine enters a new epoch.
@@ -299,12 +437,12 @@ func (e *ConsensusEngine) enterEpoch() {
 	if e.epochTimer != nil {
 		e.epochTimer.Stop()
 	}
-	e.epochTimer = time.NewTimer(time.Duration(viper.GetInt(common.CfgConsensusMaxEpochLength)) * time.Second)
+	e.epochTimer = e.timeSource.NewTimer(time.Duration(viper.GetInt(common.CfgConsensusMaxEpochLength)) * time.Second)
 
 	if e.proposalTimer != nil {
 		e.proposalTimer.Stop()
 	}
-	e.proposalTimer = time.NewTimer(time.Duration(viper.GetInt(common.CfgConsensusMinProposalWait)) * time.Second)
+	e.proposalTimer = e.timeSource.NewTimer(time.Duration(viper.GetInt(common.CfgConsensusMinProposalWait)) * time.Second)
 }
 
 // GetChannelIDs implements the p2p.MessageHandler interface.
@@ -332,6 +470,10 @@ func (e *ConsensusEngine) processMessage(msg interface{}) (endEpoch bool) {
 		e.logger.WithFields(log.Fields{
 			"block": m.BlockHeader,
 		}).Debug("Received block")
+		if e.hasSynced && m.Epoch+1 >= e.GetEpoch() && m.Proposer != e.privateKey.PublicKey().Address() {
+			// A block of the current epoch is fresh from its proposer
+			e.clock.AddBlockSample(m.Timestamp, e.timeSource.Now())
+		}
 		e.handleBlock(m)
 	case *core.AggregatedVotes:
 		// e.logger.WithFields(log.Fields{"guardian vote": m}).Debug("Received guardian vote")
@@ -366,7 +508,7 @@ func (e *ConsensusEngine) checkSyncStatus() error {
 	// current finalized height is at most maxVoteHeight-1
 	currentHeight := uint64(maxVoteHeight - 1)
 
-	e.hasSynced = !isSyncing(e.GetLastFinalizedBlock(), currentHeight)
+	e.hasSynced = !isSyncing(e.GetLastFinalizedBlock(), currentHeight, e.timeSource.Now())
 
 	return nil
 }
@@ -493,7 +635,7 @@ func (e *ConsensusEngine) validateBlock(block *core.Block, parent *core.Extended
 
 	// Validate Guardian Votes.
 	// We allow checkpoint blocs to have nil guardian votes.
-	if block.GuardianVotes != nil && block.Height >= common.HeightEnableTheta2 && common.IsCheckPointHeight(block.Height) {
+	if block.GuardianVotes != nil && core.IsForkActive(e.chain.ChainID, core.ForkTheta2, block.Height) && common.IsCheckPointHeight(block.Height) {
 		// Voted block must exist.
 		padding := uint64(20)
 		if e.chain.Root().Height+padding*uint64(common.CheckpointInterval) < block.Height {
@@ -564,7 +706,7 @@ func (e *ConsensusEngine) validateBlock(block *core.Block, parent *core.Extended
 
 	// Validate Elite Edge Node Votes.
 	// We allow checkpoint blocks to have nil elite edge node votes.
-	if block.EliteEdgeNodeVotes != nil && block.Height >= common.HeightEnableTheta3 && common.IsCheckPointHeight(block.Height) {
+	if block.EliteEdgeNodeVotes != nil && core.IsForkActive(e.chain.ChainID, core.ForkTheta3, block.Height) && common.IsCheckPointHeight(block.Height) {
 		// Voted block must exist.
 		padding := uint64(20)
 		if e.chain.Root().Height+padding*uint64(common.CheckpointInterval) < block.Height {
@@ -668,6 +810,11 @@ func (e *ConsensusEngine) handleHardcodeBlock(hash common.Hash) {
 		}).Fatal("Failed to find parent block")
 	}
 
+	if err := e.state.BeginImport(block.Hash(), ImportStageApply); err != nil {
+		e.logger.WithFields(log.Fields{"error": err}).Panic("Failed to write the import journal")
+	}
+	defer e.state.EndImport()
+
 	//result := e.ledger.ResetState(parent.Height, parent.StateHash)
 	result := e.ledger.ResetState(parent.Block)
 	if result.IsError() {
@@ -706,6 +853,15 @@ func (e *ConsensusEngine) handleNormalBlock(eb *core.ExtendedBlock) {
 		}).Debug("Ignore processed block")
 		return
 	}
+	if upgrade.ShouldHalt(block.Height) {
+		// Leave the block pending, it is applied once the node restarts with the upgraded binary
+		e.logger.WithFields(log.Fields{
+			"upgrade": upgrade.GetPendingPlan(),
+			"block":   block.Hash().Hex(),
+			"height":  block.Height,
+		}).Error("Halted at the upgrade height, please restart with the new binary")
+		return
+	}
 	parent, err := e.chain.FindBlock(block.Parent)
 	if err != nil {
 		// Should not happen since netsync layer ensures order of blocks.
@@ -737,6 +893,11 @@ func (e *ConsensusEngine) handleNormalBlock(eb *core.ExtendedBlock) {
 		e.checkCC(block.HCC.BlockHash)
 	}
 
+	// The journal is ended once the block is marked valid, before checkCC might start finalizing blocks.
+	if err := e.state.BeginImport(block.Hash(), ImportStageApply); err != nil {
+		e.logger.WithFields(log.Fields{"error": err}).Panic("Failed to write the import journal")
+	}
+
 	//result := e.ledger.ResetState(parent.Height, parent.StateHash)
 	result := e.ledger.ResetState(parent.Block)
 	if result.IsError() {
@@ -745,6 +906,7 @@ func (e *ConsensusEngine) handleNormalBlock(eb *core.ExtendedBlock) {
 			"parent.StateHash": parent.StateHash,
 		}).Error("Failed to reset state to parent.StateHash")
 		e.chain.MarkBlockInvalid(block.Hash())
+		e.state.EndImport()
 		return
 	}
 
@@ -758,6 +920,7 @@ func (e *ConsensusEngine) handleNormalBlock(eb *core.ExtendedBlock) {
 			"block.StateHash": block.StateHash.Hex(),
 		}).Error("Failed to apply block Txs")
 		e.chain.MarkBlockInvalid(block.Hash())
+		e.state.EndImport()
 		return
 	}
 	applyBlockTime := time.Since(start1)
@@ -773,7 +936,12 @@ func (e *ConsensusEngine) handleNormalBlock(eb *core.ExtendedBlock) {
 		}
 	}
 
-	e.chain.MarkBlockValid(block.Hash())
+	// The block is marked valid and the journal is ended in one batch
+	batch := e.state.NewBatch()
+	e.state.EndImportInBatch(batch)
+	if _, err := e.chain.MarkBlockValidInBatch(block.Hash(), batch); err != nil {
+		e.logger.WithFields(log.Fields{"error": err}).Panic("Failed to mark the block valid")
+	}
 
 	// Skip voting for block older than current best known epoch.
 	// Allow block with one epoch behind since votes are processed first and might advance epoch
@@ -802,6 +970,10 @@ func (e *ConsensusEngine) handleNormalBlock(eb *core.ExtendedBlock) {
 }
 
 func (e *ConsensusEngine) shouldVote(block common.Hash) bool {
+	// A node in the shadow mode only follows the blocks finalized by the validators
+	if viper.GetBool(common.CfgShadowEnabled) {
+		return false
+	}
 	return e.shouldVoteByID(e.privateKey.PublicKey().Address(), block)
 }
 
@@ -855,11 +1027,17 @@ func (e *ConsensusEngine) vote() {
 	e.logger.WithFields(log.Fields{
 		"vote": vote,
 	}).Debug("Sending vote")
-	e.broadcastVote(vote)
+	if delay := e.faults.voteDelay(); delay > 0 {
+		e.logger.WithFields(log.Fields{"vote": vote, "delay": delay}).Warn("Fault injection: delaying vote")
+		time.AfterFunc(delay, func() {
+			e.broadcastVote(vote)
+		})
+	} else {
+		e.broadcastVote(vote)
+	}
+	e.activity.RecordVote(vote.Epoch, vote.Block, e.timeSource.Now())
 
-	go func() {
-		e.AddMessage(vote)
-	}()
+	e.addSelfMessage(vote)
 }
 
 func (e *ConsensusEngine) broadcastVote(vote core.Vote) {
@@ -868,6 +1046,10 @@ func (e *ConsensusEngine) broadcastVote(vote core.Vote) {
 		e.logger.WithFields(log.Fields{"vote": vote}).Error("Failed to encode vote")
 		return
 	}
+	if corrupted := e.faults.malform(payload); corrupted != nil {
+		e.logger.WithFields(log.Fields{"vote": vote}).Warn("Fault injection: sending malformed vote")
+		payload = corrupted
+	}
 	voteMsg := dispatcher.DataResponse{
 		ChannelID: common.ChannelIDVote,
 		Payload:   payload,
@@ -1079,6 +1261,16 @@ func (e *ConsensusEngine) GetSummary() *StateStub {
 	return e.state.GetSummary()
 }
 
+// GetEliteEdgeNodeVoteDiagnostics returns how the recent votes from the given elite edge node were handled.
+func (e *ConsensusEngine) GetEliteEdgeNodeVoteDiagnostics(address common.Address, limit int) []*EENVoteDiagnostic {
+	return e.eliteEdgeNode.GetVoteDiagnostics(address, limit)
+}
+
+// GetValidatorActivity returns the activity of the local validator in the recent epochs.
+func (e *ConsensusEngine) GetValidatorActivity() *ValidatorActivity {
+	return e.activity
+}
+
 // FinalizedBlocks returns a channel that will be published with finalized blocks by the engine.
 func (e *ConsensusEngine) FinalizedBlocks() chan *core.Block {
 	return e.finalizedBlocks
@@ -1125,19 +1317,14 @@ func (e *ConsensusEngine) finalizeBlock(block *core.ExtendedBlock) error {
 
 	e.logger.WithFields(log.Fields{"block.Hash": block.Hash().Hex(), "block.Height": block.Height}).Info("Finalizing block")
 
-	e.state.SetLastFinalizedBlock(block)
-	e.ledger.FinalizeState(block.Height, block.StateHash)
-
-	e.checkSyncStatus()
-
-	// Mark block and its ancestors as finalized.
-	if err := e.chain.FinalizePreviousBlocks(block.Hash()); err != nil {
+	if err := e.state.BeginImport(block.Hash(), ImportStageFinalize); err != nil {
+		e.logger.WithFields(log.Fields{"error": err}).Panic("Failed to write the import journal")
+	}
+	if err := e.persistFinalizedBlock(block); err != nil {
 		return err
 	}
 
-	// Force update TX index on block finalization so that the index doesn't point to
-	// duplicate TX in fork.
-	e.chain.AddTxsToIndex(block, true)
+	e.checkSyncStatus()
 
 	// Guardians and Elite Edge Nodes to vote for checkpoint blocks.
 	if common.IsCheckPointHeight(block.Height) {
@@ -1152,13 +1339,150 @@ func (e *ConsensusEngine) finalizeBlock(block *core.ExtendedBlock) error {
 	default:
 		e.logger.Warnf("Failed to notify finalized block, height=%v", block.Height)
 	}
+	for _, listener := range e.finalizedBlockListeners {
+		listener(block)
+	}
+	return nil
+}
+
+// persistFinalizedBlock writes the finalization of the block to the ledger, the indices, the block
+// statuses and the consensus state, and ends the import journal. The block statuses, the consensus state
+// and the end of the journal are written in one batch, after the indices. All the writes are idempotent,
+// so they can be replayed to recover an interrupted finalization.
+func (e *ConsensusEngine) persistFinalizedBlock(block *core.ExtendedBlock) error {
+	// The ancestors not finalized yet get indirectly finalized along with the block, so they are
+	// indexed as well.
+	finalized, err := e.chain.FindBlocksToFinalize(block.Hash())
+	if err != nil {
+		return err
+	}
+
+	e.ledger.FinalizeState(block.Height, block.StateHash)
+
+	finalizedAt := time.Now()
+	for _, b := range finalized {
+		// Force update TX index on block finalization so that the index doesn't point to
+		// duplicate TX in fork.
+		e.chain.AddTxsToIndex(b, true)
+
+		// The receipts of the txs are read once for all the indices below.
+		receipts := e.chain.FindBlockTxReceipts(b)
+
+		// Record block production statistics for the chain stats index.
+		e.chain.AddBlockStats(b, receipts, finalizedAt)
+
+		// Index the finalized transactions for transaction search.
+		e.chain.AddTxsToSearchIndex(b)
+
+		// Record the coins minted and the fees burned for the supply index. The index is optional, so a failure
+		// does not stop the finalization.
+		if viper.GetBool(common.CfgStorageSupplyIndexEnabled) {
+			if err := e.chain.AddSupplyDelta(b, receipts); err != nil {
+				e.logger.WithFields(log.Fields{"error": err, "block": b.Hash().Hex()}).Warn("Failed to index the supply delta")
+			}
+		}
+
+		// Record the bloom of the receipt logs so that the logs queries can skip the block.
+		e.chain.AddLogsBloom(b, receipts)
+
+		// Record when the txs and the block were first seen for the inclusion latency analysis. The index is
+		// optional, so a failure does not stop the finalization.
+		if viper.GetBool(common.CfgStorageTxTimingIndexEnabled) {
+			if err := e.chain.AddTxTimings(b, finalizedAt); err != nil {
+				e.logger.WithFields(log.Fields{"error": err, "block": b.Hash().Hex()}).Warn("Failed to index the tx timings")
+			}
+		}
+
+		// Index the TNT-721 transfers for the NFT ownership queries. The index is optional, so a failure
+		// does not stop the finalization.
+		if viper.GetBool(common.CfgStorageNFTIndexEnabled) {
+			if err := e.chain.AddNFTTransfers(b, receipts); err != nil {
+				e.logger.WithFields(log.Fields{"error": err, "block": b.Hash().Hex()}).Warn("Failed to index the NFT transfers")
+			}
+		}
+
+		// Record the calls of the contracts for the per-contract gas and call stats. The index is optional, so
+		// a failure does not stop the finalization.
+		if viper.GetBool(common.CfgStorageContractStatsIndexEnabled) {
+			if err := e.chain.AddContractStats(b, receipts); err != nil {
+				e.logger.WithFields(log.Fields{"error": err, "block": b.Hash().Hex()}).Warn("Failed to index the contract stats")
+			}
+		}
+
+		// Record the balances of the accounts involved in the txs for the balance history.
+		if reader, ok := e.ledger.(blockchain.BalanceReader); ok {
+			if err := e.chain.AddBlockBalanceCheckpoints(b, reader); err != nil {
+				e.logger.WithFields(log.Fields{"error": err, "block": b.Hash().Hex()}).Warn("Failed to index the balance checkpoints")
+			}
+		}
+
+		// Record the penalties applied by the slash txs for the slash history.
+		if err := e.chain.AddSlashRecords(b, receipts); err != nil {
+			e.logger.WithFields(log.Fields{"error": err, "block": b.Hash().Hex()}).Warn("Failed to index the slash records")
+		}
+	}
+
+	// Mark block and its ancestors as finalized, along with the consensus state and the end of the journal.
+	batch := e.state.NewBatch()
+	e.state.SetLastFinalizedBlockInBatch(block, batch)
+	e.state.EndImportInBatch(batch)
+	if err := e.chain.FinalizePreviousBlocksInBatch(block.Hash(), batch); err != nil {
+		return err
+	}
+
+	e.pruneIndices(block.Height)
+	e.freezeBlocks(block.Height)
+
 	return nil
 }
 
+// recoverImport recovers the block import interrupted by a crash, so that the chain DB and the state
+// DB reference the same height. An interrupted finalization is replayed. A block whose state failed
+// to persist while being applied is marked pending again, so it gets re-applied when synced.
+func (e *ConsensusEngine) recoverImport() {
+	journal, ok := e.state.GetImportJournal()
+	if !ok {
+		return
+	}
+
+	block, err := e.chain.FindBlock(journal.BlockHash)
+	if err != nil {
+		e.logger.WithFields(log.Fields{
+			"error": err,
+			"block": journal.BlockHash.Hex(),
+		}).Warn("Failed to find the block of the interrupted import")
+		e.state.EndImport()
+		return
+	}
+
+	e.logger.WithFields(log.Fields{
+		"block.Hash":   block.Hash().Hex(),
+		"block.Height": block.Height,
+		"stage":        journal.Stage,
+	}).Warn("Recovering interrupted block import")
+
+	switch journal.Stage {
+	case ImportStageApply:
+		if block.Status.IsValid() && !block.Status.IsFinalized() && e.ledger.ResetState(block.Block).IsError() {
+			block.Status = core.BlockStatusPending
+			e.chain.SaveBlock(block)
+		}
+	case ImportStageFinalize:
+		if err := e.persistFinalizedBlock(block); err != nil {
+			e.logger.WithFields(log.Fields{"error": err}).Fatal("Failed to replay the block finalization")
+		}
+	}
+
+	e.state.EndImport()
+}
+
 func (e *ConsensusEngine) shouldPropose(tip *core.ExtendedBlock, epoch uint64) bool {
 	if epoch <= tip.Epoch {
 		return false
 	}
+	if viper.GetBool(common.CfgShadowEnabled) {
+		return false
+	}
 	if !e.shouldProposeByID(tip.Hash(), epoch, e.ID()) {
 		return false
 	}
@@ -1211,18 +1535,18 @@ func (e *ConsensusEngine) createProposal() (core.Proposal, error) {
 	block.Parent = tip.Hash()
 	block.Height = tip.Height + 1
 	block.Proposer = e.privateKey.PublicKey().Address()
-	block.Timestamp = big.NewInt(time.Now().Unix())
+	block.Timestamp = big.NewInt(e.timeSource.Now().Unix())
 	block.HCC.BlockHash = e.state.GetHighestCCBlock().Hash()
 	hccValidators := e.validatorManager.GetValidatorSet(block.HCC.BlockHash)
 	block.HCC.Votes = e.chain.FindVotesByHash(block.HCC.BlockHash).UniqueVoter().FilterByValidators(hccValidators)
 
 	// Add guardian votes.
-	if block.Height >= common.HeightEnableTheta2 && common.IsCheckPointHeight(block.Height) {
+	if core.IsForkActive(e.chain.ChainID, core.ForkTheta2, block.Height) && common.IsCheckPointHeight(block.Height) {
 		block.GuardianVotes = e.guardian.GetBestVote()
 	}
 
 	// Add elite edge node votes.
-	if block.Height >= common.HeightEnableTheta3 && common.IsCheckPointHeight(block.Height) {
+	if core.IsForkActive(e.chain.ChainID, core.ForkTheta3, block.Height) && common.IsCheckPointHeight(block.Height) {
 		block.EliteEdgeNodeVotes = e.eliteEdgeNode.GetBestVote()
 	}
 
@@ -1268,6 +1592,9 @@ func (e *ConsensusEngine) propose() {
 	if !e.shouldPropose(tip, e.GetEpoch()) {
 		return
 	}
+	if upgrade.ShouldHalt(tip.Height + 1) {
+		return
+	}
 
 	var proposal core.Proposal
 	var err error
@@ -1291,20 +1618,59 @@ func (e *ConsensusEngine) propose() {
 		e.logger.WithFields(log.Fields{"proposal": proposal}).Info("Making proposal")
 	}
 
+	e.activity.RecordProposal(proposal.Block.Epoch, proposal.Block.Hash(), e.timeSource.Now())
+
+	if e.faults.shouldWithholdBlock() {
+		e.logger.WithFields(log.Fields{"block": proposal.Block.Hash().Hex()}).Warn("Fault injection: withholding proposal")
+	} else if e.faults.shouldEquivocate() {
+		e.sendEquivocatingProposals(proposal)
+	} else {
+		e.sendProposal([]string{}, proposal)
+	}
+
+	e.addSelfMessage(proposal.Block)
+}
+
+func (e *ConsensusEngine) sendProposal(peerIDs []string, proposal core.Proposal) {
 	payload, err := rlp.EncodeToBytes(proposal)
 	if err != nil {
 		e.logger.WithFields(log.Fields{"proposal": proposal}).Error("Failed to encode proposal")
 		return
 	}
+	if corrupted := e.faults.malform(payload); corrupted != nil {
+		e.logger.WithFields(log.Fields{"proposal": proposal}).Warn("Fault injection: sending malformed proposal")
+		payload = corrupted
+	}
 	proposalMsg := dispatcher.DataResponse{
 		ChannelID: common.ChannelIDProposal,
 		Payload:   payload,
 	}
-	e.dispatcher.SendData([]string{}, proposalMsg)
+	e.dispatcher.SendData(peerIDs, proposalMsg)
+}
 
-	go func() {
-		e.AddMessage(proposal.Block)
-	}()
+// sendEquivocatingProposals sends the proposal to half of the peers, and a conflicting proposal of the
+// same height to the other half
+func (e *ConsensusEngine) sendEquivocatingProposals(proposal core.Proposal) {
+	conflicting, err := e.faults.conflictingBlock(proposal.Block, e.privateKey)
+	if err != nil {
+		e.logger.WithFields(log.Fields{"error": err}).Error("Fault injection: failed to create conflicting block")
+		e.sendProposal([]string{}, proposal)
+		return
+	}
+	originalPeers, otherPeers := []string{}, []string{}
+	if peers := e.dispatcher.Peers(false); len(peers) >= 2 {
+		originalPeers, otherPeers = e.faults.splitPeers(peers)
+	} // otherwise both proposals are broadcast, one right after the other
+	e.sendProposal(originalPeers, proposal)
+
+	conflictingProposal := proposal
+	conflictingProposal.Block = conflicting
+	e.logger.WithFields(log.Fields{
+		"block":       proposal.Block.Hash().Hex(),
+		"conflicting": conflicting.Hash().Hex(),
+		"height":      conflicting.Height,
+	}).Warn("Fault injection: equivocating proposal")
+	e.sendProposal(otherPeers, conflictingProposal)
 }
 
 func (e *ConsensusEngine) pruneState(currentBlockHeight uint64) {
@@ -1326,6 +1692,65 @@ func (e *ConsensusEngine) pruneState(currentBlockHeight uint64) {
 	e.ledger.PruneState(endHeight)
 }
 
+// indexRetentionConfigs maps the prunable indices to the config of their retained blocks.
+var indexRetentionConfigs = []struct {
+	indexType blockchain.IndexType
+	cfgKey    string
+}{
+	{blockchain.IndexTypeTxIndex, common.CfgStorageTxIndexRetainedBlocks},
+	{blockchain.IndexTypeTxReceipt, common.CfgStorageReceiptRetainedBlocks},
+	{blockchain.IndexTypeTxLog, common.CfgStorageLogRetainedBlocks},
+}
+
+// RetainedBlocksForIndex returns the number of blocks whose entries are retained for the
+// given index. 0 means the index is never pruned.
+func RetainedBlocksForIndex(indexType blockchain.IndexType) uint64 {
+	for _, cfg := range indexRetentionConfigs {
+		if cfg.indexType == indexType {
+			return uint64(viper.GetInt(cfg.cfgKey))
+		}
+	}
+	return 0
+}
+
+func (e *ConsensusEngine) pruneIndices(finalizedBlockHeight uint64) {
+	pruneInterval := uint64(viper.GetInt(common.CfgStorageIndexPruningInterval))
+	if pruneInterval == 0 || finalizedBlockHeight%pruneInterval != 0 {
+		return
+	}
+
+	for _, cfg := range indexRetentionConfigs {
+		retainedBlocks := uint64(viper.GetInt(cfg.cfgKey))
+		if retainedBlocks == 0 || finalizedBlockHeight <= retainedBlocks {
+			continue
+		}
+
+		endHeight := finalizedBlockHeight - retainedBlocks
+		_, err := e.chain.PruneIndex(cfg.indexType, endHeight, 3*pruneInterval)
+		if err != nil {
+			e.logger.WithFields(log.Fields{"error": err, "index": cfg.indexType}).Warn("Failed to prune index")
+		}
+	}
+}
+
+// freezeBlocks moves the finalized blocks beyond the retained ones to the ancient store, at the index pruning
+// interval and gradually like the index pruning.
+func (e *ConsensusEngine) freezeBlocks(finalizedBlockHeight uint64) {
+	freezeInterval := uint64(viper.GetInt(common.CfgStorageIndexPruningInterval))
+	if freezeInterval == 0 || finalizedBlockHeight%freezeInterval != 0 {
+		return
+	}
+	retainedBlocks := uint64(viper.GetInt(common.CfgStorageAncientRetainedBlocks))
+	if retainedBlocks == 0 || finalizedBlockHeight <= retainedBlocks {
+		return
+	}
+
+	_, err := e.chain.FreezeBlocks(finalizedBlockHeight-retainedBlocks, 3*freezeInterval)
+	if err != nil {
+		e.logger.WithFields(log.Fields{"error": err}).Warn("Failed to move the blocks to the ancient store")
+	}
+}
+
 func (e *ConsensusEngine) State() *State {
 	return e.state
 }
@@ -1334,14 +1759,14 @@ func (e *ConsensusEngine) resetGuardianTimer() {
 	if e.guardianTimer != nil {
 		e.guardianTimer.Stop()
 	}
-	e.guardianTimer = time.NewTicker(time.Duration(viper.GetInt(common.CfgGuardianRoundLength)) * time.Second)
+	e.guardianTimer = e.timeSource.NewTicker(time.Duration(viper.GetInt(common.CfgGuardianRoundLength)) * time.Second)
 }
 
-func isSyncing(lastestFinalizedBlock *core.ExtendedBlock, currentHeight uint64) bool {
+func isSyncing(lastestFinalizedBlock *core.ExtendedBlock, currentHeight uint64, now time.Time) bool {
 	if lastestFinalizedBlock == nil {
 		return true
 	}
-	currentTime := big.NewInt(time.Now().Unix())
+	currentTime := big.NewInt(now.Unix())
 	maxDiff := new(big.Int).SetUint64(30) // thirty seconds, about 5 blocks
 	threshold := new(big.Int).Sub(currentTime, maxDiff)
 	isSyncing := lastestFinalizedBlock.Timestamp.Cmp(threshold) < 0
@@ -1,16 +1,20 @@
 package consensus
 
 import (
+	"fmt"
 	"math/big"
 	"testing"
 	"time"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
 	"github.com/thetatoken/theta/store/database/backend"
 	"github.com/thetatoken/theta/store/kvstore"
 )
@@ -47,6 +51,7 @@ func TestSingleBlockValidation(t *testing.T) {
 	addr := privKey.PublicKey().Address()
 	validatorManager := MockValidatorManager{PrivKey: privKey}
 
+	core.ResetTestBlocks()
 	store := kvstore.NewKVStore(backend.NewMemDatabase())
 	root := core.CreateTestBlock("a0", "")
 	root.ChainID = "testchain"
@@ -742,3 +747,232 @@ func TestTipSelection(t *testing.T) {
 	tip = ce.GetTipToExtend()
 	assert.Equal(a2.Hash(), tip.Hash(), "should not select blocks with validator update that are higher than local HCC")
 }
+
+// importTestLedger is a ledger whose state is only available for the given blocks
+type importTestLedger struct {
+	core.Ledger
+	states map[common.Hash]bool
+}
+
+func (l *importTestLedger) ResetState(block *core.Block) result.Result {
+	if !l.states[block.Hash()] {
+		return result.Error("state not found")
+	}
+	return result.OK
+}
+
+func TestRecoverInterruptedApply(t *testing.T) {
+	require := require.New(t)
+
+	privKey, _, _ := crypto.GenerateKeyPair()
+	validatorManager := MockValidatorManager{PrivKey: privKey}
+
+	core.ResetTestBlocks()
+	store := kvstore.NewKVStore(backend.NewMemDatabase())
+	root := core.CreateTestBlock("a0", "")
+	chain := blockchain.NewChain("testchain", store, root)
+
+	b1 := core.CreateTestBlock("a1", "a0")
+	_, err := chain.AddBlock(b1)
+	require.Nil(err)
+	b2 := core.CreateTestBlock("a2", "a1")
+	_, err = chain.AddBlock(b2)
+	require.Nil(err)
+
+	ledger := &importTestLedger{states: map[common.Hash]bool{b1.Hash(): true}}
+
+	// The node crashed after marking b1 valid, and its state was persisted
+	ce := NewConsensusEngine(privKey, store, chain, nil, validatorManager)
+	ce.SetLedger(ledger)
+	chain.MarkBlockValid(b1.Hash())
+	require.Nil(ce.state.BeginImport(b1.Hash(), ImportStageApply))
+	ce.recoverImport()
+	eb1, err := chain.FindBlock(b1.Hash())
+	require.Nil(err)
+	require.True(eb1.Status.IsValid())
+	_, ok := ce.state.GetImportJournal()
+	require.False(ok)
+
+	// The node crashed after marking b2 valid, but before its state reached the disk
+	chain.MarkBlockValid(b2.Hash())
+	require.Nil(ce.state.BeginImport(b2.Hash(), ImportStageApply))
+
+	// The journal survives the restart, and the block gets applied again
+	ce = NewConsensusEngine(privKey, store, chain, nil, validatorManager)
+	ce.SetLedger(ledger)
+	ce.recoverImport()
+	eb2, err := chain.FindBlock(b2.Hash())
+	require.Nil(err)
+	require.True(eb2.Status.IsPending())
+	_, ok = ce.state.GetImportJournal()
+	require.False(ok)
+}
+
+// finalizeTestLedger is a ledger that only records the finalized heights
+type finalizeTestLedger struct {
+	core.Ledger
+	finalized []uint64
+}
+
+func (l *finalizeTestLedger) FinalizeState(height uint64, rootHash common.Hash) result.Result {
+	l.finalized = append(l.finalized, height)
+	return result.OK
+}
+
+func TestFinalizeIndirectlyFinalizedBlocks(t *testing.T) {
+	require := require.New(t)
+
+	defer viper.Set(common.CfgStorageSupplyIndexEnabled, viper.GetBool(common.CfgStorageSupplyIndexEnabled))
+	viper.Set(common.CfgStorageSupplyIndexEnabled, true)
+
+	privKey, _, _ := crypto.GenerateKeyPair()
+	validatorManager := MockValidatorManager{PrivKey: privKey}
+
+	core.ResetTestBlocks()
+	store := kvstore.NewKVStore(backend.NewMemDatabase())
+	root := core.CreateTestBlock("a0", "")
+	chain := blockchain.NewChain("testchain", store, root)
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	for i, parent := range []string{"a0", "a1", "a2", "a3", "a4"} {
+		block := core.CreateTestBlock(fmt.Sprintf("a%v", i+1), parent)
+		rawTx, err := types.TxToBytes(&types.SendTx{
+			Fee:     types.NewCoins(0, 1),
+			Inputs:  []types.TxInput{{Address: alice, Coins: types.NewCoins(0, int64(i+2))}},
+			Outputs: []types.TxOutput{{Address: bob, Coins: types.NewCoins(0, int64(i+1))}},
+		})
+		require.Nil(err)
+		block.Txs = []common.Bytes{rawTx}
+		_, err = chain.AddBlock(block)
+		require.Nil(err)
+		chain.MarkBlockValid(block.Hash())
+	}
+
+	ledger := &finalizeTestLedger{}
+	ce := NewConsensusEngine(privKey, store, chain, nil, validatorManager)
+	ce.SetLedger(ledger)
+
+	// Finalizing a3 indirectly finalizes a1 and a2, which are indexed along with a3
+	eb3, err := chain.FindBlock(core.GetTestBlock("a3").Hash())
+	require.Nil(err)
+	require.Nil(ce.persistFinalizedBlock(eb3))
+	require.Equal([]uint64{3}, ledger.finalized)
+	for height := uint64(1); height <= 3; height++ {
+		entry, found := chain.FindBlockStats(height)
+		require.True(found)
+		require.Equal(core.GetTestBlock(fmt.Sprintf("a%v", height)).Hash(), entry.BlockHash)
+	}
+	_, found := chain.FindBlockStats(4)
+	require.False(found)
+	entries, err := chain.SearchTxs(&blockchain.TxSearchFilter{StartHeight: 1, EndHeight: 6, To: &bob})
+	require.Nil(err)
+	require.Equal(3, len(entries))
+	supply, found := chain.FindSupplyDelta(3)
+	require.True(found)
+	require.Equal(int64(3), supply.CumulativeFeesBurned.TFuelWei.Int64())
+
+	for _, name := range []string{"a1", "a2"} {
+		eb, err := chain.FindBlock(core.GetTestBlock(name).Hash())
+		require.Nil(err)
+		require.Equal(core.BlockStatusIndirectlyFinalized, eb.Status)
+	}
+
+	// Only the blocks not finalized yet are indexed when a5 is finalized
+	eb5, err := chain.FindBlock(core.GetTestBlock("a5").Hash())
+	require.Nil(err)
+	require.Nil(ce.persistFinalizedBlock(eb5))
+	for height := uint64(1); height <= 5; height++ {
+		_, found := chain.FindBlockStats(height)
+		require.True(found)
+	}
+	entries, err = chain.SearchTxs(&blockchain.TxSearchFilter{StartHeight: 1, EndHeight: 6, To: &bob})
+	require.Nil(err)
+	require.Equal(5, len(entries))
+	for height := uint64(1); height <= 5; height++ {
+		supply, found := chain.FindSupplyDelta(height)
+		require.True(found)
+		require.Equal(int64(1), supply.FeesBurned.TFuelWei.Int64())
+	}
+	supply, found = chain.FindSupplyDelta(5)
+	require.True(found)
+	require.Equal(int64(5), supply.CumulativeFeesBurned.TFuelWei.Int64())
+	eb4, err := chain.FindBlock(core.GetTestBlock("a4").Hash())
+	require.Nil(err)
+	require.Equal(core.BlockStatusIndirectlyFinalized, eb4.Status)
+	eb5, err = chain.FindBlock(core.GetTestBlock("a5").Hash())
+	require.Nil(err)
+	require.Equal(core.BlockStatusDirectlyFinalized, eb5.Status)
+}
+
+// balanceTestLedger is a ledger that provides the balances recorded for the state of each block
+type balanceTestLedger struct {
+	finalizeTestLedger
+	balances map[common.Hash]map[common.Address]types.Coins
+}
+
+func (l *balanceTestLedger) GetBalances(block *core.Block, addresses []common.Address) (map[common.Address]types.Coins, error) {
+	balances := make(map[common.Address]types.Coins)
+	for _, address := range addresses {
+		if balance, ok := l.balances[block.Hash()][address]; ok {
+			balances[address] = balance
+		}
+	}
+	return balances, nil
+}
+
+func TestFinalizeBalanceCheckpointsOfIndirectlyFinalizedBlocks(t *testing.T) {
+	require := require.New(t)
+
+	privKey, _, _ := crypto.GenerateKeyPair()
+	validatorManager := MockValidatorManager{PrivKey: privKey}
+
+	core.ResetTestBlocks()
+	store := kvstore.NewKVStore(backend.NewMemDatabase())
+	root := core.CreateTestBlock("a0", "")
+	chain := blockchain.NewChain("testchain", store, root)
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	ledger := &balanceTestLedger{balances: make(map[common.Hash]map[common.Address]types.Coins)}
+	for i, parent := range []string{"a0", "a1"} {
+		block := core.CreateTestBlock(fmt.Sprintf("a%v", i+1), parent)
+		rawTx, err := types.TxToBytes(&types.SendTx{
+			Fee:     types.NewCoins(0, 1),
+			Inputs:  []types.TxInput{{Address: alice, Coins: types.NewCoins(0, 11)}},
+			Outputs: []types.TxOutput{{Address: bob, Coins: types.NewCoins(0, 10)}},
+		})
+		require.Nil(err)
+		block.Txs = []common.Bytes{rawTx}
+		_, err = chain.AddBlock(block)
+		require.Nil(err)
+		chain.MarkBlockValid(block.Hash())
+		ledger.balances[block.Hash()] = map[common.Address]types.Coins{
+			alice: types.NewCoins(0, int64(100-11*(i+1))),
+			bob:   types.NewCoins(0, int64(10*(i+1))),
+		}
+	}
+
+	ce := NewConsensusEngine(privKey, store, chain, nil, validatorManager)
+	ce.SetLedger(ledger)
+
+	// Finalizing a2 indirectly finalizes a1, whose balances are checkpointed as well
+	eb2, err := chain.FindBlock(core.GetTestBlock("a2").Hash())
+	require.Nil(err)
+	require.Nil(ce.persistFinalizedBlock(eb2))
+	require.Equal([]uint64{2}, ledger.finalized)
+
+	checkpoints, err := chain.FindBalanceHistory(bob, 1, 2)
+	require.Nil(err)
+	require.Equal(2, len(checkpoints))
+	require.Equal(uint64(1), checkpoints[0].Height)
+	require.Equal(int64(10), checkpoints[0].Balance.TFuelWei.Int64())
+	require.Equal(uint64(2), checkpoints[1].Height)
+	require.Equal(int64(20), checkpoints[1].Balance.TFuelWei.Int64())
+
+	checkpoints, err = chain.FindBalanceHistory(alice, 1, 2)
+	require.Nil(err)
+	require.Equal(2, len(checkpoints))
+	require.Equal(int64(89), checkpoints[0].Balance.TFuelWei.Int64())
+	require.Equal(int64(78), checkpoints[1].Balance.TFuelWei.Int64())
+}
@@ -0,0 +1,116 @@
+package consensus
+
+import (
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+)
+
+//
+// The fault injector makes a validator misbehave on purpose, so that the slashing and the peer scoring
+// can be exercised by the integration tests of a testnet. It is only compiled in with the "faults" build
+// tag (see faults_enabled.go), and the faults are picked in the consensus.faults.* config. In the
+// regular builds the injector is nil and all its methods report that no fault should be injected.
+//
+
+// FaultConfig lists the faults the validator injects
+type FaultConfig struct {
+	Equivocate          bool          // propose two conflicting blocks, each sent to half of the peers
+	WithholdBlocks      bool          // add the proposed blocks to the local chain but never send them out
+	VoteDelay           time.Duration // how long to hold the votes before sending them out
+	MalformedGossipRate float64       // the fraction of the outgoing proposals and votes that are corrupted
+}
+
+// IsEmpty returns true if no fault is configured
+func (c FaultConfig) IsEmpty() bool {
+	return !c.Equivocate && !c.WithholdBlocks && c.VoteDelay <= 0 && c.MalformedGossipRate <= 0
+}
+
+type faultInjector struct {
+	config FaultConfig
+
+	mu   *sync.Mutex // protects the random source, the delayed votes are sent from the timer goroutines
+	rand *rand.Rand
+}
+
+func newFaultInjectorWithConfig(config FaultConfig) *faultInjector {
+	if config.IsEmpty() {
+		return nil
+	}
+	return &faultInjector{
+		config: config,
+		mu:     &sync.Mutex{},
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (fi *faultInjector) shouldEquivocate() bool {
+	return fi != nil && fi.config.Equivocate
+}
+
+func (fi *faultInjector) shouldWithholdBlock() bool {
+	return fi != nil && fi.config.WithholdBlocks
+}
+
+func (fi *faultInjector) voteDelay() time.Duration {
+	if fi == nil || fi.config.VoteDelay < 0 {
+		return 0
+	}
+	return fi.config.VoteDelay
+}
+
+// malform returns a corrupted copy of the payload if the payload is picked for corruption, and nil otherwise
+func (fi *faultInjector) malform(payload []byte) []byte {
+	if fi == nil || fi.config.MalformedGossipRate <= 0 || len(payload) == 0 {
+		return nil
+	}
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	if fi.rand.Float64() >= fi.config.MalformedGossipRate {
+		return nil
+	}
+	corrupted := make([]byte, len(payload))
+	copy(corrupted, payload)
+	// Flip a few bytes and truncate the tail, so that the payload either fails to decode or carries
+	// an invalid signature
+	for i := 0; i < 4; i++ {
+		idx := fi.rand.Intn(len(corrupted))
+		corrupted[idx] ^= 0xff
+	}
+	return corrupted[:len(corrupted)-fi.rand.Intn(len(corrupted)/2+1)]
+}
+
+// conflictingBlock creates a block of the same height and parent as the given block, but with a different
+// hash, signed by the proposer
+func (fi *faultInjector) conflictingBlock(block *core.Block, privateKey *crypto.PrivateKey) (*core.Block, error) {
+	header := *block.BlockHeader
+	header.Timestamp = new(big.Int).Add(block.Timestamp, big.NewInt(1))
+	conflicting := &core.Block{
+		BlockHeader: &header,
+		Txs:         block.Txs,
+	}
+	sig, err := privateKey.Sign(conflicting.SignBytes())
+	if err != nil {
+		return nil, err
+	}
+	conflicting.SetSignature(sig)
+	conflicting.UpdateHash()
+	return conflicting, nil
+}
+
+// splitPeers splits the peers in two halves, each receiving one of the conflicting proposals
+func (fi *faultInjector) splitPeers(peerIDs []string) ([]string, []string) {
+	shuffled := make([]string, len(peerIDs))
+	copy(shuffled, peerIDs)
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	half := (len(shuffled) + 1) / 2
+	return shuffled[:half], shuffled[half:]
+}
@@ -0,0 +1,11 @@
+// +build !faults
+
+package consensus
+
+// FaultInjectionEnabled tells whether the binary was built with the fault injection
+const FaultInjectionEnabled = false
+
+// newFaultInjector ignores the consensus.faults.* config, the regular builds never inject faults
+func newFaultInjector() *faultInjector {
+	return nil
+}
@@ -0,0 +1,22 @@
+// +build faults
+
+package consensus
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+)
+
+// FaultInjectionEnabled tells whether the binary was built with the fault injection
+const FaultInjectionEnabled = true
+
+func newFaultInjector() *faultInjector {
+	return newFaultInjectorWithConfig(FaultConfig{
+		Equivocate:          viper.GetBool(common.CfgConsensusFaultsEquivocate),
+		WithholdBlocks:      viper.GetBool(common.CfgConsensusFaultsWithholdBlocks),
+		VoteDelay:           time.Duration(viper.GetInt64(common.CfgConsensusFaultsVoteDelayMs)) * time.Millisecond,
+		MalformedGossipRate: viper.GetFloat64(common.CfgConsensusFaultsMalformedGossipRate),
+	})
+}
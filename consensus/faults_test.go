@@ -0,0 +1,86 @@
+package consensus
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+)
+
+func TestNoFaultInjector(t *testing.T) {
+	assert := assert.New(t)
+
+	var fi *faultInjector
+	assert.False(fi.shouldEquivocate())
+	assert.False(fi.shouldWithholdBlock())
+	assert.Equal(time.Duration(0), fi.voteDelay())
+	assert.Nil(fi.malform([]byte{0x1, 0x2, 0x3}))
+
+	assert.Nil(newFaultInjectorWithConfig(FaultConfig{}))
+	if !FaultInjectionEnabled {
+		assert.Nil(newFaultInjector())
+	}
+}
+
+func TestFaultInjector(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fi := newFaultInjectorWithConfig(FaultConfig{
+		Equivocate:          true,
+		VoteDelay:           2 * time.Second,
+		MalformedGossipRate: 1.0,
+	})
+	require.NotNil(fi)
+	assert.True(fi.shouldEquivocate())
+	assert.False(fi.shouldWithholdBlock())
+	assert.Equal(2*time.Second, fi.voteDelay())
+
+	payload := common.Bytes("a well formed payload of the consensus message")
+	corrupted := fi.malform(payload)
+	assert.NotNil(corrupted)
+	assert.NotEqual(payload, corrupted)
+	assert.Equal(common.Bytes("a well formed payload of the consensus message"), payload)
+
+	peers := []string{"peer1", "peer2", "peer3", "peer4", "peer5"}
+	first, second := fi.splitPeers(peers)
+	assert.Equal(3, len(first))
+	assert.Equal(2, len(second))
+	assert.ElementsMatch(peers, append(first, second...))
+}
+
+func TestConflictingBlock(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	privKey, _, _ := crypto.GenerateKeyPair()
+	block := core.NewBlock()
+	block.ChainID = "testchain"
+	block.Epoch = 5
+	block.Height = 3
+	block.Parent = common.HexToHash("0x1234")
+	block.HCC.BlockHash = common.HexToHash("0x1234")
+	block.Proposer = privKey.PublicKey().Address()
+	block.Timestamp = big.NewInt(1000)
+	block.AddTxs([]common.Bytes{common.Bytes("tx1")})
+	sig, err := privKey.Sign(block.SignBytes())
+	require.Nil(err)
+	block.SetSignature(sig)
+	hash := block.Hash()
+
+	fi := newFaultInjectorWithConfig(FaultConfig{Equivocate: true})
+	conflicting, err := fi.conflictingBlock(block, privKey)
+	require.Nil(err)
+	assert.NotEqual(hash, conflicting.Hash())
+	assert.Equal(hash, block.Hash())
+	assert.Equal(block.Height, conflicting.Height)
+	assert.Equal(block.Parent, conflicting.Parent)
+	assert.Equal(int64(1000), block.Timestamp.Int64())
+	res := conflicting.Validate("testchain")
+	assert.True(res.IsOK(), res.Message)
+}
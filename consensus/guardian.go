@@ -68,6 +68,9 @@ func (g *GuardianEngine) StartNewBlock(block common.Hash) {
 	g.gcp = gcp
 	g.gcpHash = gcp.Hash()
 	g.signerIndex = gcp.WithStake().Index(g.privKey.PublicKey())
+	if viper.GetBool(common.CfgShadowEnabled) {
+		g.signerIndex = -1 // do not vote in the shadow mode
+	}
 
 	g.logger.WithFields(log.Fields{
 		"block":       block.Hex(),
@@ -24,8 +24,30 @@ const (
 	DBStateStubKey      = "cs/ss"
 	DBVoteByBlockPrefix = "cs/vbb/"
 	DBEpochVotesKey     = "cs/ev"
+	DBImportJournalKey  = "cs/ij"
 )
 
+// ImportStage indicates the stage of a block import recorded by the import journal
+type ImportStage uint8
+
+const (
+	// ImportStageApply indicates the txs of the block are being applied to the state
+	ImportStageApply ImportStage = iota + 1
+
+	// ImportStageFinalize indicates the block is being finalized, i.e. the consensus state, the block
+	// statuses and the indices are being updated
+	ImportStageFinalize
+)
+
+// ImportJournal records the block being imported. The writes of a block import span the chain DB
+// and the state DB, so the journal is written before the import starts and deleted after it
+// completes. A journal left behind indicates the import was interrupted, e.g. by a power loss,
+// and needs to be recovered on restart.
+type ImportJournal struct {
+	BlockHash common.Hash
+	Stage     ImportStage
+}
+
 type State struct {
 	mu *sync.RWMutex
 
@@ -197,6 +219,52 @@ func (s *State) SetLastFinalizedBlock(block *core.ExtendedBlock) error {
 	return s.commit()
 }
 
+// SetLastFinalizedBlockInBatch updates the last finalized block, writing the state to the given batch,
+// which is written by the caller
+func (s *State) SetLastFinalizedBlockInBatch(block *core.ExtendedBlock, batch store.Batch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastFinalizedBlock = block.Hash()
+	return batch.Put([]byte(DBStateStubKey), s.getSummary())
+}
+
+// NewBatch creates a batch of writes to the DB of the consensus state. The node keeps the chain in the
+// same DB, so the batch can also update the blocks.
+func (s *State) NewBatch() store.Batch {
+	return s.db.NewBatch()
+}
+
+// BeginImport records the block import in the import journal
+func (s *State) BeginImport(blockHash common.Hash, stage ImportStage) error {
+	journal := &ImportJournal{
+		BlockHash: blockHash,
+		Stage:     stage,
+	}
+	return s.db.Put([]byte(DBImportJournalKey), journal)
+}
+
+// EndImport deletes the import journal after the block import completes
+func (s *State) EndImport() error {
+	return s.db.Delete([]byte(DBImportJournalKey))
+}
+
+// EndImportInBatch deletes the import journal in the given batch, which carries the last writes of the
+// import, so that these writes and the end of the journal are committed together
+func (s *State) EndImportInBatch(batch store.Batch) error {
+	return batch.Delete([]byte(DBImportJournalKey))
+}
+
+// GetImportJournal returns the journal of the interrupted block import, if any
+func (s *State) GetImportJournal() (*ImportJournal, bool) {
+	journal := &ImportJournal{}
+	err := s.db.Get([]byte(DBImportJournalKey), journal)
+	if err != nil {
+		return nil, false
+	}
+	return journal, true
+}
+
 func (s *State) AddVote(vote *core.Vote) error {
 	if err := s.AddEpochVote(vote); err != nil {
 		return err
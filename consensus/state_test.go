@@ -96,3 +96,48 @@ func TestConsensusStateVoteSet(t *testing.T) {
 	assert.Equal(1, len(votes))
 	assert.Equal(uint64(30), votes[0].Epoch)
 }
+
+func TestConsensusStateImportJournal(t *testing.T) {
+	assert := assert.New(t)
+	core.ResetTestBlocks()
+
+	db := kvstore.NewKVStore(backend.NewMemDatabase())
+	chain := blockchain.CreateTestChainByBlocks([]string{
+		"A1", "A0",
+	})
+
+	state1 := NewState(db, chain)
+	_, ok := state1.GetImportJournal()
+	assert.False(ok)
+
+	blockHash := core.GetTestBlock("A1").Hash()
+	assert.Nil(state1.BeginImport(blockHash, ImportStageFinalize))
+
+	// The journal survives a restart
+	state2 := NewState(db, chain)
+	journal, ok := state2.GetImportJournal()
+	assert.True(ok)
+	assert.Equal(blockHash, journal.BlockHash)
+	assert.Equal(ImportStageFinalize, journal.Stage)
+
+	assert.Nil(state2.EndImport())
+	_, ok = state2.GetImportJournal()
+	assert.False(ok)
+
+	// The journal ended in a batch is only deleted along with the other writes of the batch
+	assert.Nil(state2.BeginImport(blockHash, ImportStageFinalize))
+	block, err := chain.FindBlock(blockHash)
+	assert.Nil(err)
+	batch := state2.NewBatch()
+	assert.Nil(state2.SetLastFinalizedBlockInBatch(block, batch))
+	assert.Nil(state2.EndImportInBatch(batch))
+	_, ok = NewState(db, chain).GetImportJournal()
+	assert.True(ok)
+	assert.NotEqual(blockHash, NewState(db, chain).GetSummary().LastFinalizedBlock)
+
+	assert.Nil(batch.Write())
+	state3 := NewState(db, chain)
+	_, ok = state3.GetImportJournal()
+	assert.False(ok)
+	assert.Equal(blockHash, state3.GetSummary().LastFinalizedBlock)
+}
@@ -0,0 +1,114 @@
+package consensus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// maxValidatorActivityEpochs is the max number of the recent epochs the activity of the local validator is kept for
+const maxValidatorActivityEpochs = 2000
+
+// ValidatorEpochActivity records what the local validator did in an epoch
+type ValidatorEpochActivity struct {
+	Epoch     uint64        `json:"epoch"`
+	StartedAt time.Time     `json:"started_at"` // when the local node first acted in the epoch
+	Proposal  common.Hash   `json:"proposal"`   // the block proposed in the epoch, empty if none
+	Votes     []common.Hash `json:"votes"`      // the blocks voted in the epoch
+	TimedOut  bool          `json:"timed_out"`  // the epoch timer fired before a majority of the validators moved on
+}
+
+// ValidatorActivity keeps the activity of the local validator in the recent epochs. It is kept in memory, so
+// it only covers the epochs since the node started.
+type ValidatorActivity struct {
+	mu     *sync.Mutex
+	epochs map[uint64]*ValidatorEpochActivity
+	oldest uint64
+}
+
+func NewValidatorActivity() *ValidatorActivity {
+	return &ValidatorActivity{
+		mu:     &sync.Mutex{},
+		epochs: make(map[uint64]*ValidatorEpochActivity),
+	}
+}
+
+// getOrCreate returns the activity of the epoch, and drops the oldest epochs beyond the limit. It returns nil
+// for an epoch older than all the kept ones when the limit is reached, since it would be dropped right away.
+// The caller needs to hold the lock.
+func (a *ValidatorActivity) getOrCreate(epoch uint64, now time.Time) *ValidatorEpochActivity {
+	if activity, ok := a.epochs[epoch]; ok {
+		return activity
+	}
+	if len(a.epochs) >= maxValidatorActivityEpochs && epoch < a.oldest {
+		return nil
+	}
+	activity := &ValidatorEpochActivity{Epoch: epoch, StartedAt: now, Votes: []common.Hash{}}
+	a.epochs[epoch] = activity
+	if len(a.epochs) == 1 || epoch < a.oldest {
+		a.oldest = epoch
+	}
+	for len(a.epochs) > maxValidatorActivityEpochs {
+		delete(a.epochs, a.oldest)
+		a.oldest++
+		for a.epochs[a.oldest] == nil {
+			a.oldest++
+		}
+	}
+	return activity
+}
+
+// RecordProposal records the block proposed in the epoch
+func (a *ValidatorActivity) RecordProposal(epoch uint64, block common.Hash, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if activity := a.getOrCreate(epoch, now); activity != nil {
+		activity.Proposal = block
+	}
+}
+
+// RecordVote records a vote cast in the epoch
+func (a *ValidatorActivity) RecordVote(epoch uint64, block common.Hash, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	activity := a.getOrCreate(epoch, now)
+	if activity == nil {
+		return
+	}
+	for _, voted := range activity.Votes {
+		if voted == block {
+			return
+		}
+	}
+	activity.Votes = append(activity.Votes, block)
+}
+
+// RecordTimeout records that the epoch timed out
+func (a *ValidatorActivity) RecordTimeout(epoch uint64, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if activity := a.getOrCreate(epoch, now); activity != nil {
+		activity.TimedOut = true
+	}
+}
+
+// Get returns a copy of the activity in the epoch, nil if the local node did not act in it
+func (a *ValidatorActivity) Get(epoch uint64) *ValidatorEpochActivity {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	activity, ok := a.epochs[epoch]
+	if !ok {
+		return nil
+	}
+	ret := *activity
+	ret.Votes = append([]common.Hash{}, activity.Votes...)
+	return &ret
+}
+
+// OldestEpoch returns the oldest epoch the activity is kept for, ok is false if there is none
+func (a *ValidatorActivity) OldestEpoch() (epoch uint64, ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.oldest, len(a.epochs) > 0
+}
@@ -0,0 +1,80 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestValidatorActivity(t *testing.T) {
+	assert := assert.New(t)
+
+	block1 := common.BytesToHash([]byte{1})
+	block2 := common.BytesToHash([]byte{2})
+
+	now := time.Now()
+	a := NewValidatorActivity()
+	_, ok := a.OldestEpoch()
+	assert.False(ok)
+	assert.Nil(a.Get(1))
+
+	a.RecordProposal(10, block1, now)
+	a.RecordVote(10, block1, now)
+	a.RecordVote(10, block1, now)
+	a.RecordTimeout(11, now)
+	a.RecordVote(11, block2, now)
+
+	activity := a.Get(10)
+	assert.Equal(block1, activity.Proposal)
+	assert.Equal([]common.Hash{block1}, activity.Votes) // the repeated votes are recorded once
+	assert.False(activity.TimedOut)
+	assert.True(a.Get(11).TimedOut)
+	assert.True(a.Get(11).Proposal.IsEmpty())
+
+	// The returned activity is a copy
+	activity.Votes[0] = block2
+	assert.Equal(block1, a.Get(10).Votes[0])
+
+	// Only the recent epochs are kept
+	for epoch := uint64(12); epoch < 10+maxValidatorActivityEpochs+5; epoch++ {
+		a.RecordVote(epoch, block1, now)
+	}
+	oldest, ok := a.OldestEpoch()
+	assert.True(ok)
+	assert.Equal(uint64(15), oldest)
+	assert.Nil(a.Get(14))
+	assert.NotNil(a.Get(15))
+}
+
+func TestValidatorActivityIgnoresEpochsOlderThanKeptAtCapacity(t *testing.T) {
+	assert := assert.New(t)
+
+	block := common.BytesToHash([]byte{1})
+	now := time.Now()
+	a := NewValidatorActivity()
+	for epoch := uint64(100); epoch < 100+maxValidatorActivityEpochs; epoch++ {
+		a.RecordVote(epoch, block, now)
+	}
+
+	// A late action in an epoch older than all the kept ones is ignored, rather than recorded in an activity
+	// that is evicted right away
+	assert.Nil(a.getOrCreate(50, now))
+	assert.Equal(maxValidatorActivityEpochs, len(a.epochs))
+	a.RecordProposal(50, block, now)
+	a.RecordVote(50, block, now)
+	a.RecordTimeout(50, now)
+	assert.Nil(a.Get(50))
+	oldest, ok := a.OldestEpoch()
+	assert.True(ok)
+	assert.Equal(uint64(100), oldest)
+	assert.NotNil(a.Get(100))
+	assert.NotNil(a.Get(99 + maxValidatorActivityEpochs))
+
+	// A newer epoch still evicts the oldest one
+	a.RecordVote(100+maxValidatorActivityEpochs, block, now)
+	oldest, _ = a.OldestEpoch()
+	assert.Equal(uint64(101), oldest)
+	assert.Nil(a.Get(100))
+}
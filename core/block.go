@@ -98,7 +98,16 @@ func (b *Block) updateTxHash() {
 
 // Validate checks the block is legitimate.
 func (b *Block) Validate(chainID string) result.Result {
-	res := b.BlockHeader.Validate(chainID)
+	res := b.ValidateBasic(chainID)
+	if res.IsError() {
+		return res
+	}
+	return b.BlockHeader.ValidateSignature()
+}
+
+// ValidateBasic checks the block is legitimate, except for the signature of the proposer.
+func (b *Block) ValidateBasic(chainID string) result.Result {
+	res := b.BlockHeader.ValidateBasic(chainID)
 	if res.IsError() {
 		return res
 	}
@@ -146,7 +155,7 @@ func (h *BlockHeader) EncodeRLP(w io.Writer) error {
 	if h == nil {
 		return rlp.Encode(w, &BlockHeader{})
 	}
-	if h.Height < common.HeightEnableTheta2 {
+	if !IsForkActive(h.ChainID, ForkTheta2, h.Height) {
 		return rlp.Encode(w, []interface{}{
 			h.ChainID,
 			h.Epoch,
@@ -164,7 +173,7 @@ func (h *BlockHeader) EncodeRLP(w io.Writer) error {
 	}
 
 	// Theta2.0 fork
-	if h.Height >= common.HeightEnableTheta2 && h.Height < common.HeightEnableTheta3 {
+	if IsForkActive(h.ChainID, ForkTheta2, h.Height) && !IsForkActive(h.ChainID, ForkTheta3, h.Height) {
 		return rlp.Encode(w, []interface{}{
 			h.ChainID,
 			h.Epoch,
@@ -271,7 +280,7 @@ func (h *BlockHeader) DecodeRLP(stream *rlp.Stream) error {
 	}
 
 	// Theta2.0 fork
-	if h.Height >= common.HeightEnableTheta2 {
+	if IsForkActive(h.ChainID, ForkTheta2, h.Height) {
 		raw, err := stream.Raw()
 		if err != nil {
 			return err
@@ -290,7 +299,7 @@ func (h *BlockHeader) DecodeRLP(stream *rlp.Stream) error {
 	}
 
 	// Theta3.0 fork
-	if h.Height >= common.HeightEnableTheta3 {
+	if IsForkActive(h.ChainID, ForkTheta3, h.Height) {
 		raw, err := stream.Raw()
 		if err != nil {
 			return err
@@ -360,6 +369,15 @@ func (h *BlockHeader) SetSignature(sig *crypto.Signature) {
 
 // Validate checks the header is legitimate.
 func (h *BlockHeader) Validate(chainID string) result.Result {
+	res := h.ValidateBasic(chainID)
+	if res.IsError() {
+		return res
+	}
+	return h.ValidateSignature()
+}
+
+// ValidateBasic checks the header is legitimate, except for the signature of the proposer.
+func (h *BlockHeader) ValidateBasic(chainID string) result.Result {
 	if chainID != h.ChainID {
 		return result.Error("ChainID mismatch")
 	}
@@ -378,7 +396,12 @@ func (h *BlockHeader) Validate(chainID string) result.Result {
 	if h.Signature == nil || h.Signature.IsEmpty() {
 		return result.Error("Block is not signed")
 	}
-	if !h.Signature.Verify(h.SignBytes(), h.Proposer) {
+	return result.OK
+}
+
+// ValidateSignature checks the header is signed by the proposer.
+func (h *BlockHeader) ValidateSignature() result.Result {
+	if h.Signature == nil || !h.Signature.Verify(h.SignBytes(), h.Proposer) {
 		return result.Error("Signature verification failed")
 	}
 	return result.OK
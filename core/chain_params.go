@@ -0,0 +1,178 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// Names of the economic parameters of a chain which can be set at its genesis. The amounts are in Wei, i.e.
+// ThetaWei for the Theta stakes, and TFuelWei otherwise.
+const (
+	ChainParamMinValidatorStake           = "min_validator_stake"
+	ChainParamMinGuardianStake            = "min_guardian_stake"
+	ChainParamMinEliteEdgeNodeStake       = "min_elite_edge_node_stake"
+	ChainParamMaxEliteEdgeNodeStake       = "max_elite_edge_node_stake"
+	ChainParamValidatorRewardPerBlock     = "validator_reward_per_block"
+	ChainParamEliteEdgeNodeRewardPerBlock = "elite_edge_node_reward_per_block"
+	ChainParamMinTransactionFee           = "min_transaction_fee"
+	ChainParamMinGasPrice                 = "min_gas_price"
+)
+
+// stakeChainParams are the chain parameters which need to be positive
+var stakeChainParams = map[string]bool{
+	ChainParamMinValidatorStake:     true,
+	ChainParamMinGuardianStake:      true,
+	ChainParamMinEliteEdgeNodeStake: true,
+	ChainParamMaxEliteEdgeNodeStake: true,
+}
+
+var otherChainParams = map[string]bool{
+	ChainParamValidatorRewardPerBlock:     true,
+	ChainParamEliteEdgeNodeRewardPerBlock: true,
+	ChainParamMinTransactionFee:           true,
+	ChainParamMinGasPrice:                 true,
+}
+
+// MaxEliteEdgeNodeStakeUnits caps the max elite edge node stake, in the units of the Mainnet min stake the
+// elite edge node rewards are sampled by
+const MaxEliteEdgeNodeStakeUnits = 1000
+
+//
+// ------- ChainParams ------- //
+//
+
+// ChainParams maps the names of the chain parameters to their values. The parameters not in the map take
+// the Mainnet values, which may change with the forks.
+type ChainParams map[string]*big.Int
+
+var (
+	// chainParams maps the chain IDs to their parameters. The chains without loaded parameters take the
+	// Mainnet values.
+	chainParams     = map[string]ChainParams{}
+	chainParamsLock sync.RWMutex
+)
+
+// ParseChainParams parses the chain parameters from their decimal values, e.g. the -chain_params file of
+// the genesis generator.
+func ParseChainParams(values map[string]string) (ChainParams, error) {
+	params := ChainParams{}
+	for name, raw := range values {
+		value, ok := new(big.Int).SetString(raw, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid value of chain parameter %v: %v", name, raw)
+		}
+		params[name] = value
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// Validate checks the names and the values of the chain parameters
+func (cp ChainParams) Validate() error {
+	for name, value := range cp {
+		if !stakeChainParams[name] && !otherChainParams[name] {
+			return fmt.Errorf("unknown chain parameter: %v", name)
+		}
+		if value == nil || value.Sign() < 0 || (stakeChainParams[name] && value.Sign() == 0) {
+			return fmt.Errorf("chain parameter %v cannot be %v", name, value)
+		}
+	}
+	return cp.validateEliteEdgeNodeStakes()
+}
+
+// Equal tells whether the two sets of chain parameters have the same values
+func (cp ChainParams) Equal(other ChainParams) bool {
+	if len(cp) != len(other) {
+		return false
+	}
+	for name, value := range cp {
+		if otherValue, ok := other[name]; !ok || value.Cmp(otherValue) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadChainParams sets the parameters of the given chain. The parameters are committed to the genesis state
+// of the chain, and are loaded from the state before the node starts processing its blocks. The parameters
+// of the Mainnet cannot be set.
+func LoadChainParams(chainID string, params ChainParams) error {
+	if len(params) > 0 && chainID == MainnetChainID {
+		return fmt.Errorf("the chain parameters of the %v cannot be set", MainnetChainID)
+	}
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	loaded := ChainParams{}
+	for name, value := range params {
+		loaded[name] = new(big.Int).Set(value)
+	}
+
+	chainParamsLock.Lock()
+	defer chainParamsLock.Unlock()
+	chainParams[chainID] = loaded
+	return nil
+}
+
+func (cp ChainParams) validateEliteEdgeNodeStakes() error {
+	minStake, maxStake := MinEliteEdgeNodeStakeDeposit, MaxEliteEdgeNodeStakeDeposit
+	if value, ok := cp[ChainParamMinEliteEdgeNodeStake]; ok {
+		minStake = value
+	}
+	if value, ok := cp[ChainParamMaxEliteEdgeNodeStake]; ok {
+		maxStake = value
+	}
+	if minStake.Cmp(maxStake) > 0 {
+		return fmt.Errorf("the min elite edge node stake %v is above the max one %v", minStake, maxStake)
+	}
+	if maxStake.Cmp(new(big.Int).Mul(MinEliteEdgeNodeStakeDeposit, big.NewInt(MaxEliteEdgeNodeStakeUnits))) > 0 {
+		return fmt.Errorf("the max elite edge node stake %v is more than %v times %v",
+			maxStake, MaxEliteEdgeNodeStakeUnits, MinEliteEdgeNodeStakeDeposit)
+	}
+	return nil
+}
+
+// GetChainParam returns the value of the given parameter of the given chain, ok is false if it takes the
+// Mainnet value
+func GetChainParam(chainID string, name string) (value *big.Int, ok bool) {
+	chainParamsLock.RLock()
+	defer chainParamsLock.RUnlock()
+	if value, ok = chainParams[chainID][name]; ok {
+		return new(big.Int).Set(value), true
+	}
+	return nil, false
+}
+
+func chainParamOrDefault(chainID string, name string, defaultValue *big.Int) *big.Int {
+	if value, ok := GetChainParam(chainID, name); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// MinValidatorStake returns the min stake of a validator deposit of the given chain
+func MinValidatorStake(chainID string) *big.Int {
+	return chainParamOrDefault(chainID, ChainParamMinValidatorStake, MinValidatorStakeDeposit)
+}
+
+// MinGuardianStake returns the min stake of a guardian deposit of the given chain at the given height
+func MinGuardianStake(chainID string, blockHeight uint64) *big.Int {
+	if IsForkActive(chainID, ForkLowerGNStakeThreshold, blockHeight) {
+		return chainParamOrDefault(chainID, ChainParamMinGuardianStake, MinGuardianStakeDeposit1000)
+	}
+	return chainParamOrDefault(chainID, ChainParamMinGuardianStake, MinGuardianStakeDeposit)
+}
+
+// MinEliteEdgeNodeStake returns the min stake of an elite edge node deposit of the given chain
+func MinEliteEdgeNodeStake(chainID string) *big.Int {
+	return chainParamOrDefault(chainID, ChainParamMinEliteEdgeNodeStake, MinEliteEdgeNodeStakeDeposit)
+}
+
+// MaxEliteEdgeNodeStake returns the max total stake of an elite edge node of the given chain
+func MaxEliteEdgeNodeStake(chainID string) *big.Int {
+	return chainParamOrDefault(chainID, ChainParamMaxEliteEdgeNodeStake, MaxEliteEdgeNodeStakeDeposit)
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestChainParams(t *testing.T) {
+	assert := assert.New(t)
+
+	defer LoadChainParams("testnet", nil)
+
+	// The Mainnet parameters cannot be set
+	assert.NotNil(LoadChainParams(MainnetChainID, ChainParams{ChainParamMinValidatorStake: big.NewInt(1)}))
+
+	// Unknown parameters and invalid values are rejected
+	_, err := ParseChainParams(map[string]string{"unknown": "1"})
+	assert.NotNil(err)
+	_, err = ParseChainParams(map[string]string{ChainParamMinGasPrice: "1e8"})
+	assert.NotNil(err)
+	_, err = ParseChainParams(map[string]string{ChainParamMinGasPrice: "-1"})
+	assert.NotNil(err)
+	_, err = ParseChainParams(map[string]string{ChainParamMinValidatorStake: "0"})
+	assert.NotNil(err)
+	_, err = ParseChainParams(map[string]string{ChainParamMinEliteEdgeNodeStake: "600000000000000000000000"})
+	assert.NotNil(err)
+	_, err = ParseChainParams(map[string]string{ChainParamMaxEliteEdgeNodeStake: "20000000000000000000000000"})
+	assert.NotNil(err)
+	assert.NotNil(LoadChainParams("testnet", ChainParams{ChainParamMinGasPrice: big.NewInt(-1)}))
+
+	params, err := ParseChainParams(map[string]string{
+		ChainParamMinValidatorStake:       "1000000000000000000",
+		ChainParamMinGuardianStake:        "100000000000000000000",
+		ChainParamValidatorRewardPerBlock: "0",
+	})
+	assert.Nil(err)
+	assert.Nil(LoadChainParams("testnet", params))
+	assert.Equal(big.NewInt(1e18), MinValidatorStake("testnet"))
+	assert.Equal(big.NewInt(0), chainParams["testnet"][ChainParamValidatorRewardPerBlock])
+	minGuardianStake := new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+	assert.Equal(minGuardianStake, MinGuardianStake("testnet", 0))
+	assert.Equal(minGuardianStake, MinGuardianStake("testnet", common.HeightLowerGNStakeThresholdTo1000))
+	assert.Equal(MinEliteEdgeNodeStakeDeposit, MinEliteEdgeNodeStake("testnet"))
+	_, ok := GetChainParam("testnet", ChainParamMinGasPrice)
+	assert.False(ok)
+
+	// The loaded and the returned values are copies
+	params[ChainParamMinValidatorStake].SetInt64(1)
+	MinValidatorStake("testnet").SetInt64(1)
+	assert.Equal(big.NewInt(1e18), MinValidatorStake("testnet"))
+
+	assert.False(params.Equal(ChainParams{}))
+	assert.True(params.Equal(ChainParams{
+		ChainParamMinValidatorStake:       big.NewInt(1),
+		ChainParamMinGuardianStake:        minGuardianStake,
+		ChainParamValidatorRewardPerBlock: big.NewInt(0),
+	}))
+
+	// The parameters of the other chains are not affected
+	assert.Equal(MinValidatorStakeDeposit, MinValidatorStake(MainnetChainID))
+	assert.Equal(MinGuardianStakeDeposit, MinGuardianStake("privatenet", 0))
+	assert.Equal(MinGuardianStakeDeposit1000, MinGuardianStake("privatenet", common.HeightLowerGNStakeThresholdTo1000))
+
+	assert.Nil(LoadChainParams("testnet", nil))
+	assert.Equal(MinValidatorStakeDeposit, MinValidatorStake("testnet"))
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// MaxCrossChainMessageDataSize is the max size of the payload of a cross-chain message
+const MaxCrossChainMessageDataSize = 4096
+
+//
+// ------- CrossChainMessage ------- //
+//
+
+// CrossChainMessage is a message sent from an account of the source chain to an account of the target
+// chain. The messages between two chains are delivered in the order of their nonces.
+type CrossChainMessage struct {
+	SourceChainID string         `json:"source_chain_id"`
+	TargetChainID string         `json:"target_chain_id"`
+	Nonce         uint64         `json:"nonce"` // sequence number of the message from the source chain to the target chain
+	Sender        common.Address `json:"sender"`
+	Receiver      common.Address `json:"receiver"`
+	Data          common.Bytes   `json:"data"`
+	SourceHeight  uint64         `json:"source_height"` // height of the source chain block including the message
+}
+
+func (m *CrossChainMessage) String() string {
+	return fmt.Sprintf("CrossChainMessage{source: %v, target: %v, nonce: %v, sender: %v, receiver: %v, data: %v bytes}",
+		m.SourceChainID, m.TargetChainID, m.Nonce, m.Sender, m.Receiver, len(m.Data))
+}
+
+//
+// ------- CrossChainChannel ------- //
+//
+
+// CrossChainChannel tracks the messages exchanged with a peer chain
+type CrossChainChannel struct {
+	PeerChainID  string `json:"peer_chain_id"`
+	SendNonce    uint64 `json:"send_nonce"`    // nonce of the next message sent to the peer chain
+	ReceiveNonce uint64 `json:"receive_nonce"` // nonce of the next message to be received from the peer chain
+}
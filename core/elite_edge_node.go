@@ -302,7 +302,7 @@ type EliteEdgeNodePool interface {
 	Get(eenAddr common.Address) *EliteEdgeNode
 	Upsert(een *EliteEdgeNode)
 	GetAll(withstake bool) []*EliteEdgeNode
-	DepositStake(source common.Address, holder common.Address, amount *big.Int, pubkey *bls.PublicKey, blockHeight uint64) (err error)
+	DepositStake(source common.Address, holder common.Address, amount *big.Int, pubkey *bls.PublicKey, chainID string, blockHeight uint64) (err error)
 	WithdrawStake(source common.Address, holder common.Address, currentHeight uint64) (*Stake, error)
 	RandomRewardWeight(block common.Hash, eenAddr common.Address) int
 }
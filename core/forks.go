@@ -0,0 +1,178 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// Names of the height-gated protocol changes
+const (
+	ForkValidatorReward       = "validator_reward"
+	ForkTheta2                = "theta2"
+	ForkLowerGNStakeThreshold = "lower_gn_stake_threshold"
+	ForkSmartContract         = "smart_contract"
+	ForkSampleStakingReward   = "sample_staking_reward"
+	ForkJune2021FeeAdjustment = "june2021_fee_adjustment"
+	ForkTheta3                = "theta3"
+	ForkParameterChange       = "parameter_change"
+	ForkSubchainAnchoring     = "subchain_anchoring"
+	ForkCrossChainMessaging   = "cross_chain_messaging"
+	ForkValidatorKeyChange    = "validator_key_change"
+	ForkStakeAutoCompounding  = "stake_auto_compounding"
+	ForkRandomnessBeacon      = "randomness_beacon"
+	ForkScheduledTx           = "scheduled_tx"
+	ForkFeeSponsorship        = "fee_sponsorship"
+	ForkDynamicBaseFee        = "dynamic_base_fee"
+)
+
+//
+// ------- ForkConfig ------- //
+//
+
+// ForkConfig maps the name of each fork to its activation height
+type ForkConfig map[string]uint64
+
+// MainnetForkConfig returns the fork schedule of the Mainnet, which is also the default schedule of
+// the other chains
+func MainnetForkConfig() ForkConfig {
+	return ForkConfig{
+		ForkValidatorReward:       common.HeightEnableValidatorReward,
+		ForkTheta2:                common.HeightEnableTheta2,
+		ForkLowerGNStakeThreshold: common.HeightLowerGNStakeThresholdTo1000,
+		ForkSmartContract:         common.HeightEnableSmartContract,
+		ForkSampleStakingReward:   common.HeightSampleStakingReward,
+		ForkJune2021FeeAdjustment: common.HeightJune2021FeeAdjustment,
+		ForkTheta3:                common.HeightEnableTheta3,
+		ForkParameterChange:       common.HeightEnableParameterChange,
+		ForkSubchainAnchoring:     common.HeightEnableSubchainAnchoring,
+		ForkCrossChainMessaging:   common.HeightEnableCrossChainMessaging,
+		ForkValidatorKeyChange:    common.HeightEnableValidatorKeyChange,
+		ForkStakeAutoCompounding:  common.HeightEnableStakeAutoCompounding,
+		ForkRandomnessBeacon:      common.HeightEnableRandomnessBeacon,
+		ForkScheduledTx:           common.HeightEnableScheduledTx,
+		ForkFeeSponsorship:        common.HeightEnableFeeSponsorship,
+		ForkDynamicBaseFee:        common.HeightEnableDynamicBaseFee,
+	}
+}
+
+// Height returns the activation height of the given fork
+func (fc ForkConfig) Height(fork string) uint64 {
+	height, ok := fc[fork]
+	if !ok {
+		panic(fmt.Sprintf("Unknown fork: %v", fork))
+	}
+	return height
+}
+
+// IsActive returns whether the given fork is active at the given block height
+func (fc ForkConfig) IsActive(fork string, height uint64) bool {
+	return height >= fc.Height(fork)
+}
+
+// Names returns the fork names ordered by activation height
+func (fc ForkConfig) Names() []string {
+	names := []string{}
+	for name := range fc {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if fc[names[i]] != fc[names[j]] {
+			return fc[names[i]] < fc[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func (fc ForkConfig) copy() ForkConfig {
+	ret := ForkConfig{}
+	for name, height := range fc {
+		ret[name] = height
+	}
+	return ret
+}
+
+//
+// ------- Fork registry ------- //
+//
+
+var (
+	// forkConfigs maps the chain IDs to their fork schedules. The chains without a loaded schedule
+	// follow the Mainnet schedule.
+	forkConfigs     = map[string]ForkConfig{}
+	forkConfigsLock sync.RWMutex
+
+	mainnetForkConfig = MainnetForkConfig()
+)
+
+// LoadForkConfig sets the fork schedule of the given chain, with the given activation heights overriding
+// the default ones. The Mainnet schedule cannot be overridden. It needs to be called before the node
+// starts processing the blocks of the chain.
+func LoadForkConfig(chainID string, overrides map[string]uint64) error {
+	if len(overrides) > 0 && chainID == MainnetChainID {
+		return fmt.Errorf("the fork schedule of the %v cannot be overridden", MainnetChainID)
+	}
+	return loadForkConfig(chainID, overrides)
+}
+
+// LoadShadowForkConfig is the same as LoadForkConfig except that the Mainnet schedule can be overridden,
+// so that a node in the shadow mode can execute the Mainnet blocks with the forks under development
+func LoadShadowForkConfig(chainID string, overrides map[string]uint64) error {
+	return loadForkConfig(chainID, overrides)
+}
+
+func loadForkConfig(chainID string, overrides map[string]uint64) error {
+	config := MainnetForkConfig()
+	for name, height := range overrides {
+		if _, ok := config[name]; !ok {
+			return fmt.Errorf("unknown fork: %v", name)
+		}
+		config[name] = height
+	}
+
+	forkConfigsLock.Lock()
+	defer forkConfigsLock.Unlock()
+	forkConfigs[chainID] = config
+	return nil
+}
+
+func getForkConfig(chainID string) ForkConfig {
+	forkConfigsLock.RLock()
+	defer forkConfigsLock.RUnlock()
+	if config, ok := forkConfigs[chainID]; ok {
+		return config
+	}
+	return mainnetForkConfig
+}
+
+// GetForkConfig returns a copy of the fork schedule of the given chain
+func GetForkConfig(chainID string) ForkConfig {
+	return getForkConfig(chainID).copy()
+}
+
+// IsForkConfigOverridden returns whether the fork schedule of the given chain differs from the Mainnet schedule
+func IsForkConfigOverridden(chainID string) bool {
+	config := getForkConfig(chainID)
+	if len(config) != len(mainnetForkConfig) {
+		return true
+	}
+	for name, height := range mainnetForkConfig {
+		if config[name] != height {
+			return true
+		}
+	}
+	return false
+}
+
+// IsForkActive returns whether the given fork is active at the given block height of the given chain
+func IsForkActive(chainID string, fork string, height uint64) bool {
+	return getForkConfig(chainID).IsActive(fork, height)
+}
+
+// ForkHeight returns the activation height of the given fork on the given chain
+func ForkHeight(chainID string, fork string) uint64 {
+	return getForkConfig(chainID).Height(fork)
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestForkConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	defer LoadForkConfig("testnet", nil)
+
+	assert.True(IsForkActive(MainnetChainID, ForkTheta3, common.HeightEnableTheta3))
+	assert.False(IsForkActive(MainnetChainID, ForkTheta3, common.HeightEnableTheta3-1))
+
+	// The Mainnet schedule cannot be overridden
+	assert.NotNil(LoadForkConfig(MainnetChainID, map[string]uint64{ForkTheta3: 100}))
+
+	// Unknown forks are rejected
+	assert.NotNil(LoadForkConfig("testnet", map[string]uint64{"unknown": 100}))
+
+	assert.False(IsForkConfigOverridden("testnet"))
+	assert.Nil(LoadForkConfig("testnet", map[string]uint64{ForkTheta3: 100, ForkParameterChange: 200}))
+	assert.True(IsForkConfigOverridden("testnet"))
+	assert.Equal(uint64(100), ForkHeight("testnet", ForkTheta3))
+	assert.True(IsForkActive("testnet", ForkTheta3, 100))
+	assert.False(IsForkActive("testnet", ForkParameterChange, 199))
+	assert.Equal(common.HeightEnableTheta2, ForkHeight("testnet", ForkTheta2))
+
+	names := GetForkConfig("testnet").Names()
+	assert.Equal(ForkTheta3, names[0])
+	assert.Equal(ForkParameterChange, names[1])
+
+	// The schedules of the other chains are not affected
+	assert.False(IsForkConfigOverridden(MainnetChainID))
+	assert.False(IsForkConfigOverridden("privatenet"))
+	assert.Equal(common.HeightEnableTheta3, ForkHeight(MainnetChainID, ForkTheta3))
+	assert.Equal(common.HeightEnableTheta3, ForkHeight("privatenet", ForkTheta3))
+
+	assert.Nil(LoadForkConfig("testnet", nil))
+	assert.Equal(common.HeightEnableTheta3, ForkHeight("testnet", ForkTheta3))
+	assert.False(IsForkConfigOverridden("testnet"))
+}
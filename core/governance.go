@@ -0,0 +1,132 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+)
+
+const (
+	// ParamBlockGasLimit is the name of the governed parameter for the total gas limit of the smart
+	// contract transactions in one block
+	ParamBlockGasLimit = "block_gas_limit"
+
+	// ParamMaxNumTxsPerBlock is the name of the governed parameter for the max number of regular
+	// transactions in one block
+	ParamMaxNumTxsPerBlock = "max_num_txs_per_block"
+)
+
+const (
+	// DefaultBlockGasLimit is the block gas limit before it is changed by the validators
+	DefaultBlockGasLimit uint64 = 200e6
+
+	// MinBlockGasLimit is the minimal block gas limit the validators can set, which is kept above
+	// the max gas limit of a single smart contract transaction
+	MinBlockGasLimit uint64 = 20e6
+
+	// MaxMaxNumTxsPerBlock is the upper bound of the max number of regular transactions per block
+	MaxMaxNumTxsPerBlock uint64 = 10000
+
+	// ParameterChangeDelay is the number of blocks between the approval of a parameter change and
+	// the height the change takes effect
+	ParameterChangeDelay uint64 = 100
+)
+
+//
+// ------- GovernanceParams ------- //
+//
+
+// GovernanceParams contains the on-chain parameters governed by the validators
+type GovernanceParams struct {
+	BlockGasLimit     uint64 `json:"block_gas_limit"`
+	MaxNumTxsPerBlock uint64 `json:"max_num_txs_per_block"`
+}
+
+// NewGovernanceParams creates the governance params with the default values
+func NewGovernanceParams() *GovernanceParams {
+	return &GovernanceParams{
+		BlockGasLimit:     DefaultBlockGasLimit,
+		MaxNumTxsPerBlock: uint64(MaxNumRegularTxsPerBlock),
+	}
+}
+
+// Set sets the value of the given parameter
+func (gp *GovernanceParams) Set(parameter string, value uint64) error {
+	if err := ValidateParameterChange(parameter, value); err != nil {
+		return err
+	}
+
+	switch parameter {
+	case ParamBlockGasLimit:
+		gp.BlockGasLimit = value
+	case ParamMaxNumTxsPerBlock:
+		gp.MaxNumTxsPerBlock = value
+	}
+	return nil
+}
+
+// ValidateParameterChange checks whether the given parameter can be changed to the given value
+func ValidateParameterChange(parameter string, value uint64) error {
+	switch parameter {
+	case ParamBlockGasLimit:
+		if value < MinBlockGasLimit {
+			return fmt.Errorf("block gas limit cannot be less than %v", MinBlockGasLimit)
+		}
+	case ParamMaxNumTxsPerBlock:
+		if value == 0 || value > MaxMaxNumTxsPerBlock {
+			return fmt.Errorf("max number of txs per block needs to be between 1 and %v", MaxMaxNumTxsPerBlock)
+		}
+	default:
+		return fmt.Errorf("unknown governance parameter: %v", parameter)
+	}
+	return nil
+}
+
+//
+// ------- ParameterChange ------- //
+//
+
+// ParameterChange is a proposed change of a governed parameter. The change is approved once the
+// validators with a 2/3 majority of the stake have voted for it, and takes effect at EffectiveHeight.
+type ParameterChange struct {
+	Parameter       string           `json:"parameter"`
+	Value           uint64           `json:"value"`
+	Voters          []common.Address `json:"voters"`
+	EffectiveHeight uint64           `json:"effective_height"` // 0 if not yet approved
+}
+
+// IsApproved returns whether the change has been approved by the validators
+func (pc *ParameterChange) IsApproved() bool {
+	return pc.EffectiveHeight != 0
+}
+
+// HasVoted returns whether the given validator has voted for the change
+func (pc *ParameterChange) HasVoted(voter common.Address) bool {
+	for _, v := range pc.Voters {
+		if v == voter {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveVoter removes the vote of the given validator, and returns whether the vote is found
+func (pc *ParameterChange) RemoveVoter(voter common.Address) bool {
+	for i, v := range pc.Voters {
+		if v == voter {
+			pc.Voters = append(pc.Voters[:i], pc.Voters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// HasMajority checks whether the voters of the change have a 2/3 majority of the stake of the
+// given validator set
+func (pc *ParameterChange) HasMajority(validatorSet *ValidatorSet) bool {
+	votes := []Vote{}
+	for _, voter := range pc.Voters {
+		votes = append(votes, Vote{ID: voter})
+	}
+	return validatorSet.HasMajorityVotes(votes)
+}
@@ -297,12 +297,8 @@ func (gcp *GuardianCandidatePool) Hash() common.Hash {
 	return crypto.Keccak256Hash(raw)
 }
 
-func (gcp *GuardianCandidatePool) DepositStake(source common.Address, holder common.Address, amount *big.Int, pubkey *bls.PublicKey, blockHeight uint64) (err error) {
-	minGuardianStake := MinGuardianStakeDeposit
-	if blockHeight >= common.HeightLowerGNStakeThresholdTo1000 {
-		minGuardianStake = MinGuardianStakeDeposit1000
-	}
-	if amount.Cmp(minGuardianStake) < 0 {
+func (gcp *GuardianCandidatePool) DepositStake(source common.Address, holder common.Address, amount *big.Int, pubkey *bls.PublicKey, chainID string, blockHeight uint64) (err error) {
+	if amount.Cmp(MinGuardianStake(chainID, blockHeight)) < 0 {
 		return fmt.Errorf("Insufficient stake: %v", amount)
 	}
 
@@ -174,3 +174,19 @@ func (sh *StakeHolder) returnStake(source common.Address, currentHeight uint64)
 func (sh *StakeHolder) String() string {
 	return fmt.Sprintf("{holder: %v, stakes :%v}", sh.Holder, sh.Stakes)
 }
+
+//
+// ------- StakeAutoCompounding ------- //
+//
+
+// StakeAutoCompounding redirects the TFuel rewards of a stake source into its stake to an elite edge node
+// at distribution time. As the Theta stakes cannot grow with TFuel, the rewards of the guardian stakes of
+// the source are compounded into the elite edge node stake as well.
+type StakeAutoCompounding struct {
+	Source common.Address `json:"source"`
+	Holder common.Address `json:"holder"` // the elite edge node the rewards are staked to
+}
+
+func (sac *StakeAutoCompounding) String() string {
+	return fmt.Sprintf("{source: %v, holder: %v}", sac.Source, sac.Holder)
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// MaxSubchainIDLength is the max length of the ID of a subchain anchored to the main chain
+const MaxSubchainIDLength = 64
+
+//
+// ------- Subchain ------- //
+//
+
+// Subchain is a chain which anchors its finalized blocks to the main chain. A subchain is registered
+// with its operator by the validators of the main chain, and only the operator can submit its checkpoints.
+type Subchain struct {
+	SubchainID   string         `json:"subchain_id"`
+	Operator     common.Address `json:"operator"`
+	LatestHeight uint64         `json:"latest_height"` // height of the latest anchored subchain block
+	NumAnchors   uint64         `json:"num_anchors"`   // number of the anchored checkpoints
+}
+
+func (sc *Subchain) String() string {
+	return fmt.Sprintf("Subchain{id: %v, operator: %v, latest_height: %v, num_anchors: %v}",
+		sc.SubchainID, sc.Operator, sc.LatestHeight, sc.NumAnchors)
+}
+
+//
+// ------- SubchainRegistration ------- //
+//
+
+// SubchainRegistration is a pending registration of a subchain with the given operator. The subchain is
+// registered, or its operator replaced, once the validators with a 2/3 majority of the stake have voted
+// for the registration.
+type SubchainRegistration struct {
+	SubchainID string           `json:"subchain_id"`
+	Operator   common.Address   `json:"operator"`
+	Voters     []common.Address `json:"voters"`
+}
+
+// HasVoted returns whether the given validator has voted for the registration
+func (sr *SubchainRegistration) HasVoted(voter common.Address) bool {
+	for _, v := range sr.Voters {
+		if v == voter {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveVoter removes the vote of the given validator, and returns whether the vote is found
+func (sr *SubchainRegistration) RemoveVoter(voter common.Address) bool {
+	for i, v := range sr.Voters {
+		if v == voter {
+			sr.Voters = append(sr.Voters[:i], sr.Voters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// HasMajority checks whether the voters of the registration have a 2/3 majority of the stake of the
+// given validator set
+func (sr *SubchainRegistration) HasMajority(validatorSet *ValidatorSet) bool {
+	votes := []Vote{}
+	for _, voter := range sr.Voters {
+		votes = append(votes, Vote{ID: voter})
+	}
+	return validatorSet.HasMajorityVotes(votes)
+}
+
+func (sr *SubchainRegistration) String() string {
+	return fmt.Sprintf("SubchainRegistration{id: %v, operator: %v, voters: %v}",
+		sr.SubchainID, sr.Operator, sr.Voters)
+}
+
+//
+// ------- SubchainCheckpoint ------- //
+//
+
+// SubchainCheckpoint is the commitment of a finalized subchain block stored in the main chain state
+type SubchainCheckpoint struct {
+	SubchainID   string         `json:"subchain_id"`
+	Height       uint64         `json:"height"`     // height of the subchain block
+	BlockHash    common.Hash    `json:"block_hash"` // hash of the subchain block
+	StateHash    common.Hash    `json:"state_hash"` // state root of the subchain block
+	Submitter    common.Address `json:"submitter"`
+	AnchorHeight uint64         `json:"anchor_height"` // height of the main chain block including the checkpoint
+}
+
+func (cp *SubchainCheckpoint) String() string {
+	return fmt.Sprintf("SubchainCheckpoint{subchain_id: %v, height: %v, block_hash: %v, state_hash: %v, anchor_height: %v}",
+		cp.SubchainID, cp.Height, cp.BlockHash.Hex(), cp.StateHash.Hex(), cp.AnchorHeight)
+}
+
+// ValidateSubchainID checks whether the given ID can be used as a subchain ID by the main chain with the
+// given chain ID
+func ValidateSubchainID(subchainID string, chainID string) error {
+	if len(subchainID) == 0 || len(subchainID) > MaxSubchainIDLength {
+		return fmt.Errorf("subchain ID needs to have 1 to %v characters", MaxSubchainIDLength)
+	}
+	if subchainID == chainID {
+		return fmt.Errorf("subchain ID cannot be the same as the main chain ID")
+	}
+	for _, c := range subchainID {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.') {
+			return fmt.Errorf("subchain ID can only contain letters, digits, '_', '-' and '.'")
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -164,6 +165,24 @@ func (s *ValidatorSet) Validators() []Validator {
 	return s.validators
 }
 
+// MarshalJSON implements json.Marshaler
+func (s *ValidatorSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Validators())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *ValidatorSet) UnmarshalJSON(b []byte) error {
+	validators := []Validator{}
+	if err := json.Unmarshal(b, &validators); err != nil {
+		return err
+	}
+	s.validators = []Validator{}
+	for _, v := range validators {
+		s.AddValidator(v)
+	}
+	return nil
+}
+
 //
 // ------- ValidatorCandidatePool ------- //
 //
@@ -198,8 +217,8 @@ func (vcp *ValidatorCandidatePool) GetTopStakeHolders(maxNumStakeHolders int) []
 	return vcp.SortedCandidates[:n]
 }
 
-func (vcp *ValidatorCandidatePool) DepositStake(source common.Address, holder common.Address, amount *big.Int) (err error) {
-	if amount.Cmp(MinValidatorStakeDeposit) < 0 {
+func (vcp *ValidatorCandidatePool) DepositStake(source common.Address, holder common.Address, amount *big.Int, chainID string) (err error) {
+	if amount.Cmp(MinValidatorStake(chainID)) < 0 {
 		return fmt.Errorf("Insufficient stake: %v", amount)
 	}
 
@@ -279,6 +298,30 @@ func (vcp *ValidatorCandidatePool) ReturnStakes(currentHeight uint64) []*Stake {
 	return returnedStakes
 }
 
+// ChangeHolder moves the stakes of the candidate to the new holder address. If the new source is not
+// empty, the stakes are also returned to the new source when withdrawn.
+func (vcp *ValidatorCandidatePool) ChangeHolder(holder common.Address, newHolder common.Address, newSource common.Address) error {
+	if vcp.FindStakeDelegate(newHolder) != nil {
+		return fmt.Errorf("New holder %v is already a validator candidate", newHolder)
+	}
+	candidate := vcp.FindStakeDelegate(holder)
+	if candidate == nil {
+		return fmt.Errorf("No matched stake holder address found: %v", holder)
+	}
+
+	candidate.Holder = newHolder
+	for _, stake := range candidate.Stakes {
+		stake.Holder = newHolder
+		if !newSource.IsEmpty() {
+			stake.Source = newSource
+		}
+	}
+
+	vcp.sortCandidates()
+
+	return nil
+}
+
 func (vcp *ValidatorCandidatePool) sortCandidates() {
 	sort.Slice(vcp.SortedCandidates[:], func(i, j int) bool { // descending order in (totalStake, holderAddress)
 		stakeCmp := vcp.SortedCandidates[i].TotalStake().Cmp(vcp.SortedCandidates[j].TotalStake())
@@ -295,3 +338,26 @@ func (vcp *ValidatorCandidatePool) sortCandidates() {
 // 		return stakeCmp >= 0
 // 	})
 // }
+
+//
+// ------- ValidatorKeyChange ------- //
+//
+
+// ValidatorKeyChangeDelay is the number of blocks between a validator key change transaction and the
+// height the change takes effect, approximately 1 day with 6 second block time. It gives the operator
+// time to notice and react to a change submitted with a leaked key.
+const ValidatorKeyChangeDelay uint64 = 14400
+
+// ValidatorKeyChange is a pending change of the holder address, i.e. the signing key, of a validator
+// candidate, and optionally of the address its stakes are returned to
+type ValidatorKeyChange struct {
+	Holder          common.Address `json:"holder"`
+	NewHolder       common.Address `json:"new_holder"`
+	NewSource       common.Address `json:"new_source"` // empty if the stake source is not changed
+	EffectiveHeight uint64         `json:"effective_height"`
+}
+
+func (vkc *ValidatorKeyChange) String() string {
+	return fmt.Sprintf("ValidatorKeyChange{holder: %v, new_holder: %v, new_source: %v, effective_height: %v}",
+		vkc.Holder, vkc.NewHolder, vkc.NewSource, vkc.EffectiveHeight)
+}
@@ -137,21 +137,21 @@ func TestValidatorCandidatePool(t *testing.T) {
 	log.Infof("--------------------------------------------------------")
 	log.Infof("")
 
-	assert.Nil(vcp.DepositStake(sourceAddr1, holderAddr1, stake1Amount1))
-	assert.Nil(vcp.DepositStake(sourceAddr2, holderAddr1, stake2Amount1))
-	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr1, stake3Amount2))
+	assert.Nil(vcp.DepositStake(sourceAddr1, holderAddr1, stake1Amount1, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr2, holderAddr1, stake2Amount1, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr1, stake3Amount2, "testnet"))
 
-	assert.Nil(vcp.DepositStake(sourceAddr1, holderAddr2, stake1Amount2))
-	assert.Nil(vcp.DepositStake(sourceAddr2, holderAddr2, stake2Amount2))
-	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr2, stake3Amount2))
+	assert.Nil(vcp.DepositStake(sourceAddr1, holderAddr2, stake1Amount2, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr2, holderAddr2, stake2Amount2, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr2, stake3Amount2, "testnet"))
 
-	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr3, stake3Amount1))
+	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr3, stake3Amount1, "testnet"))
 
-	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr4, stake3Amount3))
-	assert.Nil(vcp.DepositStake(sourceAddr4, holderAddr4, stake4Amount1))
+	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr4, stake3Amount3, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr4, holderAddr4, stake4Amount1, "testnet"))
 
-	assert.NotNil(vcp.DepositStake(sourceAddr4, holderAddr2, invalidStakeAmount))
-	assert.NotNil(vcp.DepositStake(sourceAddr3, holderAddr6, insufficientStakeAmount))
+	assert.NotNil(vcp.DepositStake(sourceAddr4, holderAddr2, invalidStakeAmount, "testnet"))
+	assert.NotNil(vcp.DepositStake(sourceAddr3, holderAddr6, insufficientStakeAmount, "testnet"))
 
 	assert.True(len(vcp.SortedCandidates) == 4)
 	assert.True(vcp.SortedCandidates[0].TotalStake().Cmp(new(big.Int).Mul(new(big.Int).SetUint64(13200), MinValidatorStakeDeposit)) == 0)
@@ -198,14 +198,14 @@ func TestValidatorCandidatePool(t *testing.T) {
 	log.Infof("--------------------------------------------------------")
 	log.Infof("")
 
-	assert.Nil(vcp.DepositStake(sourceAddr5, holderAddr5, stake5Amount1))
-	assert.Nil(vcp.DepositStake(sourceAddr5, holderAddr5, stake5Amount2))
+	assert.Nil(vcp.DepositStake(sourceAddr5, holderAddr5, stake5Amount1, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr5, holderAddr5, stake5Amount2, "testnet"))
 
-	assert.Nil(vcp.DepositStake(sourceAddr6, holderAddr6, stake6Amount1))
-	assert.Nil(vcp.DepositStake(sourceAddr6, holderAddr6, stake6Amount2))
-	assert.Nil(vcp.DepositStake(sourceAddr6, holderAddr6, stake6Amount3))
-	assert.Nil(vcp.DepositStake(sourceAddr6, holderAddr6, stake6Amount4))
-	assert.Nil(vcp.DepositStake(sourceAddr6, holderAddr6, stake6Amount5))
+	assert.Nil(vcp.DepositStake(sourceAddr6, holderAddr6, stake6Amount1, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr6, holderAddr6, stake6Amount2, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr6, holderAddr6, stake6Amount3, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr6, holderAddr6, stake6Amount4, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr6, holderAddr6, stake6Amount5, "testnet"))
 
 	checkAndPrintAllSortedCandidates(t, assert, vcp)
 	checkAndPrintTopCandidates(t, assert, vcp, 3)
@@ -220,8 +220,8 @@ func TestValidatorCandidatePool(t *testing.T) {
 
 	assert.NotNil(vcp.WithdrawStake(sourceAddr5, holderAddr6, height2)) // sourceAddr5 never deposited to holderAddr6, so cannot withraw from holderAddr6
 	assert.Nil(vcp.WithdrawStake(sourceAddr6, holderAddr6, height2))
-	assert.NotNil(vcp.DepositStake(sourceAddr6, holderAddr6, stake6Amount2)) // cannot deposit during the withdrawal locking period
-	assert.True(len(vcp.SortedCandidates) == 6)                              // holderAddr6's stake not returned yet, should it should still be in the candidate list
+	assert.NotNil(vcp.DepositStake(sourceAddr6, holderAddr6, stake6Amount2, "testnet")) // cannot deposit during the withdrawal locking period
+	assert.True(len(vcp.SortedCandidates) == 6)                                         // holderAddr6's stake not returned yet, should it should still be in the candidate list
 	assert.True(vcp.SortedCandidates[5].Holder == holderAddr6)
 	assert.True(vcp.SortedCandidates[5].TotalStake().Cmp(Zero) == 0) // All stakes are withdrawn
 	checkAndPrintAllSortedCandidates(t, assert, vcp)
@@ -277,7 +277,7 @@ func TestValidatorCandidatePool(t *testing.T) {
 
 	assert.Nil(vcp.WithdrawStake(sourceAddr1, holderAddr1, height6))
 	assert.Nil(vcp.WithdrawStake(sourceAddr2, holderAddr1, height6))
-	assert.NotNil(vcp.DepositStake(sourceAddr2, holderAddr1, stake2Amount2)) // cannot deposit during the withdrawal locking period
+	assert.NotNil(vcp.DepositStake(sourceAddr2, holderAddr1, stake2Amount2, "testnet")) // cannot deposit during the withdrawal locking period
 	assert.True(len(vcp.SortedCandidates) == 4)
 	assert.True(len(vcp.SortedCandidates[3].Stakes) == 3)
 	assert.True(vcp.SortedCandidates[3].TotalStake().Cmp(stake3Amount2) == 0) // Both sourceAddr1 and sourceAddr2 have withdrawn, only sourceAddr3's deposited stake is still effective
@@ -302,6 +302,41 @@ func TestValidatorCandidatePool(t *testing.T) {
 	checkAndPrintTopCandidates(t, assert, vcp, 3)
 }
 
+func TestValidatorCandidatePoolChangeHolder(t *testing.T) {
+	assert := assert.New(t)
+
+	ten18 := new(big.Int).SetUint64(1000000000000000000) // 10^18
+	stakeAmount := new(big.Int).Mul(new(big.Int).SetUint64(10000000), ten18)
+
+	sourceAddr := common.HexToAddress("0x111")
+	holderAddr1 := common.HexToAddress("0x222")
+	holderAddr2 := common.HexToAddress("0x333")
+	newHolderAddr := common.HexToAddress("0x444")
+	newSourceAddr := common.HexToAddress("0x555")
+
+	vcp := &ValidatorCandidatePool{}
+	assert.Nil(vcp.DepositStake(sourceAddr, holderAddr1, stakeAmount, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr, holderAddr2, stakeAmount, "testnet"))
+	assert.Nil(vcp.WithdrawStake(sourceAddr, holderAddr2, 100))
+
+	assert.NotNil(vcp.ChangeHolder(holderAddr1, holderAddr2, common.Address{}))
+	assert.NotNil(vcp.ChangeHolder(newHolderAddr, newSourceAddr, common.Address{}))
+
+	assert.Nil(vcp.ChangeHolder(holderAddr1, newHolderAddr, newSourceAddr))
+	assert.Nil(vcp.FindStakeDelegate(holderAddr1))
+	candidate := vcp.FindStakeDelegate(newHolderAddr)
+	assert.NotNil(candidate)
+	assert.Equal(stakeAmount, candidate.TotalStake())
+	assert.Equal(newSourceAddr, candidate.Stakes[0].Source)
+
+	// The withdrawn stakes are returned to the source as usual after the holder change
+	assert.Nil(vcp.ChangeHolder(holderAddr2, holderAddr1, common.Address{}))
+	returned := vcp.ReturnStakes(100 + ReturnLockingPeriod)
+	assert.Equal(1, len(returned))
+	assert.Equal(sourceAddr, returned[0].Source)
+	assert.Nil(vcp.FindStakeDelegate(holderAddr1))
+}
+
 func TestValidatorSetUniqueSortedOrder(t *testing.T) {
 	assert := assert.New(t)
 
@@ -323,12 +358,12 @@ func TestValidatorSetUniqueSortedOrder(t *testing.T) {
 	holderAddr6 := common.HexToAddress("0x666")
 
 	vcp := &ValidatorCandidatePool{}
-	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr3, stakeAmountA))
-	assert.Nil(vcp.DepositStake(sourceAddr1, holderAddr1, stakeAmountA))
-	assert.Nil(vcp.DepositStake(sourceAddr5, holderAddr5, stakeAmountB))
-	assert.Nil(vcp.DepositStake(sourceAddr2, holderAddr2, stakeAmountA))
-	assert.Nil(vcp.DepositStake(sourceAddr6, holderAddr6, stakeAmountB))
-	assert.Nil(vcp.DepositStake(sourceAddr4, holderAddr4, stakeAmountA))
+	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr3, stakeAmountA, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr1, holderAddr1, stakeAmountA, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr5, holderAddr5, stakeAmountB, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr2, holderAddr2, stakeAmountA, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr6, holderAddr6, stakeAmountB, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr4, holderAddr4, stakeAmountA, "testnet"))
 
 	vcp.sortCandidates()
 	vcpJson1, _ := json.MarshalIndent(vcp, "", "  ")
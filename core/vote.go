@@ -7,6 +7,7 @@ import (
 	"io"
 	"sort"
 
+	lru "github.com/hashicorp/golang-lru"
 	log "github.com/sirupsen/logrus"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/result"
@@ -119,12 +120,38 @@ func (v Vote) Validate() result.Result {
 	if v.Signature == nil || v.Signature.IsEmpty() {
 		return result.Error("Vote is not signed")
 	}
+	// The same votes are checked repeatedly, e.g. in the HCC of the blocks and by the sync manager, so the
+	// votes already verified are remembered by their hash, which covers the signature.
+	hash := v.Hash()
+	if verifiedVotes.Contains(hash) || trustedVotes.Contains(hash) {
+		return result.OK
+	}
 	if !v.Signature.Verify(v.SignBytes(), v.ID) {
 		return result.Error("Signature verification failed")
 	}
+	verifiedVotes.Add(hash, struct{}{})
 	return result.OK
 }
 
+// maxVerifiedVotes is the maximum number of the verified and the trusted votes remembered
+const maxVerifiedVotes = 100000
+
+var (
+	verifiedVotes, _ = lru.New(maxVerifiedVotes)
+	trustedVotes, _  = lru.New(maxVerifiedVotes)
+)
+
+// TrustVote marks the given vote as valid without verifying its signature, e.g. when its block is certified by
+// a trusted checkpoint hash.
+func TrustVote(v Vote) {
+	trustedVotes.Add(v.Hash(), struct{}{})
+}
+
+// ClearTrustedVotes forgets the votes marked as valid by TrustVote, so that their signatures get verified.
+func ClearTrustedVotes() {
+	trustedVotes.Purge()
+}
+
 // Hash calculates vote's hash.
 func (v Vote) Hash() common.Hash {
 	raw, _ := rlp.EncodeToBytes(v)
@@ -259,3 +259,27 @@ func TestCommitCertificate(t *testing.T) {
 	cc = CommitCertificate{Votes: invalidVoteSet, BlockHash: blockHash}
 	assert.False(cc.IsValid(vs))
 }
+
+func TestTrustedVotes(t *testing.T) {
+	assert := assert.New(t)
+
+	privKey, _, _ := crypto.GenerateKeyPair()
+	otherKey, _, _ := crypto.GenerateKeyPair()
+
+	// A vote signed by another key
+	vote := Vote{
+		Block: CreateTestBlock("", "").Hash(),
+		ID:    privKey.PublicKey().Address(),
+		Epoch: 1,
+	}
+	sig, err := otherKey.Sign(vote.SignBytes())
+	assert.Nil(err)
+	vote.SetSignature(sig)
+	assert.True(vote.Validate().IsError())
+
+	TrustVote(vote)
+	assert.True(vote.Validate().IsOK())
+
+	ClearTrustedVotes()
+	assert.True(vote.Validate().IsError())
+}
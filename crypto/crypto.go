@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"io"
 	"math/big"
+	"sync/atomic"
+	"time"
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/hexutil"
@@ -245,6 +247,9 @@ func (sig *Signature) Verify(msg common.Bytes, addr common.Address) bool {
 	if sig == nil || sig.IsEmpty() {
 		return false
 	}
+	if atomic.LoadInt32(&verifyStatsEnabled) == 1 {
+		defer recordVerification(time.Now())
+	}
 	recoveredAddress, err := sig.RecoverSignerAddress(msg)
 	if err != nil {
 		return false
@@ -0,0 +1,40 @@
+package crypto
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// The signature verification statistics are only collected when enabled, e.g. by the import benchmark,
+// so that the verifications on the hot path of a running node are not timed.
+var (
+	verifyStatsEnabled int32
+	numVerifications   uint64
+	verificationNanos  int64
+)
+
+// EnableVerificationStats turns on or off the collection of the signature verification statistics
+func EnableVerificationStats(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&verifyStatsEnabled, 1)
+	} else {
+		atomic.StoreInt32(&verifyStatsEnabled, 0)
+	}
+}
+
+// GetVerificationStats returns the number of the signatures verified and the total time spent since the
+// last reset
+func GetVerificationStats() (count uint64, elapsed time.Duration) {
+	return atomic.LoadUint64(&numVerifications), time.Duration(atomic.LoadInt64(&verificationNanos))
+}
+
+// ResetVerificationStats clears the signature verification statistics
+func ResetVerificationStats() {
+	atomic.StoreUint64(&numVerifications, 0)
+	atomic.StoreInt64(&verificationNanos, 0)
+}
+
+func recordVerification(start time.Time) {
+	atomic.AddUint64(&numVerifications, 1)
+	atomic.AddInt64(&verificationNanos, int64(time.Since(start)))
+}
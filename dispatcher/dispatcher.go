@@ -2,8 +2,10 @@ package dispatcher
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -124,6 +126,11 @@ func (dp *Dispatcher) SendData(peerIDs []string, datarsp DataResponse) {
 	}
 }
 
+// SendDataToNeighbors sends out the DataResponse to at most maxNumPeers randomly sampled neighbors
+func (dp *Dispatcher) SendDataToNeighbors(datarsp DataResponse, maxNumPeers int, skipEdgeNode bool) {
+	dp.broadcastToSampledNeighbors(datarsp.ChannelID, datarsp, maxNumPeers, skipEdgeNode)
+}
+
 // ID returns the ID of the node
 func (dp Dispatcher) ID() string {
 	if !reflect.ValueOf(dp.p2pnet).IsNil() {
@@ -169,6 +176,25 @@ func (dp *Dispatcher) PeerURLs(skipEdgeNode bool) []string {
 	return []string{}
 }
 
+// PeerCapabilities returns the capabilities advertised by the peers
+func (dp *Dispatcher) PeerCapabilities(skipEdgeNode bool) map[string]*p2ptypes.NodeCapabilities {
+	if !reflect.ValueOf(dp.p2pnet).IsNil() {
+		return dp.p2pnet.PeerCapabilities(skipEdgeNode)
+	}
+	if !reflect.ValueOf(dp.p2plnet).IsNil() {
+		return dp.p2plnet.PeerCapabilities(skipEdgeNode)
+	}
+	return map[string]*p2ptypes.NodeCapabilities{}
+}
+
+// ProbeTopology asks the peers for their neighbors. The probe is not supported over libp2p
+func (dp *Dispatcher) ProbeTopology(timeout time.Duration) (map[string][]p2ptypes.TopologyNeighbor, error) {
+	if !reflect.ValueOf(dp.p2pnet).IsNil() {
+		return dp.p2pnet.ProbeTopology(timeout)
+	}
+	return nil, errors.New("the topology probe is not supported by the network")
+}
+
 // PeerExists indicates if the given peerID is a neighboring peer
 func (dp *Dispatcher) PeerExists(peerID string) bool {
 	if !reflect.ValueOf(dp.p2pnet).IsNil() {
@@ -227,6 +253,12 @@ func (dp *Dispatcher) broadcastToAll(channelID common.ChannelIDEnum, content int
 
 // broadcastToNeighbors delivers given message to all neighbors.
 func (dp *Dispatcher) broadcastToNeighbors(channelID common.ChannelIDEnum, content interface{}, skipEdgeNode bool) {
+	maxNumPeersToBroadcast := viper.GetInt(common.CfgP2PMaxNumPeersToBroadcast)
+	dp.broadcastToSampledNeighbors(channelID, content, maxNumPeersToBroadcast, skipEdgeNode)
+}
+
+// broadcastToSampledNeighbors delivers given message to at most maxNumPeersToBroadcast neighbors.
+func (dp *Dispatcher) broadcastToSampledNeighbors(channelID common.ChannelIDEnum, content interface{}, maxNumPeersToBroadcast int, skipEdgeNode bool) {
 	messageOld := p2ptypes.Message{
 		ChannelID: channelID,
 		Content:   content,
@@ -235,7 +267,6 @@ func (dp *Dispatcher) broadcastToNeighbors(channelID common.ChannelIDEnum, conte
 		ChannelID: channelID,
 		Content:   content,
 	}
-	maxNumPeersToBroadcast := viper.GetInt(common.CfgP2PMaxNumPeersToBroadcast)
 	if !reflect.ValueOf(dp.p2pnet).IsNil() {
 		//dp.p2pnet.Broadcast(messageOld)
 		dp.p2pnet.BroadcastToNeighbors(messageOld, maxNumPeersToBroadcast, skipEdgeNode)
@@ -7,6 +7,26 @@ import (
 // MaxInventorySize defines the max number of items in InventoryRequest/InventoryResponse.
 const MaxInventorySize = 50
 
+// MaxInventoryStarts defines the max number of starting hashes in InventoryRequest. The starting
+// hashes back off exponentially, so the limit is only reached by malformed requests.
+const MaxInventoryStarts = 256
+
+// maxHashStringLength is the length of the hex string of a hash with the 0x prefix.
+const maxHashStringLength = 2 + 2*common.HashLength
+
+// MessageError is returned for the malformed messages
+type MessageError string
+
+func (e MessageError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrNoEntries      = MessageError("No entries in the message")
+	ErrTooManyEntries = MessageError("Too many entries in the message")
+	ErrInvalidHash    = MessageError("Invalid hash string in the message")
+)
+
 // InventoryRequest defines the structure of the inventory request
 type InventoryRequest struct {
 	ChannelID common.ChannelIDEnum
@@ -31,3 +51,45 @@ type DataResponse struct {
 	ChannelID common.ChannelIDEnum
 	Payload   common.Bytes
 }
+
+// Validate checks the size and structure of the request
+func (req *InventoryRequest) Validate() error {
+	if len(req.Starts) == 0 {
+		return ErrNoEntries
+	}
+	if len(req.Starts) > MaxInventoryStarts {
+		return ErrTooManyEntries
+	}
+	if len(req.End) > maxHashStringLength {
+		return ErrInvalidHash
+	}
+	return validateHashStrings(req.Starts)
+}
+
+// Validate checks the size and structure of the response
+func (resp *InventoryResponse) Validate() error {
+	if len(resp.Entries) > MaxInventorySize {
+		return ErrTooManyEntries
+	}
+	return validateHashStrings(resp.Entries)
+}
+
+// Validate checks the size and structure of the request
+func (req *DataRequest) Validate() error {
+	if len(req.Entries) == 0 {
+		return ErrNoEntries
+	}
+	if len(req.Entries) > MaxInventorySize {
+		return ErrTooManyEntries
+	}
+	return validateHashStrings(req.Entries)
+}
+
+func validateHashStrings(hashStrs []string) error {
+	for _, hashStr := range hashStrs {
+		if len(hashStr) > maxHashStringLength {
+			return ErrInvalidHash
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/snapshot"
+	"github.com/thetatoken/theta/store/database/backend"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+func handleError(err error) {
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: export_stakes -config=<path_to_config_home> -start=<start_height> -end=<end_height> -output=<path_to_output_file>")
+}
+
+//
+// Example:
+// export_stakes -config=../privatenet/node -start=1 -end=100000 -output=./theta_stakes-1-100000.jsonl
+//
+func main() {
+	configPathPtr := flag.String("config", "", "path to theta config home")
+	startPtr := flag.Uint64("start", 1, "height to start the export from")
+	endPtr := flag.Uint64("end", 0, "height to end the export at, inclusive")
+	outputPathPtr := flag.String("output", "", "path to the output file")
+	flag.Parse()
+	configPath := *configPathPtr
+	start := *startPtr
+	end := *endPtr
+	outputPath := *outputPathPtr
+	if end < start {
+		handleError(fmt.Errorf("the end height %v is below the start height %v", end, start))
+	}
+	if outputPath == "" {
+		outputPath = path.Join(configPath, fmt.Sprintf("theta_stakes-%v-%v.jsonl", start, end))
+	}
+
+	mainDBPath := path.Join(configPath, "db", "main")
+	refDBPath := path.Join(configPath, "db", "ref")
+	db, err := backend.NewLDBDatabase(mainDBPath, refDBPath, 256, 0)
+	handleError(err)
+	defer db.Close()
+
+	root := core.NewBlock()
+	store := kvstore.NewKVStore(db)
+	chain := blockchain.NewChain(root.ChainID, store, root)
+
+	file, err := os.Create(outputPath)
+	handleError(err)
+	defer file.Close()
+
+	skippedHeights, err := snapshot.ExportStakeCompositions(db, chain, start, end, file)
+	handleError(err)
+
+	fmt.Printf("Exported the stake composition of the checkpoints between height %v and %v\n", start, end)
+	if len(skippedHeights) > 0 {
+		fmt.Printf("Skipped %v checkpoints without a finalized block or state: %v\n", len(skippedHeights), skippedHeights)
+	}
+	fmt.Printf("Output file: %v\n", outputPath)
+}
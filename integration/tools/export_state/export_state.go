@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/snapshot"
+	"github.com/thetatoken/theta/store/database/backend"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+func handleError(err error) {
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: export_state -config=<path_to_config_home> -height=<height> -output=<path_to_output_file>")
+}
+
+//
+// Example:
+// export_state -config=../privatenet/node -height=1000 -output=./theta_state-1000.json
+//
+func main() {
+	configPathPtr := flag.String("config", "", "path to theta config home")
+	heightPtr := flag.Uint64("height", 0, "height of the finalized block to export the state")
+	outputPathPtr := flag.String("output", "", "path to the output file")
+	flag.Parse()
+	configPath := *configPathPtr
+	height := *heightPtr
+	outputPath := *outputPathPtr
+	if outputPath == "" {
+		outputPath = path.Join(configPath, fmt.Sprintf("theta_state-%v.json", height))
+	}
+
+	mainDBPath := path.Join(configPath, "db", "main")
+	refDBPath := path.Join(configPath, "db", "ref")
+	db, err := backend.NewLDBDatabase(mainDBPath, refDBPath, 256, 0)
+	handleError(err)
+	defer db.Close()
+
+	root := core.NewBlock()
+	store := kvstore.NewKVStore(db)
+	chain := blockchain.NewChain(root.ChainID, store, root)
+
+	file, err := os.Create(outputPath)
+	handleError(err)
+	defer file.Close()
+
+	header, err := snapshot.ExportState(db, chain, height, file)
+	handleError(err)
+
+	fmt.Printf("Exported the state of height %v, state hash: %v\n", height, header.StateHash.Hex())
+	fmt.Printf("Output file: %v\n", outputPath)
+}
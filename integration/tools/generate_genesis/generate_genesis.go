@@ -41,9 +41,14 @@ type StakeDeposit struct {
 // generate_genesis -chainID=privatenet -erc20snapshot=./data/genesis_theta_erc20_snapshot.json -stake_deposit=./data/genesis_stake_deposit.json -genesis=./genesis
 //
 func main() {
-	chainID, erc20SnapshotJSONFilePath, stakeDepositFilePath, genesisSnapshotFilePath := parseArguments()
+	chainID, erc20SnapshotJSONFilePath, stakeDepositFilePath, chainParamsFilePath, genesisSnapshotFilePath := parseArguments()
 
-	sv, metadata, err := generateGenesisSnapshot(chainID, erc20SnapshotJSONFilePath, stakeDepositFilePath)
+	chainParams := core.ChainParams{}
+	if chainParamsFilePath != "" {
+		chainParams = loadChainParams(chainID, chainParamsFilePath)
+	}
+
+	sv, metadata, err := generateGenesisSnapshot(chainID, erc20SnapshotJSONFilePath, stakeDepositFilePath, chainParams)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to generate genesis snapshot: %v", err))
 	}
@@ -70,28 +75,52 @@ func main() {
 	fmt.Println("")
 }
 
-func parseArguments() (chainID, erc20SnapshotJSONFilePath, stakeDepositFilePath, genesisSnapshotFilePath string) {
+func parseArguments() (chainID, erc20SnapshotJSONFilePath, stakeDepositFilePath, chainParamsFilePath, genesisSnapshotFilePath string) {
 	chainIDPtr := flag.String("chainID", "local_chain", "the ID of the chain")
 	erc20SnapshotJSONFilePathPtr := flag.String("erc20snapshot", "./theta_erc20_snapshot.json", "the json file contain the ERC20 balance snapshot")
 	stakeDepositFilePathPtr := flag.String("stake_deposit", "./stake_deposit.json", "the initial stake deposits")
+	chainParamsFilePathPtr := flag.String("chain_params", "", "the json file of the chain parameters, which are committed to the genesis state (optional)")
 	genesisSnapshotFilePathPtr := flag.String("genesis", "./genesis", "the genesis snapshot")
 	flag.Parse()
 
 	chainID = *chainIDPtr
 	erc20SnapshotJSONFilePath = *erc20SnapshotJSONFilePathPtr
 	stakeDepositFilePath = *stakeDepositFilePathPtr
+	chainParamsFilePath = *chainParamsFilePathPtr
 	genesisSnapshotFilePath = *genesisSnapshotFilePathPtr
 
 	return
 }
 
-// generateGenesisSnapshot generates the genesis snapshot.
-func generateGenesisSnapshot(chainID, erc20SnapshotJSONFilePath, stakeDepositFilePath string) (*state.StoreView, *core.SnapshotMetadata, error) {
+// loadChainParams loads the chain parameters, which the initial stake deposits are checked against
+func loadChainParams(chainID, chainParamsFilePath string) core.ChainParams {
+	chainParamsByteValue, err := ioutil.ReadFile(chainParamsFilePath)
+	if err != nil {
+		panic(fmt.Sprintf("failed to read the chain parameters: %v", err))
+	}
+	var chainParams map[string]string
+	if err := json.Unmarshal(chainParamsByteValue, &chainParams); err != nil {
+		panic(fmt.Sprintf("failed to parse the chain parameters: %v", err))
+	}
+	params, err := core.ParseChainParams(chainParams)
+	if err != nil {
+		panic(fmt.Sprintf("invalid chain parameters: %v", err))
+	}
+	if err := core.LoadChainParams(chainID, params); err != nil {
+		panic(fmt.Sprintf("invalid chain parameters: %v", err))
+	}
+	return params
+}
+
+// generateGenesisSnapshot generates the genesis snapshot. The chain parameters are written to the genesis
+// state, from which the nodes load them.
+func generateGenesisSnapshot(chainID, erc20SnapshotJSONFilePath, stakeDepositFilePath string, chainParams core.ChainParams) (*state.StoreView, *core.SnapshotMetadata, error) {
 	metadata := &core.SnapshotMetadata{}
 	genesisHeight := core.GenesisBlockHeight
 
 	sv := loadInitialBalances(erc20SnapshotJSONFilePath)
-	performInitialStakeDeposit(stakeDepositFilePath, genesisHeight, sv)
+	sv.SetChainParams(chainParams)
+	performInitialStakeDeposit(chainID, stakeDepositFilePath, genesisHeight, sv)
 
 	stateHash := sv.Hash()
 
@@ -156,7 +185,7 @@ func loadInitialBalances(erc20SnapshotJSONFilePath string) *state.StoreView {
 	return sv
 }
 
-func performInitialStakeDeposit(stakeDepositFilePath string, genesisHeight uint64, sv *state.StoreView) *core.ValidatorCandidatePool {
+func performInitialStakeDeposit(chainID, stakeDepositFilePath string, genesisHeight uint64, sv *state.StoreView) *core.ValidatorCandidatePool {
 	var stakeDeposits []StakeDeposit
 	stakeDepositFile, err := os.Open(stakeDepositFilePath)
 	stakeDepositByteValue, err := ioutil.ReadAll(stakeDepositFile)
@@ -188,7 +217,7 @@ func performInitialStakeDeposit(stakeDepositFilePath string, genesisHeight uint6
 			panic(fmt.Sprintf("The source account %v does NOT have sufficient balance for stake deposit. ThetaWeiBalance = %v, StakeAmount = %v",
 				sourceAddress, sourceAccount.Balance.ThetaWei, stakeDeposit.Amount))
 		}
-		err := vcp.DepositStake(sourceAddress, holderAddress, stakeAmount)
+		err := vcp.DepositStake(sourceAddress, holderAddress, stakeAmount, chainID)
 		if err != nil {
 			panic(fmt.Sprintf("Failed to deposit stake, err: %v", err))
 		}
@@ -287,6 +316,8 @@ func sanityChecks(sv *state.StoreView) error {
 			if hl.Heights[0] != uint64(0) {
 				panic(fmt.Sprintf("Only height 0 should be in the genesis height list"))
 			}
+		} else if bytes.Compare(key, state.ChainParamsKey()) == 0 {
+			logger.Infof("Chain parameters: %v", sv.GetChainParams())
 		} else { // regular account
 			var account types.Account
 			err := rlp.DecodeBytes(val, &account)
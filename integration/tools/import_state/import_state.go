@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/snapshot"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+var logger *log.Entry = log.WithFields(log.Fields{"prefix": "import_state"})
+
+type StakeDeposit struct {
+	Source string `json:"source"`
+	Holder string `json:"holder"`
+	Amount string `json:"amount"`
+}
+
+//
+// Example:
+// import_state -state=./theta_state-1000.json -chainID=forknet -stake_deposit=./stake_deposit.json -genesis=./genesis
+//
+// The state exported by export_state is imported as the genesis state of a new chain. Since the keys
+// of the original validators are usually not available, the validator candidate pool can be replaced
+// by the given stake deposits.
+//
+func main() {
+	statePathPtr := flag.String("state", "", "the state export")
+	chainIDPtr := flag.String("chainID", "local_chain", "the ID of the new chain")
+	stakeDepositFilePathPtr := flag.String("stake_deposit", "", "the stake deposits replacing the validator candidate pool (optional)")
+	genesisSnapshotFilePathPtr := flag.String("genesis", "./genesis", "the genesis snapshot")
+	flag.Parse()
+
+	if *chainIDPtr == core.MainnetChainID {
+		panic(fmt.Sprintf("The chainID of the new chain cannot be %v", core.MainnetChainID))
+	}
+
+	dbPath, err := ioutil.TempDir("", "import_state")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create the temporary db: %v", err))
+	}
+	defer os.RemoveAll(dbPath)
+	db, err := backend.NewLDBDatabase(path.Join(dbPath, "main"), path.Join(dbPath, "ref"), 256, 0)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to open the temporary db: %v", err))
+	}
+	defer db.Close()
+
+	stateFile, err := os.Open(*statePathPtr)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to open the state export: %v", err))
+	}
+	defer stateFile.Close()
+
+	sv, header, err := snapshot.ImportState(stateFile, db, core.GenesisBlockHeight)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to import the state: %v", err))
+	}
+	logger.Infof("Imported the state of %v at height %v, state hash: %v", header.ChainID, header.Height, header.StateHash.Hex())
+
+	if *stakeDepositFilePathPtr != "" {
+		replaceValidatorCandidatePool(*chainIDPtr, *stakeDepositFilePathPtr, sv)
+		sv.Save()
+	}
+
+	genesisBlockHash, err := snapshot.WriteGenesisSnapshot(sv, *chainIDPtr, *genesisSnapshotFilePathPtr)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to write genesis snapshot: %v", err))
+	}
+
+	fmt.Println("")
+	fmt.Printf("--------------------------------------------------------------------------\n")
+	fmt.Printf("Genesis block hash: %v\n", genesisBlockHash.Hex())
+	fmt.Printf("--------------------------------------------------------------------------\n")
+	fmt.Println("")
+}
+
+// replaceValidatorCandidatePool replaces the validator candidate pool with the given stake deposits. The
+// stakes of the original pool are dropped.
+func replaceValidatorCandidatePool(chainID, stakeDepositFilePath string, sv *state.StoreView) {
+	stakeDepositByteValue, err := ioutil.ReadFile(stakeDepositFilePath)
+	if err != nil {
+		panic(fmt.Sprintf("failed to read stake deposit file: %v", err))
+	}
+
+	var stakeDeposits []StakeDeposit
+	if err = json.Unmarshal(stakeDepositByteValue, &stakeDeposits); err != nil {
+		panic(fmt.Sprintf("failed to parse stake deposit file: %v", err))
+	}
+
+	vcp := &core.ValidatorCandidatePool{}
+	for _, stakeDeposit := range stakeDeposits {
+		if !common.IsHexAddress(stakeDeposit.Source) {
+			panic(fmt.Sprintf("Invalid source address: %v", stakeDeposit.Source))
+		}
+		if !common.IsHexAddress(stakeDeposit.Holder) {
+			panic(fmt.Sprintf("Invalid holder address: %v", stakeDeposit.Holder))
+		}
+		sourceAddress := common.HexToAddress(stakeDeposit.Source)
+		holderAddress := common.HexToAddress(stakeDeposit.Holder)
+		stakeAmount, success := new(big.Int).SetString(stakeDeposit.Amount, 10)
+		if !success {
+			panic(fmt.Sprintf("Failed to parse Stake amount: %v", stakeDeposit.Amount))
+		}
+
+		sourceAccount := sv.GetAccount(sourceAddress)
+		if sourceAccount == nil {
+			panic(fmt.Sprintf("Failed to retrieve account for source address: %v", sourceAddress))
+		}
+		if sourceAccount.Balance.ThetaWei.Cmp(stakeAmount) < 0 {
+			panic(fmt.Sprintf("The source account %v does NOT have sufficient balance for stake deposit. ThetaWeiBalance = %v, StakeAmount = %v",
+				sourceAddress, sourceAccount.Balance.ThetaWei, stakeDeposit.Amount))
+		}
+		if err := vcp.DepositStake(sourceAddress, holderAddress, stakeAmount, chainID); err != nil {
+			panic(fmt.Sprintf("Failed to deposit stake, err: %v", err))
+		}
+
+		stake := types.Coins{
+			ThetaWei: stakeAmount,
+			TFuelWei: new(big.Int).SetUint64(0),
+		}
+		sourceAccount.Balance = sourceAccount.Balance.Minus(stake)
+		sv.SetAccount(sourceAddress, sourceAccount)
+	}
+
+	sv.UpdateValidatorCandidatePool(vcp)
+
+	hl := &types.HeightList{}
+	hl.Append(core.GenesisBlockHeight)
+	sv.UpdateStakeTransactionHeightList(hl)
+
+	logger.Infof("Replaced the validator candidate pool with %v stake deposits", len(stakeDeposits))
+}
@@ -248,12 +248,12 @@ func adjustByOutputs(view *state.StoreView, accounts map[string]*types.Account,
 	}
 }
 
-func sanityCheckForGasPrice(gasPrice *big.Int, blockHeight uint64) bool {
+func sanityCheckForGasPrice(gasPrice *big.Int, chainID string, blockHeight uint64) bool {
 	if gasPrice == nil {
 		return false
 	}
 
-	minimumGasPrice := types.GetMinimumGasPrice(blockHeight)
+	minimumGasPrice := types.GetMinimumGasPrice(chainID, blockHeight)
 	if gasPrice.Cmp(minimumGasPrice) < 0 {
 		return false
 	}
@@ -261,22 +261,57 @@ func sanityCheckForGasPrice(gasPrice *big.Int, blockHeight uint64) bool {
 	return true
 }
 
-func sanityCheckForFee(fee types.Coins, blockHeight uint64) (minimumFee *big.Int, success bool) {
+func sanityCheckForFee(fee types.Coins, chainID string, blockHeight uint64) (minimumFee *big.Int, success bool) {
 	fee = fee.NoNil()
-	minimumFee = types.GetMinimumTransactionFeeTFuelWei(blockHeight)
+	minimumFee = types.GetMinimumTransactionFeeTFuelWei(chainID, blockHeight)
 	success = (fee.ThetaWei.Cmp(types.Zero) == 0 && fee.TFuelWei.Cmp(minimumFee) >= 0)
 
 	return minimumFee, success
 }
 
-func sanityCheckForSendTxFee(fee types.Coins, numAccountsAffected uint64, blockHeight uint64) (minimumFee *big.Int, success bool) {
+func sanityCheckForSendTxFee(fee types.Coins, numAccountsAffected uint64, chainID string, blockHeight uint64) (minimumFee *big.Int, success bool) {
 	fee = fee.NoNil()
-	minimumFee = types.GetSendTxMinimumTransactionFeeTFuelWei(numAccountsAffected, blockHeight)
+	minimumFee = types.GetSendTxMinimumTransactionFeeTFuelWei(numAccountsAffected, chainID, blockHeight)
 	success = (fee.ThetaWei.Cmp(types.Zero) == 0 && fee.TFuelWei.Cmp(minimumFee) >= 0)
 
 	return minimumFee, success
 }
 
+// validateFeePayer validates the fee payer of the transaction and returns its account. The fee payer cannot
+// be any of the other accounts touched by the transaction.
+func validateFeePayer(chainID string, view *state.StoreView, feePayers []types.FeePayer, signBytes []byte, fee types.Coins,
+	others ...common.Address) (*types.Account, result.Result) {
+	blockHeight := view.Height() + 1
+	if !core.IsForkActive(chainID, core.ForkFeeSponsorship, blockHeight) {
+		return nil, result.Error("Fee payer not supported yet")
+	}
+	if len(feePayers) != 1 {
+		return nil, result.Error("Only one fee payer is allowed, got %v", len(feePayers))
+	}
+
+	feePayer := feePayers[0]
+	for _, other := range others {
+		if feePayer.Address == other {
+			return nil, result.Error("The fee payer %v cannot be the sender or the recipient", feePayer.Address)
+		}
+	}
+
+	acc, res := getAccount(view, feePayer.Address)
+	if res.IsError() {
+		return nil, result.Error("Unknown fee payer: %v", feePayer.Address)
+	}
+	if !acc.Balance.IsGTE(fee) {
+		return nil, result.Error("Insufficient fund: fee payer balance is %v, fee is %v",
+			acc.Balance, fee).WithErrorCode(result.CodeInsufficientFund)
+	}
+	if !feePayer.Signature.Verify(signBytes, acc.Address) {
+		return nil, result.Error("Fee payer signature verification failed, SignBytes: %v",
+			hex.EncodeToString(signBytes)).WithErrorCode(result.CodeInvalidSignature)
+	}
+
+	return acc, result.OK
+}
+
 func chargeFee(account *types.Account, fee types.Coins) bool {
 	if !account.Balance.IsGTE(fee) {
 		return false
@@ -286,6 +321,19 @@ func chargeFee(account *types.Account, fee types.Coins) bool {
 	return true
 }
 
+// GetBaseFee returns the base fee per gas of the block applied on top of the view, or nil if the dynamic base
+// fee is not active yet. The base fee starts at the minimum gas price.
+func GetBaseFee(chainID string, view *state.StoreView) *big.Int {
+	blockHeight := view.Height() + 1
+	if !core.IsForkActive(chainID, core.ForkDynamicBaseFee, blockHeight) {
+		return nil
+	}
+	if baseFee := view.GetBaseFee(); baseFee != nil {
+		return baseFee
+	}
+	return types.GetMinimumGasPrice(chainID, blockHeight)
+}
+
 func getBlockHeight(ledgerState *state.LedgerState) uint64 {
 	blockHeight := ledgerState.Height() + 1
 	return blockHeight
@@ -293,7 +341,7 @@ func getBlockHeight(ledgerState *state.LedgerState) uint64 {
 
 func getRegularTxGas(ledgerState *state.LedgerState) uint64 {
 	blockHeight := getBlockHeight(ledgerState)
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+	if !core.IsForkActive(ledgerState.GetChainID(), core.ForkJune2021FeeAdjustment, blockHeight) {
 		return types.GasRegularTx
 	}
 	return types.GasRegularTxJune2021
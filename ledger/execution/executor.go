@@ -44,6 +44,14 @@ type Executor struct {
 	depositStakeTxExec            *DepositStakeExecutor
 	withdrawStakeTxExec           *WithdrawStakeExecutor
 	stakeRewardDistributionTxExec *StakeRewardDistributionTxExecutor
+	parameterChangeTxExec         *ParameterChangeTxExecutor
+	subchainCheckpointTxExec      *SubchainCheckpointTxExecutor
+	subchainRegistrationTxExec    *SubchainRegistrationTxExecutor
+	crossChainSendTxExec          *CrossChainSendTxExecutor
+	crossChainDeliverTxExec       *CrossChainDeliverTxExecutor
+	validatorKeyChangeTxExec      *ValidatorKeyChangeTxExecutor
+	stakeAutoCompoundingTxExec    *StakeAutoCompoundingTxExecutor
+	scheduledTxExec               *ScheduledTxExecutor
 
 	skipSanityCheck bool
 }
@@ -57,7 +65,7 @@ func NewExecutor(db database.Database, chain *blockchain.Chain, state *st.Ledger
 		consensus:      consensus,
 		valMgr:         valMgr,
 		coinbaseTxExec: NewCoinbaseTxExecutor(db, chain, state, consensus, valMgr),
-		// slashTxExec:          NewSlashTxExecutor(consensus, valMgr),
+		// slashTxExec:          NewSlashTxExecutor(chain, consensus, valMgr),
 		sendTxExec:                    NewSendTxExecutor(state),
 		reserveFundTxExec:             NewReserveFundTxExecutor(state),
 		releaseFundTxExec:             NewReleaseFundTxExecutor(state),
@@ -67,8 +75,16 @@ func NewExecutor(db database.Database, chain *blockchain.Chain, state *st.Ledger
 		depositStakeTxExec:            NewDepositStakeExecutor(state),
 		withdrawStakeTxExec:           NewWithdrawStakeExecutor(state),
 		stakeRewardDistributionTxExec: NewStakeRewardDistributionTxExecutor(state),
+		parameterChangeTxExec:         NewParameterChangeTxExecutor(state, valMgr),
+		subchainCheckpointTxExec:      NewSubchainCheckpointTxExecutor(state),
+		subchainRegistrationTxExec:    NewSubchainRegistrationTxExecutor(state, valMgr),
+		crossChainSendTxExec:          NewCrossChainSendTxExecutor(state),
+		crossChainDeliverTxExec:       NewCrossChainDeliverTxExecutor(state),
+		validatorKeyChangeTxExec:      NewValidatorKeyChangeTxExecutor(state),
+		stakeAutoCompoundingTxExec:    NewStakeAutoCompoundingTxExecutor(state),
 		skipSanityCheck:               false,
 	}
+	executor.scheduledTxExec = NewScheduledTxExecutor(executor)
 
 	return executor
 }
@@ -94,6 +110,18 @@ func (exec *Executor) ScreenTx(tx types.Tx) (common.Hash, result.Result) {
 	return exec.processTx(tx, core.ScreenedView)
 }
 
+// PrecheckTx checks the validity of the given transaction against the given view without executing it
+func (exec *Executor) PrecheckTx(view *st.StoreView, tx types.Tx) result.Result {
+	if !exec.isTxTypeSupported(view, tx) {
+		return result.Error("tx type not supported yet")
+	}
+	txExecutor := exec.getTxExecutor(tx)
+	if txExecutor == nil {
+		return result.Error("Unknown tx type")
+	}
+	return txExecutor.sanityCheck(exec.state.GetChainID(), view, tx)
+}
+
 // GetTxInfo extracts tx information used by mempool to sort Txs.
 func (exec *Executor) GetTxInfo(tx types.Tx) (*core.TxInfo, result.Result) {
 	txExecutor := exec.getTxExecutor(tx)
@@ -173,11 +201,39 @@ func (exec *Executor) isTxTypeSupported(view *st.StoreView, tx types.Tx) bool {
 
 	switch tx.(type) {
 	case *types.SmartContractTx:
-		if blockHeight < common.HeightEnableSmartContract {
+		if !core.IsForkActive(exec.state.GetChainID(), core.ForkSmartContract, blockHeight) {
+			return false
+		}
+	case *types.SmartContractTxV2:
+		if !core.IsForkActive(exec.state.GetChainID(), core.ForkDynamicBaseFee, blockHeight) {
 			return false
 		}
 	case *types.StakeRewardDistributionTx:
-		if blockHeight < common.HeightEnableTheta3 {
+		if !core.IsForkActive(exec.state.GetChainID(), core.ForkTheta3, blockHeight) {
+			return false
+		}
+	case *types.ParameterChangeTx:
+		if !core.IsForkActive(exec.state.GetChainID(), core.ForkParameterChange, blockHeight) {
+			return false
+		}
+	case *types.SubchainCheckpointTx, *types.SubchainRegistrationTx:
+		if !core.IsForkActive(exec.state.GetChainID(), core.ForkSubchainAnchoring, blockHeight) {
+			return false
+		}
+	case *types.CrossChainSendTx, *types.CrossChainDeliverTx:
+		if !core.IsForkActive(exec.state.GetChainID(), core.ForkCrossChainMessaging, blockHeight) {
+			return false
+		}
+	case *types.ValidatorKeyChangeTx:
+		if !core.IsForkActive(exec.state.GetChainID(), core.ForkValidatorKeyChange, blockHeight) {
+			return false
+		}
+	case *types.StakeAutoCompoundingTx:
+		if !core.IsForkActive(exec.state.GetChainID(), core.ForkStakeAutoCompounding, blockHeight) {
+			return false
+		}
+	case *types.ScheduledTx:
+		if !core.IsForkActive(exec.state.GetChainID(), core.ForkScheduledTx, blockHeight) {
 			return false
 		}
 	default:
@@ -204,7 +260,7 @@ func (exec *Executor) getTxExecutor(tx types.Tx) TxExecutor {
 		txExecutor = exec.servicePaymentTxExec
 	case *types.SplitRuleTx:
 		txExecutor = exec.splitRuleTxExec
-	case *types.SmartContractTx:
+	case *types.SmartContractTx, *types.SmartContractTxV2:
 		txExecutor = exec.smartContractTxExec
 	case *types.DepositStakeTx:
 		txExecutor = exec.depositStakeTxExec
@@ -214,6 +270,22 @@ func (exec *Executor) getTxExecutor(tx types.Tx) TxExecutor {
 		txExecutor = exec.depositStakeTxExec
 	case *types.StakeRewardDistributionTx:
 		txExecutor = exec.stakeRewardDistributionTxExec
+	case *types.ParameterChangeTx:
+		txExecutor = exec.parameterChangeTxExec
+	case *types.SubchainCheckpointTx:
+		txExecutor = exec.subchainCheckpointTxExec
+	case *types.SubchainRegistrationTx:
+		txExecutor = exec.subchainRegistrationTxExec
+	case *types.CrossChainSendTx:
+		txExecutor = exec.crossChainSendTxExec
+	case *types.CrossChainDeliverTx:
+		txExecutor = exec.crossChainDeliverTxExec
+	case *types.ValidatorKeyChangeTx:
+		txExecutor = exec.validatorKeyChangeTxExec
+	case *types.StakeAutoCompoundingTx:
+		txExecutor = exec.stakeAutoCompoundingTxExec
+	case *types.ScheduledTx:
+		txExecutor = exec.scheduledTxExec
 	default:
 		txExecutor = nil
 	}
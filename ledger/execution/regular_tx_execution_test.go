@@ -2251,3 +2251,118 @@ func TestSplitRuleZeroDuration(t *testing.T) {
 	retrievedSplitRule2ndTime := et.state().Delivered().GetSplitRule(resourceID)
 	assert.Nil(retrievedSplitRule2ndTime) // Should be expired and got deleted
 }
+
+func TestPrescreenScheduledTx(t *testing.T) {
+	assert := assert.New(t)
+	et := NewExecTest()
+
+	notBefore := uint64(100)
+	chainID := et.chainID
+	signChainID := types.ScheduledTxChainID(chainID, notBefore)
+
+	// Unsigned
+	tx := types.MakeSendTx(1, et.accOut, et.accIn)
+	assert.Equal(result.CodeInvalidSignature, prescreenScheduledTx(chainID, notBefore, tx).Code)
+
+	// Signed for the chain, instead of for the scheduled height
+	types.SignSendTx(chainID, tx, et.accIn)
+	assert.Equal(result.CodeInvalidSignature, prescreenScheduledTx(chainID, notBefore, tx).Code)
+
+	types.SignSendTx(signChainID, tx, et.accIn)
+	assert.True(prescreenScheduledTx(chainID, notBefore, tx).IsOK())
+
+	// Insufficient fee
+	tx = types.MakeSendTx(1, et.accOut, et.accIn)
+	tx.Fee = types.NewCoins(0, 0)
+	types.SignSendTx(signChainID, tx, et.accIn)
+	assert.Equal(result.CodeInvalidFee, prescreenScheduledTx(chainID, notBefore, tx).Code)
+
+	// The tx types whose signers are not known cannot be scheduled ahead
+	assert.True(prescreenScheduledTx(chainID, notBefore, &types.ParameterChangeTx{}).IsError())
+}
+
+func TestSubchainRegistrationTx(t *testing.T) {
+	assert := assert.New(t)
+	et := NewExecTest()
+	et.accProposer.Account.Balance = types.NewCoins(0, 10*getMinimumTxFee())
+	et.accVal2.Account.Balance = types.NewCoins(0, 10*getMinimumTxFee())
+	et.acc2State(et.accProposer, et.accVal2, et.accIn, et.accOut)
+
+	subchainID := "tsub_360777"
+	fee := types.NewCoins(0, getMinimumTxFee())
+	view := et.state().Delivered()
+	regExec := et.executor.subchainRegistrationTxExec
+	cpExec := et.executor.subchainCheckpointTxExec
+
+	makeRegistrationTx := func(proposer types.PrivAccount, seq uint64, operator common.Address) *types.SubchainRegistrationTx {
+		tx := &types.SubchainRegistrationTx{
+			Fee:        fee,
+			Proposer:   types.TxInput{Address: proposer.Address, Sequence: seq},
+			SubchainID: subchainID,
+			Operator:   operator,
+		}
+		tx.SetSignature(proposer.Address, proposer.Sign(tx.SignBytes(et.chainID)))
+		return tx
+	}
+	makeCheckpointTx := func(submitter types.PrivAccount, seq uint64, height uint64) *types.SubchainCheckpointTx {
+		tx := &types.SubchainCheckpointTx{
+			Fee:        fee,
+			Submitter:  types.TxInput{Address: submitter.Address, Sequence: seq},
+			SubchainID: subchainID,
+			Height:     height,
+			BlockHash:  common.BytesToHash([]byte("block")),
+			StateHash:  common.BytesToHash([]byte("state")),
+		}
+		tx.SetSignature(submitter.Address, submitter.Sign(tx.SignBytes(et.chainID)))
+		return tx
+	}
+
+	// The checkpoints of an unregistered subchain are rejected
+	res := cpExec.sanityCheck(et.chainID, view, makeCheckpointTx(et.accIn, 1, 100))
+	assert.True(res.IsError())
+
+	// Only the validators can vote for a registration
+	res = regExec.sanityCheck(et.chainID, view, makeRegistrationTx(et.accIn, 1, et.accIn.Address))
+	assert.True(res.IsError())
+
+	// The vote of val2 alone does not have a 2/3 majority of the stake
+	tx := makeRegistrationTx(et.accVal2, 1, et.accIn.Address)
+	res = regExec.sanityCheck(et.chainID, view, tx)
+	assert.True(res.IsOK(), res.Message)
+	_, res = regExec.process(et.chainID, view, tx)
+	assert.True(res.IsOK(), res.Message)
+	assert.Nil(view.GetSubchain(subchainID))
+	assert.Equal(1, len(view.GetSubchainRegistrations()))
+
+	// The subchain is registered once the majority has voted
+	tx = makeRegistrationTx(et.accProposer, 1, et.accIn.Address)
+	res = regExec.sanityCheck(et.chainID, view, tx)
+	assert.True(res.IsOK(), res.Message)
+	_, res = regExec.process(et.chainID, view, tx)
+	assert.True(res.IsOK(), res.Message)
+	subchain := view.GetSubchain(subchainID)
+	assert.NotNil(subchain)
+	assert.Equal(et.accIn.Address, subchain.Operator)
+	assert.Equal(0, len(view.GetSubchainRegistrations()))
+
+	// Only the registered operator can submit the checkpoints
+	res = cpExec.sanityCheck(et.chainID, view, makeCheckpointTx(et.accOut, 1, 100))
+	assert.True(res.IsError())
+
+	cpTx := makeCheckpointTx(et.accIn, 1, 100)
+	res = cpExec.sanityCheck(et.chainID, view, cpTx)
+	assert.True(res.IsOK(), res.Message)
+	_, res = cpExec.process(et.chainID, view, cpTx)
+	assert.True(res.IsOK(), res.Message)
+	assert.Equal(uint64(100), view.GetSubchain(subchainID).LatestHeight)
+
+	// The validators can replace the operator
+	tx = makeRegistrationTx(et.accProposer, 2, et.accOut.Address)
+	res = regExec.sanityCheck(et.chainID, view, tx)
+	assert.True(res.IsOK(), res.Message)
+	_, res = regExec.process(et.chainID, view, tx)
+	assert.True(res.IsOK(), res.Message)
+	subchain = view.GetSubchain(subchainID)
+	assert.Equal(et.accOut.Address, subchain.Operator)
+	assert.Equal(uint64(100), subchain.LatestHeight)
+}
@@ -98,7 +98,7 @@ func (exec *CoinbaseTxExecutor) sanityCheck(chainID string, view *st.StoreView,
 	guardianVotes := currentBlock.GuardianVotes
 	eliteEdgeNodeVotes := currentBlock.EliteEdgeNodeVotes
 	guardianPool, eliteEdgeNodePool := RetrievePools(exec.consensus.GetLedger(), exec.chain, exec.db, tx.BlockHeight, guardianVotes, eliteEdgeNodeVotes)
-	expectedRewards = CalculateReward(exec.consensus.GetLedger(), view, validatorSet, guardianVotes, guardianPool, eliteEdgeNodeVotes, eliteEdgeNodePool)
+	expectedRewards = CalculateReward(exec.state.GetChainID(), exec.consensus.GetLedger(), view, validatorSet, guardianVotes, guardianPool, eliteEdgeNodeVotes, eliteEdgeNodePool)
 
 	if len(expectedRewards) != len(tx.Outputs) {
 		return result.Error("Number of rewarded account is incorrect")
@@ -126,10 +126,19 @@ func (exec *CoinbaseTxExecutor) process(chainID string, view *st.StoreView, tran
 		return common.Hash{}, res
 	}
 
+	compounding := core.IsForkActive(exec.state.GetChainID(), core.ForkStakeAutoCompounding, tx.BlockHeight)
+	var eenp *st.EliteEdgeNodePool
 	for _, output := range tx.Outputs {
 		addr := string(output.Address[:])
 		if account, exists := accounts[addr]; exists {
-			account.Balance = account.Balance.Plus(output.Coins)
+			reward := output.Coins
+			if compounding {
+				if eenp == nil {
+					eenp = st.NewEliteEdgeNodePool(view, false)
+				}
+				reward = compoundReward(chainID, view, eenp, output.Address, reward)
+			}
+			account.Balance = account.Balance.Plus(reward)
 			view.SetAccount(output.Address, account)
 		}
 	}
@@ -140,15 +149,66 @@ func (exec *CoinbaseTxExecutor) process(chainID string, view *st.StoreView, tran
 	return txHash, result.OK
 }
 
+// compoundReward stakes the TFuel reward of the account to the elite edge node chosen by the account, if the
+// account has opted in for auto-compounding, and returns the rest of the reward to be paid to the account.
+// The reward is paid as usual if it cannot be staked, e.g. the stake has been withdrawn or reached the cap.
+func compoundReward(chainID string, view *st.StoreView, eenp *st.EliteEdgeNodePool, address common.Address, reward types.Coins) types.Coins {
+	if reward.TFuelWei == nil || reward.TFuelWei.Sign() <= 0 {
+		return reward
+	}
+	sac := view.GetStakeAutoCompounding(address)
+	if sac == nil {
+		return reward
+	}
+	if err := eenp.CompoundStake(address, sac.Holder, reward.TFuelWei, chainID); err != nil {
+		logger.Debugf("Failed to compound the reward of %v into elite edge node %v: %v", address, sac.Holder, err)
+		return reward
+	}
+	return types.Coins{
+		ThetaWei: reward.ThetaWei,
+		TFuelWei: big.NewInt(0),
+	}
+}
+
+// StakingRewardsPerBlock returns the TFuel rewards minted per block at the given height for the validators and
+// guardians, and for the elite edge nodes. The rewards are accumulated and issued at the checkpoint blocks.
+func StakingRewardsPerBlock(chainID string, blockHeight uint64) (validatorGuardianReward *big.Int, eenReward *big.Int) {
+	validatorGuardianReward = big.NewInt(0)
+	eenReward = big.NewInt(0)
+	if core.IsForkActive(chainID, core.ForkValidatorReward, blockHeight) {
+		validatorGuardianReward.Set(validatorRewardPerBlock(chainID))
+	}
+	if core.IsForkActive(chainID, core.ForkTheta3, blockHeight) {
+		eenReward.Set(eliteEdgeNodeRewardPerBlock(chainID))
+	}
+	return validatorGuardianReward, eenReward
+}
+
+// validatorRewardPerBlock returns the TFuel reward per block of the validators and guardians of the given chain
+func validatorRewardPerBlock(chainID string) *big.Int {
+	if reward, ok := core.GetChainParam(chainID, core.ChainParamValidatorRewardPerBlock); ok {
+		return reward
+	}
+	return tfuelRewardPerBlock
+}
+
+// eliteEdgeNodeRewardPerBlock returns the TFuel reward per block of the elite edge nodes of the given chain
+func eliteEdgeNodeRewardPerBlock(chainID string) *big.Int {
+	if reward, ok := core.GetChainParam(chainID, core.ChainParamEliteEdgeNodeRewardPerBlock); ok {
+		return reward
+	}
+	return eenTfuelRewardPerBlock
+}
+
 func RetrievePools(ledger core.Ledger, chain *blockchain.Chain, db database.Database, blockHeight uint64, guardianVotes *core.AggregatedVotes,
 	eliteEdgeNodeVotes *core.AggregatedEENVotes) (guardianPool *core.GuardianCandidatePool, eliteEdgeNodePool core.EliteEdgeNodePool) {
 	guardianPool = nil
 	eliteEdgeNodePool = nil
 
-	if blockHeight < common.HeightEnableTheta2 {
+	if !core.IsForkActive(chain.ChainID, core.ForkTheta2, blockHeight) {
 		guardianPool = nil
 		eliteEdgeNodePool = nil
-	} else if blockHeight < common.HeightEnableTheta3 {
+	} else if !core.IsForkActive(chain.ChainID, core.ForkTheta3, blockHeight) {
 		if guardianVotes != nil {
 			guradianVoteBlock, err := chain.FindBlock(guardianVotes.Block)
 			if err != nil {
@@ -157,7 +217,7 @@ func RetrievePools(ledger core.Ledger, chain *blockchain.Chain, db database.Data
 			storeView := st.NewStoreView(guradianVoteBlock.Height, guradianVoteBlock.StateHash, db)
 			guardianPool = storeView.GetGuardianCandidatePool()
 		}
-	} else { // blockHeight >= common.HeightEnableTheta3
+	} else { // after the Theta3.0 fork
 		// won't reward the elite edge nodes without the guardian votes, since we need to guardian votes to confirm that
 		// the edge nodes vote for the correct checkpoint
 		if guardianVotes != nil {
@@ -184,21 +244,74 @@ func RetrievePools(ledger core.Ledger, chain *blockchain.Chain, db database.Data
 	return guardianPool, eliteEdgeNodePool
 }
 
+// RewardEntry records the reward granted for a stake (or for all the stakes of a source)
+type RewardEntry struct {
+	Holder      common.Address // empty if the reward is aggregated over all the stakes of the source
+	Source      common.Address
+	Reward      *big.Int       // reward before the split
+	Beneficiary common.Address // empty if the reward is not split
+	SplitReward *big.Int       // part of the reward redirected to the beneficiary by the stake reward distribution rule
+}
+
+// RewardBreakdown records how the block reward is distributed among the stakers
+type RewardBreakdown struct {
+	StakerRewards        []*RewardEntry // rewards for the theta stakes of the validators and guardians
+	EliteEdgeNodeRewards []*RewardEntry // rewards for the tfuel stakes of the elite edge nodes
+}
+
+func (rb *RewardBreakdown) addEntry(rewardType string, entry *RewardEntry) {
+	if rb == nil {
+		return
+	}
+	if entry.SplitReward == nil {
+		entry.SplitReward = big.NewInt(0)
+	}
+	if rewardType == eenRewardType {
+		rb.EliteEdgeNodeRewards = append(rb.EliteEdgeNodeRewards, entry)
+	} else {
+		rb.StakerRewards = append(rb.StakerRewards, entry)
+	}
+}
+
+const (
+	blockRewardType = "Block"
+	eenRewardType   = "EEN  "
+)
+
 // CalculateReward calculates the block reward for each account
-func CalculateReward(ledger core.Ledger, view *st.StoreView, validatorSet *core.ValidatorSet,
+func CalculateReward(chainID string, ledger core.Ledger, view *st.StoreView, validatorSet *core.ValidatorSet,
 	guardianVotes *core.AggregatedVotes, guardianPool *core.GuardianCandidatePool,
 	eliteEdgeNodeVotes *core.AggregatedEENVotes, eliteEdgeNodePool core.EliteEdgeNodePool) map[string]types.Coins {
+	return calculateReward(chainID, ledger, view, validatorSet, guardianVotes, guardianPool, eliteEdgeNodeVotes, eliteEdgeNodePool, nil)
+}
+
+// CalculateRewardBreakdown calculates the block reward for each account, along with the breakdown of
+// the reward for each stake
+func CalculateRewardBreakdown(chainID string, ledger core.Ledger, view *st.StoreView, validatorSet *core.ValidatorSet,
+	guardianVotes *core.AggregatedVotes, guardianPool *core.GuardianCandidatePool,
+	eliteEdgeNodeVotes *core.AggregatedEENVotes, eliteEdgeNodePool core.EliteEdgeNodePool) (map[string]types.Coins, *RewardBreakdown) {
+	breakdown := &RewardBreakdown{
+		StakerRewards:        []*RewardEntry{},
+		EliteEdgeNodeRewards: []*RewardEntry{},
+	}
+	accountReward := calculateReward(chainID, ledger, view, validatorSet, guardianVotes, guardianPool, eliteEdgeNodeVotes, eliteEdgeNodePool, breakdown)
+	return accountReward, breakdown
+}
+
+func calculateReward(chainID string, ledger core.Ledger, view *st.StoreView, validatorSet *core.ValidatorSet,
+	guardianVotes *core.AggregatedVotes, guardianPool *core.GuardianCandidatePool,
+	eliteEdgeNodeVotes *core.AggregatedEENVotes, eliteEdgeNodePool core.EliteEdgeNodePool, breakdown *RewardBreakdown) map[string]types.Coins {
 	accountReward := map[string]types.Coins{}
 	blockHeight := view.Height() + 1 // view points to the parent block
-	if blockHeight < common.HeightEnableValidatorReward {
+	if !core.IsForkActive(chainID, core.ForkValidatorReward, blockHeight) {
 		grantValidatorsWithZeroReward(validatorSet, &accountReward)
-	} else if blockHeight < common.HeightEnableTheta2 || guardianVotes == nil || guardianPool == nil {
-		grantValidatorReward(ledger, view, validatorSet, &accountReward, blockHeight)
-	} else if blockHeight < common.HeightEnableTheta3 {
-		grantValidatorAndGuardianReward(ledger, view, validatorSet, guardianVotes, guardianPool, &accountReward, blockHeight)
-	} else { // blockHeight >= common.HeightEnableTheta3
-		grantValidatorAndGuardianReward(ledger, view, validatorSet, guardianVotes, guardianPool, &accountReward, blockHeight)
-		grantEliteEdgeNodeReward(ledger, view, guardianVotes, eliteEdgeNodeVotes, eliteEdgeNodePool, &accountReward, blockHeight)
+	} else if !core.IsForkActive(chainID, core.ForkTheta2, blockHeight) || guardianVotes == nil || guardianPool == nil {
+		grantValidatorReward(chainID, ledger, view, validatorSet, &accountReward, blockHeight, breakdown)
+	} else if !core.IsForkActive(chainID, core.ForkTheta3, blockHeight) {
+		grantValidatorAndGuardianReward(chainID, ledger, view, validatorSet, guardianVotes, guardianPool, &accountReward, blockHeight, breakdown)
+	} else { // after the Theta3.0 fork
+		grantValidatorAndGuardianReward(chainID, ledger, view, validatorSet, guardianVotes, guardianPool, &accountReward, blockHeight, breakdown)
+		grantEliteEdgeNodeReward(chainID, ledger, view, guardianVotes, eliteEdgeNodeVotes, eliteEdgeNodePool, &accountReward, blockHeight, breakdown)
 	}
 
 	addrs := []string{}
@@ -222,7 +335,8 @@ func grantValidatorsWithZeroReward(validatorSet *core.ValidatorSet, accountRewar
 	}
 }
 
-func grantValidatorReward(ledger core.Ledger, view *st.StoreView, validatorSet *core.ValidatorSet, accountReward *map[string]types.Coins, blockHeight uint64) {
+func grantValidatorReward(chainID string, ledger core.Ledger, view *st.StoreView, validatorSet *core.ValidatorSet, accountReward *map[string]types.Coins, blockHeight uint64,
+	breakdown *RewardBreakdown) {
 	if !common.IsCheckPointHeight(blockHeight) {
 		return
 	}
@@ -263,7 +377,7 @@ func grantValidatorReward(ledger core.Ledger, view *st.StoreView, validatorSet *
 		}
 	}
 
-	totalReward := big.NewInt(1).Mul(tfuelRewardPerBlock, big.NewInt(common.CheckpointInterval))
+	totalReward := big.NewInt(1).Mul(validatorRewardPerBlock(chainID), big.NewInt(common.CheckpointInterval))
 
 	// the source of the stake divides the block reward proportional to their stake
 	for stakeSourceAddr, stakeAmountSum := range stakeSourceMap {
@@ -275,14 +389,15 @@ func grantValidatorReward(ledger core.Ledger, view *st.StoreView, validatorSet *
 			TFuelWei: rewardAmount,
 		}.NoNil()
 		(*accountReward)[string(stakeSourceAddr[:])] = reward
+		breakdown.addEntry(blockRewardType, &RewardEntry{Source: stakeSourceAddr, Reward: rewardAmount})
 
 		logger.Infof("Block reward for staker %v : %v", hex.EncodeToString(stakeSourceAddr[:]), reward)
 	}
 }
 
 // grant block rewards to both the validators and active guardians (they are both theta stakers)
-func grantValidatorAndGuardianReward(ledger core.Ledger, view *st.StoreView, validatorSet *core.ValidatorSet, guardianVotes *core.AggregatedVotes,
-	guardianPool *core.GuardianCandidatePool, accountReward *map[string]types.Coins, blockHeight uint64) {
+func grantValidatorAndGuardianReward(chainID string, ledger core.Ledger, view *st.StoreView, validatorSet *core.ValidatorSet, guardianVotes *core.AggregatedVotes,
+	guardianPool *core.GuardianCandidatePool, accountReward *map[string]types.Coins, blockHeight uint64, breakdown *RewardBreakdown) {
 	if !common.IsCheckPointHeight(blockHeight) {
 		return
 	}
@@ -349,26 +464,26 @@ func grantValidatorAndGuardianReward(ledger core.Ledger, view *st.StoreView, val
 		}
 	}
 
-	totalReward := big.NewInt(1).Mul(tfuelRewardPerBlock, big.NewInt(common.CheckpointInterval))
+	totalReward := big.NewInt(1).Mul(validatorRewardPerBlock(chainID), big.NewInt(common.CheckpointInterval))
 
 	var srdsr *st.StakeRewardDistributionRuleSet
-	if blockHeight >= common.HeightEnableTheta3 {
+	if core.IsForkActive(chainID, core.ForkTheta3, blockHeight) {
 		srdsr = state.NewStakeRewardDistributionRuleSet(view)
 	}
 
-	if blockHeight < common.HeightSampleStakingReward {
+	if !core.IsForkActive(chainID, core.ForkSampleStakingReward, blockHeight) {
 		// the source of the stake divides the block reward proportional to their stake
-		issueFixedReward(effectiveStakes, totalStake, accountReward, totalReward, srdsr, "Block")
+		issueFixedReward(effectiveStakes, totalStake, accountReward, totalReward, srdsr, blockRewardType, breakdown)
 	} else {
 		// randomly select (proportional to the stake) a constant-sized set of stakers and grand the block reward
 		issueRandomizedReward(ledger, guardianVotes, view, effectiveStakes,
-			totalStake, accountReward, totalReward, srdsr, "Block")
+			totalStake, accountReward, totalReward, srdsr, blockRewardType, breakdown)
 	}
 }
 
 // grant uptime mining rewards to active elite edge nodes (they are the tfuel stakers)
-func grantEliteEdgeNodeReward(ledger core.Ledger, view *st.StoreView, guardianVotes *core.AggregatedVotes, eliteEdgeNodeVotes *core.AggregatedEENVotes,
-	eliteEdgeNodePool core.EliteEdgeNodePool, accountReward *map[string]types.Coins, blockHeight uint64) {
+func grantEliteEdgeNodeReward(chainID string, ledger core.Ledger, view *st.StoreView, guardianVotes *core.AggregatedVotes, eliteEdgeNodeVotes *core.AggregatedEENVotes,
+	eliteEdgeNodePool core.EliteEdgeNodePool, accountReward *map[string]types.Coins, blockHeight uint64, breakdown *RewardBreakdown) {
 	if !common.IsCheckPointHeight(blockHeight) {
 		return
 	}
@@ -429,17 +544,17 @@ func grantEliteEdgeNodeReward(ledger core.Ledger, view *st.StoreView, guardianVo
 	}
 
 	// the source of the stake divides the block reward proportional to their stake
-	totalReward := big.NewInt(1).Mul(eenTfuelRewardPerBlock, big.NewInt(common.CheckpointInterval))
+	totalReward := big.NewInt(1).Mul(eliteEdgeNodeRewardPerBlock(chainID), big.NewInt(common.CheckpointInterval))
 
 	logger.Debugf("grantEliteEdgeNodeReward: totalEffectiveStake = %v, totalReward = %v", totalEffectiveStake, totalReward)
 
 	var srdsr *st.StakeRewardDistributionRuleSet
-	if blockHeight >= common.HeightEnableTheta3 {
+	if core.IsForkActive(chainID, core.ForkTheta3, blockHeight) {
 		srdsr = state.NewStakeRewardDistributionRuleSet(view)
 	}
 
 	// the source of the stake divides the block reward proportional to their stake
-	issueFixedReward(effectiveStakes, totalEffectiveStake, accountReward, totalReward, srdsr, "EEN  ")
+	issueFixedReward(effectiveStakes, totalEffectiveStake, accountReward, totalReward, srdsr, eenRewardType, breakdown)
 
 }
 
@@ -457,7 +572,9 @@ func addRewardToMap(receiver common.Address, amount *big.Int, accountReward *map
 	}
 }
 
-func handleSplit(stake *core.Stake, srdsr *st.StakeRewardDistributionRuleSet, reward *big.Int, accountRewardMap *map[string]types.Coins) {
+// handleSplit splits the reward between the stake source and the beneficiary specified by the stake reward distribution
+// rule of the stake holder, and returns the beneficiary and the split reward
+func handleSplit(stake *core.Stake, srdsr *st.StakeRewardDistributionRuleSet, reward *big.Int, accountRewardMap *map[string]types.Coins) (common.Address, *big.Int) {
 	if srdsr == nil {
 		// Should not happen
 		logger.Panic("srdsr is nil")
@@ -470,7 +587,7 @@ func handleSplit(stake *core.Stake, srdsr *st.StakeRewardDistributionRuleSet, re
 	rewardDistribution := srdsr.Get(stake.Holder)
 	if rewardDistribution == nil {
 		addRewardToMap(stake.Source, reward, accountRewardMap)
-		return
+		return common.Address{}, big.NewInt(0)
 	}
 
 	if rewardDistribution.SplitBasisPoint == 0 {
@@ -493,9 +610,12 @@ func handleSplit(stake *core.Stake, srdsr *st.StakeRewardDistributionRuleSet, re
 
 	addRewardToMap(stake.Source, sourceReward, accountRewardMap)
 	addRewardToMap(rewardDistribution.Beneficiary, splitReward, accountRewardMap)
+
+	return rewardDistribution.Beneficiary, splitReward
 }
 
-func issueFixedReward(effectiveStakes [][]*core.Stake, totalStake *big.Int, accountReward *map[string]types.Coins, totalReward *big.Int, srdsr *st.StakeRewardDistributionRuleSet, rewardType string) {
+func issueFixedReward(effectiveStakes [][]*core.Stake, totalStake *big.Int, accountReward *map[string]types.Coins, totalReward *big.Int, srdsr *st.StakeRewardDistributionRuleSet, rewardType string,
+	breakdown *RewardBreakdown) {
 	if totalStake.Cmp(big.NewInt(0)) == 0 {
 		return
 	}
@@ -510,7 +630,9 @@ func issueFixedReward(effectiveStakes [][]*core.Stake, totalStake *big.Int, acco
 				logger.Infof("%v reward for staker %v : %v  (before split)", rewardType, hex.EncodeToString(stake.Source[:]), rewardAmount)
 
 				// Calculate split
-				handleSplit(stake, srdsr, rewardAmount, accountReward)
+				beneficiary, splitReward := handleSplit(stake, srdsr, rewardAmount, accountReward)
+				breakdown.addEntry(rewardType, &RewardEntry{Holder: stake.Holder, Source: stake.Source, Reward: rewardAmount,
+					Beneficiary: beneficiary, SplitReward: splitReward})
 			}
 		}
 	} else {
@@ -527,6 +649,7 @@ func issueFixedReward(effectiveStakes [][]*core.Stake, totalStake *big.Int, acco
 			rewardAmount.Mul(totalReward, totalSourceStake)
 			rewardAmount.Div(rewardAmount, totalStake)
 			addRewardToMap(stakes[0].Source, rewardAmount, accountReward)
+			breakdown.addEntry(rewardType, &RewardEntry{Source: stakes[0].Source, Reward: rewardAmount})
 
 			logger.Infof("%v reward for staker %v : %v  (before split)", rewardType, hex.EncodeToString(stakes[0].Source[:]), rewardAmount)
 		}
@@ -534,7 +657,8 @@ func issueFixedReward(effectiveStakes [][]*core.Stake, totalStake *big.Int, acco
 }
 
 func issueRandomizedReward(ledger core.Ledger, guardianVotes *core.AggregatedVotes, view *st.StoreView, effectiveStakes [][]*core.Stake,
-	totalStake *big.Int, accountReward *map[string]types.Coins, totalReward *big.Int, srdsr *st.StakeRewardDistributionRuleSet, rewardType string) {
+	totalStake *big.Int, accountReward *map[string]types.Coins, totalReward *big.Int, srdsr *st.StakeRewardDistributionRuleSet, rewardType string,
+	breakdown *RewardBreakdown) {
 
 	if guardianVotes == nil {
 		// Should never reach here
@@ -596,7 +720,9 @@ func issueRandomizedReward(ledger core.Ledger, guardianVotes *core.AggregatedVot
 					logger.Infof("%v reward for staker %v : %v (before split)", rewardType, hex.EncodeToString(stakeSourceAddr[:]), rewardAmount)
 
 					// Calculate split
-					handleSplit(stake, srdsr, rewardAmount, accountReward)
+					beneficiary, splitReward := handleSplit(stake, srdsr, rewardAmount, accountReward)
+					breakdown.addEntry(rewardType, &RewardEntry{Holder: stake.Holder, Source: stakeSourceAddr, Reward: rewardAmount,
+						Beneficiary: beneficiary, SplitReward: splitReward})
 				}
 			}
 		}
@@ -635,6 +761,7 @@ func issueRandomizedReward(ledger core.Ledger, guardianVotes *core.AggregatedVot
 				rewardAmount := tmp.Div(tmp, big.NewInt(int64(tfuelRewardN)))
 
 				addRewardToMap(stakeSourceAddr, rewardAmount, accountReward)
+				breakdown.addEntry(rewardType, &RewardEntry{Source: stakeSourceAddr, Reward: rewardAmount})
 
 				logger.Infof("%v reward for staker %v : %v (before split)", rewardType, hex.EncodeToString(stakeSourceAddr[:]), rewardAmount)
 			}
@@ -0,0 +1,66 @@
+package execution
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestIssueFixedRewardBreakdown(t *testing.T) {
+	assert := assert.New(t)
+
+	holder1 := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	holder2 := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	source1 := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	source2 := common.HexToAddress("0x4444444444444444444444444444444444444444")
+	beneficiary := common.HexToAddress("0x5555555555555555555555555555555555555555")
+
+	effectiveStakes := [][]*core.Stake{
+		{{Holder: holder1, Source: source1, Amount: big.NewInt(300)}},
+		{{Holder: holder2, Source: source2, Amount: big.NewInt(100)}},
+	}
+	totalStake := big.NewInt(400)
+	totalReward := big.NewInt(4000)
+
+	view := st.NewStoreView(0, common.Hash{}, backend.NewMemDatabase())
+	srdsr := st.NewStakeRewardDistributionRuleSet(view)
+	rd, err := core.NewRewardDistribution(holder2, beneficiary, 1000)
+	assert.Nil(err)
+	srdsr.Upsert(rd)
+
+	accountReward := map[string]types.Coins{}
+	breakdown := &RewardBreakdown{}
+	issueFixedReward(effectiveStakes, totalStake, &accountReward, totalReward, srdsr, blockRewardType, breakdown)
+
+	assert.Equal(2, len(breakdown.StakerRewards))
+	assert.Equal(0, len(breakdown.EliteEdgeNodeRewards))
+
+	entry1 := breakdown.StakerRewards[0]
+	assert.Equal(holder1, entry1.Holder)
+	assert.Equal(int64(3000), entry1.Reward.Int64())
+	assert.True(entry1.Beneficiary.IsEmpty())
+	assert.Equal(int64(0), entry1.SplitReward.Int64())
+
+	entry2 := breakdown.StakerRewards[1]
+	assert.Equal(holder2, entry2.Holder)
+	assert.Equal(int64(1000), entry2.Reward.Int64())
+	assert.Equal(beneficiary, entry2.Beneficiary)
+	assert.Equal(int64(100), entry2.SplitReward.Int64())
+
+	// The breakdown matches the rewards granted to the accounts
+	assert.Equal(int64(3000), accountReward[string(source1[:])].TFuelWei.Int64())
+	assert.Equal(int64(900), accountReward[string(source2[:])].TFuelWei.Int64())
+	assert.Equal(int64(100), accountReward[string(beneficiary[:])].TFuelWei.Int64())
+
+	// Recording the breakdown is optional
+	accountReward = map[string]types.Coins{}
+	issueFixedReward(effectiveStakes, totalStake, &accountReward, totalReward, nil, eenRewardType, nil)
+	assert.Equal(int64(3000), accountReward[string(source1[:])].TFuelWei.Int64())
+	assert.Equal(int64(1000), accountReward[string(source2[:])].TFuelWei.Int64())
+}
@@ -0,0 +1,136 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*CrossChainDeliverTxExecutor)(nil)
+
+// ------------------------------- CrossChainDeliver Transaction -----------------------------------
+
+// CrossChainDeliverTxExecutor implements the TxExecutor interface
+type CrossChainDeliverTxExecutor struct {
+	state *st.LedgerState
+}
+
+// NewCrossChainDeliverTxExecutor creates a new instance of CrossChainDeliverTxExecutor
+func NewCrossChainDeliverTxExecutor(state *st.LedgerState) *CrossChainDeliverTxExecutor {
+	return &CrossChainDeliverTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *CrossChainDeliverTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	tx := transaction.(*types.CrossChainDeliverTx)
+
+	res := tx.Relayer.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	relayerAccount, res := getInput(view, tx.Relayer)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(relayerAccount, signBytes, tx.Relayer)
+	if res.IsError() {
+		return res
+	}
+
+	message := &tx.Message
+	if message.TargetChainID != chainID {
+		return result.Error("Cross-chain message is sent to %v rather than this chain", message.TargetChainID)
+	}
+	if err := core.ValidateSubchainID(message.SourceChainID, chainID); err != nil {
+		return result.Error("Invalid source chain: %v", err)
+	}
+	if len(message.Data) > core.MaxCrossChainMessageDataSize {
+		return result.Error("Cross-chain message data cannot exceed %v bytes", core.MaxCrossChainMessageDataSize)
+	}
+
+	// Messages are delivered in the order they were sent, so a message is delivered at most once
+	channel := view.GetCrossChainChannel(message.SourceChainID)
+	if message.Nonce != channel.ReceiveNonce {
+		return result.Error("Expected cross-chain message nonce %v from %v, but got %v",
+			channel.ReceiveNonce, message.SourceChainID, message.Nonce)
+	}
+
+	checkpoint := view.GetSubchainCheckpoint(message.SourceChainID, tx.CheckpointHeight)
+	if checkpoint == nil {
+		return result.Error("No checkpoint of %v is anchored at height %v", message.SourceChainID, tx.CheckpointHeight)
+	}
+	if message.SourceHeight > checkpoint.Height {
+		return result.Error("Cross-chain message at height %v is not covered by the checkpoint at height %v",
+			message.SourceHeight, checkpoint.Height)
+	}
+	if err := st.VerifyCrossChainMessageProof(checkpoint.StateHash, message, tx.Proof); err != nil {
+		return result.Error("Invalid cross-chain message proof: %v", err)
+	}
+
+	if minTxFee, success := sanityCheckForFee(tx.Fee, exec.state.GetChainID(), blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+
+	if !relayerAccount.Balance.IsGTE(tx.Fee) {
+		return result.Error("the relayer account balance is %v, but required minimal balance is %v", relayerAccount.Balance, tx.Fee)
+	}
+
+	return result.OK
+}
+
+func (exec *CrossChainDeliverTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	tx := transaction.(*types.CrossChainDeliverTx)
+
+	relayerAccount, res := getInput(view, tx.Relayer)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	// The sanity check might be skipped while replaying the committed blocks, but the delivery order
+	// still needs to hold
+	channel := view.GetCrossChainChannel(tx.Message.SourceChainID)
+	if tx.Message.Nonce != channel.ReceiveNonce {
+		return common.Hash{}, result.Error("Expected cross-chain message nonce %v from %v, but got %v",
+			channel.ReceiveNonce, tx.Message.SourceChainID, tx.Message.Nonce)
+	}
+
+	if !chargeFee(relayerAccount, tx.Fee) {
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	view.ReceiveCrossChainMessage(&tx.Message)
+
+	relayerAccount.Sequence++
+	view.SetAccount(tx.Relayer.Address, relayerAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *CrossChainDeliverTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.CrossChainDeliverTx)
+	return &core.TxInfo{
+		Address:           tx.Relayer.Address,
+		Sequence:          tx.Relayer.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *CrossChainDeliverTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.CrossChainDeliverTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
@@ -0,0 +1,115 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*CrossChainSendTxExecutor)(nil)
+
+// ------------------------------- CrossChainSend Transaction -----------------------------------
+
+// CrossChainSendTxExecutor implements the TxExecutor interface
+type CrossChainSendTxExecutor struct {
+	state *st.LedgerState
+}
+
+// NewCrossChainSendTxExecutor creates a new instance of CrossChainSendTxExecutor
+func NewCrossChainSendTxExecutor(state *st.LedgerState) *CrossChainSendTxExecutor {
+	return &CrossChainSendTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *CrossChainSendTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	tx := transaction.(*types.CrossChainSendTx)
+
+	res := tx.Sender.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	senderAccount, res := getInput(view, tx.Sender)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(senderAccount, signBytes, tx.Sender)
+	if res.IsError() {
+		return res
+	}
+
+	if err := core.ValidateSubchainID(tx.TargetChainID, chainID); err != nil {
+		return result.Error("Invalid target chain: %v", err)
+	}
+
+	if len(tx.Data) > core.MaxCrossChainMessageDataSize {
+		return result.Error("Cross-chain message data cannot exceed %v bytes", core.MaxCrossChainMessageDataSize)
+	}
+
+	if minTxFee, success := sanityCheckForFee(tx.Fee, exec.state.GetChainID(), blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+
+	if !senderAccount.Balance.IsGTE(tx.Fee) {
+		return result.Error("the sender account balance is %v, but required minimal balance is %v", senderAccount.Balance, tx.Fee)
+	}
+
+	return result.OK
+}
+
+func (exec *CrossChainSendTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	tx := transaction.(*types.CrossChainSendTx)
+
+	senderAccount, res := getInput(view, tx.Sender)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	if !chargeFee(senderAccount, tx.Fee) {
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	view.EnqueueCrossChainMessage(&core.CrossChainMessage{
+		SourceChainID: chainID,
+		TargetChainID: tx.TargetChainID,
+		Sender:        tx.Sender.Address,
+		Receiver:      tx.Receiver,
+		Data:          tx.Data,
+		SourceHeight:  blockHeight,
+	})
+
+	senderAccount.Sequence++
+	view.SetAccount(tx.Sender.Address, senderAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *CrossChainSendTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.CrossChainSendTx)
+	return &core.TxInfo{
+		Address:           tx.Sender.Address,
+		Sequence:          tx.Sender.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *CrossChainSendTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.CrossChainSendTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
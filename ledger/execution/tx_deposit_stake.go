@@ -31,7 +31,7 @@ func NewDepositStakeExecutor(state *st.LedgerState) *DepositStakeExecutor {
 func (exec *DepositStakeExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
 	// Feature block height check
 	blockHeight := view.Height() + 1 // the view points to the parent of the current block
-	if _, ok := transaction.(*types.DepositStakeTxV2); ok && blockHeight < common.HeightEnableTheta2 {
+	if _, ok := transaction.(*types.DepositStakeTxV2); ok && !core.IsForkActive(exec.state.GetChainID(), core.ForkTheta2, blockHeight) {
 		return result.Error("Feature guardian is not active yet")
 	}
 
@@ -54,7 +54,7 @@ func (exec *DepositStakeExecutor) sanityCheck(chainID string, view *st.StoreView
 		return res
 	}
 
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(tx.Fee, exec.state.GetChainID(), blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
@@ -81,16 +81,13 @@ func (exec *DepositStakeExecutor) sanityCheck(chainID string, view *st.StoreView
 	}
 
 	// Minimum stake deposit requirement to avoid spamming
-	if tx.Purpose == core.StakeForValidator && stake.ThetaWei.Cmp(core.MinValidatorStakeDeposit) < 0 {
-		return result.Error("Insufficient amount of stake, at least %v ThetaWei is required for each validator deposit", core.MinValidatorStakeDeposit).
+	if minValidatorStake := core.MinValidatorStake(exec.state.GetChainID()); tx.Purpose == core.StakeForValidator && stake.ThetaWei.Cmp(minValidatorStake) < 0 {
+		return result.Error("Insufficient amount of stake, at least %v ThetaWei is required for each validator deposit", minValidatorStake).
 			WithErrorCode(result.CodeInsufficientStake)
 	}
 
 	if tx.Purpose == core.StakeForGuardian {
-		minGuardianStake := core.MinGuardianStakeDeposit
-		if blockHeight >= common.HeightLowerGNStakeThresholdTo1000 {
-			minGuardianStake = core.MinGuardianStakeDeposit1000
-		}
+		minGuardianStake := core.MinGuardianStake(exec.state.GetChainID(), blockHeight)
 		if stake.ThetaWei.Cmp(minGuardianStake) < 0 {
 			return result.Error("Insufficient amount of stake, at least %v ThetaWei is required for each guardian deposit", minGuardianStake).
 				WithErrorCode(result.CodeInsufficientStake)
@@ -98,12 +95,12 @@ func (exec *DepositStakeExecutor) sanityCheck(chainID string, view *st.StoreView
 	}
 
 	if tx.Purpose == core.StakeForEliteEdgeNode {
-		if blockHeight < common.HeightEnableTheta3 {
-			return result.Error(fmt.Sprintf("Elite Edge Node staking not enabled yet, please wait until block height %v", common.HeightEnableTheta3)).WithErrorCode(result.CodeGenericError)
+		if !core.IsForkActive(exec.state.GetChainID(), core.ForkTheta3, blockHeight) {
+			return result.Error(fmt.Sprintf("Elite Edge Node staking not enabled yet, please wait until block height %v", core.ForkHeight(exec.state.GetChainID(), core.ForkTheta3))).WithErrorCode(result.CodeGenericError)
 		}
 
-		minEliteEdgeNodeStake := core.MinEliteEdgeNodeStakeDeposit
-		maxEliteEdgeNodeStake := core.MaxEliteEdgeNodeStakeDeposit
+		minEliteEdgeNodeStake := core.MinEliteEdgeNodeStake(exec.state.GetChainID())
+		maxEliteEdgeNodeStake := core.MaxEliteEdgeNodeStake(exec.state.GetChainID())
 
 		if stake.ThetaWei.Cmp(big.NewInt(0)) > 0 {
 			return result.Error("Only TFuel can be deposited for elite edge nodes").
@@ -160,7 +157,7 @@ func (exec *DepositStakeExecutor) process(chainID string, view *st.StoreView, tr
 		sourceAccount.Balance = sourceAccount.Balance.Minus(stake)
 		stakeAmount := stake.ThetaWei
 		vcp := view.GetValidatorCandidatePool()
-		err := vcp.DepositStake(sourceAddress, holderAddress, stakeAmount)
+		err := vcp.DepositStake(sourceAddress, holderAddress, stakeAmount, exec.state.GetChainID())
 		if err != nil {
 			return common.Hash{}, result.Error("Failed to deposit stake, err: %v", err)
 		}
@@ -177,7 +174,7 @@ func (exec *DepositStakeExecutor) process(chainID string, view *st.StoreView, tr
 			}
 		}
 
-		err := gcp.DepositStake(sourceAddress, holderAddress, stakeAmount, tx.BlsPubkey, blockHeight)
+		err := gcp.DepositStake(sourceAddress, holderAddress, stakeAmount, tx.BlsPubkey, exec.state.GetChainID(), blockHeight)
 		if err != nil {
 			return common.Hash{}, result.Error("Failed to deposit stake, err: %v", err)
 		}
@@ -194,7 +191,7 @@ func (exec *DepositStakeExecutor) process(chainID string, view *st.StoreView, tr
 			}
 		}
 
-		err := eenp.DepositStake(sourceAddress, holderAddress, stakeAmount, tx.BlsPubkey, blockHeight)
+		err := eenp.DepositStake(sourceAddress, holderAddress, stakeAmount, tx.BlsPubkey, exec.state.GetChainID(), blockHeight)
 		if err != nil {
 			return common.Hash{}, result.Error("Failed to deposit stake, err: %v", err)
 		}
@@ -0,0 +1,155 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*ParameterChangeTxExecutor)(nil)
+
+// ------------------------------- ParameterChange Transaction -----------------------------------
+
+// ParameterChangeTxExecutor implements the TxExecutor interface
+type ParameterChangeTxExecutor struct {
+	state  *st.LedgerState
+	valMgr core.ValidatorManager
+}
+
+// NewParameterChangeTxExecutor creates a new instance of ParameterChangeTxExecutor
+func NewParameterChangeTxExecutor(state *st.LedgerState, valMgr core.ValidatorManager) *ParameterChangeTxExecutor {
+	return &ParameterChangeTxExecutor{
+		state:  state,
+		valMgr: valMgr,
+	}
+}
+
+// getValidatorSet returns the validator set of the block being processed
+func (exec *ParameterChangeTxExecutor) getValidatorSet() *core.ValidatorSet {
+	parentBlock := exec.state.ParentBlock()
+	if parentBlock == nil {
+		panic("ledger state parentBlock is nil")
+	}
+	return exec.valMgr.GetNextValidatorSet(parentBlock.Hash())
+}
+
+func (exec *ParameterChangeTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	tx := transaction.(*types.ParameterChangeTx)
+
+	res := tx.Proposer.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	proposerAccount, res := getInput(view, tx.Proposer)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(proposerAccount, signBytes, tx.Proposer)
+	if res.IsError() {
+		return res
+	}
+
+	if err := core.ValidateParameterChange(tx.Parameter, tx.Value); err != nil {
+		return result.Error("Invalid parameter change: %v", err)
+	}
+
+	if _, err := exec.getValidatorSet().GetValidator(tx.Proposer.Address); err != nil {
+		return result.Error("The parameter change proposer %v is not a validator", tx.Proposer.Address)
+	}
+
+	for _, change := range view.GetParameterChanges() {
+		if change.Parameter == tx.Parameter && change.Value == tx.Value && change.IsApproved() {
+			return result.Error("The parameter change has already been approved, effective height: %v", change.EffectiveHeight)
+		}
+	}
+
+	if minTxFee, success := sanityCheckForFee(tx.Fee, exec.state.GetChainID(), blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+
+	if !proposerAccount.Balance.IsGTE(tx.Fee) {
+		return result.Error("the proposer account balance is %v, but required minimal balance is %v", proposerAccount.Balance, tx.Fee)
+	}
+
+	return result.OK
+}
+
+func (exec *ParameterChangeTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	tx := transaction.(*types.ParameterChangeTx)
+
+	proposerAccount, res := getInput(view, tx.Proposer)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	if !chargeFee(proposerAccount, tx.Fee) {
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	// A validator votes for at most one pending value of each parameter, so a new vote replaces
+	// the previous one of the validator
+	proposer := tx.Proposer.Address
+	changes := []*core.ParameterChange{}
+	var voted *core.ParameterChange
+	for _, change := range view.GetParameterChanges() {
+		if change.Parameter == tx.Parameter && !change.IsApproved() {
+			if change.Value == tx.Value {
+				voted = change
+			} else if change.RemoveVoter(proposer) && len(change.Voters) == 0 {
+				continue
+			}
+		}
+		changes = append(changes, change)
+	}
+	if voted == nil {
+		voted = &core.ParameterChange{
+			Parameter: tx.Parameter,
+			Value:     tx.Value,
+		}
+		changes = append(changes, voted)
+	}
+	if !voted.HasVoted(proposer) {
+		voted.Voters = append(voted.Voters, proposer)
+	}
+	if voted.HasMajority(exec.getValidatorSet()) {
+		voted.EffectiveHeight = blockHeight + core.ParameterChangeDelay
+		logger.Infof("Parameter change approved: parameter = %v, value = %v, effective height = %v",
+			voted.Parameter, voted.Value, voted.EffectiveHeight)
+	}
+	view.UpdateParameterChanges(changes)
+
+	proposerAccount.Sequence++
+	view.SetAccount(proposer, proposerAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *ParameterChangeTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.ParameterChangeTx)
+	return &core.TxInfo{
+		Address:           tx.Proposer.Address,
+		Sequence:          tx.Proposer.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *ParameterChangeTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.ParameterChangeTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
@@ -0,0 +1,182 @@
+package execution
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*ScheduledTxExecutor)(nil)
+
+// ------------------------------- Scheduled Transaction -----------------------------------
+
+// ScheduledTxExecutor implements the TxExecutor interface. It checks the block height against the schedule,
+// and delegates the wrapped transaction to its executor.
+type ScheduledTxExecutor struct {
+	executor *Executor
+}
+
+// NewScheduledTxExecutor creates a new instance of ScheduledTxExecutor
+func NewScheduledTxExecutor(executor *Executor) *ScheduledTxExecutor {
+	return &ScheduledTxExecutor{
+		executor: executor,
+	}
+}
+
+func (exec *ScheduledTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	tx := transaction.(*types.ScheduledTx)
+
+	if tx.NotBefore > blockHeight+types.MaxScheduledTxLead {
+		return result.Error("Transaction scheduled too far ahead: %v, max: %v",
+			tx.NotBefore, blockHeight+types.MaxScheduledTxLead)
+	}
+
+	innerTx, innerExec, res := exec.unwrap(view, tx)
+	if res.IsError() {
+		return res
+	}
+
+	if blockHeight < tx.NotBefore {
+		// The sequence and the balances can only be checked against the state at the scheduled height,
+		// but the transactions failing the stateless checks are rejected right away
+		if res := prescreenScheduledTx(chainID, tx.NotBefore, innerTx); res.IsError() {
+			return res
+		}
+		return result.Error("Transaction scheduled at height %v, current height: %v",
+			tx.NotBefore, blockHeight).WithErrorCode(result.CodeTxNotYetEligible)
+	}
+
+	return innerExec.sanityCheck(types.ScheduledTxChainID(chainID, tx.NotBefore), view, innerTx)
+}
+
+func (exec *ScheduledTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	tx := transaction.(*types.ScheduledTx)
+
+	innerTx, innerExec, res := exec.unwrap(view, tx)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	return innerExec.process(types.ScheduledTxChainID(chainID, tx.NotBefore), view, innerTx)
+}
+
+func (exec *ScheduledTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.ScheduledTx)
+
+	innerTx, err := types.TxFromBytes(tx.Tx)
+	if err != nil {
+		return &core.TxInfo{EffectiveGasPrice: big.NewInt(0)}
+	}
+	innerExec := exec.executor.getTxExecutor(innerTx)
+	if innerExec == nil {
+		return &core.TxInfo{EffectiveGasPrice: big.NewInt(0)}
+	}
+	return innerExec.getTxInfo(innerTx)
+}
+
+// unwrap decodes the wrapped transaction and finds its executor
+func (exec *ScheduledTxExecutor) unwrap(view *st.StoreView, tx *types.ScheduledTx) (types.Tx, TxExecutor, result.Result) {
+	innerTx, err := types.TxFromBytes(tx.Tx)
+	if err != nil {
+		return nil, nil, result.Error("Failed to parse the scheduled transaction: %v", err)
+	}
+
+	switch innerTx.(type) {
+	case *types.CoinbaseTx, *types.SlashTx, *types.ScheduledTx:
+		return nil, nil, result.Error("Transaction type cannot be scheduled")
+	}
+
+	if !exec.executor.isTxTypeSupported(view, innerTx) {
+		return nil, nil, result.Error("Scheduled tx type not supported yet")
+	}
+	innerExec := exec.executor.getTxExecutor(innerTx)
+	if innerExec == nil {
+		return nil, nil, result.Error("Unknown scheduled tx type")
+	}
+
+	return innerTx, innerExec, result.OK
+}
+
+// prescreenScheduledTx performs the checks of the wrapped transaction which do not depend on the state, i.e.
+// the basic validity of the inputs, the signatures and the fee at the scheduled height. Only the transaction
+// types whose signers are known here can be scheduled ahead. The chainID is the ID of the chain executing
+// the scheduled transaction, not the one the wrapped transaction is signed for.
+func prescreenScheduledTx(chainID string, notBefore uint64, innerTx types.Tx) result.Result {
+	var inputs []types.TxInput
+	var feePayers []types.FeePayer
+	var fee *types.Coins
+	switch tx := innerTx.(type) {
+	case *types.SendTx:
+		if len(tx.Inputs) == 0 || len(tx.Outputs) == 0 {
+			return result.Error("Invalid sendTx, Inputs and/or Outputs are empty")
+		}
+		numAccountsAffected := uint64(len(tx.Inputs) + len(tx.Outputs) + len(tx.FeePayers))
+		if minTxFee, success := sanityCheckForSendTxFee(tx.Fee, numAccountsAffected, chainID, notBefore); !success {
+			return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+				minTxFee).WithErrorCode(result.CodeInvalidFee)
+		}
+		inputs, feePayers = tx.Inputs, tx.FeePayers
+	case *types.SmartContractTx:
+		if !sanityCheckForGasPrice(tx.GasPrice, chainID, notBefore) {
+			minimumGasPrice := types.GetMinimumGasPrice(chainID, notBefore)
+			return result.Error("Insufficient gas price. Gas price needs to be at least %v TFuelWei", minimumGasPrice).
+				WithErrorCode(result.CodeInvalidGasPrice)
+		}
+		inputs, feePayers = []types.TxInput{tx.From}, tx.FeePayers
+	case *types.SmartContractTxV2:
+		// The base fee at the scheduled height is not known yet
+		inputs, feePayers = []types.TxInput{tx.From}, tx.FeePayers
+	case *types.DepositStakeTx:
+		inputs, fee = []types.TxInput{tx.Source}, &tx.Fee
+	case *types.DepositStakeTxV2:
+		inputs, fee = []types.TxInput{tx.Source}, &tx.Fee
+	case *types.WithdrawStakeTx:
+		inputs, fee = []types.TxInput{tx.Source}, &tx.Fee
+	case *types.StakeRewardDistributionTx:
+		inputs, fee = []types.TxInput{tx.Holder}, &tx.Fee
+	case *types.StakeAutoCompoundingTx:
+		inputs, fee = []types.TxInput{tx.Source}, &tx.Fee
+	case *types.CrossChainSendTx:
+		inputs, fee = []types.TxInput{tx.Sender}, &tx.Fee
+	case *types.ReserveFundTx:
+		inputs, fee = []types.TxInput{tx.Source}, &tx.Fee
+	case *types.ReleaseFundTx:
+		inputs, fee = []types.TxInput{tx.Source}, &tx.Fee
+	case *types.SplitRuleTx:
+		inputs, fee = []types.TxInput{tx.Initiator}, &tx.Fee
+	default:
+		return result.Error("Transaction type cannot be scheduled ahead: %T", innerTx)
+	}
+
+	if fee != nil {
+		if minTxFee, success := sanityCheckForFee(*fee, chainID, notBefore); !success {
+			return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+				minTxFee).WithErrorCode(result.CodeInvalidFee)
+		}
+	}
+
+	if res := validateInputsBasic(inputs); res.IsError() {
+		return res
+	}
+	signBytes := innerTx.SignBytes(types.ScheduledTxChainID(chainID, notBefore))
+	for _, in := range inputs {
+		if !in.Signature.Verify(signBytes, in.Address) {
+			return result.Error("Signature verification failed, SignBytes: %v",
+				hex.EncodeToString(signBytes)).WithErrorCode(result.CodeInvalidSignature)
+		}
+	}
+	for _, feePayer := range feePayers {
+		if !feePayer.Signature.Verify(signBytes, feePayer.Address) {
+			return result.Error("Fee payer signature verification failed, SignBytes: %v",
+				hex.EncodeToString(signBytes)).WithErrorCode(result.CodeInvalidSignature)
+		}
+	}
+	return result.OK
+}
@@ -44,7 +44,7 @@ func (exec *SendTxExecutor) sanityCheck(chainID string, view *st.StoreView, tran
 		return result.Error("Invalid sendTx, Inputs and/or Outputs are empty")
 	}
 
-	numAccountsAffected := uint64(len(tx.Inputs) + len(tx.Outputs))
+	numAccountsAffected := uint64(len(tx.Inputs) + len(tx.Outputs) + len(tx.FeePayers))
 	if numAccountsAffected > types.MaxAccountsAffectedPerTx {
 		return result.Error("Trasaction modifying too many accounts. At most %v accounts are allowed per transaction",
 			types.MaxAccountsAffectedPerTx)
@@ -63,7 +63,7 @@ func (exec *SendTxExecutor) sanityCheck(chainID string, view *st.StoreView, tran
 	}
 
 	blockHeight := view.Height() + 1
-	if blockHeight >= common.HeightEnableSmartContract {
+	if core.IsForkActive(exec.state.GetChainID(), core.ForkSmartContract, blockHeight) {
 		for _, outAcc := range accounts {
 			if outAcc.IsASmartContract() {
 				return result.Error(
@@ -79,12 +79,30 @@ func (exec *SendTxExecutor) sanityCheck(chainID string, view *st.StoreView, tran
 		return res
 	}
 
-	if minTxFee, success := sanityCheckForSendTxFee(tx.Fee, numAccountsAffected, blockHeight); !success {
+	if minTxFee, success := sanityCheckForSendTxFee(tx.Fee, numAccountsAffected, exec.state.GetChainID(), blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
 
 	outTotal := sumOutputs(tx.Outputs)
+	if len(tx.FeePayers) > 0 {
+		// The fee is paid by the fee payer instead of the inputs
+		others := []common.Address{}
+		for _, in := range tx.Inputs {
+			others = append(others, in.Address)
+		}
+		for _, out := range tx.Outputs {
+			others = append(others, out.Address)
+		}
+		if _, res := validateFeePayer(exec.state.GetChainID(), view, tx.FeePayers, signBytes, tx.Fee, others...); res.IsError() {
+			return res
+		}
+		if !inTotal.IsEqual(outTotal) {
+			return result.Error("Input total (%v) != output total (%v)", inTotal, outTotal)
+		}
+		return result.OK
+	}
+
 	outPlusFees := outTotal
 	outPlusFees = outTotal.Plus(tx.Fee)
 	if !inTotal.IsEqual(outPlusFees) {
@@ -107,6 +125,17 @@ func (exec *SendTxExecutor) process(chainID string, view *st.StoreView, transact
 		return common.Hash{}, res
 	}
 
+	if feePayer := tx.GetFeePayer(); feePayer != nil {
+		feePayerAccount, res := getAccount(view, feePayer.Address)
+		if res.IsError() {
+			return common.Hash{}, result.Error("Failed to get the fee payer account")
+		}
+		if !chargeFee(feePayerAccount, tx.Fee) {
+			return common.Hash{}, result.Error("failed to charge transaction fee")
+		}
+		view.SetAccount(feePayer.Address, feePayerAccount)
+	}
+
 	adjustByInputs(view, accounts, tx.Inputs)
 	adjustByOutputs(view, accounts, tx.Outputs)
 
@@ -126,7 +155,7 @@ func (exec *SendTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
 func (exec *SendTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
 	tx := transaction.(*types.SendTx)
 	fee := tx.Fee
-	numAccountsAffected := uint64(len(tx.Inputs) + len(tx.Outputs))
+	numAccountsAffected := uint64(len(tx.Inputs) + len(tx.Outputs) + len(tx.FeePayers))
 
 	gasSendTxPerAccount := getRegularTxGas(exec.state) / 2
 	gasUint64 := gasSendTxPerAccount * numAccountsAffected
@@ -3,6 +3,7 @@ package execution
 import (
 	"math/big"
 
+	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/result"
 	"github.com/thetatoken/theta/core"
@@ -15,13 +16,15 @@ var _ TxExecutor = (*SlashTxExecutor)(nil)
 // ------------------------------- Slash Transaction -----------------------------------
 
 type SlashTxExecutor struct {
+	chain     *blockchain.Chain
 	consensus core.ConsensusEngine
 	valMgr    core.ValidatorManager
 }
 
 // NewSlashTxExecutor creates a new instance of SlashTxExecutor
-func NewSlashTxExecutor(consensus core.ConsensusEngine, valMgr core.ValidatorManager) *SlashTxExecutor {
+func NewSlashTxExecutor(chain *blockchain.Chain, consensus core.ConsensusEngine, valMgr core.ValidatorManager) *SlashTxExecutor {
 	return &SlashTxExecutor{
+		chain:     chain,
 		consensus: consensus,
 		valMgr:    valMgr,
 	}
@@ -134,6 +137,12 @@ func (exec *SlashTxExecutor) process(chainID string, view *st.StoreView, transac
 	view.SetAccount(slashedAddress, slashedAccount)
 
 	txHash := types.TxID(chainID, tx)
+
+	// The penalty is recorded in the tx receipt, from which the slash history is indexed once the block
+	// is finalized
+	slashLog := blockchain.NewSlashLog(slashedAddress, proposerAddress, tx.ReserveSequence, slashedAmount)
+	exec.chain.AddTxReceipt(tx, []*types.Log{slashLog}, nil, common.Address{}, 0, nil, nil)
+
 	return txHash, result.OK
 }
 
@@ -0,0 +1,125 @@
+package execution
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+// slashTestLedger provides the current block for looking up the validator set.
+type slashTestLedger struct {
+	core.Ledger
+	block *core.Block
+}
+
+func (l *slashTestLedger) GetCurrentBlock() *core.Block { return l.block }
+
+type slashTestConsensusEngine struct {
+	*TestConsensusEngine
+	ledger core.Ledger
+}
+
+func (tce *slashTestConsensusEngine) GetLedger() core.Ledger { return tce.ledger }
+
+func TestSlashTxRecordsSlashHistory(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID := "test_chain_id"
+	initBlock := &core.Block{
+		BlockHeader: &core.BlockHeader{
+			ChainID: chainID,
+			Height:  1,
+		},
+	}
+	ledgerState := st.NewLedgerState(chainID, backend.NewMemDatabase())
+	ledgerState.ResetState(initBlock)
+	view := ledgerState.Delivered()
+
+	proposer := types.MakeAcc("proposer")
+	alice := types.MakeAcc("alice")
+	bob := types.MakeAcc("bob")
+	carol := types.MakeAcc("carol")
+	alice.Account.ReservedFunds = []types.ReservedFund{{
+		Collateral:      types.NewCoins(0, 1001),
+		InitialFund:     types.NewCoins(0, 1000),
+		UsedFund:        types.NewCoins(0, 0),
+		ResourceIDs:     []string{"rid001"},
+		EndBlockHeight:  100,
+		ReserveSequence: 1,
+	}}
+	for _, acc := range []types.PrivAccount{proposer, alice, bob, carol} {
+		view.SetAccount(acc.Account.Address, &acc.Account)
+	}
+
+	validator := core.NewValidator(proposer.Account.Address.String(), new(big.Int).SetUint64(999))
+	valSet := core.NewValidatorSet()
+	valSet.AddValidator(validator)
+	consensus := &slashTestConsensusEngine{
+		TestConsensusEngine: NewTestConsensusEngine("localseed"),
+		ledger:              &slashTestLedger{block: initBlock},
+	}
+	chain := blockchain.CreateTestChain()
+	exec := NewSlashTxExecutor(chain, consensus, NewTestValidatorManager(validator, valSet))
+
+	// Alice signed service payments to Bob and Carol exceeding her reserved fund
+	proof := types.OverspendingProof{
+		ReserveSequence: 1,
+		ServicePayments: []types.ServicePaymentTx{
+			*createServicePaymentTx(chainID, &alice, &bob, 800, 1, 1, 1, 1, "rid001"),
+			*createServicePaymentTx(chainID, &alice, &carol, 700, 1, 1, 2, 1, "rid001"),
+		},
+	}
+	proofBytes, err := types.ToBytes(&proof)
+	require.Nil(err)
+
+	slashTx := &types.SlashTx{
+		Proposer: types.TxInput{
+			Address:  proposer.Account.Address,
+			Sequence: 1,
+		},
+		SlashedAddress:  alice.Account.Address,
+		ReserveSequence: 1,
+		SlashProof:      proofBytes,
+	}
+	slashTx.Proposer.Signature = proposer.Sign(slashTx.SignBytes(chainID))
+
+	res := exec.sanityCheck(chainID, view, slashTx)
+	require.True(res.IsOK(), res.Message)
+	_, res = exec.process(chainID, view, slashTx)
+	require.True(res.IsOK(), res.Message)
+
+	slashedAmount := types.NewCoins(0, 2001)
+	assert.Equal(0, len(view.GetAccount(alice.Account.Address).ReservedFunds))
+	assert.Equal(proposer.Account.Balance.Plus(slashedAmount), view.GetAccount(proposer.Account.Address).Balance)
+
+	// Nothing is indexed until the block is finalized
+	_, found := chain.FindSlashHistory(alice.Account.Address)
+	assert.False(found)
+
+	rawTx, err := types.TxToBytes(slashTx)
+	require.Nil(err)
+	block := core.CreateTestBlock("b1", "")
+	block.Height = 2
+	block.Txs = []common.Bytes{rawTx}
+	eb, err := chain.AddBlock(block)
+	require.Nil(err)
+	require.Nil(chain.AddSlashRecords(eb, chain.FindBlockTxReceipts(eb)))
+
+	history, found := chain.FindSlashHistory(alice.Account.Address)
+	require.True(found)
+	require.Equal(1, len(history.Records))
+	assert.Equal(uint64(2), history.Records[0].Height)
+	assert.Equal("Overspent reserved fund 1", history.Records[0].Reason)
+	assert.Equal(proposer.Account.Address, history.Records[0].Beneficiary)
+	assert.Equal(0, slashedAmount.TFuelWei.Cmp(history.Records[0].SlashedAmount.TFuelWei))
+	assert.Equal(0, slashedAmount.ThetaWei.Cmp(history.Records[0].SlashedAmount.ThetaWei))
+}
@@ -32,10 +32,13 @@ func NewSmartContractTxExecutor(chain *blockchain.Chain, state *st.LedgerState)
 }
 
 func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
-	tx := transaction.(*types.SmartContractTx)
+	tx, signBytes, maxGasPrice, res := exec.castTx(chainID, view, transaction)
+	if res.IsError() {
+		return res
+	}
 
 	// Validate from, basic
-	res := tx.From.ValidateBasic()
+	res = tx.From.ValidateBasic()
 	if res.IsError() {
 		return res
 	}
@@ -47,7 +50,6 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 	}
 
 	// Validate input, advanced
-	signBytes := tx.SignBytes(chainID)
 	res = validateInputAdvanced(fromAccount, signBytes, tx.From)
 	if res.IsError() {
 		logger.Debugf(fmt.Sprintf("validateSourceAdvanced failed on %v: %v", tx.From.Address.Hex(), res))
@@ -61,20 +63,20 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 	}
 
 	blockHeight := getBlockHeight(exec.state)
-	if !sanityCheckForGasPrice(tx.GasPrice, blockHeight) {
-		minimumGasPrice := types.GetMinimumGasPrice(blockHeight)
+	if !sanityCheckForGasPrice(tx.GasPrice, exec.state.GetChainID(), blockHeight) {
+		minimumGasPrice := types.GetMinimumGasPrice(exec.state.GetChainID(), blockHeight)
 		return result.Error("Insufficient gas price. Gas price needs to be at least %v TFuelWei", minimumGasPrice).
 			WithErrorCode(result.CodeInvalidGasPrice)
 	}
 
-	maxGasLimit := types.GetMaxGasLimit(blockHeight)
+	maxGasLimit := types.GetMaxGasLimit(exec.state.GetChainID(), blockHeight)
 	if new(big.Int).SetUint64(tx.GasLimit).Cmp(maxGasLimit) > 0 {
 		return result.Error("Invalid gas limit. Gas limit needs to be at most %v", maxGasLimit).
 			WithErrorCode(result.CodeInvalidGasLimit)
 	}
 
 	zero := big.NewInt(0)
-	feeLimit := new(big.Int).Mul(tx.GasPrice, new(big.Int).SetUint64(tx.GasLimit))
+	feeLimit := new(big.Int).Mul(maxGasPrice, new(big.Int).SetUint64(tx.GasLimit))
 	if feeLimit.BitLen() > 255 || feeLimit.Cmp(zero) < 0 {
 		// There is no explicit upper limit for big.Int. Just be conservative
 		// here to prevent potential overflow attack
@@ -83,6 +85,17 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 	}
 
 	value := coins.TFuelWei // NoNil() already guarantees value is NOT nil
+	if len(tx.FeePayers) > 0 {
+		// The gas is paid by the fee payer instead of the sender
+		maxFee := types.Coins{
+			ThetaWei: zero,
+			TFuelWei: feeLimit,
+		}
+		if _, res := validateFeePayer(exec.state.GetChainID(), view, tx.FeePayers, signBytes, maxFee, tx.From.Address, tx.To.Address); res.IsError() {
+			return res
+		}
+		feeLimit = big.NewInt(0)
+	}
 	minimalBalance := types.Coins{
 		ThetaWei: zero,
 		TFuelWei: feeLimit.Add(feeLimit, value),
@@ -97,7 +110,10 @@ func (exec *SmartContractTxExecutor) sanityCheck(chainID string, view *st.StoreV
 }
 
 func (exec *SmartContractTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
-	tx := transaction.(*types.SmartContractTx)
+	tx, _, _, res := exec.castTx(chainID, view, transaction)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
 
 	view.ResetLogs()
 
@@ -117,7 +133,16 @@ func (exec *SmartContractTxExecutor) process(chainID string, view *st.StoreView,
 		ThetaWei: big.NewInt(int64(0)),
 		TFuelWei: feeAmount,
 	}
-	if !chargeFee(fromAccount, fee) {
+	if feePayer := tx.GetFeePayer(); feePayer != nil {
+		feePayerAccount, res := getAccount(view, feePayer.Address)
+		if res.IsError() {
+			return common.Hash{}, result.Error("Failed to get the fee payer account")
+		}
+		if !chargeFee(feePayerAccount, fee) {
+			return common.Hash{}, result.Error("failed to charge transaction fee")
+		}
+		view.SetAccount(feePayer.Address, feePayerAccount)
+	} else if !chargeFee(fromAccount, fee) {
 		return common.Hash{}, result.Error("failed to charge transaction fee")
 	}
 
@@ -127,7 +152,11 @@ func (exec *SmartContractTxExecutor) process(chainID string, view *st.StoreView,
 	}
 	view.SetAccount(fromAddress, fromAccount)
 
-	txHash := types.TxID(chainID, tx)
+	if core.IsForkActive(exec.state.GetChainID(), core.ForkDynamicBaseFee, view.Height()+1) {
+		view.AddBlockGasUsed(gasUsed)
+	}
+
+	txHash := types.TxID(chainID, transaction)
 
 	// TODO: Add tx receipt: status and events
 	logs := view.PopLogs()
@@ -135,21 +164,73 @@ func (exec *SmartContractTxExecutor) process(chainID string, view *st.StoreView,
 		// Do not record events if transaction is reverted
 		logs = nil
 	}
-	exec.chain.AddTxReceipt(tx, logs, evmRet, contractAddr, gasUsed, evmErr)
+	exec.chain.AddTxReceipt(transaction, logs, evmRet, contractAddr, gasUsed, tx.GasPrice, evmErr)
 
 	return txHash, result.OK
 }
 
 func (exec *SmartContractTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
-	tx := transaction.(*types.SmartContractTx)
+	var from types.TxInput
+	switch tx := transaction.(type) {
+	case *types.SmartContractTx:
+		from = tx.From
+	case *types.SmartContractTxV2:
+		from = tx.From
+	}
 	return &core.TxInfo{
-		Address:           tx.From.Address,
-		Sequence:          tx.From.Sequence,
+		Address:           from.Address,
+		Sequence:          from.Sequence,
 		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
 	}
 }
 
 func (exec *SmartContractTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
-	tx := transaction.(*types.SmartContractTx)
-	return tx.GasPrice
+	switch tx := transaction.(type) {
+	case *types.SmartContractTxV2:
+		baseFee := GetBaseFee(exec.state.GetChainID(), exec.state.Delivered())
+		if baseFee == nil || tx.MaxFeePerGas == nil || tx.MaxPriorityFeePerGas == nil {
+			return big.NewInt(0)
+		}
+		return tx.EffectiveGasPrice(baseFee)
+	default:
+		return transaction.(*types.SmartContractTx).GasPrice
+	}
+}
+
+// castTx returns the SmartContractTx for the EVM to execute, its sign bytes, and the max gas price the sender
+// needs to be able to pay. Once the dynamic base fee is active, a SmartContractTx pays its gas price, which needs
+// to cover the base fee, and a SmartContractTxV2 pays the base fee plus its priority fee, capped by its max fee.
+// Theta burns the transaction fees, so both the base fee and the priority fee are burned. The priority fee only
+// affects the order of the transactions in the mempool.
+func (exec *SmartContractTxExecutor) castTx(chainID string, view *st.StoreView, transaction types.Tx) (
+	*types.SmartContractTx, []byte, *big.Int, result.Result) {
+	baseFee := GetBaseFee(exec.state.GetChainID(), view)
+
+	switch tx := transaction.(type) {
+	case *types.SmartContractTx:
+		if baseFee != nil && tx.GasPrice != nil && tx.GasPrice.Cmp(baseFee) < 0 {
+			return nil, nil, nil, result.Error("Insufficient gas price. Gas price needs to be at least the base fee %v TFuelWei",
+				baseFee).WithErrorCode(result.CodeInvalidGasPrice)
+		}
+		return tx, tx.SignBytes(chainID), tx.GasPrice, result.OK
+	case *types.SmartContractTxV2:
+		if baseFee == nil {
+			return nil, nil, nil, result.Error("Dynamic base fee not active yet")
+		}
+		if tx.MaxFeePerGas == nil || tx.MaxPriorityFeePerGas == nil ||
+			tx.MaxFeePerGas.Sign() < 0 || tx.MaxPriorityFeePerGas.Sign() < 0 {
+			return nil, nil, nil, result.Error("Invalid max fee per gas or max priority fee per gas").
+				WithErrorCode(result.CodeInvalidGasPrice)
+		}
+		if tx.MaxPriorityFeePerGas.Cmp(tx.MaxFeePerGas) > 0 {
+			return nil, nil, nil, result.Error("Max priority fee per gas %v exceeds the max fee per gas %v",
+				tx.MaxPriorityFeePerGas, tx.MaxFeePerGas).WithErrorCode(result.CodeInvalidGasPrice)
+		}
+		if tx.MaxFeePerGas.Cmp(baseFee) < 0 {
+			return nil, nil, nil, result.Error("Insufficient max fee per gas. It needs to be at least the base fee %v TFuelWei",
+				baseFee).WithErrorCode(result.CodeInvalidGasPrice)
+		}
+		return tx.ToSmartContractTx(tx.EffectiveGasPrice(baseFee)), tx.SignBytes(chainID), tx.MaxFeePerGas, result.OK
+	}
+	return nil, nil, nil, result.Error("Unknown smart contract tx type")
 }
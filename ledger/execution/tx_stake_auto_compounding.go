@@ -0,0 +1,127 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*StakeAutoCompoundingTxExecutor)(nil)
+
+// ------------------------------- StakeAutoCompounding Transaction -----------------------------------
+
+// StakeAutoCompoundingTxExecutor implements the TxExecutor interface
+type StakeAutoCompoundingTxExecutor struct {
+	state *st.LedgerState
+}
+
+// NewStakeAutoCompoundingTxExecutor creates a new instance of StakeAutoCompoundingTxExecutor
+func NewStakeAutoCompoundingTxExecutor(state *st.LedgerState) *StakeAutoCompoundingTxExecutor {
+	return &StakeAutoCompoundingTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *StakeAutoCompoundingTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	tx := transaction.(*types.StakeAutoCompoundingTx)
+
+	res := tx.Source.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	sourceAccount, res := getInput(view, tx.Source)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(sourceAccount, signBytes, tx.Source)
+	if res.IsError() {
+		return res
+	}
+
+	if tx.Enabled {
+		if !hasActiveEliteEdgeNodeStake(view, tx.Source.Address, tx.Holder) {
+			return result.Error("%v has no active stake to elite edge node %v", tx.Source.Address, tx.Holder)
+		}
+	} else if view.GetStakeAutoCompounding(tx.Source.Address) == nil {
+		return result.Error("Stake auto-compounding is not enabled for %v", tx.Source.Address)
+	}
+
+	if minTxFee, success := sanityCheckForFee(tx.Fee, exec.state.GetChainID(), blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+
+	if !sourceAccount.Balance.IsGTE(tx.Fee) {
+		return result.Error("the source account balance is %v, but required minimal balance is %v", sourceAccount.Balance, tx.Fee)
+	}
+
+	return result.OK
+}
+
+func (exec *StakeAutoCompoundingTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	tx := transaction.(*types.StakeAutoCompoundingTx)
+
+	sourceAccount, res := getInput(view, tx.Source)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	if !chargeFee(sourceAccount, tx.Fee) {
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	if tx.Enabled {
+		view.SetStakeAutoCompounding(&core.StakeAutoCompounding{
+			Source: tx.Source.Address,
+			Holder: tx.Holder,
+		})
+	} else {
+		view.DeleteStakeAutoCompounding(tx.Source.Address)
+	}
+
+	sourceAccount.Sequence++
+	view.SetAccount(tx.Source.Address, sourceAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *StakeAutoCompoundingTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.StakeAutoCompoundingTx)
+	return &core.TxInfo{
+		Address:           tx.Source.Address,
+		Sequence:          tx.Source.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *StakeAutoCompoundingTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.StakeAutoCompoundingTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
+
+// hasActiveEliteEdgeNodeStake returns whether the source has a stake to the elite edge node which is not withdrawn
+func hasActiveEliteEdgeNodeStake(view *st.StoreView, source common.Address, holder common.Address) bool {
+	een := st.NewEliteEdgeNodePool(view, true).Get(holder)
+	if een == nil {
+		return false
+	}
+	for _, stake := range een.Stakes {
+		if stake.Source == source && !stake.Withdrawn {
+			return true
+		}
+	}
+	return false
+}
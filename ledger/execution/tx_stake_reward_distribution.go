@@ -99,7 +99,7 @@ func (exec *StakeRewardDistributionTxExecutor) sanityCheck(chainID string, view
 	// 	return result.Error("Invalid purpose: %v", tx.Purpose)
 	// }
 
-	if minTxFee, success := sanityCheckForFee(tx.Fee, blockHeight); !success {
+	if minTxFee, success := sanityCheckForFee(tx.Fee, exec.state.GetChainID(), blockHeight); !success {
 		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
 			minTxFee).WithErrorCode(result.CodeInvalidFee)
 	}
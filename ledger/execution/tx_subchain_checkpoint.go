@@ -0,0 +1,135 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*SubchainCheckpointTxExecutor)(nil)
+
+// ------------------------------- SubchainCheckpoint Transaction -----------------------------------
+
+// SubchainCheckpointTxExecutor implements the TxExecutor interface
+type SubchainCheckpointTxExecutor struct {
+	state *st.LedgerState
+}
+
+// NewSubchainCheckpointTxExecutor creates a new instance of SubchainCheckpointTxExecutor
+func NewSubchainCheckpointTxExecutor(state *st.LedgerState) *SubchainCheckpointTxExecutor {
+	return &SubchainCheckpointTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *SubchainCheckpointTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	tx := transaction.(*types.SubchainCheckpointTx)
+
+	res := tx.Submitter.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	submitterAccount, res := getInput(view, tx.Submitter)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(submitterAccount, signBytes, tx.Submitter)
+	if res.IsError() {
+		return res
+	}
+
+	if err := core.ValidateSubchainID(tx.SubchainID, chainID); err != nil {
+		return result.Error("Invalid subchain checkpoint: %v", err)
+	}
+
+	if tx.BlockHash.IsEmpty() {
+		return result.Error("Invalid subchain checkpoint: block hash is empty")
+	}
+
+	subchain := view.GetSubchain(tx.SubchainID)
+	if subchain == nil {
+		return result.Error("Subchain %v has not been registered", tx.SubchainID)
+	}
+	if subchain.Operator != tx.Submitter.Address {
+		return result.Error("Only the operator %v can submit the checkpoints of subchain %v", subchain.Operator, tx.SubchainID)
+	}
+	if tx.Height <= subchain.LatestHeight {
+		return result.Error("Subchain checkpoint height %v is not above the latest anchored height %v", tx.Height, subchain.LatestHeight)
+	}
+
+	if minTxFee, success := sanityCheckForFee(tx.Fee, exec.state.GetChainID(), blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+
+	if !submitterAccount.Balance.IsGTE(tx.Fee) {
+		return result.Error("the submitter account balance is %v, but required minimal balance is %v", submitterAccount.Balance, tx.Fee)
+	}
+
+	return result.OK
+}
+
+func (exec *SubchainCheckpointTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	tx := transaction.(*types.SubchainCheckpointTx)
+
+	submitterAccount, res := getInput(view, tx.Submitter)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	if !chargeFee(submitterAccount, tx.Fee) {
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	submitter := tx.Submitter.Address
+	subchain := view.GetSubchain(tx.SubchainID)
+	if subchain == nil {
+		return common.Hash{}, result.Error("Subchain %v has not been registered", tx.SubchainID)
+	}
+	subchain.LatestHeight = tx.Height
+	subchain.NumAnchors++
+	view.SetSubchain(subchain)
+
+	view.SetSubchainCheckpoint(&core.SubchainCheckpoint{
+		SubchainID:   tx.SubchainID,
+		Height:       tx.Height,
+		BlockHash:    tx.BlockHash,
+		StateHash:    tx.StateHash,
+		Submitter:    submitter,
+		AnchorHeight: blockHeight,
+	})
+
+	submitterAccount.Sequence++
+	view.SetAccount(submitter, submitterAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *SubchainCheckpointTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.SubchainCheckpointTx)
+	return &core.TxInfo{
+		Address:           tx.Submitter.Address,
+		Sequence:          tx.Submitter.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *SubchainCheckpointTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.SubchainCheckpointTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
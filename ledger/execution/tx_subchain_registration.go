@@ -0,0 +1,171 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*SubchainRegistrationTxExecutor)(nil)
+
+// ------------------------------- SubchainRegistration Transaction -----------------------------------
+
+// SubchainRegistrationTxExecutor implements the TxExecutor interface
+type SubchainRegistrationTxExecutor struct {
+	state  *st.LedgerState
+	valMgr core.ValidatorManager
+}
+
+// NewSubchainRegistrationTxExecutor creates a new instance of SubchainRegistrationTxExecutor
+func NewSubchainRegistrationTxExecutor(state *st.LedgerState, valMgr core.ValidatorManager) *SubchainRegistrationTxExecutor {
+	return &SubchainRegistrationTxExecutor{
+		state:  state,
+		valMgr: valMgr,
+	}
+}
+
+// getValidatorSet returns the validator set of the block being processed
+func (exec *SubchainRegistrationTxExecutor) getValidatorSet() *core.ValidatorSet {
+	parentBlock := exec.state.ParentBlock()
+	if parentBlock == nil {
+		panic("ledger state parentBlock is nil")
+	}
+	return exec.valMgr.GetNextValidatorSet(parentBlock.Hash())
+}
+
+func (exec *SubchainRegistrationTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	tx := transaction.(*types.SubchainRegistrationTx)
+
+	res := tx.Proposer.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	proposerAccount, res := getInput(view, tx.Proposer)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(proposerAccount, signBytes, tx.Proposer)
+	if res.IsError() {
+		return res
+	}
+
+	if err := core.ValidateSubchainID(tx.SubchainID, chainID); err != nil {
+		return result.Error("Invalid subchain registration: %v", err)
+	}
+
+	if tx.Operator.IsEmpty() {
+		return result.Error("Invalid subchain registration: operator is empty")
+	}
+
+	if _, err := exec.getValidatorSet().GetValidator(tx.Proposer.Address); err != nil {
+		return result.Error("The subchain registration proposer %v is not a validator", tx.Proposer.Address)
+	}
+
+	if subchain := view.GetSubchain(tx.SubchainID); subchain != nil && subchain.Operator == tx.Operator {
+		return result.Error("Subchain %v has already been registered with operator %v", tx.SubchainID, tx.Operator)
+	}
+
+	if minTxFee, success := sanityCheckForFee(tx.Fee, exec.state.GetChainID(), blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+
+	if !proposerAccount.Balance.IsGTE(tx.Fee) {
+		return result.Error("the proposer account balance is %v, but required minimal balance is %v", proposerAccount.Balance, tx.Fee)
+	}
+
+	return result.OK
+}
+
+func (exec *SubchainRegistrationTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	tx := transaction.(*types.SubchainRegistrationTx)
+
+	proposerAccount, res := getInput(view, tx.Proposer)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	if !chargeFee(proposerAccount, tx.Fee) {
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	// A validator votes for at most one pending operator of each subchain, so a new vote replaces
+	// the previous one of the validator
+	proposer := tx.Proposer.Address
+	registrations := []*core.SubchainRegistration{}
+	var voted *core.SubchainRegistration
+	for _, registration := range view.GetSubchainRegistrations() {
+		if registration.SubchainID == tx.SubchainID {
+			if registration.Operator == tx.Operator {
+				voted = registration
+			} else if registration.RemoveVoter(proposer) && len(registration.Voters) == 0 {
+				continue
+			}
+		}
+		registrations = append(registrations, registration)
+	}
+	if voted == nil {
+		voted = &core.SubchainRegistration{
+			SubchainID: tx.SubchainID,
+			Operator:   tx.Operator,
+		}
+		registrations = append(registrations, voted)
+	}
+	if !voted.HasVoted(proposer) {
+		voted.Voters = append(voted.Voters, proposer)
+	}
+
+	if voted.HasMajority(exec.getValidatorSet()) {
+		subchain := view.GetSubchain(tx.SubchainID)
+		if subchain == nil {
+			subchain = &core.Subchain{
+				SubchainID: tx.SubchainID,
+			}
+		}
+		subchain.Operator = tx.Operator
+		view.SetSubchain(subchain)
+		logger.Infof("Subchain registered: subchain_id = %v, operator = %v", tx.SubchainID, tx.Operator)
+
+		// The approval settles all the pending registrations of the subchain
+		pending := []*core.SubchainRegistration{}
+		for _, registration := range registrations {
+			if registration.SubchainID != tx.SubchainID {
+				pending = append(pending, registration)
+			}
+		}
+		registrations = pending
+	}
+	view.UpdateSubchainRegistrations(registrations)
+
+	proposerAccount.Sequence++
+	view.SetAccount(proposer, proposerAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *SubchainRegistrationTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.SubchainRegistrationTx)
+	return &core.TxInfo{
+		Address:           tx.Proposer.Address,
+		Sequence:          tx.Proposer.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *SubchainRegistrationTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.SubchainRegistrationTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
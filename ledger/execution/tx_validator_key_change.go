@@ -0,0 +1,137 @@
+package execution
+
+import (
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+var _ TxExecutor = (*ValidatorKeyChangeTxExecutor)(nil)
+
+// ------------------------------- ValidatorKeyChange Transaction -----------------------------------
+
+// ValidatorKeyChangeTxExecutor implements the TxExecutor interface
+type ValidatorKeyChangeTxExecutor struct {
+	state *st.LedgerState
+}
+
+// NewValidatorKeyChangeTxExecutor creates a new instance of ValidatorKeyChangeTxExecutor
+func NewValidatorKeyChangeTxExecutor(state *st.LedgerState) *ValidatorKeyChangeTxExecutor {
+	return &ValidatorKeyChangeTxExecutor{
+		state: state,
+	}
+}
+
+func (exec *ValidatorKeyChangeTxExecutor) sanityCheck(chainID string, view *st.StoreView, transaction types.Tx) result.Result {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	tx := transaction.(*types.ValidatorKeyChangeTx)
+
+	res := tx.Source.ValidateBasic()
+	if res.IsError() {
+		return res
+	}
+
+	sourceAccount, res := getInput(view, tx.Source)
+	if res.IsError() {
+		return res
+	}
+
+	signBytes := tx.SignBytes(chainID)
+	res = validateInputAdvanced(sourceAccount, signBytes, tx.Source)
+	if res.IsError() {
+		return res
+	}
+
+	if tx.NewHolder.IsEmpty() || tx.NewHolder == tx.Holder {
+		return result.Error("Invalid new holder address: %v", tx.NewHolder)
+	}
+	if tx.NewHolderSignature == nil || !tx.NewHolderSignature.Verify(signBytes, tx.NewHolder) {
+		return result.Error("Signature verification failed for the new holder %v", tx.NewHolder).
+			WithErrorCode(result.CodeInvalidSignature)
+	}
+
+	// The stakes of other sources cannot be moved without their consent
+	vcp := view.GetValidatorCandidatePool()
+	candidate := vcp.FindStakeDelegate(tx.Holder)
+	if candidate == nil {
+		return result.Error("%v is not a validator candidate", tx.Holder)
+	}
+	for _, stake := range candidate.Stakes {
+		if stake.Source != tx.Source.Address {
+			return result.Error("Validator %v has stakes from other sources, e.g. %v", tx.Holder, stake.Source)
+		}
+	}
+	if vcp.FindStakeDelegate(tx.NewHolder) != nil {
+		return result.Error("New holder %v is already a validator candidate", tx.NewHolder)
+	}
+
+	for _, change := range view.GetValidatorKeyChanges() {
+		if change.Holder == tx.Holder || change.NewHolder == tx.NewHolder {
+			return result.Error("Conflicting validator key change pending, effective height: %v", change.EffectiveHeight)
+		}
+	}
+
+	if minTxFee, success := sanityCheckForFee(tx.Fee, exec.state.GetChainID(), blockHeight); !success {
+		return result.Error("Insufficient fee. Transaction fee needs to be at least %v TFuelWei",
+			minTxFee).WithErrorCode(result.CodeInvalidFee)
+	}
+
+	if !sourceAccount.Balance.IsGTE(tx.Fee) {
+		return result.Error("the source account balance is %v, but required minimal balance is %v", sourceAccount.Balance, tx.Fee)
+	}
+
+	return result.OK
+}
+
+func (exec *ValidatorKeyChangeTxExecutor) process(chainID string, view *st.StoreView, transaction types.Tx) (common.Hash, result.Result) {
+	blockHeight := view.Height() + 1 // the view points to the parent of the current block
+
+	tx := transaction.(*types.ValidatorKeyChangeTx)
+
+	sourceAccount, res := getInput(view, tx.Source)
+	if res.IsError() {
+		return common.Hash{}, res
+	}
+
+	if !chargeFee(sourceAccount, tx.Fee) {
+		return common.Hash{}, result.Error("failed to charge transaction fee")
+	}
+
+	change := &core.ValidatorKeyChange{
+		Holder:          tx.Holder,
+		NewHolder:       tx.NewHolder,
+		NewSource:       tx.NewSource,
+		EffectiveHeight: blockHeight + core.ValidatorKeyChangeDelay,
+	}
+	view.UpdateValidatorKeyChanges(append(view.GetValidatorKeyChanges(), change))
+	logger.Infof("Validator key change scheduled: holder = %v, new holder = %v, effective height = %v",
+		change.Holder, change.NewHolder, change.EffectiveHeight)
+
+	sourceAccount.Sequence++
+	view.SetAccount(tx.Source.Address, sourceAccount)
+
+	txHash := types.TxID(chainID, tx)
+	return txHash, result.OK
+}
+
+func (exec *ValidatorKeyChangeTxExecutor) getTxInfo(transaction types.Tx) *core.TxInfo {
+	tx := transaction.(*types.ValidatorKeyChangeTx)
+	return &core.TxInfo{
+		Address:           tx.Source.Address,
+		Sequence:          tx.Source.Sequence,
+		EffectiveGasPrice: exec.calculateEffectiveGasPrice(transaction),
+	}
+}
+
+func (exec *ValidatorKeyChangeTxExecutor) calculateEffectiveGasPrice(transaction types.Tx) *big.Int {
+	tx := transaction.(*types.ValidatorKeyChangeTx)
+	fee := tx.Fee
+	gas := new(big.Int).SetUint64(getRegularTxGas(exec.state))
+	effectiveGasPrice := new(big.Int).Div(fee.TFuelWei, gas)
+	return effectiveGasPrice
+}
@@ -2,9 +2,12 @@ package ledger
 
 import (
 	"encoding/hex"
+	"bytes"
 	"fmt"
+	"sort"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/viper"
@@ -29,6 +32,7 @@ import (
 var logger *log.Entry = log.WithFields(log.Fields{"prefix": "ledger"})
 
 var _ core.Ledger = (*Ledger)(nil)
+var _ blockchain.BalanceReader = (*Ledger)(nil)
 
 //
 // Ledger implements the core.Ledger interface
@@ -44,12 +48,23 @@ type Ledger struct {
 	mu       *sync.RWMutex // Lock for accessing ledger state.
 	state    *st.LedgerState
 	executor *exec.Executor
+
+	shadow   bool        // Whether to record the state root divergences instead of rejecting the blocks
+	shadowMu *sync.Mutex // Lock for accessing the shadow report.
+
+	txSelection *txSelectionPolicy // How to pick the mempool txs for the block proposals
+
+	pruningPauses int32 // Number of the pending PauseStatePruning() calls
 }
 
 // NewLedger creates an instance of Ledger
 func NewLedger(chainID string, db database.Database, chain *blockchain.Chain, consensus core.ConsensusEngine, valMgr core.ValidatorManager, mempool *mp.Mempool) *Ledger {
 	state := st.NewLedgerState(chainID, db)
 	executor := exec.NewExecutor(db, chain, state, consensus, valMgr)
+	txSelection, err := loadTxSelectionPolicy()
+	if err != nil {
+		logger.Fatalf("Invalid tx selection config: %v", err)
+	}
 	ledger := &Ledger{
 		db:        db,
 		chain:     chain,
@@ -59,6 +74,10 @@ func NewLedger(chainID string, db database.Database, chain *blockchain.Chain, co
 		mu:        &sync.RWMutex{},
 		state:     state,
 		executor:  executor,
+		shadow:    viper.GetBool(common.CfgShadowEnabled),
+		shadowMu:  &sync.Mutex{},
+
+		txSelection: txSelection,
 	}
 	return ledger
 }
@@ -109,7 +128,7 @@ func (ledger *Ledger) GetFinalizedValidatorCandidatePool(blockHash common.Hash,
 		i = 2
 	}
 	for ; ; i-- {
-		block, err := findBlock(store, blockHash)
+		block, err := ledger.findBlock(store, blockHash)
 		if err != nil {
 			logger.Errorf("Failed to find block for VCP: %v, err: %v", blockHash.Hex(), err)
 			return nil, err
@@ -146,7 +165,7 @@ func (ledger *Ledger) GetGuardianCandidatePool(blockHash common.Hash) (*core.Gua
 	store := kvstore.NewKVStore(db)
 
 	// Find last checkpoint and retrieve GCP.
-	block, err := findBlock(store, blockHash)
+	block, err := ledger.findBlock(store, blockHash)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +173,7 @@ func (ledger *Ledger) GetGuardianCandidatePool(blockHash common.Hash) (*core.Gua
 	for {
 		logger.Debugf("Ledger.GetGuardianCandidatePool, block.height = %v", block.Height)
 
-		block, err := findBlock(store, blockHash)
+		block, err := ledger.findBlock(store, blockHash)
 		if err != nil {
 			return nil, err
 		}
@@ -174,7 +193,7 @@ func (ledger *Ledger) GetEliteEdgeNodePoolOfLastCheckpoint(blockHash common.Hash
 	store := kvstore.NewKVStore(db)
 
 	// Find last checkpoint and retrieve EENP.
-	block, err := findBlock(store, blockHash)
+	block, err := ledger.findBlock(store, blockHash)
 	if err != nil {
 		return nil, err
 	}
@@ -182,7 +201,7 @@ func (ledger *Ledger) GetEliteEdgeNodePoolOfLastCheckpoint(blockHash common.Hash
 	for {
 		logger.Debugf("Ledger.GetEliteEdgeNodePoolOfLastCheckpoint, block.height = %v", block.Height)
 
-		block, err := findBlock(store, blockHash)
+		block, err := ledger.findBlock(store, blockHash)
 		if err != nil {
 			return nil, err
 		}
@@ -196,9 +215,52 @@ func (ledger *Ledger) GetEliteEdgeNodePoolOfLastCheckpoint(blockHash common.Hash
 	}
 }
 
-func findBlock(store store.Store, blockHash common.Hash) (*core.ExtendedBlock, error) {
+// GetRewardBreakdown recalculates the block reward distributed by the coinbase transaction of the given
+// block, and returns the breakdown of the reward for each stake.
+func (ledger *Ledger) GetRewardBreakdown(blockHash common.Hash) (*exec.RewardBreakdown, error) {
+	db := ledger.state.DB()
+	store := kvstore.NewKVStore(db)
+
+	block, err := ledger.findBlock(store, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsCheckPointHeight(block.Height) {
+		return nil, fmt.Errorf("Block %v at height %v is not a checkpoint, rewards are only distributed at checkpoints",
+			blockHash.Hex(), block.Height)
+	}
+	parentBlock, err := ledger.findBlock(store, block.Parent)
+	if err != nil {
+		return nil, err
+	}
+
+	// The coinbase transaction was calculated on the state of the parent block
+	view := st.NewStoreView(parentBlock.Height, parentBlock.StateHash, db)
+	if view == nil {
+		return nil, fmt.Errorf("The state of block %v is not available, it might have been pruned", block.Parent.Hex())
+	}
+	validatorSet := ledger.valMgr.GetNextValidatorSet(block.Parent)
+
+	guardianVotes := block.GuardianVotes
+	eliteEdgeNodeVotes := block.EliteEdgeNodeVotes
+	var breakdown *exec.RewardBreakdown
+	if guardianVotes != nil && core.IsForkActive(ledger.state.GetChainID(), core.ForkTheta2, block.Height) {
+		guardianPool, eliteEdgeNodePool := exec.RetrievePools(ledger, ledger.chain, db, block.Height, guardianVotes, eliteEdgeNodeVotes)
+		_, breakdown = exec.CalculateRewardBreakdown(ledger.state.GetChainID(), ledger, view, validatorSet, guardianVotes, guardianPool, eliteEdgeNodeVotes, eliteEdgeNodePool)
+	} else {
+		_, breakdown = exec.CalculateRewardBreakdown(ledger.state.GetChainID(), ledger, view, validatorSet, nil, nil, nil, nil)
+	}
+
+	return breakdown, nil
+}
+
+// findBlock looks up the given block in the store, or through the chain if it has been moved to the ancient store
+func (ledger *Ledger) findBlock(kvStore store.Store, blockHash common.Hash) (*core.ExtendedBlock, error) {
 	var block core.ExtendedBlock
-	err := store.Get(blockHash[:], &block)
+	err := kvStore.Get(blockHash[:], &block)
+	if err == store.ErrKeyNotFound && ledger.chain != nil {
+		return ledger.chain.FindBlock(blockHash)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -234,6 +296,15 @@ func (ledger *Ledger) ScreenTx(rawTx common.Bytes) (txInfo *core.TxInfo, res res
 	defer ledger.mu.RUnlock()
 
 	_, res = ledger.executor.ScreenTx(tx)
+	if res.Code == result.CodeTxNotYetEligible {
+		// The scheduled transaction passed the checks possible before its scheduled height, the tx info
+		// lets the mempool account it to its sender
+		txInfo, infoRes := ledger.executor.GetTxInfo(tx)
+		if infoRes.IsError() {
+			return nil, infoRes
+		}
+		return txInfo, res
+	}
 	if res.IsError() {
 		return nil, res
 	}
@@ -246,6 +317,36 @@ func (ledger *Ledger) ScreenTx(rawTx common.Bytes) (txInfo *core.TxInfo, res res
 	return txInfo, res
 }
 
+// PrecheckTx checks the given transaction against a snapshot of the screened state, without executing it or
+// adding it to the mempool. Unlike ScreenTx, it returns all the problems found instead of the first one. The
+// validity checks of the transaction stop at the first failure though, e.g. the signature is not verified
+// when the sequence is invalid.
+func (ledger *Ledger) PrecheckTx(tx types.Tx) []result.Result {
+	problems := []result.Result{}
+	if ledger.shouldSkipCheckTx(tx) {
+		problems = append(problems, result.Error("Unauthorized transaction, should skip").
+			WithErrorCode(result.CodeUnauthorizedTx))
+	}
+
+	view, err := ledger.GetScreenedSnapshot()
+	if err != nil {
+		return append(problems, result.Error("Failed to get the screened state: %v", err))
+	}
+
+	if core.IsForkActive(ledger.state.GetChainID(), core.ForkParameterChange, view.Height()+1) {
+		blockGasLimit := view.GetGovernanceParams().BlockGasLimit
+		if txGas := txGasLimit(tx); txGas > blockGasLimit {
+			problems = append(problems, result.Error("Gas limit %v exceeds the block gas limit %v", txGas, blockGasLimit).
+				WithErrorCode(result.CodeInvalidGasLimit))
+		}
+	}
+
+	if res := ledger.executor.PrecheckTx(view, tx); res.IsError() && res.Code != result.CodeTxNotYetEligible {
+		problems = append(problems, res)
+	}
+	return problems
+}
+
 // ProposeBlockTxs collects and executes a list of transactions, which will be used to assemble the next blockl
 // It also clears these transactions from the mempool.
 func (ledger *Ledger) ProposeBlockTxs(block *core.Block) (stateRootHash common.Hash, blockRawTxs []common.Bytes, res result.Result) {
@@ -267,23 +368,53 @@ func (ledger *Ledger) ProposeBlockTxs(block *core.Block) (stateRootHash common.H
 	ledger.addSpecialTransactions(block, view, &rawTxCandidates)
 
 	// Add regular transactions submitted by the clients
-	regularRawTxs := ledger.mempool.ReapUnsafe(core.MaxNumRegularTxsPerBlock)
+	maxNumTxs := core.MaxNumRegularTxsPerBlock
+	blockGasLimit := uint64(0) // no limit
+	if core.IsForkActive(ledger.state.GetChainID(), core.ForkParameterChange, view.Height()+1) {
+		params := view.GetGovernanceParams()
+		maxNumTxs = int(params.MaxNumTxsPerBlock)
+		blockGasLimit = params.BlockGasLimit
+	}
+	snapshot := newProposalSnapshot(block, ledger.mempool.Size(), maxNumTxs, blockGasLimit)
+	numSpecialTxs := len(rawTxCandidates)
+	var regularRawTxs []common.Bytes
+	if ledger.txSelection.isDefault() {
+		regularRawTxs = ledger.mempool.ReapUnsafe(maxNumTxs)
+	} else {
+		regularRawTxs = ledger.txSelection.selectTxs(ledger.mempool.PeekUnsafe(-1), maxNumTxs)
+		ledger.mempool.RemoveUnsafe(regularRawTxs)
+	}
 	for _, regularRawTx := range regularRawTxs {
 		rawTxCandidates = append(rawTxCandidates, regularRawTx)
 	}
 
 	blockRawTxs = []common.Bytes{}
-	for _, rawTxCandidate := range rawTxCandidates {
+	blockGas := uint64(0)
+	for i, rawTxCandidate := range rawTxCandidates {
+		special := i < numSpecialTxs
 		tx, err := types.TxFromBytes(rawTxCandidate)
 		if err != nil {
+			snapshot.addCandidate(rawTxCandidate, special, ProposalSkipInvalidTx, err.Error())
+			continue
+		}
+		txGas := txGasLimit(tx)
+		if blockGasLimit != 0 && blockGas+txGas > blockGasLimit {
+			// Skip the txs exceeding the remaining gas of the block, which stay in the mempool
+			snapshot.addCandidate(rawTxCandidate, special, ProposalSkipGasLimit, "")
 			continue
 		}
 		_, res := ledger.executor.CheckTx(tx)
 		if res.IsError() {
 			logger.Errorf("Transaction check failed: errMsg = %v, tx = %v", res.Message, tx)
+			snapshot.addCandidate(rawTxCandidate, special, ProposalSkipCheckTxFailed, res.Message)
 			continue
 		}
 		blockRawTxs = append(blockRawTxs, rawTxCandidate)
+		blockGas += txGas
+		snapshot.addCandidate(rawTxCandidate, special, "", "")
+	}
+	if block != nil {
+		ledger.saveProposalSnapshot(snapshot)
 	}
 
 	ledger.handleDelayedStateUpdates(view)
@@ -321,6 +452,12 @@ func (ledger *Ledger) ApplyBlockTxs(block *core.Block) result.Result {
 	parentBlock := extParentBlock.Block
 	logger.Debugf("ApplyBlockTxs: Start applying block transactions, block.height = %v", block.Height)
 
+	if core.IsForkActive(ledger.state.GetChainID(), core.ForkParameterChange, block.Height) {
+		if res := checkBlockLimits(view, blockRawTxs); res.IsError() {
+			return res
+		}
+	}
+
 	hasValidatorUpdate := false
 	txProcessTime := []time.Duration{}
 	for _, rawTx := range blockRawTxs {
@@ -337,7 +474,10 @@ func (ledger *Ledger) ApplyBlockTxs(block *core.Block) result.Result {
 			hasValidatorUpdate = true
 		}
 		_, res := ledger.executor.ExecuteTx(tx)
-		if res.IsError() {
+		if res.IsError() && ledger.shadow {
+			// The block was accepted by the validators, skip the tx rejected by the local logic
+			ledger.recordShadowTxFailure(block, rawTx, res)
+		} else if res.IsError() {
 			//ledger.resetState(currHeight, currStateRoot)
 			ledger.resetState(parentBlock)
 			return res
@@ -353,11 +493,14 @@ func (ledger *Ledger) ApplyBlockTxs(block *core.Block) result.Result {
 
 	newStateRoot := view.Hash()
 	if newStateRoot != expectedStateRoot {
-		//ledger.resetState(currHeight, currStateRoot)
-		ledger.resetState(parentBlock)
-		return result.Error("State root mismatch! root: %v, exptected: %v",
-			hex.EncodeToString(newStateRoot[:]),
-			hex.EncodeToString(expectedStateRoot[:]))
+		if !ledger.shadow {
+			//ledger.resetState(currHeight, currStateRoot)
+			ledger.resetState(parentBlock)
+			return result.Error("State root mismatch! root: %v, exptected: %v",
+				hex.EncodeToString(newStateRoot[:]),
+				hex.EncodeToString(expectedStateRoot[:]))
+		}
+		ledger.recordShadowStateRoot(block, parentBlock, newStateRoot)
 	}
 
 	start = time.Now()
@@ -366,6 +509,11 @@ func (ledger *Ledger) ApplyBlockTxs(block *core.Block) result.Result {
 
 	logger.Debugf("ApplyBlockTxs: Committed state change, block.height = %v", block.Height)
 
+	if ledger.shadow {
+		ledger.updateShadowReport(func(report *ShadowReport) { report.ExecutedBlocks++ })
+		go ledger.compareShadowReceipts(block)
+	}
+
 	go func() {
 		ledger.mempool.Lock()
 		defer ledger.mempool.Unlock()
@@ -378,7 +526,7 @@ func (ledger *Ledger) ApplyBlockTxs(block *core.Block) result.Result {
 	logger.Debugf("ApplyBlockTxs: Done, block.height = %v, txProcessTime = %v, handleDelayedUpdateTime = %v, commitTime = %v",
 		block.Height, txProcessTime, handleDelayedUpdateTime, commitTime)
 
-	return result.OKWith(result.Info{"hasValidatorUpdate": hasValidatorUpdate})
+	return result.OKWith(result.Info{"hasValidatorUpdate": hasValidatorUpdate, "commitTime": commitTime})
 }
 
 // ApplyBlockTxsForChainCorrection applies all block's txs and re-calculate root hash
@@ -432,8 +580,24 @@ func (ledger *Ledger) ApplyBlockTxsForChainCorrection(block *core.Block) (common
 	return view.Hash(), result.OKWith(result.Info{"hasValidatorUpdate": hasValidatorUpdate})
 }
 
+// PauseStatePruning pauses the state pruning until ResumeStatePruning is called, e.g. while a snapshot
+// is being exported. The pruning catches up gradually after it resumes.
+func (ledger *Ledger) PauseStatePruning() {
+	atomic.AddInt32(&ledger.pruningPauses, 1)
+}
+
+// ResumeStatePruning resumes the state pruning paused by PauseStatePruning
+func (ledger *Ledger) ResumeStatePruning() {
+	atomic.AddInt32(&ledger.pruningPauses, -1)
+}
+
 // PruneState attempts to prune the state up to the targetEndHeight
 func (ledger *Ledger) PruneState(targetEndHeight uint64) error {
+	if atomic.LoadInt32(&ledger.pruningPauses) > 0 {
+		logger.Debugf("State pruning is paused, skip pruning up to height %v", targetEndHeight)
+		return nil
+	}
+
 	var processedHeight uint64
 	db := ledger.State().DB()
 	kvStore := kvstore.NewKVStore(db)
@@ -573,9 +737,28 @@ func (ledger *Ledger) FinalizeState(height uint64, rootHash common.Hash) result.
 	if res.IsError() {
 		return result.Error("Failed to finalize state root: %v", hex.EncodeToString(rootHash[:]))
 	}
+
 	return result.OK
 }
 
+// GetBalances returns the balances of the given addresses in the state of the given block, for the
+// balance history.
+func (ledger *Ledger) GetBalances(block *core.Block, addresses []common.Address) (map[common.Address]types.Coins, error) {
+	view := st.NewStoreView(block.Height, block.StateHash, ledger.state.DB())
+	if view == nil {
+		return nil, fmt.Errorf("State root %v of height %v is unavailable", block.StateHash.Hex(), block.Height)
+	}
+	balances := make(map[common.Address]types.Coins)
+	for _, address := range addresses {
+		account := view.GetAccount(address)
+		if account == nil {
+			continue
+		}
+		balances[address] = account.Balance
+	}
+	return balances, nil
+}
+
 // resetState sets the ledger state with the designated root
 //func (ledger *Ledger) resetState(height uint64, rootHash common.Hash) result.Result
 func (ledger *Ledger) resetState(block *core.Block) result.Result {
@@ -612,9 +795,75 @@ func (ledger *Ledger) handleDelayedStateUpdates(view *st.StoreView) {
 	ledger.handleGuardianStakeReturn(view)
 
 	blockHeight := view.Height() + 1
-	if blockHeight >= common.HeightEnableTheta3 {
+	if core.IsForkActive(ledger.state.GetChainID(), core.ForkTheta3, blockHeight) {
 		ledger.handleEliteEdgeNodeStakeReturns(view)
 	}
+	if baseFee := exec.GetBaseFee(ledger.state.GetChainID(), view); baseFee != nil {
+		// Adjusted by the gas used against the block gas limit before the parameter changes take effect
+		view.UpdateBaseFee(baseFee, view.GetGovernanceParams().BlockGasLimit,
+			types.GetMinimumGasPrice(ledger.state.GetChainID(), blockHeight))
+	}
+	if core.IsForkActive(ledger.state.GetChainID(), core.ForkParameterChange, blockHeight) {
+		view.ApplyParameterChanges(blockHeight)
+	}
+	if core.IsForkActive(ledger.state.GetChainID(), core.ForkValidatorKeyChange, blockHeight) {
+		view.ApplyValidatorKeyChanges(blockHeight)
+	}
+	if core.IsForkActive(ledger.state.GetChainID(), core.ForkRandomnessBeacon, blockHeight) && ledger.currentBlock != nil {
+		view.UpdateRandomness(ledger.currentBlock)
+	}
+}
+
+// checkBlockLimits checks the block txs against the governed max number of regular txs per block
+// and block gas limit
+func checkBlockLimits(view *st.StoreView, blockRawTxs []common.Bytes) result.Result {
+	params := view.GetGovernanceParams()
+
+	numRegularTxs := uint64(0)
+	blockGas := uint64(0)
+	for _, rawTx := range blockRawTxs {
+		tx, err := types.TxFromBytes(rawTx)
+		if err != nil {
+			return result.Error("Failed to parse transaction: %v", hex.EncodeToString(rawTx))
+		}
+		switch tx.(type) {
+		case *types.CoinbaseTx, *types.SlashTx:
+			continue
+		}
+		blockGas += txGasLimit(tx)
+		numRegularTxs++
+	}
+
+	if numRegularTxs > params.MaxNumTxsPerBlock {
+		return result.Error("Too many transactions in the block: %v, max: %v", numRegularTxs, params.MaxNumTxsPerBlock)
+	}
+	if blockGas > params.BlockGasLimit {
+		return result.Error("Block gas limit exceeded: %v, limit: %v", blockGas, params.BlockGasLimit)
+	}
+	return result.OK
+}
+
+// txGasLimit returns the gas limit the transaction counts against the block gas limit. A scheduled
+// transaction counts the gas limit of the transaction it wraps.
+func txGasLimit(tx types.Tx) uint64 {
+	switch tx := tx.(type) {
+	case *types.SmartContractTx:
+		return tx.GasLimit
+	case *types.SmartContractTxV2:
+		return tx.GasLimit
+	case *types.ScheduledTx:
+		innerTx, err := types.TxFromBytes(tx.Tx)
+		if err != nil {
+			return 0 // rejected by the execution
+		}
+		switch innerTx := innerTx.(type) {
+		case *types.SmartContractTx:
+			return innerTx.GasLimit
+		case *types.SmartContractTxV2:
+			return innerTx.GasLimit
+		}
+	}
+	return 0
 }
 
 func (ledger *Ledger) handleValidatorStakeReturn(view *st.StoreView) {
@@ -748,11 +997,11 @@ func (ledger *Ledger) addCoinbaseTx(view *st.StoreView, proposer *core.Validator
 	guardianVotes := currentBlock.GuardianVotes
 	eliteEdgeNodeVotes := currentBlock.EliteEdgeNodeVotes
 
-	if guardianVotes != nil && ch >= common.HeightEnableTheta2 && common.IsCheckPointHeight(ch) {
+	if guardianVotes != nil && core.IsForkActive(ledger.state.GetChainID(), core.ForkTheta2, ch) && common.IsCheckPointHeight(ch) {
 		guardianPool, eliteEdgeNodePool := exec.RetrievePools(ledger, ledger.chain, ledger.db, ch, guardianVotes, eliteEdgeNodeVotes)
-		accountRewardMap = exec.CalculateReward(ledger, view, validatorSet, guardianVotes, guardianPool, eliteEdgeNodeVotes, eliteEdgeNodePool)
-	} else { // for compatibility with lower versions (e.g. blockHeight < common.HeightEnableValidatorReward)
-		accountRewardMap = exec.CalculateReward(ledger, view, validatorSet, nil, nil, nil, nil)
+		accountRewardMap = exec.CalculateReward(ledger.state.GetChainID(), ledger, view, validatorSet, guardianVotes, guardianPool, eliteEdgeNodeVotes, eliteEdgeNodePool)
+	} else { // for compatibility with lower versions (e.g. before the validator reward fork)
+		accountRewardMap = exec.CalculateReward(ledger.state.GetChainID(), ledger, view, validatorSet, nil, nil, nil, nil)
 	}
 
 	coinbaseTxOutputs := []types.TxOutput{}
@@ -764,6 +1013,10 @@ func (ledger *Ledger) addCoinbaseTx(view *st.StoreView, proposer *core.Validator
 			Coins:   accountReward,
 		})
 	}
+	// The map iteration order is random, sort the outputs so that the same proposal always yields the same block
+	sort.Slice(coinbaseTxOutputs, func(i, j int) bool {
+		return bytes.Compare(coinbaseTxOutputs[i].Address[:], coinbaseTxOutputs[j].Address[:]) < 0
+	})
 
 	coinbaseTx := &types.CoinbaseTx{
 		Proposer:    proposerTxIn,
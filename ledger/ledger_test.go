@@ -678,3 +678,32 @@ func TestGuardianStakeUpdate(t *testing.T) {
 	assert.True(returnedCoins.TFuelWei.Cmp(core.Zero) == 0)
 	log.Infof("Returned coins: %v", returnedCoins)
 }
+
+func TestCheckBlockLimitsCountsScheduledTxGas(t *testing.T) {
+	assert := assert.New(t)
+
+	_, ledger, _ := newTestLedger()
+	view := ledger.state.Delivered()
+	blockGasLimit := view.GetGovernanceParams().BlockGasLimit
+
+	toBytes := func(tx types.Tx) common.Bytes {
+		raw, err := types.TxToBytes(tx)
+		require.Nil(t, err)
+		return raw
+	}
+	smartContractTx := func(gasLimit uint64) common.Bytes {
+		return toBytes(&types.SmartContractTx{
+			From:     types.TxInput{Coins: types.NewCoins(0, 0)},
+			To:       types.TxOutput{Coins: types.NewCoins(0, 0)},
+			GasLimit: gasLimit,
+			GasPrice: big.NewInt(1),
+		})
+	}
+	scheduledTx := toBytes(&types.ScheduledTx{NotBefore: 1, Tx: smartContractTx(blockGasLimit)})
+
+	assert.True(checkBlockLimits(view, []common.Bytes{scheduledTx}).IsOK())
+
+	// The gas limit of the wrapped smart contract tx counts against the block gas limit
+	assert.True(checkBlockLimits(view, []common.Bytes{scheduledTx, smartContractTx(1)}).IsError())
+	assert.Equal(blockGasLimit, txGasLimit(&types.ScheduledTx{NotBefore: 1, Tx: smartContractTx(blockGasLimit)}))
+}
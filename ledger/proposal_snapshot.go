@@ -0,0 +1,113 @@
+package ledger
+
+import (
+	"strconv"
+
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+// The reasons for leaving a candidate out of the proposed block
+const (
+	ProposalSkipInvalidTx     = "invalid_tx"
+	ProposalSkipGasLimit      = "exceeds_block_gas_limit"
+	ProposalSkipCheckTxFailed = "check_tx_failed"
+)
+
+// ProposalCandidate is a transaction considered for a block proposal
+type ProposalCandidate struct {
+	TxHash   common.Hash
+	Special  bool // added by the proposer, e.g. the coinbase tx, instead of reaped from the mempool
+	Included bool
+	Reason   string // why the tx was left out of the block
+	Error    string // the error of the failed check
+}
+
+// ProposalSnapshot captures the candidate set and the ordering used when the node proposed a block, so that the
+// inclusion of the transactions can be audited later
+type ProposalSnapshot struct {
+	Height        uint64
+	Epoch         uint64
+	Parent        common.Hash
+	MempoolSize   uint64 // number of the mempool txs before reaping
+	MaxNumTxs     uint64 // max number of regular txs per block
+	BlockGasLimit uint64 // 0 means no limit
+	Candidates    []ProposalCandidate
+}
+
+func newProposalSnapshot(block *core.Block, mempoolSize int, maxNumTxs int, blockGasLimit uint64) *ProposalSnapshot {
+	snapshot := &ProposalSnapshot{
+		MempoolSize:   uint64(mempoolSize),
+		MaxNumTxs:     uint64(maxNumTxs),
+		BlockGasLimit: blockGasLimit,
+		Candidates:    []ProposalCandidate{},
+	}
+	if block != nil {
+		snapshot.Height = block.Height
+		snapshot.Epoch = block.Epoch
+		snapshot.Parent = block.Parent
+	}
+	return snapshot
+}
+
+func (snapshot *ProposalSnapshot) addCandidate(rawTx common.Bytes, special bool, reason string, err string) {
+	snapshot.Candidates = append(snapshot.Candidates, ProposalCandidate{
+		TxHash:   crypto.Keccak256Hash(rawTx),
+		Special:  special,
+		Included: reason == "",
+		Reason:   reason,
+		Error:    err,
+	})
+}
+
+func proposalSnapshotKey(height uint64) common.Bytes {
+	return common.Bytes("proposal/" + strconv.FormatUint(height, 10))
+}
+
+// GetProposalSnapshot returns the snapshot of the block proposed by this node at the given height and epoch
+func (ledger *Ledger) GetProposalSnapshot(height uint64, epoch uint64) (*ProposalSnapshot, bool) {
+	snapshots := []*ProposalSnapshot{}
+	if err := kvstore.NewKVStore(ledger.db).Get(proposalSnapshotKey(height), &snapshots); err != nil {
+		return nil, false
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Epoch == epoch {
+			return snapshot, true
+		}
+	}
+	return nil, false
+}
+
+// saveProposalSnapshot persists the snapshot, and prunes the ones beyond the retention. A node might propose
+// several blocks at the same height in different epochs, so the snapshots are stored by height.
+func (ledger *Ledger) saveProposalSnapshot(snapshot *ProposalSnapshot) {
+	retention := viper.GetUint64(common.CfgConsensusProposalSnapshotRetention)
+	if retention == 0 {
+		return
+	}
+
+	store := kvstore.NewKVStore(ledger.db)
+	key := proposalSnapshotKey(snapshot.Height)
+	snapshots := []*ProposalSnapshot{}
+	store.Get(key, &snapshots)
+
+	updated := []*ProposalSnapshot{}
+	for _, s := range snapshots {
+		if s.Epoch != snapshot.Epoch {
+			updated = append(updated, s)
+		}
+	}
+	updated = append(updated, snapshot)
+	if err := store.Put(key, updated); err != nil {
+		logger.Errorf("Failed to save the proposal snapshot: %v", err)
+		return
+	}
+
+	if snapshot.Height > retention {
+		store.Delete(proposalSnapshotKey(snapshot.Height - retention))
+	}
+}
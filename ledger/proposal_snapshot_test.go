@@ -0,0 +1,46 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+)
+
+func TestProposalSnapshotRetention(t *testing.T) {
+	assert := assert.New(t)
+
+	viper.Set(common.CfgConsensusProposalSnapshotRetention, 2)
+	defer viper.Set(common.CfgConsensusProposalSnapshotRetention, 100000)
+
+	_, ledger, _ := newTestLedger()
+	for height := uint64(1); height <= 3; height++ {
+		for epoch := height; epoch <= height+1; epoch++ {
+			block := &core.Block{BlockHeader: &core.BlockHeader{Height: height, Epoch: epoch}}
+			snapshot := newProposalSnapshot(block, 10, 5, 0)
+			snapshot.addCandidate(common.Bytes{byte(epoch)}, false, "", "")
+			snapshot.addCandidate(common.Bytes{0xff}, false, ProposalSkipInvalidTx, "decode failure")
+			ledger.saveProposalSnapshot(snapshot)
+		}
+	}
+
+	_, ok := ledger.GetProposalSnapshot(1, 1)
+	assert.False(ok)
+
+	snapshot, ok := ledger.GetProposalSnapshot(3, 4)
+	assert.True(ok)
+	assert.Equal(uint64(3), snapshot.Height)
+	assert.Equal(uint64(10), snapshot.MempoolSize)
+	assert.Equal(2, len(snapshot.Candidates))
+	assert.True(snapshot.Candidates[0].Included)
+	assert.False(snapshot.Candidates[1].Included)
+	assert.Equal(ProposalSkipInvalidTx, snapshot.Candidates[1].Reason)
+
+	_, ok = ledger.GetProposalSnapshot(2, 2)
+	assert.True(ok)
+	_, ok = ledger.GetProposalSnapshot(2, 5)
+	assert.False(ok)
+}
@@ -0,0 +1,199 @@
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	rpcc "github.com/ybbus/jsonrpc"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	st "github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+// maxNumShadowDivergences is the max number of the latest divergences kept in the shadow report
+const maxNumShadowDivergences = 1000
+
+var shadowReportKey = common.Bytes("shadow/report")
+
+// ShadowDivergence is a difference between the shadow execution of a block and the execution by the validators
+type ShadowDivergence struct {
+	Height    uint64
+	BlockHash common.Hash
+	TxHash    common.Hash // empty for a state root divergence
+	Field     string      // "state_root", "tx_result", or the diverged field of the tx receipt
+	Expected  string
+	Actual    string
+}
+
+// ShadowReport summarizes the divergences found by the shadow execution
+type ShadowReport struct {
+	ExecutedBlocks uint64
+	DivergedBlocks uint64             // including the blocks inheriting the divergence of the parent
+	Divergences    []ShadowDivergence // excluding the inherited state root divergences
+}
+
+func (report *ShadowReport) addDivergence(divergence ShadowDivergence) {
+	logger.WithFields(log.Fields{
+		"height":   divergence.Height,
+		"block":    divergence.BlockHash.Hex(),
+		"tx":       divergence.TxHash.Hex(),
+		"field":    divergence.Field,
+		"expected": divergence.Expected,
+		"actual":   divergence.Actual,
+	}).Warn("Shadow execution diverged")
+
+	report.Divergences = append(report.Divergences, divergence)
+	if len(report.Divergences) > maxNumShadowDivergences {
+		report.Divergences = report.Divergences[len(report.Divergences)-maxNumShadowDivergences:]
+	}
+}
+
+// GetShadowReport returns the divergences found by the shadow execution
+func (ledger *Ledger) GetShadowReport() *ShadowReport {
+	ledger.shadowMu.Lock()
+	defer ledger.shadowMu.Unlock()
+
+	return ledger.loadShadowReport()
+}
+
+func (ledger *Ledger) loadShadowReport() *ShadowReport {
+	report := &ShadowReport{}
+	kvstore.NewKVStore(ledger.db).Get(shadowReportKey, report)
+	return report
+}
+
+func (ledger *Ledger) updateShadowReport(update func(report *ShadowReport)) {
+	ledger.shadowMu.Lock()
+	defer ledger.shadowMu.Unlock()
+
+	report := ledger.loadShadowReport()
+	update(report)
+	if err := kvstore.NewKVStore(ledger.db).Put(shadowReportKey, report); err != nil {
+		logger.Errorf("Failed to save the shadow report: %v", err)
+	}
+}
+
+// recordShadowStateRoot records the state root computed by the shadow execution of the given block,
+// so that the state of the block can be loaded by its state hash
+func (ledger *Ledger) recordShadowStateRoot(block *core.Block, parentBlock *core.Block, stateRoot common.Hash) {
+	if err := st.SetShadowStateRoot(ledger.db, block.StateHash, stateRoot); err != nil {
+		logger.Panicf("Failed to save the shadow state root: %v", err)
+	}
+
+	_, parentDiverged := st.GetShadowStateRoot(ledger.db, parentBlock.StateHash)
+	ledger.updateShadowReport(func(report *ShadowReport) {
+		report.DivergedBlocks++
+		if !parentDiverged {
+			report.addDivergence(ShadowDivergence{
+				Height:    block.Height,
+				BlockHash: block.Hash(),
+				Field:     "state_root",
+				Expected:  block.StateHash.Hex(),
+				Actual:    stateRoot.Hex(),
+			})
+		}
+	})
+}
+
+// recordShadowTxFailure records a tx of the given block rejected by the shadow execution
+func (ledger *Ledger) recordShadowTxFailure(block *core.Block, rawTx common.Bytes, res result.Result) {
+	ledger.updateShadowReport(func(report *ShadowReport) {
+		report.addDivergence(ShadowDivergence{
+			Height:    block.Height,
+			BlockHash: block.Hash(),
+			TxHash:    crypto.Keccak256Hash(rawTx),
+			Field:     "tx_result",
+			Expected:  "ok",
+			Actual:    res.Message,
+		})
+	})
+}
+
+// compareShadowReceipts compares the receipts of the smart contract txs of the given block with the
+// ones of the reference node
+func (ledger *Ledger) compareShadowReceipts(block *core.Block) {
+	referenceRPC := viper.GetString(common.CfgShadowReferenceRPC)
+	if referenceRPC == "" {
+		return
+	}
+
+	client := rpcc.NewRPCClient(referenceRPC)
+	divergences := []ShadowDivergence{}
+	for _, rawTx := range block.Txs {
+		txHash := crypto.Keccak256Hash(rawTx)
+		receipt, found := ledger.chain.FindTxReceiptByHash(txHash)
+		if !found {
+			continue // only the smart contract txs have receipts
+		}
+
+		res, err := client.Call("theta.GetTransaction", struct {
+			Hash string `json:"hash"`
+		}{txHash.Hex()})
+		if err == nil && res.Error != nil {
+			err = res.Error
+		}
+		reference := struct {
+			Receipt *blockchain.TxReceiptEntry `json:"receipt"`
+		}{}
+		if err == nil {
+			err = res.GetObject(&reference)
+		}
+		if err != nil || reference.Receipt == nil {
+			logger.Warnf("Failed to get the reference receipt of tx %v: %v", txHash.Hex(), err)
+			continue
+		}
+
+		for _, field := range diffReceipts(reference.Receipt, receipt) {
+			divergences = append(divergences, ShadowDivergence{
+				Height:    block.Height,
+				BlockHash: block.Hash(),
+				TxHash:    txHash,
+				Field:     field[0],
+				Expected:  field[1],
+				Actual:    field[2],
+			})
+		}
+	}
+
+	if len(divergences) == 0 {
+		return
+	}
+	ledger.updateShadowReport(func(report *ShadowReport) {
+		for _, divergence := range divergences {
+			report.addDivergence(divergence)
+		}
+	})
+}
+
+// diffReceipts returns the name, the expected value and the actual value of each diverged receipt field
+func diffReceipts(expected, actual *blockchain.TxReceiptEntry) [][3]string {
+	fields := [][3]string{
+		{"gas_used", fmt.Sprintf("%v", expected.GasUsed), fmt.Sprintf("%v", actual.GasUsed)},
+		{"evm_err", expected.EvmErr, actual.EvmErr},
+		{"evm_ret", expected.EvmRet.String(), actual.EvmRet.String()},
+		{"contract_address", expected.ContractAddress.Hex(), actual.ContractAddress.Hex()},
+		{"logs", marshalLogs(expected), marshalLogs(actual)},
+	}
+	diffs := [][3]string{}
+	for _, field := range fields {
+		if field[1] != field[2] {
+			diffs = append(diffs, field)
+		}
+	}
+	return diffs
+}
+
+func marshalLogs(receipt *blockchain.TxReceiptEntry) string {
+	raw, err := json.Marshal(receipt.Logs)
+	if err != nil {
+		return err.Error()
+	}
+	return string(raw)
+}
@@ -0,0 +1,46 @@
+package state
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// GetBaseFee returns the base fee per gas of the block being applied, or nil if the dynamic base fee has not
+// started yet.
+func (sv *StoreView) GetBaseFee() *big.Int {
+	data := sv.Get(BaseFeeKey())
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	return new(big.Int).SetBytes(data)
+}
+
+// SetBaseFee sets the base fee per gas of the next block
+func (sv *StoreView) SetBaseFee(baseFee *big.Int) {
+	sv.Set(BaseFeeKey(), baseFee.Bytes())
+}
+
+// GetBlockGasUsed returns the gas used so far by the smart contract transactions of the block being applied
+func (sv *StoreView) GetBlockGasUsed() uint64 {
+	data := sv.Get(BlockGasUsedKey())
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// AddBlockGasUsed adds the gas used by a smart contract transaction to the gas used by the block being applied
+func (sv *StoreView) AddBlockGasUsed(gasUsed uint64) {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, sv.GetBlockGasUsed()+gasUsed)
+	sv.Set(BlockGasUsedKey(), data)
+}
+
+// UpdateBaseFee sets the base fee of the next block by the gas used by the block being applied, and resets
+// the gas used for the next block. The baseFee is the base fee of the block being applied.
+func (sv *StoreView) UpdateBaseFee(baseFee *big.Int, blockGasLimit uint64, minBaseFee *big.Int) {
+	sv.SetBaseFee(types.NextBaseFee(baseFee, sv.GetBlockGasUsed(), blockGasLimit, minBaseFee))
+	sv.Delete(BlockGasUsedKey())
+}
@@ -0,0 +1,30 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestUpdateBaseFee(t *testing.T) {
+	assert := assert.New(t)
+
+	sv := NewStoreView(uint64(1), common.Hash{}, backend.NewMemDatabase())
+	assert.Nil(sv.GetBaseFee())
+	assert.Equal(uint64(0), sv.GetBlockGasUsed())
+
+	sv.AddBlockGasUsed(150e6)
+	sv.AddBlockGasUsed(50e6)
+	assert.Equal(uint64(200e6), sv.GetBlockGasUsed())
+
+	// A full block raises the base fee by 1/8, and the gas used starts over for the next block
+	sv.UpdateBaseFee(big.NewInt(8000), 200e6, big.NewInt(4000))
+	assert.Equal(int64(9000), sv.GetBaseFee().Int64())
+	assert.Equal(uint64(0), sv.GetBlockGasUsed())
+
+	sv.UpdateBaseFee(sv.GetBaseFee(), 200e6, big.NewInt(4000))
+	assert.Equal(int64(7875), sv.GetBaseFee().Int64())
+}
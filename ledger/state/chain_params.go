@@ -0,0 +1,55 @@
+package state
+
+import (
+	"log"
+	"math/big"
+	"sort"
+
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// chainParam is the encoding of a chain parameter in the state, the parameters are stored sorted by name
+type chainParam struct {
+	Name  string
+	Value *big.Int
+}
+
+// GetChainParams gets the chain parameters set at the genesis, empty if the chain takes the Mainnet values.
+func (sv *StoreView) GetChainParams() core.ChainParams {
+	params := core.ChainParams{}
+	data := sv.Get(ChainParamsKey())
+	if data == nil || len(data) == 0 {
+		return params
+	}
+	entries := []chainParam{}
+	err := types.FromBytes(data, &entries)
+	if err != nil {
+		log.Panicf("Error reading chain params %X, error: %v",
+			data, err.Error())
+	}
+	for _, entry := range entries {
+		params[entry.Name] = entry.Value
+	}
+	return params
+}
+
+// SetChainParams sets the chain parameters. It is only called by the genesis generator, the parameters
+// never change afterwards.
+func (sv *StoreView) SetChainParams(params core.ChainParams) {
+	if len(params) == 0 {
+		sv.Delete(ChainParamsKey())
+		return
+	}
+	entries := []chainParam{}
+	for name, value := range params {
+		entries = append(entries, chainParam{Name: name, Value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	paramsBytes, err := types.ToBytes(entries)
+	if err != nil {
+		log.Panicf("Error writing chain params %v, error: %v",
+			params, err.Error())
+	}
+	sv.Set(ChainParamsKey(), paramsBytes)
+}
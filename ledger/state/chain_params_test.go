@@ -0,0 +1,41 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestChainParams(t *testing.T) {
+	assert := assert.New(t)
+
+	db := backend.NewMemDatabase()
+	sv := NewStoreView(uint64(0), common.Hash{}, db)
+	assert.Equal(0, len(sv.GetChainParams()))
+
+	params := core.ChainParams{
+		core.ChainParamMinValidatorStake:       big.NewInt(1e18),
+		core.ChainParamValidatorRewardPerBlock: big.NewInt(0),
+		core.ChainParamMinGasPrice:             big.NewInt(1e8),
+	}
+	sv.SetChainParams(params)
+	root := sv.Save()
+
+	// The parameters are committed to the state
+	loaded := NewStoreView(uint64(0), root, db).GetChainParams()
+	assert.True(params.Equal(loaded))
+
+	// The state hash does not depend on the order of the parameters
+	for i := 0; i < 10; i++ {
+		other := NewStoreView(uint64(0), common.Hash{}, backend.NewMemDatabase())
+		other.SetChainParams(params)
+		assert.Equal(root, other.Hash())
+	}
+
+	sv.SetChainParams(core.ChainParams{})
+	assert.Equal(0, len(sv.GetChainParams()))
+}
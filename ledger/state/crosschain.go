@@ -0,0 +1,113 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// GetCrossChainChannel gets the message channel with the peer chain.
+func (sv *StoreView) GetCrossChainChannel(peerChainID string) *core.CrossChainChannel {
+	data := sv.Get(CrossChainChannelKey(peerChainID))
+	if data == nil || len(data) == 0 {
+		return &core.CrossChainChannel{PeerChainID: peerChainID}
+	}
+	channel := &core.CrossChainChannel{}
+	err := types.FromBytes(data, channel)
+	if err != nil {
+		log.Panicf("Error reading cross-chain channel %X, error: %v",
+			data, err.Error())
+	}
+	return channel
+}
+
+// SetCrossChainChannel sets the message channel with the peer chain.
+func (sv *StoreView) SetCrossChainChannel(channel *core.CrossChainChannel) {
+	channelBytes, err := types.ToBytes(channel)
+	if err != nil {
+		log.Panicf("Error writing cross-chain channel %v, error: %v",
+			channel, err.Error())
+	}
+	sv.Set(CrossChainChannelKey(channel.PeerChainID), channelBytes)
+}
+
+// GetSentCrossChainMessage gets the message sent to the target chain with the given nonce.
+func (sv *StoreView) GetSentCrossChainMessage(targetChainID string, nonce uint64) *core.CrossChainMessage {
+	return sv.getCrossChainMessage(CrossChainSentMessageKey(targetChainID, nonce))
+}
+
+// GetReceivedCrossChainMessage gets the message received from the source chain with the given nonce.
+func (sv *StoreView) GetReceivedCrossChainMessage(sourceChainID string, nonce uint64) *core.CrossChainMessage {
+	return sv.getCrossChainMessage(CrossChainReceivedMessageKey(sourceChainID, nonce))
+}
+
+// EnqueueCrossChainMessage assigns the next nonce of the channel with the target chain to the message,
+// and appends the message to the send queue.
+func (sv *StoreView) EnqueueCrossChainMessage(message *core.CrossChainMessage) {
+	channel := sv.GetCrossChainChannel(message.TargetChainID)
+	message.Nonce = channel.SendNonce
+	channel.SendNonce++
+	sv.SetCrossChainChannel(channel)
+	sv.setCrossChainMessage(CrossChainSentMessageKey(message.TargetChainID, message.Nonce), message)
+}
+
+// ReceiveCrossChainMessage appends the message delivered from the source chain to the receive queue.
+func (sv *StoreView) ReceiveCrossChainMessage(message *core.CrossChainMessage) {
+	channel := sv.GetCrossChainChannel(message.SourceChainID)
+	if message.Nonce != channel.ReceiveNonce {
+		log.Panicf("Cross-chain message %v is out of order, expected nonce: %v", message, channel.ReceiveNonce)
+	}
+	channel.ReceiveNonce++
+	sv.SetCrossChainChannel(channel)
+	sv.setCrossChainMessage(CrossChainReceivedMessageKey(message.SourceChainID, message.Nonce), message)
+}
+
+// ProveSentCrossChainMessage returns the trie nodes proving the message sent to the target chain with the
+// given nonce against the state root of the view.
+func (sv *StoreView) ProveSentCrossChainMessage(targetChainID string, nonce uint64) ([]common.Bytes, error) {
+	return sv.proveKey(CrossChainSentMessageKey(targetChainID, nonce))
+}
+
+// VerifyCrossChainMessageProof verifies that the message is in the send queue of the source chain state
+// with the given state root.
+func VerifyCrossChainMessageProof(stateHash common.Hash, message *core.CrossChainMessage, nodes []common.Bytes) error {
+	data, err := verifyKeyProof(stateHash, CrossChainSentMessageKey(message.TargetChainID, message.Nonce), nodes)
+	if err != nil {
+		return err
+	}
+	messageBytes, err := types.ToBytes(message)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(data, messageBytes) {
+		return fmt.Errorf("cross-chain message %v does not match the proven one", message)
+	}
+	return nil
+}
+
+func (sv *StoreView) getCrossChainMessage(key common.Bytes) *core.CrossChainMessage {
+	data := sv.Get(key)
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	message := &core.CrossChainMessage{}
+	err := types.FromBytes(data, message)
+	if err != nil {
+		log.Panicf("Error reading cross-chain message %X, error: %v",
+			data, err.Error())
+	}
+	return message
+}
+
+func (sv *StoreView) setCrossChainMessage(key common.Bytes, message *core.CrossChainMessage) {
+	messageBytes, err := types.ToBytes(message)
+	if err != nil {
+		log.Panicf("Error writing cross-chain message %v, error: %v",
+			message, err.Error())
+	}
+	sv.Set(key, messageBytes)
+}
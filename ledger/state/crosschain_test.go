@@ -0,0 +1,59 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestCrossChainMessageProof(t *testing.T) {
+	assert := assert.New(t)
+
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	receiver := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	// The source chain sends two messages
+	source := NewStoreView(uint64(1), common.Hash{}, backend.NewMemDatabase())
+	for i := 0; i < 2; i++ {
+		source.EnqueueCrossChainMessage(&core.CrossChainMessage{
+			SourceChainID: "privatenet",
+			TargetChainID: "tsub_1",
+			Sender:        sender,
+			Receiver:      receiver,
+			Data:          common.Bytes{byte(i)},
+			SourceHeight:  1,
+		})
+	}
+	stateHash := source.Save()
+
+	channel := source.GetCrossChainChannel("tsub_1")
+	assert.Equal(uint64(2), channel.SendNonce)
+	assert.Equal(uint64(0), channel.ReceiveNonce)
+
+	message := source.GetSentCrossChainMessage("tsub_1", 1)
+	assert.NotNil(message)
+	assert.Equal(uint64(1), message.Nonce)
+	proof, err := source.ProveSentCrossChainMessage("tsub_1", 1)
+	assert.Nil(err)
+	assert.Nil(VerifyCrossChainMessageProof(stateHash, message, proof))
+
+	// A tampered message does not match the proof
+	tampered := *message
+	tampered.Data = common.Bytes{9}
+	assert.NotNil(VerifyCrossChainMessageProof(stateHash, &tampered, proof))
+	tampered = *message
+	tampered.Nonce = 0
+	assert.NotNil(VerifyCrossChainMessageProof(stateHash, &tampered, proof))
+
+	// The target chain receives the messages in order
+	target := NewStoreView(uint64(1), common.Hash{}, backend.NewMemDatabase())
+	first := source.GetSentCrossChainMessage("tsub_1", 0)
+	target.ReceiveCrossChainMessage(first)
+	target.ReceiveCrossChainMessage(message)
+	assert.Equal(uint64(2), target.GetCrossChainChannel("privatenet").ReceiveNonce)
+	assert.Equal(first.Data, target.GetReceivedCrossChainMessage("privatenet", 0).Data)
+	assert.Panics(func() { target.ReceiveCrossChainMessage(message) })
+}
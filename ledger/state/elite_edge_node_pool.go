@@ -64,6 +64,9 @@ func (eenp *EliteEdgeNodePool) RandomRewardWeight(block common.Hash, eenAddr com
 // proved that if a user split the stakes onto multiple nodes, the expected return won't changes, the
 // variance changes a bit but shouldn't be too big.
 //
+// S_min is the Mainnet min stake on all the chains. On the chains with a lower min stake, a stake below
+// S_min is sampled with one flip of head probability n * S / S_total.
+//
 func sampleEENWeight(reader io.Reader, stake *big.Int, totalStake *big.Int) int {
 	if stake.Cmp(big.NewInt(0)) == 0 || totalStake.Cmp(big.NewInt(0)) == 0 {
 		// could happen when we sample an EEN whose stakes are all withdrawn, e.g. when
@@ -76,6 +79,9 @@ func sampleEENWeight(reader io.Reader, stake *big.Int, totalStake *big.Int) int
 	}
 
 	b := new(big.Int).Div(stake, core.MinEliteEdgeNodeStakeDeposit)
+	if b.Sign() == 0 {
+		b.SetInt64(1)
+	}
 
 	base := new(big.Int).SetUint64(1e18)
 
@@ -162,14 +168,24 @@ func (eenp *EliteEdgeNodePool) Remove(een *core.EliteEdgeNode) {
 }
 
 func (eenp *EliteEdgeNodePool) GetAll(withstake bool) []*core.EliteEdgeNode {
+	eenList := []*core.EliteEdgeNode{}
+	eenp.Iterate(withstake, func(een *core.EliteEdgeNode) bool {
+		eenList = append(eenList, een)
+		return true
+	})
+	return eenList
+}
+
+// Iterate calls cb on each of the elite edge nodes without loading the entire pool into memory. The
+// iteration stops if cb returns false.
+func (eenp *EliteEdgeNodePool) Iterate(withstake bool, cb func(een *core.EliteEdgeNode) bool) {
 	prefix := EliteEdgeNodeKeyPrefix()
 
-	eenList := []*core.EliteEdgeNode{}
-	cb := func(k, v common.Bytes) bool {
+	traverseCb := func(k, v common.Bytes) bool {
 		een := &core.EliteEdgeNode{}
 		err := types.FromBytes(v, een)
 		if err != nil {
-			log.Panicf("EliteEdgeNodePool.GetAll: Error reading elite edge node %X, error: %v",
+			log.Panicf("EliteEdgeNodePool.Iterate: Error reading elite edge node %X, error: %v",
 				v, err.Error())
 		}
 		if withstake {
@@ -184,22 +200,19 @@ func (eenp *EliteEdgeNodePool) GetAll(withstake bool) []*core.EliteEdgeNode {
 				return true // Skip if een dons't have non-withdrawn stake
 			}
 		}
-		eenList = append(eenList, een)
-		return true
+		return cb(een)
 	}
 
-	eenp.sv.Traverse(prefix, cb)
-
-	return eenList
+	eenp.sv.Traverse(prefix, traverseCb)
 }
 
-func (eenp *EliteEdgeNodePool) DepositStake(source common.Address, holder common.Address, amount *big.Int, pubkey *bls.PublicKey, blockHeight uint64) (err error) {
+func (eenp *EliteEdgeNodePool) DepositStake(source common.Address, holder common.Address, amount *big.Int, pubkey *bls.PublicKey, chainID string, blockHeight uint64) (err error) {
 	if eenp.readOnly {
 		log.Panicf("EliteEdgeNodePool.DepositStake: the pool is read-only")
 	}
 
-	minEliteEdgeNodeStake := core.MinEliteEdgeNodeStakeDeposit
-	maxEliteEdgeNodeStake := core.MaxEliteEdgeNodeStakeDeposit
+	minEliteEdgeNodeStake := core.MinEliteEdgeNodeStake(chainID)
+	maxEliteEdgeNodeStake := core.MaxEliteEdgeNodeStake(chainID)
 	if amount.Cmp(minEliteEdgeNodeStake) < 0 {
 		return fmt.Errorf("Elite edge node staking amount below the lower limit: %v", amount)
 	}
@@ -238,6 +251,44 @@ func (eenp *EliteEdgeNodePool) DepositStake(source common.Address, holder common
 	return nil
 }
 
+// CompoundStake adds the amount to the existing stake of the source to the elite edge node. Unlike DepositStake,
+// the amount can be below the min deposit, since the stake is topped up rather than created.
+func (eenp *EliteEdgeNodePool) CompoundStake(source common.Address, holder common.Address, amount *big.Int, chainID string) error {
+	if eenp.readOnly {
+		log.Panicf("EliteEdgeNodePool.CompoundStake: the pool is read-only")
+	}
+
+	een := eenp.Get(holder)
+	if een == nil {
+		return fmt.Errorf("No matched stake holder address found: %v", holder)
+	}
+	hasStake := false
+	for _, stake := range een.Stakes {
+		if stake.Source == source && !stake.Withdrawn {
+			hasStake = true
+			break
+		}
+	}
+	if !hasStake {
+		return fmt.Errorf("No active stake from %v to elite edge node %v", source, holder)
+	}
+	expectedStake := big.NewInt(0).Add(een.TotalStake(), amount)
+	if expectedStake.Cmp(core.MaxEliteEdgeNodeStake(chainID)) > 0 {
+		return fmt.Errorf("Elite edge node stake would exceed the cap: %v", expectedStake)
+	}
+	if err := een.DepositStake(source, amount); err != nil {
+		return err
+	}
+
+	eenp.Upsert(een)
+
+	totalStake := eenp.sv.GetTotalEENStake()
+	totalStake.Add(totalStake, amount)
+	eenp.sv.SetTotalEENStake(totalStake)
+
+	return nil
+}
+
 func (eenp *EliteEdgeNodePool) WithdrawStake(source common.Address, holder common.Address, currentHeight uint64) (*core.Stake, error) {
 	if eenp.readOnly {
 		log.Panicf("EliteEdgeNodePool.WithdrawStake: the pool is read-only")
@@ -5,7 +5,11 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto/bls"
+	"github.com/thetatoken/theta/store/database/backend"
 )
 
 func TestSampleEENWeight(t *testing.T) {
@@ -25,6 +29,53 @@ func TestSampleEENWeight(t *testing.T) {
 	}
 }
 
+func TestSampleEENWeightBelowMinStake(t *testing.T) {
+	assert := assert.New(t)
+
+	// A stake below the Mainnet min stake, possible on the chains with a lower min stake, is sampled once
+	stake := new(big.Int).Div(core.MinEliteEdgeNodeStakeDeposit, big.NewInt(2))
+	assert.Equal(1, sampleEENWeight(crand.Reader, stake, new(big.Int).Mul(stake, big.NewInt(4))))
+
+	N := 10000
+	weight := 0
+	totalStake := new(big.Int).Mul(stake, big.NewInt(1000))
+	for i := 0; i < N; i++ {
+		weight += sampleEENWeight(crand.Reader, stake, totalStake)
+	}
+	assert.InDelta(float64(eenpRewardN)/1000, float64(weight)/float64(N), 0.05)
+}
+
+func TestCompoundStake(t *testing.T) {
+	assert := assert.New(t)
+
+	source := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	holder := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	blsKey, err := bls.RandKey()
+	assert.Nil(err)
+
+	sv := NewStoreView(uint64(1), common.Hash{}, backend.NewMemDatabase())
+	eenp := NewEliteEdgeNodePool(sv, false)
+
+	// Only an existing stake can be compounded
+	reward := big.NewInt(1000)
+	assert.NotNil(eenp.CompoundStake(source, holder, reward, "testnet"))
+
+	deposit := new(big.Int).Set(core.MinEliteEdgeNodeStakeDeposit)
+	assert.Nil(eenp.DepositStake(source, holder, deposit, blsKey.PublicKey(), "testnet", 1))
+	assert.NotNil(eenp.CompoundStake(other, holder, reward, "testnet"))
+
+	// The reward is added to the stake even though it is below the min deposit
+	assert.Nil(eenp.CompoundStake(source, holder, reward, "testnet"))
+	expected := new(big.Int).Add(deposit, reward)
+	assert.Equal(0, expected.Cmp(eenp.Get(holder).TotalStake()))
+	assert.Equal(0, expected.Cmp(sv.GetTotalEENStake()))
+
+	// The stake cannot exceed the cap
+	assert.NotNil(eenp.CompoundStake(source, holder, core.MaxEliteEdgeNodeStakeDeposit, "testnet"))
+	assert.Equal(0, expected.Cmp(eenp.Get(holder).TotalStake()))
+}
+
 func BenchmarkRandInt(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		stake := new(big.Int).Mul(core.MinEliteEdgeNodeStakeDeposit, big.NewInt(5*100))
@@ -0,0 +1,90 @@
+package state
+
+import (
+	"log"
+
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// GetGovernanceParams gets the governance params, or the default values if none has been changed.
+func (sv *StoreView) GetGovernanceParams() *core.GovernanceParams {
+	data := sv.Get(GovernanceParamsKey())
+	if data == nil || len(data) == 0 {
+		return core.NewGovernanceParams()
+	}
+	gp := &core.GovernanceParams{}
+	err := types.FromBytes(data, gp)
+	if err != nil {
+		log.Panicf("Error reading governance params %X, error: %v",
+			data, err.Error())
+	}
+	return gp
+}
+
+// UpdateGovernanceParams updates the governance params.
+func (sv *StoreView) UpdateGovernanceParams(gp *core.GovernanceParams) {
+	gpBytes, err := types.ToBytes(gp)
+	if err != nil {
+		log.Panicf("Error writing governance params %v, error: %v",
+			gp, err.Error())
+	}
+	sv.Set(GovernanceParamsKey(), gpBytes)
+}
+
+// GetParameterChanges gets the pending parameter changes.
+func (sv *StoreView) GetParameterChanges() []*core.ParameterChange {
+	data := sv.Get(ParameterChangesKey())
+	if data == nil || len(data) == 0 {
+		return []*core.ParameterChange{}
+	}
+	changes := []*core.ParameterChange{}
+	err := types.FromBytes(data, &changes)
+	if err != nil {
+		log.Panicf("Error reading parameter changes %X, error: %v",
+			data, err.Error())
+	}
+	return changes
+}
+
+// UpdateParameterChanges updates the pending parameter changes.
+func (sv *StoreView) UpdateParameterChanges(changes []*core.ParameterChange) {
+	if len(changes) == 0 {
+		sv.Delete(ParameterChangesKey())
+		return
+	}
+	changesBytes, err := types.ToBytes(changes)
+	if err != nil {
+		log.Panicf("Error writing parameter changes %v, error: %v",
+			changes, err.Error())
+	}
+	sv.Set(ParameterChangesKey(), changesBytes)
+}
+
+// ApplyParameterChanges applies the approved parameter changes which take effect from the block
+// after the given block height.
+func (sv *StoreView) ApplyParameterChanges(blockHeight uint64) {
+	changes := sv.GetParameterChanges()
+	if len(changes) == 0 {
+		return
+	}
+
+	gp := sv.GetGovernanceParams()
+	pending := []*core.ParameterChange{}
+	applied := false
+	for _, change := range changes {
+		if !change.IsApproved() || change.EffectiveHeight > blockHeight+1 {
+			pending = append(pending, change)
+			continue
+		}
+		if err := gp.Set(change.Parameter, change.Value); err != nil {
+			log.Panicf("Failed to apply parameter change %v: %v", change, err)
+		}
+		applied = true
+	}
+
+	if applied {
+		sv.UpdateGovernanceParams(gp)
+		sv.UpdateParameterChanges(pending)
+	}
+}
@@ -0,0 +1,67 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestGovernanceParams(t *testing.T) {
+	assert := assert.New(t)
+
+	db := backend.NewMemDatabase()
+	sv := NewStoreView(uint64(1), common.Hash{}, db)
+
+	// Default values
+	params := sv.GetGovernanceParams()
+	assert.Equal(core.DefaultBlockGasLimit, params.BlockGasLimit)
+	assert.Equal(uint64(core.MaxNumRegularTxsPerBlock), params.MaxNumTxsPerBlock)
+
+	assert.NotNil(params.Set(core.ParamBlockGasLimit, core.MinBlockGasLimit-1))
+	assert.NotNil(params.Set(core.ParamMaxNumTxsPerBlock, 0))
+	assert.NotNil(params.Set("unknown", 1))
+
+	val1 := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	val2 := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	val3 := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	valSet := core.NewValidatorSet()
+	valSet.AddValidator(core.NewValidator(val1.Hex(), big.NewInt(100)))
+	valSet.AddValidator(core.NewValidator(val2.Hex(), big.NewInt(100)))
+	valSet.AddValidator(core.NewValidator(val3.Hex(), big.NewInt(100)))
+
+	change := &core.ParameterChange{
+		Parameter: core.ParamMaxNumTxsPerBlock,
+		Value:     512,
+		Voters:    []common.Address{val1, val2},
+	}
+	assert.False(change.HasMajority(valSet))
+	change.Voters = append(change.Voters, val3)
+	assert.True(change.HasMajority(valSet))
+	change.EffectiveHeight = 100
+
+	unapproved := &core.ParameterChange{
+		Parameter: core.ParamBlockGasLimit,
+		Value:     300e6,
+		Voters:    []common.Address{val1},
+	}
+	sv.UpdateParameterChanges([]*core.ParameterChange{change, unapproved})
+
+	// Not effective yet
+	sv.ApplyParameterChanges(98)
+	assert.Equal(uint64(core.MaxNumRegularTxsPerBlock), sv.GetGovernanceParams().MaxNumTxsPerBlock)
+	assert.Equal(2, len(sv.GetParameterChanges()))
+
+	// Written at the end of the block before the effective height
+	sv.ApplyParameterChanges(99)
+	assert.Equal(uint64(512), sv.GetGovernanceParams().MaxNumTxsPerBlock)
+	assert.Equal(core.DefaultBlockGasLimit, sv.GetGovernanceParams().BlockGasLimit)
+
+	changes := sv.GetParameterChanges()
+	assert.Equal(1, len(changes))
+	assert.Equal(core.ParamBlockGasLimit, changes[0].Parameter)
+	assert.False(changes[0].IsApproved())
+}
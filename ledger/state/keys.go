@@ -15,9 +15,14 @@ func ChainIDKey() common.Bytes {
 	return common.Bytes("chainid")
 }
 
+// AccountKeyPrefix returns the prefix for the account keys
+func AccountKeyPrefix() common.Bytes {
+	return common.Bytes("ls/a/")
+}
+
 // AccountKey constructs the state key for the given address
 func AccountKey(addr common.Address) common.Bytes {
-	return append(common.Bytes("ls/a/"), addr[:]...)
+	return append(AccountKeyPrefix(), addr[:]...)
 }
 
 // SplitRuleKeyPrefix returns the prefix for the split rule key
@@ -98,3 +103,82 @@ func EliteEdgeNodeStakeReturnsKey(height uint64) common.Bytes {
 func EliteEdgeNodesTotalActiveStakeKey() common.Bytes {
 	return common.Bytes("ls/eentas")
 }
+
+// GovernanceParamsKey returns the state key for the governance params
+func GovernanceParamsKey() common.Bytes {
+	return common.Bytes("ls/gp")
+}
+
+// ChainParamsKey returns the state key for the chain parameters set at the genesis
+func ChainParamsKey() common.Bytes {
+	return common.Bytes("ls/cp")
+}
+
+// ParameterChangesKey returns the state key for the pending parameter changes
+func ParameterChangesKey() common.Bytes {
+	return common.Bytes("ls/gpc")
+}
+
+// ValidatorKeyChangesKey returns the state key for the pending validator key changes
+func ValidatorKeyChangesKey() common.Bytes {
+	return common.Bytes("ls/vkc")
+}
+
+// StakeAutoCompoundingKey returns the state key for the stake auto-compounding setting of the source
+func StakeAutoCompoundingKey(source common.Address) common.Bytes {
+	return append(common.Bytes("ls/sac/"), source[:]...)
+}
+
+// RandomnessKey returns the state key for the randomness beacon
+func RandomnessKey() common.Bytes {
+	return common.Bytes("ls/rnd")
+}
+
+// BaseFeeKey returns the state key for the base fee per gas of the next block
+func BaseFeeKey() common.Bytes {
+	return common.Bytes("ls/bf")
+}
+
+// BlockGasUsedKey returns the state key for the gas used by the block being applied
+func BlockGasUsedKey() common.Bytes {
+	return common.Bytes("ls/bgu")
+}
+
+// SubchainKeyPrefix returns the prefix of the subchain keys
+func SubchainKeyPrefix() common.Bytes {
+	return common.Bytes("ls/sc/")
+}
+
+// SubchainKey returns the state key for the subchain with the given ID
+func SubchainKey(subchainID string) common.Bytes {
+	return common.Bytes(string(SubchainKeyPrefix()) + subchainID)
+}
+
+// SubchainRegistrationsKey returns the state key for the pending subchain registrations
+func SubchainRegistrationsKey() common.Bytes {
+	return common.Bytes("ls/scr")
+}
+
+// SubchainCheckpointKey returns the state key for the checkpoint of the subchain at the given height
+func SubchainCheckpointKey(subchainID string, height uint64) common.Bytes {
+	heightStr := strconv.FormatUint(height, 10)
+	return common.Bytes("ls/scc/" + subchainID + "/" + heightStr)
+}
+
+// CrossChainChannelKey returns the state key for the message channel with the given peer chain
+func CrossChainChannelKey(peerChainID string) common.Bytes {
+	return common.Bytes("ls/ccc/" + peerChainID)
+}
+
+// CrossChainSentMessageKey returns the state key for the message sent to the target chain with the given nonce
+func CrossChainSentMessageKey(targetChainID string, nonce uint64) common.Bytes {
+	nonceStr := strconv.FormatUint(nonce, 10)
+	return common.Bytes("ls/ccms/" + targetChainID + "/" + nonceStr)
+}
+
+// CrossChainReceivedMessageKey returns the state key for the message received from the source chain with
+// the given nonce
+func CrossChainReceivedMessageKey(sourceChainID string, nonce uint64) common.Bytes {
+	nonceStr := strconv.FormatUint(nonce, 10)
+	return common.Bytes("ls/ccmr/" + sourceChainID + "/" + nonceStr)
+}
@@ -0,0 +1,42 @@
+package state
+
+import (
+	"encoding/binary"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+)
+
+// GetRandomness returns the randomness derived by the last applied block, or an empty hash if the randomness
+// beacon has not started yet.
+func (sv *StoreView) GetRandomness() common.Hash {
+	data := sv.Get(RandomnessKey())
+	if data == nil || len(data) == 0 {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// UpdateRandomness derives the randomness of the block from the randomness of its parent. The aggregated
+// guardian and elite edge node signatures carried by the block are mixed in. These BLS signatures cannot be
+// predicted before the votes are cast, so the randomness is unknown until the block is proposed. However, the
+// proposer decides whether to include the votes, and may also withhold the block, so it can choose between
+// a few outcomes and bias the randomness. Between two blocks carrying the aggregated signatures, the
+// randomness is predictable from its previous value.
+func (sv *StoreView) UpdateRandomness(block *core.Block) {
+	heightBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(heightBytes, block.Height)
+
+	prev := sv.GetRandomness()
+	data := [][]byte{prev[:], heightBytes, block.Parent[:]}
+	if block.GuardianVotes != nil && block.GuardianVotes.Signature != nil {
+		data = append(data, block.GuardianVotes.Signature.ToBytes())
+	}
+	if block.EliteEdgeNodeVotes != nil && block.EliteEdgeNodeVotes.Signature != nil {
+		data = append(data, block.EliteEdgeNodeVotes.Signature.ToBytes())
+	}
+
+	randomness := crypto.Keccak256Hash(data...)
+	sv.Set(RandomnessKey(), randomness[:])
+}
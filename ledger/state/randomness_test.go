@@ -0,0 +1,42 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto/bls"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestUpdateRandomness(t *testing.T) {
+	assert := assert.New(t)
+
+	newBlock := func(height uint64, message string) *core.Block {
+		block := core.NewBlock()
+		block.Height = height
+		block.Parent = common.BytesToHash([]byte("parent"))
+		if message != "" {
+			sk, err := bls.RandKey()
+			assert.Nil(err)
+			block.GuardianVotes = &core.AggregatedVotes{Signature: sk.Sign([]byte(message))}
+		}
+		return block
+	}
+
+	sv1 := NewStoreView(uint64(1), common.Hash{}, backend.NewMemDatabase())
+	sv2 := NewStoreView(uint64(1), common.Hash{}, backend.NewMemDatabase())
+	assert.Equal(common.Hash{}, sv1.GetRandomness())
+
+	block := newBlock(2, "")
+	sv1.UpdateRandomness(block)
+	sv2.UpdateRandomness(block)
+	assert.NotEqual(common.Hash{}, sv1.GetRandomness())
+	assert.Equal(sv1.GetRandomness(), sv2.GetRandomness())
+
+	// The aggregated signatures carried by the block are mixed in
+	sv1.UpdateRandomness(newBlock(3, "a"))
+	sv2.UpdateRandomness(newBlock(3, "b"))
+	assert.NotEqual(sv1.GetRandomness(), sv2.GetRandomness())
+}
@@ -0,0 +1,28 @@
+package state
+
+import (
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/store/database"
+)
+
+// ShadowStateRootKey returns the DB key mapping the state root of a block to the state root computed
+// by the shadow execution of the block
+func ShadowStateRootKey(stateRoot common.Hash) common.Bytes {
+	return append(common.Bytes("shadow/sr/"), stateRoot[:]...)
+}
+
+// SetShadowStateRoot records the state root computed by the shadow execution of a block with the given
+// state root
+func SetShadowStateRoot(db database.Database, stateRoot common.Hash, shadowStateRoot common.Hash) error {
+	return db.Put(ShadowStateRootKey(stateRoot), shadowStateRoot[:])
+}
+
+// GetShadowStateRoot returns the state root computed by the shadow execution of a block with the given
+// state root, if the shadow execution diverged
+func GetShadowStateRoot(db database.Database, stateRoot common.Hash) (common.Hash, bool) {
+	raw, err := db.Get(ShadowStateRootKey(stateRoot))
+	if err != nil || len(raw) != common.HashLength {
+		return common.Hash{}, false
+	}
+	return common.BytesToHash(raw), true
+}
@@ -0,0 +1,40 @@
+package state
+
+import (
+	"log"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// GetStakeAutoCompounding gets the stake auto-compounding setting of the source, or nil if the source has not
+// opted in.
+func (sv *StoreView) GetStakeAutoCompounding(source common.Address) *core.StakeAutoCompounding {
+	data := sv.Get(StakeAutoCompoundingKey(source))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	sac := &core.StakeAutoCompounding{}
+	err := types.FromBytes(data, sac)
+	if err != nil {
+		log.Panicf("Error reading stake auto-compounding setting %X, error: %v",
+			data, err.Error())
+	}
+	return sac
+}
+
+// SetStakeAutoCompounding sets the stake auto-compounding setting of the source.
+func (sv *StoreView) SetStakeAutoCompounding(sac *core.StakeAutoCompounding) {
+	sacBytes, err := types.ToBytes(sac)
+	if err != nil {
+		log.Panicf("Error writing stake auto-compounding setting %v, error: %v",
+			sac, err.Error())
+	}
+	sv.Set(StakeAutoCompoundingKey(sac.Source), sacBytes)
+}
+
+// DeleteStakeAutoCompounding deletes the stake auto-compounding setting of the source.
+func (sv *StoreView) DeleteStakeAutoCompounding(source common.Address) {
+	sv.Delete(StakeAutoCompoundingKey(source))
+}
@@ -0,0 +1,151 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rlp"
+	"github.com/thetatoken/theta/store/treestore"
+	"github.com/thetatoken/theta/store/trie"
+)
+
+// AccountDiff describes the change of an account between two states
+type AccountDiff struct {
+	Address common.Address
+	Before  *types.Account // nil if the account was created
+	After   *types.Account // nil if the account was removed
+	Storage []*StorageDiff // only filled if the storage diff was requested
+}
+
+// StorageDiff describes the change of a storage slot of a smart contract
+type StorageDiff struct {
+	Key    common.Hash
+	Before common.Hash
+	After  common.Hash
+}
+
+// DiffAccounts returns the accounts that differ between the from and to states, ordered by address. Only the
+// subtrees whose hashes differ are visited, so the cost is proportional to the size of the change rather than
+// the size of the state. At most maxAccounts accounts are returned if maxAccounts is positive, in which case
+// the returned flag tells whether more accounts have changed.
+func DiffAccounts(from, to *StoreView, withStorage bool, maxAccounts int) ([]*AccountDiff, bool, error) {
+	prefix := AccountKeyPrefix()
+	keys, err := diffTrieKeys(from.GetStore(), to.GetStore(), prefix)
+	if err != nil {
+		return nil, false, err
+	}
+
+	truncated := false
+	if maxAccounts > 0 && len(keys) > maxAccounts {
+		keys = keys[:maxAccounts]
+		truncated = true
+	}
+
+	diffs := []*AccountDiff{}
+	for _, key := range keys {
+		address := common.BytesToAddress(key[len(prefix):])
+		diff := &AccountDiff{
+			Address: address,
+			Before:  from.GetAccount(address),
+			After:   to.GetAccount(address),
+		}
+		if withStorage {
+			diff.Storage, err = diffAccountStorage(from, to, diff.Before, diff.After)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, truncated, nil
+}
+
+func diffAccountStorage(from, to *StoreView, before, after *types.Account) ([]*StorageDiff, error) {
+	beforeRoot, afterRoot := common.Hash{}, common.Hash{}
+	if before != nil {
+		beforeRoot = before.Root
+	}
+	if after != nil {
+		afterRoot = after.Root
+	}
+	if beforeRoot == afterRoot {
+		return []*StorageDiff{}, nil
+	}
+
+	beforeStore, err := from.store.OpenTrie(beforeRoot)
+	if err != nil {
+		return nil, fmt.Errorf("the storage of the account is not available, it might have been pruned")
+	}
+	afterStore, err := to.store.OpenTrie(afterRoot)
+	if err != nil {
+		return nil, fmt.Errorf("the storage of the account is not available, it might have been pruned")
+	}
+	keys, err := diffTrieKeys(beforeStore, afterStore, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := []*StorageDiff{}
+	for _, key := range keys {
+		beforeValue, err := getStorageValue(beforeStore, key)
+		if err != nil {
+			return nil, err
+		}
+		afterValue, err := getStorageValue(afterStore, key)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, &StorageDiff{
+			Key:    common.BytesToHash(key),
+			Before: beforeValue,
+			After:  afterValue,
+		})
+	}
+	return diffs, nil
+}
+
+func getStorageValue(store *treestore.TreeStore, key []byte) (common.Hash, error) {
+	enc, err := store.TryGet(key)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(enc) == 0 {
+		return common.Hash{}, nil
+	}
+	_, content, _, err := rlp.Split(enc)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(content), nil
+}
+
+// diffTrieKeys returns the sorted keys with the given prefix that were added, removed, or updated between
+// the two tries
+func diffTrieKeys(a, b *treestore.TreeStore, prefix common.Bytes) ([][]byte, error) {
+	changed := make(map[string]bool)
+	for _, pair := range [][2]*treestore.TreeStore{{a, b}, {b, a}} {
+		diffIt, _ := trie.NewDifferenceIterator(pair[0].NodeIterator(prefix), pair[1].NodeIterator(prefix))
+		it := trie.NewIterator(diffIt)
+		for it.Next() {
+			if !bytes.HasPrefix(it.Key, prefix) {
+				break // the keys are visited in order, so the rest are past the prefix
+			}
+			changed[string(it.Key)] = true
+		}
+		if it.Err != nil {
+			return nil, it.Err
+		}
+	}
+
+	keys := make([][]byte, 0, len(changed))
+	for key := range changed {
+		keys = append(keys, []byte(key))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i], keys[j]) < 0
+	})
+	return keys, nil
+}
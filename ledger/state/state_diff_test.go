@@ -0,0 +1,87 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestDiffAccounts(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	carol := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	contract := common.HexToAddress("0x4444444444444444444444444444444444444444")
+	slot1 := common.HexToHash("0x01")
+	slot2 := common.HexToHash("0x02")
+
+	db := backend.NewMemDatabase()
+	sv := NewStoreView(uint64(1), common.Hash{}, db)
+	for _, addr := range []common.Address{alice, bob} {
+		account := types.NewAccount(addr)
+		account.Balance = types.NewCoins(100, 200)
+		sv.SetAccount(addr, account)
+	}
+	sv.SetState(contract, slot1, common.HexToHash("0xaa"))
+	sv.SetState(contract, slot2, common.HexToHash("0xbb"))
+	sv.Set(ChainIDKey(), common.Bytes("test_chain_id"))
+	fromRoot := sv.Save()
+
+	// Update alice, remove bob, create carol, and update a storage slot of the contract
+	sv = NewStoreView(uint64(2), fromRoot, db)
+	account := sv.GetAccount(alice)
+	account.Sequence++
+	account.Balance = types.NewCoins(90, 200)
+	sv.SetAccount(alice, account)
+	sv.DeleteAccount(bob)
+	sv.SetAccount(carol, types.NewAccount(carol))
+	sv.SetState(contract, slot2, common.HexToHash("0xcc"))
+	sv.Set(ChainIDKey(), common.Bytes("other_chain_id")) // not an account, not in the diff
+	toRoot := sv.Save()
+
+	from := NewStoreView(uint64(1), fromRoot, db)
+	to := NewStoreView(uint64(2), toRoot, db)
+
+	diffs, truncated, err := DiffAccounts(from, to, false, 0)
+	require.Nil(err)
+	assert.False(truncated)
+	require.Equal(4, len(diffs))
+	assert.Equal(alice, diffs[0].Address)
+	assert.Equal(uint64(0), diffs[0].Before.Sequence)
+	assert.Equal(uint64(1), diffs[0].After.Sequence)
+	assert.Equal(int64(90), diffs[0].After.Balance.ThetaWei.Int64())
+	assert.Nil(diffs[0].Storage)
+	assert.Equal(bob, diffs[1].Address)
+	assert.NotNil(diffs[1].Before)
+	assert.Nil(diffs[1].After)
+	assert.Equal(carol, diffs[2].Address)
+	assert.Nil(diffs[2].Before)
+	assert.NotNil(diffs[2].After)
+	assert.Equal(contract, diffs[3].Address)
+
+	diffs, truncated, err = DiffAccounts(from, to, true, 0)
+	require.Nil(err)
+	require.Equal(4, len(diffs))
+	assert.Equal(0, len(diffs[0].Storage))
+	require.Equal(1, len(diffs[3].Storage))
+	assert.Equal(slot2, diffs[3].Storage[0].Key)
+	assert.Equal(common.HexToHash("0xbb"), diffs[3].Storage[0].Before)
+	assert.Equal(common.HexToHash("0xcc"), diffs[3].Storage[0].After)
+
+	diffs, truncated, err = DiffAccounts(from, to, false, 2)
+	require.Nil(err)
+	assert.True(truncated)
+	require.Equal(2, len(diffs))
+	assert.Equal(bob, diffs[1].Address)
+
+	diffs, truncated, err = DiffAccounts(to, to, true, 0)
+	require.Nil(err)
+	assert.False(truncated)
+	assert.Equal(0, len(diffs))
+}
@@ -35,9 +35,19 @@ type StoreView struct {
 func NewStoreView(height uint64, root common.Hash, db database.Database) *StoreView {
 	store := treestore.NewTreeStore(root, db)
 	if store == nil {
-		return nil
+		// In the shadow mode, the state of a diverged block is stored under the root computed by the
+		// shadow execution
+		shadowRoot, ok := GetShadowStateRoot(db, root)
+		if !ok {
+			return nil
+		}
+		if store = treestore.NewTreeStore(shadowRoot, db); store == nil {
+			return nil
+		}
 	}
 
+	store.Trie.GetDB().SetExternalRefs(accountStorageRoots)
+
 	sv := &StoreView{
 		height:       height,
 		store:        store,
@@ -170,36 +180,29 @@ func (sv *StoreView) GetAccount(addr common.Address) *types.Account {
 // 	sv.Set(AccountKey(addr), accBytes)
 // }
 
-// SetAccount sets an account.
+// SetAccount sets an account. The storage trie of the account is referenced by the state trie
+// nodes holding the account, see accountStorageRoots.
 func (sv *StoreView) SetAccount(addr common.Address, acc *types.Account) {
-	sv.setAccount(addr, acc, true)
-}
-
-func (sv *StoreView) setAccountWithoutStateTreeRefCountUpdate(addr common.Address, acc *types.Account) {
-	sv.setAccount(addr, acc, false)
-}
-
-func (sv *StoreView) setAccount(addr common.Address, acc *types.Account, updateRefCountForAccountStateTree bool) {
 	accBytes, err := types.ToBytes(acc)
 	if err != nil {
 		log.Panicf("Error writing account %v error: %v",
 			acc, err.Error())
 	}
 	sv.Set(AccountKey(addr), accBytes)
+}
 
-	if !updateRefCountForAccountStateTree {
-		return
-	}
-
-	if (acc == nil || acc.Root == common.Hash{}) || (acc.Root == core.EmptyRootHash) {
-		return
+// accountStorageRoots returns the root of the storage trie of the account stored in the given
+// value of the state trie, if any. The state trie nodes holding the account reference the storage
+// trie, so that it is persisted with the state and pruned once no state refers to it.
+func accountStorageRoots(value []byte) []common.Hash {
+	account := &types.Account{}
+	if err := types.FromBytes(value, account); err != nil {
+		return nil
 	}
-
-	tree := sv.getAccountStorage(acc)
-	_, err = tree.Commit() // update the reference count of the account state trie root
-	if err != nil {
-		log.Panic(err)
+	if (account.Root == common.Hash{}) || (account.Root == core.EmptyRootHash) {
+		return nil
 	}
+	return []common.Hash{account.Root}
 }
 
 // DeleteAccount deletes an account.
@@ -588,8 +591,15 @@ func (sv *StoreView) GetCommittedState(addr common.Address, key common.Hash) com
 	return sv.GetState(addr, key)
 }
 
+// getAccountStorage opens the storage trie of the account on the trie DB of the state trie, so
+// that the storage updates stay in memory until the state is saved.
 func (sv *StoreView) getAccountStorage(account *types.Account) *treestore.TreeStore {
-	return treestore.NewTreeStore(account.Root, sv.store.GetDB())
+	tree, err := sv.store.OpenTrie(account.Root)
+	if err != nil {
+		logger.Errorf("Failed to open the storage of account %v: %v", account.Address.Hex(), err)
+		return nil
+	}
+	return tree
 }
 
 func (sv *StoreView) GetState(addr common.Address, key common.Hash) common.Hash {
@@ -621,25 +631,25 @@ func (sv *StoreView) SetState(addr common.Address, key, val common.Hash) {
 	tree := sv.getAccountStorage(account)
 	if (val == common.Hash{}) {
 		tree.TryDelete(key[:])
-		root, err := tree.Commit()
+		root, err := tree.Trie.Commit(nil) // Persisted along with the state trie
 		if err != nil {
 			log.Panic(err)
 		}
 		account.Root = root
-		sv.setAccountWithoutStateTreeRefCountUpdate(addr, account) // The ref counts of the state tree already got updated above
+		sv.SetAccount(addr, account)
 		logger.Debugf("StoreView.SetState, address: %v, account.root: %v, key: %v, val: %v", addr.Hex(), root.Hex(), key.Hex(), val.Hex())
 		return
 	}
 	// Encoding []byte cannot fail, ok to ignore the error.
 	v, _ := rlp.EncodeToBytes(bytes.TrimLeft(val[:], "\x00"))
 	tree.TryUpdate(key[:], v)
-	root, err := tree.Commit()
+	root, err := tree.Trie.Commit(nil) // Persisted along with the state trie
 	if err != nil {
 		log.Panic(err)
 	}
 
 	account.Root = root
-	sv.setAccountWithoutStateTreeRefCountUpdate(addr, account) // The ref counts of the state tree already got updated above
+	sv.SetAccount(addr, account)
 
 	logger.Debugf("StoreView.SetState, address: %v, account.root: %v, key: %v, val: %v", addr.Hex(), root.Hex(), key.Hex(), val.Hex())
 }
@@ -696,26 +706,10 @@ func (sv *StoreView) Snapshot() common.Hash {
 	return sv.store.Hash()
 }
 
+// Prune releases the state of the StoreView, deleting the state trie nodes and the account
+// storage trie nodes no other state refers to
 func (sv *StoreView) Prune() error {
-	err := sv.store.Prune(func(node []byte) bool {
-		account := &types.Account{}
-		err := types.FromBytes(node, account)
-		if err != nil {
-			return false
-		}
-		if (account.Root == (common.Hash{})) || (account.Root == core.EmptyRootHash) {
-			return false
-		}
-		storage := sv.getAccountStorage(account)
-		logger.Debugf("StoreView.Prune, address: %v, account.root: %v", account.Address, account.Root.Hex())
-
-		err = storage.Prune(nil)
-		if err != nil {
-			logger.Errorf("Failed to prune storage for account %v", account)
-			return false
-		}
-		return true
-	})
+	err := sv.store.Prune(nil)
 	if err != nil {
 		return fmt.Errorf("Failed to prune store view, %v", err)
 	}
@@ -254,6 +254,49 @@ func TestRevertAndPruneStoreView(t *testing.T) {
 	assert.Equal(value2, sv.GetState(acc1Addr, key1))
 }
 
+func TestPruneAccountStorage(t *testing.T) {
+	assert := assert.New(t)
+
+	db := backend.NewMemDatabase()
+	sv := NewStoreView(uint64(1), common.Hash{}, db)
+
+	addr := common.HexToAddress("0x111")
+	sv.SetAccount(addr, types.NewAccount(addr))
+	key1 := common.BytesToHash([]byte{1})
+	key2 := common.BytesToHash([]byte{2})
+	value1 := common.BytesToHash([]byte{11})
+	value2 := common.BytesToHash([]byte{22})
+
+	// The intermediate storage roots stay in memory
+	sv.SetState(addr, key1, value2)
+	sv.SetState(addr, key1, value1)
+	root1 := sv.Save()
+	numNodes := db.Len()
+	sv.SetState(addr, key2, value2)
+	root2 := sv.Save()
+	// Setting the account again does not reference its storage again
+	sv.SetAccount(addr, sv.GetAccount(addr))
+	assert.Equal(root2, sv.Save())
+
+	// The storage shared by the states is kept until both are pruned
+	assert.Nil(NewStoreView(uint64(2), root1, db).Prune())
+	assert.True(db.Len() > 0)
+	sv2 := NewStoreView(uint64(2), root2, db)
+	assert.Equal(value1, sv2.GetState(addr, key1))
+	assert.Equal(value2, sv2.GetState(addr, key2))
+
+	assert.Nil(NewStoreView(uint64(2), root2, db).Prune())
+	assert.Nil(NewStoreView(uint64(2), root2, db).Prune())
+	assert.Equal(0, db.Len())
+
+	// Recreating a pruned state persists it again
+	sv = NewStoreView(uint64(1), common.Hash{}, db)
+	sv.SetAccount(addr, types.NewAccount(addr))
+	sv.SetState(addr, key1, value1)
+	assert.Equal(root1, sv.Save())
+	assert.Equal(numNodes, db.Len())
+}
+
 func TestGetAndUpdateValidatorCandidatePool(t *testing.T) {
 	assert := assert.New(t)
 
@@ -280,18 +323,18 @@ func TestGetAndUpdateValidatorCandidatePool(t *testing.T) {
 
 	vcp := &core.ValidatorCandidatePool{}
 
-	assert.Nil(vcp.DepositStake(sourceAddr1, holderAddr1, stake1Amount1))
-	assert.Nil(vcp.DepositStake(sourceAddr2, holderAddr1, stake2Amount1))
-	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr1, stake3Amount2))
+	assert.Nil(vcp.DepositStake(sourceAddr1, holderAddr1, stake1Amount1, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr2, holderAddr1, stake2Amount1, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr1, stake3Amount2, "testnet"))
 
-	assert.Nil(vcp.DepositStake(sourceAddr1, holderAddr2, stake1Amount2))
-	assert.Nil(vcp.DepositStake(sourceAddr2, holderAddr2, stake2Amount2))
-	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr2, stake3Amount2))
+	assert.Nil(vcp.DepositStake(sourceAddr1, holderAddr2, stake1Amount2, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr2, holderAddr2, stake2Amount2, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr2, stake3Amount2, "testnet"))
 
-	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr3, stake3Amount1))
+	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr3, stake3Amount1, "testnet"))
 
-	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr4, stake3Amount3))
-	assert.Nil(vcp.DepositStake(sourceAddr4, holderAddr4, stake4Amount1))
+	assert.Nil(vcp.DepositStake(sourceAddr3, holderAddr4, stake3Amount3, "testnet"))
+	assert.Nil(vcp.DepositStake(sourceAddr4, holderAddr4, stake4Amount1, "testnet"))
 
 	db := backend.NewMemDatabase()
 	sv := NewStoreView(uint64(1), common.Hash{}, db)
@@ -443,3 +486,23 @@ func compareHeightList(hl1, hl2 *types.HeightList) bool {
 
 	return true
 }
+
+func TestStoreViewShadowStateRoot(t *testing.T) {
+	assert := assert.New(t)
+
+	db := backend.NewMemDatabase()
+	sv := NewStoreView(1, common.Hash{}, db)
+	sv.Set(common.Bytes("key1"), common.Bytes("value1"))
+	shadowRoot := sv.Save()
+
+	// The state root of the block is not in the DB
+	blockStateRoot := common.BytesToHash([]byte("block state root"))
+	assert.Nil(NewStoreView(1, blockStateRoot, db))
+
+	// The state computed by the shadow execution is loaded by the state root of the block
+	assert.Nil(SetShadowStateRoot(db, blockStateRoot, shadowRoot))
+	shadowSV := NewStoreView(1, blockStateRoot, db)
+	assert.NotNil(shadowSV)
+	assert.Equal(shadowRoot, shadowSV.Hash())
+	assert.Equal(common.Bytes("value1"), shadowSV.Get(common.Bytes("key1")))
+}
@@ -0,0 +1,145 @@
+package state
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/trie"
+)
+
+// GetSubchain gets the subchain with the given ID, or nil if it has not been registered.
+func (sv *StoreView) GetSubchain(subchainID string) *core.Subchain {
+	data := sv.Get(SubchainKey(subchainID))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	subchain := &core.Subchain{}
+	err := types.FromBytes(data, subchain)
+	if err != nil {
+		log.Panicf("Error reading subchain %X, error: %v",
+			data, err.Error())
+	}
+	return subchain
+}
+
+// SetSubchain sets the subchain.
+func (sv *StoreView) SetSubchain(subchain *core.Subchain) {
+	subchainBytes, err := types.ToBytes(subchain)
+	if err != nil {
+		log.Panicf("Error writing subchain %v, error: %v",
+			subchain, err.Error())
+	}
+	sv.Set(SubchainKey(subchain.SubchainID), subchainBytes)
+}
+
+// GetSubchainRegistrations gets the pending subchain registrations.
+func (sv *StoreView) GetSubchainRegistrations() []*core.SubchainRegistration {
+	data := sv.Get(SubchainRegistrationsKey())
+	if data == nil || len(data) == 0 {
+		return []*core.SubchainRegistration{}
+	}
+	registrations := []*core.SubchainRegistration{}
+	err := types.FromBytes(data, &registrations)
+	if err != nil {
+		log.Panicf("Error reading subchain registrations %X, error: %v",
+			data, err.Error())
+	}
+	return registrations
+}
+
+// UpdateSubchainRegistrations updates the pending subchain registrations.
+func (sv *StoreView) UpdateSubchainRegistrations(registrations []*core.SubchainRegistration) {
+	if len(registrations) == 0 {
+		sv.Delete(SubchainRegistrationsKey())
+		return
+	}
+	registrationsBytes, err := types.ToBytes(registrations)
+	if err != nil {
+		log.Panicf("Error writing subchain registrations %v, error: %v",
+			registrations, err.Error())
+	}
+	sv.Set(SubchainRegistrationsKey(), registrationsBytes)
+}
+
+// GetSubchainCheckpoint gets the checkpoint of the subchain at the given height, or nil if the height
+// has not been anchored.
+func (sv *StoreView) GetSubchainCheckpoint(subchainID string, height uint64) *core.SubchainCheckpoint {
+	data := sv.Get(SubchainCheckpointKey(subchainID, height))
+	if data == nil || len(data) == 0 {
+		return nil
+	}
+	checkpoint := &core.SubchainCheckpoint{}
+	err := types.FromBytes(data, checkpoint)
+	if err != nil {
+		log.Panicf("Error reading subchain checkpoint %X, error: %v",
+			data, err.Error())
+	}
+	return checkpoint
+}
+
+// SetSubchainCheckpoint sets the checkpoint of the subchain.
+func (sv *StoreView) SetSubchainCheckpoint(checkpoint *core.SubchainCheckpoint) {
+	checkpointBytes, err := types.ToBytes(checkpoint)
+	if err != nil {
+		log.Panicf("Error writing subchain checkpoint %v, error: %v",
+			checkpoint, err.Error())
+	}
+	sv.Set(SubchainCheckpointKey(checkpoint.SubchainID, checkpoint.Height), checkpointBytes)
+}
+
+// ProveSubchainCheckpoint returns the trie nodes proving the checkpoint of the subchain at the given
+// height against the state root of the view.
+func (sv *StoreView) ProveSubchainCheckpoint(subchainID string, height uint64) ([]common.Bytes, error) {
+	return sv.proveKey(SubchainCheckpointKey(subchainID, height))
+}
+
+// VerifySubchainCheckpointProof verifies the trie nodes returned by ProveSubchainCheckpoint against the
+// state root of a main chain block, and returns the proven checkpoint.
+func VerifySubchainCheckpointProof(stateHash common.Hash, subchainID string, height uint64, nodes []common.Bytes) (*core.SubchainCheckpoint, error) {
+	data, err := verifyKeyProof(stateHash, SubchainCheckpointKey(subchainID, height), nodes)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := &core.SubchainCheckpoint{}
+	if err := types.FromBytes(data, checkpoint); err != nil {
+		return nil, err
+	}
+	if checkpoint.SubchainID != subchainID || checkpoint.Height != height {
+		return nil, fmt.Errorf("proven checkpoint %v does not match subchain %v at height %v", checkpoint, subchainID, height)
+	}
+	return checkpoint, nil
+}
+
+// proveKey returns the trie nodes on the path from the state root of the view to the given key
+func (sv *StoreView) proveKey(key common.Bytes) ([]common.Bytes, error) {
+	vp := &core.VCPProof{}
+	if err := sv.ProveVCP(key, vp); err != nil {
+		return nil, err
+	}
+	nodes := []common.Bytes{}
+	for _, kv := range vp.GetKvs() {
+		nodes = append(nodes, kv.Val)
+	}
+	return nodes, nil
+}
+
+// verifyKeyProof verifies the trie nodes returned by proveKey against the given state root, and returns
+// the proven value of the key
+func verifyKeyProof(stateHash common.Hash, key common.Bytes, nodes []common.Bytes) ([]byte, error) {
+	proof := &core.VCPProof{}
+	for _, node := range nodes {
+		proof.Put(crypto.Keccak256(node), node)
+	}
+	data, _, err := trie.VerifyProof(stateHash, key, proof)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("key %v does not exist in state %v", string(key), stateHash.Hex())
+	}
+	return data, nil
+}
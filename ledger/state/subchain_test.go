@@ -0,0 +1,61 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestSubchainCheckpointProof(t *testing.T) {
+	assert := assert.New(t)
+
+	db := backend.NewMemDatabase()
+	sv := NewStoreView(uint64(1), common.Hash{}, db)
+
+	assert.Nil(sv.GetSubchain("tsub_1"))
+	assert.Nil(sv.GetSubchainCheckpoint("tsub_1", 100))
+
+	operator := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	sv.SetSubchain(&core.Subchain{
+		SubchainID:   "tsub_1",
+		Operator:     operator,
+		LatestHeight: 200,
+		NumAnchors:   2,
+	})
+	for _, height := range []uint64{100, 200} {
+		sv.SetSubchainCheckpoint(&core.SubchainCheckpoint{
+			SubchainID:   "tsub_1",
+			Height:       height,
+			BlockHash:    common.BytesToHash([]byte{byte(height)}),
+			StateHash:    common.BytesToHash([]byte{byte(height), 1}),
+			Submitter:    operator,
+			AnchorHeight: 1,
+		})
+	}
+	stateHash := sv.Save()
+
+	subchain := sv.GetSubchain("tsub_1")
+	assert.NotNil(subchain)
+	assert.Equal(operator, subchain.Operator)
+	assert.Equal(uint64(200), subchain.LatestHeight)
+
+	proof, err := sv.ProveSubchainCheckpoint("tsub_1", 200)
+	assert.Nil(err)
+	checkpoint, err := VerifySubchainCheckpointProof(stateHash, "tsub_1", 200, proof)
+	assert.Nil(err)
+	assert.Equal(common.BytesToHash([]byte{200}), checkpoint.BlockHash)
+
+	// The proof does not prove other checkpoints or other state roots
+	_, err = VerifySubchainCheckpointProof(stateHash, "tsub_1", 100, proof)
+	assert.NotNil(err)
+	_, err = VerifySubchainCheckpointProof(common.BytesToHash([]byte{1}), "tsub_1", 200, proof)
+	assert.NotNil(err)
+
+	assert.NotNil(core.ValidateSubchainID("", "privatenet"))
+	assert.NotNil(core.ValidateSubchainID("privatenet", "privatenet"))
+	assert.NotNil(core.ValidateSubchainID("tsub/1", "privatenet"))
+	assert.Nil(core.ValidateSubchainID("tsub_1", "privatenet"))
+}
@@ -0,0 +1,79 @@
+package state
+
+import (
+	"log"
+
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// GetValidatorKeyChanges gets the pending validator key changes.
+func (sv *StoreView) GetValidatorKeyChanges() []*core.ValidatorKeyChange {
+	data := sv.Get(ValidatorKeyChangesKey())
+	if data == nil || len(data) == 0 {
+		return []*core.ValidatorKeyChange{}
+	}
+	changes := []*core.ValidatorKeyChange{}
+	err := types.FromBytes(data, &changes)
+	if err != nil {
+		log.Panicf("Error reading validator key changes %X, error: %v",
+			data, err.Error())
+	}
+	return changes
+}
+
+// UpdateValidatorKeyChanges updates the pending validator key changes.
+func (sv *StoreView) UpdateValidatorKeyChanges(changes []*core.ValidatorKeyChange) {
+	if len(changes) == 0 {
+		sv.Delete(ValidatorKeyChangesKey())
+		return
+	}
+	changesBytes, err := types.ToBytes(changes)
+	if err != nil {
+		log.Panicf("Error writing validator key changes %v, error: %v",
+			changes, err.Error())
+	}
+	sv.Set(ValidatorKeyChangesKey(), changesBytes)
+}
+
+// ApplyValidatorKeyChanges applies the validator key changes which take effect at the given block height
+// to the validator candidate pool. A change is dropped if the holder has left the candidate pool or the
+// new holder has joined it during the delay.
+func (sv *StoreView) ApplyValidatorKeyChanges(blockHeight uint64) {
+	changes := sv.GetValidatorKeyChanges()
+	if len(changes) == 0 {
+		return
+	}
+
+	vcp := sv.GetValidatorCandidatePool()
+	pending := []*core.ValidatorKeyChange{}
+	applied := false
+	for _, change := range changes {
+		if change.EffectiveHeight > blockHeight {
+			pending = append(pending, change)
+			continue
+		}
+		if err := vcp.ChangeHolder(change.Holder, change.NewHolder, change.NewSource); err != nil {
+			logger.Warnf("Failed to apply validator key change %v: %v", change, err)
+			continue
+		}
+		logger.Infof("Validator key changed: holder = %v, new holder = %v, new source = %v",
+			change.Holder, change.NewHolder, change.NewSource)
+		applied = true
+	}
+
+	if applied {
+		sv.UpdateValidatorCandidatePool(vcp)
+
+		// The validator candidate pool changes at this height, similar to a stake transaction
+		hl := sv.GetStakeTransactionHeightList()
+		if hl == nil {
+			hl = &types.HeightList{}
+		}
+		hl.Append(blockHeight)
+		sv.UpdateStakeTransactionHeightList(hl)
+	}
+	if len(pending) != len(changes) {
+		sv.UpdateValidatorKeyChanges(pending)
+	}
+}
@@ -0,0 +1,160 @@
+package ledger
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+	mp "github.com/thetatoken/theta/mempool"
+)
+
+// The strategies to order the mempool transactions for a block proposal
+const (
+	TxSelectionFeePriority = "fee_priority"
+	TxSelectionFIFO        = "fifo"
+)
+
+// txSelectionPolicy decides which mempool transactions the node includes in its block proposals. It only
+// affects the blocks proposed by the node, the validation of the blocks does not depend on it.
+type txSelectionPolicy struct {
+	strategy string
+	reserved map[string]int // tx type -> number of the txs picked before the other types
+	limits   map[string]int // tx type -> max number of the txs
+}
+
+func newTxSelectionPolicy(strategy string, reserved map[string]int, limits map[string]int) (*txSelectionPolicy, error) {
+	if strategy == "" {
+		strategy = TxSelectionFeePriority
+	}
+	if strategy != TxSelectionFeePriority && strategy != TxSelectionFIFO {
+		return nil, fmt.Errorf("unknown tx selection strategy %v, expected %v or %v", strategy, TxSelectionFeePriority, TxSelectionFIFO)
+	}
+	for _, quotas := range []map[string]int{reserved, limits} {
+		for txType, quota := range quotas {
+			if quota < 0 {
+				return nil, fmt.Errorf("negative quota %v for tx type %v", quota, txType)
+			}
+		}
+	}
+	if reserved == nil {
+		reserved = map[string]int{}
+	}
+	if limits == nil {
+		limits = map[string]int{}
+	}
+	return &txSelectionPolicy{
+		strategy: strategy,
+		reserved: reserved,
+		limits:   limits,
+	}, nil
+}
+
+// loadTxSelectionPolicy loads the policy from the config
+func loadTxSelectionPolicy() (*txSelectionPolicy, error) {
+	reserved := map[string]int{}
+	if err := viper.UnmarshalKey(common.CfgConsensusTxTypeReserved, &reserved); err != nil {
+		return nil, fmt.Errorf("failed to parse %v: %v", common.CfgConsensusTxTypeReserved, err)
+	}
+	limits := map[string]int{}
+	if err := viper.UnmarshalKey(common.CfgConsensusTxTypeLimits, &limits); err != nil {
+		return nil, fmt.Errorf("failed to parse %v: %v", common.CfgConsensusTxTypeLimits, err)
+	}
+	return newTxSelectionPolicy(viper.GetString(common.CfgConsensusTxSelection), reserved, limits)
+}
+
+// isDefault returns true if the policy is the same as reaping the mempool
+func (p *txSelectionPolicy) isDefault() bool {
+	return p == nil || p.strategy == TxSelectionFeePriority && len(p.reserved) == 0 && len(p.limits) == 0
+}
+
+// selectTxs picks up to maxNumTxs transactions from the candidates in the reap order. The transactions of
+// the reserved types are picked first, then the rest in the order of the strategy, within the type limits.
+// The transactions of a sender are only picked after all its lower sequence transactions.
+func (p *txSelectionPolicy) selectTxs(candidates []*mp.Candidate, maxNumTxs int) []common.Bytes {
+	if p.strategy == TxSelectionFIFO {
+		candidates = orderByArrival(candidates)
+	}
+
+	txTypes := make([]string, len(candidates))
+	positions := make([]int, len(candidates)) // position of the tx among the candidates of its sender
+	numCandidates := make(map[common.Address]int)
+	for i, candidate := range candidates {
+		if tx, err := types.TxFromBytes(candidate.RawTx); err == nil {
+			txTypes[i] = blockchain.TxSearchTypeOf(tx)
+		}
+		positions[i] = numCandidates[candidate.TxInfo.Address]
+		numCandidates[candidate.TxInfo.Address]++
+	}
+
+	picked := make([]bool, len(candidates))
+	numPicked := 0
+	numPickedBySender := make(map[common.Address]int)
+	numPickedByType := make(map[string]int)
+	pick := func(i int, quota map[string]int) {
+		if picked[i] || numPicked >= maxNumTxs {
+			return
+		}
+		sender := candidates[i].TxInfo.Address
+		if numPickedBySender[sender] != positions[i] {
+			return
+		}
+		if max, ok := quota[txTypes[i]]; ok && numPickedByType[txTypes[i]] >= max {
+			return
+		}
+		if max, ok := p.limits[txTypes[i]]; ok && numPickedByType[txTypes[i]] >= max {
+			return
+		}
+		picked[i] = true
+		numPicked++
+		numPickedBySender[sender]++
+		numPickedByType[txTypes[i]]++
+	}
+
+	// The lower sequence txs of a sender always precede its higher sequence ones in the candidates,
+	// so one pass picks all the eligible txs
+	if len(p.reserved) > 0 {
+		for i := range candidates {
+			if _, ok := p.reserved[txTypes[i]]; ok {
+				pick(i, p.reserved)
+			}
+		}
+	}
+	for i := range candidates {
+		pick(i, nil)
+	}
+
+	rawTxs := []common.Bytes{}
+	for i, candidate := range candidates {
+		if picked[i] {
+			rawTxs = append(rawTxs, candidate.RawTx)
+		}
+	}
+	return rawTxs
+}
+
+// orderByArrival orders the candidates by their arrival time, keeping the transactions of each sender
+// in the sequence order. A transaction is ordered by the latest arrival time of the transactions of
+// the sender up to it.
+func orderByArrival(candidates []*mp.Candidate) []*mp.Candidate {
+	keys := make(map[*mp.Candidate]int64, len(candidates))
+	latest := make(map[common.Address]int64)
+	for _, candidate := range candidates {
+		key := candidate.ArrivedAt.UnixNano()
+		if prev, ok := latest[candidate.TxInfo.Address]; ok && prev > key {
+			key = prev
+		}
+		latest[candidate.TxInfo.Address] = key
+		keys[candidate] = key
+	}
+
+	ordered := make([]*mp.Candidate, len(candidates))
+	copy(ordered, candidates)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return keys[ordered[i]] < keys[ordered[j]]
+	})
+	return ordered
+}
@@ -0,0 +1,125 @@
+package ledger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/types"
+	mp "github.com/thetatoken/theta/mempool"
+)
+
+var txSelectionBaseTime = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newSelectionCandidate(t *testing.T, txType string, sender common.Address, sequence uint64, arrivedAtSecs int) *mp.Candidate {
+	input := types.TxInput{Address: sender, Coins: types.NewCoins(0, 1), Sequence: sequence}
+	var tx types.Tx
+	switch txType {
+	case blockchain.TxSearchTypeServicePayment:
+		tx = &types.ServicePaymentTx{Fee: types.NewCoins(0, 1), Source: input, Target: types.TxInput{Coins: types.NewCoins(0, 0)}}
+	default:
+		tx = &types.SendTx{Fee: types.NewCoins(0, 1), Inputs: []types.TxInput{input}}
+	}
+	rawTx, err := types.TxToBytes(tx)
+	require.Nil(t, err)
+	return &mp.Candidate{
+		RawTx:     rawTx,
+		TxInfo:    &core.TxInfo{Address: sender, Sequence: sequence},
+		ArrivedAt: txSelectionBaseTime.Add(time.Duration(arrivedAtSecs) * time.Second),
+	}
+}
+
+func TestTxSelectionPolicyConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	policy, err := newTxSelectionPolicy("", nil, nil)
+	assert.Nil(err)
+	assert.True(policy.isDefault())
+
+	policy, err = newTxSelectionPolicy(TxSelectionFIFO, nil, nil)
+	assert.Nil(err)
+	assert.False(policy.isDefault())
+
+	policy, err = newTxSelectionPolicy(TxSelectionFeePriority, nil, map[string]int{blockchain.TxSearchTypeSend: 10})
+	assert.Nil(err)
+	assert.False(policy.isDefault())
+
+	_, err = newTxSelectionPolicy("lifo", nil, nil)
+	assert.NotNil(err)
+	_, err = newTxSelectionPolicy(TxSelectionFIFO, map[string]int{blockchain.TxSearchTypeSend: -1}, nil)
+	assert.NotNil(err)
+}
+
+func TestTxSelectionReservedAndLimits(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	carol := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	send1 := newSelectionCandidate(t, blockchain.TxSearchTypeSend, alice, 1, 0)
+	send2 := newSelectionCandidate(t, blockchain.TxSearchTypeSend, alice, 2, 1)
+	send3 := newSelectionCandidate(t, blockchain.TxSearchTypeSend, carol, 1, 2)
+	payment := newSelectionCandidate(t, blockchain.TxSearchTypeServicePayment, bob, 1, 3)
+	candidates := []*mp.Candidate{send1, send2, send3, payment}
+
+	// The reserved payment is picked although it is the last in the reap order
+	policy, err := newTxSelectionPolicy(TxSelectionFeePriority, map[string]int{blockchain.TxSearchTypeServicePayment: 1}, nil)
+	require.Nil(err)
+	assert.Equal([]common.Bytes{send1.RawTx, send2.RawTx, payment.RawTx}, policy.selectTxs(candidates, 3))
+
+	// The limit applies to the reserved txs too
+	policy, err = newTxSelectionPolicy(TxSelectionFeePriority,
+		map[string]int{blockchain.TxSearchTypeServicePayment: 1}, map[string]int{blockchain.TxSearchTypeServicePayment: 0})
+	require.Nil(err)
+	assert.Equal([]common.Bytes{send1.RawTx, send2.RawTx, send3.RawTx}, policy.selectTxs(candidates, 4))
+
+	policy, err = newTxSelectionPolicy(TxSelectionFeePriority, nil, map[string]int{blockchain.TxSearchTypeSend: 1})
+	require.Nil(err)
+	assert.Equal([]common.Bytes{send1.RawTx, payment.RawTx}, policy.selectTxs(candidates, 4))
+}
+
+func TestTxSelectionSenderOrder(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	// The reserved payment of alice cannot skip ahead of her lower sequence send
+	send := newSelectionCandidate(t, blockchain.TxSearchTypeSend, alice, 1, 0)
+	payment := newSelectionCandidate(t, blockchain.TxSearchTypeServicePayment, alice, 2, 1)
+	candidates := []*mp.Candidate{send, payment}
+
+	policy, err := newTxSelectionPolicy(TxSelectionFeePriority,
+		map[string]int{blockchain.TxSearchTypeServicePayment: 1}, map[string]int{blockchain.TxSearchTypeSend: 0})
+	require.Nil(err)
+	assert.Equal([]common.Bytes{}, policy.selectTxs(candidates, 2))
+
+	policy, err = newTxSelectionPolicy(TxSelectionFeePriority, map[string]int{blockchain.TxSearchTypeServicePayment: 1}, nil)
+	require.Nil(err)
+	assert.Equal([]common.Bytes{send.RawTx}, policy.selectTxs(candidates, 1))
+}
+
+func TestTxSelectionFIFO(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	// The higher sequence tx of alice arrived first, it waits for her lower sequence tx
+	alice1 := newSelectionCandidate(t, blockchain.TxSearchTypeSend, alice, 1, 20)
+	alice2 := newSelectionCandidate(t, blockchain.TxSearchTypeSend, alice, 2, 0)
+	bob1 := newSelectionCandidate(t, blockchain.TxSearchTypeSend, bob, 1, 10)
+	candidates := []*mp.Candidate{alice1, alice2, bob1}
+
+	policy, err := newTxSelectionPolicy(TxSelectionFIFO, nil, nil)
+	require.Nil(err)
+	assert.Equal([]common.Bytes{bob1.RawTx, alice1.RawTx, alice2.RawTx}, policy.selectTxs(candidates, 3))
+	assert.Equal([]common.Bytes{bob1.RawTx}, policy.selectTxs(candidates, 1))
+}
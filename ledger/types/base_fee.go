@@ -0,0 +1,45 @@
+package types
+
+import (
+	"math/big"
+)
+
+const (
+	// BaseFeeChangeDenominator bounds the change of the base fee between two consecutive blocks to 1/8
+	BaseFeeChangeDenominator int64 = 8
+
+	// BaseFeeElasticityMultiplier is the ratio of the block gas limit to the gas target of a block. The base fee
+	// stays the same when the smart contract txs of a block use exactly the target.
+	BaseFeeElasticityMultiplier uint64 = 2
+)
+
+// NextBaseFee calculates the base fee per gas of the next block from the base fee and the gas used by the current
+// block. The base fee goes up when the gas used is above the target, and goes down when it is below the target,
+// but never below the minBaseFee.
+func NextBaseFee(baseFee *big.Int, gasUsed uint64, blockGasLimit uint64, minBaseFee *big.Int) *big.Int {
+	gasTarget := blockGasLimit / BaseFeeElasticityMultiplier
+	next := new(big.Int).Set(baseFee)
+	if gasTarget != 0 && gasUsed != gasTarget {
+		var gasDelta *big.Int
+		if gasUsed > gasTarget {
+			gasDelta = new(big.Int).SetUint64(gasUsed - gasTarget)
+		} else {
+			gasDelta = new(big.Int).SetUint64(gasTarget - gasUsed)
+		}
+		delta := new(big.Int).Mul(baseFee, gasDelta)
+		delta.Div(delta, new(big.Int).SetUint64(gasTarget))
+		delta.Div(delta, big.NewInt(BaseFeeChangeDenominator))
+		if gasUsed > gasTarget {
+			if delta.Sign() == 0 {
+				delta.SetInt64(1) // so that a small base fee can still go up
+			}
+			next.Add(next, delta)
+		} else {
+			next.Sub(next, delta)
+		}
+	}
+	if next.Cmp(minBaseFee) < 0 {
+		next.Set(minBaseFee)
+	}
+	return next
+}
@@ -0,0 +1,26 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextBaseFee(t *testing.T) {
+	assert := assert.New(t)
+
+	baseFee := big.NewInt(8000)
+	minBaseFee := big.NewInt(4000)
+	blockGasLimit := uint64(200e6)
+
+	// Unchanged at the target, up to 1/8 above, down to 1/8 below
+	assert.Equal(int64(8000), NextBaseFee(baseFee, 100e6, blockGasLimit, minBaseFee).Int64())
+	assert.Equal(int64(9000), NextBaseFee(baseFee, 200e6, blockGasLimit, minBaseFee).Int64())
+	assert.Equal(int64(8500), NextBaseFee(baseFee, 150e6, blockGasLimit, minBaseFee).Int64())
+	assert.Equal(int64(7000), NextBaseFee(baseFee, 0, blockGasLimit, minBaseFee).Int64())
+
+	// Never below the minimum, and a tiny base fee still goes up
+	assert.Equal(int64(4000), NextBaseFee(big.NewInt(4100), 0, blockGasLimit, minBaseFee).Int64())
+	assert.Equal(int64(2), NextBaseFee(big.NewInt(1), 200e6, blockGasLimit, big.NewInt(0)).Int64())
+}
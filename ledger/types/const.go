@@ -3,7 +3,7 @@ package types
 import (
 	"math/big"
 
-	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
 )
 
 const (
@@ -92,24 +92,30 @@ const (
 	ReservedFundFreezePeriodDuration uint64 = 5
 )
 
-func GetMinimumGasPrice(blockHeight uint64) *big.Int {
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+func GetMinimumGasPrice(chainID string, blockHeight uint64) *big.Int {
+	if gasPrice, ok := core.GetChainParam(chainID, core.ChainParamMinGasPrice); ok {
+		return gasPrice
+	}
+	if !core.IsForkActive(chainID, core.ForkJune2021FeeAdjustment, blockHeight) {
 		return new(big.Int).SetUint64(MinimumGasPrice)
 	}
 
 	return new(big.Int).SetUint64(MinimumGasPriceJune2021)
 }
 
-func GetMaxGasLimit(blockHeight uint64) *big.Int {
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+func GetMaxGasLimit(chainID string, blockHeight uint64) *big.Int {
+	if !core.IsForkActive(chainID, core.ForkJune2021FeeAdjustment, blockHeight) {
 		return new(big.Int).SetUint64(MaximumTxGasLimit)
 	}
 
 	return new(big.Int).SetUint64(MaximumTxGasLimitJune2021)
 }
 
-func GetMinimumTransactionFeeTFuelWei(blockHeight uint64) *big.Int {
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+func GetMinimumTransactionFeeTFuelWei(chainID string, blockHeight uint64) *big.Int {
+	if fee, ok := core.GetChainParam(chainID, core.ChainParamMinTransactionFee); ok {
+		return fee
+	}
+	if !core.IsForkActive(chainID, core.ForkJune2021FeeAdjustment, blockHeight) {
 		return new(big.Int).SetUint64(MinimumTransactionFeeTFuelWei)
 	}
 
@@ -117,17 +123,18 @@ func GetMinimumTransactionFeeTFuelWei(blockHeight uint64) *big.Int {
 }
 
 // Special handling for many-to-many SendTx
-func GetSendTxMinimumTransactionFeeTFuelWei(numAccountsAffected uint64, blockHeight uint64) *big.Int {
-	if blockHeight < common.HeightJune2021FeeAdjustment {
-		return new(big.Int).SetUint64(MinimumTransactionFeeTFuelWei) // backward compatiblity
+func GetSendTxMinimumTransactionFeeTFuelWei(numAccountsAffected uint64, chainID string, blockHeight uint64) *big.Int {
+	minFee := GetMinimumTransactionFeeTFuelWei(chainID, blockHeight)
+	if !core.IsForkActive(chainID, core.ForkJune2021FeeAdjustment, blockHeight) {
+		return minFee // backward compatiblity
 	}
 
 	if numAccountsAffected < 2 {
 		numAccountsAffected = 2
 	}
 
-	// minSendTxFee = numAccountsAffected * MinimumTransactionFeeTFuelWeiJune2021 / 2
-	minSendTxFee := big.NewInt(1).Mul(new(big.Int).SetUint64(numAccountsAffected), new(big.Int).SetUint64(MinimumTransactionFeeTFuelWeiJune2021))
+	// minSendTxFee = numAccountsAffected * minFee / 2
+	minSendTxFee := big.NewInt(1).Mul(new(big.Int).SetUint64(numAccountsAffected), minFee)
 	minSendTxFee = big.NewInt(1).Div(minSendTxFee, new(big.Int).SetUint64(2))
 
 	return minSendTxFee
@@ -2,13 +2,27 @@ package types
 
 import (
 	"bytes"
-	"fmt"
 
 	"github.com/pkg/errors"
 	"github.com/thetatoken/theta/rlp"
 )
 
-const maxTxSize = 1024 * 1024
+// MaxTxSize is the max size of an encoded transaction
+const MaxTxSize = 1024 * 1024
+
+// TxDecodeError is returned when the raw bytes cannot be decoded into a tx
+type TxDecodeError string
+
+func (e TxDecodeError) Error() string {
+	return string(e)
+}
+
+const (
+	ErrEmptyTx       = TxDecodeError("Empty tx bytes")
+	ErrTxTooLarge    = TxDecodeError("Tx bytes exceed the max tx size")
+	ErrUnknownTxType = TxDecodeError("Unknown TX type")
+	ErrMalformedTx   = TxDecodeError("Malformed tx bytes")
+)
 
 // ----------------- Common -------------------
 
@@ -37,6 +51,15 @@ const (
 	TxWithdrawStake
 	TxDepositStakeV2
 	TxStakeRewardDistribution
+	TxParameterChange
+	TxSubchainCheckpoint
+	TxCrossChainSend
+	TxCrossChainDeliver
+	TxValidatorKeyChange
+	TxStakeAutoCompounding
+	TxScheduled
+	TxSubchainRegistration
+	TxSmartContractV2
 )
 
 func Fuzz(data []byte) int {
@@ -58,10 +81,29 @@ func Fuzz(data []byte) int {
 	return -1
 }
 
+// TxFromBytes decodes the raw bytes into a tx. The returned error, if any, has a TxDecodeError
+// as its cause.
 func TxFromBytes(raw []byte) (Tx, error) {
+	if len(raw) == 0 {
+		return nil, ErrEmptyTx
+	}
+	if len(raw) > MaxTxSize {
+		return nil, errors.Wrapf(ErrTxTooLarge, "%v bytes", len(raw))
+	}
+	tx, err := decodeTx(raw)
+	if err != nil {
+		if _, ok := errors.Cause(err).(TxDecodeError); ok {
+			return nil, err
+		}
+		return nil, errors.Wrapf(ErrMalformedTx, "%v", err)
+	}
+	return tx, nil
+}
+
+func decodeTx(raw []byte) (Tx, error) {
 	var txType TxType
 	buff := bytes.NewBuffer(raw)
-	s := rlp.NewStream(buff, maxTxSize)
+	s := rlp.NewStream(buff, MaxTxSize)
 	err := s.Decode(&txType)
 	if err != nil {
 		return nil, err
@@ -114,8 +156,44 @@ func TxFromBytes(raw []byte) (Tx, error) {
 		data := &StakeRewardDistributionTx{}
 		err = s.Decode(data)
 		return data, err
+	} else if txType == TxParameterChange {
+		data := &ParameterChangeTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxSubchainCheckpoint {
+		data := &SubchainCheckpointTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxSubchainRegistration {
+		data := &SubchainRegistrationTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxCrossChainSend {
+		data := &CrossChainSendTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxCrossChainDeliver {
+		data := &CrossChainDeliverTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxValidatorKeyChange {
+		data := &ValidatorKeyChangeTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxStakeAutoCompounding {
+		data := &StakeAutoCompoundingTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxScheduled {
+		data := &ScheduledTx{}
+		err = s.Decode(data)
+		return data, err
+	} else if txType == TxSmartContractV2 {
+		data := &SmartContractTxV2{}
+		err = s.Decode(data)
+		return data, err
 	} else {
-		return nil, fmt.Errorf("Unknown TX type: %v", txType)
+		return nil, errors.Wrapf(ErrUnknownTxType, "%v", txType)
 	}
 }
 
@@ -147,6 +225,24 @@ func TxToBytes(t Tx) ([]byte, error) {
 		txType = TxDepositStakeV2
 	case *StakeRewardDistributionTx:
 		txType = TxStakeRewardDistribution
+	case *ParameterChangeTx:
+		txType = TxParameterChange
+	case *SubchainCheckpointTx:
+		txType = TxSubchainCheckpoint
+	case *CrossChainSendTx:
+		txType = TxCrossChainSend
+	case *CrossChainDeliverTx:
+		txType = TxCrossChainDeliver
+	case *ValidatorKeyChangeTx:
+		txType = TxValidatorKeyChange
+	case *StakeAutoCompoundingTx:
+		txType = TxStakeAutoCompounding
+	case *ScheduledTx:
+		txType = TxScheduled
+	case *SubchainRegistrationTx:
+		txType = TxSubchainRegistration
+	case *SmartContractTxV2:
+		txType = TxSmartContractV2
 	default:
 		return nil, errors.New("Unsupported message type")
 	}
@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/thetatoken/theta/crypto/bls"
 
 	"github.com/stretchr/testify/assert"
@@ -212,6 +213,26 @@ func TestTx(t *testing.T) {
 	assert.False(tmp2.BlsPubkey.IsEmpty())
 }
 
+func TestTxDecodeErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := TxFromBytes(nil)
+	assert.Equal(ErrEmptyTx, errors.Cause(err))
+
+	_, err = TxFromBytes(make([]byte, MaxTxSize+1))
+	assert.Equal(ErrTxTooLarge, errors.Cause(err))
+
+	raw, err := rlp.EncodeToBytes(TxType(1000))
+	assert.Nil(err)
+	_, err = TxFromBytes(raw)
+	assert.Equal(ErrUnknownTxType, errors.Cause(err))
+
+	raw, err = rlp.EncodeToBytes(TxSend)
+	assert.Nil(err)
+	_, err = TxFromBytes(append(raw, 0xff))
+	assert.Equal(ErrMalformedTx, errors.Cause(err))
+}
+
 func TestFuzz(t *testing.T) {
 	var input []byte
 
@@ -30,6 +30,15 @@ Transaction Types:
  - WithdrawStakeTx         Withdraw stake from a target address (e.g. a validator)
  - SmartContractTx         Execute smart contract
  - StakeRewardDistribution Defines how stake reward is distributed
+ - ParameterChangeTx       Validator vote for changing a governed parameter
+ - SubchainCheckpointTx    Anchor a finalized subchain block to the main chain
+ - CrossChainSendTx        Send a message to another chain
+ - CrossChainDeliverTx     Deliver a message sent by another chain with its proof
+ - ValidatorKeyChangeTx    Change the signing key of a validator without unstaking
+ - StakeAutoCompoundingTx  Opt in or out of compounding the staking rewards
+ - ScheduledTx             Execute a transaction no earlier than the given block height
+ - SubchainRegistrationTx  Validator vote for registering a subchain with its operator
+ - SmartContractTxV2       Execute smart contract, paying the gas at the dynamic base fee plus a priority fee
 */
 
 // Gas of regular transactions
@@ -195,6 +204,44 @@ func (txOut TxOutput) String() string {
 
 //-----------------------------------------------------------------------------
 
+// FeePayer pays the TFuel fee of a transaction on behalf of its sender. It co-signs the same sign bytes as the
+// sender, which cover the fee payer address, and its account sequence is left unchanged since the sequence of
+// the sender already protects the transaction from replays.
+type FeePayer struct {
+	Address   common.Address    `json:"address"`
+	Signature *crypto.Signature `json:"signature"`
+}
+
+func (fp FeePayer) String() string {
+	return fmt.Sprintf("FeePayer{%v}", fp.Address.Hex())
+}
+
+// getFeePayer returns the fee payer of the transaction, or nil if the sender pays the fee
+func getFeePayer(feePayers []FeePayer) *FeePayer {
+	if len(feePayers) == 0 {
+		return nil
+	}
+	return &feePayers[0]
+}
+
+// clearFeePayerSignatures clears the fee payer signatures for computing the sign bytes, and returns them
+func clearFeePayerSignatures(feePayers []FeePayer) []*crypto.Signature {
+	sigz := make([]*crypto.Signature, len(feePayers))
+	for i := range feePayers {
+		sigz[i] = feePayers[i].Signature
+		feePayers[i].Signature = nil
+	}
+	return sigz
+}
+
+func restoreFeePayerSignatures(feePayers []FeePayer, sigz []*crypto.Signature) {
+	for i := range feePayers {
+		feePayers[i].Signature = sigz[i]
+	}
+}
+
+//-----------------------------------------------------------------------------
+
 type CoinbaseTx struct {
 	Proposer    TxInput
 	Outputs     []TxOutput
@@ -340,9 +387,10 @@ func (tx *SlashTx) String() string {
 //-----------------------------------------------------------------------------
 
 type SendTx struct {
-	Fee     Coins      `json:"fee"` // Fee
-	Inputs  []TxInput  `json:"inputs"`
-	Outputs []TxOutput `json:"outputs"`
+	Fee       Coins      `json:"fee"` // Fee
+	Inputs    []TxInput  `json:"inputs"`
+	Outputs   []TxOutput `json:"outputs"`
+	FeePayers []FeePayer `json:"fee_payers,omitempty" rlp:"tail"` // at most one, pays the fee instead of the inputs
 }
 
 func (_ *SendTx) AssertIsTx() {}
@@ -354,6 +402,7 @@ func (tx *SendTx) SignBytes(chainID string) []byte {
 		sigz[i] = tx.Inputs[i].Signature
 		tx.Inputs[i].Signature = nil
 	}
+	feePayerSigz := clearFeePayerSignatures(tx.FeePayers)
 	txBytes, _ := TxToBytes(tx)
 	signBytes = append(signBytes, txBytes...)
 	signBytes = addPrefixForSignBytes(signBytes)
@@ -361,6 +410,7 @@ func (tx *SendTx) SignBytes(chainID string) []byte {
 	for i := range tx.Inputs {
 		tx.Inputs[i].Signature = sigz[i]
 	}
+	restoreFeePayerSignatures(tx.FeePayers, feePayerSigz)
 	return signBytes
 }
 
@@ -371,9 +421,20 @@ func (tx *SendTx) SetSignature(addr common.Address, sig *crypto.Signature) bool
 			return true
 		}
 	}
+	for i, feePayer := range tx.FeePayers {
+		if feePayer.Address == addr {
+			tx.FeePayers[i].Signature = sig
+			return true
+		}
+	}
 	return false
 }
 
+// GetFeePayer returns the fee payer of the transaction, or nil if the inputs pay the fee
+func (tx *SendTx) GetFeePayer() *FeePayer {
+	return getFeePayer(tx.FeePayers)
+}
+
 func (tx *SendTx) String() string {
 	return fmt.Sprintf("SendTx{fee: %v, %v->%v}", tx.Fee, tx.Inputs, tx.Outputs)
 }
@@ -728,38 +789,42 @@ func (tx *SplitRuleTx) String() string {
 //-----------------------------------------------------------------------------
 
 type SmartContractTx struct {
-	From     TxInput
-	To       TxOutput
-	GasLimit uint64
-	GasPrice *big.Int
-	Data     common.Bytes
+	From      TxInput
+	To        TxOutput
+	GasLimit  uint64
+	GasPrice  *big.Int
+	Data      common.Bytes
+	FeePayers []FeePayer `rlp:"tail"` // at most one, pays the gas instead of the sender
 }
 
 type SmartContractTxJSON struct {
-	From     TxInput           `json:"from"`
-	To       TxOutput          `json:"to"`
-	GasLimit common.JSONUint64 `json:"gas_limit"`
-	GasPrice *common.JSONBig   `json:"gas_price"`
-	Data     common.Bytes      `json:"data"`
+	From      TxInput           `json:"from"`
+	To        TxOutput          `json:"to"`
+	GasLimit  common.JSONUint64 `json:"gas_limit"`
+	GasPrice  *common.JSONBig   `json:"gas_price"`
+	Data      common.Bytes      `json:"data"`
+	FeePayers []FeePayer        `json:"fee_payers,omitempty"`
 }
 
 func NewSmartContractTxJSON(a SmartContractTx) SmartContractTxJSON {
 	return SmartContractTxJSON{
-		From:     a.From,
-		To:       a.To,
-		GasLimit: common.JSONUint64(a.GasLimit),
-		GasPrice: (*common.JSONBig)(a.GasPrice),
-		Data:     a.Data,
+		From:      a.From,
+		To:        a.To,
+		GasLimit:  common.JSONUint64(a.GasLimit),
+		GasPrice:  (*common.JSONBig)(a.GasPrice),
+		Data:      a.Data,
+		FeePayers: a.FeePayers,
 	}
 }
 
 func (a SmartContractTxJSON) SmartContractTx() SmartContractTx {
 	return SmartContractTx{
-		From:     a.From,
-		To:       a.To,
-		GasLimit: uint64(a.GasLimit),
-		GasPrice: (*big.Int)(a.GasPrice),
-		Data:     a.Data,
+		From:      a.From,
+		To:        a.To,
+		GasLimit:  uint64(a.GasLimit),
+		GasPrice:  (*big.Int)(a.GasPrice),
+		Data:      a.Data,
+		FeePayers: a.FeePayers,
 	}
 }
 
@@ -782,11 +847,13 @@ func (tx *SmartContractTx) SignBytes(chainID string) []byte {
 	signBytes := encodeToBytes(chainID)
 	sig := tx.From.Signature
 	tx.From.Signature = nil
+	feePayerSigz := clearFeePayerSignatures(tx.FeePayers)
 	txBytes, _ := TxToBytes(tx)
 	signBytes = append(signBytes, txBytes...)
 	signBytes = addPrefixForSignBytes(signBytes)
 
 	tx.From.Signature = sig
+	restoreFeePayerSignatures(tx.FeePayers, feePayerSigz)
 	return signBytes
 }
 
@@ -795,9 +862,20 @@ func (tx *SmartContractTx) SetSignature(addr common.Address, sig *crypto.Signatu
 		tx.From.Signature = sig
 		return true
 	}
+	for i, feePayer := range tx.FeePayers {
+		if feePayer.Address == addr {
+			tx.FeePayers[i].Signature = sig
+			return true
+		}
+	}
 	return false
 }
 
+// GetFeePayer returns the fee payer of the transaction, or nil if the sender pays the gas
+func (tx *SmartContractTx) GetFeePayer() *FeePayer {
+	return getFeePayer(tx.FeePayers)
+}
+
 func (tx *SmartContractTx) String() string {
 	return fmt.Sprintf("SmartContractTx{%v -> %v, value: %v, gas_limit: %v, gas_price: %v, data: %v}",
 		tx.From.Address.Hex(), tx.To.Address.Hex(), tx.From.Coins.TFuelWei, tx.GasLimit, tx.GasPrice, tx.Data)
@@ -805,6 +883,132 @@ func (tx *SmartContractTx) String() string {
 
 //-----------------------------------------------------------------------------
 
+// SmartContractTxV2 is a smart contract transaction priced against the dynamic base fee. The gas is charged at
+// the base fee plus the priority fee, capped by the max fee.
+type SmartContractTxV2 struct {
+	From                 TxInput
+	To                   TxOutput
+	GasLimit             uint64
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	Data                 common.Bytes
+	FeePayers            []FeePayer `rlp:"tail"` // at most one, pays the gas instead of the sender
+}
+
+type SmartContractTxV2JSON struct {
+	From                 TxInput           `json:"from"`
+	To                   TxOutput          `json:"to"`
+	GasLimit             common.JSONUint64 `json:"gas_limit"`
+	MaxFeePerGas         *common.JSONBig   `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas *common.JSONBig   `json:"max_priority_fee_per_gas"`
+	Data                 common.Bytes      `json:"data"`
+	FeePayers            []FeePayer        `json:"fee_payers,omitempty"`
+}
+
+func NewSmartContractTxV2JSON(a SmartContractTxV2) SmartContractTxV2JSON {
+	return SmartContractTxV2JSON{
+		From:                 a.From,
+		To:                   a.To,
+		GasLimit:             common.JSONUint64(a.GasLimit),
+		MaxFeePerGas:         (*common.JSONBig)(a.MaxFeePerGas),
+		MaxPriorityFeePerGas: (*common.JSONBig)(a.MaxPriorityFeePerGas),
+		Data:                 a.Data,
+		FeePayers:            a.FeePayers,
+	}
+}
+
+func (a SmartContractTxV2JSON) SmartContractTxV2() SmartContractTxV2 {
+	return SmartContractTxV2{
+		From:                 a.From,
+		To:                   a.To,
+		GasLimit:             uint64(a.GasLimit),
+		MaxFeePerGas:         (*big.Int)(a.MaxFeePerGas),
+		MaxPriorityFeePerGas: (*big.Int)(a.MaxPriorityFeePerGas),
+		Data:                 a.Data,
+		FeePayers:            a.FeePayers,
+	}
+}
+
+func (a SmartContractTxV2) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewSmartContractTxV2JSON(a))
+}
+
+func (a *SmartContractTxV2) UnmarshalJSON(data []byte) error {
+	var b SmartContractTxV2JSON
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	*a = b.SmartContractTxV2()
+	return nil
+}
+
+func (_ *SmartContractTxV2) AssertIsTx() {}
+
+func (tx *SmartContractTxV2) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.From.Signature
+	tx.From.Signature = nil
+	feePayerSigz := clearFeePayerSignatures(tx.FeePayers)
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.From.Signature = sig
+	restoreFeePayerSignatures(tx.FeePayers, feePayerSigz)
+	return signBytes
+}
+
+func (tx *SmartContractTxV2) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.From.Address == addr {
+		tx.From.Signature = sig
+		return true
+	}
+	for i, feePayer := range tx.FeePayers {
+		if feePayer.Address == addr {
+			tx.FeePayers[i].Signature = sig
+			return true
+		}
+	}
+	return false
+}
+
+// GetFeePayer returns the fee payer of the transaction, or nil if the sender pays the gas
+func (tx *SmartContractTxV2) GetFeePayer() *FeePayer {
+	return getFeePayer(tx.FeePayers)
+}
+
+// EffectiveGasPrice returns the gas price the transaction pays under the given base fee, i.e. the base fee
+// plus the priority fee, capped by the max fee
+func (tx *SmartContractTxV2) EffectiveGasPrice(baseFee *big.Int) *big.Int {
+	gasPrice := new(big.Int).Add(baseFee, tx.MaxPriorityFeePerGas)
+	if gasPrice.Cmp(tx.MaxFeePerGas) > 0 {
+		gasPrice.Set(tx.MaxFeePerGas)
+	}
+	return gasPrice
+}
+
+// ToSmartContractTx returns the equivalent SmartContractTx charged at the given gas price, which is what the
+// EVM executes. The returned transaction shares the signatures with the original one, but its sign bytes
+// differ, so it is not a valid transaction by itself.
+func (tx *SmartContractTxV2) ToSmartContractTx(gasPrice *big.Int) *SmartContractTx {
+	return &SmartContractTx{
+		From:      tx.From,
+		To:        tx.To,
+		GasLimit:  tx.GasLimit,
+		GasPrice:  gasPrice,
+		Data:      tx.Data,
+		FeePayers: tx.FeePayers,
+	}
+}
+
+func (tx *SmartContractTxV2) String() string {
+	return fmt.Sprintf("SmartContractTxV2{%v -> %v, value: %v, gas_limit: %v, max_fee_per_gas: %v, max_priority_fee_per_gas: %v, data: %v}",
+		tx.From.Address.Hex(), tx.To.Address.Hex(), tx.From.Coins.TFuelWei, tx.GasLimit, tx.MaxFeePerGas,
+		tx.MaxPriorityFeePerGas, tx.Data)
+}
+
+//-----------------------------------------------------------------------------
+
 type DepositStakeTx struct {
 	Fee     Coins    `json:"fee"`     // Fee
 	Source  TxInput  `json:"source"`  // source staker account
@@ -975,6 +1179,318 @@ func (tx *StakeRewardDistributionTx) String() string {
 		tx.Holder.Address, tx.Beneficiary.Address, tx.SplitBasisPoint)
 }
 
+// --------------- ParameterChangeTx --------------- //
+
+// ParameterChangeTx is a vote of a validator for changing a governed parameter, e.g. the block gas limit.
+// The change takes effect after the validators with a 2/3 majority of the stake have voted for it.
+type ParameterChangeTx struct {
+	Fee       Coins   `json:"fee"`       // transction fee
+	Proposer  TxInput `json:"proposer"`  // the validator voting for the change
+	Parameter string  `json:"parameter"` // name of the governed parameter, e.g. block_gas_limit
+	Value     uint64  `json:"value"`     // new value of the parameter
+}
+
+func (_ *ParameterChangeTx) AssertIsTx() {}
+
+func (tx *ParameterChangeTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Proposer.Signature
+	tx.Proposer.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Proposer.Signature = sig
+	return signBytes
+}
+
+func (tx *ParameterChangeTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Proposer.Address == addr {
+		tx.Proposer.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *ParameterChangeTx) String() string {
+	return fmt.Sprintf("ParameterChangeTx{proposer: %v, parameter: %v, value: %v}",
+		tx.Proposer.Address, tx.Parameter, tx.Value)
+}
+
+// --------------- SubchainCheckpointTx --------------- //
+
+// SubchainCheckpointTx anchors a finalized block of a subchain to the main chain. The subchain needs to
+// be registered by a SubchainRegistrationTx, and its checkpoints need to be submitted by its operator
+// with strictly increasing heights.
+type SubchainCheckpointTx struct {
+	Fee        Coins       `json:"fee"`         // transction fee
+	Submitter  TxInput     `json:"submitter"`   // the operator of the subchain
+	SubchainID string      `json:"subchain_id"` // chain ID of the subchain
+	Height     uint64      `json:"height"`      // height of the finalized subchain block
+	BlockHash  common.Hash `json:"block_hash"`  // hash of the finalized subchain block
+	StateHash  common.Hash `json:"state_hash"`  // state root of the finalized subchain block
+}
+
+func (_ *SubchainCheckpointTx) AssertIsTx() {}
+
+func (tx *SubchainCheckpointTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Submitter.Signature
+	tx.Submitter.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Submitter.Signature = sig
+	return signBytes
+}
+
+func (tx *SubchainCheckpointTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Submitter.Address == addr {
+		tx.Submitter.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *SubchainCheckpointTx) String() string {
+	return fmt.Sprintf("SubchainCheckpointTx{submitter: %v, subchain_id: %v, height: %v, block_hash: %v, state_hash: %v}",
+		tx.Submitter.Address, tx.SubchainID, tx.Height, tx.BlockHash.Hex(), tx.StateHash.Hex())
+}
+
+// --------------- SubchainRegistrationTx --------------- //
+
+// SubchainRegistrationTx is a vote of a validator for registering a subchain with the given operator, or
+// for replacing the operator of a registered subchain. The registration takes effect after the validators
+// with a 2/3 majority of the stake have voted for it.
+type SubchainRegistrationTx struct {
+	Fee        Coins          `json:"fee"`         // transction fee
+	Proposer   TxInput        `json:"proposer"`    // the validator voting for the registration
+	SubchainID string         `json:"subchain_id"` // chain ID of the subchain
+	Operator   common.Address `json:"operator"`    // the account allowed to submit the checkpoints
+}
+
+func (_ *SubchainRegistrationTx) AssertIsTx() {}
+
+func (tx *SubchainRegistrationTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Proposer.Signature
+	tx.Proposer.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Proposer.Signature = sig
+	return signBytes
+}
+
+func (tx *SubchainRegistrationTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Proposer.Address == addr {
+		tx.Proposer.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *SubchainRegistrationTx) String() string {
+	return fmt.Sprintf("SubchainRegistrationTx{proposer: %v, subchain_id: %v, operator: %v}",
+		tx.Proposer.Address, tx.SubchainID, tx.Operator)
+}
+
+// --------------- CrossChainSendTx --------------- //
+
+// CrossChainSendTx appends a message to the send queue of the chain, from which relayers deliver it to
+// the target chain.
+type CrossChainSendTx struct {
+	Fee           Coins          `json:"fee"`             // transction fee
+	Sender        TxInput        `json:"sender"`          // the sender of the message
+	TargetChainID string         `json:"target_chain_id"` // chain ID of the target chain
+	Receiver      common.Address `json:"receiver"`        // the receiver of the message on the target chain
+	Data          common.Bytes   `json:"data"`            // payload of the message
+}
+
+func (_ *CrossChainSendTx) AssertIsTx() {}
+
+func (tx *CrossChainSendTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Sender.Signature
+	tx.Sender.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Sender.Signature = sig
+	return signBytes
+}
+
+func (tx *CrossChainSendTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Sender.Address == addr {
+		tx.Sender.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *CrossChainSendTx) String() string {
+	return fmt.Sprintf("CrossChainSendTx{sender: %v, target_chain_id: %v, receiver: %v, data: %v}",
+		tx.Sender.Address, tx.TargetChainID, tx.Receiver, hex.EncodeToString(tx.Data))
+}
+
+// --------------- CrossChainDeliverTx --------------- //
+
+// CrossChainDeliverTx delivers a message sent by the source chain. The message is proven against the state
+// root of a source chain checkpoint anchored to this chain, and needs to be delivered in the nonce order.
+type CrossChainDeliverTx struct {
+	Fee              Coins                  `json:"fee"`               // transction fee
+	Relayer          TxInput                `json:"relayer"`           // the relayer delivering the message
+	Message          core.CrossChainMessage `json:"message"`           // the delivered message
+	CheckpointHeight uint64                 `json:"checkpoint_height"` // height of the anchored source chain checkpoint
+	Proof            []common.Bytes         `json:"proof"`             // trie nodes proving the message against the checkpoint state root
+}
+
+func (_ *CrossChainDeliverTx) AssertIsTx() {}
+
+func (tx *CrossChainDeliverTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Relayer.Signature
+	tx.Relayer.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Relayer.Signature = sig
+	return signBytes
+}
+
+func (tx *CrossChainDeliverTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Relayer.Address == addr {
+		tx.Relayer.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *CrossChainDeliverTx) String() string {
+	return fmt.Sprintf("CrossChainDeliverTx{relayer: %v, message: %v, checkpoint_height: %v}",
+		tx.Relayer.Address, tx.Message.String(), tx.CheckpointHeight)
+}
+
+// --------------- ValidatorKeyChangeTx --------------- //
+
+// ValidatorKeyChangeTx moves the stakes of a validator to a new holder address, i.e. a new signing key,
+// and optionally changes the address the stakes are returned to. It needs to be signed by the source of
+// all the stakes of the validator, and by the new holder to prove the possession of the new key. The change
+// takes effect after core.ValidatorKeyChangeDelay blocks.
+type ValidatorKeyChangeTx struct {
+	Fee                Coins             `json:"fee"`                  // transction fee
+	Source             TxInput           `json:"source"`               // the source of the stakes
+	Holder             common.Address    `json:"holder"`               // the current holder address of the validator
+	NewHolder          common.Address    `json:"new_holder"`           // the new holder address of the validator
+	NewHolderSignature *crypto.Signature `json:"new_holder_signature"` // signature of the new holder
+	NewSource          common.Address    `json:"new_source"`           // the new address the stakes are returned to, empty if unchanged
+}
+
+func (_ *ValidatorKeyChangeTx) AssertIsTx() {}
+
+func (tx *ValidatorKeyChangeTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig, newHolderSig := tx.Source.Signature, tx.NewHolderSignature
+	tx.Source.Signature, tx.NewHolderSignature = nil, nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Source.Signature, tx.NewHolderSignature = sig, newHolderSig
+	return signBytes
+}
+
+func (tx *ValidatorKeyChangeTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Source.Address == addr {
+		tx.Source.Signature = sig
+		return true
+	}
+	if tx.NewHolder == addr {
+		tx.NewHolderSignature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *ValidatorKeyChangeTx) String() string {
+	return fmt.Sprintf("ValidatorKeyChangeTx{source: %v, holder: %v, new_holder: %v, new_source: %v}",
+		tx.Source.Address, tx.Holder, tx.NewHolder, tx.NewSource)
+}
+
+// --------------- StakeAutoCompoundingTx --------------- //
+
+// StakeAutoCompoundingTx opts the source in or out of compounding its TFuel staking rewards into its stake
+// to an elite edge node at distribution time.
+type StakeAutoCompoundingTx struct {
+	Fee     Coins          `json:"fee"`     // transction fee
+	Source  TxInput        `json:"source"`  // the stake source receiving the rewards
+	Holder  common.Address `json:"holder"`  // the elite edge node the rewards are staked to
+	Enabled bool           `json:"enabled"` // false to opt out
+}
+
+func (_ *StakeAutoCompoundingTx) AssertIsTx() {}
+
+func (tx *StakeAutoCompoundingTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	sig := tx.Source.Signature
+	tx.Source.Signature = nil
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+
+	tx.Source.Signature = sig
+	return signBytes
+}
+
+func (tx *StakeAutoCompoundingTx) SetSignature(addr common.Address, sig *crypto.Signature) bool {
+	if tx.Source.Address == addr {
+		tx.Source.Signature = sig
+		return true
+	}
+	return false
+}
+
+func (tx *StakeAutoCompoundingTx) String() string {
+	return fmt.Sprintf("StakeAutoCompoundingTx{source: %v, holder: %v, enabled: %v}",
+		tx.Source.Address, tx.Holder, tx.Enabled)
+}
+
+// --------------- ScheduledTx --------------- //
+
+// MaxScheduledTxLead is the max number of blocks a transaction can be scheduled ahead, about a week
+const MaxScheduledTxLead uint64 = 100800
+
+// ScheduledTx wraps a transaction which can only be included in the blocks at or above the NotBefore height.
+// The wrapped transaction is signed for the chain ID returned by ScheduledTxChainID, so that it cannot be
+// unwrapped and included earlier, nor rescheduled by anyone other than its signers.
+type ScheduledTx struct {
+	NotBefore uint64       `json:"not_before"` // the earliest height of the block including the transaction
+	Tx        common.Bytes `json:"tx"`         // the wrapped transaction
+}
+
+func (_ *ScheduledTx) AssertIsTx() {}
+
+func (tx *ScheduledTx) SignBytes(chainID string) []byte {
+	signBytes := encodeToBytes(chainID)
+	txBytes, _ := TxToBytes(tx)
+	signBytes = append(signBytes, txBytes...)
+	signBytes = addPrefixForSignBytes(signBytes)
+	return signBytes
+}
+
+func (tx *ScheduledTx) String() string {
+	return fmt.Sprintf("ScheduledTx{not_before: %v, tx: %v}", tx.NotBefore, hex.EncodeToString(tx.Tx))
+}
+
+// ScheduledTxChainID returns the chain ID the transaction scheduled at the given height is signed for
+func ScheduledTxChainID(chainID string, notBefore uint64) string {
+	return fmt.Sprintf("%v/scheduled/%v", chainID, notBefore)
+}
+
 // --------------- Utils --------------- //
 
 type EthereumTxWrapper struct {
@@ -388,6 +388,54 @@ func TestSendTxProto(t *testing.T) {
 	assert.False(tx2.Inputs[0].Signature.IsEmpty())
 }
 
+func TestSendTxFeePayerProto(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	chainID := "test_chain_id"
+	test1PrivAcc := PrivAccountFromSecret("sendtx1")
+	test2PrivAcc := PrivAccountFromSecret("sendtx2")
+	payerPrivAcc := PrivAccountFromSecret("feepayer")
+
+	tx := &SendTx{
+		Fee: Coins{ThetaWei: big.NewInt(0), TFuelWei: big.NewInt(2)},
+		Inputs: []TxInput{
+			NewTxInput(test1PrivAcc.Address, Coins{ThetaWei: big.NewInt(0), TFuelWei: big.NewInt(8)}, 1),
+		},
+		Outputs: []TxOutput{
+			TxOutput{
+				Address: test2PrivAcc.Address,
+				Coins:   Coins{ThetaWei: big.NewInt(0), TFuelWei: big.NewInt(8)},
+			},
+		},
+		FeePayers: []FeePayer{{Address: payerPrivAcc.Address}},
+	}
+
+	// The sender and the fee payer sign the same bytes
+	signBytes := tx.SignBytes(chainID)
+	assert.True(tx.SetSignature(test1PrivAcc.Address, test1PrivAcc.Sign(signBytes)))
+	assert.True(tx.SetSignature(payerPrivAcc.Address, payerPrivAcc.Sign(signBytes)))
+	assert.Equal(signBytes, tx.SignBytes(chainID))
+
+	b, err := TxToBytes(tx)
+	require.Nil(err)
+	txs, err := TxFromBytes(b)
+	require.Nil(err)
+	tx2 := txs.(*SendTx)
+
+	require.NotNil(tx2.GetFeePayer())
+	assert.Equal(payerPrivAcc.Address, tx2.GetFeePayer().Address)
+	assert.Equal(tx.FeePayers[0].Signature, tx2.FeePayers[0].Signature)
+	assert.True(payerPrivAcc.PrivKey.PublicKey().VerifySignature(tx2.SignBytes(chainID), tx2.FeePayers[0].Signature))
+
+	// A SendTx without a fee payer encodes as before
+	tx.FeePayers = nil
+	b, err = TxToBytes(tx)
+	require.Nil(err)
+	txs, err = TxFromBytes(b)
+	require.Nil(err)
+	assert.Nil(txs.(*SendTx).GetFeePayer())
+}
+
 func TestReserveFundTxSignable(t *testing.T) {
 	reserveFundTx := &ReserveFundTx{
 		Fee: Coins{ThetaWei: Zero, TFuelWei: big.NewInt(111)},
@@ -787,3 +835,43 @@ func TestSmartContractTxJSON(t *testing.T) {
 	assert.Equal(uint64(math.MaxUint64), d.GasLimit)
 	assert.Equal(0, gasPrice.Cmp(d.GasPrice))
 }
+
+func TestSmartContractTxV2(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID := "test_chain_id"
+	fromPrivAcc := PrivAccountFromSecret("sctx1")
+	tx := &SmartContractTxV2{
+		From:                 NewTxInput(fromPrivAcc.Address, NewCoins(0, 0), 1),
+		To:                   TxOutput{Address: getTestAddress("contract")},
+		GasLimit:             100000,
+		MaxFeePerGas:         big.NewInt(300),
+		MaxPriorityFeePerGas: big.NewInt(20),
+		Data:                 common.Bytes{0x1, 0x2},
+	}
+	assert.True(tx.SetSignature(fromPrivAcc.Address, fromPrivAcc.Sign(tx.SignBytes(chainID))))
+
+	b, err := TxToBytes(tx)
+	require.Nil(err)
+	decoded, err := TxFromBytes(b)
+	require.Nil(err)
+	tx2 := decoded.(*SmartContractTxV2)
+	assert.Equal(0, tx.MaxFeePerGas.Cmp(tx2.MaxFeePerGas))
+	assert.Equal(0, tx.MaxPriorityFeePerGas.Cmp(tx2.MaxPriorityFeePerGas))
+	assert.True(fromPrivAcc.PrivKey.PublicKey().VerifySignature(tx2.SignBytes(chainID), tx2.From.Signature))
+
+	// The gas price is the base fee plus the priority fee, capped by the max fee
+	assert.Equal(int64(120), tx.EffectiveGasPrice(big.NewInt(100)).Int64())
+	assert.Equal(int64(300), tx.EffectiveGasPrice(big.NewInt(290)).Int64())
+	sctx := tx.ToSmartContractTx(big.NewInt(120))
+	assert.Equal(tx.GasLimit, sctx.GasLimit)
+	assert.Equal(int64(120), sctx.GasPrice.Int64())
+
+	s, err := json.Marshal(tx)
+	require.Nil(err)
+	assert.Contains(string(s), `"max_priority_fee_per_gas":"20"`)
+	var d SmartContractTxV2
+	require.Nil(json.Unmarshal(s, &d))
+	assert.Equal(0, tx.MaxFeePerGas.Cmp(d.MaxFeePerGas))
+}
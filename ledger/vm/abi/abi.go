@@ -0,0 +1,322 @@
+// Package abi encodes and decodes the calls, and decodes the event logs of the smart contracts by their Solidity ABI.
+package abi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+)
+
+// Argument is an input or an output of a function or an event
+type Argument struct {
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Indexed    bool       `json:"indexed,omitempty"`
+	Components []Argument `json:"components,omitempty"`
+}
+
+// Entry is a function, an event, or the constructor or the fallback of a contract
+type Entry struct {
+	Type            string     `json:"type"`
+	Name            string     `json:"name"`
+	Inputs          []Argument `json:"inputs"`
+	Outputs         []Argument `json:"outputs,omitempty"`
+	StateMutability string     `json:"stateMutability,omitempty"`
+	Constant        bool       `json:"constant,omitempty"`
+	Anonymous       bool       `json:"anonymous,omitempty"`
+
+	types       []*Type
+	outputTypes []*Type
+	signature   string
+}
+
+func (e *Entry) init() error {
+	if (e.Type == "function" || e.Type == "event") && e.Name == "" {
+		return fmt.Errorf("Missing the name of a %v", e.Type)
+	}
+	var err error
+	if e.types, err = argumentTypes(e.Name, e.Inputs); err != nil {
+		return err
+	}
+	if e.outputTypes, err = argumentTypes(e.Name, e.Outputs); err != nil {
+		return err
+	}
+	strs := []string{}
+	for _, t := range e.types {
+		strs = append(strs, t.String())
+	}
+	e.signature = e.Name + "(" + strings.Join(strs, ",") + ")"
+	return nil
+}
+
+func argumentTypes(entry string, args []Argument) ([]*Type, error) {
+	types := []*Type{}
+	for _, arg := range args {
+		t, err := NewType(arg.Type, arg.Components)
+		if err != nil {
+			return nil, fmt.Errorf("%v of %v: %v", arg.Name, entry, err)
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// Signature returns the canonical signature, e.g. transfer(address,uint256)
+func (e *Entry) Signature() string {
+	return e.signature
+}
+
+// Selector returns the first 4 bytes of the hash of the signature, which select the function in the call data
+func (e *Entry) Selector() []byte {
+	return crypto.Keccak256([]byte(e.signature))[:4]
+}
+
+// IsReadOnly returns whether the function does not modify the state
+func (e *Entry) IsReadOnly() bool {
+	return e.Constant || e.StateMutability == "view" || e.StateMutability == "pure"
+}
+
+// EncodeInputs encodes the human readable arguments, without the selector. The numbers are decimal, or hex
+// with the 0x prefix, and the bytes are hex. The arrays and the tuples are JSON arrays, e.g. [1, ["0x01", true]].
+func (e *Entry) EncodeInputs(args []string) ([]byte, error) {
+	if len(args) != len(e.Inputs) {
+		return nil, fmt.Errorf("%v expects %v arguments, got %v", e.signature, len(e.Inputs), len(args))
+	}
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg
+	}
+	return encodeSequence(e.types, values)
+}
+
+// DecodeOutputs decodes the return data of the function
+func (e *Entry) DecodeOutputs(data []byte) ([]*DecodedArg, error) {
+	values, err := decodeSequence(e.outputTypes, data)
+	if err != nil {
+		return nil, err
+	}
+	decoded := []*DecodedArg{}
+	for i, value := range values {
+		decoded = append(decoded, &DecodedArg{Name: e.Outputs[i].Name, Type: e.outputTypes[i].String(), Value: value})
+	}
+	return decoded, nil
+}
+
+// ABI is the interface of a contract
+type ABI struct {
+	functions   []*Entry // in the order of the definitions, to look up the functions by name
+	constructor *Entry
+	methods     map[[4]byte]*Entry
+	events      map[common.Hash]*Entry
+}
+
+// Parse parses the JSON ABI of a contract. A compiler artifact with an abi field, or a single entry, e.g. the
+// fragment of an event, is accepted as well.
+func Parse(data []byte) (*ABI, error) {
+	entries := []*Entry{}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		artifact := struct {
+			ABI json.RawMessage `json:"abi"`
+		}{}
+		if err := json.Unmarshal(trimmed, &artifact); err != nil {
+			return nil, err
+		}
+		if len(artifact.ABI) > 0 {
+			return Parse(artifact.ABI)
+		}
+		entry := &Entry{}
+		if err := json.Unmarshal(trimmed, entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	} else if err := json.Unmarshal(trimmed, &entries); err != nil {
+		return nil, err
+	}
+
+	abi := &ABI{
+		constructor: &Entry{Type: "constructor"},
+		methods:     make(map[[4]byte]*Entry),
+		events:      make(map[common.Hash]*Entry),
+	}
+	for _, entry := range entries {
+		if entry.Type == "" {
+			entry.Type = "function"
+		}
+		switch entry.Type {
+		case "function":
+			if err := entry.init(); err != nil {
+				return nil, err
+			}
+			var selector [4]byte
+			copy(selector[:], entry.Selector())
+			abi.methods[selector] = entry
+			abi.functions = append(abi.functions, entry)
+		case "constructor":
+			if err := entry.init(); err != nil {
+				return nil, err
+			}
+			abi.constructor = entry
+		case "event":
+			if err := entry.init(); err != nil {
+				return nil, err
+			}
+			if !entry.Anonymous {
+				abi.events[crypto.Keccak256Hash([]byte(entry.signature))] = entry
+			}
+		}
+	}
+	return abi, nil
+}
+
+// Merge adds the methods and the events of the other ABI
+func (abi *ABI) Merge(other *ABI) {
+	for selector, method := range other.methods {
+		abi.methods[selector] = method
+	}
+	abi.functions = append(abi.functions, other.functions...)
+	for topic, event := range other.events {
+		abi.events[topic] = event
+	}
+}
+
+// Function finds the function by its signature, or by its name and, if it is overloaded, the number of arguments
+func (abi *ABI) Function(name string, numArgs int) (*Entry, error) {
+	candidates := []*Entry{}
+	for _, function := range abi.functions {
+		if function.signature == name {
+			return function, nil
+		}
+		if function.Name == name {
+			candidates = append(candidates, function)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("Function %v is not defined in the ABI", name)
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	for _, candidate := range candidates {
+		if len(candidate.Inputs) == numArgs {
+			return candidate, nil
+		}
+	}
+	return nil, fmt.Errorf("Function %v is overloaded, specify its signature, e.g. %v", name, candidates[0].signature)
+}
+
+// Constructor returns the constructor, or one without inputs if the ABI does not define it
+func (abi *ABI) Constructor() *Entry {
+	return abi.constructor
+}
+
+// DecodedArg is a decoded argument of a call or an event. The integers are decimal strings, and the bytes are
+// hex strings. The indexed event arguments of the dynamic types are the hashes of their values.
+type DecodedArg struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// DecodedCall is a decoded contract call
+type DecodedCall struct {
+	Method    string        `json:"method"`
+	Signature string        `json:"signature"`
+	Args      []*DecodedArg `json:"args"`
+}
+
+// DecodedEvent is a decoded event log
+type DecodedEvent struct {
+	Event     string        `json:"event"`
+	Signature string        `json:"signature"`
+	Args      []*DecodedArg `json:"args"`
+}
+
+// ErrUnknown is returned when the ABI does not define the method or the event
+var ErrUnknown = errors.New("Not defined in the ABI")
+
+// DecodeCall decodes the input data of a call
+func (abi *ABI) DecodeCall(input []byte) (*DecodedCall, error) {
+	if len(input) < 4 {
+		return nil, ErrUnknown
+	}
+	var selector [4]byte
+	copy(selector[:], input[:4])
+	method, ok := abi.methods[selector]
+	if !ok {
+		return nil, ErrUnknown
+	}
+
+	values, err := decodeSequence(method.types, input[4:])
+	if err != nil {
+		return nil, err
+	}
+	call := &DecodedCall{
+		Method:    method.Name,
+		Signature: method.signature,
+		Args:      []*DecodedArg{},
+	}
+	for i, value := range values {
+		call.Args = append(call.Args, &DecodedArg{Name: method.Inputs[i].Name, Type: method.types[i].String(), Value: value})
+	}
+	return call, nil
+}
+
+// DecodeLog decodes an event log by its first topic, which is the hash of the event signature
+func (abi *ABI) DecodeLog(topics []common.Hash, data []byte) (*DecodedEvent, error) {
+	if len(topics) == 0 {
+		return nil, ErrUnknown
+	}
+	event, ok := abi.events[topics[0]]
+	if !ok {
+		return nil, ErrUnknown
+	}
+
+	nonIndexed := []*Type{}
+	for i, input := range event.Inputs {
+		if !input.Indexed {
+			nonIndexed = append(nonIndexed, event.types[i])
+		}
+	}
+	values, err := decodeSequence(nonIndexed, data)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded := &DecodedEvent{
+		Event:     event.Name,
+		Signature: event.signature,
+		Args:      []*DecodedArg{},
+	}
+	topic := 1
+	for i, input := range event.Inputs {
+		t := event.types[i]
+		arg := &DecodedArg{Name: input.Name, Type: t.String()}
+		if input.Indexed {
+			if topic >= len(topics) {
+				return nil, errors.New("Missing topics of the indexed arguments")
+			}
+			if t.isDynamic() || t.kind == kindArray || t.kind == kindTuple {
+				arg.Value = topics[topic] // only the hash of the value is logged
+			} else if arg.Value, err = t.decodeWord(topics[topic][:]); err != nil {
+				return nil, err
+			}
+			topic++
+		} else {
+			arg.Value = values[0]
+			values = values[1:]
+		}
+		decoded.Args = append(decoded.Args, arg)
+	}
+	return decoded, nil
+}
+
+// DecodeValues decodes the values of the given types encoded one after another, e.g. the return values of a call
+func DecodeValues(types []*Type, data []byte) ([]interface{}, error) {
+	return decodeSequence(types, data)
+}
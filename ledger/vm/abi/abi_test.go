@@ -0,0 +1,214 @@
+package abi
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+)
+
+const testABI = `[
+	{"type": "function", "name": "transfer", "inputs": [{"name": "to", "type": "address"}, {"name": "value", "type": "uint256"}]},
+	{"type": "function", "name": "setName", "inputs": [{"name": "name", "type": "string"}, {"name": "ids", "type": "int32[]"}]},
+	{"type": "function", "name": "configure", "inputs": [{"name": "config", "type": "tuple", "components": [{"name": "fee", "type": "uint128"}, {"name": "memo", "type": "bytes"}]}]},
+	{"type": "event", "name": "Transfer", "inputs": [{"name": "from", "type": "address", "indexed": true}, {"name": "to", "type": "address", "indexed": true}, {"name": "value", "type": "uint256"}]},
+	{"type": "event", "name": "Named", "inputs": [{"name": "name", "type": "string", "indexed": true}, {"name": "flag", "type": "bool"}]}
+]`
+
+func word(n int64) []byte {
+	return common.BigToHash(big.NewInt(n)).Bytes()
+}
+
+func concat(words ...[]byte) []byte {
+	data := []byte{}
+	for _, w := range words {
+		data = append(data, w...)
+	}
+	return data
+}
+
+func selector(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
+
+func TestDecodeCall(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	abi, err := Parse([]byte(testABI))
+	require.Nil(err)
+
+	to := common.HexToAddress("0x2e833968e5bb786ae419c4d13189fb081cc43bab")
+	input := concat(selector("transfer(address,uint256)"), common.LeftPadBytes(to.Bytes(), 32), word(1000))
+	call, err := abi.DecodeCall(input)
+	require.Nil(err)
+	assert.Equal("transfer", call.Method)
+	assert.Equal("transfer(address,uint256)", call.Signature)
+	assert.Equal([]*DecodedArg{{Name: "to", Type: "address", Value: to}, {Name: "value", Type: "uint256", Value: "1000"}}, call.Args)
+
+	name := make([]byte, 32)
+	copy(name, "Theta")
+	minusOne := common.Hash{}
+	for i := range minusOne {
+		minusOne[i] = 0xff
+	}
+	input = concat(selector("setName(string,int32[])"), word(64), word(128), word(5), name, word(2), word(7), minusOne[:])
+	call, err = abi.DecodeCall(input)
+	require.Nil(err)
+	assert.Equal("Theta", call.Args[0].Value)
+	assert.Equal([]interface{}{"7", "-1"}, call.Args[1].Value)
+
+	memo := make([]byte, 32)
+	copy(memo, []byte{0xab, 0xcd})
+	input = concat(selector("configure((uint128,bytes))"), word(32), word(9), word(64), word(2), memo)
+	call, err = abi.DecodeCall(input)
+	require.Nil(err)
+	assert.Equal("(uint128,bytes)", call.Args[0].Type)
+	assert.Equal(map[string]interface{}{"fee": "9", "memo": "0xabcd"}, call.Args[0].Value)
+
+	_, err = abi.DecodeCall(concat(selector("unknown()")))
+	assert.Equal(ErrUnknown, err)
+	_, err = abi.DecodeCall(concat(selector("transfer(address,uint256)"), word(1)))
+	assert.NotNil(err)
+	_, err = abi.DecodeCall(concat(selector("setName(string,int32[])"), word(1<<40), word(0)))
+	assert.NotNil(err)
+}
+
+func TestDecodeLog(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	abi, err := Parse([]byte(testABI))
+	require.Nil(err)
+
+	from := common.HexToAddress("0x2e833968e5bb786ae419c4d13189fb081cc43bab")
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	topics := []common.Hash{
+		crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+		common.BytesToHash(from.Bytes()),
+		common.BytesToHash(to.Bytes()),
+	}
+	event, err := abi.DecodeLog(topics, word(42))
+	require.Nil(err)
+	assert.Equal("Transfer", event.Event)
+	assert.Equal([]*DecodedArg{
+		{Name: "from", Type: "address", Value: from},
+		{Name: "to", Type: "address", Value: to},
+		{Name: "value", Type: "uint256", Value: "42"},
+	}, event.Args)
+
+	nameHash := crypto.Keccak256Hash([]byte("Theta"))
+	event, err = abi.DecodeLog([]common.Hash{crypto.Keccak256Hash([]byte("Named(string,bool)")), nameHash}, word(1))
+	require.Nil(err)
+	assert.Equal(nameHash, event.Args[0].Value)
+	assert.Equal(true, event.Args[1].Value)
+
+	_, err = abi.DecodeLog(topics[:2], word(42))
+	assert.NotNil(err)
+	_, err = abi.DecodeLog([]common.Hash{{}}, nil)
+	assert.Equal(ErrUnknown, err)
+}
+
+func TestParseFragment(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	abi, err := Parse([]byte(`{"type": "event", "name": "Approval", "inputs": [{"name": "owner", "type": "address", "indexed": true}, {"name": "value", "type": "uint256"}]}`))
+	require.Nil(err)
+	event, err := abi.DecodeLog([]common.Hash{crypto.Keccak256Hash([]byte("Approval(address,uint256)")), {}}, word(3))
+	require.Nil(err)
+	assert.Equal("Approval", event.Event)
+
+	_, err = Parse([]byte(`{"not": "an abi"}`))
+	assert.NotNil(err)
+	_, err = Parse([]byte(`[{"type": "function", "name": "f", "inputs": [{"name": "x", "type": "uint7"}]}]`))
+	assert.NotNil(err)
+	_, err = Parse([]byte(`[{"type": "function", "name": "f", "inputs": [{"name": "x", "type": "uint256[0]"}]}]`))
+	assert.NotNil(err)
+}
+
+func TestDecodeMalformedInput(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		types []string
+		data  []byte
+	}{
+		{[]string{"uint256"}, nil},
+		{[]string{"uint256"}, []byte{0}},
+		{[]string{"bytes"}, word(32)},                            // missing length
+		{[]string{"bytes"}, concat(word(32), word(33), word(0))}, // length beyond data
+		{[]string{"string"}, concat(word(1 << 62))},              // offset beyond data
+		{[]string{"uint256[]"}, concat(word(32), word(1<<32))},   // length beyond data
+		{[]string{"uint256[]"}, concat(word(32), word(2), word(1))},
+		{[]string{"uint256[][]"}, concat(word(32), word(1), word(1<<16))},
+		{[]string{"uint256", "uint256[3]"}, concat(word(1), word(2))},
+		{[]string{"uint256[2]", "uint8[2]"}, concat(word(1), word(2))},
+		{[]string{"string[2]"}, concat(word(32), word(64))},
+		{[]string{"uint8[1000000000]"}, concat(word(1))},
+	}
+	for _, tc := range testCases {
+		types := testFunction(t, "test", tc.types...).types
+		assert.NotPanics(func() {
+			_, err := decodeSequence(types, tc.data)
+			assert.NotNil(err, "%v %x", tc.types, tc.data)
+		})
+	}
+
+	// Random data must not panic either
+	rng := rand.New(rand.NewSource(1))
+	typeLists := [][]string{
+		{"uint256", "bytes", "string[]"},
+		{"uint8[2][]", "address"},
+		{"bytes32[][2]", "int16"},
+		{"string[][]"},
+	}
+	for i := 0; i < 2000; i++ {
+		data := make([]byte, rng.Intn(8)*wordSize+rng.Intn(2))
+		for j := range data {
+			// Mostly small values, so that the offsets and the lengths are often in range
+			if j%wordSize == wordSize-1 || rng.Intn(16) == 0 {
+				data[j] = byte(rng.Intn(256))
+			}
+		}
+		types := testFunction(t, "test", typeLists[i%len(typeLists)]...).types
+		assert.NotPanics(func() {
+			decodeSequence(types, data)
+		})
+	}
+}
+
+func TestFunctionLookup(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	abi, err := Parse([]byte(`{"abi": [
+		{"type": "function", "name": "transfer", "inputs": [{"type": "address"}, {"type": "uint"}]},
+		{"type": "function", "name": "transfer", "inputs": [{"type": "address"}]},
+		{"name": "balanceOf", "inputs": [{"type": "address"}], "outputs": [{"name": "balance", "type": "uint256"}], "stateMutability": "view"}
+	]}`))
+	require.Nil(err)
+
+	function, err := abi.Function("transfer(address)", 1)
+	require.Nil(err)
+	assert.Equal(1, len(function.Inputs))
+	function, err = abi.Function("transfer", 2)
+	require.Nil(err)
+	assert.Equal("transfer(address,uint256)", function.Signature())
+	_, err = abi.Function("transfer", 3)
+	assert.NotNil(err)
+	_, err = abi.Function("approve", 1)
+	assert.NotNil(err)
+
+	function, err = abi.Function("balanceOf", 1)
+	require.Nil(err)
+	assert.True(function.IsReadOnly())
+	outputs, err := function.DecodeOutputs(word(7))
+	require.Nil(err)
+	assert.Equal([]*DecodedArg{{Name: "balance", Type: "uint256", Value: "7"}}, outputs)
+	assert.Equal(0, len(abi.Constructor().Inputs))
+}
@@ -0,0 +1,176 @@
+package abi
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// encodeSequence encodes the values of the given types one after another, e.g. the arguments of a call or the
+// components of a tuple. The values of the value types are human readable strings, and the values of the arrays
+// and the tuples are lists, or their JSON strings.
+func encodeSequence(types []*Type, values []interface{}) ([]byte, error) {
+	if len(types) != len(values) {
+		return nil, fmt.Errorf("Expected %v values, got %v", len(types), len(values))
+	}
+	headSize := 0
+	for _, t := range types {
+		headSize += t.headSize()
+	}
+	head, tail := []byte{}, []byte{}
+	for i, t := range types {
+		enc, err := t.encode(values[i])
+		if err != nil {
+			return nil, err
+		}
+		if t.isDynamic() {
+			head = append(head, encodeWord(big.NewInt(int64(headSize+len(tail))))...)
+			tail = append(tail, enc...)
+		} else {
+			head = append(head, enc...)
+		}
+	}
+	return append(head, tail...), nil
+}
+
+// encode encodes the value of the type
+func (t *Type) encode(value interface{}) ([]byte, error) {
+	switch t.kind {
+	case kindSlice, kindArray, kindTuple:
+		list, err := toList(value)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", t.str, err)
+		}
+		if t.kind == kindTuple {
+			if len(list) != len(t.fields) {
+				return nil, fmt.Errorf("%v: expected %v components, got %v", t.str, len(t.fields), len(list))
+			}
+			return encodeSequence(t.fields, list)
+		}
+		if t.kind == kindArray && len(list) != t.length {
+			return nil, fmt.Errorf("%v: expected %v items, got %v", t.str, t.length, len(list))
+		}
+		enc, err := encodeSequence(repeat(t.elem, len(list)), list)
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == kindSlice {
+			enc = append(encodeWord(big.NewInt(int64(len(list)))), enc...)
+		}
+		return enc, nil
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%v: expected a single value, got %v", t.str, value)
+	}
+	switch t.kind {
+	case kindUint, kindInt:
+		x, ok := parseBigInt(str)
+		if !ok {
+			return nil, fmt.Errorf("%v: invalid number %v", t.str, str)
+		}
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(t.size))
+		if t.kind == kindUint {
+			if x.Sign() < 0 || x.Cmp(limit) >= 0 {
+				return nil, fmt.Errorf("%v: %v out of range", t.str, str)
+			}
+			return encodeWord(x), nil
+		}
+		half := new(big.Int).Rsh(limit, 1)
+		if x.Cmp(half) >= 0 || x.Cmp(new(big.Int).Neg(half)) < 0 {
+			return nil, fmt.Errorf("%v: %v out of range", t.str, str)
+		}
+		if x.Sign() < 0 {
+			x = new(big.Int).Add(x, new(big.Int).Lsh(big.NewInt(1), 256)) // two's complement
+		}
+		return encodeWord(x), nil
+	case kindAddress:
+		if !common.IsHexAddress(str) {
+			return nil, fmt.Errorf("%v: invalid address %v", t.str, str)
+		}
+		return common.LeftPadBytes(common.HexToAddress(str).Bytes(), wordSize), nil
+	case kindBool:
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return nil, fmt.Errorf("%v: invalid bool %v", t.str, str)
+		}
+		if b {
+			return encodeWord(big.NewInt(1)), nil
+		}
+		return encodeWord(big.NewInt(0)), nil
+	case kindFixedBytes, kindFunction:
+		size := t.size
+		if t.kind == kindFunction {
+			size = 24 // address and selector
+		}
+		data, err := hex.DecodeString(strings.TrimPrefix(str, "0x"))
+		if err != nil || len(data) > size {
+			return nil, fmt.Errorf("%v: invalid bytes %v", t.str, str)
+		}
+		return common.RightPadBytes(data, wordSize), nil
+	case kindBytes:
+		data, err := hex.DecodeString(strings.TrimPrefix(str, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("%v: invalid bytes %v", t.str, str)
+		}
+		return encodeDynamicBytes(data), nil
+	case kindString:
+		return encodeDynamicBytes([]byte(str)), nil
+	}
+	return nil, fmt.Errorf("Cannot encode type %v", t.str)
+}
+
+func encodeWord(x *big.Int) []byte {
+	return common.LeftPadBytes(x.Bytes(), wordSize)
+}
+
+func encodeDynamicBytes(data []byte) []byte {
+	padded := len(data)
+	if padded%wordSize != 0 {
+		padded += wordSize - padded%wordSize
+	}
+	return append(encodeWord(big.NewInt(int64(len(data)))), common.RightPadBytes(data, padded)...)
+}
+
+func parseBigInt(str string) (*big.Int, bool) {
+	if strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0X") {
+		return new(big.Int).SetString(str[2:], 16)
+	}
+	return new(big.Int).SetString(str, 10)
+}
+
+// toList converts a JSON array, e.g. ["0x01", 2, [3, 4]], into a list of string leaves
+func toList(value interface{}) ([]interface{}, error) {
+	if str, ok := value.(string); ok {
+		decoder := json.NewDecoder(strings.NewReader(str))
+		decoder.UseNumber()
+		var list []interface{}
+		if err := decoder.Decode(&list); err != nil {
+			return nil, fmt.Errorf("expected a JSON array, got %v", str)
+		}
+		value = list
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an array, got %v", value)
+	}
+	for i, item := range list {
+		switch v := item.(type) {
+		case []interface{}:
+		case json.Number:
+			list[i] = v.String()
+		case bool:
+			list[i] = strconv.FormatBool(v)
+		case string:
+		default:
+			return nil, fmt.Errorf("unsupported item %v", item)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,207 @@
+package abi
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+)
+
+// hexWords concatenates the hex encoded words of the expected encoding
+func hexWords(t *testing.T, words ...string) []byte {
+	data, err := hex.DecodeString(strings.Join(words, ""))
+	require.Nil(t, err)
+	return data
+}
+
+func testFunction(t *testing.T, name string, types ...string) *Entry {
+	entry := &Entry{Type: "function", Name: name}
+	for _, typ := range types {
+		entry.Inputs = append(entry.Inputs, Argument{Type: typ})
+	}
+	require.Nil(t, entry.init())
+	return entry
+}
+
+// The vectors are the examples of the Solidity ABI specification
+func TestEncodeKnownVectors(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	baz := testFunction(t, "baz", "uint32", "bool")
+	assert.Equal("cdcd77c0", hex.EncodeToString(baz.Selector()))
+	enc, err := baz.EncodeInputs([]string{"69", "true"})
+	require.Nil(err)
+	assert.Equal(hexWords(t,
+		"0000000000000000000000000000000000000000000000000000000000000045",
+		"0000000000000000000000000000000000000000000000000000000000000001",
+	), enc)
+
+	bar := testFunction(t, "bar", "bytes3[2]")
+	assert.Equal("fce353f6", hex.EncodeToString(bar.Selector()))
+	enc, err = bar.EncodeInputs([]string{`["0x616263", "0x646566"]`})
+	require.Nil(err)
+	assert.Equal(hexWords(t,
+		"6162630000000000000000000000000000000000000000000000000000000000",
+		"6465660000000000000000000000000000000000000000000000000000000000",
+	), enc)
+
+	sam := testFunction(t, "sam", "bytes", "bool", "uint[]")
+	assert.Equal("a5643bf2", hex.EncodeToString(sam.Selector()))
+	enc, err = sam.EncodeInputs([]string{"0x64617665", "true", "[1, 2, 3]"})
+	require.Nil(err)
+	assert.Equal(hexWords(t,
+		"0000000000000000000000000000000000000000000000000000000000000060",
+		"0000000000000000000000000000000000000000000000000000000000000001",
+		"00000000000000000000000000000000000000000000000000000000000000a0",
+		"0000000000000000000000000000000000000000000000000000000000000004",
+		"6461766500000000000000000000000000000000000000000000000000000000",
+		"0000000000000000000000000000000000000000000000000000000000000003",
+		"0000000000000000000000000000000000000000000000000000000000000001",
+		"0000000000000000000000000000000000000000000000000000000000000002",
+		"0000000000000000000000000000000000000000000000000000000000000003",
+	), enc)
+
+	f := testFunction(t, "f", "uint256", "uint32[]", "bytes10", "bytes")
+	assert.Equal("8be65246", hex.EncodeToString(f.Selector()))
+	enc, err = f.EncodeInputs([]string{"0x123", `["0x456", "0x789"]`, "0x31323334353637383930", "0x48656c6c6f2c20776f726c6421"})
+	require.Nil(err)
+	assert.Equal(hexWords(t,
+		"0000000000000000000000000000000000000000000000000000000000000123",
+		"0000000000000000000000000000000000000000000000000000000000000080",
+		"3132333435363738393000000000000000000000000000000000000000000000",
+		"00000000000000000000000000000000000000000000000000000000000000e0",
+		"0000000000000000000000000000000000000000000000000000000000000002",
+		"0000000000000000000000000000000000000000000000000000000000000456",
+		"0000000000000000000000000000000000000000000000000000000000000789",
+		"000000000000000000000000000000000000000000000000000000000000000d",
+		"48656c6c6f2c20776f726c642100000000000000000000000000000000000000",
+	), enc)
+
+	g := testFunction(t, "g", "uint256[][]", "string[]")
+	assert.Equal("2289b18c", hex.EncodeToString(g.Selector()))
+	enc, err = g.EncodeInputs([]string{"[[1, 2], [3]]", `["one", "two", "three"]`})
+	require.Nil(err)
+	assert.Equal(hexWords(t,
+		"0000000000000000000000000000000000000000000000000000000000000040",
+		"0000000000000000000000000000000000000000000000000000000000000140",
+		"0000000000000000000000000000000000000000000000000000000000000002",
+		"0000000000000000000000000000000000000000000000000000000000000040",
+		"00000000000000000000000000000000000000000000000000000000000000a0",
+		"0000000000000000000000000000000000000000000000000000000000000002",
+		"0000000000000000000000000000000000000000000000000000000000000001",
+		"0000000000000000000000000000000000000000000000000000000000000002",
+		"0000000000000000000000000000000000000000000000000000000000000001",
+		"0000000000000000000000000000000000000000000000000000000000000003",
+		"0000000000000000000000000000000000000000000000000000000000000003",
+		"0000000000000000000000000000000000000000000000000000000000000060",
+		"00000000000000000000000000000000000000000000000000000000000000a0",
+		"00000000000000000000000000000000000000000000000000000000000000e0",
+		"0000000000000000000000000000000000000000000000000000000000000003",
+		"6f6e650000000000000000000000000000000000000000000000000000000000",
+		"0000000000000000000000000000000000000000000000000000000000000003",
+		"74776f0000000000000000000000000000000000000000000000000000000000",
+		"0000000000000000000000000000000000000000000000000000000000000005",
+		"7468726565000000000000000000000000000000000000000000000000000000",
+	), enc)
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	address := common.HexToAddress("0x2E833968E5bB786Ae419c4d13189fB081Cc43bab")
+	testCases := []struct {
+		types    []string
+		args     []string
+		expected []interface{}
+	}{
+		{
+			types:    []string{"uint8", "int8", "int256", "bool", "address"},
+			args:     []string{"255", "-128", "-1", "false", address.Hex()},
+			expected: []interface{}{"255", "-128", "-1", false, address},
+		},
+		{
+			types:    []string{"bytes4", "bytes", "string"},
+			args:     []string{"0xdeadbeef", "0x", "Theta ✓"},
+			expected: []interface{}{"0xdeadbeef", "0x", "Theta ✓"},
+		},
+		{
+			types: []string{"uint16[2][]", "string[2]", "bool[]"},
+			args:  []string{"[[1, 2], [3, 4], [5, 6]]", `["a", "bc"]`, "[]"},
+			expected: []interface{}{
+				[]interface{}{[]interface{}{"1", "2"}, []interface{}{"3", "4"}, []interface{}{"5", "6"}},
+				[]interface{}{"a", "bc"},
+				[]interface{}{},
+			},
+		},
+		{
+			types: []string{"address[][2]", "int32"},
+			args:  []string{`[["` + address.Hex() + `"], []]`, "-7"},
+			expected: []interface{}{
+				[]interface{}{[]interface{}{address}, []interface{}{}},
+				"-7",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		function := testFunction(t, "test", tc.types...)
+		enc, err := function.EncodeInputs(tc.args)
+		require.Nil(err, tc.types)
+		assert.Equal(0, len(enc)%wordSize)
+
+		values, err := decodeSequence(function.types, enc)
+		require.Nil(err, tc.types)
+		assert.Equal(tc.expected, values, tc.types)
+	}
+
+	// The components of a tuple are positional
+	function := &Entry{Type: "function", Name: "configure", Inputs: []Argument{{Name: "config", Type: "tuple", Components: []Argument{
+		{Name: "fee", Type: "uint128"},
+		{Name: "memo", Type: "bytes"},
+	}}}}
+	require.Nil(function.init())
+	enc, err := function.EncodeInputs([]string{`[9, "0xabcd"]`})
+	require.Nil(err)
+	values, err := decodeSequence(function.types, enc)
+	require.Nil(err)
+	assert.Equal([]interface{}{map[string]interface{}{"fee": "9", "memo": "0xabcd"}}, values)
+}
+
+func TestEncodeInvalidArguments(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		typ string
+		arg string
+	}{
+		{"uint8", "256"},
+		{"uint256", "-1"},
+		{"int8", "128"},
+		{"int8", "-129"},
+		{"uint", "abc"},
+		{"bool", "yes"},
+		{"address", "0x1234"},
+		{"bytes2", "0x010203"},
+		{"bytes", "0xzz"},
+		{"uint8[2]", "[1]"},
+		{"uint8[]", "1"},
+		{"uint8[]", `[{"a": 1}]`},
+	}
+	for _, tc := range testCases {
+		_, err := testFunction(t, "test", tc.typ).EncodeInputs([]string{tc.arg})
+		assert.NotNil(err, "%v %v", tc.typ, tc.arg)
+	}
+
+	_, err := testFunction(t, "test", "uint8", "bool").EncodeInputs([]string{"1"})
+	assert.NotNil(err)
+
+	for _, typ := range []string{"", "uint7", "uint264", "int0", "bytes0", "bytes33", "[]", "uint[0]", "uint[-1]", "uint[x]", "float"} {
+		_, err := NewType(typ, nil)
+		assert.NotNil(err, typ)
+	}
+}
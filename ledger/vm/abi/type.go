@@ -0,0 +1,295 @@
+package abi
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// Kinds of the ABI types
+const (
+	kindUint = iota
+	kindInt
+	kindAddress
+	kindBool
+	kindFixedBytes
+	kindBytes
+	kindString
+	kindSlice // T[]
+	kindArray // T[k]
+	kindTuple
+	kindFunction
+)
+
+// wordSize is the size of an encoded word in bytes
+const wordSize = 32
+
+// Type is a parsed ABI type
+type Type struct {
+	kind   int
+	size   int // bits of the integers, bytes of the fixed bytes
+	length int // length of the fixed arrays
+	elem   *Type
+	fields []*Type
+	names  []string // names of the tuple components
+	str    string   // canonical type name, e.g. uint256 or (address,uint256)[]
+}
+
+// String returns the canonical name of the type as used in the signatures
+func (t *Type) String() string {
+	return t.str
+}
+
+// NewType parses the given type, with the components of the tuple types
+func NewType(typ string, components []Argument) (*Type, error) {
+	// Arrays, parsed from the outermost dimension, which is the last one
+	if strings.HasSuffix(typ, "]") {
+		i := strings.LastIndex(typ, "[")
+		if i < 0 {
+			return nil, fmt.Errorf("Invalid type: %v", typ)
+		}
+		elem, err := NewType(typ[:i], components)
+		if err != nil {
+			return nil, err
+		}
+		if i == len(typ)-2 {
+			return &Type{kind: kindSlice, elem: elem, str: elem.str + "[]"}, nil
+		}
+		length, err := strconv.Atoi(typ[i+1 : len(typ)-1])
+		if err != nil || length <= 0 {
+			return nil, fmt.Errorf("Invalid array length: %v", typ)
+		}
+		return &Type{kind: kindArray, elem: elem, length: length, str: fmt.Sprintf("%v[%v]", elem.str, length)}, nil
+	}
+
+	switch {
+	case typ == "tuple":
+		t := &Type{kind: kindTuple}
+		strs := []string{}
+		for _, component := range components {
+			field, err := NewType(component.Type, component.Components)
+			if err != nil {
+				return nil, err
+			}
+			t.fields = append(t.fields, field)
+			t.names = append(t.names, component.Name)
+			strs = append(strs, field.str)
+		}
+		t.str = "(" + strings.Join(strs, ",") + ")"
+		return t, nil
+	case typ == "address":
+		return &Type{kind: kindAddress, str: typ}, nil
+	case typ == "bool":
+		return &Type{kind: kindBool, str: typ}, nil
+	case typ == "string":
+		return &Type{kind: kindString, str: typ}, nil
+	case typ == "bytes":
+		return &Type{kind: kindBytes, str: typ}, nil
+	case typ == "function":
+		return &Type{kind: kindFunction, str: typ}, nil
+	case strings.HasPrefix(typ, "bytes"):
+		size, err := strconv.Atoi(typ[len("bytes"):])
+		if err != nil || size <= 0 || size > wordSize {
+			return nil, fmt.Errorf("Invalid type: %v", typ)
+		}
+		return &Type{kind: kindFixedBytes, size: size, str: typ}, nil
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		kind, prefix := kindUint, "uint"
+		if strings.HasPrefix(typ, "int") {
+			kind, prefix = kindInt, "int"
+		}
+		size := 256
+		if typ != prefix {
+			var err error
+			if size, err = strconv.Atoi(typ[len(prefix):]); err != nil || size <= 0 || size > 256 || size%8 != 0 {
+				return nil, fmt.Errorf("Invalid type: %v", typ)
+			}
+		}
+		return &Type{kind: kind, size: size, str: fmt.Sprintf("%v%v", prefix, size)}, nil
+	default:
+		return nil, fmt.Errorf("Unsupported type: %v", typ)
+	}
+}
+
+// isDynamic returns whether the encoding of the type is referenced by an offset
+func (t *Type) isDynamic() bool {
+	switch t.kind {
+	case kindBytes, kindString, kindSlice:
+		return true
+	case kindArray:
+		return t.elem.isDynamic()
+	case kindTuple:
+		for _, field := range t.fields {
+			if field.isDynamic() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headSize returns the size of the type in the head of the encoding
+func (t *Type) headSize() int {
+	if t.isDynamic() {
+		return wordSize
+	}
+	switch t.kind {
+	case kindArray:
+		return t.length * t.elem.headSize()
+	case kindTuple:
+		size := 0
+		for _, field := range t.fields {
+			size += field.headSize()
+		}
+		return size
+	}
+	return wordSize
+}
+
+var errShortInput = errors.New("Input is too short")
+
+func readWord(data []byte, offset int) ([]byte, error) {
+	if offset < 0 || offset+wordSize > len(data) {
+		return nil, errShortInput
+	}
+	return data[offset : offset+wordSize], nil
+}
+
+func readInt(data []byte, offset int) (int, error) {
+	word, err := readWord(data, offset)
+	if err != nil {
+		return 0, err
+	}
+	value := new(big.Int).SetBytes(word)
+	if !value.IsInt64() || value.Int64() > int64(len(data)) {
+		return 0, errShortInput
+	}
+	return int(value.Int64()), nil
+}
+
+// decode decodes the value of the type at the given offset of the data. The offsets of the dynamic values
+// are relative to the start of the data.
+func (t *Type) decode(data []byte, offset int) (interface{}, error) {
+	if t.isDynamic() {
+		ref, err := readInt(data, offset)
+		if err != nil {
+			return nil, err
+		}
+		return t.decodeAt(data[ref:])
+	}
+	if offset > len(data) {
+		return nil, errShortInput
+	}
+	return t.decodeAt(data[offset:])
+}
+
+// decodeAt decodes the value of the type encoded at the start of the data
+func (t *Type) decodeAt(data []byte) (interface{}, error) {
+	switch t.kind {
+	case kindBytes, kindString:
+		length, err := readInt(data, 0)
+		if err != nil {
+			return nil, err
+		}
+		if wordSize+length > len(data) {
+			return nil, errShortInput
+		}
+		raw := data[wordSize : wordSize+length]
+		if t.kind == kindString {
+			return string(raw), nil
+		}
+		return "0x" + hex.EncodeToString(raw), nil
+	case kindSlice:
+		length, err := readInt(data, 0)
+		if err != nil {
+			return nil, err
+		}
+		// Each item takes at least a word, so that a malformed length cannot make the decoder allocate excessively
+		if length > (len(data)-wordSize)/wordSize {
+			return nil, errShortInput
+		}
+		return decodeSequence(repeat(t.elem, length), data[wordSize:])
+	case kindArray:
+		if t.length > len(data)/wordSize {
+			return nil, errShortInput
+		}
+		return decodeSequence(repeat(t.elem, t.length), data)
+	case kindTuple:
+		values, err := decodeSequence(t.fields, data)
+		if err != nil {
+			return nil, err
+		}
+		return namedValues(t.names, values), nil
+	}
+
+	word, err := readWord(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	return t.decodeWord(word)
+}
+
+// decodeWord decodes a value type
+func (t *Type) decodeWord(word []byte) (interface{}, error) {
+	switch t.kind {
+	case kindUint:
+		return new(big.Int).SetBytes(word).String(), nil
+	case kindInt:
+		value := new(big.Int).SetBytes(word)
+		if word[0]&0x80 != 0 {
+			value.Sub(value, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		return value.String(), nil
+	case kindAddress:
+		return common.BytesToAddress(word[12:]), nil
+	case kindBool:
+		return new(big.Int).SetBytes(word).Sign() != 0, nil
+	case kindFixedBytes:
+		return "0x" + hex.EncodeToString(word[:t.size]), nil
+	case kindFunction:
+		return "0x" + hex.EncodeToString(word[:24]), nil
+	}
+	return nil, fmt.Errorf("Cannot decode type %v", t.str)
+}
+
+func repeat(t *Type, n int) []*Type {
+	types := make([]*Type, n)
+	for i := range types {
+		types[i] = t
+	}
+	return types
+}
+
+// decodeSequence decodes the values of the given types encoded one after another, e.g. the arguments of a
+// call or the components of a tuple
+func decodeSequence(types []*Type, data []byte) ([]interface{}, error) {
+	values := []interface{}{}
+	offset := 0
+	for _, t := range types {
+		value, err := t.decode(data, offset)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		offset += t.headSize()
+	}
+	return values, nil
+}
+
+// namedValues returns the components of a tuple by their names, or by their positions if unnamed
+func namedValues(names []string, values []interface{}) map[string]interface{} {
+	named := make(map[string]interface{})
+	for i, value := range values {
+		name := names[i]
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		named[name] = value
+	}
+	return named
+}
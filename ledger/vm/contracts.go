@@ -23,6 +23,7 @@ import (
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/math"
+	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/crypto/bn256"
 	"github.com/thetatoken/theta/ledger/vm/params"
@@ -33,8 +34,8 @@ import (
 // requires a deterministic gas count based on the input size of the Run method of the
 // contract.
 type PrecompiledContract interface {
-	RequiredGas(input []byte, blockHeight uint64) uint64 // RequiredPrice calculates the contract gas use
-	Run(evm *EVM, input []byte) ([]byte, error)          // Run runs the precompiled contract
+	RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 // RequiredPrice calculates the contract gas use
+	Run(evm *EVM, input []byte) ([]byte, error)                          // Run runs the precompiled contract
 }
 
 // PrecompiledContractsHomestead contains the default set of pre-compiled Ethereum
@@ -62,10 +63,35 @@ var PrecompiledContractsByzantium = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{202}): &thetaStake{},
 }
 
+// PrecompiledContractsRandomnessBeacon contains the pre-compiled contracts available after the
+// randomness beacon fork.
+var PrecompiledContractsRandomnessBeacon = map[common.Address]PrecompiledContract{
+	common.BytesToAddress([]byte{1}): &ecrecover{},
+	common.BytesToAddress([]byte{2}): &sha256hash{},
+	common.BytesToAddress([]byte{3}): &ripemd160hash{},
+	common.BytesToAddress([]byte{4}): &dataCopy{},
+	common.BytesToAddress([]byte{5}): &bigModExp{},
+	common.BytesToAddress([]byte{6}): &bn256Add{},
+	common.BytesToAddress([]byte{7}): &bn256ScalarMul{},
+	common.BytesToAddress([]byte{8}): &bn256Pairing{},
+
+	common.BytesToAddress([]byte{201}): &thetaBalance{},
+	common.BytesToAddress([]byte{202}): &thetaStake{},
+	common.BytesToAddress([]byte{203}): &randomness{},
+}
+
+// ActivePrecompiledContracts returns the pre-compiled contracts available at the given block height of the given chain
+func ActivePrecompiledContracts(chainID string, blockHeight uint64) map[common.Address]PrecompiledContract {
+	if core.IsForkActive(chainID, core.ForkRandomnessBeacon, blockHeight) {
+		return PrecompiledContractsRandomnessBeacon
+	}
+	return PrecompiledContractsByzantium
+}
+
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
 func RunPrecompiledContract(evm *EVM, p PrecompiledContract, input []byte, contract *Contract) (ret []byte, err error) {
 	blockHeight := evm.StateDB.GetBlockHeight()
-	gas := p.RequiredGas(input, blockHeight)
+	gas := p.RequiredGas(input, evm.ChainID, blockHeight)
 	if contract.UseGas(gas) {
 		return p.Run(evm, input)
 	}
@@ -75,7 +101,7 @@ func RunPrecompiledContract(evm *EVM, p PrecompiledContract, input []byte, contr
 // ECRECOVER implemented as a native contract.
 type ecrecover struct{}
 
-func (c *ecrecover) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *ecrecover) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return params.EcrecoverGas
 }
 
@@ -112,7 +138,7 @@ type sha256hash struct{}
 //
 // This method does not require any overflow checking as the input size gas costs
 // required for anything significant is so high it's impossible to pay for.
-func (c *sha256hash) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *sha256hash) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return uint64(len(input)+31)/32*params.Sha256PerWordGas + params.Sha256BaseGas
 }
 func (c *sha256hash) Run(evm *EVM, input []byte) ([]byte, error) {
@@ -127,7 +153,7 @@ type ripemd160hash struct{}
 //
 // This method does not require any overflow checking as the input size gas costs
 // required for anything significant is so high it's impossible to pay for.
-func (c *ripemd160hash) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *ripemd160hash) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return uint64(len(input)+31)/32*params.Ripemd160PerWordGas + params.Ripemd160BaseGas
 }
 func (c *ripemd160hash) Run(evm *EVM, input []byte) ([]byte, error) {
@@ -143,7 +169,7 @@ type dataCopy struct{}
 //
 // This method does not require any overflow checking as the input size gas costs
 // required for anything significant is so high it's impossible to pay for.
-func (c *dataCopy) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *dataCopy) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return uint64(len(input)+31)/32*params.IdentityPerWordGas + params.IdentityBaseGas
 }
 func (c *dataCopy) Run(evm *EVM, in []byte) ([]byte, error) {
@@ -168,7 +194,7 @@ var (
 )
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *bigModExp) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *bigModExp) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	var (
 		baseLen = new(big.Int).SetBytes(getData(input, 0, 32))
 		expLen  = new(big.Int).SetBytes(getData(input, 32, 32))
@@ -279,8 +305,8 @@ func newTwistPoint(blob []byte) (*bn256.G2, error) {
 type bn256Add struct{}
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *bn256Add) RequiredGas(input []byte, blockHeight uint64) uint64 {
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+func (c *bn256Add) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
+	if !core.IsForkActive(chainID, core.ForkJune2021FeeAdjustment, blockHeight) {
 		return params.Bn256AddGas
 	}
 	return params.Bn256AddGasIstanbul
@@ -304,8 +330,8 @@ func (c *bn256Add) Run(evm *EVM, input []byte) ([]byte, error) {
 type bn256ScalarMul struct{}
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *bn256ScalarMul) RequiredGas(input []byte, blockHeight uint64) uint64 {
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+func (c *bn256ScalarMul) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
+	if !core.IsForkActive(chainID, core.ForkJune2021FeeAdjustment, blockHeight) {
 		return params.Bn256ScalarMulGas
 	}
 
@@ -337,8 +363,8 @@ var (
 type bn256Pairing struct{}
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *bn256Pairing) RequiredGas(input []byte, blockHeight uint64) uint64 {
-	if blockHeight < common.HeightJune2021FeeAdjustment {
+func (c *bn256Pairing) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
+	if !core.IsForkActive(chainID, core.ForkJune2021FeeAdjustment, blockHeight) {
 		return params.Bn256PairingBaseGas + uint64(len(input)/192)*params.Bn256PairingPerPointGas
 	}
 
@@ -379,7 +405,7 @@ type thetaBalance struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *thetaBalance) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *thetaBalance) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return params.ThetaBalanceGas
 }
 
@@ -396,7 +422,7 @@ type thetaStake struct {
 }
 
 // RequiredGas returns the gas required to execute the pre-compiled contract.
-func (c *thetaStake) RequiredGas(input []byte, blockHeight uint64) uint64 {
+func (c *thetaStake) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
 	return params.ThetaStakeGas
 }
 
@@ -407,3 +433,21 @@ func (c *thetaStake) Run(evm *EVM, input []byte) ([]byte, error) {
 	thetaStakeBytes32 := common.LeftPadBytes(thetaStakeBytes[:], 32) // easier to convert bytes32 into uint256 in smart contracts
 	return thetaStakeBytes32, nil
 }
+
+// randomness retrieves the randomness derived by the previous block. The value is the same for all the
+// transactions in a block, and is known once the previous block is proposed, so the contracts should commit
+// to a future block and use its randomness to avoid being front-run. The proposer of that block can still
+// bias the randomness by including or omitting the guardian and elite edge node votes, so it should not
+// decide outcomes worth more than the reward of proposing a block.
+type randomness struct {
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *randomness) RequiredGas(input []byte, chainID string, blockHeight uint64) uint64 {
+	return params.RandomnessGas
+}
+
+func (c *randomness) Run(evm *EVM, input []byte) ([]byte, error) {
+	randomness := evm.StateDB.GetRandomness()
+	return randomness[:], nil
+}
@@ -18,6 +18,7 @@ func Execute(parentBlock *core.Block, tx *types.SmartContractTx, storeView *stat
 	context := Context{
 		CanTransfer: CanTransfer,
 		Transfer:    Transfer,
+		ChainID:     parentBlock.ChainID,
 		Origin:      tx.From.Address,
 		GasPrice:    tx.GasPrice,
 		GasLimit:    tx.GasLimit,
@@ -45,7 +46,7 @@ func Execute(parentBlock *core.Block, tx *types.SmartContractTx, storeView *stat
 	// 	return common.Bytes{}, common.Address{}, 0, ErrInvalidGasLimit
 	// }
 	blockHeight := storeView.Height() + 1
-	maxGasLimit := types.GetMaxGasLimit(blockHeight)
+	maxGasLimit := types.GetMaxGasLimit(parentBlock.ChainID, blockHeight)
 	if new(big.Int).SetUint64(gasLimit).Cmp(maxGasLimit) > 0 {
 		return common.Bytes{}, common.Address{}, 0, ErrInvalidGasLimit
 	}
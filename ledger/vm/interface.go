@@ -33,6 +33,7 @@ type StateDB interface {
 
 	GetThetaBalance(common.Address) *big.Int // GetThetaBalance returns the ThetaWei balance of the given address
 	GetThetaStake(common.Address) *big.Int   // GetThetaStake returns the total amount of ThetaWei the address staked to validators and/or guardians
+	GetRandomness() common.Hash              // GetRandomness returns the randomness derived by the previous block
 
 	GetNonce(common.Address) uint64
 	SetNonce(common.Address, uint64)
@@ -96,6 +96,7 @@ const (
 
 	ThetaBalanceGas uint64 = 4   // Retrieve the Theta balance for an address
 	ThetaStakeGas   uint64 = 200 // Retrieve the total amount of staked Theta for an address
+	RandomnessGas   uint64 = 20  // Retrieve the randomness of the previous block
 )
 
 var (
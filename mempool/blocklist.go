@@ -0,0 +1,174 @@
+package mempool
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// blocklistReloadInterval limits how often the blocklist file is checked for changes
+const blocklistReloadInterval = 10 * time.Second
+
+// BlocklistAuditRecord is an entry of the blocklist audit log
+type BlocklistAuditRecord struct {
+	Time    time.Time      `json:"time"`
+	TxHash  common.Hash    `json:"tx_hash"`
+	Address common.Address `json:"address"` // the blocked address involved in the transaction
+}
+
+// Blocklist is the operator configured set of addresses whose transactions are not admitted to the mempool.
+// It is only consulted for the incoming transactions, i.e. those submitted through the RPC and those gossiped
+// by the peers, and never for the transactions of the blocks, so it does not affect the consensus.
+type Blocklist struct {
+	mu *sync.Mutex
+
+	static    map[common.Address]bool // the addresses of the config
+	path      string                  // the blocklist file, if any
+	fromFile  map[common.Address]bool
+	modTime   time.Time
+	checkedAt time.Time
+
+	audit io.Writer
+}
+
+// NewBlocklist creates a Blocklist of the given addresses and of the addresses listed in the given file, if
+// any. The rejections are recorded to the given audit writer, if not nil.
+func NewBlocklist(addresses []string, path string, audit io.Writer) (*Blocklist, error) {
+	static, err := parseBlockedAddresses(addresses)
+	if err != nil {
+		return nil, err
+	}
+	bl := &Blocklist{
+		mu:       &sync.Mutex{},
+		static:   static,
+		path:     path,
+		fromFile: make(map[common.Address]bool),
+		audit:    audit,
+	}
+	if path != "" {
+		if err := bl.reload(time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return bl, nil
+}
+
+// newBlocklistFromConfig creates the Blocklist of the config, nil if no blocklist is configured
+func newBlocklistFromConfig() (*Blocklist, error) {
+	addresses := viper.GetStringSlice(common.CfgMempoolBlocklist)
+	path := viper.GetString(common.CfgMempoolBlocklistFile)
+	if len(addresses) == 0 && path == "" {
+		return nil, nil
+	}
+	var audit io.Writer
+	if auditLog := viper.GetString(common.CfgMempoolBlocklistAuditLog); auditLog != "" {
+		file, err := os.OpenFile(auditLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, err
+		}
+		audit = file
+	}
+	return NewBlocklist(addresses, path, audit)
+}
+
+func parseBlockedAddresses(addresses []string) (map[common.Address]bool, error) {
+	blocked := make(map[common.Address]bool)
+	for _, address := range addresses {
+		address = strings.TrimSpace(address)
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("Invalid blocked address: %v", address)
+		}
+		blocked[common.HexToAddress(address)] = true
+	}
+	return blocked, nil
+}
+
+// reload reads the blocklist file again if it has changed. The previous addresses of the file are kept if it
+// cannot be read.
+func (bl *Blocklist) reload(now time.Time) error {
+	bl.checkedAt = now
+	info, err := os.Stat(bl.path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(bl.modTime) {
+		return nil
+	}
+
+	file, err := os.Open(bl.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	addresses := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			addresses = append(addresses, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	fromFile, err := parseBlockedAddresses(addresses)
+	if err != nil {
+		return fmt.Errorf("%v: %v", bl.path, err)
+	}
+
+	bl.fromFile = fromFile
+	bl.modTime = info.ModTime()
+	logger.Infof("Loaded %v blocked addresses from %v", len(fromFile), bl.path)
+	return nil
+}
+
+// Check returns the first address involved in the given transaction which is blocked, if any
+func (bl *Blocklist) Check(tx types.Tx) (common.Address, bool) {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+
+	if now := time.Now(); bl.path != "" && now.Sub(bl.checkedAt) >= blocklistReloadInterval {
+		if err := bl.reload(now); err != nil {
+			logger.Warnf("Failed to reload the blocklist, keeping the previous addresses: %v", err)
+		}
+	}
+	for _, address := range blockchain.TxAddresses(tx) {
+		if bl.static[address] || bl.fromFile[address] {
+			return address, true
+		}
+	}
+	return common.Address{}, false
+}
+
+// recordRejection logs the rejection of the given transaction, and writes it to the audit log if configured
+func (bl *Blocklist) recordRejection(txHash common.Hash, address common.Address) {
+	logger.Warnf("Rejected transaction %v involving the blocked address %v", txHash.Hex(), address.Hex())
+	if bl.audit == nil {
+		return
+	}
+	raw, err := json.Marshal(&BlocklistAuditRecord{Time: time.Now(), TxHash: txHash, Address: address})
+	if err != nil {
+		logger.Warnf("Failed to encode the blocklist audit record: %v", err)
+		return
+	}
+
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	if _, err := bl.audit.Write(append(raw, '\n')); err != nil {
+		logger.Warnf("Failed to write the blocklist audit record: %v", err)
+	}
+}
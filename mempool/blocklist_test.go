@@ -0,0 +1,104 @@
+package mempool
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func blocklistTestTx(from, to common.Address) *types.SendTx {
+	return &types.SendTx{
+		Fee:     types.NewCoins(0, 1),
+		Inputs:  []types.TxInput{{Address: from, Coins: types.NewCoins(0, 2), Sequence: 1}},
+		Outputs: []types.TxOutput{{Address: to, Coins: types.NewCoins(0, 1)}},
+	}
+}
+
+func TestBlocklist(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	carol := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	dir, err := ioutil.TempDir("", "blocklist")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "blocklist.txt")
+	require.Nil(ioutil.WriteFile(path, []byte("# sanctioned\n"+carol.Hex()+" # added later\n\n"), 0600))
+
+	_, err = NewBlocklist([]string{"not an address"}, "", nil)
+	assert.NotNil(err)
+
+	bl, err := NewBlocklist([]string{bob.Hex()}, path, nil)
+	require.Nil(err)
+	_, blocked := bl.Check(blocklistTestTx(alice, alice))
+	assert.False(blocked)
+	address, blocked := bl.Check(blocklistTestTx(alice, bob))
+	assert.True(blocked)
+	assert.Equal(bob, address)
+	address, blocked = bl.Check(blocklistTestTx(carol, alice))
+	assert.True(blocked)
+	assert.Equal(carol, address)
+
+	// The file is reloaded once changed, and the previous addresses are kept if it becomes invalid
+	require.Nil(ioutil.WriteFile(path, []byte(alice.Hex()+"\n"), 0600))
+	modTime := time.Now().Add(time.Minute)
+	require.Nil(os.Chtimes(path, modTime, modTime))
+	bl.checkedAt = time.Time{}
+	_, blocked = bl.Check(blocklistTestTx(carol, carol))
+	assert.False(blocked)
+	_, blocked = bl.Check(blocklistTestTx(alice, alice))
+	assert.True(blocked)
+
+	require.Nil(ioutil.WriteFile(path, []byte("garbage\n"), 0600))
+	modTime = modTime.Add(time.Minute)
+	require.Nil(os.Chtimes(path, modTime, modTime))
+	bl.checkedAt = time.Time{}
+	_, blocked = bl.Check(blocklistTestTx(alice, alice))
+	assert.True(blocked)
+}
+
+func TestMempoolBlocklist(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	viper.Set(common.CfgMempoolBlocklist, []string{bob.Hex()})
+	defer viper.Set(common.CfgMempoolBlocklist, []string{})
+	mp := CreateMempool(nil, nil)
+	audit := &bytes.Buffer{}
+	mp.blocklist.audit = audit
+
+	rawTx, err := types.TxToBytes(blocklistTestTx(alice, bob))
+	require.Nil(err)
+	err = mp.InsertTransaction(rawTx)
+	screeningErr, ok := err.(*TxScreeningError)
+	require.True(ok, err)
+	assert.Equal(result.CodeBlockedAddress, screeningErr.Code)
+	assert.Equal(0, mp.Size())
+
+	record := &BlocklistAuditRecord{}
+	require.Nil(json.Unmarshal(audit.Bytes(), record))
+	assert.Equal(crypto.Keccak256Hash(rawTx), record.TxHash)
+	assert.Equal(bob, record.Address)
+
+	_, blocked := mp.BlockedAddress(blocklistTestTx(alice, alice))
+	assert.False(blocked)
+}
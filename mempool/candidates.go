@@ -0,0 +1,93 @@
+package mempool
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+)
+
+// Candidate is a transaction in the Mempool that can be included in the next block
+type Candidate struct {
+	RawTx     common.Bytes
+	TxInfo    *core.TxInfo
+	ArrivedAt time.Time
+}
+
+// PeekUnsafe returns up to maxNumTxs candidate transactions in the order Reap would return them,
+// i.e. the transactions of each sender by sequence, and across the senders by the effective gas
+// price. Unlike Reap, the transactions stay in the Mempool until RemoveUnsafe or Update is called.
+// maxNumTxs < 0 means uncapped. Caller must call Mempool.Lock() before calling this method.
+func (mp *Mempool) PeekUnsafe(maxNumTxs int) []*Candidate {
+	if maxNumTxs < 0 {
+		maxNumTxs = mp.Size()
+	}
+
+	groups := &candidateGroups{}
+	for _, txgElem := range *mp.candidateTxs.ElementList() {
+		txg := txgElem.(*mempoolTransactionGroup)
+		txs := []*mempoolTransaction{}
+		for _, txElem := range *txg.txs.ElementList() {
+			txs = append(txs, txElem.(*mempoolTransaction))
+		}
+		if len(txs) == 0 {
+			continue
+		}
+		sort.Slice(txs, func(i, j int) bool {
+			return txs[i].txInfo.Sequence < txs[j].txInfo.Sequence
+		})
+		*groups = append(*groups, txs)
+	}
+	heap.Init(groups)
+
+	candidates := []*Candidate{}
+	for groups.Len() > 0 && len(candidates) < maxNumTxs {
+		txs := (*groups)[0]
+		tx := txs[0]
+		if len(txs) > 1 {
+			(*groups)[0] = txs[1:]
+			heap.Fix(groups, 0)
+		} else {
+			heap.Pop(groups)
+		}
+
+		// Skip the txs removed from the bookkeeper due to timeout, as Reap does
+		if _, exists := mp.txBookeepper.getStatus(getTransactionHash(tx.rawTransaction)); !exists {
+			continue
+		}
+		candidates = append(candidates, &Candidate{
+			RawTx:     tx.rawTransaction,
+			TxInfo:    tx.txInfo,
+			ArrivedAt: tx.arrivedAt,
+		})
+	}
+	return candidates
+}
+
+// RemoveUnsafe removes the given transactions from the candidate pool, e.g. the transactions picked
+// from PeekUnsafe for a block proposal. Caller must call Mempool.Lock() before calling this method.
+func (mp *Mempool) RemoveUnsafe(rawTxs []common.Bytes) {
+	mp.removeTxs(rawTxs)
+}
+
+// candidateGroups is a max-heap of the remaining transactions of each sender, sorted by sequence,
+// ordered by the effective gas price of their lowest sequence transaction
+type candidateGroups [][]*mempoolTransaction
+
+func (g candidateGroups) Len() int { return len(g) }
+func (g candidateGroups) Less(i, j int) bool {
+	return g[i][0].txInfo.EffectiveGasPrice.Cmp(g[j][0].txInfo.EffectiveGasPrice) > 0
+}
+func (g candidateGroups) Swap(i, j int) { g[i], g[j] = g[j], g[i] }
+func (g *candidateGroups) Push(x interface{}) {
+	*g = append(*g, x.([]*mempoolTransaction))
+}
+func (g *candidateGroups) Pop() interface{} {
+	old := *g
+	n := len(old)
+	x := old[n-1]
+	*g = old[:n-1]
+	return x
+}
@@ -0,0 +1,138 @@
+package mempool
+
+import (
+	"time"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/crypto"
+)
+
+//
+// The tx events record every admission decision of the Mempool, so that the operators can tell why the
+// transactions do not make it into the blocks, e.g. bad sequences vs low fees.
+//
+
+// TxEventType is the type of a Mempool transaction event
+type TxEventType string
+
+const (
+	TxEventAccepted TxEventType = "accepted" // the transaction is admitted, as a candidate or a scheduled transaction
+	TxEventRejected TxEventType = "rejected" // the transaction is turned away on arrival
+	TxEventEvicted  TxEventType = "evicted"  // the admitted transaction has become invalid before its inclusion
+	TxEventExpired  TxEventType = "expired"  // the admitted transaction has not been included in time
+	TxEventPromoted TxEventType = "promoted" // the admitted transaction has been included in a committed block
+)
+
+// TxEventReason is the cause of a rejection or an eviction
+type TxEventReason string
+
+const (
+	TxReasonDuplicate         TxEventReason = "duplicate"
+	TxReasonBlockedAddress    TxEventReason = "blocked_address"
+	TxReasonMempoolFull       TxEventReason = "mempool_full"
+	TxReasonSyncing           TxEventReason = "syncing"
+	TxReasonScheduledPoolFull TxEventReason = "scheduled_pool_full"
+	TxReasonBadSequence       TxEventReason = "bad_sequence"
+	TxReasonLowFee            TxEventReason = "low_fee"
+	TxReasonInsufficientFund  TxEventReason = "insufficient_fund"
+	TxReasonInvalidSignature  TxEventReason = "invalid_signature"
+	TxReasonInvalid           TxEventReason = "invalid"
+)
+
+// TxEvent is an admission decision of the Mempool on a transaction
+type TxEvent struct {
+	Type    TxEventType
+	Hash    common.Hash
+	Reason  TxEventReason // set for the rejections and the evictions
+	Message string        // the error message of the rejection or the eviction
+	Time    time.Time
+}
+
+// The rejections and the evictions are counted by reason, as mempool/tx/rejected/<reason> and
+// mempool/tx/evicted/<reason>
+var (
+	txAcceptedCounter = metrics.NewRegisteredCounter("mempool/tx/accepted", nil)
+	txExpiredCounter  = metrics.NewRegisteredCounter("mempool/tx/expired", nil)
+	txPromotedCounter = metrics.NewRegisteredCounter("mempool/tx/promoted", nil)
+)
+
+// AddTxEventListener registers a function called with each transaction event. It is called with the Mempool
+// locked, so it must not block. Must be called before the Mempool starts.
+func (mp *Mempool) AddTxEventListener(listener func(event *TxEvent)) {
+	mp.txEventListeners = append(mp.txEventListeners, listener)
+}
+
+// emitTxEvent counts the event in the metrics, and passes it to the listeners
+func (mp *Mempool) emitTxEvent(rawTx common.Bytes, typ TxEventType, reason TxEventReason, message string) {
+	switch typ {
+	case TxEventAccepted:
+		txAcceptedCounter.Inc(1)
+	case TxEventExpired:
+		txExpiredCounter.Inc(1)
+	case TxEventPromoted:
+		txPromotedCounter.Inc(1)
+	default:
+		metrics.GetOrRegisterCounter("mempool/tx/"+string(typ)+"/"+string(reason), nil).Inc(1)
+	}
+
+	if len(mp.txEventListeners) == 0 {
+		return
+	}
+	event := &TxEvent{
+		Type:    typ,
+		Hash:    crypto.Keccak256Hash(rawTx),
+		Reason:  reason,
+		Message: message,
+		Time:    time.Now(),
+	}
+	for _, listener := range mp.txEventListeners {
+		listener(event)
+	}
+}
+
+// emitAdmission emits the event of the admission decision on an incoming transaction
+func (mp *Mempool) emitAdmission(rawTx common.Bytes, err error) {
+	if err == nil {
+		mp.emitTxEvent(rawTx, TxEventAccepted, "", "")
+		return
+	}
+	mp.emitTxEvent(rawTx, TxEventRejected, txEventReasonOf(err), err.Error())
+}
+
+// txEventReasonOf classifies the error of an admission decision
+func txEventReasonOf(err error) TxEventReason {
+	switch err {
+	case DuplicateTxError:
+		return TxReasonDuplicate
+	case MempoolFullError:
+		return TxReasonMempoolFull
+	case FastsyncSkipTxError:
+		return TxReasonSyncing
+	case ScheduledTxPoolFullError:
+		return TxReasonScheduledPoolFull
+	}
+	if screeningErr, ok := err.(*TxScreeningError); ok {
+		return txEventReasonOfCode(screeningErr.Code)
+	}
+	return TxReasonInvalid
+}
+
+// txEventReasonOfCode classifies the error code of a transaction screening
+func txEventReasonOfCode(code result.ErrorCode) TxEventReason {
+	switch code {
+	case result.CodeInvalidSequence:
+		return TxReasonBadSequence
+	case result.CodeInvalidFee, result.CodeInvalidGasPrice:
+		return TxReasonLowFee
+	case result.CodeInsufficientFund, result.CodeNotEnoughBalanceToStake:
+		return TxReasonInsufficientFund
+	case result.CodeInvalidSignature:
+		return TxReasonInvalidSignature
+	case result.CodeBlockedAddress:
+		return TxReasonBlockedAddress
+	default:
+		return TxReasonInvalid
+	}
+}
@@ -0,0 +1,61 @@
+package mempool
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/membudget"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+)
+
+func TestTxEvents(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	mp := CreateMempool(nil, nil)
+	mp.memory = membudget.NewAllocation(membudget.Mempool, 10)
+	events := []*TxEvent{}
+	mp.AddTxEventListener(func(event *TxEvent) {
+		events = append(events, event)
+	})
+
+	tx1 := common.Bytes(bytes.Repeat([]byte{1}, 20))
+	tx2 := common.Bytes(bytes.Repeat([]byte{2}, 20))
+	mp.addCandidateTransaction(tx1, &core.TxInfo{Address: alice, Sequence: 1, EffectiveGasPrice: big.NewInt(1)})
+
+	// The rejections carry their reasons
+	assert.Equal(DuplicateTxError, mp.InsertTransaction(tx1))
+	assert.Equal(MempoolFullError, mp.InsertTransaction(tx2))
+	require.Equal(2, len(events))
+	assert.Equal(TxEventRejected, events[0].Type)
+	assert.Equal(TxReasonDuplicate, events[0].Reason)
+	assert.Equal(crypto.Keccak256Hash(tx1), events[0].Hash)
+	assert.Equal(TxReasonMempoolFull, events[1].Reason)
+	assert.Equal(MempoolFullError.Error(), events[1].Message)
+
+	// The committed transactions seen by the mempool are promoted
+	mp.recordInclusionTimes([]common.Bytes{tx1, tx2})
+	require.Equal(3, len(events))
+	assert.Equal(TxEventPromoted, events[2].Type)
+	assert.Equal(crypto.Keccak256Hash(tx1), events[2].Hash)
+}
+
+func TestTxEventReasons(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(TxReasonSyncing, txEventReasonOf(FastsyncSkipTxError))
+	assert.Equal(TxReasonScheduledPoolFull, txEventReasonOf(ScheduledTxPoolFullError))
+	assert.Equal(TxReasonBadSequence, txEventReasonOf(&TxScreeningError{Code: result.CodeInvalidSequence}))
+	assert.Equal(TxReasonLowFee, txEventReasonOf(&TxScreeningError{Code: result.CodeInvalidGasPrice}))
+	assert.Equal(TxReasonInsufficientFund, txEventReasonOf(&TxScreeningError{Code: result.CodeInsufficientFund}))
+	assert.Equal(TxReasonInvalid, txEventReasonOf(&TxScreeningError{Code: result.CodeEVMError}))
+}
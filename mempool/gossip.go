@@ -0,0 +1,58 @@
+package mempool
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
+)
+
+var (
+	txRelayTimer          = metrics.NewRegisteredTimer("mempool/tx/relay", nil)     // time from receiving a peer tx to relaying it
+	txInclusionTimer      = metrics.NewRegisteredTimer("mempool/tx/inclusion", nil) // time from first seeing a tx to its commit
+	txRelayedCounter      = metrics.NewRegisteredCounter("mempool/tx/relayed", nil)
+	txRelaySkippedCounter = metrics.NewRegisteredCounter("mempool/tx/relaySkipped", nil)
+)
+
+// RelayTx relays the transaction received from a peer according to the tx gossip policy,
+// i.e. whether to relay peer transactions and the random propagation delay.
+func (mp *Mempool) RelayTx(rawTx common.Bytes, receivedAt time.Time) {
+	if !viper.GetBool(common.CfgMempoolRelayPeerTxs) {
+		txRelaySkippedCounter.Inc(1)
+		return
+	}
+
+	maxDelay := viper.GetInt64(common.CfgMempoolTxGossipMaxDelayMillis)
+	if maxDelay <= 0 {
+		mp.relayTx(rawTx, receivedAt)
+		return
+	}
+
+	// The random delay makes it harder to infer the origin of a transaction from its
+	// propagation timing, and spreads out the relay work
+	delay := time.Duration(rand.Int63n(maxDelay)) * time.Millisecond
+	time.AfterFunc(delay, func() {
+		mp.relayTx(rawTx, receivedAt)
+	})
+}
+
+func (mp *Mempool) relayTx(rawTx common.Bytes, receivedAt time.Time) {
+	mp.BroadcastTx(rawTx)
+	txRelayTimer.UpdateSince(receivedAt)
+	txRelayedCounter.Inc(1)
+}
+
+// recordInclusionTimes records the time between the mempool first seeing the committed
+// transactions and their commit.
+func (mp *Mempool) recordInclusionTimes(committedRawTxs []common.Bytes) {
+	for _, rawTx := range committedRawTxs {
+		createdAt, ok := mp.txBookeepper.getCreatedAt(getTransactionHash(rawTx))
+		if ok {
+			txInclusionTimer.UpdateSince(createdAt)
+			mp.emitTxEvent(rawTx, TxEventPromoted, "", "")
+		}
+	}
+}
@@ -4,20 +4,26 @@ import (
 	"context"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"math/big"
+	"sort"
 	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/clist"
 	"github.com/thetatoken/theta/common/math"
+	"github.com/thetatoken/theta/common/membudget"
 	"github.com/thetatoken/theta/common/pqueue"
 	"github.com/thetatoken/theta/common/result"
 	"github.com/thetatoken/theta/consensus"
 	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
 	dp "github.com/thetatoken/theta/dispatcher"
+	"github.com/thetatoken/theta/ledger/types"
 )
 
 var logger *log.Entry = log.WithFields(log.Fields{"prefix": "mempool"})
@@ -28,8 +34,20 @@ func (m MempoolError) Error() string {
 	return string(m)
 }
 
+// TxScreeningError is returned when a transaction fails the screening, it carries the error code of the screening result
+type TxScreeningError struct {
+	Code    result.ErrorCode
+	Message string
+}
+
+func (e *TxScreeningError) Error() string {
+	return e.Message
+}
+
 const DuplicateTxError = MempoolError("Transaction already seen")
 const FastsyncSkipTxError = MempoolError("Skip tx during fastsync")
+const ScheduledTxPoolFullError = MempoolError("Too many scheduled transactions")
+const MempoolFullError = MempoolError("Mempool is full, please submit the transaction again later")
 
 const MaxMempoolTxCount int = 25600
 
@@ -40,6 +58,7 @@ type mempoolTransaction struct {
 	index          int
 	rawTransaction common.Bytes
 	txInfo         *core.TxInfo
+	arrivedAt      time.Time
 }
 
 var _ pqueue.Element = (*mempoolTransaction)(nil)
@@ -61,6 +80,7 @@ func createMempoolTransaction(rawTransaction common.Bytes, txInfo *core.TxInfo)
 	return &mempoolTransaction{
 		rawTransaction: rawTransaction,
 		txInfo:         txInfo,
+		arrivedAt:      time.Now(),
 	}
 }
 
@@ -105,8 +125,8 @@ func (mtg *mempoolTransactionGroup) IsEmpty() bool {
 	return mtg.txs.IsEmpty()
 }
 
-// RemoveTxs removes matching Txs from transaction group. Returns number of Txs removed.
-func (mtg *mempoolTransactionGroup) RemoveTxs(committedRawTxMap map[string]bool) (numRemoved int) {
+// RemoveTxs removes matching Txs from transaction group. Returns number and total size of Txs removed.
+func (mtg *mempoolTransactionGroup) RemoveTxs(committedRawTxMap map[string]bool) (numRemoved int, bytesRemoved int64) {
 	elementList := mtg.txs.ElementList()
 	elemsTobeRemoved := []pqueue.Element{}
 	for _, elem := range *elementList {
@@ -120,6 +140,7 @@ func (mtg *mempoolTransactionGroup) RemoveTxs(committedRawTxMap map[string]bool)
 	for _, elem := range elemsTobeRemoved {
 		mtg.txs.Remove(elem.GetIndex())
 		numRemoved++
+		bytesRemoved += int64(len(elem.(*mempoolTransaction).rawTransaction))
 	}
 	return
 }
@@ -149,6 +170,13 @@ type Mempool struct {
 	txBookeepper     transactionBookkeeper
 	addressToTxGroup map[common.Address]*mempoolTransactionGroup
 	size             int
+	scheduledTxs     *scheduledTxPool // scheduled transactions not yet eligible for inclusion
+	drainedAt        time.Time        // last time a candidate transaction left the pool, or the pool became non-empty
+	blocklist        *Blocklist       // nil if no blocklist is configured
+	memory           *membudget.Allocation
+	txBytes          int64 // total size of the candidate transactions
+	newTxListeners   []func(rawTx common.Bytes)
+	txEventListeners []func(event *TxEvent)
 
 	// Life cycle
 	wg      *sync.WaitGroup
@@ -160,6 +188,10 @@ type Mempool struct {
 
 // CreateMempool creates an instance of Mempool
 func CreateMempool(dispatcher *dp.Dispatcher, engine *consensus.ConsensusEngine) *Mempool {
+	blocklist, err := newBlocklistFromConfig()
+	if err != nil {
+		logger.Fatalf("Failed to load the mempool blocklist: %v", err)
+	}
 	return &Mempool{
 		mutex:            &sync.Mutex{},
 		consensus:        engine,
@@ -168,6 +200,9 @@ func CreateMempool(dispatcher *dp.Dispatcher, engine *consensus.ConsensusEngine)
 		candidateTxs:     pqueue.CreatePriorityQueue(),
 		addressToTxGroup: make(map[common.Address]*mempoolTransactionGroup),
 		txBookeepper:     createTransactionBookkeeper(defaultMaxNumTxs),
+		scheduledTxs:     newScheduledTxPool(),
+		blocklist:        blocklist,
+		memory:           membudget.Get(membudget.Mempool),
 		wg:               &sync.WaitGroup{},
 	}
 }
@@ -177,21 +212,38 @@ func (mp *Mempool) SetLedger(ledger core.Ledger) {
 	mp.ledger = ledger
 }
 
+// AddNewTxListener registers a function called with each transaction which becomes a candidate for new block
+// assembly. It is called with the Mempool locked, so it must not block. Must be called before the Mempool starts.
+func (mp *Mempool) AddNewTxListener(listener func(rawTx common.Bytes)) {
+	mp.newTxListeners = append(mp.newTxListeners, listener)
+}
+
 // InsertTransaction inserts the incoming transaction to mempool (submitted by the clients or relayed from peers)
 func (mp *Mempool) InsertTransaction(rawTx common.Bytes) error {
 	mp.mutex.Lock()
 	defer mp.mutex.Unlock()
 
+	err := mp.insertTransaction(rawTx)
+	mp.emitAdmission(rawTx, err)
+	return err
+}
+
+func (mp *Mempool) insertTransaction(rawTx common.Bytes) error {
 	if mp.txBookeepper.hasSeen(rawTx) {
 		logger.Debugf("Transaction already seen: %v, hash: 0x%v",
 			hex.EncodeToString(rawTx), getTransactionHash(rawTx))
 		return DuplicateTxError
 	}
-
-	// if mp.size >= MaxMempoolTxCount {
-	// 	logger.Debugf("Mempool is full")
-	// 	return errors.New("mempool is full, please submit your transaction again later")
-	// }
+	if err := mp.checkBlocklist(rawTx); err != nil {
+		return err
+	}
+	if !mp.memory.Admit() {
+		logger.Debugf("Mempool is full, tx.hash: 0x%v", getTransactionHash(rawTx))
+		return MempoolFullError
+	}
+	if viper.GetBool(common.CfgStorageTxTimingIndexEnabled) {
+		mp.consensus.Chain().RecordFirstSeen(crypto.Keccak256Hash(rawTx), time.Now())
+	}
 
 	var txInfo *core.TxInfo
 	var checkTxRes result.Result
@@ -199,9 +251,12 @@ func (mp *Mempool) InsertTransaction(rawTx common.Bytes) error {
 	// Delay tx verification when in fast sync
 	if mp.consensus.HasSynced() {
 		txInfo, checkTxRes = mp.ledger.ScreenTx(rawTx)
+		if checkTxRes.Code == result.CodeTxNotYetEligible {
+			return mp.scheduleTransaction(rawTx, txInfo)
+		}
 		if !checkTxRes.IsOK() {
 			logger.Debugf("Transaction screening failed, tx: %v, error: %v", hex.EncodeToString(rawTx), checkTxRes.Message)
-			return errors.New(checkTxRes.Message)
+			return &TxScreeningError{Code: checkTxRes.Code, Message: checkTxRes.Message}
 		}
 
 		// only record the transactions that passed the screening. This is because that
@@ -209,20 +264,7 @@ func (mp *Mempool) InsertTransaction(rawTx common.Bytes) error {
 		// sequence for an account is 6. The account accidentally submits txA (seq = 7), got rejected.
 		// He then submit txB(seq = 6), and then txA(seq = 7) again. For the second submission, txA
 		// should not be rejected even though it has been submitted earlier.
-		mp.txBookeepper.record(rawTx)
-
-		txGroup, ok := mp.addressToTxGroup[txInfo.Address]
-		if ok {
-			txGroup.AddTx(rawTx, txInfo)
-			mp.candidateTxs.Remove(txGroup.index) // Need to re-insert txGroup into queue since its priority could change.
-		} else {
-			txGroup = createMempoolTransactionGroup(rawTx, txInfo)
-			mp.addressToTxGroup[txInfo.Address] = txGroup
-		}
-		mp.candidateTxs.Push(txGroup)
-		logger.Debugf("rawTx: %v, txInfo: %v", hex.EncodeToString(rawTx), txInfo)
-		logger.Infof("Insert tx, tx.hash: 0x%v", getTransactionHash(rawTx))
-		mp.size++
+		mp.addCandidateTransaction(rawTx, txInfo)
 
 		return nil
 	}
@@ -230,6 +272,108 @@ func (mp *Mempool) InsertTransaction(rawTx common.Bytes) error {
 	return FastsyncSkipTxError
 }
 
+// checkBlocklist rejects the transaction if it involves a blocked address. The undecodable transactions are
+// left to the screening.
+func (mp *Mempool) checkBlocklist(rawTx common.Bytes) error {
+	if mp.blocklist == nil {
+		return nil
+	}
+	tx, err := types.TxFromBytes(rawTx)
+	if err != nil {
+		return nil
+	}
+	address, blocked := mp.blocklist.Check(tx)
+	if !blocked {
+		return nil
+	}
+	mp.blocklist.recordRejection(crypto.Keccak256Hash(rawTx), address)
+	return &TxScreeningError{
+		Code:    result.CodeBlockedAddress,
+		Message: fmt.Sprintf("Transaction involves the blocked address %v", address.Hex()),
+	}
+}
+
+// BlockedAddress returns the first address involved in the given transaction which is on the blocklist, if any
+func (mp *Mempool) BlockedAddress(tx types.Tx) (common.Address, bool) {
+	if mp.blocklist == nil {
+		return common.Address{}, false
+	}
+	return mp.blocklist.Check(tx)
+}
+
+// addCandidateTransaction adds the screened transaction to the candidates for new block assembly
+func (mp *Mempool) addCandidateTransaction(rawTx common.Bytes, txInfo *core.TxInfo) {
+	mp.txBookeepper.record(rawTx)
+
+	txGroup, ok := mp.addressToTxGroup[txInfo.Address]
+	if ok {
+		txGroup.AddTx(rawTx, txInfo)
+		mp.candidateTxs.Remove(txGroup.index) // Need to re-insert txGroup into queue since its priority could change.
+	} else {
+		txGroup = createMempoolTransactionGroup(rawTx, txInfo)
+		mp.addressToTxGroup[txInfo.Address] = txGroup
+	}
+	mp.candidateTxs.Push(txGroup)
+	logger.Debugf("rawTx: %v, txInfo: %v", hex.EncodeToString(rawTx), txInfo)
+	logger.Infof("Insert tx, tx.hash: 0x%v", getTransactionHash(rawTx))
+	if mp.size == 0 {
+		mp.drainedAt = time.Now()
+	}
+	mp.size++
+	mp.trackTxBytes(int64(len(rawTx)))
+
+	for _, listener := range mp.newTxListeners {
+		listener(rawTx)
+	}
+}
+
+// trackTxBytes updates the total size of the candidate transactions, accounted in the memory budget
+func (mp *Mempool) trackTxBytes(delta int64) {
+	mp.txBytes += delta
+	mp.memory.Reserve(delta)
+}
+
+// scheduleTransaction holds the scheduled transaction until it becomes eligible for inclusion
+func (mp *Mempool) scheduleTransaction(rawTx common.Bytes, txInfo *core.TxInfo) error {
+	tx, err := types.TxFromBytes(rawTx)
+	if err != nil {
+		return err
+	}
+	scheduledTx, ok := tx.(*types.ScheduledTx)
+	if !ok {
+		return errors.New("not a scheduled transaction")
+	}
+	if txInfo == nil {
+		return errors.New("unknown sender of the scheduled transaction")
+	}
+	if err := mp.scheduledTxs.add(rawTx, txInfo.Address, scheduledTx.NotBefore); err != nil {
+		return err
+	}
+	logger.Infof("Schedule tx at height %v, tx.hash: 0x%v", scheduledTx.NotBefore, getTransactionHash(rawTx))
+	return nil
+}
+
+// promoteScheduledTxs moves the scheduled transactions which have become eligible to the candidates
+func (mp *Mempool) promoteScheduledTxs() {
+	for _, height := range mp.scheduledTxs.heights() {
+		txs := mp.scheduledTxs.txs[height]
+		if _, res := mp.ledger.ScreenTx(txs[0].rawTx); res.Code == result.CodeTxNotYetEligible {
+			return // neither are the transactions scheduled at the greater heights
+		}
+		for _, rawTx := range mp.scheduledTxs.remove(height) {
+			txInfo, res := mp.ledger.ScreenTx(rawTx)
+			if res.IsError() {
+				// e.g. the sequence was used up, or the balance spent, while the transaction was waiting
+				logger.Warnf("Dropped scheduled tx at height %v, tx.hash: 0x%v, error: %v",
+					height, getTransactionHash(rawTx), res.Message)
+				mp.emitTxEvent(rawTx, TxEventEvicted, txEventReasonOfCode(res.Code), res.Message)
+				continue
+			}
+			mp.addCandidateTransaction(rawTx, txInfo)
+		}
+	}
+}
+
 // Start needs to be called when the Mempool starts
 func (mp *Mempool) Start(ctx context.Context) error {
 	c, cancel := context.WithCancel(ctx)
@@ -264,6 +408,14 @@ func (mp *Mempool) Size() int {
 	return mp.size
 }
 
+// DrainedAt returns the last time a transaction left the Mempool, or the Mempool became non-empty.
+// A non-empty Mempool which has not drained for long is likely wedged.
+func (mp *Mempool) DrainedAt() time.Time {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	return mp.drainedAt
+}
+
 // Reap returns a list of valid raw transactions and remove these
 // transactions from the candidate pool. maxNumTxs == 0 means
 // none, maxNumTxs < 0 means uncapped. Note that Reap does NOT remove
@@ -295,6 +447,7 @@ func (mp *Mempool) ReapUnsafe(maxNumTxs int) []common.Bytes {
 		}
 		txGroup := mp.candidateTxs.Pop().(*mempoolTransactionGroup)
 		rawTx, txInfo := txGroup.PopTx()
+		mp.trackTxBytes(-int64(len(rawTx)))
 
 		// Check for outdated txs
 		txHash := getTransactionHash(rawTx)
@@ -302,6 +455,8 @@ func (mp *Mempool) ReapUnsafe(maxNumTxs int) []common.Bytes {
 		if exists {
 			// Only add back Txs that has not been removed from bookkeeper due to timeout
 			txs = append(txs, rawTx)
+		} else {
+			mp.emitTxEvent(rawTx, TxEventExpired, "", "")
 		}
 
 		if txGroup.IsEmpty() {
@@ -331,6 +486,8 @@ func (mp *Mempool) Update(committedRawTxs []common.Bytes) {
 // UpdateUnsafe is the non-locking version of Update. Caller must call Mempool.Lock() before
 // calling this method.
 func (mp *Mempool) UpdateUnsafe(committedRawTxs []common.Bytes) {
+	mp.recordInclusionTimes(committedRawTxs)
+
 	start := time.Now()
 	mp.removeTxs(committedRawTxs)
 	removeCommittedTxTime := time.Since(start)
@@ -354,6 +511,7 @@ func (mp *Mempool) UpdateUnsafe(committedRawTxs []common.Bytes) {
 			if !exists {
 				// Tx has been removed from bookkeeper due to timeout
 				invalidTxs = append(invalidTxs, mempoolTx.rawTransaction)
+				mp.emitTxEvent(mempoolTx.rawTransaction, TxEventExpired, "", "")
 				continue
 			}
 
@@ -361,6 +519,7 @@ func (mp *Mempool) UpdateUnsafe(committedRawTxs []common.Bytes) {
 			if !checkTxRes.IsOK() {
 				invalidTxs = append(invalidTxs, mempoolTx.rawTransaction)
 				mp.txBookeepper.markAbandoned(mempoolTx.rawTransaction)
+				mp.emitTxEvent(mempoolTx.rawTransaction, TxEventEvicted, txEventReasonOfCode(checkTxRes.Code), checkTxRes.Message)
 			}
 		}
 	}
@@ -370,6 +529,8 @@ func (mp *Mempool) UpdateUnsafe(committedRawTxs []common.Bytes) {
 	mp.removeTxs(invalidTxs)
 	removeInvalidTxTime := time.Since(start)
 
+	mp.promoteScheduledTxs()
+
 	logger.Debugf("UpdateUnsafe: %d tx screened in %v, removeCommittedTxTime = %v, removed %d obsolete Txs in %v: %v,", count, screenTxTime, removeCommittedTxTime, len(invalidTxs), removeInvalidTxTime, invalidTxs)
 }
 
@@ -383,8 +544,12 @@ func (mp *Mempool) removeTxs(committedRawTxs []common.Bytes) {
 	elemsTobeRemoved := []pqueue.Element{}
 	for _, elem := range *elementList {
 		txGroup := elem.(*mempoolTransactionGroup)
-		numRemoved := txGroup.RemoveTxs(committedRawTxMap)
+		numRemoved, bytesRemoved := txGroup.RemoveTxs(committedRawTxMap)
 		mp.size -= numRemoved
+		mp.trackTxBytes(-bytesRemoved)
+		if numRemoved > 0 {
+			mp.drainedAt = time.Now()
+		}
 		if txGroup.IsEmpty() {
 			delete(mp.addressToTxGroup, txGroup.address)
 			elemsTobeRemoved = append(elemsTobeRemoved, txGroup)
@@ -423,17 +588,84 @@ func (mp *Mempool) GetCandidateTransactionHashes() []string {
 	return txHashes
 }
 
+// GetTransactionsByHashes returns the candidate transactions with the given hashes. The entries
+// of the transactions not found in the Mempool are nil.
+func (mp *Mempool) GetTransactionsByHashes(hashes []common.Hash) []common.Bytes {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	hashToIndex := make(map[common.Hash]int, len(hashes))
+	for idx, hash := range hashes {
+		hashToIndex[hash] = idx
+	}
+
+	txs := make([]common.Bytes, len(hashes))
+	txgElemList := mp.candidateTxs.ElementList()
+	for _, txgElem := range *txgElemList {
+		txg := txgElem.(*mempoolTransactionGroup)
+		txElemList := txg.txs.ElementList()
+		for _, txElem := range *txElemList {
+			rawTx := txElem.(*mempoolTransaction).rawTransaction
+			if idx, ok := hashToIndex[crypto.Keccak256Hash(rawTx)]; ok {
+				txs[idx] = rawTx
+			}
+		}
+	}
+
+	return txs
+}
+
+// PendingTransaction describes a transaction from a given sender that is still in the Mempool
+type PendingTransaction struct {
+	Hash              string
+	RawTransaction    common.Bytes
+	Sequence          uint64
+	EffectiveGasPrice *big.Int
+}
+
+// GetPendingTransactionsByAddress returns the candidate transactions sent by the given
+// address, sorted by sequence number (low to high)
+func (mp *Mempool) GetPendingTransactionsByAddress(address common.Address) []*PendingTransaction {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	pendingTxs := []*PendingTransaction{}
+	txGroup, ok := mp.addressToTxGroup[address]
+	if !ok {
+		return pendingTxs
+	}
+
+	txElemList := txGroup.txs.ElementList()
+	for _, txElem := range *txElemList {
+		tx := txElem.(*mempoolTransaction)
+		pendingTxs = append(pendingTxs, &PendingTransaction{
+			Hash:              "0x" + getTransactionHash(tx.rawTransaction),
+			RawTransaction:    tx.rawTransaction,
+			Sequence:          tx.txInfo.Sequence,
+			EffectiveGasPrice: tx.txInfo.EffectiveGasPrice,
+		})
+	}
+
+	sort.Slice(pendingTxs, func(i, j int) bool {
+		return pendingTxs[i].Sequence < pendingTxs[j].Sequence
+	})
+
+	return pendingTxs
+}
+
 // Flush removes all transactions from the Mempool and the transactionBookkeeper
 func (mp *Mempool) Flush() {
 	mp.mutex.Lock()
 	defer mp.mutex.Unlock()
 
 	mp.txBookeepper.reset()
+	mp.scheduledTxs.reset()
 
 	for !mp.candidateTxs.IsEmpty() {
 		mp.candidateTxs.Pop()
 	}
 	mp.size = 0
+	mp.trackTxBytes(-mp.txBytes)
 }
 
 // BroadcastTx broadcast given raw transaction to the network
@@ -451,6 +683,12 @@ func (mp *Mempool) BroadcastTxUnsafe(tx common.Bytes) {
 		Payload:   tx,
 	}
 
+	fanout := viper.GetInt(common.CfgMempoolTxGossipFanout)
+	if fanout > 0 {
+		mp.dispatcher.SendDataToNeighbors(data, fanout, true)
+		return
+	}
+
 	peerIDs := []string{}
 	mp.dispatcher.SendData(peerIDs, data)
 }
@@ -0,0 +1,45 @@
+package mempool
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/membudget"
+	"github.com/thetatoken/theta/core"
+)
+
+func TestMempoolMemoryBudget(t *testing.T) {
+	assert := assert.New(t)
+
+	alice := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	mp := CreateMempool(nil, nil)
+	mp.memory = membudget.NewAllocation(membudget.Mempool, 100)
+
+	tx1 := common.Bytes(bytes.Repeat([]byte{1}, 60))
+	tx2 := common.Bytes(bytes.Repeat([]byte{2}, 50))
+	tx3 := common.Bytes(bytes.Repeat([]byte{3}, 10))
+	mp.addCandidateTransaction(tx1, &core.TxInfo{Address: alice, Sequence: 1, EffectiveGasPrice: big.NewInt(1)})
+	assert.Equal(int64(60), mp.memory.Used())
+	mp.addCandidateTransaction(tx2, &core.TxInfo{Address: bob, Sequence: 1, EffectiveGasPrice: big.NewInt(2)})
+	assert.Equal(int64(110), mp.memory.Used())
+
+	// Over the budget, the new transactions are turned away
+	assert.Equal(MempoolFullError, mp.InsertTransaction(tx3))
+
+	mp.removeTxs([]common.Bytes{tx2})
+	assert.Equal(int64(60), mp.memory.Used())
+	assert.Equal(1, len(mp.ReapUnsafe(-1)))
+	assert.Equal(int64(0), mp.memory.Used())
+
+	mp.addCandidateTransaction(tx3, &core.TxInfo{Address: alice, Sequence: 2, EffectiveGasPrice: big.NewInt(1)})
+	assert.Equal(int64(10), mp.memory.Used())
+	mp.Flush()
+	assert.Equal(int64(0), mp.memory.Used())
+	assert.Equal(0, mp.Size())
+}
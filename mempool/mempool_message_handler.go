@@ -3,6 +3,7 @@ package mempool
 import (
 	"encoding/hex"
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -50,7 +51,9 @@ func Fuzz(data []byte) int {
 // ParseMessage implements the p2p.MessageHandler interface
 func (mmh *MempoolMessageHandler) ParseMessage(peerID string, channelID common.ChannelIDEnum, rawMessageBytes common.Bytes) (types.Message, error) {
 	var dataResponse dp.DataResponse
-	rlp.DecodeBytes(rawMessageBytes, &dataResponse)
+	if err := rlp.DecodeBytes(rawMessageBytes, &dataResponse); err != nil {
+		return types.Message{}, err
+	}
 
 	rawTx := dataResponse.Payload
 	message := types.Message{
@@ -66,6 +69,7 @@ func (mmh *MempoolMessageHandler) HandleMessage(message types.Message) error {
 	if message.ChannelID != common.ChannelIDTransaction {
 		return fmt.Errorf("Invalid channel for MempoolMessageHandler: %v", message.ChannelID)
 	}
+	receivedAt := time.Now()
 	rawTx := message.Content.(common.Bytes)
 	logger.Debugf("Received gossiped transaction: %v", hex.EncodeToString(rawTx))
 
@@ -81,7 +85,7 @@ func (mmh *MempoolMessageHandler) HandleMessage(message types.Message) error {
 	// nodes.
 	p2pOpt := common.P2POptEnum(viper.GetInt(common.CfgP2POpt))
 	if p2pOpt != common.P2POptLibp2p {
-		mmh.mempool.BroadcastTx(rawTx)
+		mmh.mempool.RelayTx(rawTx, receivedAt)
 	}
 
 	return nil
@@ -2,6 +2,7 @@ package mempool
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"strconv"
 	"sync"
@@ -197,6 +198,44 @@ func TestMempoolReapOrder(t *testing.T) {
 	assert.Equal("tx3", string(reapedRawTxs[9][:]))  // gasPrice: 32, address: A3, seq: 2012
 }
 
+func TestMempoolGetPendingTransactionsByAddress(t *testing.T) {
+	assert := assert.New(t)
+
+	p2psimnet := p2psim.NewSimnetWithHandler(nil)
+	mempool, _ := newTestMempool("peer0", p2psimnet)
+
+	tx1 := createTestRawTx("tx1")
+	tx2 := createTestRawTx("tx2")
+	tx3 := createTestRawTx("tx3")
+	tx4 := createTestRawTx("tx4")
+
+	mempool.InsertTransaction(tx1)
+	mempool.InsertTransaction(tx2)
+	mempool.InsertTransaction(tx3)
+	mempool.InsertTransaction(tx4)
+
+	// tx1 and tx4 are both sent from A1, and should be sorted by sequence number
+	pendingTxs := mempool.GetPendingTransactionsByAddress(common.HexToAddress("A1"))
+	assert.Equal(2, len(pendingTxs))
+	assert.Equal("tx4", string(pendingTxs[0].RawTransaction)) // seq: 1000
+	assert.Equal("tx1", string(pendingTxs[1].RawTransaction)) // seq: 1023
+	assert.Equal(uint64(1000), pendingTxs[0].Sequence)
+	assert.Equal(uint64(1023), pendingTxs[1].Sequence)
+	assert.Equal("0x"+getTransactionHash(tx4), pendingTxs[0].Hash)
+
+	pendingTxs = mempool.GetPendingTransactionsByAddress(common.HexToAddress("A2"))
+	assert.Equal(1, len(pendingTxs))
+	assert.Equal("tx2", string(pendingTxs[0].RawTransaction))
+
+	pendingTxs = mempool.GetPendingTransactionsByAddress(common.HexToAddress("B1"))
+	assert.Equal(0, len(pendingTxs))
+
+	// Reaped transactions are no longer pending in the mempool
+	mempool.Reap(-1)
+	pendingTxs = mempool.GetPendingTransactionsByAddress(common.HexToAddress("A1"))
+	assert.Equal(0, len(pendingTxs))
+}
+
 func TestMempoolUpdate(t *testing.T) {
 	assert := assert.New(t)
 
@@ -543,3 +582,49 @@ func (tnmi *TestNetworkMessageInterceptor) HandleMessage(msg p2ptypes.Message) e
 	tnmi.ReceivedMessages <- msg
 	return nil
 }
+
+func TestScheduledTxPool(t *testing.T) {
+	assert := assert.New(t)
+
+	sp := newScheduledTxPool()
+	sender := common.HexToAddress("0x2E833968E5bB786Ae419c4d13189fB081Cc43bab")
+	tx1 := common.Bytes("tx1")
+	tx2 := common.Bytes("tx2")
+	tx3 := common.Bytes("tx3")
+
+	assert.Nil(sp.add(tx1, sender, 200))
+	assert.Nil(sp.add(tx2, sender, 100))
+	assert.Nil(sp.add(tx3, sender, 200))
+	assert.Equal(DuplicateTxError, sp.add(tx1, sender, 200))
+	assert.Equal(3, sp.size)
+	assert.Equal([]uint64{100, 200}, sp.heights())
+
+	assert.Equal([]common.Bytes{tx2}, sp.remove(100))
+	assert.Equal([]common.Bytes{tx1, tx3}, sp.remove(200))
+	assert.Equal(0, sp.size)
+	assert.Empty(sp.heights())
+	assert.Empty(sp.bySender)
+
+	// A removed tx can be scheduled again
+	assert.Nil(sp.add(tx1, sender, 300))
+}
+
+func TestScheduledTxPoolSenderCap(t *testing.T) {
+	assert := assert.New(t)
+
+	sp := newScheduledTxPool()
+	spammer := common.HexToAddress("0x0000000000000000000000000000000000000bad")
+	sender := common.HexToAddress("0x2E833968E5bB786Ae419c4d13189fB081Cc43bab")
+
+	for i := 0; i < maxNumScheduledTxsPerSender; i++ {
+		assert.Nil(sp.add(common.Bytes(fmt.Sprintf("spam%v", i)), spammer, 100))
+	}
+	assert.Equal(ScheduledTxPoolFullError, sp.add(common.Bytes("one more"), spammer, 200))
+
+	// The other senders can still schedule their transactions
+	assert.Nil(sp.add(common.Bytes("tx"), sender, 200))
+
+	// The spammer can schedule again once its transactions leave the pool
+	sp.remove(100)
+	assert.Nil(sp.add(common.Bytes("one more"), spammer, 200))
+}
@@ -0,0 +1,90 @@
+package mempool
+
+import (
+	"sort"
+
+	"github.com/thetatoken/theta/common"
+)
+
+const (
+	// maxNumScheduledTxs caps the number of the scheduled transactions waiting in the mempool
+	maxNumScheduledTxs = 4096
+
+	// maxNumScheduledTxsPerSender caps the number of the scheduled transactions of each sender, so that a
+	// single sender cannot fill up the pool
+	maxNumScheduledTxsPerSender = 64
+)
+
+//
+// scheduledTxPool holds the scheduled transactions until they become eligible for inclusion. Only the
+// signatures and the fee of these transactions are checked before they become eligible, since the rest of
+// their validity, e.g. the sequence, depends on the state at the scheduled height.
+//
+type scheduledTxPool struct {
+	txs      map[uint64][]*scheduledTx // not-before height -> transactions
+	seen     map[string]bool
+	bySender map[common.Address]int // sender -> number of scheduled transactions
+	size     int
+}
+
+type scheduledTx struct {
+	rawTx  common.Bytes
+	sender common.Address
+}
+
+func newScheduledTxPool() *scheduledTxPool {
+	return &scheduledTxPool{
+		txs:      make(map[uint64][]*scheduledTx),
+		seen:     make(map[string]bool),
+		bySender: make(map[common.Address]int),
+	}
+}
+
+// add adds the transaction of the sender scheduled at the given height
+func (sp *scheduledTxPool) add(rawTx common.Bytes, sender common.Address, notBefore uint64) error {
+	if sp.seen[string(rawTx)] {
+		return DuplicateTxError
+	}
+	if sp.size >= maxNumScheduledTxs || sp.bySender[sender] >= maxNumScheduledTxsPerSender {
+		return ScheduledTxPoolFullError
+	}
+	sp.txs[notBefore] = append(sp.txs[notBefore], &scheduledTx{rawTx: rawTx, sender: sender})
+	sp.seen[string(rawTx)] = true
+	sp.bySender[sender]++
+	sp.size++
+	return nil
+}
+
+// heights returns the scheduled heights in ascending order
+func (sp *scheduledTxPool) heights() []uint64 {
+	heights := make([]uint64, 0, len(sp.txs))
+	for height := range sp.txs {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights
+}
+
+// remove removes the transactions scheduled at the given height
+func (sp *scheduledTxPool) remove(notBefore uint64) []common.Bytes {
+	txs := sp.txs[notBefore]
+	rawTxs := make([]common.Bytes, 0, len(txs))
+	for _, tx := range txs {
+		delete(sp.seen, string(tx.rawTx))
+		sp.bySender[tx.sender]--
+		if sp.bySender[tx.sender] <= 0 {
+			delete(sp.bySender, tx.sender)
+		}
+		rawTxs = append(rawTxs, tx.rawTx)
+	}
+	delete(sp.txs, notBefore)
+	sp.size -= len(txs)
+	return rawTxs
+}
+
+func (sp *scheduledTxPool) reset() {
+	sp.txs = make(map[uint64][]*scheduledTx)
+	sp.seen = make(map[string]bool)
+	sp.bySender = make(map[common.Address]int)
+	sp.size = 0
+}
@@ -86,6 +86,18 @@ func (tb *transactionBookkeeper) getStatus(txhash string) (TxStatus, bool) {
 	return txRecord.Status, true
 }
 
+// getCreatedAt returns the time when the tx was first recorded and a boolean of whether the tx is known.
+func (tb *transactionBookkeeper) getCreatedAt(txhash string) (time.Time, bool) {
+	tb.mutex.Lock()
+	defer tb.mutex.Unlock()
+
+	txRecord, exists := tb.txMap[txhash]
+	if !exists {
+		return time.Time{}, false
+	}
+	return txRecord.CreatedAt, true
+}
+
 func (tb *transactionBookkeeper) removeOutdatedTxsUnsafe() {
 	// Loop and remove all outdated Tx records
 	for {
@@ -2,6 +2,7 @@ package mempool
 
 import (
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/thetatoken/theta/common"
@@ -60,3 +61,21 @@ func createTestRawTx(rawTxStr string) common.Bytes {
 	rawTx := common.Bytes(rawTxStr)
 	return rawTx
 }
+
+func TestTxBookkeeperCreatedAt(t *testing.T) {
+	assert := assert.New(t)
+
+	tx1 := createTestRawTx("1")
+	tx2 := createTestRawTx("2")
+
+	txb := createTransactionBookkeeper(defaultMaxNumTxs)
+	before := time.Now()
+	assert.True(txb.record(tx1))
+
+	createdAt, ok := txb.getCreatedAt(getTransactionHash(tx1))
+	assert.True(ok)
+	assert.False(createdAt.Before(before))
+
+	_, ok = txb.getCreatedAt(getTransactionHash(tx2))
+	assert.False(ok)
+}
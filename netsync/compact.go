@@ -0,0 +1,168 @@
+package netsync
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/dispatcher"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rlp"
+)
+
+// TxPool is the source of the transactions used to reconstruct compact blocks, i.e. the mempool.
+type TxPool interface {
+	// GetTransactionsByHashes returns the raw transactions with the given hashes. Entries of
+	// the transactions not found in the pool are nil.
+	GetTransactionsByHashes(hashes []common.Hash) []common.Bytes
+}
+
+// CompactBlock carries a block header and the hashes of its transactions. Receivers rebuild
+// the block from the transactions in their mempool instead of downloading the full block.
+type CompactBlock struct {
+	Header       *core.BlockHeader
+	TxHashes     []common.Hash
+	PrefilledTxs []PrefilledTx // Txs that are never relayed through the mempool, e.g. the coinbase tx
+}
+
+// PrefilledTx is a transaction included in a compact block at the given index.
+type PrefilledTx struct {
+	Index uint64
+	Tx    common.Bytes
+}
+
+// NewCompactBlock creates the compact representation of the given block.
+func NewCompactBlock(block *core.Block) *CompactBlock {
+	cb := &CompactBlock{
+		Header:       block.BlockHeader,
+		TxHashes:     make([]common.Hash, 0, len(block.Txs)),
+		PrefilledTxs: []PrefilledTx{},
+	}
+	for idx, rawTx := range block.Txs {
+		cb.TxHashes = append(cb.TxHashes, crypto.Keccak256Hash(rawTx))
+		if !isRelayedTx(rawTx) {
+			cb.PrefilledTxs = append(cb.PrefilledTxs, PrefilledTx{
+				Index: uint64(idx),
+				Tx:    rawTx,
+			})
+		}
+	}
+	return cb
+}
+
+// isRelayedTx returns whether the transaction is expected to be found in the mempool of peers.
+func isRelayedTx(rawTx common.Bytes) bool {
+	tx, err := types.TxFromBytes(rawTx)
+	if err != nil {
+		return false
+	}
+	switch tx.(type) {
+	case *types.CoinbaseTx, *types.SlashTx:
+		return false
+	}
+	return true
+}
+
+// Reconstruct rebuilds the full block from the prefilled transactions and the given pool.
+// Returns the hashes of the transactions missing from the pool if it cannot be rebuilt.
+func (cb *CompactBlock) Reconstruct(pool TxPool) (*core.Block, []common.Hash, error) {
+	if cb.Header == nil {
+		return nil, nil, fmt.Errorf("Compact block header is missing")
+	}
+
+	txs := make([]common.Bytes, len(cb.TxHashes))
+	for _, ptx := range cb.PrefilledTxs {
+		if ptx.Index >= uint64(len(txs)) {
+			return nil, nil, fmt.Errorf("Prefilled tx index %v out of range", ptx.Index)
+		}
+		if crypto.Keccak256Hash(ptx.Tx) != cb.TxHashes[ptx.Index] {
+			return nil, nil, fmt.Errorf("Prefilled tx at index %v does not match its hash", ptx.Index)
+		}
+		txs[ptx.Index] = ptx.Tx
+	}
+
+	toLookup := []common.Hash{}
+	lookupIndices := []int{}
+	for idx, txHash := range cb.TxHashes {
+		if txs[idx] == nil {
+			toLookup = append(toLookup, txHash)
+			lookupIndices = append(lookupIndices, idx)
+		}
+	}
+
+	missing := []common.Hash{}
+	if len(toLookup) > 0 {
+		if pool == nil {
+			return nil, toLookup, nil
+		}
+		found := pool.GetTransactionsByHashes(toLookup)
+		for i, rawTx := range found {
+			if rawTx == nil {
+				missing = append(missing, toLookup[i])
+				continue
+			}
+			txs[lookupIndices[i]] = rawTx
+		}
+	}
+	if len(missing) > 0 {
+		return nil, missing, nil
+	}
+
+	block := &core.Block{
+		BlockHeader: cb.Header,
+		Txs:         txs,
+	}
+	return block, nil, nil
+}
+
+// SetTxPool sets the transaction pool used to reconstruct compact blocks.
+func (sm *SyncManager) SetTxPool(pool TxPool) {
+	sm.txPool = pool
+}
+
+func (sm *SyncManager) gossipCompactBlock(block *core.Block) {
+	payload, err := rlp.EncodeToBytes(NewCompactBlock(block))
+	if err != nil {
+		sm.logger.WithFields(log.Fields{
+			"block hash":   block.Hash().String(),
+			"block height": block.Height,
+			"err":          err.Error(),
+		}).Debug("failed to encode compact block")
+		return
+	}
+	cresp := dispatcher.DataResponse{ChannelID: common.ChannelIDCompactBlock, Payload: payload}
+	sm.dispatcher.SendData([]string{}, cresp)
+}
+
+func (sm *SyncManager) handleCompactBlock(cb *CompactBlock, peerID string) {
+	if cb.Header == nil {
+		return
+	}
+	if eb, err := sm.chain.FindBlock(cb.Header.Hash()); err == nil && !eb.Status.IsPending() {
+		return
+	}
+
+	block, missing, err := cb.Reconstruct(sm.txPool)
+	if err != nil {
+		sm.logger.WithFields(log.Fields{
+			"block hash": cb.Header.Hash().Hex(),
+			"error":      err,
+			"peerID":     peerID,
+		}).Warn("Invalid compact block")
+		return
+	}
+	if len(missing) > 0 {
+		// Fall back to downloading the full block.
+		sm.logger.WithFields(log.Fields{
+			"block hash": cb.Header.Hash().Hex(),
+			"missing":    len(missing),
+			"peerID":     peerID,
+		}).Debug("Failed to reconstruct compact block, requesting full block")
+		sm.handleHeader(cb.Header, []string{peerID})
+		return
+	}
+
+	sm.handleBlock(block)
+}
@@ -0,0 +1,98 @@
+package netsync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/rlp"
+)
+
+type MockTxPool struct {
+	txs map[common.Hash]common.Bytes
+}
+
+func (p *MockTxPool) GetTransactionsByHashes(hashes []common.Hash) []common.Bytes {
+	ret := make([]common.Bytes, len(hashes))
+	for idx, hash := range hashes {
+		ret[idx] = p.txs[hash]
+	}
+	return ret
+}
+
+func createTestSendTx(seq uint64) common.Bytes {
+	tx := &types.SendTx{
+		Fee: types.NewCoins(0, 1000000000000),
+		Inputs: []types.TxInput{{
+			Address:  common.HexToAddress("A1"),
+			Coins:    types.NewCoins(0, 10),
+			Sequence: seq,
+		}},
+		Outputs: []types.TxOutput{{
+			Address: common.HexToAddress("B1"),
+			Coins:   types.NewCoins(0, 10),
+		}},
+	}
+	raw, err := types.TxToBytes(tx)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func TestCompactBlock(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	coinbaseTx, err := types.TxToBytes(&types.CoinbaseTx{
+		Proposer:    types.TxInput{Address: common.HexToAddress("A1")},
+		BlockHeight: 1,
+	})
+	require.Nil(err)
+	tx1 := createTestSendTx(1)
+	tx2 := createTestSendTx(2)
+
+	block := core.NewBlock()
+	block.ChainID = "testchain"
+	block.Height = 1
+	block.AddTxs([]common.Bytes{coinbaseTx, tx1, tx2})
+
+	cb := NewCompactBlock(block)
+	assert.Equal(3, len(cb.TxHashes))
+	assert.Equal(1, len(cb.PrefilledTxs)) // Only the coinbase tx is prefilled
+	assert.Equal(uint64(0), cb.PrefilledTxs[0].Index)
+
+	// Compact block should survive RLP encoding
+	raw, err := rlp.EncodeToBytes(cb)
+	require.Nil(err)
+	decoded := &CompactBlock{}
+	require.Nil(rlp.DecodeBytes(raw, decoded))
+	assert.Equal(block.Hash(), decoded.Header.Hash())
+
+	// tx2 is missing from the pool
+	pool := &MockTxPool{txs: map[common.Hash]common.Bytes{
+		crypto.Keccak256Hash(tx1): tx1,
+	}}
+	rebuilt, missing, err := decoded.Reconstruct(pool)
+	assert.Nil(err)
+	assert.Nil(rebuilt)
+	assert.Equal([]common.Hash{crypto.Keccak256Hash(tx2)}, missing)
+
+	pool.txs[crypto.Keccak256Hash(tx2)] = tx2
+	rebuilt, missing, err = decoded.Reconstruct(pool)
+	assert.Nil(err)
+	assert.Equal(0, len(missing))
+	require.NotNil(rebuilt)
+	assert.Equal(block.Txs, rebuilt.Txs)
+	assert.Equal(block.TxHash, core.CalculateRootHash(rebuilt.Txs))
+	assert.Equal(block.Hash(), rebuilt.Hash())
+
+	// Prefilled tx that does not match the announced hash is rejected
+	decoded.PrefilledTxs[0].Tx = tx1
+	_, _, err = decoded.Reconstruct(pool)
+	assert.NotNil(err)
+}
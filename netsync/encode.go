@@ -57,14 +57,23 @@ func decodeMessage(raw common.Bytes) (interface{}, error) {
 	if msgID == common.MessageIDInvRequest {
 		data := dispatcher.InventoryRequest{}
 		err = rlp.DecodeBytes(raw[1:], &data)
+		if err == nil {
+			err = data.Validate()
+		}
 		return data, err
 	} else if msgID == common.MessageIDInvResponse {
 		data := dispatcher.InventoryResponse{}
 		err = rlp.DecodeBytes(raw[1:], &data)
+		if err == nil {
+			err = data.Validate()
+		}
 		return data, err
 	} else if msgID == common.MessageIDDataRequest {
 		data := dispatcher.DataRequest{}
 		err = rlp.DecodeBytes(raw[1:], &data)
+		if err == nil {
+			err = data.Validate()
+		}
 		return data, err
 	} else if msgID == common.MessageIDDataResponse {
 		data := dispatcher.DataResponse{}
@@ -26,3 +26,35 @@ func TestMessageEncoding(t *testing.T) {
 	assert.Equal(1, len(dataReq2.Entries))
 	assert.Equal("A0", dataReq2.Entries[0])
 }
+
+func TestMalformedMessageDecoding(t *testing.T) {
+	assert := assert.New(t)
+
+	dataReq := dispatcher.DataRequest{ChannelID: common.ChannelIDBlock, Entries: []string{}}
+	b, err := encodeMessage(dataReq)
+	assert.Nil(err)
+	_, err = decodeMessage(b)
+	assert.Equal(dispatcher.ErrNoEntries, err)
+
+	entries := make([]string, dispatcher.MaxInventorySize+1)
+	for i := range entries {
+		entries[i] = common.Hash{}.Hex()
+	}
+	invResp := dispatcher.InventoryResponse{ChannelID: common.ChannelIDBlock, Entries: entries}
+	b, err = encodeMessage(invResp)
+	assert.Nil(err)
+	_, err = decodeMessage(b)
+	assert.Equal(dispatcher.ErrTooManyEntries, err)
+
+	invReq := dispatcher.InventoryRequest{ChannelID: common.ChannelIDBlock, Starts: []string{common.Hash{}.Hex() + "00"}}
+	b, err = encodeMessage(invReq)
+	assert.Nil(err)
+	_, err = decodeMessage(b)
+	assert.Equal(dispatcher.ErrInvalidHash, err)
+
+	invReq.Starts = entries
+	b, err = encodeMessage(invReq)
+	assert.Nil(err)
+	_, err = decodeMessage(b)
+	assert.Nil(err)
+}
@@ -0,0 +1,111 @@
+package netsync
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/dispatcher"
+	"github.com/thetatoken/theta/rlp"
+)
+
+const (
+	replayCacheLimit = 8192
+
+	// maxVoteHeightLead is how many blocks a vote can be ahead of the local tip
+	maxVoteHeightLead = 2 * dispatcher.MaxInventorySize
+)
+
+var (
+	errReplayed = errors.New("replayed")
+	errStale    = errors.New("stale")
+	errFuture   = errors.New("too far in the future")
+)
+
+// messageGuard screens the consensus messages gossiped by the peers before their signatures are verified. It
+// rejects the replayed messages, and those too stale or too far in the future to be of any use, so that the
+// replayed gossip does not waste the verification cycles.
+type messageGuard struct {
+	consensus   core.ConsensusEngine
+	replayCache *lru.Cache // hashes of the recently accepted votes and proposals
+}
+
+func newMessageGuard(consensus core.ConsensusEngine) *messageGuard {
+	replayCache, _ := lru.New(replayCacheLimit)
+	return &messageGuard{
+		consensus:   consensus,
+		replayCache: replayCache,
+	}
+}
+
+// checkVote checks whether the vote should be passed down to the consensus engine
+func (g *messageGuard) checkVote(vote core.Vote) error {
+	hash := vote.Hash()
+	if g.replayCache.Contains(hash) {
+		return g.reject("vote", errReplayed)
+	}
+
+	lfbHeight := g.consensus.GetLastFinalizedBlock().Height
+	if vote.Height+viper.GetUint64(common.CfgSyncMaxVoteHeightLag) < lfbHeight {
+		return g.reject("vote", errStale)
+	}
+	tip := g.consensus.GetTip(true)
+	if vote.Height > tip.Height+maxVoteHeightLead {
+		return g.reject("vote", errFuture)
+	}
+	if vote.Epoch > tip.Epoch+viper.GetUint64(common.CfgSyncMaxVoteEpochLead) &&
+		vote.Epoch > g.consensus.GetEpoch()+viper.GetUint64(common.CfgSyncMaxVoteEpochLead) {
+		return g.reject("vote", errFuture)
+	}
+
+	g.replayCache.Add(hash, struct{}{})
+	return nil
+}
+
+// checkProposal checks whether the proposal should be handled
+func (g *messageGuard) checkProposal(proposal *core.Proposal) error {
+	raw, err := rlp.EncodeToBytes(proposal)
+	if err != nil {
+		return err
+	}
+	hash := crypto.Keccak256Hash(raw)
+	if g.replayCache.Contains(hash) {
+		return g.reject("proposal", errReplayed)
+	}
+
+	if proposal.Block != nil {
+		if proposal.Block.Height <= g.consensus.GetLastFinalizedBlock().Height {
+			return g.reject("proposal", errStale)
+		}
+		if err := g.checkBlockTimestamp(proposal.Block); err != nil {
+			return err
+		}
+	}
+
+	g.replayCache.Add(hash, struct{}{})
+	return nil
+}
+
+// checkBlockTimestamp checks the block timestamp is not too far ahead of the local clock
+func (g *messageGuard) checkBlockTimestamp(block *core.Block) error {
+	if block.Timestamp == nil {
+		return nil // rejected by the block validation
+	}
+	maxTimestamp := time.Now().Unix() + viper.GetInt64(common.CfgSyncMaxBlockTimestampDrift)
+	if block.Timestamp.Cmp(big.NewInt(maxTimestamp)) > 0 {
+		return g.reject("block", errFuture)
+	}
+	return nil
+}
+
+// reject counts the rejected message, and returns the reason
+func (g *messageGuard) reject(kind string, reason error) error {
+	metrics.GetOrRegisterCounter("netsync/guard/"+kind+"/"+reason.Error(), nil).Inc(1)
+	return reason
+}
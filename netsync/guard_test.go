@@ -0,0 +1,70 @@
+package netsync
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+)
+
+func newTestExtendedBlock(height uint64, epoch uint64) *core.ExtendedBlock {
+	return &core.ExtendedBlock{
+		Block: &core.Block{
+			BlockHeader: &core.BlockHeader{
+				Height: height,
+				Epoch:  epoch,
+			},
+		},
+	}
+}
+
+// newGuardTestConsensus returns a mock consensus whose tip is also the last finalized block
+func newGuardTestConsensus(tip *core.ExtendedBlock) *MockConsensus {
+	consensus := NewMockConsensus(nil, tip)
+	consensus.tip = tip
+	return consensus
+}
+
+func TestMessageGuardVote(t *testing.T) {
+	assert := assert.New(t)
+
+	guard := newMessageGuard(newGuardTestConsensus(newTestExtendedBlock(1000, 1000)))
+
+	vote := core.Vote{Block: common.BytesToHash([]byte("a")), Height: 1000, Epoch: 1001, ID: common.HexToAddress("0x1")}
+	assert.Nil(guard.checkVote(vote))
+	assert.Equal(errReplayed, guard.checkVote(vote))
+
+	vote = core.Vote{Block: common.BytesToHash([]byte("b")), Height: 10, Epoch: 1001, ID: common.HexToAddress("0x1")}
+	assert.Equal(errStale, guard.checkVote(vote))
+
+	vote = core.Vote{Block: common.BytesToHash([]byte("c")), Height: 1000 + 2*maxVoteHeightLead, Epoch: 1001, ID: common.HexToAddress("0x1")}
+	assert.Equal(errFuture, guard.checkVote(vote))
+
+	vote = core.Vote{Block: common.BytesToHash([]byte("d")), Height: 1001, Epoch: 1000000, ID: common.HexToAddress("0x1")}
+	assert.Equal(errFuture, guard.checkVote(vote))
+}
+
+func TestMessageGuardProposal(t *testing.T) {
+	assert := assert.New(t)
+
+	guard := newMessageGuard(newGuardTestConsensus(newTestExtendedBlock(1000, 1000)))
+
+	newProposal := func(height uint64, timestamp int64) *core.Proposal {
+		block := core.NewBlock()
+		block.Height = height
+		block.Epoch = height
+		block.Timestamp = big.NewInt(timestamp)
+		return &core.Proposal{Block: block, ProposerID: common.HexToAddress("0x1")}
+	}
+
+	now := time.Now().Unix()
+	proposal := newProposal(1001, now)
+	assert.Nil(guard.checkProposal(proposal))
+	assert.Equal(errReplayed, guard.checkProposal(proposal))
+
+	assert.Equal(errStale, guard.checkProposal(newProposal(999, now)))
+	assert.Equal(errFuture, guard.checkProposal(newProposal(1002, now+3600)))
+}
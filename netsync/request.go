@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/membudget"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/dispatcher"
@@ -33,6 +34,7 @@ const MaxNumPeersToSendRequests = 4
 const RefreshCounterLimit = 4
 const MaxBlocksPerRequest = 4
 const MaxPeerActiveScore = 16
+const approxBlockHeaderSize = 2048 // bytes, the size of a header with its votes
 
 type RequestState uint8
 
@@ -133,6 +135,9 @@ type RequestManager struct {
 
 	dumpBlockCache *lru.Cache
 
+	blockMemory  *membudget.Allocation
+	passedBlocks map[common.Hash]*passedBlock // blocks passed down to the consensus but not processed yet
+
 	endHashCache      []common.Bytes
 	blockRequestCache []common.Bytes
 
@@ -170,6 +175,9 @@ func NewRequestManager(syncMgr *SyncManager, reporter *rp.Reporter) *RequestMana
 		blockNotify:    make(chan *core.ExtendedBlock, 1),
 		dumpBlockCache: dumpBlockCache,
 
+		blockMemory:  membudget.Get(membudget.BlockCache),
+		passedBlocks: make(map[common.Hash]*passedBlock),
+
 		activePeers:    make(map[string]int),
 		refreshCounter: 0,
 		aplock:         &sync.RWMutex{},
@@ -733,10 +741,13 @@ func (rm *RequestManager) passReadyBlocks() {
 	defer timer.Stop()
 
 	for {
+		rm.releaseProcessedBlocks()
+
 		lfb := rm.syncMgr.consensus.GetLastFinalizedBlock()
 		height := lfb.Height + 1
 		parents := []*core.ExtendedBlock{lfb}
 
+	passLoop:
 		for {
 			blocks := rm.chain.FindBlocksByHeight(height)
 
@@ -761,11 +772,16 @@ func (rm *RequestManager) passReadyBlocks() {
 					continue
 				}
 
-				rm.dumpBlockCache.Add(block.Hash(), struct{}{})
 				if block.Status.IsPending() {
-					rm.syncMgr.PassdownMessage(block.Block)
-					rm.tip.Store(block)
+					// Hold the remaining blocks until the consensus catches up with the ones passed down
+					if !rm.blockMemory.Admit() {
+						break passLoop
+					}
+					rm.dumpBlockCache.Add(block.Hash(), struct{}{})
+					rm.passBlock(block)
+					continue
 				}
+				rm.dumpBlockCache.Add(block.Hash(), struct{}{})
 			}
 
 			height++
@@ -781,3 +797,34 @@ func (rm *RequestManager) passReadyBlocks() {
 	}
 
 }
+
+// passedBlock records a block passed down to the consensus, accounted in the block cache budget
+type passedBlock struct {
+	size     int64
+	passedAt time.Time
+}
+
+func (rm *RequestManager) passBlock(block *core.ExtendedBlock) {
+	size := int64(approxBlockHeaderSize)
+	for _, tx := range block.Txs {
+		size += int64(len(tx))
+	}
+	rm.passedBlocks[block.Hash()] = &passedBlock{size: size, passedAt: time.Now()}
+	rm.blockMemory.Reserve(size)
+
+	rm.syncMgr.PassdownMessage(block.Block)
+	rm.tip.Store(block)
+}
+
+// releaseProcessedBlocks releases the memory of the passed down blocks which the consensus has processed.
+// The blocks pending for too long are released too, so that they cannot hold the sync back.
+func (rm *RequestManager) releaseProcessedBlocks() {
+	for hash, passed := range rm.passedBlocks {
+		if block, err := rm.chain.FindBlock(hash); err == nil && block.Status.IsPending() &&
+			time.Since(passed.passedAt) < Expiration {
+			continue
+		}
+		rm.blockMemory.Release(passed.size)
+		delete(rm.passedBlocks, hash)
+	}
+}
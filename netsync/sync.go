@@ -58,7 +58,11 @@ type SyncManager struct {
 
 	logger *log.Entry
 
-	voteCache *lru.Cache // Cache for votes
+	voteCache *lru.Cache     // Cache for votes
+	guard     *messageGuard  // Screens the gossiped votes and proposals
+	verifier  *blockVerifier // Verifies the received blocks
+
+	txPool TxPool // Source of txs for compact block reconstruction
 }
 
 func NewSyncManager(chain *blockchain.Chain, cons core.ConsensusEngine, networkOld p2p.Network, network p2pl.Network, disp *dispatcher.Dispatcher, consumer MessageConsumer, reporter *rp.Reporter) *SyncManager {
@@ -72,6 +76,7 @@ func NewSyncManager(chain *blockchain.Chain, cons core.ConsensusEngine, networkO
 		incoming:   make(chan p2ptypes.Message, viper.GetInt(common.CfgSyncMessageQueueSize)),
 
 		voteCache: voteCache,
+		guard:     newMessageGuard(cons),
 	}
 	sm.requestMgr = NewRequestManager(sm, reporter)
 
@@ -91,6 +96,7 @@ func NewSyncManager(chain *blockchain.Chain, cons core.ConsensusEngine, networkO
 		logger = logger.WithFields(log.Fields{"id": sm.consensus.ID()})
 	}
 	sm.logger = logger
+	sm.verifier = newBlockVerifier(chain.ChainID, logger)
 
 	return sm
 }
@@ -140,6 +146,7 @@ func (sm *SyncManager) GetChannelIDs() []common.ChannelIDEnum {
 		common.ChannelIDGuardian,
 		common.ChannelIDEliteEdgeNodeVote,
 		common.ChannelIDAggregatedEliteEdgeNodeVotes,
+		common.ChannelIDCompactBlock,
 	}
 }
 
@@ -528,6 +535,79 @@ func Fuzz(data []byte) int {
 	return 0
 }
 
+// FuzzDataResponse decodes the payload of a DataResponse in the same way as handleDataResponse,
+// and exercises the methods the handlers call on the decoded messages. The first byte selects
+// the channel. Run with go-fuzz-build -func FuzzDataResponse.
+func FuzzDataResponse(data []byte) int {
+	if len(data) == 0 {
+		return -1
+	}
+	payload := data[1:]
+	switch data[0] % 7 {
+	case 0:
+		block := core.NewBlock()
+		if err := rlp.DecodeBytes(payload, block); err == nil {
+			block.Hash()
+			return 0
+		}
+		blocks := &Blocks{}
+		if err := rlp.DecodeBytes(payload, blocks); err != nil {
+			return 1
+		}
+		for _, block := range blocks.BlockArray {
+			block.Hash()
+		}
+	case 1:
+		vote := core.Vote{}
+		if err := rlp.DecodeBytes(payload, &vote); err != nil {
+			return 1
+		}
+		vote.Hash()
+	case 2:
+		proposal := &core.Proposal{}
+		if err := rlp.DecodeBytes(payload, proposal); err != nil {
+			return 1
+		}
+		if proposal.Votes != nil {
+			proposal.Votes.Votes()
+		}
+		if proposal.Block != nil {
+			proposal.Block.Hash()
+		}
+	case 3:
+		vote := &core.AggregatedVotes{}
+		if err := rlp.DecodeBytes(payload, vote); err != nil {
+			return 1
+		}
+		vote.Abs()
+		vote.Copy()
+	case 4:
+		vote := &core.AggregatedEENVotes{}
+		if err := rlp.DecodeBytes(payload, vote); err != nil {
+			return 1
+		}
+		vote.Abs()
+		vote.Copy()
+	case 5:
+		headers := &Headers{}
+		if err := rlp.DecodeBytes(payload, headers); err != nil {
+			return 1
+		}
+		for _, header := range headers.HeaderArray {
+			header.Hash()
+		}
+	case 6:
+		cb := &CompactBlock{}
+		if err := rlp.DecodeBytes(payload, cb); err != nil {
+			return 1
+		}
+		if cb.Header != nil {
+			cb.Header.Hash()
+		}
+	}
+	return 0
+}
+
 func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataResponse) {
 	switch data.ChannelID {
 	case common.ChannelIDBlock:
@@ -554,11 +634,11 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 					"block.Height": block.Height,
 					"peer":         peerID,
 				}).Debug("Received block")
-				m.handleBlock(block)
 				if block.Height > maxReceivedHeight {
 					maxReceivedHeight = block.Height
 				}
 			}
+			m.handleBlocks(blocks.BlockArray)
 		} else {
 			m.logger.WithFields(log.Fields{
 				"block.Hash":   block.Hash().Hex(),
@@ -680,6 +760,19 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 			}).Debug("Received header")
 			m.handleHeader(header, []string{peerID})
 		}
+	case common.ChannelIDCompactBlock:
+		cb := &CompactBlock{}
+		err := rlp.DecodeBytes(data.Payload, cb)
+		if err != nil {
+			m.logger.WithFields(log.Fields{
+				"channelID": data.ChannelID,
+				"payload":   data.Payload,
+				"error":     err,
+				"peerID":    peerID,
+			}).Warn("Failed to decode CompactBlock payload")
+			return
+		}
+		m.handleCompactBlock(cb, peerID)
 	default:
 		m.logger.WithFields(log.Fields{
 			"channelID": data.ChannelID,
@@ -688,11 +781,21 @@ func (m *SyncManager) handleDataResponse(peerID string, data *dispatcher.DataRes
 }
 
 func (sm *SyncManager) handleProposal(p *core.Proposal) {
+	if err := sm.guard.checkProposal(p); err != nil {
+		sm.logger.WithFields(log.Fields{
+			"proposer": p.ProposerID.Hex(),
+			"err":      err.Error(),
+		}).Debug("Proposal rejected")
+		return
+	}
 	if p.Votes != nil {
 		for _, vote := range p.Votes.Votes() {
 			sm.handleVote(vote)
 		}
 	}
+	if p.Block == nil {
+		return
+	}
 	sm.handleBlock(p.Block)
 }
 
@@ -723,30 +826,47 @@ func (sm *SyncManager) handleHeader(header *core.BlockHeader, peerID []string) {
 }
 
 func (sm *SyncManager) handleBlock(block *core.Block) {
-	if eb, err := sm.chain.FindBlock(block.Hash()); err == nil && !eb.Status.IsPending() {
-		sm.logger.WithFields(log.Fields{
-			"block hash":   block.Hash().String(),
-			"block height": block.Height,
-		}).Debug("block is already in chain")
-		return
+	sm.handleBlocks([]*core.Block{block})
+}
+
+// handleBlocks verifies the given blocks together, and adds the valid ones
+func (sm *SyncManager) handleBlocks(blocks []*core.Block) {
+	candidates := []*core.Block{}
+	for _, block := range blocks {
+		if eb, err := sm.chain.FindBlock(block.Hash()); err == nil && !eb.Status.IsPending() {
+			sm.logger.WithFields(log.Fields{
+				"block hash":   block.Hash().String(),
+				"block height": block.Height,
+			}).Debug("block is already in chain")
+			continue
+		}
+
+		if err := sm.guard.checkBlockTimestamp(block); err != nil {
+			sm.logger.WithFields(log.Fields{
+				"block hash":      block.Hash().String(),
+				"block height":    block.Height,
+				"block timestamp": block.Timestamp,
+			}).Debug("block timestamp is too far in the future")
+			continue
+		}
+		candidates = append(candidates, block)
 	}
 
-	if hash, ok := core.HardcodeBlockHashes[block.Height]; ok {
-		if hash != block.Hash().Hex() {
+	results := sm.verifier.verify(candidates)
+	for i, block := range candidates {
+		if results[i].IsError() {
 			sm.logger.WithFields(log.Fields{
 				"block hash":   block.Hash().String(),
 				"block height": block.Height,
-			}).Debug("hardcoded block")
-			return
+				"error":        results[i].String(),
+			}).Debug("block is invalid")
+			continue
 		}
-	} else if res := block.Validate(sm.chain.ChainID); res.IsError() {
-		sm.logger.WithFields(log.Fields{
-			"block hash":   block.Hash().String(),
-			"block height": block.Height,
-		}).Debug("chain ID is invalid")
-		return
+		sm.addBlock(block)
 	}
+}
 
+func (sm *SyncManager) addBlock(block *core.Block) {
 	sm.requestMgr.AddBlock(block)
 
 	p2pOpt := common.P2POptEnum(viper.GetInt(common.CfgP2POpt))
@@ -772,10 +892,23 @@ func (sm *SyncManager) handleBlock(block *core.Block) {
 		}
 		hresp := dispatcher.DataResponse{ChannelID: common.ChannelIDHeader, Payload: payload}
 		sm.dispatcher.SendData([]string{}, hresp)
+
+		// Gossip the block out as a compact block so that peers can rebuild it from their mempool
+		if viper.GetBool(common.CfgSyncCompactBlockRelay) {
+			sm.gossipCompactBlock(block)
+		}
 	}
 }
 
 func (sm *SyncManager) handleVote(vote core.Vote) {
+	if err := sm.guard.checkVote(vote); err != nil {
+		sm.logger.WithFields(log.Fields{
+			"vote": vote,
+			"err":  err.Error(),
+		}).Debug("Vote rejected")
+		return
+	}
+
 	votes := sm.chain.FindVotesByHash(vote.Block).Votes()
 	for _, v := range votes {
 		// Check if vote already processed.
@@ -175,6 +175,7 @@ func TestSyncManager(t *testing.T) {
 type MockConsensus struct {
 	chain *blockchain.Chain
 	lfb   *core.ExtendedBlock
+	tip   *core.ExtendedBlock
 }
 
 func NewMockConsensus(chain *blockchain.Chain, lfb *core.ExtendedBlock) *MockConsensus {
@@ -202,7 +203,7 @@ func (c *MockConsensus) PrivateKey() *crypto.PrivateKey {
 }
 
 func (c *MockConsensus) GetTip(includePendingBlockingLeaf bool) *core.ExtendedBlock {
-	return nil
+	return c.tip
 }
 
 func (c *MockConsensus) GetEpoch() uint64 {
@@ -0,0 +1,174 @@
+package netsync
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/core"
+)
+
+// blockVerifier verifies the blocks received by the sync manager. The blocks of a batch, e.g. the blocks
+// downloaded during the initial sync, are verified in parallel, including the votes of their HCCs, which the
+// consensus engine then finds verified already.
+//
+// The blocks below the trusted checkpoint are part of the history certified by the checkpoint hash, as the
+// chain only reaches the checkpoint through the hashes of their headers. Their signatures can optionally be
+// verified for a random sample of the blocks only, which speeds up the initial sync. A forged block then goes
+// unnoticed until the sync reaches the checkpoint, so the sampling is turned off for good as soon as a sampled
+// signature turns out invalid.
+type blockVerifier struct {
+	chainID string
+
+	checkpoints   map[uint64]string // trusted block hashes by height
+	trustedHeight uint64            // height of the highest trusted checkpoint
+	sampleRate    float64           // fraction of the signatures verified below the trusted checkpoint
+	workers       int
+
+	mu       sync.Mutex
+	sampling bool
+	rand     *rand.Rand
+
+	logger *log.Entry
+}
+
+func newBlockVerifier(chainID string, logger *log.Entry) *blockVerifier {
+	v := &blockVerifier{
+		chainID:     chainID,
+		checkpoints: make(map[uint64]string),
+		sampleRate:  viper.GetFloat64(common.CfgSyncSignatureSampleRate),
+		workers:     viper.GetInt(common.CfgSyncSignatureVerifyWorkers),
+		rand:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		logger:      logger,
+	}
+	for height, hash := range core.HardcodeBlockHashes {
+		v.addCheckpoint(height, hash)
+	}
+	if hash := viper.GetString(common.CfgSyncTrustedCheckpointHash); hash != "" {
+		v.addCheckpoint(viper.GetUint64(common.CfgSyncTrustedCheckpointHeight), common.HexToHash(hash).Hex())
+	}
+	if v.workers <= 0 {
+		v.workers = runtime.NumCPU()
+	}
+	v.sampling = v.trustedHeight > 0 && v.sampleRate < 1
+	return v
+}
+
+func (v *blockVerifier) addCheckpoint(height uint64, hash string) {
+	v.checkpoints[height] = hash
+	if height > v.trustedHeight {
+		v.trustedHeight = height
+	}
+}
+
+// shouldVerifySignatures returns whether the signatures of the given block need to be verified
+func (v *blockVerifier) shouldVerifySignatures(block *core.Block) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if !v.sampling || block.Height >= v.trustedHeight {
+		return true
+	}
+	return v.rand.Float64() < v.sampleRate
+}
+
+// verify checks the given blocks, and returns the result of each block
+func (v *blockVerifier) verify(blocks []*core.Block) []result.Result {
+	results := make([]result.Result, len(blocks))
+	verified := make([]bool, len(blocks))
+
+	check := func(i int) {
+		block := blocks[i]
+		if hash, ok := v.checkpoints[block.Height]; ok {
+			// The hash certifies the block and its history
+			if hash != block.Hash().Hex() {
+				results[i] = result.Error("Block hash does not match the checkpoint")
+			} else {
+				results[i] = result.OK
+			}
+			return
+		}
+		if results[i] = block.ValidateBasic(v.chainID); results[i].IsError() {
+			return
+		}
+		if !v.shouldVerifySignatures(block) {
+			if block.HCC.Votes != nil {
+				for _, vote := range block.HCC.Votes.Votes() {
+					core.TrustVote(vote)
+				}
+			}
+			return
+		}
+		verified[i] = true
+		results[i] = verifyBlockSignatures(block)
+	}
+
+	if len(blocks) == 1 {
+		check(0)
+	} else {
+		indices := make(chan int, len(blocks))
+		for i := range blocks {
+			indices <- i
+		}
+		close(indices)
+
+		wg := &sync.WaitGroup{}
+		for w := 0; w < v.workers && w < len(blocks); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indices {
+					check(i)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	// A forged block below the trusted checkpoint means that the unverified blocks cannot be relied on
+	// either, so the sampling is turned off and the blocks are verified again in full.
+	for i, block := range blocks {
+		if verified[i] && results[i].IsError() && block.Height < v.trustedHeight && v.disableSampling() {
+			v.logger.WithFields(log.Fields{
+				"block.Hash":   block.Hash().Hex(),
+				"block.Height": block.Height,
+				"error":        results[i].String(),
+			}).Warn("Invalid signature below the trusted checkpoint, verifying all the signatures from now on")
+			return v.verify(blocks)
+		}
+	}
+	return results
+}
+
+// disableSampling turns off the sampling, and returns whether it was on
+func (v *blockVerifier) disableSampling() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	sampling := v.sampling
+	v.sampling = false
+	if sampling {
+		core.ClearTrustedVotes()
+	}
+	return sampling
+}
+
+// verifyBlockSignatures verifies the signature of the proposer and the signatures of the HCC votes of the block.
+// The HCC is left to the consensus engine to accept or reject, which checks the votes against the validator set
+// and finds the valid ones verified already.
+func verifyBlockSignatures(block *core.Block) result.Result {
+	if res := block.ValidateSignature(); res.IsError() {
+		return res
+	}
+	if block.HCC.Votes != nil {
+		for _, vote := range block.HCC.Votes.Votes() {
+			vote.Validate()
+		}
+	}
+	return result.OK
+}
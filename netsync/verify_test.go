@@ -0,0 +1,78 @@
+package netsync
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+)
+
+func newSignedTestBlock(t *testing.T, height uint64, privKey *crypto.PrivateKey) *core.Block {
+	block := core.NewBlock()
+	block.ChainID = "testchain"
+	block.Height = height
+	block.Epoch = height
+	block.Parent = common.BytesToHash([]byte{0xff, byte(height - 1)})
+	block.HCC = core.CommitCertificate{BlockHash: block.Parent}
+	block.Timestamp = big.NewInt(int64(height))
+	block.TxHash = core.CalculateRootHash(block.Txs)
+	block.Proposer = privKey.PublicKey().Address()
+	sig, err := privKey.Sign(block.SignBytes())
+	require.Nil(t, err)
+	block.SetSignature(sig)
+	return block
+}
+
+func TestBlockVerifier(t *testing.T) {
+	assert := assert.New(t)
+
+	privKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(t, err)
+	otherKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(t, err)
+
+	blocks := []*core.Block{}
+	for height := uint64(1); height <= 10; height++ {
+		blocks = append(blocks, newSignedTestBlock(t, height, privKey))
+	}
+	forged := newSignedTestBlock(t, 5, privKey)
+	forged.Proposer = otherKey.PublicKey().Address()
+
+	// All the signatures are verified without a trusted checkpoint
+	v := newBlockVerifier("testchain", logger)
+	results := v.verify(append(blocks, forged))
+	for _, res := range results[:len(blocks)] {
+		assert.True(res.IsOK())
+	}
+	assert.True(results[len(blocks)].IsError())
+
+	// The block at the checkpoint height is checked against the checkpoint hash
+	defer viper.Set(common.CfgSyncSignatureSampleRate, 1.0)
+	defer viper.Set(common.CfgSyncTrustedCheckpointHash, "")
+	viper.Set(common.CfgSyncTrustedCheckpointHeight, 8)
+	viper.Set(common.CfgSyncTrustedCheckpointHash, blocks[7].Hash().Hex())
+	viper.Set(common.CfgSyncSignatureSampleRate, 0.0)
+	v = newBlockVerifier("testchain", logger)
+	results = v.verify([]*core.Block{blocks[7], newSignedTestBlock(t, 8, otherKey)})
+	assert.True(results[0].IsOK())
+	assert.True(results[1].IsError())
+
+	// With the sampling, the signatures below the checkpoint are skipped until a sampled one is invalid
+	assert.True(v.verify([]*core.Block{forged})[0].IsOK())
+	v.sampleRate = 0.5
+	for i := 0; i < 100 && v.sampling; i++ {
+		v.verify([]*core.Block{blocks[0], forged})
+	}
+	assert.False(v.sampling)
+	assert.True(v.verify([]*core.Block{forged})[0].IsError())
+
+	// The signatures above the checkpoint are always verified
+	forgedAbove := newSignedTestBlock(t, 9, privKey)
+	forgedAbove.Proposer = otherKey.PublicKey().Address()
+	assert.True(v.verify([]*core.Block{forgedAbove})[0].IsError())
+}
@@ -0,0 +1,190 @@
+package node
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	mp "github.com/thetatoken/theta/mempool"
+)
+
+//
+// The events let the Go programs embedding the node follow the chain head, the new transactions and the
+// status of the node in the same process, instead of polling the RPC server.
+//
+
+// EventType is the type of an event published by the node
+type EventType byte
+
+const (
+	EventFinalizedBlock EventType = iota + 1 // a block has been finalized
+	EventNewTx                               // a transaction has entered the mempool
+	EventStatus                              // the status of the node has changed
+	EventMempoolTx                           // the mempool has made an admission decision on a transaction
+)
+
+// statusInterval is the interval of checking whether the status of the node has changed
+const statusInterval = time.Second
+
+// Event is an event published by the node. Only the field of its type is set.
+type Event struct {
+	Type      EventType
+	Block     *core.ExtendedBlock
+	Tx        common.Bytes
+	Status    *Status
+	MempoolTx *mp.TxEvent
+}
+
+// Status summarizes the state of the node
+type Status struct {
+	LatestFinalizedBlockHash   common.Hash
+	LatestFinalizedBlockHeight uint64
+	CurrentEpoch               uint64
+	Syncing                    bool
+	NumPeers                   int
+}
+
+// Subscription delivers the events of the subscribed types on C, in the order they are published. The events
+// which do not fit in its buffer are dropped rather than blocking the node, so the subscriber should keep up,
+// and check Dropped() to detect the gaps.
+type Subscription struct {
+	C <-chan *Event
+
+	ch      chan *Event
+	types   map[EventType]bool
+	dropped uint64
+	hub     *eventHub
+}
+
+// Dropped returns the number of the events dropped since the subscription was made
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Unsubscribe stops the delivery of the events, and closes C
+func (s *Subscription) Unsubscribe() {
+	s.hub.unsubscribe(s)
+}
+
+// eventHub delivers the published events to the subscriptions
+type eventHub struct {
+	mu   *sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{
+		mu:   &sync.Mutex{},
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+func (h *eventHub) subscribe(bufferSize int, types []EventType) *Subscription {
+	ch := make(chan *Event, bufferSize)
+	s := &Subscription{
+		C:     ch,
+		ch:    ch,
+		types: make(map[EventType]bool),
+		hub:   h,
+	}
+	for _, typ := range types {
+		s.types[typ] = true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subs[s] = struct{}{}
+	return s
+}
+
+func (h *eventHub) unsubscribe(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[s]; ok {
+		delete(h.subs, s)
+		close(s.ch)
+	}
+}
+
+func (h *eventHub) hasSubscribers(typ EventType) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for s := range h.subs {
+		if s.types[typ] {
+			return true
+		}
+	}
+	return false
+}
+
+// publish delivers the event to the subscriptions of its type without blocking
+func (h *eventHub) publish(event *Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for s := range h.subs {
+		if !s.types[event.Type] {
+			continue
+		}
+		select {
+		case s.ch <- event:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	}
+}
+
+// Subscribe subscribes to the events of the given types published from now on. The bufferSize is the number
+// of the events the subscription holds until they are received.
+func (n *Node) Subscribe(bufferSize int, types ...EventType) *Subscription {
+	return n.events.subscribe(bufferSize, types)
+}
+
+// GetStatus returns the current status of the node
+func (n *Node) GetStatus() *Status {
+	lfb := n.Consensus.GetLastFinalizedBlock()
+	return &Status{
+		LatestFinalizedBlockHash:   lfb.Hash(),
+		LatestFinalizedBlockHeight: lfb.Height,
+		CurrentEpoch:               n.Consensus.GetEpoch(),
+		Syncing:                    !n.Consensus.HasSynced(),
+		NumPeers:                   len(n.Dispatcher.Peers(false)),
+	}
+}
+
+// registerEventSources publishes the finalized blocks, the new transactions and the mempool decisions to the
+// subscribers
+func (n *Node) registerEventSources() {
+	n.Consensus.AddFinalizedBlockListener(func(block *core.ExtendedBlock) {
+		n.events.publish(&Event{Type: EventFinalizedBlock, Block: block})
+	})
+	n.Mempool.AddNewTxListener(func(rawTx common.Bytes) {
+		n.events.publish(&Event{Type: EventNewTx, Tx: rawTx})
+	})
+	n.Mempool.AddTxEventListener(func(event *mp.TxEvent) {
+		n.events.publish(&Event{Type: EventMempoolTx, MempoolTx: event})
+	})
+}
+
+// publishStatusRoutine publishes the status of the node whenever it changes, while there are subscribers
+func (n *Node) publishStatusRoutine() {
+	ticker := time.NewTicker(statusInterval)
+	defer ticker.Stop()
+
+	var last Status
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-ticker.C:
+			if !n.events.hasSubscribers(EventStatus) {
+				continue
+			}
+			if status := n.GetStatus(); *status != last {
+				last = *status
+				n.events.publish(&Event{Type: EventStatus, Status: status})
+			}
+		}
+	}
+}
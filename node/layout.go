@@ -0,0 +1,101 @@
+package node
+
+import (
+	"os"
+	"path"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+)
+
+// LogFileName is the name of the log file under the log directory
+const LogFileName = "theta.log"
+
+// DataLayout is the placement of the data of a node. By default everything is under the data path, which
+// defaults to the config path, and each part can be moved to another directory, e.g. the DB to an NVMe
+// disk and the logs to an HDD.
+type DataLayout struct {
+	DB       string // Directory of the DB holding the blocks and the state, i.e. the main and the ref DBs
+	Ancient  string // Directory of the ancient store holding the finalized blocks far behind the tip
+	Snapshot string // Path of the snapshot the node starts from
+	Logs     string // Directory of the log file, empty if the logs are written to the stdout
+}
+
+// DefaultDataLayout returns the layout with the snapshot under the given config path, and the DB under
+// the given data path.
+func DefaultDataLayout(cfgPath, dataPath string) *DataLayout {
+	return &DataLayout{
+		DB:       path.Join(dataPath, "db"),
+		Ancient:  path.Join(dataPath, "db", "ancient"),
+		Snapshot: path.Join(cfgPath, "snapshot"),
+	}
+}
+
+// NewDataLayout returns the layout of the config, where the unset paths default to those of
+// DefaultDataLayout.
+func NewDataLayout(cfgPath string) *DataLayout {
+	dataPath := viper.GetString(common.CfgDataPath)
+	if dataPath == "" {
+		dataPath = cfgPath
+	}
+	layout := DefaultDataLayout(cfgPath, dataPath)
+	if dbPath := viper.GetString(common.CfgDataDBPath); dbPath != "" {
+		layout.DB = dbPath
+		layout.Ancient = path.Join(dbPath, "ancient")
+	}
+	if ancientPath := viper.GetString(common.CfgDataAncientPath); ancientPath != "" {
+		layout.Ancient = ancientPath
+	}
+	if snapshotPath := viper.GetString(common.CfgDataSnapshotPath); snapshotPath != "" {
+		layout.Snapshot = snapshotPath
+	}
+	layout.Logs = viper.GetString(common.CfgDataLogPath)
+	return layout
+}
+
+// MainDBPath returns the directory of the main DB
+func (l *DataLayout) MainDBPath() string {
+	return path.Join(l.DB, "main")
+}
+
+// RefDBPath returns the directory of the reference DB
+func (l *DataLayout) RefDBPath() string {
+	return path.Join(l.DB, "ref")
+}
+
+// LogFilePath returns the path of the log file, empty if the logs are written to the stdout
+func (l *DataLayout) LogFilePath() string {
+	if l.Logs == "" {
+		return ""
+	}
+	return path.Join(l.Logs, LogFileName)
+}
+
+// Prepare creates the directories of the layout
+func (l *DataLayout) Prepare() error {
+	for _, dir := range []string{l.DB, l.Logs} {
+		if dir == "" {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RedirectLogs writes the logs to the log file of the layout, if any
+func (l *DataLayout) RedirectLogs() error {
+	logFilePath := l.LogFilePath()
+	if logFilePath == "" {
+		return nil
+	}
+	file, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	log.Infof("Writing the logs to %v", logFilePath)
+	log.SetOutput(file)
+	return nil
+}
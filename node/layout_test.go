@@ -0,0 +1,54 @@
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestDataLayout(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	layout := NewDataLayout("/theta/config")
+	assert.Equal("/theta/config/db/main", layout.MainDBPath())
+	assert.Equal("/theta/config/db/ref", layout.RefDBPath())
+	assert.Equal("/theta/config/db/ancient", layout.Ancient)
+	assert.Equal("/theta/config/snapshot", layout.Snapshot)
+	assert.Equal("", layout.LogFilePath())
+
+	dir, err := ioutil.TempDir("", "layout")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	for key, value := range map[string]string{
+		common.CfgDataPath:         "/theta/data",
+		common.CfgDataDBPath:       path.Join(dir, "nvme", "db"),
+		common.CfgDataSnapshotPath: "/theta/snapshots/mainnet",
+		common.CfgDataLogPath:      path.Join(dir, "hdd", "logs"),
+	} {
+		defer viper.Set(key, "")
+		viper.Set(key, value)
+	}
+	layout = NewDataLayout("/theta/config")
+	assert.Equal(path.Join(dir, "nvme", "db", "main"), layout.MainDBPath())
+	assert.Equal(path.Join(dir, "nvme", "db", "ancient"), layout.Ancient)
+	assert.Equal("/theta/snapshots/mainnet", layout.Snapshot)
+	assert.Equal(path.Join(dir, "hdd", "logs", LogFileName), layout.LogFilePath())
+
+	defer viper.Set(common.CfgDataAncientPath, "")
+	viper.Set(common.CfgDataAncientPath, "/theta/hdd/ancient")
+	assert.Equal("/theta/hdd/ancient", NewDataLayout("/theta/config").Ancient)
+
+	require.Nil(layout.Prepare())
+	_, err = os.Stat(layout.DB)
+	assert.Nil(err)
+	_, err = os.Stat(layout.Logs)
+	assert.Nil(err)
+}
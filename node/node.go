@@ -5,6 +5,7 @@ import (
 	"log"
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/blockchain"
@@ -23,6 +24,7 @@ import (
 	"github.com/thetatoken/theta/snapshot"
 	"github.com/thetatoken/theta/store"
 	"github.com/thetatoken/theta/store/database"
+	"github.com/thetatoken/theta/store/freezer"
 	"github.com/thetatoken/theta/store/kvstore"
 )
 
@@ -36,7 +38,12 @@ type Node struct {
 	Ledger           core.Ledger
 	Mempool          *mp.Mempool
 	RPC              *rpc.ThetaRPCServer
+	PeerRoleResolver *PeerRoleResolver
+	Snapshots        *snapshot.SnapshotScheduler
 	reporter         *rp.Reporter
+	db               database.Database
+	ancient          *freezer.Freezer
+	events           *eventHub
 
 	// Life cycle
 	wg      *sync.WaitGroup
@@ -56,24 +63,42 @@ type Params struct {
 	SnapshotPath        string
 	ChainImportDirPath  string
 	ChainCorrectionPath string
+
+	SnapshotSchedulerDir string // Directory of the scheduled snapshots, defaults to the configured one
+	AncientPath          string // Directory of the ancient store, the blocks are all kept in the DB if empty
 }
 
-func NewNode(params *Params) *Node {
+// NewNode creates a node with the given params, customized by the given options
+func NewNode(params *Params, opts ...Option) *Node {
+	options := newOptions(opts)
 	store := kvstore.NewKVStore(params.DB)
 	chain := blockchain.NewChain(params.ChainID, store, params.Root)
+	if viper.GetBool(common.CfgStorageTxTimingIndexEnabled) {
+		if err := chain.EnableFirstSeen(); err != nil {
+			log.Printf("Failed to enable the tx timings index: %v", err)
+		}
+	}
+	var ancient *freezer.Freezer
+	if params.AncientPath != "" && viper.GetInt(common.CfgStorageAncientRetainedBlocks) > 0 {
+		ancient = openAncientStore(params.AncientPath, chain)
+	}
 	validatorManager := consensus.NewRotatingValidatorManager()
-	dispatcher := dp.NewDispatcher(params.NetworkOld, params.Network)
+	dispatcher := options.newDispatcher(params.NetworkOld, params.Network)
 	consensus := consensus.NewConsensusEngine(params.PrivateKey, store, chain, dispatcher, validatorManager)
-	reporter := rp.NewReporter(dispatcher, consensus, chain)
+	var reporter *rp.Reporter
+	if options.enabled(SubsystemReporter) {
+		reporter = rp.NewReporter(dispatcher, consensus, chain)
+	}
 
 	// TODO: check if this is a guardian node
 	syncMgr := netsync.NewSyncManager(chain, consensus, params.NetworkOld, params.Network, dispatcher, consensus, reporter)
-	mempool := mp.CreateMempool(dispatcher, consensus)
+	mempool := options.newMempool(dispatcher, consensus)
 	ledger := ld.NewLedger(params.ChainID, params.DB, chain, consensus, validatorManager, mempool)
 
 	validatorManager.SetConsensusEngine(consensus)
 	consensus.SetLedger(ledger)
 	mempool.SetLedger(ledger)
+	syncMgr.SetTxPool(mempool)
 	txMsgHandler := mp.CreateMempoolMessageHandler(mempool)
 
 	if !reflect.ValueOf(params.Network).IsNil() {
@@ -111,11 +136,19 @@ func NewNode(params *Params) *Node {
 		Dispatcher:       dispatcher,
 		Ledger:           ledger,
 		Mempool:          mempool,
+		PeerRoleResolver: NewPeerRoleResolver(consensus, ledger),
 		reporter:         reporter,
+		db:               params.DB,
+		ancient:          ancient,
+		events:           newEventHub(),
 	}
+	node.registerEventSources()
 
-	if viper.GetBool(common.CfgRPCEnabled) {
-		node.RPC = rpc.NewThetaRPCServer(mempool, ledger, dispatcher, chain, consensus)
+	if options.enabled(SubsystemSnapshots) {
+		node.Snapshots = snapshot.NewSnapshotScheduler(params.SnapshotSchedulerDir, params.DB, consensus, chain, ledger)
+	}
+	if options.enabled(SubsystemRPC) && viper.GetBool(common.CfgRPCEnabled) {
+		node.RPC = rpc.NewThetaRPCServer(mempool, ledger, dispatcher, chain, consensus, node.Snapshots)
 	}
 	return node
 }
@@ -130,9 +163,15 @@ func (n *Node) Start(ctx context.Context) {
 	n.SyncManager.Start(n.ctx)
 	n.Dispatcher.Start(n.ctx)
 	n.Mempool.Start(n.ctx)
-	n.reporter.Start(n.ctx)
+	if n.reporter != nil {
+		n.reporter.Start(n.ctx)
+	}
+	if n.Snapshots != nil {
+		n.Snapshots.Start(n.ctx)
+	}
+	go n.publishStatusRoutine()
 
-	if viper.GetBool(common.CfgRPCEnabled) {
+	if n.RPC != nil {
 		n.RPC.Start(n.ctx)
 	}
 }
@@ -146,7 +185,71 @@ func (n *Node) Stop() {
 func (n *Node) Wait() {
 	n.Consensus.Wait()
 	n.SyncManager.Wait()
+	if n.Snapshots != nil {
+		n.Snapshots.Wait()
+	}
 	if n.RPC != nil {
 		n.RPC.Wait()
 	}
 }
+
+// Shutdown stops the node within the given deadline. The RPC server stops accepting new requests
+// and drains the in-flight ones first. Then the other sub components are stopped, where the consensus
+// engine finishes applying the in-flight block before it exits. Finally the DB is closed. Returns false
+// if the deadline is exceeded, in which case the DB is left open since a block might still be applied.
+func (n *Node) Shutdown(timeout time.Duration) bool {
+	deadline := time.After(timeout)
+
+	if n.RPC != nil {
+		n.RPC.Stop()
+		if !waitUntil(n.RPC.Wait, deadline) {
+			log.Printf("Timed out draining the RPC server")
+			return false
+		}
+	}
+
+	n.Stop()
+	if !waitUntil(n.Wait, deadline) {
+		log.Printf("Timed out waiting for the in-flight block to be applied")
+		return false
+	}
+
+	n.db.Close()
+	if n.ancient != nil {
+		n.ancient.Close()
+	}
+	return true
+}
+
+// openAncientStore opens the ancient store of the given chain, which starts from the height above the root
+// when it is created. The blocks kept in the DB must cover the rollbacks of the startup consistency check.
+func openAncientStore(dir string, chain *blockchain.Chain) *freezer.Freezer {
+	retainedBlocks := viper.GetInt(common.CfgStorageAncientRetainedBlocks)
+	maxRollbackBlocks := viper.GetInt(common.CfgStorageMaxRollbackBlocks)
+	if retainedBlocks <= maxRollbackBlocks {
+		log.Fatalf("%v (%v) must be greater than %v (%v)", common.CfgStorageAncientRetainedBlocks, retainedBlocks,
+			common.CfgStorageMaxRollbackBlocks, maxRollbackBlocks)
+	}
+	ancient, err := freezer.Open(dir, blockchain.AncientTables, chain.Root().Height+1)
+	if err != nil {
+		log.Fatalf("Failed to open the ancient store %v: %v", dir, err)
+	}
+	chain.SetAncientStore(ancient)
+	return ancient
+}
+
+// waitUntil calls the given blocking wait function, and returns false if the deadline fires first.
+func waitUntil(wait func(), deadline <-chan time.Time) bool {
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-deadline:
+		return false
+	}
+}
@@ -1 +1,77 @@
 package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	dp "github.com/thetatoken/theta/dispatcher"
+	"github.com/thetatoken/theta/p2p"
+	"github.com/thetatoken/theta/p2pl"
+)
+
+func TestWaitUntil(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(waitUntil(func() {}, time.After(time.Second)))
+
+	release := make(chan struct{})
+	defer close(release)
+	assert.False(waitUntil(func() { <-release }, time.After(10*time.Millisecond)))
+}
+
+func TestEventHub(t *testing.T) {
+	assert := assert.New(t)
+
+	hub := newEventHub()
+	blocks := hub.subscribe(1, []EventType{EventFinalizedBlock})
+	all := hub.subscribe(4, []EventType{EventFinalizedBlock, EventNewTx})
+	assert.True(hub.hasSubscribers(EventNewTx))
+	assert.False(hub.hasSubscribers(EventStatus))
+
+	// The events are delivered to the subscribers of their type only
+	hub.publish(&Event{Type: EventNewTx})
+	hub.publish(&Event{Type: EventFinalizedBlock})
+	assert.Equal(EventNewTx, (<-all.C).Type)
+	assert.Equal(EventFinalizedBlock, (<-all.C).Type)
+	assert.Equal(EventFinalizedBlock, (<-blocks.C).Type)
+
+	// The events beyond the buffer are dropped rather than blocking the publisher
+	hub.publish(&Event{Type: EventFinalizedBlock})
+	hub.publish(&Event{Type: EventFinalizedBlock})
+	assert.Equal(uint64(1), blocks.Dropped())
+	assert.Equal(uint64(0), all.Dropped())
+
+	// No more events are delivered once unsubscribed
+	blocks.Unsubscribe()
+	blocks.Unsubscribe()
+	hub.publish(&Event{Type: EventFinalizedBlock})
+	_, ok := <-blocks.C
+	assert.True(ok) // the event buffered before
+	_, ok = <-blocks.C
+	assert.False(ok)
+	assert.Equal(3, len(all.C))
+}
+
+func TestOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := newOptions(nil)
+	assert.True(opts.enabled(SubsystemRPC))
+	assert.True(opts.enabled(SubsystemSnapshots))
+	assert.NotNil(opts.newDispatcher(nil, nil))
+
+	var created bool
+	opts = newOptions([]Option{
+		Without(SubsystemRPC, SubsystemReporter),
+		WithDispatcher(func(networkOld p2p.Network, network p2pl.Network) *dp.Dispatcher {
+			created = true
+			return dp.NewDispatcher(networkOld, network)
+		}),
+	})
+	assert.False(opts.enabled(SubsystemRPC))
+	assert.False(opts.enabled(SubsystemReporter))
+	assert.True(opts.enabled(SubsystemSnapshots))
+	opts.newDispatcher(nil, nil)
+	assert.True(created)
+}
@@ -0,0 +1,77 @@
+package node
+
+import (
+	"github.com/thetatoken/theta/consensus"
+	dp "github.com/thetatoken/theta/dispatcher"
+	mp "github.com/thetatoken/theta/mempool"
+	"github.com/thetatoken/theta/p2p"
+	"github.com/thetatoken/theta/p2pl"
+)
+
+//
+// The options let the forks and the tests assemble a node from custom components, or without some of its
+// subsystems, instead of copying the construction of the node.
+//
+
+// Subsystem is an optional subsystem of the node
+type Subsystem byte
+
+const (
+	SubsystemRPC       Subsystem = iota + 1 // the RPC server, also disabled by the config
+	SubsystemSnapshots                      // the scheduled snapshots
+	SubsystemReporter                       // the reporting of the node to its peers
+)
+
+// DispatcherFactory creates the dispatcher of the messages over the given networks, either of which may be nil
+type DispatcherFactory func(networkOld p2p.Network, network p2pl.Network) *dp.Dispatcher
+
+// MempoolFactory creates the mempool relaying the transactions with the dispatcher
+type MempoolFactory func(dispatcher *dp.Dispatcher, consensus *consensus.ConsensusEngine) *mp.Mempool
+
+// Option customizes the construction of a node
+type Option func(opts *options)
+
+type options struct {
+	newDispatcher DispatcherFactory
+	newMempool    MempoolFactory
+	disabled      map[Subsystem]bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		newDispatcher: dp.NewDispatcher,
+		newMempool:    mp.CreateMempool,
+		disabled:      make(map[Subsystem]bool),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithDispatcher creates the dispatcher with the given factory, e.g. to route the messages over a custom transport
+func WithDispatcher(factory DispatcherFactory) Option {
+	return func(opts *options) {
+		opts.newDispatcher = factory
+	}
+}
+
+// WithMempool creates the mempool with the given factory, e.g. to wrap or configure it differently
+func WithMempool(factory MempoolFactory) Option {
+	return func(opts *options) {
+		opts.newMempool = factory
+	}
+}
+
+// Without disables the given subsystems
+func Without(subsystems ...Subsystem) Option {
+	return func(opts *options) {
+		for _, subsystem := range subsystems {
+			opts.disabled[subsystem] = true
+		}
+	}
+}
+
+func (opts *options) enabled(subsystem Subsystem) bool {
+	return !opts.disabled[subsystem]
+}
@@ -0,0 +1,61 @@
+package node
+
+import (
+	"sync"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/consensus"
+	"github.com/thetatoken/theta/core"
+	ld "github.com/thetatoken/theta/ledger"
+)
+
+//
+// PeerRoleResolver determines whether a peer is a validator or a guardian based on the
+// validator set and the guardian candidate pool of the last finalized block
+//
+type PeerRoleResolver struct {
+	mu sync.Mutex
+
+	consensus *consensus.ConsensusEngine
+	ledger    *ld.Ledger
+
+	// The guardian candidate pool is cached since loading it from the state is expensive
+	gcpBlockHash common.Hash
+	gcp          *core.GuardianCandidatePool
+}
+
+// NewPeerRoleResolver creates an instance of PeerRoleResolver
+func NewPeerRoleResolver(consensus *consensus.ConsensusEngine, ledger *ld.Ledger) *PeerRoleResolver {
+	return &PeerRoleResolver{
+		consensus: consensus,
+		ledger:    ledger,
+	}
+}
+
+// IsValidator returns whether the given peer is a validator
+func (r *PeerRoleResolver) IsValidator(peerID string) bool {
+	lfb := r.consensus.GetLastFinalizedBlock()
+	vs := r.consensus.GetValidatorManager().GetNextValidatorSet(lfb.Hash())
+	if vs == nil {
+		return false
+	}
+	_, err := vs.GetValidator(common.HexToAddress(peerID))
+	return err == nil
+}
+
+// IsGuardian returns whether the given peer is a guardian
+func (r *PeerRoleResolver) IsGuardian(peerID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lfb := r.consensus.GetLastFinalizedBlock()
+	if r.gcp == nil || r.gcpBlockHash != lfb.Hash() {
+		gcp, err := r.ledger.GetGuardianCandidatePool(lfb.Hash())
+		if err != nil {
+			return false
+		}
+		r.gcp = gcp
+		r.gcpBlockHash = lfb.Hash()
+	}
+	return r.gcp.Contains(common.HexToAddress(peerID))
+}
@@ -4,6 +4,14 @@ import (
 	"github.com/thetatoken/theta/common"
 )
 
+const (
+	defaultChannelPriority = uint(0)
+	voteChannelPriority    = uint(1)
+
+	// voteChannelQueueCapacity is the number of the votes which can be queued for sending to a peer
+	voteChannelQueueCapacity = 64
+)
+
 //
 // Channel models a bi-directional channel for messsaging between two peers
 //
@@ -20,7 +28,7 @@ type Channel struct {
 // ChannelConfig specifies the configuration of a Channel
 //
 type ChannelConfig struct {
-	priority uint
+	priority uint // channels with higher priority are served first
 }
 
 // createDefaultChannel creates a channel with default configs
@@ -33,6 +41,37 @@ func createDefaultChannel(channelID common.ChannelIDEnum) Channel {
 	return channel
 }
 
+// createVoteChannel creates a channel for the consensus votes. Its packets are sent ahead of those of the
+// default channels, so the votes are not delayed by large block bodies near the checkpoints. When its queue
+// is full, the oldest vote is dropped instead of blocking the sender, since a vote is only useful for a
+// short while.
+func createVoteChannel(channelID common.ChannelIDEnum) Channel {
+	chCfg := ChannelConfig{
+		priority: voteChannelPriority,
+	}
+	sbCfg := getDefaultSendBufferConfig()
+	sbCfg.queueCapacity = voteChannelQueueCapacity
+	sbCfg.dropOldest = true
+	rbCfg := getDefaultRecvBufferConfig()
+
+	channel := createChannel(channelID, chCfg, sbCfg, rbCfg)
+	return channel
+}
+
+// createPriorityChannel creates a channel served ahead of the default channels like the vote channels, but
+// with the default send buffer, for the messages which must not be dropped, e.g. the reward-bearing elite
+// edge node votes
+func createPriorityChannel(channelID common.ChannelIDEnum) Channel {
+	chCfg := ChannelConfig{
+		priority: voteChannelPriority,
+	}
+	sbCfg := getDefaultSendBufferConfig()
+	rbCfg := getDefaultRecvBufferConfig()
+
+	channel := createChannel(channelID, chCfg, sbCfg, rbCfg)
+	return channel
+}
+
 // createChannel creates a channel for the given configs
 func createChannel(channelID common.ChannelIDEnum, channelConf ChannelConfig, sbConf SendBufferConfig, rbConf RecvBufferConfig) Channel {
 	sendBuf := createSendBuffer(sbConf)
@@ -48,7 +87,7 @@ func createChannel(channelID common.ChannelIDEnum, channelConf ChannelConfig, sb
 // createChannel creates the default channel config
 func getDefaultChannelConfig() ChannelConfig {
 	return ChannelConfig{
-		priority: 0,
+		priority: defaultChannelPriority,
 	}
 }
 
@@ -57,6 +96,11 @@ func (ch *Channel) getID() common.ChannelIDEnum {
 	return ch.id
 }
 
+// getPriority returns the priority of the channel
+func (ch *Channel) getPriority() uint {
+	return ch.config.priority
+}
+
 // enqueueMessage queues the the given message into the channel
 func (ch *Channel) enqueueMessage(bytes []byte) bool {
 	success := ch.sendBuf.insert(bytes)
@@ -75,6 +119,11 @@ func (ch *Channel) receivePacket(packet *Packet) ([]byte, bool) {
 	return bytes, success
 }
 
+// releaseRecvBuffer gives back the memory accounted for the receive buffer
+func (ch *Channel) releaseRecvBuffer() {
+	ch.recvBuf.release()
+}
+
 // sendPacketTo serializes and sends the next packet to the given writer
 func (ch *Channel) sendPacketTo(conn *Connection) (nonemptyPacket bool, numBytes int, err error) {
 	packet := ch.sendBuf.emitPacket(ch.id)
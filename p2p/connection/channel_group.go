@@ -8,6 +8,11 @@ import (
 
 const (
 	channelSelectionRoundRobinStrategy = 1
+
+	// priorityChannelWeight is the max number of packets the priority channels send in a row while the
+	// default channels have packets to send, so a steady vote traffic cannot starve the block and proposal
+	// channels
+	priorityChannelWeight = 8
 )
 
 //
@@ -20,6 +25,8 @@ type ChannelGroup struct {
 	channels   []*Channel                        // For iteration with deterministic order
 
 	channelSelector ChannelSelector
+	priorityIndex   int // index of the last served channel with priority
+	priorityStreak  int // number of packets the priority channels sent in a row
 
 	config ChannelGroupConfig
 }
@@ -51,6 +58,7 @@ func createChannelGroup(cgConfig ChannelGroupConfig, channels []*Channel) (bool,
 		mutex:           &sync.Mutex{},
 		channelMap:      make(map[common.ChannelIDEnum]*Channel),
 		channelSelector: channelSelector,
+		priorityIndex:   -1,
 		config:          cgConfig,
 	}
 
@@ -133,6 +141,10 @@ func (cg *ChannelGroup) getTotalNumChannels() uint {
 }
 
 func (cg *ChannelGroup) nextChannelToSendPacket() (success bool, channel *Channel) {
+	if channel := cg.nextPriorityChannelToSendPacket(false); channel != nil {
+		return true, channel
+	}
+
 	channels := cg.getAllChannels()
 	totalNumberOfChannels := cg.getTotalNumChannels()
 	for i := uint(0); i < totalNumberOfChannels; i++ {
@@ -144,14 +156,61 @@ func (cg *ChannelGroup) nextChannelToSendPacket() (success bool, channel *Channe
 			return false, nil
 		}
 		selectedChannel := (*channels)[selectedChannelIndex]
-		if !selectedChannel.hasPacketToSend() {
+		if selectedChannel.getPriority() != defaultChannelPriority || !selectedChannel.hasPacketToSend() {
 			continue
 		}
+		cg.resetPriorityStreak()
 		return true, selectedChannel
 	}
+
+	// Only the priority channels have packets to send
+	if channel := cg.nextPriorityChannelToSendPacket(true); channel != nil {
+		return true, channel
+	}
 	return true, nil
 }
 
+// nextPriorityChannelToSendPacket returns the channel with the highest priority which has packets to send,
+// or nil if no such channel. Channels of the same priority are served in the round robin fashion. Unless
+// ignoreWeight is set, nil is returned once the priority channels have used up their weight, to give the
+// default channels a turn.
+func (cg *ChannelGroup) nextPriorityChannelToSendPacket(ignoreWeight bool) *Channel {
+	cg.mutex.Lock()
+	defer cg.mutex.Unlock()
+
+	if !ignoreWeight && cg.priorityStreak >= priorityChannelWeight {
+		return nil
+	}
+
+	numChannels := len(cg.channels)
+	selectedIndex := -1
+	for i := 1; i <= numChannels; i++ {
+		idx := (cg.priorityIndex + i) % numChannels
+		ch := cg.channels[idx]
+		if ch.getPriority() == defaultChannelPriority || !ch.hasPacketToSend() {
+			continue
+		}
+		if selectedIndex < 0 || ch.getPriority() > cg.channels[selectedIndex].getPriority() {
+			selectedIndex = idx
+		}
+	}
+	if selectedIndex < 0 {
+		return nil
+	}
+	cg.priorityIndex = selectedIndex
+	if cg.priorityStreak < priorityChannelWeight {
+		cg.priorityStreak++
+	}
+	return cg.channels[selectedIndex]
+}
+
+func (cg *ChannelGroup) resetPriorityStreak() {
+	cg.mutex.Lock()
+	defer cg.mutex.Unlock()
+
+	cg.priorityStreak = 0
+}
+
 //
 // RoundRobinChannelSelector implments the ChannelSelector interface
 // with the round robin strategy
@@ -253,6 +253,86 @@ func TestRoundRobinChannelSelector2(t *testing.T) {
 	assert.Equal(&ch5, ch)
 }
 
+func TestVoteChannelPriority(t *testing.T) {
+	assert := assert.New(t)
+
+	cg := newTestEmptyChannelGroup()
+	ch1 := createDefaultChannel(common.ChannelIDBlock)
+	ch2 := createVoteChannel(common.ChannelIDVote)
+	ch3 := createDefaultChannel(common.ChannelIDTransaction)
+	ch4 := createVoteChannel(common.ChannelIDGuardian)
+
+	assert.True(cg.addChannel(&ch1))
+	assert.True(cg.addChannel(&ch2))
+	assert.True(cg.addChannel(&ch3))
+	assert.True(cg.addChannel(&ch4))
+
+	// Only the default channels have messages to send
+	assert.True(ch1.enqueueMessage([]byte("block")))
+	assert.True(ch3.enqueueMessage([]byte("tx")))
+	success, ch := cg.nextChannelToSendPacket()
+	assert.True(success)
+	assert.Equal(&ch1, ch)
+
+	// The vote channels are served first, in the round robin fashion
+	assert.True(ch2.enqueueMessage([]byte("vote")))
+	assert.True(ch4.enqueueMessage([]byte("guardian vote")))
+	success, ch = cg.nextChannelToSendPacket()
+	assert.True(success)
+	assert.Equal(&ch2, ch)
+	success, ch = cg.nextChannelToSendPacket()
+	assert.True(success)
+	assert.Equal(&ch4, ch)
+	success, ch = cg.nextChannelToSendPacket()
+	assert.True(success)
+	assert.Equal(&ch2, ch)
+}
+
+func TestVoteChannelDoesNotStarveDefaultChannels(t *testing.T) {
+	assert := assert.New(t)
+
+	cg := newTestEmptyChannelGroup()
+	ch1 := createDefaultChannel(common.ChannelIDBlock)
+	ch2 := createPriorityChannel(common.ChannelIDEliteEdgeNodeVote)
+
+	assert.True(cg.addChannel(&ch1))
+	assert.True(cg.addChannel(&ch2))
+
+	// The vote message spans more packets than the weight of the vote channel
+	assert.True(ch2.enqueueMessage(make([]byte, 3*priorityChannelWeight*maxPayloadSize)))
+	assert.True(ch1.enqueueMessage([]byte("block")))
+
+	// The block channel is served once the vote channel has used up its weight
+	for i := 0; i < priorityChannelWeight; i++ {
+		success, ch := cg.nextChannelToSendPacket()
+		assert.True(success)
+		assert.Equal(&ch2, ch)
+		ch.sendBuf.emitPacket(ch.id)
+	}
+	success, ch := cg.nextChannelToSendPacket()
+	assert.True(success)
+	assert.Equal(&ch1, ch)
+	ch.sendBuf.emitPacket(ch.id)
+
+	// Without packets on the default channels, the vote channel is served again
+	success, ch = cg.nextChannelToSendPacket()
+	assert.True(success)
+	assert.Equal(&ch2, ch)
+}
+
+func TestEliteEdgeNodeVoteChannelsDoNotDrop(t *testing.T) {
+	assert := assert.New(t)
+
+	vote := createVoteChannel(common.ChannelIDVote)
+	assert.True(vote.sendBuf.config.dropOldest)
+
+	for _, channelID := range []common.ChannelIDEnum{common.ChannelIDEliteEdgeNodeVote, common.ChannelIDAggregatedEliteEdgeNodeVotes} {
+		ch := createPriorityChannel(channelID)
+		assert.False(ch.sendBuf.config.dropOldest)
+		assert.Equal(voteChannelPriority, ch.getPriority())
+	}
+}
+
 // --------------- Test Utilities --------------- //
 
 func newTestEmptyChannelGroup() ChannelGroup {
@@ -39,6 +39,7 @@ type Connection struct {
 
 	channelGroup ChannelGroup
 	onParse      MessageParser
+	onFilter     MessageFilter
 	onEncode     MessageEncoder
 	onReceive    ReceiveHandler
 	onError      ErrorHandler
@@ -81,6 +82,10 @@ type ConnectionConfig struct {
 // MessageParser parses the raw message bytes to type p2ptypes.Message
 type MessageParser func(channelID common.ChannelIDEnum, rawMessageBytes common.Bytes) (p2ptypes.Message, error)
 
+// MessageFilter returns true if the raw message bytes should be dropped before being parsed,
+// e.g. a gossip message already received from another peer
+type MessageFilter func(channelID common.ChannelIDEnum, rawMessageBytes common.Bytes) bool
+
 // MessageEncoder encodes type p2ptypes.Message to raw message bytes
 type MessageEncoder func(channelID common.ChannelIDEnum, message interface{}) (common.Bytes, error)
 
@@ -104,14 +109,15 @@ func CreateConnection(netconn net.Conn, config ConnectionConfig) *Connection {
 	channelHeader := createDefaultChannel(common.ChannelIDHeader)
 	channelBlock := createDefaultChannel(common.ChannelIDBlock)
 	channelProposal := createDefaultChannel(common.ChannelIDProposal)
-	channelVote := createDefaultChannel(common.ChannelIDVote)
+	channelVote := createVoteChannel(common.ChannelIDVote)
 	channelTransaction := createDefaultChannel(common.ChannelIDTransaction)
 	channelPeerDiscover := createDefaultChannel(common.ChannelIDPeerDiscovery)
 	channelPing := createDefaultChannel(common.ChannelIDPing)
-	channelGuardian := createDefaultChannel(common.ChannelIDGuardian)
+	channelGuardian := createVoteChannel(common.ChannelIDGuardian)
 	channelNATMapping := createDefaultChannel(common.ChannelIDNATMapping)
-	channelEliteEdgeNodeVote := createDefaultChannel(common.ChannelIDEliteEdgeNodeVote)
-	channelEliteAggregatedEdgeNodeVotes := createDefaultChannel(common.ChannelIDAggregatedEliteEdgeNodeVotes)
+	channelEliteEdgeNodeVote := createPriorityChannel(common.ChannelIDEliteEdgeNodeVote)
+	channelEliteAggregatedEdgeNodeVotes := createPriorityChannel(common.ChannelIDAggregatedEliteEdgeNodeVotes)
+	channelCompactBlock := createDefaultChannel(common.ChannelIDCompactBlock)
 	channels := []*Channel{
 		&channelCheckpoint,
 		&channelHeader,
@@ -125,6 +131,7 @@ func CreateConnection(netconn net.Conn, config ConnectionConfig) *Connection {
 		&channelNATMapping,
 		&channelEliteEdgeNodeVote,
 		&channelEliteAggregatedEdgeNodeVotes,
+		&channelCompactBlock,
 	}
 
 	success, channelGroup := createChannelGroup(getDefaultChannelGroupConfig(), channels)
@@ -222,6 +229,11 @@ func (conn *Connection) SetMessageParser(messageParser MessageParser) {
 	conn.onParse = messageParser
 }
 
+// SetMessageFilter sets the message filter for the connection
+func (conn *Connection) SetMessageFilter(messageFilter MessageFilter) {
+	conn.onFilter = messageFilter
+}
+
 // SetMessageEncoder sets the message encoder for the connection
 func (conn *Connection) SetMessageEncoder(messageEncoder MessageEncoder) {
 	conn.onEncode = messageEncoder
@@ -404,6 +416,7 @@ func (conn *Connection) writePacket(packet *Packet) error {
 func (conn *Connection) recvRoutine() {
 	//defer conn.wg.Done() // NOTE: rlp.Decode() is a blocking call
 	defer conn.recover()
+	defer conn.releaseRecvBuffers()
 
 	for {
 		select {
@@ -436,6 +449,14 @@ func (conn *Connection) recvRoutine() {
 	}
 }
 
+// releaseRecvBuffers gives back the memory accounted for the receive buffers, which are only used by the
+// recvRoutine
+func (conn *Connection) releaseRecvBuffers() {
+	for _, channel := range *conn.channelGroup.getAllChannels() {
+		channel.releaseRecvBuffer()
+	}
+}
+
 func (conn *Connection) handlePingPong(packet *Packet) (success bool) {
 	if packet.ChannelID != common.ChannelIDPing {
 		logger.Errorf("Invalid channel for Ping/Pong signal")
@@ -476,6 +497,10 @@ func (conn *Connection) handleReceivedPacket(packet *Packet) (success bool) {
 		return true
 	}
 
+	if conn.onFilter != nil && conn.onFilter(packet.ChannelID, aggregatedBytes) {
+		return true
+	}
+
 	message, err := conn.onParse(packet.ChannelID, aggregatedBytes)
 	if err != nil {
 		logger.Errorf("Error parsing packet: %v, err: %v", packet, err)
@@ -1,7 +1,10 @@
 package connection
 
+import "github.com/thetatoken/theta/common/membudget"
+
 type RecvBuffer struct {
 	workspace []byte
+	grown     int64 // capacity of the workspace beyond the configured one, accounted in the memory budget
 
 	config  RecvBufferConfig
 	chanSeq uint
@@ -9,6 +12,7 @@ type RecvBuffer struct {
 
 type RecvBufferConfig struct {
 	workspaceCapacity int
+	memory            *membudget.Allocation
 }
 
 // createRecvBuffer creates a RecvBuffer instance for the given config
@@ -23,6 +27,7 @@ func createRecvBuffer(config RecvBufferConfig) RecvBuffer {
 func getDefaultRecvBufferConfig() RecvBufferConfig {
 	return RecvBufferConfig{
 		workspaceCapacity: 4 * 1024, // 4 KB
+		memory:            membudget.Get(membudget.P2P),
 	}
 }
 
@@ -43,14 +48,21 @@ func (rb *RecvBuffer) receivePacket(packet *Packet) ([]byte, bool) {
 	}
 
 	rb.workspace = append(rb.workspace, packet.Bytes...)
+	rb.trackCapacity()
 	if packet.IsEOF == byte(0x01) {
 		bytes := rb.workspace
 
 		// clear the slice without re-allocating.
 		// http://stackoverflow.com/questions/16971741/how-do-you-clear-a-slice-in-go
 		//   suggests this could be a memory leak, but we might as well keep the memory for the channel until it closes,
-		//	at which point the recving slice stops being used and should be garbage collected
-		rb.workspace = rb.workspace[:0] // make([]byte, 0, rb.config.workspaceCapacity)
+		//	at which point the recving slice stops being used and should be garbage collected. Over the memory budget
+		//	though, the memory grown for a large message is given back.
+		if rb.config.memory.Exceeded() {
+			rb.workspace = make([]byte, 0, rb.config.workspaceCapacity)
+			rb.trackCapacity()
+		} else {
+			rb.workspace = rb.workspace[:0]
+		}
 		rb.chanSeq = 0
 
 		return bytes, true
@@ -59,3 +71,19 @@ func (rb *RecvBuffer) receivePacket(packet *Packet) ([]byte, bool) {
 	rb.chanSeq++
 	return nil, true
 }
+
+// trackCapacity accounts the capacity the workspace has grown by in the memory budget
+func (rb *RecvBuffer) trackCapacity() {
+	grown := int64(cap(rb.workspace) - rb.config.workspaceCapacity)
+	if grown < 0 {
+		grown = 0
+	}
+	rb.config.memory.Reserve(grown - rb.grown)
+	rb.grown = grown
+}
+
+// release gives back the memory accounted for the workspace, once the buffer is no longer used
+func (rb *RecvBuffer) release() {
+	rb.config.memory.Release(rb.grown)
+	rb.grown = 0
+}
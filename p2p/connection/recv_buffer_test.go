@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/membudget"
 )
 
 func TestDefaultRecvBuffer(t *testing.T) {
@@ -145,6 +146,35 @@ func TestRecvExtraLongMessage(t *testing.T) {
 	assert.True(sameBytes)
 }
 
+func TestRecvBufferMemoryBudget(t *testing.T) {
+	assert := assert.New(t)
+
+	config := getDefaultRecvBufferConfig()
+	config.memory = membudget.NewAllocation(membudget.P2P, 8*1024)
+	rb := createRecvBuffer(config)
+
+	// The memory grown for a large message is kept while under the budget
+	largeMsg := bytes.Repeat([]byte{1}, 8*1024)
+	_, success := rb.receivePacket(&Packet{Bytes: largeMsg, IsEOF: byte(0x01)})
+	assert.True(success)
+	grown := config.memory.Used()
+	assert.True(grown > 0)
+	assert.False(config.memory.Exceeded())
+
+	// and given back once over the budget
+	largeMsg = bytes.Repeat([]byte{1}, 16*1024)
+	recvBytes, success := rb.receivePacket(&Packet{Bytes: largeMsg, IsEOF: byte(0x01)})
+	assert.True(success)
+	assert.Equal(largeMsg, recvBytes)
+	assert.Equal(int64(0), config.memory.Used())
+	assert.Equal(config.workspaceCapacity, cap(rb.workspace))
+
+	rb.receivePacket(&Packet{Bytes: largeMsg, IsEOF: byte(0x00)})
+	assert.True(config.memory.Used() > 0)
+	rb.release()
+	assert.Equal(int64(0), config.memory.Used())
+}
+
 // --------------- Test Utilities --------------- //
 
 func newTestDefaultRecvBuffer() RecvBuffer {
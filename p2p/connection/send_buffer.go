@@ -8,9 +8,10 @@ import (
 )
 
 type SendBuffer struct {
-	workspace []byte
-	queue     chan []byte
-	queueSize int32
+	workspace  []byte
+	queue      chan []byte
+	queueSize  int32
+	numDropped uint64
 
 	config  SendBufferConfig
 	chanSeq uint
@@ -19,6 +20,7 @@ type SendBuffer struct {
 type SendBufferConfig struct {
 	queueCapacity int
 	timeOut       time.Duration
+	dropOldest    bool // drop the oldest queued message instead of blocking when the queue is full
 }
 
 // createSendBuffer creates a SendBuffer instance for the given config
@@ -57,6 +59,9 @@ func (sb *SendBuffer) canInsert() bool {
 // Insert insert the bytes to queue, and times out after
 // the configured timeout. It is goroutine safe
 func (sb *SendBuffer) insert(bytes []byte) bool {
+	if sb.config.dropOldest {
+		return sb.insertDropOldest(bytes)
+	}
 	select {
 	case sb.queue <- bytes:
 		atomic.AddInt32(&sb.queueSize, 1)
@@ -69,6 +74,9 @@ func (sb *SendBuffer) insert(bytes []byte) bool {
 // attemptInsert attempts to insert bytes into the queue. It is a
 // non-blocking call. It is goroutine safe
 func (sb *SendBuffer) attemptInsert(bytes []byte) bool {
+	if sb.config.dropOldest {
+		return sb.insertDropOldest(bytes)
+	}
 	select {
 	case sb.queue <- bytes:
 		atomic.AddInt32(&sb.queueSize, 1)
@@ -78,6 +86,32 @@ func (sb *SendBuffer) attemptInsert(bytes []byte) bool {
 	}
 }
 
+// insertDropOldest inserts the bytes to the queue, and drops the oldest queued
+// messages to make room if necessary. It never blocks. It is goroutine safe
+func (sb *SendBuffer) insertDropOldest(bytes []byte) bool {
+	for {
+		select {
+		case sb.queue <- bytes:
+			atomic.AddInt32(&sb.queueSize, 1)
+			return true
+		default:
+		}
+
+		select {
+		case <-sb.queue:
+			atomic.AddInt32(&sb.queueSize, -1)
+			atomic.AddUint64(&sb.numDropped, 1)
+		default:
+		}
+	}
+}
+
+// getNumDropped returns the number of the messages dropped to make room for
+// the newer ones. It is goroutine safe
+func (sb *SendBuffer) getNumDropped() uint64 {
+	return atomic.LoadUint64(&sb.numDropped)
+}
+
 // EmitPacket emits a packet extracted from the bytes stored in the workspace
 func (sb *SendBuffer) emitPacket(channelID common.ChannelIDEnum) Packet {
 	if sb.workspace == nil || len(sb.workspace) == 0 {
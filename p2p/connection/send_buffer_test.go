@@ -195,6 +195,30 @@ func TestAttemptInsert(t *testing.T) {
 	assert.Equal(uint(0), packet.SeqID)
 }
 
+func TestInsertDropOldest(t *testing.T) {
+	assert := assert.New(t)
+	config := getDefaultSendBufferConfig()
+	config.queueCapacity = 2
+	config.dropOldest = true
+	sb := createSendBuffer(config)
+
+	assert.True(sb.insert([]byte("vote1")))
+	assert.True(sb.attemptInsert([]byte("vote2")))
+	assert.False(sb.canInsert())
+
+	// The oldest vote is dropped to make room for the new one
+	assert.True(sb.insert([]byte("vote3")))
+	assert.Equal(2, sb.getSize())
+	assert.Equal(uint64(1), sb.getNumDropped())
+
+	packet := sb.emitPacket(common.ChannelIDVote)
+	assert.Equal([]byte("vote2"), packet.Bytes)
+	packet = sb.emitPacket(common.ChannelIDVote)
+	assert.Equal([]byte("vote3"), packet.Bytes)
+	assert.True(sb.isEmpty())
+	assert.Equal(0, sb.getSize())
+}
+
 // --------------- Test Utilities --------------- //
 
 func newTestDefaultSendBuffer() SendBuffer {
@@ -2,6 +2,7 @@ package p2p
 
 import (
 	"context"
+	"time"
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/p2p/types"
@@ -54,6 +55,15 @@ type Network interface {
 	// PeerURLs return the URLs of all peers
 	PeerURLs(skipEdgeNode bool) []string
 
+	// PeerCapabilities returns the capabilities advertised by the peers, keyed by the peer IDs.
+	// The capabilities are nil for the peers that do not advertise them
+	PeerCapabilities(skipEdgeNode bool) map[string]*types.NodeCapabilities
+
+	// ProbeTopology asks the neighboring peers for their peers, and returns the neighbors reported by the
+	// peers that replied within the timeout, keyed by the peer IDs. The neighbors of the local node are
+	// included under its own ID
+	ProbeTopology(timeout time.Duration) (map[string][]types.TopologyNeighbor, error)
+
 	// PeerExists indicates if the given peerID is a neighboring peer
 	PeerExists(peerID string) bool
 
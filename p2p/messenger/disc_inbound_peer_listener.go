@@ -119,7 +119,9 @@ func (ipl *InboundPeerListener) listenRoutine() {
 	defer ipl.wg.Done()
 
 	seedPeerOnly := viper.GetBool(common.CfgP2PSeedPeerOnly)
+	inboundDisabled := viper.GetBool(common.CfgP2PInboundDisabled)
 	maxNumPeers := GetDefaultPeerDiscoveryManagerConfig().MaxNumPeers
+	numReservedPeers := pr.MaxNumPeersForRole(pr.PeerRoleValidator) + pr.MaxNumPeersForRole(pr.PeerRoleGuardian)
 	logger.Infof("InboundPeerListener listen routine started, seedPeerOnly set to %v", seedPeerOnly)
 
 	//purgeAllNonSeedPeersInterval := time.Duration(viper.GetInt(common.CfgP2PBootstrapNodePurgePeerInterval)) * time.Second
@@ -139,6 +141,12 @@ func (ipl *InboundPeerListener) listenRoutine() {
 		}
 
 		remoteAddr := netutil.NewNetAddress(netconn.RemoteAddr())
+		if inboundDisabled {
+			logger.Debugf("Inbound connections disabled, ignore inbound connection request from %v", remoteAddr.String())
+			netconn.Close()
+			continue
+		}
+
 		if seedPeerOnly {
 			isNotASeedPeer := !ipl.discMgr.seedPeerConnector.isASeedPeerIgnoringPort(remoteAddr)
 			if isNotASeedPeer {
@@ -149,11 +157,13 @@ func (ipl *InboundPeerListener) listenRoutine() {
 				logger.Infof("Accept inbound connection from seed peer %v", remoteAddr.String())
 			}
 		} else {
+			// The role of the peer is not known until the handshake completes, so the
+			// slots reserved for validators and guardians are checked after the handshake
 			skipEdgeNode := !viper.GetBool(common.CfgP2PIsBootstrapNode)
 			numPeers := int(ipl.discMgr.peerTable.GetTotalNumPeers(skipEdgeNode))
-			if numPeers >= maxNumPeers {
+			if numPeers >= maxNumPeers+numReservedPeers {
 				if viper.GetBool(common.CfgP2PConnectionFIFO) {
-					purgedPeer := ipl.discMgr.peerTable.PurgeOldestPeer()
+					purgedPeer := ipl.discMgr.peerTable.PurgeOldestPeerWithRole(pr.PeerRoleUnknown)
 					if purgedPeer != nil {
 						purgedPeer.Stop()
 						logger.Infof("Purged old peer %v to make room for inbound connection request from %v", purgedPeer.ID(), remoteAddr.String())
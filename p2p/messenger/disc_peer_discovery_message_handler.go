@@ -24,6 +24,8 @@ type PeerDiscoveryMessageType byte
 const (
 	peerAddressesRequestType PeerDiscoveryMessageType = 0x01
 	peerAddressesReplyType   PeerDiscoveryMessageType = 0x02
+	peerTopologyRequestType  PeerDiscoveryMessageType = 0x03
+	peerTopologyReplyType    PeerDiscoveryMessageType = 0x04
 )
 
 const (
@@ -33,6 +35,7 @@ const (
 	requestPeersAddressesPercent      = 25      // 25%
 	peersAddressesSubSamplingPercent  = 50      // 50%
 	discoverInterval                  = 3000    // 3 sec
+	maxNumTopologyNeighbors           = 1024
 )
 
 // PeerDiscoveryMessage defines the structure of the peer discovery message
@@ -40,6 +43,14 @@ type PeerDiscoveryMessage struct {
 	Type         PeerDiscoveryMessageType
 	SourcePeerID string
 	Addresses    []pr.PeerIDAddress
+	Neighbors    []types.TopologyNeighbor `rlp:"tail"` // only in the topology replies, so the other messages are unchanged
+}
+
+// topologyProbe collects the replies to a topology probe
+type topologyProbe struct {
+	pending map[string]bool // IDs of the peers yet to reply
+	replies map[string][]types.TopologyNeighbor
+	done    chan struct{}
 }
 
 //
@@ -52,6 +63,9 @@ type PeerDiscoveryMessageHandler struct {
 	peerDiscoveryPulseInterval time.Duration
 	discoveryCallback          InboundCallback
 
+	probeLock *sync.Mutex
+	probe     *topologyProbe // the topology probe in progress, nil if none
+
 	// Life cycle
 	wg      *sync.WaitGroup
 	quit    chan struct{}
@@ -65,6 +79,7 @@ func createPeerDiscoveryMessageHandler(discMgr *PeerDiscoveryManager, selfNetAdd
 	pdmh := PeerDiscoveryMessageHandler{
 		discMgr:                    discMgr,
 		peerDiscoveryPulseInterval: defaultPeerDiscoveryPulseInterval,
+		probeLock:                  &sync.Mutex{},
 		wg:                         &sync.WaitGroup{},
 	}
 	selfNetAddress, err := netutil.NewNetAddressString(selfNetAddressStr)
@@ -152,6 +167,10 @@ func (pdmh *PeerDiscoveryMessageHandler) HandleMessage(msg types.Message) error
 		pdmh.handlePeerAddressRequest(peer, discMsg)
 	case peerAddressesReplyType:
 		pdmh.handlePeerAddressReply(peer, discMsg)
+	case peerTopologyRequestType:
+		pdmh.handlePeerTopologyRequest(peer, discMsg)
+	case peerTopologyReplyType:
+		pdmh.handlePeerTopologyReply(peer, discMsg)
 	default:
 		errMsg := "Invalid PeerDiscoveryMessageType"
 		logger.Errorf(errMsg)
@@ -197,6 +216,99 @@ func (pdmh *PeerDiscoveryMessageHandler) handlePeerAddressReply(peer *pr.Peer, m
 	}
 }
 
+func (pdmh *PeerDiscoveryMessageHandler) handlePeerTopologyRequest(peer *pr.Peer, message PeerDiscoveryMessage) {
+	if !viper.GetBool(common.CfgP2PTopologyProbe) {
+		logger.Debugf("Ignored the topology request from %v, the topology probe is not enabled", peer.ID())
+		return
+	}
+	reply := PeerDiscoveryMessage{
+		Type:      peerTopologyReplyType,
+		Neighbors: pdmh.localNeighbors(),
+	}
+	peer.Send(common.ChannelIDPeerDiscovery, reply)
+}
+
+func (pdmh *PeerDiscoveryMessageHandler) handlePeerTopologyReply(peer *pr.Peer, message PeerDiscoveryMessage) {
+	pdmh.probeLock.Lock()
+	defer pdmh.probeLock.Unlock()
+
+	probe := pdmh.probe
+	if probe == nil || !probe.pending[peer.ID()] {
+		return // not solicited, or already replied
+	}
+	neighbors := message.Neighbors
+	if len(neighbors) > maxNumTopologyNeighbors {
+		neighbors = neighbors[:maxNumTopologyNeighbors]
+	}
+	probe.replies[peer.ID()] = neighbors
+	delete(probe.pending, peer.ID())
+	if len(probe.pending) == 0 {
+		close(probe.done)
+	}
+}
+
+// localNeighbors returns the topology entries of the peers of the local node
+func (pdmh *PeerDiscoveryMessageHandler) localNeighbors() []types.TopologyNeighbor {
+	neighbors := []types.TopologyNeighbor{}
+	for _, peer := range *(pdmh.discMgr.peerTable.GetAllPeers(false)) {
+		if len(neighbors) >= maxNumTopologyNeighbors {
+			break
+		}
+		neighbors = append(neighbors, types.NewTopologyNeighbor(peer.ID(), peer.Role().String(), peer.IsOutbound(), peer.Capabilities()))
+	}
+	return neighbors
+}
+
+// probeTopology asks all the peers for their neighbors, and waits for the replies until the timeout.
+// Only one probe can be in progress at a time.
+func (pdmh *PeerDiscoveryMessageHandler) probeTopology(timeout time.Duration) (map[string][]types.TopologyNeighbor, error) {
+	if !viper.GetBool(common.CfgP2PTopologyProbe) {
+		return nil, errors.New("the topology probe is not enabled")
+	}
+
+	peers := *(pdmh.discMgr.peerTable.GetAllPeers(false))
+	probe := &topologyProbe{
+		pending: make(map[string]bool),
+		replies: make(map[string][]types.TopologyNeighbor),
+		done:    make(chan struct{}),
+	}
+	for _, peer := range peers {
+		probe.pending[peer.ID()] = true
+	}
+
+	pdmh.probeLock.Lock()
+	if pdmh.probe != nil {
+		pdmh.probeLock.Unlock()
+		return nil, errors.New("another topology probe is in progress")
+	}
+	pdmh.probe = probe
+	if len(probe.pending) == 0 {
+		close(probe.done)
+	}
+	pdmh.probeLock.Unlock()
+
+	request := PeerDiscoveryMessage{
+		Type: peerTopologyRequestType,
+	}
+	for _, peer := range peers {
+		peer.Send(common.ChannelIDPeerDiscovery, request)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-probe.done:
+	case <-timer.C:
+	}
+
+	pdmh.probeLock.Lock()
+	defer pdmh.probeLock.Unlock()
+	pdmh.probe = nil
+	replies := probe.replies
+	replies[pdmh.discMgr.messenger.ID()] = pdmh.localNeighbors()
+	return replies, nil
+}
+
 // SetDiscoveryCallback sets the discovery callback function
 func (pdmh *PeerDiscoveryMessageHandler) SetDiscoveryCallback(disccb InboundCallback) {
 	pdmh.discoveryCallback = disccb
@@ -3,7 +3,9 @@ package messenger
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
+	"strings"
 	"sync"
 	"time"
 
@@ -29,6 +31,9 @@ type PeerDiscoveryManager struct {
 
 	seedPeerOnly bool
 
+	roleResolver   pr.PeerRoleResolver
+	privatePeerIDs map[string]bool
+
 	// Three mechanisms for peer discovery
 	seedPeerConnector   SeedPeerConnector           // pro-actively connect to seed peers
 	peerDiscMsgHandler  PeerDiscoveryMessageHandler // pro-actively connect to peer candidates obtained from connected peers
@@ -66,6 +71,15 @@ func CreatePeerDiscoveryManager(msgr *Messenger, nodeInfo *p2ptypes.NodeInfo, ad
 		wg:           &sync.WaitGroup{},
 	}
 
+	discMgr.privatePeerIDs = make(map[string]bool)
+	for _, pid := range strings.Split(viper.GetString(common.CfgP2PPrivatePeerIDs), ",") {
+		pid = strings.TrimSpace(pid)
+		if pid == "" {
+			continue
+		}
+		discMgr.privatePeerIDs[common.HexToAddress(pid).Hex()] = true
+	}
+
 	//discMgr.addrBook = NewAddrBook(addrBookFilePath, routabilityRestrict)
 
 	var err error
@@ -108,6 +122,11 @@ func (discMgr *PeerDiscoveryManager) SetMessenger(msgr *Messenger) {
 	discMgr.messenger = msgr
 }
 
+// SetPeerRoleResolver sets the resolver used to determine the connection slots of inbound peers
+func (discMgr *PeerDiscoveryManager) SetPeerRoleResolver(resolver pr.PeerRoleResolver) {
+	discMgr.roleResolver = resolver
+}
+
 // Start is called when the PeerDiscoveryManager starts
 func (discMgr *PeerDiscoveryManager) Start(ctx context.Context) error {
 	c, cancel := context.WithCancel(ctx)
@@ -201,9 +220,18 @@ func (discMgr *PeerDiscoveryManager) HandlePeerWithErrors(peer *pr.Peer) {
 	}
 }
 
+// getPeerConfig returns the config of the peers, which handshake with the chain ID of the messenger
+func (discMgr *PeerDiscoveryManager) getPeerConfig() pr.PeerConfig {
+	peerConfig := pr.GetDefaultPeerConfig()
+	if discMgr.messenger != nil {
+		peerConfig.ChainID = discMgr.messenger.config.chainID
+	}
+	return peerConfig
+}
+
 func (discMgr *PeerDiscoveryManager) connectToOutboundPeer(peerNetAddress *netutil.NetAddress, persistent bool) (*pr.Peer, error) {
 	logger.Debugf("Connecting to outbound peer: %v...", peerNetAddress)
-	peerConfig := pr.GetDefaultPeerConfig()
+	peerConfig := discMgr.getPeerConfig()
 	connConfig := cn.GetDefaultConnectionConfig()
 	peer, err := pr.CreateOutboundPeer(peerNetAddress, peerConfig, connConfig)
 	if err != nil {
@@ -217,7 +245,7 @@ func (discMgr *PeerDiscoveryManager) connectToOutboundPeer(peerNetAddress *netut
 
 func (discMgr *PeerDiscoveryManager) connectWithInboundPeer(netconn net.Conn, persistent bool) (*pr.Peer, error) {
 	logger.Infof("Connecting with inbound peer: %v...", netconn.RemoteAddr())
-	peerConfig := pr.GetDefaultPeerConfig()
+	peerConfig := discMgr.getPeerConfig()
 	connConfig := cn.GetDefaultConnectionConfig()
 	peer, err := pr.CreateInboundPeer(netconn, peerConfig, connConfig)
 	if err != nil {
@@ -232,7 +260,10 @@ func (discMgr *PeerDiscoveryManager) connectWithInboundPeer(netconn net.Conn, pe
 // handshakeAndAddPeer performs handshake with a peer. Upon successful handshake,
 // it save the peer to the peer table
 func (discMgr *PeerDiscoveryManager) handshakeAndAddPeer(peer *pr.Peer) error {
-	if err := peer.Handshake(discMgr.nodeInfo); err != nil {
+	// The role of the local node might change over time, so it is resolved for each handshake
+	nodeInfo := *discMgr.nodeInfo
+	nodeInfo.Capabilities = discMgr.localCapabilities()
+	if err := peer.Handshake(&nodeInfo); err != nil {
 		logger.Errorf("Failed to handshake with peer, error: %v", err)
 		return err
 	}
@@ -243,6 +274,14 @@ func (discMgr *PeerDiscoveryManager) handshakeAndAddPeer(peer *pr.Peer) error {
 		logger.Infof("Handshaked with a seed peer: %v, isOutbound: %v", peer.NetAddress(), peer.IsOutbound())
 	}
 
+	peer.SetPrivate(discMgr.isPrivatePeer(peer.ID()))
+	peer.SetRole(pr.ResolvePeerRole(peer, discMgr.roleResolver))
+	if !peer.IsOutbound() && !isSeed && !peer.IsPrivate() && !discMgr.acquireSlotForPeer(peer) {
+		errMsg := fmt.Sprintf("No connection slot available for %v peer %v", peer.Role(), peer.ID())
+		logger.Debugf(errMsg)
+		return errors.New(errMsg)
+	}
+
 	if discMgr.messenger != nil {
 		discMgr.messenger.AttachMessageHandlersToPeer(peer)
 	} else {
@@ -274,6 +313,41 @@ func (discMgr *PeerDiscoveryManager) handshakeAndAddPeer(peer *pr.Peer) error {
 	return nil
 }
 
+// acquireSlotForPeer checks if there is a connection slot for the role of the given inbound
+// peer. Peers of different roles do not compete for slots, so validators and guardians can
+// always connect even if the node is flooded with connection requests from other peers.
+func (discMgr *PeerDiscoveryManager) acquireSlotForPeer(peer *pr.Peer) bool {
+	role := peer.Role()
+	maxNumPeers := pr.MaxNumPeersForRole(role)
+	if maxNumPeers <= 0 {
+		return true
+	}
+	if int(discMgr.peerTable.GetNumPeersWithRole(role)) < maxNumPeers {
+		return true
+	}
+	if role == pr.PeerRoleUnknown && viper.GetBool(common.CfgP2PConnectionFIFO) {
+		purgedPeer := discMgr.peerTable.PurgeOldestPeerWithRole(role)
+		if purgedPeer != nil {
+			purgedPeer.Stop()
+			logger.Infof("Purged old peer %v to make room for inbound peer %v", purgedPeer.ID(), peer.ID())
+			return true
+		}
+	}
+	return false
+}
+
+// localCapabilities returns the capabilities of the local node to advertise to the peers
+func (discMgr *PeerDiscoveryManager) localCapabilities() *p2ptypes.NodeCapabilities {
+	nodeType := common.NodeType(viper.GetInt(common.CfgNodeType))
+	localID := discMgr.nodeInfo.PubKey.Address().Hex()
+	role := pr.ResolveNodeRole(nodeType, localID, discMgr.roleResolver)
+	return p2ptypes.CreateLocalNodeCapabilities(role.String())
+}
+
+func (discMgr *PeerDiscoveryManager) isPrivatePeer(pid string) bool {
+	return discMgr.privatePeerIDs[common.HexToAddress(pid).Hex()]
+}
+
 func (discMgr *PeerDiscoveryManager) isSeedPeer(pid string) bool {
 	discMgr.mutex.Lock()
 	defer discMgr.mutex.Unlock()
@@ -2,8 +2,10 @@ package messenger
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -12,15 +14,20 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/p2p"
 	pr "github.com/thetatoken/theta/p2p/peer"
+	"github.com/thetatoken/theta/p2p/seencache"
 	p2ptypes "github.com/thetatoken/theta/p2p/types"
+	"github.com/thetatoken/theta/version"
 )
 
 var logger *log.Entry = log.WithFields(log.Fields{"prefix": "p2p"})
 
+const peerVersionsCheckInterval = time.Minute
+
 //
 // Messenger implements the Network interface
 //
@@ -30,6 +37,7 @@ type Messenger struct {
 	discMgr       *PeerDiscoveryManager
 	natMgr        *NATManager
 	msgHandlerMap map[common.ChannelIDEnum](p2p.MessageHandler)
+	seenCache     *seencache.SeenCache
 
 	peerTable pr.PeerTable
 	nodeInfo  p2ptypes.NodeInfo // information of our blockchain node
@@ -52,6 +60,7 @@ type MessengerConfig struct {
 	routabilityRestrict bool
 	skipUPNP            bool
 	networkProtocol     string
+	chainID             string // The chain ID sent in the handshake, the genesis chain ID in the config if empty
 }
 
 // CreateMessenger creates an instance of Messenger
@@ -70,6 +79,7 @@ func CreateMessenger(privKey *crypto.PrivateKey, seedPeerNetAddresses []string,
 
 	messenger := &Messenger{
 		msgHandlerMap: make(map[common.ChannelIDEnum](p2p.MessageHandler)),
+		seenCache:     seencache.NewDefaultSeenCache(),
 		peerTable:     pr.CreatePeerTable(),
 		nodeInfo:      p2ptypes.CreateLocalNodeInfo(privKey, uint16(eport)),
 		config:        msgrConfig,
@@ -114,6 +124,11 @@ func (msgr *Messenger) SetPeerDiscoveryManager(discMgr *PeerDiscoveryManager) {
 	msgr.discMgr = discMgr
 }
 
+// SetPeerRoleResolver sets the resolver used to assign connection slots to peers by their roles
+func (msgr *Messenger) SetPeerRoleResolver(resolver pr.PeerRoleResolver) {
+	msgr.discMgr.SetPeerRoleResolver(resolver)
+}
+
 // SetPeerDiscoveryManager sets the PeerDiscoveryManager for the Messenger
 func (msgr *Messenger) SetNATManager(natMgr *NATManager) {
 	msgr.natMgr = natMgr
@@ -134,9 +149,43 @@ func (msgr *Messenger) Start(ctx context.Context) error {
 		err = msgr.natMgr.Start(c)
 	}
 
+	msgr.wg.Add(1)
+	go msgr.peerVersionsRoutine()
+
 	return err
 }
 
+// peerVersionsRoutine periodically compares the software versions of the peers against the local version,
+// and advises upgrading the node when a significant share of the peers runs a newer version
+func (msgr *Messenger) peerVersionsRoutine() {
+	defer msgr.wg.Done()
+
+	ticker := time.NewTicker(peerVersionsCheckInterval)
+	defer ticker.Stop()
+	upgradeAdvised := false
+	for {
+		select {
+		case <-msgr.ctx.Done():
+			return
+		case <-ticker.C:
+			summary := p2ptypes.SummarizePeerVersions(version.Version, msgr.PeerCapabilities(false))
+			metrics.GetOrRegisterGauge("p2p/peers_newer_version", nil).Update(int64(summary.NumNewer))
+			metrics.GetOrRegisterGauge("p2p/peers_newer_version_percent", nil).Update(int64(summary.NewerPercent))
+			advisedGauge := int64(0)
+			if summary.UpgradeAdvised {
+				advisedGauge = 1
+			}
+			metrics.GetOrRegisterGauge("p2p/upgrade_advised", nil).Update(advisedGauge)
+
+			if summary.UpgradeAdvised && !upgradeAdvised {
+				logger.Warnf("%.0f%% of the peers run a newer version, up to %v, the local version is %v. Please upgrade the node",
+					summary.NewerPercent, summary.NewestVersion, summary.LocalVersion)
+			}
+			upgradeAdvised = summary.UpgradeAdvised
+		}
+	}
+}
+
 // Stop is called when the Messenger stops
 func (msgr *Messenger) Stop() {
 	msgr.cancel()
@@ -183,6 +232,17 @@ func (msgr *Messenger) BroadcastToNeighbors(message p2ptypes.Message, maxNumPeer
 
 // samplePeers randomly sample a subset of peers
 func (msgr *Messenger) samplePeers(maxNumSampledPeers int, skipEdgeNode bool) []string {
+	neighbors := *msgr.peerTable.GetAllPeers(skipEdgeNode)
+
+	// Always relay to the private peers (e.g. the validators behind a sentry node), in
+	// addition to the sampled peers
+	privatePIDs := []string{}
+	for _, peer := range neighbors {
+		if peer.IsPrivate() && !msgr.discMgr.isSeedPeer(peer.ID()) {
+			privatePIDs = append(privatePIDs, peer.ID())
+		}
+	}
+
 	// Prioritize seed peers
 	sampledPIDs, idx := []string{}, 0
 	for seedPID := range msgr.discMgr.seedPeers {
@@ -190,16 +250,15 @@ func (msgr *Messenger) samplePeers(maxNumSampledPeers int, skipEdgeNode bool) []
 		sampledPIDs = append(sampledPIDs, seedPID)
 		idx++
 		if idx >= maxNumSampledPeers {
-			return sampledPIDs
+			return append(sampledPIDs, privatePIDs...)
 		}
 	}
 
 	// Randomly sample the remaining peers
-	neighbors := *msgr.peerTable.GetAllPeers(skipEdgeNode)
 	neighborPIDs := []string{}
 	for _, peer := range neighbors {
 		pid := peer.ID()
-		if pid == msgr.ID() || msgr.discMgr.isSeedPeer(pid) {
+		if pid == msgr.ID() || msgr.discMgr.isSeedPeer(pid) || peer.IsPrivate() {
 			continue
 		}
 		neighborPIDs = append(neighborPIDs, pid)
@@ -214,6 +273,7 @@ func (msgr *Messenger) samplePeers(maxNumSampledPeers int, skipEdgeNode bool) []
 	for i := 0; i < numPeersToSample; i++ {
 		sampledPIDs = append(sampledPIDs, sampledNeighbors[i])
 	}
+	sampledPIDs = append(sampledPIDs, privatePIDs...)
 
 	return sampledPIDs
 }
@@ -250,6 +310,21 @@ func (msgr *Messenger) PeerURLs(skipEdgeNode bool) []string {
 	return peerURLs
 }
 
+// PeerCapabilities returns the capabilities advertised by the peers
+func (msgr *Messenger) PeerCapabilities(skipEdgeNode bool) map[string]*p2ptypes.NodeCapabilities {
+	allPeers := msgr.peerTable.GetAllPeers(skipEdgeNode)
+	capabilities := make(map[string]*p2ptypes.NodeCapabilities)
+	for _, peer := range *allPeers {
+		capabilities[peer.ID()] = peer.Capabilities()
+	}
+	return capabilities
+}
+
+// ProbeTopology asks the peers for their neighbors
+func (msgr *Messenger) ProbeTopology(timeout time.Duration) (map[string][]p2ptypes.TopologyNeighbor, error) {
+	return msgr.discMgr.peerDiscMsgHandler.probeTopology(timeout)
+}
+
 // PeerExists indicates if the given peerID is a neighboring peer
 func (msgr *Messenger) PeerExists(peerID string) bool {
 	return msgr.peerTable.PeerExists(peerID)
@@ -279,12 +354,18 @@ func (msgr *Messenger) AttachMessageHandlersToPeer(peer *pr.Peer) {
 		msgHandler := msgr.msgHandlerMap[channelID]
 		if msgHandler == nil {
 			logger.Errorf("Failed to setup message parser for channelID %v", channelID)
+			return p2ptypes.Message{}, fmt.Errorf("no message handler for channelID %v", channelID)
 		}
 		message, err := msgHandler.ParseMessage(peerID, channelID, rawMessageBytes)
 		return message, err
 	}
 	peer.GetConnection().SetMessageParser(messageParser)
 
+	messageFilter := func(channelID common.ChannelIDEnum, rawMessageBytes common.Bytes) bool {
+		return msgr.seenCache.CheckAndMark(channelID, rawMessageBytes)
+	}
+	peer.GetConnection().SetMessageFilter(messageFilter)
+
 	messageEncoder := func(channelID common.ChannelIDEnum, message interface{}) (common.Bytes, error) {
 		msgHandler := msgr.msgHandlerMap[channelID]
 		return msgHandler.EncodeMessage(message)
@@ -296,6 +377,7 @@ func (msgr *Messenger) AttachMessageHandlersToPeer(peer *pr.Peer) {
 		msgHandler := msgr.msgHandlerMap[channelID]
 		if msgHandler == nil {
 			logger.Errorf("Failed to setup message handler for peer %v on channelID %v", message.PeerID, channelID)
+			return fmt.Errorf("no message handler for channelID %v", channelID)
 		}
 		err := msgHandler.HandleMessage(message)
 		return err
@@ -312,3 +394,8 @@ func (msgr *Messenger) AttachMessageHandlersToPeer(peer *pr.Peer) {
 func (msgrConfig *MessengerConfig) SetAddressBookFilePath(filePath string) {
 	msgrConfig.addrBookFilePath = filePath
 }
+
+// SetChainID sets the chain ID sent to the peers in the handshake
+func (msgrConfig *MessengerConfig) SetChainID(chainID string) {
+	msgrConfig.chainID = chainID
+}
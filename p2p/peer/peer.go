@@ -34,10 +34,12 @@ type Peer struct {
 	isPersistent bool
 	isOutbound   bool
 	isSeed       bool
+	isPrivate    bool
 	netAddress   *nu.NetAddress
 
 	nodeInfo p2ptypes.NodeInfo // information of the blockchain node of the peer
 	nodeType cmn.NodeType
+	role     PeerRole
 	config   PeerConfig
 
 	// Life cycle
@@ -54,6 +56,7 @@ type Peer struct {
 type PeerConfig struct {
 	HandshakeTimeout time.Duration
 	DialTimeout      time.Duration
+	ChainID          string // The chain ID sent in the handshake, the genesis chain ID in the config if empty
 }
 
 // CreateOutboundPeer creates an instance of an outbound peer
@@ -152,9 +155,13 @@ func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo) error {
 	peer.nodeInfo = targetPeerNodeInfo
 
 	// Forward compatibility.
-	localChainID := viper.GetString(cmn.CfgGenesisChainID)
+	localChainID := peer.config.ChainID
+	if localChainID == "" {
+		localChainID = viper.GetString(cmn.CfgGenesisChainID)
+	}
 	selfNodeType := viper.GetInt(cmn.CfgNodeType)
 	var peerType int
+	var peerCapabilities *p2ptypes.NodeCapabilities
 	cmn.Parallel(
 		func() {
 			sendError = rlp.Encode(peer.connection.GetBufNetconn(), localChainID)
@@ -165,6 +172,18 @@ func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo) error {
 			if sendError != nil {
 				return
 			}
+			if sourceNodeInfo.Capabilities != nil {
+				// Peers of older versions skip the unknown extra info
+				var capabilities string
+				capabilities, sendError = sourceNodeInfo.Capabilities.EncodeHandshakeInfo()
+				if sendError != nil {
+					return
+				}
+				sendError = rlp.Encode(peer.connection.GetBufNetconn(), capabilities)
+				if sendError != nil {
+					return
+				}
+			}
 			sendError = rlp.Encode(peer.connection.GetBufNetconn(), "EOH")
 		},
 		func() {
@@ -205,6 +224,9 @@ func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo) error {
 				if msg == "EOH" {
 					return
 				}
+				if capabilities, ok := p2ptypes.DecodeNodeCapabilities(msg); ok {
+					peerCapabilities = capabilities
+				}
 			}
 		},
 	)
@@ -217,7 +239,13 @@ func (peer *Peer) Handshake(sourceNodeInfo *p2ptypes.NodeInfo) error {
 		return recvError
 	}
 
+	if err := p2ptypes.CheckProtocolCompatibility(sourceNodeInfo.Capabilities, peerCapabilities); err != nil {
+		logger.Warnf("Refused incompatible peer %v: %v", targetNodePubKey.Address(), err)
+		return err
+	}
+
 	peer.nodeType = common.NodeType(peerType)
+	peer.nodeInfo.Capabilities = peerCapabilities
 
 	remotePub, err := peer.connection.DoEncHandshake(
 		crypto.PrivKeyToECDSA(sourceNodeInfo.PrivKey), crypto.PubKeyToECDSA(targetNodePubKey))
@@ -293,6 +321,21 @@ func (peer *Peer) NodeType() cmn.NodeType {
 	return peer.nodeType
 }
 
+// Capabilities returns the capabilities advertised by the peer, nil if the peer does not advertise them
+func (peer *Peer) Capabilities() *p2ptypes.NodeCapabilities {
+	return peer.nodeInfo.Capabilities
+}
+
+// SetRole sets the role of the peer
+func (peer *Peer) SetRole(role PeerRole) {
+	peer.role = role
+}
+
+// Role returns the role of the peer
+func (peer *Peer) Role() PeerRole {
+	return peer.role
+}
+
 // SetSeed sets the isSeed for the given peer
 func (peer *Peer) SetSeed(isSeed bool) {
 	peer.isSeed = isSeed
@@ -308,6 +351,16 @@ func (peer *Peer) SetNetAddress(netAddr *nu.NetAddress) {
 	peer.netAddress = netAddr
 }
 
+// SetPrivate sets whether the peer is a private peer, i.e. its address should never be shared
+func (peer *Peer) SetPrivate(isPrivate bool) {
+	peer.isPrivate = isPrivate
+}
+
+// IsPrivate returns whether the peer is a private peer
+func (peer *Peer) IsPrivate() bool {
+	return peer.isPrivate
+}
+
 // NetAddress returns the network address of the peer
 func (peer *Peer) NetAddress() *nu.NetAddress {
 	return peer.netAddress
@@ -153,6 +153,27 @@ func (pt *PeerTable) PurgeOldestPeer() *Peer {
 	return peer
 }
 
+// PurgeOldestPeerWithRole purges the oldest non-seed peer of the given role from the PeerTable
+func (pt *PeerTable) PurgeOldestPeerWithRole(role PeerRole) *Peer {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	for idx, peer := range pt.peers {
+		if peer.IsSeed() || peer.Role() != role {
+			continue
+		}
+		delete(pt.peerMap, peer.ID())
+		delete(pt.addrMap, peer.NetAddress().String())
+		pt.peers = append(pt.peers[:idx], pt.peers[idx+1:]...)
+
+		logger.Infof("Purged the oldest %v peer %v from the peer table, idx: %v", role, peer.ID(), idx)
+
+		pt.persistPeers()
+		return peer
+	}
+	return nil
+}
+
 // GetPeer returns the peer for the given peerID (if exists)
 func (pt *PeerTable) GetPeer(peerID string) *Peer {
 	pt.mutex.Lock()
@@ -243,6 +264,9 @@ func (pt *PeerTable) GetSelection(skipEdgeNode bool) (peerIDAddrs []PeerIDAddres
 		if skipEdgeNode && peer.NodeType() == common.NodeTypeEdgeNode {
 			continue
 		}
+		if peer.IsPrivate() {
+			continue // never share the address of private peers, e.g. validators behind sentry nodes
+		}
 		peerIDAddr := PeerIDAddress{
 			ID:   peer.ID(),
 			Addr: peer.netAddress,
@@ -272,6 +296,20 @@ func (pt *PeerTable) GetTotalNumPeers(skipEdgeNode bool) uint {
 	return numPeers
 }
 
+// GetNumPeersWithRole returns the number of peers of the given role in the PeerTable
+func (pt *PeerTable) GetNumPeersWithRole(role PeerRole) uint {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	var numPeers uint
+	for _, peer := range pt.peers {
+		if peer.Role() == role {
+			numPeers++
+		}
+	}
+	return numPeers
+}
+
 func (pt *PeerTable) RetrievePreviousPeers() ([]*nu.NetAddress, error) {
 	if pt.db == nil {
 		return []*nu.NetAddress{}, fmt.Errorf("peerTable DB not ready yet")
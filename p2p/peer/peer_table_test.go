@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/thetatoken/theta/crypto"
 	cn "github.com/thetatoken/theta/p2p/connection"
+	nu "github.com/thetatoken/theta/p2p/netutil"
 	p2ptypes "github.com/thetatoken/theta/p2p/types"
 )
 
@@ -136,6 +137,56 @@ func TestDefaultPeerIterationOrder(t *testing.T) {
 	assert.Equal((*allPeers)[5], peer3)
 }
 
+func TestDefaultPeerTableRoles(t *testing.T) {
+	assert := assert.New(t)
+
+	pt := newTestEmptyPeerTable()
+
+	port := 37859
+	netconn := newIncomingNetconn(port)
+
+	validator := newSimulatedInboundPeer(netconn, p2ptypes.GetTestRandPubKey())
+	validator.SetRole(PeerRoleValidator)
+	unknown1 := newSimulatedInboundPeer(netconn, p2ptypes.GetTestRandPubKey())
+	unknown2 := newSimulatedInboundPeer(netconn, p2ptypes.GetTestRandPubKey())
+
+	pt.AddPeer(validator)
+	pt.AddPeer(unknown1)
+	pt.AddPeer(unknown2)
+	assert.Equal(uint(1), pt.GetNumPeersWithRole(PeerRoleValidator))
+	assert.Equal(uint(2), pt.GetNumPeersWithRole(PeerRoleUnknown))
+	assert.Equal(uint(0), pt.GetNumPeersWithRole(PeerRoleGuardian))
+
+	// The oldest peer is a validator, it should not be purged for an unknown peer
+	purged := pt.PurgeOldestPeerWithRole(PeerRoleUnknown)
+	assert.Equal(unknown1, purged)
+	assert.True(pt.PeerExists(validator.ID()))
+	assert.False(pt.PeerExists(unknown1.ID()))
+	assert.Equal(uint(1), pt.GetNumPeersWithRole(PeerRoleUnknown))
+
+	assert.Nil(pt.PurgeOldestPeerWithRole(PeerRoleGuardian))
+}
+
+func TestDefaultPeerTableSelectionSkipsPrivatePeers(t *testing.T) {
+	assert := assert.New(t)
+
+	pt := newTestEmptyPeerTable()
+
+	port := 37860
+	netconn := newIncomingNetconn(port)
+
+	publicPeer := newSimulatedInboundPeer(netconn, p2ptypes.GetTestRandPubKey())
+	privatePeer := newSimulatedInboundPeer(netconn, p2ptypes.GetTestRandPubKey())
+	privatePeer.SetPrivate(true)
+
+	pt.AddPeer(publicPeer)
+	pt.AddPeer(privatePeer)
+
+	selection := pt.GetSelection(true)
+	assert.Equal(1, len(selection))
+	assert.Equal(publicPeer.ID(), selection[0].ID)
+}
+
 // --------------- Test Utilities --------------- //
 
 func newTestEmptyPeerTable() PeerTable {
@@ -154,6 +205,7 @@ func newSimulatedInboundPeer(netconn net.Conn, pubKey *crypto.PublicKey) *Peer {
 	_, portStr, _ := net.SplitHostPort(netconn.LocalAddr().String())
 	port, _ := strconv.ParseUint(portStr, 16, 16)
 	inboundPeer.nodeInfo = p2ptypes.CreateNodeInfo(pubKey, uint16(port))
+	inboundPeer.SetNetAddress(nu.NewNetAddress(netconn.RemoteAddr())) // set by the handshake otherwise
 	return inboundPeer
 }
 
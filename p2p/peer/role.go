@@ -0,0 +1,83 @@
+package peer
+
+import (
+	"github.com/spf13/viper"
+
+	cmn "github.com/thetatoken/theta/common"
+)
+
+// PeerRole defines the role of a peer, which determines the connection slots it can occupy
+type PeerRole int
+
+const (
+	// PeerRoleUnknown indicates a blockchain node that is neither a validator nor a guardian
+	PeerRoleUnknown PeerRole = iota
+
+	// PeerRoleValidator indicates the peer is a validator of the current validator set
+	PeerRoleValidator
+
+	// PeerRoleGuardian indicates the peer is a guardian of the current guardian candidate pool
+	PeerRoleGuardian
+
+	// PeerRoleEdgeNode indicates the peer is an edge node
+	PeerRoleEdgeNode
+)
+
+func (r PeerRole) String() string {
+	switch r {
+	case PeerRoleValidator:
+		return "validator"
+	case PeerRoleGuardian:
+		return "guardian"
+	case PeerRoleEdgeNode:
+		return "edge node"
+	default:
+		return "unknown"
+	}
+}
+
+//
+// PeerRoleResolver tells whether a peer ID (i.e. the blockchain address of the peer)
+// belongs to a validator or a guardian
+//
+type PeerRoleResolver interface {
+	IsValidator(peerID string) bool
+	IsGuardian(peerID string) bool
+}
+
+// ResolvePeerRole determines the role of the given handshaked peer
+func ResolvePeerRole(peer *Peer, resolver PeerRoleResolver) PeerRole {
+	return ResolveNodeRole(peer.NodeType(), peer.ID(), resolver)
+}
+
+// ResolveNodeRole determines the role of a node by its type and ID, which also applies to the local node
+func ResolveNodeRole(nodeType cmn.NodeType, peerID string, resolver PeerRoleResolver) PeerRole {
+	if nodeType == cmn.NodeTypeEdgeNode {
+		return PeerRoleEdgeNode
+	}
+	if resolver == nil {
+		return PeerRoleUnknown
+	}
+	if resolver.IsValidator(peerID) {
+		return PeerRoleValidator
+	}
+	if resolver.IsGuardian(peerID) {
+		return PeerRoleGuardian
+	}
+	return PeerRoleUnknown
+}
+
+// MaxNumPeersForRole returns the number of connection slots for peers of the given role.
+// Zero means there is no limit for the role.
+func MaxNumPeersForRole(role PeerRole) int {
+	switch role {
+	case PeerRoleValidator:
+		return viper.GetInt(cmn.CfgP2PMaxNumValidatorPeers)
+	case PeerRoleGuardian:
+		return viper.GetInt(cmn.CfgP2PMaxNumGuardianPeers)
+	case PeerRoleEdgeNode:
+		return viper.GetInt(cmn.CfgP2PMaxNumEdgeNodePeers)
+	default:
+		return viper.GetInt(cmn.CfgP2PMaxNumPeers)
+	}
+}
@@ -0,0 +1,221 @@
+package seencache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/metrics"
+	"github.com/thetatoken/theta/crypto"
+)
+
+const (
+	// bloomFilterBits is the number of bits of the bloom filter of each bucket (128KB). With
+	// bloomFilterNumHashes hashes the false positive rate stays below 0.1% for 50k messages per bucket.
+	bloomFilterBits      = 1 << 20
+	bloomFilterNumHashes = 4
+)
+
+// DefaultChannelIDs are the gossip channels whose messages are identical regardless of the
+// relaying peer. Request/response channels (e.g. ChannelIDBlock) are excluded since the same
+// request from different peers needs to be served separately.
+var DefaultChannelIDs = []common.ChannelIDEnum{
+	common.ChannelIDProposal,
+	common.ChannelIDVote,
+	common.ChannelIDTransaction,
+	common.ChannelIDGuardian,
+	common.ChannelIDEliteEdgeNodeVote,
+	common.ChannelIDAggregatedEliteEdgeNodeVotes,
+	common.ChannelIDCompactBlock,
+}
+
+// ChannelStats records the duplicate suppression statistics of a channel.
+type ChannelStats struct {
+	Received   uint64
+	Duplicates uint64
+}
+
+//
+// SeenCache remembers the raw gossip messages received recently so that a message relayed by
+// many peers is parsed and handled only once. Each channel keeps a ring of time-bucketed bloom
+// filters. A message is considered seen if any of the buckets contains it, and the oldest
+// bucket is cleared whenever the ring rotates. Being probabilistic, a small fraction of new
+// messages may be dropped as duplicates, which is acceptable for gossip since they are relayed
+// by other peers as well.
+//
+type SeenCache struct {
+	mu sync.Mutex
+
+	bucketDuration time.Duration
+	numBuckets     int
+	channels       map[common.ChannelIDEnum]*channelCache
+
+	now func() time.Time
+}
+
+type channelCache struct {
+	buckets     []*bloomFilter
+	current     int
+	bucketStart time.Time
+
+	stats      ChannelStats
+	received   metrics.Counter
+	duplicates metrics.Counter
+}
+
+// NewSeenCache creates a seen cache for the given channels. Messages are remembered for at
+// least (numBuckets-1)*bucketDuration and at most numBuckets*bucketDuration.
+func NewSeenCache(channelIDs []common.ChannelIDEnum, bucketDuration time.Duration, numBuckets int) *SeenCache {
+	if bucketDuration <= 0 {
+		bucketDuration = 30 * time.Second
+	}
+	if numBuckets < 2 {
+		numBuckets = 2
+	}
+	sc := &SeenCache{
+		bucketDuration: bucketDuration,
+		numBuckets:     numBuckets,
+		channels:       make(map[common.ChannelIDEnum]*channelCache),
+		now:            time.Now,
+	}
+	for _, channelID := range channelIDs {
+		cc := &channelCache{
+			buckets:     make([]*bloomFilter, numBuckets),
+			bucketStart: sc.now(),
+			received:    metrics.GetOrRegisterCounter(fmt.Sprintf("p2p/seen/%v/received", channelID), nil),
+			duplicates:  metrics.GetOrRegisterCounter(fmt.Sprintf("p2p/seen/%v/duplicates", channelID), nil),
+		}
+		for i := range cc.buckets {
+			cc.buckets[i] = newBloomFilter(bloomFilterBits)
+		}
+		sc.channels[channelID] = cc
+	}
+	return sc
+}
+
+// NewDefaultSeenCache creates a seen cache for the default gossip channels based on the
+// node config. Returns nil if the seen cache is disabled.
+func NewDefaultSeenCache() *SeenCache {
+	if !viper.GetBool(common.CfgP2PSeenCacheEnabled) {
+		return nil
+	}
+	bucketDuration := time.Duration(viper.GetInt(common.CfgP2PSeenCacheBucketSecs)) * time.Second
+	numBuckets := viper.GetInt(common.CfgP2PSeenCacheNumBuckets)
+	return NewSeenCache(DefaultChannelIDs, bucketDuration, numBuckets)
+}
+
+// CheckAndMark marks the raw message as seen on the given channel, and returns true if it
+// had already been seen, in which case the message should be dropped before it is parsed.
+// Messages on channels not tracked by the cache are never reported as duplicates.
+func (sc *SeenCache) CheckAndMark(channelID common.ChannelIDEnum, rawMessageBytes common.Bytes) bool {
+	if sc == nil {
+		return false
+	}
+	cc, ok := sc.channels[channelID]
+	if !ok {
+		return false
+	}
+
+	hash := crypto.Keccak256Hash(rawMessageBytes)
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.rotate(cc)
+
+	cc.stats.Received++
+	cc.received.Inc(1)
+	for _, bucket := range cc.buckets {
+		if bucket.contains(hash) {
+			cc.stats.Duplicates++
+			cc.duplicates.Inc(1)
+			return true
+		}
+	}
+	cc.buckets[cc.current].add(hash)
+	return false
+}
+
+// Stats returns the duplicate suppression statistics of each tracked channel.
+func (sc *SeenCache) Stats() map[common.ChannelIDEnum]ChannelStats {
+	stats := make(map[common.ChannelIDEnum]ChannelStats)
+	if sc == nil {
+		return stats
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	for channelID, cc := range sc.channels {
+		stats[channelID] = cc.stats
+	}
+	return stats
+}
+
+// rotate advances the bucket ring of the channel to the current time, clearing the buckets
+// that have expired.
+func (sc *SeenCache) rotate(cc *channelCache) {
+	now := sc.now()
+	steps := int(now.Sub(cc.bucketStart) / sc.bucketDuration)
+	if steps <= 0 {
+		return
+	}
+	if steps >= sc.numBuckets {
+		for _, bucket := range cc.buckets {
+			bucket.reset()
+		}
+		cc.bucketStart = now
+		return
+	}
+	for i := 0; i < steps; i++ {
+		cc.current = (cc.current + 1) % sc.numBuckets
+		cc.buckets[cc.current].reset()
+	}
+	cc.bucketStart = cc.bucketStart.Add(time.Duration(steps) * sc.bucketDuration)
+}
+
+type bloomFilter struct {
+	bits []uint64
+}
+
+func newBloomFilter(numBits int) *bloomFilter {
+	return &bloomFilter{
+		bits: make([]uint64, numBits/64),
+	}
+}
+
+func (bf *bloomFilter) add(hash common.Hash) {
+	for _, pos := range bf.positions(hash) {
+		bf.bits[pos/64] |= 1 << (pos % 64)
+	}
+}
+
+func (bf *bloomFilter) contains(hash common.Hash) bool {
+	for _, pos := range bf.positions(hash) {
+		if bf.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (bf *bloomFilter) reset() {
+	for i := range bf.bits {
+		bf.bits[i] = 0
+	}
+}
+
+// positions derives the bit positions of the hash. The hash is already uniformly distributed,
+// so each position is taken from a distinct 8-byte slice of it.
+func (bf *bloomFilter) positions(hash common.Hash) [bloomFilterNumHashes]uint64 {
+	var positions [bloomFilterNumHashes]uint64
+	numBits := uint64(len(bf.bits) * 64)
+	for i := 0; i < bloomFilterNumHashes; i++ {
+		positions[i] = binary.BigEndian.Uint64(hash[i*8:(i+1)*8]) % numBits
+	}
+	return positions
+}
@@ -0,0 +1,67 @@
+package seencache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thetatoken/theta/common"
+)
+
+func TestSeenCacheDuplicates(t *testing.T) {
+	assert := assert.New(t)
+
+	sc := NewSeenCache([]common.ChannelIDEnum{common.ChannelIDVote}, 10*time.Second, 3)
+
+	vote := common.Bytes("vote")
+	assert.False(sc.CheckAndMark(common.ChannelIDVote, vote))
+	assert.True(sc.CheckAndMark(common.ChannelIDVote, vote))
+	assert.True(sc.CheckAndMark(common.ChannelIDVote, vote))
+	assert.False(sc.CheckAndMark(common.ChannelIDVote, common.Bytes("another vote")))
+
+	// Untracked channels are never deduplicated
+	assert.False(sc.CheckAndMark(common.ChannelIDBlock, vote))
+	assert.False(sc.CheckAndMark(common.ChannelIDBlock, vote))
+
+	stats := sc.Stats()
+	assert.Equal(1, len(stats))
+	assert.Equal(uint64(4), stats[common.ChannelIDVote].Received)
+	assert.Equal(uint64(2), stats[common.ChannelIDVote].Duplicates)
+
+	// A nil cache never drops messages
+	var nilCache *SeenCache
+	assert.False(nilCache.CheckAndMark(common.ChannelIDVote, vote))
+	assert.False(nilCache.CheckAndMark(common.ChannelIDVote, vote))
+}
+
+func TestSeenCacheExpiration(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	sc := NewSeenCache([]common.ChannelIDEnum{common.ChannelIDVote}, 10*time.Second, 3)
+	sc.now = func() time.Time { return now }
+	sc.channels[common.ChannelIDVote].bucketStart = now
+
+	msg1 := common.Bytes("msg1")
+	msg2 := common.Bytes("msg2")
+	assert.False(sc.CheckAndMark(common.ChannelIDVote, msg1))
+
+	now = now.Add(15 * time.Second)
+	assert.False(sc.CheckAndMark(common.ChannelIDVote, msg2))
+	assert.True(sc.CheckAndMark(common.ChannelIDVote, msg1))
+
+	// msg1 is in the oldest bucket, msg2 is still retained
+	now = now.Add(10 * time.Second)
+	assert.True(sc.CheckAndMark(common.ChannelIDVote, msg1))
+
+	// The bucket of msg1 is cleared
+	now = now.Add(10 * time.Second)
+	assert.True(sc.CheckAndMark(common.ChannelIDVote, msg2))
+	assert.False(sc.CheckAndMark(common.ChannelIDVote, msg1))
+
+	// Everything expires after a long idle period
+	now = now.Add(time.Minute)
+	assert.False(sc.CheckAndMark(common.ChannelIDVote, msg1))
+	assert.False(sc.CheckAndMark(common.ChannelIDVote, msg2))
+}
@@ -214,6 +214,16 @@ func (se *SimnetEndpoint) PeerURLs(skipEdgeNode bool) []string {
 	return []string{}
 }
 
+// PeerCapabilities returns the capabilities advertised by the peers
+func (se *SimnetEndpoint) PeerCapabilities(skipEdgeNode bool) map[string]*p2ptypes.NodeCapabilities {
+	return map[string]*p2ptypes.NodeCapabilities{}
+}
+
+// ProbeTopology implements the Network interface.
+func (se *SimnetEndpoint) ProbeTopology(timeout time.Duration) (map[string][]p2ptypes.TopologyNeighbor, error) {
+	return map[string][]p2ptypes.TopologyNeighbor{se.ID(): {}}, nil
+}
+
 // PeerExists indicates if the given peerID is a neighboring peer
 func (se *SimnetEndpoint) PeerExists(peerID string) bool {
 	return false
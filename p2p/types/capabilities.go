@@ -0,0 +1,76 @@
+package types
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/version"
+)
+
+// capabilitiesHandshakePrefix marks the capabilities in the extra info of the handshake
+const capabilitiesHandshakePrefix = "CAP:"
+
+//
+// NodeCapabilities describes the role of a node and the services it provides, which are
+// advertised to the peers during the handshake
+//
+type NodeCapabilities struct {
+	Role          string `json:"role"`              // e.g. validator, guardian, edge node
+	Archive       bool   `json:"archive"`           // the full state history is retained, i.e. state pruning is disabled
+	TxIndex       bool   `json:"tx_index"`          // the tx index of all the blocks is retained
+	SnapshotOffer bool   `json:"snapshot_offer"`    // the node offers snapshots for state sync
+	RPCOpen       bool   `json:"rpc_open"`          // the RPC service is enabled and reachable from other hosts
+	Version       string `json:"version,omitempty"` // the software version, empty for the peers of older versions
+
+	ProtocolVersion    uint64 `json:"protocol_version,omitempty"`     // 0 for the peers predating the negotiation
+	MinProtocolVersion uint64 `json:"min_protocol_version,omitempty"` // the oldest protocol version of the peers the node works with
+}
+
+// CreateLocalNodeCapabilities creates the capabilities of the local node from the config, with the
+// given role
+func CreateLocalNodeCapabilities(role string) *NodeCapabilities {
+	return &NodeCapabilities{
+		Role:          role,
+		Archive:       !viper.GetBool(common.CfgStorageStatePruningEnabled),
+		TxIndex:       viper.GetInt(common.CfgStorageTxIndexRetainedBlocks) == 0,
+		SnapshotOffer: viper.GetBool(common.CfgP2PAdvertiseSnapshot),
+		RPCOpen:       viper.GetBool(common.CfgRPCEnabled) && !isLoopbackAddress(viper.GetString(common.CfgRPCAddress)),
+		Version:       version.Version,
+
+		ProtocolVersion:    ProtocolVersion,
+		MinProtocolVersion: MinCompatibleProtocolVersion(ProtocolVersion),
+	}
+}
+
+func isLoopbackAddress(address string) bool {
+	if strings.EqualFold(address, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(address)
+	return ip != nil && ip.IsLoopback()
+}
+
+// EncodeHandshakeInfo encodes the capabilities as an extra info of the handshake
+func (c *NodeCapabilities) EncodeHandshakeInfo() (string, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return capabilitiesHandshakePrefix + string(raw), nil
+}
+
+// DecodeNodeCapabilities decodes the capabilities from an extra info of the handshake. Returns
+// false if the extra info does not carry the capabilities
+func DecodeNodeCapabilities(info string) (*NodeCapabilities, bool) {
+	if !strings.HasPrefix(info, capabilitiesHandshakePrefix) {
+		return nil, false
+	}
+	c := &NodeCapabilities{}
+	if err := json.Unmarshal([]byte(info[len(capabilitiesHandshakePrefix):]), c); err != nil {
+		return nil, false
+	}
+	return c, true
+}
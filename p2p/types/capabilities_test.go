@@ -0,0 +1,42 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeCapabilitiesHandshakeInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	capabilities := &NodeCapabilities{
+		Role:          "guardian",
+		Archive:       true,
+		TxIndex:       false,
+		SnapshotOffer: true,
+		RPCOpen:       true,
+		Version:       "3.2.0",
+	}
+	info, err := capabilities.EncodeHandshakeInfo()
+	assert.Nil(err)
+
+	decoded, ok := DecodeNodeCapabilities(info)
+	assert.True(ok)
+	assert.Equal(capabilities, decoded)
+
+	// Extra info not carrying the capabilities, e.g. from a newer peer
+	_, ok = DecodeNodeCapabilities("FOO:bar")
+	assert.False(ok)
+	_, ok = DecodeNodeCapabilities("CAP:{invalid")
+	assert.False(ok)
+}
+
+func TestIsLoopbackAddress(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(isLoopbackAddress("localhost"))
+	assert.True(isLoopbackAddress("127.0.0.1"))
+	assert.True(isLoopbackAddress("::1"))
+	assert.False(isLoopbackAddress("0.0.0.0"))
+	assert.False(isLoopbackAddress("10.0.0.1"))
+}
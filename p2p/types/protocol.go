@@ -0,0 +1,136 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ProtocolVersion is the version of the p2p protocol implemented by the node. It is bumped when the
+// node stops working with the peers of some older versions, e.g. after a hard fork changes the blocks
+// or the messages exchanged between the nodes.
+const ProtocolVersion uint64 = 1
+
+//
+// protocolVersions is the compatibility matrix of the protocol versions, it maps each version to the
+// oldest version of the peers it works with. The peers not advertising a protocol version are version 0.
+// When bumping ProtocolVersion, add its entry here, e.g. 2: 1 if the version 2 nodes cannot work with
+// the nodes predating the negotiation.
+//
+var protocolVersions = map[uint64]uint64{
+	1: 0, // introduces the negotiation, works with all the earlier nodes
+}
+
+// MinCompatibleProtocolVersion returns the oldest protocol version of the peers that the nodes of the
+// given version work with
+func MinCompatibleProtocolVersion(version uint64) uint64 {
+	return protocolVersions[version]
+}
+
+// CheckProtocolCompatibility returns an error if the local node and the peer cannot work together. Each
+// side enforces its own min compatible version, and the one advertised by the other side, so that the
+// older node also knows why the connection is refused.
+func CheckProtocolCompatibility(local, peer *NodeCapabilities) error {
+	if local == nil {
+		return nil
+	}
+	peerVersion, peerMinVersion := uint64(0), uint64(0)
+	if peer != nil {
+		peerVersion, peerMinVersion = peer.ProtocolVersion, peer.MinProtocolVersion
+	}
+	if peerVersion < local.MinProtocolVersion {
+		return fmt.Errorf("the peer runs protocol version %v, below the min compatible version %v, the peer needs to upgrade",
+			peerVersion, local.MinProtocolVersion)
+	}
+	if local.ProtocolVersion < peerMinVersion {
+		return fmt.Errorf("the peer requires protocol version %v or above, the node runs version %v, please upgrade the node",
+			peerMinVersion, local.ProtocolVersion)
+	}
+	return nil
+}
+
+// UpgradeNudgePercent is the share of the peers running a newer software version above which the node
+// advises upgrading
+const UpgradeNudgePercent = 30
+
+//
+// PeerVersionSummary compares the software versions of the peers against the local version. The peers
+// not advertising a version, or advertising a version that cannot be parsed, are not counted.
+//
+type PeerVersionSummary struct {
+	LocalVersion   string  `json:"local_version"`
+	NumPeers       int     `json:"num_peers"` // peers advertising a parsable version
+	NumNewer       int     `json:"num_newer"`
+	NewerPercent   float64 `json:"newer_percent"`
+	NewestVersion  string  `json:"newest_version,omitempty"`
+	UpgradeAdvised bool    `json:"upgrade_advised"` // at least UpgradeNudgePercent of the peers run a newer version
+}
+
+// SummarizePeerVersions summarizes the versions advertised in the capabilities of the peers
+func SummarizePeerVersions(localVersion string, capabilities map[string]*NodeCapabilities) *PeerVersionSummary {
+	summary := &PeerVersionSummary{LocalVersion: localVersion}
+	local, localOK := parseVersion(localVersion)
+	var newest []int
+	for _, c := range capabilities {
+		if c == nil {
+			continue
+		}
+		v, ok := parseVersion(c.Version)
+		if !ok {
+			continue
+		}
+		summary.NumPeers++
+		if localOK && compareVersions(v, local) > 0 {
+			summary.NumNewer++
+		}
+		if newest == nil || compareVersions(v, newest) > 0 {
+			newest = v
+			summary.NewestVersion = c.Version
+		}
+	}
+	if summary.NumPeers > 0 {
+		summary.NewerPercent = float64(summary.NumNewer) * 100 / float64(summary.NumPeers)
+	}
+	summary.UpgradeAdvised = summary.NumNewer > 0 && summary.NewerPercent >= UpgradeNudgePercent
+	return summary
+}
+
+// parseVersion parses versions like 3.2.1, v3.2 or 3.2.1-rc1. The pre-release suffix is ignored.
+func parseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+ "); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" {
+		return nil, false
+	}
+	parts := strings.Split(version, ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		numbers[i] = n
+	}
+	return numbers, true
+}
+
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		x, y := 0, 0
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckProtocolCompatibility(t *testing.T) {
+	assert := assert.New(t)
+
+	local := &NodeCapabilities{ProtocolVersion: 3, MinProtocolVersion: 2}
+
+	assert.Nil(CheckProtocolCompatibility(local, &NodeCapabilities{ProtocolVersion: 2, MinProtocolVersion: 1}))
+	assert.Nil(CheckProtocolCompatibility(local, &NodeCapabilities{ProtocolVersion: 4, MinProtocolVersion: 3}))
+
+	// The peer is too old for the local node
+	assert.NotNil(CheckProtocolCompatibility(local, &NodeCapabilities{ProtocolVersion: 1}))
+	assert.NotNil(CheckProtocolCompatibility(local, nil))
+
+	// The local node is too old for the peer
+	err := CheckProtocolCompatibility(local, &NodeCapabilities{ProtocolVersion: 5, MinProtocolVersion: 4})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "please upgrade the node")
+
+	// The nodes predating the negotiation work with the current version
+	current := &NodeCapabilities{ProtocolVersion: ProtocolVersion, MinProtocolVersion: MinCompatibleProtocolVersion(ProtocolVersion)}
+	assert.Nil(CheckProtocolCompatibility(current, nil))
+	assert.Nil(CheckProtocolCompatibility(current, &NodeCapabilities{Role: "guardian"}))
+	assert.Nil(CheckProtocolCompatibility(nil, local))
+}
+
+func TestSummarizePeerVersions(t *testing.T) {
+	assert := assert.New(t)
+
+	capabilities := map[string]*NodeCapabilities{
+		"a": {Version: "3.2.0"},
+		"b": {Version: "v3.10.1"},
+		"c": {Version: "3.2.0-rc1"},
+		"d": {Version: "3.1"},
+		"e": {Version: "dev"},
+		"f": nil,
+	}
+	summary := SummarizePeerVersions("3.2.0", capabilities)
+	assert.Equal(4, summary.NumPeers)
+	assert.Equal(1, summary.NumNewer)
+	assert.Equal(float64(25), summary.NewerPercent)
+	assert.Equal("v3.10.1", summary.NewestVersion)
+	assert.False(summary.UpgradeAdvised)
+
+	summary = SummarizePeerVersions("3.1.0", capabilities)
+	assert.Equal(3, summary.NumNewer)
+	assert.True(summary.UpgradeAdvised)
+
+	// An unparsable local version is never advised to upgrade
+	summary = SummarizePeerVersions("test", capabilities)
+	assert.Equal(0, summary.NumNewer)
+	assert.False(summary.UpgradeAdvised)
+
+	summary = SummarizePeerVersions("3.2.0", map[string]*NodeCapabilities{})
+	assert.Equal(0, summary.NumPeers)
+	assert.Equal(float64(0), summary.NewerPercent)
+}
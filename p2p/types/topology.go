@@ -0,0 +1,35 @@
+package types
+
+//
+// TopologyNeighbor describes a neighbor reported by a node to a topology probe
+//
+type TopologyNeighbor struct {
+	ID           string
+	Role         string // the role of the neighbor resolved by the reporting node
+	Outbound     bool   // true if the reporting node dialed the neighbor
+	Capabilities string // advertised by the neighbor, encoded as in the handshake, empty if not advertised
+}
+
+// NewTopologyNeighbor creates the topology entry of a neighbor with the given capabilities
+func NewTopologyNeighbor(id string, role string, outbound bool, capabilities *NodeCapabilities) TopologyNeighbor {
+	neighbor := TopologyNeighbor{
+		ID:       id,
+		Role:     role,
+		Outbound: outbound,
+	}
+	if capabilities != nil {
+		if info, err := capabilities.EncodeHandshakeInfo(); err == nil {
+			neighbor.Capabilities = info
+		}
+	}
+	return neighbor
+}
+
+// NodeCapabilities decodes the capabilities advertised by the neighbor, nil if not advertised
+func (n TopologyNeighbor) NodeCapabilities() *NodeCapabilities {
+	capabilities, ok := DecodeNodeCapabilities(n.Capabilities)
+	if !ok {
+		return nil
+	}
+	return capabilities
+}
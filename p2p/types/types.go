@@ -24,6 +24,10 @@ type NodeInfo struct {
 	PubKey      *crypto.PublicKey  `rlp:"-"`
 	PubKeyBytes common.Bytes       // needed for RLP serialization
 	Port        uint16
+
+	// Capabilities are exchanged in the extra info of the handshake for backward compatibility,
+	// nil if the peer does not advertise its capabilities
+	Capabilities *NodeCapabilities `rlp:"-"`
 }
 
 // CreateNodeInfo creates an instance of NodeInfo
@@ -57,6 +57,10 @@ type Network interface {
 	// PeerURLs return the URLs of all peers
 	PeerURLs(skipEdgeNode bool) []string
 
+	// PeerCapabilities returns the capabilities advertised by the peers, keyed by the peer IDs.
+	// The capabilities are nil for the peers that do not advertise them
+	PeerCapabilities(skipEdgeNode bool) map[string]*types.NodeCapabilities
+
 	// PeerExists indicates if the given peerID is a neighboring peer
 	PeerExists(peerID string) bool
 
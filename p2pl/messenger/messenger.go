@@ -16,6 +16,7 @@ import (
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/util"
 	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/p2p/seencache"
 	p2ptypes "github.com/thetatoken/theta/p2p/types"
 	p2pcmn "github.com/thetatoken/theta/p2pl/common"
 
@@ -64,6 +65,7 @@ const (
 type Messenger struct {
 	host          host.Host
 	msgHandlerMap map[common.ChannelIDEnum](p2pl.MessageHandler)
+	seenCache     *seencache.SeenCache // pubsub messages are already deduplicated by libp2p
 	config        MessengerConfig
 	seedPeers     map[pr.ID]*pr.AddrInfo
 	pubsub        *ps.PubSub
@@ -99,15 +101,35 @@ type Messenger struct {
 //
 type MessengerConfig struct {
 	networkProtocol string
+	transports      []string
+	chainID         string
 }
 
 // GetDefaultMessengerConfig returns the default config for messenger, not necessary
 func GetDefaultMessengerConfig() MessengerConfig {
 	return MessengerConfig{
 		networkProtocol: "tcp",
+		transports:      []string{TransportTCP},
 	}
 }
 
+// SetChainID sets the chain ID in the protocol prefix, which overrides the configured chain ID and
+// protocol prefix. The messengers of the chains hosted in the same process need different chain IDs
+// so that they do not exchange messages with each other.
+func (msgrConfig *MessengerConfig) SetChainID(chainID string) {
+	msgrConfig.chainID = chainID
+}
+
+// SetTransports sets the libp2p transports from a comma separated list, e.g. "tcp,quic"
+func (msgrConfig *MessengerConfig) SetTransports(transportsStr string) error {
+	transports, err := parseTransports(transportsStr)
+	if err != nil {
+		return err
+	}
+	msgrConfig.transports = transports
+	return nil
+}
+
 func createP2PAddr(ip, port, networkProtocol string) (ma.Multiaddr, error) {
 	ipv := "ip4"
 	if strings.Index(ip, ":") > 0 {
@@ -137,7 +159,9 @@ func CreateMessenger(pubKey *crypto.PublicKey, seedPeerMultiAddresses []string,
 	bufferPoolSize := viper.GetInt(common.CfgBufferPoolSize)
 
 	var protocolPrefix string
-	if viper.GetString(common.CfgP2PProtocolPrefix) != "" {
+	if msgrConfig.chainID != "" {
+		protocolPrefix = "/theta/" + msgrConfig.chainID + "/" + viper.GetString(common.CfgP2PVersion) + "/"
+	} else if viper.GetString(common.CfgP2PProtocolPrefix) != "" {
 		protocolPrefix = viper.GetString(common.CfgP2PProtocolPrefix)
 	} else {
 		protocolPrefix = "/theta/" + viper.GetString(common.CfgGenesisChainID) + "/" + viper.GetString(common.CfgP2PVersion) + "/"
@@ -151,6 +175,7 @@ func CreateMessenger(pubKey *crypto.PublicKey, seedPeerMultiAddresses []string,
 		msgBlockBufferPool:  make(chan []byte, bufferPoolSize),
 		msgNormalBufferPool: make(chan []byte, bufferPoolSize),
 		msgHandlerMap:       make(map[common.ChannelIDEnum](p2pl.MessageHandler)),
+		seenCache:           seencache.NewDefaultSeenCache(),
 		needMdns:            needMdns,
 		seedPeerOnly:        seedPeerOnly,
 		seedPeers:           make(map[pr.ID]*pr.AddrInfo),
@@ -170,12 +195,12 @@ func CreateMessenger(pubKey *crypto.PublicKey, seedPeerMultiAddresses []string,
 	if err != nil {
 		return messenger, err
 	}
-	localNetAddress, err := createP2PAddr("0.0.0.0", strconv.Itoa(port), msgrConfig.networkProtocol)
+	localNetAddrs, err := createTransportAddrs("0.0.0.0", strconv.Itoa(port), msgrConfig.transports)
 	if err != nil {
 		return messenger, err
 	}
 
-	var extMultiAddr ma.Multiaddr
+	var extMultiAddrs []ma.Multiaddr
 	if !seedPeerOnly {
 		externalIP, err := util.GetPublicIP()
 		if err != nil {
@@ -184,16 +209,14 @@ func CreateMessenger(pubKey *crypto.PublicKey, seedPeerMultiAddresses []string,
 			//return messenger, err
 		}
 
-		extMultiAddr, err = createP2PAddr(externalIP, strconv.Itoa(port), msgrConfig.networkProtocol)
+		extMultiAddrs, err = createTransportAddrs(externalIP, strconv.Itoa(port), msgrConfig.transports)
 		if err != nil {
 			return messenger, err
 		}
 	}
 
 	addressFactory := func(addrs []ma.Multiaddr) []ma.Multiaddr {
-		if extMultiAddr != nil {
-			addrs = append(addrs, extMultiAddr)
-		}
+		addrs = append(addrs, extMultiAddrs...)
 		return addrs
 	}
 
@@ -204,7 +227,8 @@ func CreateMessenger(pubKey *crypto.PublicKey, seedPeerMultiAddresses []string,
 		ctx,
 		libp2p.EnableRelay(),
 		libp2p.Identity(hostId),
-		libp2p.ListenAddrs([]ma.Multiaddr{localNetAddress}...),
+		transportOption(msgrConfig.transports),
+		libp2p.ListenAddrs(localNetAddrs...),
 		libp2p.AddrsFactory(addressFactory),
 		libp2p.ConnectionManager(cm),
 	)
@@ -387,7 +411,7 @@ func (msgr *Messenger) maintainSeedsConnectivity(ctx context.Context) {
 			msgr.wg.Add(1)
 			go func() {
 				defer msgr.wg.Done()
-				err := msgr.host.Connect(ctx, *seedPeer)
+				err := msgr.connect(ctx, *seedPeer)
 				if err == nil {
 					logger.Infof("Successfully re-connected to seed peer: %v", seedPeer)
 				} else {
@@ -439,7 +463,7 @@ func (msgr *Messenger) maintainSufficientConnections(ctx context.Context) {
 				defer msgr.wg.Done()
 				j := perm[i]
 				peer := connections[j]
-				err := msgr.host.Connect(ctx, *peer)
+				err := msgr.connect(ctx, *peer)
 				if err == nil {
 					logger.Infof("Successfully re-connected to peer: %v", peer)
 				} else {
@@ -491,7 +515,7 @@ func (msgr *Messenger) Start(ctx context.Context) error {
 
 			j := perm[i]
 			seedPeer := connections[j]
-			err := msgr.host.Connect(ctx, *seedPeer)
+			err := msgr.connect(ctx, *seedPeer)
 			if err != nil {
 				logger.Warnf("Failed to connect to peer %v: %v. connectedness: %v", seedPeer, err, msgr.host.Network().Connectedness(seedPeer.ID))
 			}
@@ -667,6 +691,17 @@ func (msgr *Messenger) PeerURLs(skipEdgeNode bool) []string {
 	return peerURLs
 }
 
+// PeerCapabilities returns the capabilities advertised by the peers. The capabilities are not
+// exchanged over libp2p yet, so they are nil for all the peers
+func (msgr *Messenger) PeerCapabilities(skipEdgeNode bool) map[string]*p2ptypes.NodeCapabilities {
+	allPeers := msgr.peerTable.GetAllPeers(skipEdgeNode)
+	capabilities := make(map[string]*p2ptypes.NodeCapabilities)
+	for _, peer := range *allPeers {
+		capabilities[peer.ID().Pretty()] = nil
+	}
+	return capabilities
+}
+
 // PeerExists indicates if the given peerID is a neighboring peer
 func (msgr *Messenger) PeerExists(peerID string) bool {
 	prID, err := pr.IDB58Decode(peerID)
@@ -797,7 +832,7 @@ func (msgr *Messenger) registerStreamHandler(channelID common.ChannelIDEnum) {
 			}
 			stream := transport.NewBufferedStream(strm, errorHandler)
 			stream.Start(msgr.ctx)
-			go msgr.readPeerMessageRoutine(stream, peerID.String(), channelID)
+			go msgr.readPeerMessageRoutine(stream, peerID.String(), channelID, transportOfConn(strm.Conn()))
 			remotePeer.AcceptStream(channelID, stream)
 
 		} else {
@@ -806,6 +841,9 @@ func (msgr *Messenger) registerStreamHandler(channelID common.ChannelIDEnum) {
 				logger.Warnf("Failed to read stream, %v. channel: %v, peer: %v", err, channelID, peerID)
 				return
 			}
+			if msgr.seenCache.CheckAndMark(channelID, rawPeerMsg) {
+				return
+			}
 			msgHandler := msgr.msgHandlerMap[channelID]
 			message, err := msgHandler.ParseMessage(peerID.String(), channelID, rawPeerMsg)
 			if err != nil {
@@ -814,13 +852,14 @@ func (msgr *Messenger) registerStreamHandler(channelID common.ChannelIDEnum) {
 			}
 
 			msgr.recordReceivedBytes(channelID, len(rawPeerMsg))
+			msgr.recordTransportBytes(transportOfConn(strm.Conn()), len(rawPeerMsg))
 
 			msgHandler.HandleMessage(message)
 		}
 	})
 }
 
-func (msgr *Messenger) readPeerMessageRoutine(stream *transport.BufferedStream, peerID string, channelID common.ChannelIDEnum) {
+func (msgr *Messenger) readPeerMessageRoutine(stream *transport.BufferedStream, peerID string, channelID common.ChannelIDEnum, transportName string) {
 	defer stream.Stop()
 
 	for {
@@ -864,6 +903,11 @@ func (msgr *Messenger) readPeerMessageRoutine(stream *transport.BufferedStream,
 
 		rawPeerMsg := msgBuffer[:msgSize]
 
+		if msgr.seenCache.CheckAndMark(channelID, rawPeerMsg) {
+			bufferPool <- msgBuffer
+			continue
+		}
+
 		msgHandler := msgr.msgHandlerMap[channelID]
 		message, err := msgHandler.ParseMessage(peerID, channelID, rawPeerMsg)
 		bufferPool <- msgBuffer
@@ -873,6 +917,7 @@ func (msgr *Messenger) readPeerMessageRoutine(stream *transport.BufferedStream,
 		}
 
 		msgr.recordReceivedBytes(channelID, len(rawPeerMsg))
+		msgr.recordTransportBytes(transportName, len(rawPeerMsg))
 
 		msgHandler.HandleMessage(message)
 	}
@@ -885,6 +930,7 @@ func (msgr *Messenger) attachHandlersToPeer(peer *peer.Peer) {
 		msgHandler := msgr.msgHandlerMap[channelID]
 		if msgHandler == nil {
 			logger.Errorf("Failed to setup message parser for channelID %v", channelID)
+			return p2ptypes.Message{}, fmt.Errorf("no message handler for channelID %v", channelID)
 		}
 		message, err := msgHandler.ParseMessage(peerID.String(), channelID, rawMessageBytes)
 
@@ -905,6 +951,7 @@ func (msgr *Messenger) attachHandlersToPeer(peer *peer.Peer) {
 		msgHandler := msgr.msgHandlerMap[channelID]
 		if msgHandler == nil {
 			logger.Errorf("Failed to setup message handler for peer %v on channelID %v", message.PeerID, channelID)
+			return fmt.Errorf("no message handler for channelID %v", channelID)
 		}
 		err := msgHandler.HandleMessage(message)
 		return err
@@ -927,7 +974,7 @@ func (msgr *Messenger) attachHandlersToPeer(peer *peer.Peer) {
 		}
 		stream := transport.NewBufferedStream(strm, errorHandler)
 		stream.Start(msgr.ctx)
-		go msgr.readPeerMessageRoutine(stream, peer.ID().String(), channelID)
+		go msgr.readPeerMessageRoutine(stream, peer.ID().String(), channelID, transportOfConn(strm.Conn()))
 		return stream, nil
 	}
 	peer.SetStreamCreator(streamCreator)
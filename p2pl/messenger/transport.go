@@ -0,0 +1,120 @@
+package messenger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p-core/network"
+	pr "github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/thetatoken/theta/common/metrics"
+)
+
+const (
+	// TransportTCP is the TCP transport, with the streams multiplexed by yamux/mplex
+	TransportTCP = "tcp"
+	// TransportQUIC is the QUIC transport. Each channel stream maps to a native QUIC stream,
+	// so a lost packet on the block channel does not stall the vote channel, which benefits
+	// nodes on lossy links. Only available in the binaries built with the quic tag
+	TransportQUIC = "quic"
+)
+
+// parseTransports parses the comma separated transport list, e.g. "tcp,quic".
+func parseTransports(transportsStr string) ([]string, error) {
+	transports := []string{}
+	for _, t := range strings.Split(transportsStr, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if t != TransportTCP && t != TransportQUIC {
+			return nil, fmt.Errorf("Unsupported libp2p transport: %v", t)
+		}
+		if t == TransportQUIC && !QUICTransportEnabled {
+			return nil, fmt.Errorf("The QUIC transport is not built in, rebuild with -tags quic to enable it")
+		}
+		transports = append(transports, t)
+	}
+	if len(transports) == 0 {
+		return nil, fmt.Errorf("No libp2p transport specified")
+	}
+	return transports, nil
+}
+
+// createTransportAddrs creates the listen addresses for each of the transports.
+func createTransportAddrs(ip, port string, transports []string) ([]ma.Multiaddr, error) {
+	addrs := []ma.Multiaddr{}
+	for _, t := range transports {
+		networkProtocol := TransportTCP
+		if t == TransportQUIC {
+			networkProtocol = "udp"
+		}
+		addr, err := createP2PAddr(ip, port, networkProtocol)
+		if err != nil {
+			return nil, err
+		}
+		if t == TransportQUIC {
+			quicAddr, err := ma.NewMultiaddr("/quic")
+			if err != nil {
+				return nil, err
+			}
+			addr = addr.Encapsulate(quicAddr)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// transportOption returns the libp2p option enabling the given transports.
+func transportOption(transports []string) libp2p.Option {
+	opts := []libp2p.Option{}
+	for _, t := range transports {
+		switch t {
+		case TransportTCP:
+			opts = append(opts, libp2p.DefaultTransports)
+		case TransportQUIC:
+			opts = append(opts, quicTransportOption())
+		}
+	}
+	return libp2p.ChainOptions(opts...)
+}
+
+// transportOfConn returns the transport of the given connection.
+func transportOfConn(conn network.Conn) string {
+	if _, err := conn.RemoteMultiaddr().ValueForProtocol(ma.P_QUIC); err == nil {
+		return TransportQUIC
+	}
+	return TransportTCP
+}
+
+// connect connects to the given peer, and records the handshake latency of the transport
+// being used.
+func (msgr *Messenger) connect(ctx context.Context, addrInfo pr.AddrInfo) error {
+	if msgr.host.Network().Connectedness(addrInfo.ID) == network.Connected {
+		return nil
+	}
+
+	start := time.Now()
+	err := msgr.host.Connect(ctx, addrInfo)
+	if err != nil {
+		return err
+	}
+	elapsed := time.Since(start)
+
+	conns := msgr.host.Network().ConnsToPeer(addrInfo.ID)
+	if len(conns) > 0 {
+		transport := transportOfConn(conns[0])
+		metrics.GetOrRegisterTimer("p2pl/handshake/"+transport, nil).Update(elapsed)
+		logger.Debugf("Connected to peer %v via %v in %v", addrInfo.ID, transport, elapsed)
+	}
+	return nil
+}
+
+// recordTransportBytes records the throughput of the transport.
+func (msgr *Messenger) recordTransportBytes(transport string, size int) {
+	metrics.GetOrRegisterMeter("p2pl/received/"+transport, nil).Mark(int64(size))
+}
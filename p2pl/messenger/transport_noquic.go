@@ -0,0 +1,17 @@
+// +build !quic
+
+package messenger
+
+import (
+	"github.com/libp2p/go-libp2p"
+)
+
+// QUICTransportEnabled tells whether the binary was built with the QUIC transport
+const QUICTransportEnabled = false
+
+// quicTransportOption is never reached, parseTransports rejects "quic" unless the binary was
+// built with the quic tag. The quic-go version libp2p pulls in panics at init on recent Go
+// toolchains, so the regular builds do not link it.
+func quicTransportOption() libp2p.Option {
+	return nil
+}
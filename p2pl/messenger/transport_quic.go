@@ -0,0 +1,15 @@
+// +build quic
+
+package messenger
+
+import (
+	"github.com/libp2p/go-libp2p"
+	libp2pquic "github.com/libp2p/go-libp2p-quic-transport"
+)
+
+// QUICTransportEnabled tells whether the binary was built with the QUIC transport
+const QUICTransportEnabled = true
+
+func quicTransportOption() libp2p.Option {
+	return libp2p.Transport(libp2pquic.NewTransport)
+}
@@ -0,0 +1,134 @@
+package messenger
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	pr "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTransports(t *testing.T) {
+	assert := assert.New(t)
+
+	transports, err := parseTransports("tcp")
+	assert.Nil(err)
+	assert.Equal([]string{TransportTCP}, transports)
+
+	transports, err = parseTransports(" TCP, quic ")
+	if QUICTransportEnabled {
+		assert.Nil(err)
+		assert.Equal([]string{TransportTCP, TransportQUIC}, transports)
+	} else {
+		assert.NotNil(err)
+	}
+
+	_, err = parseTransports("udp")
+	assert.NotNil(err)
+
+	_, err = parseTransports("")
+	assert.NotNil(err)
+}
+
+func TestCreateTransportAddrs(t *testing.T) {
+	assert := assert.New(t)
+
+	addrs, err := createTransportAddrs("0.0.0.0", "12000", []string{TransportTCP, TransportQUIC})
+	assert.Nil(err)
+	assert.Equal(2, len(addrs))
+	assert.Equal("/ip4/0.0.0.0/tcp/12000", addrs[0].String())
+	assert.Equal("/ip4/0.0.0.0/udp/12000/quic", addrs[1].String())
+}
+
+// The transport benchmarks below measure the handshake latency and the stream throughput of each
+// transport over the loopback interface. To measure them under packet loss, e.g. 2%, run them with
+// the loss emulated by netem:
+//
+//		sudo tc qdisc add dev lo root netem loss 2%
+//		go test ./p2pl/messenger -run none -bench Transport
+//		sudo tc qdisc del dev lo root
+//
+// Add -tags quic to include the QUIC transport.
+
+const benchmarkProtocol = "/theta/benchmark/1.0.0"
+
+func benchmarkTransports() []string {
+	if QUICTransportEnabled {
+		return []string{TransportTCP, TransportQUIC}
+	}
+	return []string{TransportTCP}
+}
+
+func newBenchmarkHost(b *testing.B, transport string) host.Host {
+	addrs, err := createTransportAddrs("127.0.0.1", "0", []string{transport})
+	require.Nil(b, err)
+	h, err := libp2p.New(context.Background(), transportOption([]string{transport}), libp2p.ListenAddrs(addrs...))
+	require.Nil(b, err)
+	return h
+}
+
+func BenchmarkTransportHandshake(b *testing.B) {
+	for _, transport := range benchmarkTransports() {
+		b.Run(transport, func(b *testing.B) {
+			server := newBenchmarkHost(b, transport)
+			defer server.Close()
+			serverInfo := pr.AddrInfo{ID: server.ID(), Addrs: server.Addrs()}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				client := newBenchmarkHost(b, transport)
+				b.StartTimer()
+
+				if err := client.Connect(context.Background(), serverInfo); err != nil {
+					b.Fatal(err)
+				}
+
+				b.StopTimer()
+				client.Close()
+				b.StartTimer()
+			}
+		})
+	}
+}
+
+func BenchmarkTransportThroughput(b *testing.B) {
+	chunk := make([]byte, 64*1024)
+	for _, transport := range benchmarkTransports() {
+		b.Run(transport, func(b *testing.B) {
+			server := newBenchmarkHost(b, transport)
+			defer server.Close()
+			received := make(chan int64)
+			server.SetStreamHandler(benchmarkProtocol, func(strm network.Stream) {
+				n, _ := io.Copy(ioutil.Discard, strm)
+				strm.Close()
+				received <- n
+			})
+
+			client := newBenchmarkHost(b, transport)
+			defer client.Close()
+			ctx := context.Background()
+			require.Nil(b, client.Connect(ctx, pr.AddrInfo{ID: server.ID(), Addrs: server.Addrs()}))
+			strm, err := client.NewStream(ctx, server.ID(), benchmarkProtocol)
+			require.Nil(b, err)
+
+			b.SetBytes(int64(len(chunk)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := strm.Write(chunk); err != nil {
+					b.Fatal(err)
+				}
+			}
+			strm.Close()
+			if n := <-received; n != int64(b.N*len(chunk)) {
+				b.Fatalf("Received %v bytes, expected %v", n, b.N*len(chunk))
+			}
+		})
+	}
+}
@@ -0,0 +1,479 @@
+package rpc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/rpc"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+)
+
+//
+// The audit log records the calls of the sensitive RPC methods, i.e. the transaction broadcasts, the
+// admin and the keystore methods by default: who made the call, from which address and with which API
+// key, a digest of the parameters and the outcome. The records are written as JSON lines to a size
+// rotated file, or to the syslog. The parameters and the API keys are never logged in clear.
+//
+
+const (
+	// AuditLogSyslog is the value of the audit log config directing the records to the syslog
+	AuditLogSyslog = "syslog"
+
+	// APIKeyHeader is the HTTP header of the API key of the caller, if any
+	APIKeyHeader = "X-API-Key"
+
+	auditOutcomeOK       = "ok"
+	auditOutcomeError    = "error"
+	auditOutcomeRejected = "rejected"
+
+	// maxAuditedResponseBytes limits the response captured to determine the outcomes of the calls
+	maxAuditedResponseBytes = 1024 * 1024
+
+	// jsonrpc2BatchMethod is the method of a batch over a persistent connection, whose calls are not visible
+	// to the codec
+	jsonrpc2BatchMethod = "JSONRPC2.Batch"
+)
+
+// The methods audited by default
+var defaultAuditedMethods = concatMethods(adminMethods, keystoreMethods, []string{"theta.Broadcast*"})
+
+// AuditRecord is an entry of the audit log
+type AuditRecord struct {
+	Time         time.Time `json:"time"`
+	ChainID      string    `json:"chain_id,omitempty"`
+	Method       string    `json:"method"`
+	RemoteAddr   string    `json:"remote_addr"`
+	ForwardedFor string    `json:"forwarded_for,omitempty"`
+	APIKey       string    `json:"api_key,omitempty"` // digest of the API key
+	ParamsDigest string    `json:"params_digest"`
+	Outcome      string    `json:"outcome"` // ok, error or rejected
+	Error        string    `json:"error,omitempty"`
+}
+
+// auditSource identifies the caller
+type auditSource struct {
+	remoteAddr   string
+	forwardedFor string
+	apiKey       string
+}
+
+func newAuditSource(r *http.Request) auditSource {
+	remoteAddr := r.RemoteAddr
+	if remoteAddr == "" || remoteAddr == "@" {
+		remoteAddr = "ipc" // the peers of the Unix domain sockets have no address
+	}
+	source := auditSource{
+		remoteAddr:   remoteAddr,
+		forwardedFor: r.Header.Get("X-Forwarded-For"),
+	}
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		source.apiKey = digest([]byte(key))[:16]
+	}
+	return source
+}
+
+func digest(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// paramsDigest returns the digest of the params in the canonical JSON encoding, so that the same params
+// have the same digest regardless of their formatting
+func paramsDigest(params []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(params, &v); err == nil {
+		if canonical, err := json.Marshal(v); err == nil {
+			params = canonical
+		}
+	}
+	return digest(params)
+}
+
+// AuditLogger writes the audit records of the calls of the audited methods
+type AuditLogger struct {
+	mu      *sync.Mutex
+	w       io.Writer
+	methods []string
+	chainID string
+}
+
+// NewAuditLogger creates an AuditLogger writing the records of the calls of the methods matching the given
+// patterns to the given writer
+func NewAuditLogger(w io.Writer, methods []string, chainID string) *AuditLogger {
+	return &AuditLogger{
+		mu:      &sync.Mutex{},
+		w:       w,
+		methods: methods,
+		chainID: chainID,
+	}
+}
+
+// newAuditLoggerFromConfig creates the AuditLogger of the config, nil if the audit log is disabled
+func newAuditLoggerFromConfig(chainID string) (*AuditLogger, error) {
+	target := viper.GetString(common.CfgRPCAuditLog)
+	if target == "" {
+		return nil, nil
+	}
+	w, err := getAuditWriter(target)
+	if err != nil {
+		return nil, err
+	}
+	methods := viper.GetStringSlice(common.CfgRPCAuditMethods)
+	if len(methods) == 0 {
+		methods = defaultAuditedMethods
+	}
+	return NewAuditLogger(w, methods, chainID), nil
+}
+
+func (a *AuditLogger) isAudited(method string) bool {
+	if method == jsonrpc2BatchMethod {
+		return true
+	}
+	for _, pattern := range a.methods {
+		if matchMethod(pattern, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *AuditLogger) write(record *AuditRecord) {
+	record.ChainID = a.chainID
+	raw, err := json.Marshal(record)
+	if err != nil {
+		logger.Warnf("Failed to encode the audit record: %v", err)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.w.Write(append(raw, '\n')); err != nil {
+		logger.Warnf("Failed to write the audit record: %v", err)
+	}
+}
+
+type auditedCall struct {
+	Method string           `json:"method"`
+	Params *json.RawMessage `json:"params"`
+	ID     *json.RawMessage `json:"id"`
+}
+
+type auditedResponse struct {
+	ID    *json.RawMessage `json:"id"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func rawString(raw *json.RawMessage) string {
+	if raw == nil {
+		return "null"
+	}
+	return string(*raw)
+}
+
+// Handler wraps the given JSON-RPC HTTP handler to record the calls of the audited methods. The response is
+// captured to determine the outcomes of the calls. A nil AuditLogger records nothing.
+func (a *AuditLogger) Handler(h http.Handler) http.Handler {
+	if a == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		calls := []auditedCall{}
+		trimmed := bytes.TrimSpace(body)
+		if len(trimmed) > 0 && trimmed[0] == '[' {
+			json.Unmarshal(trimmed, &calls)
+		} else {
+			call := auditedCall{}
+			if json.Unmarshal(trimmed, &call) == nil {
+				calls = append(calls, call)
+			}
+		}
+		audited := []auditedCall{}
+		for _, call := range calls {
+			if a.isAudited(call.Method) {
+				audited = append(audited, call)
+			}
+		}
+		if len(audited) == 0 {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &auditResponseRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+
+		outcomes := a.parseOutcomes(rec)
+		source := newAuditSource(r)
+		now := time.Now()
+		for _, call := range audited {
+			record := &AuditRecord{
+				Time:         now,
+				Method:       call.Method,
+				RemoteAddr:   source.remoteAddr,
+				ForwardedFor: source.forwardedFor,
+				APIKey:       source.apiKey,
+				ParamsDigest: paramsDigest([]byte(rawString(call.Params))),
+			}
+			if outcome, ok := outcomes[rawString(call.ID)]; ok {
+				record.Outcome, record.Error = outcome[0], outcome[1]
+			} else {
+				record.Outcome, record.Error = outcomes[""][0], outcomes[""][1]
+			}
+			a.write(record)
+		}
+	})
+}
+
+// parseOutcomes returns the outcome and the error of each call by its ID. The entry of the empty ID is the
+// outcome of the calls without a response of their own, e.g. when the whole request is rejected.
+func (a *AuditLogger) parseOutcomes(rec *auditResponseRecorder) map[string][2]string {
+	outcomes := map[string][2]string{}
+	if rec.status != http.StatusOK {
+		message := fmt.Sprintf("HTTP status %v", rec.status)
+		resp := auditedResponse{}
+		if json.Unmarshal(rec.body.Bytes(), &resp) == nil && resp.Error != nil {
+			message = resp.Error.Message
+		}
+		outcomes[""] = [2]string{auditOutcomeRejected, message}
+		return outcomes
+	}
+	outcomes[""] = [2]string{auditOutcomeError, "no response"}
+
+	responses := []auditedResponse{}
+	trimmed := bytes.TrimSpace(rec.body.Bytes())
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		json.Unmarshal(trimmed, &responses)
+	} else {
+		resp := auditedResponse{}
+		if json.Unmarshal(trimmed, &resp) == nil {
+			responses = append(responses, resp)
+		}
+	}
+	for _, resp := range responses {
+		if resp.Error != nil {
+			outcomes[rawString(resp.ID)] = [2]string{auditOutcomeError, resp.Error.Message}
+		} else {
+			outcomes[rawString(resp.ID)] = [2]string{auditOutcomeOK, ""}
+		}
+	}
+	return outcomes
+}
+
+// auditResponseRecorder captures the status and the beginning of the body of a response
+type auditResponseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *auditResponseRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *auditResponseRecorder) Write(p []byte) (int, error) {
+	if remaining := maxAuditedResponseBytes - rec.body.Len(); remaining > 0 {
+		if len(p) < remaining {
+			remaining = len(p)
+		}
+		rec.body.Write(p[:remaining])
+	}
+	return rec.ResponseWriter.Write(p)
+}
+
+// Codec wraps the codec of a persistent connection, e.g. a websocket, to record the calls of the audited
+// methods. The params digest is computed over the decoded params, and the calls of a batch are recorded as
+// a whole. A nil AuditLogger records nothing.
+func (a *AuditLogger) Codec(r *http.Request, codec rpc.ServerCodec) rpc.ServerCodec {
+	if a == nil {
+		return codec
+	}
+	return &auditCodec{
+		ServerCodec: codec,
+		logger:      a,
+		source:      newAuditSource(r),
+		pending:     make(map[uint64]*AuditRecord),
+	}
+}
+
+type auditCodec struct {
+	rpc.ServerCodec
+	logger *AuditLogger
+	source auditSource
+
+	mu      sync.Mutex
+	current *AuditRecord // the record of the request being read
+	pending map[uint64]*AuditRecord
+}
+
+func (c *auditCodec) ReadRequestHeader(r *rpc.Request) error {
+	if err := c.ServerCodec.ReadRequestHeader(r); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current = nil
+	if c.logger.isAudited(r.ServiceMethod) {
+		c.current = &AuditRecord{
+			Method:       r.ServiceMethod,
+			RemoteAddr:   c.source.remoteAddr,
+			ForwardedFor: c.source.forwardedFor,
+			APIKey:       c.source.apiKey,
+		}
+		c.pending[r.Seq] = c.current
+	}
+	return nil
+}
+
+func (c *auditCodec) ReadRequestBody(x interface{}) error {
+	err := c.ServerCodec.ReadRequestBody(x)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.current != nil {
+		params, _ := json.Marshal(x)
+		c.current.ParamsDigest = paramsDigest(params)
+		c.current = nil
+	}
+	return err
+}
+
+func (c *auditCodec) WriteResponse(r *rpc.Response, x interface{}) error {
+	c.mu.Lock()
+	record, ok := c.pending[r.Seq]
+	delete(c.pending, r.Seq)
+	c.mu.Unlock()
+
+	if ok {
+		record.Time = time.Now()
+		record.Outcome = auditOutcomeOK
+		if r.Error != "" {
+			record.Outcome, record.Error = auditOutcomeError, r.Error
+		}
+		c.logger.write(record)
+	}
+	return c.ServerCodec.WriteResponse(r, x)
+}
+
+//
+// --------------------- Audit log writers -------------------------
+//
+
+var (
+	auditWritersMu = &sync.Mutex{}
+	auditWriters   = map[string]io.Writer{} // shared by the RPC servers of the chains hosted in the same process
+)
+
+// getAuditWriter returns the writer of the given audit log target, i.e. the syslog or the path of a file
+func getAuditWriter(target string) (io.Writer, error) {
+	auditWritersMu.Lock()
+	defer auditWritersMu.Unlock()
+
+	if w, ok := auditWriters[target]; ok {
+		return w, nil
+	}
+	var w io.Writer
+	var err error
+	if target == AuditLogSyslog {
+		w, err = newAuditSyslogWriter()
+	} else {
+		w, err = newRotatingFile(target, viper.GetInt64(common.CfgRPCAuditLogMaxSizeMB)*1024*1024,
+			viper.GetInt(common.CfgRPCAuditLogMaxBackups))
+	}
+	if err != nil {
+		return nil, err
+	}
+	auditWriters[target] = w
+	return w, nil
+}
+
+// rotatingFile is a file which is rotated once it exceeds the max size. The rotated files are suffixed with
+// .1, .2, etc. from the latest, and the ones beyond the max number of backups are removed.
+type rotatingFile struct {
+	mu         *sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	file *os.File
+	size int64
+}
+
+func newRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	rf := &rotatingFile{
+		mu:         &sync.Mutex{},
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *rotatingFile) open() error {
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	rf.file = file
+	rf.size = info.Size()
+	return nil
+}
+
+func (rf *rotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return err
+	}
+	os.Remove(fmt.Sprintf("%v.%v", rf.path, rf.maxBackups))
+	for i := rf.maxBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%v.%v", rf.path, i), fmt.Sprintf("%v.%v", rf.path, i+1))
+	}
+	if rf.maxBackups > 0 {
+		if err := os.Rename(rf.path, rf.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(rf.path); err != nil {
+		return err
+	}
+	return rf.open()
+}
+
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.maxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.maxSize {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
@@ -0,0 +1,12 @@
+// +build !windows
+
+package rpc
+
+import (
+	"io"
+	"log/syslog"
+)
+
+func newAuditSyslogWriter() (io.Writer, error) {
+	return syslog.New(syslog.LOG_INFO|syslog.LOG_AUTH, "theta-rpc-audit")
+}
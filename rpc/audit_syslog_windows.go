@@ -0,0 +1,10 @@
+package rpc
+
+import (
+	"errors"
+	"io"
+)
+
+func newAuditSyslogWriter() (io.Writer, error) {
+	return nil, errors.New("syslog is not supported on Windows")
+}
@@ -0,0 +1,120 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAuditRecords(t *testing.T, buf *bytes.Buffer) []AuditRecord {
+	records := []AuditRecord{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		record := AuditRecord{}
+		require.Nil(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	buf.Reset()
+	return records
+}
+
+func TestAuditHandler(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	buf := &bytes.Buffer{}
+	audit := NewAuditLogger(buf, defaultAuditedMethods, "testchain")
+	handler := audit.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case bytes.Contains(body, []byte("BackupDB")):
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32000,"message":"RPC method theta.BackupDB is not enabled on this endpoint"}}`))
+		case bytes.HasPrefix(body, []byte("[")):
+			w.Write([]byte(`[{"jsonrpc":"2.0","result":{},"id":1},{"jsonrpc":"2.0","error":{"code":-32000,"message":"invalid tx"},"id":2}]`))
+		default:
+			w.Write([]byte(`{"jsonrpc":"2.0","result":{},"id":1}`))
+		}
+	}))
+	call := func(body string, apiKey string) {
+		r := httptest.NewRequest("POST", "/rpc", strings.NewReader(body))
+		r.RemoteAddr = "10.0.0.1:1234"
+		if apiKey != "" {
+			r.Header.Set(APIKeyHeader, apiKey)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	// The methods not audited are not recorded
+	call(`{"jsonrpc":"2.0","method":"theta.GetBlock","params":[{"hash":"0x01"}],"id":1}`, "")
+	assert.Equal(0, buf.Len())
+
+	call(`{"jsonrpc":"2.0","method":"theta.BroadcastRawTransaction","params":[{"tx_bytes":"0x01"}],"id":1}`, "secret")
+	records := readAuditRecords(t, buf)
+	require.Equal(1, len(records))
+	assert.Equal("theta.BroadcastRawTransaction", records[0].Method)
+	assert.Equal("testchain", records[0].ChainID)
+	assert.Equal("10.0.0.1:1234", records[0].RemoteAddr)
+	assert.Equal(auditOutcomeOK, records[0].Outcome)
+	assert.NotEqual("", records[0].APIKey)
+	assert.NotContains(records[0].APIKey, "secret")
+	assert.Equal(paramsDigest([]byte(`[{"tx_bytes": "0x01"}]`)), records[0].ParamsDigest)
+
+	// The outcomes of the calls of a batch are matched by their IDs
+	call(`[{"method":"theta.BroadcastRawTransaction","params":[{"tx_bytes":"0x01"}],"id":1},`+
+		`{"method":"theta.GetBlock","params":[{}],"id":3},`+
+		`{"method":"theta.BroadcastRawTransactionAsync","params":[{"tx_bytes":"0x02"}],"id":2}]`, "")
+	records = readAuditRecords(t, buf)
+	require.Equal(2, len(records))
+	assert.Equal(auditOutcomeOK, records[0].Outcome)
+	assert.Equal("theta.BroadcastRawTransactionAsync", records[1].Method)
+	assert.Equal(auditOutcomeError, records[1].Outcome)
+	assert.Equal("invalid tx", records[1].Error)
+	assert.Equal("", records[1].APIKey)
+
+	call(`{"jsonrpc":"2.0","method":"theta.BackupDB","params":[{}],"id":1}`, "")
+	records = readAuditRecords(t, buf)
+	require.Equal(1, len(records))
+	assert.Equal(auditOutcomeRejected, records[0].Outcome)
+	assert.Contains(records[0].Error, "not enabled")
+}
+
+func TestRotatingFile(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "audit")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	rf, err := newRotatingFile(path, 10, 2)
+	require.Nil(err)
+	for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		_, err := rf.Write([]byte(line))
+		require.Nil(err)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	require.Nil(err)
+	assert.Equal("dddddddd\n", string(content))
+	content, err = ioutil.ReadFile(path + ".1")
+	require.Nil(err)
+	assert.Equal("cccccccc\n", string(content))
+	content, err = ioutil.ReadFile(path + ".2")
+	require.Nil(err)
+	assert.Equal("bbbbbbbb\n", string(content))
+	_, err = os.Stat(path + ".3")
+	assert.True(os.IsNotExist(err))
+}
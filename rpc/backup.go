@@ -1,11 +1,16 @@
 package rpc
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path"
+	"sync"
+	"time"
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/snapshot"
+	"github.com/thetatoken/theta/store/database/backend"
 )
 
 // ------------------------------- BackupSnapshot -----------------------------------
@@ -20,7 +25,9 @@ type BackupSnapshotResult struct {
 	SnapshotFile string `json:"snapshot_file"`
 }
 
-func (t *ThetaRPCService) BackupSnapshot(args *BackupSnapshotArgs, result *BackupSnapshotResult) error {
+func (t *ThetaRPCService) BackupSnapshot(args *BackupSnapshotArgs, result *BackupSnapshotResult) (err error) {
+	defer t.guard("BackupSnapshot", &err)()
+
 	// Default to older verison
 	if args.Version == 0 {
 		args.Version = 2
@@ -46,6 +53,85 @@ func (t *ThetaRPCService) BackupSnapshot(args *BackupSnapshotArgs, result *Backu
 	return err
 }
 
+// ------------------------------- VerifySnapshot -----------------------------------
+
+type VerifySnapshotArgs struct {
+	SnapshotFile string `json:"snapshot_file"`
+}
+
+type VerifySnapshotResult struct {
+	*snapshot.SnapshotVerification
+}
+
+func (t *ThetaRPCService) VerifySnapshot(args *VerifySnapshotArgs, result *VerifySnapshotResult) (err error) {
+	defer t.guard("VerifySnapshot", &err)()
+
+	if len(args.SnapshotFile) == 0 {
+		return errors.New("snapshot_file is required")
+	}
+	result.SnapshotVerification, err = snapshot.VerifySnapshot(args.SnapshotFile)
+	return err
+}
+
+// ------------------------------- GetSnapshotSchedule -----------------------------------
+
+type GetSnapshotScheduleArgs struct{}
+
+type GetSnapshotScheduleResult struct {
+	snapshot.SnapshotScheduleStatus
+}
+
+func (t *ThetaRPCService) GetSnapshotSchedule(args *GetSnapshotScheduleArgs, result *GetSnapshotScheduleResult) (err error) {
+	defer t.guard("GetSnapshotSchedule", &err)()
+
+	if t.snapshots == nil {
+		return errors.New("snapshot scheduler is not available")
+	}
+	result.SnapshotScheduleStatus = t.snapshots.Status()
+	return nil
+}
+
+// ------------------------------- UpdateSnapshotSchedule -----------------------------------
+
+// UpdateSnapshotScheduleArgs holds the fields of the schedule to update, the omitted ones are unchanged
+type UpdateSnapshotScheduleArgs struct {
+	Enabled           *bool              `json:"enabled"`
+	Interval          *common.JSONUint64 `json:"interval"`
+	RetainedSnapshots *int               `json:"retained_snapshots"`
+	MaxBytesPerSec    *int64             `json:"max_bytes_per_sec"`
+}
+
+type UpdateSnapshotScheduleResult struct {
+	snapshot.SnapshotSchedule
+}
+
+func (t *ThetaRPCService) UpdateSnapshotSchedule(args *UpdateSnapshotScheduleArgs, result *UpdateSnapshotScheduleResult) (err error) {
+	defer t.guard("UpdateSnapshotSchedule", &err)()
+
+	if t.snapshots == nil {
+		return errors.New("snapshot scheduler is not available")
+	}
+
+	schedule := t.snapshots.Schedule()
+	if args.Enabled != nil {
+		schedule.Enabled = *args.Enabled
+	}
+	if args.Interval != nil {
+		schedule.Interval = *args.Interval
+	}
+	if args.RetainedSnapshots != nil {
+		schedule.RetainedSnapshots = *args.RetainedSnapshots
+	}
+	if args.MaxBytesPerSec != nil {
+		schedule.MaxBytesPerSec = *args.MaxBytesPerSec
+	}
+	if err := t.snapshots.SetSchedule(schedule); err != nil {
+		return err
+	}
+	result.SnapshotSchedule = schedule
+	return nil
+}
+
 // ------------------------------- BackupChain -----------------------------------
 
 type BackupChainArgs struct {
@@ -60,7 +146,9 @@ type BackupChainResult struct {
 	ChainFile         string `json:"chain_file"`
 }
 
-func (t *ThetaRPCService) BackupChain(args *BackupChainArgs, result *BackupChainResult) error {
+func (t *ThetaRPCService) BackupChain(args *BackupChainArgs, result *BackupChainResult) (err error) {
+	defer t.guard("BackupChain", &err)()
+
 	chain := t.chain
 	startHeight := args.Start
 	endHeight := args.End
@@ -92,7 +180,9 @@ type BackupChainCorrectionResult struct {
 	BlockHashMap map[uint64]string `json:"block_hash_map"`
 }
 
-func (t *ThetaRPCService) BackupChainCorrection(args *BackupChainCorrectionArgs, result *BackupChainCorrectionResult) error {
+func (t *ThetaRPCService) BackupChainCorrection(args *BackupChainCorrectionArgs, result *BackupChainCorrectionResult) (err error) {
+	defer t.guard("BackupChainCorrection", &err)()
+
 	chain := t.chain
 	ledger := t.consensus.GetLedger()
 	snapshotHeight := args.SnapshotHeight
@@ -110,3 +200,95 @@ func (t *ThetaRPCService) BackupChainCorrection(args *BackupChainCorrectionArgs,
 
 	return err
 }
+
+// ------------------------------- BackupDB -----------------------------------
+
+// dbBackupJob tracks the database backup running in the background
+type dbBackupJob struct {
+	mu     sync.Mutex
+	status GetDBBackupStatusResult
+}
+
+// databaseBackuper is implemented by the databases supporting online backups
+type databaseBackuper interface {
+	Backup(backupDir, baseDir string) (*backend.BackupManifest, error)
+}
+
+type BackupDBArgs struct {
+	BackupDir string `json:"backup_dir"`
+	BaseDir   string `json:"base_dir"` // Full backup to take an incremental backup against, empty for a full backup
+}
+
+type BackupDBResult struct {
+	BackupDir string            `json:"backup_dir"`
+	Height    common.JSONUint64 `json:"height"`
+}
+
+// BackupDB starts backing up the database in the background without stopping the node. The progress
+// can be queried with GetDBBackupStatus.
+func (t *ThetaRPCService) BackupDB(args *BackupDBArgs, result *BackupDBResult) (err error) {
+	defer t.guard("BackupDB", &err)()
+
+	if len(args.BackupDir) == 0 {
+		return errors.New("backup_dir is required")
+	}
+	db, ok := t.ledger.State().DB().(databaseBackuper)
+	if !ok {
+		return errors.New("the database does not support online backups")
+	}
+
+	t.dbBackup.mu.Lock()
+	defer t.dbBackup.mu.Unlock()
+	if t.dbBackup.status.Running {
+		return fmt.Errorf("backup to %v is in progress", t.dbBackup.status.BackupDir)
+	}
+
+	height := common.JSONUint64(t.consensus.GetLastFinalizedBlock().Height)
+	t.dbBackup.status = GetDBBackupStatusResult{
+		BackupDir: args.BackupDir,
+		Running:   true,
+		Height:    height,
+		StartedAt: time.Now().UTC(),
+	}
+
+	go func() {
+		manifest, err := db.Backup(args.BackupDir, args.BaseDir)
+
+		t.dbBackup.mu.Lock()
+		defer t.dbBackup.mu.Unlock()
+		t.dbBackup.status.Running = false
+		t.dbBackup.status.FinishedAt = time.Now().UTC()
+		t.dbBackup.status.Manifest = manifest
+		if err != nil {
+			logger.Errorf("Failed to back up the database to %v: %v", args.BackupDir, err)
+			t.dbBackup.status.Error = err.Error()
+		}
+	}()
+
+	result.BackupDir = args.BackupDir
+	result.Height = height
+	return nil
+}
+
+// ------------------------------- GetDBBackupStatus -----------------------------------
+
+type GetDBBackupStatusArgs struct{}
+
+type GetDBBackupStatusResult struct {
+	BackupDir  string                  `json:"backup_dir"`
+	Running    bool                    `json:"running"`
+	Height     common.JSONUint64       `json:"height"` // Last finalized height when the backup started
+	StartedAt  time.Time               `json:"started_at"`
+	FinishedAt time.Time               `json:"finished_at"`
+	Error      string                  `json:"error"`
+	Manifest   *backend.BackupManifest `json:"manifest"`
+}
+
+func (t *ThetaRPCService) GetDBBackupStatus(args *GetDBBackupStatusArgs, result *GetDBBackupStatusResult) (err error) {
+	defer t.guard("GetDBBackupStatus", &err)()
+
+	t.dbBackup.mu.Lock()
+	defer t.dbBackup.mu.Unlock()
+	*result = t.dbBackup.status
+	return nil
+}
@@ -0,0 +1,212 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+// maxBatchPayouts caps the number of payouts one ComposeBatchSendTx call accepts
+const maxBatchPayouts = 50000
+
+// maxOutputsPerSendTx is the max number of outputs of a SendTx with a single input
+const maxOutputsPerSendTx = types.MaxAccountsAffectedPerTx - 1
+
+// ------------------------------ ComposeBatchSendTx -----------------------------------
+
+type BatchPayout struct {
+	Address string `json:"address"`
+	Theta   string `json:"theta"` // Optional, e.g. "10" or "10000000000000000000wei"
+	TFuel   string `json:"tfuel"` // Optional, e.g. "10" or "10000000000000000000wei"
+}
+
+type ComposeBatchSendTxArgs struct {
+	Source          string            `json:"source"`             // Address paying out
+	Payouts         []BatchPayout     `json:"payouts"`            // Recipients, each can appear only once
+	MaxOutputsPerTx int               `json:"max_outputs_per_tx"` // Optional, default to the max allowed per transaction
+	Split           bool              `json:"split"`              // Split the payouts into multiple transactions if they do not fit in one
+	Sequence        common.JSONUint64 `json:"sequence"`           // Optional, default to the next sequence of the source account
+}
+
+type BatchSendTx struct {
+	TxBytes    string            `json:"tx_bytes"`   // The unsigned transaction
+	SignBytes  string            `json:"sign_bytes"` // The bytes the source needs to sign
+	Sequence   common.JSONUint64 `json:"sequence"`
+	NumOutputs int               `json:"num_outputs"`
+	Size       int               `json:"size"` // Estimated size of the signed transaction in bytes
+	Fee        *common.JSONBig   `json:"fee"`  // TFuelWei
+}
+
+type ComposeBatchSendTxResult struct {
+	Txs        []BatchSendTx   `json:"txs"` // To be signed and broadcasted in the order of their sequences
+	TotalTheta *common.JSONBig `json:"total_theta"`
+	TotalTFuel *common.JSONBig `json:"total_tfuel"`
+	TotalFee   *common.JSONBig `json:"total_fee"`
+}
+
+func (t *ThetaRPCService) ComposeBatchSendTx(args *ComposeBatchSendTxArgs, result *ComposeBatchSendTxResult) (err error) {
+	defer t.guard("ComposeBatchSendTx", &err)()
+
+	ledgerState, err := t.ledger.GetFinalizedSnapshot()
+	if err != nil {
+		return err
+	}
+	blockHeight := ledgerState.Height() + 1
+
+	account, sequence, err := getStakeSourceAccount(ledgerState, args.Source, uint64(args.Sequence))
+	if err != nil {
+		return err
+	}
+
+	outputs, err := ParseBatchPayouts(account.Address, args.Payouts)
+	if err != nil {
+		return err
+	}
+
+	maxOutputs := maxOutputsPerSendTx
+	if args.MaxOutputsPerTx > 0 && args.MaxOutputsPerTx < maxOutputs {
+		maxOutputs = args.MaxOutputsPerTx
+	}
+	batches := splitBatchPayouts(outputs, maxOutputs)
+	if len(batches) > 1 && !args.Split {
+		return fmt.Errorf("The %v payouts need %v transactions of at most %v outputs each, enable split to compose all of them",
+			len(outputs), len(batches), maxOutputs)
+	}
+
+	chainID := t.consensus.Chain().ChainID
+	total := types.NewCoins(0, 0)
+	totalFee := big.NewInt(0)
+	result.Txs = []BatchSendTx{}
+	for i, batch := range batches {
+		fee := types.GetSendTxMinimumTransactionFeeTFuelWei(uint64(len(batch)+1), chainID, blockHeight)
+		feeCoins := types.Coins{ThetaWei: big.NewInt(0), TFuelWei: fee}
+		amount := types.NewCoins(0, 0)
+		for _, output := range batch {
+			amount = amount.Plus(output.Coins)
+		}
+
+		tx := &types.SendTx{
+			Fee: feeCoins,
+			Inputs: []types.TxInput{{
+				Address:  account.Address,
+				Coins:    amount.Plus(feeCoins),
+				Sequence: sequence + uint64(i),
+			}},
+			Outputs: batch,
+		}
+
+		size, err := estimateSignedSendTxSize(tx)
+		if err != nil {
+			return err
+		}
+		if size > types.MaxTxSize {
+			return fmt.Errorf("Transaction %v is %v bytes, which exceeds the max tx size %v, lower max_outputs_per_tx", i, size, types.MaxTxSize)
+		}
+
+		txBytes, signBytes, err := encodeUnsignedTx(tx, tx.SignBytes(chainID))
+		if err != nil {
+			return err
+		}
+		result.Txs = append(result.Txs, BatchSendTx{
+			TxBytes:    txBytes,
+			SignBytes:  signBytes,
+			Sequence:   common.JSONUint64(sequence + uint64(i)),
+			NumOutputs: len(batch),
+			Size:       size,
+			Fee:        (*common.JSONBig)(fee),
+		})
+
+		total = total.Plus(amount)
+		totalFee.Add(totalFee, fee)
+	}
+
+	required := total.Plus(types.Coins{ThetaWei: big.NewInt(0), TFuelWei: totalFee})
+	if !account.Balance.IsGTE(required) {
+		return newInsufficientFundError("Source balance is %v, but the payouts and fees require %v", account.Balance, required)
+	}
+
+	result.TotalTheta = (*common.JSONBig)(total.ThetaWei)
+	result.TotalTFuel = (*common.JSONBig)(total.TFuelWei)
+	result.TotalFee = (*common.JSONBig)(totalFee)
+	return nil
+}
+
+// ParseBatchPayouts converts the payouts into the tx outputs, in the given order
+func ParseBatchPayouts(source common.Address, payouts []BatchPayout) ([]types.TxOutput, error) {
+	if len(payouts) == 0 {
+		return nil, errors.New("No payouts specified")
+	}
+	if len(payouts) > maxBatchPayouts {
+		return nil, fmt.Errorf("Too many payouts: %v, at most %v payouts are allowed", len(payouts), maxBatchPayouts)
+	}
+
+	outputs := make([]types.TxOutput, 0, len(payouts))
+	seen := make(map[common.Address]bool)
+	for i, payout := range payouts {
+		if !common.IsHexAddress(payout.Address) {
+			return nil, fmt.Errorf("Payout %v: invalid address %v", i, payout.Address)
+		}
+		address := common.HexToAddress(payout.Address)
+		if address == source {
+			return nil, fmt.Errorf("Payout %v: cannot pay out to the source address", i)
+		}
+		if seen[address] {
+			return nil, fmt.Errorf("Payout %v: duplicated address %v", i, address.Hex())
+		}
+		seen[address] = true
+
+		coins := types.NewCoins(0, 0)
+		if payout.Theta != "" {
+			theta, ok := types.ParseCoinAmount(payout.Theta)
+			if !ok || theta.Sign() < 0 {
+				return nil, fmt.Errorf("Payout %v: failed to parse theta amount %v", i, payout.Theta)
+			}
+			coins.ThetaWei = theta
+		}
+		if payout.TFuel != "" {
+			tfuel, ok := types.ParseCoinAmount(payout.TFuel)
+			if !ok || tfuel.Sign() < 0 {
+				return nil, fmt.Errorf("Payout %v: failed to parse tfuel amount %v", i, payout.TFuel)
+			}
+			coins.TFuelWei = tfuel
+		}
+		if coins.IsZero() {
+			return nil, fmt.Errorf("Payout %v: amount to %v is zero", i, address.Hex())
+		}
+
+		outputs = append(outputs, types.TxOutput{Address: address, Coins: coins})
+	}
+	return outputs, nil
+}
+
+// splitBatchPayouts splits the outputs into batches of at most maxOutputs outputs each
+func splitBatchPayouts(outputs []types.TxOutput, maxOutputs int) [][]types.TxOutput {
+	batches := [][]types.TxOutput{}
+	for start := 0; start < len(outputs); start += maxOutputs {
+		end := start + maxOutputs
+		if end > len(outputs) {
+			end = len(outputs)
+		}
+		batches = append(batches, outputs[start:end])
+	}
+	return batches
+}
+
+// estimateSignedSendTxSize returns the size of the encoded tx once the inputs are signed
+func estimateSignedSendTxSize(tx *types.SendTx) (int, error) {
+	signed := *tx
+	signed.Inputs = make([]types.TxInput, len(tx.Inputs))
+	for i, input := range tx.Inputs {
+		input.Signature, _ = crypto.SignatureFromBytes(make([]byte, 65))
+		signed.Inputs[i] = input
+	}
+	raw, err := types.TxToBytes(&signed)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to encode transaction: %v", err)
+	}
+	return len(raw), nil
+}
@@ -0,0 +1,69 @@
+package rpc
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+func TestParseBatchPayouts(t *testing.T) {
+	assert := assert.New(t)
+
+	source := common.HexToAddress("0x2E833968E5bB786Ae419c4d13189fB081Cc43bab")
+	payouts := []BatchPayout{
+		{Address: "0x9F1233798E905E173560071255140b4A8aBd3Ec6", TFuel: "10"},
+		{Address: "0x4A8aBd3Ec69F1233798E905E173560071255140b", Theta: "1wei", TFuel: "2wei"},
+	}
+	outputs, err := ParseBatchPayouts(source, payouts)
+	assert.Nil(err)
+	assert.Equal(2, len(outputs))
+	assert.Equal(common.HexToAddress(payouts[1].Address), outputs[1].Address)
+	assert.Equal(0, big.NewInt(1).Cmp(outputs[1].Coins.ThetaWei))
+	assert.Equal(0, big.NewInt(2).Cmp(outputs[1].Coins.TFuelWei))
+
+	_, err = ParseBatchPayouts(source, nil)
+	assert.NotNil(err)
+	_, err = ParseBatchPayouts(source, []BatchPayout{{Address: "0x9F1233798E905E17", TFuel: "1"}})
+	assert.NotNil(err)
+	_, err = ParseBatchPayouts(source, []BatchPayout{{Address: source.Hex(), TFuel: "1"}})
+	assert.NotNil(err)
+	_, err = ParseBatchPayouts(source, []BatchPayout{payouts[0], payouts[0]})
+	assert.NotNil(err)
+	_, err = ParseBatchPayouts(source, []BatchPayout{{Address: payouts[0].Address}})
+	assert.NotNil(err)
+	_, err = ParseBatchPayouts(source, []BatchPayout{{Address: payouts[0].Address, TFuel: "-1"}})
+	assert.NotNil(err)
+}
+
+func TestSplitBatchPayouts(t *testing.T) {
+	assert := assert.New(t)
+
+	outputs := []types.TxOutput{}
+	for i := 0; i < 2*maxOutputsPerSendTx+3; i++ {
+		outputs = append(outputs, types.TxOutput{
+			Address: common.HexToAddress(fmt.Sprintf("%040x", i+1)),
+			Coins:   types.NewCoins(0, 1),
+		})
+	}
+
+	batches := splitBatchPayouts(outputs, maxOutputsPerSendTx)
+	assert.Equal(3, len(batches))
+	assert.Equal(maxOutputsPerSendTx, len(batches[0]))
+	assert.Equal(3, len(batches[2]))
+	assert.Equal(outputs[maxOutputsPerSendTx].Address, batches[1][0].Address)
+
+	// The largest batch fits in a transaction
+	tx := &types.SendTx{
+		Fee:     types.NewCoins(0, 1),
+		Inputs:  []types.TxInput{{Address: common.HexToAddress("0x01"), Coins: types.NewCoins(0, int64(maxOutputsPerSendTx+1))}},
+		Outputs: batches[0],
+	}
+	size, err := estimateSignedSendTxSize(tx)
+	assert.Nil(err)
+	assert.True(size < types.MaxTxSize)
+	assert.Nil(tx.Inputs[0].Signature)
+}
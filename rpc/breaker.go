@@ -0,0 +1,183 @@
+package rpc
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thetatoken/theta/common/metrics"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+)
+
+// errCodeInternal is the JSON-RPC 2.0 error code for the internal errors
+const errCodeInternal = -32603
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker tracks the failed calls of an RPC method in the current window, and opens when the
+// failure ratio exceeds the threshold. An open breaker rejects the calls until the cooldown has passed,
+// then lets a single trial call through, which closes the breaker if it succeeds.
+type circuitBreaker struct {
+	state       breakerState
+	windowStart time.Time
+	calls       int
+	failures    int
+	openedAt    time.Time
+}
+
+// CircuitBreakers holds the circuit breakers of the RPC methods
+type CircuitBreakers struct {
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+
+	failureRatio float64
+	minCalls     int
+	window       time.Duration
+	cooldown     time.Duration
+	slowCall     time.Duration
+
+	shed metrics.Counter
+}
+
+// NewCircuitBreakers creates a new instance of CircuitBreakers. The calls running longer than slowCall
+// count as failures along with the panics.
+func NewCircuitBreakers(failureRatio float64, minCalls int, window, cooldown, slowCall time.Duration, chainID string) *CircuitBreakers {
+	return &CircuitBreakers{
+		breakers:     make(map[string]*circuitBreaker),
+		failureRatio: failureRatio,
+		minCalls:     minCalls,
+		window:       window,
+		cooldown:     cooldown,
+		slowCall:     slowCall,
+		shed:         metrics.GetOrRegisterCounter(rpcMetricName(chainID, "breaker/shed"), nil),
+	}
+}
+
+func (cbs *CircuitBreakers) enabled() bool {
+	return cbs != nil && cbs.failureRatio > 0
+}
+
+// allow returns whether the call of the given method can go through. A nil CircuitBreakers allows all
+// the calls.
+func (cbs *CircuitBreakers) allow(method string, now time.Time) bool {
+	if !cbs.enabled() {
+		return true
+	}
+
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+
+	cb, ok := cbs.breakers[method]
+	if !ok {
+		return true
+	}
+	switch cb.state {
+	case breakerOpen:
+		if now.Sub(cb.openedAt) < cbs.cooldown {
+			cbs.shed.Inc(1)
+			return false
+		}
+		cb.state = breakerHalfOpen
+		cb.openedAt = now
+		return true
+	case breakerHalfOpen:
+		// Only the trial call goes through until it completes, unless the trial call never ran,
+		// e.g. it timed out in the queue
+		if now.Sub(cb.openedAt) < cbs.cooldown {
+			cbs.shed.Inc(1)
+			return false
+		}
+		cb.openedAt = now
+		return true
+	default:
+		return true
+	}
+}
+
+// record records the outcome of a call of the given method
+func (cbs *CircuitBreakers) record(method string, failed bool, now time.Time) {
+	if !cbs.enabled() {
+		return
+	}
+
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+
+	cb, ok := cbs.breakers[method]
+	if !ok {
+		cb = &circuitBreaker{windowStart: now}
+		cbs.breakers[method] = cb
+	}
+
+	switch cb.state {
+	case breakerHalfOpen:
+		if failed {
+			cb.state = breakerOpen
+			cb.openedAt = now
+			return
+		}
+		cb.state = breakerClosed
+		cb.windowStart, cb.calls, cb.failures = now, 0, 0
+		return
+	case breakerOpen:
+		return // calls started before the breaker opened
+	}
+
+	if now.Sub(cb.windowStart) > cbs.window {
+		cb.windowStart, cb.calls, cb.failures = now, 0, 0
+	}
+	cb.calls++
+	if failed {
+		cb.failures++
+	}
+	if cb.calls >= cbs.minCalls && float64(cb.failures) > cbs.failureRatio*float64(cb.calls) {
+		logger.Warnf("Circuit breaker of RPC method %v opened, %v of %v calls failed", method, cb.failures, cb.calls)
+		cb.state = breakerOpen
+		cb.openedAt = now
+	}
+}
+
+// openMethod returns the first of the given methods whose circuit breaker rejects the call
+func (cbs *CircuitBreakers) openMethod(methods []string) (string, bool) {
+	now := time.Now()
+	for _, method := range methods {
+		if !cbs.allow(strings.TrimPrefix(method, "theta."), now) {
+			return method, true
+		}
+	}
+	return "", false
+}
+
+// guard recovers from the panics of an RPC method, turning them into internal errors, and records the
+// outcome of the call for the circuit breaker of the method. It needs to be deferred at the beginning
+// of the method:
+//
+//	defer t.guard("GetAccount", &err)()
+func (t *ThetaRPCService) guard(method string, err *error) func() {
+	start := time.Now()
+	return func() {
+		failed := false
+		if p := recover(); p != nil {
+			failed = true
+			logger.Errorf("RPC method %v panicked: %v\n%s", method, p, debug.Stack())
+			metrics.GetOrRegisterCounter(rpcMetricName(t.getChainID(), "panics"), nil).Inc(1)
+			rpcErr := jsonrpc2.NewError(errCodeInternal, "internal error")
+			rpcErr.Data = fmt.Sprintf("%v failed", method)
+			*err = rpcErr
+		}
+
+		now := time.Now()
+		if t.breakers != nil && t.breakers.slowCall > 0 && now.Sub(start) > t.breakers.slowCall {
+			failed = true
+		}
+		t.breakers.record(method, failed, now)
+	}
+}
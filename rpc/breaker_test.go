@@ -0,0 +1,77 @@
+package rpc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common/util"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+)
+
+func TestCircuitBreakers(t *testing.T) {
+	assert := assert.New(t)
+
+	logger = util.GetLoggerForModule("rpc")
+
+	cbs := NewCircuitBreakers(0.5, 4, time.Minute, 10*time.Second, 0, "")
+	now := time.Now()
+
+	// The breaker stays closed until the min number of calls is reached
+	for i := 0; i < 3; i++ {
+		assert.True(cbs.allow("GetBlock", now))
+		cbs.record("GetBlock", true, now)
+	}
+	assert.True(cbs.allow("GetBlock", now))
+	cbs.record("GetBlock", true, now)
+
+	// Opened, the other methods are not affected
+	assert.False(cbs.allow("GetBlock", now))
+	assert.True(cbs.allow("GetAccount", now))
+	method, open := cbs.openMethod([]string{"theta.GetAccount", "theta.GetBlock"})
+	assert.True(open)
+	assert.Equal("theta.GetBlock", method)
+
+	// A single trial call after the cooldown, which reopens the breaker if it fails
+	now = now.Add(10 * time.Second)
+	assert.True(cbs.allow("GetBlock", now))
+	assert.False(cbs.allow("GetBlock", now))
+	cbs.record("GetBlock", true, now)
+	assert.False(cbs.allow("GetBlock", now))
+
+	// A successful trial call closes the breaker
+	now = now.Add(10 * time.Second)
+	assert.True(cbs.allow("GetBlock", now))
+	cbs.record("GetBlock", false, now)
+	assert.True(cbs.allow("GetBlock", now))
+	assert.True(cbs.allow("GetBlock", now))
+
+	// Disabled breakers allow all the calls
+	var disabled *CircuitBreakers
+	disabled.record("GetBlock", true, now)
+	assert.True(disabled.allow("GetBlock", now))
+}
+
+func TestGuardRecoversPanics(t *testing.T) {
+	assert := assert.New(t)
+
+	logger = util.GetLoggerForModule("rpc")
+
+	service := &ThetaRPCService{breakers: NewCircuitBreakers(0.4, 1, time.Minute, time.Minute, 0, "")}
+	call := func(f func()) (err error) {
+		defer service.guard("GetBlock", &err)()
+		f()
+		return errors.New("not found")
+	}
+
+	err := call(func() {})
+	assert.Equal("not found", err.Error())
+	assert.True(service.breakers.allow("GetBlock", time.Now()))
+
+	err = call(func() { panic("corrupted entry") })
+	rpcErr, ok := err.(*jsonrpc2.Error)
+	assert.True(ok)
+	assert.Equal(errCodeInternal, rpcErr.Code)
+	assert.False(service.breakers.allow("GetBlock", time.Now()))
+}
@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"encoding/json"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/thetatoken/theta/common/metrics"
+)
+
+// ResultCache caches the RPC results keyed by the method and the parameters. Only the results which
+// are immutable once finalized (blocks, transactions, receipts, historical accounts) are cached, and they
+// are kept until evicted. The cache is not invalidated when the state or the indices are pruned, so the
+// pruned results may still be served from the cache.
+type ResultCache struct {
+	cache *lru.Cache
+
+	hits   metrics.Counter
+	misses metrics.Counter
+	size   metrics.Gauge
+}
+
+// NewResultCache creates a new instance of ResultCache
+func NewResultCache(size int, chainID string) (*ResultCache, error) {
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultCache{
+		cache:  cache,
+		hits:   metrics.GetOrRegisterCounter(rpcMetricName(chainID, "cache/hits"), nil),
+		misses: metrics.GetOrRegisterCounter(rpcMetricName(chainID, "cache/misses"), nil),
+		size:   metrics.GetOrRegisterGauge(rpcMetricName(chainID, "cache/size"), nil),
+	}, nil
+}
+
+func getCacheKey(method string, args interface{}) (string, bool) {
+	params, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	return method + ":" + string(params), true
+}
+
+// get returns the cached result of the call. A nil cache never hits.
+func (c *ResultCache) get(method string, args interface{}) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	key, ok := getCacheKey(method, args)
+	if !ok {
+		return nil, false
+	}
+	value, ok := c.cache.Get(key)
+	if !ok {
+		c.misses.Inc(1)
+		return nil, false
+	}
+	c.hits.Inc(1)
+	return value, true
+}
+
+// add caches the final result of the call, which is kept until evicted. The results that may still
+// change, e.g. the latest account state or a pending transaction, must not be cached.
+func (c *ResultCache) add(method string, args interface{}, result interface{}) {
+	if c == nil {
+		return
+	}
+	key, ok := getCacheKey(method, args)
+	if !ok {
+		return
+	}
+	c.cache.Add(key, result)
+	c.size.Update(int64(c.cache.Len()))
+}
@@ -0,0 +1,66 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+)
+
+func TestResultCache(t *testing.T) {
+	assert := assert.New(t)
+
+	cache, err := NewResultCache(2, "")
+	assert.Nil(err)
+
+	args1 := &GetBlockByHeightArgs{Height: common.JSONUint64(1)}
+	args2 := &GetBlockByHeightArgs{Height: common.JSONUint64(2)}
+	args3 := &GetBlockByHeightArgs{Height: common.JSONUint64(3)}
+
+	_, ok := cache.get("GetBlockByHeight", args1)
+	assert.False(ok)
+
+	// The results are keyed by both the method and the parameters
+	cache.add("GetBlockByHeight", args1, "block1")
+	cached, ok := cache.get("GetBlockByHeight", args1)
+	assert.True(ok)
+	assert.Equal("block1", cached)
+	_, ok = cache.get("GetBlockByHeight", args2)
+	assert.False(ok)
+	_, ok = cache.get("GetAccount", args1)
+	assert.False(ok)
+
+	// The least recently used results are evicted
+	cache.add("GetBlockByHeight", args2, "block2")
+	cache.add("GetBlockByHeight", args3, "block3")
+	_, ok = cache.get("GetBlockByHeight", args1)
+	assert.False(ok)
+
+	// A nil cache is disabled
+	var disabled *ResultCache
+	disabled.add("GetBlockByHeight", args1, "block1")
+	_, ok = disabled.get("GetBlockByHeight", args1)
+	assert.False(ok)
+}
+
+func TestCachedBlockWithChildren(t *testing.T) {
+	assert := assert.New(t)
+
+	child1 := common.BytesToHash([]byte("child1"))
+	child2 := common.BytesToHash([]byte("child2"))
+	cached := GetBlockResult{GetBlockResultInner: &GetBlockResultInner{
+		Height:   common.JSONUint64(1),
+		Children: []common.Hash{child1},
+		Status:   core.BlockStatusDirectlyFinalized,
+	}}
+
+	// The children and the status are read from the chain, without modifying the cached result
+	block := &core.ExtendedBlock{Children: []common.Hash{child1, child2}, Status: core.BlockStatusIndirectlyFinalized}
+	result := cached.withChildren(block)
+	assert.Equal(common.JSONUint64(1), result.Height)
+	assert.Equal([]common.Hash{child1, child2}, result.Children)
+	assert.Equal(core.BlockStatusIndirectlyFinalized, result.Status)
+	assert.Equal([]common.Hash{child1}, cached.Children)
+	assert.Equal(core.BlockStatusDirectlyFinalized, cached.Status)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/ledger/types"
 	"github.com/thetatoken/theta/ledger/vm"
@@ -27,6 +28,8 @@ type CallSmartContractResult struct {
 // the globally consensus state. It can be used for dry run, or for retrieving info from smart contracts
 // without actually spending gas.
 func (t *ThetaRPCService) CallSmartContract(args *CallSmartContractArgs, result *CallSmartContractResult) (err error) {
+	defer t.guard("CallSmartContract", &err)()
+
 	var ledgerState *state.StoreView
 	ledgerState, err = t.ledger.GetDeliveredSnapshot()
 	if err != nil {
@@ -34,8 +37,8 @@ func (t *ThetaRPCService) CallSmartContract(args *CallSmartContractArgs, result
 	}
 
 	blockHeight := ledgerState.Height() + 1 // the view points to the parent of the current block
-	if blockHeight < common.HeightEnableSmartContract {
-		return fmt.Errorf("Smart contract feature not enabled until block height %v.", common.HeightEnableSmartContract)
+	if !core.IsForkActive(t.chain.ChainID, core.ForkSmartContract, blockHeight) {
+		return fmt.Errorf("Smart contract feature not enabled until block height %v.", core.ForkHeight(t.chain.ChainID, core.ForkSmartContract))
 	}
 
 	sctxBytes, err := hex.DecodeString(args.SctxBytes)
@@ -0,0 +1,832 @@
+package client
+
+import (
+	"context"
+
+	"github.com/thetatoken/theta/rpc"
+)
+
+// API lists the methods of the ThetaRPCService. The services depending on the node can accept an API to mock
+// it in their tests.
+type API interface {
+	BackupSnapshot(ctx context.Context, args *rpc.BackupSnapshotArgs) (*rpc.BackupSnapshotResult, error)
+	VerifySnapshot(ctx context.Context, args *rpc.VerifySnapshotArgs) (*rpc.VerifySnapshotResult, error)
+	GetSnapshotSchedule(ctx context.Context, args *rpc.GetSnapshotScheduleArgs) (*rpc.GetSnapshotScheduleResult, error)
+	UpdateSnapshotSchedule(ctx context.Context, args *rpc.UpdateSnapshotScheduleArgs) (*rpc.UpdateSnapshotScheduleResult, error)
+	BackupChain(ctx context.Context, args *rpc.BackupChainArgs) (*rpc.BackupChainResult, error)
+	BackupChainCorrection(ctx context.Context, args *rpc.BackupChainCorrectionArgs) (*rpc.BackupChainCorrectionResult, error)
+	BackupDB(ctx context.Context, args *rpc.BackupDBArgs) (*rpc.BackupDBResult, error)
+	GetDBBackupStatus(ctx context.Context, args *rpc.GetDBBackupStatusArgs) (*rpc.GetDBBackupStatusResult, error)
+	ComposeBatchSendTx(ctx context.Context, args *rpc.ComposeBatchSendTxArgs) (*rpc.ComposeBatchSendTxResult, error)
+	CallSmartContract(ctx context.Context, args *rpc.CallSmartContractArgs) (*rpc.CallSmartContractResult, error)
+	GetCrossChainChannel(ctx context.Context, args *rpc.GetCrossChainChannelArgs) (*rpc.GetCrossChainChannelResult, error)
+	GetCrossChainMessages(ctx context.Context, args *rpc.GetCrossChainMessagesArgs) (*rpc.GetCrossChainMessagesResult, error)
+	GetReceivedCrossChainMessages(ctx context.Context, args *rpc.GetReceivedCrossChainMessagesArgs) (*rpc.GetReceivedCrossChainMessagesResult, error)
+	GetEliteEdgeNodeVoteDiagnostics(ctx context.Context, args *rpc.GetEliteEdgeNodeVoteDiagnosticsArgs) (*rpc.GetEliteEdgeNodeVoteDiagnosticsResult, error)
+	GetSelfValidatorReport(ctx context.Context, args *rpc.GetSelfValidatorReportArgs) (*rpc.GetSelfValidatorReportResult, error)
+	GetGuardianVoteInclusion(ctx context.Context, args *rpc.GetGuardianVoteInclusionArgs) (*rpc.GetGuardianVoteInclusionResult, error)
+	GetFinalityProof(ctx context.Context, args *rpc.GetFinalityProofArgs) (*rpc.GetFinalityProofResult, error)
+	GetVersion(ctx context.Context, args *rpc.GetVersionArgs) (*rpc.GetVersionResult, error)
+	GetAccount(ctx context.Context, args *rpc.GetAccountArgs) (*rpc.GetAccountResult, error)
+	GetSplitRule(ctx context.Context, args *rpc.GetSplitRuleArgs) (*rpc.GetSplitRuleResult, error)
+	GetTransaction(ctx context.Context, args *rpc.GetTransactionArgs) (*rpc.GetTransactionResult, error)
+	GetTransactionByBlockHashAndIndex(ctx context.Context, args *rpc.GetTransactionByBlockHashAndIndexArgs) (*rpc.GetTransactionResult, error)
+	GetPendingTransactions(ctx context.Context, args *rpc.GetPendingTransactionsArgs) (*rpc.GetPendingTransactionsResult, error)
+	GetPendingTransactionsByAddress(ctx context.Context, args *rpc.GetPendingTransactionsByAddressArgs) (*rpc.GetPendingTransactionsByAddressResult, error)
+	GetBlock(ctx context.Context, args *rpc.GetBlockArgs) (*rpc.GetBlockResult, error)
+	GetBlockByHeight(ctx context.Context, args *rpc.GetBlockByHeightArgs) (*rpc.GetBlockResult, error)
+	GetBlockByTimestamp(ctx context.Context, args *rpc.GetBlockByTimestampArgs) (*rpc.GetBlockResult, error)
+	GetBlocksByRange(ctx context.Context, args *rpc.GetBlocksByRangeArgs) (*rpc.GetBlocksResult, error)
+	GetChainStats(ctx context.Context, args *rpc.GetChainStatsArgs) (*rpc.GetChainStatsResult, error)
+	GetContractStats(ctx context.Context, args *rpc.GetContractStatsArgs) (*rpc.GetContractStatsResult, error)
+	GetSupplyDelta(ctx context.Context, args *rpc.GetSupplyDeltaArgs) (*rpc.GetSupplyDeltaResult, error)
+	GetTxTimings(ctx context.Context, args *rpc.GetTxTimingsArgs) (*rpc.GetTxTimingsResult, error)
+	GetContractStorage(ctx context.Context, args *rpc.GetContractStorageArgs) (*rpc.GetContractStorageResult, error)
+	VerifyContract(ctx context.Context, args *rpc.VerifyContractArgs) (*rpc.VerifyContractResult, error)
+	GetVerifiedContract(ctx context.Context, args *rpc.GetVerifiedContractArgs) (*rpc.GetVerifiedContractResult, error)
+	GetNFTsByOwner(ctx context.Context, args *rpc.GetNFTsByOwnerArgs) (*rpc.GetNFTsByOwnerResult, error)
+	GetNFTHistory(ctx context.Context, args *rpc.GetNFTHistoryArgs) (*rpc.GetNFTHistoryResult, error)
+	GetLogs(ctx context.Context, args *rpc.GetLogsArgs) (*rpc.GetLogsResult, error)
+	NewFilter(ctx context.Context, args *rpc.NewFilterArgs) (*rpc.NewFilterResult, error)
+	NewBlockFilter(ctx context.Context, args *rpc.NewBlockFilterArgs) (*rpc.NewBlockFilterResult, error)
+	NewPendingTransactionFilter(ctx context.Context, args *rpc.NewPendingTransactionFilterArgs) (*rpc.NewPendingTransactionFilterResult, error)
+	GetFilterChanges(ctx context.Context, args *rpc.GetFilterChangesArgs) (*rpc.GetFilterChangesResult, error)
+	GetFilterLogs(ctx context.Context, args *rpc.GetFilterLogsArgs) (*rpc.GetFilterLogsResult, error)
+	UninstallFilter(ctx context.Context, args *rpc.UninstallFilterArgs) (*rpc.UninstallFilterResult, error)
+	GetSlashHistory(ctx context.Context, args *rpc.GetSlashHistoryArgs) (*rpc.GetSlashHistoryResult, error)
+	GetBalanceHistory(ctx context.Context, args *rpc.GetBalanceHistoryArgs) (*rpc.GetBalanceHistoryResult, error)
+	SearchTransactions(ctx context.Context, args *rpc.SearchTransactionsArgs) (*rpc.SearchTransactionsResult, error)
+	GetRewardDistribution(ctx context.Context, args *rpc.GetRewardDistributionArgs) (*rpc.GetRewardDistributionResult, error)
+	GetRetentionBoundaries(ctx context.Context, args *rpc.GetRetentionBoundariesArgs) (*rpc.GetRetentionBoundariesResult, error)
+	GetStatus(ctx context.Context, args *rpc.GetStatusArgs) (*rpc.GetStatusResult, error)
+	GetPeerURLs(ctx context.Context, args *rpc.GetPeersArgs) (*rpc.GetPeerURLsResult, error)
+	GetPeers(ctx context.Context, args *rpc.GetPeersArgs) (*rpc.GetPeersResult, error)
+	GetPeerCapabilities(ctx context.Context, args *rpc.GetPeerCapabilitiesArgs) (*rpc.GetPeerCapabilitiesResult, error)
+	GetGovernanceParams(ctx context.Context, args *rpc.GetGovernanceParamsArgs) (*rpc.GetGovernanceParamsResult, error)
+	GetValidatorKeyChanges(ctx context.Context, args *rpc.GetValidatorKeyChangesArgs) (*rpc.GetValidatorKeyChangesResult, error)
+	GetStakeAutoCompounding(ctx context.Context, args *rpc.GetStakeAutoCompoundingArgs) (*rpc.GetStakeAutoCompoundingResult, error)
+	GetRandomness(ctx context.Context, args *rpc.GetRandomnessArgs) (*rpc.GetRandomnessResult, error)
+	GetBaseFee(ctx context.Context, args *rpc.GetBaseFeeArgs) (*rpc.GetBaseFeeResult, error)
+	GetStateDiff(ctx context.Context, args *rpc.GetStateDiffArgs) (*rpc.GetStateDiffResult, error)
+	GetPendingUpgrade(ctx context.Context, args *rpc.GetPendingUpgradeArgs) (*rpc.GetPendingUpgradeResult, error)
+	GetForkConfig(ctx context.Context, args *rpc.GetForkConfigArgs) (*rpc.GetForkConfigResult, error)
+	GetShadowReport(ctx context.Context, args *rpc.GetShadowReportArgs) (*rpc.GetShadowReportResult, error)
+	GetProposalSnapshot(ctx context.Context, args *rpc.GetProposalSnapshotArgs) (*rpc.GetProposalSnapshotResult, error)
+	GetVcpByHeight(ctx context.Context, args *rpc.GetVcpByHeightArgs) (*rpc.GetVcpResult, error)
+	GetGcpByHeight(ctx context.Context, args *rpc.GetGcpByHeightArgs) (*rpc.GetGcpResult, error)
+	GetStakeComposition(ctx context.Context, args *rpc.GetStakeCompositionArgs) (*rpc.GetStakeCompositionResult, error)
+	ProbeNetworkTopology(ctx context.Context, args *rpc.ProbeNetworkTopologyArgs) (*rpc.ProbeNetworkTopologyResult, error)
+	GetGuardianInfo(ctx context.Context, args *rpc.GetGuardianInfoArgs) (*rpc.GetGuardianInfoResult, error)
+	GetEenpByHeight(ctx context.Context, args *rpc.GetEenpByHeightArgs) (*rpc.GetEenpResult, error)
+	GetStakeRewardDistributionByHeight(ctx context.Context, args *rpc.GetStakeRewardDistributionRuleSetByHeightArgs) (*rpc.GetStakeRewardDistributionRuleSetResult, error)
+	GetEliteEdgeNodeStakeReturnsByHeight(ctx context.Context, args *rpc.GetEliteEdgeNodeStakeReturnsByHeightArgs) (*rpc.GetEliteEdgeNodeStakeReturnsByHeightResult, error)
+	GetAllPendingEliteEdgeNodeStakeReturns(ctx context.Context, args *rpc.GetAllPendingEliteEdgeNodeStakeReturnsArgs) (*rpc.GetAllPendingEliteEdgeNodeStakeReturnsResult, error)
+	ComposeDepositStakeTx(ctx context.Context, args *rpc.ComposeDepositStakeTxArgs) (*rpc.ComposeDepositStakeTxResult, error)
+	ComposeWithdrawStakeTx(ctx context.Context, args *rpc.ComposeWithdrawStakeTxArgs) (*rpc.ComposeWithdrawStakeTxResult, error)
+	GetStakingParams(ctx context.Context, args *rpc.GetStakingParamsArgs) (*rpc.GetStakingParamsResult, error)
+	GetStakeSummary(ctx context.Context, args *rpc.GetStakeSummaryArgs) (*rpc.GetStakeSummaryResult, error)
+	SimulateStakeWithdrawal(ctx context.Context, args *rpc.SimulateStakeWithdrawalArgs) (*rpc.SimulateStakeWithdrawalResult, error)
+	GetSubchain(ctx context.Context, args *rpc.GetSubchainArgs) (*rpc.GetSubchainResult, error)
+	GetSubchainCheckpoint(ctx context.Context, args *rpc.GetSubchainCheckpointArgs) (*rpc.GetSubchainCheckpointResult, error)
+	BroadcastRawTransaction(ctx context.Context, args *rpc.BroadcastRawTransactionArgs) (*rpc.BroadcastRawTransactionResult, error)
+	BroadcastRawTransactionAsync(ctx context.Context, args *rpc.BroadcastRawTransactionAsyncArgs) (*rpc.BroadcastRawTransactionAsyncResult, error)
+	PrecheckTransaction(ctx context.Context, args *rpc.PrecheckTransactionArgs) (*rpc.PrecheckTransactionResult, error)
+}
+
+// BackupSnapshot calls theta.BackupSnapshot
+func (c *Client) BackupSnapshot(ctx context.Context, args *rpc.BackupSnapshotArgs) (*rpc.BackupSnapshotResult, error) {
+	result := &rpc.BackupSnapshotResult{}
+	if err := c.Call(ctx, "BackupSnapshot", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// VerifySnapshot calls theta.VerifySnapshot
+func (c *Client) VerifySnapshot(ctx context.Context, args *rpc.VerifySnapshotArgs) (*rpc.VerifySnapshotResult, error) {
+	result := &rpc.VerifySnapshotResult{}
+	if err := c.Call(ctx, "VerifySnapshot", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetSnapshotSchedule calls theta.GetSnapshotSchedule
+func (c *Client) GetSnapshotSchedule(ctx context.Context, args *rpc.GetSnapshotScheduleArgs) (*rpc.GetSnapshotScheduleResult, error) {
+	result := &rpc.GetSnapshotScheduleResult{}
+	if err := c.Call(ctx, "GetSnapshotSchedule", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateSnapshotSchedule calls theta.UpdateSnapshotSchedule
+func (c *Client) UpdateSnapshotSchedule(ctx context.Context, args *rpc.UpdateSnapshotScheduleArgs) (*rpc.UpdateSnapshotScheduleResult, error) {
+	result := &rpc.UpdateSnapshotScheduleResult{}
+	if err := c.Call(ctx, "UpdateSnapshotSchedule", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BackupChain calls theta.BackupChain
+func (c *Client) BackupChain(ctx context.Context, args *rpc.BackupChainArgs) (*rpc.BackupChainResult, error) {
+	result := &rpc.BackupChainResult{}
+	if err := c.Call(ctx, "BackupChain", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BackupChainCorrection calls theta.BackupChainCorrection
+func (c *Client) BackupChainCorrection(ctx context.Context, args *rpc.BackupChainCorrectionArgs) (*rpc.BackupChainCorrectionResult, error) {
+	result := &rpc.BackupChainCorrectionResult{}
+	if err := c.Call(ctx, "BackupChainCorrection", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BackupDB calls theta.BackupDB
+func (c *Client) BackupDB(ctx context.Context, args *rpc.BackupDBArgs) (*rpc.BackupDBResult, error) {
+	result := &rpc.BackupDBResult{}
+	if err := c.Call(ctx, "BackupDB", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetDBBackupStatus calls theta.GetDBBackupStatus
+func (c *Client) GetDBBackupStatus(ctx context.Context, args *rpc.GetDBBackupStatusArgs) (*rpc.GetDBBackupStatusResult, error) {
+	result := &rpc.GetDBBackupStatusResult{}
+	if err := c.Call(ctx, "GetDBBackupStatus", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ComposeBatchSendTx calls theta.ComposeBatchSendTx
+func (c *Client) ComposeBatchSendTx(ctx context.Context, args *rpc.ComposeBatchSendTxArgs) (*rpc.ComposeBatchSendTxResult, error) {
+	result := &rpc.ComposeBatchSendTxResult{}
+	if err := c.Call(ctx, "ComposeBatchSendTx", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CallSmartContract calls theta.CallSmartContract
+func (c *Client) CallSmartContract(ctx context.Context, args *rpc.CallSmartContractArgs) (*rpc.CallSmartContractResult, error) {
+	result := &rpc.CallSmartContractResult{}
+	if err := c.Call(ctx, "CallSmartContract", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCrossChainChannel calls theta.GetCrossChainChannel
+func (c *Client) GetCrossChainChannel(ctx context.Context, args *rpc.GetCrossChainChannelArgs) (*rpc.GetCrossChainChannelResult, error) {
+	result := &rpc.GetCrossChainChannelResult{}
+	if err := c.Call(ctx, "GetCrossChainChannel", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCrossChainMessages calls theta.GetCrossChainMessages
+func (c *Client) GetCrossChainMessages(ctx context.Context, args *rpc.GetCrossChainMessagesArgs) (*rpc.GetCrossChainMessagesResult, error) {
+	result := &rpc.GetCrossChainMessagesResult{}
+	if err := c.Call(ctx, "GetCrossChainMessages", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetReceivedCrossChainMessages calls theta.GetReceivedCrossChainMessages
+func (c *Client) GetReceivedCrossChainMessages(ctx context.Context, args *rpc.GetReceivedCrossChainMessagesArgs) (*rpc.GetReceivedCrossChainMessagesResult, error) {
+	result := &rpc.GetReceivedCrossChainMessagesResult{}
+	if err := c.Call(ctx, "GetReceivedCrossChainMessages", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetEliteEdgeNodeVoteDiagnostics calls theta.GetEliteEdgeNodeVoteDiagnostics
+func (c *Client) GetEliteEdgeNodeVoteDiagnostics(ctx context.Context, args *rpc.GetEliteEdgeNodeVoteDiagnosticsArgs) (*rpc.GetEliteEdgeNodeVoteDiagnosticsResult, error) {
+	result := &rpc.GetEliteEdgeNodeVoteDiagnosticsResult{}
+	if err := c.Call(ctx, "GetEliteEdgeNodeVoteDiagnostics", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetSelfValidatorReport calls theta.GetSelfValidatorReport
+func (c *Client) GetSelfValidatorReport(ctx context.Context, args *rpc.GetSelfValidatorReportArgs) (*rpc.GetSelfValidatorReportResult, error) {
+	result := &rpc.GetSelfValidatorReportResult{}
+	if err := c.Call(ctx, "GetSelfValidatorReport", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetGuardianVoteInclusion calls theta.GetGuardianVoteInclusion
+func (c *Client) GetGuardianVoteInclusion(ctx context.Context, args *rpc.GetGuardianVoteInclusionArgs) (*rpc.GetGuardianVoteInclusionResult, error) {
+	result := &rpc.GetGuardianVoteInclusionResult{}
+	if err := c.Call(ctx, "GetGuardianVoteInclusion", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetFinalityProof calls theta.GetFinalityProof
+func (c *Client) GetFinalityProof(ctx context.Context, args *rpc.GetFinalityProofArgs) (*rpc.GetFinalityProofResult, error) {
+	result := &rpc.GetFinalityProofResult{}
+	if err := c.Call(ctx, "GetFinalityProof", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetVersion calls theta.GetVersion
+func (c *Client) GetVersion(ctx context.Context, args *rpc.GetVersionArgs) (*rpc.GetVersionResult, error) {
+	result := &rpc.GetVersionResult{}
+	if err := c.Call(ctx, "GetVersion", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAccount calls theta.GetAccount
+func (c *Client) GetAccount(ctx context.Context, args *rpc.GetAccountArgs) (*rpc.GetAccountResult, error) {
+	result := &rpc.GetAccountResult{}
+	if err := c.Call(ctx, "GetAccount", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetSplitRule calls theta.GetSplitRule
+func (c *Client) GetSplitRule(ctx context.Context, args *rpc.GetSplitRuleArgs) (*rpc.GetSplitRuleResult, error) {
+	result := &rpc.GetSplitRuleResult{}
+	if err := c.Call(ctx, "GetSplitRule", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTransaction calls theta.GetTransaction
+func (c *Client) GetTransaction(ctx context.Context, args *rpc.GetTransactionArgs) (*rpc.GetTransactionResult, error) {
+	result := &rpc.GetTransactionResult{}
+	if err := c.Call(ctx, "GetTransaction", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTransactionByBlockHashAndIndex calls theta.GetTransactionByBlockHashAndIndex
+func (c *Client) GetTransactionByBlockHashAndIndex(ctx context.Context, args *rpc.GetTransactionByBlockHashAndIndexArgs) (*rpc.GetTransactionResult, error) {
+	result := &rpc.GetTransactionResult{}
+	if err := c.Call(ctx, "GetTransactionByBlockHashAndIndex", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPendingTransactions calls theta.GetPendingTransactions
+func (c *Client) GetPendingTransactions(ctx context.Context, args *rpc.GetPendingTransactionsArgs) (*rpc.GetPendingTransactionsResult, error) {
+	result := &rpc.GetPendingTransactionsResult{}
+	if err := c.Call(ctx, "GetPendingTransactions", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPendingTransactionsByAddress calls theta.GetPendingTransactionsByAddress
+func (c *Client) GetPendingTransactionsByAddress(ctx context.Context, args *rpc.GetPendingTransactionsByAddressArgs) (*rpc.GetPendingTransactionsByAddressResult, error) {
+	result := &rpc.GetPendingTransactionsByAddressResult{}
+	if err := c.Call(ctx, "GetPendingTransactionsByAddress", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBlock calls theta.GetBlock
+func (c *Client) GetBlock(ctx context.Context, args *rpc.GetBlockArgs) (*rpc.GetBlockResult, error) {
+	result := &rpc.GetBlockResult{}
+	if err := c.Call(ctx, "GetBlock", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBlockByHeight calls theta.GetBlockByHeight
+func (c *Client) GetBlockByHeight(ctx context.Context, args *rpc.GetBlockByHeightArgs) (*rpc.GetBlockResult, error) {
+	result := &rpc.GetBlockResult{}
+	if err := c.Call(ctx, "GetBlockByHeight", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBlockByTimestamp calls theta.GetBlockByTimestamp
+func (c *Client) GetBlockByTimestamp(ctx context.Context, args *rpc.GetBlockByTimestampArgs) (*rpc.GetBlockResult, error) {
+	result := &rpc.GetBlockResult{}
+	if err := c.Call(ctx, "GetBlockByTimestamp", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBlocksByRange calls theta.GetBlocksByRange
+func (c *Client) GetBlocksByRange(ctx context.Context, args *rpc.GetBlocksByRangeArgs) (*rpc.GetBlocksResult, error) {
+	result := &rpc.GetBlocksResult{}
+	if err := c.Call(ctx, "GetBlocksByRange", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetChainStats calls theta.GetChainStats
+func (c *Client) GetChainStats(ctx context.Context, args *rpc.GetChainStatsArgs) (*rpc.GetChainStatsResult, error) {
+	result := &rpc.GetChainStatsResult{}
+	if err := c.Call(ctx, "GetChainStats", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetContractStats calls theta.GetContractStats
+func (c *Client) GetContractStats(ctx context.Context, args *rpc.GetContractStatsArgs) (*rpc.GetContractStatsResult, error) {
+	result := &rpc.GetContractStatsResult{}
+	if err := c.Call(ctx, "GetContractStats", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetSupplyDelta calls theta.GetSupplyDelta
+func (c *Client) GetSupplyDelta(ctx context.Context, args *rpc.GetSupplyDeltaArgs) (*rpc.GetSupplyDeltaResult, error) {
+	result := &rpc.GetSupplyDeltaResult{}
+	if err := c.Call(ctx, "GetSupplyDelta", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTxTimings calls theta.GetTxTimings
+func (c *Client) GetTxTimings(ctx context.Context, args *rpc.GetTxTimingsArgs) (*rpc.GetTxTimingsResult, error) {
+	result := &rpc.GetTxTimingsResult{}
+	if err := c.Call(ctx, "GetTxTimings", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetContractStorage calls theta.GetContractStorage
+func (c *Client) GetContractStorage(ctx context.Context, args *rpc.GetContractStorageArgs) (*rpc.GetContractStorageResult, error) {
+	result := &rpc.GetContractStorageResult{}
+	if err := c.Call(ctx, "GetContractStorage", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// VerifyContract calls theta.VerifyContract
+func (c *Client) VerifyContract(ctx context.Context, args *rpc.VerifyContractArgs) (*rpc.VerifyContractResult, error) {
+	result := &rpc.VerifyContractResult{}
+	if err := c.Call(ctx, "VerifyContract", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetVerifiedContract calls theta.GetVerifiedContract
+func (c *Client) GetVerifiedContract(ctx context.Context, args *rpc.GetVerifiedContractArgs) (*rpc.GetVerifiedContractResult, error) {
+	result := &rpc.GetVerifiedContractResult{}
+	if err := c.Call(ctx, "GetVerifiedContract", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetNFTsByOwner calls theta.GetNFTsByOwner
+func (c *Client) GetNFTsByOwner(ctx context.Context, args *rpc.GetNFTsByOwnerArgs) (*rpc.GetNFTsByOwnerResult, error) {
+	result := &rpc.GetNFTsByOwnerResult{}
+	if err := c.Call(ctx, "GetNFTsByOwner", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetNFTHistory calls theta.GetNFTHistory
+func (c *Client) GetNFTHistory(ctx context.Context, args *rpc.GetNFTHistoryArgs) (*rpc.GetNFTHistoryResult, error) {
+	result := &rpc.GetNFTHistoryResult{}
+	if err := c.Call(ctx, "GetNFTHistory", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetLogs calls theta.GetLogs
+func (c *Client) GetLogs(ctx context.Context, args *rpc.GetLogsArgs) (*rpc.GetLogsResult, error) {
+	result := &rpc.GetLogsResult{}
+	if err := c.Call(ctx, "GetLogs", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// NewFilter calls theta.NewFilter
+func (c *Client) NewFilter(ctx context.Context, args *rpc.NewFilterArgs) (*rpc.NewFilterResult, error) {
+	result := &rpc.NewFilterResult{}
+	if err := c.Call(ctx, "NewFilter", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// NewBlockFilter calls theta.NewBlockFilter
+func (c *Client) NewBlockFilter(ctx context.Context, args *rpc.NewBlockFilterArgs) (*rpc.NewBlockFilterResult, error) {
+	result := &rpc.NewBlockFilterResult{}
+	if err := c.Call(ctx, "NewBlockFilter", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// NewPendingTransactionFilter calls theta.NewPendingTransactionFilter
+func (c *Client) NewPendingTransactionFilter(ctx context.Context, args *rpc.NewPendingTransactionFilterArgs) (*rpc.NewPendingTransactionFilterResult, error) {
+	result := &rpc.NewPendingTransactionFilterResult{}
+	if err := c.Call(ctx, "NewPendingTransactionFilter", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetFilterChanges calls theta.GetFilterChanges
+func (c *Client) GetFilterChanges(ctx context.Context, args *rpc.GetFilterChangesArgs) (*rpc.GetFilterChangesResult, error) {
+	result := &rpc.GetFilterChangesResult{}
+	if err := c.Call(ctx, "GetFilterChanges", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetFilterLogs calls theta.GetFilterLogs
+func (c *Client) GetFilterLogs(ctx context.Context, args *rpc.GetFilterLogsArgs) (*rpc.GetFilterLogsResult, error) {
+	result := &rpc.GetFilterLogsResult{}
+	if err := c.Call(ctx, "GetFilterLogs", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UninstallFilter calls theta.UninstallFilter
+func (c *Client) UninstallFilter(ctx context.Context, args *rpc.UninstallFilterArgs) (*rpc.UninstallFilterResult, error) {
+	result := &rpc.UninstallFilterResult{}
+	if err := c.Call(ctx, "UninstallFilter", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetSlashHistory calls theta.GetSlashHistory
+func (c *Client) GetSlashHistory(ctx context.Context, args *rpc.GetSlashHistoryArgs) (*rpc.GetSlashHistoryResult, error) {
+	result := &rpc.GetSlashHistoryResult{}
+	if err := c.Call(ctx, "GetSlashHistory", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBalanceHistory calls theta.GetBalanceHistory
+func (c *Client) GetBalanceHistory(ctx context.Context, args *rpc.GetBalanceHistoryArgs) (*rpc.GetBalanceHistoryResult, error) {
+	result := &rpc.GetBalanceHistoryResult{}
+	if err := c.Call(ctx, "GetBalanceHistory", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SearchTransactions calls theta.SearchTransactions
+func (c *Client) SearchTransactions(ctx context.Context, args *rpc.SearchTransactionsArgs) (*rpc.SearchTransactionsResult, error) {
+	result := &rpc.SearchTransactionsResult{}
+	if err := c.Call(ctx, "SearchTransactions", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetRewardDistribution calls theta.GetRewardDistribution
+func (c *Client) GetRewardDistribution(ctx context.Context, args *rpc.GetRewardDistributionArgs) (*rpc.GetRewardDistributionResult, error) {
+	result := &rpc.GetRewardDistributionResult{}
+	if err := c.Call(ctx, "GetRewardDistribution", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetRetentionBoundaries calls theta.GetRetentionBoundaries
+func (c *Client) GetRetentionBoundaries(ctx context.Context, args *rpc.GetRetentionBoundariesArgs) (*rpc.GetRetentionBoundariesResult, error) {
+	result := &rpc.GetRetentionBoundariesResult{}
+	if err := c.Call(ctx, "GetRetentionBoundaries", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStatus calls theta.GetStatus
+func (c *Client) GetStatus(ctx context.Context, args *rpc.GetStatusArgs) (*rpc.GetStatusResult, error) {
+	result := &rpc.GetStatusResult{}
+	if err := c.Call(ctx, "GetStatus", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPeerURLs calls theta.GetPeerURLs
+func (c *Client) GetPeerURLs(ctx context.Context, args *rpc.GetPeersArgs) (*rpc.GetPeerURLsResult, error) {
+	result := &rpc.GetPeerURLsResult{}
+	if err := c.Call(ctx, "GetPeerURLs", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPeers calls theta.GetPeers
+func (c *Client) GetPeers(ctx context.Context, args *rpc.GetPeersArgs) (*rpc.GetPeersResult, error) {
+	result := &rpc.GetPeersResult{}
+	if err := c.Call(ctx, "GetPeers", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPeerCapabilities calls theta.GetPeerCapabilities
+func (c *Client) GetPeerCapabilities(ctx context.Context, args *rpc.GetPeerCapabilitiesArgs) (*rpc.GetPeerCapabilitiesResult, error) {
+	result := &rpc.GetPeerCapabilitiesResult{}
+	if err := c.Call(ctx, "GetPeerCapabilities", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetGovernanceParams calls theta.GetGovernanceParams
+func (c *Client) GetGovernanceParams(ctx context.Context, args *rpc.GetGovernanceParamsArgs) (*rpc.GetGovernanceParamsResult, error) {
+	result := &rpc.GetGovernanceParamsResult{}
+	if err := c.Call(ctx, "GetGovernanceParams", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetValidatorKeyChanges calls theta.GetValidatorKeyChanges
+func (c *Client) GetValidatorKeyChanges(ctx context.Context, args *rpc.GetValidatorKeyChangesArgs) (*rpc.GetValidatorKeyChangesResult, error) {
+	result := &rpc.GetValidatorKeyChangesResult{}
+	if err := c.Call(ctx, "GetValidatorKeyChanges", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStakeAutoCompounding calls theta.GetStakeAutoCompounding
+func (c *Client) GetStakeAutoCompounding(ctx context.Context, args *rpc.GetStakeAutoCompoundingArgs) (*rpc.GetStakeAutoCompoundingResult, error) {
+	result := &rpc.GetStakeAutoCompoundingResult{}
+	if err := c.Call(ctx, "GetStakeAutoCompounding", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetRandomness calls theta.GetRandomness
+func (c *Client) GetRandomness(ctx context.Context, args *rpc.GetRandomnessArgs) (*rpc.GetRandomnessResult, error) {
+	result := &rpc.GetRandomnessResult{}
+	if err := c.Call(ctx, "GetRandomness", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBaseFee calls theta.GetBaseFee
+func (c *Client) GetBaseFee(ctx context.Context, args *rpc.GetBaseFeeArgs) (*rpc.GetBaseFeeResult, error) {
+	result := &rpc.GetBaseFeeResult{}
+	if err := c.Call(ctx, "GetBaseFee", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStateDiff calls theta.GetStateDiff
+func (c *Client) GetStateDiff(ctx context.Context, args *rpc.GetStateDiffArgs) (*rpc.GetStateDiffResult, error) {
+	result := &rpc.GetStateDiffResult{}
+	if err := c.Call(ctx, "GetStateDiff", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPendingUpgrade calls theta.GetPendingUpgrade
+func (c *Client) GetPendingUpgrade(ctx context.Context, args *rpc.GetPendingUpgradeArgs) (*rpc.GetPendingUpgradeResult, error) {
+	result := &rpc.GetPendingUpgradeResult{}
+	if err := c.Call(ctx, "GetPendingUpgrade", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetForkConfig calls theta.GetForkConfig
+func (c *Client) GetForkConfig(ctx context.Context, args *rpc.GetForkConfigArgs) (*rpc.GetForkConfigResult, error) {
+	result := &rpc.GetForkConfigResult{}
+	if err := c.Call(ctx, "GetForkConfig", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetShadowReport calls theta.GetShadowReport
+func (c *Client) GetShadowReport(ctx context.Context, args *rpc.GetShadowReportArgs) (*rpc.GetShadowReportResult, error) {
+	result := &rpc.GetShadowReportResult{}
+	if err := c.Call(ctx, "GetShadowReport", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetProposalSnapshot calls theta.GetProposalSnapshot
+func (c *Client) GetProposalSnapshot(ctx context.Context, args *rpc.GetProposalSnapshotArgs) (*rpc.GetProposalSnapshotResult, error) {
+	result := &rpc.GetProposalSnapshotResult{}
+	if err := c.Call(ctx, "GetProposalSnapshot", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetVcpByHeight calls theta.GetVcpByHeight
+func (c *Client) GetVcpByHeight(ctx context.Context, args *rpc.GetVcpByHeightArgs) (*rpc.GetVcpResult, error) {
+	result := &rpc.GetVcpResult{}
+	if err := c.Call(ctx, "GetVcpByHeight", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetGcpByHeight calls theta.GetGcpByHeight
+func (c *Client) GetGcpByHeight(ctx context.Context, args *rpc.GetGcpByHeightArgs) (*rpc.GetGcpResult, error) {
+	result := &rpc.GetGcpResult{}
+	if err := c.Call(ctx, "GetGcpByHeight", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStakeComposition calls theta.GetStakeComposition
+func (c *Client) GetStakeComposition(ctx context.Context, args *rpc.GetStakeCompositionArgs) (*rpc.GetStakeCompositionResult, error) {
+	result := &rpc.GetStakeCompositionResult{}
+	if err := c.Call(ctx, "GetStakeComposition", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ProbeNetworkTopology calls theta.ProbeNetworkTopology
+func (c *Client) ProbeNetworkTopology(ctx context.Context, args *rpc.ProbeNetworkTopologyArgs) (*rpc.ProbeNetworkTopologyResult, error) {
+	result := &rpc.ProbeNetworkTopologyResult{}
+	if err := c.Call(ctx, "ProbeNetworkTopology", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetGuardianInfo calls theta.GetGuardianInfo
+func (c *Client) GetGuardianInfo(ctx context.Context, args *rpc.GetGuardianInfoArgs) (*rpc.GetGuardianInfoResult, error) {
+	result := &rpc.GetGuardianInfoResult{}
+	if err := c.Call(ctx, "GetGuardianInfo", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetEenpByHeight calls theta.GetEenpByHeight
+func (c *Client) GetEenpByHeight(ctx context.Context, args *rpc.GetEenpByHeightArgs) (*rpc.GetEenpResult, error) {
+	result := &rpc.GetEenpResult{}
+	if err := c.Call(ctx, "GetEenpByHeight", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStakeRewardDistributionByHeight calls theta.GetStakeRewardDistributionByHeight
+func (c *Client) GetStakeRewardDistributionByHeight(ctx context.Context, args *rpc.GetStakeRewardDistributionRuleSetByHeightArgs) (*rpc.GetStakeRewardDistributionRuleSetResult, error) {
+	result := &rpc.GetStakeRewardDistributionRuleSetResult{}
+	if err := c.Call(ctx, "GetStakeRewardDistributionByHeight", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetEliteEdgeNodeStakeReturnsByHeight calls theta.GetEliteEdgeNodeStakeReturnsByHeight
+func (c *Client) GetEliteEdgeNodeStakeReturnsByHeight(ctx context.Context, args *rpc.GetEliteEdgeNodeStakeReturnsByHeightArgs) (*rpc.GetEliteEdgeNodeStakeReturnsByHeightResult, error) {
+	result := &rpc.GetEliteEdgeNodeStakeReturnsByHeightResult{}
+	if err := c.Call(ctx, "GetEliteEdgeNodeStakeReturnsByHeight", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAllPendingEliteEdgeNodeStakeReturns calls theta.GetAllPendingEliteEdgeNodeStakeReturns
+func (c *Client) GetAllPendingEliteEdgeNodeStakeReturns(ctx context.Context, args *rpc.GetAllPendingEliteEdgeNodeStakeReturnsArgs) (*rpc.GetAllPendingEliteEdgeNodeStakeReturnsResult, error) {
+	result := &rpc.GetAllPendingEliteEdgeNodeStakeReturnsResult{}
+	if err := c.Call(ctx, "GetAllPendingEliteEdgeNodeStakeReturns", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ComposeDepositStakeTx calls theta.ComposeDepositStakeTx
+func (c *Client) ComposeDepositStakeTx(ctx context.Context, args *rpc.ComposeDepositStakeTxArgs) (*rpc.ComposeDepositStakeTxResult, error) {
+	result := &rpc.ComposeDepositStakeTxResult{}
+	if err := c.Call(ctx, "ComposeDepositStakeTx", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ComposeWithdrawStakeTx calls theta.ComposeWithdrawStakeTx
+func (c *Client) ComposeWithdrawStakeTx(ctx context.Context, args *rpc.ComposeWithdrawStakeTxArgs) (*rpc.ComposeWithdrawStakeTxResult, error) {
+	result := &rpc.ComposeWithdrawStakeTxResult{}
+	if err := c.Call(ctx, "ComposeWithdrawStakeTx", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStakingParams calls theta.GetStakingParams
+func (c *Client) GetStakingParams(ctx context.Context, args *rpc.GetStakingParamsArgs) (*rpc.GetStakingParamsResult, error) {
+	result := &rpc.GetStakingParamsResult{}
+	if err := c.Call(ctx, "GetStakingParams", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SimulateStakeWithdrawal calls theta.SimulateStakeWithdrawal
+func (c *Client) SimulateStakeWithdrawal(ctx context.Context, args *rpc.SimulateStakeWithdrawalArgs) (*rpc.SimulateStakeWithdrawalResult, error) {
+	result := &rpc.SimulateStakeWithdrawalResult{}
+	if err := c.Call(ctx, "SimulateStakeWithdrawal", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStakeSummary calls theta.GetStakeSummary
+func (c *Client) GetStakeSummary(ctx context.Context, args *rpc.GetStakeSummaryArgs) (*rpc.GetStakeSummaryResult, error) {
+	result := &rpc.GetStakeSummaryResult{}
+	if err := c.Call(ctx, "GetStakeSummary", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetSubchain calls theta.GetSubchain
+func (c *Client) GetSubchain(ctx context.Context, args *rpc.GetSubchainArgs) (*rpc.GetSubchainResult, error) {
+	result := &rpc.GetSubchainResult{}
+	if err := c.Call(ctx, "GetSubchain", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetSubchainCheckpoint calls theta.GetSubchainCheckpoint
+func (c *Client) GetSubchainCheckpoint(ctx context.Context, args *rpc.GetSubchainCheckpointArgs) (*rpc.GetSubchainCheckpointResult, error) {
+	result := &rpc.GetSubchainCheckpointResult{}
+	if err := c.Call(ctx, "GetSubchainCheckpoint", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BroadcastRawTransaction calls theta.BroadcastRawTransaction
+func (c *Client) BroadcastRawTransaction(ctx context.Context, args *rpc.BroadcastRawTransactionArgs) (*rpc.BroadcastRawTransactionResult, error) {
+	result := &rpc.BroadcastRawTransactionResult{}
+	if err := c.Call(ctx, "BroadcastRawTransaction", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BroadcastRawTransactionAsync calls theta.BroadcastRawTransactionAsync
+func (c *Client) BroadcastRawTransactionAsync(ctx context.Context, args *rpc.BroadcastRawTransactionAsyncArgs) (*rpc.BroadcastRawTransactionAsyncResult, error) {
+	result := &rpc.BroadcastRawTransactionAsyncResult{}
+	if err := c.Call(ctx, "BroadcastRawTransactionAsync", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PrecheckTransaction calls theta.PrecheckTransaction
+func (c *Client) PrecheckTransaction(ctx context.Context, args *rpc.PrecheckTransactionArgs) (*rpc.PrecheckTransactionResult, error) {
+	result := &rpc.PrecheckTransactionResult{}
+	if err := c.Call(ctx, "PrecheckTransaction", args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,254 @@
+// Package client is a typed Go client of the Theta node RPC API. It wraps every method of the
+// ThetaRPCService with its request and response structs, and handles the timeouts, the retries of
+// the transport failures, and the reconnection of the WebSocket connections.
+//
+// Example:
+//
+//	c := client.New("http://localhost:16888/rpc", client.WithTimeout(10*time.Second))
+//	status, err := c.GetStatus(ctx, &rpc.GetStatusArgs{})
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/rpc"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thetatoken/theta/rpc/lib/ipc"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	DefaultTimeout      = 30 * time.Second
+	DefaultMaxRetries   = 3
+	DefaultRetryBackoff = 500 * time.Millisecond
+
+	serviceName = "theta"
+)
+
+// Caller sends a single JSON-RPC call. The Client uses it as the transport, and tests can replace it with a MockCaller.
+type Caller interface {
+	Call(ctx context.Context, method string, args interface{}, result interface{}) error
+	Close() error
+}
+
+// Error is the error returned by the node for a call, as opposed to the transport failures
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Option customizes the Client
+type Option func(*options)
+
+type options struct {
+	timeout      time.Duration
+	maxRetries   int
+	retryBackoff time.Duration
+	httpClient   *http.Client
+}
+
+// WithTimeout sets the timeout of each call, including the retries
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// WithRetries sets the number of retries of a call after a transport failure, and the initial backoff between the
+// retries which doubles after each retry. The errors returned by the node are never retried.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.maxRetries = maxRetries
+		o.retryBackoff = backoff
+	}
+}
+
+// WithHTTPClient sets the http.Client used by the HTTP transport
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) { o.httpClient = httpClient }
+}
+
+// Client is the typed client of the Theta node RPC API. It is safe for concurrent use.
+type Client struct {
+	caller Caller
+	opts   options
+}
+
+var _ API = (*Client)(nil)
+
+// New creates a Client of the node at the given URL. URLs ending with /ws are served over WebSocket, the
+// connection is established lazily and re-established after it breaks. URLs starting with unix:// are served
+// over the IPC socket of the node at the given path, e.g. unix:///home/theta/node/theta.ipc.
+func New(url string, opts ...Option) *Client {
+	o := newOptions(opts)
+
+	var caller Caller
+	if strings.HasSuffix(url, "/ws") {
+		caller = &wsCaller{url: url}
+	} else if socketPath, ok := ipc.ParseEndpoint(url); ok {
+		caller = &httpCaller{client: jsonrpc2.NewCustomHTTPClient(ipc.RPCURL, ipc.NewHTTPClient(socketPath, o.timeout))}
+	} else {
+		caller = &httpCaller{client: jsonrpc2.NewCustomHTTPClient(url, o.httpClient)}
+	}
+	return &Client{caller: caller, opts: o}
+}
+
+// NewWithCaller creates a Client over the given Caller, e.g. a MockCaller in tests
+func NewWithCaller(caller Caller, opts ...Option) *Client {
+	return &Client{caller: caller, opts: newOptions(opts)}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		timeout:      DefaultTimeout,
+		maxRetries:   DefaultMaxRetries,
+		retryBackoff: DefaultRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.httpClient == nil {
+		o.httpClient = &http.Client{Timeout: o.timeout}
+	}
+	return o
+}
+
+// Call invokes the given method of the ThetaRPCService, e.g. "GetStatus", and decodes the response into result
+func (c *Client) Call(ctx context.Context, method string, args interface{}, result interface{}) error {
+	if c.opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.timeout)
+		defer cancel()
+	}
+
+	backoff := c.opts.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := c.caller.Call(ctx, serviceName+"."+method, args, result)
+		if err == nil || !isRetryable(err) || attempt >= c.opts.maxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// Close releases the connection of the WebSocket transport
+func (c *Client) Close() error {
+	return c.caller.Close()
+}
+
+func isRetryable(err error) bool {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// toError converts the errors returned by the node into Error, and leaves the transport failures as is
+func toError(err error) error {
+	if _, ok := err.(rpc.ServerError); !ok {
+		return err
+	}
+	e := jsonrpc2.ServerError(err)
+	return &Error{Code: e.Code, Message: e.Message}
+}
+
+// callWithContext runs the call on the net/rpc client, and gives up when the context is done
+func callWithContext(ctx context.Context, client *rpc.Client, method string, args interface{}, result interface{}) error {
+	call := client.Go(method, args, result, make(chan *rpc.Call, 1))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-call.Done:
+		return toError(call.Error)
+	}
+}
+
+//
+// --------------------- HTTP transport -------------------------
+//
+
+type httpCaller struct {
+	client *jsonrpc2.Client
+}
+
+func (h *httpCaller) Call(ctx context.Context, method string, args interface{}, result interface{}) error {
+	return callWithContext(ctx, h.client.Client, method, args, result)
+}
+
+func (h *httpCaller) Close() error {
+	return nil
+}
+
+//
+// --------------------- WebSocket transport -------------------------
+//
+
+type wsCaller struct {
+	url string
+
+	mu     sync.Mutex
+	client *jsonrpc2.Client
+}
+
+func (w *wsCaller) Call(ctx context.Context, method string, args interface{}, result interface{}) error {
+	client, err := w.connect()
+	if err != nil {
+		return err
+	}
+	err = callWithContext(ctx, client.Client, method, args, result)
+	if err == rpc.ErrShutdown {
+		w.reset(client) // reconnect on the next attempt
+	}
+	return err
+}
+
+func (w *wsCaller) connect() (*jsonrpc2.Client, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.client != nil {
+		return w.client, nil
+	}
+	ws, err := websocket.Dial(w.url, "", w.url)
+	if err != nil {
+		return nil, err
+	}
+	w.client = jsonrpc2.NewClient(ws)
+	return w.client, nil
+}
+
+func (w *wsCaller) reset(client *jsonrpc2.Client) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.client == client {
+		w.client.Close()
+		w.client = nil
+	}
+}
+
+func (w *wsCaller) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.client == nil {
+		return nil
+	}
+	err := w.client.Close()
+	w.client = nil
+	return err
+}
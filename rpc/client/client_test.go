@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	netrpc "net/rpc"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+)
+
+func TestClientTypedCall(t *testing.T) {
+	require := require.New(t)
+
+	mock := NewMockCaller()
+	mock.Handle("GetStatus", func(args interface{}) (interface{}, error) {
+		return &rpc.GetStatusResult{ChainID: "privatenet", LatestFinalizedBlockHeight: 42}, nil
+	})
+	c := NewWithCaller(mock)
+
+	status, err := c.GetStatus(context.Background(), &rpc.GetStatusArgs{})
+	require.Nil(err)
+	require.Equal("privatenet", status.ChainID)
+	require.Equal(common.JSONUint64(42), status.LatestFinalizedBlockHeight)
+
+	_, err = c.GetVersion(context.Background(), &rpc.GetVersionArgs{})
+	var rpcErr *Error
+	require.True(errors.As(err, &rpcErr))
+}
+
+func TestClientRetries(t *testing.T) {
+	assert := assert.New(t)
+
+	mock := NewMockCaller()
+	failures := 2
+	mock.Handle("GetStatus", func(args interface{}) (interface{}, error) {
+		if failures > 0 {
+			failures--
+			return nil, errors.New("connection reset by peer")
+		}
+		return &rpc.GetStatusResult{ChainID: "privatenet"}, nil
+	})
+	mock.Handle("GetAccount", func(args interface{}) (interface{}, error) {
+		return nil, &Error{Code: -32000, Message: "Account is not found"}
+	})
+	c := NewWithCaller(mock, WithRetries(3, time.Millisecond))
+
+	// The transport failures are retried
+	status, err := c.GetStatus(context.Background(), &rpc.GetStatusArgs{})
+	assert.Nil(err)
+	assert.Equal("privatenet", status.ChainID)
+	assert.Equal(3, mock.NumCalls("GetStatus"))
+
+	// The errors returned by the node are not
+	_, err = c.GetAccount(context.Background(), &rpc.GetAccountArgs{Address: "0x0"})
+	assert.NotNil(err)
+	assert.Equal(1, mock.NumCalls("GetAccount"))
+}
+
+func TestWaitForTransaction(t *testing.T) {
+	assert := assert.New(t)
+
+	mock := NewMockCaller()
+	polls := 0
+	mock.Handle("GetTransaction", func(args interface{}) (interface{}, error) {
+		polls++
+		if polls < 3 {
+			return &rpc.GetTransactionResult{Status: rpc.TxStatusPending}, nil
+		}
+		return &rpc.GetTransactionResult{Status: rpc.TxStatusFinalized, BlockHeight: 7}, nil
+	})
+	c := NewWithCaller(mock)
+
+	tx, err := WaitForTransaction(context.Background(), c, "0x01", time.Millisecond)
+	assert.Nil(err)
+	assert.Equal(common.JSONUint64(7), tx.BlockHeight)
+	assert.Equal(3, polls)
+}
+
+type ipcTestService struct{}
+
+func (s *ipcTestService) GetVersion(args *rpc.GetVersionArgs, result *rpc.GetVersionResult) error {
+	result.Version = "1.0.0"
+	return nil
+}
+
+func TestClientIPC(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ipc")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "theta.ipc")
+
+	server := netrpc.NewServer()
+	require.Nil(server.RegisterName("theta", &ipcTestService{}))
+	l, err := net.Listen("unix", path)
+	require.Nil(err)
+	defer l.Close()
+	go http.Serve(l, jsonrpc2.HTTPHandler(server))
+
+	c := New("unix://"+path, WithRetries(0, 0))
+	version, err := c.GetVersion(context.Background(), &rpc.GetVersionArgs{})
+	require.Nil(err)
+	assert.Equal("1.0.0", version.Version)
+}
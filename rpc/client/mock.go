@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MockHandler serves a mocked call. It receives the request args and returns the result, which is converted into
+// the typed result through JSON like a real response.
+type MockHandler func(args interface{}) (interface{}, error)
+
+// MockCaller is a Caller serving the calls with the registered handlers, for testing the code using the Client
+// without a node:
+//
+//	mock := client.NewMockCaller()
+//	mock.Handle("GetStatus", func(args interface{}) (interface{}, error) {
+//		return &rpc.GetStatusResult{ChainID: "privatenet"}, nil
+//	})
+//	c := client.NewWithCaller(mock)
+type MockCaller struct {
+	mu       sync.Mutex
+	handlers map[string]MockHandler
+	calls    map[string]int
+}
+
+var _ Caller = (*MockCaller)(nil)
+
+// NewMockCaller creates a MockCaller without any handler
+func NewMockCaller() *MockCaller {
+	return &MockCaller{
+		handlers: make(map[string]MockHandler),
+		calls:    make(map[string]int),
+	}
+}
+
+// Handle registers the handler of the given method, e.g. "GetStatus"
+func (m *MockCaller) Handle(method string, handler MockHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers[method] = handler
+}
+
+// NumCalls returns the number of calls of the given method, including the retries
+func (m *MockCaller) NumCalls(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+
+func (m *MockCaller) Call(ctx context.Context, method string, args interface{}, result interface{}) error {
+	method = strings.TrimPrefix(method, serviceName+".")
+
+	m.mu.Lock()
+	handler, ok := m.handlers[method]
+	m.calls[method]++
+	m.mu.Unlock()
+
+	if !ok {
+		return &Error{Code: -32601, Message: fmt.Sprintf("rpc: can't find method %v.%v", serviceName, method)}
+	}
+	res, err := handler(args)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, result)
+}
+
+func (m *MockCaller) Close() error {
+	return nil
+}
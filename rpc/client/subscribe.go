@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/rpc"
+)
+
+// DefaultPollInterval is the interval between the polls of the subscription helpers
+const DefaultPollInterval = 2 * time.Second
+
+// SubscribeFinalizedBlocks delivers the finalized blocks in order, starting from the given height, or from the
+// next finalized block if the height is 0. The node has no push notifications, so the subscription polls the
+// node over the transport of the Client, WebSocket or HTTP. Both channels are closed when the context is done.
+// Errors are delivered without stopping the subscription, the missed blocks are fetched on the next poll.
+func SubscribeFinalizedBlocks(ctx context.Context, c API, fromHeight uint64, interval time.Duration) (<-chan *rpc.GetBlockResult, <-chan error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	blocks := make(chan *rpc.GetBlockResult)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(blocks)
+		defer close(errs)
+
+		next := fromHeight
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			status, err := c.GetStatus(ctx, &rpc.GetStatusArgs{})
+			if err != nil {
+				sendError(errs, err)
+			} else {
+				lfbHeight := uint64(status.LatestFinalizedBlockHeight)
+				if next == 0 {
+					next = lfbHeight + 1
+				}
+				for ; next <= lfbHeight; next++ {
+					block, err := c.GetBlockByHeight(ctx, &rpc.GetBlockByHeightArgs{Height: common.JSONUint64(next)})
+					if err != nil {
+						sendError(errs, err)
+						break
+					}
+					if block.GetBlockResultInner == nil {
+						sendError(errs, fmt.Errorf("finalized block at height %v is not found", next))
+						break
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case blocks <- block:
+					}
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return blocks, errs
+}
+
+// WaitForTransaction polls the status of the transaction until it is finalized or abandoned, or the context is done
+func WaitForTransaction(ctx context.Context, c API, hash string, interval time.Duration) (*rpc.GetTransactionResult, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		tx, err := c.GetTransaction(ctx, &rpc.GetTransactionArgs{Hash: hash})
+		if err == nil {
+			switch tx.Status {
+			case rpc.TxStatusFinalized:
+				return tx, nil
+			case rpc.TxStatusAbandoned:
+				return tx, fmt.Errorf("transaction %v was abandoned", hash)
+			}
+		} else if !isRetryable(err) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendError delivers the error unless an undelivered one is pending
+func sendError(errs chan error, err error) {
+	select {
+	case errs <- err:
+	default:
+	}
+}
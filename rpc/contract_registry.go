@@ -0,0 +1,429 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/spf13/viper"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/vm/abi"
+)
+
+// maxCachedContractABIs is the number of the parsed ABIs of the verified contracts kept in memory
+const maxCachedContractABIs = 1000
+
+// contractSourceFile is the name under which the uploaded source is passed to the compiler
+const contractSourceFile = "contract.sol"
+
+var errContractRegistryDisabled = errors.New("The contract registry is not enabled")
+
+func newContractABICache() *lru.Cache {
+	if !viper.GetBool(common.CfgRPCContractRegistryEnabled) {
+		return nil
+	}
+	cache, err := lru.New(maxCachedContractABIs)
+	if err != nil {
+		logger.Panic(err)
+	}
+	return cache
+}
+
+// contractABI returns the ABI of the verified contract at the given address, or nil if it is not verified
+func (t *ThetaRPCService) contractABI(address common.Address) *abi.ABI {
+	if t.contractABIs == nil {
+		return nil
+	}
+	if cached, ok := t.contractABIs.Get(address); ok {
+		return cached.(*abi.ABI)
+	}
+	var parsed *abi.ABI
+	if contract, found := t.chain.FindVerifiedContract(address); found {
+		var err error
+		if parsed, err = abi.Parse([]byte(contract.ABI)); err != nil {
+			logger.Warnf("Failed to parse the ABI of the verified contract %v: %v", address.Hex(), err)
+		}
+	}
+	t.contractABIs.Add(address, parsed)
+	return parsed
+}
+
+// requestedABI returns the ABI passed with a request to decode the calls and the logs, either as the JSON ABI or
+// a fragment of it, or by the address of a verified contract, e.g. the implementation behind a proxy. It returns
+// nil if neither is set.
+func (t *ThetaRPCService) requestedABI(abiJSON json.RawMessage, contract string) (*abi.ABI, error) {
+	var requested *abi.ABI
+	if len(abiJSON) > 0 {
+		var err error
+		if requested, err = abi.Parse(abiJSON); err != nil {
+			return nil, fmt.Errorf("Invalid ABI: %v", err)
+		}
+	}
+	if contract == "" {
+		return requested, nil
+	}
+	if t.contractABIs == nil {
+		return nil, errContractRegistryDisabled
+	}
+	if !common.IsHexAddress(contract) {
+		return nil, errors.New("The ABI contract must be a valid address")
+	}
+	contractABI := t.contractABI(common.HexToAddress(contract))
+	if contractABI == nil {
+		return nil, newNotFoundError("Contract %v is not verified", contract)
+	}
+	if requested == nil {
+		return contractABI, nil
+	}
+	requested.Merge(contractABI)
+	return requested, nil
+}
+
+// decodeTxInput decodes the call made by the given tx with the requested ABI, or the ABI of the called contract
+// if it is verified
+func (t *ThetaRPCService) decodeTxInput(tx types.Tx, requested *abi.ABI) *abi.DecodedCall {
+	var to common.Address
+	var data []byte
+	switch tx := tx.(type) {
+	case *types.SmartContractTx:
+		to, data = tx.To.Address, tx.Data
+	case *types.SmartContractTxV2:
+		to, data = tx.To.Address, tx.Data
+	default:
+		return nil
+	}
+	for _, contractABI := range []*abi.ABI{requested, t.contractABI(to)} {
+		if contractABI == nil {
+			continue
+		}
+		if call, err := contractABI.DecodeCall(data); err == nil {
+			return call
+		}
+	}
+	return nil
+}
+
+// decodeLog decodes the given log with the requested ABI, or the ABI of the emitting contract if it is verified,
+// or returns nil
+func (t *ThetaRPCService) decodeLog(address common.Address, topics []common.Hash, data []byte, requested *abi.ABI) *abi.DecodedEvent {
+	for _, contractABI := range []*abi.ABI{requested, t.contractABI(address)} {
+		if contractABI == nil {
+			continue
+		}
+		if event, err := contractABI.DecodeLog(topics, data); err == nil {
+			return event
+		}
+	}
+	return nil
+}
+
+// ------------------------------ VerifyContract -----------------------------------
+
+type VerifyContractArgs struct {
+	Address         string            `json:"address"`
+	Name            string            `json:"name"`             // the contract matched against all the contracts of the source if empty
+	Source          string            `json:"source"`           // the Solidity source, with the imports flattened
+	CompilerVersion string            `json:"compiler_version"` // checked against the version of the solc of the node if set
+	Optimize        bool              `json:"optimize"`
+	Runs            common.JSONUint64 `json:"runs"`
+	EVMVersion      string            `json:"evm_version"`
+}
+
+type VerifyContractResult struct {
+	Address         common.Address  `json:"address"`
+	Name            string          `json:"name"`
+	CompilerVersion string          `json:"compiler_version"`
+	FullMatch       bool            `json:"full_match"` // whether the metadata hash matched as well
+	ABI             json.RawMessage `json:"abi"`
+}
+
+func (t *ThetaRPCService) VerifyContract(args *VerifyContractArgs, result *VerifyContractResult) (err error) {
+	defer t.guard("VerifyContract", &err)()
+
+	if t.contractABIs == nil {
+		return errContractRegistryDisabled
+	}
+	if !common.IsHexAddress(args.Address) {
+		return errors.New("A valid contract address must be specified")
+	}
+	if args.Source == "" {
+		return errors.New("The source must be specified")
+	}
+
+	address := common.HexToAddress(args.Address)
+	view, _, err := t.getFinalizedView(0)
+	if err != nil {
+		return err
+	}
+	code := view.GetCode(address)
+	if len(code) == 0 {
+		return newNotFoundError("No contract is deployed at %v", address.Hex())
+	}
+
+	compiler := &solcCompiler{
+		path:    viper.GetString(common.CfgRPCContractRegistrySolc),
+		timeout: viper.GetDuration(common.CfgRPCContractRegistryCompileTimeoutSecs) * time.Second,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), compiler.timeout)
+	defer cancel()
+
+	version, err := compiler.version(ctx)
+	if err != nil {
+		return err
+	}
+	if args.CompilerVersion != "" && !strings.HasPrefix(version, strings.TrimPrefix(args.CompilerVersion, "v")) {
+		return fmt.Errorf("The compiler of the node is %v, not %v", version, args.CompilerVersion)
+	}
+	contracts, err := compiler.compile(ctx, args.Source, args.Optimize, uint64(args.Runs), args.EVMVersion)
+	if err != nil {
+		return err
+	}
+	name, compiled, fullMatch, err := matchCompiledContract(contracts, args.Name, code)
+	if err != nil {
+		return err
+	}
+	contractABI, err := abi.Parse(compiled.ABI)
+	if err != nil {
+		return fmt.Errorf("Failed to parse the compiled ABI: %v", err)
+	}
+
+	contract := &blockchain.VerifiedContract{
+		Address:         address,
+		Name:            name,
+		CompilerVersion: version,
+		Optimize:        args.Optimize,
+		Runs:            uint64(args.Runs),
+		EVMVersion:      args.EVMVersion,
+		Source:          args.Source,
+		ABI:             string(compiled.ABI),
+		CodeHash:        crypto.Keccak256Hash(code),
+		FullMatch:       fullMatch,
+		VerifiedAt:      uint64(time.Now().Unix()),
+	}
+	if err := t.chain.AddVerifiedContract(contract); err != nil {
+		return err
+	}
+	t.contractABIs.Add(address, contractABI)
+
+	logger.Infof("Verified contract %v at %v, full match: %v", name, address.Hex(), fullMatch)
+
+	result.Address = address
+	result.Name = name
+	result.CompilerVersion = version
+	result.FullMatch = fullMatch
+	result.ABI = compiled.ABI
+
+	return nil
+}
+
+// ------------------------------ GetVerifiedContract -----------------------------------
+
+type GetVerifiedContractArgs struct {
+	Address string `json:"address"`
+}
+
+type GetVerifiedContractResult struct {
+	Address         common.Address    `json:"address"`
+	Name            string            `json:"name"`
+	CompilerVersion string            `json:"compiler_version"`
+	Optimize        bool              `json:"optimize"`
+	Runs            common.JSONUint64 `json:"runs"`
+	EVMVersion      string            `json:"evm_version"`
+	Source          string            `json:"source"`
+	ABI             json.RawMessage   `json:"abi"`
+	CodeHash        common.Hash       `json:"code_hash"`
+	FullMatch       bool              `json:"full_match"`
+	VerifiedAt      common.JSONUint64 `json:"verified_at"`
+}
+
+func (t *ThetaRPCService) GetVerifiedContract(args *GetVerifiedContractArgs, result *GetVerifiedContractResult) (err error) {
+	defer t.guard("GetVerifiedContract", &err)()
+
+	if t.contractABIs == nil {
+		return errContractRegistryDisabled
+	}
+	if !common.IsHexAddress(args.Address) {
+		return errors.New("A valid contract address must be specified")
+	}
+	address := common.HexToAddress(args.Address)
+	contract, found := t.chain.FindVerifiedContract(address)
+	if !found {
+		return newNotFoundError("Contract %v is not verified", address.Hex())
+	}
+
+	result.Address = contract.Address
+	result.Name = contract.Name
+	result.CompilerVersion = contract.CompilerVersion
+	result.Optimize = contract.Optimize
+	result.Runs = common.JSONUint64(contract.Runs)
+	result.EVMVersion = contract.EVMVersion
+	result.Source = contract.Source
+	result.ABI = json.RawMessage(contract.ABI)
+	result.CodeHash = contract.CodeHash
+	result.FullMatch = contract.FullMatch
+	result.VerifiedAt = common.JSONUint64(contract.VerifiedAt)
+
+	return nil
+}
+
+// ------------------------------ Compilation -----------------------------------
+
+// solcCompiler compiles the sources with the solc binary through its standard JSON interface
+type solcCompiler struct {
+	path    string
+	timeout time.Duration
+}
+
+type solcInput struct {
+	Language string                       `json:"language"`
+	Sources  map[string]map[string]string `json:"sources"`
+	Settings solcSettings                 `json:"settings"`
+}
+
+type solcSettings struct {
+	Optimizer       solcOptimizer                  `json:"optimizer"`
+	EVMVersion      string                         `json:"evmVersion,omitempty"`
+	OutputSelection map[string]map[string][]string `json:"outputSelection"`
+}
+
+type solcOptimizer struct {
+	Enabled bool   `json:"enabled"`
+	Runs    uint64 `json:"runs"`
+}
+
+type solcOutput struct {
+	Errors []struct {
+		Severity         string `json:"severity"`
+		FormattedMessage string `json:"formattedMessage"`
+	} `json:"errors"`
+	Contracts map[string]map[string]*compiledContract `json:"contracts"`
+}
+
+type compiledContract struct {
+	ABI json.RawMessage `json:"abi"`
+	EVM struct {
+		DeployedBytecode struct {
+			Object              string                                   `json:"object"`
+			ImmutableReferences map[string][]struct{ Start, Length int } `json:"immutableReferences"`
+		} `json:"deployedBytecode"`
+	} `json:"evm"`
+}
+
+// version returns the version of the compiler, e.g. 0.8.17+commit.8df45f5f.Linux.g++
+func (c *solcCompiler) version(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, c.path, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("Failed to run solc: %v", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "Version: ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "Version: ")), nil
+		}
+	}
+	return "", errors.New("Failed to parse the version of solc")
+}
+
+// compile compiles the given source, and returns the compiled contracts by name
+func (c *solcCompiler) compile(ctx context.Context, source string, optimize bool, runs uint64, evmVersion string) (map[string]*compiledContract, error) {
+	if runs == 0 {
+		runs = 200
+	}
+	input, err := json.Marshal(&solcInput{
+		Language: "Solidity",
+		Sources:  map[string]map[string]string{contractSourceFile: {"content": source}},
+		Settings: solcSettings{
+			Optimizer:  solcOptimizer{Enabled: optimize, Runs: runs},
+			EVMVersion: evmVersion,
+			OutputSelection: map[string]map[string][]string{
+				"*": {"*": {"abi", "evm.deployedBytecode.object", "evm.deployedBytecode.immutableReferences"}},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, c.path, "--standard-json")
+	cmd.Stdin = bytes.NewReader(input)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to run solc: %v", err)
+	}
+	output := &solcOutput{}
+	if err := json.Unmarshal(out, output); err != nil {
+		return nil, fmt.Errorf("Failed to parse the output of solc: %v", err)
+	}
+	messages := []string{}
+	for _, e := range output.Errors {
+		if e.Severity == "error" {
+			messages = append(messages, e.FormattedMessage)
+		}
+	}
+	if len(messages) > 0 {
+		return nil, fmt.Errorf("Compilation failed: %v", strings.Join(messages, "\n"))
+	}
+	return output.Contracts[contractSourceFile], nil
+}
+
+// matchCompiledContract finds the compiled contract matching the deployed code, among the contract of the given
+// name, or all the contracts of the source if the name is empty. A full match includes the metadata hash, which commits to the
+// exact source, while a partial match only proves the source compiles into the same executable code.
+func matchCompiledContract(contracts map[string]*compiledContract, name string, code []byte) (string, *compiledContract, bool, error) {
+	if name != "" {
+		compiled, ok := contracts[name]
+		if !ok {
+			return "", nil, false, fmt.Errorf("Contract %v is not found in the source", name)
+		}
+		contracts = map[string]*compiledContract{name: compiled}
+	}
+	for contractName, compiled := range contracts {
+		if strings.Contains(compiled.EVM.DeployedBytecode.Object, "__") {
+			return "", nil, false, errors.New("Contracts linked to libraries are not supported")
+		}
+		compiledCode, err := hex.DecodeString(compiled.EVM.DeployedBytecode.Object)
+		if err != nil || len(compiledCode) == 0 || len(compiledCode) != len(code) {
+			continue
+		}
+		// The immutables are only filled in on the deployment
+		deployed := common.CopyBytes(code)
+		for _, refs := range compiled.EVM.DeployedBytecode.ImmutableReferences {
+			for _, ref := range refs {
+				if ref.Start >= 0 && ref.Length >= 0 && ref.Start+ref.Length <= len(deployed) {
+					copy(deployed[ref.Start:ref.Start+ref.Length], compiledCode[ref.Start:ref.Start+ref.Length])
+				}
+			}
+		}
+		if bytes.Equal(deployed, compiledCode) {
+			return contractName, compiled, true, nil
+		}
+		if bytes.Equal(stripMetadata(deployed), stripMetadata(compiledCode)) {
+			return contractName, compiled, false, nil
+		}
+	}
+	return "", nil, false, errors.New("The compiled bytecode does not match the deployed code")
+}
+
+// stripMetadata removes the CBOR encoded metadata appended by solc to the code, whose length is given by the last
+// two bytes of the code
+func stripMetadata(code []byte) []byte {
+	if len(code) < 2 {
+		return code
+	}
+	length := int(code[len(code)-2])<<8 | int(code[len(code)-1])
+	start := len(code) - 2 - length
+	if length == 0 || start < 0 || code[start]&0xe0 != 0xa0 { // the metadata is a CBOR map
+		return code
+	}
+	return code[:start]
+}
@@ -0,0 +1,238 @@
+package rpc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+const testContractABI = `[
+	{"type": "function", "name": "transfer", "inputs": [{"name": "to", "type": "address"}, {"name": "value", "type": "uint256"}], "outputs": [{"name": "", "type": "bool"}]},
+	{"type": "event", "name": "Transfer", "inputs": [{"name": "from", "type": "address", "indexed": true}, {"name": "to", "type": "address", "indexed": true}, {"name": "value", "type": "uint256"}]}
+]`
+
+// withMetadata appends the CBOR encoded metadata, as solc does
+func withMetadata(code []byte, hash byte) []byte {
+	metadata := append([]byte{0xa2, 0x64, 'i', 'p', 'f', 's', 0x42}, hash, hash)
+	code = append(common.CopyBytes(code), metadata...)
+	return append(code, 0, byte(len(metadata)))
+}
+
+func TestStripMetadata(t *testing.T) {
+	assert := assert.New(t)
+
+	code := []byte{0x60, 0x80, 0x60, 0x40}
+	assert.Equal(code, stripMetadata(withMetadata(code, 1)))
+	assert.Equal(code, stripMetadata(code))
+	assert.Equal([]byte{0x01}, stripMetadata([]byte{0x01}))
+}
+
+func TestMatchCompiledContract(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	code := []byte{0x60, 0x80, 0x60, 0x40, 0x52, 0x7f, 0x00, 0x00, 0x00}
+	compiled := func(code []byte) *compiledContract {
+		c := &compiledContract{ABI: json.RawMessage(testContractABI)}
+		c.EVM.DeployedBytecode.Object = hex.EncodeToString(code)
+		return c
+	}
+	contracts := map[string]*compiledContract{
+		"Other": compiled([]byte{0x60, 0x01}),
+		"Token": compiled(withMetadata(code, 1)),
+	}
+
+	name, _, fullMatch, err := matchCompiledContract(contracts, "", withMetadata(code, 1))
+	require.Nil(err)
+	assert.Equal("Token", name)
+	assert.True(fullMatch)
+
+	// A different source, e.g. with different comments, only changes the metadata hash
+	name, _, fullMatch, err = matchCompiledContract(contracts, "Token", withMetadata(code, 2))
+	require.Nil(err)
+	assert.Equal("Token", name)
+	assert.False(fullMatch)
+
+	_, _, _, err = matchCompiledContract(contracts, "Other", withMetadata(code, 1))
+	assert.NotNil(err)
+	_, _, _, err = matchCompiledContract(contracts, "Unknown", withMetadata(code, 1))
+	assert.NotNil(err)
+
+	// The immutables are filled in on the deployment
+	deployed := withMetadata(code, 1)
+	deployed[6], deployed[7], deployed[8] = 0xaa, 0xbb, 0xcc
+	_, _, _, err = matchCompiledContract(contracts, "Token", deployed)
+	assert.NotNil(err)
+	contracts["Token"].EVM.DeployedBytecode.ImmutableReferences = map[string][]struct{ Start, Length int }{"3": {{Start: 6, Length: 3}}}
+	_, _, fullMatch, err = matchCompiledContract(contracts, "Token", deployed)
+	require.Nil(err)
+	assert.True(fullMatch)
+
+	// The libraries are not linked
+	contracts["Token"].EVM.DeployedBytecode.Object = "6080__$1234$__"
+	_, _, _, err = matchCompiledContract(contracts, "Token", deployed)
+	assert.NotNil(err)
+}
+
+func TestSolcCompiler(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The fake solc is a shell script")
+	}
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "solc")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	output := `{"errors": [{"severity": "warning", "formattedMessage": "unused variable"}],
+		"contracts": {"contract.sol": {"Token": {"abi": [], "evm": {"deployedBytecode": {"object": "6080"}}}}}}`
+	script := "#!/bin/sh\n" +
+		"if [ \"$1\" = \"--version\" ]; then echo 'solc, the solidity compiler commandline interface'; echo 'Version: 0.8.17+commit.8df45f5f.Linux.g++'; exit 0; fi\n" +
+		"cat > " + filepath.Join(dir, "input.json") + "\n" +
+		"echo '" + output + "'\n"
+	solc := filepath.Join(dir, "solc")
+	require.Nil(ioutil.WriteFile(solc, []byte(script), 0755))
+
+	compiler := &solcCompiler{path: solc, timeout: 10 * time.Second}
+	version, err := compiler.version(context.Background())
+	require.Nil(err)
+	assert.Equal("0.8.17+commit.8df45f5f.Linux.g++", version)
+
+	contracts, err := compiler.compile(context.Background(), "contract Token {}", true, 0, "london")
+	require.Nil(err)
+	require.Contains(contracts, "Token")
+	assert.Equal("6080", contracts["Token"].EVM.DeployedBytecode.Object)
+
+	raw, err := ioutil.ReadFile(filepath.Join(dir, "input.json"))
+	require.Nil(err)
+	input := &solcInput{}
+	require.Nil(json.Unmarshal(raw, input))
+	assert.Equal("contract Token {}", input.Sources[contractSourceFile]["content"])
+	assert.Equal(solcOptimizer{Enabled: true, Runs: 200}, input.Settings.Optimizer)
+	assert.Equal("london", input.Settings.EVMVersion)
+
+	// The compilation errors are reported
+	output = `{"errors": [{"severity": "error", "formattedMessage": "ParserError: Expected semicolon"}]}`
+	require.Nil(ioutil.WriteFile(solc, []byte("#!/bin/sh\necho '"+output+"'\n"), 0755))
+	_, err = compiler.compile(context.Background(), "contract Token {", false, 0, "")
+	require.NotNil(err)
+	assert.Contains(err.Error(), "ParserError")
+}
+
+func TestDecodeTransaction(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chain := blockchain.CreateTestChain()
+	cache, err := lru.New(10)
+	require.Nil(err)
+	service := &ThetaRPCService{chain: chain, contractABIs: cache}
+
+	token := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := common.HexToAddress("0x2e833968e5bb786ae419c4d13189fb081cc43bab")
+	input := append(crypto.Keccak256([]byte("transfer(address,uint256)"))[:4], common.LeftPadBytes(to.Bytes(), 32)...)
+	input = append(input, common.LeftPadBytes([]byte{100}, 32)...)
+	transfer := &types.Log{
+		Address: token,
+		Topics:  []common.Hash{crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")), {}, common.BytesToHash(to.Bytes())},
+		Data:    common.LeftPadBytes([]byte{100}, 32),
+	}
+	other := &types.Log{Address: to, Topics: []common.Hash{{}}}
+	newResult := func() *GetTransactionResult {
+		return &GetTransactionResult{
+			Tx:      &types.SmartContractTx{To: types.TxOutput{Address: token}, Data: input},
+			Receipt: &blockchain.TxReceiptEntry{Logs: []*types.Log{other, transfer}},
+		}
+	}
+
+	// Not verified yet
+	result := newResult()
+	service.decodeTransaction(result, nil)
+	assert.Nil(result.DecodedInput)
+	assert.Nil(result.DecodedLogs)
+
+	require.Nil(chain.AddVerifiedContract(&blockchain.VerifiedContract{Address: token, Name: "Token", ABI: testContractABI}))
+	cache.Remove(token)
+
+	result = newResult()
+	service.decodeTransaction(result, nil)
+	require.NotNil(result.DecodedInput)
+	assert.Equal("transfer", result.DecodedInput.Method)
+	assert.Equal(to, result.DecodedInput.Args[0].Value)
+	require.Equal(2, len(result.DecodedLogs))
+	assert.Nil(result.DecodedLogs[0])
+	assert.Equal("Transfer", result.DecodedLogs[1].Event)
+	assert.Equal("100", result.DecodedLogs[1].Args[2].Value)
+}
+
+func TestRequestedABI(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chain := blockchain.CreateTestChain()
+	service := &ThetaRPCService{chain: chain}
+
+	token := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	approval := json.RawMessage(`{"type": "event", "name": "Approval", "inputs": [{"name": "owner", "type": "address", "indexed": true}, {"name": "value", "type": "uint256"}]}`)
+	approvalLog := &types.Log{
+		Address: token,
+		Topics:  []common.Hash{crypto.Keccak256Hash([]byte("Approval(address,uint256)")), {}},
+		Data:    common.LeftPadBytes([]byte{7}, 32),
+	}
+	transferLog := &types.Log{
+		Address: token,
+		Topics:  []common.Hash{crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")), {}, {}},
+		Data:    common.LeftPadBytes([]byte{100}, 32),
+	}
+
+	// A fragment decodes the logs without the contract registry
+	requested, err := service.requestedABI(approval, "")
+	require.Nil(err)
+	result := &GetTransactionResult{
+		Tx:      &types.SmartContractTx{To: types.TxOutput{Address: token}},
+		Receipt: &blockchain.TxReceiptEntry{Logs: []*types.Log{approvalLog, transferLog}},
+	}
+	service.decodeTransaction(result, requested)
+	require.Equal(2, len(result.DecodedLogs))
+	assert.Equal("Approval", result.DecodedLogs[0].Event)
+	assert.Equal("7", result.DecodedLogs[0].Args[1].Value)
+	assert.Nil(result.DecodedLogs[1])
+
+	_, err = service.requestedABI(json.RawMessage(`{"type": "event", "name": "Bad", "inputs": [{"name": "x", "type": "uint7"}]}`), "")
+	assert.NotNil(err)
+	_, err = service.requestedABI(nil, token.Hex())
+	assert.Equal(errContractRegistryDisabled, err)
+
+	// A referenced verified contract adds its ABI to the fragment
+	cache, err := lru.New(10)
+	require.Nil(err)
+	service.contractABIs = cache
+	_, err = service.requestedABI(nil, token.Hex())
+	assert.NotNil(err)
+	require.Nil(chain.AddVerifiedContract(&blockchain.VerifiedContract{Address: token, Name: "Token", ABI: testContractABI}))
+	cache.Purge()
+	requested, err = service.requestedABI(approval, token.Hex())
+	require.Nil(err)
+	assert.NotNil(service.decodeLog(token, approvalLog.Topics, approvalLog.Data, requested))
+	assert.NotNil(service.decodeLog(token, transferLog.Topics, transferLog.Data, requested))
+
+	// The requested ABI decodes the logs of any contract
+	other := common.HexToAddress("0x2e833968e5bb786ae419c4d13189fb081cc43bab")
+	assert.NotNil(service.decodeLog(other, transferLog.Topics, transferLog.Data, requested))
+	assert.Nil(service.decodeLog(other, transferLog.Topics, transferLog.Data, nil))
+}
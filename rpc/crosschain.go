@@ -0,0 +1,172 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+)
+
+// maxCrossChainMessagesPerQuery caps the number of the cross-chain messages returned by one query
+const maxCrossChainMessagesPerQuery = 100
+
+// ------------------------------ GetCrossChainChannel -----------------------------------
+
+type GetCrossChainChannelArgs struct {
+	PeerChainID string `json:"peer_chain_id"`
+}
+
+type GetCrossChainChannelResult struct {
+	BlockHeight common.JSONUint64       `json:"block_height"`
+	Channel     *core.CrossChainChannel `json:"channel"`
+}
+
+func (t *ThetaRPCService) GetCrossChainChannel(args *GetCrossChainChannelArgs, result *GetCrossChainChannelResult) (err error) {
+	defer t.guard("GetCrossChainChannel", &err)()
+
+	finalizedView, err := t.ledger.GetFinalizedSnapshot()
+	if err != nil {
+		return err
+	}
+
+	result.BlockHeight = common.JSONUint64(finalizedView.Height())
+	result.Channel = finalizedView.GetCrossChainChannel(args.PeerChainID)
+
+	return nil
+}
+
+// ------------------------------ GetCrossChainMessages -----------------------------------
+
+type GetCrossChainMessagesArgs struct {
+	TargetChainID string            `json:"target_chain_id"`
+	Height        common.JSONUint64 `json:"height"` // the finalized block to prove the messages against, 0 for the latest
+	StartNonce    common.JSONUint64 `json:"start_nonce"`
+	Limit         common.JSONUint64 `json:"limit"`
+}
+
+type CrossChainMessageWithProof struct {
+	Message *core.CrossChainMessage `json:"message"`
+	Proof   []common.Bytes          `json:"proof"` // trie nodes from the state root to the message
+}
+
+// GetCrossChainMessagesResult carries the messages sent to the target chain with their proofs against the
+// state root of a finalized block. A relayer asks for the proofs against the height of the latest checkpoint
+// anchored to the target chain, and delivers the messages to the target chain in the nonce order.
+type GetCrossChainMessagesResult struct {
+	BlockHeight common.JSONUint64             `json:"block_height"`
+	BlockHash   common.Hash                   `json:"block_hash"`
+	StateHash   common.Hash                   `json:"state_hash"`
+	Messages    []*CrossChainMessageWithProof `json:"messages"`
+}
+
+func (t *ThetaRPCService) GetCrossChainMessages(args *GetCrossChainMessagesArgs, result *GetCrossChainMessagesResult) (err error) {
+	defer t.guard("GetCrossChainMessages", &err)()
+
+	view, block, err := t.getFinalizedView(uint64(args.Height))
+	if err != nil {
+		return err
+	}
+
+	limit := uint64(args.Limit)
+	if limit == 0 || limit > maxCrossChainMessagesPerQuery {
+		limit = maxCrossChainMessagesPerQuery
+	}
+
+	result.BlockHeight = common.JSONUint64(block.Height)
+	result.BlockHash = block.Hash()
+	result.StateHash = block.StateHash
+	result.Messages = []*CrossChainMessageWithProof{}
+
+	sendNonce := view.GetCrossChainChannel(args.TargetChainID).SendNonce
+	for nonce := uint64(args.StartNonce); nonce < sendNonce && uint64(len(result.Messages)) < limit; nonce++ {
+		message := view.GetSentCrossChainMessage(args.TargetChainID, nonce)
+		if message == nil {
+			return fmt.Errorf("Cross-chain message to %v with nonce %v is missing", args.TargetChainID, nonce)
+		}
+		proof, err := view.ProveSentCrossChainMessage(args.TargetChainID, nonce)
+		if err != nil {
+			return err
+		}
+		result.Messages = append(result.Messages, &CrossChainMessageWithProof{
+			Message: message,
+			Proof:   proof,
+		})
+	}
+
+	return nil
+}
+
+// ------------------------------ GetReceivedCrossChainMessages -----------------------------------
+
+type GetReceivedCrossChainMessagesArgs struct {
+	SourceChainID string            `json:"source_chain_id"`
+	StartNonce    common.JSONUint64 `json:"start_nonce"`
+	Limit         common.JSONUint64 `json:"limit"`
+}
+
+type GetReceivedCrossChainMessagesResult struct {
+	BlockHeight common.JSONUint64         `json:"block_height"`
+	Messages    []*core.CrossChainMessage `json:"messages"`
+}
+
+func (t *ThetaRPCService) GetReceivedCrossChainMessages(args *GetReceivedCrossChainMessagesArgs, result *GetReceivedCrossChainMessagesResult) (err error) {
+	defer t.guard("GetReceivedCrossChainMessages", &err)()
+
+	finalizedView, err := t.ledger.GetFinalizedSnapshot()
+	if err != nil {
+		return err
+	}
+
+	limit := uint64(args.Limit)
+	if limit == 0 || limit > maxCrossChainMessagesPerQuery {
+		limit = maxCrossChainMessagesPerQuery
+	}
+
+	result.BlockHeight = common.JSONUint64(finalizedView.Height())
+	result.Messages = []*core.CrossChainMessage{}
+
+	receiveNonce := finalizedView.GetCrossChainChannel(args.SourceChainID).ReceiveNonce
+	for nonce := uint64(args.StartNonce); nonce < receiveNonce && uint64(len(result.Messages)) < limit; nonce++ {
+		message := finalizedView.GetReceivedCrossChainMessage(args.SourceChainID, nonce)
+		if message == nil {
+			return fmt.Errorf("Cross-chain message from %v with nonce %v is missing", args.SourceChainID, nonce)
+		}
+		result.Messages = append(result.Messages, message)
+	}
+
+	return nil
+}
+
+// getFinalizedView returns the state of the finalized block at the given height, or the latest finalized
+// block if the height is 0
+func (t *ThetaRPCService) getFinalizedView(height uint64) (*state.StoreView, *core.ExtendedBlock, error) {
+	if height == 0 {
+		finalizedView, err := t.ledger.GetFinalizedSnapshot()
+		if err != nil {
+			return nil, nil, err
+		}
+		stateHash := finalizedView.Hash()
+		for _, b := range t.chain.FindBlocksByHeight(finalizedView.Height()) {
+			if b.Status.IsFinalized() && b.StateHash == stateHash {
+				return finalizedView, b, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("No finalized block found at height %v", finalizedView.Height())
+	}
+
+	deliveredView, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, b := range t.chain.FindBlocksByHeight(height) {
+		if b.Status.IsFinalized() {
+			view := state.NewStoreView(height, b.StateHash, deliveredView.GetDB())
+			if view == nil { // might have been pruned
+				return nil, nil, fmt.Errorf("the state at height %v is not available, it might have been pruned", height)
+			}
+			return view, b, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("No finalized block found at height %v", height)
+}
@@ -0,0 +1,127 @@
+package rpc
+
+import (
+	"errors"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/consensus"
+)
+
+// maxEENVoteCarrierDistance is the max number of the checkpoint intervals between a checkpoint and the
+// block carrying the aggregated elite edge node votes for it
+const maxEENVoteCarrierDistance = 3
+
+// ------------------------------ GetEliteEdgeNodeVoteDiagnostics -----------------------------------
+
+type GetEliteEdgeNodeVoteDiagnosticsArgs struct {
+	Address string            `json:"address"` // address of the elite edge node
+	Limit   common.JSONUint64 `json:"limit"`   // max number of the recent votes returned, 0 for all the kept ones
+}
+
+// EENVoteStatus reports how a vote from the elite edge node was handled by this node, and whether it made it
+// into the aggregated votes of a finalized block. Only the aggregated votes are rewarded.
+type EENVoteStatus struct {
+	Vote             *consensus.EENVoteDiagnostic `json:"vote"`
+	CheckpointHeight common.JSONUint64            `json:"checkpoint_height"`
+	Aggregated       bool                         `json:"aggregated"`
+	IncludedIn       common.JSONUint64            `json:"included_in"` // height of the block carrying the aggregated votes
+	Pending          bool                         `json:"pending"`     // no finalized block has carried votes for the checkpoint yet
+}
+
+type GetEliteEdgeNodeVoteDiagnosticsResult struct {
+	Address          common.Address    `json:"address"`
+	Votes            []*EENVoteStatus  `json:"votes"` // latest first
+	NumAccepted      common.JSONUint64 `json:"num_accepted"`
+	NumAggregated    common.JSONUint64 `json:"num_aggregated"`
+	RejectionReasons map[string]uint64 `json:"rejection_reasons"` // reason -> number of the rejected votes
+}
+
+// eenVoteCarrier is the finalized block carrying the aggregated elite edge node votes for a checkpoint
+type eenVoteCarrier struct {
+	checkpointHeight uint64
+	height           uint64 // 0 if not found
+	pending          bool
+	signers          map[common.Address]bool
+}
+
+func (t *ThetaRPCService) GetEliteEdgeNodeVoteDiagnostics(args *GetEliteEdgeNodeVoteDiagnosticsArgs, result *GetEliteEdgeNodeVoteDiagnosticsResult) (err error) {
+	defer t.guard("GetEliteEdgeNodeVoteDiagnostics", &err)()
+
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	address := common.HexToAddress(args.Address)
+
+	result.Address = address
+	result.Votes = []*EENVoteStatus{}
+	result.RejectionReasons = make(map[string]uint64)
+
+	carriers := make(map[common.Hash]*eenVoteCarrier) // checkpoint -> carrier
+	for _, diagnostic := range t.consensus.GetEliteEdgeNodeVoteDiagnostics(address, int(args.Limit)) {
+		status := &EENVoteStatus{Vote: diagnostic}
+		result.Votes = append(result.Votes, status)
+		if !diagnostic.Accepted {
+			result.RejectionReasons[diagnostic.Reason]++
+			continue
+		}
+		result.NumAccepted++
+
+		carrier, ok := carriers[diagnostic.Block]
+		if !ok {
+			carrier = t.findEENVoteCarrier(diagnostic.Block)
+			carriers[diagnostic.Block] = carrier
+		}
+		if carrier == nil {
+			continue
+		}
+		status.CheckpointHeight = common.JSONUint64(carrier.checkpointHeight)
+		status.Pending = carrier.pending
+		if carrier.signers[address] {
+			status.Aggregated = true
+			status.IncludedIn = common.JSONUint64(carrier.height)
+			result.NumAggregated++
+		}
+	}
+
+	return nil
+}
+
+// findEENVoteCarrier finds the finalized block carrying the aggregated elite edge node votes for the
+// checkpoint. It returns nil if the checkpoint block is unknown.
+func (t *ThetaRPCService) findEENVoteCarrier(checkpoint common.Hash) *eenVoteCarrier {
+	block, err := t.chain.FindBlock(checkpoint)
+	if err != nil {
+		return nil
+	}
+
+	carrier := &eenVoteCarrier{
+		checkpointHeight: block.Height,
+		signers:          make(map[common.Address]bool),
+	}
+	lastFinalizedHeight := t.consensus.GetLastFinalizedBlock().Height
+	interval := uint64(common.CheckpointInterval)
+	for i := uint64(1); i <= maxEENVoteCarrierDistance; i++ {
+		height := block.Height + i*interval
+		if height > lastFinalizedHeight {
+			carrier.pending = true
+			break
+		}
+		for _, b := range t.chain.FindBlocksByHeight(height) {
+			if !b.Status.IsFinalized() {
+				continue
+			}
+			votes := b.EliteEdgeNodeVotes
+			if votes == nil || votes.Block != checkpoint {
+				break
+			}
+			carrier.height = height
+			for j, signer := range votes.Addresses {
+				if j < len(votes.Multiplies) && votes.Multiplies[j] > 0 {
+					carrier.signers[signer] = true
+				}
+			}
+			return carrier
+		}
+	}
+	return carrier
+}
@@ -0,0 +1,26 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+)
+
+// errCodeServer is the JSON-RPC 2.0 error code for the errors of the RPC methods
+const errCodeServer = -32000
+
+// newResultCodeError returns the JSON-RPC error with the message and the result code
+func newResultCodeError(code result.ErrorCode, message string) error {
+	rpcErr := jsonrpc2.NewError(errCodeServer, message)
+	rpcErr.Data = result.ErrorData{ResultCode: code}
+	return rpcErr
+}
+
+func newNotFoundError(format string, args ...interface{}) error {
+	return newResultCodeError(result.CodeNotFound, fmt.Sprintf(format, args...))
+}
+
+func newInsufficientFundError(format string, args ...interface{}) error {
+	return newResultCodeError(result.CodeInsufficientFund, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,49 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common/result"
+	"github.com/thetatoken/theta/mempool"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+)
+
+func TestResultCodeError(t *testing.T) {
+	assert := assert.New(t)
+
+	// The error is relayed to the client as the JSON-RPC error object, with the result code in the data
+	err := newInsufficientFundError("Source balance is %v, but the stake and fee require %v", 1, 2)
+	rpcErr := jsonrpc2.ServerError(errors.New(err.Error()))
+	assert.Equal(errCodeServer, rpcErr.Code)
+	assert.Equal("Source balance is 1, but the stake and fee require 2", rpcErr.Message)
+
+	raw, _ := json.Marshal(rpcErr.Data)
+	data := result.ErrorData{}
+	assert.Nil(json.Unmarshal(raw, &data))
+	assert.Equal(result.CodeInsufficientFund, data.ResultCode)
+}
+
+func TestExplainBroadcastError(t *testing.T) {
+	assert := assert.New(t)
+
+	service := &ThetaRPCService{chain: &blockchain.Chain{ChainID: "privatenet"}}
+
+	err := service.explainBroadcastError(&mempool.TxScreeningError{Code: result.CodeInvalidSequence, Message: "ValidateInputAdvanced: Got 3, expected 2. (acc.seq=1)"})
+	rpcErr, ok := err.(*jsonrpc2.Error)
+	assert.True(ok)
+	assert.Equal("ValidateInputAdvanced: Got 3, expected 2. (acc.seq=1)", rpcErr.Message)
+	assert.Equal(result.ErrorData{ResultCode: result.CodeInvalidSequence}, rpcErr.Data)
+
+	err = service.explainBroadcastError(&mempool.TxScreeningError{Code: result.CodeInvalidSignature, Message: "Signature verification failed"})
+	rpcErr, ok = err.(*jsonrpc2.Error)
+	assert.True(ok)
+	assert.Contains(rpcErr.Message, "signed with the chain ID privatenet")
+	assert.Equal(result.ErrorData{ResultCode: result.CodeInvalidSignature}, rpcErr.Data)
+
+	// The errors without a result code are relayed as is
+	assert.Equal(mempool.DuplicateTxError, service.explainBroadcastError(mempool.DuplicateTxError))
+}
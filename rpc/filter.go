@@ -0,0 +1,391 @@
+package rpc
+
+import (
+	"crypto/rand"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/vm/abi"
+)
+
+//
+// The filters are the polling alternative to the subscriptions, compatible with the eth_newFilter family
+// of the Ethereum JSON-RPC API. A client installs a filter, polls GetFilterChanges for the events since
+// the previous poll, and uninstalls the filter when done. The filters not polled for a while are removed.
+//
+
+const (
+	filterCleanupInterval = 10 * time.Second
+
+	// maxFilterBlocksPerPoll limits the number of blocks returned by a single poll of a block filter.
+	// The rest are returned by the following polls.
+	maxFilterBlocksPerPoll = 1000
+)
+
+type filterType string
+
+const (
+	filterTypeLog       filterType = "log"
+	filterTypeBlock     filterType = "block"
+	filterTypePendingTx filterType = "pending_tx"
+)
+
+// eventFilter is a filter installed by a client, along with the progress of its polling
+type eventFilter struct {
+	mu *sync.Mutex
+
+	id         string
+	typ        filterType
+	criteria   *blockchain.LogFilter
+	fromHeight uint64
+	toHeight   uint64 // 0 means no upper bound
+
+	lastHeight uint64          // the last finalized height whose events have been returned
+	seenTxs    map[string]bool // the pending txs that have been returned
+	lastPolled time.Time
+}
+
+// filterManager keeps the installed filters and removes the ones that have timed out
+type filterManager struct {
+	mu         *sync.Mutex
+	filters    map[string]*eventFilter
+	timeout    time.Duration
+	maxFilters int
+}
+
+func newFilterManager(timeout time.Duration, maxFilters int) *filterManager {
+	return &filterManager{
+		mu:         &sync.Mutex{},
+		filters:    make(map[string]*eventFilter),
+		timeout:    timeout,
+		maxFilters: maxFilters,
+	}
+}
+
+func newFilterID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return common.Bytes2Hex(id), nil
+}
+
+// install adds the filter and assigns it an ID
+func (fm *filterManager) install(f *eventFilter, now time.Time) (string, error) {
+	id, err := newFilterID()
+	if err != nil {
+		return "", err
+	}
+
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.maxFilters > 0 && len(fm.filters) >= fm.maxFilters {
+		return "", errors.New("too many filters installed, uninstall the unused filters or retry later")
+	}
+	f.mu = &sync.Mutex{}
+	f.id = "0x" + id
+	f.lastPolled = now
+	fm.filters[f.id] = f
+	return f.id, nil
+}
+
+// get returns the filter with the given ID, and resets its timeout
+func (fm *filterManager) get(id string, now time.Time) (*eventFilter, bool) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	f, ok := fm.filters[id]
+	if !ok {
+		return nil, false
+	}
+	f.lastPolled = now
+	return f, true
+}
+
+func (fm *filterManager) uninstall(id string) bool {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	_, ok := fm.filters[id]
+	delete(fm.filters, id)
+	return ok
+}
+
+// trim removes the filters not polled within the timeout
+func (fm *filterManager) trim(now time.Time) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	for id, f := range fm.filters {
+		if now.Sub(f.lastPolled) > fm.timeout {
+			logger.Debugf("Removing timed out filter %v", id)
+			delete(fm.filters, id)
+		}
+	}
+}
+
+func (fm *filterManager) size() int {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	return len(fm.filters)
+}
+
+// newPendingTxs returns the pending txs not returned before, and forgets the ones no longer pending
+func (f *eventFilter) newPendingTxs(pending []string) []string {
+	txs := []string{}
+	seenTxs := make(map[string]bool, len(pending))
+	for _, hash := range pending {
+		if !f.seenTxs[hash] {
+			txs = append(txs, hash)
+		}
+		seenTxs[hash] = true
+	}
+	f.seenTxs = seenTxs
+	return txs
+}
+
+func (t *ThetaRPCServer) filterCleanupLoop() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(filterCleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+			t.filters.trim(time.Now())
+		}
+	}
+}
+
+func newLogFilterCriteria(addresses []string, topics [][]string) *blockchain.LogFilter {
+	filter := &blockchain.LogFilter{}
+	for _, address := range addresses {
+		filter.Addresses = append(filter.Addresses, common.HexToAddress(address))
+	}
+	for _, alternatives := range topics {
+		hashes := []common.Hash{}
+		for _, topic := range alternatives {
+			hashes = append(hashes, common.HexToHash(topic))
+		}
+		filter.Topics = append(filter.Topics, hashes)
+	}
+	return filter
+}
+
+// newLogEntries converts the given logs, decoding them with the requested ABI, if any, or the ABIs of the
+// verified contracts
+func (t *ThetaRPCService) newLogEntries(logs []*blockchain.FilteredLog, requested *abi.ABI) []*LogEntry {
+	entries := []*LogEntry{}
+	for _, log := range logs {
+		entries = append(entries, &LogEntry{
+			Address:     log.Address,
+			Topics:      log.Topics,
+			Data:        log.Data,
+			BlockHash:   log.BlockHash,
+			BlockHeight: common.JSONUint64(log.BlockHeight),
+			TxHash:      log.TxHash,
+			TxIndex:     common.JSONUint64(log.TxIndex),
+			LogIndex:    common.JSONUint64(log.LogIndex),
+			Decoded:     t.decodeLog(log.Address, log.Topics, log.Data, requested),
+		})
+	}
+	return entries
+}
+
+// ------------------------------ NewFilter -----------------------------------
+
+type NewFilterArgs struct {
+	Start     common.JSONUint64 `json:"start"` // defaults to the block following the latest finalized block
+	End       common.JSONUint64 `json:"end"`   // defaults to no upper bound
+	Addresses []string          `json:"addresses"`
+	Topics    [][]string        `json:"topics"` // topics by position, any of the alternatives at a position matches
+}
+
+type NewFilterResult struct {
+	FilterID string `json:"filter_id"`
+}
+
+func (t *ThetaRPCService) NewFilter(args *NewFilterArgs, result *NewFilterResult) (err error) {
+	defer t.guard("NewFilter", &err)()
+
+	start := uint64(args.Start)
+	end := uint64(args.End)
+	if start == 0 {
+		start = t.consensus.GetLastFinalizedBlock().Height + 1
+	}
+	if end != 0 && end < start {
+		return errors.New("start height must not be greater than end height")
+	}
+
+	f := &eventFilter{
+		typ:        filterTypeLog,
+		criteria:   newLogFilterCriteria(args.Addresses, args.Topics),
+		fromHeight: start,
+		toHeight:   end,
+		lastHeight: start - 1,
+	}
+	result.FilterID, err = t.filters.install(f, time.Now())
+	return err
+}
+
+// ------------------------------ NewBlockFilter -----------------------------------
+
+type NewBlockFilterArgs struct{}
+
+type NewBlockFilterResult struct {
+	FilterID string `json:"filter_id"`
+}
+
+func (t *ThetaRPCService) NewBlockFilter(args *NewBlockFilterArgs, result *NewBlockFilterResult) (err error) {
+	defer t.guard("NewBlockFilter", &err)()
+
+	f := &eventFilter{
+		typ:        filterTypeBlock,
+		lastHeight: t.consensus.GetLastFinalizedBlock().Height,
+	}
+	result.FilterID, err = t.filters.install(f, time.Now())
+	return err
+}
+
+// ------------------------------ NewPendingTransactionFilter -----------------------------------
+
+type NewPendingTransactionFilterArgs struct{}
+
+type NewPendingTransactionFilterResult struct {
+	FilterID string `json:"filter_id"`
+}
+
+func (t *ThetaRPCService) NewPendingTransactionFilter(args *NewPendingTransactionFilterArgs, result *NewPendingTransactionFilterResult) (err error) {
+	defer t.guard("NewPendingTransactionFilter", &err)()
+
+	f := &eventFilter{
+		typ: filterTypePendingTx,
+	}
+	f.newPendingTxs(t.mempool.GetCandidateTransactionHashes())
+	result.FilterID, err = t.filters.install(f, time.Now())
+	return err
+}
+
+// ------------------------------ GetFilterChanges -----------------------------------
+
+type GetFilterChangesArgs struct {
+	FilterID string `json:"filter_id"`
+}
+
+// GetFilterChangesResult holds the events since the previous poll. Only the field of the type of the filter is set.
+type GetFilterChangesResult struct {
+	Type        string        `json:"type"`
+	Logs        []*LogEntry   `json:"logs,omitempty"`
+	BlockHashes []common.Hash `json:"block_hashes,omitempty"`
+	TxHashes    []string      `json:"tx_hashes,omitempty"`
+}
+
+func (t *ThetaRPCService) GetFilterChanges(args *GetFilterChangesArgs, result *GetFilterChangesResult) (err error) {
+	defer t.guard("GetFilterChanges", &err)()
+
+	f, ok := t.filters.get(args.FilterID, time.Now())
+	if !ok {
+		return errors.New("filter not found")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	result.Type = string(f.typ)
+	finalizedHeight := t.consensus.GetLastFinalizedBlock().Height
+	switch f.typ {
+	case filterTypeLog:
+		end := finalizedHeight
+		if f.toHeight != 0 && end > f.toHeight {
+			end = f.toHeight
+		}
+		if end-f.lastHeight > blockchain.MaxLogsQueryWindow {
+			end = f.lastHeight + blockchain.MaxLogsQueryWindow
+		}
+		result.Logs = []*LogEntry{}
+		if end <= f.lastHeight {
+			return nil
+		}
+		logs, err := t.chain.GetLogs(f.lastHeight+1, end, f.criteria)
+		if err != nil {
+			return err
+		}
+		result.Logs = t.newLogEntries(logs, nil)
+		f.lastHeight = end
+	case filterTypeBlock:
+		result.BlockHashes = []common.Hash{}
+		for f.lastHeight < finalizedHeight && len(result.BlockHashes) < maxFilterBlocksPerPoll {
+			block := t.chain.FindFinalizedBlockByHeight(f.lastHeight + 1)
+			if block == nil {
+				break
+			}
+			result.BlockHashes = append(result.BlockHashes, block.Hash())
+			f.lastHeight++
+		}
+	case filterTypePendingTx:
+		result.TxHashes = f.newPendingTxs(t.mempool.GetCandidateTransactionHashes())
+	}
+	return nil
+}
+
+// ------------------------------ GetFilterLogs -----------------------------------
+
+type GetFilterLogsArgs struct {
+	FilterID string `json:"filter_id"`
+}
+
+type GetFilterLogsResult struct {
+	Logs []*LogEntry `json:"logs"`
+}
+
+// GetFilterLogs returns all the logs matching a log filter, regardless of the polling progress.
+func (t *ThetaRPCService) GetFilterLogs(args *GetFilterLogsArgs, result *GetFilterLogsResult) (err error) {
+	defer t.guard("GetFilterLogs", &err)()
+
+	f, ok := t.filters.get(args.FilterID, time.Now())
+	if !ok {
+		return errors.New("filter not found")
+	}
+	if f.typ != filterTypeLog {
+		return errors.New("not a log filter")
+	}
+
+	end := t.consensus.GetLastFinalizedBlock().Height
+	if f.toHeight != 0 && end > f.toHeight {
+		end = f.toHeight
+	}
+	result.Logs = []*LogEntry{}
+	if end < f.fromHeight {
+		return nil
+	}
+	logs, err := t.chain.GetLogs(f.fromHeight, end, f.criteria)
+	if err != nil {
+		return err
+	}
+	result.Logs = t.newLogEntries(logs, nil)
+	return nil
+}
+
+// ------------------------------ UninstallFilter -----------------------------------
+
+type UninstallFilterArgs struct {
+	FilterID string `json:"filter_id"`
+}
+
+type UninstallFilterResult struct {
+	Uninstalled bool `json:"uninstalled"`
+}
+
+func (t *ThetaRPCService) UninstallFilter(args *UninstallFilterArgs, result *UninstallFilterResult) (err error) {
+	defer t.guard("UninstallFilter", &err)()
+
+	result.Uninstalled = t.filters.uninstall(args.FilterID)
+	return nil
+}
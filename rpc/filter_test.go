@@ -0,0 +1,98 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/consensus"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/store/database/backend"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+func TestFilterManager(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	now := time.Now()
+	fm := newFilterManager(time.Minute, 2)
+
+	id1, err := fm.install(&eventFilter{typ: filterTypeBlock}, now)
+	require.Nil(err)
+	id2, err := fm.install(&eventFilter{typ: filterTypePendingTx}, now)
+	require.Nil(err)
+	assert.NotEqual(id1, id2)
+
+	// The number of filters is limited
+	_, err = fm.install(&eventFilter{typ: filterTypeBlock}, now)
+	assert.NotNil(err)
+
+	// Polling a filter resets its timeout
+	_, ok := fm.get(id1, now.Add(50*time.Second))
+	assert.True(ok)
+	fm.trim(now.Add(90 * time.Second))
+	_, ok = fm.get(id1, now.Add(90*time.Second))
+	assert.True(ok)
+	_, ok = fm.get(id2, now.Add(90*time.Second))
+	assert.False(ok)
+	assert.Equal(1, fm.size())
+
+	assert.True(fm.uninstall(id1))
+	assert.False(fm.uninstall(id1))
+	assert.Equal(0, fm.size())
+}
+
+func TestPendingTxFilter(t *testing.T) {
+	assert := assert.New(t)
+
+	f := &eventFilter{typ: filterTypePendingTx}
+	assert.Equal([]string{"0x01", "0x02"}, f.newPendingTxs([]string{"0x01", "0x02"}))
+	assert.Equal([]string{"0x03"}, f.newPendingTxs([]string{"0x02", "0x03"}))
+	assert.Equal([]string{}, f.newPendingTxs([]string{"0x02", "0x03"}))
+
+	// A tx dropped and re-added to the mempool is returned again
+	assert.Equal([]string{"0x01"}, f.newPendingTxs([]string{"0x01", "0x03"}))
+}
+
+func TestFilterChanges(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	core.ResetTestBlocks()
+	chain := blockchain.CreateTestChain()
+	nodeKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(err)
+	engine := consensus.NewConsensusEngine(nodeKey, kvstore.NewKVStore(backend.NewMemDatabase()), chain, nil, nil)
+	service := &ThetaRPCService{
+		chain:     chain,
+		consensus: engine,
+		filters:   newFilterManager(time.Minute, 10),
+		breakers:  NewCircuitBreakers(0.5, 1, time.Minute, time.Minute, 0, ""),
+	}
+
+	newResult := &NewBlockFilterResult{}
+	require.Nil(service.NewBlockFilter(&NewBlockFilterArgs{}, newResult))
+
+	result := &GetFilterChangesResult{}
+	require.Nil(service.GetFilterChanges(&GetFilterChangesArgs{FilterID: newResult.FilterID}, result))
+	assert.Equal(string(filterTypeBlock), result.Type)
+	assert.Equal(0, len(result.BlockHashes))
+
+	// A log filter has no logs before the blocks are finalized
+	newLogResult := &NewFilterResult{}
+	require.Nil(service.NewFilter(&NewFilterArgs{Addresses: []string{"0x1111111111111111111111111111111111111111"}}, newLogResult))
+	result = &GetFilterChangesResult{}
+	require.Nil(service.GetFilterChanges(&GetFilterChangesArgs{FilterID: newLogResult.FilterID}, result))
+	assert.Equal(string(filterTypeLog), result.Type)
+	assert.Equal(0, len(result.Logs))
+
+	uninstallResult := &UninstallFilterResult{}
+	require.Nil(service.UninstallFilter(&UninstallFilterArgs{FilterID: newResult.FilterID}, uninstallResult))
+	assert.True(uninstallResult.Uninstalled)
+	err = service.GetFilterChanges(&GetFilterChangesArgs{FilterID: newResult.FilterID}, &GetFilterChangesResult{})
+	assert.NotNil(err)
+}
@@ -0,0 +1,148 @@
+package rpc
+
+import (
+	"errors"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+)
+
+const (
+	// defaultNumGuardianVoteCheckpoints is the number of the recent checkpoints checked by default
+	defaultNumGuardianVoteCheckpoints = 20
+
+	// maxNumGuardianVoteCheckpoints caps the number of the checkpoints checked by one query
+	maxNumGuardianVoteCheckpoints = 500
+)
+
+// ------------------------------ GetGuardianVoteInclusion -----------------------------------
+
+type GetGuardianVoteInclusionArgs struct {
+	Address        string            `json:"address"`         // holder address of the guardian
+	NumCheckpoints common.JSONUint64 `json:"num_checkpoints"` // number of the recent checkpoints to check
+}
+
+// GuardianVoteInclusion reports whether the signature of the guardian is included in the aggregated guardian
+// votes carried by a checkpoint block. The guardian is eligible if it had stake in the guardian candidate
+// pool of the voted block. Only the guardians whose votes are included get the rewards of the checkpoint.
+type GuardianVoteInclusion struct {
+	Height     common.JSONUint64 `json:"height"`
+	BlockHash  common.Hash       `json:"block_hash"`
+	VotedBlock common.Hash       `json:"voted_block"` // empty if the block carries no guardian votes
+	Eligible   bool              `json:"eligible"`
+	Included   bool              `json:"included"`
+	Multiplier uint32            `json:"multiplier"` // number of the times the signature was aggregated
+}
+
+type GetGuardianVoteInclusionResult struct {
+	Address       common.Address           `json:"address"`
+	Checkpoints   []*GuardianVoteInclusion `json:"checkpoints"` // latest first
+	NumEligible   common.JSONUint64        `json:"num_eligible"`
+	NumIncluded   common.JSONUint64        `json:"num_included"`
+	InclusionRate float64                  `json:"inclusion_rate"` // included / eligible
+}
+
+func (t *ThetaRPCService) GetGuardianVoteInclusion(args *GetGuardianVoteInclusionArgs, result *GetGuardianVoteInclusionResult) (err error) {
+	defer t.guard("GetGuardianVoteInclusion", &err)()
+
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	address := common.HexToAddress(args.Address)
+
+	numCheckpoints := uint64(args.NumCheckpoints)
+	if numCheckpoints == 0 {
+		numCheckpoints = defaultNumGuardianVoteCheckpoints
+	}
+	if numCheckpoints > maxNumGuardianVoteCheckpoints {
+		numCheckpoints = maxNumGuardianVoteCheckpoints
+	}
+
+	deliveredView, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+	db := deliveredView.GetDB()
+
+	interval := uint64(common.CheckpointInterval)
+	height := t.consensus.GetLastFinalizedBlock().Height
+	height -= height % interval
+
+	result.Address = address
+	result.Checkpoints = []*GuardianVoteInclusion{}
+	gcps := make(map[common.Hash]*core.GuardianCandidatePool) // voted block -> guardian pool with stake
+	for ; numCheckpoints > 0 && height > 0; height -= interval {
+		var block *core.ExtendedBlock
+		for _, b := range t.chain.FindBlocksByHeight(height) {
+			if b.Status.IsFinalized() {
+				block = b
+				break
+			}
+		}
+		if block == nil {
+			break
+		}
+		numCheckpoints--
+
+		inclusion := &GuardianVoteInclusion{
+			Height:    common.JSONUint64(height),
+			BlockHash: block.Hash(),
+		}
+		result.Checkpoints = append(result.Checkpoints, inclusion)
+
+		votes := block.GuardianVotes
+		if votes == nil {
+			continue
+		}
+		inclusion.VotedBlock = votes.Block
+
+		gcp, ok := gcps[votes.Block]
+		if !ok {
+			votedBlock, err := t.chain.FindBlock(votes.Block)
+			if err != nil {
+				return err
+			}
+			view := state.NewStoreView(votedBlock.Height, votedBlock.StateHash, db)
+			if view == nil { // might have been pruned
+				result.Checkpoints = result.Checkpoints[:len(result.Checkpoints)-1]
+				break
+			}
+			gcp = view.GetGuardianCandidatePool().WithStake()
+			gcps[votes.Block] = gcp
+		}
+
+		inclusion.check(address, gcp, votes)
+		result.add(inclusion)
+	}
+
+	return nil
+}
+
+// check sets the eligibility and the inclusion of the guardian in the aggregated votes. The multipliers of
+// the votes are indexed by the position of the guardians in the pool with stake.
+func (inclusion *GuardianVoteInclusion) check(address common.Address, gcp *core.GuardianCandidatePool, votes *core.AggregatedVotes) {
+	for i, g := range gcp.SortedGuardians {
+		if g.Holder != address {
+			continue
+		}
+		if i < len(votes.Multiplies) {
+			inclusion.Eligible = true
+			inclusion.Multiplier = votes.Multiplies[i]
+			inclusion.Included = votes.Multiplies[i] > 0
+		}
+		return
+	}
+}
+
+// add counts the checkpoint towards the inclusion rate
+func (result *GetGuardianVoteInclusionResult) add(inclusion *GuardianVoteInclusion) {
+	if !inclusion.Eligible {
+		return
+	}
+	result.NumEligible++
+	if inclusion.Included {
+		result.NumIncluded++
+	}
+	result.InclusionRate = float64(result.NumIncluded) / float64(result.NumEligible)
+}
@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+)
+
+func TestGuardianVoteInclusion(t *testing.T) {
+	assert := assert.New(t)
+
+	g1 := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	g2 := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	g3 := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	gcp := core.NewGuardianCandidatePool()
+	for _, holder := range []common.Address{g3, g1, g2} {
+		gcp.Add(&core.Guardian{StakeHolder: &core.StakeHolder{Holder: holder}})
+	}
+
+	// The multipliers follow the order of the guardians sorted by holder address
+	votes := &core.AggregatedVotes{Multiplies: []uint32{0, 2, 1}}
+	result := &GetGuardianVoteInclusionResult{}
+	for _, tc := range []struct {
+		address    common.Address
+		eligible   bool
+		included   bool
+		multiplier uint32
+	}{
+		{g1, true, false, 0},
+		{g2, true, true, 2},
+		{g3, true, true, 1},
+		{common.HexToAddress("0x4444444444444444444444444444444444444444"), false, false, 0},
+	} {
+		inclusion := &GuardianVoteInclusion{}
+		inclusion.check(tc.address, gcp, votes)
+		assert.Equal(tc.eligible, inclusion.Eligible, tc.address.Hex())
+		assert.Equal(tc.included, inclusion.Included, tc.address.Hex())
+		assert.Equal(tc.multiplier, inclusion.Multiplier, tc.address.Hex())
+		result.add(inclusion)
+	}
+	assert.Equal(common.JSONUint64(3), result.NumEligible)
+	assert.Equal(common.JSONUint64(2), result.NumIncluded)
+	assert.InDelta(2.0/3.0, result.InclusionRate, 1e-9)
+
+	// Guardians beyond the multipliers were not in the pool when the votes were aggregated
+	inclusion := &GuardianVoteInclusion{}
+	inclusion.check(g3, gcp, &core.AggregatedVotes{Multiplies: []uint32{1, 1}})
+	assert.False(inclusion.Eligible)
+	result = &GetGuardianVoteInclusionResult{}
+	result.add(inclusion)
+	assert.Equal(common.JSONUint64(0), result.NumEligible)
+	assert.Equal(0.0, result.InclusionRate)
+}
@@ -0,0 +1,172 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+)
+
+const (
+	HealthCheckSync    = "sync"
+	HealthCheckPeers   = "peers"
+	HealthCheckMempool = "mempool"
+	HealthCheckDB      = "db"
+)
+
+var healthProbeKey = []byte("rpc/health/probe")
+
+// HealthCheck is the result of a single readiness criterion
+type HealthCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// HealthReport is the response body of the /healthz and /readyz endpoints
+type HealthReport struct {
+	Status string        `json:"status"` // "ok" or "unavailable"
+	Checks []HealthCheck `json:"checks,omitempty"`
+}
+
+// healthCriteria are the conditions for the node to be ready to serve the RPC traffic
+type healthCriteria struct {
+	maxBlocksBehind uint64
+	minPeers        int
+	mempoolStall    time.Duration // 0 disables the mempool check
+	checkDBWrite    bool
+}
+
+func loadHealthCriteria() healthCriteria {
+	return healthCriteria{
+		maxBlocksBehind: uint64(viper.GetInt64(common.CfgRPCHealthMaxBlocksBehind)),
+		minPeers:        viper.GetInt(common.CfgRPCHealthMinPeers),
+		mempoolStall:    time.Duration(viper.GetInt(common.CfgRPCHealthMempoolStallSecs)) * time.Second,
+		checkDBWrite:    viper.GetBool(common.CfgRPCHealthCheckDBWrite),
+	}
+}
+
+// healthSnapshot is the node state the readiness criteria are evaluated against
+type healthSnapshot struct {
+	now              time.Time
+	syncing          bool
+	blocksBehind     uint64
+	numPeers         int
+	mempoolSize      int
+	mempoolDrainedAt time.Time
+	dbErr            error
+}
+
+// evaluate returns the result of each readiness criterion, and whether all of them pass
+func (c healthCriteria) evaluate(s healthSnapshot) ([]HealthCheck, bool) {
+	checks := []HealthCheck{}
+
+	sync := HealthCheck{Name: HealthCheckSync, OK: s.blocksBehind <= c.maxBlocksBehind}
+	sync.Detail = fmt.Sprintf("%v blocks behind the network tip, at most %v allowed", s.blocksBehind, c.maxBlocksBehind)
+	if s.syncing {
+		sync.Detail += ", still syncing"
+	}
+	checks = append(checks, sync)
+
+	checks = append(checks, HealthCheck{
+		Name:   HealthCheckPeers,
+		OK:     s.numPeers >= c.minPeers,
+		Detail: fmt.Sprintf("%v peers connected, at least %v required", s.numPeers, c.minPeers),
+	})
+
+	if c.mempoolStall > 0 {
+		mempool := HealthCheck{Name: HealthCheckMempool, OK: true}
+		if s.mempoolSize > 0 {
+			stalled := s.now.Sub(s.mempoolDrainedAt)
+			mempool.OK = stalled <= c.mempoolStall
+			mempool.Detail = fmt.Sprintf("%v pending transactions, none included for %v", s.mempoolSize, stalled.Truncate(time.Second))
+		}
+		checks = append(checks, mempool)
+	}
+
+	if c.checkDBWrite {
+		db := HealthCheck{Name: HealthCheckDB, OK: s.dbErr == nil}
+		if s.dbErr != nil {
+			db.Detail = s.dbErr.Error()
+		}
+		checks = append(checks, db)
+	}
+
+	ok := true
+	for _, check := range checks {
+		ok = ok && check.OK
+	}
+	return checks, ok
+}
+
+// Healthz is the liveness probe, which succeeds as long as the RPC server is serving
+func (t *ThetaRPCService) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, &HealthReport{Status: "ok"}, true)
+}
+
+// Readyz is the readiness probe for the load balancers. It fails with 503 if the node lags behind the network,
+// has too few peers, has a wedged mempool or cannot write to its database.
+func (t *ThetaRPCService) Readyz(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := t.healthSnapshot()
+	if err != nil {
+		writeHealthReport(w, &HealthReport{
+			Status: "unavailable",
+			Checks: []HealthCheck{{Name: HealthCheckSync, Detail: err.Error()}},
+		}, false)
+		return
+	}
+
+	checks, ok := loadHealthCriteria().evaluate(snapshot)
+	report := &HealthReport{Status: "ok", Checks: checks}
+	if !ok {
+		report.Status = "unavailable"
+	}
+	writeHealthReport(w, report, ok)
+}
+
+func (t *ThetaRPCService) healthSnapshot() (healthSnapshot, error) {
+	status := &GetStatusResult{}
+	if err := t.GetStatus(&GetStatusArgs{}, status); err != nil {
+		return healthSnapshot{}, err
+	}
+
+	snapshot := healthSnapshot{
+		now:              time.Now(),
+		syncing:          status.Syncing,
+		blocksBehind:     uint64(status.BlocksBehind),
+		numPeers:         int(status.NumPeers),
+		mempoolSize:      t.mempool.Size(),
+		mempoolDrainedAt: t.mempool.DrainedAt(),
+	}
+	if viper.GetBool(common.CfgRPCHealthCheckDBWrite) {
+		snapshot.dbErr = t.probeDBWrite()
+	}
+	return snapshot, nil
+}
+
+// probeDBWrite writes and then deletes a probe key, to detect a full disk or a read-only database
+func (t *ThetaRPCService) probeDBWrite() error {
+	db := t.ledger.State().DB()
+	value := []byte(time.Now().String())
+	if err := db.Put(healthProbeKey, value); err != nil {
+		return fmt.Errorf("Failed to write to the database: %v", err)
+	}
+	if err := db.Delete(healthProbeKey); err != nil {
+		return fmt.Errorf("Failed to delete from the database: %v", err)
+	}
+	return nil
+}
+
+func writeHealthReport(w http.ResponseWriter, report *HealthReport, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,71 @@
+package rpc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCriteriaEvaluate(t *testing.T) {
+	assert := assert.New(t)
+
+	criteria := healthCriteria{
+		maxBlocksBehind: 20,
+		minPeers:        2,
+		mempoolStall:    5 * time.Minute,
+		checkDBWrite:    true,
+	}
+	now := time.Now()
+	healthy := healthSnapshot{
+		now:              now,
+		blocksBehind:     3,
+		numPeers:         5,
+		mempoolSize:      10,
+		mempoolDrainedAt: now.Add(-time.Minute),
+	}
+
+	checks, ok := criteria.evaluate(healthy)
+	assert.True(ok)
+	assert.Equal(4, len(checks))
+
+	failedChecks := func(s healthSnapshot) []string {
+		checks, ok := criteria.evaluate(s)
+		names := []string{}
+		for _, check := range checks {
+			if !check.OK {
+				names = append(names, check.Name)
+			}
+		}
+		assert.Equal(len(names) == 0, ok)
+		return names
+	}
+
+	s := healthy
+	s.blocksBehind = 21
+	assert.Equal([]string{HealthCheckSync}, failedChecks(s))
+
+	s = healthy
+	s.numPeers = 1
+	assert.Equal([]string{HealthCheckPeers}, failedChecks(s))
+
+	s = healthy
+	s.mempoolDrainedAt = now.Add(-10 * time.Minute)
+	assert.Equal([]string{HealthCheckMempool}, failedChecks(s))
+
+	// An empty mempool is never wedged
+	s.mempoolSize = 0
+	assert.Equal([]string{}, failedChecks(s))
+
+	s = healthy
+	s.dbErr = errors.New("disk full")
+	assert.Equal([]string{HealthCheckDB}, failedChecks(s))
+
+	// The disabled checks are skipped
+	criteria.mempoolStall = 0
+	criteria.checkDBWrite = false
+	checks, ok = criteria.evaluate(s)
+	assert.True(ok)
+	assert.Equal(2, len(checks))
+}
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+)
+
+//
+// The IPC listener serves the RPC requests over a Unix domain socket, so that the local tools, e.g. thetacli
+// and the signers, can talk to the node without a TCP port. The access is controlled by the file permissions
+// of the socket, which is only accessible by the owner of the node process by default.
+//
+
+// newIPCServer creates the server of the IPC listener, nil if the IPC listener is disabled
+func (t *ThetaRPCServer) newIPCServer() *http.Server {
+	if viper.GetString(common.CfgRPCIPCPath) == "" {
+		return nil
+	}
+	profile, err := GetRPCProfile(viper.GetString(common.CfgRPCIPCProfile))
+	if err != nil {
+		logger.Fatalf("Failed to load the profile of the RPC IPC listener: %v", err)
+	}
+	return &http.Server{
+		Handler: withRPCProfile(profile, t.chainRouter(t.router)),
+	}
+}
+
+// listenIPC creates the Unix domain socket at the given path with the given file mode. A stale socket left
+// by a previous run is removed, but a socket still served by another process is not.
+func listenIPC(path string, mode os.FileMode) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%v exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%v is in use by another process", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+func (t *ThetaRPCServer) serveIPC() {
+	path := viper.GetString(common.CfgRPCIPCPath)
+	mode, err := strconv.ParseUint(viper.GetString(common.CfgRPCIPCMode), 8, 32)
+	if err != nil {
+		logger.Fatalf("Invalid file mode of the RPC IPC socket: %v", err)
+	}
+
+	l, err := listenIPC(path, os.FileMode(mode))
+	if err != nil {
+		logger.WithFields(log.Fields{"error": err}).Fatal("Failed to create IPC listener")
+	} else {
+		logger.WithFields(log.Fields{"path": path, "profile": viper.GetString(common.CfgRPCIPCProfile)}).Info("RPC IPC listener started")
+	}
+	defer l.Close() // also removes the socket file
+
+	logger.Info(t.ipcServer.Serve(l))
+}
@@ -0,0 +1,77 @@
+package rpc
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenIPC(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ipc")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "node", "theta.ipc")
+
+	l, err := listenIPC(path, 0600)
+	require.Nil(err)
+	info, err := os.Stat(path)
+	require.Nil(err)
+	assert.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})}
+	go server.Serve(l)
+
+	httpClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := httpClient.Get("http://ipc/rpc")
+	require.Nil(err)
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Nil(err)
+	assert.Equal("ok", string(body))
+
+	// The socket of a running node is not taken over
+	_, err = listenIPC(path, 0600)
+	assert.NotNil(err)
+
+	// The socket is removed when the listener is closed
+	server.Close()
+	_, err = os.Stat(path)
+	assert.True(os.IsNotExist(err))
+
+	// A stale socket left by a crashed node is replaced
+	stale, err := net.Listen("unix", path)
+	require.Nil(err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+	l, err = listenIPC(path, 0660)
+	require.Nil(err)
+	info, err = os.Stat(path)
+	require.Nil(err)
+	assert.Equal(os.FileMode(0660), info.Mode().Perm())
+	l.Close()
+
+	// Other files are never removed
+	other := filepath.Join(dir, "config.yaml")
+	require.Nil(ioutil.WriteFile(other, []byte{}, 0600))
+	_, err = listenIPC(other, 0600)
+	assert.NotNil(err)
+	_, err = os.Stat(other)
+	assert.Nil(err)
+}
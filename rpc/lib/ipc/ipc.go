@@ -0,0 +1,39 @@
+// Package ipc dials the RPC IPC listener of the node, the Unix domain socket configured by rpc.ipcPath.
+// It only depends on the standard library so that the tools can talk to the node over the socket without
+// importing the rpc package.
+package ipc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EndpointPrefix is the prefix of the endpoints of the IPC sockets, followed by the path of the socket
+const EndpointPrefix = "unix://"
+
+// RPCURL is the URL of the RPC requests sent with the http.Client of NewHTTPClient, the host is ignored
+const RPCURL = "http://ipc/rpc"
+
+// ParseEndpoint returns the path of the socket if the endpoint is an IPC socket, e.g. unix:///tmp/theta.ipc
+func ParseEndpoint(endpoint string) (string, bool) {
+	if !strings.HasPrefix(endpoint, EndpointPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(endpoint, EndpointPrefix), true
+}
+
+// NewHTTPClient creates an http.Client sending the requests over the IPC socket at the given path
+func NewHTTPClient(socketPath string, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+}
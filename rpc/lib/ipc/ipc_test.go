@@ -0,0 +1,18 @@
+package ipc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	_, ok := ParseEndpoint("http://localhost:16888/rpc")
+	assert.False(ok)
+
+	socketPath, ok := ParseEndpoint("unix:///home/theta/node/theta.ipc")
+	assert.True(ok)
+	assert.Equal("/home/theta/node/theta.ipc", socketPath)
+}
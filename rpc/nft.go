@@ -0,0 +1,215 @@
+package rpc
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/vm"
+	"github.com/thetatoken/theta/ledger/vm/abi"
+)
+
+const (
+	defaultNFTsPerQuery = 100
+	maxNFTsPerQuery     = 1000
+
+	// nftTokenURIGasLimit limits the gas of the tokenURI calls reading the metadata pointers
+	nftTokenURIGasLimit = 1000000
+)
+
+var tokenURISelector = crypto.Keccak256([]byte("tokenURI(uint256)"))[:4]
+
+var errNFTIndexDisabled = errors.New("The NFT index is not enabled")
+
+type NFTInfo struct {
+	Contract common.Address  `json:"contract"`
+	TokenID  *common.JSONBig `json:"token_id"`
+	TokenURI string          `json:"token_uri"` // empty if the contract does not implement the metadata extension
+}
+
+// nftReader reads the metadata pointers of the TNT-721 tokens at the latest finalized state, and records
+// them for the later queries
+type nftReader struct {
+	chain *blockchain.Chain
+	view  *state.StoreView
+	block *core.ExtendedBlock
+}
+
+func (t *ThetaRPCService) newNFTReader() (*nftReader, error) {
+	view, block, err := t.getFinalizedView(0)
+	if err != nil {
+		return nil, err
+	}
+	return &nftReader{chain: t.chain, view: view, block: block}, nil
+}
+
+func (r *nftReader) info(contract common.Address, tokenID *big.Int) *NFTInfo {
+	return &NFTInfo{
+		Contract: contract,
+		TokenID:  (*common.JSONBig)(tokenID),
+		TokenURI: r.tokenURI(contract, tokenID),
+	}
+}
+
+// tokenURI returns the metadata pointer of the given token, or an empty string if it cannot be read
+func (r *nftReader) tokenURI(contract common.Address, tokenID *big.Int) string {
+	if metadata, found := r.chain.FindNFTMetadata(contract, tokenID); found {
+		return metadata.URI
+	}
+
+	sctx := &types.SmartContractTx{
+		From:     types.TxInput{Address: common.Address{}},
+		To:       types.TxOutput{Address: contract},
+		GasLimit: nftTokenURIGasLimit,
+		GasPrice: big.NewInt(0),
+		Data:     append(common.CopyBytes(tokenURISelector), common.BigToHash(tokenID).Bytes()...),
+	}
+	ret, _, _, vmErr := vm.Execute(r.block.Block, sctx, r.view)
+	if vmErr != nil {
+		return ""
+	}
+	stringType, _ := abi.NewType("string", nil)
+	values, err := abi.DecodeValues([]*abi.Type{stringType}, ret)
+	if err != nil {
+		return ""
+	}
+	uri := values[0].(string)
+
+	metadata := &blockchain.NFTMetadata{URI: uri, Height: r.block.Height}
+	if err := r.chain.AddNFTMetadata(contract, tokenID, metadata); err != nil {
+		logger.Warnf("Failed to record the metadata pointer of NFT %v of %v: %v", tokenID, contract.Hex(), err)
+	}
+	return uri
+}
+
+// ------------------------------ GetNFTsByOwner -----------------------------------
+
+type GetNFTsByOwnerArgs struct {
+	Address  string            `json:"address"`
+	Contract string            `json:"contract"` // only the tokens of the given contract if set
+	Offset   common.JSONUint64 `json:"offset"`
+	Limit    common.JSONUint64 `json:"limit"`
+}
+
+type GetNFTsByOwnerResult struct {
+	Address common.Address    `json:"address"`
+	Total   common.JSONUint64 `json:"total"`
+	Tokens  []*NFTInfo        `json:"tokens"`
+}
+
+func (t *ThetaRPCService) GetNFTsByOwner(args *GetNFTsByOwnerArgs, result *GetNFTsByOwnerResult) (err error) {
+	defer t.guard("GetNFTsByOwner", &err)()
+
+	if !viper.GetBool(common.CfgStorageNFTIndexEnabled) {
+		return errNFTIndexDisabled
+	}
+	if !common.IsHexAddress(args.Address) {
+		return errors.New("A valid owner address must be specified")
+	}
+	if args.Contract != "" && !common.IsHexAddress(args.Contract) {
+		return errors.New("The contract must be a valid address")
+	}
+	limit := uint64(args.Limit)
+	if limit == 0 {
+		limit = defaultNFTsPerQuery
+	}
+	if limit > maxNFTsPerQuery {
+		limit = maxNFTsPerQuery
+	}
+
+	owner := common.HexToAddress(args.Address)
+	tokens := []blockchain.NFT{}
+	for _, token := range t.chain.FindNFTsByOwner(owner) {
+		if args.Contract == "" || token.Contract == common.HexToAddress(args.Contract) {
+			tokens = append(tokens, token)
+		}
+	}
+
+	result.Address = owner
+	result.Total = common.JSONUint64(len(tokens))
+	result.Tokens = []*NFTInfo{}
+	if uint64(args.Offset) >= uint64(len(tokens)) {
+		return nil
+	}
+	tokens = tokens[args.Offset:]
+	if uint64(len(tokens)) > limit {
+		tokens = tokens[:limit]
+	}
+
+	reader, err := t.newNFTReader()
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		result.Tokens = append(result.Tokens, reader.info(token.Contract, token.TokenID))
+	}
+
+	return nil
+}
+
+// ------------------------------ GetNFTHistory -----------------------------------
+
+type GetNFTHistoryArgs struct {
+	Contract string `json:"contract"`
+	TokenID  string `json:"token_id"` // decimal, or hex with the 0x prefix
+}
+
+type NFTTransferRecord struct {
+	From        common.Address    `json:"from"`
+	To          common.Address    `json:"to"`
+	TxHash      common.Hash       `json:"tx_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	LogIndex    common.JSONUint64 `json:"log_index"`
+}
+
+type GetNFTHistoryResult struct {
+	*NFTInfo
+	Owner     common.Address       `json:"owner"` // the zero address if the token is burnt
+	Transfers []*NFTTransferRecord `json:"transfers"`
+}
+
+func (t *ThetaRPCService) GetNFTHistory(args *GetNFTHistoryArgs, result *GetNFTHistoryResult) (err error) {
+	defer t.guard("GetNFTHistory", &err)()
+
+	if !viper.GetBool(common.CfgStorageNFTIndexEnabled) {
+		return errNFTIndexDisabled
+	}
+	if !common.IsHexAddress(args.Contract) {
+		return errors.New("A valid contract address must be specified")
+	}
+	tokenID, ok := new(big.Int).SetString(args.TokenID, 0)
+	if !ok || tokenID.Sign() < 0 {
+		return errors.New("A valid token ID must be specified")
+	}
+
+	contract := common.HexToAddress(args.Contract)
+	history, found := t.chain.FindNFTHistory(contract, tokenID)
+	if !found {
+		return newNotFoundError("NFT %v of %v is not found", tokenID, contract.Hex())
+	}
+
+	reader, err := t.newNFTReader()
+	if err != nil {
+		return err
+	}
+	result.NFTInfo = reader.info(contract, tokenID)
+	result.Owner = history.Owner()
+	result.Transfers = []*NFTTransferRecord{}
+	for _, transfer := range history.Transfers {
+		result.Transfers = append(result.Transfers, &NFTTransferRecord{
+			From:        transfer.From,
+			To:          transfer.To,
+			TxHash:      transfer.TxHash,
+			BlockHeight: common.JSONUint64(transfer.Height),
+			LogIndex:    common.JSONUint64(transfer.LogIndex),
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestNFTReaderRecordedMetadata(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chain := blockchain.CreateTestChain()
+	contract := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	require.Nil(chain.AddNFTMetadata(contract, big.NewInt(7), &blockchain.NFTMetadata{URI: "ipfs://7", Height: 10}))
+
+	// The recorded metadata pointer is returned without calling the contract
+	reader := &nftReader{chain: chain}
+	info := reader.info(contract, big.NewInt(7))
+	assert.Equal(contract, info.Contract)
+	assert.Equal(big.NewInt(7), info.TokenID.ToInt())
+	assert.Equal("ipfs://7", info.TokenURI)
+}
+
+func TestGetNFTHistoryArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	service := &ThetaRPCService{
+		chain:    blockchain.CreateTestChain(),
+		breakers: NewCircuitBreakers(0.5, 1, time.Minute, time.Minute, 0, ""),
+	}
+	contract := "0x1111111111111111111111111111111111111111"
+
+	// The NFT index is opt-in
+	defer viper.Set(common.CfgStorageNFTIndexEnabled, viper.Get(common.CfgStorageNFTIndexEnabled))
+	viper.Set(common.CfgStorageNFTIndexEnabled, false)
+	assert.Equal(errNFTIndexDisabled, service.GetNFTHistory(&GetNFTHistoryArgs{Contract: contract, TokenID: "1"}, &GetNFTHistoryResult{}))
+	assert.Equal(errNFTIndexDisabled, service.GetNFTsByOwner(&GetNFTsByOwnerArgs{Address: contract}, &GetNFTsByOwnerResult{}))
+	viper.Set(common.CfgStorageNFTIndexEnabled, true)
+
+	err := service.GetNFTHistory(&GetNFTHistoryArgs{Contract: "0x11", TokenID: "1"}, &GetNFTHistoryResult{})
+	assert.NotNil(err)
+	err = service.GetNFTHistory(&GetNFTHistoryArgs{Contract: contract, TokenID: "-1"}, &GetNFTHistoryResult{})
+	assert.NotNil(err)
+	err = service.GetNFTHistory(&GetNFTHistoryArgs{Contract: contract, TokenID: "0x2a"}, &GetNFTHistoryResult{})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "not found")
+
+	result := &GetNFTsByOwnerResult{}
+	assert.Nil(service.GetNFTsByOwner(&GetNFTsByOwnerArgs{Address: contract}, result))
+	assert.Equal(common.JSONUint64(0), result.Total)
+	assert.Equal(0, len(result.Tokens))
+}
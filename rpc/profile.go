@@ -0,0 +1,203 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+)
+
+//
+// The RPC profiles select the methods served by each RPC listener, so that e.g. a public endpoint only
+// exposes the queries, while the listener bound to localhost serves the admin methods as well. A method
+// is matched by its full name, e.g. theta.GetBlock, or by a pattern ending with *, e.g. theta.Get* or
+// theta.* for the whole namespace.
+//
+
+const (
+	// RPCProfileOperator serves all the methods
+	RPCProfileOperator = "operator"
+	// RPCProfilePublic serves the chain queries, without the admin, keystore and heavy historical methods
+	RPCProfilePublic = "public"
+	// RPCProfileWallet serves the public methods and the transaction composition and broadcast
+	RPCProfileWallet = "wallet"
+	// RPCProfileArchive serves the public methods and the heavy historical queries
+	RPCProfileArchive = "archive"
+)
+
+// RPCProfile is a set of the enabled RPC methods. The denied patterns take precedence over the allowed ones.
+type RPCProfile struct {
+	Allow []string `mapstructure:"allow"`
+	Deny  []string `mapstructure:"deny"`
+}
+
+var (
+	// The methods operating the node, which expose its internals or act on its host
+	adminMethods = []string{
+		"theta.BackupChain",
+		"theta.BackupChainCorrection",
+		"theta.BackupDB",
+		"theta.BackupSnapshot",
+		"theta.GetDBBackupStatus",
+		"theta.GetSnapshotSchedule",
+		"theta.UpdateSnapshotSchedule",
+		"theta.VerifySnapshot",
+		"theta.GetPeers",
+		"theta.GetPeerURLs",
+		"theta.GetPeerCapabilities",
+		"theta.ProbeNetworkTopology",
+		"theta.GetShadowReport",
+		"theta.GetEliteEdgeNodeVoteDiagnostics",
+		"theta.GetSelfValidatorReport",
+		"theta.VerifyContract",
+	}
+
+	// The methods using the keys of the node
+	keystoreMethods = []string{
+		"theta.GetGuardianInfo",
+	}
+
+	// The queries scanning many blocks or historical states
+	archiveMethods = []string{
+		"theta.GetStateDiff",
+		"theta.GetBalanceHistory",
+		"theta.SearchTransactions",
+		"theta.GetChainStats",
+		"theta.GetSupplyDelta",
+		"theta.GetContractStats",
+	}
+
+	// The methods composing and broadcasting transactions
+	walletMethods = []string{
+		"theta.Compose*",
+		"theta.Broadcast*",
+		"theta.PrecheckTransaction",
+	}
+
+	// The chain queries
+	queryMethods = []string{
+		"theta.Get*",
+		"theta.CallSmartContract",
+		"theta.SimulateStakeWithdrawal",
+		"theta.NewFilter",
+		"theta.NewBlockFilter",
+		"theta.NewPendingTransactionFilter",
+		"theta.UninstallFilter",
+	}
+)
+
+func concatMethods(lists ...[]string) []string {
+	methods := []string{}
+	for _, list := range lists {
+		methods = append(methods, list...)
+	}
+	return methods
+}
+
+var builtinRPCProfiles = map[string]*RPCProfile{
+	RPCProfileOperator: {
+		Allow: []string{"*"},
+	},
+	RPCProfilePublic: {
+		Allow: queryMethods,
+		Deny:  concatMethods(adminMethods, keystoreMethods, archiveMethods),
+	},
+	RPCProfileWallet: {
+		Allow: concatMethods(queryMethods, walletMethods),
+		Deny:  concatMethods(adminMethods, keystoreMethods, archiveMethods),
+	},
+	RPCProfileArchive: {
+		Allow: concatMethods(queryMethods, archiveMethods),
+		Deny:  concatMethods(adminMethods, keystoreMethods),
+	},
+}
+
+func matchMethod(pattern, method string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(method, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == method
+}
+
+// Allows returns whether the given method, e.g. theta.GetBlock, is enabled by the profile
+func (p *RPCProfile) Allows(method string) bool {
+	for _, pattern := range p.Deny {
+		if matchMethod(pattern, method) {
+			return false
+		}
+	}
+	for _, pattern := range p.Allow {
+		if matchMethod(pattern, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// disallowedMethod returns the first of the given methods not enabled by the profile
+func (p *RPCProfile) disallowedMethod(methods []string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	for _, method := range methods {
+		if !p.Allows(method) {
+			return method, true
+		}
+	}
+	return "", false
+}
+
+// GetRPCProfile returns the profile of the given name. The profiles defined in the config override the
+// built-in profiles of the same name.
+func GetRPCProfile(name string) (*RPCProfile, error) {
+	profiles := map[string]*RPCProfile{}
+	if err := viper.UnmarshalKey(common.CfgRPCProfiles, &profiles); err != nil {
+		return nil, fmt.Errorf("Failed to parse the RPC profiles: %v", err)
+	}
+	if profile, ok := profiles[name]; ok {
+		return profile, nil
+	}
+	if profile, ok := builtinRPCProfiles[name]; ok {
+		return profile, nil
+	}
+	return nil, fmt.Errorf("Unknown RPC profile: %v", name)
+}
+
+// RPCListenerConfig is the config of an additional RPC listener
+type RPCListenerConfig struct {
+	Address string `mapstructure:"address"`
+	Port    string `mapstructure:"port"`
+	Profile string `mapstructure:"profile"`
+}
+
+type rpcProfileKey struct{}
+
+// withRPCProfile attaches the profile of the listener to the requests, which is checked by the scheduler
+// before the requests are served
+func withRPCProfile(profile *RPCProfile, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), rpcProfileKey{}, profile)))
+	})
+}
+
+// rpcProfileFromContext returns the profile of the listener the request is received from, nil if the
+// listener serves all the methods
+func rpcProfileFromContext(ctx context.Context) *RPCProfile {
+	profile, _ := ctx.Value(rpcProfileKey{}).(*RPCProfile)
+	return profile
+}
+
+// requireRPCMethod guards an HTTP endpoint serving the same content as the given RPC method, e.g. a streaming
+// endpoint, by the RPC profile of the listener
+func requireRPCMethod(method string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, denied := rpcProfileFromContext(r.Context()).disallowedMethod([]string{method}); denied {
+			writeStreamError(w, http.StatusForbidden, fmt.Errorf("RPC method %v is not enabled on this endpoint", method))
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
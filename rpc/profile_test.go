@@ -0,0 +1,93 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestRPCProfiles(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	operator, err := GetRPCProfile(RPCProfileOperator)
+	require.Nil(err)
+	assert.True(operator.Allows("theta.BackupDB"))
+	assert.True(operator.Allows("theta.GetGuardianInfo"))
+
+	public, err := GetRPCProfile(RPCProfilePublic)
+	require.Nil(err)
+	assert.True(public.Allows("theta.GetBlock"))
+	assert.True(public.Allows("theta.GetStatus"))
+	assert.True(public.Allows("theta.NewFilter"))
+	assert.True(public.Allows("theta.SimulateStakeWithdrawal"))
+	assert.False(public.Allows("theta.BackupDB"))
+	assert.False(public.Allows("theta.GetPeers"))
+	assert.False(public.Allows("theta.GetSelfValidatorReport"))
+	assert.False(public.Allows("theta.GetGuardianInfo"))
+	assert.False(public.Allows("theta.GetStateDiff"))
+	assert.False(public.Allows("theta.BroadcastRawTransaction"))
+
+	wallet, err := GetRPCProfile(RPCProfileWallet)
+	require.Nil(err)
+	assert.True(wallet.Allows("theta.BroadcastRawTransactionAsync"))
+	assert.True(wallet.Allows("theta.ComposeDepositStakeTx"))
+	assert.False(wallet.Allows("theta.GetStateDiff"))
+
+	archive, err := GetRPCProfile(RPCProfileArchive)
+	require.Nil(err)
+	assert.True(archive.Allows("theta.GetStateDiff"))
+	assert.False(archive.Allows("theta.BroadcastRawTransaction"))
+	assert.False(archive.Allows("theta.UpdateSnapshotSchedule"))
+
+	_, err = GetRPCProfile("unknown")
+	assert.NotNil(err)
+
+	// The profiles in the config override the built-in ones
+	defer viper.Set(common.CfgRPCProfiles, nil)
+	viper.Set(common.CfgRPCProfiles, map[string]interface{}{
+		"public": map[string]interface{}{
+			"allow": []string{"theta.*"},
+			"deny":  []string{"theta.Backup*"},
+		},
+	})
+	public, err = GetRPCProfile(RPCProfilePublic)
+	require.Nil(err)
+	assert.True(public.Allows("theta.GetPeers"))
+	assert.False(public.Allows("theta.BackupChain"))
+	assert.False(public.Allows("thetacli.Send"))
+}
+
+func TestRequestSchedulerProfile(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := NewRequestScheduler(1, 10, "")
+	s.Start(ctx)
+
+	handler := withRPCProfile(builtinRPCProfiles[RPCProfilePublic], s.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+	call := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/rpc", strings.NewReader(body)))
+		return w
+	}
+
+	assert.Equal(http.StatusOK, call(`{"jsonrpc":"2.0","method":"theta.GetBlock","params":[{}],"id":1}`).Code)
+	w := call(`{"jsonrpc":"2.0","method":"theta.BackupDB","params":[{}],"id":1}`)
+	assert.Equal(http.StatusForbidden, w.Code)
+	assert.Contains(w.Body.String(), "theta.BackupDB")
+
+	// A batch is rejected if any of its calls is not enabled
+	assert.Equal(http.StatusForbidden, call(`[{"method":"theta.GetBlock"},{"method":"theta.GetGuardianInfo"}]`).Code)
+}
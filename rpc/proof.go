@@ -0,0 +1,256 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+)
+
+// maxFinalityProofHeaders caps the number of headers in a finality proof bundle. It covers
+// the walk to the nearest checkpoint plus the checkpoint carrying its guardian votes.
+const maxFinalityProofHeaders = 3 * common.CheckpointInterval
+
+// ------------------------------ GetFinalityProof -----------------------------------
+
+type GetFinalityProofArgs struct {
+	Hash   common.Hash       `json:"hash"`
+	Height common.JSONUint64 `json:"height"`
+}
+
+type GetFinalityProofResult struct {
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+
+	// Headers is the finalized header chain starting from the target block, up to the block
+	// carrying the guardian votes of the nearest checkpoint.
+	Headers []*FinalityProofHeader `json:"headers"`
+
+	// ValidatorSet is the validator set that signed the votes for the target block.
+	ValidatorSet *core.ValidatorSet `json:"validator_set"`
+
+	// CommitCertificate is the HCC of the first child block in Headers that certifies its
+	// parent, i.e. child.Parent == child.HCC.BlockHash. The certified parent is the target
+	// block or a descendant of it.
+	CommitCertificate core.CommitCertificate `json:"commit_certificate"`
+
+	// ChildCommitCertificate holds the votes on the certifying child block. Together with
+	// CommitCertificate they satisfy the finalization condition of the target block.
+	ChildCommitCertificate core.CommitCertificate `json:"child_commit_certificate"`
+	ChildValidatorSet      *core.ValidatorSet     `json:"child_validator_set"`
+
+	// Guardian votes on the nearest checkpoint at or above the target block.
+	CheckpointHash        common.Hash                 `json:"checkpoint_hash"`
+	CheckpointHeight      common.JSONUint64           `json:"checkpoint_height"`
+	GuardianVotes         *core.AggregatedVotes       `json:"guardian_votes"`
+	GuardianCandidatePool *core.GuardianCandidatePool `json:"guardian_candidate_pool"`
+}
+
+// FinalityProofHeader is a block header along with the fields left out of the JSON encoding of the
+// headers, which the verifiers need to recalculate the header hashes and signatures.
+type FinalityProofHeader struct {
+	*core.BlockHeader
+	ReceiptHash common.Hash
+	Bloom       core.Bloom
+}
+
+func newFinalityProofHeader(header *core.BlockHeader) *FinalityProofHeader {
+	return &FinalityProofHeader{
+		BlockHeader: header,
+		ReceiptHash: header.ReceiptHash,
+		Bloom:       header.Bloom,
+	}
+}
+
+// header returns the complete block header
+func (h *FinalityProofHeader) header() *core.BlockHeader {
+	header := *h.BlockHeader
+	header.ReceiptHash = h.ReceiptHash
+	header.Bloom = h.Bloom
+	return &header
+}
+
+func (t *ThetaRPCService) GetFinalityProof(args *GetFinalityProofArgs, result *GetFinalityProofResult) (err error) {
+	defer t.guard("GetFinalityProof", &err)()
+
+	var block *core.ExtendedBlock
+	if !args.Hash.IsEmpty() {
+		block, err = t.chain.FindBlock(args.Hash)
+		if err != nil {
+			return err
+		}
+	} else if args.Height != 0 {
+		for _, b := range t.chain.FindBlocksByHeight(uint64(args.Height)) {
+			if b.Status.IsFinalized() {
+				block = b
+				break
+			}
+		}
+		if block == nil {
+			return fmt.Errorf("No finalized block found at height %v", args.Height)
+		}
+	} else {
+		return errors.New("Block hash or height must be specified")
+	}
+
+	if !block.Status.IsFinalized() {
+		return fmt.Errorf("Block %v is not finalized", block.Hash().Hex())
+	}
+
+	result.BlockHash = block.Hash()
+	result.BlockHeight = common.JSONUint64(block.Height)
+	result.ValidatorSet = t.consensus.GetValidatorManager().GetValidatorSet(block.Hash())
+	result.Headers = []*FinalityProofHeader{newFinalityProofHeader(block.BlockHeader)}
+
+	checkpointHeight := block.Height
+	for !common.IsCheckPointHeight(checkpointHeight) {
+		checkpointHeight++
+	}
+	result.CheckpointHeight = common.JSONUint64(checkpointHeight)
+	if checkpointHeight == block.Height {
+		result.CheckpointHash = block.Hash()
+	}
+
+	curr := block
+	for len(result.Headers) < int(maxFinalityProofHeaders) {
+		next := t.findCertifiedChild(curr)
+		if next == nil {
+			break
+		}
+		result.Headers = append(result.Headers, newFinalityProofHeader(next.BlockHeader))
+
+		// The first block in the walk that is certified by its child directly finalizes
+		// itself and all its ancestors, including the target block.
+		if result.CommitCertificate.Votes == nil && next.HCC.BlockHash == curr.Hash() {
+			result.CommitCertificate = next.HCC.Copy()
+			result.ChildValidatorSet = t.consensus.GetValidatorManager().GetValidatorSet(next.Hash())
+			childVotes := t.chain.FindVotesByHash(next.Hash()).UniqueVoter().FilterByValidators(result.ChildValidatorSet)
+			result.ChildCommitCertificate = core.CommitCertificate{
+				BlockHash: next.Hash(),
+				Votes:     childVotes,
+			}
+		}
+		if next.Height == checkpointHeight {
+			result.CheckpointHash = next.Hash()
+		}
+
+		// Guardian votes on a checkpoint are included in a subsequent checkpoint block
+		if !result.CheckpointHash.IsEmpty() && next.GuardianVotes != nil &&
+			next.GuardianVotes.Block == result.CheckpointHash {
+			result.GuardianVotes = next.GuardianVotes
+			break
+		}
+		curr = next
+	}
+
+	if result.CommitCertificate.Votes == nil {
+		return fmt.Errorf("Commit certificate for block %v is not available", block.Hash().Hex())
+	}
+
+	if !result.CheckpointHash.IsEmpty() {
+		gcp, err := t.ledger.GetGuardianCandidatePool(result.CheckpointHash)
+		if err == nil {
+			result.GuardianCandidatePool = gcp
+		}
+	}
+
+	return nil
+}
+
+// findCertifiedChild returns the child of the given block that extends the finalized chain. For
+// the tip of the finalized chain, it returns the committed child that certifies the block.
+func (t *ThetaRPCService) findCertifiedChild(block *core.ExtendedBlock) *core.ExtendedBlock {
+	var candidate *core.ExtendedBlock
+	for _, hash := range block.Children {
+		child, err := t.chain.FindBlock(hash)
+		if err != nil {
+			continue
+		}
+		if child.Status.IsFinalized() {
+			return child
+		}
+		if child.HCC.BlockHash == block.Hash() && child.Status.IsValid() {
+			candidate = child
+		}
+	}
+	return candidate
+}
+
+// Verify checks the finality proof offline: the headers form a signed chain starting from the target
+// block, the commit certificates carry the majority votes of the validator sets, and the guardian votes,
+// if any, are signed by the guardian candidate pool. The validator sets and the guardian candidate pool
+// are taken from the proof, the verifier needs to check them against a trusted source.
+func (p *GetFinalityProofResult) Verify() error {
+	if len(p.Headers) == 0 {
+		return errors.New("Finality proof has no headers")
+	}
+	headers := make([]*core.BlockHeader, len(p.Headers))
+	hashes := make([]common.Hash, len(p.Headers))
+	for i, h := range p.Headers {
+		if h == nil || h.BlockHeader == nil {
+			return fmt.Errorf("Header %v is missing", i)
+		}
+		header := h.header()
+		if res := header.Validate(p.Headers[0].ChainID); res.IsError() {
+			return fmt.Errorf("Header %v is invalid: %v", i, res.Message)
+		}
+		headers[i] = header
+		hashes[i] = header.CalculateHash()
+		if i > 0 && (header.Parent != hashes[i-1] || header.Height != headers[i-1].Height+1) {
+			return fmt.Errorf("Header %v is not a child of header %v", i, i-1)
+		}
+	}
+	if hashes[0] != p.BlockHash || headers[0].Height != uint64(p.BlockHeight) {
+		return fmt.Errorf("First header is not block %v", p.BlockHash.Hex())
+	}
+
+	// The certified block needs to be followed by the child carrying its commit certificate
+	certified := -1
+	for i := 0; i < len(hashes)-1; i++ {
+		if hashes[i] == p.CommitCertificate.BlockHash {
+			certified = i
+			break
+		}
+	}
+	if certified < 0 {
+		return errors.New("Commit certificate does not certify a block followed by its child")
+	}
+	child := headers[certified+1]
+	if child.HCC.BlockHash != hashes[certified] {
+		return fmt.Errorf("Header %v does not carry the commit certificate of its parent", certified+1)
+	}
+	if p.ValidatorSet == nil || !p.CommitCertificate.IsValid(p.ValidatorSet) {
+		return fmt.Errorf("Commit certificate for block %v does not have majority votes", hashes[certified].Hex())
+	}
+	if p.ChildCommitCertificate.BlockHash != hashes[certified+1] {
+		return errors.New("Child commit certificate does not certify the child block")
+	}
+	if p.ChildValidatorSet == nil || !p.ChildCommitCertificate.IsValid(p.ChildValidatorSet) {
+		return fmt.Errorf("Child commit certificate for block %v does not have majority votes", hashes[certified+1].Hex())
+	}
+
+	if p.GuardianVotes == nil {
+		return nil
+	}
+	if p.GuardianVotes.Block != p.CheckpointHash || !common.IsCheckPointHeight(uint64(p.CheckpointHeight)) {
+		return fmt.Errorf("Guardian votes are not on checkpoint %v", p.CheckpointHash.Hex())
+	}
+	found := false
+	for i, hash := range hashes {
+		if hash == p.CheckpointHash && headers[i].Height == uint64(p.CheckpointHeight) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("Checkpoint %v is not in the headers", p.CheckpointHash.Hex())
+	}
+	if p.GuardianCandidatePool == nil {
+		return errors.New("Guardian candidate pool is missing")
+	}
+	if res := p.GuardianVotes.Validate(p.GuardianCandidatePool); res.IsError() {
+		return fmt.Errorf("Guardian votes are invalid: %v", res.Message)
+	}
+	return nil
+}
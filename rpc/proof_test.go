@@ -0,0 +1,121 @@
+package rpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+)
+
+func signedTestCC(block common.Hash, keys ...*crypto.PrivateKey) core.CommitCertificate {
+	votes := core.NewVoteSet()
+	for _, key := range keys {
+		vote := core.Vote{Block: block, ID: key.PublicKey().Address(), Epoch: 1}
+		vote.Sign(key)
+		votes.AddVote(vote)
+	}
+	return core.CommitCertificate{BlockHash: block, Votes: votes}
+}
+
+// createTestFinalityProof creates the proof of block a1, certified by a2 whose votes are in a3
+func createTestFinalityProof(t *testing.T) (*GetFinalityProofResult, *crypto.PrivateKey) {
+	core.ResetTestBlocks()
+	core.CreateTestBlock("a0", "")
+	a1 := core.CreateTestBlock("a1", "a0")
+	a2 := core.CreateTestBlock("a2", "a1")
+	a3 := core.CreateTestBlock("a3", "a2")
+
+	key, _, err := crypto.GenerateKeyPair()
+	assert.Nil(t, err)
+	validators := core.NewValidatorSet()
+	validators.AddValidator(core.NewValidator(key.PublicKey().Address().Hex(), big.NewInt(100)))
+
+	return &GetFinalityProofResult{
+		BlockHash:              a1.Hash(),
+		BlockHeight:            common.JSONUint64(a1.Height),
+		Headers:                []*FinalityProofHeader{newFinalityProofHeader(a1.BlockHeader), newFinalityProofHeader(a2.BlockHeader), newFinalityProofHeader(a3.BlockHeader)},
+		ValidatorSet:           validators,
+		CommitCertificate:      signedTestCC(a1.Hash(), key),
+		ChildCommitCertificate: signedTestCC(a2.Hash(), key),
+		ChildValidatorSet:      validators,
+	}, key
+}
+
+func TestVerifyFinalityProof(t *testing.T) {
+	assert := assert.New(t)
+
+	proof, _ := createTestFinalityProof(t)
+	assert.Nil(proof.Verify())
+
+	// The proof can be verified by the clients that receive it over RPC
+	raw, err := json.Marshal(proof)
+	assert.Nil(err)
+	received := &GetFinalityProofResult{}
+	assert.Nil(json.Unmarshal(raw, received))
+	assert.Nil(received.Verify())
+
+	// The first header needs to be the target block
+	proof.BlockHash = core.GetTestBlock("a0").Hash()
+	assert.NotNil(proof.Verify())
+}
+
+func TestVerifyShortFinalityProof(t *testing.T) {
+	assert := assert.New(t)
+
+	proof, _ := createTestFinalityProof(t)
+	proof.Headers = nil
+	assert.NotNil(proof.Verify())
+
+	// The certifying child is missing
+	proof, _ = createTestFinalityProof(t)
+	proof.Headers = proof.Headers[:1]
+	assert.NotNil(proof.Verify())
+
+	// A gap in the header chain
+	proof, _ = createTestFinalityProof(t)
+	proof.Headers = []*FinalityProofHeader{proof.Headers[0], proof.Headers[2]}
+	assert.NotNil(proof.Verify())
+
+	// The votes on the child are missing
+	proof, _ = createTestFinalityProof(t)
+	proof.ChildCommitCertificate = core.CommitCertificate{BlockHash: proof.ChildCommitCertificate.BlockHash}
+	assert.NotNil(proof.Verify())
+}
+
+func TestVerifyForgedFinalityProof(t *testing.T) {
+	assert := assert.New(t)
+
+	// A tampered header no longer matches its signature
+	proof, _ := createTestFinalityProof(t)
+	forged := *proof.Headers[1].BlockHeader
+	forged.StateHash = common.HexToHash("f0")
+	proof.Headers[1] = newFinalityProofHeader(&forged)
+	assert.NotNil(proof.Verify())
+
+	// Votes signed by a key outside the validator set
+	proof, _ = createTestFinalityProof(t)
+	outsider, _, _ := crypto.GenerateKeyPair()
+	proof.CommitCertificate = signedTestCC(proof.BlockHash, outsider)
+	assert.NotNil(proof.Verify())
+
+	// Votes on a different block
+	proof, key := createTestFinalityProof(t)
+	proof.CommitCertificate = signedTestCC(proof.BlockHash, key)
+	proof.CommitCertificate.BlockHash = core.GetTestBlock("a2").Hash()
+	assert.NotNil(proof.Verify())
+
+	// The validator set does not reach the majority
+	proof, _ = createTestFinalityProof(t)
+	proof.ValidatorSet.AddValidator(core.NewValidator(outsider.PublicKey().Address().Hex(), big.NewInt(100)))
+	assert.NotNil(proof.Verify())
+
+	// Guardian votes on a block other than the checkpoint
+	proof, _ = createTestFinalityProof(t)
+	proof.CheckpointHash = proof.BlockHash
+	proof.GuardianVotes = &core.AggregatedVotes{Block: core.GetTestBlock("a2").Hash()}
+	assert.NotNil(proof.Verify())
+}
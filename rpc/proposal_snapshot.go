@@ -0,0 +1,77 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+)
+
+// ------------------------------ GetProposalSnapshot -----------------------------------
+
+type GetProposalSnapshotArgs struct {
+	Height common.JSONUint64 `json:"height"`
+	Epoch  common.JSONUint64 `json:"epoch"` // optional, default to the epoch of the finalized block at the height
+}
+
+type ProposalCandidate struct {
+	TxHash   common.Hash `json:"tx_hash"`
+	Special  bool        `json:"special"` // added by the proposer, e.g. the coinbase tx, instead of reaped from the mempool
+	Included bool        `json:"included"`
+	Reason   string      `json:"reason,omitempty"` // why the tx was left out of the block
+	Error    string      `json:"error,omitempty"`
+}
+
+type GetProposalSnapshotResult struct {
+	Height        common.JSONUint64    `json:"height"`
+	Epoch         common.JSONUint64    `json:"epoch"`
+	Parent        common.Hash          `json:"parent"`
+	MempoolSize   common.JSONUint64    `json:"mempool_size"`
+	MaxNumTxs     common.JSONUint64    `json:"max_num_txs"`
+	BlockGasLimit common.JSONUint64    `json:"block_gas_limit"` // 0 means no limit
+	Candidates    []*ProposalCandidate `json:"candidates"`      // in the order considered by the proposer
+}
+
+func (t *ThetaRPCService) GetProposalSnapshot(args *GetProposalSnapshotArgs, result *GetProposalSnapshotResult) (err error) {
+	defer t.guard("GetProposalSnapshot", &err)()
+
+	if args.Height == 0 {
+		return errors.New("Block height must be specified")
+	}
+
+	epoch := uint64(args.Epoch)
+	if epoch == 0 {
+		for _, block := range t.chain.FindBlocksByHeight(uint64(args.Height)) {
+			if block.Status.IsFinalized() {
+				epoch = block.Epoch
+				break
+			}
+		}
+		if epoch == 0 {
+			return fmt.Errorf("No finalized block at height %v, the epoch must be specified", args.Height)
+		}
+	}
+
+	snapshot, ok := t.ledger.GetProposalSnapshot(uint64(args.Height), epoch)
+	if !ok {
+		return fmt.Errorf("No block proposed by this node at height %v and epoch %v", args.Height, epoch)
+	}
+
+	result.Height = common.JSONUint64(snapshot.Height)
+	result.Epoch = common.JSONUint64(snapshot.Epoch)
+	result.Parent = snapshot.Parent
+	result.MempoolSize = common.JSONUint64(snapshot.MempoolSize)
+	result.MaxNumTxs = common.JSONUint64(snapshot.MaxNumTxs)
+	result.BlockGasLimit = common.JSONUint64(snapshot.BlockGasLimit)
+	result.Candidates = []*ProposalCandidate{}
+	for _, c := range snapshot.Candidates {
+		result.Candidates = append(result.Candidates, &ProposalCandidate{
+			TxHash:   c.TxHash,
+			Special:  c.Special,
+			Included: c.Included,
+			Reason:   c.Reason,
+			Error:    c.Error,
+		})
+	}
+	return nil
+}
@@ -2,24 +2,35 @@ package rpc
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"math/big"
 	"math/rand"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/consensus"
 	"github.com/thetatoken/theta/crypto/bls"
+	"github.com/thetatoken/theta/store/kvstore"
 
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
+	exec "github.com/thetatoken/theta/ledger/execution"
 	"github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/ledger/vm/abi"
 	"github.com/thetatoken/theta/mempool"
+	p2ptypes "github.com/thetatoken/theta/p2p/types"
+	"github.com/thetatoken/theta/snapshot"
+	"github.com/thetatoken/theta/upgrade"
 	"github.com/thetatoken/theta/version"
+	ks "github.com/thetatoken/theta/wallet/softwallet/keystore"
 )
 
 // ------------------------------- GetVersion -----------------------------------
@@ -34,6 +45,8 @@ type GetVersionResult struct {
 }
 
 func (t *ThetaRPCService) GetVersion(args *GetVersionArgs, result *GetVersionResult) (err error) {
+	defer t.guard("GetVersion", &err)()
+
 	result.Version = version.Version
 	result.GitHash = version.GitHash
 	result.Timestamp = version.Timestamp
@@ -46,7 +59,8 @@ type GetAccountArgs struct {
 	Name    string            `json:"name"`
 	Address string            `json:"address"`
 	Height  common.JSONUint64 `json:"height"`
-	Preview bool              `json:"preview"` // preview the account balance from the ScreenedView
+	Preview bool              `json:"preview"`  // preview the account balance from the ScreenedView
+	AfterTx string            `json:"after_tx"` // hash returned by a broadcast, the query observes the effect of the transaction once accepted
 }
 
 type GetAccountResult struct {
@@ -55,16 +69,25 @@ type GetAccountResult struct {
 }
 
 func (t *ThetaRPCService) GetAccount(args *GetAccountArgs, result *GetAccountResult) (err error) {
+	defer t.guard("GetAccount", &err)()
+
 	if args.Address == "" {
 		return errors.New("Address must be specified")
 	}
+	if args.Height != 0 { // only the accounts at the finalized heights are cached
+		if cached, ok := t.cache.get("GetAccount", args); ok {
+			*result = cached.(GetAccountResult)
+			return nil
+		}
+	}
+
 	address := common.HexToAddress(args.Address)
 	result.Address = args.Address
 	height := uint64(args.Height)
 
 	if height == 0 { // get the latest
 		var ledgerState *state.StoreView
-		if args.Preview {
+		if args.Preview || t.isTxPendingFinalization(args.AfterTx) {
 			ledgerState, err = t.ledger.GetScreenedSnapshot()
 		} else {
 			ledgerState, err = t.ledger.GetFinalizedSnapshot()
@@ -75,7 +98,7 @@ func (t *ThetaRPCService) GetAccount(args *GetAccountArgs, result *GetAccountRes
 
 		account := ledgerState.GetAccount(address)
 		if account == nil {
-			return fmt.Errorf("Account with address %s is not found", address.Hex())
+			return newNotFoundError("Account with address %s is not found", address.Hex())
 		}
 		account.UpdateToHeight(ledgerState.Height())
 
@@ -102,9 +125,10 @@ func (t *ThetaRPCService) GetAccount(args *GetAccountArgs, result *GetAccountRes
 				}
 				account := ledgerState.GetAccount(address)
 				if account == nil {
-					return fmt.Errorf("Account with address %v is not found", address.Hex())
+					return newNotFoundError("Account with address %v is not found", address.Hex())
 				}
 				result.Account = account
+				t.cache.add("GetAccount", args, *result) // the account at a finalized height never changes
 				break
 			}
 		}
@@ -125,6 +149,8 @@ type GetSplitRuleResult struct {
 }
 
 func (t *ThetaRPCService) GetSplitRule(args *GetSplitRuleArgs, result *GetSplitRuleResult) (err error) {
+	defer t.guard("GetSplitRule", &err)()
+
 	if args.ResourceID == "" {
 		return errors.New("ResourceID must be specified")
 	}
@@ -137,20 +163,45 @@ func (t *ThetaRPCService) GetSplitRule(args *GetSplitRuleArgs, result *GetSplitR
 	return nil
 }
 
+// isTxPendingFinalization returns whether the transaction is accepted but not yet reflected in the finalized
+// view, in which case the queries following its broadcast need the ScreenedView to read their own writes.
+func (t *ThetaRPCService) isTxPendingFinalization(hash string) bool {
+	if hash == "" {
+		return false
+	}
+	txHash := common.HexToHash(hash)
+	_, block, found := t.chain.FindTxByHash(txHash)
+	if found {
+		return !block.Status.IsFinalized()
+	}
+	txStatus, exists := t.mempool.GetTransactionStatus(hex.EncodeToString(txHash[:])) // the mempool keys are not 0x prefixed
+	return exists && txStatus == mempool.TxStatusPending
+}
+
 // ------------------------------ GetTransaction -----------------------------------
 
 type GetTransactionArgs struct {
 	Hash string `json:"hash"`
+
+	// The ABI decoding the call and the events, in addition to the ABIs of the verified contracts. Either the JSON
+	// ABI or a fragment of it, and/or the address of a verified contract, e.g. the implementation behind a proxy.
+	ABI         json.RawMessage `json:"abi,omitempty"`
+	ABIContract string          `json:"abi_contract,omitempty"`
 }
 
 type GetTransactionResult struct {
 	BlockHash   common.Hash                `json:"block_hash"`
 	BlockHeight common.JSONUint64          `json:"block_height"`
+	Index       common.JSONUint64          `json:"index"` // position of the tx in the block
 	Status      TxStatus                   `json:"status"`
 	TxHash      common.Hash                `json:"hash"`
 	Type        byte                       `json:"type"`
 	Tx          types.Tx                   `json:"transaction"`
 	Receipt     *blockchain.TxReceiptEntry `json:"receipt"`
+
+	// The decoded call and events of the verified contracts, the events are in the order of the receipt logs
+	DecodedInput *abi.DecodedCall    `json:"decoded_input,omitempty"`
+	DecodedLogs  []*abi.DecodedEvent `json:"decoded_logs,omitempty"`
 }
 
 type TxStatus string
@@ -163,13 +214,27 @@ const (
 )
 
 func (t *ThetaRPCService) GetTransaction(args *GetTransactionArgs, result *GetTransactionResult) (err error) {
+	defer t.guard("GetTransaction", &err)()
+
 	if args.Hash == "" {
 		return errors.New("Transanction hash must be specified")
 	}
+	requested, err := t.requestedABI(args.ABI, args.ABIContract)
+	if err != nil {
+		return err
+	}
+	// The decoded fields are not cached, so the result is cached regardless of the ABI
+	cacheArgs := &GetTransactionArgs{Hash: args.Hash}
+	if cached, ok := t.cache.get("GetTransaction", cacheArgs); ok {
+		*result = cached.(GetTransactionResult)
+		t.decodeTransaction(result, requested)
+		return nil
+	}
+
 	hash := common.HexToHash(args.Hash)
 	result.TxHash = hash
 
-	raw, block, found := t.chain.FindTxByHash(hash)
+	raw, block, index, found := t.chain.FindTxAndIndexByHash(hash)
 	if !found {
 		txStatus, exists := t.mempool.GetTransactionStatus(args.Hash)
 		if exists {
@@ -183,8 +248,23 @@ func (t *ThetaRPCService) GetTransaction(args *GetTransactionArgs, result *GetTr
 		}
 		return nil
 	}
+	if err := t.fillTransactionResult(raw, block, index, result); err != nil {
+		return err
+	}
+
+	if result.Status == TxStatusFinalized {
+		t.cache.add("GetTransaction", cacheArgs, *result)
+	}
+	t.decodeTransaction(result, requested)
+
+	return nil
+}
+
+// fillTransactionResult fills the result with the given tx included in the block at the given position
+func (t *ThetaRPCService) fillTransactionResult(raw common.Bytes, block *core.ExtendedBlock, index uint64, result *GetTransactionResult) error {
 	result.BlockHash = block.Hash()
 	result.BlockHeight = common.JSONUint64(block.Height)
+	result.Index = common.JSONUint64(index)
 
 	if block.Status.IsFinalized() {
 		result.Status = TxStatusFinalized
@@ -200,10 +280,137 @@ func (t *ThetaRPCService) GetTransaction(args *GetTransactionArgs, result *GetTr
 	result.Type = getTxType(tx)
 
 	// Add receipt
-	receipt, found := t.chain.FindTxReceiptByHash(hash)
+	receipt, found := t.chain.FindTxReceiptByHash(result.TxHash)
 	if found {
 		result.Receipt = receipt
 	}
+	return nil
+}
+
+// ------------------------------ GetTransactionByBlockHashAndIndex -----------------------------------
+
+type GetTransactionByBlockHashAndIndexArgs struct {
+	BlockHash common.Hash       `json:"block_hash"`
+	Index     common.JSONUint64 `json:"index"`
+
+	// The ABI decoding the call and the events, as for GetTransaction
+	ABI         json.RawMessage `json:"abi,omitempty"`
+	ABIContract string          `json:"abi_contract,omitempty"`
+}
+
+// GetTransactionByBlockHashAndIndex returns the tx at the given position in the given block
+func (t *ThetaRPCService) GetTransactionByBlockHashAndIndex(args *GetTransactionByBlockHashAndIndexArgs, result *GetTransactionResult) (err error) {
+	defer t.guard("GetTransactionByBlockHashAndIndex", &err)()
+
+	if args.BlockHash.IsEmpty() {
+		return errors.New("Block hash must be specified")
+	}
+	requested, err := t.requestedABI(args.ABI, args.ABIContract)
+	if err != nil {
+		return err
+	}
+	block, err := t.chain.FindBlock(args.BlockHash)
+	if err != nil {
+		return err
+	}
+	index := uint64(args.Index)
+	if index >= uint64(len(block.Txs)) {
+		return fmt.Errorf("Index %v is out of range, block %v has %v transactions", index, args.BlockHash.Hex(), len(block.Txs))
+	}
+
+	raw := block.Txs[index]
+	result.TxHash = crypto.Keccak256Hash(raw)
+	if err := t.fillTransactionResult(raw, block, index, result); err != nil {
+		return err
+	}
+	t.decodeTransaction(result, requested)
+
+	return nil
+}
+
+// decodeTransaction adds the call and the events decoded with the requested ABI or the ABIs of the verified
+// contracts. They are not cached, as the contracts can be verified after the tx is finalized.
+func (t *ThetaRPCService) decodeTransaction(result *GetTransactionResult, requested *abi.ABI) {
+	if (t.contractABIs == nil && requested == nil) || result.Tx == nil {
+		return
+	}
+	result.DecodedInput = t.decodeTxInput(result.Tx, requested)
+	if result.Receipt == nil {
+		return
+	}
+	decoded := false
+	logs := make([]*abi.DecodedEvent, len(result.Receipt.Logs))
+	for i, log := range result.Receipt.Logs {
+		logs[i] = t.decodeLog(log.Address, log.Topics, log.Data, requested)
+		decoded = decoded || logs[i] != nil
+	}
+	if decoded {
+		result.DecodedLogs = logs
+	}
+}
+
+// ------------------------------ GetTxTimings -----------------------------------
+
+type GetTxTimingsArgs struct {
+	Hash string `json:"hash"`
+}
+
+// The times are Unix timestamps in milliseconds, 0 if unknown
+type GetTxTimingsResult struct {
+	TxHash              common.Hash       `json:"hash"`
+	Status              TxStatus          `json:"status"`
+	BlockHash           common.Hash       `json:"block_hash"`
+	BlockHeight         common.JSONUint64 `json:"block_height"`
+	FirstSeen           common.JSONUint64 `json:"first_seen"`           // when the tx arrived at the mempool of the node
+	BlockFirstSeen      common.JSONUint64 `json:"block_first_seen"`     // when the block including the tx was received
+	BlockTimestamp      common.JSONUint64 `json:"block_timestamp"`      // the block timestamp set by the proposer
+	FinalizedAt         common.JSONUint64 `json:"finalized_at"`         // when the block was finalized
+	InclusionLatency    common.JSONUint64 `json:"inclusion_latency"`    // from the tx arrival to the block arrival
+	FinalizationLatency common.JSONUint64 `json:"finalization_latency"` // from the tx arrival to the finalization
+}
+
+var errTxTimingIndexDisabled = errors.New("The tx timing index is not enabled")
+
+func (t *ThetaRPCService) GetTxTimings(args *GetTxTimingsArgs, result *GetTxTimingsResult) (err error) {
+	defer t.guard("GetTxTimings", &err)()
+
+	if !viper.GetBool(common.CfgStorageTxTimingIndexEnabled) {
+		return errTxTimingIndexDisabled
+	}
+
+	if args.Hash == "" {
+		return errors.New("Transanction hash must be specified")
+	}
+	hash := common.HexToHash(args.Hash)
+	result.TxHash = hash
+
+	entry, found := t.chain.FindTxTimings(hash)
+	if !found {
+		result.Status = TxStatusNotFound
+		if firstSeen, ok := t.chain.FindFirstSeen(hash); ok {
+			result.Status = TxStatusPending
+			result.FirstSeen = common.JSONUint64(firstSeen)
+		}
+		return nil
+	}
+
+	result.Status = TxStatusFinalized
+	result.BlockHash = entry.BlockHash
+	result.BlockHeight = common.JSONUint64(entry.BlockHeight)
+	result.FirstSeen = common.JSONUint64(entry.FirstSeen)
+	result.BlockFirstSeen = common.JSONUint64(entry.BlockFirstSeen)
+	result.FinalizedAt = common.JSONUint64(entry.FinalizedAt)
+	if stats, ok := t.chain.FindBlockStats(entry.BlockHeight); ok && stats.BlockHash == entry.BlockHash {
+		result.BlockTimestamp = common.JSONUint64(stats.Timestamp * 1000)
+	}
+	if entry.FirstSeen != 0 {
+		if entry.BlockFirstSeen >= entry.FirstSeen {
+			result.InclusionLatency = common.JSONUint64(entry.BlockFirstSeen - entry.FirstSeen)
+		}
+		if entry.FinalizedAt >= entry.FirstSeen {
+			result.FinalizationLatency = common.JSONUint64(entry.FinalizedAt - entry.FirstSeen)
+		}
+	}
 
 	return nil
 }
@@ -218,11 +425,78 @@ type GetPendingTransactionsResult struct {
 }
 
 func (t *ThetaRPCService) GetPendingTransactions(args *GetPendingTransactionsArgs, result *GetPendingTransactionsResult) (err error) {
+	defer t.guard("GetPendingTransactions", &err)()
+
 	pendingTxHashes := t.mempool.GetCandidateTransactionHashes()
 	result.TxHashes = pendingTxHashes
 	return nil
 }
 
+// ------------------------------ GetPendingTransactionsByAddress -----------------------------------
+
+type GetPendingTransactionsByAddressArgs struct {
+	Address string `json:"address"`
+}
+
+type PendingTx struct {
+	Hash              string            `json:"hash"`
+	Sequence          common.JSONUint64 `json:"sequence"`
+	EffectiveGasPrice *common.JSONBig   `json:"effective_gas_price"`
+	Type              byte              `json:"type"`
+	Tx                types.Tx          `json:"transaction"`
+}
+
+type GetPendingTransactionsByAddressResult struct {
+	Address      string            `json:"address"`
+	NextSequence common.JSONUint64 `json:"next_sequence"`
+	Txs          []PendingTx       `json:"transactions"`
+}
+
+func (t *ThetaRPCService) GetPendingTransactionsByAddress(
+	args *GetPendingTransactionsByAddressArgs, result *GetPendingTransactionsByAddressResult) (err error) {
+	defer t.guard("GetPendingTransactionsByAddress", &err)()
+
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	address := common.HexToAddress(args.Address)
+	result.Address = args.Address
+
+	nextSequence := uint64(1)
+	ledgerState, err := t.ledger.GetScreenedSnapshot()
+	if err != nil {
+		return err
+	}
+	if account := ledgerState.GetAccount(address); account != nil {
+		nextSequence = account.Sequence + 1
+	}
+
+	result.Txs = []PendingTx{}
+	pendingTxs := t.mempool.GetPendingTransactionsByAddress(address)
+	for _, ptx := range pendingTxs {
+		tx, err := types.TxFromBytes(ptx.RawTransaction)
+		if err != nil {
+			return err
+		}
+		result.Txs = append(result.Txs, PendingTx{
+			Hash:              ptx.Hash,
+			Sequence:          common.JSONUint64(ptx.Sequence),
+			EffectiveGasPrice: (*common.JSONBig)(ptx.EffectiveGasPrice),
+			Type:              getTxType(tx),
+			Tx:                tx,
+		})
+
+		// Pending txs are sorted by sequence, so the next usable sequence is the
+		// first gap after the account sequence
+		if ptx.Sequence == nextSequence {
+			nextSequence++
+		}
+	}
+	result.NextSequence = common.JSONUint64(nextSequence)
+
+	return nil
+}
+
 // ------------------------------ GetBlock -----------------------------------
 
 type GetBlockArgs struct {
@@ -242,6 +516,15 @@ type GetBlockResult struct {
 
 type GetBlocksResult []*GetBlockResultInner
 
+// withChildren returns a copy of the cached result with the current children and status of the block,
+// which keep changing after the block is finalized
+func (r GetBlockResult) withChildren(block *core.ExtendedBlock) GetBlockResult {
+	inner := *r.GetBlockResultInner
+	inner.Children = block.Children
+	inner.Status = block.Status
+	return GetBlockResult{GetBlockResultInner: &inner}
+}
+
 type GetBlockResultInner struct {
 	ChainID            string                   `json:"chain_id"`
 	Epoch              common.JSONUint64        `json:"epoch"`
@@ -277,17 +560,31 @@ const (
 	TxTypeWithdrawStake
 	TxTypeDepositStakeTxV2
 	TxTypeStakeRewardDistributionTx
+	TxTypeParameterChangeTx
+	TxTypeSubchainCheckpointTx
+	TxTypeCrossChainSendTx
+	TxTypeCrossChainDeliverTx
+	TxTypeValidatorKeyChangeTx
+	TxTypeStakeAutoCompoundingTx
+	TxTypeScheduledTx
+	TxTypeSubchainRegistrationTx
+	TxTypeSmartContractTxV2
 )
 
 func (t *ThetaRPCService) GetBlock(args *GetBlockArgs, result *GetBlockResult) (err error) {
+	defer t.guard("GetBlock", &err)()
+
 	if args.Hash.IsEmpty() {
 		return errors.New("Block hash must be specified")
 	}
-
 	block, err := t.chain.FindBlock(args.Hash)
 	if err != nil {
 		return err
 	}
+	if cached, ok := t.cache.get("GetBlock", args); ok {
+		*result = cached.(GetBlockResult).withChildren(block)
+		return nil
+	}
 
 	result.GetBlockResultInner = &GetBlockResultInner{}
 	result.ChainID = block.ChainID
@@ -328,6 +625,9 @@ func (t *ThetaRPCService) GetBlock(args *GetBlockArgs, result *GetBlockResult) (
 
 		result.Txs = append(result.Txs, txw)
 	}
+	if block.Status.IsFinalized() {
+		t.cache.add("GetBlock", args, *result)
+	}
 	return
 }
 
@@ -338,10 +638,11 @@ type GetBlockByHeightArgs struct {
 }
 
 func (t *ThetaRPCService) GetBlockByHeight(args *GetBlockByHeightArgs, result *GetBlockResult) (err error) {
+	defer t.guard("GetBlockByHeight", &err)()
+
 	if args.Height == 0 {
 		return errors.New("Block height must be specified")
 	}
-
 	blocks := t.chain.FindBlocksByHeight(uint64(args.Height))
 
 	var block *core.ExtendedBlock
@@ -355,6 +656,10 @@ func (t *ThetaRPCService) GetBlockByHeight(args *GetBlockByHeightArgs, result *G
 	if block == nil {
 		return
 	}
+	if cached, ok := t.cache.get("GetBlockByHeight", args); ok {
+		*result = cached.(GetBlockResult).withChildren(block)
+		return nil
+	}
 
 	result.GetBlockResultInner = &GetBlockResultInner{}
 	result.ChainID = block.ChainID
@@ -396,9 +701,48 @@ func (t *ThetaRPCService) GetBlockByHeight(args *GetBlockByHeightArgs, result *G
 
 		result.Txs = append(result.Txs, txw)
 	}
+	t.cache.add("GetBlockByHeight", args, *result)
 	return
 }
 
+// ------------------------------ GetBlockByTimestamp -----------------------------------
+
+type GetBlockByTimestampArgs struct {
+	Timestamp common.JSONUint64 `json:"timestamp"` // Unix timestamp in seconds
+	Direction string            `json:"direction"` // "before" (default) for the last block at or before the timestamp, "after" for the first block at or after it
+}
+
+const (
+	TimestampDirectionBefore = "before"
+	TimestampDirectionAfter  = "after"
+)
+
+func (t *ThetaRPCService) GetBlockByTimestamp(args *GetBlockByTimestampArgs, result *GetBlockResult) (err error) {
+	defer t.guard("GetBlockByTimestamp", &err)()
+
+	if args.Timestamp == 0 {
+		return errors.New("Timestamp must be specified")
+	}
+
+	before := true
+	switch strings.ToLower(args.Direction) {
+	case "", TimestampDirectionBefore:
+	case TimestampDirectionAfter:
+		before = false
+	default:
+		return fmt.Errorf("Invalid direction: %v, should be either %v or %v", args.Direction, TimestampDirectionBefore, TimestampDirectionAfter)
+	}
+
+	start := t.chain.Root().Height
+	end := t.consensus.GetLastFinalizedBlock().Height
+	height, found := t.chain.FindFinalizedBlockHeightByTimestamp(uint64(args.Timestamp), before, start, end)
+	if !found {
+		return nil
+	}
+
+	return t.GetBlockByHeight(&GetBlockByHeightArgs{Height: common.JSONUint64(height)}, result)
+}
+
 // ------------------------------ GetBlocksByRange -----------------------------------
 
 type GetBlocksByRangeArgs struct {
@@ -407,6 +751,8 @@ type GetBlocksByRangeArgs struct {
 }
 
 func (t *ThetaRPCService) GetBlocksByRange(args *GetBlocksByRangeArgs, result *GetBlocksResult) (err error) {
+	defer t.guard("GetBlocksByRange", &err)()
+
 	if args.Start == 0 && args.End == 0 {
 		return errors.New("Starting block and ending block must be specified")
 	}
@@ -478,134 +824,1174 @@ func (t *ThetaRPCService) GetBlocksByRange(args *GetBlocksByRangeArgs, result *G
 	return
 }
 
-// ------------------------------ GetStatus -----------------------------------
+// ------------------------------ GetChainStats -----------------------------------
 
-type GetStatusArgs struct{}
+type GetChainStatsArgs struct {
+	Start common.JSONUint64 `json:"start"`
+	End   common.JSONUint64 `json:"end"`
+}
 
-type GetStatusResult struct {
-	Address                    string            `json:"address"`
-	ChainID                    string            `json:"chain_id"`
-	PeerID                     string            `json:"peer_id"`
-	LatestFinalizedBlockHash   common.Hash       `json:"latest_finalized_block_hash"`
-	LatestFinalizedBlockHeight common.JSONUint64 `json:"latest_finalized_block_height"`
-	LatestFinalizedBlockTime   *common.JSONBig   `json:"latest_finalized_block_time"`
-	LatestFinalizedBlockEpoch  common.JSONUint64 `json:"latest_finalized_block_epoch"`
-	CurrentEpoch               common.JSONUint64 `json:"current_epoch"`
-	CurrentHeight              common.JSONUint64 `json:"current_height"`
-	CurrentTime                *common.JSONBig   `json:"current_time"`
-	Syncing                    bool              `json:"syncing"`
+type GetChainStatsResult struct {
+	StartHeight                common.JSONUint64 `json:"start_height"`
+	EndHeight                  common.JSONUint64 `json:"end_height"`
+	NumBlocks                  common.JSONUint64 `json:"num_blocks"`
+	AverageBlockInterval       float64           `json:"average_block_interval"`
+	AverageTxsPerBlock         float64           `json:"average_txs_per_block"`
+	AverageGasUsedPerBlock     float64           `json:"average_gas_used_per_block"`
+	EmptyBlockRatio            float64           `json:"empty_block_ratio"`
+	AverageFinalizationLatency float64           `json:"average_finalization_latency"`
 }
 
-func (t *ThetaRPCService) GetStatus(args *GetStatusArgs, result *GetStatusResult) (err error) {
-	s := t.consensus.GetSummary()
-	result.Address = t.consensus.ID()
-	//result.PeerID = t.dispatcher.ID()
-	result.PeerID = t.dispatcher.LibP2PID() // TODO: use ID() instead after 1.3.0 upgrade
-	result.ChainID = t.consensus.Chain().ChainID
-	latestFinalizedHash := s.LastFinalizedBlock
-	var latestFinalizedBlock *core.ExtendedBlock
-	if !latestFinalizedHash.IsEmpty() {
-		result.LatestFinalizedBlockHash = latestFinalizedHash
-		latestFinalizedBlock, err = t.chain.FindBlock(latestFinalizedHash)
-		if err != nil {
-			return err
-		}
-		result.LatestFinalizedBlockEpoch = common.JSONUint64(latestFinalizedBlock.Epoch)
-		result.LatestFinalizedBlockHeight = common.JSONUint64(latestFinalizedBlock.Height)
-		result.LatestFinalizedBlockTime = (*common.JSONBig)(latestFinalizedBlock.Timestamp)
+const defaultChainStatsWindow = 100
+
+func (t *ThetaRPCService) GetChainStats(args *GetChainStatsArgs, result *GetChainStatsResult) (err error) {
+	defer t.guard("GetChainStats", &err)()
+
+	start := uint64(args.Start)
+	end := uint64(args.End)
+
+	// Default to the most recent finalized blocks
+	if end == 0 {
+		end = t.consensus.GetLastFinalizedBlock().Height
+	}
+	if start == 0 && end > defaultChainStatsWindow {
+		start = end - defaultChainStatsWindow + 1
 	}
-	result.CurrentEpoch = common.JSONUint64(s.Epoch)
-	result.CurrentTime = (*common.JSONBig)(big.NewInt(time.Now().Unix()))
 
-	maxVoteHeight := uint64(0)
-	epochVotes, err := t.consensus.State().GetEpochVotes()
+	stats, err := t.chain.GetChainStats(start, end)
 	if err != nil {
 		return err
 	}
-	if epochVotes != nil {
-		for _, v := range epochVotes.Votes() {
-			if v.Height > maxVoteHeight {
-				maxVoteHeight = v.Height
-			}
-		}
-		result.CurrentHeight = common.JSONUint64(maxVoteHeight - 1) // current finalized height is at most maxVoteHeight-1
-	}
 
-	result.Syncing = !t.consensus.HasSynced()
+	result.StartHeight = common.JSONUint64(stats.StartHeight)
+	result.EndHeight = common.JSONUint64(stats.EndHeight)
+	result.NumBlocks = common.JSONUint64(stats.NumBlocks)
+	result.AverageBlockInterval = stats.AverageBlockInterval
+	result.AverageTxsPerBlock = stats.AverageTxsPerBlock
+	result.AverageGasUsedPerBlock = stats.AverageGasUsedPerBlock
+	result.EmptyBlockRatio = stats.EmptyBlockRatio
+	result.AverageFinalizationLatency = stats.AverageFinalizationLatency
 
-	return
+	return nil
 }
 
-// ------------------------------ GetPeerURLs -----------------------------------
+// ------------------------------ GetContractStats -----------------------------------
 
-type GetPeerURLsArgs struct {
-	SkipEdgeNode bool `json:"skip_edge_node"`
+type GetContractStatsArgs struct {
+	Address string            `json:"address"`
+	Start   common.JSONUint64 `json:"start"`
+	End     common.JSONUint64 `json:"end"`
 }
 
-type GetPeerURLsResult struct {
-	PeerURLs []string `json:"peer_urls"`
+type ContractCaller struct {
+	Address common.Address    `json:"address"`
+	Calls   common.JSONUint64 `json:"calls"`
 }
 
-func (t *ThetaRPCService) GetPeerURLs(args *GetPeersArgs, result *GetPeerURLsResult) (err error) {
-	peerURLs := t.dispatcher.PeerURLs(args.SkipEdgeNode)
+// Only the txs calling the contract directly are counted, not the calls from other contracts
+type GetContractStatsResult struct {
+	Address           common.Address    `json:"address"`
+	StartHeight       common.JSONUint64 `json:"start_height"`
+	EndHeight         common.JSONUint64 `json:"end_height"`
+	Calls             common.JSONUint64 `json:"calls"`
+	Failures          common.JSONUint64 `json:"failures"`
+	GasUsed           common.JSONUint64 `json:"gas_used"`
+	UniqueCallers     common.JSONUint64 `json:"unique_callers"`
+	AverageGasPerCall float64           `json:"average_gas_per_call"`
+	FailureRate       float64           `json:"failure_rate"`
+	TopCallers        []*ContractCaller `json:"top_callers"`
+}
 
-	numPeers := len(peerURLs)
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(numPeers, func(i, j int) { peerURLs[i], peerURLs[j] = peerURLs[j], peerURLs[i] })
+const defaultContractStatsWindow = 14400 // about a day
 
-	maxNumOfPeers := 256
-	if len(peerURLs) < maxNumOfPeers {
-		maxNumOfPeers = len(peerURLs)
+var errContractStatsIndexDisabled = errors.New("The contract stats index is not enabled")
+
+func (t *ThetaRPCService) GetContractStats(args *GetContractStatsArgs, result *GetContractStatsResult) (err error) {
+	defer t.guard("GetContractStats", &err)()
+
+	if !viper.GetBool(common.CfgStorageContractStatsIndexEnabled) {
+		return errContractStatsIndexDisabled
 	}
-	result.PeerURLs = peerURLs[0:maxNumOfPeers]
 
-	return
-}
+	if !common.IsHexAddress(args.Address) {
+		return errors.New("A valid contract address must be specified")
+	}
 
-// ------------------------------ GetPeers -----------------------------------
+	start := uint64(args.Start)
+	end := uint64(args.End)
 
-type GetPeersArgs struct {
-	SkipEdgeNode bool `json:"skip_edge_node"`
-}
+	// Default to the most recent finalized blocks
+	if end == 0 {
+		end = t.consensus.GetLastFinalizedBlock().Height
+	}
+	if start == 0 && end > defaultContractStatsWindow {
+		start = end - defaultContractStatsWindow + 1
+	}
 
-type GetPeersResult struct {
-	Peers []string `json:"peers"`
-}
+	stats, err := t.chain.GetContractStats(common.HexToAddress(args.Address), start, end)
+	if err != nil {
+		return err
+	}
 
-func (t *ThetaRPCService) GetPeers(args *GetPeersArgs, result *GetPeersResult) (err error) {
-	peers := t.dispatcher.Peers(args.SkipEdgeNode)
-	result.Peers = peers
+	result.Address = stats.Contract
+	result.StartHeight = common.JSONUint64(stats.StartHeight)
+	result.EndHeight = common.JSONUint64(stats.EndHeight)
+	result.Calls = common.JSONUint64(stats.Calls)
+	result.Failures = common.JSONUint64(stats.Failures)
+	result.GasUsed = common.JSONUint64(stats.GasUsed)
+	result.UniqueCallers = common.JSONUint64(stats.UniqueCallers)
+	if stats.Calls > 0 {
+		result.AverageGasPerCall = float64(stats.GasUsed) / float64(stats.Calls)
+		result.FailureRate = float64(stats.Failures) / float64(stats.Calls)
+	}
+	result.TopCallers = []*ContractCaller{}
+	for _, caller := range stats.TopCallers {
+		result.TopCallers = append(result.TopCallers, &ContractCaller{
+			Address: caller.Address,
+			Calls:   common.JSONUint64(caller.Calls),
+		})
+	}
 
-	return
+	return nil
 }
 
-// ------------------------------ GetVcp -----------------------------------
+// ------------------------------ GetSupplyDelta -----------------------------------
 
-type GetVcpByHeightArgs struct {
-	Height common.JSONUint64 `json:"height"`
+type GetSupplyDeltaArgs struct {
+	Start common.JSONUint64 `json:"start"`
+	End   common.JSONUint64 `json:"end"`
 }
 
-type GetVcpResult struct {
-	BlockHashVcpPairs []BlockHashVcpPair
+type GetSupplyDeltaResult struct {
+	StartHeight          common.JSONUint64 `json:"start_height"`
+	EndHeight            common.JSONUint64 `json:"end_height"`
+	NumBlocks            common.JSONUint64 `json:"num_blocks"`
+	Minted               types.Coins       `json:"minted"`
+	FeesBurned           types.Coins       `json:"fees_burned"`
+	NetIssuance          types.Coins       `json:"net_issuance"`
+	CumulativeMinted     types.Coins       `json:"cumulative_minted"`
+	CumulativeFeesBurned types.Coins       `json:"cumulative_fees_burned"`
 }
 
-type BlockHashVcpPair struct {
-	BlockHash  common.Hash
-	Vcp        *core.ValidatorCandidatePool
-	HeightList *types.HeightList
-}
+var errSupplyIndexDisabled = errors.New("The supply index is not enabled")
 
-func (t *ThetaRPCService) GetVcpByHeight(args *GetVcpByHeightArgs, result *GetVcpResult) (err error) {
-	deliveredView, err := t.ledger.GetDeliveredSnapshot()
+func (t *ThetaRPCService) GetSupplyDelta(args *GetSupplyDeltaArgs, result *GetSupplyDeltaResult) (err error) {
+	defer t.guard("GetSupplyDelta", &err)()
+
+	if !viper.GetBool(common.CfgStorageSupplyIndexEnabled) {
+		return errSupplyIndexDisabled
+	}
+
+	start := uint64(args.Start)
+	end := uint64(args.End)
+
+	// Default to the most recent finalized blocks
+	if end == 0 {
+		end = t.consensus.GetLastFinalizedBlock().Height
+	}
+	if start == 0 && end > defaultChainStatsWindow {
+		start = end - defaultChainStatsWindow + 1
+	}
+
+	delta, err := t.chain.GetSupplyDelta(start, end)
 	if err != nil {
 		return err
 	}
 
-	db := deliveredView.GetDB()
-	height := uint64(args.Height)
+	result.StartHeight = common.JSONUint64(delta.StartHeight)
+	result.EndHeight = common.JSONUint64(delta.EndHeight)
+	result.NumBlocks = common.JSONUint64(delta.NumBlocks)
+	result.Minted = delta.Minted
+	result.FeesBurned = delta.FeesBurned
+	result.NetIssuance = delta.Minted.Minus(delta.FeesBurned)
+	result.CumulativeMinted = delta.CumulativeMinted
+	result.CumulativeFeesBurned = delta.CumulativeFeesBurned
 
-	blockHashVcpPairs := []BlockHashVcpPair{}
-	blocks := t.chain.FindBlocksByHeight(height)
+	return nil
+}
+
+// ------------------------------ GetLogs -----------------------------------
+
+type GetLogsArgs struct {
+	Start     common.JSONUint64 `json:"start"`
+	End       common.JSONUint64 `json:"end"`
+	Addresses []string          `json:"addresses"`
+	Topics    [][]string        `json:"topics"` // topics by position, any of the alternatives at a position matches
+
+	// The ABI decoding the events, as for GetTransaction
+	ABI         json.RawMessage `json:"abi,omitempty"`
+	ABIContract string          `json:"abi_contract,omitempty"`
+}
+
+type LogEntry struct {
+	Address     common.Address    `json:"address"`
+	Topics      []common.Hash     `json:"topics"`
+	Data        common.Bytes      `json:"data"`
+	BlockHash   common.Hash       `json:"block_hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	TxHash      common.Hash       `json:"tx_hash"`
+	TxIndex     common.JSONUint64 `json:"tx_index"`
+	LogIndex    common.JSONUint64 `json:"log_index"`
+	Decoded     *abi.DecodedEvent `json:"decoded,omitempty"` // decoded with the requested ABI or the ABI of the verified contract
+}
+
+type GetLogsResult struct {
+	StartHeight common.JSONUint64 `json:"start_height"`
+	EndHeight   common.JSONUint64 `json:"end_height"`
+	Logs        []*LogEntry       `json:"logs"`
+}
+
+func (t *ThetaRPCService) GetLogs(args *GetLogsArgs, result *GetLogsResult) (err error) {
+	defer t.guard("GetLogs", &err)()
+
+	requested, err := t.requestedABI(args.ABI, args.ABIContract)
+	if err != nil {
+		return err
+	}
+
+	start := uint64(args.Start)
+	end := uint64(args.End)
+
+	// Default to the latest finalized block
+	if end == 0 {
+		end = t.consensus.GetLastFinalizedBlock().Height
+	}
+	if start == 0 {
+		start = end
+	}
+
+	filter := newLogFilterCriteria(args.Addresses, args.Topics)
+	logs, err := t.chain.GetLogs(start, end, filter)
+	if err != nil {
+		return err
+	}
+
+	result.StartHeight = common.JSONUint64(start)
+	result.EndHeight = common.JSONUint64(end)
+	result.Logs = t.newLogEntries(logs, requested)
+
+	return nil
+}
+
+// ------------------------------ GetSlashHistory -----------------------------------
+
+type GetSlashHistoryArgs struct {
+	Address string `json:"address"`
+}
+
+type SlashRecord struct {
+	TxHash        common.Hash       `json:"tx_hash"`
+	Height        common.JSONUint64 `json:"height"`
+	Reason        string            `json:"reason"`
+	SlashedAmount types.Coins       `json:"slashed_amount"`
+	Beneficiary   common.Address    `json:"beneficiary"`
+}
+
+type GetSlashHistoryResult struct {
+	Address string         `json:"address"`
+	Records []*SlashRecord `json:"records"`
+}
+
+func (t *ThetaRPCService) GetSlashHistory(args *GetSlashHistoryArgs, result *GetSlashHistoryResult) (err error) {
+	defer t.guard("GetSlashHistory", &err)()
+
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	address := common.HexToAddress(args.Address)
+	result.Address = args.Address
+	result.Records = []*SlashRecord{}
+
+	history, _ := t.chain.FindSlashHistory(address)
+	for _, record := range history.Records {
+		result.Records = append(result.Records, &SlashRecord{
+			TxHash:        record.TxHash,
+			Height:        common.JSONUint64(record.Height),
+			Reason:        record.Reason,
+			SlashedAmount: record.SlashedAmount,
+			Beneficiary:   record.Beneficiary,
+		})
+	}
+
+	return nil
+}
+
+// ------------------------------ GetBalanceHistory -----------------------------------
+
+type GetBalanceHistoryArgs struct {
+	Address string            `json:"address"`
+	Start   common.JSONUint64 `json:"start"`
+	End     common.JSONUint64 `json:"end"`
+}
+
+type BalanceCheckpoint struct {
+	Height  common.JSONUint64 `json:"height"`
+	Balance types.Coins       `json:"balance"`
+}
+
+type GetBalanceHistoryResult struct {
+	Address  string               `json:"address"`
+	Start    common.JSONUint64    `json:"start"`
+	End      common.JSONUint64    `json:"end"`
+	Balances []*BalanceCheckpoint `json:"balances"`
+}
+
+const defaultBalanceHistoryWindow = 100000
+
+// GetBalanceHistory returns the balances of an address over a height window. The first entry is the
+// balance as of the start height, followed by an entry for each finalized block that changed the balance.
+func (t *ThetaRPCService) GetBalanceHistory(args *GetBalanceHistoryArgs, result *GetBalanceHistoryResult) (err error) {
+	defer t.guard("GetBalanceHistory", &err)()
+
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	address := common.HexToAddress(args.Address)
+	start := uint64(args.Start)
+	end := uint64(args.End)
+
+	// Default to the most recent finalized blocks
+	if end == 0 {
+		end = t.consensus.GetLastFinalizedBlock().Height
+	}
+	if start == 0 && end > defaultBalanceHistoryWindow {
+		start = end - defaultBalanceHistoryWindow + 1
+	}
+
+	checkpoints, err := t.chain.FindBalanceHistory(address, start, end)
+	if err != nil {
+		return err
+	}
+
+	result.Address = args.Address
+	result.Start = common.JSONUint64(start)
+	result.End = common.JSONUint64(end)
+	result.Balances = []*BalanceCheckpoint{}
+	for i, checkpoint := range checkpoints {
+		height := checkpoint.Height
+		if i == 0 && height < start {
+			height = start
+		}
+		result.Balances = append(result.Balances, &BalanceCheckpoint{
+			Height:  common.JSONUint64(height),
+			Balance: checkpoint.Balance,
+		})
+	}
+
+	return nil
+}
+
+// ------------------------------ SearchTransactions -----------------------------------
+
+type SearchTransactionsArgs struct {
+	StartHeight common.JSONUint64 `json:"start_height"`
+	EndHeight   common.JSONUint64 `json:"end_height"`
+	Types       []string          `json:"types"` // e.g. "send", "smart_contract", or "stake" for both deposit and withdraw stake txs
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	MinTheta    string            `json:"min_theta"` // in ThetaWei
+	MinTFuel    string            `json:"min_tfuel"` // in TFuelWei
+	Limit       common.JSONUint64 `json:"limit"`
+}
+
+type SearchTransactionEntry struct {
+	TxHash      common.Hash       `json:"hash"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	Type        string            `json:"type"`
+	From        []common.Address  `json:"from"`
+	To          []common.Address  `json:"to"`
+	Amount      types.Coins       `json:"amount"`
+}
+
+type SearchTransactionsResult struct {
+	Transactions []*SearchTransactionEntry `json:"transactions"`
+}
+
+const (
+	defaultTxSearchWindow = 1000
+	maxTxSearchResults    = 1000
+)
+
+var searchTxTypeAliases = map[string][]string{
+	blockchain.TxSearchTypeCoinbase:                {blockchain.TxSearchTypeCoinbase},
+	blockchain.TxSearchTypeSlash:                   {blockchain.TxSearchTypeSlash},
+	blockchain.TxSearchTypeSend:                    {blockchain.TxSearchTypeSend},
+	blockchain.TxSearchTypeReserveFund:             {blockchain.TxSearchTypeReserveFund},
+	blockchain.TxSearchTypeReleaseFund:             {blockchain.TxSearchTypeReleaseFund},
+	blockchain.TxSearchTypeServicePayment:          {blockchain.TxSearchTypeServicePayment},
+	blockchain.TxSearchTypeSplitRule:               {blockchain.TxSearchTypeSplitRule},
+	blockchain.TxSearchTypeSmartContract:           {blockchain.TxSearchTypeSmartContract},
+	blockchain.TxSearchTypeDepositStake:            {blockchain.TxSearchTypeDepositStake},
+	blockchain.TxSearchTypeWithdrawStake:           {blockchain.TxSearchTypeWithdrawStake},
+	blockchain.TxSearchTypeStakeRewardDistribution: {blockchain.TxSearchTypeStakeRewardDistribution},
+	"stake": {blockchain.TxSearchTypeDepositStake, blockchain.TxSearchTypeWithdrawStake},
+}
+
+func (t *ThetaRPCService) SearchTransactions(args *SearchTransactionsArgs, result *SearchTransactionsResult) (err error) {
+	defer t.guard("SearchTransactions", &err)()
+
+	filter := &blockchain.TxSearchFilter{
+		StartHeight: uint64(args.StartHeight),
+		EndHeight:   uint64(args.EndHeight),
+		Limit:       maxTxSearchResults,
+	}
+
+	// Default to the most recent finalized blocks
+	if filter.EndHeight == 0 {
+		filter.EndHeight = t.consensus.GetLastFinalizedBlock().Height
+	}
+	if filter.StartHeight == 0 && filter.EndHeight > defaultTxSearchWindow {
+		filter.StartHeight = filter.EndHeight - defaultTxSearchWindow + 1
+	}
+	if args.Limit != 0 && uint64(args.Limit) < maxTxSearchResults {
+		filter.Limit = int(args.Limit)
+	}
+
+	for _, tp := range args.Types {
+		txTypes, ok := searchTxTypeAliases[strings.ToLower(tp)]
+		if !ok {
+			return fmt.Errorf("Unknown transaction type: %v", tp)
+		}
+		filter.Types = append(filter.Types, txTypes...)
+	}
+
+	if args.From != "" {
+		from := common.HexToAddress(args.From)
+		filter.From = &from
+	}
+	if args.To != "" {
+		to := common.HexToAddress(args.To)
+		filter.To = &to
+	}
+
+	if args.MinTheta != "" || args.MinTFuel != "" {
+		minAmount := types.NewCoins(0, 0)
+		if args.MinTheta != "" {
+			if _, ok := minAmount.ThetaWei.SetString(args.MinTheta, 10); !ok {
+				return fmt.Errorf("Failed to parse min theta: %v", args.MinTheta)
+			}
+		}
+		if args.MinTFuel != "" {
+			if _, ok := minAmount.TFuelWei.SetString(args.MinTFuel, 10); !ok {
+				return fmt.Errorf("Failed to parse min tfuel: %v", args.MinTFuel)
+			}
+		}
+		filter.MinAmount = &minAmount
+	}
+
+	entries, err := t.chain.SearchTxs(filter)
+	if err != nil {
+		return err
+	}
+
+	result.Transactions = []*SearchTransactionEntry{}
+	for _, entry := range entries {
+		result.Transactions = append(result.Transactions, &SearchTransactionEntry{
+			TxHash:      entry.TxHash,
+			BlockHeight: common.JSONUint64(entry.Height),
+			Type:        entry.Type,
+			From:        entry.From,
+			To:          entry.To,
+			Amount:      entry.Amount,
+		})
+	}
+
+	return nil
+}
+
+// ------------------------------ GetRewardDistribution -----------------------------------
+
+type GetRewardDistributionArgs struct {
+	Height common.JSONUint64 `json:"height"`
+}
+
+const (
+	RewardHolderTypeValidator     = "validator"
+	RewardHolderTypeGuardian      = "guardian"
+	RewardHolderTypeEliteEdgeNode = "elite_edge_node"
+	RewardHolderTypeStaker        = "staker" // reward aggregated over all the stakes of a source, before the split rules were enabled
+)
+
+type RewardShare struct {
+	HolderType  string          `json:"holder_type"`
+	Holder      common.Address  `json:"holder"`
+	Source      common.Address  `json:"source"`
+	Reward      *common.JSONBig `json:"reward"`       // TFuelWei, before the split
+	Beneficiary common.Address  `json:"beneficiary"`  // empty if the reward is not split
+	SplitReward *common.JSONBig `json:"split_reward"` // TFuelWei redirected to the beneficiary
+}
+
+type GetRewardDistributionResult struct {
+	BlockHash    common.Hash                `json:"block_hash"`
+	Height       common.JSONUint64          `json:"height"`
+	TotalRewards map[string]*common.JSONBig `json:"total_rewards"` // holder type -> total TFuelWei reward
+	TotalSplit   *common.JSONBig            `json:"total_split"`   // total TFuelWei redirected by the split rules
+	Shares       []*RewardShare             `json:"shares"`
+}
+
+func (t *ThetaRPCService) GetRewardDistribution(args *GetRewardDistributionArgs, result *GetRewardDistributionResult) (err error) {
+	defer t.guard("GetRewardDistribution", &err)()
+
+	height := uint64(args.Height)
+	var block *core.ExtendedBlock
+	for _, b := range t.chain.FindBlocksByHeight(height) {
+		if b.Status.IsFinalized() {
+			block = b
+			break
+		}
+	}
+	if block == nil {
+		return newNotFoundError("Finalized block at height %v is not found", height)
+	}
+
+	breakdown, err := t.ledger.GetRewardBreakdown(block.Hash())
+	if err != nil {
+		return err
+	}
+
+	validatorSet := t.consensus.GetValidatorManager().GetNextValidatorSet(block.Parent)
+	isValidator := func(addr common.Address) bool {
+		if validatorSet == nil {
+			return false
+		}
+		_, err := validatorSet.GetValidator(addr)
+		return err == nil
+	}
+
+	totalRewards := map[string]*big.Int{}
+	totalSplit := big.NewInt(0)
+	result.Shares = []*RewardShare{}
+	addShares := func(entries []*exec.RewardEntry, isEliteEdgeNode bool) {
+		for _, entry := range entries {
+			holderType := RewardHolderTypeStaker
+			if isEliteEdgeNode {
+				holderType = RewardHolderTypeEliteEdgeNode
+			} else if !core.IsForkActive(t.chain.ChainID, core.ForkTheta2, height) || (!entry.Holder.IsEmpty() && isValidator(entry.Holder)) {
+				holderType = RewardHolderTypeValidator
+			} else if !entry.Holder.IsEmpty() {
+				holderType = RewardHolderTypeGuardian
+			}
+
+			if _, ok := totalRewards[holderType]; !ok {
+				totalRewards[holderType] = big.NewInt(0)
+			}
+			totalRewards[holderType].Add(totalRewards[holderType], entry.Reward)
+			totalSplit.Add(totalSplit, entry.SplitReward)
+
+			result.Shares = append(result.Shares, &RewardShare{
+				HolderType:  holderType,
+				Holder:      entry.Holder,
+				Source:      entry.Source,
+				Reward:      (*common.JSONBig)(entry.Reward),
+				Beneficiary: entry.Beneficiary,
+				SplitReward: (*common.JSONBig)(entry.SplitReward),
+			})
+		}
+	}
+	addShares(breakdown.StakerRewards, false)
+	addShares(breakdown.EliteEdgeNodeRewards, true)
+
+	result.BlockHash = block.Hash()
+	result.Height = common.JSONUint64(height)
+	result.TotalRewards = map[string]*common.JSONBig{}
+	for holderType, reward := range totalRewards {
+		result.TotalRewards[holderType] = (*common.JSONBig)(reward)
+	}
+	result.TotalSplit = (*common.JSONBig)(totalSplit)
+
+	return nil
+}
+
+// ------------------------------ GetRetentionBoundaries -----------------------------------
+
+type GetRetentionBoundariesArgs struct{}
+
+type RetentionBoundary struct {
+	Pruned                  bool              `json:"pruned"`
+	RetainedBlocks          common.JSONUint64 `json:"retained_blocks"`
+	EarliestAvailableHeight common.JSONUint64 `json:"earliest_available_height"`
+}
+
+type GetRetentionBoundariesResult struct {
+	State     RetentionBoundary `json:"state"`
+	TxIndex   RetentionBoundary `json:"tx_index"`
+	TxReceipt RetentionBoundary `json:"tx_receipt"`
+	TxLog     RetentionBoundary `json:"tx_log"`
+}
+
+func (t *ThetaRPCService) GetRetentionBoundaries(args *GetRetentionBoundariesArgs, result *GetRetentionBoundariesResult) (err error) {
+	defer t.guard("GetRetentionBoundaries", &err)()
+
+	rootHeight := t.chain.Root().Height
+
+	var statePrunedHeight uint64
+	kvStore := kvstore.NewKVStore(t.ledger.State().DB())
+	if kvStore.Get(state.StatePruningProgressKey(), &statePrunedHeight) == nil {
+		result.State.Pruned = true
+		result.State.EarliestAvailableHeight = common.JSONUint64(statePrunedHeight + 1)
+	} else {
+		result.State.EarliestAvailableHeight = common.JSONUint64(rootHeight)
+	}
+	if viper.GetBool(common.CfgStorageStatePruningEnabled) {
+		result.State.RetainedBlocks = common.JSONUint64(viper.GetInt(common.CfgStorageStatePruningRetainedBlocks))
+	}
+
+	boundaries := map[blockchain.IndexType]*RetentionBoundary{
+		blockchain.IndexTypeTxIndex:   &result.TxIndex,
+		blockchain.IndexTypeTxReceipt: &result.TxReceipt,
+		blockchain.IndexTypeTxLog:     &result.TxLog,
+	}
+	for indexType, boundary := range boundaries {
+		boundary.RetainedBlocks = common.JSONUint64(consensus.RetainedBlocksForIndex(indexType))
+		prunedHeight, pruned := t.chain.IndexPruningProgress(indexType)
+		if pruned {
+			boundary.Pruned = true
+			boundary.EarliestAvailableHeight = common.JSONUint64(prunedHeight + 1)
+		} else {
+			boundary.EarliestAvailableHeight = common.JSONUint64(rootHeight)
+		}
+	}
+
+	return nil
+}
+
+// ------------------------------ GetStatus -----------------------------------
+
+type GetStatusArgs struct{}
+
+type GetStatusResult struct {
+	Address                    string                 `json:"address"`
+	ChainID                    string                 `json:"chain_id"`
+	PeerID                     string                 `json:"peer_id"`
+	LatestFinalizedBlockHash   common.Hash            `json:"latest_finalized_block_hash"`
+	LatestFinalizedBlockHeight common.JSONUint64      `json:"latest_finalized_block_height"`
+	LatestFinalizedBlockTime   *common.JSONBig        `json:"latest_finalized_block_time"`
+	LatestFinalizedBlockEpoch  common.JSONUint64      `json:"latest_finalized_block_epoch"`
+	CurrentEpoch               common.JSONUint64      `json:"current_epoch"`
+	CurrentHeight              common.JSONUint64      `json:"current_height"`
+	CurrentTime                *common.JSONBig        `json:"current_time"`
+	Syncing                    bool                   `json:"syncing"`
+	ClockDrift                 *consensus.ClockStatus `json:"clock_drift"`
+
+	NetworkTipHeight common.JSONUint64 `json:"network_tip_height"` // estimated from the votes and the blocks received from the peers
+	BlocksBehind     common.JSONUint64 `json:"blocks_behind"`      // between the latest finalized block and the network tip
+	SyncRate         float64           `json:"sync_rate"`          // blocks finalized per second over the last few minutes
+	SyncETASecs      common.JSONUint64 `json:"sync_eta_secs"`      // 0 if synced or the sync is stalled
+	NumPeers         common.JSONUint64 `json:"num_peers"`
+
+	PeerVersions *p2ptypes.PeerVersionSummary `json:"peer_versions"` // upgrade_advised if many peers run a newer version
+}
+
+func (t *ThetaRPCService) GetStatus(args *GetStatusArgs, result *GetStatusResult) (err error) {
+	defer t.guard("GetStatus", &err)()
+
+	s := t.consensus.GetSummary()
+	result.Address = t.consensus.ID()
+	//result.PeerID = t.dispatcher.ID()
+	result.PeerID = t.dispatcher.LibP2PID() // TODO: use ID() instead after 1.3.0 upgrade
+	result.ChainID = t.consensus.Chain().ChainID
+	latestFinalizedHash := s.LastFinalizedBlock
+	var latestFinalizedBlock *core.ExtendedBlock
+	if !latestFinalizedHash.IsEmpty() {
+		result.LatestFinalizedBlockHash = latestFinalizedHash
+		latestFinalizedBlock, err = t.chain.FindBlock(latestFinalizedHash)
+		if err != nil {
+			return err
+		}
+		result.LatestFinalizedBlockEpoch = common.JSONUint64(latestFinalizedBlock.Epoch)
+		result.LatestFinalizedBlockHeight = common.JSONUint64(latestFinalizedBlock.Height)
+		result.LatestFinalizedBlockTime = (*common.JSONBig)(latestFinalizedBlock.Timestamp)
+	}
+	result.CurrentEpoch = common.JSONUint64(s.Epoch)
+	result.CurrentTime = (*common.JSONBig)(big.NewInt(time.Now().Unix()))
+
+	maxVoteHeight := uint64(0)
+	epochVotes, err := t.consensus.State().GetEpochVotes()
+	if err != nil {
+		return err
+	}
+	if epochVotes != nil {
+		for _, v := range epochVotes.Votes() {
+			if v.Height > maxVoteHeight {
+				maxVoteHeight = v.Height
+			}
+		}
+		result.CurrentHeight = common.JSONUint64(maxVoteHeight - 1) // current finalized height is at most maxVoteHeight-1
+	}
+
+	result.Syncing = !t.consensus.HasSynced()
+	result.ClockDrift = t.consensus.ClockMonitor().Status()
+
+	lfbHeight := uint64(result.LatestFinalizedBlockHeight)
+	tipHeight := lfbHeight
+	if maxVoteHeight > 0 && maxVoteHeight-1 > tipHeight {
+		tipHeight = maxVoteHeight - 1
+	}
+	if tip := t.consensus.GetTip(true); tip != nil && tip.Height > tipHeight {
+		tipHeight = tip.Height
+	}
+	result.NetworkTipHeight = common.JSONUint64(tipHeight)
+	result.BlocksBehind = common.JSONUint64(tipHeight - lfbHeight)
+	if t.syncProgress != nil {
+		result.SyncRate = t.syncProgress.rate()
+	}
+	if result.BlocksBehind > 0 && result.SyncRate > 0 {
+		result.SyncETASecs = common.JSONUint64(float64(result.BlocksBehind) / result.SyncRate)
+	}
+	result.NumPeers = common.JSONUint64(len(t.dispatcher.Peers(false)))
+	result.PeerVersions = p2ptypes.SummarizePeerVersions(version.Version, t.dispatcher.PeerCapabilities(false))
+
+	return
+}
+
+// ------------------------------ GetPeerURLs -----------------------------------
+
+type GetPeerURLsArgs struct {
+	SkipEdgeNode bool `json:"skip_edge_node"`
+}
+
+type GetPeerURLsResult struct {
+	PeerURLs []string `json:"peer_urls"`
+}
+
+func (t *ThetaRPCService) GetPeerURLs(args *GetPeersArgs, result *GetPeerURLsResult) (err error) {
+	defer t.guard("GetPeerURLs", &err)()
+
+	peerURLs := t.dispatcher.PeerURLs(args.SkipEdgeNode)
+
+	numPeers := len(peerURLs)
+	rand.Seed(time.Now().UnixNano())
+	rand.Shuffle(numPeers, func(i, j int) { peerURLs[i], peerURLs[j] = peerURLs[j], peerURLs[i] })
+
+	maxNumOfPeers := 256
+	if len(peerURLs) < maxNumOfPeers {
+		maxNumOfPeers = len(peerURLs)
+	}
+	result.PeerURLs = peerURLs[0:maxNumOfPeers]
+
+	return
+}
+
+// ------------------------------ GetPeers -----------------------------------
+
+type GetPeersArgs struct {
+	SkipEdgeNode bool `json:"skip_edge_node"`
+}
+
+type GetPeersResult struct {
+	Peers []string `json:"peers"`
+}
+
+func (t *ThetaRPCService) GetPeers(args *GetPeersArgs, result *GetPeersResult) (err error) {
+	defer t.guard("GetPeers", &err)()
+
+	peers := t.dispatcher.Peers(args.SkipEdgeNode)
+	result.Peers = peers
+
+	return
+}
+
+// ------------------------------ GetPeerCapabilities -----------------------------------
+
+type GetPeerCapabilitiesArgs struct {
+	SkipEdgeNode bool `json:"skip_edge_node"`
+}
+
+type PeerCapabilities struct {
+	PeerID       string                     `json:"peer_id"`
+	Capabilities *p2ptypes.NodeCapabilities `json:"capabilities"` // nil if not advertised by the peer
+}
+
+type GetPeerCapabilitiesResult struct {
+	Peers []PeerCapabilities `json:"peers"`
+}
+
+func (t *ThetaRPCService) GetPeerCapabilities(args *GetPeerCapabilitiesArgs, result *GetPeerCapabilitiesResult) (err error) {
+	defer t.guard("GetPeerCapabilities", &err)()
+
+	capabilities := t.dispatcher.PeerCapabilities(args.SkipEdgeNode)
+
+	result.Peers = []PeerCapabilities{}
+	for peerID, peerCapabilities := range capabilities {
+		result.Peers = append(result.Peers, PeerCapabilities{
+			PeerID:       peerID,
+			Capabilities: peerCapabilities,
+		})
+	}
+	sort.Slice(result.Peers, func(i, j int) bool {
+		return result.Peers[i].PeerID < result.Peers[j].PeerID
+	})
+
+	return
+}
+
+// ------------------------------ GetGovernanceParams -----------------------------------
+
+type GetGovernanceParamsArgs struct {
+}
+
+type GetGovernanceParamsResult struct {
+	BlockHeight    common.JSONUint64       `json:"block_height"`
+	Params         *core.GovernanceParams  `json:"params"`
+	PendingChanges []*core.ParameterChange `json:"pending_changes"`
+}
+
+func (t *ThetaRPCService) GetGovernanceParams(args *GetGovernanceParamsArgs, result *GetGovernanceParamsResult) (err error) {
+	defer t.guard("GetGovernanceParams", &err)()
+
+	finalizedView, err := t.ledger.GetFinalizedSnapshot()
+	if err != nil {
+		return err
+	}
+
+	result.BlockHeight = common.JSONUint64(finalizedView.Height())
+	result.Params = finalizedView.GetGovernanceParams()
+	result.PendingChanges = finalizedView.GetParameterChanges()
+
+	return nil
+}
+
+// ------------------------------ GetValidatorKeyChanges -----------------------------------
+
+type GetValidatorKeyChangesArgs struct {
+}
+
+type GetValidatorKeyChangesResult struct {
+	BlockHeight    common.JSONUint64          `json:"block_height"`
+	PendingChanges []*core.ValidatorKeyChange `json:"pending_changes"`
+}
+
+func (t *ThetaRPCService) GetValidatorKeyChanges(args *GetValidatorKeyChangesArgs, result *GetValidatorKeyChangesResult) (err error) {
+	defer t.guard("GetValidatorKeyChanges", &err)()
+
+	finalizedView, err := t.ledger.GetFinalizedSnapshot()
+	if err != nil {
+		return err
+	}
+
+	result.BlockHeight = common.JSONUint64(finalizedView.Height())
+	result.PendingChanges = finalizedView.GetValidatorKeyChanges()
+
+	return nil
+}
+
+// ------------------------------ GetStakeAutoCompounding -----------------------------------
+
+type GetStakeAutoCompoundingArgs struct {
+	Address string `json:"address"`
+}
+
+type GetStakeAutoCompoundingResult struct {
+	BlockHeight common.JSONUint64          `json:"block_height"`
+	Enabled     bool                       `json:"enabled"`
+	Setting     *core.StakeAutoCompounding `json:"setting"`
+}
+
+func (t *ThetaRPCService) GetStakeAutoCompounding(args *GetStakeAutoCompoundingArgs, result *GetStakeAutoCompoundingResult) (err error) {
+	defer t.guard("GetStakeAutoCompounding", &err)()
+
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	address := common.HexToAddress(args.Address)
+
+	finalizedView, err := t.ledger.GetFinalizedSnapshot()
+	if err != nil {
+		return err
+	}
+
+	result.BlockHeight = common.JSONUint64(finalizedView.Height())
+	result.Setting = finalizedView.GetStakeAutoCompounding(address)
+	result.Enabled = result.Setting != nil
+
+	return nil
+}
+
+// ------------------------------ GetRandomness -----------------------------------
+
+type GetRandomnessArgs struct {
+	Height common.JSONUint64 `json:"height"` // 0 for the last finalized block
+}
+
+type GetRandomnessResult struct {
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	BlockHash   common.Hash       `json:"block_hash"`
+	Randomness  common.Hash       `json:"randomness"` // derived by the block, returned by the precompile to the txs of the next block
+}
+
+func (t *ThetaRPCService) GetRandomness(args *GetRandomnessArgs, result *GetRandomnessResult) (err error) {
+	defer t.guard("GetRandomness", &err)()
+
+	view, block, err := t.getFinalizedView(uint64(args.Height))
+	if err != nil {
+		return err
+	}
+	if !core.IsForkActive(t.chain.ChainID, core.ForkRandomnessBeacon, block.Height) {
+		return fmt.Errorf("The randomness beacon is not active at height %v", block.Height)
+	}
+
+	result.BlockHeight = common.JSONUint64(block.Height)
+	result.BlockHash = block.Hash()
+	result.Randomness = view.GetRandomness()
+
+	return nil
+}
+
+// ------------------------------ GetBaseFee -----------------------------------
+
+type GetBaseFeeArgs struct {
+	Height common.JSONUint64 `json:"height"` // 0 for the last finalized block
+}
+
+type GetBaseFeeResult struct {
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	BlockHash   common.Hash       `json:"block_hash"`
+	BaseFee     *common.JSONBig   `json:"base_fee"` // base fee per gas in TFuelWei of the block after the given one
+}
+
+func (t *ThetaRPCService) GetBaseFee(args *GetBaseFeeArgs, result *GetBaseFeeResult) (err error) {
+	defer t.guard("GetBaseFee", &err)()
+
+	view, block, err := t.getFinalizedView(uint64(args.Height))
+	if err != nil {
+		return err
+	}
+	baseFee := exec.GetBaseFee(t.chain.ChainID, view)
+	if baseFee == nil {
+		return fmt.Errorf("The dynamic base fee is not active at height %v", block.Height+1)
+	}
+
+	result.BlockHeight = common.JSONUint64(block.Height)
+	result.BlockHash = block.Hash()
+	result.BaseFee = (*common.JSONBig)(baseFee)
+
+	return nil
+}
+
+// ------------------------------ GetStateDiff -----------------------------------
+
+// maxStateDiffAccounts caps the number of the accounts returned by a GetStateDiff call
+const maxStateDiffAccounts = 1000
+
+type GetStateDiffArgs struct {
+	FromHeight     common.JSONUint64 `json:"from_height"`
+	ToHeight       common.JSONUint64 `json:"to_height"` // 0 for the last finalized block
+	IncludeStorage bool              `json:"include_storage"`
+	MaxAccounts    common.JSONUint64 `json:"max_accounts"` // 0 for the max allowed
+}
+
+type StateDiffStorage struct {
+	Key    common.Hash `json:"key"`
+	Before common.Hash `json:"before"`
+	After  common.Hash `json:"after"`
+}
+
+type StateDiffAccount struct {
+	Address string              `json:"address"`
+	Before  *types.Account      `json:"before"` // null if the account was created
+	After   *types.Account      `json:"after"`  // null if the account was removed
+	Storage []*StateDiffStorage `json:"storage,omitempty"`
+}
+
+type GetStateDiffResult struct {
+	FromBlockHeight common.JSONUint64   `json:"from_block_height"`
+	FromBlockHash   common.Hash         `json:"from_block_hash"`
+	ToBlockHeight   common.JSONUint64   `json:"to_block_height"`
+	ToBlockHash     common.Hash         `json:"to_block_hash"`
+	Accounts        []*StateDiffAccount `json:"accounts"`
+	Truncated       bool                `json:"truncated"` // true if more accounts changed than returned
+}
+
+func (t *ThetaRPCService) GetStateDiff(args *GetStateDiffArgs, result *GetStateDiffResult) (err error) {
+	defer t.guard("GetStateDiff", &err)()
+
+	if args.FromHeight == 0 {
+		return errors.New("from_height must be specified")
+	}
+	toView, toBlock, err := t.getFinalizedView(uint64(args.ToHeight))
+	if err != nil {
+		return err
+	}
+	if uint64(args.FromHeight) > toBlock.Height {
+		return fmt.Errorf("from_height %v is above to_height %v", args.FromHeight, toBlock.Height)
+	}
+	fromView, fromBlock, err := t.getFinalizedView(uint64(args.FromHeight))
+	if err != nil {
+		return err
+	}
+
+	maxAccounts := maxStateDiffAccounts
+	if args.MaxAccounts != 0 && uint64(args.MaxAccounts) < maxStateDiffAccounts {
+		maxAccounts = int(args.MaxAccounts)
+	}
+	diffs, truncated, err := state.DiffAccounts(fromView, toView, args.IncludeStorage, maxAccounts)
+	if err != nil {
+		return err
+	}
+
+	result.FromBlockHeight = common.JSONUint64(fromBlock.Height)
+	result.FromBlockHash = fromBlock.Hash()
+	result.ToBlockHeight = common.JSONUint64(toBlock.Height)
+	result.ToBlockHash = toBlock.Hash()
+	result.Accounts = []*StateDiffAccount{}
+	for _, diff := range diffs {
+		account := &StateDiffAccount{
+			Address: diff.Address.Hex(),
+			Before:  diff.Before,
+			After:   diff.After,
+		}
+		for _, storageDiff := range diff.Storage {
+			account.Storage = append(account.Storage, &StateDiffStorage{
+				Key:    storageDiff.Key,
+				Before: storageDiff.Before,
+				After:  storageDiff.After,
+			})
+		}
+		result.Accounts = append(result.Accounts, account)
+	}
+	result.Truncated = truncated
+
+	return nil
+}
+
+// ------------------------------ GetPendingUpgrade -----------------------------------
+
+type GetPendingUpgradeArgs struct {
+}
+
+type GetPendingUpgradeResult struct {
+	Plan        *upgrade.Plan `json:"plan"`
+	Implemented bool          `json:"implemented"`
+	Halted      bool          `json:"halted"`
+}
+
+func (t *ThetaRPCService) GetPendingUpgrade(args *GetPendingUpgradeArgs, result *GetPendingUpgradeResult) (err error) {
+	defer t.guard("GetPendingUpgrade", &err)()
+
+	result.Plan = upgrade.GetPendingPlan()
+	if result.Plan == nil {
+		return nil
+	}
+
+	result.Implemented = result.Plan.IsImplemented()
+	result.Halted = upgrade.ShouldHalt(t.consensus.GetLastFinalizedBlock().Height + 1)
+
+	return nil
+}
+
+// ------------------------------ GetForkConfig -----------------------------------
+
+type GetForkConfigArgs struct {
+}
+
+type ForkInfo struct {
+	Name   string            `json:"name"`
+	Height common.JSONUint64 `json:"height"`
+	Active bool              `json:"active"`
+}
+
+type GetForkConfigResult struct {
+	ChainID     string            `json:"chain_id"`
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	Forks       []ForkInfo        `json:"forks"`
+}
+
+func (t *ThetaRPCService) GetForkConfig(args *GetForkConfigArgs, result *GetForkConfigResult) (err error) {
+	defer t.guard("GetForkConfig", &err)()
+
+	blockHeight := t.consensus.GetLastFinalizedBlock().Height
+	forkConfig := core.GetForkConfig(t.chain.ChainID)
+
+	result.ChainID = t.consensus.Chain().ChainID
+	result.BlockHeight = common.JSONUint64(blockHeight)
+	result.Forks = []ForkInfo{}
+	for _, name := range forkConfig.Names() {
+		result.Forks = append(result.Forks, ForkInfo{
+			Name:   name,
+			Height: common.JSONUint64(forkConfig.Height(name)),
+			Active: forkConfig.IsActive(name, blockHeight),
+		})
+	}
+
+	return nil
+}
+
+// ------------------------------ GetShadowReport -----------------------------------
+
+type GetShadowReportArgs struct {
+}
+
+type ShadowDivergence struct {
+	Height    common.JSONUint64 `json:"height"`
+	BlockHash common.Hash       `json:"block_hash"`
+	TxHash    common.Hash       `json:"tx_hash"`
+	Field     string            `json:"field"`
+	Expected  string            `json:"expected"`
+	Actual    string            `json:"actual"`
+}
+
+type GetShadowReportResult struct {
+	Enabled        bool               `json:"enabled"`
+	ExecutedBlocks common.JSONUint64  `json:"executed_blocks"`
+	DivergedBlocks common.JSONUint64  `json:"diverged_blocks"`
+	Divergences    []ShadowDivergence `json:"divergences"`
+}
+
+func (t *ThetaRPCService) GetShadowReport(args *GetShadowReportArgs, result *GetShadowReportResult) (err error) {
+	defer t.guard("GetShadowReport", &err)()
+
+	result.Enabled = viper.GetBool(common.CfgShadowEnabled)
+	result.Divergences = []ShadowDivergence{}
+	if !result.Enabled {
+		return nil
+	}
+
+	report := t.ledger.GetShadowReport()
+	result.ExecutedBlocks = common.JSONUint64(report.ExecutedBlocks)
+	result.DivergedBlocks = common.JSONUint64(report.DivergedBlocks)
+	for _, divergence := range report.Divergences {
+		result.Divergences = append(result.Divergences, ShadowDivergence{
+			Height:    common.JSONUint64(divergence.Height),
+			BlockHash: divergence.BlockHash,
+			TxHash:    divergence.TxHash,
+			Field:     divergence.Field,
+			Expected:  divergence.Expected,
+			Actual:    divergence.Actual,
+		})
+	}
+
+	return nil
+}
+
+// ------------------------------ GetVcp -----------------------------------
+
+type GetVcpByHeightArgs struct {
+	Height common.JSONUint64 `json:"height"`
+}
+
+type GetVcpResult struct {
+	BlockHashVcpPairs []BlockHashVcpPair
+}
+
+type BlockHashVcpPair struct {
+	BlockHash  common.Hash
+	Vcp        *core.ValidatorCandidatePool
+	HeightList *types.HeightList
+}
+
+func (t *ThetaRPCService) GetVcpByHeight(args *GetVcpByHeightArgs, result *GetVcpResult) (err error) {
+	defer t.guard("GetVcpByHeight", &err)()
+
+	deliveredView, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	db := deliveredView.GetDB()
+	height := uint64(args.Height)
+
+	blockHashVcpPairs := []BlockHashVcpPair{}
+	blocks := t.chain.FindBlocksByHeight(height)
 	for _, b := range blocks {
 		blockHash := b.Hash()
 		stateRoot := b.StateHash
@@ -643,6 +2029,8 @@ type BlockHashGcpPair struct {
 }
 
 func (t *ThetaRPCService) GetGcpByHeight(args *GetGcpByHeightArgs, result *GetGcpResult) (err error) {
+	defer t.guard("GetGcpByHeight", &err)()
+
 	deliveredView, err := t.ledger.GetDeliveredSnapshot()
 	if err != nil {
 		return err
@@ -672,19 +2060,84 @@ func (t *ThetaRPCService) GetGcpByHeight(args *GetGcpByHeightArgs, result *GetGc
 	return nil
 }
 
+// ------------------------------ GetStakeComposition -----------------------------------
+
+// maxStakeCompositionCheckpoints caps the number of the checkpoints returned by a GetStakeComposition call,
+// the export_stakes tool exports longer ranges
+const maxStakeCompositionCheckpoints = 100
+
+type GetStakeCompositionArgs struct {
+	StartHeight common.JSONUint64 `json:"start_height"`
+	EndHeight   common.JSONUint64 `json:"end_height"` // inclusive
+}
+
+type GetStakeCompositionResult struct {
+	Checkpoints    []*snapshot.StakeComposition `json:"checkpoints"`
+	SkippedHeights []common.JSONUint64          `json:"skipped_heights"` // checkpoints without a finalized block or state
+}
+
+func (t *ThetaRPCService) GetStakeComposition(args *GetStakeCompositionArgs, result *GetStakeCompositionResult) (err error) {
+	defer t.guard("GetStakeComposition", &err)()
+
+	start, end := uint64(args.StartHeight), uint64(args.EndHeight)
+	if end < start {
+		return fmt.Errorf("end_height %v is below start_height %v", end, start)
+	}
+	if end-start >= maxStakeCompositionCheckpoints*uint64(common.CheckpointInterval) {
+		return fmt.Errorf("the range spans more than %v checkpoints", maxStakeCompositionCheckpoints)
+	}
+
+	deliveredView, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		return err
+	}
+
+	result.Checkpoints = []*snapshot.StakeComposition{}
+	skippedHeights, err := snapshot.WalkStakeCompositions(deliveredView.GetDB(), t.chain, start, end,
+		func(composition *snapshot.StakeComposition) error {
+			result.Checkpoints = append(result.Checkpoints, composition)
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	result.SkippedHeights = []common.JSONUint64{}
+	for _, height := range skippedHeights {
+		result.SkippedHeights = append(result.SkippedHeights, common.JSONUint64(height))
+	}
+
+	return nil
+}
+
 // ------------------------------ GetGuardianKey -----------------------------------
 
-type GetGuardianInfoArgs struct{}
+type GetGuardianInfoArgs struct {
+	Address  string `json:"address"`  // Optional, the address of a key in the node keystore. Default to the node key
+	Password string `json:"password"` // The password of the keystore key, required if Address is specified
+}
 
 type GetGuardianInfoResult struct {
 	BLSPubkey string
 	BLSPop    string
 	Address   string
 	Signature string
+	Summary   string
 }
 
 func (t *ThetaRPCService) GetGuardianInfo(args *GetGuardianInfoArgs, result *GetGuardianInfoResult) (err error) {
+	defer t.guard("GetGuardianInfo", &err)()
+
 	privKey := t.consensus.PrivateKey()
+	if args.Address != "" {
+		address := common.HexToAddress(args.Address)
+		if address != privKey.PublicKey().Address() {
+			privKey, err = loadKeystoreKey(address, args.Password)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	blsKey, err := bls.GenKey(strings.NewReader(common.Bytes2Hex(privKey.PublicKey().ToBytes())))
 	if err != nil {
 		return fmt.Errorf("Failed to get BLS key: %v", err.Error())
@@ -700,10 +2153,33 @@ func (t *ThetaRPCService) GetGuardianInfo(args *GetGuardianInfoArgs, result *Get
 		return fmt.Errorf("Failed to generate signature: %v", err.Error())
 	}
 	result.Signature = hex.EncodeToString(sig.ToBytes())
+	result.Summary = result.Address + result.BLSPubkey + result.BLSPop + result.Signature
 
 	return nil
 }
 
+// loadKeystoreKey loads the private key of the given address from the encrypted keystore of the node
+func loadKeystoreKey(address common.Address, password string) (*crypto.PrivateKey, error) {
+	keyPath := viper.GetString(common.CfgKeyPath)
+	if keyPath == "" {
+		keyPath = viper.GetString(common.CfgConfigPath)
+	}
+	if keyPath == "" {
+		keyPath = filepath.Dir(viper.ConfigFileUsed())
+	}
+
+	keysDir := filepath.Join(keyPath, "key")
+	keystore, err := ks.NewKeystoreEncrypted(keysDir, ks.StandardScryptN, ks.StandardScryptP)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open keystore: %v", err)
+	}
+	key, err := keystore.GetKey(address, password)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load key for %v: %v", address.Hex(), err)
+	}
+	return key.PrivateKey, nil
+}
+
 // ------------------------------ GetEenp -----------------------------------
 
 type GetEenpByHeightArgs struct {
@@ -720,6 +2196,8 @@ type BlockHashEenpPair struct {
 }
 
 func (t *ThetaRPCService) GetEenpByHeight(args *GetEenpByHeightArgs, result *GetEenpResult) (err error) {
+	defer t.guard("GetEenpByHeight", &err)()
+
 	deliveredView, err := t.ledger.GetDeliveredSnapshot()
 	if err != nil {
 		return err
@@ -768,6 +2246,8 @@ type BlockHashStakeRewardDistributionRuleSetPair struct {
 
 func (t *ThetaRPCService) GetStakeRewardDistributionByHeight(
 	args *GetStakeRewardDistributionRuleSetByHeightArgs, result *GetStakeRewardDistributionRuleSetResult) (err error) {
+	defer t.guard("GetStakeRewardDistributionByHeight", &err)()
+
 	deliveredView, err := t.ledger.GetDeliveredSnapshot()
 	if err != nil {
 		return err
@@ -820,6 +2300,8 @@ type GetEliteEdgeNodeStakeReturnsByHeightResult struct {
 
 func (t *ThetaRPCService) GetEliteEdgeNodeStakeReturnsByHeight(
 	args *GetEliteEdgeNodeStakeReturnsByHeightArgs, result *GetEliteEdgeNodeStakeReturnsByHeightResult) (err error) {
+	defer t.guard("GetEliteEdgeNodeStakeReturnsByHeight", &err)()
+
 	deliveredView, err := t.ledger.GetDeliveredSnapshot()
 	if err != nil {
 		return err
@@ -847,6 +2329,8 @@ type GetAllPendingEliteEdgeNodeStakeReturnsResult struct {
 
 func (t *ThetaRPCService) GetAllPendingEliteEdgeNodeStakeReturns(
 	args *GetAllPendingEliteEdgeNodeStakeReturnsArgs, result *GetAllPendingEliteEdgeNodeStakeReturnsResult) (err error) {
+	defer t.guard("GetAllPendingEliteEdgeNodeStakeReturns", &err)()
+
 	deliveredView, err := t.ledger.GetDeliveredSnapshot()
 	if err != nil {
 		return err
@@ -855,10 +2339,11 @@ func (t *ThetaRPCService) GetAllPendingEliteEdgeNodeStakeReturns(
 	eenHeightStakeReturnsPairs := []HeightStakeReturnsPair{}
 	cb := func(k, v common.Bytes) bool {
 		srList := []state.StakeWithHolder{}
-		err := types.FromBytes(v, &srList)
+		err = types.FromBytes(v, &srList)
 		if err != nil {
-			log.Panicf("GetAllPendingEliteEdgeNodeStakeReturns: Error reading StakeWithHolder %X, error: %v",
+			err = fmt.Errorf("GetAllPendingEliteEdgeNodeStakeReturns: Error reading StakeWithHolder %X, error: %v",
 				v, err.Error())
+			return false
 		}
 
 		eenHeightStakeReturnsPairs = append(eenHeightStakeReturnsPairs, HeightStakeReturnsPair{
@@ -870,6 +2355,9 @@ func (t *ThetaRPCService) GetAllPendingEliteEdgeNodeStakeReturns(
 
 	prefix := state.EliteEdgeNodeStakeReturnsKeyPrefix()
 	deliveredView.Traverse(prefix, cb)
+	if err != nil {
+		return err
+	}
 
 	result.EENHeightStakeReturnsPairs = eenHeightStakeReturnsPairs
 
@@ -905,6 +2393,24 @@ func getTxType(tx types.Tx) byte {
 		t = TxTypeDepositStakeTxV2
 	case *types.StakeRewardDistributionTx:
 		t = TxTypeStakeRewardDistributionTx
+	case *types.ParameterChangeTx:
+		t = TxTypeParameterChangeTx
+	case *types.SubchainCheckpointTx:
+		t = TxTypeSubchainCheckpointTx
+	case *types.CrossChainSendTx:
+		t = TxTypeCrossChainSendTx
+	case *types.CrossChainDeliverTx:
+		t = TxTypeCrossChainDeliverTx
+	case *types.ValidatorKeyChangeTx:
+		t = TxTypeValidatorKeyChangeTx
+	case *types.StakeAutoCompoundingTx:
+		t = TxTypeStakeAutoCompoundingTx
+	case *types.ScheduledTx:
+		t = TxTypeScheduledTx
+	case *types.SubchainRegistrationTx:
+		t = TxTypeSubchainRegistrationTx
+	case *types.SmartContractTxV2:
+		t = TxTypeSmartContractTxV2
 	}
 
 	return t
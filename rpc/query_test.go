@@ -0,0 +1,265 @@
+package rpc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/consensus"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/mempool"
+	"github.com/thetatoken/theta/store/database/backend"
+	"github.com/thetatoken/theta/store/kvstore"
+	ks "github.com/thetatoken/theta/wallet/softwallet/keystore"
+)
+
+func TestGetGuardianInfo(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	keyPath, err := ioutil.TempDir("", "guardian_info")
+	require.Nil(err)
+	defer os.RemoveAll(keyPath)
+	defer viper.Set(common.CfgKeyPath, viper.GetString(common.CfgKeyPath))
+	viper.Set(common.CfgKeyPath, keyPath)
+
+	// A guardian key in the node keystore other than the node key
+	keystore, err := ks.NewKeystoreEncrypted(filepath.Join(keyPath, "key"), ks.LightScryptN, ks.LightScryptP)
+	require.Nil(err)
+	guardianKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(err)
+	require.Nil(keystore.StoreKey(ks.NewKey(guardianKey), "qwertyuiop"))
+
+	core.ResetTestBlocks()
+	nodeKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(err)
+	engine := consensus.NewConsensusEngine(nodeKey, kvstore.NewKVStore(backend.NewMemDatabase()),
+		blockchain.CreateTestChain(), nil, nil)
+	service := &ThetaRPCService{
+		consensus: engine,
+		breakers:  NewCircuitBreakers(0.5, 1, time.Minute, time.Minute, 0, ""),
+	}
+
+	// Default to the node key
+	result := &GetGuardianInfoResult{}
+	require.Nil(service.GetGuardianInfo(&GetGuardianInfoArgs{}, result))
+	assert.Equal(nodeKey.PublicKey().Address().Hex(), result.Address)
+	assert.Equal(result.Address+result.BLSPubkey+result.BLSPop+result.Signature, result.Summary)
+	nodeSummary, err := parseHolder(core.StakeForGuardian, result.Summary)
+	require.Nil(err)
+	assert.Equal(nodeKey.PublicKey().Address(), nodeSummary.Address)
+
+	// The node key does not need to be in the keystore
+	result = &GetGuardianInfoResult{}
+	require.Nil(service.GetGuardianInfo(&GetGuardianInfoArgs{Address: nodeKey.PublicKey().Address().Hex()}, result))
+	assert.Equal(nodeKey.PublicKey().Address().Hex(), result.Address)
+
+	// The summary of the keystore key is signed by the key, with the BLS key derived from it
+	result = &GetGuardianInfoResult{}
+	require.Nil(service.GetGuardianInfo(&GetGuardianInfoArgs{
+		Address:  guardianKey.PublicKey().Address().Hex(),
+		Password: "qwertyuiop",
+	}, result))
+	assert.Equal(guardianKey.PublicKey().Address().Hex(), result.Address)
+	guardianSummary, err := parseHolder(core.StakeForGuardian, result.Summary)
+	require.Nil(err)
+	assert.Equal(guardianKey.PublicKey().Address(), guardianSummary.Address)
+	assert.NotEqual(nodeSummary.BlsPubkey.ToBytes(), guardianSummary.BlsPubkey.ToBytes())
+
+	// Wrong password and unknown addresses
+	assert.NotNil(service.GetGuardianInfo(&GetGuardianInfoArgs{
+		Address:  guardianKey.PublicKey().Address().Hex(),
+		Password: "wrong",
+	}, &GetGuardianInfoResult{}))
+	unknownKey, _, err := crypto.GenerateKeyPair()
+	require.Nil(err)
+	assert.NotNil(service.GetGuardianInfo(&GetGuardianInfoArgs{
+		Address:  unknownKey.PublicKey().Address().Hex(),
+		Password: "qwertyuiop",
+	}, &GetGuardianInfoResult{}))
+}
+
+func TestGetAccountAfterTx(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	core.ResetTestBlocks()
+	chain := blockchain.CreateTestChain()
+	db := backend.NewMemDatabase()
+	address := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	// The account has 100 TFuel wei in the finalized state, and 90 in the screened state after a pending tx
+	stateRoot := func(height uint64, balance int64) common.Hash {
+		view := state.NewStoreView(height, common.Hash{}, db)
+		view.SetAccount(address, &types.Account{Address: address, Balance: types.NewCoins(0, balance)})
+		return view.Save()
+	}
+	finalizedRoot := stateRoot(1, 100)
+	screenedRoot := stateRoot(2, 90)
+	l := ledger.NewLedger(chain.ChainID, db, chain, nil, nil, mempool.CreateMempool(nil, nil))
+	require.True(l.ResetState(&core.Block{BlockHeader: &core.BlockHeader{ChainID: chain.ChainID, Height: 2, StateHash: screenedRoot}}).IsOK())
+	require.True(l.FinalizeState(1, finalizedRoot).IsOK())
+
+	// finalizedTx is in the finalized block a1, pendingTx in its valid child a2
+	finalizedTx, pendingTx := common.Bytes("finalized tx"), common.Bytes("pending tx")
+	addBlock := func(name, parent string, tx common.Bytes) *core.ExtendedBlock {
+		block := core.CreateTestBlock(name, parent)
+		block.Txs = []common.Bytes{tx}
+		_, err := chain.AddBlock(block)
+		require.Nil(err)
+		eb := chain.MarkBlockValid(block.Hash())
+		chain.AddTxsToIndex(eb, true)
+		return eb
+	}
+	a1 := addBlock("a1", "a0", finalizedTx)
+	addBlock("a2", "a1", pendingTx)
+	require.Nil(chain.FinalizePreviousBlocks(a1.Hash()))
+
+	service := &ThetaRPCService{
+		ledger:   l,
+		chain:    chain,
+		mempool:  mempool.CreateMempool(nil, nil),
+		breakers: NewCircuitBreakers(0.5, 1, time.Minute, time.Minute, 0, ""),
+	}
+	getBalance := func(args *GetAccountArgs) int64 {
+		args.Address = address.Hex()
+		result := &GetAccountResult{}
+		require.Nil(service.GetAccount(args, result))
+		return result.Balance.TFuelWei.Int64()
+	}
+
+	assert.Equal(int64(100), getBalance(&GetAccountArgs{}))
+	assert.Equal(int64(90), getBalance(&GetAccountArgs{Preview: true}))
+
+	// The effect of a tx not finalized yet is only in the screened state
+	assert.Equal(int64(90), getBalance(&GetAccountArgs{AfterTx: crypto.Keccak256Hash(pendingTx).Hex()}))
+	assert.Equal(int64(100), getBalance(&GetAccountArgs{AfterTx: crypto.Keccak256Hash(finalizedTx).Hex()}))
+
+	// Unknown txs, e.g. dropped from the mempool, fall back to the finalized state
+	assert.Equal(int64(100), getBalance(&GetAccountArgs{AfterTx: crypto.Keccak256Hash([]byte("unknown tx")).Hex()}))
+}
+
+func TestGetTransactionByBlockHashAndIndex(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	core.ResetTestBlocks()
+	chain := blockchain.CreateTestChain()
+	txs := []common.Bytes{}
+	for seq := uint64(1); seq <= 3; seq++ {
+		raw, err := types.TxToBytes(&types.SendTx{
+			Fee:     types.NewCoins(0, 1),
+			Inputs:  []types.TxInput{{Address: common.HexToAddress("0x1111111111111111111111111111111111111111"), Sequence: seq}},
+			Outputs: []types.TxOutput{{Address: common.HexToAddress("0x2222222222222222222222222222222222222222")}},
+		})
+		require.Nil(err)
+		txs = append(txs, raw)
+	}
+	block := core.CreateTestBlock("a1", "a0")
+	block.Txs = txs
+	_, err := chain.AddBlock(block)
+	require.Nil(err)
+	chain.AddTxsToIndex(chain.MarkBlockValid(block.Hash()), true)
+
+	service := &ThetaRPCService{
+		chain:    chain,
+		mempool:  mempool.CreateMempool(nil, nil),
+		breakers: NewCircuitBreakers(0.5, 1, time.Minute, time.Minute, 0, ""),
+	}
+	result := &GetTransactionResult{}
+	require.Nil(service.GetTransactionByBlockHashAndIndex(&GetTransactionByBlockHashAndIndexArgs{
+		BlockHash: block.Hash(), Index: 1}, result))
+	assert.Equal(crypto.Keccak256Hash(txs[1]), result.TxHash)
+	assert.Equal(common.JSONUint64(1), result.Index)
+	assert.Equal(block.Hash(), result.BlockHash)
+	assert.Equal(uint64(2), result.Tx.(*types.SendTx).Inputs[0].Sequence)
+
+	assert.NotNil(service.GetTransactionByBlockHashAndIndex(&GetTransactionByBlockHashAndIndexArgs{
+		BlockHash: block.Hash(), Index: 3}, &GetTransactionResult{}))
+	assert.NotNil(service.GetTransactionByBlockHashAndIndex(&GetTransactionByBlockHashAndIndexArgs{
+		BlockHash: crypto.Keccak256Hash([]byte("unknown")), Index: 0}, &GetTransactionResult{}))
+
+	// The position is returned by GetTransaction too
+	result = &GetTransactionResult{}
+	require.Nil(service.GetTransaction(&GetTransactionArgs{Hash: crypto.Keccak256Hash(txs[2]).Hex()}, result))
+	assert.Equal(common.JSONUint64(2), result.Index)
+	assert.Equal(TxStatus(TxStatusPending), result.Status)
+}
+
+func TestGetContractStats(t *testing.T) {
+	assert := assert.New(t)
+
+	service := &ThetaRPCService{
+		chain:    blockchain.CreateTestChain(),
+		breakers: NewCircuitBreakers(0.5, 1, time.Minute, time.Minute, 0, ""),
+	}
+	args := &GetContractStatsArgs{Address: "0x1111111111111111111111111111111111111111", Start: 1, End: 100}
+
+	// The contract stats index is opt-in
+	defer viper.Set(common.CfgStorageContractStatsIndexEnabled, viper.Get(common.CfgStorageContractStatsIndexEnabled))
+	viper.Set(common.CfgStorageContractStatsIndexEnabled, false)
+	assert.Equal(errContractStatsIndexDisabled, service.GetContractStats(args, &GetContractStatsResult{}))
+	viper.Set(common.CfgStorageContractStatsIndexEnabled, true)
+
+	result := &GetContractStatsResult{}
+	assert.Nil(service.GetContractStats(args, result))
+	assert.Equal(common.JSONUint64(0), result.Calls)
+	assert.Equal(0, len(result.TopCallers))
+}
+
+func TestGetSupplyDelta(t *testing.T) {
+	assert := assert.New(t)
+
+	service := &ThetaRPCService{
+		chain:    blockchain.CreateTestChain(),
+		breakers: NewCircuitBreakers(0.5, 1, time.Minute, time.Minute, 0, ""),
+	}
+	args := &GetSupplyDeltaArgs{Start: 1, End: 100}
+
+	// The supply index is opt-in
+	defer viper.Set(common.CfgStorageSupplyIndexEnabled, viper.Get(common.CfgStorageSupplyIndexEnabled))
+	viper.Set(common.CfgStorageSupplyIndexEnabled, false)
+	assert.Equal(errSupplyIndexDisabled, service.GetSupplyDelta(args, &GetSupplyDeltaResult{}))
+	viper.Set(common.CfgStorageSupplyIndexEnabled, true)
+
+	result := &GetSupplyDeltaResult{}
+	assert.Nil(service.GetSupplyDelta(args, result))
+	assert.Equal(common.JSONUint64(0), result.NumBlocks)
+}
+
+func TestGetTxTimings(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chain := blockchain.CreateTestChain()
+	require.Nil(chain.EnableFirstSeen())
+	service := &ThetaRPCService{
+		chain:    chain,
+		breakers: NewCircuitBreakers(0.5, 1, time.Minute, time.Minute, 0, ""),
+	}
+	hash := crypto.Keccak256Hash([]byte("tx1"))
+	args := &GetTxTimingsArgs{Hash: hash.Hex()}
+
+	// The tx timing index is opt-in
+	defer viper.Set(common.CfgStorageTxTimingIndexEnabled, viper.Get(common.CfgStorageTxTimingIndexEnabled))
+	viper.Set(common.CfgStorageTxTimingIndexEnabled, false)
+	assert.Equal(errTxTimingIndexDisabled, service.GetTxTimings(args, &GetTxTimingsResult{}))
+	viper.Set(common.CfgStorageTxTimingIndexEnabled, true)
+
+	chain.RecordFirstSeen(hash, time.Unix(1000, 0))
+	result := &GetTxTimingsResult{}
+	assert.Nil(service.GetTxTimings(args, result))
+	assert.Equal(TxStatus(TxStatusPending), result.Status)
+	assert.Equal(common.JSONUint64(1000000), result.FirstSeen)
+}
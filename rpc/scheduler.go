@@ -0,0 +1,354 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/rpc"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/thetatoken/theta/common/metrics"
+)
+
+// RequestPriority is the priority class of an RPC request. Requests of a higher priority class
+// are always dispatched to the workers before the requests of lower classes.
+type RequestPriority int
+
+const (
+	// PriorityAdmin is for the node operation calls, e.g. GetStatus, which should stay responsive under load
+	PriorityAdmin RequestPriority = iota
+	// PriorityBroadcast is for the transaction broadcast calls
+	PriorityBroadcast
+	// PriorityQuery is for all the other calls, including the heavy analytic queries
+	PriorityQuery
+
+	numPriorities
+)
+
+func (p RequestPriority) String() string {
+	switch p {
+	case PriorityAdmin:
+		return "admin"
+	case PriorityBroadcast:
+		return "broadcast"
+	default:
+		return "query"
+	}
+}
+
+var methodPriorities = map[string]RequestPriority{
+	"theta.GetStatus":                    PriorityAdmin,
+	"theta.GetVersion":                   PriorityAdmin,
+	"theta.GetPeers":                     PriorityAdmin,
+	"theta.GetPeerCapabilities":          PriorityAdmin,
+	"theta.GetPeerURLs":                  PriorityAdmin,
+	"theta.GetGuardianInfo":              PriorityAdmin,
+	"theta.GetSnapshotSchedule":          PriorityAdmin,
+	"theta.UpdateSnapshotSchedule":       PriorityAdmin,
+	"theta.BackupDB":                     PriorityAdmin,
+	"theta.GetDBBackupStatus":            PriorityAdmin,
+	"theta.BroadcastRawTransaction":      PriorityBroadcast,
+	"theta.BroadcastRawTransactionAsync": PriorityBroadcast,
+}
+
+// getRequestMethods returns the methods called by a JSON-RPC request body, nil if the body is malformed.
+func getRequestMethods(body []byte) []string {
+	type call struct {
+		Method string `json:"method"`
+	}
+
+	calls := []call{}
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		if err := json.Unmarshal(body, &calls); err != nil {
+			return nil
+		}
+	} else {
+		c := call{}
+		if err := json.Unmarshal(body, &c); err != nil {
+			return nil
+		}
+		calls = append(calls, c)
+	}
+
+	methods := []string{}
+	for _, c := range calls {
+		methods = append(methods, c.Method)
+	}
+	return methods
+}
+
+// getRequestPriority determines the priority of a JSON-RPC request body. A batch request gets the
+// lowest priority of its calls, so a batch can not be used to promote heavy queries.
+func getRequestPriority(body []byte) RequestPriority {
+	return getMethodsPriority(getRequestMethods(body))
+}
+
+func getMethodsPriority(methods []string) RequestPriority {
+	if len(methods) == 0 {
+		return PriorityQuery
+	}
+
+	priority := PriorityAdmin
+	for _, method := range methods {
+		p, ok := methodPriorities[method]
+		if !ok {
+			p = PriorityQuery
+		}
+		if p > priority {
+			priority = p
+		}
+	}
+	return priority
+}
+
+const (
+	jobQueued int32 = iota
+	jobRunning
+	jobCancelled
+)
+
+type rpcJob struct {
+	ctx      context.Context
+	run      func()
+	done     chan struct{}
+	queuedAt time.Time
+	state    int32 // jobQueued, jobRunning or jobCancelled, updated atomically
+}
+
+// start marks the job as running. It returns false if the job has been cancelled, in which case the
+// job must not run, since its request may have already returned.
+func (job *rpcJob) start() bool {
+	return job.ctx.Err() == nil && atomic.CompareAndSwapInt32(&job.state, jobQueued, jobRunning)
+}
+
+// cancel marks the job as cancelled. It returns false if the job is already running.
+func (job *rpcJob) cancel() bool {
+	return atomic.CompareAndSwapInt32(&job.state, jobQueued, jobCancelled)
+}
+
+// RequestScheduler runs the RPC handlers in a bounded worker pool, dispatching the requests by
+// their priority classes.
+type RequestScheduler struct {
+	numWorkers int
+	queues     [numPriorities]chan *rpcJob
+	breakers   *CircuitBreakers
+
+	queueDepths [numPriorities]metrics.Gauge
+	waitTimers  [numPriorities]metrics.Timer
+	rejected    [numPriorities]metrics.Counter
+	panics      metrics.Counter
+}
+
+// NewRequestScheduler creates a new instance of RequestScheduler
+func NewRequestScheduler(numWorkers int, maxQueueDepth int, chainID string) *RequestScheduler {
+	if numWorkers <= 0 {
+		numWorkers = 1
+	}
+	s := &RequestScheduler{
+		numWorkers: numWorkers,
+		panics:     metrics.GetOrRegisterCounter(rpcMetricName(chainID, "panics"), nil),
+	}
+	for p := RequestPriority(0); p < numPriorities; p++ {
+		s.queues[p] = make(chan *rpcJob, maxQueueDepth)
+		s.queueDepths[p] = metrics.GetOrRegisterGauge(rpcMetricName(chainID, fmt.Sprintf("queue/%v", p)), nil)
+		s.waitTimers[p] = metrics.GetOrRegisterTimer(rpcMetricName(chainID, fmt.Sprintf("wait/%v", p)), nil)
+		s.rejected[p] = metrics.GetOrRegisterCounter(rpcMetricName(chainID, fmt.Sprintf("rejected/%v", p)), nil)
+	}
+	return s
+}
+
+// Start starts the workers, which exit when the context is cancelled.
+func (s *RequestScheduler) Start(ctx context.Context) {
+	for i := 0; i < s.numWorkers; i++ {
+		go s.workerLoop(ctx)
+	}
+}
+
+func (s *RequestScheduler) workerLoop(ctx context.Context) {
+	for {
+		job := s.nextJob(ctx)
+		if job == nil {
+			return
+		}
+		if job.start() { // Skip the requests that have timed out while waiting in the queue
+			s.runJob(job)
+		}
+		close(job.done)
+	}
+}
+
+// runJob runs the job, recovering from the panics outside of the RPC methods, e.g. in the codec, so
+// that they can not take down the worker
+func (s *RequestScheduler) runJob(job *rpcJob) {
+	defer func() {
+		if p := recover(); p != nil {
+			logger.Errorf("RPC request panicked: %v\n%s", p, debug.Stack())
+			s.panics.Inc(1)
+		}
+	}()
+	job.run()
+}
+
+// nextJob returns the job of the highest priority, blocking until a job is available.
+func (s *RequestScheduler) nextJob(ctx context.Context) *rpcJob {
+	for p := RequestPriority(0); p < numPriorities; p++ {
+		select {
+		case job := <-s.queues[p]:
+			s.dequeued(p, job)
+			return job
+		default:
+		}
+	}
+
+	select {
+	case job := <-s.queues[PriorityAdmin]:
+		s.dequeued(PriorityAdmin, job)
+		return job
+	case job := <-s.queues[PriorityBroadcast]:
+		s.dequeued(PriorityBroadcast, job)
+		return job
+	case job := <-s.queues[PriorityQuery]:
+		s.dequeued(PriorityQuery, job)
+		return job
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+func (s *RequestScheduler) dequeued(p RequestPriority, job *rpcJob) {
+	s.queueDepths[p].Update(int64(len(s.queues[p])))
+	s.waitTimers[p].UpdateSince(job.queuedAt)
+}
+
+// schedule queues the given function, and blocks until it has been run by a worker, or the
+// context is done before a worker picks it up. Once started, the function is waited for even
+// if the context is done, so it never runs after schedule returns. It returns false if the
+// queue of the priority class is full.
+func (s *RequestScheduler) schedule(ctx context.Context, p RequestPriority, run func()) bool {
+	job := &rpcJob{
+		ctx:      ctx,
+		run:      run,
+		done:     make(chan struct{}),
+		queuedAt: time.Now(),
+	}
+
+	select {
+	case s.queues[p] <- job:
+		s.queueDepths[p].Update(int64(len(s.queues[p])))
+	default:
+		s.rejected[p].Inc(1)
+		return false
+	}
+
+	select {
+	case <-job.done:
+	case <-ctx.Done():
+		if !job.cancel() {
+			<-job.done
+		}
+	}
+	return true
+}
+
+// Handler wraps the given JSON-RPC handler so that the requests are run by the worker pool. The requests
+// calling methods not enabled by the RPC profile of the listener are rejected.
+func (s *RequestScheduler) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		methods := getRequestMethods(body)
+		if method, denied := rpcProfileFromContext(r.Context()).disallowedMethod(methods); denied {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, "{\"error\": {\"message\":\"RPC method %v is not enabled on this endpoint\"}}", method)
+			return
+		}
+		if method, open := s.breakers.openMethod(methods); open {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "{\"error\": {\"message\":\"RPC method %v is temporarily unavailable due to internal errors, please retry later\"}}", method)
+			return
+		}
+
+		p := getMethodsPriority(methods)
+		ok := s.schedule(r.Context(), p, func() {
+			h.ServeHTTP(w, r)
+		})
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "{\"error\": {\"message\":\"RPC server is busy, too many pending %v requests\"}}", p)
+		}
+	})
+}
+
+// ServeCodec serves the requests read from the codec of a persistent connection, e.g. a websocket,
+// one at a time until the connection is closed or the context is done. Like the HTTP requests, each
+// request is run by the worker pool according to its priority, and rejected if the circuit breaker
+// of the method is open or the queue of its priority class is full.
+func (s *RequestScheduler) ServeCodec(ctx context.Context, server *rpc.Server, codec rpc.ServerCodec) {
+	defer codec.Close()
+
+	sc := &scheduledCodec{ServerCodec: codec}
+	for ctx.Err() == nil {
+		if err := sc.readNext(); err != nil {
+			return
+		}
+
+		methods := []string{sc.header.ServiceMethod}
+		if method, denied := rpcProfileFromContext(ctx).disallowedMethod(methods); denied {
+			sc.reject(fmt.Sprintf("RPC method %v is not enabled on this endpoint", method))
+			continue
+		}
+		if method, open := s.breakers.openMethod(methods); open {
+			sc.reject(fmt.Sprintf("RPC method %v is temporarily unavailable due to internal errors, please retry later", method))
+			continue
+		}
+
+		p := getMethodsPriority(methods)
+		ok := s.schedule(ctx, p, func() {
+			// The errors of the request are sent to the client, only the failures to read the header,
+			// which has already been read, would end the connection
+			server.ServeRequest(sc)
+		})
+		if !ok {
+			sc.reject(fmt.Sprintf("RPC server is busy, too many pending %v requests", p))
+		}
+	}
+}
+
+// scheduledCodec reads the request header ahead, so that the request can be scheduled by its method
+// before it is served by rpc.Server.ServeRequest.
+type scheduledCodec struct {
+	rpc.ServerCodec
+	header rpc.Request
+}
+
+func (c *scheduledCodec) readNext() error {
+	c.header = rpc.Request{}
+	return c.ServerCodec.ReadRequestHeader(&c.header)
+}
+
+// ReadRequestHeader returns the header read ahead by readNext
+func (c *scheduledCodec) ReadRequestHeader(r *rpc.Request) error {
+	r.ServiceMethod = c.header.ServiceMethod
+	r.Seq = c.header.Seq
+	return nil
+}
+
+// reject discards the request read ahead and replies with the error
+func (c *scheduledCodec) reject(message string) {
+	c.ServerCodec.ReadRequestBody(nil)
+	resp := &rpc.Response{ServiceMethod: c.header.ServiceMethod, Seq: c.header.Seq, Error: message}
+	if err := c.ServerCodec.WriteResponse(resp, struct{}{}); err != nil {
+		logger.Debugf("Failed to reject RPC request %v: %v", c.header.ServiceMethod, err)
+	}
+}
@@ -0,0 +1,148 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/rpc"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common/util"
+	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+)
+
+func TestGetRequestPriority(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(PriorityAdmin, getRequestPriority([]byte(`{"jsonrpc":"2.0","method":"theta.GetStatus","params":[{}],"id":1}`)))
+	assert.Equal(PriorityBroadcast, getRequestPriority([]byte(`{"jsonrpc":"2.0","method":"theta.BroadcastRawTransaction","params":[{}],"id":1}`)))
+	assert.Equal(PriorityQuery, getRequestPriority([]byte(`{"jsonrpc":"2.0","method":"theta.GetEenpByHeight","params":[{}],"id":1}`)))
+	assert.Equal(PriorityQuery, getRequestPriority([]byte(`not json`)))
+
+	// A batch gets the lowest priority of its calls
+	assert.Equal(PriorityBroadcast, getRequestPriority([]byte(` [{"method":"theta.GetStatus"},{"method":"theta.BroadcastRawTransaction"}]`)))
+	assert.Equal(PriorityQuery, getRequestPriority([]byte(`[{"method":"theta.GetStatus"},{"method":"theta.GetBlock"}]`)))
+	assert.Equal(PriorityQuery, getRequestPriority([]byte(`[]`)))
+}
+
+func TestRequestSchedulerPriority(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := NewRequestScheduler(1, 2, "")
+
+	// Queue the requests before the worker starts
+	mu := &sync.Mutex{}
+	order := []RequestPriority{}
+	wg := &sync.WaitGroup{}
+	submit := func(p RequestPriority) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok := s.schedule(ctx, p, func() {
+				mu.Lock()
+				order = append(order, p)
+				mu.Unlock()
+			})
+			assert.True(ok)
+		}()
+	}
+	for _, p := range []RequestPriority{PriorityQuery, PriorityQuery, PriorityBroadcast, PriorityAdmin} {
+		depth := len(s.queues[p])
+		submit(p)
+		for len(s.queues[p]) == depth {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// The queue of each priority class is bounded
+	assert.False(s.schedule(ctx, PriorityQuery, func() {}))
+
+	s.Start(ctx)
+	wg.Wait()
+
+	assert.Equal([]RequestPriority{PriorityAdmin, PriorityBroadcast, PriorityQuery, PriorityQuery}, order)
+}
+
+func TestRequestSchedulerCancel(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := NewRequestScheduler(1, 2, "")
+
+	// A job cancelled while queued never runs
+	reqCtx, reqCancel := context.WithCancel(ctx)
+	time.AfterFunc(10*time.Millisecond, reqCancel)
+	ran := false
+	assert.True(s.schedule(reqCtx, PriorityQuery, func() { ran = true }))
+
+	s.Start(ctx)
+	assert.True(s.schedule(ctx, PriorityQuery, func() {}))
+	assert.False(ran)
+
+	// A running job is waited for even if its request is cancelled
+	reqCtx, reqCancel = context.WithCancel(ctx)
+	started := make(chan struct{})
+	finished := false
+	go func() {
+		<-started
+		reqCancel()
+	}()
+	assert.True(s.schedule(reqCtx, PriorityQuery, func() {
+		close(started)
+		time.Sleep(10 * time.Millisecond)
+		finished = true
+	}))
+	assert.True(finished)
+}
+
+type schedulerTestService struct{}
+
+func (schedulerTestService) Echo(args *string, reply *string) error {
+	*reply = *args
+	return nil
+}
+
+func (schedulerTestService) GetBlock(args *string, reply *string) error {
+	*reply = *args
+	return nil
+}
+
+func TestRequestSchedulerServeCodec(t *testing.T) {
+	assert := assert.New(t)
+
+	logger = util.GetLoggerForModule("rpc")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := NewRequestScheduler(1, 2, "")
+	s.breakers = NewCircuitBreakers(0.5, 1, time.Minute, time.Minute, 0, "")
+	s.breakers.record("GetBlock", true, time.Now())
+	s.Start(ctx)
+
+	server := rpc.NewServer()
+	assert.Nil(server.RegisterName("theta", schedulerTestService{}))
+
+	serverConn, clientConn := net.Pipe()
+	go s.ServeCodec(ctx, server, jsonrpc2.NewServerCodec(serverConn, server))
+	client := jsonrpc2.NewClient(clientConn)
+	defer client.Close()
+
+	reply := ""
+	assert.Nil(client.Call("theta.Echo", []string{"hello"}, &reply))
+	assert.Equal("hello", reply)
+
+	// The requests of the methods with open breakers are rejected, and the connection stays usable
+	err := client.Call("theta.GetBlock", []string{"hello"}, &reply)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "temporarily unavailable")
+	assert.Nil(client.Call("theta.Echo", []string{"world"}, &reply))
+	assert.Equal("world", reply)
+}
@@ -3,6 +3,7 @@ package rpc
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -14,6 +15,7 @@ import (
 	"net/rpc"
 
 	"github.com/gorilla/mux"
+	lru "github.com/hashicorp/golang-lru"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -25,6 +27,7 @@ import (
 	"github.com/thetatoken/theta/ledger"
 	"github.com/thetatoken/theta/mempool"
 	"github.com/thetatoken/theta/rpc/lib/rpc-codec/jsonrpc2"
+	"github.com/thetatoken/theta/snapshot"
 	"golang.org/x/net/netutil"
 	"golang.org/x/net/websocket"
 )
@@ -37,6 +40,14 @@ type ThetaRPCService struct {
 	dispatcher *dispatcher.Dispatcher
 	chain      *blockchain.Chain
 	consensus  *consensus.ConsensusEngine
+	cache      *ResultCache
+	breakers   *CircuitBreakers
+	snapshots  *snapshot.SnapshotScheduler
+	dbBackup   *dbBackupJob
+
+	syncProgress *syncProgress
+	filters      *filterManager
+	contractABIs *lru.Cache // the parsed ABIs of the verified contracts, nil if the contract registry is disabled
 
 	// Life cycle
 	wg      *sync.WaitGroup
@@ -49,19 +60,32 @@ type ThetaRPCService struct {
 type ThetaRPCServer struct {
 	*ThetaRPCService
 
-	server   *http.Server
-	handler  *rpc.Server
-	router   *mux.Router
-	listener net.Listener
+	server    *http.Server
+	handler   *rpc.Server
+	scheduler *RequestScheduler
+	router    *mux.Router
+	listener  net.Listener
+
+	extraListeners []RPCListenerConfig // the additional listeners, each with its own RPC profile
+	extraServers   []*http.Server
+	ipcServer      *http.Server // the server of the Unix domain socket, nil if disabled
+
+	hosted   bool                       // Whether the server is hosted by the RPC server of another chain
+	chains   map[string]*ThetaRPCServer // RPC servers of the other chains hosted in the same process
+	chainsMu *sync.RWMutex
 }
 
 // NewThetaRPCServer creates a new instance of ThetaRPCServer.
 func NewThetaRPCServer(mempool *mempool.Mempool, ledger *ledger.Ledger, dispatcher *dispatcher.Dispatcher,
-	chain *blockchain.Chain, consensus *consensus.ConsensusEngine) *ThetaRPCServer {
+	chain *blockchain.Chain, consensus *consensus.ConsensusEngine, snapshots *snapshot.SnapshotScheduler) *ThetaRPCServer {
 	t := &ThetaRPCServer{
 		ThetaRPCService: &ThetaRPCService{
-			wg: &sync.WaitGroup{},
+			dbBackup:     &dbBackupJob{},
+			syncProgress: newSyncProgress(),
+			wg:           &sync.WaitGroup{},
 		},
+		chains:   make(map[string]*ThetaRPCServer),
+		chainsMu: &sync.RWMutex{},
 	}
 
 	t.mempool = mempool
@@ -69,24 +93,74 @@ func NewThetaRPCServer(mempool *mempool.Mempool, ledger *ledger.Ledger, dispatch
 	t.dispatcher = dispatcher
 	t.chain = chain
 	t.consensus = consensus
+	t.snapshots = snapshots
+	t.filters = newFilterManager(viper.GetDuration(common.CfgRPCFilterTimeoutSecs)*time.Second, viper.GetInt(common.CfgRPCMaxFilters))
+
+	logger = util.GetLoggerForModule("rpc")
+
+	t.contractABIs = newContractABICache()
+
+	if viper.GetBool(common.CfgRPCCacheEnabled) {
+		cache, err := NewResultCache(viper.GetInt(common.CfgRPCCacheSize), chain.ChainID)
+		if err != nil {
+			logger.Warnf("Failed to create the RPC result cache: %v", err)
+		} else {
+			t.cache = cache
+		}
+	}
+
+	t.breakers = NewCircuitBreakers(viper.GetFloat64(common.CfgRPCBreakerFailureRatio), viper.GetInt(common.CfgRPCBreakerMinCalls),
+		viper.GetDuration(common.CfgRPCBreakerWindowSecs)*time.Second, viper.GetDuration(common.CfgRPCBreakerCooldownSecs)*time.Second,
+		viper.GetDuration(common.CfgRPCTimeoutSecs)*time.Second, chain.ChainID)
 
 	s := rpc.NewServer()
 	s.RegisterName("theta", t.ThetaRPCService)
 
 	t.handler = s
+	t.scheduler = NewRequestScheduler(viper.GetInt(common.CfgRPCNumWorkers), viper.GetInt(common.CfgRPCMaxQueueDepth), chain.ChainID)
+	t.scheduler.breakers = t.breakers
+
+	audit, err := newAuditLoggerFromConfig(chain.ChainID)
+	if err != nil {
+		logger.Fatalf("Failed to open the RPC audit log: %v", err)
+	}
 
 	t.router = mux.NewRouter()
 	t.router.Handle("/", &defaultHTTPHandler{})
-	t.router.Handle("/rpc", corsMiddleware(TimeoutHandler(jsonrpc2.HTTPHandler(s), viper.GetDuration(common.CfgRPCTimeoutSecs)*time.Second, "")))
+	t.router.Handle("/rpc", corsMiddleware(TimeoutHandler(audit.Handler(t.scheduler.Handler(jsonrpc2.HTTPHandler(s))), viper.GetDuration(common.CfgRPCTimeoutSecs)*time.Second, "",
+		viper.GetInt64(common.CfgRPCMaxRequestBytes), viper.GetInt64(common.CfgRPCMaxResponseBytes))))
 	t.router.Handle("/ws", websocket.Handler(func(ws *websocket.Conn) {
-		s.ServeCodec(jsonrpc2.NewServerCodec(ws, s))
+		ws.MaxPayloadBytes = viper.GetInt(common.CfgRPCMaxRequestBytes)
+		t.scheduler.ServeCodec(ws.Request().Context(), s, audit.Codec(ws.Request(), jsonrpc2.NewServerCodec(ws, s)))
 	}))
+	t.router.Handle("/stream/eenp", corsMiddleware(requireRPCMethod("theta.GetEenpByHeight", http.HandlerFunc(t.ThetaRPCService.StreamEenpByHeight))))
+	t.router.Handle("/healthz", http.HandlerFunc(t.ThetaRPCService.Healthz))
+	t.router.Handle("/readyz", http.HandlerFunc(t.ThetaRPCService.Readyz))
+	t.router.Handle("/spec", corsMiddleware(http.HandlerFunc(t.ThetaRPCService.ServeSpec)))
 
+	profile, err := GetRPCProfile(viper.GetString(common.CfgRPCProfile))
+	if err != nil {
+		logger.Fatalf("Failed to load the profile of the RPC listener: %v", err)
+	}
 	t.server = &http.Server{
-		Handler: t.router,
+		Handler: withRPCProfile(profile, t.chainRouter(t.router)),
 	}
 
-	logger = util.GetLoggerForModule("rpc")
+	listenerConfigs := []RPCListenerConfig{}
+	if err := viper.UnmarshalKey(common.CfgRPCListeners, &listenerConfigs); err != nil {
+		logger.Fatalf("Failed to parse the RPC listeners: %v", err)
+	}
+	for _, config := range listenerConfigs {
+		profile, err := GetRPCProfile(config.Profile)
+		if err != nil {
+			logger.Fatalf("Failed to load the profile of the RPC listener %v:%v: %v", config.Address, config.Port, err)
+		}
+		t.extraListeners = append(t.extraListeners, config)
+		t.extraServers = append(t.extraServers, &http.Server{
+			Handler: withRPCProfile(profile, t.chainRouter(t.router)),
+		})
+	}
+	t.ipcServer = t.newIPCServer()
 
 	return t
 }
@@ -97,21 +171,60 @@ func (t *ThetaRPCServer) Start(ctx context.Context) {
 	t.ctx = c
 	t.cancel = cancel
 
+	t.scheduler.Start(c)
+
 	t.wg.Add(1)
 	go t.mainLoop()
 
 	t.wg.Add(1)
 	go t.txCallback()
+
+	t.wg.Add(1)
+	go t.syncProgressLoop()
+
+	t.wg.Add(1)
+	go t.filterCleanupLoop()
 }
 
 func (t *ThetaRPCServer) mainLoop() {
 	defer t.wg.Done()
 
+	if t.hosted {
+		// The requests are served and drained by the hosting server
+		<-t.ctx.Done()
+		t.stopped = true
+		return
+	}
+
 	go t.serve()
+	for i, config := range t.extraListeners {
+		go t.serveListener(t.extraServers[i], config)
+	}
+	if t.ipcServer != nil {
+		go t.serveIPC()
+	}
 
 	<-t.ctx.Done()
 	t.stopped = true
-	t.server.Shutdown(t.ctx)
+
+	// Stop accepting new requests and drain the in-flight ones. Note t.ctx is already cancelled at
+	// this point, so a separate context is needed for the deadline
+	timeout := time.Duration(viper.GetInt(common.CfgNodeShutdownTimeoutSecs)) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := t.server.Shutdown(ctx); err != nil {
+		logger.Warnf("Failed to drain the in-flight RPC requests: %v", err)
+	}
+	for _, server := range t.extraServers {
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Warnf("Failed to drain the in-flight RPC requests: %v", err)
+		}
+	}
+	if t.ipcServer != nil {
+		if err := t.ipcServer.Shutdown(ctx); err != nil {
+			logger.Warnf("Failed to drain the in-flight RPC IPC requests: %v", err)
+		}
+	}
 }
 
 func (t *ThetaRPCServer) serve() {
@@ -131,6 +244,69 @@ func (t *ThetaRPCServer) serve() {
 	logger.Info(t.server.Serve(ll))
 }
 
+// serveListener serves the requests of an additional listener
+func (t *ThetaRPCServer) serveListener(server *http.Server, config RPCListenerConfig) {
+	l, err := net.Listen("tcp", config.Address+":"+config.Port)
+	if err != nil {
+		logger.WithFields(log.Fields{"error": err}).Fatal("Failed to create listener")
+	} else {
+		logger.WithFields(log.Fields{"address": config.Address, "port": config.Port, "profile": config.Profile}).Info("RPC listener started")
+	}
+	defer l.Close()
+
+	ll := netutil.LimitListener(l, viper.GetInt(common.CfgRPCMaxConnections))
+	logger.Info(server.Serve(ll))
+}
+
+// HostChain serves the RPC requests of another chain hosted in the same process, which are routed by
+// the chain_id query parameter, e.g. /rpc?chain_id=tsub360777. The hosted server does not listen on
+// its own. It needs to be called before the hosted server starts.
+func (t *ThetaRPCServer) HostChain(chainID string, hosted *ThetaRPCServer) {
+	hosted.hosted = true
+
+	t.chainsMu.Lock()
+	defer t.chainsMu.Unlock()
+	t.chains[chainID] = hosted
+}
+
+// chainRouter dispatches the requests with the chain_id parameter of a hosted chain to the RPC server
+// of that chain. The other requests are handled by the given handler.
+func (t *ThetaRPCServer) chainRouter(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chainID := r.URL.Query().Get("chain_id")
+		if chainID == "" || chainID == t.chain.ChainID {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		t.chainsMu.RLock()
+		hosted, ok := t.chains[chainID]
+		t.chainsMu.RUnlock()
+		if !ok {
+			http.Error(w, fmt.Sprintf("Chain %v is not hosted by this node", chainID), http.StatusNotFound)
+			return
+		}
+		hosted.router.ServeHTTP(w, r)
+	})
+}
+
+// getChainID returns the ID of the chain served, or an empty string if the service has no chain
+func (t *ThetaRPCService) getChainID() string {
+	if t.chain == nil {
+		return ""
+	}
+	return t.chain.ChainID
+}
+
+// rpcMetricName returns the name of the RPC metric of the chain. The chains hosted in the same process
+// report their metrics under their own chain IDs, the chain ID is omitted if empty.
+func rpcMetricName(chainID string, name string) string {
+	if chainID == "" {
+		return "rpc/" + name
+	}
+	return "rpc/" + chainID + "/" + name
+}
+
 func corsMiddleware(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		//Allow CORS here By * or specific origin
@@ -178,18 +354,25 @@ func (dh *defaultHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 //
 // TimeoutHandler supports the Pusher interface but does not support
 // the Hijacker or Flusher interfaces.
-func TimeoutHandler(h http.Handler, dt time.Duration, msg string) http.Handler {
+//
+// Requests larger than maxRequestBytes and responses larger than
+// maxResponseBytes are rejected with an error, zero means no limit.
+func TimeoutHandler(h http.Handler, dt time.Duration, msg string, maxRequestBytes, maxResponseBytes int64) http.Handler {
 	return &timeoutHandler{
-		handler: h,
-		body:    msg,
-		dt:      dt,
+		handler:          h,
+		body:             msg,
+		dt:               dt,
+		maxRequestBytes:  maxRequestBytes,
+		maxResponseBytes: maxResponseBytes,
 	}
 }
 
 type timeoutHandler struct {
-	handler http.Handler
-	body    string
-	dt      time.Duration
+	handler          http.Handler
+	body             string
+	dt               time.Duration
+	maxRequestBytes  int64
+	maxResponseBytes int64
 
 	// When set, no context will be created and this context will
 	// be used instead.
@@ -213,17 +396,27 @@ func (h *timeoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	r = r.WithContext(ctx)
 	done := make(chan struct{})
 	tw := &timeoutWriter{
-		w:   w,
-		h:   make(http.Header),
-		req: r,
+		w:        w,
+		h:        make(http.Header),
+		req:      r,
+		maxBytes: h.maxResponseBytes,
 	}
 	panicChan := make(chan interface{}, 1)
 
-	buf, bodyErr := ioutil.ReadAll(r.Body)
+	var body io.Reader = r.Body
+	if h.maxRequestBytes > 0 {
+		body = io.LimitReader(r.Body, h.maxRequestBytes+1)
+	}
+	buf, bodyErr := ioutil.ReadAll(body)
 	if bodyErr != nil {
 		http.Error(w, bodyErr.Error(), http.StatusInternalServerError)
 		return
 	}
+	if h.maxRequestBytes > 0 && int64(len(buf)) > h.maxRequestBytes {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		fmt.Fprintf(w, "{\"error\": {\"message\":\"Request too large, at most %v bytes are allowed\"}}", h.maxRequestBytes)
+		return
+	}
 
 	rdr1 := ioutil.NopCloser(bytes.NewBuffer(buf))
 	rdr2 := ioutil.NopCloser(bytes.NewBuffer(buf))
@@ -245,6 +438,13 @@ func (h *timeoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		tw.mu.Lock()
 		defer tw.mu.Unlock()
 
+		if tw.tooLarge {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "{\"error\": {\"message\":\"Response too large, at most %v bytes are allowed. Please narrow down the query or use the streaming endpoints\"}}", h.maxResponseBytes)
+			logger.Warnf("RPC response exceeded %v bytes, requestBodySize=%v", h.maxResponseBytes, len(buf))
+			return
+		}
+
 		dst := w.Header()
 		for k, vv := range tw.h {
 			dst[k] = vv
@@ -274,8 +474,14 @@ type timeoutWriter struct {
 	timedOut    bool
 	wroteHeader bool
 	code        int
+
+	maxBytes int64
+	tooLarge bool
 }
 
+// errResponseTooLarge is returned by timeoutWriter.Write when the response exceeds the size limit.
+var errResponseTooLarge = errors.New("rpc: response too large")
+
 var _ http.Pusher = (*timeoutWriter)(nil)
 
 // Push implements the Pusher interface.
@@ -294,6 +500,15 @@ func (tw *timeoutWriter) Write(p []byte) (int, error) {
 	if tw.timedOut {
 		return 0, http.ErrHandlerTimeout
 	}
+	if tw.tooLarge {
+		return 0, errResponseTooLarge
+	}
+	if tw.maxBytes > 0 && int64(tw.wbuf.Len()+len(p)) > tw.maxBytes {
+		// Release the buffered response right away instead of holding it until the handler returns
+		tw.tooLarge = true
+		tw.wbuf = bytes.Buffer{}
+		return 0, errResponseTooLarge
+	}
 	if !tw.wroteHeader {
 		tw.writeHeaderLocked(http.StatusOK)
 	}
@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common/util"
+)
+
+func TestTimeoutHandlerSizeLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	logger = util.GetLoggerForModule("rpc")
+
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+		w.Write(body)
+	})
+	h := TimeoutHandler(echo, time.Second, "", 10, 16)
+
+	// Request and response within the limits
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/rpc", strings.NewReader("12345678")))
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal("1234567812345678", rec.Body.String())
+
+	// Request too large
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/rpc", strings.NewReader("12345678901")))
+	assert.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(rec.Body.String(), "Request too large")
+
+	// Response too large
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/rpc", strings.NewReader("123456789")))
+	assert.Equal(http.StatusInternalServerError, rec.Code)
+	assert.Contains(rec.Body.String(), "Response too large")
+
+	// Zero means no limit
+	h = TimeoutHandler(echo, time.Second, "", 0, 0)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/rpc", strings.NewReader(strings.Repeat("a", 1000))))
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal(2000, rec.Body.Len())
+}
+
+func TestChainRouter(t *testing.T) {
+	assert := assert.New(t)
+
+	respond := func(s string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(s))
+		})
+	}
+
+	main := &ThetaRPCServer{
+		ThetaRPCService: &ThetaRPCService{chain: &blockchain.Chain{ChainID: "privatenet"}},
+		chains:          make(map[string]*ThetaRPCServer),
+		chainsMu:        &sync.RWMutex{},
+	}
+	hosted := &ThetaRPCServer{router: mux.NewRouter()}
+	hosted.router.Handle("/rpc", respond("subchain"))
+	main.HostChain("tsub360777", hosted)
+	assert.True(hosted.hosted)
+
+	h := main.chainRouter(respond("main"))
+	for url, expected := range map[string]string{
+		"/rpc":                     "main",
+		"/rpc?chain_id=privatenet": "main",
+		"/rpc?chain_id=tsub360777": "subchain",
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("POST", url, strings.NewReader("{}")))
+		assert.Equal(http.StatusOK, rec.Code)
+		assert.Equal(expected, rec.Body.String())
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/rpc?chain_id=unknown", strings.NewReader("{}")))
+	assert.Equal(http.StatusNotFound, rec.Code)
+}
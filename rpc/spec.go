@@ -0,0 +1,258 @@
+package rpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/version"
+)
+
+// ------------------------------ Spec -----------------------------------
+
+// The spec is generated by reflecting over the RPC methods and their args/result structs, so it never goes out of
+// sync with the code. All the methods are called through the JSON-RPC 2.0 endpoint /rpc, the paths of the spec are
+// named after the methods for the code generators, with the actual method in the x-jsonrpc-method extension.
+
+var (
+	specOnce sync.Once
+	specJSON []byte
+	specErr  error
+)
+
+var (
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	bigIntType        = reflect.TypeOf(big.Int{})
+)
+
+// stringFormats are the formats of the well known types encoded as JSON strings
+var stringFormats = map[reflect.Type]string{
+	reflect.TypeOf(common.Address{}):     "address",
+	reflect.TypeOf(common.Hash{}):        "hash",
+	reflect.TypeOf(common.JSONUint64(0)): "uint64",
+	reflect.TypeOf(common.JSONBig{}):     "bigint",
+}
+
+// ServeSpec serves the OpenAPI spec of the RPC API
+func (t *ThetaRPCService) ServeSpec(w http.ResponseWriter, r *http.Request) {
+	specOnce.Do(func() {
+		specJSON, specErr = json.MarshalIndent(GenerateSpec(), "", "  ")
+	})
+	if specErr != nil {
+		http.Error(w, specErr.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(specJSON)
+}
+
+// GenerateSpec returns the OpenAPI 3.0 spec of the methods of the ThetaRPCService
+func GenerateSpec() map[string]interface{} {
+	g := &specGenerator{schemas: make(map[string]interface{})}
+
+	paths := make(map[string]interface{})
+	svcType := reflect.TypeOf((*ThetaRPCService)(nil))
+	for i := 0; i < svcType.NumMethod(); i++ {
+		method := svcType.Method(i)
+		mtype := method.Type
+		if mtype.NumIn() != 3 || mtype.NumOut() != 1 || mtype.Out(0) != errorType ||
+			mtype.In(1).Kind() != reflect.Ptr || mtype.In(2).Kind() != reflect.Ptr {
+			continue // not an RPC method
+		}
+
+		rpcMethod := "theta." + method.Name
+		paths["/"+rpcMethod] = map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId":      method.Name,
+				"x-jsonrpc-method": rpcMethod,
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{
+							"schema": jsonRPCRequestSchema(rpcMethod, g.schemaOf(mtype.In(1).Elem())),
+						},
+					},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "JSON-RPC 2.0 response",
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{
+								"schema": jsonRPCResponseSchema(g.schemaOf(mtype.In(2).Elem())),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Theta Node RPC API",
+			"version":     version.Version,
+			"description": "All the methods are called by POSTing a JSON-RPC 2.0 request to the /rpc endpoint, see x-jsonrpc-method for the method name.",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": g.schemas,
+		},
+	}
+}
+
+func jsonRPCRequestSchema(method string, params map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"jsonrpc", "method", "params", "id"},
+		"properties": map[string]interface{}{
+			"jsonrpc": map[string]interface{}{"type": "string", "enum": []string{"2.0"}},
+			"method":  map[string]interface{}{"type": "string", "enum": []string{method}},
+			"params":  map[string]interface{}{"type": "array", "items": params, "minItems": 1, "maxItems": 1},
+			"id":      map[string]interface{}{},
+		},
+	}
+}
+
+func jsonRPCResponseSchema(result map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"jsonrpc": map[string]interface{}{"type": "string"},
+			"id":      map[string]interface{}{},
+			"result":  result,
+			"error": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"code":    map[string]interface{}{"type": "integer"},
+					"message": map[string]interface{}{"type": "string"},
+					"data":    map[string]interface{}{},
+				},
+			},
+		},
+	}
+}
+
+// specGenerator converts the Go types into JSON schemas following the encoding/json rules. The named structs are
+// added to the components, which also breaks the recursion of the recursive types.
+type specGenerator struct {
+	schemas map[string]interface{}
+}
+
+func (g *specGenerator) schemaOf(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if format, ok := stringFormats[t]; ok {
+		return map[string]interface{}{"type": "string", "format": format}
+	}
+	if t == bigIntType {
+		return map[string]interface{}{"type": "integer", "format": "bigint"}
+	}
+	if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		if !t.Implements(jsonMarshalerType) && !reflect.PtrTo(t).Implements(jsonMarshalerType) {
+			return map[string]interface{}{"type": "string"}
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := schemaName(t)
+		if _, ok := g.schemas[name]; !ok {
+			g.schemas[name] = map[string]interface{}{} // placeholder for the recursive types
+			g.schemas[name] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	default: // interfaces, e.g. the transactions
+		return map[string]interface{}{}
+	}
+}
+
+func (g *specGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	g.addFields(t, properties)
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		schema["description"] = "Has a custom JSON encoding, e.g. the big integers encoded as strings, which might differ from the listed fields"
+	}
+	return schema
+}
+
+// addFields adds the JSON fields of the struct, including the promoted fields of the embedded structs, which are
+// shadowed by the fields of the outer struct with the same name
+func (g *specGenerator) addFields(t reflect.Type, properties map[string]interface{}) {
+	embedded := []reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schemaOf(field.Type)
+	}
+
+	for _, ft := range embedded {
+		promoted := make(map[string]interface{})
+		g.addFields(ft, promoted)
+		for name, schema := range promoted {
+			if _, ok := properties[name]; !ok {
+				properties[name] = schema
+			}
+		}
+	}
+}
+
+// schemaName qualifies the type name with its package, e.g. core.BlockHeader
+func schemaName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+)
+
+type specTestInner struct {
+	Height common.JSONUint64 `json:"height"`
+	Name   string            `json:"name"`
+}
+
+type specTestOuter struct {
+	*specTestInner
+	Name     string            `json:"name,omitempty"`
+	Address  common.Address    `json:"address"`
+	Data     common.Bytes      `json:"data"`
+	Children []*specTestOuter  `json:"children"`
+	Labels   map[string]uint64 `json:"labels"`
+	Ignored  bool              `json:"-"`
+	hidden   bool
+}
+
+func TestSpecSchemaOf(t *testing.T) {
+	assert := assert.New(t)
+
+	g := &specGenerator{schemas: make(map[string]interface{})}
+	ref := g.schemaOf(reflect.TypeOf(&specTestOuter{}))
+	assert.Equal("#/components/schemas/rpc.specTestOuter", ref["$ref"])
+
+	schema := g.schemas["rpc.specTestOuter"].(map[string]interface{})
+	properties := schema["properties"].(map[string]interface{})
+	assert.Equal(6, len(properties))
+	assert.Equal(map[string]interface{}{"type": "string", "format": "uint64"}, properties["height"])
+	assert.Equal(map[string]interface{}{"type": "string"}, properties["name"])
+	assert.Equal(map[string]interface{}{"type": "string", "format": "address"}, properties["address"])
+	assert.Equal(map[string]interface{}{"type": "string", "format": "byte"}, properties["data"])
+	assert.Equal(map[string]interface{}{"type": "array", "items": ref}, properties["children"])
+	assert.Equal(map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "integer"}}, properties["labels"])
+}
+
+func TestGenerateSpec(t *testing.T) {
+	require := require.New(t)
+
+	spec := GenerateSpec()
+	paths := spec["paths"].(map[string]interface{})
+	require.Contains(paths, "/theta.GetStatus")
+	require.Contains(paths, "/theta.BroadcastRawTransaction")
+	require.NotContains(paths, "/theta.ServeSpec")
+	require.NotContains(paths, "/theta.Readyz")
+
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	require.Contains(schemas, "rpc.GetStatusResult")
+
+	_, err := json.Marshal(spec)
+	require.Nil(err)
+}
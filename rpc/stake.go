@@ -0,0 +1,584 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/consensus"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/crypto/bls"
+	exec "github.com/thetatoken/theta/ledger/execution"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+)
+
+const (
+	guardianSummaryLength = 229 // address (20) + BLS pubkey (48) + BLS pop (96) + holder signature (65)
+	eenSummaryLength      = 261 // guardian summary format + summary hash (32)
+)
+
+// holderSummary is the decoded holder summary of a guardian or an elite edge node
+type holderSummary struct {
+	Address   common.Address
+	BlsPubkey *bls.PublicKey
+	BlsPop    *bls.Signature
+	HolderSig *crypto.Signature
+}
+
+// parseHolder parses the holder of a stake deposit. For validators the holder is an address, and for
+// guardians and elite edge nodes it is the summary generated by the holder node.
+func parseHolder(purpose uint8, holder string) (*holderSummary, error) {
+	holder = strings.TrimPrefix(holder, "0x")
+
+	if purpose == core.StakeForValidator {
+		if len(holder) != 2*common.AddressLength {
+			return nil, fmt.Errorf("Holder must be a valid validator address, got %v hex characters instead of %v",
+				len(holder), 2*common.AddressLength)
+		}
+		return &holderSummary{Address: common.HexToAddress(holder)}, nil
+	}
+
+	expectedLength := 2 * guardianSummaryLength
+	holderType := "guardian"
+	if purpose == core.StakeForEliteEdgeNode {
+		expectedLength = 2 * eenSummaryLength
+		holderType = "elite edge node"
+	}
+	if len(holder) != expectedLength {
+		return nil, fmt.Errorf("Holder must be a valid %v summary, got %v hex characters instead of %v. Please use the summary returned by the %v node",
+			holderType, len(holder), expectedLength, holderType)
+	}
+	summaryBytes, err := hex.DecodeString(holder)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode the %v summary: %v", holderType, err)
+	}
+
+	summary := &holderSummary{
+		Address: common.BytesToAddress(summaryBytes[:20]),
+	}
+	summary.BlsPubkey, err = bls.PublicKeyFromBytes(summaryBytes[20:68])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode the BLS pubkey of the %v summary: %v", holderType, err)
+	}
+	summary.BlsPop, err = bls.SignatureFromBytes(summaryBytes[68:164])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode the BLS proof-of-possession of the %v summary: %v", holderType, err)
+	}
+	summary.HolderSig, err = crypto.SignatureFromBytes(summaryBytes[164:229])
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode the holder signature of the %v summary: %v", holderType, err)
+	}
+
+	if purpose == core.StakeForEliteEdgeNode {
+		expectedSummaryHash := crypto.Keccak256Hash([]byte("0x" + holder[:2*guardianSummaryLength]))
+		summaryHash := common.BytesToHash(summaryBytes[guardianSummaryLength:])
+		if expectedSummaryHash != summaryHash {
+			return nil, fmt.Errorf("The elite edge node summary is corrupted: summary hash mismatch, %v vs %v",
+				expectedSummaryHash.Hex(), summaryHash.Hex())
+		}
+	}
+
+	if !summary.HolderSig.Verify(summary.BlsPop.ToBytes(), summary.Address) {
+		return nil, fmt.Errorf("The %v summary is not signed by the holder %v", holderType, summary.Address.Hex())
+	}
+	if !summary.BlsPop.PopVerify(summary.BlsPubkey) {
+		return nil, fmt.Errorf("The BLS proof-of-possession of the %v summary is invalid", holderType)
+	}
+
+	return summary, nil
+}
+
+// validateStakePurpose checks the stake purpose
+func validateStakePurpose(purpose uint8) error {
+	if purpose != core.StakeForValidator && purpose != core.StakeForGuardian && purpose != core.StakeForEliteEdgeNode {
+		return fmt.Errorf("Invalid stake purpose %v, should be %v (validator), %v (guardian), or %v (elite edge node)",
+			purpose, core.StakeForValidator, core.StakeForGuardian, core.StakeForEliteEdgeNode)
+	}
+	return nil
+}
+
+// validateStakeAmount checks the stake amount against the minimum (and maximum) deposit requirements
+func validateStakeAmount(purpose uint8, stake *big.Int, chainID string, blockHeight uint64) error {
+	if stake.Sign() <= 0 {
+		return errors.New("Stake must be positive")
+	}
+
+	switch purpose {
+	case core.StakeForValidator:
+		if minStake := core.MinValidatorStake(chainID); stake.Cmp(minStake) < 0 {
+			return fmt.Errorf("Insufficient amount of stake, at least %v ThetaWei is required for each validator deposit",
+				minStake)
+		}
+	case core.StakeForGuardian:
+		minGuardianStake := core.MinGuardianStake(chainID, blockHeight)
+		if stake.Cmp(minGuardianStake) < 0 {
+			return fmt.Errorf("Insufficient amount of stake, at least %v ThetaWei is required for each guardian deposit",
+				minGuardianStake)
+		}
+	case core.StakeForEliteEdgeNode:
+		if !core.IsForkActive(chainID, core.ForkTheta3, blockHeight) {
+			return fmt.Errorf("Elite Edge Node staking not enabled yet, please wait until block height %v", core.ForkHeight(chainID, core.ForkTheta3))
+		}
+		if minStake := core.MinEliteEdgeNodeStake(chainID); stake.Cmp(minStake) < 0 {
+			return fmt.Errorf("Insufficient amount of stake, at least %v TFuelWei is required for each elite edge node deposit",
+				minStake)
+		}
+		if maxStake := core.MaxEliteEdgeNodeStake(chainID); stake.Cmp(maxStake) > 0 {
+			return fmt.Errorf("Stake exceeds the cap, at most %v TFuelWei can be deposited to each elite edge node",
+				maxStake)
+		}
+	}
+	return nil
+}
+
+// parseStakeTxFee parses the transaction fee, and checks it against the minimum fee
+func parseStakeTxFee(feeStr string, chainID string, blockHeight uint64) (*big.Int, error) {
+	minFee := types.GetMinimumTransactionFeeTFuelWei(chainID, blockHeight)
+	if feeStr == "" {
+		return minFee, nil
+	}
+	fee, ok := types.ParseCoinAmount(feeStr)
+	if !ok {
+		return nil, fmt.Errorf("Failed to parse fee: %v", feeStr)
+	}
+	if fee.Cmp(minFee) < 0 {
+		return nil, fmt.Errorf("Insufficient fee. Transaction fee needs to be at least %v TFuelWei", minFee)
+	}
+	return fee, nil
+}
+
+// getStakeSourceAccount retrieves the source account and determines the sequence of the stake tx
+func getStakeSourceAccount(ledgerState *state.StoreView, source string, sequence uint64) (*types.Account, uint64, error) {
+	if source == "" {
+		return nil, 0, errors.New("Source must be specified")
+	}
+	sourceAddress := common.HexToAddress(source)
+	account := ledgerState.GetAccount(sourceAddress)
+	if account == nil {
+		return nil, 0, newNotFoundError("Source account %v is not found", sourceAddress.Hex())
+	}
+	if sequence == 0 {
+		sequence = account.Sequence + 1
+	} else if sequence != account.Sequence+1 {
+		return nil, 0, fmt.Errorf("Invalid sequence %v, the next sequence of the source account is %v", sequence, account.Sequence+1)
+	}
+	return account, sequence, nil
+}
+
+// ------------------------------ ComposeDepositStakeTx -----------------------------------
+
+type ComposeDepositStakeTxArgs struct {
+	Source   string            `json:"source"`   // Address of the staker
+	Holder   string            `json:"holder"`   // Validator address, or guardian/elite edge node summary
+	Stake    string            `json:"stake"`    // Amount to stake, e.g. "1000" or "1000000000000000000000wei"
+	Fee      string            `json:"fee"`      // Optional, default to the minimum transaction fee
+	Purpose  uint8             `json:"purpose"`  // 0: validator, 1: guardian, 2: elite edge node
+	Sequence common.JSONUint64 `json:"sequence"` // Optional, default to the next sequence of the source account
+}
+
+type ComposeDepositStakeTxResult struct {
+	TxBytes   string            `json:"tx_bytes"`   // The unsigned transaction
+	SignBytes string            `json:"sign_bytes"` // The bytes the source needs to sign
+	Sequence  common.JSONUint64 `json:"sequence"`
+}
+
+func (t *ThetaRPCService) ComposeDepositStakeTx(args *ComposeDepositStakeTxArgs, result *ComposeDepositStakeTxResult) (err error) {
+	defer t.guard("ComposeDepositStakeTx", &err)()
+
+	if err = validateStakePurpose(args.Purpose); err != nil {
+		return err
+	}
+
+	ledgerState, err := t.ledger.GetFinalizedSnapshot()
+	if err != nil {
+		return err
+	}
+	blockHeight := ledgerState.Height() + 1
+
+	stake, ok := types.ParseCoinAmount(args.Stake)
+	if !ok {
+		return fmt.Errorf("Failed to parse stake: %v", args.Stake)
+	}
+	if err = validateStakeAmount(args.Purpose, stake, t.chain.ChainID, blockHeight); err != nil {
+		return err
+	}
+	fee, err := parseStakeTxFee(args.Fee, t.chain.ChainID, blockHeight)
+	if err != nil {
+		return err
+	}
+
+	holder, err := parseHolder(args.Purpose, args.Holder)
+	if err != nil {
+		return err
+	}
+
+	account, sequence, err := getStakeSourceAccount(ledgerState, args.Source, uint64(args.Sequence))
+	if err != nil {
+		return err
+	}
+
+	coins := types.Coins{ThetaWei: stake, TFuelWei: big.NewInt(0)}
+	if args.Purpose == core.StakeForEliteEdgeNode {
+		coins = types.Coins{ThetaWei: big.NewInt(0), TFuelWei: stake}
+		een := state.NewEliteEdgeNodePool(ledgerState, true).Get(holder.Address)
+		maxStake := core.MaxEliteEdgeNodeStake(t.chain.ChainID)
+		if een != nil && new(big.Int).Add(een.TotalStake(), stake).Cmp(maxStake) > 0 {
+			return fmt.Errorf("Stake exceeds the cap, elite edge node %v already has %v TFuelWei staked, at most %v TFuelWei can be deposited to each elite edge node",
+				holder.Address.Hex(), een.TotalStake(), maxStake)
+		}
+	}
+
+	feeCoins := types.Coins{ThetaWei: big.NewInt(0), TFuelWei: fee}
+	if !account.Balance.IsGTE(coins.Plus(feeCoins)) {
+		return newInsufficientFundError("Source balance is %v, but the stake and fee require %v", account.Balance, coins.Plus(feeCoins))
+	}
+
+	tx := &types.DepositStakeTxV2{
+		Fee: feeCoins,
+		Source: types.TxInput{
+			Address:  account.Address,
+			Coins:    coins,
+			Sequence: sequence,
+		},
+		Holder: types.TxOutput{
+			Address: holder.Address,
+		},
+		Purpose:   args.Purpose,
+		BlsPubkey: holder.BlsPubkey,
+		BlsPop:    holder.BlsPop,
+		HolderSig: holder.HolderSig,
+	}
+
+	result.TxBytes, result.SignBytes, err = encodeUnsignedTx(tx, tx.SignBytes(t.consensus.Chain().ChainID))
+	result.Sequence = common.JSONUint64(sequence)
+	return err
+}
+
+// ------------------------------ ComposeWithdrawStakeTx -----------------------------------
+
+type ComposeWithdrawStakeTxArgs struct {
+	Source   string            `json:"source"`   // Address of the staker
+	Holder   string            `json:"holder"`   // Address of the stake holder
+	Fee      string            `json:"fee"`      // Optional, default to the minimum transaction fee
+	Purpose  uint8             `json:"purpose"`  // 0: validator, 1: guardian, 2: elite edge node
+	Sequence common.JSONUint64 `json:"sequence"` // Optional, default to the next sequence of the source account
+}
+
+type ComposeWithdrawStakeTxResult struct {
+	TxBytes   string            `json:"tx_bytes"`   // The unsigned transaction
+	SignBytes string            `json:"sign_bytes"` // The bytes the source needs to sign
+	Sequence  common.JSONUint64 `json:"sequence"`
+}
+
+func (t *ThetaRPCService) ComposeWithdrawStakeTx(args *ComposeWithdrawStakeTxArgs, result *ComposeWithdrawStakeTxResult) (err error) {
+	defer t.guard("ComposeWithdrawStakeTx", &err)()
+
+	if err = validateStakePurpose(args.Purpose); err != nil {
+		return err
+	}
+
+	ledgerState, err := t.ledger.GetFinalizedSnapshot()
+	if err != nil {
+		return err
+	}
+	blockHeight := ledgerState.Height() + 1
+
+	fee, err := parseStakeTxFee(args.Fee, t.chain.ChainID, blockHeight)
+	if err != nil {
+		return err
+	}
+
+	holder := strings.TrimPrefix(args.Holder, "0x")
+	if len(holder) != 2*common.AddressLength {
+		return errors.New("Holder must be a valid address. For guardians and elite edge nodes, use the first 40 hex characters of the summary")
+	}
+	holderAddress := common.HexToAddress(holder)
+
+	account, sequence, err := getStakeSourceAccount(ledgerState, args.Source, uint64(args.Sequence))
+	if err != nil {
+		return err
+	}
+
+	if _, err = findActiveStake(ledgerState, args.Purpose, account.Address, holderAddress); err != nil {
+		return err
+	}
+
+	feeCoins := types.Coins{ThetaWei: big.NewInt(0), TFuelWei: fee}
+	if !account.Balance.IsGTE(feeCoins) {
+		return newInsufficientFundError("Source balance is %v, which is insufficient to pay the fee %v", account.Balance, feeCoins)
+	}
+
+	tx := &types.WithdrawStakeTx{
+		Fee: feeCoins,
+		Source: types.TxInput{
+			Address:  account.Address,
+			Sequence: sequence,
+		},
+		Holder: types.TxOutput{
+			Address: holderAddress,
+		},
+		Purpose: args.Purpose,
+	}
+
+	result.TxBytes, result.SignBytes, err = encodeUnsignedTx(tx, tx.SignBytes(t.consensus.Chain().ChainID))
+	result.Sequence = common.JSONUint64(sequence)
+	return err
+}
+
+// findActiveStake returns the stake of the source on the holder for the given purpose, which is not withdrawn
+func findActiveStake(view *state.StoreView, purpose uint8, source common.Address, holder common.Address) (*core.Stake, error) {
+	var stakeHolder *core.StakeHolder
+	switch purpose {
+	case core.StakeForValidator:
+		stakeHolder = view.GetValidatorCandidatePool().FindStakeDelegate(holder)
+	case core.StakeForGuardian:
+		if g := view.GetGuardianCandidatePool().GetWithHolderAddress(holder); g != nil {
+			stakeHolder = g.StakeHolder
+		}
+	case core.StakeForEliteEdgeNode:
+		if een := state.NewEliteEdgeNodePool(view, true).Get(holder); een != nil {
+			stakeHolder = een.StakeHolder
+		}
+	}
+	if stakeHolder == nil {
+		return nil, fmt.Errorf("%v is not a stake holder for purpose %v", holder.Hex(), purpose)
+	}
+	for _, stake := range stakeHolder.Stakes {
+		if stake.Source == source && !stake.Withdrawn {
+			return stake, nil
+		}
+	}
+	return nil, fmt.Errorf("%v has no active stake on holder %v", source.Hex(), holder.Hex())
+}
+
+// ------------------------------ SimulateStakeWithdrawal -----------------------------------
+
+// defaultBlockInterval is the block interval in seconds assumed when the recent blocks are not available
+const defaultBlockInterval = 6.0
+
+type SimulateStakeWithdrawalArgs struct {
+	Source  string `json:"source"`  // Address of the staker
+	Holder  string `json:"holder"`  // Address of the stake holder
+	Purpose uint8  `json:"purpose"` // 0: validator, 1: guardian, 2: elite edge node
+}
+
+type SimulateStakeWithdrawalResult struct {
+	BlockHeight          common.JSONUint64 `json:"block_height"`           // the latest finalized block the simulation is based on
+	Amount               *common.JSONBig   `json:"amount"`                 // the stake to withdraw
+	WithdrawalHeight     common.JSONUint64 `json:"withdrawal_height"`      // the block the withdrawal is included in if submitted now
+	ReturnHeight         common.JSONUint64 `json:"return_height"`          // the block the stake is returned at
+	AverageBlockInterval float64           `json:"average_block_interval"` // in seconds, over the recent blocks
+	EstimatedReturnTime  common.JSONUint64 `json:"estimated_return_time"`  // Unix timestamp in seconds
+
+	RewardCheckpoints   common.JSONUint64 `json:"reward_checkpoints"`    // the reward checkpoints during the lockup
+	RewardPerCheckpoint *common.JSONBig   `json:"reward_per_checkpoint"` // the expected reward of the stake per checkpoint
+	ForfeitedRewards    *common.JSONBig   `json:"forfeited_rewards"`     // the expected rewards the stake would earn during the lockup
+}
+
+// SimulateStakeWithdrawal simulates the withdrawal of a stake submitted now, without submitting it. The
+// forfeited rewards are the expected TFuel rewards of the stake before any reward split, with the current
+// reward rates and stakes, and with all the guardians voting.
+func (t *ThetaRPCService) SimulateStakeWithdrawal(args *SimulateStakeWithdrawalArgs, result *SimulateStakeWithdrawalResult) (err error) {
+	defer t.guard("SimulateStakeWithdrawal", &err)()
+
+	if err = validateStakePurpose(args.Purpose); err != nil {
+		return err
+	}
+	if args.Source == "" || args.Holder == "" {
+		return errors.New("Source and holder must be specified")
+	}
+
+	ledgerState, err := t.ledger.GetFinalizedSnapshot()
+	if err != nil {
+		return err
+	}
+	lfb := t.consensus.GetLastFinalizedBlock()
+	validatorSet := t.consensus.GetValidatorManager().GetNextValidatorSet(lfb.Hash())
+	err = result.simulate(t.chain.ChainID, ledgerState, validatorSet, args.Purpose,
+		common.HexToAddress(args.Source), common.HexToAddress(args.Holder))
+	if err != nil {
+		return err
+	}
+
+	result.AverageBlockInterval = defaultBlockInterval
+	if lfb.Height > 1 {
+		start := uint64(1)
+		if lfb.Height > defaultChainStatsWindow {
+			start = lfb.Height - defaultChainStatsWindow + 1
+		}
+		if stats, err := t.chain.GetChainStats(start, lfb.Height); err == nil && stats.AverageBlockInterval > 0 {
+			result.AverageBlockInterval = stats.AverageBlockInterval
+		}
+	}
+	remaining := float64(uint64(result.ReturnHeight) - lfb.Height)
+	result.EstimatedReturnTime = common.JSONUint64(lfb.Timestamp.Uint64() + uint64(remaining*result.AverageBlockInterval))
+	return nil
+}
+
+// simulate fills the heights and the forfeited rewards of the withdrawal on top of the given state
+func (result *SimulateStakeWithdrawalResult) simulate(chainID string, view *state.StoreView, validatorSet *core.ValidatorSet,
+	purpose uint8, source common.Address, holder common.Address) error {
+	stake, err := findActiveStake(view, purpose, source, holder)
+	if err != nil {
+		return err
+	}
+	withdrawalHeight := view.Height() + 1
+	returnHeight := withdrawalHeight + core.ReturnLockingPeriod
+	result.BlockHeight = common.JSONUint64(view.Height())
+	result.Amount = (*common.JSONBig)(new(big.Int).Set(stake.Amount))
+	result.WithdrawalHeight = common.JSONUint64(withdrawalHeight)
+	result.ReturnHeight = common.JSONUint64(returnHeight)
+
+	// The stake earns no reward from the checkpoint after the withdrawal to the return, since the reward of
+	// a checkpoint is issued before the transactions of its block
+	interval := uint64(common.CheckpointInterval)
+	numCheckpoints := returnHeight/interval - withdrawalHeight/interval
+	result.RewardCheckpoints = common.JSONUint64(numCheckpoints)
+
+	validatorGuardianReward, eenReward := exec.StakingRewardsPerBlock(chainID, withdrawalHeight)
+	rewardPerBlock, totalStake := big.NewInt(0), big.NewInt(0)
+	switch purpose {
+	case core.StakeForValidator, core.StakeForGuardian:
+		if validatorSet != nil {
+			// The stakes of the candidates not elected as validators earn no reward
+			if _, err := validatorSet.GetValidator(holder); purpose == core.StakeForGuardian || err == nil {
+				rewardPerBlock = validatorGuardianReward
+			}
+			totalStake.Add(totalStake, validatorSet.TotalStake())
+		}
+		if core.IsForkActive(chainID, core.ForkTheta2, withdrawalHeight) {
+			for _, g := range view.GetGuardianCandidatePool().WithStake().SortedGuardians {
+				totalStake.Add(totalStake, g.TotalStake())
+			}
+		} else if purpose == core.StakeForGuardian {
+			rewardPerBlock = big.NewInt(0)
+		}
+	case core.StakeForEliteEdgeNode:
+		rewardPerBlock = eenReward
+		totalStake = view.GetTotalEENStake()
+	}
+
+	rewardPerCheckpoint := big.NewInt(0)
+	if totalStake.Sign() > 0 {
+		rewardPerCheckpoint.Mul(rewardPerBlock, big.NewInt(common.CheckpointInterval))
+		rewardPerCheckpoint.Mul(rewardPerCheckpoint, stake.Amount)
+		rewardPerCheckpoint.Div(rewardPerCheckpoint, totalStake)
+	}
+	result.RewardPerCheckpoint = (*common.JSONBig)(rewardPerCheckpoint)
+	result.ForfeitedRewards = (*common.JSONBig)(new(big.Int).Mul(rewardPerCheckpoint, new(big.Int).SetUint64(numCheckpoints)))
+	return nil
+}
+
+// ------------------------------ GetStakingParams -----------------------------------
+
+type GetStakingParamsArgs struct {
+	Height common.JSONUint64 `json:"height"` // 0 for the next block
+}
+
+// StakingRoleParams are the staking requirements of a node role. The stakes of the validators and guardians
+// are in ThetaWei, and those of the elite edge nodes in TFuelWei.
+type StakingRoleParams struct {
+	Enabled     bool            `json:"enabled"`
+	MinStake    *common.JSONBig `json:"min_stake"`     // min amount of each deposit
+	MaxStake    *common.JSONBig `json:"max_stake"`     // max total stake of a node, nil if uncapped
+	MaxPoolSize int             `json:"max_pool_size"` // max number of active nodes, 0 if unlimited
+}
+
+// StakingParamsChange is a fork which changes the staking params
+type StakingParamsChange struct {
+	Fork        string            `json:"fork"`
+	Height      common.JSONUint64 `json:"height"`
+	Active      bool              `json:"active"`
+	Description string            `json:"description"`
+}
+
+type GetStakingParamsResult struct {
+	BlockHeight common.JSONUint64 `json:"block_height"`
+
+	Validator     StakingRoleParams `json:"validator"`
+	Guardian      StakingRoleParams `json:"guardian"`
+	EliteEdgeNode StakingRoleParams `json:"elite_edge_node"`
+
+	ReturnLockingPeriod common.JSONUint64 `json:"return_locking_period"` // blocks between the withdrawal and the return of a stake
+
+	RewardInterval                  common.JSONUint64 `json:"reward_interval"`                     // rewards are issued every this number of blocks
+	ValidatorGuardianRewardPerBlock *common.JSONBig   `json:"validator_guardian_reward_per_block"` // TFuelWei
+	EliteEdgeNodeRewardPerBlock     *common.JSONBig   `json:"elite_edge_node_reward_per_block"`    // TFuelWei
+	SampledStakingReward            bool              `json:"sampled_staking_reward"`              // validator and guardian rewards go to sampled stakes
+
+	Changes []StakingParamsChange `json:"changes"`
+}
+
+// stakingParamsChanges lists the forks which change the staking params, and how
+var stakingParamsChanges = []struct {
+	fork        string
+	description string
+}{
+	{core.ForkValidatorReward, "Validators start to receive the TFuel staking rewards"},
+	{core.ForkTheta2, "Guardians join the consensus and share the TFuel staking rewards with the validators"},
+	{core.ForkLowerGNStakeThreshold, fmt.Sprintf("Min guardian stake lowered to %v ThetaWei", core.MinGuardianStakeDeposit1000)},
+	{core.ForkSampleStakingReward, "Validator and guardian rewards are issued to randomly sampled stakes"},
+	{core.ForkTheta3, "Elite edge node staking enabled with its own TFuel rewards"},
+	{core.ForkStakeAutoCompounding, "TFuel rewards can be compounded into the elite edge node stakes"},
+}
+
+func (t *ThetaRPCService) GetStakingParams(args *GetStakingParamsArgs, result *GetStakingParamsResult) (err error) {
+	defer t.guard("GetStakingParams", &err)()
+
+	height := uint64(args.Height)
+	if height == 0 {
+		height = t.consensus.GetLastFinalizedBlock().Height + 1
+	}
+	result.BlockHeight = common.JSONUint64(height)
+
+	result.Validator = StakingRoleParams{
+		Enabled:     true,
+		MinStake:    (*common.JSONBig)(core.MinValidatorStake(t.chain.ChainID)),
+		MaxPoolSize: consensus.MaxValidatorCount,
+	}
+
+	result.Guardian = StakingRoleParams{
+		Enabled:  core.IsForkActive(t.chain.ChainID, core.ForkTheta2, height),
+		MinStake: (*common.JSONBig)(core.MinGuardianStake(t.chain.ChainID, height)),
+	}
+
+	result.EliteEdgeNode = StakingRoleParams{
+		Enabled:  core.IsForkActive(t.chain.ChainID, core.ForkTheta3, height),
+		MinStake: (*common.JSONBig)(core.MinEliteEdgeNodeStake(t.chain.ChainID)),
+		MaxStake: (*common.JSONBig)(core.MaxEliteEdgeNodeStake(t.chain.ChainID)),
+	}
+
+	result.ReturnLockingPeriod = common.JSONUint64(core.ReturnLockingPeriod)
+
+	validatorGuardianReward, eenReward := exec.StakingRewardsPerBlock(t.chain.ChainID, height)
+	result.RewardInterval = common.JSONUint64(common.CheckpointInterval)
+	result.ValidatorGuardianRewardPerBlock = (*common.JSONBig)(validatorGuardianReward)
+	result.EliteEdgeNodeRewardPerBlock = (*common.JSONBig)(eenReward)
+	result.SampledStakingReward = core.IsForkActive(t.chain.ChainID, core.ForkSampleStakingReward, height)
+
+	result.Changes = []StakingParamsChange{}
+	for _, change := range stakingParamsChanges {
+		result.Changes = append(result.Changes, StakingParamsChange{
+			Fork:        change.fork,
+			Height:      common.JSONUint64(core.ForkHeight(t.chain.ChainID, change.fork)),
+			Active:      core.IsForkActive(t.chain.ChainID, change.fork, height),
+			Description: change.description,
+		})
+	}
+
+	return nil
+}
+
+// encodeUnsignedTx hex encodes the unsigned transaction along with its sign bytes
+func encodeUnsignedTx(tx types.Tx, signBytes []byte) (string, string, error) {
+	raw, err := types.TxToBytes(tx)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to encode transaction: %v", err)
+	}
+	return hex.EncodeToString(raw), hex.EncodeToString(signBytes), nil
+}
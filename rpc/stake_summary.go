@@ -0,0 +1,189 @@
+package rpc
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+)
+
+const (
+	StakeRoleValidator     = "validator"
+	StakeRoleGuardian      = "guardian"
+	StakeRoleEliteEdgeNode = "elite_edge_node"
+
+	defaultStakeSummaryRewardCheckpoints = 5
+	maxStakeSummaryRewardCheckpoints     = 50
+)
+
+// ------------------------------ GetStakeSummary -----------------------------------
+
+type GetStakeSummaryArgs struct {
+	Address           string            `json:"address"`
+	RewardCheckpoints common.JSONUint64 `json:"reward_checkpoints"` // number of recent checkpoints to collect the rewards from, default to 5
+}
+
+type StakeSummaryEntry struct {
+	Role         string            `json:"role"`
+	Holder       common.Address    `json:"holder"`
+	Source       common.Address    `json:"source"`
+	Amount       *common.JSONBig   `json:"amount"`
+	Withdrawn    bool              `json:"withdrawn"`
+	ReturnHeight common.JSONUint64 `json:"return_height,omitempty"`
+}
+
+type StakeSummaryReward struct {
+	Height common.JSONUint64 `json:"height"`
+	*RewardShare
+}
+
+type GetStakeSummaryResult struct {
+	BlockHeight common.JSONUint64 `json:"block_height"`
+
+	Stakes         []*StakeSummaryEntry `json:"stakes"`          // stakes deposited by or delegated to the address
+	PendingReturns []*StakeSummaryEntry `json:"pending_returns"` // withdrawn stakes not returned yet
+
+	TotalStaked    map[string]*common.JSONBig `json:"total_staked"`    // by role, for the active stakes deposited by the address
+	TotalDelegated map[string]*common.JSONBig `json:"total_delegated"` // by role, for the active stakes held by the address
+
+	Rewards            []*StakeSummaryReward `json:"rewards"`             // rewards of the recent checkpoints
+	RewardsUnavailable []common.JSONUint64   `json:"rewards_unavailable"` // recent checkpoints whose reward breakdown is unavailable, e.g. pruned
+	TotalRewards       *common.JSONBig       `json:"total_rewards"`
+
+	SplitRules []*core.RewardDistribution `json:"split_rules"` // stake reward distribution rules with the address as the holder or the beneficiary
+}
+
+func (t *ThetaRPCService) GetStakeSummary(args *GetStakeSummaryArgs, result *GetStakeSummaryResult) (err error) {
+	defer t.guard("GetStakeSummary", &err)()
+
+	if args.Address == "" {
+		return errors.New("Address must be specified")
+	}
+	address := common.HexToAddress(args.Address)
+
+	numCheckpoints := uint64(args.RewardCheckpoints)
+	if numCheckpoints == 0 {
+		numCheckpoints = defaultStakeSummaryRewardCheckpoints
+	}
+	if numCheckpoints > maxStakeSummaryRewardCheckpoints {
+		numCheckpoints = maxStakeSummaryRewardCheckpoints
+	}
+
+	finalizedView, err := t.ledger.GetFinalizedSnapshot()
+	if err != nil {
+		return err
+	}
+	height := finalizedView.Height()
+	result.BlockHeight = common.JSONUint64(height)
+
+	result.collectStakes(address, finalizedView)
+
+	// Collect the rewards from the reward breakdowns of the recent checkpoints
+	result.Rewards = []*StakeSummaryReward{}
+	result.RewardsUnavailable = []common.JSONUint64{}
+	totalRewards := big.NewInt(0)
+	interval := uint64(common.CheckpointInterval)
+	checkpoint := height / interval * interval
+	for i := uint64(0); i < numCheckpoints && checkpoint > 0; i++ {
+		distribution := &GetRewardDistributionResult{}
+		if t.GetRewardDistribution(&GetRewardDistributionArgs{Height: common.JSONUint64(checkpoint)}, distribution) != nil {
+			result.RewardsUnavailable = append(result.RewardsUnavailable, common.JSONUint64(checkpoint))
+		} else {
+			result.addRewards(address, checkpoint, distribution.Shares, totalRewards)
+		}
+		if checkpoint < interval {
+			break
+		}
+		checkpoint -= interval
+	}
+	result.TotalRewards = (*common.JSONBig)(totalRewards)
+
+	return nil
+}
+
+// collectStakes collects the stakes deposited by or delegated to the address, and the reward split rules
+// involving the address from the given state
+func (result *GetStakeSummaryResult) collectStakes(address common.Address, view *state.StoreView) {
+	result.Stakes = []*StakeSummaryEntry{}
+	result.PendingReturns = []*StakeSummaryEntry{}
+	result.TotalStaked = map[string]*common.JSONBig{}
+	result.TotalDelegated = map[string]*common.JSONBig{}
+	addStakes := func(role string, holders []*core.StakeHolder) {
+		totalStaked, totalDelegated := big.NewInt(0), big.NewInt(0)
+		for _, holder := range holders {
+			for _, stake := range holder.Stakes {
+				if holder.Holder != address && stake.Source != address {
+					continue
+				}
+				entry := &StakeSummaryEntry{
+					Role:      role,
+					Holder:    holder.Holder,
+					Source:    stake.Source,
+					Amount:    (*common.JSONBig)(stake.Amount),
+					Withdrawn: stake.Withdrawn,
+				}
+				if stake.Withdrawn {
+					entry.ReturnHeight = common.JSONUint64(stake.ReturnHeight)
+					result.PendingReturns = append(result.PendingReturns, entry)
+					continue
+				}
+				result.Stakes = append(result.Stakes, entry)
+				if stake.Source == address {
+					totalStaked.Add(totalStaked, stake.Amount)
+				}
+				if holder.Holder == address {
+					totalDelegated.Add(totalDelegated, stake.Amount)
+				}
+			}
+		}
+		result.TotalStaked[role] = (*common.JSONBig)(totalStaked)
+		result.TotalDelegated[role] = (*common.JSONBig)(totalDelegated)
+	}
+
+	if vcp := view.GetValidatorCandidatePool(); vcp != nil {
+		addStakes(StakeRoleValidator, vcp.SortedCandidates)
+	}
+	if gcp := view.GetGuardianCandidatePool(); gcp != nil {
+		holders := make([]*core.StakeHolder, len(gcp.SortedGuardians))
+		for i, g := range gcp.SortedGuardians {
+			holders[i] = g.StakeHolder
+		}
+		addStakes(StakeRoleGuardian, holders)
+	}
+	eens := state.NewEliteEdgeNodePool(view, true).GetAll(true)
+	holders := make([]*core.StakeHolder, len(eens))
+	for i, een := range eens {
+		holders[i] = een.StakeHolder
+	}
+	addStakes(StakeRoleEliteEdgeNode, holders)
+
+	result.SplitRules = []*core.RewardDistribution{}
+	for _, rd := range state.NewStakeRewardDistributionRuleSet(view).GetAll() {
+		if rd.StakeHolder == address || rd.Beneficiary == address {
+			result.SplitRules = append(result.SplitRules, rd)
+		}
+	}
+}
+
+// addRewards adds the reward shares of the checkpoint involving the address. The address earns the
+// reward of its own stakes less the split, plus the splits it is the beneficiary of.
+func (result *GetStakeSummaryResult) addRewards(address common.Address, checkpoint uint64, shares []*RewardShare, totalRewards *big.Int) {
+	for _, share := range shares {
+		if share.Source != address && share.Holder != address && share.Beneficiary != address {
+			continue
+		}
+		result.Rewards = append(result.Rewards, &StakeSummaryReward{
+			Height:      common.JSONUint64(checkpoint),
+			RewardShare: share,
+		})
+		if share.Source == address {
+			totalRewards.Add(totalRewards, (*big.Int)(share.Reward))
+			totalRewards.Sub(totalRewards, (*big.Int)(share.SplitReward))
+		}
+		if share.Beneficiary == address {
+			totalRewards.Add(totalRewards, (*big.Int)(share.SplitReward))
+		}
+	}
+}
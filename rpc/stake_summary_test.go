@@ -0,0 +1,166 @@
+package rpc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto/bls"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestCollectStakes(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	address := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	beneficiary := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	blsKey, err := bls.RandKey()
+	require.Nil(err)
+
+	view := state.NewStoreView(0, common.Hash{}, backend.NewMemDatabase())
+	view.UpdateValidatorCandidatePool(&core.ValidatorCandidatePool{SortedCandidates: []*core.StakeHolder{
+		{Holder: address, Stakes: []*core.Stake{
+			{Source: address, Amount: big.NewInt(100)},
+			{Source: other, Amount: big.NewInt(200)},
+		}},
+		{Holder: other, Stakes: []*core.Stake{
+			{Source: other, Amount: big.NewInt(300)},
+		}},
+	}})
+	gcp := core.NewGuardianCandidatePool()
+	gcp.Add(&core.Guardian{
+		StakeHolder: &core.StakeHolder{Holder: other, Stakes: []*core.Stake{
+			{Source: address, Amount: big.NewInt(10)},
+			{Source: address, Amount: big.NewInt(20), Withdrawn: true, ReturnHeight: 1000},
+		}},
+		Pubkey: blsKey.PublicKey(),
+	})
+	view.UpdateGuardianCandidatePool(gcp)
+	state.NewEliteEdgeNodePool(view, false).Upsert(core.NewEliteEdgeNode(
+		&core.StakeHolder{Holder: other, Stakes: []*core.Stake{{Source: address, Amount: big.NewInt(5)}}}, blsKey.PublicKey()))
+	rules := state.NewStakeRewardDistributionRuleSet(view)
+	rules.Upsert(&core.RewardDistribution{StakeHolder: other, Beneficiary: address, SplitBasisPoint: 100})
+	rules.Upsert(&core.RewardDistribution{StakeHolder: beneficiary, Beneficiary: beneficiary, SplitBasisPoint: 100})
+
+	result := &GetStakeSummaryResult{}
+	result.collectStakes(address, view)
+
+	// The stakes of the other holders from the other sources are left out
+	assert.Equal(4, len(result.Stakes))
+	assert.Equal(1, len(result.PendingReturns))
+	assert.Equal(StakeRoleGuardian, result.PendingReturns[0].Role)
+	assert.Equal(common.JSONUint64(1000), result.PendingReturns[0].ReturnHeight)
+
+	assert.Equal(int64(100), result.TotalStaked[StakeRoleValidator].ToInt().Int64())
+	assert.Equal(int64(300), result.TotalDelegated[StakeRoleValidator].ToInt().Int64())
+	assert.Equal(int64(10), result.TotalStaked[StakeRoleGuardian].ToInt().Int64())
+	assert.Equal(int64(0), result.TotalDelegated[StakeRoleGuardian].ToInt().Int64())
+	assert.Equal(int64(5), result.TotalStaked[StakeRoleEliteEdgeNode].ToInt().Int64())
+
+	require.Equal(1, len(result.SplitRules))
+	assert.Equal(other, result.SplitRules[0].StakeHolder)
+}
+
+func TestAddStakeRewards(t *testing.T) {
+	assert := assert.New(t)
+
+	address := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	jsonBig := func(x int64) *common.JSONBig {
+		return (*common.JSONBig)(big.NewInt(x))
+	}
+
+	result := &GetStakeSummaryResult{}
+	totalRewards := big.NewInt(0)
+	result.addRewards(address, 100, []*RewardShare{
+		{Holder: other, Source: address, Reward: jsonBig(1000), Beneficiary: other, SplitReward: jsonBig(100)}, // own stake, split
+		{Holder: other, Source: other, Reward: jsonBig(500), Beneficiary: address, SplitReward: jsonBig(50)},   // beneficiary of the split
+		{Holder: other, Source: other, Reward: jsonBig(700), SplitReward: jsonBig(0)},                          // unrelated
+	}, totalRewards)
+	result.addRewards(address, 200, []*RewardShare{
+		{Holder: address, Source: address, Reward: jsonBig(10), SplitReward: jsonBig(0)},
+	}, totalRewards)
+
+	assert.Equal(3, len(result.Rewards))
+	assert.Equal(common.JSONUint64(100), result.Rewards[0].Height)
+	assert.Equal(common.JSONUint64(200), result.Rewards[2].Height)
+	assert.Equal(int64(1000-100+50+10), totalRewards.Int64())
+}
+
+func TestSimulateStakeWithdrawal(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	chainID := "testnet"
+	require.Nil(core.LoadChainParams(chainID, core.ChainParams{
+		core.ChainParamValidatorRewardPerBlock:     big.NewInt(1000),
+		core.ChainParamEliteEdgeNodeRewardPerBlock: big.NewInt(500),
+	}))
+	defer core.LoadChainParams(chainID, nil)
+
+	address := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	candidate := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	blsKey, err := bls.RandKey()
+	require.Nil(err)
+
+	height := core.ForkHeight(chainID, core.ForkTheta3)
+	view := state.NewStoreView(height, common.Hash{}, backend.NewMemDatabase())
+	view.UpdateValidatorCandidatePool(&core.ValidatorCandidatePool{SortedCandidates: []*core.StakeHolder{
+		{Holder: address, Stakes: []*core.Stake{{Source: address, Amount: big.NewInt(100)}}},
+		{Holder: other, Stakes: []*core.Stake{{Source: other, Amount: big.NewInt(300)}}},
+		{Holder: candidate, Stakes: []*core.Stake{{Source: address, Amount: big.NewInt(50)}}},
+	}})
+	gcp := core.NewGuardianCandidatePool()
+	gcp.Add(&core.Guardian{
+		StakeHolder: &core.StakeHolder{Holder: other, Stakes: []*core.Stake{
+			{Source: address, Amount: big.NewInt(100)},
+			{Source: other, Amount: big.NewInt(20), Withdrawn: true, ReturnHeight: height + 10},
+		}},
+		Pubkey: blsKey.PublicKey(),
+	})
+	view.UpdateGuardianCandidatePool(gcp)
+	state.NewEliteEdgeNodePool(view, false).Upsert(core.NewEliteEdgeNode(
+		&core.StakeHolder{Holder: other, Stakes: []*core.Stake{{Source: address, Amount: big.NewInt(5)}}}, blsKey.PublicKey()))
+	view.SetTotalEENStake(big.NewInt(20))
+
+	validatorSet := core.NewValidatorSet()
+	validatorSet.SetValidators([]core.Validator{
+		{Address: address, Stake: big.NewInt(100)},
+		{Address: other, Stake: big.NewInt(300)},
+	})
+
+	// The validator and guardian rewards are shared by the validator stakes and the guardian stakes
+	result := &SimulateStakeWithdrawalResult{}
+	require.Nil(result.simulate(chainID, view, validatorSet, core.StakeForValidator, address, address))
+	assert.Equal(common.JSONUint64(height), result.BlockHeight)
+	assert.Equal(common.JSONUint64(height+1), result.WithdrawalHeight)
+	assert.Equal(common.JSONUint64(height+1+core.ReturnLockingPeriod), result.ReturnHeight)
+	assert.Equal(int64(100), result.Amount.ToInt().Int64())
+	assert.Equal(common.JSONUint64(core.ReturnLockingPeriod/uint64(common.CheckpointInterval)), result.RewardCheckpoints)
+	assert.Equal(int64(1000*100*100/500), result.RewardPerCheckpoint.ToInt().Int64())
+	assert.Equal(int64(1000*100*100/500)*int64(result.RewardCheckpoints), result.ForfeitedRewards.ToInt().Int64())
+
+	result = &SimulateStakeWithdrawalResult{}
+	require.Nil(result.simulate(chainID, view, validatorSet, core.StakeForGuardian, address, other))
+	assert.Equal(int64(1000*100*100/500), result.RewardPerCheckpoint.ToInt().Int64())
+
+	// The candidates not elected as validators earn no reward
+	result = &SimulateStakeWithdrawalResult{}
+	require.Nil(result.simulate(chainID, view, validatorSet, core.StakeForValidator, address, candidate))
+	assert.Equal(0, result.ForfeitedRewards.ToInt().Sign())
+
+	result = &SimulateStakeWithdrawalResult{}
+	require.Nil(result.simulate(chainID, view, validatorSet, core.StakeForEliteEdgeNode, address, other))
+	assert.Equal(int64(500*100*5/20), result.RewardPerCheckpoint.ToInt().Int64())
+
+	// Only the active stakes can be withdrawn
+	assert.NotNil(result.simulate(chainID, view, validatorSet, core.StakeForGuardian, other, other))
+	assert.NotNil(result.simulate(chainID, view, validatorSet, core.StakeForEliteEdgeNode, other, address))
+}
@@ -0,0 +1,109 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/crypto/bls"
+)
+
+func createGuardianSummary(t *testing.T) (common.Address, string) {
+	privKey, _, err := crypto.GenerateKeyPair()
+	assert.Nil(t, err)
+	blsKey, err := bls.RandKey()
+	assert.Nil(t, err)
+
+	popBytes := blsKey.PopProve().ToBytes()
+	sig, err := privKey.Sign(popBytes)
+	assert.Nil(t, err)
+
+	address := privKey.PublicKey().Address()
+	summary := address.Hex() + hex.EncodeToString(blsKey.PublicKey().ToBytes()) +
+		hex.EncodeToString(popBytes) + hex.EncodeToString(sig.ToBytes())
+	return address, summary
+}
+
+func TestParseHolder(t *testing.T) {
+	assert := assert.New(t)
+
+	holder, err := parseHolder(core.StakeForValidator, "0x2E833968E5bB786Ae419c4d13189fB081Cc43bab")
+	assert.Nil(err)
+	assert.Equal(common.HexToAddress("0x2E833968E5bB786Ae419c4d13189fB081Cc43bab"), holder.Address)
+
+	_, err = parseHolder(core.StakeForValidator, "0x2E833968E5bB786Ae419c4d1")
+	assert.NotNil(err)
+
+	address, summary := createGuardianSummary(t)
+	holder, err = parseHolder(core.StakeForGuardian, summary)
+	assert.Nil(err)
+	assert.Equal(address, holder.Address)
+	assert.NotNil(holder.BlsPubkey)
+	assert.NotNil(holder.BlsPop)
+	assert.NotNil(holder.HolderSig)
+
+	// The summary of a different holder does not pass the signature check
+	otherAddress, _ := createGuardianSummary(t)
+	_, err = parseHolder(core.StakeForGuardian, otherAddress.Hex()+summary[42:])
+	assert.NotNil(err)
+
+	// Guardian summaries are not valid elite edge node summaries
+	_, err = parseHolder(core.StakeForEliteEdgeNode, summary)
+	assert.NotNil(err)
+
+	summaryHash := crypto.Keccak256Hash([]byte(summary))
+	holder, err = parseHolder(core.StakeForEliteEdgeNode, summary+hex.EncodeToString(summaryHash[:]))
+	assert.Nil(err)
+	assert.Equal(address, holder.Address)
+
+	_, err = parseHolder(core.StakeForEliteEdgeNode, summary+hex.EncodeToString(make([]byte, 32)))
+	assert.NotNil(err)
+}
+
+func TestValidateStakeAmount(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NotNil(validateStakeAmount(core.StakeForValidator, big.NewInt(0), core.MainnetChainID, 0))
+	assert.NotNil(validateStakeAmount(core.StakeForValidator, new(big.Int).Sub(core.MinValidatorStakeDeposit, big.NewInt(1)), core.MainnetChainID, 0))
+	assert.Nil(validateStakeAmount(core.StakeForValidator, core.MinValidatorStakeDeposit, core.MainnetChainID, 0))
+
+	assert.NotNil(validateStakeAmount(core.StakeForGuardian, core.MinGuardianStakeDeposit1000, core.MainnetChainID, common.HeightLowerGNStakeThresholdTo1000-1))
+	assert.Nil(validateStakeAmount(core.StakeForGuardian, core.MinGuardianStakeDeposit1000, core.MainnetChainID, common.HeightLowerGNStakeThresholdTo1000))
+
+	assert.Nil(validateStakeAmount(core.StakeForEliteEdgeNode, core.MinEliteEdgeNodeStakeDeposit, core.MainnetChainID, common.HeightEnableTheta3))
+	assert.NotNil(validateStakeAmount(core.StakeForEliteEdgeNode, new(big.Int).Add(core.MaxEliteEdgeNodeStakeDeposit, big.NewInt(1)), core.MainnetChainID, common.HeightEnableTheta3))
+
+	assert.NotNil(validateStakePurpose(3))
+}
+
+func TestGetStakingParams(t *testing.T) {
+	assert := assert.New(t)
+
+	service := &ThetaRPCService{
+		chain:    &blockchain.Chain{ChainID: core.MainnetChainID},
+		breakers: NewCircuitBreakers(0.4, 1, time.Minute, time.Minute, 0, ""),
+	}
+
+	result := &GetStakingParamsResult{}
+	assert.Nil(service.GetStakingParams(&GetStakingParamsArgs{Height: common.JSONUint64(common.HeightEnableTheta3 - 1)}, result))
+	assert.False(result.EliteEdgeNode.Enabled)
+	assert.Equal(0, result.EliteEdgeNodeRewardPerBlock.ToInt().Sign())
+	assert.Equal(0, core.MinGuardianStakeDeposit1000.Cmp(result.Guardian.MinStake.ToInt()))
+	assert.Equal(common.JSONUint64(core.ReturnLockingPeriod), result.ReturnLockingPeriod)
+
+	result = &GetStakingParamsResult{}
+	assert.Nil(service.GetStakingParams(&GetStakingParamsArgs{Height: common.JSONUint64(common.HeightEnableTheta3)}, result))
+	assert.True(result.EliteEdgeNode.Enabled)
+	assert.Equal(1, result.EliteEdgeNodeRewardPerBlock.ToInt().Sign())
+	assert.Equal(0, core.MaxEliteEdgeNodeStakeDeposit.Cmp(result.EliteEdgeNode.MaxStake.ToInt()))
+	assert.Nil(result.Guardian.MaxStake)
+	for _, change := range result.Changes {
+		assert.Equal(change.Height <= result.BlockHeight, change.Active, change.Fork)
+	}
+}
@@ -0,0 +1,543 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+)
+
+//
+// The storage layout output of solc describes where each state variable of a contract is stored, so that the
+// variables can be read from the storage trie without computing the slots by hand. A variable is selected by
+// a path starting with its name, followed by the mapping keys and the array indices in brackets, and the struct
+// members after dots, e.g. owner, balances[0x2e833968e5bb786ae419c4d13189fb081cc43bab], orders[3].price or
+// allowances[0x2e83...][0x9f1a...].
+//
+
+const (
+	// maxStorageLayoutArrayItems is the maximum number of the items of a dynamic array returned, the items beyond
+	// need to be selected by index
+	maxStorageLayoutArrayItems = 100
+
+	// maxStorageLayoutSlotReads limits the number of the storage slots read by a single query
+	maxStorageLayoutSlotReads = 10000
+)
+
+// StorageLayout is the storage layout of a contract as output by solc
+type StorageLayout struct {
+	Storage []*StorageLayoutEntry         `json:"storage"`
+	Types   map[string]*StorageLayoutType `json:"types"`
+}
+
+// StorageLayoutEntry is a state variable, or a member of a struct
+type StorageLayoutEntry struct {
+	Label  string `json:"label"`
+	Offset uint64 `json:"offset"` // offset in bytes within the slot, from the lower-order end
+	Slot   string `json:"slot"`   // decimal slot number, relative to the struct for a member
+	Type   string `json:"type"`
+}
+
+// StorageLayoutType describes how the values of a type are stored
+type StorageLayoutType struct {
+	Encoding      string                `json:"encoding"` // inplace, mapping, dynamic_array or bytes
+	Label         string                `json:"label"`
+	NumberOfBytes string                `json:"numberOfBytes"`
+	Key           string                `json:"key,omitempty"`   // key type of a mapping
+	Value         string                `json:"value,omitempty"` // value type of a mapping
+	Base          string                `json:"base,omitempty"`  // item type of an array
+	Members       []*StorageLayoutEntry `json:"members,omitempty"`
+}
+
+// storageLocation is the location of a value in the storage
+type storageLocation struct {
+	typeID string
+	slot   *big.Int
+	offset uint64
+}
+
+// storageLayoutDecoder decodes the values of the variables of a storage layout
+type storageLayoutDecoder struct {
+	layout   *StorageLayout
+	readSlot func(key common.Hash) common.Hash
+	slots    map[common.Hash]common.Hash
+}
+
+func newStorageLayoutDecoder(layout *StorageLayout, readSlot func(key common.Hash) common.Hash) *storageLayoutDecoder {
+	return &storageLayoutDecoder{
+		layout:   layout,
+		readSlot: readSlot,
+		slots:    make(map[common.Hash]common.Hash),
+	}
+}
+
+func (d *storageLayoutDecoder) getType(typeID string) (*StorageLayoutType, error) {
+	t, ok := d.layout.Types[typeID]
+	if !ok {
+		return nil, fmt.Errorf("Type %v is not defined in the storage layout", typeID)
+	}
+	return t, nil
+}
+
+func (d *storageLayoutDecoder) read(slot *big.Int) (common.Hash, error) {
+	key := common.BigToHash(slot)
+	if value, ok := d.slots[key]; ok {
+		return value, nil
+	}
+	if len(d.slots) >= maxStorageLayoutSlotReads {
+		return common.Hash{}, fmt.Errorf("The query reads more than %v storage slots", maxStorageLayoutSlotReads)
+	}
+	value := d.readSlot(key)
+	d.slots[key] = value
+	return value, nil
+}
+
+func parseSlot(slot string) (*big.Int, error) {
+	value, ok := new(big.Int).SetString(slot, 0)
+	if !ok {
+		return nil, fmt.Errorf("Invalid slot: %v", slot)
+	}
+	return value, nil
+}
+
+func parseNumberOfBytes(t *StorageLayoutType) (uint64, error) {
+	size, err := strconv.ParseUint(t.NumberOfBytes, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid number of bytes of type %v: %v", t.Label, t.NumberOfBytes)
+	}
+	return size, nil
+}
+
+// keccakSlot returns the slot of the data of a dynamic array or a long bytes/string stored at the given slot
+func keccakSlot(slot *big.Int) *big.Int {
+	return new(big.Int).SetBytes(crypto.Keccak256(common.BigToHash(slot).Bytes()))
+}
+
+// resolve returns the location of the value selected by the given path
+func (d *storageLayoutDecoder) resolve(path string) (*storageLocation, error) {
+	name := path
+	if i := strings.IndexAny(path, "[."); i >= 0 {
+		name = path[:i]
+	}
+	var loc *storageLocation
+	for _, entry := range d.layout.Storage {
+		if entry.Label == name {
+			slot, err := parseSlot(entry.Slot)
+			if err != nil {
+				return nil, err
+			}
+			loc = &storageLocation{typeID: entry.Type, slot: slot, offset: entry.Offset}
+			break
+		}
+	}
+	if loc == nil {
+		return nil, fmt.Errorf("Variable %v is not in the storage layout", name)
+	}
+
+	rest := path[len(name):]
+	for len(rest) > 0 {
+		t, err := d.getType(loc.typeID)
+		if err != nil {
+			return nil, err
+		}
+		switch rest[0] {
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("Unclosed bracket in %v", path)
+			}
+			key := rest[1:end]
+			rest = rest[end+1:]
+			if loc, err = d.resolveIndex(t, loc, key); err != nil {
+				return nil, err
+			}
+		case '.':
+			member := rest[1:]
+			if i := strings.IndexAny(member, "[."); i >= 0 {
+				member = member[:i]
+			}
+			rest = rest[1+len(member):]
+			if loc, err = d.resolveMember(t, loc, member); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("Invalid variable path: %v", path)
+		}
+	}
+	return loc, nil
+}
+
+func (d *storageLayoutDecoder) resolveIndex(t *StorageLayoutType, loc *storageLocation, key string) (*storageLocation, error) {
+	switch {
+	case t.Encoding == "mapping":
+		keyType, err := d.getType(t.Key)
+		if err != nil {
+			return nil, err
+		}
+		encodedKey, err := encodeMappingKey(keyType, key)
+		if err != nil {
+			return nil, err
+		}
+		// The value of key k is at keccak256(h(k) . p), where p is the slot of the mapping, and h(k) is the
+		// key padded to 32 bytes for the value types, or the unpadded bytes for the strings and bytes
+		slot := new(big.Int).SetBytes(crypto.Keccak256(encodedKey, common.BigToHash(loc.slot).Bytes()))
+		return &storageLocation{typeID: t.Value, slot: slot}, nil
+	case t.Base != "":
+		index, ok := new(big.Int).SetString(key, 0)
+		if !ok || index.Sign() < 0 || !index.IsUint64() {
+			return nil, fmt.Errorf("Invalid array index: %v", key)
+		}
+		length, dataSlot, err := d.arrayLength(t, loc)
+		if err != nil {
+			return nil, err
+		}
+		if index.Uint64() >= length {
+			return nil, fmt.Errorf("Array index %v is out of range, the length is %v", key, length)
+		}
+		return d.arrayItem(t, dataSlot, index.Uint64())
+	default:
+		return nil, fmt.Errorf("Type %v cannot be indexed", t.Label)
+	}
+}
+
+func (d *storageLayoutDecoder) resolveMember(t *StorageLayoutType, loc *storageLocation, name string) (*storageLocation, error) {
+	for _, member := range t.Members {
+		if member.Label == name {
+			slot, err := parseSlot(member.Slot)
+			if err != nil {
+				return nil, err
+			}
+			return &storageLocation{typeID: member.Type, slot: slot.Add(slot, loc.slot), offset: member.Offset}, nil
+		}
+	}
+	return nil, fmt.Errorf("Type %v has no member %v", t.Label, name)
+}
+
+// arrayLength returns the length of the array at the given location, and the slot of its first item
+func (d *storageLayoutDecoder) arrayLength(t *StorageLayoutType, loc *storageLocation) (uint64, *big.Int, error) {
+	if t.Encoding == "dynamic_array" {
+		word, err := d.read(loc.slot)
+		if err != nil {
+			return 0, nil, err
+		}
+		length := word.Big()
+		if !length.IsUint64() {
+			return 0, nil, fmt.Errorf("Invalid array length: %v", length)
+		}
+		return length.Uint64(), keccakSlot(loc.slot), nil
+	}
+
+	// The length of a static array is only in its label, e.g. uint256[3]
+	i := strings.LastIndex(t.Label, "[")
+	if i < 0 || !strings.HasSuffix(t.Label, "]") {
+		return 0, nil, fmt.Errorf("Invalid array type: %v", t.Label)
+	}
+	length, err := strconv.ParseUint(t.Label[i+1:len(t.Label)-1], 10, 64)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Invalid array type: %v", t.Label)
+	}
+	return length, loc.slot, nil
+}
+
+// arrayItem returns the location of the item of the given index. The items of up to 16 bytes are packed into the
+// slots, the others start a new slot each.
+func (d *storageLayoutDecoder) arrayItem(t *StorageLayoutType, dataSlot *big.Int, index uint64) (*storageLocation, error) {
+	base, err := d.getType(t.Base)
+	if err != nil {
+		return nil, err
+	}
+	size, err := parseNumberOfBytes(base)
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, fmt.Errorf("Invalid number of bytes of type %v", base.Label)
+	}
+	slot := new(big.Int).Set(dataSlot)
+	if size <= 16 {
+		perSlot := 32 / size
+		slot.Add(slot, new(big.Int).SetUint64(index/perSlot))
+		return &storageLocation{typeID: t.Base, slot: slot, offset: (index % perSlot) * size}, nil
+	}
+	slotsPerItem := (size + 31) / 32
+	slot.Add(slot, new(big.Int).Mul(new(big.Int).SetUint64(index), new(big.Int).SetUint64(slotsPerItem)))
+	return &storageLocation{typeID: t.Base, slot: slot}, nil
+}
+
+// decode decodes the value at the given location. The mappings cannot be enumerated, so their values need to be
+// selected by key.
+func (d *storageLayoutDecoder) decode(loc *storageLocation) (interface{}, error) {
+	t, err := d.getType(loc.typeID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.Encoding {
+	case "mapping":
+		return nil, nil
+	case "bytes":
+		return d.decodeBytes(t, loc.slot)
+	case "dynamic_array":
+		length, dataSlot, err := d.arrayLength(t, loc)
+		if err != nil {
+			return nil, err
+		}
+		items := []interface{}{}
+		for i := uint64(0); i < length && i < maxStorageLayoutArrayItems; i++ {
+			item, err := d.arrayItem(t, dataSlot, i)
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.decode(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return map[string]interface{}{"length": length, "items": items}, nil
+	case "inplace":
+		if len(t.Members) > 0 {
+			members := make(map[string]interface{})
+			for _, member := range t.Members {
+				slot, err := parseSlot(member.Slot)
+				if err != nil {
+					return nil, err
+				}
+				value, err := d.decode(&storageLocation{typeID: member.Type, slot: slot.Add(slot, loc.slot), offset: member.Offset})
+				if err != nil {
+					return nil, err
+				}
+				members[member.Label] = value
+			}
+			return members, nil
+		}
+		if t.Base != "" {
+			length, dataSlot, err := d.arrayLength(t, loc)
+			if err != nil {
+				return nil, err
+			}
+			items := []interface{}{}
+			for i := uint64(0); i < length; i++ {
+				item, err := d.arrayItem(t, dataSlot, i)
+				if err != nil {
+					return nil, err
+				}
+				value, err := d.decode(item)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, value)
+			}
+			return items, nil
+		}
+		return d.decodeValue(t, loc)
+	default:
+		return nil, fmt.Errorf("Unknown encoding %v of type %v", t.Encoding, t.Label)
+	}
+}
+
+// decodeValue decodes a value type, e.g. uint256, address or bytes4
+func (d *storageLayoutDecoder) decodeValue(t *StorageLayoutType, loc *storageLocation) (interface{}, error) {
+	size, err := parseNumberOfBytes(t)
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 || size > 32 || loc.offset+size > 32 {
+		return nil, fmt.Errorf("Invalid value of type %v at offset %v", t.Label, loc.offset)
+	}
+	word, err := d.read(loc.slot)
+	if err != nil {
+		return nil, err
+	}
+	// The offset counts from the lower-order end of the big-endian word
+	raw := word[32-loc.offset-size : 32-loc.offset]
+
+	switch label := t.Label; {
+	case label == "bool":
+		return new(big.Int).SetBytes(raw).Sign() != 0, nil
+	case label == "address" || label == "address payable" || strings.HasPrefix(label, "contract "):
+		return common.BytesToAddress(raw), nil
+	case strings.HasPrefix(label, "uint") || strings.HasPrefix(label, "enum "):
+		return new(big.Int).SetBytes(raw).String(), nil
+	case strings.HasPrefix(label, "int"):
+		value := new(big.Int).SetBytes(raw)
+		if raw[0]&0x80 != 0 {
+			value.Sub(value, new(big.Int).Lsh(big.NewInt(1), uint(8*size)))
+		}
+		return value.String(), nil
+	default: // bytesN, function types and the like
+		return "0x" + hex.EncodeToString(raw), nil
+	}
+}
+
+// decodeBytes decodes a bytes or a string. The data of up to 31 bytes is stored in the higher-order bytes of the
+// slot along with twice the length in the lowest-order byte. Otherwise the slot holds twice the length plus 1,
+// and the data is stored from keccak256 of the slot.
+func (d *storageLayoutDecoder) decodeBytes(t *StorageLayoutType, slot *big.Int) (interface{}, error) {
+	word, err := d.read(slot)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if word[31]&1 == 0 {
+		length := int(word[31] / 2)
+		if length > 31 {
+			return nil, errors.New("Invalid short bytes length")
+		}
+		data = word[:length]
+	} else {
+		length := new(big.Int).Rsh(word.Big(), 1)
+		if !length.IsUint64() || length.Uint64() > 32*maxStorageLayoutSlotReads {
+			return nil, fmt.Errorf("Bytes of length %v are too long", length)
+		}
+		dataSlot := keccakSlot(slot)
+		for remaining := length.Uint64(); remaining > 0; {
+			chunk, err := d.read(dataSlot)
+			if err != nil {
+				return nil, err
+			}
+			n := uint64(32)
+			if remaining < n {
+				n = remaining
+			}
+			data = append(data, chunk[:n]...)
+			remaining -= n
+			dataSlot = new(big.Int).Add(dataSlot, big.NewInt(1))
+		}
+	}
+
+	if t.Label == "string" {
+		return string(data), nil
+	}
+	return "0x" + hex.EncodeToString(data), nil
+}
+
+// encodeMappingKey encodes the given key of a mapping for the slot computation
+func encodeMappingKey(t *StorageLayoutType, key string) ([]byte, error) {
+	switch label := t.Label; {
+	case label == "string":
+		return []byte(strings.Trim(key, "\"")), nil
+	case label == "bytes":
+		return hex.DecodeString(strings.TrimPrefix(key, "0x"))
+	case label == "bool":
+		b, err := strconv.ParseBool(key)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid bool key: %v", key)
+		}
+		if b {
+			return common.LeftPadBytes([]byte{1}, 32), nil
+		}
+		return make([]byte, 32), nil
+	case label == "address" || label == "address payable" || strings.HasPrefix(label, "contract "):
+		if !common.IsHexAddress(key) {
+			return nil, fmt.Errorf("Invalid address key: %v", key)
+		}
+		return common.LeftPadBytes(common.HexToAddress(key).Bytes(), 32), nil
+	case strings.HasPrefix(label, "uint") || strings.HasPrefix(label, "int") || strings.HasPrefix(label, "enum "):
+		value, ok := new(big.Int).SetString(key, 0)
+		if !ok {
+			return nil, fmt.Errorf("Invalid integer key: %v", key)
+		}
+		if value.Sign() < 0 { // two's complement
+			value.Add(value, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		if value.Sign() < 0 || value.BitLen() > 256 {
+			return nil, fmt.Errorf("Integer key out of range: %v", key)
+		}
+		return common.BigToHash(value).Bytes(), nil
+	case strings.HasPrefix(label, "bytes"):
+		raw, err := hex.DecodeString(strings.TrimPrefix(key, "0x"))
+		if err != nil || len(raw) > 32 {
+			return nil, fmt.Errorf("Invalid %v key: %v", label, key)
+		}
+		return common.RightPadBytes(raw, 32), nil
+	default:
+		return nil, fmt.Errorf("Unsupported mapping key type: %v", label)
+	}
+}
+
+// ------------------------------ GetContractStorage -----------------------------------
+
+type GetContractStorageArgs struct {
+	Address   string            `json:"address"`
+	Height    common.JSONUint64 `json:"height"`    // the last finalized block if 0
+	Layout    *StorageLayout    `json:"layout"`    // the storageLayout output of solc
+	Variables []string          `json:"variables"` // all the variables of the layout if empty
+}
+
+type ContractStorageValue struct {
+	Variable string      `json:"variable"`
+	Type     string      `json:"type,omitempty"`
+	Slot     common.Hash `json:"slot"`
+	Offset   uint64      `json:"offset"`
+	Value    interface{} `json:"value"`
+	Error    string      `json:"error,omitempty"`
+}
+
+type GetContractStorageResult struct {
+	Address     common.Address          `json:"address"`
+	BlockHash   common.Hash             `json:"block_hash"`
+	BlockHeight common.JSONUint64       `json:"block_height"`
+	Values      []*ContractStorageValue `json:"values"`
+}
+
+func (t *ThetaRPCService) GetContractStorage(args *GetContractStorageArgs, result *GetContractStorageResult) (err error) {
+	defer t.guard("GetContractStorage", &err)()
+
+	if !common.IsHexAddress(args.Address) {
+		return errors.New("A valid contract address must be specified")
+	}
+	if args.Layout == nil || len(args.Layout.Storage) == 0 {
+		return errors.New("The storage layout must be specified")
+	}
+
+	view, block, err := t.getFinalizedView(uint64(args.Height))
+	if err != nil {
+		return err
+	}
+	address := common.HexToAddress(args.Address)
+	if view.GetAccount(address) == nil {
+		return newNotFoundError("Account with address %v is not found", address.Hex())
+	}
+
+	result.Address = address
+	result.BlockHash = block.Hash()
+	result.BlockHeight = common.JSONUint64(block.Height)
+
+	variables := args.Variables
+	if len(variables) == 0 {
+		for _, entry := range args.Layout.Storage {
+			variables = append(variables, entry.Label)
+		}
+	}
+
+	decoder := newStorageLayoutDecoder(args.Layout, func(key common.Hash) common.Hash {
+		return view.GetState(address, key)
+	})
+	result.Values = []*ContractStorageValue{}
+	for _, variable := range variables {
+		value := &ContractStorageValue{Variable: variable}
+		result.Values = append(result.Values, value)
+
+		loc, err := decoder.resolve(variable)
+		if err != nil {
+			value.Error = err.Error()
+			continue
+		}
+		value.Slot = common.BigToHash(loc.slot)
+		value.Offset = loc.offset
+		if typ, ok := args.Layout.Types[loc.typeID]; ok {
+			value.Type = typ.Label
+		}
+		if value.Value, err = decoder.decode(loc); err != nil {
+			value.Error = err.Error()
+		}
+	}
+
+	return nil
+}
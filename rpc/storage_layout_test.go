@@ -0,0 +1,147 @@
+package rpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/crypto"
+)
+
+// The storage layout of
+//
+//	contract Token {
+//		uint256 total;
+//		address owner;
+//		bool paused;
+//		mapping(address => uint256) balances;
+//		string name;
+//		uint8[] levels;
+//		struct Config { uint128 fee; int64 delta; }
+//		Config config;
+//	}
+const testStorageLayout = `{
+	"storage": [
+		{"label": "total", "offset": 0, "slot": "0", "type": "t_uint256"},
+		{"label": "owner", "offset": 0, "slot": "1", "type": "t_address"},
+		{"label": "paused", "offset": 20, "slot": "1", "type": "t_bool"},
+		{"label": "balances", "offset": 0, "slot": "2", "type": "t_mapping(t_address,t_uint256)"},
+		{"label": "name", "offset": 0, "slot": "3", "type": "t_string_storage"},
+		{"label": "levels", "offset": 0, "slot": "4", "type": "t_array(t_uint8)dyn_storage"},
+		{"label": "config", "offset": 0, "slot": "5", "type": "t_struct(Config)10_storage"}
+	],
+	"types": {
+		"t_uint256": {"encoding": "inplace", "label": "uint256", "numberOfBytes": "32"},
+		"t_uint128": {"encoding": "inplace", "label": "uint128", "numberOfBytes": "16"},
+		"t_int64": {"encoding": "inplace", "label": "int64", "numberOfBytes": "8"},
+		"t_uint8": {"encoding": "inplace", "label": "uint8", "numberOfBytes": "1"},
+		"t_address": {"encoding": "inplace", "label": "address", "numberOfBytes": "20"},
+		"t_bool": {"encoding": "inplace", "label": "bool", "numberOfBytes": "1"},
+		"t_string_storage": {"encoding": "bytes", "label": "string", "numberOfBytes": "32"},
+		"t_mapping(t_address,t_uint256)": {"encoding": "mapping", "key": "t_address", "label": "mapping(address => uint256)", "numberOfBytes": "32", "value": "t_uint256"},
+		"t_array(t_uint8)dyn_storage": {"base": "t_uint8", "encoding": "dynamic_array", "label": "uint8[]", "numberOfBytes": "32"},
+		"t_struct(Config)10_storage": {"encoding": "inplace", "label": "struct Token.Config", "numberOfBytes": "32", "members": [
+			{"label": "fee", "offset": 0, "slot": "0", "type": "t_uint128"},
+			{"label": "delta", "offset": 16, "slot": "0", "type": "t_int64"}
+		]}
+	}
+}`
+
+func TestStorageLayoutDecoder(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	layout := &StorageLayout{}
+	require.Nil(json.Unmarshal([]byte(testStorageLayout), layout))
+
+	owner := common.HexToAddress("0x2e833968e5bb786ae419c4d13189fb081cc43bab")
+	storage := map[common.Hash]common.Hash{}
+	slot := func(n int64) common.Hash { return common.BigToHash(big.NewInt(n)) }
+
+	storage[slot(0)] = common.BigToHash(big.NewInt(1000))
+	// owner and paused packed into slot 1
+	word := common.Hash{}
+	copy(word[12:], owner.Bytes())
+	word[11] = 1
+	storage[slot(1)] = word
+	storage[crypto.Keccak256Hash(common.LeftPadBytes(owner.Bytes(), 32), slot(2).Bytes())] = common.BigToHash(big.NewInt(42))
+	// short string
+	word = common.Hash{}
+	copy(word[:], "Theta")
+	word[31] = 2 * 5
+	storage[slot(3)] = word
+	// levels = [1, 2, 3], packed into a single slot
+	storage[slot(4)] = common.BigToHash(big.NewInt(3))
+	word = common.Hash{}
+	word[31], word[30], word[29] = 1, 2, 3
+	storage[crypto.Keccak256Hash(slot(4).Bytes())] = word
+	// config = {fee: 7, delta: -2}
+	word = common.Hash{}
+	word[31] = 7
+	for i := 8; i < 16; i++ {
+		word[i] = 0xff
+	}
+	word[15] = 0xfe
+	storage[slot(5)] = word
+
+	decoder := newStorageLayoutDecoder(layout, func(key common.Hash) common.Hash {
+		return storage[key]
+	})
+	decode := func(path string) interface{} {
+		loc, err := decoder.resolve(path)
+		require.Nil(err, path)
+		value, err := decoder.decode(loc)
+		require.Nil(err, path)
+		return value
+	}
+
+	assert.Equal("1000", decode("total"))
+	assert.Equal(owner, decode("owner"))
+	assert.Equal(true, decode("paused"))
+	assert.Equal("42", decode("balances["+owner.Hex()+"]"))
+	assert.Equal("0", decode("balances[0x0000000000000000000000000000000000000001]"))
+	assert.Nil(decode("balances"))
+	assert.Equal("Theta", decode("name"))
+	assert.Equal("3", decode("levels[2]"))
+	assert.Equal(map[string]interface{}{"length": uint64(3), "items": []interface{}{"1", "2", "3"}}, decode("levels"))
+	assert.Equal("-2", decode("config.delta"))
+	assert.Equal(map[string]interface{}{"fee": "7", "delta": "-2"}, decode("config"))
+
+	_, err := decoder.resolve("levels[3]")
+	assert.NotNil(err)
+	_, err = decoder.resolve("config.unknown")
+	assert.NotNil(err)
+	_, err = decoder.resolve("unknown")
+	assert.NotNil(err)
+	_, err = decoder.resolve("balances[notanaddress]")
+	assert.NotNil(err)
+}
+
+func TestStorageLayoutLongBytes(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	layout := &StorageLayout{}
+	require.Nil(json.Unmarshal([]byte(testStorageLayout), layout))
+
+	text := "a string longer than thirty one bytes, spanning two slots"
+	storage := map[common.Hash]common.Hash{}
+	storage[common.BigToHash(big.NewInt(3))] = common.BigToHash(big.NewInt(int64(2*len(text) + 1)))
+	dataSlot := new(big.Int).SetBytes(crypto.Keccak256(common.BigToHash(big.NewInt(3)).Bytes()))
+	storage[common.BigToHash(dataSlot)] = common.BytesToHash([]byte(text[:32]))
+	second := common.Hash{}
+	copy(second[:], text[32:])
+	storage[common.BigToHash(dataSlot.Add(dataSlot, big.NewInt(1)))] = second
+
+	decoder := newStorageLayoutDecoder(layout, func(key common.Hash) common.Hash {
+		return storage[key]
+	})
+	loc, err := decoder.resolve("name")
+	require.Nil(err)
+	value, err := decoder.decode(loc)
+	require.Nil(err)
+	assert.Equal(text, value)
+}
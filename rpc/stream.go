@@ -0,0 +1,146 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+)
+
+//
+// The streaming endpoints return very large results as chunked HTTP responses. The results
+// are encoded item by item while traversing the state, so neither the node nor the client
+// needs to hold the entire result in memory, and they are not subject to the RPC response size
+// limit. The JSON format is the same as the corresponding RPC result.
+//
+
+// jsonStreamWriter writes a JSON document in chunks, and flushes the chunks to the client periodically.
+type jsonStreamWriter struct {
+	w             io.Writer
+	flusher       http.Flusher
+	flushInterval int
+	numItems      int
+	err           error
+}
+
+func newJSONStreamWriter(w http.ResponseWriter) *jsonStreamWriter {
+	flusher, _ := w.(http.Flusher)
+	flushInterval := viper.GetInt(common.CfgRPCStreamFlushInterval)
+	if flushInterval <= 0 {
+		flushInterval = 1
+	}
+	return &jsonStreamWriter{
+		w:             w,
+		flusher:       flusher,
+		flushInterval: flushInterval,
+	}
+}
+
+// writeRaw writes a raw JSON fragment, e.g. "{" or "]".
+func (sw *jsonStreamWriter) writeRaw(fragment string) {
+	if sw.err != nil {
+		return
+	}
+	_, sw.err = io.WriteString(sw.w, fragment)
+}
+
+// writeItem writes a JSON encoded value, preceded by a comma unless it is the first item of a list.
+func (sw *jsonStreamWriter) writeItem(first bool, v interface{}) {
+	if sw.err != nil {
+		return
+	}
+	if !first {
+		sw.writeRaw(",")
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		sw.err = err
+		return
+	}
+	_, sw.err = sw.w.Write(raw)
+
+	sw.numItems++
+	if sw.numItems%sw.flushInterval == 0 {
+		sw.flush()
+	}
+}
+
+func (sw *jsonStreamWriter) flush() {
+	if sw.err == nil && sw.flusher != nil {
+		sw.flusher.Flush()
+	}
+}
+
+func writeStreamError(w http.ResponseWriter, code int, err error) {
+	w.WriteHeader(code)
+	fmt.Fprintf(w, "{\"error\": {\"message\":%q}}", err.Error())
+}
+
+// ------------------------------ StreamEenpByHeight -----------------------------------
+
+// StreamEenpByHeight streams the elite edge node pool at the given height, e.g. /stream/eenp?height=12345.
+// The result has the same format as GetEenpByHeight.
+func (t *ThetaRPCService) StreamEenpByHeight(w http.ResponseWriter, r *http.Request) {
+	height, err := strconv.ParseUint(r.URL.Query().Get("height"), 10, 64)
+	if err != nil {
+		writeStreamError(w, http.StatusBadRequest, fmt.Errorf("Invalid height: %v", err))
+		return
+	}
+
+	deliveredView, err := t.ledger.GetDeliveredSnapshot()
+	if err != nil {
+		writeStreamError(w, http.StatusInternalServerError, err)
+		return
+	}
+	db := deliveredView.GetDB()
+
+	blocks := t.chain.FindBlocksByHeight(height)
+	views := []*state.StoreView{}
+	for _, b := range blocks {
+		blockStoreView := state.NewStoreView(height, b.StateHash, db)
+		if blockStoreView == nil { // might have been pruned
+			writeStreamError(w, http.StatusNotFound, fmt.Errorf("the EENP for height %v does not exists, it might have been pruned", height))
+			return
+		}
+		views = append(views, blockStoreView)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	sw := newJSONStreamWriter(w)
+	ctx := r.Context()
+
+	sw.writeRaw("{\"BlockHashEenpPairs\":[")
+	for i, b := range blocks {
+		if i > 0 {
+			sw.writeRaw(",")
+		}
+		sw.writeRaw("{\"BlockHash\":")
+		sw.writeItem(true, b.Hash())
+		sw.writeRaw(",\"EENs\":[")
+
+		first := true
+		eenp := state.NewEliteEdgeNodePool(views[i], true)
+		eenp.Iterate(false, func(een *core.EliteEdgeNode) bool {
+			sw.writeItem(first, een)
+			first = false
+			// Stop the traversal if the client has gone away or the connection is broken
+			return sw.err == nil && ctx.Err() == nil
+		})
+		sw.writeRaw("]}")
+	}
+	sw.writeRaw("]}")
+	sw.flush()
+
+	if sw.err == nil {
+		sw.err = ctx.Err()
+	}
+	if sw.err != nil {
+		logger.Warnf("Failed to stream the EENP for height %v: %v", height, sw.err)
+	}
+}
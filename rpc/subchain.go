@@ -0,0 +1,92 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+)
+
+// ------------------------------ GetSubchain -----------------------------------
+
+type GetSubchainArgs struct {
+	SubchainID string `json:"subchain_id"`
+}
+
+type GetSubchainResult struct {
+	BlockHeight common.JSONUint64 `json:"block_height"`
+	Subchain    *core.Subchain    `json:"subchain"`
+}
+
+func (t *ThetaRPCService) GetSubchain(args *GetSubchainArgs, result *GetSubchainResult) (err error) {
+	defer t.guard("GetSubchain", &err)()
+
+	finalizedView, err := t.ledger.GetFinalizedSnapshot()
+	if err != nil {
+		return err
+	}
+
+	subchain := finalizedView.GetSubchain(args.SubchainID)
+	if subchain == nil {
+		return fmt.Errorf("Subchain %v has no anchored checkpoint", args.SubchainID)
+	}
+
+	result.BlockHeight = common.JSONUint64(finalizedView.Height())
+	result.Subchain = subchain
+
+	return nil
+}
+
+// ------------------------------ GetSubchainCheckpoint -----------------------------------
+
+type GetSubchainCheckpointArgs struct {
+	SubchainID string            `json:"subchain_id"`
+	Height     common.JSONUint64 `json:"height"` // height of the subchain block, 0 for the latest anchored one
+}
+
+// GetSubchainCheckpointResult carries a subchain checkpoint with its proof against the state root of a
+// finalized main chain block. A verifier checks the finality of the main chain block with GetFinalityProof,
+// and then the proof with state.VerifySubchainCheckpointProof.
+type GetSubchainCheckpointResult struct {
+	Checkpoint *core.SubchainCheckpoint `json:"checkpoint"`
+
+	BlockHeight common.JSONUint64 `json:"block_height"` // the finalized main chain block the proof is against
+	BlockHash   common.Hash       `json:"block_hash"`
+	StateHash   common.Hash       `json:"state_hash"`
+	Proof       []common.Bytes    `json:"proof"` // trie nodes from the state root to the checkpoint
+}
+
+func (t *ThetaRPCService) GetSubchainCheckpoint(args *GetSubchainCheckpointArgs, result *GetSubchainCheckpointResult) (err error) {
+	defer t.guard("GetSubchainCheckpoint", &err)()
+
+	finalizedView, block, err := t.getFinalizedView(0)
+	if err != nil {
+		return err
+	}
+
+	height := uint64(args.Height)
+	if height == 0 {
+		subchain := finalizedView.GetSubchain(args.SubchainID)
+		if subchain == nil {
+			return fmt.Errorf("Subchain %v has no anchored checkpoint", args.SubchainID)
+		}
+		height = subchain.LatestHeight
+	}
+
+	checkpoint := finalizedView.GetSubchainCheckpoint(args.SubchainID, height)
+	if checkpoint == nil {
+		return fmt.Errorf("Subchain %v has no anchored checkpoint at height %v", args.SubchainID, height)
+	}
+	proof, err := finalizedView.ProveSubchainCheckpoint(args.SubchainID, height)
+	if err != nil {
+		return err
+	}
+
+	result.Checkpoint = checkpoint
+	result.BlockHeight = common.JSONUint64(block.Height)
+	result.BlockHash = block.Hash()
+	result.StateHash = block.StateHash
+	result.Proof = proof
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package rpc
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	syncProgressSampleInterval = 10 * time.Second
+	syncProgressMaxSamples     = 30 // the sync rate is averaged over the last 5 minutes
+)
+
+type syncProgressSample struct {
+	time   time.Time
+	height uint64
+}
+
+// syncProgress samples the last finalized height periodically to estimate the sync rate
+type syncProgress struct {
+	mu      *sync.Mutex
+	samples []syncProgressSample
+}
+
+func newSyncProgress() *syncProgress {
+	return &syncProgress{
+		mu:      &sync.Mutex{},
+		samples: []syncProgressSample{},
+	}
+}
+
+func (sp *syncProgress) addSample(now time.Time, height uint64) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	sp.samples = append(sp.samples, syncProgressSample{time: now, height: height})
+	if len(sp.samples) > syncProgressMaxSamples {
+		sp.samples = sp.samples[len(sp.samples)-syncProgressMaxSamples:]
+	}
+}
+
+// rate returns the number of blocks finalized per second over the sampled window
+func (sp *syncProgress) rate() float64 {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	if len(sp.samples) < 2 {
+		return 0
+	}
+	first, last := sp.samples[0], sp.samples[len(sp.samples)-1]
+	elapsed := last.time.Sub(first.time).Seconds()
+	if elapsed <= 0 || last.height < first.height {
+		return 0
+	}
+	return float64(last.height-first.height) / elapsed
+}
+
+func (t *ThetaRPCServer) syncProgressLoop() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(syncProgressSampleInterval)
+	defer ticker.Stop()
+	for {
+		t.syncProgress.addSample(time.Now(), t.consensus.GetLastFinalizedBlock().Height)
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
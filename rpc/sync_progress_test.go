@@ -0,0 +1,29 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncProgressRate(t *testing.T) {
+	assert := assert.New(t)
+
+	sp := newSyncProgress()
+	assert.Equal(0.0, sp.rate())
+
+	start := time.Now()
+	sp.addSample(start, 100)
+	assert.Equal(0.0, sp.rate())
+
+	sp.addSample(start.Add(10*time.Second), 150)
+	assert.Equal(5.0, sp.rate())
+
+	// Only the last samples are kept
+	for i := 2; i <= syncProgressMaxSamples+10; i++ {
+		sp.addSample(start.Add(time.Duration(i)*10*time.Second), uint64(100+20*i))
+	}
+	assert.Equal(syncProgressMaxSamples, len(sp.samples))
+	assert.Equal(2.0, sp.rate())
+}
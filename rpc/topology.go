@@ -0,0 +1,190 @@
+package rpc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thetatoken/theta/common"
+	p2ptypes "github.com/thetatoken/theta/p2p/types"
+	"github.com/thetatoken/theta/version"
+)
+
+const (
+	defaultTopologyProbeTimeoutSecs = 5
+	maxTopologyProbeTimeoutSecs     = 30
+
+	TopologyFormatJSON = "json"
+	TopologyFormatDOT  = "dot"
+)
+
+// ------------------------------ ProbeNetworkTopology -----------------------------------
+
+type ProbeNetworkTopologyArgs struct {
+	TimeoutSecs common.JSONUint64 `json:"timeout_secs"` // how long to wait for the replies of the peers, 0 for the default
+	Format      string            `json:"format"`       // json (default) or dot
+}
+
+type TopologyNode struct {
+	ID      string `json:"id"`
+	Role    string `json:"role"`
+	Version string `json:"version"` // empty if not advertised
+	Local   bool   `json:"local"`
+	Probed  bool   `json:"probed"` // true if the node reported its neighbors
+}
+
+type TopologyEdge struct {
+	From string `json:"from"` // the node that dialed the connection
+	To   string `json:"to"`
+}
+
+type ProbeNetworkTopologyResult struct {
+	Nodes        []*TopologyNode `json:"nodes"`
+	Edges        []*TopologyEdge `json:"edges"`
+	Unresponsive []string        `json:"unresponsive"` // peers that did not report their neighbors
+	DOT          string          `json:"dot,omitempty"`
+}
+
+// ProbeNetworkTopology asks the peers for their neighbors and assembles the topology graph of the neighborhood
+// of the node. Both the node and its peers need to enable the topology probe in their config.
+func (t *ThetaRPCService) ProbeNetworkTopology(args *ProbeNetworkTopologyArgs, result *ProbeNetworkTopologyResult) (err error) {
+	defer t.guard("ProbeNetworkTopology", &err)()
+
+	format := args.Format
+	if format == "" {
+		format = TopologyFormatJSON
+	}
+	if format != TopologyFormatJSON && format != TopologyFormatDOT {
+		return fmt.Errorf("unknown format %v, expected %v or %v", format, TopologyFormatJSON, TopologyFormatDOT)
+	}
+	timeoutSecs := uint64(args.TimeoutSecs)
+	if timeoutSecs == 0 {
+		timeoutSecs = defaultTopologyProbeTimeoutSecs
+	}
+	if timeoutSecs > maxTopologyProbeTimeoutSecs {
+		timeoutSecs = maxTopologyProbeTimeoutSecs
+	}
+
+	reports, err := t.dispatcher.ProbeTopology(time.Duration(timeoutSecs) * time.Second)
+	if err != nil {
+		return err
+	}
+	buildTopology(t.consensus.ID(), reports, result)
+	if format == TopologyFormatDOT {
+		result.DOT = topologyToDOT(result)
+	}
+	return nil
+}
+
+// buildTopology assembles the graph from the neighbors reported by the nodes, keyed by the node IDs
+func buildTopology(localID string, reports map[string][]p2ptypes.TopologyNeighbor, result *ProbeNetworkTopologyResult) {
+	nodes := make(map[string]*TopologyNode)
+	getNode := func(id string) *TopologyNode {
+		node, ok := nodes[id]
+		if !ok {
+			node = &TopologyNode{ID: id, Role: "unknown"}
+			nodes[id] = node
+		}
+		return node
+	}
+	edges := make(map[TopologyEdge]bool)
+
+	local := getNode(localID)
+	local.Local = true
+	local.Version = version.Version
+
+	// Process the reports in order, so that the roles resolved by the local node take precedence
+	reporters := make([]string, 0, len(reports))
+	for id := range reports {
+		reporters = append(reporters, id)
+	}
+	sort.Slice(reporters, func(i, j int) bool {
+		if (reporters[i] == localID) != (reporters[j] == localID) {
+			return reporters[i] == localID
+		}
+		return reporters[i] < reporters[j]
+	})
+
+	for _, reporter := range reporters {
+		getNode(reporter).Probed = true
+		for _, neighbor := range reports[reporter] {
+			node := getNode(neighbor.ID)
+			capabilities := neighbor.NodeCapabilities()
+			if node.Role == "unknown" {
+				if neighbor.Role != "" && neighbor.Role != "unknown" {
+					node.Role = neighbor.Role
+				} else if capabilities != nil && capabilities.Role != "" {
+					node.Role = capabilities.Role
+				}
+			}
+			if node.Version == "" && capabilities != nil {
+				node.Version = capabilities.Version
+			}
+			if neighbor.Outbound {
+				edges[TopologyEdge{From: reporter, To: neighbor.ID}] = true
+			} else {
+				edges[TopologyEdge{From: neighbor.ID, To: reporter}] = true
+			}
+		}
+	}
+
+	result.Nodes = make([]*TopologyNode, 0, len(nodes))
+	for _, node := range nodes {
+		result.Nodes = append(result.Nodes, node)
+	}
+	sort.Slice(result.Nodes, func(i, j int) bool {
+		return result.Nodes[i].ID < result.Nodes[j].ID
+	})
+
+	result.Edges = make([]*TopologyEdge, 0, len(edges))
+	for edge := range edges {
+		e := edge
+		result.Edges = append(result.Edges, &e)
+	}
+	sort.Slice(result.Edges, func(i, j int) bool {
+		if result.Edges[i].From != result.Edges[j].From {
+			return result.Edges[i].From < result.Edges[j].From
+		}
+		return result.Edges[i].To < result.Edges[j].To
+	})
+
+	result.Unresponsive = []string{}
+	for _, neighbor := range reports[localID] {
+		if _, ok := reports[neighbor.ID]; !ok {
+			result.Unresponsive = append(result.Unresponsive, neighbor.ID)
+		}
+	}
+	sort.Strings(result.Unresponsive)
+}
+
+// topologyToDOT renders the topology in the Graphviz DOT language. The nodes that did not report their
+// neighbors are dashed, since only part of their connections are known.
+func topologyToDOT(topology *ProbeNetworkTopologyResult) string {
+	var sb strings.Builder
+	sb.WriteString("digraph theta {\n")
+	for _, node := range topology.Nodes {
+		label := node.ID + "\\n" + node.Role
+		if node.Version != "" {
+			label += " " + node.Version
+		}
+		attrs := []string{"label=" + dotQuote(label)}
+		if node.Local {
+			attrs = append(attrs, "penwidth=2")
+		}
+		if !node.Probed {
+			attrs = append(attrs, "style=dashed")
+		}
+		sb.WriteString(fmt.Sprintf("  %v [%v];\n", dotQuote(node.ID), strings.Join(attrs, ", ")))
+	}
+	for _, edge := range topology.Edges {
+		sb.WriteString(fmt.Sprintf("  %v -> %v;\n", dotQuote(edge.From), dotQuote(edge.To)))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// dotQuote quotes a DOT identifier, the escape sequences like \n are kept for the labels
+func dotQuote(s string) string {
+	return "\"" + strings.Replace(s, "\"", "\\\"", -1) + "\""
+}
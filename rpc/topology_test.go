@@ -0,0 +1,65 @@
+package rpc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p2ptypes "github.com/thetatoken/theta/p2p/types"
+)
+
+func TestBuildTopology(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	validator := &p2ptypes.NodeCapabilities{Role: "validator", Version: "3.2.0"}
+	guardian := &p2ptypes.NodeCapabilities{Role: "guardian", Version: "3.1.0"}
+
+	// The local node A dialed the validator B, the guardian C dialed A, B dialed C, and
+	// the edge node D connected to C did not reply to the probe
+	reports := map[string][]p2ptypes.TopologyNeighbor{
+		"A": {
+			p2ptypes.NewTopologyNeighbor("B", "validator", true, validator),
+			p2ptypes.NewTopologyNeighbor("C", "guardian", false, guardian),
+		},
+		"B": {
+			p2ptypes.NewTopologyNeighbor("A", "unknown", false, nil),
+			p2ptypes.NewTopologyNeighbor("C", "unknown", true, guardian),
+		},
+		"C": {
+			p2ptypes.NewTopologyNeighbor("A", "unknown", true, nil),
+			p2ptypes.NewTopologyNeighbor("B", "validator", false, validator),
+			p2ptypes.NewTopologyNeighbor("D", "unknown", false, &p2ptypes.NodeCapabilities{Role: "edge node"}),
+		},
+	}
+	result := &ProbeNetworkTopologyResult{}
+	buildTopology("A", reports, result)
+
+	require.Equal(4, len(result.Nodes))
+	assert.Equal(TopologyNode{ID: "A", Role: "unknown", Version: result.Nodes[0].Version, Local: true, Probed: true}, *result.Nodes[0])
+	assert.Equal(TopologyNode{ID: "B", Role: "validator", Version: "3.2.0", Probed: true}, *result.Nodes[1])
+	assert.Equal(TopologyNode{ID: "C", Role: "guardian", Version: "3.1.0", Probed: true}, *result.Nodes[2])
+	assert.Equal(TopologyNode{ID: "D", Role: "edge node"}, *result.Nodes[3])
+
+	// The connections reported by both ends are merged
+	edges := []TopologyEdge{}
+	for _, edge := range result.Edges {
+		edges = append(edges, *edge)
+	}
+	assert.Equal([]TopologyEdge{{"A", "B"}, {"B", "C"}, {"C", "A"}, {"D", "C"}}, edges)
+	assert.Equal([]string{}, result.Unresponsive)
+
+	dot := topologyToDOT(result)
+	assert.True(strings.HasPrefix(dot, "digraph theta {\n"))
+	assert.Contains(dot, `"B" [label="B\nvalidator 3.2.0"];`)
+	assert.Contains(dot, `"D" [label="D\nedge node", style=dashed];`)
+	assert.Contains(dot, `"C" -> "A";`)
+
+	// The peers of the local node that did not reply
+	delete(reports, "C")
+	result = &ProbeNetworkTopologyResult{}
+	buildTopology("A", reports, result)
+	assert.Equal([]string{"C"}, result.Unresponsive)
+}
@@ -3,13 +3,17 @@ package rpc
 import (
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/spf13/viper"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/common/hexutil"
+	"github.com/thetatoken/theta/common/result"
 	"github.com/thetatoken/theta/core"
 	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger/types"
 	"github.com/thetatoken/theta/mempool"
 )
 
@@ -123,6 +127,7 @@ func (t *ThetaRPCService) txCallback() {
 
 type BroadcastRawTransactionArgs struct {
 	TxBytes string `json:"tx_bytes"`
+	ChainID string `json:"chain_id"` // the chain the transaction is intended for, rejected if the node serves another chain
 }
 
 type BroadcastRawTransactionResult struct {
@@ -132,6 +137,11 @@ type BroadcastRawTransactionResult struct {
 
 func (t *ThetaRPCService) BroadcastRawTransaction(
 	args *BroadcastRawTransactionArgs, result *BroadcastRawTransactionResult) (err error) {
+	defer t.guard("BroadcastRawTransaction", &err)()
+
+	if err := t.checkBroadcastChainID(args.ChainID); err != nil {
+		return err
+	}
 	txBytes, err := decodeTxHexBytes(args.TxBytes)
 	if err != nil {
 		return err
@@ -148,7 +158,7 @@ func (t *ThetaRPCService) BroadcastRawTransaction(
 		logger.Infof("Broadcasted raw transaction (sync): %v, hash: %v", hex.EncodeToString(txBytes), hash.Hex())
 	} else {
 		logger.Warnf("Failed to broadcast raw transaction (sync): %v, hash: %v, err: %v", hex.EncodeToString(txBytes), hash.Hex(), err)
-		return err
+		return t.explainBroadcastError(err)
 	}
 
 	finalized := make(chan *core.Block)
@@ -179,6 +189,7 @@ func (t *ThetaRPCService) BroadcastRawTransaction(
 
 type BroadcastRawTransactionAsyncArgs struct {
 	TxBytes string `json:"tx_bytes"`
+	ChainID string `json:"chain_id"` // the chain the transaction is intended for, rejected if the node serves another chain
 }
 
 type BroadcastRawTransactionAsyncResult struct {
@@ -187,6 +198,11 @@ type BroadcastRawTransactionAsyncResult struct {
 
 func (t *ThetaRPCService) BroadcastRawTransactionAsync(
 	args *BroadcastRawTransactionAsyncArgs, result *BroadcastRawTransactionAsyncResult) (err error) {
+	defer t.guard("BroadcastRawTransactionAsync", &err)()
+
+	if err := t.checkBroadcastChainID(args.ChainID); err != nil {
+		return err
+	}
 	txBytes, err := decodeTxHexBytes(args.TxBytes)
 	if err != nil {
 		return err
@@ -206,11 +222,152 @@ func (t *ThetaRPCService) BroadcastRawTransactionAsync(
 
 	logger.Warnf("Failed to broadcast raw transaction (async): %v, hash: %v, err: %v", hex.EncodeToString(txBytes), hash.Hex(), err)
 
-	return err
+	return t.explainBroadcastError(err)
+}
+
+// ------------------------------- PrecheckTransaction -----------------------------------
+
+// The checks of PrecheckTransaction
+const (
+	PrecheckSize      = "size"
+	PrecheckDecode    = "decode"
+	PrecheckChainID   = "chain_id"
+	PrecheckDuplicate = "duplicate"
+	PrecheckSignature = "signature"
+	PrecheckSequence  = "sequence"
+	PrecheckFee       = "fee"
+	PrecheckGasLimit  = "gas_limit"
+	PrecheckBalance   = "balance"
+	PrecheckValidity  = "validity"
+	PrecheckBlocklist = "blocklist"
+)
+
+type PrecheckTransactionArgs struct {
+	TxBytes string `json:"tx_bytes"`
+	ChainID string `json:"chain_id"` // the chain the transaction is intended for
+}
+
+type PrecheckViolation struct {
+	Check      string           `json:"check"`
+	ResultCode result.ErrorCode `json:"result_code"`
+	Message    string           `json:"message"`
+}
+
+type PrecheckTransactionResult struct {
+	TxHash     string               `json:"hash"`
+	Size       int                  `json:"size"`
+	MaxSize    int                  `json:"max_size"`
+	Accepted   bool                 `json:"accepted"` // whether the transaction would pass the screening of the mempool now
+	Violations []*PrecheckViolation `json:"violations"`
+}
+
+// PrecheckTransaction checks whether the mempool would accept the transaction, without submitting it. The
+// checks run against the current state, so the result can change by the time the transaction is broadcast.
+func (t *ThetaRPCService) PrecheckTransaction(
+	args *PrecheckTransactionArgs, result *PrecheckTransactionResult) (err error) {
+	defer t.guard("PrecheckTransaction", &err)()
+
+	txBytes, err := decodeTxHexBytes(args.TxBytes)
+	if err != nil {
+		return err
+	}
+
+	hash := crypto.Keccak256Hash(txBytes)
+	result.TxHash = hash.Hex()
+	result.Size = len(txBytes)
+	result.MaxSize = types.MaxTxSize
+	result.Violations = t.precheckTx(args.ChainID, txBytes)
+	result.Accepted = len(result.Violations) == 0
+
+	return nil
+}
+
+func (t *ThetaRPCService) precheckTx(chainID string, txBytes []byte) []*PrecheckViolation {
+	violations := []*PrecheckViolation{}
+	addViolation := func(check string, code result.ErrorCode, message string) {
+		violations = append(violations, &PrecheckViolation{Check: check, ResultCode: code, Message: message})
+	}
+
+	if err := t.checkBroadcastChainID(chainID); err != nil {
+		addViolation(PrecheckChainID, result.CodeGenericError, err.Error())
+	}
+	if len(txBytes) > types.MaxTxSize {
+		addViolation(PrecheckSize, result.CodeGenericError,
+			fmt.Sprintf("Transaction size %v exceeds the max size %v", len(txBytes), types.MaxTxSize))
+		return violations
+	}
+	tx, err := types.TxFromBytes(txBytes)
+	if err != nil {
+		addViolation(PrecheckDecode, result.CodeGenericError, err.Error())
+		return violations
+	}
+	hash := crypto.Keccak256Hash(txBytes)
+	if status, ok := t.mempool.GetTransactionStatus(hex.EncodeToString(hash[:])); ok && status == mempool.TxStatusPending {
+		addViolation(PrecheckDuplicate, result.CodeGenericError, "Transaction already in the mempool")
+	}
+	if address, blocked := t.mempool.BlockedAddress(tx); blocked {
+		addViolation(PrecheckBlocklist, result.CodeBlockedAddress,
+			fmt.Sprintf("Transaction involves the blocked address %v", address.Hex()))
+	}
+
+	for _, res := range t.ledger.PrecheckTx(tx) {
+		message := res.Message
+		if res.Code == result.CodeInvalidSignature {
+			message = fmt.Sprintf("%v. Please make sure the transaction is signed with the chain ID %v served by this node", message, t.chain.ChainID)
+		}
+		addViolation(precheckOf(res.Code), res.Code, message)
+	}
+	return violations
+}
+
+// precheckOf returns the check a result code of the transaction validation falls under
+func precheckOf(code result.ErrorCode) string {
+	switch code {
+	case result.CodeInvalidSignature, result.CodeEmptyPubKeyWithSequence1:
+		return PrecheckSignature
+	case result.CodeInvalidSequence:
+		return PrecheckSequence
+	case result.CodeInvalidFee, result.CodeInvalidGasPrice:
+		return PrecheckFee
+	case result.CodeInvalidGasLimit, result.CodeFeeLimitTooHigh:
+		return PrecheckGasLimit
+	case result.CodeInsufficientFund, result.CodeNotEnoughBalanceToStake:
+		return PrecheckBalance
+	}
+	return PrecheckValidity
 }
 
 // -------------------------- Utilities -------------------------- //
 
+// checkBroadcastChainID rejects the transactions intended for another chain, e.g. a testnet transaction submitted
+// to a mainnet node by an SDK with the default endpoint
+func (t *ThetaRPCService) checkBroadcastChainID(chainID string) error {
+	if chainID == "" {
+		if viper.GetBool(common.CfgRPCRequireChainID) {
+			return fmt.Errorf("The chain_id parameter is required, this node serves chain %v", t.chain.ChainID)
+		}
+		return nil
+	}
+	if chainID != t.chain.ChainID {
+		return fmt.Errorf("Chain ID mismatch: the transaction is intended for chain %v, but this node serves chain %v", chainID, t.chain.ChainID)
+	}
+	return nil
+}
+
+// explainBroadcastError relays the result code of the screening error, and points out the likely chain ID mismatch
+// when the signature verification fails, since the chain ID is part of the signed bytes
+func (t *ThetaRPCService) explainBroadcastError(err error) error {
+	screeningErr, ok := err.(*mempool.TxScreeningError)
+	if !ok {
+		return err
+	}
+	message := screeningErr.Message
+	if screeningErr.Code == result.CodeInvalidSignature {
+		message = fmt.Sprintf("%v. Please make sure the transaction is signed with the chain ID %v served by this node", message, t.chain.ChainID)
+	}
+	return newResultCodeError(screeningErr.Code, message)
+}
+
 func decodeTxHexBytes(txBytes string) ([]byte, error) {
 	if hexutil.Has0xPrefix(txBytes) {
 		txBytes = txBytes[2:]
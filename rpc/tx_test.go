@@ -1,12 +1,23 @@
 package rpc
 
 import (
+	"encoding/hex"
+	"math/big"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thetatoken/theta/blockchain"
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/mempool"
+	"github.com/thetatoken/theta/store/database/backend"
 )
 
 func TestTxCallbackManager(t *testing.T) {
@@ -55,3 +66,95 @@ func TestTxCallbackManager(t *testing.T) {
 	assert.Equal(1, len(m.txHashToCallback))
 	assert.Equal(1, len(m.callbacks))
 }
+
+func TestBroadcastChainIDMismatch(t *testing.T) {
+	assert := assert.New(t)
+
+	defer viper.Set(common.CfgRPCRequireChainID, viper.GetBool(common.CfgRPCRequireChainID))
+	viper.Set(common.CfgRPCRequireChainID, false)
+
+	service := &ThetaRPCService{
+		chain:    &blockchain.Chain{ChainID: core.MainnetChainID},
+		breakers: NewCircuitBreakers(0.5, 1, time.Minute, time.Minute, 0, ""),
+	}
+
+	// The transactions for another chain are rejected before reaching the mempool
+	err := service.BroadcastRawTransactionAsync(&BroadcastRawTransactionAsyncArgs{TxBytes: "0x01", ChainID: "testnet"},
+		&BroadcastRawTransactionAsyncResult{})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "Chain ID mismatch")
+	err = service.BroadcastRawTransaction(&BroadcastRawTransactionArgs{TxBytes: "0x01", ChainID: "testnet"},
+		&BroadcastRawTransactionResult{})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "Chain ID mismatch")
+
+	assert.Nil(service.checkBroadcastChainID(core.MainnetChainID))
+	assert.Nil(service.checkBroadcastChainID(""))
+	viper.Set(common.CfgRPCRequireChainID, true)
+	assert.NotNil(service.checkBroadcastChainID(""))
+	assert.Nil(service.checkBroadcastChainID(core.MainnetChainID))
+}
+
+func TestPrecheckTransaction(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	core.ResetTestBlocks()
+	chain := blockchain.CreateTestChain()
+	db := backend.NewMemDatabase()
+	sender := types.PrivAccountFromSecret("precheck sender")
+	receiver := types.PrivAccountFromSecret("precheck receiver")
+
+	view := state.NewStoreView(2, common.Hash{}, db)
+	view.SetAccount(sender.Address, &types.Account{Address: sender.Address, Balance: types.NewCoins(0, 1e18)})
+	l := ledger.NewLedger(chain.ChainID, db, chain, nil, nil, mempool.CreateMempool(nil, nil))
+	require.True(l.ResetState(&core.Block{BlockHeader: &core.BlockHeader{ChainID: chain.ChainID, Height: 2, StateHash: view.Save()}}).IsOK())
+
+	service := &ThetaRPCService{
+		ledger:   l,
+		chain:    chain,
+		mempool:  mempool.CreateMempool(nil, nil),
+		breakers: NewCircuitBreakers(0.5, 1, time.Minute, time.Minute, 0, ""),
+	}
+	precheck := func(txBytes []byte, chainID string) *PrecheckTransactionResult {
+		result := &PrecheckTransactionResult{}
+		require.Nil(service.PrecheckTransaction(&PrecheckTransactionArgs{TxBytes: hex.EncodeToString(txBytes), ChainID: chainID}, result))
+		return result
+	}
+	checks := func(result *PrecheckTransactionResult) []string {
+		names := []string{}
+		for _, violation := range result.Violations {
+			names = append(names, violation.Check)
+		}
+		return names
+	}
+	sendTx := func(sequence uint64) []byte {
+		fee := types.GetSendTxMinimumTransactionFeeTFuelWei(2, chain.ChainID, 3)
+		tx := &types.SendTx{
+			Fee: types.Coins{ThetaWei: types.Zero, TFuelWei: fee},
+			Inputs: []types.TxInput{{Address: sender.Address, Sequence: sequence,
+				Coins: types.Coins{ThetaWei: types.Zero, TFuelWei: new(big.Int).Add(fee, big.NewInt(100))}}},
+			Outputs: []types.TxOutput{{Address: receiver.Address, Coins: types.NewCoins(0, 100)}},
+		}
+		tx.SetSignature(sender.Address, sender.Sign(tx.SignBytes(chain.ChainID)))
+		txBytes, err := types.TxToBytes(tx)
+		require.Nil(err)
+		return txBytes
+	}
+
+	result := precheck(sendTx(1), chain.ChainID)
+	assert.True(result.Accepted, result.Violations)
+	assert.Equal(0, len(result.Violations))
+
+	// The problems are reported together, and nothing enters the mempool
+	result = precheck(sendTx(5), "another_chain")
+	assert.False(result.Accepted)
+	assert.Equal([]string{PrecheckChainID, PrecheckSequence}, checks(result))
+	assert.Equal(0, service.mempool.Size())
+
+	result = precheck([]byte("garbage"), "")
+	assert.Equal([]string{PrecheckDecode}, checks(result))
+
+	result = precheck([]byte(strings.Repeat("x", types.MaxTxSize+1)), "")
+	assert.Equal([]string{PrecheckSize}, checks(result))
+}
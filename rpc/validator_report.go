@@ -0,0 +1,225 @@
+package rpc
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/consensus"
+	"github.com/thetatoken/theta/core"
+)
+
+const (
+	defaultValidatorReportWindow = 1000 // blocks
+
+	ValidatorMissProposal = "proposal"
+	ValidatorMissVote     = "vote"
+
+	// Reasons for which the local validator missed a proposal or a vote
+	ValidatorMissLate     = "late"      // the node acted after the other validators had moved on, or was behind the chain
+	ValidatorMissNoQuorum = "no quorum" // the node proposed, but the epoch timed out without a majority of the votes
+	ValidatorMissOffline  = "offline"   // the node did not act in the epoch
+	ValidatorMissUnknown  = "unknown"   // the epoch is older than the activity recorded since the node started
+)
+
+// ------------------------------ GetSelfValidatorReport -----------------------------------
+
+type GetSelfValidatorReportArgs struct {
+	NumBlocks common.JSONUint64 `json:"num_blocks"` // number of the recent finalized blocks covered, default to 1000
+}
+
+// ValidatorMiss is a proposal or a vote of the local validator which did not make it into the finalized chain
+type ValidatorMiss struct {
+	Type   string            `json:"type"`
+	Epoch  common.JSONUint64 `json:"epoch"`
+	Height common.JSONUint64 `json:"height"` // the height of the block to propose or to vote on
+	Reason string            `json:"reason"`
+}
+
+type GetSelfValidatorReportResult struct {
+	Address            common.Address    `json:"address"`
+	IsValidator        bool              `json:"is_validator"` // whether the node is a validator of the next block
+	StartHeight        common.JSONUint64 `json:"start_height"`
+	EndHeight          common.JSONUint64 `json:"end_height"`
+	RecordedSinceEpoch common.JSONUint64 `json:"recorded_since_epoch"` // the oldest epoch the activity of the node is kept for
+
+	EpochsAsValidator  common.JSONUint64 `json:"epochs_as_validator"` // epochs of the finalized blocks the node was a validator of
+	EpochsParticipated common.JSONUint64 `json:"epochs_participated"` // of which the node voted in
+	ProposalsExpected  common.JSONUint64 `json:"proposals_expected"`
+	ProposalsMade      common.JSONUint64 `json:"proposals_made"` // the proposals finalized
+	VotesExpected      common.JSONUint64 `json:"votes_expected"` // the blocks whose votes are included in a later block
+	VotesCast          common.JSONUint64 `json:"votes_cast"`
+	VotesIncluded      common.JSONUint64 `json:"votes_included"`
+	RewardsEarned      *common.JSONBig   `json:"rewards_earned"` // TFuelWei rewards of the stakes held by the node, before the split
+
+	Misses      []*ValidatorMiss  `json:"misses"`
+	MissReasons map[string]uint64 `json:"miss_reasons"` // reason -> number of the misses
+}
+
+// GetSelfValidatorReport reports the performance of the local node as a validator over the recent finalized
+// blocks. The proposals and the votes are checked against the finalized chain, and the misses are explained
+// with the activity the node has recorded since it started.
+func (t *ThetaRPCService) GetSelfValidatorReport(args *GetSelfValidatorReportArgs, result *GetSelfValidatorReportResult) (err error) {
+	defer t.guard("GetSelfValidatorReport", &err)()
+
+	numBlocks := uint64(args.NumBlocks)
+	if numBlocks == 0 {
+		numBlocks = defaultValidatorReportWindow
+	}
+	if numBlocks > blockchain.MaxChainStatsWindow {
+		return errors.New("height window too large")
+	}
+
+	self := t.consensus.PrivateKey().PublicKey().Address()
+	validatorManager := t.consensus.GetValidatorManager()
+	lfb := t.consensus.GetLastFinalizedBlock()
+	result.Address = self
+	result.IsValidator = isValidatorOf(validatorManager.GetNextValidatorSet(lfb.Hash()), self)
+
+	// The block before the window is needed for the epochs of the first block
+	start := uint64(1)
+	if lfb.Height > numBlocks {
+		start = lfb.Height - numBlocks + 1
+	}
+	blocks := []*core.ExtendedBlock{}
+	for height := start - 1; height <= lfb.Height; height++ {
+		if block := t.chain.FindFinalizedBlockByHeight(height); block != nil {
+			blocks = append(blocks, block)
+		}
+	}
+	result.StartHeight = common.JSONUint64(start)
+	result.EndHeight = common.JSONUint64(lfb.Height)
+	result.analyze(self, blocks, validatorManager, t.consensus.GetValidatorActivity())
+
+	// Collect the rewards from the reward breakdowns of the checkpoints
+	rewards := big.NewInt(0)
+	interval := uint64(common.CheckpointInterval)
+	for checkpoint := (start + interval - 1) / interval * interval; checkpoint <= lfb.Height; checkpoint += interval {
+		distribution := &GetRewardDistributionResult{}
+		if t.GetRewardDistribution(&GetRewardDistributionArgs{Height: common.JSONUint64(checkpoint)}, distribution) != nil {
+			continue // e.g. pruned
+		}
+		for _, share := range distribution.Shares {
+			if share.Holder == self {
+				rewards.Add(rewards, (*big.Int)(share.Reward))
+			}
+		}
+	}
+	result.RewardsEarned = (*common.JSONBig)(rewards)
+
+	return nil
+}
+
+// analyze checks the proposals and the votes of the validator against the given finalized blocks, in the
+// increasing order of the heights. The first block only provides the epoch the next one starts from.
+func (result *GetSelfValidatorReportResult) analyze(self common.Address, blocks []*core.ExtendedBlock,
+	validatorManager core.ValidatorManager, activity *consensus.ValidatorActivity) {
+	result.Misses = []*ValidatorMiss{}
+	result.MissReasons = make(map[string]uint64)
+	oldestEpoch, recorded := activity.OldestEpoch()
+	result.RecordedSinceEpoch = common.JSONUint64(oldestEpoch)
+
+	addMiss := func(typ string, epoch uint64, height uint64, reason string) {
+		result.Misses = append(result.Misses, &ValidatorMiss{
+			Type:   typ,
+			Epoch:  common.JSONUint64(epoch),
+			Height: common.JSONUint64(height),
+			Reason: reason,
+		})
+		result.MissReasons[reason]++
+	}
+	missReason := func(epoch uint64, act *consensus.ValidatorEpochActivity) string {
+		if !recorded || epoch < oldestEpoch {
+			return ValidatorMissUnknown
+		}
+		if act == nil {
+			return ValidatorMissOffline
+		}
+		if act.TimedOut && !act.Proposal.IsEmpty() {
+			return ValidatorMissNoQuorum
+		}
+		return ValidatorMissLate
+	}
+
+	// The votes for a block are included in the later block which has it as the highest committed block
+	includedVotes := make(map[common.Hash]*core.VoteSet)
+	for _, block := range blocks {
+		if block.HCC.Votes != nil && !block.HCC.Votes.IsEmpty() {
+			if _, ok := includedVotes[block.HCC.BlockHash]; !ok {
+				includedVotes[block.HCC.BlockHash] = block.HCC.Votes
+			}
+		}
+	}
+
+	for i := 1; i < len(blocks); i++ {
+		parent, block := blocks[i-1], blocks[i]
+		if block.Parent != parent.Hash() {
+			continue
+		}
+
+		// Proposals, over the epochs from the parent to the block
+		if isValidatorOf(validatorManager.GetNextValidatorSet(parent.Hash()), self) {
+			for epoch := parent.Epoch + 1; epoch <= block.Epoch; epoch++ {
+				act := activity.Get(epoch)
+				result.EpochsAsValidator++
+				if act != nil && len(act.Votes) > 0 {
+					result.EpochsParticipated++
+					result.VotesCast += common.JSONUint64(len(act.Votes))
+				}
+				if validatorManager.GetNextProposer(parent.Hash(), epoch).Address != self {
+					continue
+				}
+				result.ProposalsExpected++
+				if epoch == block.Epoch && block.Proposer == self {
+					result.ProposalsMade++
+				} else {
+					addMiss(ValidatorMissProposal, epoch, block.Height, missReason(epoch, act))
+				}
+			}
+		}
+
+		// Votes, for the blocks whose votes are included
+		votes, ok := includedVotes[block.Hash()]
+		if !ok {
+			continue
+		}
+		if !isValidatorOf(validatorManager.GetValidatorSet(block.Hash()), self) {
+			continue
+		}
+		result.VotesExpected++
+		included := false
+		for _, vote := range votes.Votes() {
+			if vote.ID == self {
+				included = true
+				break
+			}
+		}
+		if included {
+			result.VotesIncluded++
+			continue
+		}
+		// The node votes on a block in the epoch of the block, or the later ones before the next block
+		lastEpoch := block.Epoch
+		if i+1 < len(blocks) {
+			lastEpoch = blocks[i+1].Epoch
+		}
+		var act *consensus.ValidatorEpochActivity
+		for epoch := block.Epoch; epoch <= lastEpoch && act == nil; epoch++ {
+			act = activity.Get(epoch)
+		}
+		reason := missReason(block.Epoch, act)
+		if reason == ValidatorMissNoQuorum {
+			reason = ValidatorMissLate // the votes of the block reached a majority
+		}
+		addMiss(ValidatorMissVote, block.Epoch, block.Height, reason)
+	}
+}
+
+func isValidatorOf(validatorSet *core.ValidatorSet, address common.Address) bool {
+	if validatorSet == nil {
+		return false
+	}
+	_, err := validatorSet.GetValidator(address)
+	return err == nil
+}
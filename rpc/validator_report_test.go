@@ -0,0 +1,114 @@
+package rpc
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/consensus"
+	"github.com/thetatoken/theta/core"
+)
+
+// reportValidatorManager has a fixed validator set, and the proposers given by epoch
+type reportValidatorManager struct {
+	validators *core.ValidatorSet
+	proposers  map[uint64]common.Address
+}
+
+func (m *reportValidatorManager) SetConsensusEngine(consensus core.ConsensusEngine) {}
+
+func (m *reportValidatorManager) GetProposer(blockHash common.Hash, epoch uint64) core.Validator {
+	return m.GetNextProposer(blockHash, epoch)
+}
+
+func (m *reportValidatorManager) GetNextProposer(blockHash common.Hash, epoch uint64) core.Validator {
+	return core.Validator{Address: m.proposers[epoch], Stake: big.NewInt(1)}
+}
+
+func (m *reportValidatorManager) GetValidatorSet(blockHash common.Hash) *core.ValidatorSet {
+	return m.validators
+}
+
+func (m *reportValidatorManager) GetNextValidatorSet(blockHash common.Hash) *core.ValidatorSet {
+	return m.validators
+}
+
+func TestValidatorReport(t *testing.T) {
+	assert := assert.New(t)
+
+	self := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	validators := core.NewValidatorSet()
+	validators.SetValidators([]core.Validator{
+		{Address: self, Stake: big.NewInt(1)},
+		{Address: other, Stake: big.NewInt(1)},
+	})
+
+	// Blocks at the epochs 1, 2, 3, 6, 7 and 8, each carrying the votes for its parent
+	epochs := []uint64{1, 2, 3, 6, 7, 8}
+	blocks := []*core.ExtendedBlock{}
+	for i, epoch := range epochs {
+		block := core.NewBlock()
+		block.Height = uint64(i + 1)
+		block.Epoch = epoch
+		block.Proposer = other
+		if i > 0 {
+			parent := blocks[i-1]
+			block.Parent = parent.Hash()
+			block.HCC.BlockHash = parent.Hash()
+			votes := core.NewVoteSet()
+			votes.AddVote(core.Vote{Block: parent.Hash(), ID: other})
+			if i != 3 && i != 5 {
+				votes.AddVote(core.Vote{Block: parent.Hash(), ID: self})
+			}
+			block.HCC.Votes = votes
+		}
+		if epoch == 2 {
+			block.Proposer = self
+		}
+		blocks = append(blocks, &core.ExtendedBlock{Block: block})
+	}
+	proposers := map[uint64]common.Address{2: self, 4: self, 5: self, 7: self}
+	validatorManager := &reportValidatorManager{validators: validators, proposers: proposers}
+
+	// The node started at the epoch 4, proposed in the epochs 4 and 7, the epoch 4 timed out, and the node
+	// was down in the epoch 5
+	now := time.Now()
+	activity := consensus.NewValidatorActivity()
+	activity.RecordProposal(4, common.BytesToHash([]byte{4}), now)
+	activity.RecordTimeout(4, now)
+	activity.RecordVote(4, blocks[2].Hash(), now)
+	activity.RecordVote(6, blocks[3].Hash(), now)
+	activity.RecordProposal(7, common.BytesToHash([]byte{7}), now)
+	activity.RecordVote(7, blocks[4].Hash(), now)
+
+	result := &GetSelfValidatorReportResult{}
+	result.analyze(self, blocks, validatorManager, activity)
+
+	assert.Equal(common.JSONUint64(4), result.RecordedSinceEpoch)
+	assert.Equal(common.JSONUint64(7), result.EpochsAsValidator)
+	assert.Equal(common.JSONUint64(3), result.EpochsParticipated)
+	assert.Equal(common.JSONUint64(3), result.VotesCast)
+	assert.Equal(common.JSONUint64(4), result.ProposalsExpected)
+	assert.Equal(common.JSONUint64(1), result.ProposalsMade)
+	assert.Equal(common.JSONUint64(4), result.VotesExpected) // the votes for the last block are not included yet
+	assert.Equal(common.JSONUint64(2), result.VotesIncluded)
+
+	reasons := map[common.JSONUint64]string{}
+	for _, miss := range result.Misses {
+		if miss.Type == ValidatorMissProposal {
+			reasons[miss.Epoch] = miss.Reason
+		}
+	}
+	assert.Equal(map[common.JSONUint64]string{
+		4: ValidatorMissNoQuorum,
+		5: ValidatorMissOffline,
+		7: ValidatorMissLate,
+	}, reasons)
+	assert.Equal(uint64(1), result.MissReasons[ValidatorMissOffline])
+	assert.Equal(uint64(1), result.MissReasons[ValidatorMissNoQuorum])
+	assert.Equal(uint64(1), result.MissReasons[ValidatorMissUnknown]) // the vote for the block at the epoch 3
+	assert.Equal(uint64(2), result.MissReasons[ValidatorMissLate])
+}
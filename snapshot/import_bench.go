@@ -0,0 +1,223 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"time"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/consensus"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/ledger"
+	"github.com/thetatoken/theta/ledger/state"
+	mp "github.com/thetatoken/theta/mempool"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+// ImportBenchmarkConfig describes the captured block range to replay. The range is captured as the snapshot
+// of the state at its start and the chain backup of the following blocks.
+type ImportBenchmarkConfig struct {
+	SnapshotPath string
+	ChainPath    string
+	Iterations   int
+	CPUProfile   io.Writer // if set, receives the CPU profile of the last replay
+}
+
+// ImportBenchmarkRun is the measurement of one replay of the block range
+type ImportBenchmarkRun struct {
+	Duration       time.Duration `json:"duration"`
+	TxsPerSecond   float64       `json:"txs_per_second"`
+	CommitTime     time.Duration `json:"commit_time"`     // spent committing the state to the database
+	SigVerifyTime  time.Duration `json:"sig_verify_time"` // spent verifying the tx signatures
+	NumSigVerifies uint64        `json:"num_sig_verifies"`
+	NumAllocs      uint64        `json:"num_allocs"`
+	AllocBytes     uint64        `json:"alloc_bytes"`
+}
+
+// ImportBenchmarkResult summarizes the replays of the block range
+type ImportBenchmarkResult struct {
+	ChainID     string               `json:"chain_id"`
+	StartHeight uint64               `json:"start_height"`
+	EndHeight   uint64               `json:"end_height"`
+	NumBlocks   uint64               `json:"num_blocks"`
+	NumTxs      uint64               `json:"num_txs"`
+	Runs        []ImportBenchmarkRun `json:"runs"`
+	Median      ImportBenchmarkRun   `json:"median"` // the run of the median duration, the figure to compare between builds
+}
+
+// BenchmarkImport replays the captured block range against a fresh state the given number of times, and
+// measures the execution of the blocks by the ledger. Each replay loads the snapshot into a new temporary
+// database first, which is not part of the measurement.
+func BenchmarkImport(config ImportBenchmarkConfig) (*ImportBenchmarkResult, error) {
+	if config.Iterations <= 0 {
+		return nil, fmt.Errorf("The number of iterations must be positive")
+	}
+	blocks, err := readChainBackup(config.ChainPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportBenchmarkResult{}
+	for i := 0; i < config.Iterations; i++ {
+		var cpuProfile io.Writer
+		if i == config.Iterations-1 {
+			cpuProfile = config.CPUProfile
+		}
+		run, err := benchmarkImportOnce(config.SnapshotPath, blocks, result, cpuProfile)
+		if err != nil {
+			return nil, err
+		}
+		logger.Infof("Import benchmark run %v/%v: %v blocks, %v txs in %v", i+1, config.Iterations, result.NumBlocks, result.NumTxs, run.Duration)
+		result.Runs = append(result.Runs, *run)
+	}
+
+	runs := make([]ImportBenchmarkRun, len(result.Runs))
+	copy(runs, result.Runs)
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Duration < runs[j].Duration })
+	result.Median = runs[len(runs)/2]
+
+	return result, nil
+}
+
+func benchmarkImportOnce(snapshotPath string, blocks []*core.ExtendedBlock, result *ImportBenchmarkResult, cpuProfile io.Writer) (*ImportBenchmarkRun, error) {
+	db, cleanup, err := newTmpDB()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	snapshotBlockHeader, _, err := loadSnapshot(snapshotPath, db, "Loading Snapshot")
+	if err != nil {
+		return nil, err
+	}
+	chainParams := state.NewStoreView(snapshotBlockHeader.Height, snapshotBlockHeader.StateHash, db).GetChainParams()
+	if err := core.LoadChainParams(snapshotBlockHeader.ChainID, chainParams); err != nil {
+		return nil, err
+	}
+
+	// Only replay the blocks following the snapshot
+	for len(blocks) > 0 && blocks[0].Height <= snapshotBlockHeader.Height {
+		blocks = blocks[1:]
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("The chain backup has no block after the snapshot height %v", snapshotBlockHeader.Height)
+	}
+	if blocks[0].Parent != snapshotBlockHeader.Hash() {
+		return nil, fmt.Errorf("Block %v at height %v does not follow the snapshot block %v",
+			blocks[0].Hash().Hex(), blocks[0].Height, snapshotBlockHeader.Hash().Hex())
+	}
+
+	store := kvstore.NewKVStore(db)
+	chain := blockchain.NewChain(snapshotBlockHeader.ChainID, store, &core.Block{BlockHeader: snapshotBlockHeader})
+	numTxs := uint64(0)
+	for _, block := range blocks {
+		blockHash := block.Hash()
+		if err := store.Put(blockHash[:], block); err != nil {
+			return nil, err
+		}
+		chain.AddBlockByHeightIndex(block.Height, blockHash)
+		numTxs += uint64(len(block.Txs))
+	}
+	result.ChainID = snapshotBlockHeader.ChainID
+	result.StartHeight = blocks[0].Height
+	result.EndHeight = blocks[len(blocks)-1].Height
+	result.NumBlocks = uint64(len(blocks))
+	result.NumTxs = numTxs
+
+	// The node components the ledger depends on, not started
+	privateKey, _, err := crypto.GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	validatorManager := consensus.NewRotatingValidatorManager()
+	engine := consensus.NewConsensusEngine(privateKey, store, chain, nil, validatorManager)
+	mempool := mp.CreateMempool(nil, engine)
+	ld := ledger.NewLedger(snapshotBlockHeader.ChainID, db, chain, engine, validatorManager, mempool)
+	validatorManager.SetConsensusEngine(engine)
+	engine.SetLedger(ld)
+	mempool.SetLedger(ld)
+
+	if cpuProfile != nil {
+		if err := pprof.StartCPUProfile(cpuProfile); err != nil {
+			return nil, err
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	crypto.ResetVerificationStats()
+	crypto.EnableVerificationStats(true)
+	defer crypto.EnableVerificationStats(false)
+
+	runtime.GC()
+	var memStatsBefore, memStatsAfter runtime.MemStats
+	runtime.ReadMemStats(&memStatsBefore)
+
+	run := &ImportBenchmarkRun{}
+	parent := &core.Block{BlockHeader: snapshotBlockHeader}
+	start := time.Now()
+	for _, block := range blocks {
+		if res := ld.ResetState(parent); res.IsError() {
+			return nil, fmt.Errorf("Failed to reset the state to height %v: %v", parent.Height, res.String())
+		}
+		res := ld.ApplyBlockTxs(block.Block)
+		if res.IsError() {
+			return nil, fmt.Errorf("Failed to apply block %v at height %v: %v", block.Hash().Hex(), block.Height, res.String())
+		}
+		if commitTime, ok := res.Info["commitTime"].(time.Duration); ok {
+			run.CommitTime += commitTime
+		}
+		parent = block.Block
+	}
+	run.Duration = time.Since(start)
+
+	runtime.ReadMemStats(&memStatsAfter)
+	run.NumSigVerifies, run.SigVerifyTime = crypto.GetVerificationStats()
+	run.NumAllocs = memStatsAfter.Mallocs - memStatsBefore.Mallocs
+	run.AllocBytes = memStatsAfter.TotalAlloc - memStatsBefore.TotalAlloc
+	if run.Duration > 0 {
+		run.TxsPerSecond = float64(numTxs) / run.Duration.Seconds()
+	}
+
+	return run, nil
+}
+
+// readChainBackup reads the blocks of a chain backup, which are written from the highest down, and returns
+// them in the order of their heights
+func readChainBackup(chainPath string) ([]*core.ExtendedBlock, error) {
+	file, err := os.Open(chainPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	blocks := []*core.ExtendedBlock{}
+	for {
+		backupBlock := &core.BackupBlock{}
+		_, err := core.ReadRecord(file, backupBlock)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read backup record, %v", err)
+		}
+		blocks = append(blocks, backupBlock.Block)
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("The chain backup %v has no block", chainPath)
+	}
+
+	for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+		blocks[i], blocks[j] = blocks[j], blocks[i]
+	}
+	for i := 1; i < len(blocks); i++ {
+		if blocks[i].Height != blocks[i-1].Height+1 || blocks[i].Parent != blocks[i-1].Hash() {
+			return nil, fmt.Errorf("Block at height %v does not extend the block at height %v", blocks[i].Height, blocks[i-1].Height)
+		}
+	}
+	return blocks, nil
+}
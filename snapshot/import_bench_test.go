@@ -0,0 +1,108 @@
+package snapshot
+
+import (
+	"bufio"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/testkit"
+)
+
+func writeTestChainBackup(t *testing.T, filePath string, blocks []*core.ExtendedBlock) {
+	file, err := os.Create(filePath)
+	require.Nil(t, err)
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for i := len(blocks) - 1; i >= 0; i-- {
+		require.Nil(t, writeBlock(writer, &core.BackupBlock{Block: blocks[i], Votes: core.NewVoteSet()}))
+	}
+}
+
+func TestReadChainBackup(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "chain_backup")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	blocks := []*core.ExtendedBlock{}
+	parent := common.Hash{}
+	for height := uint64(10); height < 15; height++ {
+		block := core.NewBlock()
+		block.ChainID = "test_chain"
+		block.Height = height
+		block.Parent = parent
+		block.Timestamp = big.NewInt(int64(height))
+		blocks = append(blocks, &core.ExtendedBlock{Block: block, Status: core.BlockStatusDirectlyFinalized})
+		parent = block.Hash()
+	}
+
+	// The blocks are written from the highest down, and read back in the order of their heights
+	filePath := path.Join(dir, "theta_chain-10-14")
+	writeTestChainBackup(t, filePath, blocks)
+	read, err := readChainBackup(filePath)
+	require.Nil(err)
+	require.Equal(len(blocks), len(read))
+	for i, block := range read {
+		assert.Equal(blocks[i].Hash(), block.Hash())
+	}
+
+	// A gap in the range is rejected
+	gapped := append([]*core.ExtendedBlock{}, blocks[:2]...)
+	gapped = append(gapped, blocks[3:]...)
+	writeTestChainBackup(t, filePath, gapped)
+	_, err = readChainBackup(filePath)
+	assert.NotNil(err)
+}
+
+func TestBenchmarkImport(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cluster := testkit.NewCluster(testkit.Config{NumValidators: 3})
+	cluster.Start()
+	defer cluster.Stop()
+	require.True(cluster.RunUntil(func() bool { return cluster.MinFinalizedHeight() >= 25 }, 10*time.Minute))
+
+	dir, err := ioutil.TempDir("", "import_bench")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	// Capture the blocks 11 to 20 on top of the state at height 10
+	node := cluster.Nodes()[0]
+	viper.Set(common.CfgGenesisHash, node.Chain.Root().Hash().Hex())
+	snapshotFile, err := ExportSnapshotV3(node.DB, node.Consensus, node.Chain, dir, 10)
+	require.Nil(err)
+	_, _, chainFile, err := ExportChainBackup(node.Chain, 5, 20, dir)
+	require.Nil(err)
+
+	result, err := BenchmarkImport(ImportBenchmarkConfig{
+		SnapshotPath: path.Join(dir, snapshotFile),
+		ChainPath:    path.Join(dir, chainFile),
+		Iterations:   3,
+	})
+	require.Nil(err)
+	assert.Equal(uint64(11), result.StartHeight)
+	assert.Equal(uint64(20), result.EndHeight)
+	assert.Equal(uint64(10), result.NumTxs) // the coinbase txs
+	assert.Equal(3, len(result.Runs))
+	for _, run := range result.Runs {
+		assert.Equal(uint64(10), run.NumSigVerifies)
+		assert.True(run.TxsPerSecond > 0)
+		assert.True(run.CommitTime > 0)
+		assert.True(run.SigVerifyTime > 0)
+		assert.True(run.NumAllocs > 0)
+	}
+}
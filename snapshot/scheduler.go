@@ -0,0 +1,363 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/consensus"
+	"github.com/thetatoken/theta/ledger"
+	"github.com/thetatoken/theta/store/database"
+)
+
+const (
+	snapshotFilePrefix = "theta_snapshot-"
+
+	// schedulerCheckInterval is the interval at which the scheduler checks the last finalized block
+	schedulerCheckInterval = 10 * time.Second
+)
+
+// SnapshotSchedule defines how the snapshots are generated in the background
+type SnapshotSchedule struct {
+	Enabled           bool              `json:"enabled"`
+	Interval          common.JSONUint64 `json:"interval"`           // number of finalized blocks between the snapshots
+	RetainedSnapshots int               `json:"retained_snapshots"` // number of the latest snapshots to keep, 0 keeps all
+	MaxBytesPerSec    int64             `json:"max_bytes_per_sec"`  // write rate limit, 0 means unlimited
+}
+
+// SnapshotScheduleStatus reports the state of the snapshot scheduler
+type SnapshotScheduleStatus struct {
+	SnapshotSchedule
+	Dir              string            `json:"dir"`
+	Running          bool              `json:"running"`
+	LastHeight       common.JSONUint64 `json:"last_height"`
+	LastFile         string            `json:"last_file"`
+	LastError        string            `json:"last_error"`
+	LastDurationSecs float64           `json:"last_duration_secs"`
+	Snapshots        []string          `json:"snapshots"`
+}
+
+// SnapshotScheduler generates a snapshot in the background every given number of finalized blocks, and
+// removes the older ones beyond the retained number. The state pruning is paused while a snapshot is
+// being exported, and the writes are throttled so that the export does not starve block processing.
+type SnapshotScheduler struct {
+	db        database.Database
+	consensus *consensus.ConsensusEngine
+	chain     *blockchain.Chain
+	ledger    *ledger.Ledger
+	dir       string
+
+	mu       *sync.Mutex // Lock for accessing the schedule and the status
+	schedule SnapshotSchedule
+	status   SnapshotScheduleStatus
+	attempt  uint64 // Height of the last attempted snapshot, a failed snapshot is retried at the next interval
+
+	// Life cycle
+	wg     *sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewSnapshotScheduler creates a new instance of SnapshotScheduler with the schedule from the config. The
+// snapshots are written to dir, or the configured directory if dir is empty.
+func NewSnapshotScheduler(dir string, db database.Database, consensus *consensus.ConsensusEngine, chain *blockchain.Chain, ledger *ledger.Ledger) *SnapshotScheduler {
+	if dir == "" {
+		dir = viper.GetString(common.CfgSnapshotSchedulerDir)
+	}
+	if dir == "" {
+		dir = path.Join(viper.GetString(common.CfgConfigPath), "backup", "scheduled_snapshot")
+	}
+
+	s := &SnapshotScheduler{
+		db:        db,
+		consensus: consensus,
+		chain:     chain,
+		ledger:    ledger,
+		dir:       dir,
+		mu:        &sync.Mutex{},
+		schedule: SnapshotSchedule{
+			Enabled:           viper.GetBool(common.CfgSnapshotSchedulerEnabled),
+			Interval:          common.JSONUint64(viper.GetUint64(common.CfgSnapshotSchedulerInterval)),
+			RetainedSnapshots: viper.GetInt(common.CfgSnapshotSchedulerRetainedSnapshots),
+			MaxBytesPerSec:    viper.GetInt64(common.CfgSnapshotSchedulerMaxBytesPerSec),
+		},
+		wg: &sync.WaitGroup{},
+	}
+
+	// Resume from the latest snapshot generated before the restart
+	if snapshots, err := listSnapshots(dir); err == nil && len(snapshots) > 0 {
+		s.status.LastHeight = common.JSONUint64(snapshots[0].height)
+		s.status.LastFile = snapshots[0].name
+	}
+
+	return s
+}
+
+// Start starts the scheduler
+func (s *SnapshotScheduler) Start(ctx context.Context) {
+	c, cancel := context.WithCancel(ctx)
+	s.ctx = c
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go s.mainLoop()
+}
+
+// Stop notifies the scheduler to stop, which aborts the in-progress export
+func (s *SnapshotScheduler) Stop() {
+	s.cancel()
+}
+
+// Wait blocks until the scheduler stops
+func (s *SnapshotScheduler) Wait() {
+	s.wg.Wait()
+}
+
+// Schedule returns the current schedule
+func (s *SnapshotScheduler) Schedule() SnapshotSchedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.schedule
+}
+
+// SetSchedule updates the schedule. It takes effect from the next snapshot and is not persisted to
+// the config file.
+func (s *SnapshotScheduler) SetSchedule(schedule SnapshotSchedule) error {
+	if schedule.Enabled && schedule.Interval == 0 {
+		return fmt.Errorf("The snapshot interval must be positive")
+	}
+	if schedule.RetainedSnapshots < 0 {
+		return fmt.Errorf("The number of retained snapshots can't be negative")
+	}
+	if schedule.MaxBytesPerSec < 0 {
+		return fmt.Errorf("The max bytes per second can't be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedule = schedule
+	logger.Infof("Snapshot schedule updated: %+v", schedule)
+	return nil
+}
+
+// Status returns the schedule, the outcome of the last snapshot, and the retained snapshots
+func (s *SnapshotScheduler) Status() SnapshotScheduleStatus {
+	s.mu.Lock()
+	status := s.status
+	status.SnapshotSchedule = s.schedule
+	s.mu.Unlock()
+
+	status.Dir = s.dir
+	status.Snapshots = []string{}
+	snapshots, err := listSnapshots(s.dir)
+	if err != nil {
+		return status
+	}
+	for _, snapshot := range snapshots {
+		status.Snapshots = append(status.Snapshots, snapshot.name)
+	}
+	return status
+}
+
+func (s *SnapshotScheduler) mainLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(schedulerCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.maybeGenerateSnapshot()
+		}
+	}
+}
+
+// maybeGenerateSnapshot generates a snapshot of the last finalized block if it has crossed the next
+// multiple of the interval since the last snapshot
+func (s *SnapshotScheduler) maybeGenerateSnapshot() {
+	schedule := s.Schedule()
+	if !schedule.Enabled || schedule.Interval == 0 {
+		return
+	}
+
+	lfb := s.consensus.GetLastFinalizedBlock()
+	interval := uint64(schedule.Interval)
+	s.mu.Lock()
+	lastHeight := uint64(s.status.LastHeight)
+	if s.attempt > lastHeight {
+		lastHeight = s.attempt
+	}
+	s.mu.Unlock()
+	if lfb == nil || lfb.Height/interval <= lastHeight/interval {
+		return
+	}
+
+	s.mu.Lock()
+	s.status.Running = true
+	s.attempt = lfb.Height
+	s.mu.Unlock()
+
+	start := time.Now()
+	filename, err := s.generateSnapshot(lfb.Height, schedule.MaxBytesPerSec)
+	duration := time.Since(start)
+
+	s.mu.Lock()
+	s.status.Running = false
+	s.status.LastDurationSecs = duration.Seconds()
+	if err != nil {
+		s.status.LastError = err.Error()
+	} else {
+		s.status.LastHeight = common.JSONUint64(lfb.Height)
+		s.status.LastFile = filename
+		s.status.LastError = ""
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		logger.Errorf("Failed to generate the scheduled snapshot at height %v: %v", lfb.Height, err)
+		return
+	}
+	logger.Infof("Generated the scheduled snapshot %v in %v", filename, duration)
+
+	if err := pruneSnapshots(s.dir, schedule.RetainedSnapshots); err != nil {
+		logger.Warnf("Failed to remove the old scheduled snapshots: %v", err)
+	}
+}
+
+// generateSnapshot exports the snapshot at the given height with the state pruning paused. The
+// partially written file is removed if the export fails.
+func (s *SnapshotScheduler) generateSnapshot(height uint64, maxBytesPerSec int64) (filename string, err error) {
+	if err := os.MkdirAll(s.dir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	s.ledger.PauseStatePruning()
+	defer s.ledger.ResumeStatePruning()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+		if err != nil {
+			removePartialSnapshot(s.dir, height)
+		}
+	}()
+
+	logger.Infof("Generating the scheduled snapshot at height %v", height)
+	return exportSnapshotV3(s.ctx, s.db, s.consensus, s.chain, s.dir, height, maxBytesPerSec)
+}
+
+type snapshotFile struct {
+	name   string
+	height uint64
+}
+
+// listSnapshots returns the snapshot files in the given directory, the latest first
+func listSnapshots(dir string) ([]snapshotFile, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []snapshotFile{}
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasPrefix(info.Name(), snapshotFilePrefix) {
+			continue
+		}
+		parts := strings.Split(strings.TrimPrefix(info.Name(), snapshotFilePrefix), "-")
+		height, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshotFile{name: info.Name(), height: height})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].height > snapshots[j].height
+	})
+	return snapshots, nil
+}
+
+// pruneSnapshots removes the snapshot files in the given directory except for the latest retained ones
+func pruneSnapshots(dir string, retained int) error {
+	if retained <= 0 {
+		return nil
+	}
+	snapshots, err := listSnapshots(dir)
+	if err != nil {
+		return err
+	}
+	for i := retained; i < len(snapshots); i++ {
+		logger.Infof("Removing the old scheduled snapshot %v", snapshots[i].name)
+		if err := os.Remove(path.Join(dir, snapshots[i].name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removePartialSnapshot(dir string, height uint64) {
+	snapshots, err := listSnapshots(dir)
+	if err != nil {
+		return
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.height == height {
+			os.Remove(path.Join(dir, snapshot.name))
+		}
+	}
+}
+
+// throttledWriter limits the average write rate to the given number of bytes per second, and fails
+// the writes once the context is cancelled
+type throttledWriter struct {
+	ctx         context.Context
+	w           io.Writer
+	bytesPerSec int64
+
+	start   time.Time
+	written int64
+}
+
+func newThrottledWriter(ctx context.Context, w io.Writer, bytesPerSec int64) *throttledWriter {
+	return &throttledWriter{
+		ctx:         ctx,
+		w:           w,
+		bytesPerSec: bytesPerSec,
+		start:       time.Now(),
+	}
+}
+
+func (tw *throttledWriter) Write(p []byte) (int, error) {
+	if err := tw.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := tw.w.Write(p)
+	if err != nil || tw.bytesPerSec <= 0 {
+		return n, err
+	}
+
+	tw.written += int64(n)
+	expected := time.Duration(float64(tw.written) / float64(tw.bytesPerSec) * float64(time.Second))
+	if wait := expected - time.Since(tw.start); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-tw.ctx.Done():
+		}
+	}
+	return n, nil
+}
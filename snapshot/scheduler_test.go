@@ -0,0 +1,74 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneSnapshots(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "scheduled_snapshot")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{
+		"theta_snapshot-300-0xc3-2026-10-03",
+		"theta_snapshot-1200-0xa1-2026-10-01",
+		"theta_snapshot-900-0xb2-2026-10-02",
+		"notes.txt",
+	} {
+		assert.Nil(ioutil.WriteFile(path.Join(dir, name), []byte{}, 0644))
+	}
+
+	snapshots, err := listSnapshots(dir)
+	assert.Nil(err)
+	assert.Equal(3, len(snapshots))
+	assert.Equal(uint64(1200), snapshots[0].height)
+	assert.Equal(uint64(300), snapshots[2].height)
+
+	assert.Nil(pruneSnapshots(dir, 2))
+	snapshots, err = listSnapshots(dir)
+	assert.Nil(err)
+	assert.Equal(2, len(snapshots))
+	assert.Equal("theta_snapshot-1200-0xa1-2026-10-01", snapshots[0].name)
+	assert.Equal("theta_snapshot-900-0xb2-2026-10-02", snapshots[1].name)
+
+	_, err = os.Stat(path.Join(dir, "notes.txt"))
+	assert.Nil(err)
+
+	removePartialSnapshot(dir, 900)
+	snapshots, err = listSnapshots(dir)
+	assert.Nil(err)
+	assert.Equal(1, len(snapshots))
+}
+
+func TestThrottledWriter(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := &bytes.Buffer{}
+	w := newThrottledWriter(context.Background(), buf, 1000)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		n, err := w.Write(make([]byte, 50))
+		assert.Nil(err)
+		assert.Equal(50, n)
+	}
+	assert.True(time.Since(start) >= 150*time.Millisecond)
+	assert.Equal(200, buf.Len())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w = newThrottledWriter(ctx, buf, 0)
+	_, err := w.Write([]byte{1})
+	assert.Nil(err)
+	cancel()
+	_, err = w.Write([]byte{1})
+	assert.Equal(context.Canceled, err)
+}
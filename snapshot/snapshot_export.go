@@ -3,6 +3,7 @@ package snapshot
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -239,6 +240,13 @@ func ExportSnapshotV2(db database.Database, consensus *cns.ConsensusEngine, chai
 }
 
 func ExportSnapshotV3(db database.Database, consensus *cns.ConsensusEngine, chain *blockchain.Chain, snapshotDir string, height uint64) (string, error) {
+	return exportSnapshotV3(context.Background(), db, consensus, chain, snapshotDir, height, 0)
+}
+
+// exportSnapshotV3 exports the snapshot, writing at most maxBytesPerSec bytes per second to the snapshot
+// file (unlimited if maxBytesPerSec is 0). The export is aborted once ctx is cancelled.
+func exportSnapshotV3(ctx context.Context, db database.Database, consensus *cns.ConsensusEngine, chain *blockchain.Chain,
+	snapshotDir string, height uint64, maxBytesPerSec int64) (string, error) {
 	var lastFinalizedBlock *core.ExtendedBlock
 	if height != 0 {
 		blocks := chain.FindBlocksByHeight(height)
@@ -270,7 +278,7 @@ func ExportSnapshotV3(db database.Database, consensus *cns.ConsensusEngine, chai
 		return "", err
 	}
 	defer file.Close()
-	writer := bufio.NewWriter(file)
+	writer := bufio.NewWriter(newThrottledWriter(ctx, file, maxBytesPerSec))
 
 	// --------------- Export the Header Section --------------- //
 
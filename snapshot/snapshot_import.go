@@ -2,6 +2,7 @@ package snapshot
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -20,6 +21,7 @@ import (
 	"github.com/thetatoken/theta/common"
 	"github.com/thetatoken/theta/consensus"
 	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
 	"github.com/thetatoken/theta/ledger/state"
 	"github.com/thetatoken/theta/ledger/types"
 	"github.com/thetatoken/theta/rlp"
@@ -95,18 +97,11 @@ func ImportSnapshot(snapshotFilePath, chainImportDirPath, chainCorrectionPath st
 func ValidateSnapshot(snapshotFilePath, chainImportDirPath, chainCorrectionPath string) (*core.BlockHeader, error) {
 	logger.Infof("Verifying snapshot: %v", snapshotFilePath)
 
-	tmpdbRoot, err := ioutil.TempDir("", "tmpdb")
+	tmpdb, cleanup, err := newTmpDB()
 	if err != nil {
 		log.Panicf("Failed to create temporary db for snapshot verification: %v", err)
 	}
-	mainTmpDBPath := path.Join(tmpdbRoot, "main")
-	refTmpDBPath := path.Join(tmpdbRoot, "ref")
-	defer func() {
-		os.RemoveAll(mainTmpDBPath)
-		os.RemoveAll(refTmpDBPath)
-	}()
-
-	tmpdb, err := backend.NewLDBDatabase(mainTmpDBPath, refTmpDBPath, 256, 0)
+	defer cleanup()
 
 	snapshotBlockHeader, metadata, err := loadSnapshot(snapshotFilePath, tmpdb, "Validating Snapshot")
 	if err != nil {
@@ -140,6 +135,24 @@ func ValidateSnapshot(snapshotFilePath, chainImportDirPath, chainCorrectionPath
 	return snapshotBlockHeader, nil
 }
 
+// newTmpDB creates a temporary database, which is closed and deleted by the returned cleanup function
+func newTmpDB() (database.Database, func(), error) {
+	tmpdbRoot, err := ioutil.TempDir("", "tmpdb")
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpdb, err := backend.NewLDBDatabase(path.Join(tmpdbRoot, "main"), path.Join(tmpdbRoot, "ref"), 256, 0)
+	if err != nil {
+		os.RemoveAll(tmpdbRoot)
+		return nil, nil, err
+	}
+	cleanup := func() {
+		tmpdb.Close()
+		os.RemoveAll(tmpdbRoot)
+	}
+	return tmpdb, cleanup, nil
+}
+
 func LoadSnapshotCheckpointHeader(snapshotFilePath string) *core.BlockHeader {
 	var err error
 
@@ -697,6 +710,11 @@ func loadStateV3(file *os.File, db database.Database, fileSize uint64, logStr st
 			}
 		}
 
+		// The records are trie nodes keyed by their hashes
+		if !bytes.Equal(crypto.Keccak256Hash(record.V).Bytes(), record.K) {
+			return fmt.Errorf("Snapshot record hash mismatch, key: %v", hex.EncodeToString(record.K))
+		}
+
 		err = batch.Put(record.K, record.V)
 		if err != nil {
 			return fmt.Errorf("Failed to write snapshot record, %v", err)
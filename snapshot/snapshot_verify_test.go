@@ -0,0 +1,44 @@
+package snapshot
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database/backend"
+)
+
+func TestVerifyStateTrie(t *testing.T) {
+	assert := assert.New(t)
+
+	db := backend.NewMemDatabase()
+	storage := state.NewStoreView(0, common.Hash{}, db)
+	storage.Set(common.Bytes("slot1"), common.Bytes("value1"))
+	storage.Set(common.Bytes("slot2"), common.Bytes("value2"))
+	storageRoot := storage.Save()
+
+	sv := state.NewStoreView(0, common.Hash{}, db)
+	address := common.BigToAddress(big.NewInt(1))
+	sv.SetAccount(address, &types.Account{
+		Address:  address,
+		Root:     storageRoot,
+		CodeHash: types.EmptyCodeHash,
+		Balance:  types.NewCoins(1000, 5000),
+	})
+	sv.Set(common.Bytes("other"), common.Bytes("data"))
+	root := sv.Save()
+
+	numStateKeys, numStorageKeys, err := verifyStateTrie(root, db)
+	assert.Nil(err)
+	assert.Equal(uint64(2), numStateKeys)
+	assert.Equal(uint64(2), numStorageKeys)
+
+	// A missing trie node of the account storage is detected
+	assert.Nil(db.Delete(storageRoot.Bytes()))
+	_, _, err = verifyStateTrie(root, db)
+	assert.NotNil(err)
+}
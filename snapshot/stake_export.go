@@ -0,0 +1,142 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/store/database"
+)
+
+//
+// The stake export lists the validator and guardian stake composition of each checkpoint in a height
+// range, one JSON document per line, so that the concentration of the stakes over time can be studied
+// without replaying the chain. Example:
+//
+// {"height":"101","block_hash":"0x3e1f...","validators":{"total_stake":"...","holders":[...]},"guardians":{...}}
+// {"height":"201","block_hash":"0x9a0c...","validators":{"total_stake":"...","holders":[...]},"guardians":{...}}
+//
+
+// StakeComposition is the validator and guardian stake composition of the state of a checkpoint
+type StakeComposition struct {
+	Height     common.JSONUint64    `json:"height"`
+	BlockHash  common.Hash          `json:"block_hash"`
+	Validators *StakeSetComposition `json:"validators"`
+	Guardians  *StakeSetComposition `json:"guardians"`
+}
+
+// StakeSetComposition lists the stake holders of a candidate pool, from the largest to the smallest
+type StakeSetComposition struct {
+	TotalStake *common.JSONBig           `json:"total_stake"`
+	Holders    []*StakeHolderComposition `json:"holders"`
+}
+
+// StakeHolderComposition lists the stakes delegated to a stake holder
+type StakeHolderComposition struct {
+	Holder     common.Address  `json:"holder"`
+	TotalStake *common.JSONBig `json:"total_stake"` // the withdrawn stakes excluded
+	Stakes     []*core.Stake   `json:"stakes"`
+}
+
+// GetStakeComposition returns the stake composition of the given state
+func GetStakeComposition(sv *state.StoreView, block *core.ExtendedBlock) *StakeComposition {
+	composition := &StakeComposition{
+		Height:     common.JSONUint64(block.Height),
+		BlockHash:  block.Hash(),
+		Validators: newStakeSetComposition([]*core.StakeHolder{}),
+		Guardians:  newStakeSetComposition([]*core.StakeHolder{}),
+	}
+	if vcp := sv.GetValidatorCandidatePool(); vcp != nil {
+		composition.Validators = newStakeSetComposition(vcp.SortedCandidates)
+	}
+	if gcp := sv.GetGuardianCandidatePool(); gcp != nil {
+		holders := make([]*core.StakeHolder, 0, len(gcp.SortedGuardians))
+		for _, g := range gcp.SortedGuardians {
+			holders = append(holders, g.StakeHolder)
+		}
+		composition.Guardians = newStakeSetComposition(holders)
+	}
+	return composition
+}
+
+func newStakeSetComposition(holders []*core.StakeHolder) *StakeSetComposition {
+	total := big.NewInt(0)
+	compositions := make([]*StakeHolderComposition, 0, len(holders))
+	for _, holder := range holders {
+		holderTotal := holder.TotalStake()
+		total.Add(total, holderTotal)
+		compositions = append(compositions, &StakeHolderComposition{
+			Holder:     holder.Holder,
+			TotalStake: (*common.JSONBig)(holderTotal),
+			Stakes:     holder.Stakes,
+		})
+	}
+	sort.SliceStable(compositions, func(i, j int) bool {
+		if cmp := compositions[i].TotalStake.ToInt().Cmp(compositions[j].TotalStake.ToInt()); cmp != 0 {
+			return cmp > 0
+		}
+		return bytes.Compare(compositions[i].Holder[:], compositions[j].Holder[:]) < 0
+	})
+	return &StakeSetComposition{
+		TotalStake: (*common.JSONBig)(total),
+		Holders:    compositions,
+	}
+}
+
+// WalkStakeCompositions calls the callback with the stake composition of each checkpoint between the start
+// and the end heights, inclusive. The checkpoints without a finalized block or whose state has been pruned
+// are skipped, and their heights returned.
+func WalkStakeCompositions(db database.Database, chain *blockchain.Chain, startHeight, endHeight uint64,
+	cb func(composition *StakeComposition) error) (skippedHeights []uint64, err error) {
+	height := startHeight
+	for height <= endHeight && !common.IsCheckPointHeight(height) {
+		height++
+	}
+
+	skippedHeights = []uint64{}
+	for ; height <= endHeight; height += uint64(common.CheckpointInterval) {
+		var sv *state.StoreView
+		var block *core.ExtendedBlock
+		for _, b := range chain.FindBlocksByHeight(height) {
+			if b.Status.IsFinalized() {
+				block = b
+				sv = state.NewStoreView(height, b.StateHash, db)
+				break
+			}
+		}
+		if sv == nil {
+			skippedHeights = append(skippedHeights, height)
+			continue
+		}
+		if err := cb(GetStakeComposition(sv, block)); err != nil {
+			return nil, err
+		}
+	}
+	return skippedHeights, nil
+}
+
+// ExportStakeCompositions writes the stake composition of each checkpoint between the start and the end
+// heights to the writer, and returns the heights of the skipped checkpoints
+func ExportStakeCompositions(db database.Database, chain *blockchain.Chain, startHeight, endHeight uint64, w io.Writer) ([]uint64, error) {
+	writer := bufio.NewWriter(w)
+	skippedHeights, err := WalkStakeCompositions(db, chain, startHeight, endHeight, func(composition *StakeComposition) error {
+		raw, err := json.Marshal(composition)
+		if err != nil {
+			return err
+		}
+		writer.Write(raw)
+		_, err = writer.WriteString("\n")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return skippedHeights, writer.Flush()
+}
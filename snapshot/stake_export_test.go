@@ -0,0 +1,85 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto/bls"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/store/database/backend"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+func TestStakeCompositionExport(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	validator1 := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	validator2 := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	guardian := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	source1 := common.HexToAddress("0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+	source2 := common.HexToAddress("0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+
+	db := backend.NewMemDatabase()
+	sv := state.NewStoreView(101, common.Hash{}, db)
+
+	vcp := &core.ValidatorCandidatePool{}
+	require.Nil(vcp.DepositStake(source1, validator1, new(big.Int).Mul(big.NewInt(2), core.MinValidatorStakeDeposit), "testnet"))
+	require.Nil(vcp.DepositStake(source1, validator2, core.MinValidatorStakeDeposit, "testnet"))
+	require.Nil(vcp.DepositStake(source2, validator2, new(big.Int).Mul(big.NewInt(3), core.MinValidatorStakeDeposit), "testnet"))
+	sv.UpdateValidatorCandidatePool(vcp)
+
+	blsKey, err := bls.RandKey()
+	require.Nil(err)
+	gcp := core.NewGuardianCandidatePool()
+	gcp.SortedGuardians = append(gcp.SortedGuardians, &core.Guardian{
+		StakeHolder: core.NewStakeHolder(guardian, []*core.Stake{core.NewStake(source1, big.NewInt(1000))}),
+		Pubkey:      blsKey.PublicKey(),
+	})
+	sv.UpdateGuardianCandidatePool(gcp)
+
+	root := core.NewBlock()
+	root.ChainID = "testchain"
+	root.Height = 101
+	root.StateHash = sv.Save()
+	chain := blockchain.NewChain(root.ChainID, kvstore.NewKVStore(db), root)
+
+	var export bytes.Buffer
+	skippedHeights, err := ExportStakeCompositions(db, chain, 50, 301, &export)
+	require.Nil(err)
+	assert.Equal([]uint64{201, 301}, skippedHeights)
+
+	lines := strings.Split(strings.TrimSpace(export.String()), "\n")
+	require.Equal(1, len(lines))
+	composition := &StakeComposition{}
+	require.Nil(json.Unmarshal([]byte(lines[0]), composition))
+	assert.Equal(common.JSONUint64(101), composition.Height)
+
+	// The stake holders are listed from the largest to the smallest
+	validators := composition.Validators
+	assert.Equal(0, validators.TotalStake.ToInt().Cmp(new(big.Int).Mul(big.NewInt(6), core.MinValidatorStakeDeposit)))
+	require.Equal(2, len(validators.Holders))
+	assert.Equal(validator2, validators.Holders[0].Holder)
+	assert.Equal(2, len(validators.Holders[0].Stakes))
+	assert.Equal(validator1, validators.Holders[1].Holder)
+
+	require.Equal(1, len(composition.Guardians.Holders))
+	assert.Equal(guardian, composition.Guardians.Holders[0].Holder)
+	assert.Equal(int64(1000), composition.Guardians.TotalStake.ToInt().Int64())
+
+	// No checkpoint in the range
+	export.Reset()
+	skippedHeights, err = ExportStakeCompositions(db, chain, 102, 200, &export)
+	require.Nil(err)
+	assert.Equal(0, len(skippedHeights))
+	assert.Equal(0, export.Len())
+}
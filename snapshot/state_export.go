@@ -0,0 +1,282 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/common/hexutil"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database"
+	"github.com/thetatoken/theta/store/treestore"
+)
+
+//
+// The state export is a canonical JSON document of the ledger state at a finalized height. The entries
+// of the state trie are listed in key order, each followed by the entries of its account storage if any,
+// one entry per line, so that the exports of the same state are identical and the exports of different
+// heights can be compared with diff. Example:
+//
+// {
+// "chain_id": "mainnet",
+// "height": "12000000",
+// "state_hash": "0x8e4a...",
+// "entries": [
+// {"key":"0x6c732f61...","value":"0xf86e..."},
+// {"owner":"0x6c732f61...","key":"0x0000...","value":"0x01"},
+// ...
+// ]
+// }
+//
+
+// StateExportHeader describes the state in the export
+type StateExportHeader struct {
+	ChainID   string            `json:"chain_id"`
+	Height    common.JSONUint64 `json:"height"`
+	StateHash common.Hash       `json:"state_hash"`
+}
+
+// StateEntry is a key value pair of the state trie, or of the storage of the account stored under Owner
+type StateEntry struct {
+	Owner hexutil.Bytes `json:"owner,omitempty"`
+	Key   hexutil.Bytes `json:"key"`
+	Value hexutil.Bytes `json:"value"`
+}
+
+// ExportState writes the ledger state of the finalized block at the given height to the writer
+func ExportState(db database.Database, chain *blockchain.Chain, height uint64, w io.Writer) (*StateExportHeader, error) {
+	var block *core.ExtendedBlock
+	for _, b := range chain.FindBlocksByHeight(height) {
+		if b.Status.IsFinalized() {
+			block = b
+			break
+		}
+	}
+	if block == nil {
+		return nil, fmt.Errorf("finalized block not found for height %v", height)
+	}
+
+	header := &StateExportHeader{
+		ChainID:   block.ChainID,
+		Height:    common.JSONUint64(block.Height),
+		StateHash: block.StateHash,
+	}
+
+	writer := bufio.NewWriter(w)
+	if err := writeStateExportHeader(writer, header); err != nil {
+		return nil, err
+	}
+
+	sv := state.NewStoreView(block.Height, block.StateHash, db)
+	first := true
+	var err error
+	writeEntry := func(entry *StateEntry) bool {
+		if !first {
+			writer.WriteString(",\n")
+		}
+		first = false
+		var raw []byte
+		raw, err = json.Marshal(entry)
+		if err != nil {
+			return false
+		}
+		_, err = writer.Write(raw)
+		return err == nil
+	}
+	sv.GetStore().Traverse(nil, func(k, v common.Bytes) bool {
+		if !writeEntry(&StateEntry{Key: hexutil.Bytes(k), Value: hexutil.Bytes(v)}) {
+			return false
+		}
+		if !bytes.HasPrefix(k, []byte("ls/a")) {
+			return true
+		}
+		account := &types.Account{}
+		if err = types.FromBytes([]byte(v), account); err != nil {
+			err = fmt.Errorf("failed to parse account %v: %v", hexutil.Encode(k), err)
+			return false
+		}
+		if account.Root == (common.Hash{}) {
+			return true
+		}
+		storage := treestore.NewTreeStore(account.Root, db)
+		return storage.Traverse(nil, func(ak, av common.Bytes) bool {
+			return writeEntry(&StateEntry{Owner: hexutil.Bytes(k), Key: hexutil.Bytes(ak), Value: hexutil.Bytes(av)})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = writer.WriteString("\n]\n}\n"); err != nil {
+		return nil, err
+	}
+	return header, writer.Flush()
+}
+
+func writeStateExportHeader(writer *bufio.Writer, header *StateExportHeader) error {
+	fields := []struct {
+		name  string
+		value interface{}
+	}{
+		{"chain_id", header.ChainID},
+		{"height", header.Height},
+		{"state_hash", header.StateHash},
+	}
+	writer.WriteString("{\n")
+	for _, field := range fields {
+		raw, err := json.Marshal(field.value)
+		if err != nil {
+			return err
+		}
+		writer.WriteString(fmt.Sprintf("\"%v\": %v,\n", field.name, string(raw)))
+	}
+	_, err := writer.WriteString("\"entries\": [\n")
+	return err
+}
+
+// ImportState loads the state export from the reader into a new StoreView at the given height, and
+// verifies the state hash and the account storage roots against the export
+func ImportState(r io.Reader, db database.Database, height uint64) (*state.StoreView, *StateExportHeader, error) {
+	header := &StateExportHeader{}
+	decoder := json.NewDecoder(r)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, nil, err
+	}
+
+	sv := state.NewStoreView(height, common.Hash{}, db)
+	entriesFound := false
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		switch token {
+		case "chain_id":
+			err = decoder.Decode(&header.ChainID)
+		case "height":
+			err = decoder.Decode(&header.Height)
+		case "state_hash":
+			err = decoder.Decode(&header.StateHash)
+		case "entries":
+			entriesFound = true
+			err = importStateEntries(decoder, sv, db)
+		default:
+			err = fmt.Errorf("unknown field in the state export: %v", token)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if !entriesFound {
+		return nil, nil, fmt.Errorf("no state entries found in the state export")
+	}
+
+	if stateHash := sv.Save(); stateHash != header.StateHash {
+		return nil, nil, fmt.Errorf("state hash mismatch, expected: %v, imported: %v", header.StateHash.Hex(), stateHash.Hex())
+	}
+	return sv, header, nil
+}
+
+func importStateEntries(decoder *json.Decoder, sv *state.StoreView, db database.Database) error {
+	if err := expectDelim(decoder, '['); err != nil {
+		return err
+	}
+
+	var owner common.Bytes
+	var storage *state.StoreView
+	saveStorage := func() error {
+		if storage == nil {
+			return nil
+		}
+		account := &types.Account{}
+		if err := types.FromBytes(sv.Get(owner), account); err != nil {
+			return fmt.Errorf("failed to parse account %v: %v", hexutil.Encode(owner), err)
+		}
+		if root := storage.Save(); root != account.Root {
+			return fmt.Errorf("storage root mismatch for account %v, expected: %v, imported: %v",
+				hexutil.Encode(owner), account.Root.Hex(), root.Hex())
+		}
+		storage = nil
+		return nil
+	}
+
+	for decoder.More() {
+		entry := &StateEntry{}
+		if err := decoder.Decode(entry); err != nil {
+			return err
+		}
+		if len(entry.Owner) == 0 {
+			if err := saveStorage(); err != nil {
+				return err
+			}
+			sv.Set(common.Bytes(entry.Key), common.Bytes(entry.Value))
+			continue
+		}
+		if storage == nil || !bytes.Equal(owner, entry.Owner) {
+			if err := saveStorage(); err != nil {
+				return err
+			}
+			owner = common.Bytes(entry.Owner)
+			storage = state.NewStoreView(sv.Height(), common.Hash{}, db)
+		}
+		storage.Set(common.Bytes(entry.Key), common.Bytes(entry.Value))
+	}
+	if err := saveStorage(); err != nil {
+		return err
+	}
+
+	return expectDelim(decoder, ']')
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("malformed state export, expected %v, got %v", delim, token)
+	}
+	return nil
+}
+
+// WriteGenesisSnapshot writes the given state as the genesis snapshot of a new chain with the given
+// chainID, and returns the genesis block hash
+func WriteGenesisSnapshot(sv *state.StoreView, chainID string, genesisSnapshotFilePath string) (common.Hash, error) {
+	genesisBlock := core.NewBlock()
+	genesisBlock.ChainID = chainID
+	genesisBlock.Height = core.GenesisBlockHeight
+	genesisBlock.Epoch = genesisBlock.Height
+	genesisBlock.Parent = common.Hash{}
+	genesisBlock.StateHash = sv.Hash()
+	genesisBlock.Timestamp = big.NewInt(time.Now().Unix())
+
+	metadata := &core.SnapshotMetadata{
+		TailTrio: core.SnapshotBlockTrio{
+			First:  core.SnapshotFirstBlock{},
+			Second: core.SnapshotSecondBlock{Header: genesisBlock.BlockHeader},
+			Third:  core.SnapshotThirdBlock{},
+		},
+	}
+
+	file, err := os.Create(genesisSnapshotFilePath)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	if err = core.WriteMetadata(writer, metadata); err != nil {
+		return common.Hash{}, err
+	}
+	writeStoreView(sv, true, writer, sv.GetDB())
+
+	return genesisBlock.Hash(), nil
+}
@@ -0,0 +1,74 @@
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/store/database/backend"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+func TestStateExportImport(t *testing.T) {
+	assert := assert.New(t)
+
+	db := backend.NewMemDatabase()
+	sv := state.NewStoreView(0, common.Hash{}, db)
+
+	storage := state.NewStoreView(0, common.Hash{}, db)
+	storage.Set(common.Bytes("slot1"), common.Bytes("value1"))
+	storage.Set(common.Bytes("slot2"), common.Bytes("value2"))
+	storageRoot := storage.Save()
+
+	for i, root := range []common.Hash{{}, storageRoot} {
+		address := common.BigToAddress(big.NewInt(int64(i + 1)))
+		sv.SetAccount(address, &types.Account{
+			Address:  address,
+			Root:     root,
+			CodeHash: types.EmptyCodeHash,
+			Balance:  types.NewCoins(int64(1000*(i+1)), int64(5000*(i+1))),
+		})
+	}
+	sv.Set(common.Bytes("other"), common.Bytes("data"))
+
+	root := core.NewBlock()
+	root.ChainID = "testchain"
+	root.Height = 10
+	root.StateHash = sv.Save()
+	chain := blockchain.NewChain(root.ChainID, kvstore.NewKVStore(db), root)
+
+	var export1, export2 bytes.Buffer
+	header, err := ExportState(db, chain, 10, &export1)
+	assert.Nil(err)
+	assert.Equal("testchain", header.ChainID)
+	assert.Equal(root.StateHash, header.StateHash)
+
+	// The export is canonical
+	_, err = ExportState(db, chain, 10, &export2)
+	assert.Nil(err)
+	assert.Equal(export1.String(), export2.String())
+
+	_, err = ExportState(db, chain, 11, &export2)
+	assert.NotNil(err)
+
+	importedDB := backend.NewMemDatabase()
+	importedSV, importedHeader, err := ImportState(bytes.NewReader(export1.Bytes()), importedDB, core.GenesisBlockHeight)
+	assert.Nil(err)
+	assert.Equal(*header, *importedHeader)
+	assert.Equal(root.StateHash, importedSV.Hash())
+
+	importedStorage := state.NewStoreView(0, storageRoot, importedDB)
+	assert.Equal(common.Bytes("value2"), importedStorage.Get(common.Bytes("slot2")))
+
+	// Tampered exports are rejected
+	tampered := bytes.Replace(export1.Bytes(), []byte("0x64617461"), []byte("0x64617462"), 1) // "data" -> "datb"
+	_, _, err = ImportState(bytes.NewReader(tampered), backend.NewMemDatabase(), core.GenesisBlockHeight)
+	assert.NotNil(err)
+}
@@ -49,6 +49,8 @@ type LDBDatabase struct {
 	db    *leveldb.DB // LevelDB instance
 	refdb *leveldb.DB // LevelDB instance for references
 
+	writeMu *sync.RWMutex // Held by the writes, so that the backups can snapshot both instances at the same point
+
 	compTimeMeter    metrics.Meter // Meter for measuring the total time spent in database compaction
 	compReadMeter    metrics.Meter // Meter for measuring the data read during compaction
 	compWriteMeter   metrics.Meter // Meter for measuring the data written during compaction
@@ -103,9 +105,10 @@ func NewLDBDatabase(file string, reffile string, cache int, handles int) (*LDBDa
 	}
 
 	return &LDBDatabase{
-		fn:    file,
-		db:    db,
-		refdb: refdb,
+		fn:      file,
+		db:      db,
+		refdb:   refdb,
+		writeMu: &sync.RWMutex{},
 	}, nil
 }
 
@@ -116,6 +119,8 @@ func (db *LDBDatabase) Path() string {
 
 // Put puts the given key / value to the queue
 func (db *LDBDatabase) Put(key []byte, value []byte) error {
+	db.writeMu.RLock()
+	defer db.writeMu.RUnlock()
 	return db.db.Put(key, value, nil)
 }
 
@@ -137,6 +142,8 @@ func (db *LDBDatabase) Get(key []byte) ([]byte, error) {
 
 // Delete deletes the key from the queue and database
 func (db *LDBDatabase) Delete(key []byte) error {
+	db.writeMu.RLock()
+	defer db.writeMu.RUnlock()
 	db.refdb.Delete(key, nil)
 	err := db.db.Delete(key, nil)
 	if err != nil && err == leveldb.ErrNotFound {
@@ -146,6 +153,9 @@ func (db *LDBDatabase) Delete(key []byte) error {
 }
 
 func (db *LDBDatabase) Reference(key []byte) error {
+	db.writeMu.RLock()
+	defer db.writeMu.RUnlock()
+
 	// check if k/v exists
 	value, err := db.Get(key)
 	if err != nil {
@@ -176,6 +186,9 @@ func (db *LDBDatabase) Reference(key []byte) error {
 }
 
 func (db *LDBDatabase) Dereference(key []byte) error {
+	db.writeMu.RLock()
+	defer db.writeMu.RUnlock()
+
 	// check if k/v exists
 	value, err := db.Get(key)
 	if err != nil {
@@ -245,6 +258,18 @@ func (db *LDBDatabase) NewIteratorWithPrefix(prefix []byte) iterator.Iterator {
 	return db.db.NewIterator(util.BytesPrefix(prefix), nil)
 }
 
+// IterateWithPrefix calls f for the keys with the given prefix in the ascending order, until f returns false.
+func (db *LDBDatabase) IterateWithPrefix(prefix []byte, f func(key, value []byte) bool) error {
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+	for it.Next() {
+		if !f(it.Key(), it.Value()) {
+			break
+		}
+	}
+	return it.Error()
+}
+
 func (db *LDBDatabase) Close() {
 	// Stop the metrics collection to avoid internal database races
 	db.quitLock.Lock()
@@ -468,12 +493,13 @@ func (db *LDBDatabase) meter(refresh time.Duration) {
 }
 
 func (db *LDBDatabase) NewBatch() database.Batch {
-	return &ldbBatch{db: db.db, refdb: db.refdb, b: new(leveldb.Batch), references: make(map[string]int)}
+	return &ldbBatch{db: db.db, refdb: db.refdb, writeMu: db.writeMu, b: new(leveldb.Batch), references: make(map[string]int)}
 }
 
 type ldbBatch struct {
 	db         *leveldb.DB
 	refdb      *leveldb.DB
+	writeMu    *sync.RWMutex
 	b          *leveldb.Batch
 	references map[string]int
 	size       int
@@ -486,7 +512,9 @@ func (b *ldbBatch) Put(key, value []byte) error {
 }
 
 func (b *ldbBatch) Delete(key []byte) error {
+	b.writeMu.RLock()
 	b.refdb.Delete(key, nil)
+	b.writeMu.RUnlock()
 	b.b.Delete(key)
 	b.size += 1
 	return nil
@@ -505,6 +533,9 @@ func (b *ldbBatch) Dereference(key []byte) error {
 }
 
 func (b *ldbBatch) Write() error {
+	b.writeMu.RLock()
+	defer b.writeMu.RUnlock()
+
 	err := b.db.Write(b.b, nil)
 	if err != nil {
 		return err
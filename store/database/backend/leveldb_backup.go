@@ -0,0 +1,367 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/thetatoken/theta/store/database"
+)
+
+const (
+	// BackupTypeFull marks a backup holding all the entries of the database
+	BackupTypeFull = "full"
+	// BackupTypeIncremental marks a backup holding the changes since its base full backup
+	BackupTypeIncremental = "incremental"
+
+	backupManifestFile = "backup.json"
+	backupMainDir      = "main"
+	backupRefDir       = "ref"
+)
+
+// Markers prepended to the values of the incremental backups
+const (
+	backupDeleted byte = 0
+	backupPut     byte = 1
+)
+
+// BackupManifest describes a backup. It is written after all the entries are backed up, so a
+// directory without the manifest is an incomplete backup.
+type BackupManifest struct {
+	Type       string    `json:"type"`
+	Base       string    `json:"base,omitempty"` // Path of the base full backup of an incremental backup
+	CreatedAt  time.Time `json:"created_at"`
+	NumEntries uint64    `json:"num_entries"` // Number of the backed up entries, or the changed entries of an incremental backup
+	NumRefs    uint64    `json:"num_refs"`
+}
+
+// Backup writes a consistent copy of the database to backupDir while the database stays online. If
+// baseDir is not empty, only the changes since the full backup in baseDir are written.
+func (db *LDBDatabase) Backup(backupDir, baseDir string) (manifest *BackupManifest, err error) {
+	var base *BackupManifest
+	if baseDir != "" {
+		if baseDir, err = filepath.Abs(baseDir); err != nil {
+			return nil, err
+		}
+		base, err = ReadBackupManifest(baseDir)
+		if err != nil {
+			return nil, err
+		}
+		if base.Type != BackupTypeFull {
+			return nil, fmt.Errorf("Base backup %v is not a full backup", baseDir)
+		}
+	}
+	if _, err := os.Stat(backupDir); !os.IsNotExist(err) {
+		return nil, fmt.Errorf("Backup directory %v already exists", backupDir)
+	}
+
+	// Block the writes briefly so that the main db and the reference db are snapshotted at the same point
+	db.writeMu.Lock()
+	snap, err := db.db.GetSnapshot()
+	if err != nil {
+		db.writeMu.Unlock()
+		return nil, err
+	}
+	defer snap.Release()
+	refSnap, err := db.refdb.GetSnapshot()
+	db.writeMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	defer refSnap.Release()
+
+	defer func() {
+		if err != nil {
+			os.RemoveAll(backupDir)
+		}
+	}()
+
+	manifest = &BackupManifest{
+		Type:      BackupTypeFull,
+		CreatedAt: time.Now().UTC(),
+	}
+	if base != nil {
+		manifest.Type = BackupTypeIncremental
+		manifest.Base = baseDir
+	}
+
+	logger.Infof("Backing up the database to %v, type: %v", backupDir, manifest.Type)
+	manifest.NumEntries, err = backupInstance(snap.NewIterator(nil, nil), path.Join(backupDir, backupMainDir), baseDir, backupMainDir)
+	if err != nil {
+		return nil, err
+	}
+	manifest.NumRefs, err = backupInstance(refSnap.NewIterator(nil, nil), path.Join(backupDir, backupRefDir), baseDir, backupRefDir)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	if err = ioutil.WriteFile(path.Join(backupDir, backupManifestFile), raw, 0600); err != nil {
+		return nil, err
+	}
+	logger.Infof("Database backed up to %v, entries: %v, refs: %v", backupDir, manifest.NumEntries, manifest.NumRefs)
+
+	return manifest, nil
+}
+
+// backupInstance writes the entries of the snapshot iterator to a new LevelDB instance in dir. If baseDir
+// is not empty, only the entries which differ from the ones of the base backup are written.
+func backupInstance(it iterator.Iterator, dir string, baseDir string, instance string) (uint64, error) {
+	defer it.Release()
+
+	out, err := leveldb.OpenFile(dir, &opt.Options{ErrorIfExist: true})
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+
+	w := &backupWriter{db: out, batch: new(leveldb.Batch)}
+	if baseDir == "" {
+		for it.Next() {
+			if err := w.put(it.Key(), it.Value()); err != nil {
+				return w.count, err
+			}
+		}
+	} else {
+		baseDB, err := leveldb.OpenFile(path.Join(baseDir, instance), &opt.Options{ReadOnly: true, ErrorIfMissing: true})
+		if err != nil {
+			return 0, err
+		}
+		defer baseDB.Close()
+		baseIt := baseDB.NewIterator(nil, nil)
+		defer baseIt.Release()
+
+		if err := diffIterators(it, baseIt, w); err != nil {
+			return w.count, err
+		}
+		if err := baseIt.Error(); err != nil {
+			return w.count, err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return w.count, err
+	}
+	return w.count, w.flush()
+}
+
+// diffIterators walks the two sorted iterators side by side, and records the entries that are added,
+// changed or removed in the current iterator compared to the base iterator
+func diffIterators(curr, base iterator.Iterator, w *backupWriter) error {
+	hasCurr, hasBase := curr.Next(), base.Next()
+	for hasCurr || hasBase {
+		cmp := 0
+		switch {
+		case !hasBase:
+			cmp = -1
+		case !hasCurr:
+			cmp = 1
+		default:
+			cmp = bytes.Compare(curr.Key(), base.Key())
+		}
+
+		var err error
+		switch {
+		case cmp < 0:
+			err = w.put(curr.Key(), append([]byte{backupPut}, curr.Value()...))
+			hasCurr = curr.Next()
+		case cmp > 0:
+			err = w.put(base.Key(), []byte{backupDeleted})
+			hasBase = base.Next()
+		default:
+			if !bytes.Equal(curr.Value(), base.Value()) {
+				err = w.put(curr.Key(), append([]byte{backupPut}, curr.Value()...))
+			}
+			hasCurr, hasBase = curr.Next(), base.Next()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type backupWriter struct {
+	db    *leveldb.DB
+	batch *leveldb.Batch
+	size  int
+	count uint64
+}
+
+func (w *backupWriter) put(key, value []byte) error {
+	w.batch.Put(key, value)
+	w.size += len(key) + len(value)
+	w.count++
+	if w.size < database.IdealBatchSize {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *backupWriter) flush() error {
+	if err := w.db.Write(w.batch, nil); err != nil {
+		return err
+	}
+	w.batch.Reset()
+	w.size = 0
+	return nil
+}
+
+// ReadBackupManifest reads the manifest of the backup in backupDir
+func ReadBackupManifest(backupDir string) (*BackupManifest, error) {
+	raw, err := ioutil.ReadFile(path.Join(backupDir, backupManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the manifest of backup %v, it might be incomplete: %v", backupDir, err)
+	}
+	manifest := &BackupManifest{}
+	if err := json.Unmarshal(raw, manifest); err != nil {
+		return nil, fmt.Errorf("Failed to parse the manifest of backup %v: %v", backupDir, err)
+	}
+	return manifest, nil
+}
+
+// RestoreBackup rebuilds the main db and the reference db from the backup in backupDir. An incremental
+// backup is restored on top of its base full backup. The table files of the full backup are hard-linked
+// instead of copied when possible, as LevelDB never modifies them.
+func RestoreBackup(backupDir, mainDBPath, refDBPath string) error {
+	manifest, err := ReadBackupManifest(backupDir)
+	if err != nil {
+		return err
+	}
+	for _, dir := range []string{mainDBPath, refDBPath} {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			return fmt.Errorf("Database directory %v already exists", dir)
+		}
+	}
+
+	fullDir := backupDir
+	if manifest.Type == BackupTypeIncremental {
+		fullDir = manifest.Base
+		base, err := ReadBackupManifest(fullDir)
+		if err != nil {
+			return err
+		}
+		if base.Type != BackupTypeFull || base.CreatedAt.After(manifest.CreatedAt) {
+			return fmt.Errorf("Invalid base backup %v of incremental backup %v", fullDir, backupDir)
+		}
+	}
+
+	logger.Infof("Restoring the database from %v", fullDir)
+	if err := linkOrCopyDir(path.Join(fullDir, backupMainDir), mainDBPath); err != nil {
+		return err
+	}
+	if err := linkOrCopyDir(path.Join(fullDir, backupRefDir), refDBPath); err != nil {
+		return err
+	}
+
+	if manifest.Type == BackupTypeIncremental {
+		logger.Infof("Applying the incremental backup %v", backupDir)
+		if err := applyIncrementalBackup(path.Join(backupDir, backupMainDir), mainDBPath); err != nil {
+			return err
+		}
+		if err := applyIncrementalBackup(path.Join(backupDir, backupRefDir), refDBPath); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("Database restored from %v", backupDir)
+	return nil
+}
+
+// applyIncrementalBackup applies the changes recorded in the incremental backup instance to the database
+func applyIncrementalBackup(incDir string, dbDir string) error {
+	inc, err := leveldb.OpenFile(incDir, &opt.Options{ReadOnly: true, ErrorIfMissing: true})
+	if err != nil {
+		return err
+	}
+	defer inc.Close()
+
+	db, err := leveldb.OpenFile(dbDir, &opt.Options{ErrorIfMissing: true})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	it := inc.NewIterator(nil, nil)
+	defer it.Release()
+
+	batch := new(leveldb.Batch)
+	for it.Next() {
+		value := it.Value()
+		switch {
+		case len(value) == 1 && value[0] == backupDeleted:
+			batch.Delete(it.Key())
+		case len(value) > 0 && value[0] == backupPut:
+			batch.Put(it.Key(), value[1:])
+		default:
+			return fmt.Errorf("Malformed incremental backup entry %x", it.Key())
+		}
+		if batch.Len() >= 1000 {
+			if err := db.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return db.Write(batch, nil)
+}
+
+// linkOrCopyDir recreates the LevelDB instance in src at dst. The immutable table files are hard-linked,
+// and the rest, e.g. the manifest and the journal, are copied.
+func linkOrCopyDir(src, dst string) error {
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return err
+	}
+	infos, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if info.IsDir() || info.Name() == "LOCK" {
+			continue
+		}
+		srcFile, dstFile := filepath.Join(src, info.Name()), filepath.Join(dst, info.Name())
+		ext := filepath.Ext(info.Name())
+		if ext == ".ldb" || ext == ".sst" {
+			if err := os.Link(srcFile, dstFile); err == nil {
+				continue
+			}
+		}
+		if err := copyFile(srcFile, dstFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
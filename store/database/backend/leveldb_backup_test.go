@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/store"
+)
+
+func TestLDBBackupAndRestore(t *testing.T) {
+	assert := assert.New(t)
+
+	db, remove := newTestLDB()
+	defer remove()
+
+	dir, err := ioutil.TempDir("", "ldb_backup_test_")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	assert.Nil(db.Put([]byte("a"), []byte("1")))
+	assert.Nil(db.Put([]byte("b"), []byte("2")))
+	assert.Nil(db.Put([]byte("c"), []byte("3")))
+	assert.Nil(db.Reference([]byte("a")))
+
+	fullDir := path.Join(dir, "full")
+	manifest, err := db.Backup(fullDir, "")
+	assert.Nil(err)
+	assert.Equal(BackupTypeFull, manifest.Type)
+	assert.Equal(uint64(3), manifest.NumEntries)
+	assert.Equal(uint64(1), manifest.NumRefs)
+
+	// Changes after the full backup
+	assert.Nil(db.Put([]byte("b"), []byte("20")))
+	assert.Nil(db.Delete([]byte("c")))
+	assert.Nil(db.Put([]byte("d"), []byte("4")))
+
+	incDir := path.Join(dir, "inc")
+	manifest, err = db.Backup(incDir, fullDir)
+	assert.Nil(err)
+	assert.Equal(BackupTypeIncremental, manifest.Type)
+	assert.Equal(uint64(3), manifest.NumEntries)
+
+	_, err = db.Backup(path.Join(dir, "inc2"), incDir)
+	assert.NotNil(err)
+
+	// Restore the full backup
+	restored := path.Join(dir, "restored_full")
+	assert.Nil(RestoreBackup(fullDir, path.Join(restored, "main"), path.Join(restored, "ref")))
+	rdb, err := NewLDBDatabase(path.Join(restored, "main"), path.Join(restored, "ref"), 0, 0)
+	assert.Nil(err)
+	v, err := rdb.Get([]byte("b"))
+	assert.Nil(err)
+	assert.Equal([]byte("2"), v)
+	_, err = rdb.Get([]byte("d"))
+	assert.Equal(store.ErrKeyNotFound, err)
+	ref, err := rdb.CountReference([]byte("a"))
+	assert.Nil(err)
+	assert.Equal(1, ref)
+	rdb.Close()
+
+	// Restore the incremental backup on top of the full backup
+	restored = path.Join(dir, "restored_inc")
+	assert.Nil(RestoreBackup(incDir, path.Join(restored, "main"), path.Join(restored, "ref")))
+	rdb, err = NewLDBDatabase(path.Join(restored, "main"), path.Join(restored, "ref"), 0, 0)
+	assert.Nil(err)
+	defer rdb.Close()
+	v, err = rdb.Get([]byte("b"))
+	assert.Nil(err)
+	assert.Equal([]byte("20"), v)
+	_, err = rdb.Get([]byte("c"))
+	assert.Equal(store.ErrKeyNotFound, err)
+	v, err = rdb.Get([]byte("d"))
+	assert.Nil(err)
+	assert.Equal([]byte("4"), v)
+
+	// The base full backup is not affected
+	manifest, err = ReadBackupManifest(fullDir)
+	assert.Nil(err)
+	assert.Equal(BackupTypeFull, manifest.Type)
+	assert.NotNil(RestoreBackup(fullDir, path.Join(restored, "main"), path.Join(restored, "ref")))
+}
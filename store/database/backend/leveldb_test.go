@@ -496,3 +496,50 @@ func testParallelPutGet(db database.Database, t *testing.T) {
 	}
 	pending.Wait()
 }
+
+func TestLDB_IterateWithPrefix(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testIterateWithPrefix(db, db, t)
+}
+
+func TestMemoryDB_IterateWithPrefix(t *testing.T) {
+	memDB := NewMemDatabase()
+	testIterateWithPrefix(memDB, memDB, t)
+}
+
+func testIterateWithPrefix(db database.Database, iterable database.PrefixIterable, t *testing.T) {
+	for _, k := range []string{"p/2", "p/1", "q/1", "p", "p/3"} {
+		if err := db.Put([]byte(k), []byte("v"+k)); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+	}
+
+	keys := []string{}
+	err := iterable.IterateWithPrefix([]byte("p/"), func(key, value []byte) bool {
+		if string(value) != "v"+string(key) {
+			t.Fatalf("wrong value for %q: %q", key, value)
+		}
+		keys = append(keys, string(key))
+		return true
+	})
+	if err != nil {
+		t.Fatalf("iterate failed: %v", err)
+	}
+	if fmt.Sprint(keys) != "[p/1 p/2 p/3]" {
+		t.Fatalf("wrong keys: %v", keys)
+	}
+
+	// The iteration stops when f returns false
+	keys = []string{}
+	err = iterable.IterateWithPrefix([]byte("p/"), func(key, value []byte) bool {
+		keys = append(keys, string(key))
+		return false
+	})
+	if err != nil {
+		t.Fatalf("iterate failed: %v", err)
+	}
+	if fmt.Sprint(keys) != "[p/1]" {
+		t.Fatalf("wrong keys: %v", keys)
+	}
+}
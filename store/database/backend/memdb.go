@@ -18,6 +18,8 @@
 package backend
 
 import (
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/thetatoken/theta/common"
@@ -85,6 +87,30 @@ func (db *MemDatabase) Keys() [][]byte {
 	return keys
 }
 
+// IterateWithPrefix calls f for the keys with the given prefix in the ascending order, until f returns false.
+func (db *MemDatabase) IterateWithPrefix(prefix []byte, f func(key, value []byte) bool) error {
+	db.lock.RLock()
+	keys := []string{}
+	for key := range db.db {
+		if strings.HasPrefix(key, string(prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	db.lock.RUnlock()
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, err := db.Get([]byte(key))
+		if err == store.ErrKeyNotFound {
+			continue // deleted since the keys were collected
+		}
+		if !f([]byte(key), value) {
+			break
+		}
+	}
+	return nil
+}
+
 func (db *MemDatabase) Delete(key []byte) error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
@@ -54,6 +54,13 @@ type Database interface {
 	NewBatch() Batch
 }
 
+// PrefixIterable is implemented by the databases which can iterate over the keys with a given prefix, in
+// the ascending order of the keys, until f returns false. The key and the value passed to f are only valid
+// during the call.
+type PrefixIterable interface {
+	IterateWithPrefix(prefix []byte, f func(key, value []byte) bool) error
+}
+
 // Batch is a write-only database that commits changes to its host database
 // when Write is called. Batch cannot be used concurrently.
 type Batch interface {
@@ -3,3 +3,5 @@ package store
 import "errors"
 
 var ErrKeyNotFound = errors.New("KeyNotFound")
+
+var ErrIterationNotSupported = errors.New("IterationNotSupported")
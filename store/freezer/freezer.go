@@ -0,0 +1,354 @@
+// Package freezer implements the ancient store, an append-only flat-file store of the items that are no longer
+// modified, e.g. the finalized blocks far behind the tip. Moving them out of the key-value store keeps the hot
+// DB small and spares it the compactions of the data that is only ever read.
+//
+// The items are numbered consecutively from the offset of the freezer, and each item has an entry in each of
+// the tables. A table consists of a data file holding the concatenated entries, and an index file holding the
+// end offset of each entry in the data file as an 8-byte big-endian integer.
+package freezer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	offsetFileName = "OFFSET"
+	indexEntrySize = 8
+)
+
+var (
+	// ErrNotFound is returned when the requested item has not been frozen
+	ErrNotFound = errors.New("item not found in the freezer")
+	// ErrOutOfOrder is returned when an item is appended out of order
+	ErrOutOfOrder = errors.New("items must be appended in order")
+	// ErrClosed is returned when the freezer is used after it is closed
+	ErrClosed = errors.New("freezer is closed")
+)
+
+// Freezer is the append-only flat-file store of the ancient items
+type Freezer struct {
+	mu     *sync.RWMutex
+	dir    string
+	offset uint64 // number of the first item
+	items  uint64 // number of the items frozen
+	tables map[string]*table
+	closed bool
+}
+
+// Open opens the freezer with the given tables under the given directory, creating it if it does not exist. The
+// offset is the number of the first item of a new freezer, an existing freezer keeps its own offset. The tables
+// are truncated to the items fully written to all of them, which drops the item being appended during a crash.
+func Open(dir string, tableNames []string, offset uint64) (*Freezer, error) {
+	if len(tableNames) == 0 {
+		return nil, errors.New("the freezer needs at least one table")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	offset, err := loadOffset(dir, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Freezer{
+		mu:     &sync.RWMutex{},
+		dir:    dir,
+		offset: offset,
+		tables: make(map[string]*table),
+	}
+	first := true
+	for _, name := range tableNames {
+		t, err := openTable(dir, name)
+		if err != nil {
+			f.closeTables()
+			return nil, err
+		}
+		f.tables[name] = t
+		if first || t.items < f.items {
+			f.items = t.items
+		}
+		first = false
+	}
+	for _, t := range f.tables {
+		if err := t.truncate(f.items); err != nil {
+			f.closeTables()
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func loadOffset(dir string, offset uint64) (uint64, error) {
+	path := filepath.Join(dir, offsetFileName)
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		raw = make([]byte, 8)
+		binary.BigEndian.PutUint64(raw, offset)
+		return offset, ioutil.WriteFile(path, raw, 0600)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(raw) != 8 {
+		return 0, fmt.Errorf("corrupted freezer offset file %v", path)
+	}
+	return binary.BigEndian.Uint64(raw), nil
+}
+
+// Offset returns the number of the first item
+func (f *Freezer) Offset() uint64 {
+	return f.offset
+}
+
+// Frontier returns the number of the next item to append
+func (f *Freezer) Frontier() uint64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.offset + f.items
+}
+
+// Has returns whether the given item has been frozen
+func (f *Freezer) Has(number uint64) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return number >= f.offset && number < f.offset+f.items
+}
+
+// Retrieve returns the entry of the given item in the given table
+func (f *Freezer) Retrieve(tableName string, number uint64) ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.closed {
+		return nil, ErrClosed
+	}
+	t, ok := f.tables[tableName]
+	if !ok {
+		return nil, fmt.Errorf("unknown freezer table %v", tableName)
+	}
+	if number < f.offset || number >= f.offset+f.items {
+		return nil, ErrNotFound
+	}
+	return t.retrieve(number - f.offset)
+}
+
+// Append appends the given item, with an entry for each table. The number must be the frontier of the
+// freezer. The entries are not durable until Sync is called.
+func (f *Freezer) Append(number uint64, entries map[string][]byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return ErrClosed
+	}
+	if number != f.offset+f.items {
+		return ErrOutOfOrder
+	}
+	if len(entries) != len(f.tables) {
+		return fmt.Errorf("expected %v entries, got %v", len(f.tables), len(entries))
+	}
+	for name := range entries {
+		if _, ok := f.tables[name]; !ok {
+			return fmt.Errorf("unknown freezer table %v", name)
+		}
+	}
+	for name, entry := range entries {
+		if err := f.tables[name].append(entry); err != nil {
+			// Drop the entries appended to the other tables, so that the tables stay aligned
+			for _, t := range f.tables {
+				t.truncate(f.items)
+			}
+			return err
+		}
+	}
+	f.items++
+	return nil
+}
+
+// Sync flushes the appended items to the disk
+func (f *Freezer) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return ErrClosed
+	}
+	for _, t := range f.tables {
+		if err := t.sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close syncs and closes the tables
+func (f *Freezer) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	var err error
+	for _, t := range f.tables {
+		if e := t.sync(); e != nil && err == nil {
+			err = e
+		}
+	}
+	if e := f.closeTables(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+func (f *Freezer) closeTables() error {
+	var err error
+	for _, t := range f.tables {
+		if e := t.close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// table is a data file with the concatenated entries, and an index file with the end offsets of the entries
+type table struct {
+	data  *os.File
+	index *os.File
+	items uint64
+	size  uint64 // end offset of the last entry
+}
+
+func openTable(dir, name string) (*table, error) {
+	data, err := os.OpenFile(filepath.Join(dir, name+".dat"), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	index, err := os.OpenFile(filepath.Join(dir, name+".idx"), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		data.Close()
+		return nil, err
+	}
+	t := &table{data: data, index: index}
+	if err := t.repair(); err != nil {
+		t.close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// repair drops a partially written index entry, and the index entries beyond the end of the data file
+func (t *table) repair() error {
+	indexInfo, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	dataInfo, err := t.data.Stat()
+	if err != nil {
+		return err
+	}
+	items := uint64(indexInfo.Size()) / indexEntrySize
+	for ; items > 0; items-- {
+		end, err := t.readIndex(items - 1)
+		if err != nil {
+			return err
+		}
+		if end <= uint64(dataInfo.Size()) {
+			break
+		}
+	}
+	return t.truncate(items)
+}
+
+// truncate drops the entries from the given one on
+func (t *table) truncate(items uint64) error {
+	size := uint64(0)
+	if items > 0 {
+		end, err := t.readIndex(items - 1)
+		if err != nil {
+			return err
+		}
+		size = end
+	}
+	if err := t.index.Truncate(int64(items * indexEntrySize)); err != nil {
+		return err
+	}
+	if err := t.data.Truncate(int64(size)); err != nil {
+		return err
+	}
+	t.items, t.size = items, size
+	return nil
+}
+
+func (t *table) readIndex(i uint64) (uint64, error) {
+	raw := make([]byte, indexEntrySize)
+	if _, err := t.index.ReadAt(raw, int64(i*indexEntrySize)); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(raw), nil
+}
+
+func (t *table) retrieve(i uint64) ([]byte, error) {
+	if i >= t.items {
+		return nil, ErrNotFound
+	}
+	start := uint64(0)
+	if i > 0 {
+		end, err := t.readIndex(i - 1)
+		if err != nil {
+			return nil, err
+		}
+		start = end
+	}
+	end, err := t.readIndex(i)
+	if err != nil {
+		return nil, err
+	}
+	if end < start {
+		return nil, fmt.Errorf("corrupted freezer index at entry %v", i)
+	}
+	entry := make([]byte, end-start)
+	if _, err := t.data.ReadAt(entry, int64(start)); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// append writes the entry to the data file before its end offset to the index file, so that the index never
+// points beyond the data
+func (t *table) append(entry []byte) error {
+	if _, err := t.data.WriteAt(entry, int64(t.size)); err != nil {
+		return err
+	}
+	raw := make([]byte, indexEntrySize)
+	binary.BigEndian.PutUint64(raw, t.size+uint64(len(entry)))
+	if _, err := t.index.WriteAt(raw, int64(t.items*indexEntrySize)); err != nil {
+		return err
+	}
+	t.items++
+	t.size += uint64(len(entry))
+	return nil
+}
+
+func (t *table) sync() error {
+	if err := t.data.Sync(); err != nil {
+		return err
+	}
+	return t.index.Sync()
+}
+
+func (t *table) close() error {
+	err := t.data.Close()
+	if e := t.index.Close(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package freezer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreezer(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "freezer")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+
+	tables := []string{"blocks", "receipts"}
+	f, err := Open(dir, tables, 100)
+	require.Nil(err)
+	assert.Equal(uint64(100), f.Frontier())
+
+	assert.Equal(ErrOutOfOrder, f.Append(99, map[string][]byte{"blocks": {1}, "receipts": {2}}))
+	require.Nil(f.Append(100, map[string][]byte{"blocks": []byte("block100"), "receipts": {}}))
+	require.Nil(f.Append(101, map[string][]byte{"blocks": []byte("block101"), "receipts": []byte("receipts101")}))
+	assert.NotNil(f.Append(102, map[string][]byte{"blocks": {1}}))
+	require.Nil(f.Sync())
+
+	assert.True(f.Has(101))
+	assert.False(f.Has(99))
+	assert.False(f.Has(102))
+	entry, err := f.Retrieve("blocks", 100)
+	require.Nil(err)
+	assert.Equal([]byte("block100"), entry)
+	entry, err = f.Retrieve("receipts", 100)
+	require.Nil(err)
+	assert.Equal(0, len(entry))
+	entry, err = f.Retrieve("receipts", 101)
+	require.Nil(err)
+	assert.Equal([]byte("receipts101"), entry)
+	_, err = f.Retrieve("blocks", 102)
+	assert.Equal(ErrNotFound, err)
+	require.Nil(f.Close())
+
+	// Simulate a crash while appending item 102: the entry reached one table only, and the last index entry
+	// of the other table is partially written
+	data, err := os.OpenFile(filepath.Join(dir, "blocks.dat"), os.O_WRONLY|os.O_APPEND, 0600)
+	require.Nil(err)
+	data.Write([]byte("block102"))
+	data.Close()
+	index, err := os.OpenFile(filepath.Join(dir, "blocks.idx"), os.O_WRONLY|os.O_APPEND, 0600)
+	require.Nil(err)
+	index.Write([]byte{0, 0, 0, 0, 0, 0, 0, 24})
+	index.Close()
+	index, err = os.OpenFile(filepath.Join(dir, "receipts.idx"), os.O_WRONLY|os.O_APPEND, 0600)
+	require.Nil(err)
+	index.Write([]byte{0, 0, 0})
+	index.Close()
+
+	// The existing freezer keeps its offset, and the tables are truncated to the complete items
+	f, err = Open(dir, tables, 0)
+	require.Nil(err)
+	defer f.Close()
+	assert.Equal(uint64(100), f.Offset())
+	assert.Equal(uint64(102), f.Frontier())
+	require.Nil(f.Append(102, map[string][]byte{"blocks": []byte("new102"), "receipts": []byte("receipts102")}))
+	entry, err = f.Retrieve("blocks", 102)
+	require.Nil(err)
+	assert.Equal([]byte("new102"), entry)
+	entry, err = f.Retrieve("blocks", 101)
+	require.Nil(err)
+	assert.Equal([]byte("block101"), entry)
+}
@@ -9,4 +9,20 @@ type Store interface {
 	Put(key common.Bytes, value interface{}) error
 	Delete(key common.Bytes) error
 	Get(key common.Bytes, value interface{}) error
+	NewBatch() Batch
+}
+
+// PrefixIterator is implemented by the stores which can iterate over the entries whose keys start with a
+// given prefix. The entries are visited in the ascending order of the keys until f returns false, and
+// decode decodes the value of the visited entry.
+type PrefixIterator interface {
+	IterateWithPrefix(prefix common.Bytes, f func(key common.Bytes, decode func(value interface{}) error) bool) error
+}
+
+// Batch groups the writes to a Store, which are committed atomically when Write is called. Batch
+// cannot be used concurrently.
+type Batch interface {
+	Put(key common.Bytes, value interface{}) error
+	Delete(key common.Bytes) error
+	Write() error
 }
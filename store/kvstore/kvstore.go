@@ -12,6 +12,9 @@ func NewKVStore(db database.Database) store.Store {
 	return &KVStore{db}
 }
 
+// errIterationNotSupported is store.ErrIterationNotSupported, which the receivers named store shadow
+var errIterationNotSupported = store.ErrIterationNotSupported
+
 // KVStore a Database wrapped object.
 type KVStore struct {
 	db database.Database
@@ -39,3 +42,46 @@ func (store *KVStore) Get(key common.Bytes, value interface{}) error {
 	}
 	return rlp.DecodeBytes(encodedValue, value)
 }
+
+// IterateWithPrefix iterates over the entries whose keys start with the given prefix, in the ascending order
+// of the keys. ErrIterationNotSupported is returned if the DB cannot iterate over its keys.
+func (store *KVStore) IterateWithPrefix(prefix common.Bytes, f func(key common.Bytes, decode func(value interface{}) error) bool) error {
+	db, ok := store.db.(database.PrefixIterable)
+	if !ok {
+		return errIterationNotSupported
+	}
+	return db.IterateWithPrefix(prefix, func(key, value []byte) bool {
+		return f(key, func(v interface{}) error {
+			return rlp.DecodeBytes(value, v)
+		})
+	})
+}
+
+// NewBatch creates a batch of writes to the DB
+func (store *KVStore) NewBatch() store.Batch {
+	return &KVBatch{store.db.NewBatch()}
+}
+
+// KVBatch a Batch wrapped object.
+type KVBatch struct {
+	batch database.Batch
+}
+
+// Put upserts key/value into the batch
+func (b *KVBatch) Put(key common.Bytes, value interface{}) error {
+	encodedValue, err := rlp.EncodeToBytes(value)
+	if err != nil {
+		return err
+	}
+	return b.batch.Put(key, encodedValue)
+}
+
+// Delete deletes key entry in the batch
+func (b *KVBatch) Delete(key common.Bytes) error {
+	return b.batch.Delete(key)
+}
+
+// Write commits the batch to DB
+func (b *KVBatch) Write() error {
+	return b.batch.Write()
+}
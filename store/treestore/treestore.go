@@ -64,6 +64,18 @@ func (store *TreeStore) Revert(root common.Hash) (*TreeStore, error) {
 	return revertedStore, nil
 }
 
+// OpenTrie creates a TreeStore for the trie with the given root on the in-memory
+// trie DB of the current Trie, e.g. the storage trie of an account in the state
+// trie. The tries share the nodes not committed to the disk yet, and committing
+// the current Trie also persists the tries referenced by its values.
+func (store *TreeStore) OpenTrie(root common.Hash) (*TreeStore, error) {
+	tr, err := trie.New(root, store.Trie.GetDB())
+	if err != nil {
+		return nil, err
+	}
+	return &TreeStore{tr, store.db}, nil
+}
+
 // Copy returns a copy of the TreeStore
 func (store *TreeStore) Copy() (*TreeStore, error) {
 	store.Trie.Commit(nil)
@@ -52,6 +52,15 @@ var secureKeyPrefix = []byte("secure-key-")
 // secureKeyLength is the length of the above prefix + 32byte hash.
 const secureKeyLength = 11 + 32
 
+// refLock serializes the commits and the prunes of the tries. A commit references the nodes already on the disk
+// instead of writing them again, so a concurrent prune must not delete a node between the two.
+var refLock sync.Mutex
+
+// ExternalRefs returns the roots of the other tries a value stored in a trie refers to, e.g. the root of the
+// storage trie of an account stored in the state trie. A node holding the value references these roots like
+// its children, so that the tries are persisted and pruned along with the node.
+type ExternalRefs func(value []byte) []common.Hash
+
 // DatabaseReader wraps the Get and Has method of a backing store for the trie.
 type DatabaseReader interface {
 	// Get retrieves the value associated with key from the database.
@@ -72,6 +81,7 @@ type Database struct {
 	newest common.Hash                 // Newest tracked node, flush-list tail
 
 	preimages map[common.Hash][]byte // Preimages of nodes from the secure trie
+	externals ExternalRefs           // Resolver of the tries referenced by the values, nil if none
 	seckeybuf [secureKeyLength]byte  // Ephemeral buffer for calculating preimage keys
 
 	gctime  time.Duration // Time spent on garbage collection since last commit
@@ -202,6 +212,22 @@ func gatherChildren(n node, children *[]common.Hash) {
 	}
 }
 
+// gatherValues traverses the node hierarchy of a collapsed storage node and
+// retrieves all the values stored in the node itself, i.e. not in its children.
+func gatherValues(n node, values *[]valueNode) {
+	switch n := n.(type) {
+	case *rawShortNode:
+		gatherValues(n.Val, values)
+
+	case rawFullNode:
+		for i := 0; i < len(n); i++ {
+			gatherValues(n[i], values)
+		}
+	case valueNode:
+		*values = append(*values, n)
+	}
+}
+
 // simplifyNode traverses the hierarchy of an expanded memory node and discards
 // all the internal caches, returning a node that only contains the raw data.
 func simplifyNode(n node) node {
@@ -276,6 +302,15 @@ func NewDatabase(diskdb database.Database) *Database {
 	}
 }
 
+// SetExternalRefs sets the resolver of the tries referenced by the values stored in the tries. It must be set
+// before any node is inserted, since the references are resolved when the nodes are cached.
+func (db *Database) SetExternalRefs(externals ExternalRefs) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.externals = externals
+}
+
 // DiskDB retrieves the persistent storage backing the trie database.
 func (db *Database) DiskDB() DatabaseReader {
 	return db.diskdb
@@ -307,6 +342,18 @@ func (db *Database) insert(hash common.Hash, blob []byte, node node) {
 		size:      uint16(len(blob)),
 		flushPrev: db.newest,
 	}
+	if db.externals != nil {
+		values := []valueNode{}
+		gatherValues(entry.node, &values)
+		for _, value := range values {
+			for _, root := range db.externals(value) {
+				if entry.children == nil {
+					entry.children = make(map[common.Hash]uint16)
+				}
+				entry.children[root]++
+			}
+		}
+	}
 	for _, child := range entry.childs() {
 		if c := db.nodes[child]; c != nil {
 			c.parents++
@@ -610,6 +657,8 @@ func (db *Database) Commit(node common.Hash, report bool) error {
 	// outside code doesn't see an inconsistent state (referenced data removed from
 	// memory cache during commit but not yet in persistent storage). This is ensured
 	// by only uncaching existing data when the database write finalizes.
+	refLock.Lock()
+	defer refLock.Unlock()
 	db.lock.RLock()
 
 	start := time.Now()
@@ -631,7 +680,7 @@ func (db *Database) Commit(node common.Hash, report bool) error {
 	}
 	// Move the trie itself into the batch, flushing if enough data is accumulated
 	nodes, storage := len(db.nodes), db.nodesSize
-	if err := db.commit(node, batch); err != nil {
+	if err := db.commit(node, batch, make(map[common.Hash]struct{})); err != nil {
 		logger.Error("Failed to commit trie from trie database", "err", err)
 		db.lock.RUnlock()
 		return err
@@ -667,11 +716,12 @@ func (db *Database) Commit(node common.Hash, report bool) error {
 	return nil
 }
 
-// commit is the private locked version of Commit.
-func (db *Database) commit(hash common.Hash, batch database.Batch) error {
-	//ref, _ := db.diskdb.CountReference(hash[:])
-	//logger.Debugf("Database.commit, ref: %v, hash: %v", ref, hash.Hex())
-
+// commit is the private locked version of Commit. The reference count of a node
+// on the disk is the number of the roots committed at it, plus the number of its
+// parents, i.e. the nodes on the disk holding it as a child or referencing it
+// through their values. A node already on the disk, e.g. recreated by an update
+// reverted later, is only referenced, since its children are referenced already.
+func (db *Database) commit(hash common.Hash, batch database.Batch, written map[common.Hash]struct{}) error {
 	// update reference count
 	batch.Reference(hash[:])
 
@@ -680,8 +730,23 @@ func (db *Database) commit(hash common.Hash, batch database.Batch) error {
 	if !ok {
 		return nil
 	}
+	if _, ok := written[hash]; ok {
+		return nil
+	}
+	written[hash] = struct{}{}
+	has, err := db.diskdb.Has(hash[:])
+	if err != nil {
+		return err
+	}
+	if has {
+		return nil
+	}
 	for _, child := range node.childs() {
-		if err := db.commit(child, batch); err != nil {
+		// An external trie is referenced once for each value of the node referring to it
+		for i := uint16(1); i < node.children[child]; i++ {
+			batch.Reference(child[:])
+		}
+		if err := db.commit(child, batch, written); err != nil {
 			return err
 		}
 	}
@@ -500,13 +500,20 @@ func (t *Trie) hashRoot(db *Database, onleaf LeafCallback) (node, node, error) {
 	return h.hash(t.root, db, true)
 }
 
-// Prune deletes all non-referenced nodes of the Trie from DB
+// Prune releases the reference of the root of the Trie, and deletes the nodes
+// no longer referenced from the DB, along with the tries referenced by their
+// values. The callback, if any, is called with each value of the deleted nodes.
 func (t *Trie) Prune(cb func(n []byte) bool) error {
+	refLock.Lock()
+	defer refLock.Unlock()
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
 	logger.Debugf("Trie.Prune, t.originalRoot: %v, t.root: %v", t.originalRoot.Hex(), t.root)
 
+	if t.root == nil {
+		return nil
+	}
 	err := t.pruneNode(t.root, cb)
 	if err != nil {
 		logger.Warnf("Trie.Prune error: %v", err)
@@ -522,10 +529,10 @@ func (t *Trie) Prune(cb func(n []byte) bool) error {
 func (t *Trie) pruneNode(n node, cb func(n []byte) bool) error {
 	hash, _ := n.cache()
 	if hash == nil {
-		return nil
+		// The node is embedded in its parent, which is being deleted
+		return t.pruneChildren(n, cb)
 	}
 	ref, err := t.db.diskdb.CountReference(hash[:])
-	//logger.Debugf("Trie.pruneNode, ref: %v, hash: %v", ref, hash)
 	if err != nil {
 		if err == store.ErrKeyNotFound {
 			return nil
@@ -533,7 +540,6 @@ func (t *Trie) pruneNode(n node, cb func(n []byte) bool) error {
 		return err
 	}
 	if ref > 1 {
-		//logger.Debugf("Trie.pruneNode, deference node, ref: %v, hash: %v", ref, hash)
 		return t.db.diskdb.Dereference(hash[:])
 	}
 
@@ -545,71 +551,49 @@ func (t *Trie) pruneNode(n node, cb func(n []byte) bool) error {
 	if err != nil && err != store.ErrKeyNotFound {
 		return err
 	}
-	//logger.Debugf("Trie.pruneNode, delete node, hash: %v", hash)
 	return nil
 }
 
 func (t *Trie) pruneChildren(nd node, cb func(n []byte) bool) error {
-	var err error
 	switch n := nd.(type) {
 	case *shortNode:
-		if _, ok := n.Val.(valueNode); !ok {
-			switch m := n.Val.(type) {
-			case valueNode:
-				if cb != nil {
-					cb(n.Val.(valueNode))
-				}
-			case hashNode:
-				childNode := t.db.node(common.BytesToHash(m[:]), 0)
-				err = t.pruneNode(childNode, cb)
-				if err != nil {
-					return err
-				}
-			case *shortNode:
-				t.pruneNode(m, cb)
-			case *fullNode:
-				t.pruneNode(m, cb)
-			default:
-			}
-		} else {
-			if cb != nil {
-				cb(n.Val.(valueNode))
+		return t.pruneChild(n.Val, cb)
+	case *fullNode:
+		for i := 0; i < len(n.Children); i++ {
+			if err := t.pruneChild(n.Children[i], cb); err != nil {
+				return err
 			}
 		}
-	case *fullNode:
-		for i := 0; i < 16; i++ {
-			if n.Children[i] != nil {
-				switch m := n.Children[i].(type) {
-				case valueNode:
-					if cb != nil {
-						cb(n.Children[i].(valueNode))
-					}
-				case hashNode:
-					hashNode := n.Children[i].(hashNode)
-					childNode := t.db.node(common.BytesToHash(hashNode[:]), 0)
-					err = t.pruneNode(childNode, cb)
-					if err != nil {
-						return err
-					}
-				case *shortNode:
-					if _, ok := m.Val.(valueNode); !ok {
-						hashNode := m.Val.(hashNode)
-						childNode := t.db.node(common.BytesToHash(hashNode[:]), 0)
-						err = t.pruneNode(childNode, cb)
-						if err != nil {
-							return err
-						}
-					}
-				case *fullNode:
-					return t.pruneNode(m, cb)
-				default:
-				}
+	}
+	return nil
+}
+
+func (t *Trie) pruneChild(child node, cb func(n []byte) bool) error {
+	switch c := child.(type) {
+	case nil:
+		return nil
+	case valueNode:
+		if cb != nil {
+			cb(c)
+		}
+		if t.db.externals == nil {
+			return nil
+		}
+		for _, root := range t.db.externals(c) {
+			if err := t.pruneChild(hashNode(root[:]), nil); err != nil {
+				return err
 			}
 		}
+		return nil
+	case hashNode:
+		childNode := t.db.node(common.BytesToHash(c), 0)
+		if childNode == nil {
+			return nil // deleted already
+		}
+		return t.pruneNode(childNode, cb)
 	default:
+		return t.pruneNode(child, cb)
 	}
-
-	return nil
 }
 
 func FmtNode(node node, ind string, level int, db database.Database, cb func([]byte) string) string {
@@ -430,6 +430,73 @@ func (db *countingDB) Get(key []byte) ([]byte, error) {
 
 // TestCacheUnload checks that decoded nodes are unloaded after a
 // certain number of commit operations.
+func TestPruneSharedNodes(t *testing.T) {
+	diskdb := dbbackend.NewMemDatabase()
+	commit := func(root common.Hash, updates map[string]string) common.Hash {
+		triedb := NewDatabase(diskdb)
+		trie, err := New(root, triedb)
+		if err != nil {
+			t.Fatalf("failed to open trie %x: %v", root, err)
+		}
+		for k, v := range updates {
+			trie.Update([]byte(k), []byte(v))
+		}
+		hash, err := trie.Commit(nil)
+		if err != nil {
+			t.Fatalf("failed to commit trie: %v", err)
+		}
+		if err := triedb.Commit(hash, false); err != nil {
+			t.Fatalf("failed to write trie: %v", err)
+		}
+		return hash
+	}
+	prune := func(root common.Hash) {
+		trie, err := New(root, NewDatabase(diskdb))
+		if err != nil {
+			t.Fatalf("failed to open trie %x: %v", root, err)
+		}
+		if err := trie.Prune(nil); err != nil {
+			t.Fatalf("failed to prune trie %x: %v", root, err)
+		}
+	}
+
+	// The short values are embedded in their parents
+	base := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		base[fmt.Sprintf("key%03d", i)] = fmt.Sprintf("value%d", i)
+	}
+	root1 := commit(common.Hash{}, base)
+	root2 := commit(root1, map[string]string{"key042": "updated"})
+	// Reverting the update recreates the nodes of root1, which are on the disk already
+	root3 := commit(root2, map[string]string{"key042": "value42"})
+	if root3 != root1 {
+		t.Fatalf("root mismatch after revert: have %x, want %x", root3, root1)
+	}
+
+	prune(root1)
+	for _, root := range []common.Hash{root2, root3} {
+		trie, err := New(root, NewDatabase(diskdb))
+		if err != nil {
+			t.Fatalf("trie %x pruned with the other root: %v", root, err)
+		}
+		for k, v := range base {
+			if root == root2 && k == "key042" {
+				v = "updated"
+			}
+			if have, err := trie.TryGet([]byte(k)); err != nil || string(have) != v {
+				t.Errorf("trie %x, key %v: have %q (%v), want %q", root, k, have, err, v)
+			}
+		}
+	}
+
+	// No node is left once all the roots are pruned
+	prune(root2)
+	prune(root3)
+	if diskdb.Len() != 0 {
+		t.Errorf("%v nodes left after pruning all the roots", diskdb.Len())
+	}
+}
+
 func TestCacheUnload(t *testing.T) {
 	// Create test trie with two branches.
 	trie := newEmpty()
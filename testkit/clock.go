@@ -0,0 +1,157 @@
+package testkit
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thetatoken/theta/common/timer"
+)
+
+var _ timer.Clock = (*SimClock)(nil)
+
+// SimClock is a simulated clock shared by the nodes of a cluster. The time only moves when the cluster
+// advances it, and the timers fire in the order of their deadlines, then of their creation.
+type SimClock struct {
+	mu     *sync.Mutex
+	now    time.Time
+	timers []*simTimer
+	seq    uint64
+}
+
+// NewSimClock creates a simulated clock starting at the given time.
+func NewSimClock(start time.Time) *SimClock {
+	return &SimClock{
+		mu:  &sync.Mutex{},
+		now: start,
+	}
+}
+
+// Now implements the timer.Clock interface.
+func (c *SimClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer implements the timer.Clock interface.
+func (c *SimClock) NewTimer(d time.Duration) timer.Timer {
+	return c.addTimer(d, 0)
+}
+
+// NewTicker implements the timer.Clock interface.
+func (c *SimClock) NewTicker(d time.Duration) timer.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return &simTicker{c.addTimer(d, d)}
+}
+
+func (c *SimClock) addTimer(d, period time.Duration) *simTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+	t := &simTimer{
+		clock:    c,
+		ch:       make(chan time.Time, 1),
+		deadline: c.now.Add(d),
+		period:   period,
+		seq:      c.seq,
+	}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// NextDeadline returns the deadline of the next timer to fire, if any.
+func (c *SimClock) NextDeadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.timers) == 0 {
+		return time.Time{}, false
+	}
+	c.sortTimers()
+	return c.timers[0].deadline, true
+}
+
+// AdvanceTo moves the clock forward to the given time, and fires the timers whose deadlines have passed.
+// Like the timers of the time package, a timer whose channel is full drops the tick.
+func (c *SimClock) AdvanceTo(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.timers) > 0 {
+		c.sortTimers()
+		next := c.timers[0]
+		if next.deadline.After(t) {
+			break
+		}
+		if next.deadline.After(c.now) {
+			c.now = next.deadline
+		}
+		select {
+		case next.ch <- c.now:
+		default:
+		}
+		if next.period > 0 {
+			next.deadline = next.deadline.Add(next.period)
+		} else {
+			c.timers = c.timers[1:]
+		}
+	}
+	if t.After(c.now) {
+		c.now = t
+	}
+}
+
+// Advance moves the clock forward by the given duration.
+func (c *SimClock) Advance(d time.Duration) {
+	c.AdvanceTo(c.Now().Add(d))
+}
+
+func (c *SimClock) sortTimers() {
+	sort.Slice(c.timers, func(i, j int) bool {
+		if !c.timers[i].deadline.Equal(c.timers[j].deadline) {
+			return c.timers[i].deadline.Before(c.timers[j].deadline)
+		}
+		return c.timers[i].seq < c.timers[j].seq
+	})
+}
+
+func (c *SimClock) removeTimer(t *simTimer) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type simTimer struct {
+	clock    *SimClock
+	ch       chan time.Time
+	deadline time.Time
+	period   time.Duration
+	seq      uint64
+}
+
+func (t *simTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *simTimer) Stop() bool {
+	return t.clock.removeTimer(t)
+}
+
+type simTicker struct {
+	*simTimer
+}
+
+func (t *simTicker) Stop() {
+	t.simTimer.Stop()
+}
@@ -0,0 +1,248 @@
+package testkit
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+)
+
+//
+// The testkit runs a cluster of in-process nodes connected by an in-memory network and sharing a simulated
+// clock, so that the integration tests of the consensus can run within a unit test, deterministically.
+// All the nodes are driven from the goroutine of the test: the cluster delivers the messages one at a
+// time, lets each node react to them, and only advances the clock when the nodes have nothing left to do.
+// Given the same config and the same calls, two runs produce the same blocks. Example:
+//
+//   cluster := testkit.NewCluster(testkit.Config{NumValidators: 4})
+//   cluster.Start()
+//   defer cluster.Stop()
+//
+//   cluster.Run(2 * time.Minute)
+//   cluster.Partition(cluster.Nodes()[:3], cluster.Nodes()[3:])
+//   cluster.Run(2 * time.Minute)
+//   cluster.Heal()
+//   cluster.RunUntil(cluster.Converged, 5*time.Minute)
+//
+
+const (
+	defaultChainID = "testkit"
+
+	maxSettleIterations = 1000000
+)
+
+// DefaultStartTime is the default time the simulated clock starts at.
+var DefaultStartTime = time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Config is the config of a cluster.
+type Config struct {
+	NumValidators int       // number of validator nodes, with equal stakes
+	NumObservers  int       // number of nodes not staking, they follow the chain without voting
+	ChainID       string    // defaults to "testkit"
+	StartTime     time.Time // defaults to DefaultStartTime
+}
+
+// Cluster is a set of in-process nodes running the same chain.
+type Cluster struct {
+	Clock   *SimClock
+	Network *Network
+
+	genesis *Genesis
+	nodes   []*Node
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// NewCluster creates the nodes of a cluster. The validators are the first nodes.
+func NewCluster(config Config) *Cluster {
+	if config.NumValidators <= 0 {
+		panic("a cluster needs at least one validator")
+	}
+	if config.ChainID == "" {
+		config.ChainID = defaultChainID
+	}
+	if config.StartTime.IsZero() {
+		config.StartTime = DefaultStartTime
+	}
+
+	c := &Cluster{
+		Clock:   NewSimClock(config.StartTime),
+		Network: NewNetwork(),
+		genesis: &Genesis{
+			ChainID:    config.ChainID,
+			Timestamp:  config.StartTime.Unix(),
+			Validators: []common.Address{},
+			Stake:      new(big.Int).Mul(big.NewInt(10), core.MinValidatorStakeDeposit),
+		},
+	}
+	keys := []*crypto.PrivateKey{}
+	for i := 0; i < config.NumValidators+config.NumObservers; i++ {
+		keys = append(keys, nodeKey(i))
+		if i < config.NumValidators {
+			c.genesis.Validators = append(c.genesis.Validators, keys[i].PublicKey().Address())
+		}
+	}
+	for i, key := range keys {
+		c.nodes = append(c.nodes, newNode(fmt.Sprintf("node%d", i), key, c.genesis, c.Clock, c.Network))
+	}
+	return c
+}
+
+// nodeKey derives the key of the i-th node, so that the node IDs are the same in every run.
+func nodeKey(i int) *crypto.PrivateKey {
+	privateKey, _, err := crypto.TEST_GenerateKeyPairWithSeed(fmt.Sprintf("testkit-node-%d", i))
+	if err != nil {
+		panic(err)
+	}
+	return privateKey
+}
+
+// Start starts the nodes.
+func (c *Cluster) Start() {
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	for _, node := range c.nodes {
+		node.start(c.ctx)
+	}
+}
+
+// Stop stops the nodes.
+func (c *Cluster) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// Nodes returns the nodes of the cluster, the validators first.
+func (c *Cluster) Nodes() []*Node {
+	return c.nodes
+}
+
+// Validators returns the validator nodes.
+func (c *Cluster) Validators() []*Node {
+	return c.nodes[:len(c.genesis.Validators)]
+}
+
+// AddNode adds a node not staking to a running cluster. The new node only knows the genesis block, and
+// catches up from the blocks proposed after it joins.
+func (c *Cluster) AddNode() *Node {
+	node := newNode(fmt.Sprintf("node%d", len(c.nodes)), nodeKey(len(c.nodes)), c.genesis, c.Clock, c.Network)
+	c.nodes = append(c.nodes, node)
+	if c.ctx != nil {
+		node.start(c.ctx)
+	}
+	return node
+}
+
+// Partition splits the network into the given groups of nodes, the nodes not listed are isolated.
+func (c *Cluster) Partition(groups ...[]*Node) {
+	ids := [][]string{}
+	for _, group := range groups {
+		groupIDs := []string{}
+		for _, node := range group {
+			groupIDs = append(groupIDs, node.ID)
+		}
+		ids = append(ids, groupIDs)
+	}
+	c.Network.Partition(ids...)
+}
+
+// Heal reconnects all the nodes.
+func (c *Cluster) Heal() {
+	c.Network.Heal()
+}
+
+// Settle delivers the messages in flight and lets the nodes react, until the nodes have nothing left to
+// do at the current time.
+func (c *Cluster) Settle() {
+	for i := 0; i < maxSettleIterations; i++ {
+		progress := false
+		for _, node := range c.nodes {
+			if node.step() {
+				progress = true
+			}
+		}
+		if c.Network.DeliverNext() {
+			progress = true
+		}
+		if !progress {
+			return
+		}
+	}
+	panic("the cluster does not settle, the nodes keep exchanging messages")
+}
+
+// Run advances the simulated clock by the given duration. The clock moves from one timer deadline to the
+// next, and the cluster settles at each of them.
+func (c *Cluster) Run(d time.Duration) {
+	c.RunUntil(func() bool { return false }, d)
+}
+
+// RunUntil advances the simulated clock until the condition holds, checked each time the cluster settles,
+// or at most by the given duration. Returns whether the condition holds.
+func (c *Cluster) RunUntil(cond func() bool, max time.Duration) bool {
+	end := c.Clock.Now().Add(max)
+	for {
+		c.Settle()
+		if cond() {
+			return true
+		}
+		next, ok := c.Clock.NextDeadline()
+		if !ok || next.After(end) {
+			c.Clock.AdvanceTo(end)
+			c.Settle()
+			return cond()
+		}
+		c.Clock.AdvanceTo(next)
+	}
+}
+
+// Converged returns true if all the nodes have finalized the same last block.
+func (c *Cluster) Converged() bool {
+	lfb := c.nodes[0].LastFinalizedBlock().Hash()
+	for _, node := range c.nodes[1:] {
+		if node.LastFinalizedBlock().Hash() != lfb {
+			return false
+		}
+	}
+	return true
+}
+
+// MinFinalizedHeight returns the lowest last finalized height among the given nodes, or all the nodes of
+// the cluster if none is given.
+func (c *Cluster) MinFinalizedHeight(nodes ...*Node) uint64 {
+	if len(nodes) == 0 {
+		nodes = c.nodes
+	}
+	height := nodes[0].LastFinalizedBlock().Height
+	for _, node := range nodes[1:] {
+		if h := node.LastFinalizedBlock().Height; h < height {
+			height = h
+		}
+	}
+	return height
+}
+
+// CheckSafety returns an error if two nodes have finalized different blocks at the same height.
+func (c *Cluster) CheckSafety() error {
+	finalized := make(map[uint64]common.Hash)
+	owners := make(map[uint64]string)
+	for _, node := range c.nodes {
+		for _, block := range node.FinalizedBlocks() {
+			hash, ok := finalized[block.Height]
+			if !ok {
+				finalized[block.Height] = block.Hash()
+				owners[block.Height] = node.Name
+				continue
+			}
+			if hash != block.Hash() {
+				return fmt.Errorf("conflicting blocks finalized at height %v: %v by %v, %v by %v",
+					block.Height, hash.Hex(), owners[block.Height], block.Hash().Hex(), node.Name)
+			}
+		}
+	}
+	return nil
+}
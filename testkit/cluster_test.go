@@ -0,0 +1,149 @@
+package testkit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterProducesBlocks(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cluster := NewCluster(Config{NumValidators: 4, NumObservers: 1})
+	cluster.Start()
+	defer cluster.Stop()
+
+	require.True(cluster.RunUntil(func() bool { return cluster.MinFinalizedHeight() >= 5 }, 5*time.Minute))
+	assert.Nil(cluster.CheckSafety())
+}
+
+func TestClusterIsDeterministic(t *testing.T) {
+	assert := assert.New(t)
+
+	run := func() []string {
+		cluster := NewCluster(Config{NumValidators: 3})
+		cluster.Start()
+		defer cluster.Stop()
+
+		cluster.Run(2 * time.Minute)
+		hashes := []string{}
+		for _, block := range cluster.Nodes()[0].FinalizedBlocks() {
+			hashes = append(hashes, block.Hash().Hex())
+		}
+		return hashes
+	}
+	first := run()
+	assert.True(len(first) > 0)
+	assert.Equal(first, run())
+}
+
+func TestClusterPartition(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cluster := NewCluster(Config{NumValidators: 4})
+	cluster.Start()
+	defer cluster.Stop()
+
+	require.True(cluster.RunUntil(func() bool { return cluster.MinFinalizedHeight() >= 3 }, 5*time.Minute))
+
+	// Three of the four validators hold the majority and keep finalizing, the isolated one cannot
+	nodes := cluster.Nodes()
+	majority, minority := nodes[:3], nodes[3:]
+	cluster.Partition(majority, minority)
+	isolatedHeight := minority[0].LastFinalizedBlock().Height
+	target := cluster.MinFinalizedHeight(majority...) + 5
+	require.True(cluster.RunUntil(func() bool { return cluster.MinFinalizedHeight(majority...) >= target }, 10*time.Minute))
+	assert.Equal(isolatedHeight, minority[0].LastFinalizedBlock().Height)
+
+	// After healing, the isolated validator catches up with the others
+	cluster.Heal()
+	target = cluster.MinFinalizedHeight(majority...) + 3
+	require.True(cluster.RunUntil(func() bool {
+		return cluster.MinFinalizedHeight() >= target
+	}, 10*time.Minute))
+	assert.Nil(cluster.CheckSafety())
+}
+
+func TestClusterSplitBrain(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cluster := NewCluster(Config{NumValidators: 4})
+	cluster.Start()
+	defer cluster.Stop()
+
+	require.True(cluster.RunUntil(func() bool { return cluster.MinFinalizedHeight() >= 2 }, 5*time.Minute))
+
+	// Neither half holds the majority, so no block is finalized until the network heals
+	nodes := cluster.Nodes()
+	cluster.Partition(nodes[:2], nodes[2:])
+	cluster.Settle()
+	heights := []uint64{}
+	for _, node := range nodes {
+		heights = append(heights, node.LastFinalizedBlock().Height)
+	}
+	cluster.Run(3 * time.Minute)
+	for i, node := range nodes {
+		assert.True(node.LastFinalizedBlock().Height <= heights[i]+1)
+	}
+
+	cluster.Heal()
+	target := cluster.MinFinalizedHeight() + 3
+	require.True(cluster.RunUntil(func() bool { return cluster.MinFinalizedHeight() >= target }, 10*time.Minute))
+	assert.Nil(cluster.CheckSafety())
+}
+
+func TestClusterLateJoiner(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cluster := NewCluster(Config{NumValidators: 3})
+	cluster.Start()
+	defer cluster.Stop()
+
+	require.True(cluster.RunUntil(func() bool { return cluster.MinFinalizedHeight() >= 8 }, 5*time.Minute))
+
+	joiner := cluster.AddNode()
+	target := cluster.MinFinalizedHeight(cluster.Validators()...) + 2
+	require.True(cluster.RunUntil(func() bool { return joiner.LastFinalizedBlock().Height >= target }, 5*time.Minute))
+	assert.Nil(cluster.CheckSafety())
+}
+
+func TestSimClock(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := NewSimClock(DefaultStartTime)
+	first := clock.NewTimer(2 * time.Second)
+	second := clock.NewTimer(1 * time.Second)
+	ticker := clock.NewTicker(time.Second)
+	stopped := clock.NewTimer(time.Second)
+	assert.True(stopped.Stop())
+
+	next, ok := clock.NextDeadline()
+	assert.True(ok)
+	assert.Equal(DefaultStartTime.Add(time.Second), next)
+
+	clock.Advance(1500 * time.Millisecond)
+	assert.Equal(DefaultStartTime.Add(1500*time.Millisecond), clock.Now())
+	assert.Equal(DefaultStartTime.Add(time.Second), <-second.C())
+	assert.Equal(DefaultStartTime.Add(time.Second), <-ticker.C())
+	select {
+	case <-first.C():
+		assert.Fail("timer fired early")
+	case <-stopped.C():
+		assert.Fail("stopped timer fired")
+	default:
+	}
+
+	clock.Advance(time.Second)
+	assert.Equal(DefaultStartTime.Add(2*time.Second), <-first.C())
+	assert.Equal(DefaultStartTime.Add(2*time.Second), <-ticker.C())
+	assert.False(first.Stop())
+	ticker.Stop()
+	_, ok = clock.NextDeadline()
+	assert.False(ok)
+}
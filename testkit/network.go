@@ -0,0 +1,306 @@
+package testkit
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/p2p"
+	p2ptypes "github.com/thetatoken/theta/p2p/types"
+	"github.com/thetatoken/theta/p2pl"
+)
+
+// Envelope is a message in flight between two endpoints. The content is kept encoded, so that the nodes
+// never share the decoded objects.
+type Envelope struct {
+	From      string
+	To        string
+	ChannelID common.ChannelIDEnum
+	Raw       common.Bytes
+}
+
+// Network is an in-memory transport connecting the endpoints of a cluster. The messages are queued in the
+// order they are sent, and only delivered when the cluster asks for it, one at a time. The endpoints in
+// different partitions cannot reach each other, the messages between them are dropped on delivery.
+type Network struct {
+	mu        *sync.Mutex
+	endpoints map[string]*Endpoint
+	queue     []*Envelope
+	partition map[string]int // partition index of the endpoints, all in partition 0 if healed
+	filter    func(envelope *Envelope) bool
+
+	Delivered int
+	Dropped   int
+}
+
+// NewNetwork creates an empty network.
+func NewNetwork() *Network {
+	return &Network{
+		mu:        &sync.Mutex{},
+		endpoints: make(map[string]*Endpoint),
+		partition: make(map[string]int),
+	}
+}
+
+// AddEndpoint adds an endpoint with the given ID, connected to all the other endpoints.
+func (n *Network) AddEndpoint(id string) *Endpoint {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	endpoint := &Endpoint{
+		id:       id,
+		network:  n,
+		handlers: make(map[common.ChannelIDEnum]p2p.MessageHandler),
+	}
+	n.endpoints[id] = endpoint
+	return endpoint
+}
+
+// Partition splits the network, the endpoints of each group can only reach the endpoints of the same
+// group. The endpoints not listed in any group are isolated from all the others.
+func (n *Network) Partition(groups ...[]string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.partition = make(map[string]int)
+	for id := range n.endpoints {
+		n.partition[id] = -1
+	}
+	for i, group := range groups {
+		for _, id := range group {
+			n.partition[id] = i + 1
+		}
+	}
+}
+
+// Heal reconnects all the endpoints.
+func (n *Network) Heal() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.partition = make(map[string]int)
+}
+
+// SetFilter sets a function called on each message before its delivery, the message is dropped if the
+// function returns false. A nil filter delivers all the messages.
+func (n *Network) SetFilter(filter func(envelope *Envelope) bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.filter = filter
+}
+
+// Pending returns the number of the messages waiting for delivery.
+func (n *Network) Pending() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return len(n.queue)
+}
+
+// DeliverNext delivers the oldest message in flight, and returns false if there was none. The receiver
+// handles the message before DeliverNext returns.
+func (n *Network) DeliverNext() bool {
+	n.mu.Lock()
+	if len(n.queue) == 0 {
+		n.mu.Unlock()
+		return false
+	}
+	envelope := n.queue[0]
+	n.queue = n.queue[1:]
+	receiver, ok := n.endpoints[envelope.To]
+	if !ok || !n.canReach(envelope.From, envelope.To) || (n.filter != nil && !n.filter(envelope)) {
+		n.Dropped++
+		n.mu.Unlock()
+		return true
+	}
+	n.Delivered++
+	n.mu.Unlock()
+
+	receiver.deliver(envelope)
+	return true
+}
+
+// canReach must be called with the lock held.
+func (n *Network) canReach(from, to string) bool {
+	if len(n.partition) == 0 {
+		return true
+	}
+	fromPartition, toPartition := n.partition[from], n.partition[to]
+	return fromPartition >= 0 && fromPartition == toPartition
+}
+
+// peersOf returns the sorted IDs of the endpoints reachable from the given endpoint.
+func (n *Network) peersOf(id string) []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	peers := []string{}
+	for peerID := range n.endpoints {
+		if peerID != id && n.canReach(id, peerID) {
+			peers = append(peers, peerID)
+		}
+	}
+	sort.Strings(peers)
+	return peers
+}
+
+func (n *Network) enqueue(envelope *Envelope) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.queue = append(n.queue, envelope)
+}
+
+var _ p2p.Network = (*Endpoint)(nil)
+
+// Endpoint is the implementation of the p2p.Network interface for a node of the in-memory network. The
+// messages are encoded and decoded by the handlers registered for their channels, like the messenger does.
+type Endpoint struct {
+	id       string
+	network  *Network
+	handlers map[common.ChannelIDEnum]p2p.MessageHandler
+}
+
+// Start implements the p2p.Network interface.
+func (e *Endpoint) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop implements the p2p.Network interface.
+func (e *Endpoint) Stop() {
+}
+
+// Wait implements the p2p.Network interface.
+func (e *Endpoint) Wait() {
+}
+
+// Broadcast implements the p2p.Network interface.
+func (e *Endpoint) Broadcast(message p2ptypes.Message, skipEdgeNode bool) chan bool {
+	successes := make(chan bool, 1)
+	successes <- e.sendToPeers(e.Peers(skipEdgeNode), message)
+	return successes
+}
+
+// BroadcastToNeighbors implements the p2p.Network interface. All the peers are neighbors in the in-memory
+// network, so the messages are sent to all of them regardless of the sample size.
+func (e *Endpoint) BroadcastToNeighbors(message p2ptypes.Message, maxNumPeersToBroadcast int, skipEdgeNode bool) chan bool {
+	return e.Broadcast(message, skipEdgeNode)
+}
+
+// Send implements the p2p.Network interface.
+func (e *Endpoint) Send(peerID string, message p2ptypes.Message) bool {
+	return e.sendToPeers([]string{peerID}, message)
+}
+
+func (e *Endpoint) sendToPeers(peerIDs []string, message p2ptypes.Message) bool {
+	handler, ok := e.handlers[message.ChannelID]
+	if !ok {
+		log.WithFields(log.Fields{"id": e.id, "channelID": message.ChannelID}).Warn("No handler to encode message")
+		return false
+	}
+	raw, err := handler.EncodeMessage(message.Content)
+	if err != nil {
+		log.WithFields(log.Fields{"id": e.id, "channelID": message.ChannelID, "error": err}).Warn("Failed to encode message")
+		return false
+	}
+	for _, peerID := range peerIDs {
+		e.network.enqueue(&Envelope{
+			From:      e.id,
+			To:        peerID,
+			ChannelID: message.ChannelID,
+			Raw:       raw,
+		})
+	}
+	return true
+}
+
+func (e *Endpoint) deliver(envelope *Envelope) {
+	handler, ok := e.handlers[envelope.ChannelID]
+	if !ok {
+		return
+	}
+	message, err := handler.ParseMessage(envelope.From, envelope.ChannelID, envelope.Raw)
+	if err != nil {
+		log.WithFields(log.Fields{"id": e.id, "from": envelope.From, "error": err}).Warn("Failed to parse message")
+		return
+	}
+	handler.HandleMessage(message)
+}
+
+// Peers implements the p2p.Network interface.
+func (e *Endpoint) Peers(skipEdgeNode bool) []string {
+	return e.network.peersOf(e.id)
+}
+
+// PeerURLs implements the p2p.Network interface.
+func (e *Endpoint) PeerURLs(skipEdgeNode bool) []string {
+	return []string{}
+}
+
+// PeerCapabilities implements the p2p.Network interface.
+func (e *Endpoint) PeerCapabilities(skipEdgeNode bool) map[string]*p2ptypes.NodeCapabilities {
+	capabilities := make(map[string]*p2ptypes.NodeCapabilities)
+	for _, peerID := range e.Peers(skipEdgeNode) {
+		capabilities[peerID] = nil
+	}
+	return capabilities
+}
+
+// ProbeTopology implements the p2p.Network interface.
+func (e *Endpoint) ProbeTopology(timeout time.Duration) (map[string][]p2ptypes.TopologyNeighbor, error) {
+	neighbors := []p2ptypes.TopologyNeighbor{}
+	for _, peerID := range e.Peers(false) {
+		neighbors = append(neighbors, p2ptypes.TopologyNeighbor{ID: peerID, Outbound: peerID > e.id})
+	}
+	return map[string][]p2ptypes.TopologyNeighbor{e.id: neighbors}, nil
+}
+
+// PeerExists implements the p2p.Network interface.
+func (e *Endpoint) PeerExists(peerID string) bool {
+	for _, id := range e.Peers(false) {
+		if id == peerID {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterMessageHandler implements the p2p.Network interface.
+func (e *Endpoint) RegisterMessageHandler(handler p2p.MessageHandler) {
+	for _, channelID := range handler.GetChannelIDs() {
+		e.handlers[channelID] = handler
+	}
+}
+
+// ID implements the p2p.Network interface.
+func (e *Endpoint) ID() string {
+	return e.id
+}
+
+// noLibp2p stands for the absent libp2p network. The dispatcher only skips a network given as a nil
+// pointer, not as a nil interface.
+type noLibp2p struct{}
+
+var _ p2pl.Network = (*noLibp2p)(nil)
+
+func (*noLibp2p) Start(ctx context.Context) error                     { return nil }
+func (*noLibp2p) Wait()                                               {}
+func (*noLibp2p) Stop()                                               {}
+func (*noLibp2p) Publish(message p2ptypes.Message) error              { return nil }
+func (*noLibp2p) Send(peerID string, message p2ptypes.Message) bool   { return false }
+func (*noLibp2p) Peers(skipEdgeNode bool) []string                    { return []string{} }
+func (*noLibp2p) PeerURLs(skipEdgeNode bool) []string                 { return []string{} }
+func (*noLibp2p) PeerExists(peerID string) bool                       { return false }
+func (*noLibp2p) RegisterMessageHandler(handler p2pl.MessageHandler)  {}
+func (*noLibp2p) ID() string                                          { return "" }
+func (*noLibp2p) Broadcast(p2ptypes.Message, bool) chan bool          { return nil }
+func (*noLibp2p) BroadcastToNeighbors(p2ptypes.Message, int, bool) chan bool {
+	return nil
+}
+func (*noLibp2p) PeerCapabilities(skipEdgeNode bool) map[string]*p2ptypes.NodeCapabilities {
+	return nil
+}
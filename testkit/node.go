@@ -0,0 +1,315 @@
+package testkit
+
+import (
+	"context"
+	"math/big"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/thetatoken/theta/blockchain"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/consensus"
+	"github.com/thetatoken/theta/core"
+	"github.com/thetatoken/theta/crypto"
+	"github.com/thetatoken/theta/dispatcher"
+	"github.com/thetatoken/theta/ledger"
+	"github.com/thetatoken/theta/ledger/state"
+	"github.com/thetatoken/theta/ledger/types"
+	"github.com/thetatoken/theta/mempool"
+	"github.com/thetatoken/theta/netsync"
+	"github.com/thetatoken/theta/p2p"
+	p2ptypes "github.com/thetatoken/theta/p2p/types"
+	"github.com/thetatoken/theta/rlp"
+	"github.com/thetatoken/theta/store/database"
+	"github.com/thetatoken/theta/store/database/backend"
+	"github.com/thetatoken/theta/store/kvstore"
+)
+
+// Genesis describes the initial state shared by the nodes of a cluster.
+type Genesis struct {
+	ChainID    string
+	Timestamp  int64
+	Validators []common.Address
+	Stake      *big.Int // stake of each validator
+}
+
+// write saves the genesis state to the database and returns the genesis block. The nodes write the same
+// state, so they all agree on the genesis block hash.
+func (g *Genesis) write(db database.Database) *core.Block {
+	sv := state.NewStoreView(core.GenesisBlockHeight, common.Hash{}, db)
+
+	vcp := &core.ValidatorCandidatePool{}
+	for _, validator := range g.Validators {
+		account := types.NewAccount(validator)
+		account.Balance = types.Coins{
+			ThetaWei: new(big.Int).Mul(g.Stake, big.NewInt(2)),
+			TFuelWei: new(big.Int).Mul(g.Stake, big.NewInt(10)),
+		}
+		if err := vcp.DepositStake(validator, validator, g.Stake, g.ChainID); err != nil {
+			log.Panicf("Failed to deposit the genesis stake: %v", err)
+		}
+		account.Balance = account.Balance.Minus(types.Coins{ThetaWei: g.Stake, TFuelWei: big.NewInt(0)})
+		sv.SetAccount(validator, account)
+	}
+	sv.UpdateValidatorCandidatePool(vcp)
+	hl := &types.HeightList{}
+	hl.Append(core.GenesisBlockHeight)
+	sv.UpdateStakeTransactionHeightList(hl)
+	stateHash := sv.Save()
+
+	block := core.NewBlock()
+	block.ChainID = g.ChainID
+	block.Height = core.GenesisBlockHeight
+	block.Epoch = block.Height
+	block.StateHash = stateHash
+	block.Timestamp = big.NewInt(g.Timestamp)
+	return block
+}
+
+var _ p2p.MessageHandler = (*Node)(nil)
+
+// Node is a node of the cluster, running the real chain, ledger and consensus engine on an in-memory database.
+// The consensus engine runs in the stepping mode, and the node relays the proposals, blocks and votes itself
+// in place of the sync manager, whose goroutines and timers would make the runs nondeterministic. A node
+// receiving a block whose parent it does not know asks the sender for the parent, which is how the nodes
+// catch up after a partition or when they join late.
+type Node struct {
+	Name       string
+	ID         string
+	PrivateKey *crypto.PrivateKey
+	DB         database.Database
+	Chain      *blockchain.Chain
+	Consensus  *consensus.ConsensusEngine
+	Ledger     *ledger.Ledger
+	Mempool    *mempool.Mempool
+	Dispatcher *dispatcher.Dispatcher
+
+	endpoint  *Endpoint
+	inbox     []interface{} // messages for the consensus engine, handed over one at a time
+	finalized []*core.Block
+	passed    map[common.Hash]bool // pending blocks passed down to the consensus engine
+	requested map[common.Hash]bool // missing parents requested from the peers
+}
+
+func newNode(name string, privateKey *crypto.PrivateKey, genesis *Genesis, clock *SimClock, network *Network) *Node {
+	db := backend.NewMemDatabase()
+	root := genesis.write(db)
+	store := kvstore.NewKVStore(db)
+	chain := blockchain.NewChain(genesis.ChainID, store, root)
+
+	id := privateKey.PublicKey().Address().Hex()
+	endpoint := network.AddEndpoint(id)
+	disp := dispatcher.NewDispatcher(endpoint, (*noLibp2p)(nil))
+
+	validatorManager := consensus.NewRotatingValidatorManager()
+	engine := consensus.NewConsensusEngine(privateKey, store, chain, disp, validatorManager)
+	engine.SetClock(clock)
+	mp := mempool.CreateMempool(disp, engine)
+	ld := ledger.NewLedger(genesis.ChainID, db, chain, engine, validatorManager, mp)
+	validatorManager.SetConsensusEngine(engine)
+	engine.SetLedger(ld)
+	mp.SetLedger(ld)
+
+	n := &Node{
+		Name:       name,
+		ID:         id,
+		PrivateKey: privateKey,
+		DB:         db,
+		Chain:      chain,
+		Consensus:  engine,
+		Ledger:     ld,
+		Mempool:    mp,
+		Dispatcher: disp,
+
+		endpoint:  endpoint,
+		finalized: []*core.Block{},
+		passed:    make(map[common.Hash]bool),
+		requested: make(map[common.Hash]bool),
+	}
+	endpoint.RegisterMessageHandler(n)
+	return n
+}
+
+func (n *Node) start(ctx context.Context) {
+	n.Consensus.StartStepping(ctx)
+}
+
+// step runs the consensus engine until it has nothing left to do, and returns false if it did nothing. The
+// messages are only handed over when the engine is idle, so that its bounded queue never fills up.
+func (n *Node) step() bool {
+	progress := false
+	for {
+		if n.Consensus.Step() {
+			progress = true
+			n.passReadyBlocks()
+			continue
+		}
+		if len(n.inbox) == 0 {
+			break
+		}
+		n.Consensus.AddMessage(n.inbox[0])
+		n.inbox = n.inbox[1:]
+	}
+	for {
+		select {
+		case block := <-n.Consensus.FinalizedBlocks():
+			n.finalized = append(n.finalized, block)
+			continue
+		default:
+		}
+		break
+	}
+	return progress
+}
+
+// FinalizedBlocks returns the blocks finalized by the node since it started, in order.
+func (n *Node) FinalizedBlocks() []*core.Block {
+	return n.finalized
+}
+
+// LastFinalizedBlock returns the last finalized block of the node.
+func (n *Node) LastFinalizedBlock() *core.ExtendedBlock {
+	return n.Consensus.GetLastFinalizedBlock()
+}
+
+// GetChannelIDs implements the p2p.MessageHandler interface.
+func (n *Node) GetChannelIDs() []common.ChannelIDEnum {
+	return []common.ChannelIDEnum{
+		common.ChannelIDBlock,
+		common.ChannelIDProposal,
+		common.ChannelIDVote,
+	}
+}
+
+// ParseMessage implements the p2p.MessageHandler interface.
+func (n *Node) ParseMessage(peerID string, channelID common.ChannelIDEnum, rawMessageBytes common.Bytes) (p2ptypes.Message, error) {
+	content, err := netsync.DecodeMessage(rawMessageBytes)
+	return p2ptypes.Message{
+		PeerID:    peerID,
+		ChannelID: channelID,
+		Content:   content,
+	}, err
+}
+
+// EncodeMessage implements the p2p.MessageHandler interface.
+func (n *Node) EncodeMessage(message interface{}) (common.Bytes, error) {
+	return netsync.EncodeMessage(message)
+}
+
+// HandleMessage implements the p2p.MessageHandler interface.
+func (n *Node) HandleMessage(message p2ptypes.Message) error {
+	switch content := message.Content.(type) {
+	case dispatcher.DataRequest:
+		n.handleDataRequest(message.PeerID, content)
+	case dispatcher.DataResponse:
+		n.handleDataResponse(message.PeerID, content)
+	}
+	return nil
+}
+
+func (n *Node) handleDataRequest(peerID string, request dispatcher.DataRequest) {
+	if request.ChannelID != common.ChannelIDBlock {
+		return
+	}
+	for _, entry := range request.Entries {
+		block, err := n.Chain.FindBlock(common.HexToHash(entry))
+		if err != nil {
+			continue
+		}
+		payload, err := rlp.EncodeToBytes(block.Block)
+		if err != nil {
+			continue
+		}
+		n.endpoint.Send(peerID, p2ptypes.Message{
+			ChannelID: common.ChannelIDBlock,
+			Content:   dispatcher.DataResponse{ChannelID: common.ChannelIDBlock, Payload: payload},
+		})
+	}
+}
+
+func (n *Node) handleDataResponse(peerID string, response dispatcher.DataResponse) {
+	switch response.ChannelID {
+	case common.ChannelIDBlock:
+		block := core.NewBlock()
+		if err := rlp.DecodeBytes(response.Payload, block); err != nil {
+			return
+		}
+		n.handleBlock(peerID, block)
+	case common.ChannelIDVote:
+		vote := core.Vote{}
+		if err := rlp.DecodeBytes(response.Payload, &vote); err != nil {
+			return
+		}
+		n.handleVote(vote)
+	case common.ChannelIDProposal:
+		proposal := &core.Proposal{}
+		if err := rlp.DecodeBytes(response.Payload, proposal); err != nil {
+			return
+		}
+		if proposal.Votes != nil {
+			for _, vote := range proposal.Votes.Votes() {
+				n.handleVote(vote)
+			}
+		}
+		if proposal.Block != nil {
+			n.handleBlock(peerID, proposal.Block)
+		}
+	}
+}
+
+func (n *Node) handleVote(vote core.Vote) {
+	for _, v := range n.Chain.FindVotesByHash(vote.Block).Votes() {
+		if v.Block == vote.Block && v.Epoch == vote.Epoch && v.Height == vote.Height && v.ID == vote.ID {
+			return
+		}
+	}
+	if b, err := n.Chain.FindBlock(vote.Block); err == nil && b.Status == core.BlockStatusDisposed {
+		return
+	}
+	n.inbox = append(n.inbox, vote)
+}
+
+func (n *Node) handleBlock(peerID string, block *core.Block) {
+	if eb, err := n.Chain.FindBlock(block.Hash()); err == nil && !eb.Status.IsPending() {
+		return
+	}
+	if res := block.Validate(n.Chain.ChainID); res.IsError() {
+		return
+	}
+	n.Chain.AddBlock(block)
+
+	if _, err := n.Chain.FindBlock(block.Parent); err != nil && !n.requested[block.Parent] {
+		n.requested[block.Parent] = true
+		request := dispatcher.DataRequest{
+			ChannelID: common.ChannelIDBlock,
+			Entries:   []string{block.Parent.Hex()},
+		}
+		n.endpoint.Send(peerID, p2ptypes.Message{ChannelID: common.ChannelIDBlock, Content: request})
+	}
+	n.passReadyBlocks()
+}
+
+// passReadyBlocks passes down the pending blocks whose parents are valid to the consensus engine, in the
+// order of their heights.
+func (n *Node) passReadyBlocks() {
+	lfb := n.Consensus.GetLastFinalizedBlock()
+	parents := []*core.ExtendedBlock{lfb}
+	for height := lfb.Height + 1; ; height++ {
+		blocks := n.Chain.FindBlocksByHeight(height)
+		if len(blocks) == 0 {
+			return
+		}
+		for _, block := range blocks {
+			if n.passed[block.Hash()] || !block.Status.IsPending() {
+				continue
+			}
+			for _, parent := range parents {
+				if parent.Hash() == block.Parent && parent.Status.IsValid() {
+					n.passed[block.Hash()] = true
+					n.inbox = append(n.inbox, block.Block)
+					break
+				}
+			}
+		}
+		parents = blocks
+	}
+}
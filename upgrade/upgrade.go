@@ -0,0 +1,60 @@
+package upgrade
+
+import (
+	"github.com/spf13/viper"
+	"github.com/thetatoken/theta/common"
+	"github.com/thetatoken/theta/core"
+)
+
+// implementedUpgrades lists the names of the upgrades implemented by this binary. A new release adds
+// the name of the upgrade it implements, so that the nodes running it continue past the upgrade height.
+var implementedUpgrades = map[string]bool{
+	core.ForkTheta2:                true,
+	core.ForkTheta3:                true,
+	core.ForkJune2021FeeAdjustment: true,
+	core.ForkParameterChange:       true,
+	core.ForkSubchainAnchoring:     true,
+	core.ForkCrossChainMessaging:   true,
+	core.ForkValidatorKeyChange:    true,
+	core.ForkStakeAutoCompounding:  true,
+	core.ForkRandomnessBeacon:      true,
+	core.ForkScheduledTx:           true,
+	core.ForkFeeSponsorship:        true,
+	core.ForkDynamicBaseFee:        true,
+}
+
+//
+// Plan describes a coordinated upgrade. The nodes running a binary that does not implement the
+// upgrade stop producing and applying blocks at the upgrade height until restarted with a binary
+// that does, so that the nodes upgraded at different times do not split the chain.
+//
+type Plan struct {
+	Name   string `json:"name"`
+	Height uint64 `json:"height"`
+	Info   string `json:"info"` // e.g. the release URL
+}
+
+// IsImplemented returns whether the upgrade is implemented by this binary
+func (p *Plan) IsImplemented() bool {
+	return implementedUpgrades[p.Name]
+}
+
+// GetPendingPlan returns the upgrade plan signaled by the config, nil if no upgrade is pending
+func GetPendingPlan() *Plan {
+	name := viper.GetString(common.CfgUpgradeName)
+	height := viper.GetUint64(common.CfgUpgradeHeight)
+	if name == "" || height == 0 {
+		return nil
+	}
+	return &Plan{
+		Name:   name,
+		Height: height,
+		Info:   viper.GetString(common.CfgUpgradeInfo),
+	}
+}
+
+// ShouldHalt returns whether the node should stop producing and applying the block at the given height
+func ShouldHalt(height uint64) bool {
+	plan := GetPendingPlan()
+	return plan != nil && !plan.IsImplemented() && height >= plan.Height
+}
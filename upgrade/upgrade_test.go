@@ -0,0 +1,37 @@
+package upgrade
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/thetatoken/theta/common"
+)
+
+func TestShouldHalt(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func() {
+		viper.Set(common.CfgUpgradeName, "")
+		viper.Set(common.CfgUpgradeHeight, 0)
+	}()
+
+	// No pending upgrade
+	assert.Nil(GetPendingPlan())
+	assert.False(ShouldHalt(1000))
+
+	// Upgrade not implemented by this binary
+	viper.Set(common.CfgUpgradeName, "future_upgrade")
+	viper.Set(common.CfgUpgradeHeight, 1000)
+	plan := GetPendingPlan()
+	assert.NotNil(plan)
+	assert.False(plan.IsImplemented())
+	assert.False(ShouldHalt(999))
+	assert.True(ShouldHalt(1000))
+	assert.True(ShouldHalt(1001))
+
+	// Upgrade implemented by this binary
+	viper.Set(common.CfgUpgradeName, "theta3")
+	assert.True(GetPendingPlan().IsImplemented())
+	assert.False(ShouldHalt(1000))
+}